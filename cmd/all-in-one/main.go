@@ -0,0 +1,500 @@
+// Command all-in-one runs the API server, the tenant controller and the workflow worker in one
+// process, for small installations and edge deployments. The three share the compute registry,
+// tenant repository and database pool, and the controller runs workflows in process through the
+// inprocess workflow provider instead of a workflow engine, so no Restate server is needed.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api"
+	"github.com/jaxxstorm/landlord/internal/approval"
+	approvalpostgres "github.com/jaxxstorm/landlord/internal/approval/postgres"
+	"github.com/jaxxstorm/landlord/internal/capture"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	computedocker "github.com/jaxxstorm/landlord/internal/compute/providers/docker"
+	computedecs "github.com/jaxxstorm/landlord/internal/compute/providers/ecs"
+	computefirecracker "github.com/jaxxstorm/landlord/internal/compute/providers/firecracker"
+	computemock "github.com/jaxxstorm/landlord/internal/compute/providers/mock"
+	computepool "github.com/jaxxstorm/landlord/internal/compute/providers/pool"
+	computepoolpostgres "github.com/jaxxstorm/landlord/internal/compute/providers/pool/postgres"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/controller"
+	"github.com/jaxxstorm/landlord/internal/cost"
+	"github.com/jaxxstorm/landlord/internal/database"
+	dataplaneobjectstore "github.com/jaxxstorm/landlord/internal/dataplane/objectstore"
+	dataplanepostgres "github.com/jaxxstorm/landlord/internal/dataplane/postgres"
+	"github.com/jaxxstorm/landlord/internal/doctor"
+	"github.com/jaxxstorm/landlord/internal/egress"
+	"github.com/jaxxstorm/landlord/internal/endpointauth"
+	"github.com/jaxxstorm/landlord/internal/execution"
+	executionpostgres "github.com/jaxxstorm/landlord/internal/execution/postgres"
+	"github.com/jaxxstorm/landlord/internal/featureflag"
+	"github.com/jaxxstorm/landlord/internal/housekeeping"
+	"github.com/jaxxstorm/landlord/internal/imagepolicy"
+	"github.com/jaxxstorm/landlord/internal/imageupdate"
+	"github.com/jaxxstorm/landlord/internal/logger"
+	"github.com/jaxxstorm/landlord/internal/observe"
+	operationpostgres "github.com/jaxxstorm/landlord/internal/operation/postgres"
+	"github.com/jaxxstorm/landlord/internal/plugin"
+	"github.com/jaxxstorm/landlord/internal/project"
+	projectpostgres "github.com/jaxxstorm/landlord/internal/project/postgres"
+	"github.com/jaxxstorm/landlord/internal/providerconfig"
+	providerconfigpostgres "github.com/jaxxstorm/landlord/internal/providerconfig/postgres"
+	"github.com/jaxxstorm/landlord/internal/resolution"
+	"github.com/jaxxstorm/landlord/internal/resource"
+	"github.com/jaxxstorm/landlord/internal/retention"
+	"github.com/jaxxstorm/landlord/internal/schedule"
+	schedulepostgres "github.com/jaxxstorm/landlord/internal/schedule/postgres"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantcache "github.com/jaxxstorm/landlord/internal/tenant/cache"
+	"github.com/jaxxstorm/landlord/internal/tenant/postgres"
+	"github.com/jaxxstorm/landlord/internal/uptime"
+	"github.com/jaxxstorm/landlord/internal/vulnscan"
+	"github.com/jaxxstorm/landlord/internal/warmpool"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/providers/inprocess"
+	"github.com/jaxxstorm/landlord/internal/workflow/providers/restate"
+)
+
+// stopper is a background controller started alongside the server
+type stopper interface {
+	Stop()
+}
+
+func main() {
+	// Load configuration
+	v := config.NewViperInstance()
+	if err := config.BindEnvironmentVariables(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind environment variables: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Find and load config file
+	configFile, err := config.FindConfigFile("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find config file: %v\n", err)
+		os.Exit(1)
+	}
+	if configFile == "" {
+		fmt.Fprintln(os.Stderr, "Config file is required for startup")
+		os.Exit(1)
+	}
+
+	if err := config.LoadConfigFile(v, configFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadFromViper(v)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Initialize logger
+	log, err := logger.New(cfg.Log.Format, cfg.Log.Level)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	log.Info("starting landlord in all-in-one mode")
+
+	ctx := context.Background()
+
+	// Initialize database
+	dbProvider, err := database.NewProvider(ctx, &cfg.Database, log)
+	if err != nil {
+		log.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbProvider.Close()
+
+	// Initialize compute registry and register providers
+	computeRegistry := compute.NewRegistry(log)
+	if cfg.Compute.Mock != nil {
+		mockProvider := computemock.NewWithDefaults(cfg.Compute.Mock.Defaults)
+		if len(cfg.Compute.Mock.Defaults) > 0 {
+			if err := validateProviderDefaults("mock", mockProvider, cfg.Compute.Mock.Defaults); err != nil {
+				log.Fatal("Invalid mock compute defaults", zap.Error(err))
+			}
+		}
+		computeRegistry.Register(mockProvider)
+	}
+	if cfg.Compute.ECS != nil {
+		ecsProvider := computedecs.New(log, cfg.Compute.ECS.Defaults)
+		if err := validateProviderDefaults("ecs", ecsProvider, cfg.Compute.ECS.Defaults); err != nil {
+			log.Fatal("Invalid ECS compute defaults", zap.Error(err))
+		}
+		computeRegistry.Register(ecsProvider)
+	}
+
+	// Register Docker provider if configured
+	if cfg.Compute.Docker != nil {
+		log.Info("registering Docker compute provider")
+		dockerProvider, err := computedocker.New(
+			&computedocker.Config{
+				Host:              cfg.Compute.Docker.Host,
+				NetworkName:       cfg.Compute.Docker.NetworkName,
+				NetworkDriver:     cfg.Compute.Docker.NetworkDriver,
+				LabelPrefix:       cfg.Compute.Docker.LabelPrefix,
+				NetworkIsolation:  cfg.Compute.Docker.NetworkIsolation,
+				IngressNetwork:    cfg.Compute.Docker.IngressNetwork,
+				EmulatedPlatforms: cfg.Compute.Docker.EmulatedPlatforms,
+				Runtime:           cfg.Compute.Docker.Runtime,
+				Namespace:         cfg.Compute.Docker.Namespace,
+				EgressFirewall:    cfg.Compute.Docker.EgressFirewall,
+				Naming: computedocker.NamingConfig{
+					Prefix:             cfg.Compute.Docker.Naming.Prefix,
+					IncludeName:        cfg.Compute.Docker.Naming.IncludeName,
+					IncludeEnvironment: cfg.Compute.Docker.Naming.IncludeEnvironment,
+					ID:                 cfg.Compute.Docker.Naming.ID,
+				},
+			},
+			cfg.Compute.Docker.Defaults,
+			log,
+		)
+		if err != nil {
+			log.Fatal("Failed to initialize Docker provider", zap.Error(err))
+		}
+		if err := validateProviderDefaults("docker", dockerProvider, cfg.Compute.Docker.Defaults); err != nil {
+			log.Fatal("Invalid Docker compute defaults", zap.Error(err))
+		}
+		computeRegistry.Register(dockerProvider)
+	}
+
+	// Register Firecracker provider if configured
+	if cfg.Compute.Firecracker != nil {
+		log.Info("registering Firecracker compute provider")
+		firecrackerProvider, err := computefirecracker.New(
+			&computefirecracker.Config{
+				Mode:        cfg.Compute.Firecracker.Mode,
+				BinaryPath:  cfg.Compute.Firecracker.BinaryPath,
+				StateDir:    cfg.Compute.Firecracker.StateDir,
+				KataRuntime: cfg.Compute.Firecracker.KataRuntime,
+				Namespace:   cfg.Compute.Firecracker.Namespace,
+			},
+			cfg.Compute.Firecracker.Defaults,
+			log,
+		)
+		if err != nil {
+			log.Fatal("Failed to initialize Firecracker provider", zap.Error(err))
+		}
+		if err := validateProviderDefaults("firecracker", firecrackerProvider, cfg.Compute.Firecracker.Defaults); err != nil {
+			log.Fatal("Invalid Firecracker compute defaults", zap.Error(err))
+		}
+		computeRegistry.Register(firecrackerProvider)
+	}
+
+	// Launch out-of-process provider plugins; this binary runs no other workflow providers
+	plugins, err := plugin.Load(ctx, cfg.Plugins, log)
+	if err != nil {
+		log.Fatal("Failed to load plugins", zap.Error(err))
+	}
+	defer plugins.Close()
+	if err := plugins.Register(computeRegistry, nil); err != nil {
+		log.Fatal("Failed to register plugins", zap.Error(err))
+	}
+
+	// Get database connection pool from provider
+	pool, ok := dbProvider.Pool().(*pgxpool.Pool)
+	if !ok {
+		log.Fatal("Database provider is not a pgxpool.Pool")
+	}
+
+	// Register the pool provider now the database is available; slot assignments are kept there
+	if cfg.Compute.Pool != nil {
+		log.Info("registering pool compute provider")
+		slotStore, err := computepoolpostgres.New(pool, log)
+		if err != nil {
+			log.Fatal("Failed to initialize pool slot store", zap.Error(err))
+		}
+		slots := make([]computepool.Slot, 0, len(cfg.Compute.Pool.Slots))
+		for _, slot := range cfg.Compute.Pool.Slots {
+			slots = append(slots, computepool.Slot{Name: slot.Name, Address: slot.Address, Ports: slot.Ports, Labels: slot.Labels})
+		}
+		poolProvider, err := computepool.New(slots, slotStore, cfg.Compute.Pool.Defaults, log)
+		if err != nil {
+			log.Fatal("Failed to initialize pool provider", zap.Error(err))
+		}
+		if len(cfg.Compute.Pool.Defaults) > 0 {
+			if err := validateProviderDefaults("pool", poolProvider, cfg.Compute.Pool.Defaults); err != nil {
+				log.Fatal("Invalid pool compute defaults", zap.Error(err))
+			}
+		}
+		computeRegistry.Register(poolProvider)
+	}
+
+	// Initialize tenant repository
+	tenantRepo, err := postgres.New(pool, log)
+	if err != nil {
+		log.Fatal("Failed to initialize tenant repository", zap.Error(err))
+	}
+	projects, err := projectpostgres.New(pool, log)
+	if err != nil {
+		log.Fatal("Failed to initialize project store", zap.Error(err))
+	}
+	// Status changes go through the notifying repository so project webhooks fire
+	var tenants tenant.Repository = project.NewNotifyingRepository(tenantRepo, project.NewNotifier(projects, log))
+	var cache *tenantcache.Cache
+	if cfg.TenantCache.Enabled {
+		cache = tenantcache.New(tenants, cfg.TenantCache, log)
+		go cache.Listen(ctx, tenantRepo)
+		tenants = cache
+	}
+
+	// Every workflow runs in this process, through the worker's tenant service
+	defaultComputeProvider := cfg.Compute.DefaultProvider()
+	resolver := workflow.NewCachedComputeProviderResolver(nil, tenants, defaultComputeProvider, cfg.Workflow.Restate.WorkerComputeCacheTTL, log)
+	resolver.SetRules(resolution.New(cfg.ComputeResolution))
+	service := restate.NewTenantProvisioningService(computeRegistry, defaultComputeProvider, resolver, log)
+
+	resourceRegistry := resource.NewRegistry(log)
+	if cfg.DataPlane.Postgres != nil {
+		postgresDataPlane, err := dataplanepostgres.New(ctx, *cfg.DataPlane.Postgres, log)
+		if err != nil {
+			log.Fatal("Failed to initialize postgres data plane", zap.Error(err))
+		}
+		defer postgresDataPlane.Close()
+		if err := resourceRegistry.Register(postgresDataPlane); err != nil {
+			log.Fatal("Failed to register postgres resource provider", zap.Error(err))
+		}
+	}
+	if cfg.DataPlane.Bucket != nil {
+		bucketDataPlane, err := dataplaneobjectstore.New(ctx, *cfg.DataPlane.Bucket, log)
+		if err != nil {
+			log.Fatal("Failed to initialize bucket data plane", zap.Error(err))
+		}
+		if err := resourceRegistry.Register(bucketDataPlane); err != nil {
+			log.Fatal("Failed to register bucket resource provider", zap.Error(err))
+		}
+	}
+	service.SetResourceRegistry(resourceRegistry)
+	if len(cfg.Compute.Limits) > 0 {
+		service.SetConcurrencyLimiter(compute.NewLimiter(cfg.Compute.Limits))
+	}
+	if cfg.VulnerabilityScan.Enabled {
+		service.SetVulnerabilityScanner(vulnscan.New(cfg.VulnerabilityScan, log))
+	}
+	var endpointAuth *endpointauth.Generator
+	if cfg.EndpointAuth.Enabled {
+		endpointAuth = endpointauth.New(cfg.EndpointAuth)
+		service.SetEndpointAuth(endpointAuth)
+	}
+	service.SetOperationLease(workflow.LeaseConfig{
+		HeartbeatTimeout: cfg.Workflow.Restate.WorkerHeartbeatTimeout,
+		MaxDuration:      cfg.Workflow.Restate.WorkerOperationTimeout,
+	})
+	executionSteps, err := executionpostgres.New(pool, log)
+	if err != nil {
+		log.Fatal("Failed to initialize execution step store", zap.Error(err))
+	}
+	service.SetStepReporter(execution.NewReporter(executionSteps))
+
+	workflowProvider := inprocess.New(service.ExecuteAs, log)
+	workflowRegistry := workflow.NewRegistry(log)
+	if err := workflowRegistry.Register(workflowProvider); err != nil {
+		log.Fatal("Failed to register in-process workflow provider", zap.Error(err))
+	}
+	workflowClient := controller.NewWorkflowClient(workflow.New(workflowRegistry, log), log, cfg.Controller.WorkflowTriggerTimeout, workflowProvider.Name())
+	featureFlags, err := featureflag.NewRegistry(cfg.FeatureFlags)
+	if err != nil {
+		log.Fatal("Invalid feature flags", zap.Error(err))
+	}
+	workflowClient.SetFeatureFlags(featureFlags)
+
+	// Apply provider changes made at runtime through the admin API, such as reconfigured defaults
+	providerSettings, err := providerconfigpostgres.New(pool, log)
+	if err != nil {
+		log.Fatal("Failed to initialize provider settings store", zap.Error(err))
+	}
+	providerAdmin := providerconfig.NewManager(computeRegistry, workflowRegistry, providerSettings, log)
+	if err := providerAdmin.Apply(ctx); err != nil {
+		log.Fatal("Failed to apply provider settings", zap.Error(err))
+	}
+
+	reconciler := controller.NewReconciler(tenants, workflowClient, cfg.Controller, log)
+	reconciler.SetExecutionSteps(executionSteps)
+	if cfg.Controller.TriggerOutbox.Enabled {
+		reconciler.SetTriggerOutbox(tenantRepo)
+	}
+
+	srv := api.New(&cfg.HTTP, dbProvider, computeRegistry, defaultComputeProvider, tenants, workflowClient, log)
+	// A standalone controller runs in its own process and reports its readiness there
+	if !cfg.Controller.Standalone {
+		srv.SetController(reconciler)
+	}
+	srv.SetAPIKeys(cfg.Auth)
+	if cache != nil {
+		srv.SetTenantCache(cache)
+	}
+	srv.SetListCache(cfg.ListCache)
+	srv.SetProjects(projects)
+	srv.SetProviderAdmin(providerAdmin)
+	srv.SetComputeResolution(resolution.New(cfg.ComputeResolution))
+	srv.SetCostEstimator(cost.New(cfg.Cost))
+	srv.SetEmergency(cfg.Emergency)
+	srv.SetHistoryScrubber(tenantRepo)
+	srv.SetFeatureFlags(featureFlags)
+	srv.SetExecutions(executionSteps)
+	srv.SetLogLevels(logger.LevelsOf(log))
+	if cfg.Capture.Enabled {
+		recorder, err := capture.NewRecorder(cfg.Capture, log)
+		if err != nil {
+			log.Fatal("Failed to initialize request capture", zap.Error(err))
+		}
+		defer recorder.Close()
+		srv.SetCapture(recorder)
+	}
+	if endpointAuth != nil {
+		srv.SetEndpointAuth(endpointAuth)
+	}
+	operations, err := operationpostgres.New(pool, log)
+	if err != nil {
+		log.Fatal("Failed to initialize operation store", zap.Error(err))
+	}
+	srv.SetOperations(operations)
+	if cfg.Approvals.Enabled {
+		approvals, err := approvalpostgres.New(pool, log)
+		if err != nil {
+			log.Fatal("Failed to initialize approval store", zap.Error(err))
+		}
+		srv.SetApprovals(approvals, approval.NewPolicy(cfg.Approvals))
+	}
+	diagnostics := doctor.New(cfg.Doctor, log)
+	diagnostics.Register(doctor.DatabaseCheck(dbProvider))
+	if !cfg.Controller.Standalone {
+		diagnostics.Register(doctor.ControllerCheck(reconciler))
+	}
+	diagnostics.Register(
+		doctor.ComputeProvidersCheck(computeRegistry),
+		doctor.WorkflowProvidersCheck(workflowRegistry),
+		doctor.ComputeInventoryCheck(computeRegistry, defaultComputeProvider, tenants),
+		doctor.StuckTenantsCheck(tenants, cfg.Doctor.StuckThreshold),
+	)
+	srv.SetDoctor(diagnostics)
+
+	// Background controllers, started once the server is configured and stopped in reverse order
+	var controllers []interface{ Start() error }
+	var policy *imagepolicy.Policy
+	if cfg.ImagePolicy.Enabled() {
+		policy = imagepolicy.New(cfg.ImagePolicy, log)
+		srv.SetImagePolicy(policy)
+		if cfg.ImagePolicy.ScanInterval > 0 {
+			controllers = append(controllers, imagepolicy.NewScanner(policy, tenants, cfg.ImagePolicy.ScanInterval, log))
+		}
+	}
+	registries := imageupdate.NewRegistries(cfg.ImageUpdate.Registries)
+	srv.SetImageRegistries(registries)
+	if cfg.ImageUpdate.Enabled {
+		controllers = append(controllers, imageupdate.NewUpdater(tenants, registries, policy, cfg.ImageUpdate.Interval, log))
+	}
+	if cfg.Schedules.Enabled {
+		schedules, err := schedulepostgres.New(pool, log)
+		if err != nil {
+			log.Fatal("Failed to initialize schedule store", zap.Error(err))
+		}
+		srv.SetSchedules(schedules)
+		executor := schedule.NewComputeExecutor(computeRegistry, defaultComputeProvider, workflow.NewHookRunner(nil, log))
+		controllers = append(controllers, schedule.NewController(schedules, tenants, executor, cfg.Schedules, log))
+	}
+	if cfg.WarmPools.Enabled {
+		warmPools := warmpool.NewController(tenants, projects, cfg.WarmPools, log)
+		srv.SetWarmPools(warmPools)
+		controllers = append(controllers, warmPools)
+	}
+	if cfg.Uptime.Enabled {
+		checker := uptime.NewChecker(tenants, cfg.Uptime, log)
+		srv.SetUptime(checker)
+		controllers = append(controllers, checker)
+	}
+	if cfg.EgressMonitor.Enabled {
+		controllers = append(controllers, egress.NewMonitor(tenantRepo, computeRegistry, cfg.EgressMonitor, log))
+	}
+	if cfg.Observe.Enabled {
+		controllers = append(controllers, observe.NewRefresher(tenantRepo, computeRegistry, defaultComputeProvider, resolution.New(cfg.ComputeResolution), cfg.Observe, log))
+	}
+	if cfg.Retention.Enabled {
+		controllers = append(controllers, retention.NewScrubber(tenantRepo, providerSettings, cfg.Retention, log))
+	}
+	if cfg.Housekeeping.Enabled {
+		// Workflows run in process, so no compute callbacks are queued; only the trigger outbox grows
+		tables := housekeeping.Tables{Outbox: tenantRepo, OutboxMaxAttempts: cfg.Controller.TriggerOutbox.MaxAttempts}
+		controllers = append(controllers, housekeeping.NewCollector(tables, cfg.Housekeeping, log))
+	}
+
+	if cfg.Controller.Standalone {
+		log.Info("controller runs standalone, not starting the embedded controller")
+	} else if err := reconciler.Start(); err != nil {
+		log.Fatal("Failed to start controller", zap.Error(err))
+	}
+	for _, c := range controllers {
+		if err := c.Start(); err != nil {
+			log.Fatal("Failed to start background controller", zap.String("controller", fmt.Sprintf("%T", c)), zap.Error(err))
+		}
+		if s, ok := c.(stopper); ok {
+			defer s.Stop()
+		}
+	}
+
+	serveCtx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- srv.Start()
+	}()
+
+	select {
+	case err := <-serverErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("HTTP server failed", zap.Error(err))
+		}
+	case <-serveCtx.Done():
+	}
+
+	log.Info("shutting down")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer shutdownCancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("HTTP server shutdown failed", zap.Error(err))
+	}
+	if !cfg.Controller.Standalone {
+		if err := reconciler.Stop(); err != nil {
+			log.Error("Controller shutdown failed", zap.Error(err))
+		}
+	}
+	// Executions still running are cancelled; the controller's workflow_timeouts retry them after a restart
+	if err := workflowProvider.Stop(shutdownCtx); err != nil {
+		log.Warn("Workflow executions did not stop in time", zap.Error(err))
+	}
+	log.Info("landlord stopped")
+}
+
+func validateProviderDefaults(providerName string, provider compute.Provider, defaults map[string]interface{}) error {
+	if provider == nil {
+		return nil
+	}
+	if len(defaults) == 0 {
+		return fmt.Errorf("compute.%s must include default compute_config values", providerName)
+	}
+	raw, err := json.Marshal(defaults)
+	if err != nil {
+		return fmt.Errorf("marshal %s defaults: %w", providerName, err)
+	}
+	if err := provider.ValidateConfig(raw); err != nil {
+		return fmt.Errorf("invalid %s compute defaults: %w", providerName, err)
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 
 	cliapi "github.com/jaxxstorm/landlord/internal/cli"
 	"github.com/spf13/cobra"
@@ -21,7 +20,7 @@ func newArchiveCommand() *cobra.Command {
 				target = tenantName
 			}
 			if target == "" {
-				return fmt.Errorf("tenant-id or tenant-name is required")
+				return invalidInput("tenant-id or tenant-name is required")
 			}
 
 			client := cliapi.NewClient(cfg.APIURL)
@@ -30,6 +29,10 @@ func newArchiveCommand() *cobra.Command {
 				return err
 			}
 
+			if structuredOutput() {
+				return printOutput(cmd, tenant)
+			}
+
 			cmd.Println(successStyle.Render("Tenant archival requested"))
 			if tenant != nil {
 				cmd.Println(renderTenantDetails(*tenant))
@@ -40,6 +43,7 @@ func newArchiveCommand() *cobra.Command {
 
 	cmd.Flags().StringVar(&tenantID, "tenant-id", "", "Tenant UUID")
 	cmd.Flags().StringVar(&tenantName, "tenant-name", "", "Tenant name")
+	registerTenantCompletions(cmd)
 
 	return cmd
 }
@@ -3,11 +3,17 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	cliapi "github.com/jaxxstorm/landlord/internal/cli"
 )
 
 func newTestServer(t *testing.T, handler http.Handler) *httptest.Server {
@@ -47,6 +53,15 @@ func TestCLICommands(t *testing.T) {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusCreated)
 			_, _ = w.Write([]byte(`{"id":"123","name":"demo","status":"planning","desired_config":{"image":"nginx:alpine"},"compute_config":{"image":"nginx:alpine"}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/tenants:validate":
+			var payload map[string]any
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			w.Header().Set("Content-Type", "application/json")
+			if payload["name"] != "demo" {
+				_, _ = w.Write([]byte(`{"valid":false,"violations":[{"field":"name","check":"request","message":"name is required"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"valid":true,"violations":[]}`))
 		case r.Method == http.MethodGet && r.URL.Path == "/v1/compute/config":
 			if r.URL.Query().Get("provider") != "docker" {
 				w.WriteHeader(http.StatusBadRequest)
@@ -77,6 +92,9 @@ func TestCLICommands(t *testing.T) {
 		case r.Method == http.MethodDelete && r.URL.Path == "/v1/tenants/123":
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write([]byte(`{"id":"123","name":"demo","status":"deleting","desired_config":{"image":"nginx:alpine"},"compute_config":{"image":"nginx:alpine"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/admin/doctor":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"status":"fail","results":[{"check":"tenants/stuck","status":"fail","severity":"warning","message":"1 tenant(s) have not progressed in 30m0s","fix":"retry or archive them","details":["demo (123) provisioning for 1h0m0s"]},{"check":"database","status":"pass","severity":"info","message":"database is reachable"}],"checked_at":"2026-01-01T00:00:00Z"}`))
 		default:
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		}
@@ -110,6 +128,22 @@ func TestCLICommands(t *testing.T) {
 		t.Fatalf("expected create output, got %s", output)
 	}
 
+	output, err = run("lint", "-f", `{"name":"demo","compute_config":{"image":"nginx:alpine"}}`)
+	if err != nil {
+		t.Fatalf("lint command failed: %v", err)
+	}
+	if !strings.Contains(output, "Tenant spec is valid") {
+		t.Fatalf("expected lint output, got %s", output)
+	}
+
+	output, err = run("lint", "-f", `{"compute_config":{"image":"nginx:alpine"}}`)
+	if err == nil {
+		t.Fatalf("expected lint to fail for an invalid spec, got output %s", output)
+	}
+	if !strings.Contains(output, "name is required") {
+		t.Fatalf("expected lint violations in output, got %s", output)
+	}
+
 	output, err = run("compute", "--provider", "docker")
 	if err != nil {
 		t.Fatalf("compute command failed: %v", err)
@@ -165,4 +199,232 @@ func TestCLICommands(t *testing.T) {
 	if !strings.Contains(output, "Tenant deletion requested") {
 		t.Fatalf("expected delete output, got %s", output)
 	}
+
+	output, err = run("doctor", "--problems-only")
+	if err == nil {
+		t.Fatalf("expected doctor to fail when a check fails, got output %s", output)
+	}
+	if !strings.Contains(output, "demo (123) provisioning") || !strings.Contains(output, "retry or archive them") {
+		t.Fatalf("expected doctor problems with fixes in output, got %s", output)
+	}
+	if strings.Contains(output, "database is reachable") {
+		t.Fatalf("expected --problems-only to hide passing checks, got %s", output)
+	}
+}
+
+func TestCLIOutputFormatsAndExitCodes(t *testing.T) {
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/tenants":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"tenants":[{"id":"123","name":"demo","status":"ready","labels":{"team":"web"},"version":4,"desired_config":{"image":"nginx:alpine"},"compute_config":{"image":"nginx:alpine"}}],"total":1,"limit":50,"offset":0}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/tenants/123":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"123","name":"demo","status":"ready","desired_config":{"image":"nginx:alpine"},"compute_config":{"image":"nginx:alpine"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/tenants/00000000-0000-0000-0000-000000000000":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"type":"urn:landlord:problem:not-found","title":"Not found","status":404,"detail":"Tenant not found","error_code":"NOT_FOUND"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/tenants:validate":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"valid":false,"violations":[{"field":"name","check":"request","message":"name is required"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/compute/config":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusGatewayTimeout)
+			_, _ = w.Write([]byte(`{"error":"Request timed out"}`))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+
+	t.Setenv("LANDLORD_CLI_API_URL", server.URL)
+
+	// Errors go to stderr, so stdout holds only the command's output
+	run := func(args ...string) (string, error) {
+		cmd := newRootCommand()
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), err
+	}
+
+	stdout, err := run("list", "-o", "json")
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	var list struct {
+		Tenants []map[string]any `json:"tenants"`
+		Total   int              `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &list); err != nil {
+		t.Fatalf("expected JSON list on stdout, got %q: %v", stdout, err)
+	}
+	if list.Total != 1 || list.Tenants[0]["name"] != "demo" {
+		t.Fatalf("unexpected JSON list %+v", list)
+	}
+
+	stdout, err = run("get", "--tenant-name", "demo", "--output", "yaml")
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if !strings.Contains(stdout, "name: demo\n") || !strings.Contains(stdout, "desired_config:\n") {
+		t.Fatalf("expected YAML tenant with API field names, got %s", stdout)
+	}
+
+	stdout, err = run("list", "-o", "wide")
+	if err != nil {
+		t.Fatalf("wide list failed: %v", err)
+	}
+	if !strings.Contains(stdout, "team=web") || !strings.Contains(stdout, "Version") {
+		t.Fatalf("expected wide columns, got %s", stdout)
+	}
+
+	stdout, err = run("lint", "-o", "json", "-f", `{"compute_config":{"image":"nginx:alpine"}}`)
+	if code := exitCode(err); code != exitValidation {
+		t.Fatalf("expected lint to exit %d, got %d (%v)", exitValidation, code, err)
+	}
+	if !strings.Contains(stdout, `"valid": false`) {
+		t.Fatalf("expected the validation result on stdout, got %s", stdout)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want int
+	}{
+		{[]string{"get"}, exitValidation},
+		{[]string{"list", "--no-such-flag"}, exitValidation},
+		{[]string{"list", "-o", "xml"}, exitValidation},
+		{[]string{"get", "--tenant-name", "missing"}, exitNotFound},
+		{[]string{"get", "--tenant-id", "00000000-0000-0000-0000-000000000000"}, exitNotFound},
+		{[]string{"compute", "--provider", "docker"}, exitTimeout},
+		{[]string{"list"}, exitOK},
+	} {
+		_, err := run(tc.args...)
+		if code := exitCode(err); code != tc.want {
+			t.Errorf("%v: expected exit code %d, got %d (%v)", tc.args, tc.want, code, err)
+		}
+	}
+
+	_, err = run("get", "-o", "json", "--tenant-name", "missing")
+	var out errorOutput
+	if jsonErr := json.Unmarshal([]byte(renderError(err)), &out); jsonErr != nil {
+		t.Fatalf("expected a JSON error, got %q: %v", renderError(err), jsonErr)
+	}
+	if out.Error.ExitCode != exitNotFound || out.Error.Message != "tenant not found: missing" {
+		t.Fatalf("unexpected JSON error %+v", out)
+	}
+}
+
+func TestCLICompletionAndTUI(t *testing.T) {
+	var archived, retried bool
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/tenants":
+			_, _ = w.Write([]byte(`{"tenants":[{"id":"123","name":"demo","status":"ready"},{"id":"00000000-0000-0000-0000-000000000456","name":"web","status":"failed","status_message":"image pull failed"}],"total":2,"limit":50,"offset":0}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/tenants/00000000-0000-0000-0000-000000000456/retry":
+			retried = true
+			_, _ = w.Write([]byte(`{"id":"00000000-0000-0000-0000-000000000456","name":"web","status":"provisioning"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/tenants/00000000-0000-0000-0000-000000000456/archive":
+			archived = true
+			_, _ = w.Write([]byte(`{"id":"00000000-0000-0000-0000-000000000456","name":"web","status":"archiving"}`))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+
+	t.Setenv("LANDLORD_CLI_API_URL", server.URL)
+
+	complete := func(args ...string) string {
+		cmd := newRootCommand()
+		var stdout bytes.Buffer
+		cmd.SetOut(&stdout)
+		cmd.SetErr(io.Discard)
+		cmd.SetArgs(append([]string{"__complete"}, args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("complete %v: %v", args, err)
+		}
+		return stdout.String()
+	}
+
+	if got := complete("get", "--tenant-name", "w"); !strings.Contains(got, "web\tfailed\n") || strings.Contains(got, "demo") {
+		t.Fatalf("expected tenant names matching the prefix, got %q", got)
+	}
+	if got := complete("archive", "--tenant-id", ""); !strings.Contains(got, "123\tdemo (ready)\n") || !strings.Contains(got, "00000000-0000-0000-0000-000000000456\tweb (failed)\n") {
+		t.Fatalf("expected tenant ids described by name, got %q", got)
+	}
+	if got := complete("list", "--output", "y"); !strings.Contains(got, "yaml\n") {
+		t.Fatalf("expected output format completions, got %q", got)
+	}
+
+	if err := loadCLIConfig(newRootCommand()); err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	var model tea.Model = newTUIModel(cliapi.NewClient(cfg.APIURL), time.Second)
+	// run delivers a message, then the message each resulting command produces, except the
+	// scheduled refresh, which would wait for the tick
+	var run func(msg tea.Msg)
+	run = func(msg tea.Msg) {
+		var cmd tea.Cmd
+		model, cmd = model.Update(msg)
+		if cmd == nil {
+			return
+		}
+		if _, ok := msg.(tenantsMsg); ok {
+			return
+		}
+		run(cmd())
+	}
+	key := func(k string) tea.KeyMsg {
+		if k == "down" {
+			return tea.KeyMsg{Type: tea.KeyDown}
+		}
+		if k == "enter" {
+			return tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+	}
+
+	run(model.Init()())
+	view := model.View()
+	if !strings.Contains(view, "2 tenants") || !strings.Contains(view, "image pull failed") {
+		t.Fatalf("expected the tenant list, got %s", view)
+	}
+
+	run(key("down"))
+	run(key("enter"))
+	if view := model.View(); !strings.Contains(view, "> web") || !strings.Contains(view, "456") {
+		t.Fatalf("expected web selected with its details, got %s", view)
+	}
+
+	run(key("r"))
+	if !retried || !strings.Contains(model.View(), "retry requested for web") {
+		t.Fatalf("expected web to be retried, got %s", model.View())
+	}
+
+	run(key("a"))
+	if view := model.View(); archived || !strings.Contains(view, "Archive web? (y/n)") {
+		t.Fatalf("expected an archive confirmation, got %s", view)
+	}
+	run(key("n"))
+	if archived || !strings.Contains(model.View(), "archive cancelled") {
+		t.Fatalf("expected the archive to be cancelled, got %s", model.View())
+	}
+	run(key("a"))
+	run(key("y"))
+	if !archived || !strings.Contains(model.View(), "archiving") {
+		t.Fatalf("expected web to be archived, got %s", model.View())
+	}
+
+	// The cursor follows the selected tenant across refreshes
+	run(tenantsMsg{tenants: []models.TenantResponse{{ID: "00000000-0000-0000-0000-000000000456", Name: "web", Status: "archived"}, {ID: "123", Name: "demo", Status: "ready"}}})
+	if view := model.View(); !strings.Contains(view, "> web") {
+		t.Fatalf("expected the cursor to stay on web, got %s", view)
+	}
+
+	if _, cmd := model.Update(key("q")); cmd == nil || cmd() != tea.Quit() {
+		t.Fatalf("expected q to quit")
+	}
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	cliapi "github.com/jaxxstorm/landlord/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout bounds the tenant list behind a completion, so a slow or unreachable API
+// does not hang the shell
+const completionTimeout = 2 * time.Second
+
+// registerTenantCompletions completes a command's --tenant-name and --tenant-id flags with the
+// tenants the API knows about
+func registerTenantCompletions(cmd *cobra.Command) {
+	_ = cmd.RegisterFlagCompletionFunc("tenant-name", completeTenants(func(t models.TenantResponse) (string, string) {
+		return t.Name, t.Status
+	}))
+	_ = cmd.RegisterFlagCompletionFunc("tenant-id", completeTenants(func(t models.TenantResponse) (string, string) {
+		return t.ID, fmt.Sprintf("%s (%s)", t.Name, t.Status)
+	}))
+}
+
+// completeTenants returns a completion function that lists tenants and offers the value
+// returned by candidate for each, with its description
+func completeTenants(candidate func(models.TenantResponse) (string, string)) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// Completion skips the persistent pre-run, so the config is loaded here
+		if err := loadCLIConfig(cmd); err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+		list, err := cliapi.NewClient(cfg.APIURL).ListTenants(ctx, false)
+		if err != nil {
+			cobra.CompDebugln(fmt.Sprintf("list tenants: %v", err), false)
+			return nil, cobra.ShellCompDirectiveError
+		}
+
+		completions := make([]string, 0, len(list.Tenants))
+		for _, t := range list.Tenants {
+			value, description := candidate(t)
+			if value == "" || !strings.HasPrefix(value, toComplete) {
+				continue
+			}
+			completions = append(completions, cobra.CompletionWithDesc(value, description))
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
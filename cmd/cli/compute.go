@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 
 	cliapi "github.com/jaxxstorm/landlord/internal/cli"
 	"github.com/spf13/cobra"
@@ -16,7 +15,7 @@ func newComputeCommand() *cobra.Command {
 		Short: "Show compute config schema for a provider",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			if provider == "" {
-				return fmt.Errorf("--provider is required")
+				return invalidInput("--provider is required")
 			}
 			client := cliapi.NewClient(cfg.APIURL)
 			resp, err := client.GetComputeConfigDiscovery(context.Background(), provider)
@@ -24,6 +23,10 @@ func newComputeCommand() *cobra.Command {
 				return err
 			}
 
+			if structuredOutput() {
+				return printOutput(cmd, resp)
+			}
+
 			cmd.Println(successStyle.Render("Compute config discovery"))
 			cmd.Println(renderComputeConfigDiscovery(*resp))
 			return nil
@@ -31,7 +34,6 @@ func newComputeCommand() *cobra.Command {
 	}
 
 	cmd.Flags().StringVar(&provider, "provider", "", "Compute provider identifier")
-	_ = cmd.MarkFlagRequired("provider")
 
 	return cmd
 }
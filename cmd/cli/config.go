@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -11,6 +12,7 @@ import (
 type cliConfig struct {
 	APIURL     string
 	ConfigFile string
+	Output     string
 }
 
 var cfg cliConfig
@@ -25,6 +27,9 @@ func bindCLIFlags(cmd *cobra.Command) error {
 	if err := v.BindPFlag("api.url", cmd.PersistentFlags().Lookup("api-url")); err != nil {
 		return err
 	}
+	if err := v.BindPFlag("output", cmd.PersistentFlags().Lookup("output")); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -38,6 +43,7 @@ func loadCLIConfig(cmd *cobra.Command) error {
 	v.AutomaticEnv()
 
 	v.SetDefault("api.url", "http://localhost:8081")
+	v.SetDefault("output", outputTable)
 
 	configFile := v.GetString("config")
 	if configFile != "" {
@@ -58,11 +64,17 @@ func loadCLIConfig(cmd *cobra.Command) error {
 	cfg = cliConfig{
 		APIURL:     v.GetString("api.url"),
 		ConfigFile: v.GetString("config"),
+		Output:     strings.ToLower(v.GetString("output")),
 	}
 
 	if cfg.APIURL == "" {
 		return fmt.Errorf("api url is required")
 	}
+	if !slices.Contains(outputFormats, cfg.Output) {
+		output := cfg.Output
+		cfg.Output = outputTable
+		return invalidInput("invalid output format %q (must be one of %s)", output, strings.Join(outputFormats, ", "))
+	}
 
 	return nil
 }
@@ -63,3 +63,24 @@ func TestParseConfigInputFilePathJSON(t *testing.T) {
 		t.Fatalf("expected image to be parsed, got %v", parsed["image"])
 	}
 }
+
+func TestParseTenantSpec(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "tenant.yaml")
+	content := []byte("name: demo\ncompute_config:\n  image: nginx:latest\nlabels:\n  team: web\n")
+	if err := os.WriteFile(filePath, content, 0644); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+
+	req, err := parseTenantSpec(filePath)
+	if err != nil {
+		t.Fatalf("expected tenant spec to parse: %v", err)
+	}
+	if req.Name != "demo" || req.ComputeConfig["image"] != "nginx:latest" || req.Labels["team"] != "web" {
+		t.Fatalf("unexpected tenant spec: %+v", req)
+	}
+
+	if _, err := parseTenantSpec("name: demo\ncompute_confg:\n  image: nginx:latest\n"); err == nil {
+		t.Fatal("expected unknown field to be rejected")
+	}
+}
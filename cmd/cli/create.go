@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/jaxxstorm/landlord/internal/api/models"
 	cliapi "github.com/jaxxstorm/landlord/internal/cli"
@@ -18,10 +17,10 @@ func newCreateCommand() *cobra.Command {
 		Short: "Create a tenant",
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			if tenantName == "" {
-				return fmt.Errorf("tenant-name is required")
+				return invalidInput("tenant-name is required")
 			}
 			if config == "" {
-				return fmt.Errorf("config is required")
+				return invalidInput("config is required")
 			}
 
 			client := cliapi.NewClient(cfg.APIURL)
@@ -30,7 +29,7 @@ func newCreateCommand() *cobra.Command {
 			}
 			parsed, err := parseConfigInput(config)
 			if err != nil {
-				return err
+				return &inputError{err: err}
 			}
 			req.ComputeConfig = parsed
 			tenant, err := client.CreateTenant(context.Background(), req)
@@ -38,6 +37,10 @@ func newCreateCommand() *cobra.Command {
 				return err
 			}
 
+			if structuredOutput() {
+				return printOutput(cmd, tenant)
+			}
+
 			cmd.Println(successStyle.Render("Tenant created"))
 			cmd.Println(renderTenantDetails(*tenant))
 			return nil
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	cliapi "github.com/jaxxstorm/landlord/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+func newDoctorCommand() *cobra.Command {
+	var problemsOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the control plane and report problems with suggested fixes",
+		Long: "Runs the server's connectivity and consistency checks: database and migrations, controller, " +
+			"compute and workflow providers, compute resources against tenant records, and stuck tenants. " +
+			"Problems are listed first, most serious first. Exits non-zero when any check fails. Requires an admin API key.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client := cliapi.NewClient(cfg.APIURL)
+			report, err := client.Doctor(context.Background())
+			if err != nil {
+				return err
+			}
+
+			if structuredOutput() {
+				if err := printOutput(cmd, report); err != nil {
+					return err
+				}
+			} else {
+				cmd.Println(headerStyle.Render("Landlord doctor"))
+				cmd.Println(renderDoctorReport(*report, problemsOnly))
+			}
+			if failed := countDoctorResults(*report, "fail"); failed > 0 {
+				return fmt.Errorf("doctor found %d failed check(s)", failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&problemsOnly, "problems-only", false, "Hide passing and skipped checks")
+
+	return cmd
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+
+	cliapi "github.com/jaxxstorm/landlord/internal/cli"
+)
+
+// Exit codes. They are part of the CLI's interface, so scripts can branch on them; do not
+// renumber them.
+const (
+	exitOK         = 0
+	exitError      = 1
+	exitNotFound   = 2
+	exitValidation = 3
+	exitTimeout    = 4
+)
+
+// inputError is a problem with a command's flags or input files
+type inputError struct {
+	err error
+}
+
+func (e *inputError) Error() string { return e.err.Error() }
+func (e *inputError) Unwrap() error { return e.err }
+
+// invalidInput returns an error that exits with the validation exit code
+func invalidInput(format string, args ...any) error {
+	return &inputError{err: fmt.Errorf(format, args...)}
+}
+
+// exitCode maps a command's error to the process exit code
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	var input *inputError
+	if errors.As(err, &input) {
+		return exitValidation
+	}
+	if errors.Is(err, cliapi.ErrTenantNotFound) {
+		return exitNotFound
+	}
+	var apiErr *cliapi.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusNotFound:
+			return exitNotFound
+		case http.StatusBadRequest, http.StatusUnprocessableEntity:
+			return exitValidation
+		case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+			return exitTimeout
+		}
+		return exitError
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return exitTimeout
+	}
+	return exitError
+}
+
+// errorOutput is a failed command's error in the json and yaml output formats
+type errorOutput struct {
+	Error errorDetails `json:"error"`
+}
+
+type errorDetails struct {
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+
+	// Status and ErrorCode are the API's HTTP status and error_code, when the API reported the error
+	Status    int    `json:"status,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+// renderError renders a failed command's error in the configured output format
+func renderError(err error) string {
+	if !structuredOutput() {
+		return errorStyle.Render(err.Error())
+	}
+
+	out := errorOutput{Error: errorDetails{Message: err.Error(), ExitCode: exitCode(err)}}
+	var apiErr *cliapi.APIError
+	if errors.As(err, &apiErr) {
+		out.Error.Status = apiErr.StatusCode
+		out.Error.ErrorCode = apiErr.Code
+	}
+	rendered, marshalErr := marshalOutput(out)
+	if marshalErr != nil {
+		return err.Error()
+	}
+	return rendered
+}
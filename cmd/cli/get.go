@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 
 	cliapi "github.com/jaxxstorm/landlord/internal/cli"
 	"github.com/spf13/cobra"
@@ -21,7 +20,7 @@ func newGetCommand() *cobra.Command {
 				target = tenantName
 			}
 			if target == "" {
-				return fmt.Errorf("tenant-id or tenant-name is required")
+				return invalidInput("tenant-id or tenant-name is required")
 			}
 
 			client := cliapi.NewClient(cfg.APIURL)
@@ -30,6 +29,10 @@ func newGetCommand() *cobra.Command {
 				return err
 			}
 
+			if structuredOutput() {
+				return printOutput(cmd, tenant)
+			}
+
 			cmd.Println(headerStyle.Render("Tenant details"))
 			cmd.Println(renderTenantDetails(*tenant))
 			return nil
@@ -38,6 +41,7 @@ func newGetCommand() *cobra.Command {
 
 	cmd.Flags().StringVar(&tenantID, "tenant-id", "", "Tenant UUID")
 	cmd.Flags().StringVar(&tenantName, "tenant-name", "", "Tenant name")
+	registerTenantCompletions(cmd)
 
 	return cmd
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	cliapi "github.com/jaxxstorm/landlord/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+func newLintCommand() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Validate a tenant spec without creating it",
+		Long:  "Runs every create-time check (naming, provider, compute_config schema, provider validation and capacity) against a tenant spec and reports all violations. Exits non-zero when the spec is invalid.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if file == "" {
+				return invalidInput("file is required")
+			}
+
+			req, err := parseTenantSpec(file)
+			if err != nil {
+				return &inputError{err: err}
+			}
+
+			client := cliapi.NewClient(cfg.APIURL)
+			resp, err := client.ValidateTenant(context.Background(), *req)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case structuredOutput():
+				if err := printOutput(cmd, resp); err != nil {
+					return err
+				}
+			case resp.Valid:
+				cmd.Println(successStyle.Render("Tenant spec is valid"))
+			default:
+				cmd.Println(errorStyle.Render("Tenant spec is invalid"))
+				cmd.Println(renderViolations(resp.Violations))
+			}
+			if !resp.Valid {
+				return invalidInput("%d violation(s) found", len(resp.Violations))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Tenant spec (name, compute_config, labels, annotations) as a YAML or JSON file")
+
+	return cmd
+}
+
+// parseTenantSpec reads a tenant spec file; unknown top-level fields are rejected so typos fail the lint
+func parseTenantSpec(value string) (*models.CreateTenantRequest, error) {
+	parsed, err := parseConfigInput(value)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := json.Marshal(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("encode tenant spec: %w", err)
+	}
+
+	var req models.CreateTenantRequest
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return nil, fmt.Errorf("parse tenant spec: %w", err)
+	}
+	return &req, nil
+}
+
+func renderViolations(violations []models.Violation) string {
+	headers := []string{"Field", "Check", "Message"}
+	rows := make([][]string, 0, len(violations))
+	for _, v := range violations {
+		rows = append(rows, []string{v.Field, v.Check, v.Message})
+	}
+
+	widths := columnWidths(headers, rows)
+	lines := []string{headerStyle.Render(formatRow(headers, widths))}
+	for _, row := range rows {
+		lines = append(lines, formatRow(row, widths))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -20,7 +20,14 @@ func newListCommand() *cobra.Command {
 				return err
 			}
 
-			cmd.Println(renderTenantList(list.Tenants))
+			switch {
+			case structuredOutput():
+				return printOutput(cmd, list)
+			case cfg.Output == outputWide:
+				cmd.Println(renderTenantListWide(list.Tenants))
+			default:
+				cmd.Println(renderTenantList(list.Tenants))
+			}
 			return nil
 		},
 	}
@@ -18,9 +18,9 @@ func main() {
 			if err == nil {
 				return
 			}
-			fmt.Fprintln(w, errorStyle.Render(err.Error()))
+			fmt.Fprintln(w, renderError(err))
 		}),
 	); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
@@ -4,13 +4,64 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
+// Output formats. json and yaml write the API's response for the command, with the API's field
+// names, so scripts can rely on them; table and wide are for people and may change.
+const (
+	outputTable = "table"
+	outputWide  = "wide"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputFormats = []string{outputTable, outputWide, outputJSON, outputYAML}
+
+// structuredOutput reports whether the configured output format is machine-readable
+func structuredOutput() bool {
+	return cfg.Output == outputJSON || cfg.Output == outputYAML
+}
+
+// printOutput writes v to stdout in the configured machine-readable format
+func printOutput(cmd *cobra.Command, v any) error {
+	rendered, err := marshalOutput(v)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), rendered)
+	return nil
+}
+
+// marshalOutput encodes v as JSON or YAML. YAML is converted from the JSON encoding, so both use
+// the same field names.
+func marshalOutput(v any) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode output: %w", err)
+	}
+	if cfg.Output != outputYAML {
+		return string(data), nil
+	}
+
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return "", fmt.Errorf("encode output: %w", err)
+	}
+	out, err := yaml.Marshal(generic)
+	if err != nil {
+		return "", fmt.Errorf("encode output: %w", err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
 var (
 	headerStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
 	successStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#04B575"))
@@ -44,6 +95,43 @@ func renderTenantList(tenants []models.TenantResponse) string {
 	return strings.Join(lines, "\n")
 }
 
+// renderTenantListWide adds each tenant's version, labels and last update to the table
+func renderTenantListWide(tenants []models.TenantResponse) string {
+	headers := []string{"ID", "Name", "Status", "Workflow", "Retries", "Version", "Labels", "Updated"}
+	rows := make([][]string, 0, len(tenants))
+
+	for _, t := range tenants {
+		workflow := ""
+		if t.WorkflowSubState != nil {
+			workflow = *t.WorkflowSubState
+		}
+		retries := ""
+		if t.WorkflowRetryCount != nil {
+			retries = fmt.Sprintf("%d", *t.WorkflowRetryCount)
+		}
+		labels := make([]string, 0, len(t.Labels))
+		for key, value := range t.Labels {
+			labels = append(labels, key+"="+value)
+		}
+		sort.Strings(labels)
+		updated := ""
+		if !t.UpdatedAt.IsZero() {
+			updated = t.UpdatedAt.Format(time.RFC3339)
+		}
+		rows = append(rows, []string{t.ID, t.Name, formatStatus(t.Status), workflow, retries,
+			fmt.Sprintf("%d", t.Version), strings.Join(labels, ","), updated})
+	}
+
+	widths := columnWidths(headers, rows)
+	var lines []string
+	lines = append(lines, headerStyle.Render(formatRow(headers, widths)))
+	for _, row := range rows {
+		lines = append(lines, formatRow(row, widths))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 func renderTenantDetails(tenant models.TenantResponse) string {
 	lines := []string{
 		fmt.Sprintf("%s %s", labelStyle.Render("ID:"), tenant.ID),
@@ -126,6 +214,56 @@ func renderComputeConfigDiscovery(resp models.ComputeConfigDiscoveryResponse) st
 	return strings.Join(lines, "\n")
 }
 
+var warnStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#F5A623"))
+
+func renderDoctorReport(report models.DoctorReportResponse, problemsOnly bool) string {
+	var lines []string
+	for _, result := range report.Results {
+		if problemsOnly && (result.Status == "pass" || result.Status == "skip") {
+			continue
+		}
+
+		marker := strings.ToUpper(result.Status)
+		switch result.Status {
+		case "pass":
+			marker = successStyle.Render(marker)
+		case "fail":
+			marker = errorStyle.Render(marker)
+		case "warn":
+			marker = warnStyle.Render(marker)
+		}
+		line := fmt.Sprintf("%s %s %s", marker, labelStyle.Render(result.Check+":"), result.Message)
+		if result.Status == "fail" || result.Status == "warn" {
+			line = fmt.Sprintf("%s [%s]", line, result.Severity)
+		}
+		lines = append(lines, line)
+
+		for _, detail := range result.Details {
+			lines = append(lines, "    - "+detail)
+		}
+		if result.Fix != "" {
+			lines = append(lines, fmt.Sprintf("    %s %s", labelStyle.Render("Fix:"), result.Fix))
+		}
+	}
+
+	summary := fmt.Sprintf("%d failed, %d warning(s), %d passed, %d skipped",
+		countDoctorResults(report, "fail"), countDoctorResults(report, "warn"),
+		countDoctorResults(report, "pass"), countDoctorResults(report, "skip"))
+	lines = append(lines, "", labelStyle.Render(summary))
+
+	return strings.Join(lines, "\n")
+}
+
+func countDoctorResults(report models.DoctorReportResponse, status string) int {
+	count := 0
+	for _, result := range report.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
 func formatStatus(status string) string {
 	switch status {
 	case "ready":
@@ -136,6 +274,8 @@ func formatStatus(status string) string {
 		return lipgloss.NewStyle().Foreground(lipgloss.Color("#F5A623")).Render(status)
 	case "archiving":
 		return lipgloss.NewStyle().Foreground(lipgloss.Color("#F5A623")).Render(status)
+	case "pending_approval":
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("#F5A623")).Render(status)
 	default:
 		return status
 	}
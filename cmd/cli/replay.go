@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/jaxxstorm/landlord/internal/capture"
+)
+
+func newReplayCommand() *cobra.Command {
+	var (
+		target         string
+		apiKey         string
+		delay          time.Duration
+		stopOnMismatch bool
+		dryRun         bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "replay DIR",
+		Short: "Re-issue captured API requests against a test instance",
+		Long: "Reads the request captures in DIR, written by a server with capture.enabled, and re-issues them in " +
+			"the order they were made against --target. IDs the test instance assigns replace the recorded IDs in " +
+			"later requests. Each response's status is compared with the recorded one; exits non-zero when any " +
+			"differs or fails. --target is required so a capture is never replayed against the configured API by accident.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if target == "" && !dryRun {
+				return invalidInput("--target is required")
+			}
+			if delay < 0 {
+				return invalidInput("--delay must not be negative")
+			}
+			records, err := capture.ReadDir(args[0])
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			replayer := capture.NewReplayer(capture.ReplayOptions{
+				BaseURL:        target,
+				APIKey:         apiKey,
+				Delay:          delay,
+				StopOnMismatch: stopOnMismatch,
+				DryRun:         dryRun,
+			})
+			report := func(result capture.ReplayResult) {
+				if structuredOutput() {
+					return
+				}
+				cmd.Println(renderReplayResult(result))
+			}
+			if !structuredOutput() {
+				cmd.Println(headerStyle.Render(fmt.Sprintf("Replaying %d request(s)", len(records))))
+			}
+			results, replayErr := replayer.Replay(ctx, records, report)
+
+			summary := capture.Summarize(results)
+			if structuredOutput() {
+				if err := printOutput(cmd, map[string]any{
+					"results":    results,
+					"matched":    summary.Matched,
+					"mismatched": summary.Mismatched,
+					"failed":     summary.Failed,
+					"skipped":    summary.Skipped,
+				}); err != nil {
+					return err
+				}
+			} else {
+				cmd.Printf("\n%d matched, %d mismatched, %d failed, %d skipped\n",
+					summary.Matched, summary.Mismatched, summary.Failed, summary.Skipped)
+			}
+			if replayErr != nil {
+				return replayErr
+			}
+			if summary.Mismatched > 0 || summary.Failed > 0 {
+				return fmt.Errorf("%d request(s) did not match the capture", summary.Mismatched+summary.Failed)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&target, "target", "", "URL of the test instance to replay against")
+	cmd.Flags().StringVar(&apiKey, "api-key", "", "API key for the test instance, sent as a bearer token")
+	cmd.Flags().DurationVar(&delay, "delay", 0, "Time to wait between requests, such as 2s, giving the reconciler time to act")
+	cmd.Flags().BoolVar(&stopOnMismatch, "stop-on-mismatch", false, "Stop at the first response whose status differs from the capture")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the requests without sending them")
+
+	return cmd
+}
+
+func renderReplayResult(result capture.ReplayResult) string {
+	line := fmt.Sprintf("%4d %-6s %s", result.Record.Seq, result.Method, result.Path)
+	switch {
+	case result.Skipped != "":
+		return fmt.Sprintf("%s  %s", line, warnStyle.Render("skipped: "+result.Skipped))
+	case result.Error != "":
+		return fmt.Sprintf("%s  %s", line, errorStyle.Render("error: "+result.Error))
+	case result.Matched():
+		return fmt.Sprintf("%s  %s", line, successStyle.Render(fmt.Sprintf("%d", result.Status)))
+	default:
+		return fmt.Sprintf("%s  %s", line, errorStyle.Render(fmt.Sprintf("%d, recorded %d", result.Status, result.RecordedStatus)))
+	}
+}
@@ -21,18 +21,28 @@ func newRootCommand() *cobra.Command {
 
 	cmd.PersistentFlags().String("config", "", "Config file path")
 	cmd.PersistentFlags().String("api-url", "http://localhost:8081", "Landlord API base URL (versioned paths are appended if missing)")
+	cmd.PersistentFlags().StringP("output", "o", outputTable, "Output format: table, wide, json or yaml")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(outputFormats, cobra.ShellCompDirectiveNoFileComp))
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &inputError{err: err}
+	})
 
 	if err := bindCLIFlags(cmd); err != nil {
 		cmd.PrintErrln(fmt.Sprintf("failed to bind flags: %v", err))
 	}
 
 	cmd.AddCommand(newCreateCommand())
+	cmd.AddCommand(newLintCommand())
 	cmd.AddCommand(newComputeCommand())
 	cmd.AddCommand(newListCommand())
 	cmd.AddCommand(newGetCommand())
 	cmd.AddCommand(newSetCommand())
 	cmd.AddCommand(newArchiveCommand())
 	cmd.AddCommand(newDeleteCommand())
+	cmd.AddCommand(newDoctorCommand())
+	cmd.AddCommand(newSupportBundleCommand())
+	cmd.AddCommand(newReplayCommand())
+	cmd.AddCommand(newTUICommand())
 
 	return cmd
 }
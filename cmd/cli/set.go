@@ -31,17 +31,17 @@ func newSetCommand() *cobra.Command {
 				target = tenantName
 			}
 			if target == "" {
-				return fmt.Errorf("tenant-id or tenant-name is required")
+				return invalidInput("tenant-id or tenant-name is required")
 			}
 			if config == "" {
-				return fmt.Errorf("config is required")
+				return invalidInput("config is required")
 			}
 
 			req := models.UpdateTenantRequest{}
 			if config != "" {
 				parsed, err := parseConfigInput(config)
 				if err != nil {
-					return err
+					return &inputError{err: err}
 				}
 				req.ComputeConfig = parsed
 			}
@@ -57,6 +57,10 @@ func newSetCommand() *cobra.Command {
 				return err
 			}
 
+			if structuredOutput() {
+				return printOutput(cmd, tenant)
+			}
+
 			cmd.Println(successStyle.Render("Tenant updated"))
 			cmd.Println(renderTenantDetails(*tenant))
 			return nil
@@ -67,6 +71,7 @@ func newSetCommand() *cobra.Command {
 	cmd.Flags().StringVar(&tenantName, "tenant-name", "", "Tenant name")
 	cmd.Flags().StringVar(&config, "config", "", "Compute config JSON or path to JSON file")
 	cmd.Flags().BoolVar(&usePatch, "patch", false, "Use PATCH instead of PUT")
+	registerTenantCompletions(cmd)
 
 	return cmd
 }
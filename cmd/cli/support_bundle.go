@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	cliapi "github.com/jaxxstorm/landlord/internal/cli"
+	"github.com/jaxxstorm/landlord/internal/supportbundle"
+	"github.com/jaxxstorm/landlord/internal/version"
+	"github.com/spf13/cobra"
+)
+
+func newSupportBundleCommand() *cobra.Command {
+	var tenant string
+	var recipientPath string
+	var signingKeyPath string
+	var outPath string
+	var opts supportbundle.Options
+
+	cmd := &cobra.Command{
+		Use:   "support-bundle",
+		Short: "Export an encrypted, signed support bundle for a tenant",
+		Long: "Collects a tenant's record, state history, recent executions, compute provider status and the\n" +
+			"landlord log lines that mention it, redacts secrets, encrypts the archive to the support team's\n" +
+			"key and signs it with yours.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if tenant == "" {
+				return invalidInput("--tenant is required")
+			}
+			if recipientPath == "" {
+				return invalidInput("--recipient is required")
+			}
+			if signingKeyPath == "" {
+				return invalidInput("--signing-key is required")
+			}
+
+			recipientPEM, err := os.ReadFile(recipientPath)
+			if err != nil {
+				return invalidInput("read recipient key: %v", err)
+			}
+			recipient, err := supportbundle.ParseRecipientPublicKey(recipientPEM)
+			if err != nil {
+				return invalidInput("recipient key %s: %v", recipientPath, err)
+			}
+			signingPEM, err := os.ReadFile(signingKeyPath)
+			if err != nil {
+				return invalidInput("read signing key: %v", err)
+			}
+			signer, err := supportbundle.ParseSigningPrivateKey(signingPEM)
+			if err != nil {
+				return invalidInput("signing key %s: %v", signingKeyPath, err)
+			}
+
+			now := time.Now()
+			opts.CLIVersion = version.Version
+			client := cliapi.NewClient(cfg.APIURL)
+			bundle, err := supportbundle.Collect(context.Background(), client, tenant, opts, now)
+			if err != nil {
+				return err
+			}
+			archive, err := bundle.Archive()
+			if err != nil {
+				return err
+			}
+			sealed, err := supportbundle.Seal(archive, recipient, signer)
+			if err != nil {
+				return err
+			}
+
+			if outPath == "" {
+				outPath = fmt.Sprintf("landlord-support-%s-%s.bundle", bundle.Manifest.TenantName, now.UTC().Format("20060102T150405Z"))
+			}
+			if err := os.WriteFile(outPath, sealed, 0o600); err != nil {
+				return fmt.Errorf("write bundle: %w", err)
+			}
+
+			if structuredOutput() {
+				return printOutput(cmd, supportBundleResult{Path: outPath, Manifest: bundle.Manifest})
+			}
+
+			cmd.Println(successStyle.Render("Support bundle written"))
+			cmd.Println(renderSupportBundleManifest(outPath, bundle.Manifest))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&tenant, "tenant", "", "Tenant UUID or name")
+	cmd.Flags().StringVar(&recipientPath, "recipient", "", "PEM file with the support team's public key")
+	cmd.Flags().StringVar(&signingKeyPath, "signing-key", "", "PEM file with your private signing key")
+	cmd.Flags().StringVar(&outPath, "out", "", "Bundle file to write (default landlord-support-<tenant>-<time>.bundle)")
+	cmd.Flags().StringSliceVar(&opts.LogFiles, "log-file", nil, "Landlord log file to collect the tenant's lines from (repeatable)")
+	cmd.Flags().IntVar(&opts.HistoryLimit, "history-limit", supportbundle.DefaultHistoryLimit, "Most recent state transitions to include")
+	cmd.Flags().IntVar(&opts.Executions, "executions", supportbundle.DefaultExecutions, "Most recent workflow and compute executions to include")
+	cmd.Flags().IntVar(&opts.LogLines, "log-lines", supportbundle.DefaultLogLines, "Most recent matching lines to include from each log file")
+	_ = cmd.RegisterFlagCompletionFunc("tenant", completeTenants(func(t models.TenantResponse) (string, string) {
+		return t.ID, fmt.Sprintf("%s (%s)", t.Name, t.Status)
+	}))
+
+	cmd.AddCommand(newSupportBundleKeygenCommand())
+	cmd.AddCommand(newSupportBundleOpenCommand())
+
+	return cmd
+}
+
+func newSupportBundleKeygenCommand() *cobra.Command {
+	var keyType string
+	var outPrefix string
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate a support bundle key pair",
+		Long: "Generates a recipient key pair, which the support team keeps to open bundles, or a signing key\n" +
+			"pair, which operators sign bundles with. Writes <out>.key (mode 0600) and <out>.pub.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if outPrefix == "" {
+				return invalidInput("--out is required")
+			}
+
+			var private, public interface{}
+			switch keyType {
+			case "recipient":
+				key, err := supportbundle.GenerateRecipientKey()
+				if err != nil {
+					return err
+				}
+				private, public = key, key.PublicKey()
+			case "signing":
+				key, err := supportbundle.GenerateSigningKey()
+				if err != nil {
+					return err
+				}
+				private, public = key, key.Public()
+			default:
+				return invalidInput("--type must be recipient or signing")
+			}
+
+			privatePEM, err := supportbundle.MarshalPrivateKey(private)
+			if err != nil {
+				return err
+			}
+			publicPEM, err := supportbundle.MarshalPublicKey(public)
+			if err != nil {
+				return err
+			}
+			keyPath, pubPath := outPrefix+".key", outPrefix+".pub"
+			if _, err := os.Stat(keyPath); err == nil {
+				return invalidInput("%s already exists", keyPath)
+			}
+			if err := os.WriteFile(keyPath, privatePEM, 0o600); err != nil {
+				return fmt.Errorf("write private key: %w", err)
+			}
+			if err := os.WriteFile(pubPath, publicPEM, 0o644); err != nil {
+				return fmt.Errorf("write public key: %w", err)
+			}
+
+			cmd.Println(successStyle.Render(fmt.Sprintf("Generated %s key pair", keyType)))
+			cmd.Printf("Private key: %s\nPublic key:  %s\n", keyPath, pubPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&keyType, "type", "", "Key pair to generate: recipient or signing")
+	cmd.Flags().StringVar(&outPrefix, "out", "", "Path prefix for the .key and .pub files")
+	_ = cmd.RegisterFlagCompletionFunc("type", cobra.FixedCompletions([]string{"recipient", "signing"}, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+func newSupportBundleOpenCommand() *cobra.Command {
+	var keyPath string
+	var signerPath string
+	var outPath string
+
+	cmd := &cobra.Command{
+		Use:   "open BUNDLE",
+		Short: "Verify and decrypt a support bundle",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if keyPath == "" {
+				return invalidInput("--key is required")
+			}
+			if signerPath == "" {
+				return invalidInput("--signer is required")
+			}
+			if outPath == "" {
+				outPath = strings.TrimSuffix(args[0], ".bundle") + ".tar.gz"
+			}
+
+			keyPEM, err := os.ReadFile(keyPath)
+			if err != nil {
+				return invalidInput("read key: %v", err)
+			}
+			key, err := supportbundle.ParseRecipientPrivateKey(keyPEM)
+			if err != nil {
+				return invalidInput("key %s: %v", keyPath, err)
+			}
+			signerPEM, err := os.ReadFile(signerPath)
+			if err != nil {
+				return invalidInput("read signer key: %v", err)
+			}
+			signer, err := supportbundle.ParseSigningPublicKey(signerPEM)
+			if err != nil {
+				return invalidInput("signer key %s: %v", signerPath, err)
+			}
+
+			sealed, err := os.ReadFile(args[0])
+			if err != nil {
+				return invalidInput("read bundle: %v", err)
+			}
+			archive, err := supportbundle.Open(sealed, key, signer)
+			if errors.Is(err, supportbundle.ErrUntrustedSigner) {
+				return fmt.Errorf("%w: %s", err, signerPath)
+			}
+			if err != nil {
+				return err
+			}
+			bundle, err := supportbundle.ReadArchive(archive)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(outPath, archive, 0o600); err != nil {
+				return fmt.Errorf("write archive: %w", err)
+			}
+
+			if structuredOutput() {
+				return printOutput(cmd, supportBundleResult{Path: outPath, Manifest: bundle.Manifest})
+			}
+
+			cmd.Println(successStyle.Render("Support bundle verified"))
+			cmd.Println(renderSupportBundleManifest(outPath, bundle.Manifest))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&keyPath, "key", "", "PEM file with the recipient private key")
+	cmd.Flags().StringVar(&signerPath, "signer", "", "PEM file with the public key the bundle must be signed with")
+	cmd.Flags().StringVar(&outPath, "out", "", "Archive file to write (default BUNDLE with a .tar.gz extension)")
+
+	return cmd
+}
+
+// supportBundleResult is the structured output of support-bundle and support-bundle open
+type supportBundleResult struct {
+	Path     string                 `json:"path"`
+	Manifest supportbundle.Manifest `json:"manifest"`
+}
+
+func renderSupportBundleManifest(path string, manifest supportbundle.Manifest) string {
+	lines := []string{
+		fmt.Sprintf("File: %s", path),
+		fmt.Sprintf("Tenant: %s (%s)", manifest.TenantName, manifest.TenantID),
+		fmt.Sprintf("Created: %s", manifest.CreatedAt.Format(time.RFC3339)),
+		fmt.Sprintf("Redacted values: %d", manifest.Redactions),
+		headerStyle.Render("Contents"),
+	}
+	for _, file := range manifest.Files {
+		lines = append(lines, fmt.Sprintf("  %s (%d bytes)", file.Name, file.Size))
+	}
+	if len(manifest.Errors) > 0 {
+		lines = append(lines, warnStyle.Render("Not collected"))
+		names := make([]string, 0, len(manifest.Errors))
+		for name := range manifest.Errors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			lines = append(lines, fmt.Sprintf("  %s: %s", name, manifest.Errors[name]))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
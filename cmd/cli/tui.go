@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	cliapi "github.com/jaxxstorm/landlord/internal/cli"
+	"github.com/spf13/cobra"
+)
+
+// tuiRequestTimeout bounds each API call the TUI makes, so a slow API leaves the screen
+// responsive
+const tuiRequestTimeout = 10 * time.Second
+
+var (
+	cursorStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#7D56F4"))
+	helpStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#767676"))
+)
+
+func newTUICommand() *cobra.Command {
+	var refresh time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Browse and manage tenants interactively",
+		Long:  "Lists tenants with their live status and lets you inspect, retry and archive them.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if refresh <= 0 {
+				return invalidInput("refresh must be positive")
+			}
+
+			model := newTUIModel(cliapi.NewClient(cfg.APIURL), refresh)
+			program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithInput(cmd.InOrStdin()), tea.WithOutput(cmd.OutOrStdout()))
+			final, err := program.Run()
+			if err != nil {
+				return fmt.Errorf("run tui: %w", err)
+			}
+			if m, ok := final.(tuiModel); ok && m.err != nil && m.tenants == nil {
+				return m.err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&refresh, "refresh", 2*time.Second, "How often to refresh tenant status")
+
+	return cmd
+}
+
+// tuiModel is the state of the tenant TUI
+type tuiModel struct {
+	client  *cliapi.Client
+	refresh time.Duration
+
+	tenants []models.TenantResponse
+	// selected is the ID of the tenant under the cursor, so the cursor follows it across refreshes
+	selected string
+	cursor   int
+	details  bool
+
+	// confirmArchive is set while the TUI waits for the archive to be confirmed
+	confirmArchive bool
+
+	message string
+	err     error
+}
+
+// tenantsMsg is the result of listing tenants
+type tenantsMsg struct {
+	tenants []models.TenantResponse
+	err     error
+}
+
+// actionMsg is the result of retrying or archiving a tenant
+type actionMsg struct {
+	action string
+	tenant *models.TenantResponse
+	err    error
+}
+
+// refreshMsg asks the TUI to list tenants again
+type refreshMsg time.Time
+
+func newTUIModel(client *cliapi.Client, refresh time.Duration) tuiModel {
+	return tuiModel{client: client, refresh: refresh}
+}
+
+func (m tuiModel) Init() tea.Cmd {
+	return m.listTenants()
+}
+
+func (m tuiModel) listTenants() tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), tuiRequestTimeout)
+		defer cancel()
+		list, err := client.ListTenants(ctx, false)
+		if err != nil {
+			return tenantsMsg{err: err}
+		}
+		return tenantsMsg{tenants: list.Tenants}
+	}
+}
+
+func (m tuiModel) scheduleRefresh() tea.Cmd {
+	return tea.Tick(m.refresh, func(t time.Time) tea.Msg {
+		return refreshMsg(t)
+	})
+}
+
+// tenantAction runs a retry or archive against the selected tenant
+func (m tuiModel) tenantAction(action string, run func(context.Context, string) (*models.TenantResponse, error)) tea.Cmd {
+	id := m.selected
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), tuiRequestTimeout)
+		defer cancel()
+		tenant, err := run(ctx, id)
+		return actionMsg{action: action, tenant: tenant, err: err}
+	}
+}
+
+func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tenantsMsg:
+		// Refreshes continue after a failed list, so the TUI recovers when the API does
+		if msg.err != nil {
+			m.err = msg.err
+			return m, m.scheduleRefresh()
+		}
+		m.err = nil
+		m.tenants = msg.tenants
+		m.selectTenant()
+		return m, m.scheduleRefresh()
+
+	case refreshMsg:
+		return m, m.listTenants()
+
+	case actionMsg:
+		if msg.err != nil {
+			m.err = fmt.Errorf("%s: %w", msg.action, msg.err)
+			return m, nil
+		}
+		m.err = nil
+		if msg.tenant != nil {
+			m.message = fmt.Sprintf("%s requested for %s", msg.action, msg.tenant.Name)
+			for i := range m.tenants {
+				if m.tenants[i].ID == msg.tenant.ID {
+					m.tenants[i] = *msg.tenant
+				}
+			}
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+func (m tuiModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "ctrl+c" {
+		return m, tea.Quit
+	}
+
+	if m.confirmArchive {
+		m.confirmArchive = false
+		if key == "y" {
+			return m, m.tenantAction("archive", m.client.ArchiveTenant)
+		}
+		m.message = "archive cancelled"
+		return m, nil
+	}
+
+	switch key {
+	case "q", "esc":
+		return m, tea.Quit
+	case "up", "k":
+		m.moveCursor(-1)
+	case "down", "j":
+		m.moveCursor(1)
+	case "enter":
+		m.details = !m.details
+	case "r":
+		if m.selected != "" {
+			m.message = ""
+			return m, m.tenantAction("retry", m.client.RetryTenant)
+		}
+	case "a":
+		if m.selected != "" {
+			m.message = ""
+			m.confirmArchive = true
+		}
+	}
+	return m, nil
+}
+
+func (m *tuiModel) moveCursor(delta int) {
+	if len(m.tenants) == 0 {
+		return
+	}
+	m.cursor = min(max(m.cursor+delta, 0), len(m.tenants)-1)
+	m.selected = m.tenants[m.cursor].ID
+}
+
+// selectTenant moves the cursor to the selected tenant after a refresh, or keeps it in range
+// when that tenant is gone
+func (m *tuiModel) selectTenant() {
+	for i, t := range m.tenants {
+		if t.ID == m.selected {
+			m.cursor = i
+			return
+		}
+	}
+	if len(m.tenants) == 0 {
+		m.cursor = 0
+		m.selected = ""
+		return
+	}
+	m.cursor = min(m.cursor, len(m.tenants)-1)
+	m.selected = m.tenants[m.cursor].ID
+}
+
+func (m tuiModel) View() string {
+	var b strings.Builder
+	b.WriteString(headerStyle.Render("Landlord tenants"))
+	b.WriteString(helpStyle.Render(fmt.Sprintf("  %d tenants, refreshed every %s", len(m.tenants), m.refresh)))
+	b.WriteString("\n\n")
+
+	if m.tenants == nil && m.err == nil {
+		b.WriteString("Loading tenants...\n")
+	} else if len(m.tenants) == 0 && m.err == nil {
+		b.WriteString("No tenants\n")
+	} else {
+		b.WriteString(m.renderTable())
+		b.WriteString("\n")
+	}
+
+	if m.details && m.selected != "" {
+		b.WriteString("\n")
+		b.WriteString(renderTenantDetails(m.tenants[m.cursor]))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	switch {
+	case m.confirmArchive:
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Archive %s? (y/n)", m.tenants[m.cursor].Name)))
+	case m.err != nil:
+		b.WriteString(errorStyle.Render(m.err.Error()))
+	case m.message != "":
+		b.WriteString(successStyle.Render(m.message))
+	}
+	b.WriteString("\n")
+	b.WriteString(helpStyle.Render("↑/k ↓/j move • enter details • r retry • a archive • q quit"))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// renderTable renders the tenant list with the cursor on the selected tenant. Widths are taken
+// before statuses are coloured, so colour codes do not skew the columns.
+func (m tuiModel) renderTable() string {
+	headers := []string{"Name", "Status", "Workflow", "Message"}
+	rows := make([][]string, 0, len(m.tenants))
+	for _, t := range m.tenants {
+		workflow := ""
+		if t.WorkflowSubState != nil {
+			workflow = *t.WorkflowSubState
+		}
+		rows = append(rows, []string{t.Name, t.Status, workflow, t.StatusMessage})
+	}
+	widths := columnWidths(headers, rows)
+
+	lines := []string{"  " + headerStyle.Render(formatRow(headers, widths))}
+	for i, row := range rows {
+		status := formatStatus(row[1]) + strings.Repeat(" ", widths[1]+2-len(row[1]))
+		line := padRight(row[0], widths[0]+2) + status + formatRow(row[2:], widths[2:])
+		if i == m.cursor {
+			line = cursorStyle.Render("> ") + line
+		} else {
+			line = "  " + line
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return strings.Join(lines, "\n")
+}
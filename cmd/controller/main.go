@@ -0,0 +1,197 @@
+// Command controller runs the tenant reconciliation controller on its own, so the control loop can
+// be scaled and deployed apart from the API. It reads and updates tenants in the database and
+// triggers workflows through the configured workflow provider, and serves its health probes and
+// metrics on the controller admin listener. Set controller.standalone so servers that would embed
+// the controller leave it to this command.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/controller"
+	"github.com/jaxxstorm/landlord/internal/database"
+	executionpostgres "github.com/jaxxstorm/landlord/internal/execution/postgres"
+	"github.com/jaxxstorm/landlord/internal/featureflag"
+	"github.com/jaxxstorm/landlord/internal/logger"
+	"github.com/jaxxstorm/landlord/internal/project"
+	projectpostgres "github.com/jaxxstorm/landlord/internal/project/postgres"
+	"github.com/jaxxstorm/landlord/internal/providerconfig"
+	providerconfigpostgres "github.com/jaxxstorm/landlord/internal/providerconfig/postgres"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantcache "github.com/jaxxstorm/landlord/internal/tenant/cache"
+	"github.com/jaxxstorm/landlord/internal/tenant/postgres"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	workflowmock "github.com/jaxxstorm/landlord/internal/workflow/providers/mock"
+	"github.com/jaxxstorm/landlord/internal/workflow/providers/restate"
+	"github.com/jaxxstorm/landlord/internal/workflow/providers/stepfunctions"
+	"github.com/jaxxstorm/landlord/internal/workflow/providers/webhook"
+)
+
+func main() {
+	// Load configuration
+	v := config.NewViperInstance()
+	if err := config.BindEnvironmentVariables(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind environment variables: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Find and load config file
+	configFile, err := config.FindConfigFile("")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to find config file: %v\n", err)
+		os.Exit(1)
+	}
+	if configFile == "" {
+		fmt.Fprintln(os.Stderr, "Config file is required for startup")
+		os.Exit(1)
+	}
+
+	if err := config.LoadConfigFile(v, configFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadFromViper(v)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Initialize logger
+	log, err := logger.New(cfg.Log.Format, cfg.Log.Level)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer log.Sync()
+
+	if !cfg.Controller.Enabled {
+		log.Fatal("controller.enabled must be set to run the controller")
+	}
+	// The admin listener is the only endpoint this command serves, so it always runs
+	cfg.Controller.Admin.Enabled = true
+	if err := cfg.Controller.Admin.Validate(); err != nil {
+		log.Fatal("Invalid controller admin listener", zap.Error(err))
+	}
+	if !cfg.Controller.Standalone {
+		log.Warn("controller.standalone is not set, so servers may run an embedded controller alongside this one")
+	}
+
+	log.Info("starting landlord controller")
+
+	ctx := context.Background()
+
+	// Initialize database
+	dbProvider, err := database.NewProvider(ctx, &cfg.Database, log)
+	if err != nil {
+		log.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbProvider.Close()
+
+	pool, ok := dbProvider.Pool().(*pgxpool.Pool)
+	if !ok {
+		log.Fatal("Database provider is not a pgxpool.Pool")
+	}
+
+	// Initialize tenant repository
+	tenantRepo, err := postgres.New(pool, log)
+	if err != nil {
+		log.Fatal("Failed to initialize tenant repository", zap.Error(err))
+	}
+	projects, err := projectpostgres.New(pool, log)
+	if err != nil {
+		log.Fatal("Failed to initialize project store", zap.Error(err))
+	}
+	// Status changes go through the notifying repository so project webhooks fire
+	var tenants tenant.Repository = project.NewNotifyingRepository(tenantRepo, project.NewNotifier(projects, log))
+	if cfg.TenantCache.Enabled {
+		cache := tenantcache.New(tenants, cfg.TenantCache, log)
+		go cache.Listen(ctx, tenantRepo)
+		tenants = cache
+	}
+
+	// Initialize the workflow provider the controller triggers
+	providerName := cfg.Controller.WorkflowProvider
+	if providerName == "" {
+		providerName = cfg.Workflow.DefaultProvider
+	}
+	workflowProvider, err := newWorkflowProvider(ctx, providerName, cfg.Workflow, log)
+	if err != nil {
+		log.Fatal("Failed to initialize workflow provider", zap.String("provider", providerName), zap.Error(err))
+	}
+	workflowRegistry := workflow.NewRegistry(log)
+	if err := workflowRegistry.Register(workflowProvider); err != nil {
+		log.Fatal("Failed to register workflow provider", zap.Error(err))
+	}
+
+	// Apply workflow providers disabled or reconfigured through the admin API; the controller
+	// registers no compute providers, so their settings are skipped
+	providerSettings, err := providerconfigpostgres.New(pool, log)
+	if err != nil {
+		log.Fatal("Failed to initialize provider settings store", zap.Error(err))
+	}
+	providerAdmin := providerconfig.NewManager(compute.NewRegistry(log), workflowRegistry, providerSettings, log)
+	if err := providerAdmin.Apply(ctx); err != nil {
+		log.Fatal("Failed to apply provider settings", zap.Error(err))
+	}
+
+	workflowClient := controller.NewWorkflowClient(workflow.New(workflowRegistry, log), log, cfg.Controller.WorkflowTriggerTimeout, workflowProvider.Name())
+	featureFlags, err := featureflag.NewRegistry(cfg.FeatureFlags)
+	if err != nil {
+		log.Fatal("Invalid feature flags", zap.Error(err))
+	}
+	workflowClient.SetFeatureFlags(featureFlags)
+
+	executionSteps, err := executionpostgres.New(pool, log)
+	if err != nil {
+		log.Fatal("Failed to initialize execution step store", zap.Error(err))
+	}
+
+	reconciler := controller.NewReconciler(tenants, workflowClient, cfg.Controller, log)
+	reconciler.SetExecutionSteps(executionSteps)
+	if cfg.Controller.TriggerOutbox.Enabled {
+		reconciler.SetTriggerOutbox(tenantRepo)
+	}
+
+	if err := reconciler.Start(); err != nil {
+		log.Fatal("Failed to start controller", zap.Error(err))
+	}
+	log.Info("controller started", zap.String("admin_address", reconciler.AdminAddr()), zap.String("workflow_provider", workflowProvider.Name()))
+
+	sigCtx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	<-sigCtx.Done()
+
+	log.Info("shutting down")
+	if err := reconciler.Stop(); err != nil {
+		log.Error("Controller shutdown failed", zap.Error(err))
+	}
+	log.Info("landlord controller stopped")
+}
+
+// newWorkflowProvider creates the named workflow provider from the workflow configuration
+func newWorkflowProvider(ctx context.Context, name string, cfg config.WorkflowConfig, log *zap.Logger) (workflow.Provider, error) {
+	switch name {
+	case "mock":
+		return workflowmock.New(log), nil
+	case "step-functions":
+		return stepfunctions.New(ctx, stepfunctions.Config{Region: cfg.StepFunctions.Region, RoleARN: cfg.StepFunctions.RoleARN}, log)
+	case "restate":
+		return restate.New(cfg.Restate, log)
+	case webhook.ProviderName:
+		// Webhook executions are tracked by the provider instance that receives their callbacks,
+		// which is the API server's
+		return nil, fmt.Errorf("the webhook provider needs the controller embedded in the server that receives callbacks")
+	default:
+		return nil, fmt.Errorf("unknown workflow provider %q", name)
+	}
+}
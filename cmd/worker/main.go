@@ -10,13 +10,33 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jaxxstorm/landlord/internal/compute"
+	callbackpostgres "github.com/jaxxstorm/landlord/internal/compute/callbackqueue/postgres"
 	computedecs "github.com/jaxxstorm/landlord/internal/compute/providers/ecs"
 	computedocker "github.com/jaxxstorm/landlord/internal/compute/providers/docker"
+	computefirecracker "github.com/jaxxstorm/landlord/internal/compute/providers/firecracker"
 	computemock "github.com/jaxxstorm/landlord/internal/compute/providers/mock"
+	computepool "github.com/jaxxstorm/landlord/internal/compute/providers/pool"
+	computepoolpostgres "github.com/jaxxstorm/landlord/internal/compute/providers/pool/postgres"
 	"github.com/jaxxstorm/landlord/internal/config"
+	dataplaneobjectstore "github.com/jaxxstorm/landlord/internal/dataplane/objectstore"
+	dataplanepostgres "github.com/jaxxstorm/landlord/internal/dataplane/postgres"
 	"github.com/jaxxstorm/landlord/internal/database"
+	"github.com/jaxxstorm/landlord/internal/egress"
+	"github.com/jaxxstorm/landlord/internal/execution"
+	executionpostgres "github.com/jaxxstorm/landlord/internal/execution/postgres"
+	"github.com/jaxxstorm/landlord/internal/housekeeping"
 	"github.com/jaxxstorm/landlord/internal/logger"
+	"github.com/jaxxstorm/landlord/internal/observe"
+	"github.com/jaxxstorm/landlord/internal/plugin"
+	"github.com/jaxxstorm/landlord/internal/providerconfig"
+	providerconfigpostgres "github.com/jaxxstorm/landlord/internal/providerconfig/postgres"
+	"github.com/jaxxstorm/landlord/internal/resolution"
+	"github.com/jaxxstorm/landlord/internal/retention"
+	"github.com/jaxxstorm/landlord/internal/resource"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantcache "github.com/jaxxstorm/landlord/internal/tenant/cache"
 	"github.com/jaxxstorm/landlord/internal/tenant/postgres"
+	"github.com/jaxxstorm/landlord/internal/vulnscan"
 	"github.com/jaxxstorm/landlord/internal/workflow"
 	"github.com/jaxxstorm/landlord/internal/workflow/providers/restate"
 	"go.uber.org/zap"
@@ -74,7 +94,13 @@ func main() {
 	// Initialize compute registry and register providers
 	computeRegistry := compute.NewRegistry(log)
 	if cfg.Compute.Mock != nil {
-		computeRegistry.Register(computemock.New())
+		mockProvider := computemock.NewWithDefaults(cfg.Compute.Mock.Defaults)
+		if len(cfg.Compute.Mock.Defaults) > 0 {
+			if err := validateProviderDefaults("mock", mockProvider, cfg.Compute.Mock.Defaults); err != nil {
+				log.Fatal("Invalid mock compute defaults", zap.Error(err))
+			}
+		}
+		computeRegistry.Register(mockProvider)
 	}
 	if cfg.Compute.ECS != nil {
 		ecsProvider := computedecs.New(log, cfg.Compute.ECS.Defaults)
@@ -89,10 +115,22 @@ func main() {
 		log.Info("registering Docker compute provider")
 		dockerProvider, err := computedocker.New(
 			&computedocker.Config{
-				Host:          cfg.Compute.Docker.Host,
-				NetworkName:   cfg.Compute.Docker.NetworkName,
-				NetworkDriver: cfg.Compute.Docker.NetworkDriver,
-				LabelPrefix:   cfg.Compute.Docker.LabelPrefix,
+				Host:              cfg.Compute.Docker.Host,
+				NetworkName:       cfg.Compute.Docker.NetworkName,
+				NetworkDriver:     cfg.Compute.Docker.NetworkDriver,
+				LabelPrefix:       cfg.Compute.Docker.LabelPrefix,
+				NetworkIsolation:  cfg.Compute.Docker.NetworkIsolation,
+				IngressNetwork:    cfg.Compute.Docker.IngressNetwork,
+				EmulatedPlatforms: cfg.Compute.Docker.EmulatedPlatforms,
+				Runtime:           cfg.Compute.Docker.Runtime,
+				Namespace:         cfg.Compute.Docker.Namespace,
+				EgressFirewall:    cfg.Compute.Docker.EgressFirewall,
+				Naming: computedocker.NamingConfig{
+					Prefix:             cfg.Compute.Docker.Naming.Prefix,
+					IncludeName:        cfg.Compute.Docker.Naming.IncludeName,
+					IncludeEnvironment: cfg.Compute.Docker.Naming.IncludeEnvironment,
+					ID:                 cfg.Compute.Docker.Naming.ID,
+				},
 			},
 			cfg.Compute.Docker.Defaults,
 			log,
@@ -106,35 +144,116 @@ func main() {
 		computeRegistry.Register(dockerProvider)
 	}
 
-	// Initialize tenant repository
+	// Register Firecracker provider if configured
+	if cfg.Compute.Firecracker != nil {
+		log.Info("registering Firecracker compute provider")
+		firecrackerProvider, err := computefirecracker.New(
+			&computefirecracker.Config{
+				Mode:        cfg.Compute.Firecracker.Mode,
+				BinaryPath:  cfg.Compute.Firecracker.BinaryPath,
+				StateDir:    cfg.Compute.Firecracker.StateDir,
+				KataRuntime: cfg.Compute.Firecracker.KataRuntime,
+				Namespace:   cfg.Compute.Firecracker.Namespace,
+			},
+			cfg.Compute.Firecracker.Defaults,
+			log,
+		)
+		if err != nil {
+			log.Fatal("Failed to initialize Firecracker provider", zap.Error(err))
+		}
+		if err := validateProviderDefaults("firecracker", firecrackerProvider, cfg.Compute.Firecracker.Defaults); err != nil {
+			log.Fatal("Invalid Firecracker compute defaults", zap.Error(err))
+		}
+		computeRegistry.Register(firecrackerProvider)
+	}
+
+	// Launch out-of-process provider plugins; workers only host compute plugins
+	plugins, err := plugin.Load(ctx, cfg.Plugins, log)
+	if err != nil {
+		log.Fatal("Failed to load plugins", zap.Error(err))
+	}
+	defer plugins.Close()
+	if err := plugins.Register(computeRegistry, nil); err != nil {
+		log.Fatal("Failed to register plugins", zap.Error(err))
+	}
+
 	// Get database connection pool from provider
 	pool, ok := dbProvider.Pool().(*pgxpool.Pool)
 	if !ok {
 		log.Fatal("Database provider is not a pgxpool.Pool")
 	}
+
+	// Register the pool provider now the database is available; slot assignments are kept there
+	if cfg.Compute.Pool != nil {
+		log.Info("registering pool compute provider")
+		slotStore, err := computepoolpostgres.New(pool, log)
+		if err != nil {
+			log.Fatal("Failed to initialize pool slot store", zap.Error(err))
+		}
+		slots := make([]computepool.Slot, 0, len(cfg.Compute.Pool.Slots))
+		for _, slot := range cfg.Compute.Pool.Slots {
+			slots = append(slots, computepool.Slot{Name: slot.Name, Address: slot.Address, Ports: slot.Ports, Labels: slot.Labels})
+		}
+		poolProvider, err := computepool.New(slots, slotStore, cfg.Compute.Pool.Defaults, log)
+		if err != nil {
+			log.Fatal("Failed to initialize pool provider", zap.Error(err))
+		}
+		if len(cfg.Compute.Pool.Defaults) > 0 {
+			if err := validateProviderDefaults("pool", poolProvider, cfg.Compute.Pool.Defaults); err != nil {
+				log.Fatal("Invalid pool compute defaults", zap.Error(err))
+			}
+		}
+		computeRegistry.Register(poolProvider)
+	}
+
+	// Initialize tenant repository
 	tenantRepo, err := postgres.New(pool, log)
 	if err != nil {
 		log.Fatal("Failed to initialize tenant repository", zap.Error(err))
 	}
 
+	// Apply provider changes made at runtime through the admin API, such as reconfigured defaults
+	providerSettings, err := providerconfigpostgres.New(pool, log)
+	if err != nil {
+		log.Fatal("Failed to initialize provider settings store", zap.Error(err))
+	}
+	if err := providerconfig.NewManager(computeRegistry, nil, providerSettings, log).Apply(ctx); err != nil {
+		log.Fatal("Failed to apply provider settings", zap.Error(err))
+	}
+
 	if cfg.Workflow.Restate.WorkerComputeProvider == "" {
 		cfg.Workflow.Restate.WorkerComputeProvider = cfg.Compute.DefaultProvider()
 	}
 
 	var landlordClient workflow.LandlordClient
 	if cfg.Workflow.Restate.WorkerLandlordAPIURL != "" {
-		landlordClient = workflow.NewHTTPLandlordClient(cfg.Workflow.Restate.WorkerLandlordAPIURL, log)
+		landlordClient = workflow.NewHTTPLandlordClient(cfg.Workflow.Restate.WorkerLandlordAPIURL, workflow.HTTPLandlordClientOptions{
+			APIKey:           cfg.Workflow.Restate.WorkerLandlordAPIKey,
+			Timeout:          cfg.Workflow.Restate.WorkerLandlordAPITimeout,
+			MaxRetries:       cfg.Workflow.Restate.WorkerLandlordAPIRetries,
+			NegativeCacheTTL: cfg.Workflow.Restate.WorkerLandlordAPINegativeCacheTTL,
+		}, log)
+	}
+
+	// Compute resolution reads the tenant of every workflow step; the cache drops tenants other processes change
+	var resolverTenants tenant.Repository = tenantRepo
+	if cfg.TenantCache.Enabled {
+		cache := tenantcache.New(tenantRepo, cfg.TenantCache, log)
+		go cache.Listen(ctx, tenantRepo)
+		resolverTenants = cache
 	}
 
 	var computeResolver workflow.ComputeProviderResolver
 	if landlordClient != nil || cfg.Workflow.Restate.WorkerComputeProvider != "" {
-		computeResolver = workflow.NewCachedComputeProviderResolver(
+		resolver := workflow.NewCachedComputeProviderResolver(
 			landlordClient,
-			tenantRepo,
+			resolverTenants,
 			cfg.Workflow.Restate.WorkerComputeProvider,
 			cfg.Workflow.Restate.WorkerComputeCacheTTL,
 			log,
 		)
+		resolver.SetRules(resolution.New(cfg.ComputeResolution))
+		computeResolver = resolver
 	}
 
 	workerRegistry := workflow.NewWorkerRegistry(log)
@@ -142,6 +261,40 @@ func main() {
 	if err != nil {
 		log.Fatal("Failed to initialize restate worker engine", zap.Error(err))
 	}
+
+	resourceRegistry := resource.NewRegistry(log)
+	if cfg.DataPlane.Postgres != nil {
+		postgresDataPlane, err := dataplanepostgres.New(ctx, *cfg.DataPlane.Postgres, log)
+		if err != nil {
+			log.Fatal("Failed to initialize postgres data plane", zap.Error(err))
+		}
+		defer postgresDataPlane.Close()
+		if err := resourceRegistry.Register(postgresDataPlane); err != nil {
+			log.Fatal("Failed to register postgres resource provider", zap.Error(err))
+		}
+	}
+	if cfg.DataPlane.Bucket != nil {
+		bucketDataPlane, err := dataplaneobjectstore.New(ctx, *cfg.DataPlane.Bucket, log)
+		if err != nil {
+			log.Fatal("Failed to initialize bucket data plane", zap.Error(err))
+		}
+		if err := resourceRegistry.Register(bucketDataPlane); err != nil {
+			log.Fatal("Failed to register bucket resource provider", zap.Error(err))
+		}
+	}
+	restateWorker.SetResourceRegistry(resourceRegistry)
+	if len(cfg.Compute.Limits) > 0 {
+		restateWorker.SetConcurrencyLimiter(compute.NewLimiter(cfg.Compute.Limits))
+	}
+	if cfg.VulnerabilityScan.Enabled {
+		restateWorker.SetVulnerabilityScanner(vulnscan.New(cfg.VulnerabilityScan, log))
+	}
+	// Workflow steps are recorded straight into the database the API server reads them from
+	executionSteps, err := executionpostgres.New(pool, log)
+	if err != nil {
+		log.Fatal("Failed to initialize execution step store", zap.Error(err))
+	}
+	restateWorker.SetStepReporter(execution.NewReporter(executionSteps))
 	if err := workerRegistry.Register(restateWorker); err != nil {
 		log.Fatal("Failed to register restate worker engine", zap.Error(err))
 	}
@@ -151,8 +304,72 @@ func main() {
 		log.Fatal("Failed to select worker engine", zap.Error(err))
 	}
 
-	if err := workerEngine.Register(ctx); err != nil {
-		log.Fatal("Failed to register worker engine", zap.Error(err))
+	// The worker hosts the compute providers that enforce egress policies, so it reads what they deny
+	if cfg.EgressMonitor.Enabled {
+		monitor := egress.NewMonitor(tenantRepo, computeRegistry, cfg.EgressMonitor, log)
+		if err := monitor.Start(); err != nil {
+			log.Fatal("Failed to start egress monitor", zap.Error(err))
+		}
+		defer monitor.Stop()
+	}
+
+	if cfg.Retention.Enabled {
+		scrubber := retention.NewScrubber(tenantRepo, providerSettings, cfg.Retention, log)
+		if err := scrubber.Start(); err != nil {
+			log.Fatal("Failed to start retention scrubber", zap.Error(err))
+		}
+		defer scrubber.Stop()
+	}
+
+	// Deletes the outbox entries and callbacks that have been published or given up on
+	if cfg.Housekeeping.Enabled {
+		callbackQueue, err := callbackpostgres.New(pool, log)
+		if err != nil {
+			log.Fatal("Failed to initialize callback queue", zap.Error(err))
+		}
+		collector := housekeeping.NewCollector(housekeeping.Tables{
+			Outbox:              tenantRepo,
+			OutboxMaxAttempts:   cfg.Controller.TriggerOutbox.MaxAttempts,
+			Callbacks:           callbackQueue,
+			CallbackMaxAttempts: cfg.Workflow.Callbacks.MaxAttempts,
+		}, cfg.Housekeeping, log)
+		if err := collector.Start(); err != nil {
+			log.Fatal("Failed to start housekeeping", zap.Error(err))
+		}
+		defer collector.Stop()
+	}
+
+	// The worker hosts the compute providers, so it reads what each ready tenant is actually running
+	if cfg.Observe.Enabled {
+		refresher := observe.NewRefresher(tenantRepo, computeRegistry, cfg.Compute.DefaultProvider(), resolution.New(cfg.ComputeResolution), cfg.Observe, log)
+		if err := refresher.Start(); err != nil {
+			log.Fatal("Failed to start observe refresher", zap.Error(err))
+		}
+		defer refresher.Stop()
+	}
+
+	// Deliver the compute callbacks queued for workflow providers that use the callback outbox
+	if outbox := cfg.Workflow.Callbacks.OutboxProviders(); len(outbox) > 0 {
+		callbackQueue, err := callbackpostgres.New(pool, log)
+		if err != nil {
+			log.Fatal("Failed to initialize callback queue", zap.Error(err))
+		}
+		callbackProviders := make(map[string]compute.WorkflowProvider, len(outbox))
+		for _, name := range outbox {
+			if name != "restate" {
+				log.Fatal("Callback outbox is not supported for workflow provider", zap.String("provider", name))
+			}
+			restateProvider, err := restate.New(cfg.Workflow.Restate, log)
+			if err != nil {
+				log.Fatal("Failed to initialize restate callback provider", zap.Error(err))
+			}
+			callbackProviders[name] = restateProvider
+		}
+		dispatcher := compute.NewCallbackDispatcher(callbackQueue, callbackProviders, cfg.Workflow.Callbacks, log)
+		if err := dispatcher.Start(); err != nil {
+			log.Fatal("Failed to start callback dispatcher", zap.Error(err))
+		}
+		defer dispatcher.Stop()
 	}
 
 	// Start the worker
@@ -160,11 +377,23 @@ func main() {
 	defer cancel()
 
 	workerAddr := getWorkerAddress()
-	log.Info("worker started, waiting for workflows",
+	log.Info("starting worker server",
 		zap.String("address", workerAddr),
 		zap.String("worker_engine", workerEngine.Name()),
 	)
 
+	// Register once the listener is bound; failures are retried in the background
+	go func() {
+		if err := workflow.RegisterWorker(workerCtx, workerEngine, 0, 0, log); err != nil {
+			log.Error("Worker registration abandoned", zap.Error(err))
+			return
+		}
+		log.Info("worker started, waiting for workflows",
+			zap.String("address", workerAddr),
+			zap.String("worker_engine", workerEngine.Name()),
+		)
+	}()
+
 	if err := workerEngine.Start(workerCtx, workerAddr); err != nil {
 		log.Fatal("Worker failed", zap.Error(err))
 	}
@@ -7,14 +7,24 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/jaxxstorm/landlord/internal/compute"
 	computedecs "github.com/jaxxstorm/landlord/internal/compute/providers/ecs"
 	computedocker "github.com/jaxxstorm/landlord/internal/compute/providers/docker"
+	computefirecracker "github.com/jaxxstorm/landlord/internal/compute/providers/firecracker"
 	computemock "github.com/jaxxstorm/landlord/internal/compute/providers/mock"
+	computepool "github.com/jaxxstorm/landlord/internal/compute/providers/pool"
+	computepoolpostgres "github.com/jaxxstorm/landlord/internal/compute/providers/pool/postgres"
 	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/database"
+	dataplaneobjectstore "github.com/jaxxstorm/landlord/internal/dataplane/objectstore"
+	dataplanepostgres "github.com/jaxxstorm/landlord/internal/dataplane/postgres"
+	"github.com/jaxxstorm/landlord/internal/endpointauth"
 	"github.com/jaxxstorm/landlord/internal/logger"
+	"github.com/jaxxstorm/landlord/internal/plugin"
+	"github.com/jaxxstorm/landlord/internal/resolution"
+	"github.com/jaxxstorm/landlord/internal/resource"
+	"github.com/jaxxstorm/landlord/internal/vulnscan"
 	"github.com/jaxxstorm/landlord/internal/workflow"
 	"github.com/jaxxstorm/landlord/internal/workflow/providers/restate"
 	"go.uber.org/zap"
@@ -65,7 +75,13 @@ func main() {
 	// Initialize compute registry and register providers
 	computeRegistry := compute.NewRegistry(log)
 	if cfg.Compute.Mock != nil {
-		computeRegistry.Register(computemock.New())
+		mockProvider := computemock.NewWithDefaults(cfg.Compute.Mock.Defaults)
+		if len(cfg.Compute.Mock.Defaults) > 0 {
+			if err := validateProviderDefaults("mock", mockProvider, cfg.Compute.Mock.Defaults); err != nil {
+				log.Fatal("Invalid mock compute defaults", zap.Error(err))
+			}
+		}
+		computeRegistry.Register(mockProvider)
 	}
 	if cfg.Compute.ECS != nil {
 		ecsProvider := computedecs.New(log, cfg.Compute.ECS.Defaults)
@@ -80,10 +96,22 @@ func main() {
 		log.Info("registering Docker compute provider")
 		dockerProvider, err := computedocker.New(
 			&computedocker.Config{
-				Host:          cfg.Compute.Docker.Host,
-				NetworkName:   cfg.Compute.Docker.NetworkName,
-				NetworkDriver: cfg.Compute.Docker.NetworkDriver,
-				LabelPrefix:   cfg.Compute.Docker.LabelPrefix,
+				Host:              cfg.Compute.Docker.Host,
+				NetworkName:       cfg.Compute.Docker.NetworkName,
+				NetworkDriver:     cfg.Compute.Docker.NetworkDriver,
+				LabelPrefix:       cfg.Compute.Docker.LabelPrefix,
+				NetworkIsolation:  cfg.Compute.Docker.NetworkIsolation,
+				IngressNetwork:    cfg.Compute.Docker.IngressNetwork,
+				EmulatedPlatforms: cfg.Compute.Docker.EmulatedPlatforms,
+				Runtime:           cfg.Compute.Docker.Runtime,
+				Namespace:         cfg.Compute.Docker.Namespace,
+				EgressFirewall:    cfg.Compute.Docker.EgressFirewall,
+				Naming: computedocker.NamingConfig{
+					Prefix:             cfg.Compute.Docker.Naming.Prefix,
+					IncludeName:        cfg.Compute.Docker.Naming.IncludeName,
+					IncludeEnvironment: cfg.Compute.Docker.Naming.IncludeEnvironment,
+					ID:                 cfg.Compute.Docker.Naming.ID,
+				},
 			},
 			cfg.Compute.Docker.Defaults,
 			log,
@@ -97,24 +125,96 @@ func main() {
 		computeRegistry.Register(dockerProvider)
 	}
 
+	// Register Firecracker provider if configured
+	if cfg.Compute.Firecracker != nil {
+		log.Info("registering Firecracker compute provider")
+		firecrackerProvider, err := computefirecracker.New(
+			&computefirecracker.Config{
+				Mode:        cfg.Compute.Firecracker.Mode,
+				BinaryPath:  cfg.Compute.Firecracker.BinaryPath,
+				StateDir:    cfg.Compute.Firecracker.StateDir,
+				KataRuntime: cfg.Compute.Firecracker.KataRuntime,
+				Namespace:   cfg.Compute.Firecracker.Namespace,
+			},
+			cfg.Compute.Firecracker.Defaults,
+			log,
+		)
+		if err != nil {
+			log.Fatal("Failed to initialize Firecracker provider", zap.Error(err))
+		}
+		if err := validateProviderDefaults("firecracker", firecrackerProvider, cfg.Compute.Firecracker.Defaults); err != nil {
+			log.Fatal("Invalid Firecracker compute defaults", zap.Error(err))
+		}
+		computeRegistry.Register(firecrackerProvider)
+	}
+
+	// Register the pool provider; slot assignments are kept in the database
+	if cfg.Compute.Pool != nil {
+		log.Info("registering pool compute provider")
+		// This worker only opens the database for the pool's slot assignments
+		dbProvider, err := database.NewProvider(ctx, &cfg.Database, log)
+		if err != nil {
+			log.Fatal("Failed to initialize database", zap.Error(err))
+		}
+		defer dbProvider.Close()
+		slotStore, err := computepoolpostgres.New(dbProvider.Pool(), log)
+		if err != nil {
+			log.Fatal("Failed to initialize pool slot store", zap.Error(err))
+		}
+		slots := make([]computepool.Slot, 0, len(cfg.Compute.Pool.Slots))
+		for _, slot := range cfg.Compute.Pool.Slots {
+			slots = append(slots, computepool.Slot{Name: slot.Name, Address: slot.Address, Ports: slot.Ports, Labels: slot.Labels})
+		}
+		poolProvider, err := computepool.New(slots, slotStore, cfg.Compute.Pool.Defaults, log)
+		if err != nil {
+			log.Fatal("Failed to initialize pool provider", zap.Error(err))
+		}
+		if len(cfg.Compute.Pool.Defaults) > 0 {
+			if err := validateProviderDefaults("pool", poolProvider, cfg.Compute.Pool.Defaults); err != nil {
+				log.Fatal("Invalid pool compute defaults", zap.Error(err))
+			}
+		}
+		computeRegistry.Register(poolProvider)
+	}
+
+	// Launch out-of-process provider plugins; workers only host compute plugins
+	plugins, err := plugin.Load(ctx, cfg.Plugins, log)
+	if err != nil {
+		log.Fatal("Failed to load plugins", zap.Error(err))
+	}
+	defer plugins.Close()
+	if err := plugins.Register(computeRegistry, nil); err != nil {
+		log.Fatal("Failed to register plugins", zap.Error(err))
+	}
+
 	if cfg.Workflow.Restate.WorkerComputeProvider == "" {
 		cfg.Workflow.Restate.WorkerComputeProvider = cfg.Compute.DefaultProvider()
 	}
 
 	var landlordClient workflow.LandlordClient
+	var stepReporter workflow.StepReporter
 	if cfg.Workflow.Restate.WorkerLandlordAPIURL != "" {
-		landlordClient = workflow.NewHTTPLandlordClient(cfg.Workflow.Restate.WorkerLandlordAPIURL, log)
+		httpClient := workflow.NewHTTPLandlordClient(cfg.Workflow.Restate.WorkerLandlordAPIURL, workflow.HTTPLandlordClientOptions{
+			APIKey:           cfg.Workflow.Restate.WorkerLandlordAPIKey,
+			Timeout:          cfg.Workflow.Restate.WorkerLandlordAPITimeout,
+			MaxRetries:       cfg.Workflow.Restate.WorkerLandlordAPIRetries,
+			NegativeCacheTTL: cfg.Workflow.Restate.WorkerLandlordAPINegativeCacheTTL,
+		}, log)
+		landlordClient = httpClient
+		stepReporter = httpClient
 	}
 
 	var computeResolver workflow.ComputeProviderResolver
 	if landlordClient != nil || cfg.Workflow.Restate.WorkerComputeProvider != "" {
-		computeResolver = workflow.NewCachedComputeProviderResolver(
+		resolver := workflow.NewCachedComputeProviderResolver(
 			landlordClient,
 			nil,
 			cfg.Workflow.Restate.WorkerComputeProvider,
 			cfg.Workflow.Restate.WorkerComputeCacheTTL,
 			log,
 		)
+		resolver.SetRules(resolution.New(cfg.ComputeResolution))
+		computeResolver = resolver
 	}
 
 	restateWorker, err := restate.NewWorkerEngine(cfg.Workflow.Restate, computeRegistry, computeResolver, log)
@@ -122,6 +222,38 @@ func main() {
 		log.Fatal("Failed to initialize restate worker engine", zap.Error(err))
 	}
 
+	resourceRegistry := resource.NewRegistry(log)
+	if cfg.DataPlane.Postgres != nil {
+		postgresDataPlane, err := dataplanepostgres.New(ctx, *cfg.DataPlane.Postgres, log)
+		if err != nil {
+			log.Fatal("Failed to initialize postgres data plane", zap.Error(err))
+		}
+		defer postgresDataPlane.Close()
+		if err := resourceRegistry.Register(postgresDataPlane); err != nil {
+			log.Fatal("Failed to register postgres resource provider", zap.Error(err))
+		}
+	}
+	if cfg.DataPlane.Bucket != nil {
+		bucketDataPlane, err := dataplaneobjectstore.New(ctx, *cfg.DataPlane.Bucket, log)
+		if err != nil {
+			log.Fatal("Failed to initialize bucket data plane", zap.Error(err))
+		}
+		if err := resourceRegistry.Register(bucketDataPlane); err != nil {
+			log.Fatal("Failed to register bucket resource provider", zap.Error(err))
+		}
+	}
+	restateWorker.SetResourceRegistry(resourceRegistry)
+	restateWorker.SetStepReporter(stepReporter)
+	if len(cfg.Compute.Limits) > 0 {
+		restateWorker.SetConcurrencyLimiter(compute.NewLimiter(cfg.Compute.Limits))
+	}
+	if cfg.VulnerabilityScan.Enabled {
+		restateWorker.SetVulnerabilityScanner(vulnscan.New(cfg.VulnerabilityScan, log))
+	}
+	if cfg.EndpointAuth.Enabled {
+		restateWorker.SetEndpointAuth(endpointauth.New(cfg.EndpointAuth))
+	}
+
 	workerRegistry := workflow.NewWorkerRegistry(log)
 	if err := workerRegistry.Register(restateWorker); err != nil {
 		log.Fatal("Failed to register restate worker engine", zap.Error(err))
@@ -155,17 +287,18 @@ func main() {
 		startErr <- selectedWorker.Start(workerCtx, workerAddr)
 	}()
 
-	// Give the worker server a moment to start before registering with Restate.
-	time.Sleep(500 * time.Millisecond)
-
-	if err := selectedWorker.Register(ctx); err != nil {
-		log.Fatal("Failed to register worker engine", zap.Error(err))
-	}
-
-	log.Info("worker started, waiting for workflows",
-		zap.String("address", workerAddr),
-		zap.String("worker_engine", selectedWorker.Name()),
-	)
+	// Register once the listener is bound; failures are retried in the background
+	// so a slow Restate admin API does not take the worker down.
+	go func() {
+		if err := workflow.RegisterWorker(workerCtx, selectedWorker, 0, 0, log); err != nil {
+			log.Error("Worker registration abandoned", zap.Error(err))
+			return
+		}
+		log.Info("worker started, waiting for workflows",
+			zap.String("address", workerAddr),
+			zap.String("worker_engine", selectedWorker.Name()),
+		)
+	}()
 
 	if err := <-startErr; err != nil {
 		log.Fatal("Worker failed", zap.Error(err))
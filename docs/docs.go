@@ -481,6 +481,10 @@ const docTemplate = `{
         "models.ErrorResponse": {
             "type": "object",
             "properties": {
+                "code": {
+                    "description": "Code classifies the error; see ErrorCode for the stable set of values",
+                    "type": "string"
+                },
                 "details": {
                     "description": "Details provides additional context about the error",
                     "type": "array",
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+func TestTenantActionMetadata(t *testing.T) {
+	repo := tenantmemory.New()
+	executionID := "exec-update"
+	finalizing := string(workflow.SubStateFinalizing)
+	startedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	tenants := []*tenant.Tenant{
+		{Name: "requested", Status: tenant.StatusRequested},
+		{Name: "ready", Status: tenant.StatusReady},
+		{
+			Name:                "updating",
+			Status:              tenant.StatusUpdating,
+			WorkflowExecutionID: &executionID,
+			LastAction:          &tenant.ActionRecord{Action: tenant.ActionUpdate, Source: "controller:config-change", ExecutionID: executionID, At: startedAt},
+		},
+		{Name: "finalizing", Status: tenant.StatusDeleting, WorkflowSubState: &finalizing, Finalizers: []string{"dns"}},
+	}
+	for _, tn := range tenants {
+		if err := repo.CreateTenant(context.Background(), tn); err != nil {
+			t.Fatalf("create tenant %s: %v", tn.Name, err)
+		}
+	}
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo}
+	srv.registerRoutes()
+
+	cases := []struct {
+		name       string
+		lastAction string
+		source     string
+		next       string
+	}{
+		{name: "requested", next: tenant.ActionProvision},
+		{name: "ready"},
+		{name: "updating", lastAction: tenant.ActionUpdate, source: "controller:config-change"},
+		{name: "finalizing", next: tenant.ActionFinalize},
+	}
+	for _, tc := range cases {
+		for _, target := range []string{"/v1/tenants/" + tc.name, "/v1/tenants/" + tc.name + "/status"} {
+			rec := httptest.NewRecorder()
+			srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("%s: expected 200, got %d: %s", target, rec.Code, rec.Body.String())
+			}
+			var resp models.TenantStatusResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("%s: decode: %v", target, err)
+			}
+			if resp.LastAction != tc.lastAction || resp.LastActionSource != tc.source || resp.NextPlannedAction != tc.next {
+				t.Errorf("%s: unexpected actions last=%q source=%q next=%q", target, resp.LastAction, resp.LastActionSource, resp.NextPlannedAction)
+			}
+			if tc.lastAction != "" && (resp.LastActionAt == nil || !resp.LastActionAt.Equal(startedAt)) {
+				t.Errorf("%s: expected last_action_at %s, got %v", target, startedAt, resp.LastActionAt)
+			}
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/providerconfig"
+)
+
+// ProviderAdmin manages compute and workflow providers at runtime
+type ProviderAdmin interface {
+	List(ctx context.Context) ([]*providerconfig.Status, error)
+	Get(ctx context.Context, kind providerconfig.Kind, name string) (*providerconfig.Status, error)
+	SetEnabled(ctx context.Context, kind providerconfig.Kind, name string, enabled bool, actor, requestID string) (*providerconfig.Status, error)
+	Reconfigure(ctx context.Context, kind providerconfig.Kind, name string, config map[string]interface{}, actor, requestID string) (*providerconfig.Status, error)
+	Audit(ctx context.Context, kind providerconfig.Kind, name string) ([]*providerconfig.AuditEntry, error)
+}
+
+// SetProviderAdmin enables the /admin/providers endpoints
+func (s *Server) SetProviderAdmin(admin ProviderAdmin) {
+	s.providerAdmin = admin
+}
+
+// handleListProviders lists compute and workflow providers with their runtime state
+// @Summary List providers
+// @Description Returns every registered compute and workflow provider with its runtime state
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.ListProvidersResponse "Registered providers"
+// @Failure 500 {object} models.ErrorResponse "Failed to list providers"
+// @Failure 503 {object} models.ErrorResponse "Provider administration not configured"
+// @Router /v1/admin/providers [get]
+func (s *Server) handleListProviders(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireProviderAdmin(w, r, requestID) {
+		return
+	}
+
+	statuses, err := s.providerAdmin.List(r.Context())
+	if err != nil {
+		s.writeProviderAdminError(w, r, err, requestID)
+		return
+	}
+
+	resp := models.ListProvidersResponse{Providers: make([]models.ProviderResponse, 0, len(statuses))}
+	for _, status := range statuses {
+		resp.Providers = append(resp.Providers, toProviderResponse(status))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleGetProvider returns one provider's runtime state
+// @Summary Get provider
+// @Tags admin
+// @Produce json
+// @Param kind path string true "Provider kind (compute or workflow)"
+// @Param name path string true "Provider name"
+// @Success 200 {object} models.ProviderResponse "Provider state"
+// @Failure 400 {object} models.ErrorResponse "Invalid provider kind"
+// @Failure 404 {object} models.ErrorResponse "Provider not found"
+// @Failure 503 {object} models.ErrorResponse "Provider administration not configured"
+// @Router /v1/admin/providers/{kind}/{name} [get]
+func (s *Server) handleGetProvider(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	kind, name, ok := s.providerFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	status, err := s.providerAdmin.Get(r.Context(), kind, name)
+	if err != nil {
+		s.writeProviderAdminError(w, r, err, requestID)
+		return
+	}
+	s.writeProvider(w, status)
+}
+
+// handleEnableProvider returns a drained provider to service
+// @Summary Enable provider
+// @Tags admin
+// @Produce json
+// @Param kind path string true "Provider kind (compute or workflow)"
+// @Param name path string true "Provider name"
+// @Param field_manager query string false "Actor recorded in the audit log (defaults to X-Field-Manager header, then api)"
+// @Success 200 {object} models.ProviderResponse "Provider state"
+// @Failure 400 {object} models.ErrorResponse "Invalid provider kind"
+// @Failure 404 {object} models.ErrorResponse "Provider not found"
+// @Failure 503 {object} models.ErrorResponse "Provider administration not configured"
+// @Router /v1/admin/providers/{kind}/{name}/enable [post]
+func (s *Server) handleEnableProvider(w http.ResponseWriter, r *http.Request) {
+	s.setProviderEnabled(w, r, true)
+}
+
+// handleDisableProvider drains a provider: compute providers receive no new tenants and workflow
+// providers start no new executions, while existing tenants and executions are unaffected
+// @Summary Disable provider
+// @Tags admin
+// @Produce json
+// @Param kind path string true "Provider kind (compute or workflow)"
+// @Param name path string true "Provider name"
+// @Param field_manager query string false "Actor recorded in the audit log (defaults to X-Field-Manager header, then api)"
+// @Success 200 {object} models.ProviderResponse "Provider state"
+// @Failure 400 {object} models.ErrorResponse "Invalid provider kind"
+// @Failure 404 {object} models.ErrorResponse "Provider not found"
+// @Failure 503 {object} models.ErrorResponse "Provider administration not configured"
+// @Router /v1/admin/providers/{kind}/{name}/disable [post]
+func (s *Server) handleDisableProvider(w http.ResponseWriter, r *http.Request) {
+	s.setProviderEnabled(w, r, false)
+}
+
+func (s *Server) setProviderEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	requestID := r.Header.Get("X-Request-ID")
+	kind, name, ok := s.providerFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	status, err := s.providerAdmin.SetEnabled(r.Context(), kind, name, enabled, fieldManager(r), requestID)
+	if err != nil {
+		s.writeProviderAdminError(w, r, err, requestID)
+		return
+	}
+	s.writeProvider(w, status)
+}
+
+// handleReconfigureProvider replaces a provider's runtime configuration
+// @Summary Reconfigure provider
+// @Description Replaces the provider configuration. For compute providers this is the default compute_config merged into every tenant's config.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param kind path string true "Provider kind (compute or workflow)"
+// @Param name path string true "Provider name"
+// @Param body body models.ReconfigureProviderRequest true "New configuration"
+// @Param field_manager query string false "Actor recorded in the audit log (defaults to X-Field-Manager header, then api)"
+// @Success 200 {object} models.ProviderResponse "Provider state"
+// @Failure 400 {object} models.ErrorResponse "Invalid request or configuration"
+// @Failure 404 {object} models.ErrorResponse "Provider not found"
+// @Failure 409 {object} models.ErrorResponse "Provider does not support reconfiguration"
+// @Failure 503 {object} models.ErrorResponse "Provider administration not configured"
+// @Router /v1/admin/providers/{kind}/{name}/config [put]
+func (s *Server) handleReconfigureProvider(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	kind, name, ok := s.providerFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	var req models.ReconfigureProviderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid request body", []string{err.Error()}, requestID)
+		return
+	}
+	if req.Config == nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "config is required", nil, requestID)
+		return
+	}
+
+	status, err := s.providerAdmin.Reconfigure(r.Context(), kind, name, req.Config, fieldManager(r), requestID)
+	if err != nil {
+		s.writeProviderAdminError(w, r, err, requestID)
+		return
+	}
+	s.writeProvider(w, status)
+}
+
+// handleListProviderAudit returns the runtime change history of a provider
+// @Summary Provider audit log
+// @Tags admin
+// @Produce json
+// @Param kind path string true "Provider kind (compute or workflow)"
+// @Param name path string true "Provider name"
+// @Success 200 {object} models.ListProviderAuditResponse "Changes, newest first"
+// @Failure 400 {object} models.ErrorResponse "Invalid provider kind"
+// @Failure 404 {object} models.ErrorResponse "Provider not found"
+// @Failure 503 {object} models.ErrorResponse "Provider administration not configured"
+// @Router /v1/admin/providers/{kind}/{name}/audit [get]
+func (s *Server) handleListProviderAudit(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	kind, name, ok := s.providerFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	entries, err := s.providerAdmin.Audit(r.Context(), kind, name)
+	if err != nil {
+		s.writeProviderAdminError(w, r, err, requestID)
+		return
+	}
+
+	resp := models.ListProviderAuditResponse{Entries: make([]models.ProviderAuditEntry, 0, len(entries))}
+	for _, entry := range entries {
+		resp.Entries = append(resp.Entries, models.ProviderAuditEntry{
+			ID:        entry.ID.String(),
+			Action:    entry.Action,
+			Actor:     entry.Actor,
+			RequestID: entry.RequestID,
+			Enabled:   entry.Enabled,
+			Config:    entry.Config,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// requireProviderAdmin writes a 503 when provider administration is not configured
+func (s *Server) requireProviderAdmin(w http.ResponseWriter, r *http.Request, requestID string) bool {
+	if !project.PrincipalFromContext(r.Context()).Unrestricted() {
+		s.writeErrorResponse(w, r, http.StatusForbidden, "Provider administration requires an admin API key", nil, requestID)
+		return false
+	}
+	if s.providerAdmin == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Provider administration not configured", nil, requestID)
+		return false
+	}
+	return true
+}
+
+// providerFromPath reads the provider kind and name from the URL
+func (s *Server) providerFromPath(w http.ResponseWriter, r *http.Request, requestID string) (providerconfig.Kind, string, bool) {
+	if !s.requireProviderAdmin(w, r, requestID) {
+		return "", "", false
+	}
+	kind, err := providerconfig.ParseKind(chi.URLParam(r, "kind"))
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid provider kind", []string{err.Error()}, requestID)
+		return "", "", false
+	}
+	return kind, chi.URLParam(r, "name"), true
+}
+
+func (s *Server) writeProvider(w http.ResponseWriter, status *providerconfig.Status) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toProviderResponse(status))
+}
+
+// writeProviderAdminError writes the response for a provider administration failure
+func (s *Server) writeProviderAdminError(w http.ResponseWriter, r *http.Request, err error, requestID string) {
+	switch {
+	case errors.Is(err, providerconfig.ErrProviderNotFound):
+		s.writeError(w, r, http.StatusNotFound, models.ErrorCodeProviderNotFound, "Provider not found", []string{err.Error()}, requestID)
+	case errors.Is(err, providerconfig.ErrReconfigureUnsupported):
+		s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Provider does not support reconfiguration", []string{err.Error()}, requestID)
+	case errors.Is(err, providerconfig.ErrInvalidConfig):
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid provider configuration", []string{err.Error()}, requestID)
+	default:
+		s.logger.Error("provider administration failed", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to update provider", []string{err.Error()}, requestID)
+	}
+}
+
+func toProviderResponse(status *providerconfig.Status) models.ProviderResponse {
+	return models.ProviderResponse{
+		Kind:           string(status.Kind),
+		Name:           status.Name,
+		Enabled:        status.Enabled,
+		Reconfigurable: status.Reconfigurable,
+		Config:         status.Config,
+		UpdatedBy:      status.UpdatedBy,
+		UpdatedAt:      status.UpdatedAt,
+	}
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/providerconfig"
+	"github.com/jaxxstorm/landlord/internal/providerconfig/memory"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+func newAdminTestServer() (*Server, *compute.Registry) {
+	computeRegistry := newTestComputeRegistry()
+	srv := &Server{
+		router:          chi.NewRouter(),
+		logger:          zap.NewNop(),
+		computeRegistry: computeRegistry,
+	}
+	srv.SetProviderAdmin(providerconfig.NewManager(computeRegistry, workflow.NewRegistry(zap.NewNop()), memory.New(), zap.NewNop()))
+	srv.registerRoutes()
+	return srv, computeRegistry
+}
+
+func serveAdmin(srv *Server, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Field-Manager", "ops")
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminDisableAndEnableProvider(t *testing.T) {
+	srv, registry := newAdminTestServer()
+
+	rec := serveAdmin(srv, http.MethodPost, "/v1/admin/providers/compute/mock/disable", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var provider models.ProviderResponse
+	if err := json.NewDecoder(rec.Body).Decode(&provider); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if provider.Enabled || provider.UpdatedBy != "ops" {
+		t.Errorf("unexpected provider %+v", provider)
+	}
+	if registry.Enabled("mock") {
+		t.Error("expected provider to be disabled in the registry")
+	}
+
+	rec = serveAdmin(srv, http.MethodPost, "/v1/admin/providers/compute/mock/enable", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveAdmin(srv, http.MethodGet, "/v1/admin/providers/compute/mock/audit", "")
+	var audit models.ListProviderAuditResponse
+	if err := json.NewDecoder(rec.Body).Decode(&audit); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(audit.Entries) != 2 || audit.Entries[0].Action != providerconfig.ActionEnable || audit.Entries[1].Action != providerconfig.ActionDisable {
+		t.Errorf("unexpected audit log %+v", audit.Entries)
+	}
+}
+
+func TestAdminListProviders(t *testing.T) {
+	srv, _ := newAdminTestServer()
+
+	rec := serveAdmin(srv, http.MethodGet, "/v1/admin/providers", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var resp models.ListProvidersResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Providers) != 1 || resp.Providers[0].Name != "mock" || !resp.Providers[0].Enabled || !resp.Providers[0].Reconfigurable {
+		t.Errorf("unexpected providers %+v", resp.Providers)
+	}
+}
+
+func TestAdminProviderErrors(t *testing.T) {
+	srv, _ := newAdminTestServer()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		wantCode   models.ErrorCode
+	}{
+		{"invalid kind", http.MethodGet, "/v1/admin/providers/storage/mock", "", http.StatusBadRequest, models.ErrorCodeInvalidRequest},
+		{"unknown provider", http.MethodPost, "/v1/admin/providers/compute/missing/disable", "", http.StatusNotFound, models.ErrorCodeProviderNotFound},
+		{"missing config", http.MethodPut, "/v1/admin/providers/compute/mock/config", `{}`, http.StatusBadRequest, models.ErrorCodeInvalidRequest},
+		{"rejected config", http.MethodPut, "/v1/admin/providers/compute/mock/config", `{"config":{"min_latency":"soon"}}`, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveAdmin(srv, tt.method, tt.path, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			var problem models.ProblemDetails
+			json.NewDecoder(rec.Body).Decode(&problem)
+			if problem.ErrorCode != tt.wantCode {
+				t.Errorf("expected code %s, got %s", tt.wantCode, problem.ErrorCode)
+			}
+		})
+	}
+}
+
+func TestAdminNotConfigured(t *testing.T) {
+	srv := newVersioningTestServer()
+
+	rec := serveAdmin(srv, http.MethodGet, "/v1/admin/providers", "")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", rec.Code)
+	}
+}
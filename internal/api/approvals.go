@@ -0,0 +1,391 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/approval"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// SetApprovals makes archive and delete of tenants protected by policy wait for a second principal
+func (s *Server) SetApprovals(store approval.Store, policy *approval.Policy) {
+	s.approvals = store
+	s.approvalPolicy = policy
+}
+
+// approvalActor identifies who requests or decides an approval. With API keys the key name is
+// used, so a caller cannot approve its own request by sending a different X-Field-Manager.
+func approvalActor(r *http.Request) string {
+	if principal := project.PrincipalFromContext(r.Context()); principal != nil {
+		return principal.Name
+	}
+	return fieldManager(r)
+}
+
+// requestApproval records a pending approval when op on t needs one, responding 202 with it.
+// A pending approval for the same operation is reused rather than duplicated. Returns true
+// when it wrote the response, either the approval or an error, and the caller must stop.
+func (s *Server) requestApproval(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, op approval.Operation, requestID string) bool {
+	if s.approvals == nil || !s.approvalPolicy.Required(t, op) {
+		return false
+	}
+	ctx := r.Context()
+
+	pending, err := s.approvals.List(ctx, approval.ListFilters{TenantID: &t.ID, Status: approval.StatusPending})
+	if err != nil {
+		s.logger.Error("failed to list approvals", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to check pending approvals", nil, requestID)
+		return true
+	}
+	var a *approval.Approval
+	for _, candidate := range pending {
+		if candidate.Operation == op {
+			a = candidate
+			break
+		}
+	}
+
+	if a == nil {
+		a = &approval.Approval{
+			TenantID:    t.ID,
+			TenantName:  t.Name,
+			Operation:   op,
+			Status:      approval.StatusPending,
+			RequestedBy: approvalActor(r),
+		}
+		if err := s.approvals.Create(ctx, a); err != nil {
+			s.logger.Error("failed to create approval", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to request approval", nil, requestID)
+			return true
+		}
+		s.logger.Info("tenant operation awaiting approval",
+			zap.String("tenant_id", t.ID.String()),
+			zap.String("operation", string(op)),
+			zap.String("approval_id", a.ID.String()),
+			zap.String("requested_by", a.RequestedBy),
+			zap.String("request_id", requestID))
+	}
+
+	w.Header().Set("Location", "/v1/approvals/"+a.ID.String())
+	s.writeApproval(w, http.StatusAccepted, a)
+	return true
+}
+
+// handleListApprovals lists approvals for tenants the caller can see
+// @Summary List approvals
+// @Description Lists archive and delete requests for protected tenants, newest first.
+// @Tags approvals
+// @Produce json
+// @Param status query string false "Filter by status (pending, approved, rejected)"
+// @Param tenant query string false "Filter by tenant (UUID or name)"
+// @Success 200 {object} models.ListApprovalsResponse "Approvals"
+// @Failure 400 {object} models.ErrorResponse "Invalid status filter"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Approvals not configured"
+// @Router /v1/approvals [get]
+func (s *Server) handleListApprovals(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireApprovals(w, r, requestID) {
+		return
+	}
+
+	var filters approval.ListFilters
+	if status := strings.TrimSpace(r.URL.Query().Get("status")); status != "" {
+		filters.Status = approval.Status(status)
+		switch filters.Status {
+		case approval.StatusPending, approval.StatusApproved, approval.StatusRejected:
+		default:
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid status filter",
+				[]string{fmt.Sprintf("status must be %s, %s or %s", approval.StatusPending, approval.StatusApproved, approval.StatusRejected)}, requestID)
+			return
+		}
+	}
+	if identifier := strings.TrimSpace(r.URL.Query().Get("tenant")); identifier != "" {
+		t, err := s.lookupTenant(ctx, identifier)
+		if err != nil {
+			if errors.Is(err, tenant.ErrTenantNotFound) {
+				s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant not found", nil, requestID)
+				return
+			}
+			s.logger.Error("failed to get tenant", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+			return
+		}
+		filters.TenantID = &t.ID
+	}
+
+	approvals, err := s.approvals.List(ctx, filters)
+	if err != nil {
+		s.logger.Error("failed to list approvals", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list approvals", nil, requestID)
+		return
+	}
+
+	resp := models.ListApprovalsResponse{Approvals: []models.ApprovalResponse{}}
+	inScope := map[uuid.UUID]bool{}
+	for _, a := range approvals {
+		visible, seen := inScope[a.TenantID]
+		if !seen {
+			_, err := s.lookupTenant(ctx, a.TenantID.String())
+			if err != nil && !errors.Is(err, tenant.ErrTenantNotFound) {
+				s.logger.Error("failed to get tenant", zap.Error(err), zap.String("request_id", requestID))
+				s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list approvals", nil, requestID)
+				return
+			}
+			visible = err == nil
+			inScope[a.TenantID] = visible
+		}
+		if visible {
+			resp.Approvals = append(resp.Approvals, models.ToApprovalResponse(a))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleGetApproval returns one approval
+// @Summary Get an approval
+// @Tags approvals
+// @Produce json
+// @Param id path string true "Approval ID"
+// @Success 200 {object} models.ApprovalResponse "Approval"
+// @Failure 400 {object} models.ErrorResponse "Invalid approval ID"
+// @Failure 404 {object} models.ErrorResponse "Approval not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Approvals not configured"
+// @Router /v1/approvals/{id} [get]
+func (s *Server) handleGetApproval(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireApprovals(w, r, requestID) {
+		return
+	}
+
+	a, _, ok := s.approvalFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	s.writeApproval(w, http.StatusOK, a)
+}
+
+// handleApproveApproval runs the approved operation on the tenant
+// @Summary Approve a pending operation
+// @Description Archives or deletes the tenant as requested. The approver must be a different principal than the requester.
+// @Description The response is the tenant, as returned by the archive or delete endpoint.
+// @Tags approvals
+// @Produce json
+// @Param id path string true "Approval ID"
+// @Success 202 {object} models.TenantResponse "Operation started"
+// @Failure 400 {object} models.ErrorResponse "Invalid approval ID"
+// @Failure 403 {object} models.ErrorResponse "The requester cannot approve their own request"
+// @Failure 404 {object} models.ErrorResponse "Approval not found"
+// @Failure 409 {object} models.ErrorResponse "Approval already decided, or the tenant cannot be archived or deleted"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Approvals not configured"
+// @Router /v1/approvals/{id}/approve [post]
+func (s *Server) handleApproveApproval(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireApprovals(w, r, requestID) {
+		return
+	}
+
+	a, t, ok := s.approvalFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	actor := approvalActor(r)
+	if !s.approvalDecidable(w, r, a, actor, true, requestID) {
+		return
+	}
+
+	t, release, ok := s.lockTenantForDecision(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	// Another approver may have decided while this request waited for the lock
+	a, err := s.approvals.Get(ctx, a.ID)
+	if err != nil {
+		s.logger.Error("failed to get approval", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve approval", nil, requestID)
+		return
+	}
+	if !s.approvalDecidable(w, r, a, actor, true, requestID) {
+		return
+	}
+
+	var done bool
+	switch a.Operation {
+	case approval.OperationArchive:
+		done = s.archiveTenant(w, r, t, requestID, false)
+	case approval.OperationDelete:
+		done = s.deleteTenant(w, r, t, requestID, false)
+	default:
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Unknown approval operation", []string{string(a.Operation)}, requestID)
+		return
+	}
+	if !done {
+		return
+	}
+
+	// The operation has started, so a failure to record the decision must not fail the request
+	if err := a.Approve(actor, time.Now()); err != nil {
+		s.logger.Error("failed to approve approval", zap.Error(err), zap.String("request_id", requestID))
+		return
+	}
+	if err := s.approvals.Decide(ctx, a); err != nil {
+		s.logger.Error("failed to record approval", zap.Error(err), zap.String("approval_id", a.ID.String()), zap.String("request_id", requestID))
+		return
+	}
+	s.logger.Info("tenant operation approved",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("operation", string(a.Operation)),
+		zap.String("approval_id", a.ID.String()),
+		zap.String("requested_by", a.RequestedBy),
+		zap.String("approved_by", actor),
+		zap.String("request_id", requestID))
+}
+
+// handleRejectApproval discards a pending operation
+// @Summary Reject a pending operation
+// @Description Leaves the tenant unchanged. The requester may reject their own request to withdraw it.
+// @Tags approvals
+// @Accept json
+// @Produce json
+// @Param id path string true "Approval ID"
+// @Param body body models.RejectApprovalRequest false "Rejection reason"
+// @Success 200 {object} models.ApprovalResponse "Approval rejected"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Approval not found"
+// @Failure 409 {object} models.ErrorResponse "Approval already decided"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Approvals not configured"
+// @Router /v1/approvals/{id}/reject [post]
+func (s *Server) handleRejectApproval(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireApprovals(w, r, requestID) {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	var req models.RejectApprovalRequest
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+			return
+		}
+	}
+
+	a, _, ok := s.approvalFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	actor := approvalActor(r)
+	if !s.approvalDecidable(w, r, a, actor, false, requestID) {
+		return
+	}
+
+	a.Reject(actor, strings.TrimSpace(req.Reason), time.Now())
+	if err := s.approvals.Decide(ctx, a); err != nil {
+		if errors.Is(err, approval.ErrNotPending) {
+			s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Approval has already been decided", nil, requestID)
+			return
+		}
+		s.logger.Error("failed to record rejection", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to reject approval", nil, requestID)
+		return
+	}
+
+	s.logger.Info("tenant operation rejected",
+		zap.String("tenant_id", a.TenantID.String()),
+		zap.String("operation", string(a.Operation)),
+		zap.String("approval_id", a.ID.String()),
+		zap.String("rejected_by", actor),
+		zap.String("request_id", requestID))
+
+	s.writeApproval(w, http.StatusOK, a)
+}
+
+// requireApprovals writes a 503 when no approval store is configured
+func (s *Server) requireApprovals(w http.ResponseWriter, r *http.Request, requestID string) bool {
+	if s.approvals == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Approvals not configured", nil, requestID)
+		return false
+	}
+	return true
+}
+
+// approvalFromPath looks up the approval named by the id path parameter and its tenant. Approvals
+// for tenants outside the caller's scope are reported as not found.
+func (s *Server) approvalFromPath(w http.ResponseWriter, r *http.Request, requestID string) (*approval.Approval, *tenant.Tenant, bool) {
+	ctx := r.Context()
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "invalid approval identifier format", []string{err.Error()}, requestID)
+		return nil, nil, false
+	}
+
+	a, err := s.approvals.Get(ctx, id)
+	if err == nil {
+		var t *tenant.Tenant
+		t, err = s.lookupTenant(ctx, a.TenantID.String())
+		if err == nil {
+			return a, t, true
+		}
+	}
+	if errors.Is(err, approval.ErrNotFound) || errors.Is(err, tenant.ErrTenantNotFound) {
+		s.writeErrorResponse(w, r, http.StatusNotFound, "Approval not found", nil, requestID)
+		return nil, nil, false
+	}
+	s.logger.Error("failed to get approval", zap.Error(err), zap.String("request_id", requestID))
+	s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve approval", nil, requestID)
+	return nil, nil, false
+}
+
+// approvalDecidable writes the error response when a is no longer pending, or when approving
+// and actor is the principal that requested it
+func (s *Server) approvalDecidable(w http.ResponseWriter, r *http.Request, a *approval.Approval, actor string, approving bool, requestID string) bool {
+	if a.Status != approval.StatusPending {
+		s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Approval has already been decided",
+			[]string{fmt.Sprintf("approval was %s by %s", a.Status, a.DecidedBy)}, requestID)
+		return false
+	}
+	if approving && actor == a.RequestedBy {
+		s.writeError(w, r, http.StatusForbidden, models.ErrorCodeForbidden, "Approval must come from a different principal",
+			[]string{fmt.Sprintf("%s requested this %s", a.RequestedBy, a.Operation)}, requestID)
+		return false
+	}
+	return true
+}
+
+func (s *Server) writeApproval(w http.ResponseWriter, status int, a *approval.Approval) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.ToApprovalResponse(a))
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/approval"
+	approvalmemory "github.com/jaxxstorm/landlord/internal/approval/memory"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func newApprovalServer(t *testing.T) (*Server, *tenantmemory.Repository) {
+	t.Helper()
+	repo := tenantmemory.New()
+	tenants := []*tenant.Tenant{
+		{Name: "billing", Status: tenant.StatusReady, Labels: map[string]string{"protected": "true"}},
+		{Name: "web-prod", Status: tenant.StatusReady, Labels: map[string]string{"env": "prod"}},
+		{Name: "scratch", Status: tenant.StatusReady},
+	}
+	for _, tn := range tenants {
+		if err := repo.CreateTenant(context.Background(), tn); err != nil {
+			t.Fatalf("create tenant %s: %v", tn.Name, err)
+		}
+	}
+
+	srv := &Server{
+		router:         chi.NewRouter(),
+		logger:         zap.NewNop(),
+		tenantRepo:     repo,
+		workflowClient: &mockWorkflowClient{},
+	}
+	srv.SetApprovals(approvalmemory.New(), approval.NewPolicy(config.ApprovalConfig{
+		Enabled:  true,
+		Selector: map[string]string{"env": "prod"},
+	}))
+	srv.registerRoutes()
+	return srv, repo
+}
+
+func doApprovalRequest(srv *Server, method, path, actor, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Field-Manager", actor)
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeApproval(t *testing.T, rec *httptest.ResponseRecorder) models.ApprovalResponse {
+	t.Helper()
+	var resp models.ApprovalResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode approval: %v", err)
+	}
+	return resp
+}
+
+func TestArchiveProtectedTenantRequiresApproval(t *testing.T) {
+	ctx := context.Background()
+	srv, repo := newApprovalServer(t)
+
+	rec := doApprovalRequest(srv, http.MethodPost, "/v1/tenants/billing/archive", "alice", "")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("archive: expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	pending := decodeApproval(t, rec)
+	if pending.Status != string(approval.StatusPending) || pending.Operation != string(approval.OperationArchive) || pending.RequestedBy != "alice" {
+		t.Fatalf("unexpected approval: %+v", pending)
+	}
+	if got := rec.Header().Get("Location"); got != "/v1/approvals/"+pending.ID {
+		t.Fatalf("unexpected Location %q", got)
+	}
+	billing, err := repo.GetTenantByName(ctx, "billing")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if billing.Status != tenant.StatusReady {
+		t.Fatalf("tenant changed before approval: %s", billing.Status)
+	}
+
+	// Repeating the request returns the same pending approval
+	rec = doApprovalRequest(srv, http.MethodPost, "/v1/tenants/billing/archive", "alice", "")
+	if again := decodeApproval(t, rec); again.ID != pending.ID {
+		t.Fatalf("expected the pending approval to be reused, got %s and %s", pending.ID, again.ID)
+	}
+
+	rec = doApprovalRequest(srv, http.MethodPost, "/v1/approvals/"+pending.ID+"/approve", "alice", "")
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("self-approve: expected status 403, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doApprovalRequest(srv, http.MethodPost, "/v1/approvals/"+pending.ID+"/approve", "bob", "")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("approve: expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	billing, err = repo.GetTenantByName(ctx, "billing")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if billing.Status != tenant.StatusArchiving {
+		t.Fatalf("expected archiving after approval, got %s", billing.Status)
+	}
+
+	rec = doApprovalRequest(srv, http.MethodGet, "/v1/approvals/"+pending.ID, "carol", "")
+	if decided := decodeApproval(t, rec); decided.Status != string(approval.StatusApproved) || decided.DecidedBy != "bob" {
+		t.Fatalf("unexpected decided approval: %+v", decided)
+	}
+	rec = doApprovalRequest(srv, http.MethodPost, "/v1/approvals/"+pending.ID+"/approve", "carol", "")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("second approve: expected status 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRejectDeleteApproval(t *testing.T) {
+	ctx := context.Background()
+	srv, repo := newApprovalServer(t)
+
+	rec := doApprovalRequest(srv, http.MethodDelete, "/v1/tenants/web-prod", "alice", "")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("delete: expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	pending := decodeApproval(t, rec)
+	if pending.Operation != string(approval.OperationDelete) {
+		t.Fatalf("expected a delete approval, got %+v", pending)
+	}
+
+	rec = doApprovalRequest(srv, http.MethodPost, "/v1/approvals/"+pending.ID+"/reject", "bob", `{"reason": "still serving traffic"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reject: expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rejected := decodeApproval(t, rec); rejected.Status != string(approval.StatusRejected) || rejected.Reason != "still serving traffic" {
+		t.Fatalf("unexpected rejection: %+v", rejected)
+	}
+	prod, err := repo.GetTenantByName(ctx, "web-prod")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if prod.Status != tenant.StatusReady {
+		t.Fatalf("rejected delete changed the tenant: %s", prod.Status)
+	}
+
+	rec = doApprovalRequest(srv, http.MethodGet, "/v1/approvals?status=pending", "bob", "")
+	var list models.ListApprovalsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(list.Approvals) != 0 {
+		t.Fatalf("expected no pending approvals, got %+v", list.Approvals)
+	}
+}
+
+func TestUnprotectedTenantSkipsApproval(t *testing.T) {
+	srv, _ := newApprovalServer(t)
+
+	rec := doApprovalRequest(srv, http.MethodPost, "/v1/tenants/scratch/archive", "alice", "")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("archive: expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp models.TenantResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Status != string(tenant.StatusArchiving) {
+		t.Fatalf("expected archiving without approval, got %s", resp.Status)
+	}
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/project"
+)
+
+// apiKey is a configured API key and the principal it authenticates
+type apiKey struct {
+	key       []byte
+	principal *project.Principal
+}
+
+// SetAPIKeys enables API key authentication. With no keys configured every request is
+// served unauthenticated and unrestricted.
+func (s *Server) SetAPIKeys(cfg config.AuthConfig) {
+	s.apiKeys = make([]apiKey, 0, len(cfg.APIKeys))
+	for _, key := range cfg.APIKeys {
+		s.apiKeys = append(s.apiKeys, apiKey{
+			key: []byte(key.Key),
+			principal: &project.Principal{
+				Name:         key.Name,
+				Admin:        key.Admin,
+				Organization: key.Organization,
+				Projects:     append([]string(nil), key.Projects...),
+			},
+		})
+	}
+}
+
+// authenticate resolves the request's API key to a principal and stores it in the request context
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(s.apiKeys) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		requestID := r.Header.Get("X-Request-ID")
+		presented := requestAPIKey(r)
+		if presented == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="landlord"`)
+			s.writeErrorResponse(w, r, http.StatusUnauthorized, "API key required", nil, requestID)
+			return
+		}
+
+		principal := s.principalForKey(presented)
+		if principal == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="landlord", error="invalid_token"`)
+			s.writeErrorResponse(w, r, http.StatusUnauthorized, "Invalid API key", nil, requestID)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(project.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// principalForKey compares against every key so lookup time does not depend on which key matched
+func (s *Server) principalForKey(presented string) *project.Principal {
+	var found *project.Principal
+	for _, key := range s.apiKeys {
+		if subtle.ConstantTimeCompare(key.key, []byte(presented)) == 1 {
+			found = key.principal
+		}
+	}
+	return found
+}
+
+// requestAPIKey reads the key from an Authorization bearer token or the X-API-Key header
+func requestAPIKey(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		scheme, token, ok := strings.Cut(auth, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
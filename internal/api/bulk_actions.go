@@ -0,0 +1,291 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/operation"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// maxBulkTenants is the most tenants one bulk operation may act on
+const maxBulkTenants = 1000
+
+// SetOperations enables bulk tenant actions, recording their progress in store
+func (s *Server) SetOperations(store operation.Store) {
+	s.operations = store
+}
+
+// handleBulkAction runs an action on every tenant matching a label selector
+// @Summary Run an action on tenants by label selector
+// @Description Archives, suspends, resumes, restarts or retries every tenant whose labels match the selector, within the caller's project scope. Archived tenants are not selected.
+// @Description The action runs in the background, one tenant at a time, exactly as the single-tenant endpoint would, so approvals and state checks still apply. Poll the returned operation for per-tenant outcomes.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param body body models.BulkActionRequest true "Action and selector"
+// @Success 202 {object} models.OperationResponse "Operation started"
+// @Failure 400 {object} models.ErrorResponse "Invalid request, or the selector matches too many tenants"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Bulk operations not configured"
+// @Router /v1/tenants:bulk-action [post]
+func (s *Server) handleBulkAction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireOperations(w, r, requestID) {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	var req models.BulkActionRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return
+	}
+	action := operation.Action(req.Action)
+	if !action.IsValid() {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Invalid action",
+			[]string{"action must be archive, suspend, resume, restart or retry"}, requestID)
+		return
+	}
+	if len(req.Selector) == 0 {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Selector is required",
+			[]string{"selector must match at least one label, so a bulk action cannot reach every tenant by accident"}, requestID)
+		return
+	}
+	if req.Component != "" && action != operation.ActionRestart {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Component is only valid for restart", nil, requestID)
+		return
+	}
+
+	projectIDs, err := s.scopedProjectIDs(ctx, strings.TrimSpace(req.Organization), strings.TrimSpace(req.Project))
+	if err != nil {
+		s.logger.Error("failed to resolve project scope", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to select tenants", nil, requestID)
+		return
+	}
+	var summaries []*tenant.Summary
+	if projectIDs == nil || len(projectIDs) > 0 {
+		summaries, err = s.tenantRepo.ListTenantSummaries(ctx, tenant.ListFilters{
+			Labels:     req.Selector,
+			ProjectIDs: projectIDs,
+			Limit:      maxBulkTenants + 1,
+		})
+		if err != nil {
+			s.logger.Error("failed to select tenants", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to select tenants", nil, requestID)
+			return
+		}
+	}
+	if len(summaries) > maxBulkTenants {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Selector matches too many tenants",
+			[]string{fmt.Sprintf("a bulk operation acts on at most %d tenants; narrow the selector", maxBulkTenants)}, requestID)
+		return
+	}
+
+	o := &operation.Operation{
+		Action:      action,
+		Selector:    req.Selector,
+		Component:   req.Component,
+		Status:      operation.StatusRunning,
+		RequestedBy: approvalActor(r),
+		Tenants:     make([]operation.TenantResult, 0, len(summaries)),
+	}
+	for _, summary := range summaries {
+		o.Tenants = append(o.Tenants, operation.TenantResult{TenantID: summary.ID, TenantName: summary.Name, Status: operation.TenantPending})
+	}
+	if len(o.Tenants) == 0 {
+		o.Complete(time.Now())
+	}
+	if err := s.operations.Create(ctx, o); err != nil {
+		s.logger.Error("failed to create operation", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to start bulk operation", nil, requestID)
+		return
+	}
+
+	s.logger.Info("bulk operation started",
+		zap.String("operation_id", o.ID.String()),
+		zap.String("action", string(o.Action)),
+		zap.Int("tenants", len(o.Tenants)),
+		zap.String("requested_by", o.RequestedBy),
+		zap.String("request_id", requestID))
+
+	// The response is taken before the operation starts changing o
+	resp := models.ToOperationResponse(o)
+	if o.Status == operation.StatusRunning {
+		s.bulkOperations.Add(1)
+		go func() {
+			defer s.bulkOperations.Done()
+			s.runBulkOperation(context.WithoutCancel(ctx), r, o, requestID)
+		}()
+	}
+
+	w.Header().Set("Location", "/v1/operations/"+o.ID.String())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// runBulkOperation runs o's action on each of its tenants in turn, saving progress after each.
+// ctx keeps the caller's principal, so every tenant is acted on with the caller's scope.
+func (s *Server) runBulkOperation(ctx context.Context, r *http.Request, o *operation.Operation, requestID string) {
+	handler := s.bulkActionHandler(o.Action)
+	for i, result := range o.Tenants {
+		if s.shuttingDown.Load() {
+			o.Record(i, operation.TenantFailed, "API server shut down before the action ran")
+			continue
+		}
+
+		rec := httptest.NewRecorder()
+		handler(rec, s.bulkTenantRequest(ctx, r, o, result.TenantID))
+		status, message := bulkOutcome(rec)
+		o.Record(i, status, message)
+
+		if err := s.operations.Update(ctx, o); err != nil {
+			s.logger.Warn("failed to save operation progress", zap.Error(err),
+				zap.String("operation_id", o.ID.String()), zap.String("request_id", requestID))
+		}
+	}
+
+	o.Complete(time.Now())
+	if err := s.operations.Update(ctx, o); err != nil {
+		s.logger.Error("failed to complete operation", zap.Error(err),
+			zap.String("operation_id", o.ID.String()), zap.String("request_id", requestID))
+		return
+	}
+	s.logger.Info("bulk operation completed",
+		zap.String("operation_id", o.ID.String()),
+		zap.String("status", string(o.Status)),
+		zap.Int("succeeded", o.Succeeded),
+		zap.Int("failed", o.Failed),
+		zap.Int("pending_approval", o.PendingApproval),
+		zap.String("request_id", requestID))
+}
+
+// bulkActionHandler returns the single-tenant endpoint that performs action
+func (s *Server) bulkActionHandler(action operation.Action) http.HandlerFunc {
+	switch action {
+	case operation.ActionArchive:
+		return s.handleArchiveTenant
+	case operation.ActionSuspend:
+		return s.handleSuspendTenant
+	case operation.ActionResume:
+		return s.handleResumeTenant
+	case operation.ActionRestart:
+		return s.handleRestartTenant
+	default:
+		return s.handleRetryTenant
+	}
+}
+
+// bulkTenantRequest builds the single-tenant request for one of o's tenants from the bulk request r
+func (s *Server) bulkTenantRequest(ctx context.Context, r *http.Request, o *operation.Operation, tenantID uuid.UUID) *http.Request {
+	var body []byte
+	if o.Component != "" {
+		body, _ = json.Marshal(models.RestartTenantRequest{Component: o.Component})
+	}
+
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", tenantID.String())
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, routeCtx)
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "/v1/tenants/"+tenantID.String()+"/"+string(o.Action), bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", models.ProblemContentType)
+	req.Header.Set("X-Request-ID", r.Header.Get("X-Request-ID"))
+	req.Header.Set("X-Field-Manager", fieldManager(r))
+	return req
+}
+
+// bulkOutcome reads a tenant's outcome from the single-tenant endpoint's response
+func bulkOutcome(rec *httptest.ResponseRecorder) (operation.TenantStatus, string) {
+	if rec.Code >= 200 && rec.Code < 300 {
+		if location := rec.Header().Get("Location"); strings.HasPrefix(location, "/v1/approvals/") {
+			return operation.TenantPendingApproval, "Awaiting approval at " + location
+		}
+		return operation.TenantSucceeded, ""
+	}
+
+	var problem models.ProblemDetails
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil || problem.Detail == "" {
+		return operation.TenantFailed, http.StatusText(rec.Code)
+	}
+	message := problem.Detail
+	if len(problem.Errors) > 0 {
+		message += ": " + strings.Join(problem.Errors, "; ")
+	}
+	return operation.TenantFailed, message
+}
+
+// handleGetOperation returns a bulk operation's progress
+// @Summary Get a bulk operation
+// @Description Returns the outcome so far for each tenant of a bulk action. Callers that are limited to an organization only see the operations they started.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Operation ID"
+// @Success 200 {object} models.OperationResponse "Operation"
+// @Failure 400 {object} models.ErrorResponse "Invalid operation ID"
+// @Failure 404 {object} models.ErrorResponse "Operation not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Bulk operations not configured"
+// @Router /v1/operations/{id} [get]
+func (s *Server) handleGetOperation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireOperations(w, r, requestID) {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "invalid operation identifier format", []string{err.Error()}, requestID)
+		return
+	}
+
+	o, err := s.operations.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, operation.ErrNotFound) {
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Operation not found", nil, requestID)
+			return
+		}
+		s.logger.Error("failed to get operation", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve operation", nil, requestID)
+		return
+	}
+	if principal := project.PrincipalFromContext(ctx); !principal.Unrestricted() && principal.Name != o.RequestedBy {
+		s.writeErrorResponse(w, r, http.StatusNotFound, "Operation not found", nil, requestID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ToOperationResponse(o))
+}
+
+func (s *Server) requireOperations(w http.ResponseWriter, r *http.Request, requestID string) bool {
+	if s.operations == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Bulk operations not configured", nil, requestID)
+		return false
+	}
+	return true
+}
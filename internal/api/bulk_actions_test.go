@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	operationmemory "github.com/jaxxstorm/landlord/internal/operation/memory"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestBulkAction(t *testing.T) {
+	repo := tenantmemory.New()
+	ctx := context.Background()
+	for _, tn := range []*tenant.Tenant{
+		{Name: "failed-a", Status: tenant.StatusFailed, Labels: map[string]string{"team": "payments"}},
+		{Name: "failed-b", Status: tenant.StatusFailed, Labels: map[string]string{"team": "payments"}},
+		{Name: "ready", Status: tenant.StatusReady, Labels: map[string]string{"team": "payments"}},
+		{Name: "other-team", Status: tenant.StatusFailed, Labels: map[string]string{"team": "search"}},
+	} {
+		if err := repo.CreateTenant(ctx, tn); err != nil {
+			t.Fatalf("create tenant: %v", err)
+		}
+	}
+
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo}
+	srv.registerRoutes()
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tenants:bulk-action", strings.NewReader(body)))
+		return rec
+	}
+	if rec := post(`{"action":"retry","selector":{"team":"payments"}}`); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without an operation store, got %d", rec.Code)
+	}
+
+	srv.SetOperations(operationmemory.New())
+	for _, body := range []string{
+		`{"action":"retry"}`,
+		`{"action":"delete","selector":{"team":"payments"}}`,
+		`{"action":"retry","selector":{"team":"payments"},"component":"worker"}`,
+	} {
+		if rec := post(body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, rec.Code)
+		}
+	}
+
+	rec := post(`{"action":"retry","selector":{"team":"payments"}}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var started models.OperationResponse
+	if err := json.NewDecoder(rec.Body).Decode(&started); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if started.Total != 3 || rec.Header().Get("Location") != "/v1/operations/"+started.ID {
+		t.Fatalf("expected 3 tenants selected, got %+v (Location %q)", started, rec.Header().Get("Location"))
+	}
+
+	var op models.OperationResponse
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/operations/"+started.ID, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&op); err != nil {
+			t.Fatalf("decode operation: %v", err)
+		}
+		if op.CompletedAt != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("operation did not complete: %+v", op)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if op.Status != "failed" || op.Succeeded != 2 || op.Failed != 1 {
+		t.Fatalf("expected 2 retries and 1 failure, got %+v", op)
+	}
+	for _, result := range op.Tenants {
+		switch result.TenantName {
+		case "ready":
+			if result.Status != "failed" || !strings.Contains(result.Message, "Only failed tenants can be retried") {
+				t.Errorf("expected the ready tenant to fail with the retry error, got %+v", result)
+			}
+		default:
+			if result.Status != "succeeded" {
+				t.Errorf("expected %s retried, got %+v", result.TenantName, result)
+			}
+			updated, err := repo.GetTenantByName(ctx, result.TenantName)
+			if err != nil {
+				t.Fatalf("get tenant: %v", err)
+			}
+			if updated.Status != tenant.StatusProvisioning {
+				t.Errorf("expected %s provisioning, got %s", result.TenantName, updated.Status)
+			}
+		}
+	}
+	if other, _ := repo.GetTenantByName(ctx, "other-team"); other.Status != tenant.StatusFailed {
+		t.Errorf("expected the unselected tenant untouched, got %s", other.Status)
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/jaxxstorm/landlord/internal/capture"
+)
+
+// uncapturedRoutes are mutating routes capture skips: they change nothing the reconciler acts on,
+// or are reported by workers rather than called by clients
+var uncapturedRoutes = map[string]bool{
+	"/v1/tenants:validate":           true,
+	"/v1/simulate":                   true,
+	"/v1/executions/{id}/steps":      true,
+	"/v1/admin/loglevel":             true,
+	"/v1/admin/loglevel/{component}": true,
+	"/v1/admin/debug-flags":          true,
+	"/v1/admin/debug-flags/{flag}":   true,
+}
+
+// SetCapture records the server's mutating requests and their responses with rec
+func (s *Server) SetCapture(rec *capture.Recorder) {
+	s.capture = rec
+}
+
+// captureRequests records mutating requests when capture is on. The request body is read up to the
+// recorder's limit and restored for the handler; the response is copied as it is written.
+func (s *Server) captureRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := s.capture
+		switch {
+		case rec == nil, r.Method == http.MethodGet, r.Method == http.MethodHead, r.Method == http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limit := rec.MaxBodyBytes()
+		var requestBody []byte
+		if r.Body != nil {
+			read, err := io.ReadAll(io.LimitReader(r.Body, int64(limit)+1))
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			requestBody = read
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(read), r.Body), r.Body}
+		}
+
+		response := &limitedBuffer{limit: limit}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(response)
+		start := time.Now()
+		next.ServeHTTP(ww, r)
+
+		route := chi.RouteContext(r.Context()).RoutePattern()
+		if route == "" || uncapturedRoutes[route] {
+			return
+		}
+
+		record := capture.Record{
+			Time:       start.UTC(),
+			Method:     r.Method,
+			Route:      route,
+			Path:       r.URL.Path,
+			Query:      r.URL.RawQuery,
+			Headers:    make(map[string]string),
+			Status:     ww.Status(),
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		for _, name := range capture.RecordedHeaders {
+			if value := r.Header.Get(name); value != "" {
+				record.Headers[name] = value
+			}
+		}
+		switch {
+		case len(requestBody) > limit:
+			record.Truncated = true
+		case json.Valid(requestBody):
+			record.RequestBody = requestBody
+		case len(bytes.TrimSpace(requestBody)) > 0:
+			record.RequestText = string(requestBody)
+		}
+		if !response.truncated && json.Valid(response.Bytes()) {
+			record.ResponseBody = response.Bytes()
+		}
+
+		params := make(map[string]string)
+		urlParams := chi.RouteContext(r.Context()).URLParams
+		for i, key := range urlParams.Keys {
+			if i < len(urlParams.Values) && !strings.HasSuffix(key, "*") {
+				params[key] = urlParams.Values[i]
+			}
+		}
+		rec.Record(record, params)
+	})
+}
+
+// limitedBuffer keeps the first limit bytes written to it
+type limitedBuffer struct {
+	bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room < len(p) {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.Buffer.Write(p)
+}
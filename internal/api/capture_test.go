@@ -0,0 +1,95 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/capture"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/logger"
+)
+
+func TestCaptureRequests(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "capture")
+	recorder, err := capture.NewRecorder(config.CaptureConfig{Directory: dir, MaxBodyBytes: 256, MaxRecords: 100}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop()}
+	srv.registerRoutes()
+	srv.SetCapture(recorder)
+	log, err := logger.New("production", "info")
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	srv.SetLogLevels(logger.LevelsOf(log))
+
+	call := func(method, path, body string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	header := http.Header{
+		"Content-Type":    {"application/json"},
+		"Authorization":   {"Bearer admin-key"},
+		"X-Field-Manager": {"alice"},
+	}
+	if rec := call(http.MethodPost, "/v1/tenants", `{"image":"nginx","compute_config":{"env":{"API_TOKEN":"hunter2"}}}`, header); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d", rec.Code)
+	}
+	// The handler reads the whole body, not just the part capture keeps
+	large := `{"image":"` + strings.Repeat("x", 512) + `"}`
+	if rec := call(http.MethodPost, "/v1/tenants", large, nil); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "name is required") {
+		t.Fatalf("expected the handler to read the whole body, got %d: %s", rec.Code, rec.Body.String())
+	}
+	call(http.MethodPost, "/v1/tenants", "not json", nil)
+	call(http.MethodGet, "/v1/admin/loglevel", "", nil)
+	call(http.MethodPut, "/v1/admin/debug-flags", `{"flag": "restate-payloads"}`, nil)
+	if err := recorder.Close(); err != nil {
+		t.Fatalf("close recorder: %v", err)
+	}
+
+	records, err := capture.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read capture: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected 3 records, got %d: %+v", len(records), records)
+	}
+
+	first := records[0]
+	if first.Route != "/v1/tenants" || first.Method != http.MethodPost || first.Status != http.StatusBadRequest {
+		t.Fatalf("unexpected record: %+v", first)
+	}
+	if strings.Contains(string(first.RequestBody), "hunter2") || !strings.Contains(string(first.RequestBody), `"image":"nginx"`) {
+		t.Fatalf("expected a redacted request body, got %s", first.RequestBody)
+	}
+	if !strings.Contains(string(first.ResponseBody), "name is required") {
+		t.Fatalf("expected the response body, got %s", first.ResponseBody)
+	}
+	if _, ok := first.Headers["Authorization"]; ok {
+		t.Fatalf("expected credentials to be dropped, got %v", first.Headers)
+	}
+	if manager := first.Headers["X-Field-Manager"]; !strings.HasPrefix(manager, "actor-") {
+		t.Fatalf("expected a pseudonymous field manager, got %q", manager)
+	}
+
+	if !records[1].Truncated || len(records[1].RequestBody) != 0 {
+		t.Fatalf("expected a truncated record without a body, got %+v", records[1])
+	}
+	if records[2].RequestText != "not json" {
+		t.Fatalf("expected the text body, got %+v", records[2])
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// componentConfig is the compute config one of a tenant's components is provisioned with
+type componentConfig struct {
+	// field is the compute_config path the component is declared at
+	field  string
+	name   string
+	values map[string]interface{}
+	raw    json.RawMessage
+}
+
+// componentConfigs splits a compute_config into the configs its compute provider validates: the
+// whole config for a tenant without components, or each component's config
+func componentConfigs(computeConfig map[string]interface{}) ([]componentConfig, error) {
+	components, err := tenant.ParseComponents(computeConfig)
+	if err != nil {
+		return nil, err
+	}
+	configs := make([]componentConfig, 0, len(components))
+	for _, component := range components {
+		raw, err := json.Marshal(component.Config)
+		if err != nil {
+			return nil, err
+		}
+		config := componentConfig{field: "compute_config", values: component.Config, raw: raw}
+		if tenant.HasComponents(computeConfig) {
+			config.name = component.Name
+			config.field = fmt.Sprintf("compute_config.%s.%s", tenant.ComponentsConfigKey, component.Name)
+		}
+		configs = append(configs, config)
+	}
+	return configs, nil
+}
+
+// details prefixes validation details with the component they apply to
+func (c componentConfig) details(details []string) []string {
+	if c.name == "" {
+		return details
+	}
+	prefixed := make([]string, len(details))
+	for i, detail := range details {
+		prefixed[i] = fmt.Sprintf("%s.%s: %s", tenant.ComponentsConfigKey, c.name, detail)
+	}
+	return prefixed
+}
+
+// validateComponentConfigs checks the config of each of a tenant's components against its compute
+// applyProviderDefaults fills the compute_config from the compute provider's defaults and records the
+// defaulted fields in the annotations, so the tenant shows the config it runs with. It writes an error
+// response and returns false when the defaults cannot be read.
+func (s *Server) applyProviderDefaults(w http.ResponseWriter, r *http.Request, provider compute.Provider, computeConfig *map[string]interface{}, annotations *map[string]string, requestID string) bool {
+	config, fields, err := compute.ApplyConfigDefaults(provider, *computeConfig)
+	if err != nil {
+		s.logger.Error("failed to apply compute provider defaults", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to apply compute provider defaults", nil, requestID)
+		return false
+	}
+	*computeConfig = config
+	*annotations = compute.AnnotateProviderDefaults(*annotations, fields)
+	return true
+}
+
+// provider, writing the error response when one is not valid
+func (s *Server) validateComponentConfigs(w http.ResponseWriter, r *http.Request, provider compute.Provider, computeConfig map[string]interface{}, requestID string) bool {
+	configs, err := componentConfigs(computeConfig)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid components configuration", []string{err.Error()}, requestID)
+		return false
+	}
+	for _, config := range configs {
+		if err := compute.ValidateConfigAgainstSchema(provider, config.raw); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid compute configuration", config.details(computeSchemaErrorDetails(err)), requestID)
+			return false
+		}
+		if err := provider.ValidateConfig(config.raw); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid compute configuration", config.details([]string{err.Error()}), requestID)
+			return false
+		}
+		if _, err := workflow.ParseReadinessProbe(config.values); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid readiness probe configuration", config.details([]string{err.Error()}), requestID)
+			return false
+		}
+	}
+	return true
+}
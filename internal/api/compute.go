@@ -21,19 +21,19 @@ import (
 func (s *Server) handleComputeConfigDiscovery(w http.ResponseWriter, r *http.Request) {
 	requestID := r.Header.Get("X-Request-ID")
 	if s.computeRegistry == nil {
-		s.writeErrorResponse(w, http.StatusInternalServerError, "Compute provider registry not configured", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Compute provider registry not configured", nil, requestID)
 		return
 	}
 
 	provider := strings.TrimSpace(r.URL.Query().Get("provider"))
 	if provider == "" {
-		s.writeErrorResponse(w, http.StatusBadRequest, "provider is required", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "provider is required", nil, requestID)
 		return
 	}
 
 	schema, defaults, err := s.computeRegistry.GetProviderSchema(provider)
 	if err != nil {
-		s.writeErrorResponse(w, http.StatusBadRequest, "Compute provider not available", []string{err.Error()}, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Compute provider not available", []string{err.Error()}, requestID)
 		return
 	}
 
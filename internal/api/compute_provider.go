@@ -1,52 +1,46 @@
 package api
 
 import (
-	"fmt"
+	"errors"
+	"net/http"
 
+	"github.com/jaxxstorm/landlord/internal/api/models"
 	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/resolution"
 	"github.com/jaxxstorm/landlord/internal/tenant"
 )
 
-func providerFromMaps(config map[string]interface{}, labels map[string]string, annotations map[string]string) string {
-	if config != nil {
-		if provider, ok := config["compute_provider"]; ok {
-			if value, ok := provider.(string); ok {
-				return value
-			}
-		}
-		if provider, ok := config["compute_provider_type"]; ok {
-			if value, ok := provider.(string); ok {
-				return value
-			}
-		}
-	}
-	if labels != nil {
-		if provider, ok := labels["compute_provider"]; ok {
-			return provider
-		}
-	}
-	if annotations != nil {
-		if provider, ok := annotations["compute_provider"]; ok {
-			return provider
-		}
-	}
-	return ""
+var (
+	errComputeRegistryNotConfigured = errors.New("compute provider registry not configured")
+	errComputeProviderRequired      = errors.New("compute provider is required when multiple providers are configured")
+)
+
+// SetComputeResolution sets the rules that choose a compute provider for tenants that do not name one
+func (s *Server) SetComputeResolution(resolver *resolution.Resolver) {
+	s.computeResolution = resolver
 }
 
-func (s *Server) resolveComputeProvider(config map[string]interface{}, labels map[string]string, annotations map[string]string, fallback *tenant.Tenant) (compute.Provider, string, error) {
+// resolveComputeProvider returns the provider for a tenant: one named by the request, then one the
+// existing fallback tenant names, then the first matching resolution rule, then the default
+func (s *Server) resolveComputeProvider(name string, config map[string]interface{}, labels map[string]string, annotations map[string]string, fallback *tenant.Tenant) (compute.Provider, string, error) {
 	if s.computeRegistry == nil {
-		return nil, "", fmt.Errorf("compute provider registry not configured")
+		return nil, "", errComputeRegistryNotConfigured
 	}
 
-	providerName := providerFromMaps(config, labels, annotations)
+	providerName, _ := resolution.ExplicitProvider(config, labels, annotations)
 	if providerName == "" && fallback != nil {
-		providerName = providerFromMaps(fallback.DesiredConfig, fallback.Labels, fallback.Annotations)
+		providerName, _ = resolution.ExplicitProvider(fallback.DesiredConfig, fallback.Labels, fallback.Annotations)
 	}
 	if providerName == "" {
-		providerName = s.defaultComputeProvider
+		providerName = s.computeResolution.Resolve(resolution.Subject{
+			Name:        name,
+			Config:      config,
+			Labels:      labels,
+			Annotations: annotations,
+		}, s.defaultComputeProvider)
 	}
 	if providerName == "" {
-		return nil, "", fmt.Errorf("compute provider is required when multiple providers are configured")
+		return nil, "", errComputeProviderRequired
 	}
 
 	provider, err := s.computeRegistry.Get(providerName)
@@ -55,3 +49,19 @@ func (s *Server) resolveComputeProvider(config map[string]interface{}, labels ma
 	}
 	return provider, providerName, nil
 }
+
+// writeComputeProviderError writes the response for a resolveComputeProvider failure
+func (s *Server) writeComputeProviderError(w http.ResponseWriter, r *http.Request, err error, requestID string) {
+	switch {
+	case errors.Is(err, errComputeRegistryNotConfigured):
+		s.writeError(w, r, http.StatusInternalServerError, models.ErrorCodeInternal, "Compute provider registry not configured", []string{err.Error()}, requestID)
+	case errors.Is(err, errComputeProviderRequired):
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeProviderRequired, "compute_provider is required when multiple compute providers are configured", []string{err.Error()}, requestID)
+	case errors.Is(err, compute.ErrProviderDisabled):
+		s.writeError(w, r, http.StatusConflict, models.ErrorCodeProviderDisabled, "Compute provider is not accepting new tenants", []string{err.Error()}, requestID)
+	case errors.Is(err, compute.ErrProviderNotFound):
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeProviderNotFound, "Compute provider not available", []string{err.Error()}, requestID)
+	default:
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Compute provider not available", []string{err.Error()}, requestID)
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// compressedContentTypes are the response types gzip compression applies to
+var compressedContentTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"text/plain",
+	"text/html",
+	"text/css",
+	"text/javascript",
+	"application/javascript",
+}
+
+// compressResponses compresses responses for clients that accept gzip or deflate
+func compressResponses(level int) func(http.Handler) http.Handler {
+	return middleware.Compress(level, compressedContentTypes...)
+}
+
+// tenantETag identifies a version of a tenant's representation. It is weak because the same
+// version is served with different bytes under ?fields= and compression.
+func tenantETag(t *tenant.Tenant) string {
+	return "W/" + strconv.Quote(strconv.Itoa(t.Version))
+}
+
+// bodyETag identifies an encoded response body
+func bodyETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return "W/" + strconv.Quote(hex.EncodeToString(sum[:16]))
+}
+
+// etagMatches reports whether an If-None-Match header names etag, comparing weakly as RFC 9110 requires
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified reports whether the client already has the response identified by etag. If-None-Match
+// takes precedence; If-Modified-Since is checked against lastModified only without it, and only when
+// lastModified is set.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return etagMatches(ifNoneMatch, etag)
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// HTTP dates have whole seconds
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// writeConditionalJSON writes v with its validators, or 304 Not Modified when the request shows the
+// client already has it. An empty etag is derived from the encoded body. lastModified is sent as
+// Last-Modified when set; If-Modified-Since is only honoured when honourModifiedSince is set, for
+// responses whose every change moves lastModified forward. Cache-Control defaults to no-cache.
+func writeConditionalJSON(w http.ResponseWriter, r *http.Request, v interface{}, etag string, lastModified time.Time, honourModifiedSince bool) error {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(v); err != nil {
+		return err
+	}
+	if etag == "" {
+		etag = bodyETag(body.Bytes())
+	}
+
+	w.Header().Set("ETag", etag)
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	since := lastModified
+	if !honourModifiedSince {
+		since = time.Time{}
+	}
+	if notModified(r, etag, since) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err := w.Write(body.Bytes())
+	return err
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestConditionalTenantReads(t *testing.T) {
+	repo := tenantmemory.New()
+	ctx := context.Background()
+	web := &tenant.Tenant{Name: "web", Status: tenant.StatusReady}
+	if err := repo.CreateTenant(ctx, web); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	srv := &Server{logger: zap.NewNop(), tenantRepo: repo}
+	srv.router = chi.NewRouter()
+	srv.router.Use(compressResponses(5))
+	srv.router.Get("/v1/tenants", srv.handleListTenants)
+	srv.router.Get("/v1/tenants/{id}", srv.handleGetTenant)
+
+	get := func(url string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, url, nil)
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, url := range []string{"/v1/tenants/web", "/v1/tenants", "/v1/tenants?view=summary", "/v1/tenants?fields=name"} {
+		w := get(url, nil)
+		etag := w.Header().Get("ETag")
+		if w.Code != http.StatusOK || etag == "" || w.Header().Get("Last-Modified") == "" {
+			t.Fatalf("expected 200 with validators for %s, got %d: %v", url, w.Code, w.Header())
+		}
+		if w := get(url, map[string]string{"If-None-Match": `"other", ` + etag}); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("expected 304 without a body for %s, got %d: %s", url, w.Code, w.Body.String())
+		}
+		if w := get(url, map[string]string{"If-None-Match": `"other"`}); w.Code != http.StatusOK {
+			t.Errorf("expected 200 for a stale etag on %s, got %d", url, w.Code)
+		}
+	}
+
+	// A tenant is not modified since its updated_at; a list cannot tell deletions by time
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if w := get("/v1/tenants/web", map[string]string{"If-Modified-Since": future}); w.Code != http.StatusNotModified {
+		t.Errorf("expected 304 for If-Modified-Since, got %d", w.Code)
+	}
+	if w := get("/v1/tenants", map[string]string{"If-Modified-Since": future}); w.Code != http.StatusOK {
+		t.Errorf("expected lists to ignore If-Modified-Since, got %d", w.Code)
+	}
+
+	// Changing the tenant changes both validators
+	before := get("/v1/tenants/web", nil)
+	listBefore := get("/v1/tenants", nil)
+	web.Labels = map[string]string{"team": "web"}
+	if err := repo.UpdateTenant(ctx, web); err != nil {
+		t.Fatalf("update tenant: %v", err)
+	}
+	if w := get("/v1/tenants/web", map[string]string{"If-None-Match": before.Header().Get("ETag")}); w.Code != http.StatusOK {
+		t.Errorf("expected 200 after an update, got %d", w.Code)
+	}
+	if w := get("/v1/tenants", map[string]string{"If-None-Match": listBefore.Header().Get("ETag")}); w.Code != http.StatusOK {
+		t.Errorf("expected list 200 after an update, got %d", w.Code)
+	}
+
+	w := get("/v1/tenants", map[string]string{"Accept-Encoding": "gzip"})
+	if w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a gzip response, got %v", w.Header())
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatalf("read gzip: %v", err)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read gzip: %v", err)
+	}
+	if !strings.Contains(string(body), `"name":"web"`) {
+		t.Errorf("unexpected decompressed body: %s", body)
+	}
+}
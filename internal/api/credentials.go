@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/endpointauth"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// SetEndpointAuth serves tenants' endpoint credentials, derived by generator
+func (s *Server) SetEndpointAuth(generator *endpointauth.Generator) {
+	s.endpointAuth = generator
+}
+
+// handleGetTenantCredentials returns the credentials that protect a tenant's endpoints
+// @Summary Get tenant endpoint credentials
+// @Description Returns the credentials landlord injects into the tenant and its ingress when compute_config.endpoint_auth is set.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Success 200 {object} models.TenantCredentialsResponse "Endpoint credentials"
+// @Failure 400 {object} models.ErrorResponse "Invalid tenant identifier format"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found, or its endpoints are not protected"
+// @Failure 503 {object} models.ErrorResponse "Endpoint auth not configured"
+// @Router /v1/tenants/{id}/credentials [get]
+func (s *Server) handleGetTenantCredentials(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+
+	if s.endpointAuth == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Endpoint auth not configured", nil, requestID)
+		return
+	}
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	spec, err := endpointauth.Parse(t.DesiredConfig)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid endpoint auth configuration", []string{err.Error()}, requestID)
+		return
+	}
+	if spec == nil {
+		s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant endpoints are not protected", []string{fmt.Sprintf("set compute_config.%s to protect them", endpointauth.ConfigKey)}, requestID)
+		return
+	}
+
+	s.writeCredentials(w, t, spec, http.StatusOK)
+}
+
+// handleRotateTenantCredentials replaces the credentials that protect a tenant's endpoints
+// @Summary Rotate tenant endpoint credentials
+// @Description Bumps compute_config.endpoint_auth.rotation and updates the tenant, so its containers and ingress receive new credentials.
+// @Description The old credentials keep working until the update completes.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Success 202 {object} models.TenantCredentialsResponse "New endpoint credentials, applied by the update"
+// @Failure 400 {object} models.ErrorResponse "Invalid endpoint auth configuration"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found, or its endpoints are not protected"
+// @Failure 409 {object} models.ErrorResponse "Tenant is not ready"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Endpoint auth not configured"
+// @Router /v1/tenants/{id}/credentials/rotate [post]
+func (s *Server) handleRotateTenantCredentials(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	if s.endpointAuth == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Endpoint auth not configured", nil, requestID)
+		return
+	}
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	rotated, spec, err := endpointauth.Rotate(t.DesiredConfig)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid endpoint auth configuration", []string{err.Error()}, requestID)
+		return
+	}
+	if spec == nil {
+		s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant endpoints are not protected", []string{fmt.Sprintf("set compute_config.%s to protect them", endpointauth.ConfigKey)}, requestID)
+		return
+	}
+	// The new credentials reach the tenant through an update workflow
+	if t.Status != tenant.StatusReady {
+		s.writeInvalidStateError(w, r, "Tenant must be ready to rotate its credentials", []string{fmt.Sprintf("tenant is %s", t.Status)}, requestID)
+		return
+	}
+
+	now := time.Now()
+	manager := fieldManager(r)
+	message := "Endpoint credentials rotation requested"
+	transition := tenant.NewStateTransition(t, tenant.StatusUpdating, message, manager).WithReasonCode(tenant.ReasonCredentialsRotationRequested)
+
+	previousConfig := t.DesiredConfig
+	t.DesiredConfig = rotated
+	t.UpdateManagedFields(previousConfig, manager, tenant.ManagedFieldOperationUpdate, now)
+
+	t.Status = tenant.StatusUpdating
+	t.StatusMessage = message
+	t.WorkflowExecutionID = nil
+	t.WorkflowStartedAt = nil
+	t.WorkflowSubState = nil
+	t.WorkflowRetryCount = nil
+	t.WorkflowErrorMessage = nil
+	t.UpdatedAt = now
+
+	if err := s.tenantRepo.UpdateTenant(ctx, t); err != nil {
+		if errors.Is(err, tenant.ErrVersionConflict) {
+			s.writeErrorResponse(w, r, http.StatusConflict, "Tenant was modified concurrently", []string{"retry the rotation"}, requestID)
+			return
+		}
+		s.logger.Error("failed to rotate tenant credentials", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to rotate credentials", nil, requestID)
+		return
+	}
+
+	transition.DesiredStateSnapshot = t.DesiredConfig
+	transition.ObservedStateSnapshot = map[string]interface{}{"rotation": spec.Rotation}
+	s.recordTransition(ctx, transition, requestID)
+
+	s.writeCredentials(w, t, spec, http.StatusAccepted)
+}
+
+func (s *Server) writeCredentials(w http.ResponseWriter, t *tenant.Tenant, spec *endpointauth.Spec, status int) {
+	resp := models.TenantCredentialsResponse{
+		TenantID:    t.ID.String(),
+		TenantName:  t.Name,
+		Credentials: *s.endpointAuth.Credentials(t.ID.String(), spec),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/endpointauth"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestRotateTenantCredentials(t *testing.T) {
+	repo := tenantmemory.New()
+	ctx := context.Background()
+	preview := &tenant.Tenant{
+		ID:     uuid.New(),
+		Name:   "preview",
+		Status: tenant.StatusReady,
+		DesiredConfig: map[string]interface{}{
+			"image":                "nginx:latest",
+			endpointauth.ConfigKey: map[string]interface{}{"username": "reviewer"},
+		},
+	}
+	if err := repo.CreateTenant(ctx, preview); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	if err := repo.CreateTenant(ctx, &tenant.Tenant{ID: uuid.New(), Name: "open", Status: tenant.StatusReady, DesiredConfig: map[string]interface{}{"image": "nginx:latest"}}); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo}
+	srv.registerRoutes()
+
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tenants/preview/credentials/rotate", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503 without endpoint auth, got %d", rec.Code)
+	}
+
+	srv.SetEndpointAuth(endpointauth.New(config.EndpointAuthConfig{Enabled: true, Secret: "0123456789abcdef", Username: "landlord"}))
+
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tenants/preview/credentials", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var current models.TenantCredentialsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&current); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if current.Username != "reviewer" || current.Password == "" || current.Rotation != 0 {
+		t.Fatalf("unexpected credentials %+v", current)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tenants/preview/credentials/rotate", nil))
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var rotated models.TenantCredentialsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&rotated); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if rotated.Rotation != 1 || rotated.Password == current.Password {
+		t.Fatalf("expected new credentials, got %+v", rotated)
+	}
+
+	updated, err := repo.GetTenantByName(ctx, "preview")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if updated.Status != tenant.StatusUpdating {
+		t.Fatalf("expected the rotation to update the tenant, got %s", updated.Status)
+	}
+	if spec, _ := endpointauth.Parse(updated.DesiredConfig); spec == nil || spec.Rotation != 1 || spec.Username != "reviewer" {
+		t.Fatalf("unexpected endpoint auth %+v", spec)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tenants/preview/credentials/rotate", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 while the tenant updates, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tenants/open/credentials/rotate", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for a tenant without endpoint auth, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/doctor"
+	"github.com/jaxxstorm/landlord/internal/project"
+)
+
+// SetDoctor enables GET /v1/admin/doctor, which runs the doctor's checks
+func (s *Server) SetDoctor(d *doctor.Doctor) {
+	s.doctor = d
+}
+
+// handleDoctor runs connectivity and consistency checks and reports what is wrong
+// @Summary Run diagnostics
+// @Description Checks the database and its migrations, the controller, compute and workflow providers, compute resources against tenant records, and stuck tenants. Results list failures first, each with a suggested fix. The response is 200 whatever the checks find.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.DoctorReportResponse "Diagnostics report"
+// @Failure 403 {object} models.ErrorResponse "Diagnostics require an admin API key"
+// @Failure 503 {object} models.ErrorResponse "Diagnostics not configured"
+// @Router /v1/admin/doctor [get]
+func (s *Server) handleDoctor(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !project.PrincipalFromContext(r.Context()).Unrestricted() {
+		s.writeErrorResponse(w, r, http.StatusForbidden, "Diagnostics require an admin API key", nil, requestID)
+		return
+	}
+	if s.doctor == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Diagnostics not configured", nil, requestID)
+		return
+	}
+
+	report := s.doctor.Run(r.Context())
+	resp := models.DoctorReportResponse{
+		Status:    string(report.Status),
+		Results:   make([]models.DoctorResult, 0, len(report.Results)),
+		CheckedAt: report.CheckedAt,
+	}
+	for _, result := range report.Results {
+		resp.Results = append(resp.Results, models.DoctorResult{
+			Check:    result.Check,
+			Status:   string(result.Status),
+			Severity: string(result.Severity),
+			Message:  result.Message,
+			Fix:      result.Fix,
+			Details:  result.Details,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
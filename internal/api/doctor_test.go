@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/doctor"
+)
+
+func TestDoctor(t *testing.T) {
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop()}
+	srv.registerRoutes()
+
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/doctor", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503 without a doctor, got %d", rec.Code)
+	}
+
+	d := doctor.New(config.DoctorConfig{StuckThreshold: time.Minute, CheckTimeout: time.Second}, zap.NewNop())
+	d.Register(doctor.Check{Name: "stuck", Run: func(ctx context.Context) []doctor.Result {
+		return []doctor.Result{doctor.Fail("", doctor.SeverityWarning, "1 tenant(s) have not progressed", "retry them")}
+	}})
+	srv.SetDoctor(d)
+
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/admin/doctor", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var report models.DoctorReportResponse
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if report.Status != "fail" || len(report.Results) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if got := report.Results[0]; got.Check != "stuck" || got.Severity != "warning" || got.Fix != "retry them" {
+		t.Errorf("unexpected result %+v", got)
+	}
+}
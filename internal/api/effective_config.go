@@ -0,0 +1,151 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/resource"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// redactedValue replaces credentials in an effective config
+const redactedValue = "<redacted>"
+
+// handleGetTenantEffectiveConfig shows the config a tenant's compute is provisioned with
+// @Summary Get a tenant's effective config
+// @Description Returns the config each of the tenant's components is sent to its compute provider with: the desired config, which already holds template and project policy defaults, with endpoint auth credentials injected and the compute provider's current defaults merged under it, plus the labels the worker puts on its compute.
+// @Description Credentials are redacted. Resource credentials are added to env by the worker when it provisions the resources, so only the resources are listed.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Success 200 {object} models.TenantEffectiveConfigResponse "Effective config"
+// @Failure 400 {object} models.ErrorResponse "Invalid tenant identifier format, or the tenant's config is not valid"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/effective-config [get]
+func (s *Server) handleGetTenantEffectiveConfig(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+
+	t, ok := s.readTenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	provider, providerName, err := s.resolveComputeProvider(t.Name, t.DesiredConfig, t.Labels, t.Annotations, nil)
+	if err != nil {
+		s.writeComputeProviderError(w, r, err, requestID)
+		return
+	}
+
+	// The worker injects endpoint auth before splitting the config into components
+	desiredConfig := t.DesiredConfig
+	secretEnv := map[string]bool{}
+	if s.endpointAuth != nil {
+		injected, refs, err := s.endpointAuth.Inject(t.ID.String(), t.ComputeName(), desiredConfig)
+		if err != nil {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid endpoint auth configuration", []string{err.Error()}, requestID)
+			return
+		}
+		desiredConfig = injected
+		for _, ref := range refs {
+			secretEnv[ref.EnvVar] = true
+		}
+	}
+
+	specs, err := resource.ParseSpecs(t.DesiredConfig)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid resources configuration", []string{err.Error()}, requestID)
+		return
+	}
+	components, err := tenant.ParseComponents(desiredConfig)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid components configuration", []string{err.Error()}, requestID)
+		return
+	}
+
+	resp := models.TenantEffectiveConfigResponse{
+		TenantID:        t.ID.String(),
+		TenantName:      t.Name,
+		ComputeProvider: providerName,
+		Components:      make([]models.EffectiveComponent, 0, len(components)),
+		Sources: models.EffectiveConfigSources{
+			Template:         t.Labels[tenant.LabelTemplate],
+			AppliedPolicies:  splitAnnotation(t.Annotations[project.AnnotationAppliedPolicies]),
+			AppliedDefaults:  splitAnnotation(t.Annotations[project.AnnotationAppliedDefaults]),
+			ProviderDefaults: splitAnnotation(t.Annotations[compute.AnnotationProviderDefaults]),
+		},
+	}
+	for _, spec := range specs {
+		resp.Resources = append(resp.Resources, spec.Name)
+	}
+
+	managed := tenant.ComputeLabels(t)
+	for _, component := range components {
+		// Compute providers merge their defaults under each component's config when they provision it
+		config, fields, err := compute.ApplyConfigDefaults(provider, component.Config)
+		if err != nil {
+			s.logger.Error("failed to apply compute provider defaults", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to apply compute provider defaults", nil, requestID)
+			return
+		}
+		computeID := tenant.ComponentComputeID(t.ComputeName(), component.Name)
+		effective := models.EffectiveComponent{
+			ComputeID: computeID,
+			Labels: compute.MergeLabels(managed,
+				compute.DefaultMetadata(&compute.TenantComputeSpec{TenantID: computeID, ProviderType: providerName})),
+			ProviderDefaults: fields,
+		}
+		if tenant.HasComponents(desiredConfig) {
+			effective.Name = component.Name
+		}
+		effective.Config, effective.SecretEnv = redactEnv(config, secretEnv)
+		resp.Components = append(resp.Components, effective)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// redactEnv returns config with the values of the secret env vars it sets redacted, and their names
+func redactEnv(config map[string]interface{}, secret map[string]bool) (map[string]interface{}, []string) {
+	env, ok := config["env"].(map[string]interface{})
+	if !ok || len(secret) == 0 {
+		return config, nil
+	}
+	redacted := make(map[string]interface{}, len(env))
+	var names []string
+	for key, value := range env {
+		if secret[key] {
+			value = redactedValue
+			names = append(names, key)
+		}
+		redacted[key] = value
+	}
+	if len(names) == 0 {
+		return config, nil
+	}
+	sort.Strings(names)
+
+	copied := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		copied[key] = value
+	}
+	copied["env"] = redacted
+	return copied, names
+}
+
+// splitAnnotation splits a comma-separated annotation value
+func splitAnnotation(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	computemock "github.com/jaxxstorm/landlord/internal/compute/providers/mock"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/endpointauth"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestGetTenantEffectiveConfig(t *testing.T) {
+	repo := tenantmemory.New()
+	api := &tenant.Tenant{
+		ID:     uuid.New(),
+		Name:   "api",
+		Status: tenant.StatusReady,
+		DesiredConfig: map[string]interface{}{
+			"image":                "nginx:latest",
+			"env":                  map[string]interface{}{"LOG_LEVEL": "info"},
+			endpointauth.ConfigKey: map[string]interface{}{"type": endpointauth.TypeToken},
+			"resources":            []interface{}{map[string]interface{}{"name": "db", "type": "postgres"}},
+			"components": map[string]interface{}{
+				"app":    map[string]interface{}{},
+				"worker": map[string]interface{}{"latency": "1ms"},
+			},
+		},
+		Labels: map[string]string{tenant.LabelProject: "acme/web", tenant.LabelTemplate: "api", "team": "platform", tenant.LabelEnvironment: "prod"},
+		Annotations: map[string]string{
+			project.AnnotationAppliedPolicies:  "baseline,production",
+			compute.AnnotationProviderDefaults: "fail_provision_times",
+		},
+	}
+	if err := repo.CreateTenant(context.Background(), api); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	registry := compute.NewRegistry(zap.NewNop())
+	_ = registry.Register(computemock.NewWithDefaults(map[string]interface{}{"latency": "10ms"}))
+	srv := &Server{
+		router:                 chi.NewRouter(),
+		logger:                 zap.NewNop(),
+		tenantRepo:             repo,
+		computeRegistry:        registry,
+		defaultComputeProvider: "mock",
+	}
+	srv.SetEndpointAuth(endpointauth.New(config.EndpointAuthConfig{Enabled: true, Secret: "0123456789abcdef", Username: "landlord"}))
+	srv.registerRoutes()
+
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tenants/api/effective-config", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp models.TenantEffectiveConfigResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	if resp.ComputeProvider != "mock" || len(resp.Components) != 2 || !reflect.DeepEqual(resp.Resources, []string{"db"}) {
+		t.Fatalf("unexpected effective config %+v", resp)
+	}
+	wantSources := models.EffectiveConfigSources{Template: "api", AppliedPolicies: []string{"baseline", "production"}, ProviderDefaults: []string{"fail_provision_times"}}
+	if !reflect.DeepEqual(resp.Sources, wantSources) {
+		t.Errorf("expected sources %+v, got %+v", wantSources, resp.Sources)
+	}
+
+	// app is the default component, so its compute keeps the tenant's name
+	app, worker := resp.Components[0], resp.Components[1]
+	if app.Name != "app" || app.ComputeID != "api" || worker.ComputeID != "api-worker" {
+		t.Fatalf("unexpected components %+v", resp.Components)
+	}
+	if app.Config["latency"] != "10ms" || !reflect.DeepEqual(app.ProviderDefaults, []string{"latency"}) {
+		t.Errorf("expected the provider default on app, got %v (%v)", app.Config, app.ProviderDefaults)
+	}
+	if worker.Config["latency"] != "1ms" || len(worker.ProviderDefaults) != 0 {
+		t.Errorf("expected the worker to keep its own latency, got %v (%v)", worker.Config, worker.ProviderDefaults)
+	}
+	if _, ok := app.Config["resources"]; ok {
+		t.Errorf("expected resources to stay at the tenant level, got %v", app.Config)
+	}
+
+	env, _ := app.Config["env"].(map[string]interface{})
+	if env["LOG_LEVEL"] != "info" || env[endpointauth.EnvToken] != redactedValue || !reflect.DeepEqual(app.SecretEnv, []string{endpointauth.EnvToken}) {
+		t.Errorf("expected the injected token redacted, got env %v (%v)", env, app.SecretEnv)
+	}
+	labels, _ := app.Config["labels"].(map[string]interface{})
+	if labels[endpointauth.LabelType] != endpointauth.TypeToken {
+		t.Errorf("expected the endpoint auth labels injected, got %v", labels)
+	}
+
+	wantLabels := map[string]string{
+		tenant.LabelProject:           "acme/web",
+		tenant.LabelTemplate:          "api",
+		tenant.LabelTenantID:          api.ID.String(),
+		tenant.LabelTenantName:        "api",
+		tenant.LabelTenantEnvironment: "prod",
+		compute.MetadataOwnerKey:      compute.MetadataOwnerValue,
+		compute.MetadataTenantIDKey:   "api",
+		compute.MetadataProviderKey:   "mock",
+	}
+	if !reflect.DeepEqual(app.Labels, wantLabels) {
+		t.Errorf("expected compute labels %v, got %v", wantLabels, app.Labels)
+	}
+
+	if stored, _ := repo.GetTenantByName(context.Background(), "api"); stored.DesiredConfig["env"].(map[string]interface{})[endpointauth.EnvToken] != nil {
+		t.Errorf("expected the stored config untouched, got %v", stored.DesiredConfig)
+	}
+}
@@ -0,0 +1,228 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/schedule"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// Emergency actions
+const (
+	EmergencyActionDestroy = "destroy"
+	EmergencyActionStop    = "stop"
+)
+
+// SetEmergency enables the emergency compute endpoint for the actions cfg allows
+func (s *Server) SetEmergency(cfg config.EmergencyConfig) {
+	s.emergency = cfg
+}
+
+// handleEmergencyTenant acts on a tenant's compute directly, bypassing the workflow engine
+// @Summary Run an emergency compute operation
+// @Description Destroys or stops the tenant's compute through its compute provider without a workflow, for use while the workflow engine is down.
+// @Description A destroyed tenant moves to archiving, so the archive workflow finishes cleaning it up once the engine recovers. A stopped tenant gets the Suspended condition.
+// @Description Requires an admin API key and the emergency config block. Every operation is logged and recorded in the tenant's state history.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Param body body models.EmergencyRequest true "Emergency operation"
+// @Success 200 {object} models.TenantResponse "Operation completed"
+// @Failure 400 {object} models.ErrorResponse "Invalid request, or compute provider cannot stop tenants"
+// @Failure 403 {object} models.ErrorResponse "Not an admin, or the action is not enabled"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 409 {object} models.ErrorResponse "Tenant is archived"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/admin/tenants/{id}/emergency [post]
+func (s *Server) handleEmergencyTenant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	if !project.PrincipalFromContext(ctx).Unrestricted() {
+		s.writeErrorResponse(w, r, http.StatusForbidden, "Emergency operations require an admin API key", nil, requestID)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	var req models.EmergencyRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return
+	}
+	if req.Action != EmergencyActionDestroy && req.Action != EmergencyActionStop {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "action must be destroy or stop", nil, requestID)
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "reason is required", nil, requestID)
+		return
+	}
+	if !s.emergency.Allows(req.Action) {
+		s.writeErrorResponse(w, r, http.StatusForbidden, "Emergency action is not enabled",
+			[]string{fmt.Sprintf("enable %s in the emergency config block", req.Action)}, requestID)
+		return
+	}
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if t.Status == tenant.StatusArchived {
+		s.writeInvalidStateError(w, r, "Tenant is archived", nil, requestID)
+		return
+	}
+
+	provider, providerName, err := s.resolveComputeProvider(t.Name, t.DesiredConfig, t.Labels, t.Annotations, nil)
+	if err != nil {
+		s.writeComputeProviderError(w, r, err, requestID)
+		return
+	}
+
+	// Act on every component the tenant declares, and on those the last workflow left running
+	components := tenant.RecordedComponentNames(t.ObservedConfig)
+	if declared, err := tenant.ComponentNames(t.DesiredConfig); err == nil {
+		for _, name := range declared {
+			if !slices.Contains(components, name) {
+				components = append(components, name)
+			}
+		}
+	}
+
+	manager := fieldManager(r)
+	logger := s.logger.With(
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("tenant_name", t.Name),
+		zap.String("action", req.Action),
+		zap.String("actor", manager),
+		zap.String("reason", reason),
+		zap.Strings("components", components),
+		zap.String("compute_provider", providerName),
+		zap.String("request_id", requestID))
+	logger.Warn("emergency compute operation requested")
+
+	if req.Action == EmergencyActionStop {
+		s.emergencyStop(w, r, t, provider, providerName, components, manager, reason, logger, requestID)
+		return
+	}
+	s.emergencyDestroy(w, r, t, provider, components, manager, reason, logger, requestID)
+}
+
+// emergencyStop stops every component of a tenant and sets its Suspended condition
+func (s *Server) emergencyStop(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, provider compute.Provider, providerName string, components []string, manager, reason string, logger *zap.Logger, requestID string) {
+	ctx := r.Context()
+	power, ok := provider.(compute.PowerManager)
+	if !ok {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Compute provider does not support the action",
+			[]string{fmt.Sprintf("compute provider %s cannot stop tenants", providerName)}, requestID)
+		return
+	}
+	for _, component := range components {
+		if err := power.Suspend(ctx, tenant.ComponentComputeID(t.ComputeName(), component)); err != nil && !errors.Is(err, compute.ErrTenantNotFound) {
+			logger.Error("emergency stop failed", zap.String("component", component), zap.Error(err))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to stop tenant", []string{fmt.Sprintf("component %s: %v", component, err)}, requestID)
+			return
+		}
+	}
+
+	message := fmt.Sprintf("Emergency stop by %s: %s", manager, reason)
+	now := time.Now()
+	t.SetCondition(tenant.Condition{
+		Type:    schedule.ConditionSuspended,
+		Status:  tenant.ConditionTrue,
+		Reason:  tenant.ReasonEmergencyStop,
+		Message: message,
+	}, now)
+	t.UpdatedAt = now
+	if err := s.tenantRepo.UpdateTenant(ctx, t); err != nil {
+		logger.Error("failed to record emergency stop", zap.Error(err))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Tenant stopped, but its Suspended condition was not recorded", nil, requestID)
+		return
+	}
+	s.recordTransition(ctx, tenant.NewStateTransition(t, t.Status, message, manager).WithReasonCode(tenant.ReasonEmergencyStop), requestID)
+	logger.Warn("emergency stop completed")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ToTenantResponse(t))
+}
+
+// emergencyDestroy destroys every component of a tenant, then moves it to archiving so the archive
+// workflow finishes removing it, including its resources, once the workflow engine recovers
+func (s *Server) emergencyDestroy(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, provider compute.Provider, components []string, manager, reason string, logger *zap.Logger, requestID string) {
+	ctx := r.Context()
+	for _, component := range components {
+		if err := provider.Destroy(ctx, tenant.ComponentComputeID(t.ComputeName(), component)); err != nil && !errors.Is(err, compute.ErrTenantNotFound) {
+			logger.Error("emergency destroy failed", zap.String("component", component), zap.Error(err))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to destroy tenant compute", []string{fmt.Sprintf("component %s: %v", component, err)}, requestID)
+			return
+		}
+	}
+
+	message := fmt.Sprintf("Emergency destroy by %s: %s", manager, reason)
+	var transitions []*tenant.StateTransition
+	if t.Status != tenant.StatusArchiving && t.Status != tenant.StatusDeleting {
+		// The workflow in flight can no longer finish, so a tenant mid-workflow fails before archiving
+		if !t.Status.CanTransition(tenant.StatusArchiving) {
+			transitions = append(transitions, tenant.NewStateTransition(t, tenant.StatusFailed, message, manager).WithReasonCode(tenant.ReasonEmergencyDestroy))
+			t.Status = tenant.StatusFailed
+		}
+		if t.WorkflowExecutionID != nil && *t.WorkflowExecutionID != "" && s.workflowClient != nil {
+			if err := s.workflowClient.StopExecution(ctx, t, *t.WorkflowExecutionID, "Emergency destroy"); err != nil {
+				logger.Warn("failed to stop workflow during emergency destroy", zap.String("execution_id", *t.WorkflowExecutionID), zap.Error(err))
+			}
+		}
+		transitions = append(transitions, tenant.NewStateTransition(t, tenant.StatusArchiving, message, manager).WithReasonCode(tenant.ReasonEmergencyDestroy))
+		t.ClearPromotion()
+		t.Status = tenant.StatusArchiving
+		t.WorkflowExecutionID = nil
+		t.WorkflowStartedAt = nil
+		t.WorkflowSubState = nil
+		t.WorkflowRetryCount = nil
+		t.WorkflowErrorMessage = nil
+	} else {
+		transitions = append(transitions, tenant.NewStateTransition(t, t.Status, message, manager).WithReasonCode(tenant.ReasonEmergencyDestroy))
+	}
+	t.StatusMessage = message
+	t.UpdatedAt = time.Now()
+	if err := s.tenantRepo.UpdateTenant(ctx, t); err != nil {
+		logger.Error("failed to record emergency destroy", zap.Error(err))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Tenant compute destroyed, but the tenant was not moved to archiving", nil, requestID)
+		return
+	}
+	for _, transition := range transitions {
+		s.recordTransition(ctx, transition, requestID)
+	}
+	logger.Warn("emergency destroy completed", zap.String("status", string(t.Status)))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ToTenantResponse(t))
+}
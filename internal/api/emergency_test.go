@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/schedule"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestEmergencyTenant(t *testing.T) {
+	repo := tenantmemory.New()
+	ctx := context.Background()
+	executionID := "exec-stuck"
+	stuck := &tenant.Tenant{Name: "stuck", Status: tenant.StatusUpdating, WorkflowExecutionID: &executionID, DesiredConfig: map[string]interface{}{
+		"image":      "nginx:latest",
+		"components": map[string]interface{}{"app": map[string]interface{}{}, "worker": map[string]interface{}{}},
+	}}
+	ready := &tenant.Tenant{Name: "ready", Status: tenant.StatusReady, DesiredConfig: map[string]interface{}{"image": "nginx:latest"}}
+	for _, tn := range []*tenant.Tenant{stuck, ready} {
+		if err := repo.CreateTenant(ctx, tn); err != nil {
+			t.Fatalf("create tenant: %v", err)
+		}
+	}
+
+	registry := newTestComputeRegistry()
+	provider, err := registry.Get("mock")
+	if err != nil {
+		t.Fatalf("get provider: %v", err)
+	}
+	for _, computeID := range []string{"stuck", "stuck-worker", "ready"} {
+		if _, err := provider.Provision(ctx, &compute.TenantComputeSpec{
+			TenantID:     computeID,
+			ProviderType: "mock",
+			Containers:   []compute.ContainerSpec{{Name: "app", Image: "nginx:latest"}},
+		}); err != nil {
+			t.Fatalf("provision: %v", err)
+		}
+	}
+
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo, computeRegistry: registry, defaultComputeProvider: "mock", workflowClient: &mockWorkflowClient{}}
+	srv.registerRoutes()
+
+	emergency := func(name, body string, principal *project.Principal) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/tenants/"+name+"/emergency", strings.NewReader(body))
+		req = req.WithContext(project.WithPrincipal(req.Context(), principal))
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	destroy := `{"action": "destroy", "reason": "workflow engine down, tenant leaking"}`
+	if rec := emergency("stuck", destroy, nil); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 while emergency mode is disabled, got %d", rec.Code)
+	}
+
+	srv.SetEmergency(config.EmergencyConfig{Enabled: true})
+	if rec := emergency("stuck", destroy, &project.Principal{Name: "acme-ci", Organization: "acme"}); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a non-admin principal, got %d", rec.Code)
+	}
+	if rec := emergency("stuck", `{"action": "destroy"}`, nil); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 without a reason, got %d", rec.Code)
+	}
+
+	if rec := emergency("stuck", destroy, &project.Principal{Name: "oncall", Admin: true}); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	for _, computeID := range []string{"stuck", "stuck-worker"} {
+		if _, err := provider.GetStatus(ctx, computeID); !errors.Is(err, compute.ErrTenantNotFound) {
+			t.Fatalf("expected %s destroyed, got %v", computeID, err)
+		}
+	}
+	destroyed, err := repo.GetTenantByName(ctx, "stuck")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if destroyed.Status != tenant.StatusArchiving || destroyed.WorkflowExecutionID != nil {
+		t.Fatalf("expected the tenant archiving without a workflow, got %s %v", destroyed.Status, destroyed.WorkflowExecutionID)
+	}
+	history, err := repo.GetStateHistory(ctx, destroyed.ID, tenant.HistoryFilters{})
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	var path []tenant.Status
+	for _, transition := range history {
+		if !strings.Contains(transition.Reason, "Emergency destroy by oncall: workflow engine down") || transition.ReasonCode != tenant.ReasonEmergencyDestroy {
+			t.Fatalf("unexpected transition reason %s %q", transition.ReasonCode, transition.Reason)
+		}
+		path = append(path, transition.ToStatus)
+	}
+	if len(path) != 2 {
+		t.Fatalf("expected the updating tenant to fail and then archive, got %v", path)
+	}
+
+	if rec := emergency("ready", `{"action": "stop", "reason": "noisy neighbour"}`, nil); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	stopped, err := repo.GetTenantByName(ctx, "ready")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if condition := stopped.Condition(schedule.ConditionSuspended); condition == nil || condition.Reason != "EmergencyStop" {
+		t.Fatalf("expected the EmergencyStop Suspended condition, got %+v", condition)
+	}
+	status, err := provider.GetStatus(ctx, "ready")
+	if err != nil {
+		t.Fatalf("get status: %v", err)
+	}
+	if status.State != compute.ComputeStateStopped {
+		t.Fatalf("expected the tenant stopped, got %s", status.State)
+	}
+
+	srv.SetEmergency(config.EmergencyConfig{Enabled: true, Actions: []string{"stop"}})
+	if rec := emergency("ready", destroy, nil); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for an action that is not enabled, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/execution"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// SetExecutions records the workflow steps workers report and serves them from /v1/executions/{id}
+func (s *Server) SetExecutions(store execution.Store) {
+	s.executions = store
+}
+
+// handleRecordExecutionStep records the start or finish of a workflow step
+// @Summary Record a workflow step
+// @Description Called by workers as they start and finish each step of a tenant workflow. A step started again, as when Restate retries an execution, counts another attempt.
+// @Tags executions
+// @Accept json
+// @Param id path string true "Execution ID"
+// @Param body body models.ExecutionStepRequest true "Step progress"
+// @Success 204 "Step recorded"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Execution steps not configured"
+// @Router /v1/executions/{id}/steps [post]
+func (s *Server) handleRecordExecutionStep(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireExecutions(w, r, requestID) {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	var req models.ExecutionStepRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return
+	}
+	status := workflow.StepStatus(req.Status)
+	var problems []string
+	if strings.TrimSpace(req.Step) == "" {
+		problems = append(problems, "step is required")
+	}
+	if !status.IsValid() {
+		problems = append(problems, "status must be running, succeeded or failed")
+	}
+	if req.StartedAt.IsZero() {
+		problems = append(problems, "started_at is required")
+	}
+	if status != workflow.StepRunning && req.FinishedAt == nil {
+		problems = append(problems, "finished_at is required once a step has finished")
+	}
+	if len(problems) > 0 {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Invalid step", problems, requestID)
+		return
+	}
+
+	t, err := s.lookupTenant(ctx, req.TenantID)
+	if err != nil {
+		if errors.Is(err, tenant.ErrTenantNotFound) {
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant not found", nil, requestID)
+			return
+		}
+		s.logger.Error("failed to get tenant", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+		return
+	}
+
+	step := &execution.Step{
+		ExecutionID: chi.URLParam(r, "id"),
+		TenantID:    t.ID,
+		Name:        req.Step,
+		Status:      status,
+		Error:       req.Error,
+		StartedAt:   req.StartedAt,
+		FinishedAt:  req.FinishedAt,
+	}
+	if err := s.executions.RecordStep(ctx, step); err != nil {
+		s.logger.Error("failed to record execution step", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to record step", nil, requestID)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetExecution shows the steps of a workflow execution
+// @Summary Get a workflow execution
+// @Description Returns the steps workers reported for the execution, with their status and duration, and the execution's state when the workflow provider can report it.
+// @Description The execution ID is the tenant's workflow_execution_id. Only executions run by workers that report their steps are known.
+// @Tags executions
+// @Produce json
+// @Param id path string true "Execution ID"
+// @Success 200 {object} models.ExecutionResponse "Execution"
+// @Failure 404 {object} models.ErrorResponse "Execution not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Execution steps not configured"
+// @Router /v1/executions/{id} [get]
+func (s *Server) handleGetExecution(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireExecutions(w, r, requestID) {
+		return
+	}
+
+	executionID := chi.URLParam(r, "id")
+	steps, err := s.executions.ListSteps(ctx, executionID)
+	var t *tenant.Tenant
+	if err == nil {
+		// Executions of tenants outside the caller's scope are reported as not found
+		t, err = s.lookupTenant(ctx, steps[0].TenantID.String())
+	}
+	if err != nil {
+		if errors.Is(err, execution.ErrNotFound) || errors.Is(err, tenant.ErrTenantNotFound) {
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Execution not found", nil, requestID)
+			return
+		}
+		s.logger.Error("failed to get execution", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve execution", nil, requestID)
+		return
+	}
+
+	resp := models.ExecutionResponse{
+		ExecutionID: executionID,
+		TenantID:    t.ID.String(),
+		TenantName:  t.Name,
+		Steps:       make([]models.ExecutionStepResponse, 0, len(steps)),
+	}
+	now := time.Now()
+	for _, step := range steps {
+		resp.Steps = append(resp.Steps, models.ToExecutionStepResponse(step, now))
+	}
+	if s.workflowClient != nil {
+		if status, err := s.workflowClient.GetExecutionStatus(ctx, executionID); err == nil {
+			resp.State = string(status.State)
+		} else {
+			s.logger.Debug("execution state unavailable", zap.Error(err), zap.String("execution_id", executionID), zap.String("request_id", requestID))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) requireExecutions(w http.ResponseWriter, r *http.Request, requestID string) bool {
+	if s.executions == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Execution steps not configured", nil, requestID)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	executionmemory "github.com/jaxxstorm/landlord/internal/execution/memory"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestExecutionSteps(t *testing.T) {
+	repo := tenantmemory.New()
+	tn := &tenant.Tenant{Name: "acme", Status: tenant.StatusProvisioning}
+	if err := repo.CreateTenant(context.Background(), tn); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo}
+	srv.registerRoutes()
+
+	post := func(body string) int {
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/executions/inv_1/steps", strings.NewReader(body)))
+		return rec.Code
+	}
+	get := func(id string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/executions/"+id, nil))
+		return rec
+	}
+
+	running := `{"tenant_id":"acme","step":"provision","status":"running","started_at":"2026-01-02T03:04:05Z"}`
+	if code := post(running); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without an execution store, got %d", code)
+	}
+
+	srv.SetExecutions(executionmemory.New())
+	for _, body := range []string{
+		`{"tenant_id":"acme","step":"provision","status":"done","started_at":"2026-01-02T03:04:05Z"}`,
+		`{"tenant_id":"acme","step":"provision","status":"succeeded","started_at":"2026-01-02T03:04:05Z"}`,
+		`{"tenant_id":"acme","status":"running","started_at":"2026-01-02T03:04:05Z"}`,
+	} {
+		if code := post(body); code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", body, code)
+		}
+	}
+	if code := post(`{"tenant_id":"missing","step":"provision","status":"running","started_at":"2026-01-02T03:04:05Z"}`); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown tenant, got %d", code)
+	}
+	if rec := get("inv_1"); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 before any step is reported, got %d", rec.Code)
+	}
+
+	for _, body := range []string{
+		`{"tenant_id":"acme","step":"validate","status":"running","started_at":"2026-01-02T03:04:05Z"}`,
+		`{"tenant_id":"acme","step":"validate","status":"succeeded","started_at":"2026-01-02T03:04:05Z","finished_at":"2026-01-02T03:04:06Z"}`,
+		running,
+		`{"tenant_id":"acme","step":"provision","status":"failed","started_at":"2026-01-02T03:04:06Z","finished_at":"2026-01-02T03:04:16Z","error":"quota exceeded"}`,
+		// Restate retries the execution, starting the step again
+		running,
+	} {
+		if code := post(body); code != http.StatusNoContent {
+			t.Fatalf("%s: expected status 204, got %d", body, code)
+		}
+	}
+
+	rec := get("inv_1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp models.ExecutionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.TenantID != tn.ID.String() || resp.TenantName != "acme" || len(resp.Steps) != 2 {
+		t.Fatalf("unexpected execution: %+v", resp)
+	}
+	validate, provision := resp.Steps[0], resp.Steps[1]
+	if validate.Name != "validate" || validate.Status != "succeeded" || validate.Attempts != 1 || validate.DurationMs != 1000 {
+		t.Errorf("unexpected validate step: %+v", validate)
+	}
+	if provision.Name != "provision" || provision.Status != "running" || provision.Attempts != 2 || provision.FinishedAt != nil || provision.Error != "" {
+		t.Errorf("unexpected provision step: %+v", provision)
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/featureflag"
+)
+
+// SetFeatureFlags sets the feature flags tenants may set, besides the built-in ones
+func (s *Server) SetFeatureFlags(registry *featureflag.Registry) {
+	s.featureFlags = registry
+}
+
+// featureFlagProblems returns a problem for each feature flag annotation among annotations that
+// names an undeclared flag or holds a value that is not of the flag's type
+func (s *Server) featureFlagProblems(annotations map[string]string) []string {
+	if _, err := s.featureFlags.Parse(annotations); err != nil {
+		return strings.Split(err.Error(), "\n")
+	}
+	return nil
+}
+
+// handleListFeatureFlags lists the feature flags tenants may set
+// @Summary List feature flags
+// @Description Returns the feature flags tenants may set with landlord.io/flags/<name> annotations: the built-in flags and those the configuration declares.
+// @Tags meta
+// @Produce json
+// @Success 200 {object} models.ListFeatureFlagsResponse "Declared feature flags"
+// @Router /v1/meta/feature-flags [get]
+func (s *Server) handleListFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	resp := models.ListFeatureFlagsResponse{
+		AnnotationPrefix: featureflag.AnnotationPrefix,
+		Flags:            s.featureFlags.Definitions(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
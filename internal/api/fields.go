@@ -0,0 +1,121 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+)
+
+var (
+	// tenantFields are the top-level fields ?fields= may select from a tenant
+	tenantFields = jsonFieldNames(reflect.TypeOf(models.TenantResponse{}))
+
+	// summaryFields are the tenant fields a list can serve from summaries, without reading each
+	// tenant's configuration
+	summaryFields = jsonFieldNames(reflect.TypeOf(models.TenantSummary{}))
+)
+
+// fieldSelection is a parsed ?fields= parameter: the dot-separated JSON paths to return, such as
+// "status" or "observed_config.compute_status.state". Paths select object fields; arrays are
+// returned whole.
+type fieldSelection [][]string
+
+// parseFields parses a comma-separated ?fields= value, returning nil when it is empty. Each path
+// must start with one of allowed; what lies below it is not checked, so paths into a tenant's
+// configuration that a tenant does not have are left out of its response.
+func parseFields(raw string, allowed map[string]bool) (fieldSelection, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	var selection fieldSelection
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		path := strings.Split(field, ".")
+		for _, segment := range path {
+			if segment == "" {
+				return nil, fmt.Errorf("%q is not a valid field path", field)
+			}
+		}
+		if !allowed[path[0]] {
+			return nil, fmt.Errorf("unknown field %q", path[0])
+		}
+		selection = append(selection, path)
+	}
+	if len(selection) == 0 {
+		return nil, fmt.Errorf("fields must name at least one field")
+	}
+	return selection, nil
+}
+
+// within reports whether every selected path starts with one of fields
+func (f fieldSelection) within(fields map[string]bool) bool {
+	for _, path := range f {
+		if !fields[path[0]] {
+			return false
+		}
+	}
+	return true
+}
+
+// apply returns v as a JSON object holding only the selected paths
+func (f fieldSelection) apply(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	// Numbers are kept as written, so large integers in tenant configuration are not rounded
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var source map[string]interface{}
+	if err := decoder.Decode(&source); err != nil {
+		return nil, err
+	}
+
+	selected := make(map[string]interface{})
+	for _, path := range f {
+		copyPath(selected, source, path)
+	}
+	return selected, nil
+}
+
+// copyPath copies the value at path from src into dst, creating the objects along the way. A path
+// through a missing field or a value that is not an object copies nothing.
+func copyPath(dst, src map[string]interface{}, path []string) {
+	value, ok := src[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		dst[path[0]] = value
+		return
+	}
+	child, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+	dstChild, ok := dst[path[0]].(map[string]interface{})
+	if !ok {
+		dstChild = make(map[string]interface{})
+		dst[path[0]] = dstChild
+	}
+	copyPath(dstChild, child, path[1:])
+}
+
+// jsonFieldNames returns the JSON names of a struct's fields
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
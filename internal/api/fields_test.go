@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestTenantFieldSelection(t *testing.T) {
+	repo := tenantmemory.New()
+	ctx := context.Background()
+	for _, name := range []string{"alpha", "beta"} {
+		if err := repo.CreateTenant(ctx, &tenant.Tenant{
+			Name:           name,
+			Status:         tenant.StatusReady,
+			Labels:         map[string]string{"team": name, "tier": "gold"},
+			DesiredConfig:  map[string]interface{}{"image": "nginx:latest", "replicas": 3},
+			ObservedConfig: map[string]interface{}{"compute_status": map[string]interface{}{"state": "running", "health": "healthy"}},
+		}); err != nil {
+			t.Fatalf("create tenant: %v", err)
+		}
+	}
+	srv := &Server{logger: zap.NewNop(), tenantRepo: repo}
+	srv.router = chi.NewRouter()
+	srv.router.Get("/v1/tenants", srv.handleListTenants)
+	srv.router.Get("/v1/tenants/{id}", srv.handleGetTenant)
+
+	get := func(url string) (int, []byte) {
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w.Code, w.Body.Bytes()
+	}
+
+	code, body := get("/v1/tenants/alpha?fields=name,status,labels.team,observed_config.compute_status.state,desired_config.replicas,observed_config.missing.state")
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", code, body)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	want := map[string]interface{}{
+		"name":            "alpha",
+		"status":          "ready",
+		"labels":          map[string]interface{}{"team": "alpha"},
+		"observed_config": map[string]interface{}{"compute_status": map[string]interface{}{"state": "running"}},
+		"desired_config":  map[string]interface{}{"replicas": float64(3)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("selected fields = %v, want %v", got, want)
+	}
+
+	// Summary fields are served from summaries; the rest from full tenants
+	for _, fields := range []string{"name,status", "name,workflow_sub_state,desired_config.image"} {
+		code, body = get("/v1/tenants?limit=1&fields=" + fields)
+		if code != http.StatusOK {
+			t.Fatalf("expected status 200 for %s, got %d: %s", fields, code, body)
+		}
+		var list struct {
+			Tenants []map[string]interface{} `json:"tenants"`
+			Total   int                      `json:"total"`
+		}
+		if err := json.Unmarshal(body, &list); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if list.Total != 2 || len(list.Tenants) != 1 {
+			t.Fatalf("expected 1 of 2 tenants for %s, got %d of %d", fields, len(list.Tenants), list.Total)
+		}
+		if _, ok := list.Tenants[0]["id"]; ok || list.Tenants[0]["name"] == nil {
+			t.Errorf("expected only %s, got %v", fields, list.Tenants[0])
+		}
+	}
+
+	for _, url := range []string{
+		"/v1/tenants/alpha?fields=secret",
+		"/v1/tenants/alpha?fields=labels..team",
+		"/v1/tenants?fields=,",
+		"/v1/tenants?view=summary&fields=desired_config",
+	} {
+		if code, body := get(url); code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d: %s", url, code, body)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// handleAddFinalizer registers a finalizer on a tenant
+// @Summary Add a tenant finalizer
+// @Description Registers a component that must clean up after the tenant before it is deleted or archived. Once the tenant's delete or archive workflow succeeds, the tenant stays deleting or archiving until every finalizer is removed.
+// @Description Adding a finalizer the tenant already has changes nothing. Finalizers cannot be added to a tenant being deleted or archived.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Param name path string true "Finalizer name, such as dns or ingress.example.com"
+// @Success 200 {object} models.TenantResponse "Tenant with the finalizer"
+// @Failure 400 {object} models.ErrorResponse "Invalid finalizer name"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 409 {object} models.ErrorResponse "Tenant is being deleted or archived"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/finalizers/{name} [put]
+func (s *Server) handleAddFinalizer(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+
+	name := chi.URLParam(r, "name")
+	if err := tenant.ValidateFinalizerName(name); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid finalizer name", []string{err.Error()}, requestID)
+		return
+	}
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if t.IsBeingRemoved() {
+		s.writeInvalidStateError(w, r, "Finalizers cannot be added to a tenant being deleted or archived", []string{fmt.Sprintf("tenant is %s", t.Status)}, requestID)
+		return
+	}
+	if t.AddFinalizer(name) && !s.saveFinalizers(w, r, t, requestID) {
+		return
+	}
+
+	s.logger.Info("tenant finalizer added",
+		zap.String("tenant_name", t.Name),
+		zap.String("finalizer", name),
+		zap.String("request_id", requestID))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ToTenantResponse(t))
+}
+
+// handleRemoveFinalizer removes a finalizer from a tenant
+// @Summary Remove a tenant finalizer
+// @Description Reports that a component has cleaned up after the tenant. A deleting or archiving tenant whose workflow has succeeded is deleted or archived once its last finalizer is removed.
+// @Description Removing a finalizer by hand lets the tenant be removed while the component's resources may still exist.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Param name path string true "Finalizer name"
+// @Success 200 {object} models.TenantResponse "Tenant without the finalizer"
+// @Failure 400 {object} models.ErrorResponse "Invalid finalizer name"
+// @Failure 404 {object} models.ErrorResponse "Tenant or finalizer not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/finalizers/{name} [delete]
+func (s *Server) handleRemoveFinalizer(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+
+	name := chi.URLParam(r, "name")
+	if err := tenant.ValidateFinalizerName(name); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid finalizer name", []string{err.Error()}, requestID)
+		return
+	}
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if !t.RemoveFinalizer(name) {
+		s.writeErrorResponse(w, r, http.StatusNotFound, "Finalizer not found", []string{fmt.Sprintf("tenant %s has no finalizer %q", t.Name, name)}, requestID)
+		return
+	}
+	if !s.saveFinalizers(w, r, t, requestID) {
+		return
+	}
+	transition := tenant.NewStateTransition(t, t.Status, fmt.Sprintf("Finalizer %s removed", name), fieldManager(r)).WithReasonCode(tenant.ReasonFinalizerRemoved)
+	s.recordTransition(r.Context(), transition, requestID)
+
+	s.logger.Info("tenant finalizer removed",
+		zap.String("tenant_name", t.Name),
+		zap.String("finalizer", name),
+		zap.String("status", string(t.Status)),
+		zap.String("request_id", requestID))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ToTenantResponse(t))
+}
+
+// saveFinalizers writes a locked tenant whose finalizers changed, writing an error response when it fails
+func (s *Server) saveFinalizers(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, requestID string) bool {
+	t.UpdatedAt = time.Now()
+	if err := s.tenantRepo.UpdateTenant(r.Context(), t); err != nil {
+		if errors.Is(err, tenant.ErrVersionConflict) {
+			s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Tenant was modified concurrently, retry the request", nil, requestID)
+			return false
+		}
+		s.logger.Error("failed to update tenant finalizers", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to update tenant finalizers", nil, requestID)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func newFinalizerServer(t *testing.T, tenants ...*tenant.Tenant) (*Server, *tenantmemory.Repository) {
+	t.Helper()
+	repo := tenantmemory.New()
+	for _, tn := range tenants {
+		if err := repo.CreateTenant(context.Background(), tn); err != nil {
+			t.Fatalf("create tenant %s: %v", tn.Name, err)
+		}
+	}
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo}
+	srv.registerRoutes()
+	return srv, repo
+}
+
+func doFinalizerRequest(srv *Server, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-Field-Manager", "dns-operator")
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestFinalizers_AddAndRemove(t *testing.T) {
+	srv, repo := newFinalizerServer(t, &tenant.Tenant{Name: "web", Status: tenant.StatusReady})
+
+	for range 2 {
+		rec := doFinalizerRequest(srv, http.MethodPut, "/v1/tenants/web/finalizers/dns.example.com")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("add: expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp models.TenantResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		// Adding it again changes nothing
+		if !reflect.DeepEqual(resp.Finalizers, []string{"dns.example.com"}) {
+			t.Fatalf("unexpected finalizers %v", resp.Finalizers)
+		}
+	}
+
+	rec := doFinalizerRequest(srv, http.MethodPut, "/v1/tenants/web/finalizers/DNS_Records")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid name: expected 400, got %d", rec.Code)
+	}
+
+	rec = doFinalizerRequest(srv, http.MethodDelete, "/v1/tenants/web/finalizers/dns.example.com")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("remove: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	stored, err := repo.GetTenantByName(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if len(stored.Finalizers) != 0 {
+		t.Fatalf("expected no finalizers, got %v", stored.Finalizers)
+	}
+
+	history, err := repo.GetStateHistory(context.Background(), stored.ID, tenant.HistoryFilters{})
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	if len(history) != 1 || history[0].ReasonCode != tenant.ReasonFinalizerRemoved || history[0].TriggeredBy != "dns-operator" {
+		t.Fatalf("expected a FinalizerRemoved transition, got %+v", history)
+	}
+
+	rec = doFinalizerRequest(srv, http.MethodDelete, "/v1/tenants/web/finalizers/dns.example.com")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("remove missing: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestFinalizers_RemovalInProgress(t *testing.T) {
+	srv, repo := newFinalizerServer(t, &tenant.Tenant{Name: "web", Status: tenant.StatusDeleting, Finalizers: []string{"dns"}})
+
+	rec := doFinalizerRequest(srv, http.MethodPut, "/v1/tenants/web/finalizers/ingress")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("add while deleting: expected 409, got %d", rec.Code)
+	}
+
+	// A deleting tenant's finalizers can still be removed
+	rec = doFinalizerRequest(srv, http.MethodDelete, "/v1/tenants/web/finalizers/dns")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("remove while deleting: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	stored, err := repo.GetTenantByName(context.Background(), "web")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if stored.Status != tenant.StatusDeleting || len(stored.Finalizers) != 0 {
+		t.Fatalf("unexpected tenant %s with finalizers %v", stored.Status, stored.Finalizers)
+	}
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// SetHistoryScrubber enables the forget endpoint, which removes snapshots from state history
+func (s *Server) SetHistoryScrubber(scrubber tenant.HistoryScrubber) {
+	s.historyScrubber = scrubber
+}
+
+// handleForgetTenant removes the configuration snapshots from a tenant's state history
+// @Summary Forget a tenant's historical payloads
+// @Description Removes the desired and observed state snapshots from every state history record of the tenant, for requests to erase personal data.
+// @Description The records themselves are kept with their statuses, reasons, triggers and times. The tenant's current configuration is not changed.
+// @Description Requires an admin API key. The request is recorded in the tenant's state history.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Param body body models.ForgetTenantRequest true "Forget request"
+// @Success 200 {object} models.ForgetTenantResponse "History forgotten"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 403 {object} models.ErrorResponse "Not an admin"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "The tenant store cannot scrub history"
+// @Router /v1/admin/tenants/{id}/forget [post]
+func (s *Server) handleForgetTenant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	if !project.PrincipalFromContext(ctx).Unrestricted() {
+		s.writeErrorResponse(w, r, http.StatusForbidden, "Forgetting a tenant requires an admin API key", nil, requestID)
+		return
+	}
+	if s.historyScrubber == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "History scrubbing is not available", nil, requestID)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	var req models.ForgetTenantRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "reason is required", nil, requestID)
+		return
+	}
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	// The request is recorded first so the snapshot it takes is removed with the rest
+	manager := fieldManager(r)
+	s.recordTransition(ctx, tenant.NewStateTransition(t, t.Status, fmt.Sprintf("State history forgotten by %s: %s", manager, reason), manager).
+		WithReasonCode(tenant.ReasonHistoryForgotten), requestID)
+
+	forgotten, err := s.historyScrubber.ForgetStateHistory(ctx, t.ID)
+	if err != nil {
+		s.logger.Error("failed to forget tenant history", zap.String("tenant_id", t.ID.String()), zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to forget tenant history", nil, requestID)
+		return
+	}
+	s.logger.Warn("tenant history forgotten",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("actor", manager),
+		zap.String("reason", reason),
+		zap.Int("transitions", forgotten),
+		zap.String("request_id", requestID))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ForgetTenantResponse{TenantID: t.ID.String(), Transitions: forgotten})
+}
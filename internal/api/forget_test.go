@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestForgetTenant(t *testing.T) {
+	repo := tenantmemory.New()
+	ctx := context.Background()
+	tn := &tenant.Tenant{Name: "acme", Status: tenant.StatusReady, DesiredConfig: map[string]interface{}{
+		"image": "nginx:latest",
+		"env":   map[string]interface{}{"OWNER_EMAIL": "alice@example.com"},
+	}}
+	if err := repo.CreateTenant(ctx, tn); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	if err := repo.RecordStateTransition(ctx, tenant.NewStateTransition(tn, tenant.StatusReady, "Config updated", "api")); err != nil {
+		t.Fatalf("record transition: %v", err)
+	}
+
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo}
+	srv.registerRoutes()
+
+	forget := func(name, body string, principal *project.Principal) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/admin/tenants/"+name+"/forget", strings.NewReader(body))
+		req = req.WithContext(project.WithPrincipal(req.Context(), principal))
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	body := `{"reason": "erasure request #42"}`
+	if rec := forget("acme", body, nil); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503 without a scrubber, got %d", rec.Code)
+	}
+
+	srv.SetHistoryScrubber(repo)
+	if rec := forget("acme", body, &project.Principal{Name: "acme-ci", Organization: "acme"}); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a non-admin principal, got %d", rec.Code)
+	}
+	if rec := forget("acme", `{}`, nil); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 without a reason, got %d", rec.Code)
+	}
+	if rec := forget("missing", body, nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+
+	rec := forget("acme", body, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp models.ForgetTenantResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.TenantID != tn.ID.String() || resp.Transitions != 2 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	history, err := repo.GetStateHistory(ctx, tn.ID, tenant.HistoryFilters{})
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	if len(history) != 2 || !strings.HasPrefix(history[0].Reason, "State history forgotten by ") || history[1].Reason != "Config updated" {
+		t.Fatalf("expected the records to be kept, got %+v", history)
+	}
+	for _, st := range history {
+		if st.DesiredStateSnapshot != nil || st.ObservedStateSnapshot != nil || st.ScrubbedAt == nil {
+			t.Errorf("expected snapshots to be removed from %+v", st)
+		}
+	}
+
+	current, err := repo.GetTenantByName(ctx, "acme")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if current.DesiredConfig["env"] == nil {
+		t.Error("expected the tenant's current config to be kept")
+	}
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// handleGetTenantHistory returns a tenant's recorded state transitions
+// @Summary Get tenant state history
+// @Description Returns the tenant's state transitions, newest first, a page at a time. Each carries a free-text reason and, for records written since reason codes were introduced, a machine-readable reason_code. When more transitions match, next_cursor is set; pass it as cursor, with the same filters, for the next page.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Param limit query int false "Maximum number of transitions (default 100, max 1000)"
+// @Param cursor query string false "next_cursor from the previous page"
+// @Param to_status query string false "Comma-separated statuses; only transitions to one of them"
+// @Param reason_code query string false "Comma-separated reason codes to return, from GET /v1/meta/reason-codes"
+// @Param since query string false "Only transitions recorded after this time (RFC 3339)"
+// @Param until query string false "Only transitions recorded before this time (RFC 3339)"
+// @Success 200 {object} models.TenantHistoryResponse "State transitions"
+// @Failure 400 {object} models.ErrorResponse "Invalid tenant identifier format or history parameters"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/history [get]
+func (s *Server) handleGetTenantHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	identifier := chi.URLParam(r, "id")
+	if strings.TrimSpace(identifier) == "" {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "tenant identifier is required", nil, requestID)
+		return
+	}
+	if isUUIDLike(identifier) {
+		if _, err := uuid.Parse(identifier); err != nil {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "invalid tenant identifier format", []string{err.Error()}, requestID)
+			return
+		}
+	}
+
+	filters, problems := parseHistoryFilters(r.URL.Query())
+	if len(problems) > 0 {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid history parameters", problems, requestID)
+		return
+	}
+
+	t, err := s.readTenant(ctx, identifier)
+	if err != nil {
+		if errors.Is(err, tenant.ErrTenantNotFound) {
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant not found", nil, requestID)
+			return
+		}
+		s.logger.Error("failed to get tenant", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+		return
+	}
+
+	// One transition past the page tells whether there is another
+	limit := filters.Limit
+	filters.Limit++
+	transitions, err := s.tenantRepo.GetStateHistory(ctx, t.ID, filters)
+	if err != nil {
+		s.logger.Error("failed to get tenant history", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant history", nil, requestID)
+		return
+	}
+
+	resp := models.TenantHistoryResponse{Transitions: make([]tenant.StateTransition, 0, min(len(transitions), limit)), Limit: limit}
+	if len(transitions) > limit {
+		transitions = transitions[:limit]
+		resp.NextCursor = encodeHistoryCursor(transitions[limit-1].Cursor())
+	}
+	for _, transition := range transitions {
+		resp.Transitions = append(resp.Transitions, *transition)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// parseHistoryFilters reads the history query parameters, returning a problem for each invalid one
+func parseHistoryFilters(query url.Values) (tenant.HistoryFilters, []string) {
+	filters := tenant.HistoryFilters{Limit: defaultHistoryLimit}
+	var problems []string
+
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxHistoryLimit {
+			problems = append(problems, fmt.Sprintf("limit must be between 1 and %d", maxHistoryLimit))
+		}
+		filters.Limit = parsed
+	}
+	if raw := strings.TrimSpace(query.Get("cursor")); raw != "" {
+		cursor, err := decodeHistoryCursor(raw)
+		if err != nil {
+			problems = append(problems, "cursor must be the next_cursor of a previous page")
+		} else {
+			filters.After = &cursor
+		}
+	}
+	if raw := strings.TrimSpace(query.Get("to_status")); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			status := tenant.Status(strings.TrimSpace(part))
+			if !status.IsValid() {
+				problems = append(problems, fmt.Sprintf("unknown status %q", status))
+				continue
+			}
+			filters.ToStatuses = append(filters.ToStatuses, status)
+		}
+	}
+	if raw := strings.TrimSpace(query.Get("reason_code")); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			code := tenant.ReasonCode(strings.TrimSpace(part))
+			if !tenant.KnownReasonCode(code) {
+				problems = append(problems, fmt.Sprintf("unknown reason code %q; GET /v1/meta/reason-codes lists them", code))
+				continue
+			}
+			filters.ReasonCodes = append(filters.ReasonCodes, code)
+		}
+	}
+	var problem string
+	if filters.CreatedAfter, problem = parseHistoryTime(query, "since"); problem != "" {
+		problems = append(problems, problem)
+	}
+	if filters.CreatedBefore, problem = parseHistoryTime(query, "until"); problem != "" {
+		problems = append(problems, problem)
+	}
+	return filters, problems
+}
+
+// parseHistoryTime reads the named RFC 3339 time parameter, nil when it is not set
+func parseHistoryTime(query url.Values, name string) (*time.Time, string) {
+	raw := strings.TrimSpace(query.Get(name))
+	if raw == "" {
+		return nil, ""
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Sprintf("%s must be an RFC 3339 time, such as 2026-01-02T15:04:05Z", name)
+	}
+	return &parsed, ""
+}
+
+// encodeHistoryCursor returns an opaque token for the position in a tenant's history
+func encodeHistoryCursor(cursor tenant.HistoryCursor) string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "/" + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeHistoryCursor reads a token returned by encodeHistoryCursor
+func decodeHistoryCursor(token string) (tenant.HistoryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return tenant.HistoryCursor{}, err
+	}
+	created, id, ok := strings.Cut(string(raw), "/")
+	if !ok {
+		return tenant.HistoryCursor{}, fmt.Errorf("malformed cursor")
+	}
+	var cursor tenant.HistoryCursor
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, created); err != nil {
+		return tenant.HistoryCursor{}, err
+	}
+	if cursor.ID, err = uuid.Parse(id); err != nil {
+		return tenant.HistoryCursor{}, err
+	}
+	return cursor, nil
+}
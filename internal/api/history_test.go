@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestGetTenantHistory(t *testing.T) {
+	ctx := context.Background()
+	repo := tenantmemory.New()
+	tn := &tenant.Tenant{Name: "acme", Status: tenant.StatusRequested}
+	if err := repo.CreateTenant(ctx, tn); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	if err := repo.RecordStateTransition(ctx, tenant.NewStateTransition(tn, tenant.StatusProvisioning, "Workflow started", "reconciler")); err != nil {
+		t.Fatalf("record transition: %v", err)
+	}
+	tn.Status = tenant.StatusProvisioning
+	restart := tenant.NewStateTransition(tn, tenant.StatusProvisioning, "Restarted by alice", "alice").WithReasonCode(tenant.ReasonRestarted)
+	if err := repo.RecordStateTransition(ctx, restart); err != nil {
+		t.Fatalf("record transition: %v", err)
+	}
+
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo}
+	srv.registerRoutes()
+
+	get := func(url string) (int, models.TenantHistoryResponse) {
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		var resp models.TenantHistoryResponse
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	code, resp := get("/v1/tenants/acme/history")
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+	if len(resp.Transitions) != 2 || resp.Transitions[0].ReasonCode != tenant.ReasonRestarted ||
+		resp.Transitions[1].ToStatus != tenant.StatusProvisioning || resp.Transitions[1].Reason != "Workflow started" || resp.Transitions[1].ReasonCode != "" {
+		t.Errorf("unexpected history %+v", resp.Transitions)
+	}
+
+	code, resp = get("/v1/tenants/acme/history?reason_code=Restarted,EmergencyStop")
+	if code != http.StatusOK || len(resp.Transitions) != 1 || resp.Transitions[0].Reason != "Restarted by alice" {
+		t.Errorf("unexpected filtered history %d %+v", code, resp.Transitions)
+	}
+
+	// Pages follow next_cursor until the oldest transition
+	code, resp = get("/v1/tenants/acme/history?limit=1")
+	if code != http.StatusOK || len(resp.Transitions) != 1 || resp.Limit != 1 || resp.NextCursor == "" || resp.Transitions[0].Reason != "Restarted by alice" {
+		t.Fatalf("unexpected first page %d %+v", code, resp)
+	}
+	code, resp = get("/v1/tenants/acme/history?limit=1&cursor=" + resp.NextCursor)
+	if code != http.StatusOK || len(resp.Transitions) != 1 || resp.NextCursor != "" || resp.Transitions[0].Reason != "Workflow started" {
+		t.Errorf("unexpected last page %d %+v", code, resp)
+	}
+
+	code, resp = get("/v1/tenants/acme/history?to_status=provisioning&since=2000-01-01T00:00:00Z&until=2999-01-01T00:00:00Z")
+	if code != http.StatusOK || len(resp.Transitions) != 2 {
+		t.Errorf("unexpected history filtered by status and time %d %+v", code, resp)
+	}
+	if code, resp = get("/v1/tenants/acme/history?to_status=ready"); code != http.StatusOK || len(resp.Transitions) != 0 {
+		t.Errorf("expected no transitions to ready, got %d %+v", code, resp)
+	}
+
+	for _, url := range []string{
+		"/v1/tenants/acme/history?reason_code=restarted",
+		"/v1/tenants/acme/history?to_status=running",
+		"/v1/tenants/acme/history?limit=0",
+		"/v1/tenants/acme/history?limit=5000",
+		"/v1/tenants/acme/history?cursor=nope",
+		"/v1/tenants/acme/history?since=yesterday",
+	} {
+		if code, _ := get(url); code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", url, code)
+		}
+	}
+	if code, _ := get("/v1/tenants/missing/history"); code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", code)
+	}
+}
+
+func TestDashboardRoutes(t *testing.T) {
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop()}
+	srv.registerRoutes()
+
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ui", nil))
+	if rec.Code != http.StatusMovedPermanently || rec.Header().Get("Location") != "/ui/" {
+		t.Fatalf("expected redirect to /ui/, got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ui/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "app.js") {
+		t.Errorf("expected the dashboard page, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/imagepolicy"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// SetImagePolicy enforces an image policy on tenant creates and updates
+func (s *Server) SetImagePolicy(policy *imagepolicy.Policy) {
+	s.imagePolicy = policy
+}
+
+// enforceImagePolicy checks t's images against the image policy and records the result as its
+// ImagePolicyCompliant condition. When an image is not allowed it writes the error response and
+// returns false.
+func (s *Server) enforceImagePolicy(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, requestID string) bool {
+	if s.imagePolicy == nil {
+		return true
+	}
+
+	violations := s.imagePolicy.Check(r.Context(), t.Labels, t.DesiredConfig)
+	if len(violations) > 0 {
+		details := make([]string, 0, len(violations))
+		for _, v := range violations {
+			details = append(details, v.String())
+		}
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeImagePolicyViolation, "Image not allowed by image policy", details, requestID)
+		return false
+	}
+	imagepolicy.SetCondition(t, nil, time.Now())
+	return true
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/imagepolicy"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestImagePolicyEnforcedOnCreateAndUpdate(t *testing.T) {
+	repo := tenantmemory.New()
+	srv := &Server{
+		router:                 chi.NewRouter(),
+		logger:                 zap.NewNop(),
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+		tenantRepo:             repo,
+	}
+	srv.SetImagePolicy(imagepolicy.New(config.ImagePolicyConfig{
+		TagRules: []config.ImageTagRuleConfig{
+			{Name: "no-latest-in-prod", Selector: map[string]string{"env": "prod"}, DenyTags: []string{"latest"}},
+		},
+	}, zap.NewNop()))
+	srv.registerRoutes()
+
+	do := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		return rec
+	}
+	expectViolation := func(rec *httptest.ResponseRecorder) {
+		t.Helper()
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("expected status 400, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var problem models.ProblemDetails
+		if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+			t.Fatalf("decode problem: %v", err)
+		}
+		if problem.ErrorCode != models.ErrorCodeImagePolicyViolation {
+			t.Fatalf("expected error code %s, got %s", models.ErrorCodeImagePolicyViolation, problem.ErrorCode)
+		}
+	}
+
+	expectViolation(do(http.MethodPost, "/v1/tenants", "application/json",
+		`{"name": "shop", "labels": {"env": "prod"}, "compute_config": {"image": "nginx:latest"}}`))
+
+	rec := do(http.MethodPost, "/v1/tenants", "application/json",
+		`{"name": "shop", "labels": {"env": "prod"}, "compute_config": {"image": "nginx:1.27"}}`)
+	if rec.Code != http.StatusCreated && rec.Code != http.StatusAccepted {
+		t.Fatalf("expected compliant tenant to be created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	stored, err := repo.GetTenantByName(context.Background(), "shop")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if c := stored.Condition(imagepolicy.ConditionCompliant); c == nil || c.Status != tenant.ConditionTrue {
+		t.Fatalf("expected a True %s condition, got %+v", imagepolicy.ConditionCompliant, c)
+	}
+
+	// A tenant may not be moved onto a denied tag, nor relabelled into a rule that its image breaks
+	stored.Status = tenant.StatusReady
+	if err := repo.UpdateTenant(context.Background(), stored); err != nil {
+		t.Fatalf("update tenant: %v", err)
+	}
+	expectViolation(do(http.MethodPut, "/v1/tenants/shop", "application/json", `{"compute_config": {"image": "nginx:latest"}}`))
+
+	if err := repo.CreateTenant(context.Background(), &tenant.Tenant{
+		Name:          "staging",
+		Status:        tenant.StatusReady,
+		DesiredConfig: map[string]interface{}{"image": "nginx:latest"},
+	}); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	expectViolation(do(http.MethodPatch, "/v1/tenants/staging", "application/merge-patch+json", `{"labels": {"env": "prod"}}`))
+}
@@ -0,0 +1,290 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/project"
+)
+
+// listCache serves identical tenant list requests from memory for a sub-second TTL, so many
+// clients polling the same list cost one database read per TTL. Identical requests that miss
+// together share one read. Lists are keyed by the caller's scope and query, since those are all
+// a list depends on.
+type listCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*cachedList
+	// writers holds when each scope last changed something through the API; its lists bypass
+	// the cache for a TTL afterwards, so callers see their own writes
+	writers map[string]time.Time
+
+	group singleflight.Group
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	coalesced atomic.Uint64
+	bypasses  atomic.Uint64
+}
+
+// cachedList is a list response as the handler wrote it
+type cachedList struct {
+	status    int
+	header    http.Header
+	body      []byte
+	expiresAt time.Time
+}
+
+func newListCache(cfg config.ListCacheConfig) *listCache {
+	return &listCache{
+		ttl:        cfg.TTL,
+		maxEntries: cfg.MaxEntries,
+		entries:    make(map[string]*cachedList),
+		writers:    make(map[string]time.Time),
+	}
+}
+
+// SetListCache sets the Cache-Control header of tenant lists and, when enabled, serves identical
+// lists from an in-process cache with its counters on /metrics. Changes made by other callers
+// are seen within the cache's TTL.
+func (s *Server) SetListCache(cfg config.ListCacheConfig) {
+	s.listCacheControl = cfg.CacheControl()
+	s.listCache = nil
+	if cfg.Enabled {
+		s.listCache = newListCache(cfg)
+	}
+}
+
+// cacheTenantLists serves GET /v1/tenants through the list cache. The X-Cache header says
+// whether a response came from the cache.
+func (s *Server) cacheTenantLists(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := s.listCache
+		if c == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		scope := principalScope(project.PrincipalFromContext(r.Context()))
+		if c.bypass(r, scope) {
+			c.bypasses.Add(1)
+			w.Header().Set("X-Cache", "BYPASS")
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := scope + "?" + r.URL.Query().Encode()
+		if cached := c.lookup(key); cached != nil {
+			c.hits.Add(1)
+			cached.write(w, r, "HIT")
+			return
+		}
+
+		led := false
+		result, _, _ := c.group.Do(key, func() (interface{}, error) {
+			led = true
+			c.misses.Add(1)
+			// The cached response must be the full list, whatever this caller already holds
+			unconditional := r.Clone(r.Context())
+			unconditional.Header.Del("If-None-Match")
+			unconditional.Header.Del("If-Modified-Since")
+			rec := &listRecorder{header: make(http.Header)}
+			next.ServeHTTP(rec, unconditional)
+			cached := &cachedList{status: rec.status, header: rec.header, body: rec.body.Bytes(), expiresAt: time.Now().Add(c.ttl)}
+			if cached.status == http.StatusOK {
+				c.store(key, cached)
+			}
+			return cached, nil
+		})
+		cached := result.(*cachedList)
+		if led {
+			cached.write(w, r, "MISS")
+			return
+		}
+		// A failed list is the leader's to report; the others list for themselves
+		if cached.status != http.StatusOK {
+			c.misses.Add(1)
+			w.Header().Set("X-Cache", "MISS")
+			next.ServeHTTP(w, r)
+			return
+		}
+		c.coalesced.Add(1)
+		cached.write(w, r, "HIT")
+	})
+}
+
+// trackListWriters marks the scopes that change anything through the API, so their lists
+// bypass the list cache
+func (s *Server) trackListWriters(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := s.listCache
+		switch {
+		case c == nil, r.Method == http.MethodGet, r.Method == http.MethodHead, r.Method == http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+		if ww.Status() < http.StatusBadRequest {
+			c.wrote(principalScope(project.PrincipalFromContext(r.Context())))
+		}
+	})
+}
+
+// principalScope identifies what a principal may see. Principals with the same scope get the
+// same lists.
+func principalScope(p *project.Principal) string {
+	if p == nil {
+		return ""
+	}
+	if p.Admin {
+		return "admin"
+	}
+	return p.Organization + "/" + strings.Join(p.Projects, ",")
+}
+
+// bypass reports whether a request must be listed from the database: the caller asked for a
+// fresh list with Cache-Control, or its scope changed something within the TTL
+func (c *listCache) bypass(r *http.Request, scope string) bool {
+	for _, directive := range strings.Split(r.Header.Get("Cache-Control"), ",") {
+		switch strings.TrimSpace(strings.ToLower(directive)) {
+		case "no-cache", "no-store", "max-age=0":
+			return true
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	last, ok := c.writers[scope]
+	return ok && time.Since(last) < c.ttl
+}
+
+// wrote records that scope changed something. Writers older than the TTL are dropped.
+func (c *listCache) wrote(scope string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for s, last := range c.writers {
+		if now.Sub(last) >= c.ttl {
+			delete(c.writers, s)
+		}
+	}
+	c.writers[scope] = now
+}
+
+func (c *listCache) lookup(key string) *cachedList {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(cached.expiresAt) {
+		delete(c.entries, key)
+		return nil
+	}
+	return cached
+}
+
+// store caches a list, dropping expired lists, then the one closest to expiry, when full
+func (c *listCache) store(key string, cached *cachedList) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		now := time.Now()
+		var oldestKey string
+		var oldest time.Time
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+				continue
+			}
+			if oldestKey == "" || e.expiresAt.Before(oldest) {
+				oldestKey, oldest = k, e.expiresAt
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			delete(c.entries, oldestKey)
+		}
+	}
+	c.entries[key] = cached
+}
+
+// write replays a cached list, or 304 Not Modified when the caller holds its ETag
+func (e *cachedList) write(w http.ResponseWriter, r *http.Request, cacheStatus string) {
+	for key, values := range e.header {
+		w.Header()[key] = append([]string(nil), values...)
+	}
+	w.Header().Set("X-Cache", cacheStatus)
+	if e.status == http.StatusOK && notModified(r, e.header.Get("ETag"), time.Time{}) {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(e.status)
+	_, _ = w.Write(e.body)
+}
+
+// listRecorder captures a list response for the cache
+type listRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *listRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *listRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+func (r *listRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(p)
+}
+
+// WriteMetrics writes the list cache's hits, misses, coalesced reads, bypasses and size in the
+// Prometheus text exposition format
+func (c *listCache) WriteMetrics(w io.Writer) error {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+
+	out := bufio.NewWriter(w)
+	for _, m := range []struct {
+		name, help, kind string
+		value            float64
+	}{
+		{"landlord_list_cache_hits_total", "Tenant lists served from the list cache", "counter", float64(c.hits.Load())},
+		{"landlord_list_cache_misses_total", "Tenant lists read from the database because none was cached", "counter", float64(c.misses.Load())},
+		{"landlord_list_cache_coalesced_total", "Tenant lists that shared an identical request's database read", "counter", float64(c.coalesced.Load())},
+		{"landlord_list_cache_bypasses_total", "Tenant lists read from the database because the caller asked for a fresh list or had just made a change", "counter", float64(c.bypasses.Load())},
+		{"landlord_list_cache_entries", "Tenant lists currently cached", "gauge", float64(entries)},
+	} {
+		fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", m.name, m.help, m.name, m.kind, m.name, m.value)
+	}
+	return out.Flush()
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestListCache(t *testing.T) {
+	repo := tenantmemory.New()
+	ctx := context.Background()
+	web := &tenant.Tenant{Name: "web", Status: tenant.StatusReady}
+	if err := repo.CreateTenant(ctx, web); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	srv := &Server{
+		router:                 chi.NewRouter(),
+		logger:                 zap.NewNop(),
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+		tenantRepo:             repo,
+	}
+	srv.registerRoutes()
+	ttl := 100 * time.Millisecond
+	srv.SetListCache(config.ListCacheConfig{
+		Enabled:              true,
+		TTL:                  ttl,
+		MaxEntries:           10,
+		MaxAge:               2 * time.Second,
+		StaleWhileRevalidate: 30 * time.Second,
+	})
+
+	do := func(method, url, body string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		return rec
+	}
+	list := func(header map[string]string) (*httptest.ResponseRecorder, int) {
+		t.Helper()
+		rec := do(http.MethodGet, "/v1/tenants", "", header)
+		if rec.Code == http.StatusNotModified {
+			return rec, -1
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp struct {
+			Total int `json:"total"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return rec, resp.Total
+	}
+	expectCache := func(rec *httptest.ResponseRecorder, want string) {
+		t.Helper()
+		if got := rec.Header().Get("X-Cache"); got != want {
+			t.Fatalf("expected X-Cache %s, got %q", want, got)
+		}
+	}
+
+	first, total := list(nil)
+	expectCache(first, "MISS")
+	if total != 1 {
+		t.Fatalf("expected 1 tenant, got %d", total)
+	}
+	if got := first.Header().Get("Cache-Control"); got != "private, max-age=2, stale-while-revalidate=30" {
+		t.Fatalf("unexpected Cache-Control %q", got)
+	}
+
+	// A change made elsewhere is not seen until the list expires
+	if err := repo.CreateTenant(ctx, &tenant.Tenant{Name: "elsewhere", Status: tenant.StatusReady}); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	hit, total := list(nil)
+	expectCache(hit, "HIT")
+	if total != 1 || hit.Header().Get("ETag") != first.Header().Get("ETag") {
+		t.Fatalf("expected the cached list, got %d tenants", total)
+	}
+	rec, _ := list(map[string]string{"If-None-Match": first.Header().Get("ETag")})
+	expectCache(rec, "HIT")
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("expected a cached 304, got %d", rec.Code)
+	}
+	rec, total = list(map[string]string{"Cache-Control": "no-cache"})
+	expectCache(rec, "BYPASS")
+	if total != 2 {
+		t.Fatalf("expected a fresh list of 2 tenants, got %d", total)
+	}
+
+	time.Sleep(ttl + 10*time.Millisecond)
+	rec, total = list(nil)
+	expectCache(rec, "MISS")
+	if total != 2 {
+		t.Fatalf("expected 2 tenants after expiry, got %d", total)
+	}
+
+	// A caller that changes a tenant sees its change at once
+	if rec := do(http.MethodPost, "/v1/tenants", `{"name": "api", "compute_config": {"image": "nginx:1.27"}}`, nil); rec.Code != http.StatusCreated && rec.Code != http.StatusAccepted {
+		t.Fatalf("expected tenant to be created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec, total = list(nil)
+	expectCache(rec, "BYPASS")
+	if total != 3 {
+		t.Fatalf("expected the writer to see 3 tenants, got %d", total)
+	}
+
+	var metrics strings.Builder
+	if err := srv.listCache.WriteMetrics(&metrics); err != nil {
+		t.Fatalf("write metrics: %v", err)
+	}
+	for _, want := range []string{
+		"landlord_list_cache_hits_total 2\n",
+		"landlord_list_cache_misses_total 2\n",
+		"landlord_list_cache_bypasses_total 2\n",
+		"landlord_list_cache_entries 1\n",
+	} {
+		if !strings.Contains(metrics.String(), want) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, metrics.String())
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/logger"
+	"github.com/jaxxstorm/landlord/internal/project"
+)
+
+// SetLogLevels enables the log level and debug flag endpoints, which override the levels of the
+// server process's loggers. Pass logger.LevelsOf the process logger.
+func (s *Server) SetLogLevels(levels *logger.Levels) {
+	s.logLevels = levels
+}
+
+// handleGetLogLevels returns the server process's log levels
+// @Summary Get log levels
+// @Description Returns the configured log level, the components with loggers in the server process, and the active runtime overrides.
+// @Description Requires an admin API key.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.LogLevelsResponse "Log levels"
+// @Failure 403 {object} models.ErrorResponse "Not an admin"
+// @Failure 503 {object} models.ErrorResponse "Runtime log levels are not available"
+// @Router /v1/admin/loglevel [get]
+func (s *Server) handleGetLogLevels(w http.ResponseWriter, r *http.Request) {
+	if !s.logLevelsAvailable(w, r) {
+		return
+	}
+	s.writeLogLevels(w)
+}
+
+// handleSetLogLevel overrides a component's log level for a bounded duration
+// @Summary Override a component's log level
+// @Description Sets the log level of a component's loggers, such as reconciler, restate-client or docker-provider, in the server process.
+// @Description The level reverts to the configured level when the duration passes; setting it again replaces the override and restarts the duration.
+// @Description Requires an admin API key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body models.SetLogLevelRequest true "Log level override"
+// @Success 200 {object} models.LogLevelsResponse "Log levels"
+// @Failure 400 {object} models.ErrorResponse "Invalid request or unknown component"
+// @Failure 403 {object} models.ErrorResponse "Not an admin"
+// @Failure 503 {object} models.ErrorResponse "Runtime log levels are not available"
+// @Router /v1/admin/loglevel [put]
+func (s *Server) handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.logLevelsAvailable(w, r) {
+		return
+	}
+
+	var req models.SetLogLevelRequest
+	if !s.decodeLogRequest(w, r, &req, requestID) {
+		return
+	}
+	var problems []string
+	if strings.TrimSpace(req.Component) == "" {
+		problems = append(problems, "component is required")
+	}
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil || level < zapcore.DebugLevel || level > zapcore.ErrorLevel {
+		problems = append(problems, "level must be debug, info, warn or error")
+	}
+	duration, err := parseOverrideDuration(req.Duration)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	if len(problems) > 0 {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid log level override", problems, requestID)
+		return
+	}
+
+	if _, err := s.logLevels.SetLevel(req.Component, level, duration, fieldManager(r)); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid log level override", []string{err.Error()}, requestID)
+		return
+	}
+	s.writeLogLevels(w)
+}
+
+// handleResetLogLevel removes a component's log level override
+// @Summary Remove a component's log level override
+// @Description Reverts a component's loggers to the configured level before the override expires.
+// @Description Requires an admin API key.
+// @Tags admin
+// @Produce json
+// @Param component path string true "Component"
+// @Success 200 {object} models.LogLevelsResponse "Log levels"
+// @Failure 403 {object} models.ErrorResponse "Not an admin"
+// @Failure 404 {object} models.ErrorResponse "The component has no override"
+// @Failure 503 {object} models.ErrorResponse "Runtime log levels are not available"
+// @Router /v1/admin/loglevel/{component} [delete]
+func (s *Server) handleResetLogLevel(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.logLevelsAvailable(w, r) {
+		return
+	}
+	if !s.logLevels.ResetLevel(chi.URLParam(r, "component")) {
+		s.writeErrorResponse(w, r, http.StatusNotFound, "The component has no log level override", nil, requestID)
+		return
+	}
+	s.writeLogLevels(w)
+}
+
+// handleGetDebugFlags lists the debug flags and which are on
+// @Summary List debug flags
+// @Description Lists the debug flags the server process checks and which are on.
+// @Description Requires an admin API key.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} models.DebugFlagsResponse "Debug flags"
+// @Failure 403 {object} models.ErrorResponse "Not an admin"
+// @Failure 503 {object} models.ErrorResponse "Runtime log levels are not available"
+// @Router /v1/admin/debug-flags [get]
+func (s *Server) handleGetDebugFlags(w http.ResponseWriter, r *http.Request) {
+	if !s.logLevelsAvailable(w, r) {
+		return
+	}
+	s.writeDebugFlags(w)
+}
+
+// handleSetDebugFlag turns a debug flag on for a bounded duration
+// @Summary Turn a debug flag on
+// @Description Turns a debug flag on in the server process until the duration passes; setting it again restarts the duration.
+// @Description Requires an admin API key.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param body body models.SetDebugFlagRequest true "Debug flag"
+// @Success 200 {object} models.DebugFlagsResponse "Debug flags"
+// @Failure 400 {object} models.ErrorResponse "Invalid request or unknown flag"
+// @Failure 403 {object} models.ErrorResponse "Not an admin"
+// @Failure 503 {object} models.ErrorResponse "Runtime log levels are not available"
+// @Router /v1/admin/debug-flags [put]
+func (s *Server) handleSetDebugFlag(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.logLevelsAvailable(w, r) {
+		return
+	}
+
+	var req models.SetDebugFlagRequest
+	if !s.decodeLogRequest(w, r, &req, requestID) {
+		return
+	}
+	duration, err := parseOverrideDuration(req.Duration)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid debug flag", []string{err.Error()}, requestID)
+		return
+	}
+	if _, err := s.logLevels.SetFlag(req.Flag, duration, fieldManager(r)); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid debug flag", []string{err.Error()}, requestID)
+		return
+	}
+	s.writeDebugFlags(w)
+}
+
+// handleResetDebugFlag turns a debug flag off
+// @Summary Turn a debug flag off
+// @Description Turns a debug flag off before its duration passes.
+// @Description Requires an admin API key.
+// @Tags admin
+// @Produce json
+// @Param flag path string true "Debug flag"
+// @Success 200 {object} models.DebugFlagsResponse "Debug flags"
+// @Failure 403 {object} models.ErrorResponse "Not an admin"
+// @Failure 404 {object} models.ErrorResponse "The flag is not on"
+// @Failure 503 {object} models.ErrorResponse "Runtime log levels are not available"
+// @Router /v1/admin/debug-flags/{flag} [delete]
+func (s *Server) handleResetDebugFlag(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.logLevelsAvailable(w, r) {
+		return
+	}
+	if !s.logLevels.ResetFlag(chi.URLParam(r, "flag")) {
+		s.writeErrorResponse(w, r, http.StatusNotFound, "The debug flag is not on", nil, requestID)
+		return
+	}
+	s.writeDebugFlags(w)
+}
+
+// logLevelsAvailable writes an error and returns false unless the caller is an admin and the
+// server can change its log levels
+func (s *Server) logLevelsAvailable(w http.ResponseWriter, r *http.Request) bool {
+	requestID := r.Header.Get("X-Request-ID")
+	if !project.PrincipalFromContext(r.Context()).Unrestricted() {
+		s.writeErrorResponse(w, r, http.StatusForbidden, "Changing log levels requires an admin API key", nil, requestID)
+		return false
+	}
+	if s.logLevels == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Runtime log levels are not available", nil, requestID)
+		return false
+	}
+	return true
+}
+
+func (s *Server) decodeLogRequest(w http.ResponseWriter, r *http.Request, req interface{}, requestID string) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return false
+	}
+	defer r.Body.Close()
+
+	if err := json.Unmarshal(body, req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return false
+	}
+	return true
+}
+
+// parseOverrideDuration parses an override's duration, defaulting to logger.DefaultOverrideDuration
+func parseOverrideDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return logger.DefaultOverrideDuration, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 || duration > logger.MaxOverrideDuration {
+		return 0, fmt.Errorf("duration must be a duration greater than 0 and at most %s, such as 30m", logger.MaxOverrideDuration)
+	}
+	return duration, nil
+}
+
+func (s *Server) writeLogLevels(w http.ResponseWriter) {
+	resp := models.LogLevelsResponse{
+		BaseLevel:  s.logLevels.Base().String(),
+		Components: s.logLevels.Components(),
+		Overrides:  []models.LogOverride{},
+	}
+	for _, override := range s.logLevels.LevelOverrides() {
+		resp.Overrides = append(resp.Overrides, models.LogOverride{
+			Name:      override.Name,
+			Level:     override.Level,
+			SetBy:     override.SetBy,
+			ExpiresAt: override.ExpiresAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *Server) writeDebugFlags(w http.ResponseWriter) {
+	enabled := make(map[string]logger.Override)
+	for _, override := range s.logLevels.FlagOverrides() {
+		enabled[override.Name] = override
+	}
+
+	resp := models.DebugFlagsResponse{Flags: make([]models.DebugFlagResponse, 0, len(logger.DebugFlags))}
+	for _, info := range logger.DebugFlags {
+		flag := models.DebugFlagResponse{Name: info.Name, Description: info.Description}
+		if override, ok := enabled[info.Name]; ok {
+			expiresAt := override.ExpiresAt
+			flag.Enabled, flag.SetBy, flag.ExpiresAt = true, override.SetBy, &expiresAt
+		}
+		resp.Flags = append(resp.Flags, flag)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
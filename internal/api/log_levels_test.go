@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/logger"
+	"github.com/jaxxstorm/landlord/internal/project"
+)
+
+func TestLogLevels(t *testing.T) {
+	log, err := logger.New("production", "info")
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	reconciler := log.With(zap.String("component", "reconciler"))
+
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop()}
+	srv.registerRoutes()
+
+	call := func(method, path, body string, principal *project.Principal) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(project.WithPrincipal(req.Context(), principal))
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := call(http.MethodGet, "/v1/admin/loglevel", "", nil); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503 without levels, got %d", rec.Code)
+	}
+	srv.SetLogLevels(logger.LevelsOf(log))
+
+	body := `{"component": "reconciler", "level": "debug", "duration": "30m"}`
+	if rec := call(http.MethodPut, "/v1/admin/loglevel", body, &project.Principal{Name: "acme-ci", Organization: "acme"}); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status 403 for a non-admin principal, got %d", rec.Code)
+	}
+	for _, invalid := range []string{
+		`{"component": "reconciler", "level": "verbose"}`,
+		`{"component": "reconciler", "level": "debug", "duration": "72h"}`,
+		`{"component": "reconciler", "level": "fatal"}`,
+		`{"level": "debug"}`,
+		`{"component": "scheduler", "level": "debug"}`,
+	} {
+		if rec := call(http.MethodPut, "/v1/admin/loglevel", invalid, nil); rec.Code != http.StatusBadRequest {
+			t.Errorf("expected status 400 for %s, got %d", invalid, rec.Code)
+		}
+	}
+
+	rec := call(http.MethodPut, "/v1/admin/loglevel", body, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var levels models.LogLevelsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&levels); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if levels.BaseLevel != "info" || len(levels.Overrides) != 1 || levels.Overrides[0].Name != "reconciler" || levels.Overrides[0].Level != "debug" {
+		t.Fatalf("unexpected response %+v", levels)
+	}
+	if !reconciler.Core().Enabled(zapcore.DebugLevel) {
+		t.Error("expected the reconciler to log at debug")
+	}
+
+	if rec := call(http.MethodDelete, "/v1/admin/loglevel/reconciler", "", nil); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if reconciler.Core().Enabled(zapcore.DebugLevel) {
+		t.Error("expected the reconciler to revert to info")
+	}
+	if rec := call(http.MethodDelete, "/v1/admin/loglevel/reconciler", "", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404 without an override, got %d", rec.Code)
+	}
+}
+
+func TestDebugFlagEndpoints(t *testing.T) {
+	log, err := logger.New("production", "info")
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop()}
+	srv.registerRoutes()
+	srv.SetLogLevels(logger.LevelsOf(log))
+
+	call := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := call(http.MethodPut, "/v1/admin/debug-flags", `{"flag": "everything"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for an unknown flag, got %d", rec.Code)
+	}
+	rec := call(http.MethodPut, "/v1/admin/debug-flags", `{"flag": "restate-payloads", "duration": "5m"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var flags models.DebugFlagsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&flags); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	enabled := map[string]bool{}
+	for _, flag := range flags.Flags {
+		enabled[flag.Name] = flag.Enabled
+		if flag.Enabled && flag.ExpiresAt == nil {
+			t.Errorf("expected %s to have an expiry", flag.Name)
+		}
+	}
+	if len(flags.Flags) != len(logger.DebugFlags) || !enabled[logger.FlagRestatePayloads] || enabled[logger.FlagDockerContainerConfig] {
+		t.Fatalf("unexpected flags %+v", flags.Flags)
+	}
+	if !logger.DebugFlag(log, logger.FlagRestatePayloads) {
+		t.Error("expected the flag to be on")
+	}
+
+	if rec := call(http.MethodDelete, "/v1/admin/debug-flags/restate-payloads", ""); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if logger.DebugFlag(log, logger.FlagRestatePayloads) {
+		t.Error("expected the flag to be off")
+	}
+}
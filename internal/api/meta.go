@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/version"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
+	"go.uber.org/zap"
+)
+
+// WorkflowCatalog is implemented by workflow clients that can list registered workflow definitions
+type WorkflowCatalog interface {
+	ListWorkflows(ctx context.Context) (map[string][]workflow.WorkflowDefinition, error)
+}
+
+// handleListWorkflows lists registered workflow IDs and versions per provider.
+// @Summary List workflow definitions
+// @Description Returns registered workflow IDs and versions for each workflow provider. New executions use the latest version; in-flight executions complete on the version they started with.
+// @Tags meta
+// @Produce json
+// @Success 200 {object} models.ListWorkflowsResponse "Registered workflows"
+// @Failure 500 {object} models.ErrorResponse "Failed to list workflows"
+// @Failure 503 {object} models.ErrorResponse "Workflow catalog not configured"
+// @Router /v1/meta/workflows [get]
+func (s *Server) handleListWorkflows(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+
+	catalog, ok := s.workflowClient.(WorkflowCatalog)
+	if !ok || catalog == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Workflow catalog not configured", nil, requestID)
+		return
+	}
+
+	byProvider, err := catalog.ListWorkflows(r.Context())
+	if err != nil {
+		s.logger.Error("failed to list workflows", zap.Error(err))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list workflows", []string{err.Error()}, requestID)
+		return
+	}
+
+	names := make([]string, 0, len(byProvider))
+	for name := range byProvider {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resp := models.ListWorkflowsResponse{
+		LatestVersion: workflow.LatestWorkflowVersion,
+		Providers:     make([]models.WorkflowProviderInfo, 0, len(names)),
+	}
+	for _, name := range names {
+		info := models.WorkflowProviderInfo{
+			Provider:  name,
+			Workflows: make([]models.WorkflowDefinitionInfo, 0, len(byProvider[name])),
+		}
+		for _, def := range byProvider[name] {
+			info.Workflows = append(info.Workflows, models.WorkflowDefinitionInfo{
+				WorkflowID: def.WorkflowID,
+				Version:    def.Version,
+				Latest:     def.Latest,
+			})
+		}
+		resp.Providers = append(resp.Providers, info)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleListSchemas lists the versioned schemas of workflow payloads.
+// @Summary List workflow payload schemas
+// @Description Returns every version of the JSON schemas provision requests and compute callbacks are validated against. Payloads declare their version in schema_version; payloads without one are v1.
+// @Tags meta
+// @Produce json
+// @Success 200 {object} models.ListSchemasResponse "Payload schemas"
+// @Failure 500 {object} models.ErrorResponse "Failed to load schemas"
+// @Router /v1/meta/schemas [get]
+func (s *Server) handleListSchemas(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+
+	schemas, err := schema.List()
+	if err != nil {
+		s.logger.Error("failed to load payload schemas", zap.Error(err))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to load schemas", []string{err.Error()}, requestID)
+		return
+	}
+
+	resp := models.ListSchemasResponse{Schemas: make([]models.SchemaInfo, 0, len(schemas))}
+	for _, sc := range schemas {
+		resp.Schemas = append(resp.Schemas, models.SchemaInfo{
+			Name:        sc.Name,
+			Version:     sc.Version,
+			Latest:      sc.Latest,
+			Description: sc.Description,
+			Document:    sc.Document,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleGetSchema returns one version of a workflow payload schema as a JSON schema document.
+// @Summary Get a workflow payload schema
+// @Description Returns the JSON schema document for one version of a workflow payload.
+// @Tags meta
+// @Produce json
+// @Param name path string true "Schema name (provision-request or compute-callback)"
+// @Param version path string true "Schema version (e.g. v1)"
+// @Success 200 {object} map[string]interface{} "JSON schema"
+// @Failure 404 {object} models.ErrorResponse "Schema not found"
+// @Failure 500 {object} models.ErrorResponse "Failed to load schemas"
+// @Router /v1/meta/schemas/{name}/{version} [get]
+func (s *Server) handleGetSchema(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+
+	sc, ok, err := schema.Get(chi.URLParam(r, "name"), chi.URLParam(r, "version"))
+	if err != nil {
+		s.logger.Error("failed to load payload schemas", zap.Error(err))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to load schemas", []string{err.Error()}, requestID)
+		return
+	}
+	if !ok {
+		s.writeErrorResponse(w, r, http.StatusNotFound, "Schema not found", nil, requestID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(sc.Document)
+}
+
+// trackVersions records the version headers of workers and clients, and answers every request with
+// the server's version so they can detect skew too
+func (s *Server) trackVersions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(version.HeaderVersion, version.Version)
+		if s.versions != nil {
+			s.versions.Observe(r.Header.Get(version.HeaderComponent), r.Header.Get(version.HeaderVersion))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleGetVersion returns the server's build and the versions of the components talking to it.
+// @Summary Get component versions
+// @Description Returns the server's version and commit, the workflow version new executions use, and the versions workers and clients reported in the X-Landlord-Version header since the server started. Component versions more than one minor version from the server are flagged as skewed and listed in warnings.
+// @Tags meta
+// @Produce json
+// @Success 200 {object} models.VersionResponse "Component versions"
+// @Router /v1/meta/version [get]
+func (s *Server) handleGetVersion(w http.ResponseWriter, r *http.Request) {
+	resp := models.VersionResponse{
+		Server:          version.Current(version.ComponentServer),
+		WorkflowVersion: workflow.LatestWorkflowVersion,
+		Components:      []version.Component{},
+		Warnings:        []version.SkewEvent{},
+	}
+	if s.versions != nil {
+		resp.Server = s.versions.Self()
+		resp.Components = s.versions.Components()
+		resp.Warnings = s.versions.Events()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
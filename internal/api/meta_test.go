@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/version"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
+	"go.uber.org/zap"
+)
+
+type catalogWorkflowClient struct {
+	mockWorkflowClient
+	workflows map[string][]workflow.WorkflowDefinition
+	err       error
+}
+
+func (c *catalogWorkflowClient) ListWorkflows(ctx context.Context) (map[string][]workflow.WorkflowDefinition, error) {
+	return c.workflows, c.err
+}
+
+func TestHandleListWorkflows(t *testing.T) {
+	srv := &Server{
+		logger: zap.NewNop(),
+		workflowClient: &catalogWorkflowClient{
+			workflows: map[string][]workflow.WorkflowDefinition{
+				"restate": {{WorkflowID: "tenant-provisioning", Version: "v1", Latest: true}},
+				"mock":    {},
+			},
+		},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/meta/workflows", nil)
+	w := httptest.NewRecorder()
+	srv.handleListWorkflows(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp models.ListWorkflowsResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.LatestVersion != workflow.LatestWorkflowVersion {
+		t.Fatalf("expected latest version %s, got %s", workflow.LatestWorkflowVersion, resp.LatestVersion)
+	}
+	if len(resp.Providers) != 2 || resp.Providers[0].Provider != "mock" || resp.Providers[1].Provider != "restate" {
+		t.Fatalf("expected providers sorted by name, got %+v", resp.Providers)
+	}
+	if resp.Providers[0].Workflows == nil {
+		t.Fatal("expected empty workflows list to encode as an array")
+	}
+	restate := resp.Providers[1].Workflows
+	if len(restate) != 1 || restate[0].WorkflowID != "tenant-provisioning" || !restate[0].Latest {
+		t.Fatalf("unexpected restate workflows: %+v", restate)
+	}
+}
+
+func TestHandleListWorkflowsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		client WorkflowClient
+		status int
+	}{
+		{name: "no catalog", client: &mockWorkflowClient{}, status: http.StatusServiceUnavailable},
+		{name: "nil client", client: nil, status: http.StatusServiceUnavailable},
+		{name: "list failure", client: &catalogWorkflowClient{err: errors.New("boom")}, status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &Server{logger: zap.NewNop(), workflowClient: tt.client}
+			w := httptest.NewRecorder()
+			srv.handleListWorkflows(w, httptest.NewRequest(http.MethodGet, "/v1/meta/workflows", nil))
+			if w.Code != tt.status {
+				t.Fatalf("expected %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandleSchemas(t *testing.T) {
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop()}
+	srv.registerRoutes()
+
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/meta/schemas", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp models.ListSchemasResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Schemas) != 3 || resp.Schemas[2].Name != schema.ProvisionRequest || !resp.Schemas[2].Latest || len(resp.Schemas[2].Document) == 0 {
+		t.Fatalf("unexpected schemas %+v", resp.Schemas)
+	}
+
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/meta/schemas/compute-callback/v1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var document map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&document); err != nil || document["title"] != "ComputeCallback v1" {
+		t.Fatalf("unexpected schema document %v, %v", document, err)
+	}
+
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/meta/schemas/compute-callback/v2", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown version, got %d", w.Code)
+	}
+}
+
+func TestHandleVersion(t *testing.T) {
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop()}
+	srv.versions = version.NewTracker(version.Info{Component: version.ComponentServer, Version: "v1.4.0"}, zap.NewNop())
+	srv.router.Use(srv.trackVersions)
+	srv.registerRoutes()
+
+	for _, reported := range []string{"v1.3.2", "v1.1.0"} {
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set(version.HeaderComponent, version.ComponentWorker)
+		req.Header.Set(version.HeaderVersion, reported)
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		if w.Header().Get(version.HeaderVersion) != version.Version {
+			t.Fatalf("expected the server version in the response, got %q", w.Header().Get(version.HeaderVersion))
+		}
+	}
+
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/meta/version", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp models.VersionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Server.Version != "v1.4.0" || resp.WorkflowVersion != workflow.LatestWorkflowVersion {
+		t.Fatalf("unexpected server version %+v", resp)
+	}
+	if len(resp.Components) != 2 || resp.Components[0].Version != "v1.1.0" || !resp.Components[0].Skewed || resp.Components[1].Skewed {
+		t.Fatalf("unexpected components %+v", resp.Components)
+	}
+	if len(resp.Warnings) != 1 || resp.Warnings[0].Version != "v1.1.0" {
+		t.Fatalf("expected one skew warning, got %+v", resp.Warnings)
+	}
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// handleMigrateTenant moves a tenant to another compute provider
+// @Summary Migrate a tenant to another compute provider
+// @Description Provisions the tenant on the target provider, switches its endpoints over once the target is healthy, then destroys it on the current provider.
+// @Description Progress is reported in the migration field. A failed migration resumes from its failed phase when requested again with the same target.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Param body body models.MigrateTenantRequest true "Tenant migration request"
+// @Success 202 {object} models.TenantResponse "Tenant migration initiated"
+// @Failure 400 {object} models.ErrorResponse "Invalid request, unknown target provider or invalid configuration for the target"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 409 {object} models.ErrorResponse "Tenant cannot migrate in its current state or the target provider is disabled"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/migrate [post]
+func (s *Server) handleMigrateTenant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	identifier := chi.URLParam(r, "id")
+	if strings.TrimSpace(identifier) == "" {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "tenant identifier is required", nil, requestID)
+		return
+	}
+	if isUUIDLike(identifier) {
+		if _, err := uuid.Parse(identifier); err != nil {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "invalid tenant identifier format", []string{err.Error()}, requestID)
+			return
+		}
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	var req models.MigrateTenantRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return
+	}
+	target := strings.TrimSpace(req.TargetProvider)
+	if target == "" {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "target_provider is required", nil, requestID)
+		return
+	}
+
+	t, err := s.lookupTenant(ctx, identifier)
+	if err != nil {
+		if errors.Is(err, tenant.ErrTenantNotFound) {
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant not found", nil, requestID)
+			return
+		}
+		s.logger.Error("failed to get tenant", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+		return
+	}
+
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	migration := t.Migration()
+	switch {
+	case t.Status == tenant.StatusMigrating && migration != nil && migration.Target == target:
+		// Already under way
+		resp := models.ToTenantResponse(t)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(resp)
+		return
+	case migration != nil && migration.Target != target:
+		s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Tenant has an unfinished migration to another provider",
+			[]string{fmt.Sprintf("migration to %s is at %s", migration.Target, migration.Phase)}, requestID)
+		return
+	case t.Status == tenant.StatusFailed && migration != nil:
+		// Resume from the failed phase; the source keeps serving until the switchover phase succeeds
+	case t.Status != tenant.StatusReady:
+		s.writeInvalidStateError(w, r, "Tenant must be ready to migrate", []string{fmt.Sprintf("tenant is %s", t.Status)}, requestID)
+		return
+	default:
+		_, source, err := s.resolveComputeProvider(t.Name, t.DesiredConfig, t.Labels, t.Annotations, nil)
+		if err != nil {
+			s.writeComputeProviderError(w, r, err, requestID)
+			return
+		}
+		if source == target {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Tenant is already on the target compute provider", []string{fmt.Sprintf("compute provider is %s", source)}, requestID)
+			return
+		}
+		migration = &tenant.Migration{Source: source, Target: target, Phase: tenant.MigrationPhaseProvisionTarget}
+	}
+
+	if s.computeRegistry == nil {
+		s.writeComputeProviderError(w, r, errComputeRegistryNotConfigured, requestID)
+		return
+	}
+	// Draining providers receive no new tenants, migrated ones included
+	provider, err := s.computeRegistry.GetForPlacement(target)
+	if err != nil {
+		s.writeComputeProviderError(w, r, err, requestID)
+		return
+	}
+
+	// The tenant's configuration must be valid on the target as it will be after switchover
+	targetConfig := make(map[string]interface{}, len(t.DesiredConfig)+1)
+	for key, value := range t.DesiredConfig {
+		targetConfig[key] = value
+	}
+	targetConfig["compute_provider"] = target
+	configs, err := componentConfigs(targetConfig)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid components configuration", []string{err.Error()}, requestID)
+		return
+	}
+	// Components cut over one at a time would leave the tenant split across providers
+	if len(configs) > 1 {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Tenants with several components cannot be migrated", nil, requestID)
+		return
+	}
+	if err := compute.ValidateConfigAgainstSchema(provider, configs[0].raw); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Compute configuration is not valid for the target provider", configs[0].details(computeSchemaErrorDetails(err)), requestID)
+		return
+	}
+	if err := provider.ValidateConfig(configs[0].raw); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Compute configuration is not valid for the target provider", configs[0].details([]string{err.Error()}), requestID)
+		return
+	}
+
+	previousStatus := t.Status
+	if previousStatus == tenant.StatusReady {
+		t.StartMigration(migration.Source, migration.Target)
+	}
+	t.Status = tenant.StatusMigrating
+	t.StatusMessage = fmt.Sprintf("Migration to %s requested", target)
+	t.WorkflowExecutionID = nil
+	t.WorkflowStartedAt = nil
+	t.WorkflowSubState = nil
+	t.WorkflowRetryCount = nil
+	t.WorkflowErrorMessage = nil
+	if err := tenant.ValidateTransition(previousStatus, t.Status); err != nil {
+		s.writeInvalidStateError(w, r, "Invalid state transition", []string{err.Error()}, requestID)
+		return
+	}
+
+	t.UpdatedAt = time.Now()
+	if err := s.tenantRepo.UpdateTenant(ctx, t); err != nil {
+		if errors.Is(err, tenant.ErrVersionConflict) {
+			s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Tenant was modified concurrently, retry the request", nil, requestID)
+			return
+		}
+		s.logger.Error("failed to update tenant status to migrating", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to initiate migration", nil, requestID)
+		return
+	}
+
+	s.logger.Info("tenant migration requested, awaiting reconciliation",
+		zap.String("tenant_name", t.Name),
+		zap.String("source", migration.Source),
+		zap.String("target", migration.Target),
+		zap.String("phase", string(t.Migration().Phase)),
+		zap.String("request_id", requestID))
+
+	resp := models.ToTenantResponse(t)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(resp)
+}
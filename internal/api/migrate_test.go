@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+func newMigrationTestRegistry() *compute.Registry {
+	registry := compute.NewRegistry(zap.NewNop())
+	_ = registry.Register(&testComputeProvider{name: "docker", schema: json.RawMessage(`{"type":"object"}`)})
+	_ = registry.Register(&testComputeProvider{name: "kubernetes", schema: json.RawMessage(`{"type":"object"}`)})
+	_ = registry.Register(&testComputeProvider{name: "ecs", schema: json.RawMessage(`{"type":"object","required":["task_role_arn"]}`)})
+	return registry
+}
+
+func migrateRequest(tenantID uuid.UUID, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/v1/tenants/"+tenantID.String()+"/migrate", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+		URLParams: chi.RouteParams{Keys: []string{"id"}, Values: []string{tenantID.String()}},
+	}))
+}
+
+func TestMigrateTenant(t *testing.T) {
+	failedMigration := func(target string) *tenant.Tenant {
+		tn := &tenant.Tenant{Status: tenant.StatusFailed, DesiredConfig: map[string]interface{}{"image": "nginx", "compute_provider": "docker"}}
+		tn.StartMigration("docker", target)
+		tn.SetMigrationPhase(tenant.MigrationPhaseSwitchover)
+		return tn
+	}
+
+	tests := []struct {
+		name          string
+		existing      *tenant.Tenant
+		body          string
+		disableTarget bool
+		wantStatus    int
+		wantCode      models.ErrorCode
+		wantPhase     tenant.MigrationPhase
+	}{
+		{
+			name:       "ready tenant starts migration",
+			existing:   &tenant.Tenant{Status: tenant.StatusReady, DesiredConfig: map[string]interface{}{"image": "nginx", "compute_provider": "docker"}},
+			body:       `{"target_provider":"kubernetes"}`,
+			wantStatus: http.StatusAccepted,
+			wantPhase:  tenant.MigrationPhaseProvisionTarget,
+		},
+		{
+			name:       "failed migration resumes from its phase",
+			existing:   failedMigration("kubernetes"),
+			body:       `{"target_provider":"kubernetes"}`,
+			wantStatus: http.StatusAccepted,
+			wantPhase:  tenant.MigrationPhaseSwitchover,
+		},
+		{
+			name:       "failed migration to another target",
+			existing:   failedMigration("ecs"),
+			body:       `{"target_provider":"kubernetes"}`,
+			wantStatus: http.StatusConflict,
+			wantCode:   models.ErrorCodeConflict,
+		},
+		{
+			name:       "missing target",
+			existing:   &tenant.Tenant{Status: tenant.StatusReady},
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   models.ErrorCodeInvalidRequest,
+		},
+		{
+			name:       "already on target",
+			existing:   &tenant.Tenant{Status: tenant.StatusReady, DesiredConfig: map[string]interface{}{"image": "nginx", "compute_provider": "kubernetes"}},
+			body:       `{"target_provider":"kubernetes"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   models.ErrorCodeInvalidRequest,
+		},
+		{
+			name:       "unknown target",
+			existing:   &tenant.Tenant{Status: tenant.StatusReady, DesiredConfig: map[string]interface{}{"image": "nginx", "compute_provider": "docker"}},
+			body:       `{"target_provider":"nomad"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   models.ErrorCodeProviderNotFound,
+		},
+		{
+			name:          "disabled target",
+			existing:      &tenant.Tenant{Status: tenant.StatusReady, DesiredConfig: map[string]interface{}{"image": "nginx", "compute_provider": "docker"}},
+			body:          `{"target_provider":"kubernetes"}`,
+			disableTarget: true,
+			wantStatus:    http.StatusConflict,
+			wantCode:      models.ErrorCodeProviderDisabled,
+		},
+		{
+			name:       "config invalid on target",
+			existing:   &tenant.Tenant{Status: tenant.StatusReady, DesiredConfig: map[string]interface{}{"image": "nginx", "compute_provider": "docker"}},
+			body:       `{"target_provider":"ecs"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   models.ErrorCodeInvalidConfiguration,
+		},
+		{
+			name:       "tenant not ready",
+			existing:   &tenant.Tenant{Status: tenant.StatusProvisioning, DesiredConfig: map[string]interface{}{"image": "nginx", "compute_provider": "docker"}},
+			body:       `{"target_provider":"kubernetes"}`,
+			wantStatus: http.StatusConflict,
+			wantCode:   models.ErrorCodeInvalidStateTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenantID := uuid.New()
+			tt.existing.ID = tenantID
+			tt.existing.Name = "test-tenant"
+
+			var saved *tenant.Tenant
+			registry := newMigrationTestRegistry()
+			if tt.disableTarget {
+				registry.SetEnabled("kubernetes", false)
+			}
+			srv := &Server{
+				logger: zap.NewNop(),
+				tenantRepo: &mockTenantRepo{
+					getByIDFunc: func(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+						return tt.existing, nil
+					},
+					updateFunc: func(ctx context.Context, t *tenant.Tenant) error {
+						saved = t
+						return nil
+					},
+				},
+				computeRegistry: registry,
+			}
+
+			w := httptest.NewRecorder()
+			srv.handleMigrateTenant(w, migrateRequest(tenantID, tt.body))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusAccepted {
+				var problem models.ProblemDetails
+				json.NewDecoder(w.Body).Decode(&problem)
+				if problem.ErrorCode != tt.wantCode {
+					t.Fatalf("expected code %s, got %s", tt.wantCode, problem.ErrorCode)
+				}
+				if saved != nil {
+					t.Fatalf("expected tenant not to be saved, got status %s", saved.Status)
+				}
+				return
+			}
+
+			var resp models.TenantResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Status != string(tenant.StatusMigrating) {
+				t.Errorf("expected status migrating, got %s", resp.Status)
+			}
+			if resp.Migration == nil || resp.Migration.Source != "docker" || resp.Migration.Target != "kubernetes" || resp.Migration.Phase != tt.wantPhase {
+				t.Errorf("expected migration docker -> kubernetes at %s, got %+v", tt.wantPhase, resp.Migration)
+			}
+			if saved == nil || saved.Status != tenant.StatusMigrating {
+				t.Fatal("expected tenant to be saved as migrating")
+			}
+		})
+	}
+}
+
+func TestUpdateMigratingTenantReturns409(t *testing.T) {
+	tenantID := uuid.New()
+	existing := &tenant.Tenant{ID: tenantID, Name: "test-tenant", Status: tenant.StatusMigrating}
+	existing.StartMigration("docker", "kubernetes")
+
+	srv := &Server{
+		logger: zap.NewNop(),
+		tenantRepo: &mockTenantRepo{
+			getByIDFunc: func(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+				return existing, nil
+			},
+		},
+		computeRegistry: newMigrationTestRegistry(),
+	}
+
+	body, _ := json.Marshal(models.UpdateTenantRequest{ComputeConfig: map[string]interface{}{"image": "nginx:2"}})
+	req := httptest.NewRequest(http.MethodPut, "/v1/tenants/"+tenantID.String(), strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+		URLParams: chi.RouteParams{Keys: []string{"id"}, Values: []string{tenantID.String()}},
+	}))
+	w := httptest.NewRecorder()
+
+	srv.handleUpdateTenant(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
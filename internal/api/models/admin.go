@@ -0,0 +1,177 @@
+package models
+
+import "time"
+
+// ProviderResponse is the runtime state of a compute or workflow provider.
+type ProviderResponse struct {
+	// Kind is the provider kind: compute or workflow.
+	Kind string `json:"kind"`
+
+	// Name is the provider identifier (e.g., "docker").
+	Name string `json:"name"`
+
+	// Enabled is false when the provider is drained: disabled compute providers receive no new tenants
+	// and disabled workflow providers start no new executions.
+	Enabled bool `json:"enabled"`
+
+	// Reconfigurable is true when the provider accepts configuration changes at runtime.
+	Reconfigurable bool `json:"reconfigurable"`
+
+	// Config is the runtime configuration, omitted while the provider runs with its startup configuration.
+	Config map[string]interface{} `json:"config,omitempty"`
+
+	// UpdatedBy is the actor that made the last runtime change.
+	UpdatedBy string `json:"updated_by,omitempty"`
+
+	// UpdatedAt is when the last runtime change was made.
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// ListProvidersResponse is the response for GET /v1/admin/providers.
+type ListProvidersResponse struct {
+	// Providers lists compute providers then workflow providers, each sorted by name.
+	Providers []ProviderResponse `json:"providers"`
+}
+
+// ReconfigureProviderRequest is the request body for PUT /v1/admin/providers/{kind}/{name}/config.
+type ReconfigureProviderRequest struct {
+	// Config replaces the provider configuration. For compute providers it is the default
+	// compute_config merged into every tenant's config.
+	Config map[string]interface{} `json:"config"`
+}
+
+// ProviderAuditEntry records one runtime change to a provider.
+type ProviderAuditEntry struct {
+	ID        string                 `json:"id"`
+	Action    string                 `json:"action"`
+	Actor     string                 `json:"actor,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Enabled   bool                   `json:"enabled"`
+	Config    map[string]interface{} `json:"config,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// ListProviderAuditResponse is the response for GET /v1/admin/providers/{kind}/{name}/audit.
+type ListProviderAuditResponse struct {
+	// Entries are the provider's changes, newest first.
+	Entries []ProviderAuditEntry `json:"entries"`
+}
+
+// DoctorResult is one finding of a doctor check.
+type DoctorResult struct {
+	// Check names what was checked, such as "database/migrations" or "workflow/restate/ingress".
+	Check string `json:"check"`
+
+	// Status is pass, warn, fail or skip (the check could not run here).
+	Status string `json:"status"`
+
+	// Severity is critical, warning or info.
+	Severity string `json:"severity"`
+
+	Message string `json:"message"`
+
+	// Fix suggests how to resolve the problem.
+	Fix string `json:"fix,omitempty"`
+
+	// Details lists the affected items, such as tenant IDs.
+	Details []string `json:"details,omitempty"`
+}
+
+// DoctorReportResponse is the response for GET /v1/admin/doctor.
+type DoctorReportResponse struct {
+	// Status is the worst status of any result: fail, warn or pass.
+	Status string `json:"status"`
+
+	// Results lists failures first, then warnings, passes and skipped checks, each by severity.
+	Results []DoctorResult `json:"results"`
+
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// EmergencyRequest represents the request body for an emergency compute operation
+type EmergencyRequest struct {
+	// Action is destroy or stop
+	Action string `json:"action" validate:"required"`
+
+	// Reason explains the operation; it is recorded in the audit log and state history
+	Reason string `json:"reason" validate:"required"`
+}
+
+// ForgetTenantRequest represents the request body for forgetting a tenant's history
+type ForgetTenantRequest struct {
+	// Reason explains the request; it is recorded in the state history
+	Reason string `json:"reason" validate:"required"`
+}
+
+// ForgetTenantResponse is the response for POST /v1/admin/tenants/{id}/forget
+type ForgetTenantResponse struct {
+	TenantID string `json:"tenant_id"`
+
+	// Transitions is how many state history records had their snapshots removed
+	Transitions int `json:"transitions"`
+}
+
+// SetLogLevelRequest is the request body for PUT /v1/admin/loglevel.
+type SetLogLevelRequest struct {
+	// Component is the component whose loggers change level, such as reconciler, restate-client or docker-provider.
+	Component string `json:"component" validate:"required"`
+
+	// Level is debug, info, warn or error.
+	Level string `json:"level" validate:"required"`
+
+	// Duration is how long the level applies before it reverts, such as "30m". Defaults to 15m; at most 24h.
+	Duration string `json:"duration,omitempty"`
+}
+
+// LogOverride is a runtime log level or debug flag that reverts at ExpiresAt.
+type LogOverride struct {
+	// Name is the component or debug flag.
+	Name string `json:"name"`
+
+	// Level is the component's level while the override lasts; empty for debug flags.
+	Level string `json:"level,omitempty"`
+
+	// SetBy is the actor that made the override.
+	SetBy string `json:"set_by"`
+
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// LogLevelsResponse is the response for GET and PUT /v1/admin/loglevel.
+type LogLevelsResponse struct {
+	// BaseLevel is the configured level, used by components without an override.
+	BaseLevel string `json:"base_level"`
+
+	// Components are the components that have loggers in the server process.
+	Components []string `json:"components"`
+
+	// Overrides are the active level overrides, sorted by component.
+	Overrides []LogOverride `json:"overrides"`
+}
+
+// SetDebugFlagRequest is the request body for PUT /v1/admin/debug-flags.
+type SetDebugFlagRequest struct {
+	// Flag is the debug flag to turn on.
+	Flag string `json:"flag" validate:"required"`
+
+	// Duration is how long the flag stays on, such as "30m". Defaults to 15m; at most 24h.
+	Duration string `json:"duration,omitempty"`
+}
+
+// DebugFlagResponse is a debug flag and whether it is on.
+type DebugFlagResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+
+	// SetBy is the actor that turned the flag on.
+	SetBy string `json:"set_by,omitempty"`
+
+	// ExpiresAt is when the flag turns off.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// DebugFlagsResponse is the response for GET and PUT /v1/admin/debug-flags.
+type DebugFlagsResponse struct {
+	Flags []DebugFlagResponse `json:"flags"`
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/approval"
+)
+
+// ApprovalResponse represents a pending or decided approval in API responses.
+type ApprovalResponse struct {
+	ID         string `json:"id"`
+	TenantID   string `json:"tenant_id"`
+	TenantName string `json:"tenant_name"`
+
+	// Operation is the tenant operation awaiting approval: archive or delete.
+	Operation string `json:"operation"`
+
+	// Status is pending, approved or rejected.
+	Status string `json:"status"`
+
+	RequestedBy string     `json:"requested_by"`
+	DecidedBy   string     `json:"decided_by,omitempty"`
+	Reason      string     `json:"reason,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+}
+
+// ListApprovalsResponse is the response for GET /v1/approvals.
+type ListApprovalsResponse struct {
+	// Approvals are sorted newest first.
+	Approvals []ApprovalResponse `json:"approvals"`
+}
+
+// RejectApprovalRequest is the request body for POST /v1/approvals/{id}/reject.
+type RejectApprovalRequest struct {
+	// Reason explains the rejection.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ToApprovalResponse converts an approval to its API representation.
+func ToApprovalResponse(a *approval.Approval) ApprovalResponse {
+	return ApprovalResponse{
+		ID:          a.ID.String(),
+		TenantID:    a.TenantID.String(),
+		TenantName:  a.TenantName,
+		Operation:   string(a.Operation),
+		Status:      string(a.Status),
+		RequestedBy: a.RequestedBy,
+		DecidedBy:   a.DecidedBy,
+		Reason:      a.Reason,
+		CreatedAt:   a.CreatedAt,
+		DecidedAt:   a.DecidedAt,
+	}
+}
@@ -0,0 +1,102 @@
+package models
+
+import "net/http"
+
+// ErrorCode is a stable, machine-readable classification of an API error.
+// Clients should branch on the code rather than the human-readable message.
+type ErrorCode string
+
+const (
+	// ErrorCodeInvalidRequest means the request was malformed or failed validation
+	ErrorCodeInvalidRequest ErrorCode = "INVALID_REQUEST"
+
+	// ErrorCodeInvalidConfiguration means compute_config, hooks or resources were rejected
+	ErrorCodeInvalidConfiguration ErrorCode = "INVALID_CONFIGURATION"
+
+	// ErrorCodeImagePolicyViolation means an image in compute_config is not allowed by the image policy
+	ErrorCodeImagePolicyViolation ErrorCode = "IMAGE_POLICY_VIOLATION"
+
+	// ErrorCodePromotionNotAllowed means the tenants are not linked environments that can be promoted between
+	ErrorCodePromotionNotAllowed ErrorCode = "PROMOTION_NOT_ALLOWED"
+
+	// ErrorCodeProviderRequired means no compute provider was named and none is configured as default
+	ErrorCodeProviderRequired ErrorCode = "PROVIDER_REQUIRED"
+
+	// ErrorCodeProviderNotFound means the named compute provider is not registered
+	ErrorCodeProviderNotFound ErrorCode = "PROVIDER_NOT_FOUND"
+
+	// ErrorCodeProviderDisabled means the compute provider has been disabled for new tenants
+	ErrorCodeProviderDisabled ErrorCode = "PROVIDER_DISABLED"
+
+	// ErrorCodeUnauthorized means the request carried no API key or an unknown one
+	ErrorCodeUnauthorized ErrorCode = "UNAUTHORIZED"
+
+	// ErrorCodeForbidden means the API key is not allowed to act on the resource
+	ErrorCodeForbidden ErrorCode = "FORBIDDEN"
+
+	// ErrorCodeQuotaExceeded means the project has no room for another tenant
+	ErrorCodeQuotaExceeded ErrorCode = "QUOTA_EXCEEDED"
+
+	// ErrorCodeNotFound means the requested resource does not exist
+	ErrorCodeNotFound ErrorCode = "NOT_FOUND"
+
+	// ErrorCodeConflict means the request conflicts with the current state of the resource
+	ErrorCodeConflict ErrorCode = "CONFLICT"
+
+	// ErrorCodeOperationInProgress means another request is changing the same tenant; retry after the Retry-After delay
+	ErrorCodeOperationInProgress ErrorCode = "OPERATION_IN_PROGRESS"
+
+	// ErrorCodeInvalidStateTransition means the tenant cannot move to the requested status
+	ErrorCodeInvalidStateTransition ErrorCode = "INVALID_STATE_TRANSITION"
+
+	// ErrorCodePreconditionFailed means a JSON Patch test operation did not match
+	ErrorCodePreconditionFailed ErrorCode = "PRECONDITION_FAILED"
+
+	// ErrorCodeUnsupportedMediaType means the request content type is not accepted
+	ErrorCodeUnsupportedMediaType ErrorCode = "UNSUPPORTED_MEDIA_TYPE"
+
+	// ErrorCodeVersionRequired means the request path did not include an API version
+	ErrorCodeVersionRequired ErrorCode = "VERSION_REQUIRED"
+
+	// ErrorCodeUnsupportedVersion means the requested API version is not served
+	ErrorCodeUnsupportedVersion ErrorCode = "UNSUPPORTED_VERSION"
+
+	// ErrorCodeWorkflowTriggerFailed means the tenant was saved but its workflow could not be started
+	ErrorCodeWorkflowTriggerFailed ErrorCode = "WORKFLOW_TRIGGER_FAILED"
+
+	// ErrorCodeServiceUnavailable means a dependency of the endpoint is not configured or reachable
+	ErrorCodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
+
+	// ErrorCodeTimeout means the request did not complete before its timeout, usually because
+	// the database or workflow engine stalled
+	ErrorCodeTimeout ErrorCode = "TIMEOUT"
+
+	// ErrorCodeInternal means the server failed unexpectedly
+	ErrorCodeInternal ErrorCode = "INTERNAL_ERROR"
+)
+
+// DefaultErrorCode returns the error code used when a handler does not set a more specific one
+func DefaultErrorCode(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrorCodeInvalidRequest
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return ErrorCodeForbidden
+	case http.StatusNotFound:
+		return ErrorCodeNotFound
+	case http.StatusConflict:
+		return ErrorCodeConflict
+	case http.StatusPreconditionFailed:
+		return ErrorCodePreconditionFailed
+	case http.StatusUnsupportedMediaType:
+		return ErrorCodeUnsupportedMediaType
+	case http.StatusServiceUnavailable:
+		return ErrorCodeServiceUnavailable
+	case http.StatusGatewayTimeout:
+		return ErrorCodeTimeout
+	default:
+		return ErrorCodeInternal
+	}
+}
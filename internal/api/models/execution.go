@@ -0,0 +1,92 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/execution"
+)
+
+// ExecutionStepRequest is the request body for POST /v1/executions/{id}/steps, which workers
+// send as they start and finish each step of a workflow.
+type ExecutionStepRequest struct {
+	// TenantID is the tenant's UUID or name.
+	TenantID string `json:"tenant_id"`
+
+	// Step is validate, provision, update, destroy, migrate, health-check or finalize.
+	Step string `json:"step"`
+
+	// Status is running, succeeded or failed.
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// ExecutionStepResponse represents one step of a workflow execution in API responses.
+type ExecutionStepResponse struct {
+	Name string `json:"name"`
+
+	// Status is running, succeeded or failed.
+	Status string `json:"status"`
+
+	// Attempts counts how many times the step started.
+	Attempts int `json:"attempts"`
+
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// DurationMs is how long the latest attempt ran, or has been running.
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// ExecutionResponse is the response for GET /v1/executions/{id}.
+type ExecutionResponse struct {
+	ExecutionID string `json:"execution_id"`
+	TenantID    string `json:"tenant_id"`
+	TenantName  string `json:"tenant_name"`
+
+	// State is the execution's state as the workflow provider reports it, when it can be read.
+	State string `json:"state,omitempty"`
+
+	// Steps are listed in the order they first started.
+	Steps []ExecutionStepResponse `json:"steps"`
+}
+
+// ToExecutionStepResponse converts an execution step to its API representation.
+func ToExecutionStepResponse(s *execution.Step, now time.Time) ExecutionStepResponse {
+	return ExecutionStepResponse{
+		Name:       s.Name,
+		Status:     string(s.Status),
+		Attempts:   s.Attempts,
+		Error:      s.Error,
+		StartedAt:  s.StartedAt,
+		FinishedAt: s.FinishedAt,
+		DurationMs: s.Duration(now).Milliseconds(),
+	}
+}
+
+// WorkflowCallbackRequest is the request body for POST /v1/workflow-callbacks, which external
+// orchestrators send as an execution started by the webhook workflow provider progresses.
+type WorkflowCallbackRequest struct {
+	ExecutionID string `json:"execution_id"`
+
+	// State is pending, running, succeeded, failed, timed_out or cancelled.
+	State string `json:"state"`
+
+	// Output is the execution's result, kept when it finishes.
+	Output json.RawMessage `json:"output,omitempty"`
+
+	// Error describes why a failed execution failed, or an error a running one is retrying.
+	Error *WorkflowCallbackError `json:"error,omitempty"`
+
+	// Message is a short description of what the execution is doing.
+	Message string `json:"message,omitempty"`
+}
+
+// WorkflowCallbackError describes an execution's error in a workflow callback.
+type WorkflowCallbackError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/jaxxstorm/landlord/internal/featureflag"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/version"
+)
+
+// WorkflowDefinitionInfo describes a registered workflow at a specific version.
+type WorkflowDefinitionInfo struct {
+	// WorkflowID is the provider workflow identifier (e.g., "tenant-provisioning").
+	WorkflowID string `json:"workflow_id"`
+
+	// Version is the workflow definition version (e.g., "v1").
+	Version string `json:"version"`
+
+	// Latest is true for the version new executions start on.
+	Latest bool `json:"latest"`
+}
+
+// WorkflowProviderInfo lists the workflows registered with a workflow provider.
+type WorkflowProviderInfo struct {
+	// Provider is the workflow provider identifier (e.g., "restate").
+	Provider string `json:"provider"`
+
+	// Workflows are the registered workflow definitions, empty if the provider cannot list them.
+	Workflows []WorkflowDefinitionInfo `json:"workflows"`
+}
+
+// ListWorkflowsResponse is the response for GET /v1/meta/workflows.
+type ListWorkflowsResponse struct {
+	// LatestVersion is the workflow version used for new executions.
+	LatestVersion string `json:"latest_version"`
+
+	// Providers lists workflow definitions per provider, sorted by provider name.
+	Providers []WorkflowProviderInfo `json:"providers"`
+}
+
+// SchemaInfo describes one version of a workflow payload schema.
+type SchemaInfo struct {
+	// Name is the payload the schema describes (e.g., "provision-request").
+	Name string `json:"name"`
+
+	// Version is the schema version payloads declare in schema_version (e.g., "v1").
+	Version string `json:"version"`
+
+	// Latest is true for the version this landlord writes.
+	Latest bool `json:"latest"`
+
+	// Description explains where the payload is sent.
+	Description string `json:"description"`
+
+	// Document is the JSON schema.
+	Document json.RawMessage `json:"document"`
+}
+
+// ListSchemasResponse is the response for GET /v1/meta/schemas.
+type ListSchemasResponse struct {
+	// Schemas lists every payload schema version, sorted by name and version.
+	Schemas []SchemaInfo `json:"schemas"`
+}
+
+// VersionResponse is the response for GET /v1/meta/version.
+type VersionResponse struct {
+	// Server is the build of the API server answering the request.
+	Server version.Info `json:"server"`
+
+	// WorkflowVersion is the workflow definition version new executions start on.
+	WorkflowVersion string `json:"workflow_version"`
+
+	// Components lists the worker and client versions that have reported themselves since the server started.
+	Components []version.Component `json:"components"`
+
+	// Warnings are the component versions first seen more than one minor version from the server.
+	Warnings []version.SkewEvent `json:"warnings"`
+}
+
+// ListFeatureFlagsResponse is the response for GET /v1/meta/feature-flags
+type ListFeatureFlagsResponse struct {
+	// AnnotationPrefix is prefixed to a flag's name to set it on a tenant.
+	AnnotationPrefix string `json:"annotation_prefix"`
+
+	// Flags are the declared flags, sorted by name.
+	Flags []featureflag.Definition `json:"flags"`
+}
+
+// ListReasonCodesResponse is the response for GET /v1/meta/reason-codes
+type ListReasonCodesResponse struct {
+	// ReasonCodes are the codes state transitions and conditions carry, with an English description
+	// UIs can fall back to when they have no localized string.
+	ReasonCodes []tenant.ReasonCodeInfo `json:"reason_codes"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/operation"
+)
+
+// BulkActionRequest is the request body for POST /v1/tenants:bulk-action.
+type BulkActionRequest struct {
+	// Action is archive, suspend, resume, restart or retry.
+	Action string `json:"action"`
+
+	// Selector chooses the tenants whose labels match all of its key=value pairs. It must not be empty.
+	Selector map[string]string `json:"selector"`
+
+	// Organization and Project limit the selection further, as on GET /v1/tenants.
+	Organization string `json:"organization,omitempty"`
+	Project      string `json:"project,omitempty"`
+
+	// Component limits a restart to one of each tenant's components.
+	Component string `json:"component,omitempty"`
+}
+
+// OperationTenantResult is the outcome of a bulk operation's action on one tenant.
+type OperationTenantResult struct {
+	TenantID   string `json:"tenant_id"`
+	TenantName string `json:"tenant_name"`
+
+	// Status is pending, succeeded, failed or pending_approval.
+	Status string `json:"status"`
+
+	// Message is the error for failed tenants, or the approval awaiting a decision.
+	Message string `json:"message,omitempty"`
+}
+
+// OperationResponse represents a bulk operation in API responses.
+type OperationResponse struct {
+	ID        string            `json:"id"`
+	Action    string            `json:"action"`
+	Selector  map[string]string `json:"selector"`
+	Component string            `json:"component,omitempty"`
+
+	// Status is running, succeeded, or failed when the action failed on any tenant.
+	Status string `json:"status"`
+
+	RequestedBy string `json:"requested_by"`
+
+	// Tenants are listed in the order they are processed.
+	Tenants []OperationTenantResult `json:"tenants"`
+
+	Total           int        `json:"total"`
+	Succeeded       int        `json:"succeeded"`
+	Failed          int        `json:"failed"`
+	PendingApproval int        `json:"pending_approval"`
+	CreatedAt       time.Time  `json:"created_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+}
+
+// ToOperationResponse converts an operation to its API representation.
+func ToOperationResponse(o *operation.Operation) OperationResponse {
+	resp := OperationResponse{
+		ID:              o.ID.String(),
+		Action:          string(o.Action),
+		Selector:        o.Selector,
+		Component:       o.Component,
+		Status:          string(o.Status),
+		RequestedBy:     o.RequestedBy,
+		Tenants:         make([]OperationTenantResult, 0, len(o.Tenants)),
+		Total:           len(o.Tenants),
+		Succeeded:       o.Succeeded,
+		Failed:          o.Failed,
+		PendingApproval: o.PendingApproval,
+		CreatedAt:       o.CreatedAt,
+		CompletedAt:     o.CompletedAt,
+	}
+	for _, result := range o.Tenants {
+		resp.Tenants = append(resp.Tenants, OperationTenantResult{
+			TenantID:   result.TenantID.String(),
+			TenantName: result.TenantName,
+			Status:     string(result.Status),
+			Message:    result.Message,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,88 @@
+package models
+
+import "strings"
+
+// ProblemContentType is the media type of RFC 7807 problem detail responses
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem detail error response
+type ProblemDetails struct {
+	// Type is a URI identifying the problem type; it is stable for a given ErrorCode
+	Type string `json:"type"`
+
+	// Title is a short summary of the problem type
+	Title string `json:"title"`
+
+	// Status is the HTTP status code
+	Status int `json:"status"`
+
+	// Detail explains this occurrence of the problem
+	Detail string `json:"detail,omitempty"`
+
+	// Instance is the request path that produced the problem
+	Instance string `json:"instance,omitempty"`
+
+	// ErrorCode classifies the error; see ErrorCode for the stable set of values
+	ErrorCode ErrorCode `json:"error_code"`
+
+	// RequestID is the correlation ID from the request context
+	RequestID string `json:"request_id,omitempty"`
+
+	// Errors lists individual validation failures, when there are several
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ProblemType returns the problem type URI for an error code
+func ProblemType(code ErrorCode) string {
+	return "urn:landlord:problem:" + strings.ReplaceAll(strings.ToLower(string(code)), "_", "-")
+}
+
+// Title returns the problem title for an error code
+func (c ErrorCode) Title() string {
+	switch c {
+	case ErrorCodeInvalidRequest:
+		return "Invalid request"
+	case ErrorCodeInvalidConfiguration:
+		return "Invalid configuration"
+	case ErrorCodeImagePolicyViolation:
+		return "Image policy violation"
+	case ErrorCodePromotionNotAllowed:
+		return "Promotion not allowed"
+	case ErrorCodeProviderRequired:
+		return "Compute provider required"
+	case ErrorCodeProviderNotFound:
+		return "Compute provider not found"
+	case ErrorCodeProviderDisabled:
+		return "Compute provider disabled"
+	case ErrorCodeUnauthorized:
+		return "Unauthorized"
+	case ErrorCodeForbidden:
+		return "Forbidden"
+	case ErrorCodeQuotaExceeded:
+		return "Quota exceeded"
+	case ErrorCodeNotFound:
+		return "Not found"
+	case ErrorCodeConflict:
+		return "Conflict"
+	case ErrorCodeOperationInProgress:
+		return "Operation in progress"
+	case ErrorCodeInvalidStateTransition:
+		return "Invalid state transition"
+	case ErrorCodePreconditionFailed:
+		return "Precondition failed"
+	case ErrorCodeUnsupportedMediaType:
+		return "Unsupported media type"
+	case ErrorCodeVersionRequired:
+		return "API version required"
+	case ErrorCodeUnsupportedVersion:
+		return "Unsupported API version"
+	case ErrorCodeWorkflowTriggerFailed:
+		return "Workflow trigger failed"
+	case ErrorCodeServiceUnavailable:
+		return "Service unavailable"
+	case ErrorCodeTimeout:
+		return "Request timed out"
+	default:
+		return "Internal error"
+	}
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/project"
+)
+
+// CreateOrganizationRequest is the request body for POST /v1/organizations.
+type CreateOrganizationRequest struct {
+	// Name is the unique organization name: lowercase letters, digits and hyphens.
+	Name string `json:"name"`
+
+	// DisplayName is an optional human-friendly name.
+	DisplayName string `json:"display_name,omitempty"`
+}
+
+// OrganizationResponse represents an organization in API responses.
+type OrganizationResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"display_name,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ListOrganizationsResponse is the response for GET /v1/organizations.
+type ListOrganizationsResponse struct {
+	Organizations []OrganizationResponse `json:"organizations"`
+}
+
+// CreateProjectRequest is the request body for POST /v1/organizations/{org}/projects.
+type CreateProjectRequest struct {
+	// Name is the project name, unique within its organization.
+	Name string `json:"name"`
+
+	// DisplayName is an optional human-friendly name.
+	DisplayName string `json:"display_name,omitempty"`
+
+	// Settings are the project's quota, notification and template policies.
+	Settings project.Settings `json:"settings"`
+}
+
+// UpdateProjectRequest is the request body for PUT /v1/organizations/{org}/projects/{project}.
+type UpdateProjectRequest struct {
+	// DisplayName replaces the project's display name.
+	DisplayName string `json:"display_name,omitempty"`
+
+	// Settings replaces the project's settings.
+	Settings project.Settings `json:"settings"`
+}
+
+// ProjectResponse represents a project in API responses.
+type ProjectResponse struct {
+	ID           string           `json:"id"`
+	Organization string           `json:"organization"`
+	Name         string           `json:"name"`
+	DisplayName  string           `json:"display_name,omitempty"`
+	Settings     project.Settings `json:"settings"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// ListProjectsResponse is the response for GET /v1/organizations/{org}/projects.
+type ListProjectsResponse struct {
+	Projects []ProjectResponse `json:"projects"`
+}
+
+// ToOrganizationResponse converts an organization to its API representation.
+func ToOrganizationResponse(org *project.Organization) OrganizationResponse {
+	return OrganizationResponse{
+		ID:          org.ID.String(),
+		Name:        org.Name,
+		DisplayName: org.DisplayName,
+		CreatedAt:   org.CreatedAt,
+		UpdatedAt:   org.UpdatedAt,
+	}
+}
+
+// ToProjectResponse converts a project to its API representation.
+func ToProjectResponse(p *project.Project) ProjectResponse {
+	return ProjectResponse{
+		ID:           p.ID.String(),
+		Organization: p.Organization,
+		Name:         p.Name,
+		DisplayName:  p.DisplayName,
+		Settings:     p.Settings,
+		CreatedAt:    p.CreatedAt,
+		UpdatedAt:    p.UpdatedAt,
+	}
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/schedule"
+)
+
+// ScheduleRequest is the request body for creating or replacing a tenant schedule.
+type ScheduleRequest struct {
+	// Name identifies the schedule within its tenant.
+	Name string `json:"name"`
+
+	// Cron is a five-field cron expression (minute hour day-of-month month day-of-week)
+	// or a descriptor such as "@daily".
+	Cron string `json:"cron"`
+
+	// Timezone is the IANA time zone the expression is evaluated in. Defaults to UTC.
+	Timezone string `json:"timezone,omitempty"`
+
+	// Action is restart, suspend, resume or run_hook.
+	Action string `json:"action"`
+
+	// Hook names the hook from desired_config.hooks that a run_hook schedule runs.
+	Hook string `json:"hook,omitempty"`
+
+	// Enabled defaults to true. Disabled schedules keep their history but never fire.
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// ScheduleResponse represents a tenant schedule in API responses.
+type ScheduleResponse struct {
+	ID        string     `json:"id"`
+	TenantID  string     `json:"tenant_id"`
+	Name      string     `json:"name"`
+	Cron      string     `json:"cron"`
+	Timezone  string     `json:"timezone,omitempty"`
+	Action    string     `json:"action"`
+	Hook      string     `json:"hook,omitempty"`
+	Enabled   bool       `json:"enabled"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// ListSchedulesResponse is the response for GET /v1/tenants/{id}/schedules.
+type ListSchedulesResponse struct {
+	// Schedules are sorted by name.
+	Schedules []ScheduleResponse `json:"schedules"`
+}
+
+// ScheduleRunResponse represents one firing of a schedule in API responses.
+type ScheduleRunResponse struct {
+	ID         string `json:"id"`
+	ScheduleID string `json:"schedule_id"`
+	Action     string `json:"action"`
+
+	// Status is running, succeeded, failed or skipped.
+	Status string `json:"status"`
+
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Message     string     `json:"message,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	Output      string     `json:"output,omitempty"`
+}
+
+// ListScheduleRunsResponse is the response for GET /v1/tenants/{id}/schedules/{schedule}/runs.
+type ListScheduleRunsResponse struct {
+	// Runs are sorted newest first.
+	Runs []ScheduleRunResponse `json:"runs"`
+}
+
+// ToScheduleResponse converts a schedule to its API representation.
+func ToScheduleResponse(s *schedule.Schedule) ScheduleResponse {
+	return ScheduleResponse{
+		ID:        s.ID.String(),
+		TenantID:  s.TenantID.String(),
+		Name:      s.Name,
+		Cron:      s.Cron,
+		Timezone:  s.Timezone,
+		Action:    string(s.Action),
+		Hook:      s.Hook,
+		Enabled:   s.Enabled,
+		NextRunAt: s.NextRunAt,
+		LastRunAt: s.LastRunAt,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+// ToScheduleRunResponse converts a schedule run to its API representation.
+func ToScheduleRunResponse(r *schedule.Run) ScheduleRunResponse {
+	return ScheduleRunResponse{
+		ID:          r.ID.String(),
+		ScheduleID:  r.ScheduleID.String(),
+		Action:      string(r.Action),
+		Status:      string(r.Status),
+		ScheduledAt: r.ScheduledAt,
+		StartedAt:   r.StartedAt,
+		CompletedAt: r.CompletedAt,
+		Message:     r.Message,
+		Error:       r.Error,
+		Output:      r.Output,
+	}
+}
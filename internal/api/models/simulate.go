@@ -0,0 +1,71 @@
+package models
+
+import "github.com/jaxxstorm/landlord/internal/cost"
+
+// SimulateRequest is a set of hypothetical tenants to plan, such as an onboarding wave
+type SimulateRequest struct {
+	// Tenants are create requests, simulated in order
+	Tenants []CreateTenantRequest `json:"tenants"`
+}
+
+// SimulateResponse describes what creating the simulated tenants would do. Nothing is created.
+type SimulateResponse struct {
+	// Feasible is true when every tenant could be created
+	Feasible bool `json:"feasible"`
+
+	// Tenants lists each simulated tenant, in request order
+	Tenants []SimulatedTenant `json:"tenants"`
+
+	// Quotas reports the quota of every project the tenants land in
+	Quotas []SimulatedQuota `json:"quotas"`
+
+	// Placements counts the tenants that could be created on each compute provider
+	Placements map[string]int `json:"placements"`
+
+	// Cost totals the estimates of the tenants that could be created; omitted when no rates are configured
+	Cost *cost.Estimate `json:"cost,omitempty"`
+}
+
+// SimulatedTenant is the outcome of simulating one tenant
+type SimulatedTenant struct {
+	Name string `json:"name"`
+
+	// Project is the organization/project the tenant would belong to
+	Project string `json:"project,omitempty"`
+
+	// ComputeProvider is the provider the tenant would be placed on
+	ComputeProvider string `json:"compute_provider,omitempty"`
+
+	// Placement says how the provider was chosen: explicit, rule, default or none
+	Placement string `json:"placement"`
+
+	// Rule names the resolution rule that chose the provider
+	Rule string `json:"rule,omitempty"`
+
+	// Components lists the tenant's components
+	Components []string `json:"components,omitempty"`
+
+	// Violations lists why the tenant could not be created, including quota left to earlier tenants of the request
+	Violations []Violation `json:"violations"`
+
+	// Cost estimates what the tenant would cost to run; omitted when its provider has no rates
+	Cost *cost.Estimate `json:"cost,omitempty"`
+}
+
+// SimulatedQuota is a project's tenant quota after the simulated tenants
+type SimulatedQuota struct {
+	// Project is the organization/project
+	Project string `json:"project"`
+
+	// MaxTenants is the project's quota; 0 is unlimited
+	MaxTenants int `json:"max_tenants"`
+
+	// Existing counts the tenants the project already holds
+	Existing int `json:"existing"`
+
+	// Requested counts the simulated tenants that could be created in the project
+	Requested int `json:"requested"`
+
+	// Remaining is the quota left after them; omitted when the project is unlimited
+	Remaining *int `json:"remaining,omitempty"`
+}
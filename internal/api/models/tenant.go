@@ -3,7 +3,11 @@ package models
 import (
 	"time"
 
+	"github.com/jaxxstorm/landlord/internal/endpointauth"
+	"github.com/jaxxstorm/landlord/internal/resolution"
 	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/uptime"
+	"github.com/jaxxstorm/landlord/internal/workflow"
 )
 
 // CreateTenantRequest represents the request body for creating a new tenant
@@ -20,6 +24,13 @@ type CreateTenantRequest struct {
 
 	// Annotations are key-value pairs for metadata
 	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Organization and Project place the tenant; both default from the caller's API key scope
+	Organization string `json:"organization,omitempty"`
+	Project      string `json:"project,omitempty"`
+
+	// Template names a compute_config template of the project that ComputeConfig is merged over
+	Template string `json:"template,omitempty"`
 }
 
 // UpdateTenantRequest represents the request body for updating a tenant
@@ -38,6 +49,36 @@ type UpdateTenantRequest struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+// MigrateTenantRequest represents the request body for moving a tenant to another compute provider
+type MigrateTenantRequest struct {
+	// TargetProvider is the compute provider to move the tenant to
+	TargetProvider string `json:"target_provider" validate:"required"`
+}
+
+// RestartTenantRequest represents the optional request body for restarting a tenant's workloads
+type RestartTenantRequest struct {
+	// Component restricts the restart to one of the tenant's components; all are restarted when empty
+	Component string `json:"component,omitempty"`
+}
+
+// TransferTenantOwnershipRequest represents the request body for transferring a tenant to another owner
+type TransferTenantOwnershipRequest struct {
+	// Owner is the name of the API key that becomes the tenant's owner
+	Owner string `json:"owner"`
+}
+
+// PromoteTenantRequest represents the request body for promoting a tenant to the next environment
+type PromoteTenantRequest struct {
+	// Target names the tenant to promote to; defaults to the source's landlord/promotes_to annotation
+	Target string `json:"target,omitempty"`
+}
+
+// RejectPromotionRequest represents the request body for rejecting a pending promotion
+type RejectPromotionRequest struct {
+	// Reason explains the rejection and is recorded in the tenant's history
+	Reason string `json:"reason,omitempty"`
+}
+
 // TenantResponse represents a tenant in API responses
 type TenantResponse struct {
 	// ID is the internal database identifier (UUID)
@@ -46,6 +87,9 @@ type TenantResponse struct {
 	// Name is the user-facing stable identifier
 	Name string `json:"name"`
 
+	// ProjectID is the project the tenant belongs to
+	ProjectID string `json:"project_id"`
+
 	// Status represents where the tenant is in its lifecycle
 	Status string `json:"status"`
 
@@ -58,6 +102,9 @@ type TenantResponse struct {
 	// ComputeConfig is the provider-specific compute configuration
 	ComputeConfig map[string]interface{} `json:"compute_config,omitempty"`
 
+	// ManagedFields records which actor last set each top-level compute_config field
+	ManagedFields map[string]tenant.ManagedField `json:"managed_fields,omitempty"`
+
 	// ObservedConfig is the actual configuration applied to running resources
 	ObservedConfig map[string]interface{} `json:"observed_config,omitempty"`
 
@@ -76,6 +123,45 @@ type TenantResponse struct {
 	// WorkflowErrorMessage is the latest workflow error message
 	WorkflowErrorMessage *string `json:"workflow_error_message,omitempty"`
 
+	// WorkflowVersion is the workflow definition version of the current or last execution
+	WorkflowVersion *string `json:"workflow_version,omitempty"`
+
+	// WorkflowStartedAt is when the current workflow execution started
+	WorkflowStartedAt *time.Time `json:"workflow_started_at,omitempty"`
+
+	// LastAction is the last workflow the controller started for the tenant: provision, update,
+	// migrate, delete or archive
+	LastAction string `json:"last_action,omitempty"`
+
+	// LastActionSource is what started it: controller, controller:outbox or controller:config-change
+	LastActionSource string `json:"last_action_source,omitempty"`
+
+	// LastActionAt is when it was started
+	LastActionAt *time.Time `json:"last_action_at,omitempty"`
+
+	// NextPlannedAction is the workflow the controller starts for the tenant next, or finalize while
+	// a removed tenant waits for its finalizers. Empty while a workflow runs or none is needed.
+	NextPlannedAction string `json:"next_planned_action,omitempty"`
+
+	// Migration is the in-progress or failed move to another compute provider
+	Migration *tenant.Migration `json:"migration,omitempty"`
+
+	// Promotion is the change from another environment awaiting approval
+	Promotion *tenant.Promotion `json:"promotion,omitempty"`
+
+	// Conditions are observations about the tenant alongside its status, such as image policy compliance
+	Conditions []tenant.Condition `json:"conditions,omitempty"`
+
+	// Finalizers name the components that must clean up after the tenant before it is deleted or archived
+	Finalizers []string `json:"finalizers,omitempty"`
+
+	// Owner is the API key that created the tenant or was given it; only it, its collaborators and
+	// admins may change the tenant. Empty when the tenant has no owner.
+	Owner string `json:"owner,omitempty"`
+
+	// Collaborators are the API keys the owner shared the tenant with
+	Collaborators []string `json:"collaborators,omitempty"`
+
 	// CreatedAt is when the tenant was first created
 	CreatedAt time.Time `json:"created_at"`
 
@@ -103,11 +189,173 @@ type ListTenantsResponse struct {
 	Offset int `json:"offset"` // Starting position
 }
 
-// ErrorResponse represents a standardized error response
+// TenantSummary is a tenant as listed by GET /v1/tenants?view=summary
+type TenantSummary struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Status    string            `json:"status"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// ListTenantSummariesResponse represents a paginated list of tenant summaries
+type ListTenantSummariesResponse struct {
+	// Tenants is the array of tenant summaries
+	Tenants []TenantSummary `json:"tenants"`
+
+	// Pagination metadata
+	Total  int `json:"total"`  // Total number of tenants
+	Limit  int `json:"limit"`  // Number of items per page
+	Offset int `json:"offset"` // Starting position
+}
+
+// ListTenantFieldsResponse is a paginated list of tenants holding only the fields selected with ?fields=
+type ListTenantFieldsResponse struct {
+	// Tenants holds the selected fields of each tenant
+	Tenants []map[string]interface{} `json:"tenants"`
+
+	// Pagination metadata
+	Total  int `json:"total"`  // Total number of tenants
+	Limit  int `json:"limit"`  // Number of items per page
+	Offset int `json:"offset"` // Starting position
+}
+
+// TenantHistoryResponse is the response for GET /v1/tenants/{id}/history
+type TenantHistoryResponse struct {
+	// Transitions are the tenant's state transitions, newest first
+	Transitions []tenant.StateTransition `json:"transitions"`
+
+	// Limit is the maximum number of transitions in a page
+	Limit int `json:"limit"`
+
+	// NextCursor is set when more transitions match; pass it as cursor for the next page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// TenantResolutionResponse is the response for GET /v1/tenants/{id}/resolution. It explains which
+// compute provider the tenant resolves to and how every resolution rule evaluated.
+type TenantResolutionResponse struct {
+	TenantID   string `json:"tenant_id"`
+	TenantName string `json:"tenant_name"`
+
+	resolution.Explanation
+}
+
+// TenantEffectiveConfigResponse is the response for GET /v1/tenants/{id}/effective-config. It shows
+// the config each of the tenant's components is sent to its compute provider with, and where it came from.
+type TenantEffectiveConfigResponse struct {
+	TenantID        string `json:"tenant_id"`
+	TenantName      string `json:"tenant_name"`
+	ComputeProvider string `json:"compute_provider"`
+
+	// Components are the tenant's components, or one unnamed component for a tenant without components
+	Components []EffectiveComponent `json:"components"`
+
+	// Resources are the declared resources whose credentials the worker adds to env when it provisions them
+	Resources []string `json:"resources,omitempty"`
+
+	Sources EffectiveConfigSources `json:"sources"`
+}
+
+// EffectiveComponent is the compute config and labels one component is provisioned with
+type EffectiveComponent struct {
+	Name      string `json:"name,omitempty"`
+	ComputeID string `json:"compute_id"`
+
+	// Config is the component's config with endpoint auth injected and compute provider defaults merged under it
+	Config map[string]interface{} `json:"config"`
+
+	// Labels are the managed labels and compute metadata the worker adds to the component's compute
+	Labels map[string]string `json:"labels"`
+
+	// SecretEnv are the env vars in Config whose values are credentials, shown redacted
+	SecretEnv []string `json:"secret_env,omitempty"`
+
+	// ProviderDefaults are the top-level fields the compute provider's current defaults fill in or add to
+	ProviderDefaults []string `json:"provider_defaults,omitempty"`
+}
+
+// EffectiveConfigSources records what contributed to the tenant's desired config when it was admitted
+type EffectiveConfigSources struct {
+	// Template is the project template the tenant was created from
+	Template string `json:"template,omitempty"`
+
+	// AppliedPolicies are the project policies whose defaults the tenant received
+	AppliedPolicies []string `json:"applied_policies,omitempty"`
+
+	// AppliedDefaults are the top-level fields project policy defaults filled in
+	AppliedDefaults []string `json:"applied_defaults,omitempty"`
+
+	// ProviderDefaults are the top-level fields compute provider defaults filled in at admission
+	ProviderDefaults []string `json:"provider_defaults,omitempty"`
+}
+
+// TenantUptimeResponse is the response for GET /v1/tenants/{id}/uptime
+type TenantUptimeResponse struct {
+	TenantID   string `json:"tenant_id"`
+	TenantName string `json:"tenant_name"`
+
+	uptime.Report
+}
+
+// TenantStatusResponse is the response for GET /v1/tenants/{id}/status, the parts of a tenant
+// that change as it moves through its lifecycle
+type TenantStatusResponse struct {
+	ID                   string     `json:"id"`
+	Name                 string     `json:"name"`
+	Status               string     `json:"status"`
+	StatusMessage        string     `json:"status_message,omitempty"`
+	WorkflowExecutionID  *string    `json:"workflow_execution_id,omitempty"`
+	WorkflowSubState     *string    `json:"workflow_sub_state,omitempty"`
+	WorkflowErrorMessage *string    `json:"workflow_error_message,omitempty"`
+	LastAction           string     `json:"last_action,omitempty"`
+	LastActionSource     string     `json:"last_action_source,omitempty"`
+	LastActionAt         *time.Time `json:"last_action_at,omitempty"`
+	NextPlannedAction    string     `json:"next_planned_action,omitempty"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+	Version              int        `json:"version"`
+}
+
+// ToTenantStatusResponse converts a domain tenant to its status response
+func ToTenantStatusResponse(t *tenant.Tenant) TenantStatusResponse {
+	resp := TenantStatusResponse{
+		ID:                   t.ID.String(),
+		Name:                 t.Name,
+		Status:               string(t.Status),
+		StatusMessage:        t.StatusMessage,
+		WorkflowExecutionID:  t.WorkflowExecutionID,
+		WorkflowSubState:     t.WorkflowSubState,
+		WorkflowErrorMessage: t.WorkflowErrorMessage,
+		NextPlannedAction:    NextPlannedAction(t),
+		UpdatedAt:            t.UpdatedAt,
+		Version:              t.Version,
+	}
+	if t.LastAction != nil {
+		at := t.LastAction.At
+		resp.LastAction = t.LastAction.Action
+		resp.LastActionSource = t.LastAction.Source
+		resp.LastActionAt = &at
+	}
+	return resp
+}
+
+// TenantCredentialsResponse is the response for the tenant endpoint credentials routes
+type TenantCredentialsResponse struct {
+	TenantID   string `json:"tenant_id"`
+	TenantName string `json:"tenant_name"`
+
+	endpointauth.Credentials
+}
+
+// ErrorResponse is the legacy error response, served when http.error_format is legacy.
+// New clients should expect ProblemDetails.
 type ErrorResponse struct {
 	// Error is the error message
 	Error string `json:"error"`
 
+	// Code classifies the error; see ErrorCode for the stable set of values
+	Code ErrorCode `json:"code,omitempty"`
+
 	// Details provides additional context about the error
 	Details []string `json:"details,omitempty"`
 
@@ -120,22 +368,40 @@ func ToTenantResponse(t *tenant.Tenant) TenantResponse {
 	resp := TenantResponse{
 		ID:                  t.ID.String(),
 		Name:                t.Name,
+		ProjectID:           t.ProjectID.String(),
 		Status:              string(t.Status),
 		StatusMessage:       t.StatusMessage,
 		DesiredConfig:       t.DesiredConfig,
+		ManagedFields:       t.ManagedFields,
 		ObservedConfig:      t.ObservedConfig,
 		ObservedResourceIDs: t.ObservedResourceIDs,
 		WorkflowExecutionID: t.WorkflowExecutionID,
 		WorkflowSubState:    t.WorkflowSubState,
 		WorkflowRetryCount:  t.WorkflowRetryCount,
 		WorkflowErrorMessage: t.WorkflowErrorMessage,
+		WorkflowVersion:      t.WorkflowVersion,
+		WorkflowStartedAt:    t.WorkflowStartedAt,
 		CreatedAt:           t.CreatedAt,
 		UpdatedAt:           t.UpdatedAt,
 		Version:             t.Version,
 		Labels:              t.Labels,
 		Annotations:         t.Annotations,
+		Conditions:          t.Conditions,
+		Finalizers:          t.Finalizers,
+		Owner:               t.Owner,
+		Collaborators:       t.Collaborators,
 	}
 
+	resp.Migration = t.Migration()
+	resp.Promotion = t.Promotion()
+	if t.LastAction != nil {
+		at := t.LastAction.At
+		resp.LastAction = t.LastAction.Action
+		resp.LastActionSource = t.LastAction.Source
+		resp.LastActionAt = &at
+	}
+	resp.NextPlannedAction = NextPlannedAction(t)
+
 	// Convert DesiredConfig map to ComputeConfig map for API response
 	if len(t.DesiredConfig) > 0 {
 		resp.ComputeConfig = copyInterfaceMap(t.DesiredConfig)
@@ -144,6 +410,31 @@ func ToTenantResponse(t *tenant.Tenant) TenantResponse {
 	return resp
 }
 
+// NextPlannedAction returns the workflow action the controller takes for t next, as reported in
+// next_planned_action
+func NextPlannedAction(t *tenant.Tenant) string {
+	if t.WorkflowSubState != nil && *t.WorkflowSubState == string(workflow.SubStateFinalizing) &&
+		(t.Status == tenant.StatusDeleting || t.Status == tenant.StatusArchiving) {
+		return tenant.ActionFinalize
+	}
+	// The running workflow is the last action; nothing else is planned until it finishes
+	if t.WorkflowExecutionID != nil && *t.WorkflowExecutionID != "" {
+		return ""
+	}
+	return tenant.ActionForStatus(t.Status)
+}
+
+// ToTenantSummary converts a domain tenant summary to an API response
+func ToTenantSummary(s *tenant.Summary) TenantSummary {
+	return TenantSummary{
+		ID:        s.ID.String(),
+		Name:      s.Name,
+		Status:    string(s.Status),
+		Labels:    s.Labels,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
 // FromCreateRequest converts a create request to a domain tenant
 func FromCreateRequest(req *CreateTenantRequest) (*tenant.Tenant, error) {
 	t := &tenant.Tenant{
@@ -176,12 +467,30 @@ func ApplyUpdateRequest(t *tenant.Tenant, req *UpdateTenantRequest) error {
 	}
 
 	if req.Annotations != nil {
+		// The compute name is the server's record of where the tenant runs, so it survives
+		// annotation replacement
+		computeName := t.Annotations[tenant.AnnotationComputeName]
 		t.Annotations = req.Annotations
+		if computeName != "" {
+			t.Annotations = copyStringMap(req.Annotations)
+			t.Annotations[tenant.AnnotationComputeName] = computeName
+		} else if _, ok := req.Annotations[tenant.AnnotationComputeName]; ok {
+			t.Annotations = copyStringMap(req.Annotations)
+			delete(t.Annotations, tenant.AnnotationComputeName)
+		}
 	}
 
 	return nil
 }
 
+func copyStringMap(input map[string]string) map[string]string {
+	output := make(map[string]string, len(input)+1)
+	for k, v := range input {
+		output[k] = v
+	}
+	return output
+}
+
 func copyInterfaceMap(input map[string]interface{}) map[string]interface{} {
 	if input == nil {
 		return nil
@@ -192,3 +501,25 @@ func copyInterfaceMap(input map[string]interface{}) map[string]interface{} {
 	}
 	return output
 }
+
+// ValidateTenantResponse is the result of validating a tenant spec without creating it
+type ValidateTenantResponse struct {
+	// Valid is true when the spec has no violations
+	Valid bool `json:"valid"`
+
+	// Violations lists every problem found; empty when the spec is valid
+	Violations []Violation `json:"violations"`
+}
+
+// Violation is a single problem found while validating a tenant spec
+type Violation struct {
+	// Field is the request field at fault (e.g., "name" or "compute_config")
+	Field string `json:"field"`
+
+	// Check is the validation stage that failed: request, naming, project, scope, template, labels, provider,
+	// schema, provider_config, hooks, resources or quota
+	Check string `json:"check"`
+
+	// Message describes the problem
+	Message string `json:"message"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// Timeline entry types
+const (
+	// TimelineTypeStateTransition is a change of the tenant's status
+	TimelineTypeStateTransition = "state_transition"
+
+	// TimelineTypeEvent is a history record that did not change the tenant's status, such as an
+	// uptime change, an egress violation or a hook result
+	TimelineTypeEvent = "event"
+
+	// TimelineTypeWorkflowExecution is a workflow execution, from the steps workers reported
+	TimelineTypeWorkflowExecution = "workflow_execution"
+
+	// TimelineTypeComputeExecution is a compute operation tracked by the compute manager
+	TimelineTypeComputeExecution = "compute_execution"
+
+	// TimelineTypeAudit is an action a principal took on the tenant, such as requesting or deciding an approval
+	TimelineTypeAudit = "audit"
+)
+
+// TimelineTypes are the entry types, in the order they are documented
+var TimelineTypes = []string{TimelineTypeStateTransition, TimelineTypeEvent, TimelineTypeWorkflowExecution, TimelineTypeComputeExecution, TimelineTypeAudit}
+
+// TimelineEntry is one thing that happened to a tenant. Exactly one of the detail fields is set,
+// matching Type: Transition for state_transition and event entries.
+type TimelineEntry struct {
+	Time time.Time `json:"time"`
+
+	// Type is state_transition, event, workflow_execution, compute_execution or audit.
+	Type string `json:"type"`
+
+	// ID identifies the record the entry comes from: a transition, execution or approval ID.
+	ID string `json:"id"`
+
+	// Summary is a one-line description of the entry.
+	Summary string `json:"summary"`
+
+	// Actor is who or what caused the entry, when known.
+	Actor string `json:"actor,omitempty"`
+
+	Transition        *tenant.StateTransition    `json:"transition,omitempty"`
+	WorkflowExecution *TimelineWorkflowExecution `json:"workflow_execution,omitempty"`
+	ComputeExecution  *compute.ComputeExecution  `json:"compute_execution,omitempty"`
+	Audit             *TimelineAuditEntry        `json:"audit,omitempty"`
+}
+
+// TimelineWorkflowExecution is a workflow execution on a tenant's timeline.
+type TimelineWorkflowExecution struct {
+	ExecutionID string `json:"execution_id"`
+
+	// Status is running while any step runs, failed if a step failed, and succeeded otherwise.
+	Status string `json:"status"`
+
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// Steps are listed in the order they first started.
+	Steps []ExecutionStepResponse `json:"steps"`
+}
+
+// TimelineAuditEntry is an action a principal took on a tenant.
+type TimelineAuditEntry struct {
+	// Action is approval.requested, approval.approved or approval.rejected.
+	Action string `json:"action"`
+
+	// Operation is the tenant operation the action concerns, such as archive or delete.
+	Operation string `json:"operation,omitempty"`
+
+	Reason string `json:"reason,omitempty"`
+}
+
+// TenantTimelineResponse is the response for GET /v1/tenants/{id}/timeline
+type TenantTimelineResponse struct {
+	// Entries are sorted newest first.
+	Entries []TimelineEntry `json:"entries"`
+
+	// Pagination metadata
+	Total  int `json:"total"`  // Total number of entries matching the filter
+	Limit  int `json:"limit"`  // Number of items per page
+	Offset int `json:"offset"` // Starting position
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// tenantChangeAllowed checks that the caller may change t: admins always may, other API keys only
+// when they own t or it is shared with them. It writes a 403 when the caller may not.
+func (s *Server) tenantChangeAllowed(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, requestID string) bool {
+	principal := project.PrincipalFromContext(r.Context())
+	if principal.Unrestricted() || t.SharedWith(principal.Name) {
+		return true
+	}
+	s.writeErrorResponse(w, r, http.StatusForbidden, "Tenant is not shared with this API key",
+		[]string{fmt.Sprintf("tenant %s is owned by %s", t.Name, t.Owner)}, requestID)
+	return false
+}
+
+// tenantAccessManageable checks that the caller may transfer or share t: admins always may, other
+// API keys only when they own it. A tenant without an owner is given one by an admin.
+func (s *Server) tenantAccessManageable(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, requestID string) bool {
+	principal := project.PrincipalFromContext(r.Context())
+	if principal.Unrestricted() || (t.Owner != "" && t.Owner == principal.Name) {
+		return true
+	}
+	detail := fmt.Sprintf("tenant %s is owned by %s", t.Name, t.Owner)
+	if t.Owner == "" {
+		detail = fmt.Sprintf("tenant %s has no owner; an admin must assign one", t.Name)
+	}
+	s.writeErrorResponse(w, r, http.StatusForbidden, "Only the tenant's owner may change who can access it", []string{detail}, requestID)
+	return false
+}
+
+// checkTenantPrincipal checks that name can own or collaborate on t. With API keys configured it
+// must name one of them, and that key must be able to see t's project.
+func (s *Server) checkTenantPrincipal(ctx context.Context, name string, t *tenant.Tenant) error {
+	if err := tenant.ValidatePrincipalName(name); err != nil {
+		return err
+	}
+	if len(s.apiKeys) == 0 {
+		return nil
+	}
+	var principal *project.Principal
+	for _, key := range s.apiKeys {
+		if key.principal.Name == name {
+			principal = key.principal
+		}
+	}
+	if principal == nil {
+		return fmt.Errorf("no API key is named %q", name)
+	}
+	if principal.Unrestricted() {
+		return nil
+	}
+	p, err := s.projectByID(ctx, t.ProjectID)
+	if err != nil {
+		return err
+	}
+	if !principal.CanAccessProject(p.Organization, p.Name) {
+		return fmt.Errorf("API key %s cannot access project %s/%s", name, p.Organization, p.Name)
+	}
+	return nil
+}
+
+// handleTransferTenantOwnership gives a tenant to another owner
+// @Summary Transfer a tenant to another owner
+// @Description Makes another API key the tenant's owner. Only the current owner and admins may transfer a tenant, and only admins may give an owner to a tenant without one.
+// @Description The previous owner can no longer change the tenant unless it is shared with it.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Param request body models.TransferTenantOwnershipRequest true "New owner"
+// @Success 200 {object} models.TenantResponse "Tenant with its new owner"
+// @Failure 400 {object} models.ErrorResponse "Invalid or unknown owner"
+// @Failure 403 {object} models.ErrorResponse "Caller does not own the tenant"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/owner [put]
+func (s *Server) handleTransferTenantOwnership(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+
+	var req models.TransferTenantOwnershipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return
+	}
+	req.Owner = strings.TrimSpace(req.Owner)
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if !s.tenantAccessManageable(w, r, t, requestID) {
+		return
+	}
+	if err := s.checkTenantPrincipal(r.Context(), req.Owner, t); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid owner", []string{err.Error()}, requestID)
+		return
+	}
+
+	previous := t.Owner
+	if previous != req.Owner {
+		t.TransferOwnership(req.Owner)
+		if !s.saveTenantAccess(w, r, t, requestID) {
+			return
+		}
+		message := fmt.Sprintf("Ownership transferred to %s", req.Owner)
+		if previous != "" {
+			message = fmt.Sprintf("Ownership transferred from %s to %s", previous, req.Owner)
+		}
+		transition := tenant.NewStateTransition(t, t.Status, message, creator(r)).WithReasonCode(tenant.ReasonOwnershipTransferred)
+		s.recordTransition(r.Context(), transition, requestID)
+
+		s.logger.Info("tenant ownership transferred",
+			zap.String("tenant_name", t.Name),
+			zap.String("previous_owner", previous),
+			zap.String("owner", req.Owner),
+			zap.String("request_id", requestID))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ToTenantResponse(t))
+}
+
+// handleShareTenant shares a tenant with another API key
+// @Summary Share a tenant
+// @Description Lets another API key change the tenant as a collaborator. Only the tenant's owner and admins may share it, and the collaborator must be able to see the tenant's project.
+// @Description Sharing a tenant with a collaborator it already has, or with its owner, changes nothing.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Param principal path string true "API key name"
+// @Success 200 {object} models.TenantResponse "Tenant with the collaborator"
+// @Failure 400 {object} models.ErrorResponse "Invalid or unknown principal"
+// @Failure 403 {object} models.ErrorResponse "Caller does not own the tenant"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/collaborators/{principal} [put]
+func (s *Server) handleShareTenant(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	name := chi.URLParam(r, "principal")
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if !s.tenantAccessManageable(w, r, t, requestID) {
+		return
+	}
+	if err := s.checkTenantPrincipal(r.Context(), name, t); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid collaborator", []string{err.Error()}, requestID)
+		return
+	}
+
+	if t.AddCollaborator(name) {
+		if !s.saveTenantAccess(w, r, t, requestID) {
+			return
+		}
+		transition := tenant.NewStateTransition(t, t.Status, fmt.Sprintf("Shared with %s", name), creator(r)).WithReasonCode(tenant.ReasonTenantShared)
+		s.recordTransition(r.Context(), transition, requestID)
+
+		s.logger.Info("tenant shared",
+			zap.String("tenant_name", t.Name),
+			zap.String("collaborator", name),
+			zap.String("request_id", requestID))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ToTenantResponse(t))
+}
+
+// handleUnshareTenant stops sharing a tenant with an API key
+// @Summary Stop sharing a tenant
+// @Description Removes a collaborator from the tenant. The tenant's owner and admins may remove any collaborator, and a collaborator may remove itself.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Param principal path string true "API key name"
+// @Success 200 {object} models.TenantResponse "Tenant without the collaborator"
+// @Failure 403 {object} models.ErrorResponse "Caller does not own the tenant"
+// @Failure 404 {object} models.ErrorResponse "Tenant or collaborator not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/collaborators/{principal} [delete]
+func (s *Server) handleUnshareTenant(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	name := chi.URLParam(r, "principal")
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if principal := project.PrincipalFromContext(r.Context()); principal == nil || principal.Name != name {
+		if !s.tenantAccessManageable(w, r, t, requestID) {
+			return
+		}
+	}
+	if !t.RemoveCollaborator(name) {
+		s.writeErrorResponse(w, r, http.StatusNotFound, "Collaborator not found", []string{fmt.Sprintf("tenant %s is not shared with %q", t.Name, name)}, requestID)
+		return
+	}
+	if !s.saveTenantAccess(w, r, t, requestID) {
+		return
+	}
+	transition := tenant.NewStateTransition(t, t.Status, fmt.Sprintf("No longer shared with %s", name), creator(r)).WithReasonCode(tenant.ReasonTenantUnshared)
+	s.recordTransition(r.Context(), transition, requestID)
+
+	s.logger.Info("tenant unshared",
+		zap.String("tenant_name", t.Name),
+		zap.String("collaborator", name),
+		zap.String("request_id", requestID))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ToTenantResponse(t))
+}
+
+// saveTenantAccess writes a locked tenant whose owner or collaborators changed, writing an error response when it fails
+func (s *Server) saveTenantAccess(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, requestID string) bool {
+	t.UpdatedAt = time.Now()
+	if err := s.tenantRepo.UpdateTenant(r.Context(), t); err != nil {
+		if errors.Is(err, tenant.ErrVersionConflict) {
+			s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Tenant was modified concurrently, retry the request", nil, requestID)
+			return false
+		}
+		s.logger.Error("failed to update tenant access", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to update tenant access", nil, requestID)
+		return false
+	}
+	return true
+}
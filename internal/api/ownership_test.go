@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+func TestTenantOwnership(t *testing.T) {
+	srv := newProjectTestServer(t)
+	ctx := context.Background()
+
+	rec := serveWithKey(srv, webKey, http.MethodPost, "/v1/tenants", `{"name":"shop","compute_config":{"image":"nginx:latest"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created models.TenantResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode tenant: %v", err)
+	}
+	if created.Owner != "acme-web" {
+		t.Fatalf("expected the creating key to own the tenant, got %q", created.Owner)
+	}
+
+	change := func(key string) int {
+		t.Helper()
+		return serveWithKey(srv, key, http.MethodPut, "/v1/tenants/shop/finalizers/dns", "").Code
+	}
+	// Other keys in the project see the tenant but cannot change it
+	if rec := serveWithKey(srv, acmeKey, http.MethodGet, "/v1/tenants/shop", ""); rec.Code != http.StatusOK {
+		t.Fatalf("get: expected 200, got %d", rec.Code)
+	}
+	if code := change(acmeKey); code != http.StatusForbidden {
+		t.Fatalf("change by another key: expected 403, got %d", code)
+	}
+	if code := change(adminKey); code != http.StatusOK {
+		t.Fatalf("change by an admin: expected 200, got %d", code)
+	}
+
+	for _, tc := range []struct {
+		key, method, path, body string
+		want                    int
+	}{
+		{acmeKey, http.MethodPut, "/v1/tenants/shop/collaborators/acme-ops", "", http.StatusForbidden},
+		{webKey, http.MethodPut, "/v1/tenants/shop/collaborators/other-ops", "", http.StatusBadRequest},
+		{webKey, http.MethodPut, "/v1/tenants/shop/collaborators/nobody", "", http.StatusBadRequest},
+		{webKey, http.MethodPut, "/v1/tenants/shop/collaborators/acme-ops", "", http.StatusOK},
+		{webKey, http.MethodPut, "/v1/tenants/shop/collaborators/acme-ops", "", http.StatusOK},
+		// A collaborator may change the tenant, but not share it further
+		{acmeKey, http.MethodDelete, "/v1/tenants/shop/finalizers/dns", "", http.StatusOK},
+		{acmeKey, http.MethodPut, "/v1/tenants/shop/collaborators/root", "", http.StatusForbidden},
+		{acmeKey, http.MethodPut, "/v1/tenants/shop/owner", `{"owner":"acme-ops"}`, http.StatusForbidden},
+		{webKey, http.MethodPut, "/v1/tenants/shop/owner", `{"owner":"nobody"}`, http.StatusBadRequest},
+		{webKey, http.MethodPut, "/v1/tenants/shop/owner", `{"owner":"acme-ops"}`, http.StatusOK},
+		// The previous owner has no access left, and a collaborator may remove itself
+		{webKey, http.MethodPut, "/v1/tenants/shop/finalizers/dns", "", http.StatusForbidden},
+		{acmeKey, http.MethodPut, "/v1/tenants/shop/collaborators/acme-web", "", http.StatusOK},
+		{webKey, http.MethodDelete, "/v1/tenants/shop/collaborators/acme-web", "", http.StatusOK},
+		{webKey, http.MethodDelete, "/v1/tenants/shop/collaborators/acme-web", "", http.StatusForbidden},
+		{acmeKey, http.MethodDelete, "/v1/tenants/shop/collaborators/acme-web", "", http.StatusNotFound},
+	} {
+		if rec := serveWithKey(srv, tc.key, tc.method, tc.path, tc.body); rec.Code != tc.want {
+			t.Fatalf("%s %s %s: expected %d, got %d: %s", tc.key, tc.method, tc.path, tc.want, rec.Code, rec.Body.String())
+		}
+	}
+
+	stored, err := srv.tenantRepo.GetTenantByName(ctx, "shop")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if stored.Owner != "acme-ops" || len(stored.Collaborators) != 0 {
+		t.Fatalf("unexpected owner %q and collaborators %v", stored.Owner, stored.Collaborators)
+	}
+	history, err := srv.tenantRepo.GetStateHistory(ctx, stored.ID, tenant.HistoryFilters{})
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	var codes []tenant.ReasonCode
+	for _, transition := range history {
+		codes = append(codes, transition.ReasonCode)
+	}
+	want := []tenant.ReasonCode{tenant.ReasonTenantUnshared, tenant.ReasonTenantShared, tenant.ReasonOwnershipTransferred, tenant.ReasonFinalizerRemoved, tenant.ReasonTenantShared}
+	if len(codes) != len(want) {
+		t.Fatalf("expected transitions %v, got %v", want, codes)
+	}
+	for i := range want {
+		if codes[i] != want[i] {
+			t.Fatalf("expected transitions %v, got %v", want, codes)
+		}
+	}
+	if history[2].TriggeredBy != "acme-web" {
+		t.Errorf("expected the transfer to be triggered by acme-web, got %q", history[2].TriggeredBy)
+	}
+}
+
+func TestTenantWithoutOwner(t *testing.T) {
+	srv := newProjectTestServer(t)
+	acmeWeb, err := srv.projects.GetProject(context.Background(), "acme", "web")
+	if err != nil {
+		t.Fatalf("get project: %v", err)
+	}
+	if err := srv.tenantRepo.CreateTenant(context.Background(), &tenant.Tenant{Name: "legacy", ProjectID: acmeWeb.ID, Status: tenant.StatusReady}); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	// Every key in scope may change it, but only an admin may give it an owner
+	if rec := serveWithKey(srv, acmeKey, http.MethodPut, "/v1/tenants/legacy/finalizers/dns", ""); rec.Code != http.StatusOK {
+		t.Fatalf("change: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveWithKey(srv, acmeKey, http.MethodPut, "/v1/tenants/legacy/owner", `{"owner":"acme-ops"}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("claim: expected 403, got %d", rec.Code)
+	}
+	if rec := serveWithKey(srv, adminKey, http.MethodPut, "/v1/tenants/legacy/owner", `{"owner":"acme-web"}`); rec.Code != http.StatusOK {
+		t.Fatalf("assign: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serveWithKey(srv, acmeKey, http.MethodDelete, "/v1/tenants/legacy/finalizers/dns", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("change after assignment: expected 403, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,388 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// mergePatchContentType selects RFC 7386 JSON Merge Patch semantics
+	mergePatchContentType = "application/merge-patch+json"
+
+	// jsonPatchContentType selects RFC 6902 JSON Patch semantics
+	jsonPatchContentType = "application/json-patch+json"
+)
+
+// errPatchTestFailed is returned when a JSON Patch test operation does not match the document
+var errPatchTestFailed = errors.New("test failed")
+
+// jsonPatchOperation is a single RFC 6902 operation
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// patchContentType returns the normalized media type for a PATCH request.
+// Plain application/json (and an absent header) is treated as a merge patch,
+// which keeps existing clients that send partial UpdateTenantRequest bodies working.
+func patchContentType(header string) (string, error) {
+	if strings.TrimSpace(header) == "" {
+		return mergePatchContentType, nil
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return "", fmt.Errorf("invalid content type: %w", err)
+	}
+	switch mediaType {
+	case "application/json", mergePatchContentType:
+		return mergePatchContentType, nil
+	case jsonPatchContentType:
+		return jsonPatchContentType, nil
+	default:
+		return "", fmt.Errorf("unsupported patch content type %q (supported: %s, %s)", mediaType, mergePatchContentType, jsonPatchContentType)
+	}
+}
+
+// applyMergePatch applies an RFC 7386 merge patch to target and returns the result.
+// Objects are merged recursively, null removes a member, and any other value replaces the target.
+func applyMergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	} else {
+		targetObj = copyDocument(targetObj).(map[string]interface{})
+	}
+
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = applyMergePatch(targetObj[key], value)
+	}
+	return targetObj
+}
+
+// applyJSONPatch applies RFC 6902 operations to doc in order.
+// The input document is not modified; if any operation fails the whole patch is rejected.
+func applyJSONPatch(doc map[string]interface{}, ops []jsonPatchOperation) (map[string]interface{}, error) {
+	var current interface{} = copyDocument(doc)
+
+	for i, op := range ops {
+		var err error
+		switch op.Op {
+		case "add":
+			var value interface{}
+			value, err = decodePatchValue(op)
+			if err == nil {
+				current, err = pointerAdd(current, op.Path, value)
+			}
+		case "remove":
+			current, _, err = pointerRemove(current, op.Path)
+		case "replace":
+			var value interface{}
+			value, err = decodePatchValue(op)
+			if err == nil {
+				current, _, err = pointerRemove(current, op.Path)
+			}
+			if err == nil {
+				current, err = pointerAdd(current, op.Path, value)
+			}
+		case "move":
+			if op.From == op.Path {
+				break
+			}
+			if strings.HasPrefix(op.Path, op.From+"/") {
+				err = fmt.Errorf("cannot move %q into one of its children", op.From)
+				break
+			}
+			var value interface{}
+			current, value, err = pointerRemove(current, op.From)
+			if err == nil {
+				current, err = pointerAdd(current, op.Path, value)
+			}
+		case "copy":
+			var value interface{}
+			value, err = pointerGet(current, op.From)
+			if err == nil {
+				current, err = pointerAdd(current, op.Path, copyDocument(value))
+			}
+		case "test":
+			var expected, actual interface{}
+			expected, err = decodePatchValue(op)
+			if err == nil {
+				actual, err = pointerGet(current, op.Path)
+			}
+			if err == nil && !reflect.DeepEqual(normalizeNumbers(expected), normalizeNumbers(actual)) {
+				err = fmt.Errorf("%w at %q", errPatchTestFailed, op.Path)
+			}
+		case "":
+			err = fmt.Errorf("op is required")
+		default:
+			err = fmt.Errorf("unsupported op %q", op.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	result, ok := current.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("patch result must be a JSON object")
+	}
+	return result, nil
+}
+
+func decodePatchValue(op jsonPatchOperation) (interface{}, error) {
+	if len(op.Value) == 0 {
+		return nil, fmt.Errorf("value is required")
+	}
+	var value interface{}
+	if err := json.Unmarshal(op.Value, &value); err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+	return value, nil
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		token = strings.ReplaceAll(token, "~1", "/")
+		tokens[i] = strings.ReplaceAll(token, "~0", "~")
+	}
+	return tokens, nil
+}
+
+func pointerGet(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	current := doc
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %q does not exist", pointer)
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node), false)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+	}
+	return current, nil
+}
+
+func pointerAdd(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return addAt(doc, tokens, value, pointer)
+}
+
+func addAt(node interface{}, tokens []string, value interface{}, pointer string) (interface{}, error) {
+	token := tokens[0]
+	last := len(tokens) == 1
+
+	switch typed := node.(type) {
+	case map[string]interface{}:
+		if last {
+			typed[token] = value
+			return typed, nil
+		}
+		child, ok := typed[token]
+		if !ok {
+			return nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+		updated, err := addAt(child, tokens[1:], value, pointer)
+		if err != nil {
+			return nil, err
+		}
+		typed[token] = updated
+		return typed, nil
+	case []interface{}:
+		if last {
+			index, err := arrayIndex(token, len(typed), true)
+			if err != nil {
+				return nil, err
+			}
+			result := make([]interface{}, 0, len(typed)+1)
+			result = append(result, typed[:index]...)
+			result = append(result, value)
+			return append(result, typed[index:]...), nil
+		}
+		index, err := arrayIndex(token, len(typed), false)
+		if err != nil {
+			return nil, err
+		}
+		updated, err := addAt(typed[index], tokens[1:], value, pointer)
+		if err != nil {
+			return nil, err
+		}
+		typed[index] = updated
+		return typed, nil
+	default:
+		return nil, fmt.Errorf("path %q does not exist", pointer)
+	}
+}
+
+func pointerRemove(doc interface{}, pointer string) (interface{}, interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the document root")
+	}
+	return removeAt(doc, tokens, pointer)
+}
+
+func removeAt(node interface{}, tokens []string, pointer string) (interface{}, interface{}, error) {
+	token := tokens[0]
+	last := len(tokens) == 1
+
+	switch typed := node.(type) {
+	case map[string]interface{}:
+		child, ok := typed[token]
+		if !ok {
+			return nil, nil, fmt.Errorf("path %q does not exist", pointer)
+		}
+		if last {
+			delete(typed, token)
+			return typed, child, nil
+		}
+		updated, removed, err := removeAt(child, tokens[1:], pointer)
+		if err != nil {
+			return nil, nil, err
+		}
+		typed[token] = updated
+		return typed, removed, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(typed), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if last {
+			removed := typed[index]
+			result := make([]interface{}, 0, len(typed)-1)
+			result = append(result, typed[:index]...)
+			return append(result, typed[index+1:]...), removed, nil
+		}
+		updated, removed, err := removeAt(typed[index], tokens[1:], pointer)
+		if err != nil {
+			return nil, nil, err
+		}
+		typed[index] = updated
+		return typed, removed, nil
+	default:
+		return nil, nil, fmt.Errorf("path %q does not exist", pointer)
+	}
+}
+
+// arrayIndex resolves an array reference token; "-" is only valid when appending
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" {
+		if allowEnd {
+			return length, nil
+		}
+		return 0, fmt.Errorf("index \"-\" is only valid for add")
+	}
+	if len(token) > 1 && token[0] == '0' {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	max := length - 1
+	if allowEnd {
+		max = length
+	}
+	if index > max {
+		return 0, fmt.Errorf("array index %d out of range", index)
+	}
+	return index, nil
+}
+
+// copyDocument deep copies a decoded JSON value so patches never alias stored tenant state
+func copyDocument(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(typed))
+		for k, v := range typed {
+			out[k] = copyDocument(v)
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]interface{}, len(typed))
+		for k, v := range typed {
+			out[k] = v
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(typed))
+		for i, v := range typed {
+			out[i] = copyDocument(v)
+		}
+		return out
+	default:
+		return typed
+	}
+}
+
+// normalizeNumbers converts integer types to float64 so test comparisons
+// match values regardless of how they were decoded
+func normalizeNumbers(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(typed))
+		for k, v := range typed {
+			out[k] = normalizeNumbers(v)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(typed))
+		for i, v := range typed {
+			out[i] = normalizeNumbers(v)
+		}
+		return out
+	case int:
+		return float64(typed)
+	case int64:
+		return float64(typed)
+	case int32:
+		return float64(typed)
+	default:
+		return typed
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	target := map[string]interface{}{
+		"image": "nginx:1.0",
+		"env": map[string]interface{}{
+			"A": "1",
+			"B": "2",
+		},
+	}
+	patch := map[string]interface{}{
+		"env": map[string]interface{}{
+			"B": nil,
+			"C": "3",
+		},
+		"replicas": float64(2),
+	}
+
+	got := applyMergePatch(target, patch)
+	want := map[string]interface{}{
+		"image": "nginx:1.0",
+		"env": map[string]interface{}{
+			"A": "1",
+			"C": "3",
+		},
+		"replicas": float64(2),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected merge result: %#v", got)
+	}
+
+	// The original document must not be modified
+	if _, ok := target["env"].(map[string]interface{})["B"]; !ok {
+		t.Fatal("expected target to remain unmodified")
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	doc := map[string]interface{}{
+		"compute_config": map[string]interface{}{
+			"image": "nginx:1.0",
+			"ports": []interface{}{float64(80)},
+			"env":   map[string]interface{}{"A/B": "1"},
+		},
+	}
+
+	var ops []jsonPatchOperation
+	raw := `[
+		{"op": "test", "path": "/compute_config/image", "value": "nginx:1.0"},
+		{"op": "replace", "path": "/compute_config/image", "value": "nginx:2.0"},
+		{"op": "add", "path": "/compute_config/ports/-", "value": 443},
+		{"op": "remove", "path": "/compute_config/env/A~1B"},
+		{"op": "copy", "from": "/compute_config/image", "path": "/compute_config/previous"},
+		{"op": "move", "from": "/compute_config/previous", "path": "/compute_config/image_copy"}
+	]`
+	if err := json.Unmarshal([]byte(raw), &ops); err != nil {
+		t.Fatalf("unmarshal ops: %v", err)
+	}
+
+	got, err := applyJSONPatch(doc, ops)
+	if err != nil {
+		t.Fatalf("apply patch: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"compute_config": map[string]interface{}{
+			"image":      "nginx:2.0",
+			"image_copy": "nginx:2.0",
+			"ports":      []interface{}{float64(80), float64(443)},
+			"env":        map[string]interface{}{},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected patch result: %#v", got)
+	}
+	if doc["compute_config"].(map[string]interface{})["image"] != "nginx:1.0" {
+		t.Fatal("expected source document to remain unmodified")
+	}
+}
+
+func TestApplyJSONPatchErrors(t *testing.T) {
+	doc := map[string]interface{}{"labels": map[string]interface{}{"team": "a"}}
+
+	cases := map[string]string{
+		"failed test":     `[{"op": "test", "path": "/labels/team", "value": "b"}]`,
+		"missing path":    `[{"op": "remove", "path": "/labels/missing"}]`,
+		"unknown op":      `[{"op": "frobnicate", "path": "/labels"}]`,
+		"missing value":   `[{"op": "add", "path": "/labels/env"}]`,
+		"invalid pointer": `[{"op": "add", "path": "labels", "value": 1}]`,
+	}
+	for name, raw := range cases {
+		t.Run(name, func(t *testing.T) {
+			var ops []jsonPatchOperation
+			if err := json.Unmarshal([]byte(raw), &ops); err != nil {
+				t.Fatalf("unmarshal ops: %v", err)
+			}
+			if _, err := applyJSONPatch(doc, ops); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestPatchContentType(t *testing.T) {
+	cases := map[string]string{
+		"":                                mergePatchContentType,
+		"application/json":                mergePatchContentType,
+		"application/json; charset=utf-8": mergePatchContentType,
+		"application/merge-patch+json":    mergePatchContentType,
+		"application/json-patch+json":     jsonPatchContentType,
+	}
+	for header, want := range cases {
+		got, err := patchContentType(header)
+		if err != nil {
+			t.Fatalf("content type %q: %v", header, err)
+		}
+		if got != want {
+			t.Fatalf("content type %q: expected %s, got %s", header, want, got)
+		}
+	}
+
+	if _, err := patchContentType("text/plain"); err == nil {
+		t.Fatal("expected error for unsupported content type")
+	}
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+// writeError writes an error response with an explicit error code.
+// Responses are RFC 7807 problem details unless the server is configured for the legacy format
+// and the client did not ask for application/problem+json.
+// Server errors written after the request's deadline passed become 504 TIMEOUT responses.
+func (s *Server) writeError(w http.ResponseWriter, r *http.Request, statusCode int, code models.ErrorCode, message string, details []string, requestID string) {
+	if detail, timeoutDetails, ok := timeoutError(r, statusCode, message, details); ok {
+		s.logRequestTimeout(r, message)
+		statusCode, code, message, details = http.StatusGatewayTimeout, models.ErrorCodeTimeout, detail, timeoutDetails
+	}
+
+	if s.errorFormat == config.ErrorFormatLegacy && !acceptsProblem(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(models.ErrorResponse{
+			Error:     message,
+			Code:      code,
+			Details:   details,
+			RequestID: requestID,
+		})
+		return
+	}
+
+	problem := models.ProblemDetails{
+		Type:      models.ProblemType(code),
+		Title:     code.Title(),
+		Status:    statusCode,
+		Detail:    message,
+		ErrorCode: code,
+		RequestID: requestID,
+		Errors:    details,
+	}
+	if r != nil {
+		problem.Instance = r.URL.Path
+	}
+	w.Header().Set("Content-Type", models.ProblemContentType)
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// acceptsProblem reports whether the client explicitly accepts problem detail responses
+func acceptsProblem(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err == nil && mediaType == models.ProblemContentType {
+			return true
+		}
+	}
+	return false
+}
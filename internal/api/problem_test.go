@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+func TestErrorsUseProblemDetailsByDefault(t *testing.T) {
+	srv := newVersioningTestServer()
+	req := httptest.NewRequest(http.MethodGet, "/v2/tenants", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	rec := httptest.NewRecorder()
+
+	srv.router.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != models.ProblemContentType {
+		t.Fatalf("expected content type %s, got %q", models.ProblemContentType, ct)
+	}
+
+	var problem models.ProblemDetails
+	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if problem.Type != "urn:landlord:problem:unsupported-version" {
+		t.Errorf("unexpected type %q", problem.Type)
+	}
+	if problem.Title != "Unsupported API version" {
+		t.Errorf("unexpected title %q", problem.Title)
+	}
+	if problem.Status != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", problem.Status)
+	}
+	if problem.Instance != "/v2/tenants" {
+		t.Errorf("expected instance /v2/tenants, got %q", problem.Instance)
+	}
+	if problem.RequestID != "req-1" {
+		t.Errorf("expected request id req-1, got %q", problem.RequestID)
+	}
+}
+
+func TestErrorsLegacyFormat(t *testing.T) {
+	router := chi.NewRouter()
+	srv := &Server{router: router, errorFormat: config.ErrorFormatLegacy}
+	srv.registerRoutes()
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+	}{
+		{name: "legacy by default", contentType: "application/json"},
+		{name: "problem when accepted", accept: "application/json, application/problem+json;q=0.9", contentType: models.ProblemContentType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v2/tenants", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			srv.router.ServeHTTP(rec, req)
+
+			if ct := rec.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Fatalf("expected content type %s, got %q", tt.contentType, ct)
+			}
+			if tt.contentType != "application/json" {
+				return
+			}
+
+			var resp models.ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Error != "unsupported_version" || resp.Code != models.ErrorCodeUnsupportedVersion {
+				t.Errorf("unexpected legacy response %+v", resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,565 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/resolution"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/warmpool"
+)
+
+// errProjectForbidden is returned when the caller's API key does not cover the requested project
+var errProjectForbidden = errors.New("API key is not scoped to this project")
+
+// SetProjects enables the /organizations endpoints and resolves tenants' organizations and projects
+// against store. Without a store every tenant belongs to the default project.
+func (s *Server) SetProjects(store project.Store) {
+	s.projects = store
+}
+
+// handleCreateOrganization creates an organization
+// @Summary Create an organization
+// @Description Creates an organization and its default project. Requires an admin API key when authentication is enabled.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param body body models.CreateOrganizationRequest true "Organization to create"
+// @Success 201 {object} models.OrganizationResponse "Organization created"
+// @Failure 400 {object} models.ErrorResponse "Invalid organization"
+// @Failure 403 {object} models.ErrorResponse "API key is not an admin key"
+// @Failure 409 {object} models.ErrorResponse "Organization already exists"
+// @Failure 503 {object} models.ErrorResponse "Project management not configured"
+// @Router /v1/organizations [post]
+func (s *Server) handleCreateOrganization(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireProjects(w, r, requestID) {
+		return
+	}
+	if !project.PrincipalFromContext(r.Context()).Unrestricted() {
+		s.writeErrorResponse(w, r, http.StatusForbidden, "Creating organizations requires an admin API key", nil, requestID)
+		return
+	}
+
+	var req models.CreateOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	org := &project.Organization{Name: strings.TrimSpace(req.Name), DisplayName: req.DisplayName}
+	if err := org.Validate(); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid organization", []string{err.Error()}, requestID)
+		return
+	}
+	if err := s.projects.CreateOrganization(r.Context(), org); err != nil {
+		s.writeProjectError(w, r, err, requestID)
+		return
+	}
+	// Organization keys create tenants in the default project unless they name another one
+	if err := s.projects.CreateProject(r.Context(), &project.Project{OrganizationID: org.ID, Name: project.DefaultName}); err != nil {
+		s.writeProjectError(w, r, err, requestID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.ToOrganizationResponse(org))
+}
+
+// handleListOrganizations lists the organizations the caller can see
+// @Summary List organizations
+// @Tags organizations
+// @Produce json
+// @Success 200 {object} models.ListOrganizationsResponse "Organizations visible to the API key"
+// @Failure 503 {object} models.ErrorResponse "Project management not configured"
+// @Router /v1/organizations [get]
+func (s *Server) handleListOrganizations(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireProjects(w, r, requestID) {
+		return
+	}
+
+	orgs, err := s.projects.ListOrganizations(r.Context())
+	if err != nil {
+		s.writeProjectError(w, r, err, requestID)
+		return
+	}
+
+	principal := project.PrincipalFromContext(r.Context())
+	resp := models.ListOrganizationsResponse{Organizations: []models.OrganizationResponse{}}
+	for _, org := range orgs {
+		if principal.CanAccessOrganization(org.Name) {
+			resp.Organizations = append(resp.Organizations, models.ToOrganizationResponse(org))
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleGetOrganization returns one organization
+// @Summary Get an organization
+// @Tags organizations
+// @Produce json
+// @Param org path string true "Organization name"
+// @Success 200 {object} models.OrganizationResponse "Organization found"
+// @Failure 404 {object} models.ErrorResponse "Organization not found"
+// @Failure 503 {object} models.ErrorResponse "Project management not configured"
+// @Router /v1/organizations/{org} [get]
+func (s *Server) handleGetOrganization(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireProjects(w, r, requestID) {
+		return
+	}
+
+	org, ok := s.organizationFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ToOrganizationResponse(org))
+}
+
+// handleCreateProject creates a project in an organization
+// @Summary Create a project
+// @Description Creates a project with its quota, notification and template settings.
+// @Description Requires an admin key or a key scoped to the whole organization.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param org path string true "Organization name"
+// @Param body body models.CreateProjectRequest true "Project to create"
+// @Success 201 {object} models.ProjectResponse "Project created"
+// @Failure 400 {object} models.ErrorResponse "Invalid project"
+// @Failure 403 {object} models.ErrorResponse "API key cannot manage the organization's projects"
+// @Failure 404 {object} models.ErrorResponse "Organization not found"
+// @Failure 409 {object} models.ErrorResponse "Project already exists"
+// @Failure 503 {object} models.ErrorResponse "Project management not configured"
+// @Router /v1/organizations/{org}/projects [post]
+func (s *Server) handleCreateProject(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireProjects(w, r, requestID) {
+		return
+	}
+
+	org, ok := s.organizationFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	if !project.PrincipalFromContext(r.Context()).CanManageOrganization(org.Name) {
+		s.writeErrorResponse(w, r, http.StatusForbidden, "API key cannot manage this organization's projects", nil, requestID)
+		return
+	}
+
+	var req models.CreateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	p := &project.Project{
+		OrganizationID: org.ID,
+		Name:           strings.TrimSpace(req.Name),
+		DisplayName:    req.DisplayName,
+		Settings:       req.Settings,
+	}
+	if err := p.Validate(); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid project", []string{err.Error()}, requestID)
+		return
+	}
+	if err := s.projects.CreateProject(r.Context(), p); err != nil {
+		s.writeProjectError(w, r, err, requestID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(models.ToProjectResponse(p))
+}
+
+// handleListProjects lists an organization's projects visible to the caller
+// @Summary List projects
+// @Tags organizations
+// @Produce json
+// @Param org path string true "Organization name"
+// @Success 200 {object} models.ListProjectsResponse "Projects visible to the API key"
+// @Failure 404 {object} models.ErrorResponse "Organization not found"
+// @Failure 503 {object} models.ErrorResponse "Project management not configured"
+// @Router /v1/organizations/{org}/projects [get]
+func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireProjects(w, r, requestID) {
+		return
+	}
+
+	org, ok := s.organizationFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	projects, err := s.projects.ListProjects(r.Context(), org.Name)
+	if err != nil {
+		s.writeProjectError(w, r, err, requestID)
+		return
+	}
+
+	principal := project.PrincipalFromContext(r.Context())
+	resp := models.ListProjectsResponse{Projects: []models.ProjectResponse{}}
+	for _, p := range projects {
+		if principal.CanAccessProject(p.Organization, p.Name) {
+			resp.Projects = append(resp.Projects, models.ToProjectResponse(p))
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleGetProject returns one project
+// @Summary Get a project
+// @Tags organizations
+// @Produce json
+// @Param org path string true "Organization name"
+// @Param project path string true "Project name"
+// @Success 200 {object} models.ProjectResponse "Project found"
+// @Failure 404 {object} models.ErrorResponse "Project not found"
+// @Failure 503 {object} models.ErrorResponse "Project management not configured"
+// @Router /v1/organizations/{org}/projects/{project} [get]
+func (s *Server) handleGetProject(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireProjects(w, r, requestID) {
+		return
+	}
+
+	p, ok := s.projectFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ToProjectResponse(p))
+}
+
+// handleUpdateProject replaces a project's display name and settings
+// @Summary Update a project
+// @Description Replaces the project's display name and settings. Quotas apply to new tenants only.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param org path string true "Organization name"
+// @Param project path string true "Project name"
+// @Param body body models.UpdateProjectRequest true "Project changes"
+// @Success 200 {object} models.ProjectResponse "Project updated"
+// @Failure 400 {object} models.ErrorResponse "Invalid project settings"
+// @Failure 403 {object} models.ErrorResponse "API key cannot manage the organization's projects"
+// @Failure 404 {object} models.ErrorResponse "Project not found"
+// @Failure 503 {object} models.ErrorResponse "Project management not configured"
+// @Router /v1/organizations/{org}/projects/{project} [put]
+func (s *Server) handleUpdateProject(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireProjects(w, r, requestID) {
+		return
+	}
+
+	p, ok := s.projectFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	// Project-scoped keys may use a project but not change its quota or notifications
+	if !project.PrincipalFromContext(r.Context()).CanManageOrganization(p.Organization) {
+		s.writeErrorResponse(w, r, http.StatusForbidden, "API key cannot manage this organization's projects", nil, requestID)
+		return
+	}
+
+	var req models.UpdateProjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	p.DisplayName = req.DisplayName
+	p.Settings = req.Settings
+	if err := p.Validate(); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid project", []string{err.Error()}, requestID)
+		return
+	}
+	if err := s.projects.UpdateProject(r.Context(), p); err != nil {
+		s.writeProjectError(w, r, err, requestID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ToProjectResponse(p))
+}
+
+func (s *Server) requireProjects(w http.ResponseWriter, r *http.Request, requestID string) bool {
+	if s.projects == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Project management not configured", nil, requestID)
+		return false
+	}
+	return true
+}
+
+// organizationFromPath loads the organization named in the URL, hiding organizations outside the caller's scope
+func (s *Server) organizationFromPath(w http.ResponseWriter, r *http.Request, requestID string) (*project.Organization, bool) {
+	name := chi.URLParam(r, "org")
+	if !project.PrincipalFromContext(r.Context()).CanAccessOrganization(name) {
+		s.writeProjectError(w, r, project.ErrOrganizationNotFound, requestID)
+		return nil, false
+	}
+	org, err := s.projects.GetOrganization(r.Context(), name)
+	if err != nil {
+		s.writeProjectError(w, r, err, requestID)
+		return nil, false
+	}
+	return org, true
+}
+
+// projectFromPath loads the project named in the URL, hiding projects outside the caller's scope
+func (s *Server) projectFromPath(w http.ResponseWriter, r *http.Request, requestID string) (*project.Project, bool) {
+	orgName, name := chi.URLParam(r, "org"), chi.URLParam(r, "project")
+	if !project.PrincipalFromContext(r.Context()).CanAccessProject(orgName, name) {
+		s.writeProjectError(w, r, project.ErrProjectNotFound, requestID)
+		return nil, false
+	}
+	p, err := s.projects.GetProject(r.Context(), orgName, name)
+	if err != nil {
+		s.writeProjectError(w, r, err, requestID)
+		return nil, false
+	}
+	return p, true
+}
+
+// writeProjectError writes the response for an organization, project or scope failure
+func (s *Server) writeProjectError(w http.ResponseWriter, r *http.Request, err error, requestID string) {
+	switch {
+	case errors.Is(err, project.ErrOrganizationNotFound):
+		s.writeErrorResponse(w, r, http.StatusNotFound, "Organization not found", []string{err.Error()}, requestID)
+	case errors.Is(err, project.ErrProjectNotFound):
+		s.writeErrorResponse(w, r, http.StatusNotFound, "Project not found", []string{err.Error()}, requestID)
+	case errors.Is(err, project.ErrOrganizationExists):
+		s.writeErrorResponse(w, r, http.StatusConflict, "Organization already exists", nil, requestID)
+	case errors.Is(err, project.ErrProjectExists):
+		s.writeErrorResponse(w, r, http.StatusConflict, "Project already exists", nil, requestID)
+	case errors.Is(err, project.ErrInvalid):
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid project", []string{err.Error()}, requestID)
+	case errors.Is(err, errProjectForbidden):
+		s.writeErrorResponse(w, r, http.StatusForbidden, "Project not allowed", []string{err.Error()}, requestID)
+	case errors.Is(err, project.ErrQuotaExceeded):
+		s.writeError(w, r, http.StatusConflict, models.ErrorCodeQuotaExceeded, "Project quota exceeded", []string{err.Error()}, requestID)
+	default:
+		s.logger.Error("project operation failed", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Project operation failed", nil, requestID)
+	}
+}
+
+// defaultProject stands in for the project store when none is configured
+var defaultProject = project.Project{
+	ID:             project.DefaultProjectID,
+	OrganizationID: project.DefaultOrganizationID,
+	Organization:   project.DefaultName,
+	Name:           project.DefaultName,
+}
+
+// projectByName loads a project, falling back to the default project when no store is configured
+func (s *Server) projectByName(ctx context.Context, organization, name string) (*project.Project, error) {
+	if s.projects == nil {
+		if organization != project.DefaultName || name != project.DefaultName {
+			return nil, fmt.Errorf("%w: %s/%s", project.ErrProjectNotFound, organization, name)
+		}
+		p := defaultProject
+		return &p, nil
+	}
+	p, err := s.projects.GetProject(ctx, organization, name)
+	if err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			return nil, fmt.Errorf("%w: %s/%s", project.ErrProjectNotFound, organization, name)
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// projectByID loads a tenant's project, falling back to the default project when no store is configured
+func (s *Server) projectByID(ctx context.Context, id uuid.UUID) (*project.Project, error) {
+	if s.projects == nil {
+		if id != project.DefaultProjectID {
+			return nil, project.ErrProjectNotFound
+		}
+		p := defaultProject
+		return &p, nil
+	}
+	return s.projects.GetProjectByID(ctx, id)
+}
+
+// resolveTenantProject picks the project a create request lands in, defaulting organization and
+// project from the caller's scope, and checks the caller may use it
+func (s *Server) resolveTenantProject(ctx context.Context, req *models.CreateTenantRequest) (*project.Project, error) {
+	principal := project.PrincipalFromContext(ctx)
+	organization, name := principal.DefaultScope()
+	if req.Organization != "" {
+		organization = req.Organization
+		if req.Project == "" {
+			name = project.DefaultName
+		}
+	}
+	if req.Project != "" {
+		name = req.Project
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: project is required because the API key is scoped to several projects", project.ErrInvalid)
+	}
+	if !principal.CanAccessProject(organization, name) {
+		return nil, fmt.Errorf("%w: %s/%s", errProjectForbidden, organization, name)
+	}
+	return s.projectByName(ctx, organization, name)
+}
+
+// checkProjectQuota returns ErrQuotaExceeded when the project already holds its maximum number of tenants
+func (s *Server) checkProjectQuota(ctx context.Context, p *project.Project) error {
+	max := p.Settings.Quota.MaxTenants
+	if max == 0 {
+		return nil
+	}
+	count, err := s.countProjectTenants(ctx, p)
+	if err != nil {
+		return err
+	}
+	if count >= max {
+		return fmt.Errorf("%w: project %s/%s allows %d tenants", project.ErrQuotaExceeded, p.Organization, p.Name, max)
+	}
+	return nil
+}
+
+// countProjectTenants counts the tenants that count against a project's quota
+func (s *Server) countProjectTenants(ctx context.Context, p *project.Project) (int, error) {
+	existing, err := s.tenantRepo.ListTenants(ctx, tenant.ListFilters{ProjectIDs: []uuid.UUID{p.ID}})
+	if err != nil {
+		return 0, fmt.Errorf("count project tenants: %w", err)
+	}
+	// Unclaimed warm tenants are spare capacity; claiming one counts it like a new tenant
+	count := 0
+	for _, t := range existing {
+		if !warmpool.IsWarm(t) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// applyProjectTemplate merges the request's compute_config over the named project template
+func applyProjectTemplate(p *project.Project, req *models.CreateTenantRequest) error {
+	if req.Template == "" {
+		return nil
+	}
+	template, ok := p.Template(req.Template)
+	if !ok {
+		return fmt.Errorf("project %s/%s has no template %q", p.Organization, p.Name, req.Template)
+	}
+	req.ComputeConfig = compute.MergeConfigMaps(template, req.ComputeConfig)
+	return nil
+}
+
+// applyProjectPolicies fills the request from the project's policies and records what they applied in
+// its annotations. Annotations claiming applied defaults are dropped from the request first, so the record
+// is always the server's.
+func applyProjectPolicies(p *project.Project, req *models.CreateTenantRequest) error {
+	delete(req.Annotations, project.AnnotationAppliedPolicies)
+	delete(req.Annotations, project.AnnotationAppliedDefaults)
+	if len(p.Settings.Policies) == 0 {
+		return nil
+	}
+
+	explicitProvider, _ := resolution.ExplicitProvider(req.ComputeConfig, req.Labels, req.Annotations)
+	hasProvider := explicitProvider != ""
+	config, applied, err := p.Settings.ApplyPolicies(req.Labels, req.ComputeConfig, hasProvider)
+	if err != nil {
+		return err
+	}
+	if req.ComputeConfig != nil || len(config) > 0 {
+		req.ComputeConfig = config
+	}
+	req.Annotations = applied.Annotate(req.Annotations)
+	return nil
+}
+
+// tenantInScope reports whether the caller may see the tenant
+func (s *Server) tenantInScope(ctx context.Context, t *tenant.Tenant) (bool, error) {
+	principal := project.PrincipalFromContext(ctx)
+	if principal.Unrestricted() {
+		return true, nil
+	}
+	p, err := s.projectByID(ctx, t.ProjectID)
+	if err != nil {
+		if errors.Is(err, project.ErrProjectNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return principal.CanAccessProject(p.Organization, p.Name), nil
+}
+
+// scopedProjectIDs returns the projects a tenant list is limited to. A nil result means no
+// restriction; an empty non-nil result means nothing the caller may see matches.
+func (s *Server) scopedProjectIDs(ctx context.Context, organization, name string) ([]uuid.UUID, error) {
+	principal := project.PrincipalFromContext(ctx)
+	if organization == "" && name == "" && principal.Unrestricted() {
+		return nil, nil
+	}
+
+	if s.projects == nil {
+		matches := (organization == "" || organization == project.DefaultName) &&
+			(name == "" || name == project.DefaultName) &&
+			principal.CanAccessProject(project.DefaultName, project.DefaultName)
+		if matches {
+			return []uuid.UUID{project.DefaultProjectID}, nil
+		}
+		return []uuid.UUID{}, nil
+	}
+
+	var organizations []string
+	switch {
+	case organization != "":
+		organizations = []string{organization}
+	case !principal.Unrestricted():
+		organizations = []string{principal.Organization}
+	default:
+		orgs, err := s.projects.ListOrganizations(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, org := range orgs {
+			organizations = append(organizations, org.Name)
+		}
+	}
+
+	ids := []uuid.UUID{}
+	for _, org := range organizations {
+		projects, err := s.projects.ListProjects(ctx, org)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range projects {
+			if (name == "" || p.Name == name) && principal.CanAccessProject(p.Organization, p.Name) {
+				ids = append(ids, p.ID)
+			}
+		}
+	}
+	return ids, nil
+}
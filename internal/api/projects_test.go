@@ -0,0 +1,371 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/project"
+	projectmemory "github.com/jaxxstorm/landlord/internal/project/memory"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+const (
+	adminKey = "admin-key-0123456789"
+	acmeKey  = "acme-key-0123456789"
+	webKey   = "acme-web-key-012345"
+	otherKey = "other-key-012345678"
+)
+
+func newProjectTestServer(t *testing.T) *Server {
+	t.Helper()
+	store := projectmemory.New()
+	ctx := context.Background()
+	for _, name := range []string{"acme", "other"} {
+		org := &project.Organization{Name: name}
+		if err := store.CreateOrganization(ctx, org); err != nil {
+			t.Fatalf("create organization: %v", err)
+		}
+		if err := store.CreateProject(ctx, &project.Project{OrganizationID: org.ID, Name: "web"}); err != nil {
+			t.Fatalf("create project: %v", err)
+		}
+	}
+
+	srv := &Server{
+		router:                 chi.NewRouter(),
+		logger:                 zap.NewNop(),
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+		tenantRepo:             tenantmemory.New(),
+	}
+	srv.SetProjects(store)
+	srv.SetAPIKeys(config.AuthConfig{APIKeys: []config.APIKeyConfig{
+		{Name: "root", Key: adminKey, Admin: true},
+		{Name: "acme-ops", Key: acmeKey, Organization: "acme"},
+		{Name: "acme-web", Key: webKey, Organization: "acme", Projects: []string{"web"}},
+		{Name: "other-ops", Key: otherKey, Organization: "other"},
+	}})
+	srv.registerRoutes()
+	return srv
+}
+
+func serveWithKey(srv *Server, key, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func errorCode(t *testing.T, rec *httptest.ResponseRecorder) models.ErrorCode {
+	t.Helper()
+	var problem models.ProblemDetails
+	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	return problem.ErrorCode
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	srv := newProjectTestServer(t)
+
+	rec := serveWithKey(srv, "", http.MethodGet, "/v1/tenants", "")
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("expected 401 with a challenge, got %d", rec.Code)
+	}
+	if code := errorCode(t, rec); code != models.ErrorCodeUnauthorized {
+		t.Errorf("expected %s, got %s", models.ErrorCodeUnauthorized, code)
+	}
+
+	if rec := serveWithKey(srv, "not-a-key", http.MethodGet, "/v1/tenants", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for an unknown key, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/tenants", nil)
+	req.Header.Set("X-API-Key", acmeKey)
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected X-API-Key to authenticate, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := serveWithKey(srv, "", http.MethodGet, "/v1/swagger.json", ""); rec.Code == http.StatusUnauthorized {
+		t.Error("expected the API documentation to stay public")
+	}
+
+	if rec := serveWithKey(srv, acmeKey, http.MethodGet, "/v1/admin/providers", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("expected non-admin keys to be refused provider administration, got %d", rec.Code)
+	}
+}
+
+func TestOrganizationAndProjectEndpoints(t *testing.T) {
+	srv := newProjectTestServer(t)
+
+	if rec := serveWithKey(srv, acmeKey, http.MethodPost, "/v1/organizations", `{"name":"initech"}`); rec.Code != http.StatusForbidden {
+		t.Fatalf("expected non-admin organization create to be forbidden, got %d", rec.Code)
+	}
+	if rec := serveWithKey(srv, adminKey, http.MethodPost, "/v1/organizations", `{"name":"Not Valid"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected invalid organization name to be rejected, got %d", rec.Code)
+	}
+	if rec := serveWithKey(srv, adminKey, http.MethodPost, "/v1/organizations", `{"name":"initech"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("expected organization to be created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if _, err := srv.projects.GetProject(context.Background(), "initech", project.DefaultName); err != nil {
+		t.Fatalf("expected the organization to get a default project: %v", err)
+	}
+	if rec := serveWithKey(srv, adminKey, http.MethodPost, "/v1/organizations", `{"name":"initech"}`); rec.Code != http.StatusConflict {
+		t.Fatalf("expected duplicate organization to conflict, got %d", rec.Code)
+	}
+
+	rec := serveWithKey(srv, acmeKey, http.MethodGet, "/v1/organizations", "")
+	var orgs models.ListOrganizationsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&orgs); err != nil {
+		t.Fatalf("decode organizations: %v", err)
+	}
+	if len(orgs.Organizations) != 1 || orgs.Organizations[0].Name != "acme" {
+		t.Errorf("expected a scoped key to see only its organization, got %+v", orgs.Organizations)
+	}
+	if rec := serveWithKey(srv, acmeKey, http.MethodGet, "/v1/organizations/other", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected other organizations to be hidden, got %d", rec.Code)
+	}
+
+	body := `{"name":"api","settings":{"quota":{"max_tenants":2},"notifications":{"webhooks":[{"url":"https://hooks.example.com/landlord","statuses":["ready","failed"]}]}}}`
+	rec = serveWithKey(srv, acmeKey, http.MethodPost, "/v1/organizations/acme/projects", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected project to be created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created models.ProjectResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode project: %v", err)
+	}
+	if created.Organization != "acme" || created.Settings.Quota.MaxTenants != 2 || len(created.Settings.Notifications.Webhooks) != 1 {
+		t.Errorf("unexpected project %+v", created)
+	}
+
+	if rec := serveWithKey(srv, webKey, http.MethodPost, "/v1/organizations/acme/projects", `{"name":"batch"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("expected a project-scoped key to be refused project creation, got %d", rec.Code)
+	}
+	if rec := serveWithKey(srv, acmeKey, http.MethodPost, "/v1/organizations/acme/projects", `{"name":"bad","settings":{"notifications":{"webhooks":[{"url":"ftp://example.com"}]}}}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected invalid webhook URL to be rejected, got %d", rec.Code)
+	}
+
+	rec = serveWithKey(srv, webKey, http.MethodGet, "/v1/organizations/acme/projects", "")
+	var projects models.ListProjectsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&projects); err != nil {
+		t.Fatalf("decode projects: %v", err)
+	}
+	if len(projects.Projects) != 1 || projects.Projects[0].Name != "web" {
+		t.Errorf("expected a project-scoped key to see only its project, got %+v", projects.Projects)
+	}
+
+	if rec := serveWithKey(srv, webKey, http.MethodPut, "/v1/organizations/acme/projects/web", `{"settings":{"quota":{"max_tenants":100}}}`); rec.Code != http.StatusForbidden {
+		t.Errorf("expected a project-scoped key to be refused settings changes, got %d", rec.Code)
+	}
+	rec = serveWithKey(srv, acmeKey, http.MethodPut, "/v1/organizations/acme/projects/api", `{"display_name":"API","settings":{"quota":{"max_tenants":5}}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected project update, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = serveWithKey(srv, acmeKey, http.MethodGet, "/v1/organizations/acme/projects/api", "")
+	var updated models.ProjectResponse
+	if err := json.NewDecoder(rec.Body).Decode(&updated); err != nil {
+		t.Fatalf("decode project: %v", err)
+	}
+	if updated.DisplayName != "API" || updated.Settings.Quota.MaxTenants != 5 || len(updated.Settings.Notifications.Webhooks) != 0 {
+		t.Errorf("expected settings to be replaced, got %+v", updated)
+	}
+}
+
+func TestTenantProjectScope(t *testing.T) {
+	srv := newProjectTestServer(t)
+	spec := `"compute_config":{"image":"nginx:latest"}`
+
+	rec := serveWithKey(srv, webKey, http.MethodPost, "/v1/tenants", `{"name":"acme-web-1",`+spec+`}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected tenant to be created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created models.TenantResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode tenant: %v", err)
+	}
+	acmeWeb, err := srv.projects.GetProject(context.Background(), "acme", "web")
+	if err != nil {
+		t.Fatalf("get project: %v", err)
+	}
+	if created.ProjectID != acmeWeb.ID.String() {
+		t.Errorf("expected tenant in acme/web, got project %s", created.ProjectID)
+	}
+
+	if rec := serveWithKey(srv, otherKey, http.MethodPost, "/v1/tenants", `{"name":"other-1","project":"web",`+spec+`}`); rec.Code != http.StatusCreated {
+		t.Fatalf("expected tenant to be created, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = serveWithKey(srv, webKey, http.MethodPost, "/v1/tenants", `{"name":"sneaky","organization":"other","project":"web",`+spec+`}`)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected create outside the key's scope to be forbidden, got %d", rec.Code)
+	}
+	if code := errorCode(t, rec); code != models.ErrorCodeForbidden {
+		t.Errorf("expected %s, got %s", models.ErrorCodeForbidden, code)
+	}
+	if rec := serveWithKey(srv, acmeKey, http.MethodPost, "/v1/tenants", `{"name":"lost","project":"missing",`+spec+`}`); rec.Code != http.StatusNotFound {
+		t.Errorf("expected unknown project to be reported, got %d", rec.Code)
+	}
+
+	if rec := serveWithKey(srv, otherKey, http.MethodGet, "/v1/tenants/acme-web-1", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected tenants outside the key's scope to be hidden, got %d", rec.Code)
+	}
+	if rec := serveWithKey(srv, otherKey, http.MethodDelete, "/v1/tenants/acme-web-1", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected delete outside the key's scope to be hidden, got %d", rec.Code)
+	}
+
+	listNames := func(key, query string) []string {
+		t.Helper()
+		rec := serveWithKey(srv, key, http.MethodGet, "/v1/tenants"+query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list tenants: %d %s", rec.Code, rec.Body.String())
+		}
+		var resp models.ListTenantsResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode tenants: %v", err)
+		}
+		names := []string{}
+		for _, tenant := range resp.Tenants {
+			names = append(names, tenant.Name)
+		}
+		return names
+	}
+	if names := listNames(acmeKey, ""); len(names) != 1 || names[0] != "acme-web-1" {
+		t.Errorf("expected acme key to list only acme tenants, got %v", names)
+	}
+	if names := listNames(adminKey, ""); len(names) != 2 {
+		t.Errorf("expected admin key to list every tenant, got %v", names)
+	}
+	if names := listNames(adminKey, "?organization=other"); len(names) != 1 || names[0] != "other-1" {
+		t.Errorf("expected organization filter, got %v", names)
+	}
+	if names := listNames(acmeKey, "?organization=other"); len(names) != 0 {
+		t.Errorf("expected filters outside the key's scope to match nothing, got %v", names)
+	}
+}
+
+func TestCreateTenantProjectQuotaAndTemplate(t *testing.T) {
+	srv := newProjectTestServer(t)
+	ctx := context.Background()
+	p, err := srv.projects.GetProject(ctx, "acme", "web")
+	if err != nil {
+		t.Fatalf("get project: %v", err)
+	}
+	p.Settings = project.Settings{
+		Quota: project.Quota{MaxTenants: 1},
+		Templates: map[string]map[string]interface{}{
+			"small": {"image": "nginx:latest", "env": map[string]interface{}{"SIZE": "small", "REGION": "us"}},
+		},
+	}
+	if err := srv.projects.UpdateProject(ctx, p); err != nil {
+		t.Fatalf("update project: %v", err)
+	}
+
+	if rec := serveWithKey(srv, webKey, http.MethodPost, "/v1/tenants", `{"name":"t1","template":"large"}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected unknown template to be rejected, got %d", rec.Code)
+	}
+
+	rec := serveWithKey(srv, webKey, http.MethodPost, "/v1/tenants", `{"name":"t1","template":"small","compute_config":{"env":{"REGION":"eu"}}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected tenant from template, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created models.TenantResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode tenant: %v", err)
+	}
+	env, _ := created.ComputeConfig["env"].(map[string]interface{})
+	if created.ComputeConfig["image"] != "nginx:latest" || env["SIZE"] != "small" || env["REGION"] != "eu" {
+		t.Errorf("expected request config merged over the template, got %+v", created.ComputeConfig)
+	}
+
+	rec = serveWithKey(srv, webKey, http.MethodPost, "/v1/tenants", `{"name":"t2","template":"small"}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("expected project quota to be enforced, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if code := errorCode(t, rec); code != models.ErrorCodeQuotaExceeded {
+		t.Errorf("expected %s, got %s", models.ErrorCodeQuotaExceeded, code)
+	}
+
+	rec = serveWithKey(srv, webKey, http.MethodPost, "/v1/tenants:validate", `{"name":"t2","template":"small"}`)
+	var validation models.ValidateTenantResponse
+	if err := json.NewDecoder(rec.Body).Decode(&validation); err != nil {
+		t.Fatalf("decode validation: %v", err)
+	}
+	if validation.Valid || len(validation.Violations) != 1 || validation.Violations[0].Check != "quota" {
+		t.Errorf("expected a quota violation, got %+v", validation)
+	}
+}
+
+func TestCreateTenantProjectPolicies(t *testing.T) {
+	srv := newProjectTestServer(t)
+	ctx := context.Background()
+	p, err := srv.projects.GetProject(ctx, "acme", "web")
+	if err != nil {
+		t.Fatalf("get project: %v", err)
+	}
+	p.Settings.Policies = []project.Policy{
+		{
+			Name:           "production",
+			Selector:       map[string]string{"env": "production"},
+			ComputeConfig:  map[string]interface{}{"env": map[string]interface{}{"REPLICAS": "3"}},
+			RequiredLabels: []string{"team"},
+		},
+		{
+			Name:            "baseline",
+			ComputeProvider: "mock",
+			ComputeConfig:   map[string]interface{}{"image": "nginx:stable"},
+		},
+	}
+	if err := srv.projects.UpdateProject(ctx, p); err != nil {
+		t.Fatalf("update project: %v", err)
+	}
+
+	rec := serveWithKey(srv, webKey, http.MethodPost, "/v1/tenants", `{"name":"prod","labels":{"env":"production"}}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected missing required label to be rejected, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	body := `{"name":"prod","labels":{"env":"production","team":"web"},"annotations":{"landlord/applied_policies":"forged"},"compute_config":{"env":{"REGION":"eu"}}}`
+	rec = serveWithKey(srv, webKey, http.MethodPost, "/v1/tenants", body)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected tenant with policy defaults, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var created models.TenantResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode tenant: %v", err)
+	}
+	env, _ := created.ComputeConfig["env"].(map[string]interface{})
+	if created.ComputeConfig["compute_provider"] != "mock" || created.ComputeConfig["image"] != "nginx:stable" || env["REPLICAS"] != "3" || env["REGION"] != "eu" {
+		t.Errorf("expected policy defaults under the request config, got %+v", created.ComputeConfig)
+	}
+	if created.Annotations[project.AnnotationAppliedPolicies] != "production,baseline" ||
+		created.Annotations[project.AnnotationAppliedDefaults] != "compute_provider,env,image" {
+		t.Errorf("expected applied defaults to be recorded, got %+v", created.Annotations)
+	}
+
+	rec = serveWithKey(srv, webKey, http.MethodPost, "/v1/tenants", `{"name":"dev","compute_config":{"image":"nginx:latest"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected tenant, got %d: %s", rec.Code, rec.Body.String())
+	}
+	created = models.TenantResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("decode tenant: %v", err)
+	}
+	if created.ComputeConfig["image"] != "nginx:latest" || created.Annotations[project.AnnotationAppliedPolicies] != "baseline" ||
+		created.Annotations[project.AnnotationAppliedDefaults] != "compute_provider" {
+		t.Errorf("expected only the baseline provider default, got config %+v annotations %+v", created.ComputeConfig, created.Annotations)
+	}
+}
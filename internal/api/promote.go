@@ -0,0 +1,511 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/endpointauth"
+	"github.com/jaxxstorm/landlord/internal/imagepolicy"
+	"github.com/jaxxstorm/landlord/internal/imageupdate"
+	"github.com/jaxxstorm/landlord/internal/resource"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// SetImageRegistries lets promotions pin images in these registries, keyed by host, to their digest
+func (s *Server) SetImageRegistries(registries map[string]imageupdate.Registry) {
+	s.imageRegistries = registries
+}
+
+// handlePromoteTenant copies a tenant's configuration to its linked tenant in the next environment
+// @Summary Promote a tenant to the next environment
+// @Description Copies the tenant's image, pinned to its digest, and compute_config changes to the tenant named by its landlord/promotes_to annotation.
+// @Description The target moves to pending_approval and keeps its current configuration until the promotion is approved.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param id path string true "Source tenant identifier (UUID or name)"
+// @Param body body models.PromoteTenantRequest false "Promotion request"
+// @Success 202 {object} models.TenantResponse "Promotion awaiting approval on the target tenant"
+// @Failure 400 {object} models.ErrorResponse "Tenants are not linked environments, or the promoted configuration is invalid for the target"
+// @Failure 404 {object} models.ErrorResponse "Tenant or promotion target not found"
+// @Failure 409 {object} models.ErrorResponse "A tenant is not ready, a promotion is already pending, or there is nothing to promote"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "The image digest could not be resolved"
+// @Router /v1/tenants/{id}/promote [post]
+func (s *Server) handlePromoteTenant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	source, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	var req models.PromoteTenantRequest
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+			return
+		}
+	}
+	targetName := strings.TrimSpace(req.Target)
+	if targetName == "" {
+		targetName = strings.TrimSpace(source.Annotations[tenant.AnnotationPromotesTo])
+	}
+	if targetName == "" {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodePromotionNotAllowed, "Tenant has no promotion target",
+			[]string{fmt.Sprintf("set the %s annotation or name a target", tenant.AnnotationPromotesTo)}, requestID)
+		return
+	}
+
+	// Only a settled source has been exercised with the configuration being promoted
+	if source.Status != tenant.StatusReady {
+		s.writeInvalidStateError(w, r, "Tenant must be ready to promote from", []string{fmt.Sprintf("tenant is %s", source.Status)}, requestID)
+		return
+	}
+
+	target, err := s.lookupTenant(ctx, targetName)
+	if err != nil {
+		if errors.Is(err, tenant.ErrTenantNotFound) {
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Promotion target not found", []string{fmt.Sprintf("tenant %s does not exist", targetName)}, requestID)
+			return
+		}
+		s.logger.Error("failed to get promotion target", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve promotion target", nil, requestID)
+		return
+	}
+	if target.ID == source.ID {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodePromotionNotAllowed, "Tenant cannot be promoted to itself", nil, requestID)
+		return
+	}
+	sourceEnv, targetEnv := source.Labels[tenant.LabelEnvironment], target.Labels[tenant.LabelEnvironment]
+	if sourceEnv == "" || targetEnv == "" || sourceEnv == targetEnv {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodePromotionNotAllowed, "Promotion requires tenants in different environments",
+			[]string{fmt.Sprintf("%s has %s=%q, %s has %s=%q", source.Name, tenant.LabelEnvironment, sourceEnv, target.Name, tenant.LabelEnvironment, targetEnv)}, requestID)
+		return
+	}
+
+	target, release, ok := s.lockTenant(w, r, target, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if promotion := target.Promotion(); target.Status == tenant.StatusPendingApproval && promotion != nil {
+		s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Tenant already has a promotion awaiting approval",
+			[]string{fmt.Sprintf("promotion from %s requested by %s", promotion.Source, promotion.RequestedBy)}, requestID)
+		return
+	}
+	if target.Status != tenant.StatusReady {
+		s.writeInvalidStateError(w, r, "Tenant must be ready to receive a promotion", []string{fmt.Sprintf("%s is %s", target.Name, target.Status)}, requestID)
+		return
+	}
+
+	sourceConfig, err := s.pinImage(ctx, source.DesiredConfig)
+	if err != nil {
+		s.writeError(w, r, http.StatusServiceUnavailable, models.ErrorCodeServiceUnavailable, "Failed to resolve image digest", []string{err.Error()}, requestID)
+		return
+	}
+	promoted := tenant.PromotedConfig(sourceConfig, target.DesiredConfig, target.PromotionExclusions())
+	changes := tenant.DiffConfig(target.DesiredConfig, promoted)
+	if len(changes) == 0 {
+		s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Nothing to promote",
+			[]string{fmt.Sprintf("%s already has the configuration of %s", target.Name, source.Name)}, requestID)
+		return
+	}
+
+	if !s.validatePromotedConfig(w, r, target, promoted, requestID) {
+		return
+	}
+	// The target's labels select the image policy rules, so a staging image may be refused for prod
+	if !s.enforceImagePolicy(w, r, &tenant.Tenant{Labels: target.Labels, DesiredConfig: promoted}, requestID) {
+		return
+	}
+
+	now := time.Now()
+	manager := fieldManager(r)
+	transition := tenant.NewStateTransition(target, tenant.StatusPendingApproval, fmt.Sprintf("Promotion from %s requested", source.Name), manager).
+		WithReasonCode(tenant.ReasonPromotionRequested)
+
+	if err := target.RequestPromotion(source.Name, promoted, manager, now); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid compute configuration format", []string{err.Error()}, requestID)
+		return
+	}
+	previousStatus := target.Status
+	target.Status = tenant.StatusPendingApproval
+	target.StatusMessage = fmt.Sprintf("Promotion from %s awaiting approval", source.Name)
+	if err := tenant.ValidateTransition(previousStatus, target.Status); err != nil {
+		s.writeInvalidStateError(w, r, "Invalid state transition", []string{err.Error()}, requestID)
+		return
+	}
+
+	target.UpdatedAt = now
+	if !s.savePromotion(w, r, target, "Failed to request promotion", requestID) {
+		return
+	}
+
+	transition.DesiredStateSnapshot = promoted
+	transition.ObservedStateSnapshot = map[string]interface{}{
+		"source":       source.Name,
+		"source_env":   sourceEnv,
+		"target_env":   targetEnv,
+		"changes":      changes,
+		"requested_by": manager,
+	}
+	s.recordTransition(ctx, transition, requestID)
+
+	s.logger.Info("tenant promotion requested, awaiting approval",
+		zap.String("source", source.Name),
+		zap.String("target", target.Name),
+		zap.Int("changes", len(changes)),
+		zap.String("request_id", requestID))
+
+	resp := models.ToTenantResponse(target)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleApprovePromotion applies a pending promotion
+// @Summary Approve a pending promotion
+// @Description Applies the promoted compute_config and starts the update workflow.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Target tenant identifier (UUID or name)"
+// @Success 202 {object} models.TenantResponse "Promotion approved, tenant updating"
+// @Failure 400 {object} models.ErrorResponse "The promoted configuration is no longer valid"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 409 {object} models.ErrorResponse "Tenant has no promotion awaiting approval"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/promotion/approve [post]
+func (s *Server) handleApprovePromotion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	promotion, ok := s.pendingPromotion(w, r, t, requestID)
+	if !ok {
+		return
+	}
+
+	// Providers and the image policy may have changed while the promotion waited
+	if !s.validatePromotedConfig(w, r, t, promotion.Config, requestID) {
+		return
+	}
+	now := time.Now()
+	manager := fieldManager(r)
+	transition := tenant.NewStateTransition(t, tenant.StatusUpdating, fmt.Sprintf("Promotion from %s approved", promotion.Source), manager).
+		WithReasonCode(tenant.ReasonPromotionApproved)
+
+	previousConfig := t.DesiredConfig
+	t.DesiredConfig = promotion.Config
+	if !s.enforceImagePolicy(w, r, t, requestID) {
+		return
+	}
+	t.UpdateManagedFields(previousConfig, manager, tenant.ManagedFieldOperationUpdate, now)
+	t.ClearPromotion()
+
+	previousStatus := t.Status
+	t.Status = tenant.StatusUpdating
+	t.StatusMessage = fmt.Sprintf("Promotion from %s approved", promotion.Source)
+	t.WorkflowExecutionID = nil
+	t.WorkflowStartedAt = nil
+	t.WorkflowSubState = nil
+	t.WorkflowRetryCount = nil
+	t.WorkflowErrorMessage = nil
+	if err := tenant.ValidateTransition(previousStatus, t.Status); err != nil {
+		s.writeInvalidStateError(w, r, "Invalid state transition", []string{err.Error()}, requestID)
+		return
+	}
+
+	t.UpdatedAt = now
+	if !s.savePromotion(w, r, t, "Failed to approve promotion", requestID) {
+		return
+	}
+
+	transition.DesiredStateSnapshot = t.DesiredConfig
+	transition.ObservedStateSnapshot = map[string]interface{}{
+		"source":       promotion.Source,
+		"changes":      promotion.Changes,
+		"requested_by": promotion.RequestedBy,
+		"approved_by":  manager,
+	}
+	s.recordTransition(ctx, transition, requestID)
+
+	s.logger.Info("tenant promotion approved",
+		zap.String("source", promotion.Source),
+		zap.String("target", t.Name),
+		zap.String("approved_by", manager),
+		zap.String("request_id", requestID))
+
+	resp := models.ToTenantResponse(t)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleRejectPromotion discards a pending promotion
+// @Summary Reject a pending promotion
+// @Description Discards the promoted compute_config; the tenant returns to ready with its configuration unchanged.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param id path string true "Target tenant identifier (UUID or name)"
+// @Param body body models.RejectPromotionRequest false "Rejection reason"
+// @Success 200 {object} models.TenantResponse "Promotion rejected"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 409 {object} models.ErrorResponse "Tenant has no promotion awaiting approval"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/promotion/reject [post]
+func (s *Server) handleRejectPromotion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	var req models.RejectPromotionRequest
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+			return
+		}
+	}
+
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	promotion, ok := s.pendingPromotion(w, r, t, requestID)
+	if !ok {
+		return
+	}
+
+	manager := fieldManager(r)
+	reason := fmt.Sprintf("Promotion from %s rejected", promotion.Source)
+	if strings.TrimSpace(req.Reason) != "" {
+		reason += ": " + strings.TrimSpace(req.Reason)
+	}
+	transition := tenant.NewStateTransition(t, tenant.StatusReady, reason, manager).WithReasonCode(tenant.ReasonPromotionRejected)
+
+	t.ClearPromotion()
+	previousStatus := t.Status
+	t.Status = tenant.StatusReady
+	t.StatusMessage = reason
+	if err := tenant.ValidateTransition(previousStatus, t.Status); err != nil {
+		s.writeInvalidStateError(w, r, "Invalid state transition", []string{err.Error()}, requestID)
+		return
+	}
+
+	t.UpdatedAt = time.Now()
+	if !s.savePromotion(w, r, t, "Failed to reject promotion", requestID) {
+		return
+	}
+
+	transition.DesiredStateSnapshot = promotion.Config
+	transition.ObservedStateSnapshot = map[string]interface{}{
+		"source":       promotion.Source,
+		"changes":      promotion.Changes,
+		"requested_by": promotion.RequestedBy,
+		"rejected_by":  manager,
+	}
+	s.recordTransition(ctx, transition, requestID)
+
+	s.logger.Info("tenant promotion rejected",
+		zap.String("source", promotion.Source),
+		zap.String("target", t.Name),
+		zap.String("rejected_by", manager),
+		zap.String("request_id", requestID))
+
+	resp := models.ToTenantResponse(t)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// tenantFromPath looks up the tenant named by the id path parameter, writing the error response when it cannot
+func (s *Server) tenantFromPath(w http.ResponseWriter, r *http.Request, requestID string) (*tenant.Tenant, bool) {
+	return s.findTenantFromPath(w, r, requestID, s.lookupTenant)
+}
+
+// readTenantFromPath is tenantFromPath for handlers that do not change the tenant, so it may come from the tenant cache
+func (s *Server) readTenantFromPath(w http.ResponseWriter, r *http.Request, requestID string) (*tenant.Tenant, bool) {
+	return s.findTenantFromPath(w, r, requestID, s.readTenant)
+}
+
+func (s *Server) findTenantFromPath(w http.ResponseWriter, r *http.Request, requestID string, find func(ctx context.Context, identifier string) (*tenant.Tenant, error)) (*tenant.Tenant, bool) {
+	identifier := chi.URLParam(r, "id")
+	if strings.TrimSpace(identifier) == "" {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "tenant identifier is required", nil, requestID)
+		return nil, false
+	}
+	if isUUIDLike(identifier) {
+		if _, err := uuid.Parse(identifier); err != nil {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "invalid tenant identifier format", []string{err.Error()}, requestID)
+			return nil, false
+		}
+	}
+
+	t, err := find(r.Context(), identifier)
+	if err != nil {
+		if errors.Is(err, tenant.ErrTenantNotFound) {
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant not found", nil, requestID)
+			return nil, false
+		}
+		s.logger.Error("failed to get tenant", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+		return nil, false
+	}
+	return t, true
+}
+
+// pendingPromotion returns t's promotion, writing the error response when none awaits approval
+func (s *Server) pendingPromotion(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, requestID string) (*tenant.Promotion, bool) {
+	promotion := t.Promotion()
+	if t.Status != tenant.StatusPendingApproval || promotion == nil {
+		s.writeInvalidStateError(w, r, "Tenant has no promotion awaiting approval", []string{fmt.Sprintf("tenant is %s", t.Status)}, requestID)
+		return nil, false
+	}
+	return promotion, true
+}
+
+// validatePromotedConfig checks config against the target tenant's compute provider, writing the
+// error response when it is not valid there
+func (s *Server) validatePromotedConfig(w http.ResponseWriter, r *http.Request, target *tenant.Tenant, config map[string]interface{}, requestID string) bool {
+	provider, _, err := s.resolveComputeProvider(target.Name, config, target.Labels, target.Annotations, target)
+	if err != nil {
+		s.writeComputeProviderError(w, r, err, requestID)
+		return false
+	}
+	configs, err := componentConfigs(config)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid components configuration", []string{err.Error()}, requestID)
+		return false
+	}
+	for _, component := range configs {
+		if err := compute.ValidateConfigAgainstSchema(provider, component.raw); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Promoted configuration is not valid for the target", component.details(computeSchemaErrorDetails(err)), requestID)
+			return false
+		}
+		if err := provider.ValidateConfig(component.raw); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Promoted configuration is not valid for the target", component.details([]string{err.Error()}), requestID)
+			return false
+		}
+		if _, err := workflow.ParseReadinessProbe(component.values); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid readiness probe configuration", component.details([]string{err.Error()}), requestID)
+			return false
+		}
+	}
+	if _, err := workflow.ParseHooks(config); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid hooks configuration", []string{err.Error()}, requestID)
+		return false
+	}
+	if _, err := endpointauth.Parse(config); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid endpoint auth configuration", []string{err.Error()}, requestID)
+		return false
+	}
+	if _, err := compute.ParseEgressPolicy(config); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid egress configuration", []string{err.Error()}, requestID)
+		return false
+	}
+	if _, err := resource.ParseSpecs(config); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid resources configuration", []string{err.Error()}, requestID)
+		return false
+	}
+	return true
+}
+
+// savePromotion persists a promotion step, writing the error response when it fails
+func (s *Server) savePromotion(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, failure, requestID string) bool {
+	if err := s.tenantRepo.UpdateTenant(r.Context(), t); err != nil {
+		if errors.Is(err, tenant.ErrVersionConflict) {
+			s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Tenant was modified concurrently, retry the request", nil, requestID)
+			return false
+		}
+		s.logger.Error("failed to update tenant promotion", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, failure, nil, requestID)
+		return false
+	}
+	return true
+}
+
+// recordTransition appends a transition to the tenant's history; the change itself is already saved
+func (s *Server) recordTransition(ctx context.Context, transition *tenant.StateTransition, requestID string) {
+	if err := s.tenantRepo.RecordStateTransition(ctx, transition); err != nil {
+		s.logger.Warn("failed to record state transition",
+			zap.String("tenant_id", transition.TenantID.String()),
+			zap.Error(err),
+			zap.String("request_id", requestID))
+	}
+}
+
+// pinImage returns config with its image pinned to the digest the tag currently resolves to, so the
+// target runs exactly what the source runs. Images already pinned, or in registries without a
+// configured client, are copied as written.
+func (s *Server) pinImage(ctx context.Context, config map[string]interface{}) (map[string]interface{}, error) {
+	image, ok := config["image"].(string)
+	if !ok || image == "" {
+		return config, nil
+	}
+	ref, err := imagepolicy.ParseReference(image)
+	if err != nil || ref.Digest != "" {
+		return config, nil
+	}
+	registry, ok := s.imageRegistries[ref.Registry]
+	if !ok {
+		return config, nil
+	}
+	digest, err := registry.Digest(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("resolve digest of %s: %w", image, err)
+	}
+
+	pinned := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		pinned[key] = value
+	}
+	pinned["image"] = image + "@" + digest
+	return pinned, nil
+}
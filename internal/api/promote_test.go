@@ -0,0 +1,227 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/imagepolicy"
+	"github.com/jaxxstorm/landlord/internal/imageupdate"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+const promotedDigest = "sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+type digestRegistry struct{}
+
+func (digestRegistry) Tags(ctx context.Context, ref imagepolicy.Reference) ([]string, error) {
+	return nil, nil
+}
+
+func (digestRegistry) Digest(ctx context.Context, ref imagepolicy.Reference) (string, error) {
+	return promotedDigest, nil
+}
+
+func newPromotionServer(t *testing.T) (*Server, *tenantmemory.Repository) {
+	t.Helper()
+	ctx := context.Background()
+	repo := tenantmemory.New()
+	tenants := []*tenant.Tenant{
+		{
+			Name:          "web-staging",
+			Status:        tenant.StatusReady,
+			Labels:        map[string]string{"env": "staging"},
+			Annotations:   map[string]string{tenant.AnnotationPromotesTo: "web-prod"},
+			DesiredConfig: map[string]interface{}{"image": "registry.local/web:1.3", "replicas": float64(1), "log_level": "debug"},
+		},
+		{
+			Name:          "web-prod",
+			Status:        tenant.StatusReady,
+			Labels:        map[string]string{"env": "prod"},
+			Annotations:   map[string]string{tenant.AnnotationPromotionExclude: "replicas"},
+			DesiredConfig: map[string]interface{}{"image": "registry.local/web:1.2", "replicas": float64(4), "log_level": "info"},
+		},
+		{
+			Name:          "web-qa",
+			Status:        tenant.StatusReady,
+			Labels:        map[string]string{"env": "staging"},
+			DesiredConfig: map[string]interface{}{"image": "registry.local/web:1.3"},
+		},
+	}
+	for _, tn := range tenants {
+		if err := repo.CreateTenant(ctx, tn); err != nil {
+			t.Fatalf("create tenant %s: %v", tn.Name, err)
+		}
+	}
+
+	srv := &Server{
+		router:                 chi.NewRouter(),
+		logger:                 zap.NewNop(),
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+		tenantRepo:             repo,
+	}
+	srv.SetImageRegistries(map[string]imageupdate.Registry{"registry.local": digestRegistry{}})
+	srv.registerRoutes()
+	return srv, repo
+}
+
+func doPromotionRequest(srv *Server, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Field-Manager", "release-manager")
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPromoteTenantAndApprove(t *testing.T) {
+	ctx := context.Background()
+	srv, repo := newPromotionServer(t)
+
+	rec := doPromotionRequest(srv, http.MethodPost, "/v1/tenants/web-staging/promote", "")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("promote: expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp models.TenantResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Name != "web-prod" || resp.Status != string(tenant.StatusPendingApproval) {
+		t.Fatalf("expected web-prod pending approval, got %s %s", resp.Name, resp.Status)
+	}
+	if resp.Promotion == nil || resp.Promotion.Source != "web-staging" || resp.Promotion.RequestedBy != "release-manager" {
+		t.Fatalf("unexpected promotion: %+v", resp.Promotion)
+	}
+	changed := map[string]bool{}
+	for _, change := range resp.Promotion.Changes {
+		changed[change.Field] = true
+	}
+	if len(changed) != 2 || !changed["image"] || !changed["log_level"] {
+		t.Fatalf("expected image and log_level to change, got %+v", resp.Promotion.Changes)
+	}
+	if resp.ComputeConfig["image"] != "registry.local/web:1.2" {
+		t.Fatalf("target configuration changed before approval: %v", resp.ComputeConfig)
+	}
+
+	// The target is frozen while the promotion waits
+	rec = doPromotionRequest(srv, http.MethodPut, "/v1/tenants/web-prod", `{"compute_config": {"image": "registry.local/web:1.4"}}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("update: expected status 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = doPromotionRequest(srv, http.MethodPost, "/v1/tenants/web-staging/promote", "")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("second promote: expected status 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doPromotionRequest(srv, http.MethodPost, "/v1/tenants/web-prod/promotion/approve", "")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("approve: expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	prod, err := repo.GetTenantByName(ctx, "web-prod")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if prod.Status != tenant.StatusUpdating {
+		t.Fatalf("expected updating after approval, got %s", prod.Status)
+	}
+	if got := prod.DesiredConfig["image"]; got != "registry.local/web:1.3@"+promotedDigest {
+		t.Fatalf("expected image pinned to the promoted digest, got %v", got)
+	}
+	if prod.DesiredConfig["replicas"] != float64(4) || prod.DesiredConfig["log_level"] != "debug" {
+		t.Fatalf("unexpected promoted config: %v", prod.DesiredConfig)
+	}
+	if prod.Promotion() != nil {
+		t.Fatalf("expected the promotion record to be cleared")
+	}
+	if manager := prod.ManagedFields["image"].Manager; manager != "release-manager" {
+		t.Fatalf("expected image managed by the approver, got %q", manager)
+	}
+
+	history, err := repo.GetStateHistory(ctx, prod.ID, tenant.HistoryFilters{})
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	var reasons []string
+	for _, transition := range history {
+		reasons = append(reasons, transition.Reason)
+	}
+	joined := strings.Join(reasons, "|")
+	if !strings.Contains(joined, "Promotion from web-staging requested") || !strings.Contains(joined, "Promotion from web-staging approved") {
+		t.Fatalf("expected request and approval in history, got %v", reasons)
+	}
+}
+
+func TestRejectPromotion(t *testing.T) {
+	ctx := context.Background()
+	srv, repo := newPromotionServer(t)
+
+	rec := doPromotionRequest(srv, http.MethodPost, "/v1/tenants/web-prod/promotion/reject", "")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("reject without promotion: expected status 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doPromotionRequest(srv, http.MethodPost, "/v1/tenants/web-staging/promote", `{"target": "web-prod"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("promote: expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = doPromotionRequest(srv, http.MethodPost, "/v1/tenants/web-prod/promotion/reject", `{"reason": "failing smoke tests"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("reject: expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	prod, err := repo.GetTenantByName(ctx, "web-prod")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if prod.Status != tenant.StatusReady || prod.Promotion() != nil {
+		t.Fatalf("expected ready with no promotion, got %s %+v", prod.Status, prod.Promotion())
+	}
+	if prod.DesiredConfig["image"] != "registry.local/web:1.2" {
+		t.Fatalf("rejected promotion changed the config: %v", prod.DesiredConfig)
+	}
+	if !strings.Contains(prod.StatusMessage, "failing smoke tests") {
+		t.Fatalf("expected the rejection reason in the status message, got %q", prod.StatusMessage)
+	}
+}
+
+func TestPromoteRequiresLinkedEnvironments(t *testing.T) {
+	srv, _ := newPromotionServer(t)
+
+	cases := []struct {
+		name string
+		path string
+		body string
+	}{
+		{"no target", "/v1/tenants/web-qa/promote", ""},
+		{"same environment", "/v1/tenants/web-qa/promote", `{"target": "web-staging"}`},
+		{"itself", "/v1/tenants/web-staging/promote", `{"target": "web-staging"}`},
+	}
+	for _, tc := range cases {
+		rec := doPromotionRequest(srv, http.MethodPost, tc.path, tc.body)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status 400, got %d: %s", tc.name, rec.Code, rec.Body.String())
+		}
+		var problem models.ProblemDetails
+		if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+			t.Fatalf("%s: decode problem: %v", tc.name, err)
+		}
+		if problem.ErrorCode != models.ErrorCodePromotionNotAllowed {
+			t.Fatalf("%s: expected error code %s, got %s", tc.name, models.ErrorCodePromotionNotAllowed, problem.ErrorCode)
+		}
+	}
+
+	rec := doPromotionRequest(srv, http.MethodPost, "/v1/tenants/web-staging/promote", `{"target": "web-missing"}`)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing target: expected status 404, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// handleListReasonCodes lists the reason codes state transitions and conditions carry
+// @Summary List reason codes
+// @Description Returns the machine-readable reason codes recorded on state transitions, events and conditions, with an English description of each. UIs map codes to localized strings; alerting filters on them.
+// @Tags meta
+// @Produce json
+// @Success 200 {object} models.ListReasonCodesResponse "Reason codes"
+// @Router /v1/meta/reason-codes [get]
+func (s *Server) handleListReasonCodes(w http.ResponseWriter, r *http.Request) {
+	resp := models.ListReasonCodesResponse{ReasonCodes: tenant.ReasonCodes}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
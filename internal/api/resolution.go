@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/resolution"
+)
+
+// handleGetTenantResolution explains which compute provider a tenant resolves to
+// @Summary Explain a tenant's compute provider
+// @Description Returns the compute provider the tenant resolves to, where it came from, and how every compute resolution rule evaluated
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Success 200 {object} models.TenantResolutionResponse "Resolution explanation"
+// @Failure 400 {object} models.ErrorResponse "Invalid tenant identifier format"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/resolution [get]
+func (s *Server) handleGetTenantResolution(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+
+	t, ok := s.readTenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	resp := models.TenantResolutionResponse{
+		TenantID:   t.ID.String(),
+		TenantName: t.Name,
+		Explanation: s.computeResolution.Explain(resolution.Subject{
+			Name:        t.Name,
+			Config:      t.DesiredConfig,
+			Labels:      t.Labels,
+			Annotations: t.Annotations,
+		}, s.defaultComputeProvider),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
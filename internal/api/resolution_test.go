@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/resolution"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func newResolutionTestServer(t *testing.T, tenants ...*tenant.Tenant) *Server {
+	t.Helper()
+	repo := tenantmemory.New()
+	for _, tn := range tenants {
+		if err := repo.CreateTenant(context.Background(), tn); err != nil {
+			t.Fatalf("create tenant: %v", err)
+		}
+	}
+	srv := &Server{
+		router:                 chi.NewRouter(),
+		logger:                 zap.NewNop(),
+		tenantRepo:             repo,
+		computeRegistry:        newMigrationTestRegistry(),
+		defaultComputeProvider: "docker",
+		computeResolution: resolution.New(config.ComputeResolutionConfig{Rules: []config.ComputeResolutionRuleConfig{
+			{Name: "gpu", Selector: map[string]string{"tier": "gpu"}, Provider: "kubernetes"},
+			{Name: "prod", NamePattern: "prod-*", Provider: "ecs"},
+		}}),
+	}
+	srv.registerRoutes()
+	return srv
+}
+
+func TestResolveComputeProviderAppliesRules(t *testing.T) {
+	srv := newResolutionTestServer(t)
+
+	tests := []struct {
+		name     string
+		tenant   string
+		config   map[string]interface{}
+		labels   map[string]string
+		fallback *tenant.Tenant
+		want     string
+	}{
+		{name: "label rule", tenant: "acme", labels: map[string]string{"tier": "gpu"}, want: "kubernetes"},
+		{name: "name rule", tenant: "prod-acme", want: "ecs"},
+		{name: "first rule wins", tenant: "prod-acme", labels: map[string]string{"tier": "gpu"}, want: "kubernetes"},
+		{name: "explicit beats rules", tenant: "prod-acme", config: map[string]interface{}{"compute_provider": "docker"}, want: "docker"},
+		{name: "existing tenant's provider beats rules", tenant: "prod-acme", fallback: &tenant.Tenant{DesiredConfig: map[string]interface{}{"compute_provider": "kubernetes"}}, want: "kubernetes"},
+		{name: "default when nothing matches", tenant: "acme", want: "docker"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, err := srv.resolveComputeProvider(tt.tenant, tt.config, tt.labels, nil, tt.fallback)
+			if err != nil {
+				t.Fatalf("resolve provider: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected provider %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestGetTenantResolution(t *testing.T) {
+	srv := newResolutionTestServer(t, &tenant.Tenant{Name: "prod-acme", Status: tenant.StatusReady, Labels: map[string]string{"tier": "cpu"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/tenants/prod-acme/resolution", nil)
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp models.TenantResolutionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.TenantName != "prod-acme" || resp.Provider != "ecs" || resp.Source != resolution.SourceRule || resp.Rule != "prod" {
+		t.Errorf("unexpected resolution %+v", resp)
+	}
+	if len(resp.Rules) != 2 || resp.Rules[0].Matched || resp.Rules[0].Reason != `label tier is "cpu", not "gpu"` || !resp.Rules[1].Matched {
+		t.Errorf("unexpected rule results %+v", resp.Rules)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/tenants/missing/resolution", nil)
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
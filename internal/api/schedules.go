@@ -0,0 +1,417 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/schedule"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+const (
+	defaultScheduleRunLimit = 50
+	maxScheduleRunLimit     = 500
+)
+
+// SetSchedules enables the tenant schedule endpoints
+func (s *Server) SetSchedules(store schedule.Store) {
+	s.schedules = store
+}
+
+// handleCreateSchedule adds a schedule to a tenant
+// @Summary Create a tenant schedule
+// @Description Runs restart, suspend, resume or one of the tenant's hooks whenever the cron expression matches.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant ID or name"
+// @Param body body models.ScheduleRequest true "Schedule"
+// @Success 201 {object} models.ScheduleResponse "Schedule created"
+// @Failure 400 {object} models.ErrorResponse "Invalid schedule, or the tenant's compute provider does not support the action"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 409 {object} models.ErrorResponse "The tenant already has a schedule with the name"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Schedules not configured"
+// @Router /v1/tenants/{id}/schedules [post]
+func (s *Server) handleCreateSchedule(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireSchedules(w, r, requestID) {
+		return
+	}
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok || !s.tenantChangeAllowed(w, r, t, requestID) {
+		return
+	}
+
+	sched := &schedule.Schedule{TenantID: t.ID}
+	if !s.decodeSchedule(w, r, t, sched, requestID) {
+		return
+	}
+	if err := s.schedules.Create(r.Context(), sched); err != nil {
+		s.writeScheduleError(w, r, err, requestID)
+		return
+	}
+
+	s.logger.Info("schedule created",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("schedule", sched.Name),
+		zap.String("action", string(sched.Action)),
+		zap.String("cron", sched.Cron),
+		zap.String("request_id", requestID))
+
+	s.writeSchedule(w, http.StatusCreated, sched)
+}
+
+// handleListSchedules lists a tenant's schedules
+// @Summary List tenant schedules
+// @Tags schedules
+// @Produce json
+// @Param id path string true "Tenant ID or name"
+// @Success 200 {object} models.ListSchedulesResponse "Schedules"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Schedules not configured"
+// @Router /v1/tenants/{id}/schedules [get]
+func (s *Server) handleListSchedules(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireSchedules(w, r, requestID) {
+		return
+	}
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	schedules, err := s.schedules.List(r.Context(), schedule.ListFilters{TenantID: &t.ID})
+	if err != nil {
+		s.logger.Error("failed to list schedules", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list schedules", nil, requestID)
+		return
+	}
+
+	resp := models.ListSchedulesResponse{Schedules: make([]models.ScheduleResponse, 0, len(schedules))}
+	for _, sched := range schedules {
+		resp.Schedules = append(resp.Schedules, models.ToScheduleResponse(sched))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleGetSchedule returns one of a tenant's schedules
+// @Summary Get a tenant schedule
+// @Tags schedules
+// @Produce json
+// @Param id path string true "Tenant ID or name"
+// @Param schedule path string true "Schedule ID or name"
+// @Success 200 {object} models.ScheduleResponse "Schedule"
+// @Failure 404 {object} models.ErrorResponse "Tenant or schedule not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Schedules not configured"
+// @Router /v1/tenants/{id}/schedules/{schedule} [get]
+func (s *Server) handleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireSchedules(w, r, requestID) {
+		return
+	}
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	sched, ok := s.scheduleFromPath(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	s.writeSchedule(w, http.StatusOK, sched)
+}
+
+// handleUpdateSchedule replaces a tenant schedule's definition
+// @Summary Replace a tenant schedule
+// @Description Replaces the schedule's definition and recomputes its next run. Run history is kept.
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant ID or name"
+// @Param schedule path string true "Schedule ID or name"
+// @Param body body models.ScheduleRequest true "Schedule"
+// @Success 200 {object} models.ScheduleResponse "Schedule updated"
+// @Failure 400 {object} models.ErrorResponse "Invalid schedule, or the tenant's compute provider does not support the action"
+// @Failure 404 {object} models.ErrorResponse "Tenant or schedule not found"
+// @Failure 409 {object} models.ErrorResponse "The tenant already has a schedule with the name"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Schedules not configured"
+// @Router /v1/tenants/{id}/schedules/{schedule} [put]
+func (s *Server) handleUpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireSchedules(w, r, requestID) {
+		return
+	}
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok || !s.tenantChangeAllowed(w, r, t, requestID) {
+		return
+	}
+	sched, ok := s.scheduleFromPath(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	if !s.decodeSchedule(w, r, t, sched, requestID) {
+		return
+	}
+	if err := s.schedules.Update(r.Context(), sched); err != nil {
+		s.writeScheduleError(w, r, err, requestID)
+		return
+	}
+
+	s.logger.Info("schedule updated",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("schedule", sched.Name),
+		zap.String("action", string(sched.Action)),
+		zap.String("cron", sched.Cron),
+		zap.Bool("enabled", sched.Enabled),
+		zap.String("request_id", requestID))
+
+	s.writeSchedule(w, http.StatusOK, sched)
+}
+
+// handleDeleteSchedule removes a tenant schedule and its run history
+// @Summary Delete a tenant schedule
+// @Description A run already in progress finishes, but is no longer recorded.
+// @Tags schedules
+// @Param id path string true "Tenant ID or name"
+// @Param schedule path string true "Schedule ID or name"
+// @Success 204 "Schedule deleted"
+// @Failure 404 {object} models.ErrorResponse "Tenant or schedule not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Schedules not configured"
+// @Router /v1/tenants/{id}/schedules/{schedule} [delete]
+func (s *Server) handleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireSchedules(w, r, requestID) {
+		return
+	}
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok || !s.tenantChangeAllowed(w, r, t, requestID) {
+		return
+	}
+	sched, ok := s.scheduleFromPath(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	if err := s.schedules.Delete(r.Context(), sched.ID); err != nil {
+		s.writeScheduleError(w, r, err, requestID)
+		return
+	}
+
+	s.logger.Info("schedule deleted",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("schedule", sched.Name),
+		zap.String("request_id", requestID))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListScheduleRuns returns a schedule's run history
+// @Summary List schedule runs
+// @Description Lists the schedule's runs, newest first, including runs skipped because the previous run was still in progress or the tenant was not ready.
+// @Tags schedules
+// @Produce json
+// @Param id path string true "Tenant ID or name"
+// @Param schedule path string true "Schedule ID or name"
+// @Param status query string false "Filter by status (running, succeeded, failed, skipped)"
+// @Param limit query int false "Maximum number of runs (default 50, max 500)"
+// @Success 200 {object} models.ListScheduleRunsResponse "Runs"
+// @Failure 400 {object} models.ErrorResponse "Invalid filter"
+// @Failure 404 {object} models.ErrorResponse "Tenant or schedule not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Failure 503 {object} models.ErrorResponse "Schedules not configured"
+// @Router /v1/tenants/{id}/schedules/{schedule}/runs [get]
+func (s *Server) handleListScheduleRuns(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+	if !s.requireSchedules(w, r, requestID) {
+		return
+	}
+
+	filters := schedule.RunFilters{Limit: defaultScheduleRunLimit}
+	if status := strings.TrimSpace(r.URL.Query().Get("status")); status != "" {
+		filters.Status = schedule.RunStatus(status)
+		switch filters.Status {
+		case schedule.RunStatusRunning, schedule.RunStatusSucceeded, schedule.RunStatusFailed, schedule.RunStatusSkipped:
+		default:
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid status filter",
+				[]string{fmt.Sprintf("status must be %s, %s, %s or %s", schedule.RunStatusRunning, schedule.RunStatusSucceeded, schedule.RunStatusFailed, schedule.RunStatusSkipped)}, requestID)
+			return
+		}
+	}
+	if limit := strings.TrimSpace(r.URL.Query().Get("limit")); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > maxScheduleRunLimit {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid limit",
+				[]string{fmt.Sprintf("limit must be between 1 and %d", maxScheduleRunLimit)}, requestID)
+			return
+		}
+		filters.Limit = n
+	}
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+	sched, ok := s.scheduleFromPath(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	filters.ScheduleID = &sched.ID
+
+	runs, err := s.schedules.ListRuns(r.Context(), filters)
+	if err != nil {
+		s.logger.Error("failed to list schedule runs", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list schedule runs", nil, requestID)
+		return
+	}
+
+	resp := models.ListScheduleRunsResponse{Runs: make([]models.ScheduleRunResponse, 0, len(runs))}
+	for _, run := range runs {
+		resp.Runs = append(resp.Runs, models.ToScheduleRunResponse(run))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// requireSchedules writes a 503 when no schedule store is configured
+func (s *Server) requireSchedules(w http.ResponseWriter, r *http.Request, requestID string) bool {
+	if s.schedules == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Schedules not configured", nil, requestID)
+		return false
+	}
+	return true
+}
+
+// scheduleFromPath looks up the schedule named by the schedule path parameter, by ID or name,
+// among t's schedules
+func (s *Server) scheduleFromPath(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, requestID string) (*schedule.Schedule, bool) {
+	ctx := r.Context()
+	identifier := chi.URLParam(r, "schedule")
+
+	var found *schedule.Schedule
+	if id, err := uuid.Parse(identifier); err == nil {
+		sched, err := s.schedules.Get(ctx, id)
+		if err != nil && !errors.Is(err, schedule.ErrNotFound) {
+			s.logger.Error("failed to get schedule", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve schedule", nil, requestID)
+			return nil, false
+		}
+		if err == nil && sched.TenantID == t.ID {
+			found = sched
+		}
+	} else {
+		schedules, err := s.schedules.List(ctx, schedule.ListFilters{TenantID: &t.ID})
+		if err != nil {
+			s.logger.Error("failed to list schedules", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve schedule", nil, requestID)
+			return nil, false
+		}
+		for _, sched := range schedules {
+			if sched.Name == identifier {
+				found = sched
+				break
+			}
+		}
+	}
+
+	if found == nil {
+		s.writeErrorResponse(w, r, http.StatusNotFound, "Schedule not found", nil, requestID)
+		return nil, false
+	}
+	return found, true
+}
+
+// decodeSchedule reads a ScheduleRequest into sched, checks that t can run it and computes its next run
+func (s *Server) decodeSchedule(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, sched *schedule.Schedule, requestID string) bool {
+	var req models.ScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return false
+	}
+	defer r.Body.Close()
+
+	sched.Name = strings.TrimSpace(req.Name)
+	sched.Cron = strings.TrimSpace(req.Cron)
+	sched.Timezone = strings.TrimSpace(req.Timezone)
+	sched.Action = schedule.Action(strings.TrimSpace(req.Action))
+	sched.Hook = strings.TrimSpace(req.Hook)
+	sched.Enabled = req.Enabled == nil || *req.Enabled
+
+	if err := sched.Validate(); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Invalid schedule", []string{err.Error()}, requestID)
+		return false
+	}
+	if !s.scheduleActionSupported(w, r, t, sched, requestID) {
+		return false
+	}
+	if err := sched.Reschedule(time.Now()); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Invalid schedule", []string{err.Error()}, requestID)
+		return false
+	}
+	return true
+}
+
+// scheduleActionSupported checks that a run_hook schedule names a hook t declares, and that
+// t's compute provider can restart, suspend and resume it
+func (s *Server) scheduleActionSupported(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, sched *schedule.Schedule, requestID string) bool {
+	if sched.Action == schedule.ActionRunHook {
+		if _, _, err := schedule.FindHook(t.DesiredConfig, sched.Hook); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Invalid schedule", []string{err.Error()}, requestID)
+			return false
+		}
+		return true
+	}
+
+	provider, providerName, err := s.resolveComputeProvider(t.Name, t.DesiredConfig, t.Labels, t.Annotations, nil)
+	if err != nil {
+		s.writeComputeProviderError(w, r, err, requestID)
+		return false
+	}
+	if _, ok := provider.(compute.PowerManager); !ok {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Compute provider does not support the action",
+			[]string{fmt.Sprintf("compute provider %s cannot %s tenants", providerName, sched.Action)}, requestID)
+		return false
+	}
+	return true
+}
+
+func (s *Server) writeScheduleError(w http.ResponseWriter, r *http.Request, err error, requestID string) {
+	switch {
+	case errors.Is(err, schedule.ErrNameConflict):
+		s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Schedule name already exists for tenant", nil, requestID)
+	case errors.Is(err, schedule.ErrNotFound):
+		s.writeErrorResponse(w, r, http.StatusNotFound, "Schedule not found", nil, requestID)
+	default:
+		s.logger.Error("failed to save schedule", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to save schedule", nil, requestID)
+	}
+}
+
+func (s *Server) writeSchedule(w http.ResponseWriter, status int, sched *schedule.Schedule) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.ToScheduleResponse(sched))
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	schedulememory "github.com/jaxxstorm/landlord/internal/schedule/memory"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func newScheduleServer(t *testing.T) *Server {
+	t.Helper()
+	repo := tenantmemory.New()
+	acme := &tenant.Tenant{
+		Name:   "acme",
+		Status: tenant.StatusReady,
+		DesiredConfig: map[string]interface{}{
+			"image": "nginx:latest",
+			"hooks": map[string]interface{}{
+				"post_provision": []interface{}{
+					map[string]interface{}{"name": "smoke-test", "type": "http", "http": map[string]interface{}{"url": "http://acme.internal/health"}},
+				},
+			},
+		},
+	}
+	if err := repo.CreateTenant(context.Background(), acme); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	srv := &Server{
+		router:                 chi.NewRouter(),
+		logger:                 zap.NewNop(),
+		tenantRepo:             repo,
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+	}
+	srv.SetSchedules(schedulememory.New())
+	srv.registerRoutes()
+	return srv
+}
+
+func doScheduleRequest(srv *Server, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeSchedule(t *testing.T, rec *httptest.ResponseRecorder) models.ScheduleResponse {
+	t.Helper()
+	var resp models.ScheduleResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode schedule: %v", err)
+	}
+	return resp
+}
+
+func TestScheduleLifecycle(t *testing.T) {
+	srv := newScheduleServer(t)
+
+	rec := doScheduleRequest(srv, http.MethodPost, "/v1/tenants/acme/schedules",
+		`{"name":"evening","cron":"0 19 * * 1-5","timezone":"Europe/London","action":"suspend"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	created := decodeSchedule(t, rec)
+	if !created.Enabled || created.NextRunAt == nil || created.Action != "suspend" {
+		t.Fatalf("unexpected schedule: %+v", created)
+	}
+
+	rec = doScheduleRequest(srv, http.MethodPost, "/v1/tenants/acme/schedules",
+		`{"name":"evening","cron":"0 20 * * *","action":"restart"}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("duplicate: expected status 409, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doScheduleRequest(srv, http.MethodGet, "/v1/tenants/acme/schedules/evening", "")
+	if got := decodeSchedule(t, rec); got.ID != created.ID {
+		t.Fatalf("get by name: expected %s, got %+v", created.ID, got)
+	}
+
+	rec = doScheduleRequest(srv, http.MethodPut, "/v1/tenants/acme/schedules/"+created.ID,
+		`{"name":"evening","cron":"0 19 * * 1-5","timezone":"Europe/London","action":"suspend","enabled":false}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if updated := decodeSchedule(t, rec); updated.Enabled || updated.NextRunAt != nil {
+		t.Fatalf("expected disabled schedule without a next run, got %+v", updated)
+	}
+
+	rec = doScheduleRequest(srv, http.MethodPost, "/v1/tenants/acme/schedules",
+		`{"name":"smoke","cron":"@hourly","action":"run_hook","hook":"smoke-test"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create hook schedule: expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doScheduleRequest(srv, http.MethodGet, "/v1/tenants/acme/schedules", "")
+	var list models.ListSchedulesResponse
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("decode list: %v", err)
+	}
+	if len(list.Schedules) != 2 || list.Schedules[0].Name != "evening" || list.Schedules[1].Name != "smoke" {
+		t.Fatalf("unexpected schedules: %+v", list.Schedules)
+	}
+
+	rec = doScheduleRequest(srv, http.MethodGet, "/v1/tenants/acme/schedules/smoke/runs", "")
+	var runs models.ListScheduleRunsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&runs); err != nil {
+		t.Fatalf("decode runs: %v", err)
+	}
+	if rec.Code != http.StatusOK || runs.Runs == nil || len(runs.Runs) != 0 {
+		t.Fatalf("expected no runs, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doScheduleRequest(srv, http.MethodDelete, "/v1/tenants/acme/schedules/smoke", "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected status 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+	rec = doScheduleRequest(srv, http.MethodGet, "/v1/tenants/acme/schedules/smoke", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("get deleted: expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestCreateScheduleValidation(t *testing.T) {
+	srv := newScheduleServer(t)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "bad cron", body: `{"name":"x","cron":"0 25 * * *","action":"restart"}`, want: "cron hour"},
+		{name: "bad timezone", body: `{"name":"x","cron":"@daily","timezone":"Nowhere/City","action":"restart"}`, want: "unknown timezone"},
+		{name: "bad action", body: `{"name":"x","cron":"@daily","action":"scale"}`, want: "action must be"},
+		{name: "unknown hook", body: `{"name":"x","cron":"@daily","action":"run_hook","hook":"migrate"}`, want: `no hook named \"migrate\"`},
+		{name: "hook on power action", body: `{"name":"x","cron":"@daily","action":"restart","hook":"smoke-test"}`, want: "hook is only valid"},
+	}
+	for _, tt := range tests {
+		rec := doScheduleRequest(srv, http.MethodPost, "/v1/tenants/acme/schedules", tt.body)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), tt.want) {
+			t.Fatalf("%s: expected 400 mentioning %q, got %d: %s", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestSchedulesNotConfigured(t *testing.T) {
+	srv := newScheduleServer(t)
+	srv.schedules = nil
+
+	rec := doScheduleRequest(srv, http.MethodGet, "/v1/tenants/acme/schedules", "")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", rec.Code)
+	}
+}
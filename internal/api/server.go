@@ -13,6 +13,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -20,12 +22,32 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaxxstorm/landlord/internal/apiversion"
+	"github.com/jaxxstorm/landlord/internal/approval"
+	"github.com/jaxxstorm/landlord/internal/callbackauth"
+	"github.com/jaxxstorm/landlord/internal/capture"
 	"github.com/jaxxstorm/landlord/internal/compute"
 	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/cost"
 	"github.com/jaxxstorm/landlord/internal/database"
+	"github.com/jaxxstorm/landlord/internal/doctor"
+	"github.com/jaxxstorm/landlord/internal/endpointauth"
+	"github.com/jaxxstorm/landlord/internal/execution"
+	"github.com/jaxxstorm/landlord/internal/featureflag"
+	"github.com/jaxxstorm/landlord/internal/imagepolicy"
+	"github.com/jaxxstorm/landlord/internal/imageupdate"
 	"github.com/jaxxstorm/landlord/internal/logger"
+	"github.com/jaxxstorm/landlord/internal/operation"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/resolution"
+	"github.com/jaxxstorm/landlord/internal/schedule"
 	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantcache "github.com/jaxxstorm/landlord/internal/tenant/cache"
+	"github.com/jaxxstorm/landlord/internal/ui"
+	"github.com/jaxxstorm/landlord/internal/uptime"
+	"github.com/jaxxstorm/landlord/internal/version"
+	"github.com/jaxxstorm/landlord/internal/warmpool"
 	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/providers/webhook"
 )
 
 // Server represents the HTTP API server
@@ -35,9 +57,42 @@ type Server struct {
 	provider        database.Provider
 	computeRegistry *compute.Registry
 	defaultComputeProvider string
+	computeResolution *resolution.Resolver
+	costs           *cost.Estimator
 	tenantRepo      tenant.Repository
 	controller      ControllerHealthChecker
 	workflowClient  WorkflowClient
+	providerAdmin   ProviderAdmin
+	doctor          *doctor.Doctor
+	projects        project.Store
+	imagePolicy     *imagepolicy.Policy
+	imageRegistries map[string]imageupdate.Registry
+	approvals       approval.Store
+	approvalPolicy  *approval.Policy
+	emergency       config.EmergencyConfig
+	schedules       schedule.Store
+	operations      operation.Store
+	executions      execution.Store
+	computeExecutions compute.ExecutionRepository
+	historyScrubber   tenant.HistoryScrubber
+	featureFlags      *featureflag.Registry
+	workflowCallbacks *webhook.Provider
+	callbackAuth      *callbackauth.Verifier
+	logLevels         *logger.Levels
+	capture           *capture.Recorder
+	bulkOperations  sync.WaitGroup
+	shuttingDown    atomic.Bool
+	warmPools       *warmpool.Controller
+	uptime          *uptime.Checker
+	tenantCache     *tenantcache.Cache
+	listCache       *listCache
+	listCacheControl string
+	versions        *version.Tracker
+	endpointAuth    *endpointauth.Generator
+	requestTimeout  time.Duration
+	routeTimeouts   map[string]time.Duration
+	apiKeys         []apiKey
+	errorFormat     string
 	logger          *zap.Logger
 }
 
@@ -67,7 +122,9 @@ func New(cfg *config.HTTPConfig, dbProvider database.Provider, computeRegistry *
 	r.Use(logger.HTTPMiddleware(log))
 	r.Use(logger.CorrelationIDMiddleware)
 	r.Use(middleware.Recoverer)
-	r.Use(middleware.Timeout(60 * time.Second))
+	if cfg.CompressionLevel > 0 {
+		r.Use(compressResponses(cfg.CompressionLevel))
+	}
 
 	srv := &Server{
 		router:          r,
@@ -77,6 +134,10 @@ func New(cfg *config.HTTPConfig, dbProvider database.Provider, computeRegistry *
 		tenantRepo:      tenantRepo,
 		controller:      nil, // Set later with SetController()
 		workflowClient:  workflowClient,
+		requestTimeout:  cfg.RequestTimeout,
+		routeTimeouts:   cfg.RouteTimeouts,
+		errorFormat:     cfg.ErrorFormat,
+		versions:        version.NewTracker(version.Current(version.ComponentServer), log),
 		logger:          log,
 		server: &http.Server{
 			Addr:         cfg.Address(),
@@ -87,6 +148,10 @@ func New(cfg *config.HTTPConfig, dbProvider database.Provider, computeRegistry *
 		},
 	}
 
+	// Bound each request by its route's timeout; routes must be registered before the lookup runs
+	r.Use(srv.timeoutRequests)
+	r.Use(srv.trackVersions)
+
 	// Register routes
 	srv.registerRoutes()
 
@@ -98,25 +163,143 @@ func (s *Server) SetController(controller ControllerHealthChecker) {
 	s.controller = controller
 }
 
+// SetWarmPools lets create requests that name a template claim a tenant from the template's warm pool
+func (s *Server) SetWarmPools(controller *warmpool.Controller) {
+	s.warmPools = controller
+}
+
+// SetUptime serves tenants' uptime checks from /v1/tenants/{id}/uptime and /metrics
+func (s *Server) SetUptime(checker *uptime.Checker) {
+	s.uptime = checker
+}
+
+// SetTenantCache serves the read-only tenant endpoints from cache and its counters from /metrics.
+// The cache replaces the server's tenant repository, so tenant writes made through the API drop
+// the tenants they change; requests that change a tenant still read it from the database.
+func (s *Server) SetTenantCache(cache *tenantcache.Cache) {
+	s.tenantCache = cache
+	s.tenantRepo = cache
+}
+
+// Handler returns the server's HTTP handler, for serving the API without Start
+func (s *Server) Handler() http.Handler {
+	return s.router
+}
+
 // registerRoutes registers all HTTP routes
 func (s *Server) registerRoutes() {
 	s.router.Get("/health", s.handleHealth)
 	s.router.Get("/ready", s.handleReady)
+	s.router.With(s.authenticate).Get("/metrics", s.handleMetrics)
+
+	// The dashboard is static; it calls the API below with the operator's API key
+	s.router.Get("/ui", http.RedirectHandler("/ui/", http.StatusMovedPermanently).ServeHTTP)
+	s.router.Handle("/ui/*", http.StripPrefix("/ui", ui.Handler()))
 
 	s.router.Route("/"+apiversion.Current, func(r chi.Router) {
 		r.Get("/swagger.json", s.handleSwaggerSpec)
 		r.Get("/docs", s.handleDocsUI)
 
-		// Compute config routes
-		r.Get("/compute/config", s.handleComputeConfigDiscovery)
+		// Callbacks from external orchestrators are authenticated by their signature instead of an API key
+		r.Post("/workflow-callbacks", s.handleWorkflowCallback)
 
-		// Tenant routes
-		r.Post("/tenants", s.handleCreateTenant)
-		r.Get("/tenants", s.handleListTenants)
-		r.Get("/tenants/{id}", s.handleGetTenant)
-		r.Put("/tenants/{id}", s.handleUpdateTenant)
-		r.Post("/tenants/{id}/archive", s.handleArchiveTenant)
-		r.Delete("/tenants/{id}", s.handleDeleteTenant)
+		// Everything except the API documentation requires an API key when keys are configured
+		r.Group(func(r chi.Router) {
+			r.Use(s.authenticate)
+			r.Use(s.trackListWriters)
+			r.Use(s.captureRequests)
+
+			// Compute config routes
+			r.Get("/compute/config", s.handleComputeConfigDiscovery)
+
+			// Meta routes
+			r.Get("/meta/workflows", s.handleListWorkflows)
+			r.Get("/meta/schemas", s.handleListSchemas)
+			r.Get("/meta/schemas/{name}/{version}", s.handleGetSchema)
+			r.Get("/meta/version", s.handleGetVersion)
+			r.Get("/meta/feature-flags", s.handleListFeatureFlags)
+			r.Get("/meta/reason-codes", s.handleListReasonCodes)
+
+			// Tenant routes
+			r.Post("/tenants", s.handleCreateTenant)
+			r.Post("/tenants:validate", s.handleValidateTenant)
+			r.Post("/tenants:bulk-action", s.handleBulkAction)
+			r.Post("/simulate", s.handleSimulate)
+			r.With(s.cacheTenantLists).Get("/tenants", s.handleListTenants)
+			r.Get("/tenants/{id}", s.handleGetTenant)
+			r.Get("/tenants/{id}/status", s.handleGetTenantStatus)
+			r.Get("/tenants/{id}/history", s.handleGetTenantHistory)
+			r.Get("/tenants/{id}/timeline", s.handleGetTenantTimeline)
+			r.Get("/tenants/{id}/resolution", s.handleGetTenantResolution)
+			r.Get("/tenants/{id}/effective-config", s.handleGetTenantEffectiveConfig)
+			r.Get("/tenants/{id}/uptime", s.handleGetTenantUptime)
+			r.Get("/tenants/{id}/credentials", s.handleGetTenantCredentials)
+			r.Post("/tenants/{id}/credentials/rotate", s.handleRotateTenantCredentials)
+			r.Put("/tenants/{id}", s.handleUpdateTenant)
+			r.Patch("/tenants/{id}", s.handlePatchTenant)
+			r.Post("/tenants/{id}/archive", s.handleArchiveTenant)
+			r.Post("/tenants/{id}/retry", s.handleRetryTenant)
+			r.Post("/tenants/{id}/suspend", s.handleSuspendTenant)
+			r.Post("/tenants/{id}/resume", s.handleResumeTenant)
+			r.Post("/tenants/{id}/restart", s.handleRestartTenant)
+			r.Post("/tenants/{id}/migrate", s.handleMigrateTenant)
+			r.Post("/tenants/{id}/promote", s.handlePromoteTenant)
+			r.Post("/tenants/{id}/promotion/approve", s.handleApprovePromotion)
+			r.Post("/tenants/{id}/promotion/reject", s.handleRejectPromotion)
+			r.Delete("/tenants/{id}", s.handleDeleteTenant)
+			r.Put("/tenants/{id}/finalizers/{name}", s.handleAddFinalizer)
+			r.Delete("/tenants/{id}/finalizers/{name}", s.handleRemoveFinalizer)
+			r.Put("/tenants/{id}/owner", s.handleTransferTenantOwnership)
+			r.Put("/tenants/{id}/collaborators/{principal}", s.handleShareTenant)
+			r.Delete("/tenants/{id}/collaborators/{principal}", s.handleUnshareTenant)
+
+			// Schedule routes
+			r.Post("/tenants/{id}/schedules", s.handleCreateSchedule)
+			r.Get("/tenants/{id}/schedules", s.handleListSchedules)
+			r.Get("/tenants/{id}/schedules/{schedule}", s.handleGetSchedule)
+			r.Put("/tenants/{id}/schedules/{schedule}", s.handleUpdateSchedule)
+			r.Delete("/tenants/{id}/schedules/{schedule}", s.handleDeleteSchedule)
+			r.Get("/tenants/{id}/schedules/{schedule}/runs", s.handleListScheduleRuns)
+
+			// Approval routes
+			r.Get("/approvals", s.handleListApprovals)
+			r.Get("/approvals/{id}", s.handleGetApproval)
+			r.Post("/approvals/{id}/approve", s.handleApproveApproval)
+			r.Post("/approvals/{id}/reject", s.handleRejectApproval)
+
+			// Bulk operation routes
+			r.Get("/operations/{id}", s.handleGetOperation)
+
+			// Workflow execution routes
+			r.Get("/executions/{id}", s.handleGetExecution)
+			r.Post("/executions/{id}/steps", s.handleRecordExecutionStep)
+
+			// Admin routes
+			r.Get("/admin/providers", s.handleListProviders)
+			r.Get("/admin/providers/{kind}/{name}", s.handleGetProvider)
+			r.Post("/admin/providers/{kind}/{name}/enable", s.handleEnableProvider)
+			r.Post("/admin/providers/{kind}/{name}/disable", s.handleDisableProvider)
+			r.Put("/admin/providers/{kind}/{name}/config", s.handleReconfigureProvider)
+			r.Get("/admin/providers/{kind}/{name}/audit", s.handleListProviderAudit)
+			r.Post("/admin/tenants/{id}/emergency", s.handleEmergencyTenant)
+			r.Post("/admin/tenants/{id}/forget", s.handleForgetTenant)
+			r.Get("/admin/doctor", s.handleDoctor)
+			r.Get("/admin/loglevel", s.handleGetLogLevels)
+			r.Put("/admin/loglevel", s.handleSetLogLevel)
+			r.Delete("/admin/loglevel/{component}", s.handleResetLogLevel)
+			r.Get("/admin/debug-flags", s.handleGetDebugFlags)
+			r.Put("/admin/debug-flags", s.handleSetDebugFlag)
+			r.Delete("/admin/debug-flags/{flag}", s.handleResetDebugFlag)
+
+			// Organization and project routes
+			r.Post("/organizations", s.handleCreateOrganization)
+			r.Get("/organizations", s.handleListOrganizations)
+			r.Get("/organizations/{org}", s.handleGetOrganization)
+			r.Post("/organizations/{org}/projects", s.handleCreateProject)
+			r.Get("/organizations/{org}/projects", s.handleListProjects)
+			r.Get("/organizations/{org}/projects/{project}", s.handleGetProject)
+			r.Put("/organizations/{org}/projects/{project}", s.handleUpdateProject)
+		})
 	})
 
 	s.router.Route("/api", func(r chi.Router) {
@@ -276,5 +459,18 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("server shutdown failed: %w", err)
 	}
 	s.logger.Info("HTTP server shut down successfully")
+
+	// Bulk operations skip the tenants they have not reached yet, so they finish quickly
+	s.shuttingDown.Store(true)
+	done := make(chan struct{})
+	go func() {
+		s.bulkOperations.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.logger.Warn("bulk operations still running at shutdown")
+	}
 	return nil
 }
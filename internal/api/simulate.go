@@ -0,0 +1,168 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/cost"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/resolution"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// maxSimulatedTenants bounds a simulation, since every tenant is validated against its provider
+const maxSimulatedTenants = 1000
+
+// SetCostEstimator prices tenants in simulations; without one, simulations report no cost
+func (s *Server) SetCostEstimator(estimator *cost.Estimator) {
+	s.costs = estimator
+}
+
+// handleSimulate plans a set of hypothetical tenants without creating anything
+// @Summary Simulate creating tenants
+// @Description Runs the create-time checks of /v1/tenants:validate on each tenant in order, then reports where each would be placed, the project quota the set consumes and its estimated cost.
+// @Description Quota is consumed by earlier tenants of the request, so the tenants that would not fit are reported with a quota violation. Nothing is created.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param body body models.SimulateRequest true "Tenants to simulate"
+// @Success 200 {object} models.SimulateResponse "Simulation result; feasible is false when any tenant could not be created"
+// @Failure 400 {object} models.ErrorResponse "Request body is not valid JSON, or names no tenants or too many"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/simulate [post]
+func (s *Server) handleSimulate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	var req models.SimulateRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return
+	}
+	if len(req.Tenants) == 0 || len(req.Tenants) > maxSimulatedTenants {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, fmt.Sprintf("tenants must list between 1 and %d tenants", maxSimulatedTenants), nil, requestID)
+		return
+	}
+
+	resp := models.SimulateResponse{
+		Feasible:   true,
+		Tenants:    make([]models.SimulatedTenant, 0, len(req.Tenants)),
+		Quotas:     []models.SimulatedQuota{},
+		Placements: map[string]int{},
+	}
+	if s.costs != nil {
+		resp.Cost = &cost.Estimate{Currency: s.costs.Currency()}
+	}
+	quotas := make(map[string]*models.SimulatedQuota)
+	names := make(map[string]bool, len(req.Tenants))
+
+	for i := range req.Tenants {
+		spec := &req.Tenants[i]
+		violations, err := s.validateTenantSpec(r, spec)
+		if err != nil {
+			s.logger.Error("failed to simulate tenant", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to simulate tenants", nil, requestID)
+			return
+		}
+		// Quota is accounted across the whole request below
+		kept := violations[:0]
+		for _, v := range violations {
+			if v.Field != "project" || v.Check != "quota" {
+				kept = append(kept, v)
+			}
+		}
+		violations = kept
+		if names[spec.Name] {
+			violations = append(violations, models.Violation{Field: "name", Check: "naming", Message: fmt.Sprintf("tenant name %q appears earlier in the request", spec.Name)})
+		}
+		names[spec.Name] = true
+
+		simulated := models.SimulatedTenant{Name: spec.Name}
+		explanation := s.computeResolution.Explain(resolution.Subject{
+			Name:        spec.Name,
+			Config:      spec.ComputeConfig,
+			Labels:      spec.Labels,
+			Annotations: spec.Annotations,
+		}, s.defaultComputeProvider)
+		simulated.ComputeProvider = explanation.Provider
+		simulated.Placement = string(explanation.Source)
+		simulated.Rule = explanation.Rule
+
+		if components, err := tenant.ParseComponents(spec.ComputeConfig); err == nil && spec.ComputeConfig != nil {
+			for _, component := range components {
+				simulated.Components = append(simulated.Components, component.Name)
+			}
+			simulated.Cost, _ = s.costs.Estimate(explanation.Provider, components)
+		}
+
+		p, err := s.resolveTenantProject(ctx, spec)
+		if err == nil {
+			key := p.Organization + "/" + p.Name
+			simulated.Project = key
+			quota, ok := quotas[key]
+			if !ok {
+				existing, err := s.countProjectTenants(ctx, p)
+				if err != nil {
+					s.logger.Error("failed to simulate tenant", zap.Error(err), zap.String("request_id", requestID))
+					s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to simulate tenants", nil, requestID)
+					return
+				}
+				quota = &models.SimulatedQuota{Project: key, MaxTenants: p.Settings.Quota.MaxTenants, Existing: existing}
+				quotas[key] = quota
+			}
+			if len(violations) == 0 {
+				if quota.MaxTenants > 0 && quota.Existing+quota.Requested >= quota.MaxTenants {
+					violations = append(violations, models.Violation{Field: "project", Check: "quota",
+						Message: fmt.Sprintf("%s: project %s allows %d tenants", project.ErrQuotaExceeded, key, quota.MaxTenants)})
+				} else {
+					quota.Requested++
+				}
+			}
+		}
+
+		if violations == nil {
+			violations = []models.Violation{}
+		}
+		simulated.Violations = violations
+		if len(violations) == 0 {
+			resp.Placements[simulated.ComputeProvider]++
+			if resp.Cost != nil && simulated.Cost != nil {
+				resp.Cost.Add(simulated.Cost)
+				for _, component := range simulated.Cost.Unsized {
+					resp.Cost.Unsized = append(resp.Cost.Unsized, simulated.Name+"."+component)
+				}
+			}
+		} else {
+			resp.Feasible = false
+		}
+		resp.Tenants = append(resp.Tenants, simulated)
+	}
+
+	for _, quota := range quotas {
+		if quota.MaxTenants > 0 {
+			remaining := quota.MaxTenants - quota.Existing - quota.Requested
+			if remaining < 0 {
+				remaining = 0
+			}
+			quota.Remaining = &remaining
+		}
+		resp.Quotas = append(resp.Quotas, *quota)
+	}
+	sort.Slice(resp.Quotas, func(i, j int) bool { return resp.Quotas[i].Project < resp.Quotas[j].Project })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/cost"
+	"github.com/jaxxstorm/landlord/internal/project"
+	projectmemory "github.com/jaxxstorm/landlord/internal/project/memory"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestSimulate(t *testing.T) {
+	ctx := context.Background()
+	store := projectmemory.New()
+	org := &project.Organization{Name: "acme"}
+	if err := store.CreateOrganization(ctx, org); err != nil {
+		t.Fatalf("create organization: %v", err)
+	}
+	web := &project.Project{OrganizationID: org.ID, Name: "web", Settings: project.Settings{Quota: project.Quota{MaxTenants: 3}}}
+	if err := store.CreateProject(ctx, web); err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	repo := tenantmemory.New()
+	if err := repo.CreateTenant(ctx, &tenant.Tenant{Name: "existing", ProjectID: web.ID, Status: tenant.StatusReady}); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	srv := &Server{
+		router:                 chi.NewRouter(),
+		logger:                 zap.NewNop(),
+		tenantRepo:             repo,
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+	}
+	srv.SetProjects(store)
+	srv.SetCostEstimator(cost.New(config.CostConfig{Rates: map[string]config.CostRateConfig{
+		"mock": {ComponentHour: 0.01, CPUHour: 0.04},
+	}}))
+	srv.registerRoutes()
+
+	simulate := func(body string) models.SimulateResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/v1/simulate", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp models.SimulateResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return resp
+	}
+
+	spec := `"compute_config":{"image":"nginx:latest","limits":{"cpu":500},"components":{"app":{},"worker":{"limits":null}}}`
+	resp := simulate(`{"tenants":[
+		{"name":"one","organization":"acme","project":"web",` + spec + `},
+		{"name":"one","organization":"acme","project":"web",` + spec + `},
+		{"name":"two","organization":"acme","project":"web",` + spec + `},
+		{"name":"three","organization":"acme","project":"web",` + spec + `}
+	]}`)
+
+	if resp.Feasible {
+		t.Fatal("expected the wave not to be feasible")
+	}
+	wantChecks := []string{"", "naming", "", "quota"}
+	for i, want := range wantChecks {
+		got := resp.Tenants[i]
+		if want == "" {
+			if len(got.Violations) != 0 {
+				t.Errorf("tenant %d: expected no violations, got %+v", i, got.Violations)
+			}
+			continue
+		}
+		if len(got.Violations) != 1 || got.Violations[0].Check != want {
+			t.Errorf("tenant %d: expected a %s violation, got %+v", i, want, got.Violations)
+		}
+	}
+	if first := resp.Tenants[0]; first.ComputeProvider != "mock" || first.Placement != "default" || first.Project != "acme/web" || len(first.Components) != 2 {
+		t.Errorf("unexpected simulated tenant %+v", first)
+	}
+	if resp.Placements["mock"] != 2 {
+		t.Errorf("expected two tenants placed on mock, got %v", resp.Placements)
+	}
+
+	if len(resp.Quotas) != 1 {
+		t.Fatalf("expected one project quota, got %+v", resp.Quotas)
+	}
+	quota := resp.Quotas[0]
+	if quota.Existing != 1 || quota.Requested != 2 || quota.Remaining == nil || *quota.Remaining != 0 {
+		t.Errorf("unexpected quota %+v", quota)
+	}
+
+	// Each tenant is an app at 0.01 + 0.5 CPU at 0.04, and an unsized worker at 0.01
+	if resp.Cost == nil || resp.Cost.Hourly != 0.08 {
+		t.Fatalf("expected an hourly cost of 0.08, got %+v", resp.Cost)
+	}
+	if strings.Join(resp.Cost.Unsized, ",") != "one.worker,two.worker" {
+		t.Errorf("expected the workers reported unsized, got %v", resp.Cost.Unsized)
+	}
+
+	if resp := simulate(`{"tenants":[{"name":"four","organization":"acme","project":"web","compute_config":{"image":"nginx:latest"}}]}`); !resp.Feasible {
+		t.Errorf("expected a tenant that fits to be feasible, got %+v", resp.Tenants)
+	}
+	if tenants, err := repo.ListTenants(ctx, tenant.ListFilters{}); err != nil || len(tenants) != 1 {
+		t.Fatalf("expected the simulation to create nothing, got %d tenants (%v)", len(tenants), err)
+	}
+}
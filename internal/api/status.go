@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/apiversion"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+const (
+	// maxStatusWait caps how long a status request may wait for a change
+	maxStatusWait = 60 * time.Second
+
+	// statusPollInterval is how often a waiting status request reads the tenant again
+	statusPollInterval = 250 * time.Millisecond
+
+	// statusResponseMargin is kept back from the request's deadline to write the response
+	statusResponseMargin = 500 * time.Millisecond
+)
+
+// tenantStatusRoute is the route pattern of the long-polling status endpoint
+var tenantStatusRoute = "/" + apiversion.Current + "/tenants/{id}/status"
+
+// handleGetTenantStatus returns a tenant's status, waiting for it to change
+// @Summary Get tenant status
+// @Description Returns the tenant's status with its version as the ETag. With If-None-Match (or the version parameter) naming the current version, the request waits up to the wait duration for the tenant to change and answers 304 Not Modified if it does not.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Param wait query string false "How long to wait for a change, as a duration (for example 30s); at most 60s"
+// @Param version query int false "Tenant version the caller already has; an alternative to If-None-Match"
+// @Param If-None-Match header string false "ETag the caller already has"
+// @Success 200 {object} models.TenantStatusResponse "Tenant status"
+// @Success 304 "Tenant unchanged"
+// @Failure 400 {object} models.ErrorResponse "Invalid tenant identifier, wait or version"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/status [get]
+func (s *Server) handleGetTenantStatus(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	wait, err := statusWait(r)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid wait", []string{err.Error()}, requestID)
+		return
+	}
+	known, err := knownStatusETag(r)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid version", []string{err.Error()}, requestID)
+		return
+	}
+
+	t, ok := s.readTenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	// Leave room to answer before the request times out
+	deadline := time.Now().Add(wait)
+	if requestDeadline, ok := ctx.Deadline(); ok && requestDeadline.Add(-statusResponseMargin).Before(deadline) {
+		deadline = requestDeadline.Add(-statusResponseMargin)
+	}
+
+	ticker := time.NewTicker(statusPollInterval)
+	defer ticker.Stop()
+	for known != "" && statusETag(t) == known && time.Now().Before(deadline) {
+		if !sleepUntilTick(ctx, ticker, deadline) {
+			break
+		}
+		current, err := s.readTenant(ctx, t.ID.String())
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			if errors.Is(err, tenant.ErrTenantNotFound) {
+				s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant not found", nil, requestID)
+				return
+			}
+			s.logger.Error("failed to get tenant status", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+			return
+		}
+		t = current
+	}
+
+	etag := statusETag(t)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if etag == known {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(models.ToTenantStatusResponse(t))
+}
+
+// sleepUntilTick waits for the next tick, reporting false if the request ends or deadline passes first
+func sleepUntilTick(ctx context.Context, ticker *time.Ticker, deadline time.Time) bool {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return false
+	case <-ticker.C:
+		return true
+	}
+}
+
+// statusETag identifies a tenant version; every status change writes a new version
+func statusETag(t *tenant.Tenant) string {
+	return strconv.Quote(strconv.Itoa(t.Version))
+}
+
+// statusWait returns the wait query parameter, capped at maxStatusWait
+func statusWait(r *http.Request) (time.Duration, error) {
+	raw := r.URL.Query().Get("wait")
+	if raw == "" {
+		return 0, nil
+	}
+	wait, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("wait must be a duration such as 30s: %w", err)
+	}
+	if wait < 0 {
+		return 0, fmt.Errorf("wait must be non-negative")
+	}
+	return min(wait, maxStatusWait), nil
+}
+
+// knownStatusETag returns the ETag the caller already has, from If-None-Match or the version parameter
+func knownStatusETag(r *http.Request) (string, error) {
+	if raw := r.URL.Query().Get("version"); raw != "" {
+		version, err := strconv.Atoi(raw)
+		if err != nil {
+			return "", fmt.Errorf("version must be an integer: %w", err)
+		}
+		return strconv.Quote(strconv.Itoa(version)), nil
+	}
+	// A weak validator names the same version as far as status is concerned
+	return strings.TrimPrefix(strings.TrimSpace(r.Header.Get("If-None-Match")), "W/"), nil
+}
+
+// longPollWait returns how long r may wait beyond its route's timeout: the wait of a status request
+func (s *Server) longPollWait(r *http.Request) time.Duration {
+	if r.Method != http.MethodGet || !r.URL.Query().Has("wait") || s.routePattern(r) != tenantStatusRoute {
+		return 0
+	}
+	wait, err := statusWait(r)
+	if err != nil {
+		return 0
+	}
+	return wait
+}
+
+// routePattern returns the pattern of the route r matches, or "" if none does
+func (s *Server) routePattern(r *http.Request) string {
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	return s.router.Find(chi.NewRouteContext(), r.Method, path)
+}
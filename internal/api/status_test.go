@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func newStatusTestServer(t *testing.T, requestTimeout time.Duration) (*Server, *tenantmemory.Repository, *tenant.Tenant) {
+	t.Helper()
+	repo := tenantmemory.New()
+	web := &tenant.Tenant{ID: uuid.New(), Name: "web", Status: tenant.StatusProvisioning}
+	if err := repo.CreateTenant(context.Background(), web); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo, requestTimeout: requestTimeout}
+	srv.router.Use(srv.timeoutRequests)
+	srv.registerRoutes()
+	return srv, repo, web
+}
+
+func getTenantStatus(srv *Server, target, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGetTenantStatusETag(t *testing.T) {
+	srv, _, web := newStatusTestServer(t, 0)
+
+	rec := getTenantStatus(srv, "/v1/tenants/web/status", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp models.TenantStatusResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.ID != web.ID.String() || resp.Status != string(tenant.StatusProvisioning) {
+		t.Fatalf("unexpected status response: %+v", resp)
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	// Without a wait an unchanged tenant answers straight away
+	rec = getTenantStatus(srv, "/v1/tenants/web/status", etag)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("expected status 304, got %d", rec.Code)
+	}
+	if rec.Header().Get("ETag") != etag {
+		t.Fatalf("expected ETag %s on 304, got %s", etag, rec.Header().Get("ETag"))
+	}
+
+	// An older version is answered with the current status
+	rec = getTenantStatus(srv, "/v1/tenants/web/status?version=0", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 for an old version, got %d", rec.Code)
+	}
+
+	for _, target := range []string{"/v1/tenants/web/status?wait=soon", "/v1/tenants/web/status?wait=-1s", "/v1/tenants/web/status?version=latest"} {
+		if rec := getTenantStatus(srv, target, ""); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", target, rec.Code)
+		}
+	}
+	if rec := getTenantStatus(srv, "/v1/tenants/missing/status", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestGetTenantStatusLongPoll(t *testing.T) {
+	// The wait outlasts the request timeout, which long polls extend
+	srv, repo, web := newStatusTestServer(t, time.Second)
+	etag := getTenantStatus(srv, "/v1/tenants/web/status", "").Header().Get("ETag")
+
+	start := time.Now()
+	rec := getTenantStatus(srv, "/v1/tenants/web/status?wait=1500ms", etag)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("expected status 304 after the wait, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed < 1400*time.Millisecond {
+		t.Fatalf("expected the request to wait, it returned after %s", elapsed)
+	}
+
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		stored, err := repo.GetTenantByID(context.Background(), web.ID)
+		if err != nil {
+			return
+		}
+		stored.Status = tenant.StatusReady
+		_ = repo.UpdateTenant(context.Background(), stored)
+	}()
+	rec = getTenantStatus(srv, "/v1/tenants/web/status?wait=5s", etag)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200 once the tenant changed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp models.TenantStatusResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Status != string(tenant.StatusReady) {
+		t.Fatalf("expected ready, got %s", resp.Status)
+	}
+	if rec.Header().Get("ETag") == etag {
+		t.Fatal("expected a new ETag for the changed tenant")
+	}
+}
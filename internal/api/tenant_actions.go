@@ -0,0 +1,309 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/schedule"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// handleRetryTenant re-runs the provisioning or update a failed tenant stopped in
+// @Summary Retry a failed tenant
+// @Description Moves a failed tenant back to provisioning, or to updating when it was provisioned before, so the reconciler starts a new workflow for its desired configuration.
+// @Description Failed migrations resume through /v1/tenants/{id}/migrate; failed deletions and archivals are requested again.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Success 202 {object} models.TenantResponse "Tenant retry initiated"
+// @Failure 400 {object} models.ErrorResponse "Invalid tenant identifier format"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 409 {object} models.ErrorResponse "Tenant is not failed, or failed during a migration"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/retry [post]
+func (s *Server) handleRetryTenant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if t.Status != tenant.StatusFailed {
+		s.writeInvalidStateError(w, r, "Only failed tenants can be retried", []string{fmt.Sprintf("tenant is %s", t.Status)}, requestID)
+		return
+	}
+	if migration := t.Migration(); migration != nil {
+		s.writeInvalidStateError(w, r, "Tenant failed during a migration",
+			[]string{fmt.Sprintf("resume the migration to %s with POST /v1/tenants/{id}/migrate", migration.Target)}, requestID)
+		return
+	}
+
+	// A tenant that never reported compute state is provisioned from scratch
+	next := tenant.StatusUpdating
+	if len(t.ObservedConfig) == 0 && len(t.ObservedResourceIDs) == 0 {
+		next = tenant.StatusProvisioning
+	}
+	if err := tenant.ValidateTransition(t.Status, next); err != nil {
+		s.writeInvalidStateError(w, r, "Invalid state transition", []string{err.Error()}, requestID)
+		return
+	}
+
+	message := fmt.Sprintf("Retry requested after failure: %s", t.StatusMessage)
+	transition := tenant.NewStateTransition(t, next, message, fieldManager(r)).WithReasonCode(tenant.ReasonRetryRequested)
+
+	t.Status = next
+	t.StatusMessage = "Retry requested"
+	t.WorkflowExecutionID = nil
+	t.WorkflowStartedAt = nil
+	t.WorkflowSubState = nil
+	t.WorkflowRetryCount = nil
+	t.WorkflowErrorMessage = nil
+	t.UpdatedAt = time.Now()
+	if err := s.tenantRepo.UpdateTenant(ctx, t); err != nil {
+		if errors.Is(err, tenant.ErrVersionConflict) {
+			s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Tenant was modified concurrently, retry the request", nil, requestID)
+			return
+		}
+		s.logger.Error("failed to retry tenant", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retry tenant", nil, requestID)
+		return
+	}
+	s.recordTransition(ctx, transition, requestID)
+
+	s.logger.Info("tenant retry requested, awaiting reconciliation",
+		zap.String("tenant_name", t.Name),
+		zap.String("status", string(t.Status)),
+		zap.String("request_id", requestID))
+
+	resp := models.ToTenantResponse(t)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleSuspendTenant stops a ready tenant's workloads without deprovisioning them
+// @Summary Suspend a tenant
+// @Description Stops the tenant's workloads through its compute provider and sets its Suspended condition. The tenant stays ready and keeps its resources.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Success 200 {object} models.TenantResponse "Tenant suspended"
+// @Failure 400 {object} models.ErrorResponse "Compute provider cannot suspend tenants"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 409 {object} models.ErrorResponse "Tenant is not ready"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/suspend [post]
+func (s *Server) handleSuspendTenant(w http.ResponseWriter, r *http.Request) {
+	s.setTenantPower(w, r, schedule.ActionSuspend)
+}
+
+// handleResumeTenant restarts a suspended tenant's workloads
+// @Summary Resume a tenant
+// @Description Starts the tenant's workloads through its compute provider and clears its Suspended condition.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Success 200 {object} models.TenantResponse "Tenant resumed"
+// @Failure 400 {object} models.ErrorResponse "Compute provider cannot resume tenants"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 409 {object} models.ErrorResponse "Tenant is not ready"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/resume [post]
+func (s *Server) handleResumeTenant(w http.ResponseWriter, r *http.Request) {
+	s.setTenantPower(w, r, schedule.ActionResume)
+}
+
+// handleRestartTenant restarts a ready tenant's workloads in place
+// @Summary Restart a tenant
+// @Description Restarts the tenant's workloads through its compute provider, without changing its desired configuration or running a workflow.
+// @Description The restart can be limited to one of the tenant's components. It is recorded in the tenant's state history.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Param body body models.RestartTenantRequest false "Component to restart"
+// @Success 200 {object} models.TenantResponse "Tenant restarted"
+// @Failure 400 {object} models.ErrorResponse "Invalid request, unknown component, or compute provider cannot restart tenants"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 409 {object} models.ErrorResponse "Tenant is not ready or is suspended"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/restart [post]
+func (s *Server) handleRestartTenant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	var req models.RestartTenantRequest
+	if len(strings.TrimSpace(string(body))) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+			return
+		}
+	}
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if t.Status != tenant.StatusReady {
+		s.writeInvalidStateError(w, r, "Tenant must be ready to restart", []string{fmt.Sprintf("tenant is %s", t.Status)}, requestID)
+		return
+	}
+	if suspended := t.Condition(schedule.ConditionSuspended); suspended != nil && suspended.Status == tenant.ConditionTrue {
+		s.writeInvalidStateError(w, r, "Tenant is suspended", []string{"resume it with POST /v1/tenants/{id}/resume"}, requestID)
+		return
+	}
+
+	components, err := tenant.ComponentNames(t.DesiredConfig)
+	if err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid components configuration", []string{err.Error()}, requestID)
+		return
+	}
+	if req.Component != "" {
+		if !slices.Contains(components, req.Component) {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Unknown component",
+				[]string{fmt.Sprintf("tenant %s has components %s", t.Name, strings.Join(components, ", "))}, requestID)
+			return
+		}
+		components = []string{req.Component}
+	}
+
+	provider, providerName, err := s.resolveComputeProvider(t.Name, t.DesiredConfig, t.Labels, t.Annotations, nil)
+	if err != nil {
+		s.writeComputeProviderError(w, r, err, requestID)
+		return
+	}
+	power, ok := provider.(compute.PowerManager)
+	if !ok {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Compute provider does not support the action",
+			[]string{fmt.Sprintf("compute provider %s cannot restart tenants", providerName)}, requestID)
+		return
+	}
+
+	for _, component := range components {
+		if err := power.Restart(ctx, tenant.ComponentComputeID(t.ComputeName(), component)); err != nil {
+			s.logger.Error("failed to restart tenant", zap.String("component", component), zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to restart tenant", []string{fmt.Sprintf("component %s: %v", component, err)}, requestID)
+			return
+		}
+	}
+
+	manager := fieldManager(r)
+	message := fmt.Sprintf("Restarted by %s", manager)
+	if req.Component != "" {
+		message = fmt.Sprintf("Component %s restarted by %s", req.Component, manager)
+	}
+	s.recordTransition(ctx, tenant.NewStateTransition(t, t.Status, message, manager).WithReasonCode(tenant.ReasonRestarted), requestID)
+
+	s.logger.Info("tenant restarted",
+		zap.String("tenant_name", t.Name),
+		zap.Strings("components", components),
+		zap.String("request_id", requestID))
+
+	resp := models.ToTenantResponse(t)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// setTenantPower suspends or resumes a tenant now, recording the same Suspended condition schedules do
+func (s *Server) setTenantPower(w http.ResponseWriter, r *http.Request, action schedule.Action) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	t, ok := s.tenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if t.Status != tenant.StatusReady {
+		s.writeInvalidStateError(w, r, fmt.Sprintf("Tenant must be ready to %s", action), []string{fmt.Sprintf("tenant is %s", t.Status)}, requestID)
+		return
+	}
+
+	provider, providerName, err := s.resolveComputeProvider(t.Name, t.DesiredConfig, t.Labels, t.Annotations, nil)
+	if err != nil {
+		s.writeComputeProviderError(w, r, err, requestID)
+		return
+	}
+	power, ok := provider.(compute.PowerManager)
+	if !ok {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Compute provider does not support the action",
+			[]string{fmt.Sprintf("compute provider %s cannot %s tenants", providerName, action)}, requestID)
+		return
+	}
+
+	manager := fieldManager(r)
+	condition := tenant.Condition{
+		Type:    schedule.ConditionSuspended,
+		Status:  tenant.ConditionTrue,
+		Reason:  tenant.ReasonManualSuspend,
+		Message: fmt.Sprintf("Suspended by %s", manager),
+	}
+	if action == schedule.ActionSuspend {
+		err = power.Suspend(ctx, t.ComputeName())
+	} else {
+		err = power.Resume(ctx, t.ComputeName())
+		condition.Status = tenant.ConditionFalse
+		condition.Reason = tenant.ReasonManualResume
+		condition.Message = fmt.Sprintf("Resumed by %s", manager)
+	}
+	if err != nil {
+		s.logger.Error("failed to change tenant power state", zap.String("action", string(action)), zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, fmt.Sprintf("Failed to %s tenant", action), []string{err.Error()}, requestID)
+		return
+	}
+
+	now := time.Now()
+	if t.SetCondition(condition, now) {
+		t.UpdatedAt = now
+		if err := s.tenantRepo.UpdateTenant(ctx, t); err != nil {
+			s.logger.Error("failed to record suspended condition", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to record the tenant's Suspended condition", nil, requestID)
+			return
+		}
+		s.recordTransition(ctx, tenant.NewStateTransition(t, t.Status, condition.Message, manager).WithReasonCode(condition.Reason), requestID)
+	}
+
+	resp := models.ToTenantResponse(t)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
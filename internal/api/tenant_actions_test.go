@@ -0,0 +1,233 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/schedule"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestRetryTenant(t *testing.T) {
+	repo := tenantmemory.New()
+	ctx := context.Background()
+	tenants := map[string]*tenant.Tenant{
+		"never-provisioned": {Name: "never-provisioned", Status: tenant.StatusFailed, StatusMessage: "image pull failed"},
+		"was-ready":         {Name: "was-ready", Status: tenant.StatusFailed, ObservedConfig: map[string]interface{}{"image": "nginx:1.25"}},
+		"migrating":         {Name: "migrating", Status: tenant.StatusFailed, ObservedConfig: map[string]interface{}{"image": "nginx:1.25"}},
+		"ready":             {Name: "ready", Status: tenant.StatusReady},
+	}
+	tenants["migrating"].StartMigration("docker", "ecs")
+	for _, tn := range tenants {
+		if err := repo.CreateTenant(ctx, tn); err != nil {
+			t.Fatalf("create tenant: %v", err)
+		}
+	}
+
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo}
+	srv.registerRoutes()
+
+	for name, tc := range map[string]struct {
+		code   int
+		status tenant.Status
+	}{
+		"never-provisioned": {http.StatusAccepted, tenant.StatusProvisioning},
+		"was-ready":         {http.StatusAccepted, tenant.StatusUpdating},
+		"migrating":         {http.StatusConflict, tenant.StatusFailed},
+		"ready":             {http.StatusConflict, tenant.StatusReady},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tenants/"+name+"/retry", nil))
+			if rec.Code != tc.code {
+				t.Fatalf("expected status %d, got %d: %s", tc.code, rec.Code, rec.Body.String())
+			}
+			updated, err := repo.GetTenantByName(ctx, name)
+			if err != nil {
+				t.Fatalf("get tenant: %v", err)
+			}
+			if updated.Status != tc.status {
+				t.Fatalf("expected status %s, got %s", tc.status, updated.Status)
+			}
+			if tc.code == http.StatusAccepted && updated.WorkflowExecutionID != nil {
+				t.Fatal("expected the failed execution to be cleared")
+			}
+		})
+	}
+}
+
+func TestSuspendAndResumeTenant(t *testing.T) {
+	repo := tenantmemory.New()
+	ctx := context.Background()
+	acme := &tenant.Tenant{Name: "acme", Status: tenant.StatusReady, DesiredConfig: map[string]interface{}{"image": "nginx:latest"}}
+	if err := repo.CreateTenant(ctx, acme); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	registry := newTestComputeRegistry()
+	provider, err := registry.Get("mock")
+	if err != nil {
+		t.Fatalf("get provider: %v", err)
+	}
+	if _, err := provider.Provision(ctx, &compute.TenantComputeSpec{
+		TenantID:     acme.ComputeName(),
+		ProviderType: "mock",
+		Containers:   []compute.ContainerSpec{{Name: "app", Image: "nginx:latest"}},
+	}); err != nil {
+		t.Fatalf("provision: %v", err)
+	}
+
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo, computeRegistry: registry, defaultComputeProvider: "mock"}
+	srv.registerRoutes()
+
+	for _, step := range []struct {
+		action    string
+		suspended tenant.ConditionStatus
+	}{
+		{"suspend", tenant.ConditionTrue},
+		{"resume", tenant.ConditionFalse},
+	} {
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tenants/acme/"+step.action, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", step.action, rec.Code, rec.Body.String())
+		}
+		var resp models.TenantResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		if resp.Status != string(tenant.StatusReady) {
+			t.Fatalf("%s: expected the tenant to stay ready, got %s", step.action, resp.Status)
+		}
+
+		updated, err := repo.GetTenantByName(ctx, "acme")
+		if err != nil {
+			t.Fatalf("get tenant: %v", err)
+		}
+		condition := updated.Condition(schedule.ConditionSuspended)
+		if condition == nil || condition.Status != step.suspended {
+			t.Fatalf("%s: unexpected Suspended condition %+v", step.action, condition)
+		}
+	}
+
+	status, err := provider.GetStatus(ctx, acme.ComputeName())
+	if err != nil {
+		t.Fatalf("get status: %v", err)
+	}
+	if status.State == compute.ComputeStateStopped {
+		t.Fatal("expected the tenant to run again after resume")
+	}
+
+	current, err := repo.GetTenantByName(ctx, "acme")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	current.Status = tenant.StatusUpdating
+	if err := repo.UpdateTenant(ctx, current); err != nil {
+		t.Fatalf("update tenant: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tenants/acme/suspend", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 while the tenant updates, got %d", rec.Code)
+	}
+}
+
+func TestRestartTenant(t *testing.T) {
+	repo := tenantmemory.New()
+	ctx := context.Background()
+	acme := &tenant.Tenant{Name: "acme", Status: tenant.StatusReady, DesiredConfig: map[string]interface{}{
+		"image":      "nginx:latest",
+		"components": map[string]interface{}{"app": map[string]interface{}{}, "worker": map[string]interface{}{}},
+	}}
+	if err := repo.CreateTenant(ctx, acme); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	registry := newTestComputeRegistry()
+	provider, err := registry.Get("mock")
+	if err != nil {
+		t.Fatalf("get provider: %v", err)
+	}
+	for _, computeID := range []string{"acme", "acme-worker"} {
+		if _, err := provider.Provision(ctx, &compute.TenantComputeSpec{
+			TenantID:     computeID,
+			ProviderType: "mock",
+			Containers:   []compute.ContainerSpec{{Name: "app", Image: "nginx:latest"}},
+		}); err != nil {
+			t.Fatalf("provision: %v", err)
+		}
+	}
+	restarts := func(computeID string) int {
+		status, err := provider.GetStatus(ctx, computeID)
+		if err != nil {
+			t.Fatalf("get status: %v", err)
+		}
+		return status.Containers[0].RestartCount
+	}
+
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo, computeRegistry: registry, defaultComputeProvider: "mock"}
+	srv.registerRoutes()
+
+	restart := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/tenants/acme/restart", strings.NewReader(body)))
+		return rec
+	}
+
+	if rec := restart(`{"component": "worker"}`); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if restarts("acme") != 0 || restarts("acme-worker") != 1 {
+		t.Fatalf("expected only the worker restarted, got app=%d worker=%d", restarts("acme"), restarts("acme-worker"))
+	}
+
+	if rec := restart(""); rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if restarts("acme") != 1 || restarts("acme-worker") != 2 {
+		t.Fatalf("expected every component restarted, got app=%d worker=%d", restarts("acme"), restarts("acme-worker"))
+	}
+
+	history, err := repo.GetStateHistory(ctx, acme.ID, tenant.HistoryFilters{})
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	reasons := make([]string, len(history))
+	for i, transition := range history {
+		reasons[i] = transition.Reason
+	}
+	for _, want := range []string{"Component worker restarted by " + tenant.ManagerAPI, "Restarted by " + tenant.ManagerAPI} {
+		if !slices.Contains(reasons, want) {
+			t.Fatalf("expected %q in the state history, got %v", want, reasons)
+		}
+	}
+
+	if rec := restart(`{"component": "cron"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an unknown component, got %d", rec.Code)
+	}
+
+	current, err := repo.GetTenantByName(ctx, "acme")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	current.SetCondition(tenant.Condition{Type: schedule.ConditionSuspended, Status: tenant.ConditionTrue}, time.Now())
+	if err := repo.UpdateTenant(ctx, current); err != nil {
+		t.Fatalf("update tenant: %v", err)
+	}
+	if rec := restart(""); rec.Code != http.StatusConflict {
+		t.Errorf("expected status 409 while the tenant is suspended, got %d", rec.Code)
+	}
+}
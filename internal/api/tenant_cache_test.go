@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantcache "github.com/jaxxstorm/landlord/internal/tenant/cache"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestGetTenantUsesTenantCache(t *testing.T) {
+	repo := tenantmemory.New()
+	ctx := context.Background()
+	web := &tenant.Tenant{ID: uuid.New(), Name: "web", Status: tenant.StatusReady}
+	if err := repo.CreateTenant(ctx, web); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo}
+	srv.registerRoutes()
+	cache := tenantcache.New(repo, config.TenantCacheConfig{Enabled: true, TTL: time.Minute, MaxEntries: 10}, zap.NewNop())
+	srv.SetTenantCache(cache)
+
+	getStatus := func() tenant.Status {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tenants/web", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var resp models.TenantResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return tenant.Status(resp.Status)
+	}
+
+	if got := getStatus(); got != tenant.StatusReady {
+		t.Fatalf("expected ready, got %s", got)
+	}
+
+	// A write behind the cache's back is not seen until the entry expires or is invalidated
+	stored, err := repo.GetTenantByID(ctx, web.ID)
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	stored.Status = tenant.StatusUpdating
+	if err := repo.UpdateTenant(ctx, stored); err != nil {
+		t.Fatalf("update tenant: %v", err)
+	}
+	if got := getStatus(); got != tenant.StatusReady {
+		t.Fatalf("expected the cached ready status, got %s", got)
+	}
+
+	// Requests that change the tenant read it from the database
+	fresh, err := srv.lookupTenant(ctx, "web")
+	if err != nil {
+		t.Fatalf("lookup tenant: %v", err)
+	}
+	if fresh.Status != tenant.StatusUpdating {
+		t.Fatalf("expected lookupTenant to bypass the cache, got %s", fresh.Status)
+	}
+
+	// Writes through the server's repository invalidate the tenant
+	fresh.Status = tenant.StatusReady
+	fresh.StatusMessage = "updated"
+	if err := srv.tenantRepo.UpdateTenant(ctx, fresh); err != nil {
+		t.Fatalf("update tenant: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tenants/web", nil))
+	if !strings.Contains(rec.Body.String(), `"status_message":"updated"`) {
+		t.Fatalf("expected the invalidated tenant to be read again, got %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.Contains(body, "landlord_tenant_cache_hits_total 1\n") || !strings.Contains(body, "landlord_tenant_cache_misses_total 2\n") {
+		t.Errorf("unexpected metrics %d: %s", rec.Code, body)
+	}
+}
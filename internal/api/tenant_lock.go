@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// tenantLockRetryAfter is the Retry-After value, in seconds, sent when a tenant is locked by another request
+const tenantLockRetryAfter = "1"
+
+// lockTenant claims t's mutation lock for the rest of the request, so concurrent changes to one
+// tenant run one after another instead of interleaving status transitions. It returns the tenant
+// re-read under the lock and the function that releases it. When the lock is held elsewhere, the
+// tenant cannot be re-read, or the caller may not change it, it writes the error response and
+// returns ok=false.
+func (s *Server) lockTenant(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, requestID string) (*tenant.Tenant, func(), bool) {
+	fresh, release, ok := s.lockTenantForDecision(w, r, t, requestID)
+	if !ok {
+		return nil, nil, false
+	}
+	if !s.tenantChangeAllowed(w, r, fresh, requestID) {
+		release()
+		return nil, nil, false
+	}
+	return fresh, release, true
+}
+
+// lockTenantForDecision is lockTenant for approval decisions, which carry out a change the requester
+// was allowed to make, so the approver need not own the tenant
+func (s *Server) lockTenantForDecision(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, requestID string) (*tenant.Tenant, func(), bool) {
+	ctx := r.Context()
+
+	release, err := s.tenantRepo.LockTenant(ctx, t.ID)
+	if err != nil {
+		if errors.Is(err, tenant.ErrTenantLocked) {
+			w.Header().Set("Retry-After", tenantLockRetryAfter)
+			s.writeError(w, r, http.StatusConflict, models.ErrorCodeOperationInProgress, "Another operation is in progress for this tenant", nil, requestID)
+			return nil, nil, false
+		}
+		s.logger.Error("failed to lock tenant", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to lock tenant", nil, requestID)
+		return nil, nil, false
+	}
+
+	// The tenant may have changed between the lookup and the lock
+	fresh, err := s.uncachedTenants().GetTenantByID(ctx, t.ID)
+	if err != nil {
+		release()
+		if errors.Is(err, tenant.ErrTenantNotFound) {
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant not found", nil, requestID)
+			return nil, nil, false
+		}
+		s.logger.Error("failed to get tenant", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+		return nil, nil, false
+	}
+	return fresh, release, true
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestMutatingRequestsRejectedWhileTenantLocked(t *testing.T) {
+	ctx := context.Background()
+	repo := tenantmemory.New()
+	tn := &tenant.Tenant{
+		Name:          "acme",
+		Status:        tenant.StatusReady,
+		DesiredConfig: map[string]interface{}{"image": "nginx:latest"},
+	}
+	if err := repo.CreateTenant(ctx, tn); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	srv := &Server{
+		router:                 chi.NewRouter(),
+		logger:                 zap.NewNop(),
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+		tenantRepo:             repo,
+	}
+	srv.registerRoutes()
+
+	release, err := repo.LockTenant(ctx, tn.ID)
+	if err != nil {
+		t.Fatalf("lock tenant: %v", err)
+	}
+
+	requests := []struct {
+		method      string
+		path        string
+		contentType string
+		body        string
+	}{
+		{http.MethodPut, "/v1/tenants/acme", "application/json", `{"compute_config": {"image": "nginx:1.27"}}`},
+		{http.MethodPatch, "/v1/tenants/acme", "application/merge-patch+json", `{"labels": {"team": "web"}}`},
+		{http.MethodPost, "/v1/tenants/acme/archive", "", ""},
+		{http.MethodPost, "/v1/tenants/acme/migrate", "application/json", `{"target_provider": "mock"}`},
+		{http.MethodDelete, "/v1/tenants/" + tn.ID.String(), "", ""},
+	}
+	for _, tc := range requests {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		if tc.contentType != "" {
+			req.Header.Set("Content-Type", tc.contentType)
+		}
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("%s %s: expected status 409, got %d: %s", tc.method, tc.path, rec.Code, rec.Body.String())
+		}
+		if got := rec.Header().Get("Retry-After"); got != tenantLockRetryAfter {
+			t.Errorf("%s %s: expected Retry-After %q, got %q", tc.method, tc.path, tenantLockRetryAfter, got)
+		}
+		var problem models.ProblemDetails
+		if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+			t.Fatalf("decode problem: %v", err)
+		}
+		if problem.ErrorCode != models.ErrorCodeOperationInProgress {
+			t.Errorf("%s %s: expected error code %s, got %s", tc.method, tc.path, models.ErrorCodeOperationInProgress, problem.ErrorCode)
+		}
+	}
+
+	stored, err := repo.GetTenantByID(ctx, tn.ID)
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if stored.Version != tn.Version || stored.Status != tenant.StatusReady {
+		t.Fatalf("locked tenant was changed: version %d, status %s", stored.Version, stored.Status)
+	}
+
+	// Once the lock is released the same request goes through, and releases the lock again
+	release()
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/v1/tenants/acme/archive", nil)
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusAccepted {
+			t.Fatalf("archive attempt %d: expected status 202, got %d: %s", i+1, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"regexp"
@@ -16,20 +17,30 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/approval"
 	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/endpointauth"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/resource"
 	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/warmpool"
+	"github.com/jaxxstorm/landlord/internal/workflow"
 )
 
 // handleCreateTenant creates a new tenant
 // @Summary Create a new tenant
 // @Description Creates a new tenant with provided configuration
+// @Description When the named template has a warm pool, a ready warm tenant is claimed instead of provisioning new compute.
 // @Tags tenants
 // @Accept json
 // @Produce json
 // @Param body body models.CreateTenantRequest true "Tenant creation request"
+// @Param field_manager query string false "Actor recorded as the owner of changed compute_config fields (defaults to X-Field-Manager header, then api)"
 // @Success 201 {object} models.TenantResponse "Tenant created successfully"
 // @Failure 400 {object} models.ErrorResponse "Invalid request or validation error"
-// @Failure 409 {object} models.ErrorResponse "Tenant name already exists"
+// @Failure 403 {object} models.ErrorResponse "API key is not scoped to the project"
+// @Failure 404 {object} models.ErrorResponse "Project not found"
+// @Failure 409 {object} models.ErrorResponse "Tenant name already exists or project quota exceeded"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /v1/tenants [post]
 func (s *Server) handleCreateTenant(w http.ResponseWriter, r *http.Request) {
@@ -39,63 +50,107 @@ func (s *Server) handleCreateTenant(w http.ResponseWriter, r *http.Request) {
 	// Parse request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
-		s.writeErrorResponse(w, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
 		return
 	}
 	defer r.Body.Close()
 
 	var req models.CreateTenantRequest
 	if err := json.Unmarshal(body, &req); err != nil {
-		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
 		return
 	}
 
 	// Validate required fields
 	req.Name = strings.TrimSpace(req.Name)
 	if req.Name == "" {
-		s.writeErrorResponse(w, http.StatusBadRequest, "name is required", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "name is required", nil, requestID)
 		return
 	}
 
 	if len(req.Name) > 255 {
-		s.writeErrorResponse(w, http.StatusBadRequest, "name must be <= 255 characters", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "name must be <= 255 characters", nil, requestID)
+		return
+	}
+
+	// These annotations are the server's record of warm pool membership and of where a tenant's
+	// compute runs, so a client cannot set them
+	delete(req.Annotations, warmpool.AnnotationTemplate)
+	delete(req.Annotations, tenant.AnnotationComputeName)
+	delete(req.Annotations, compute.AnnotationProviderDefaults)
+
+	if err := tenant.ValidateLabels(req.Labels, nil); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid labels", []string{err.Error()}, requestID)
+		return
+	}
+
+	// Place the tenant in a project, start from the project's template if one was named, and fill
+	// anything still unset from the project's policies
+	p, err := s.resolveTenantProject(ctx, &req)
+	if err != nil {
+		s.writeProjectError(w, r, err, requestID)
+		return
+	}
+	if err := applyProjectTemplate(p, &req); err != nil {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Unknown compute_config template", []string{err.Error()}, requestID)
+		return
+	}
+	if err := applyProjectPolicies(p, &req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Required labels missing", []string{err.Error()}, requestID)
+		return
+	}
+	if _, err := tenant.PriorityFromLabels(req.Labels); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid priority class", []string{err.Error()}, requestID)
+		return
+	}
+	if problems := s.featureFlagProblems(req.Annotations); len(problems) > 0 {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid feature flags", problems, requestID)
+		return
+	}
+	if err := s.checkProjectQuota(ctx, p); err != nil {
+		s.writeProjectError(w, r, err, requestID)
 		return
 	}
 
 	if req.ComputeConfig == nil {
-		s.writeErrorResponse(w, http.StatusBadRequest, "compute_config is required", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "compute_config is required", nil, requestID)
 		return
 	}
 
 	// Validate compute configuration if provided
+	var providerName string
 	if req.ComputeConfig != nil {
-		provider, _, err := s.resolveComputeProvider(req.ComputeConfig, req.Labels, req.Annotations, nil)
+		var provider compute.Provider
+		provider, providerName, err = s.resolveComputeProvider(req.Name, req.ComputeConfig, req.Labels, req.Annotations, nil)
 		if err != nil {
-			status := http.StatusBadRequest
-			message := "Compute provider not available"
-			if errors.Is(err, compute.ErrProviderNotFound) {
-				message = "Compute provider not available"
-			} else if strings.Contains(err.Error(), "registry not configured") {
-				status = http.StatusInternalServerError
-				message = "Compute provider registry not configured"
-			} else if strings.Contains(err.Error(), "multiple providers") {
-				message = "compute_provider is required when multiple compute providers are configured"
-			}
-			s.writeErrorResponse(w, status, message, []string{err.Error()}, requestID)
+			s.writeComputeProviderError(w, r, err, requestID)
 			return
 		}
-		// Convert map to JSON for validation
-		configJSON, err := json.Marshal(req.ComputeConfig)
-		if err != nil {
-			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid compute configuration format", []string{err.Error()}, requestID)
+		// Disabled providers keep serving existing tenants but receive no new ones
+		if !s.computeRegistry.Enabled(providerName) {
+			s.writeComputeProviderError(w, r, fmt.Errorf("%w: %s", compute.ErrProviderDisabled, providerName), requestID)
 			return
 		}
-		if err := compute.ValidateConfigAgainstSchema(provider, configJSON); err != nil {
-			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid compute configuration", computeSchemaErrorDetails(err), requestID)
+		if !s.applyProviderDefaults(w, r, provider, &req.ComputeConfig, &req.Annotations, requestID) {
 			return
 		}
-		if err := provider.ValidateConfig(configJSON); err != nil {
-			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid compute configuration", []string{err.Error()}, requestID)
+		if !s.validateComponentConfigs(w, r, provider, req.ComputeConfig, requestID) {
+			return
+		}
+		if _, err := workflow.ParseHooks(req.ComputeConfig); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid hooks configuration", []string{err.Error()}, requestID)
+			return
+		}
+		if _, err := endpointauth.Parse(req.ComputeConfig); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid endpoint auth configuration", []string{err.Error()}, requestID)
+			return
+		}
+		if _, err := compute.ParseEgressPolicy(req.ComputeConfig); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid egress configuration", []string{err.Error()}, requestID)
+			return
+		}
+		if _, err := resource.ParseSpecs(req.ComputeConfig); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid resources configuration", []string{err.Error()}, requestID)
 			return
 		}
 	}
@@ -103,26 +158,61 @@ func (s *Server) handleCreateTenant(w http.ResponseWriter, r *http.Request) {
 	// Convert request to domain model
 	t, err := models.FromCreateRequest(&req)
 	if err != nil {
-		s.writeErrorResponse(w, http.StatusBadRequest, "Failed to process request", []string{err.Error()}, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to process request", []string{err.Error()}, requestID)
 		return
 	}
 
-	// Set ID and timestamps
+	// Set ID, project, managed labels and timestamps
 	t.ID = uuid.New()
+	t.ProjectID = p.ID
+	t.Labels = tenant.WithManagedLabels(t.Labels, tenant.NewManagedLabels(p.Organization+"/"+p.Name, creator(r), req.Template))
+	if principal := project.PrincipalFromContext(ctx); principal != nil {
+		t.Owner = principal.Name
+	}
 	now := time.Now()
 	t.CreatedAt = now
 	t.UpdatedAt = now
 	t.Version = 1
+	t.UpdateManagedFields(nil, fieldManager(r), tenant.ManagedFieldOperationCreate, now)
+	if !s.enforceImagePolicy(w, r, t, requestID) {
+		return
+	}
+
+	// A ready warm tenant built from the template becomes this tenant instead of new compute
+	if s.warmPools != nil && req.Template != "" {
+		claimed, err := s.warmPools.Claim(ctx, p, req.Template, t, func(warm *tenant.Tenant) bool {
+			_, warmProvider, err := s.resolveComputeProvider(warm.Name, warm.DesiredConfig, warm.Labels, warm.Annotations, nil)
+			return err == nil && warmProvider == providerName
+		})
+		switch {
+		case err == nil:
+			s.logger.Info("tenant created from warm pool",
+				zap.String("tenant_name", claimed.Name),
+				zap.String("template", req.Template),
+				zap.String("status", string(claimed.Status)),
+				zap.String("request_id", requestID))
+
+			resp := models.ToTenantResponse(claimed)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(resp)
+			return
+		case errors.Is(err, warmpool.ErrNoWarmTenant), errors.Is(err, tenant.ErrTenantExists):
+			// Provision new compute; a taken name is reported by the create below
+		default:
+			s.logger.Warn("failed to claim warm tenant, provisioning a new one", zap.Error(err), zap.String("request_id", requestID))
+		}
+	}
 
 	// Create tenant in database
 	if err := s.tenantRepo.CreateTenant(ctx, t); err != nil {
 		// Check if it's a duplicate key error
 		if errors.Is(err, tenant.ErrTenantExists) {
-			s.writeErrorResponse(w, http.StatusConflict, "Tenant name already exists", nil, requestID)
+			s.writeErrorResponse(w, r, http.StatusConflict, "Tenant name already exists", nil, requestID)
 			return
 		}
 		s.logger.Error("failed to create tenant", zap.Error(err), zap.String("request_id", requestID))
-		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to create tenant", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to create tenant", nil, requestID)
 		return
 	}
 
@@ -143,8 +233,12 @@ func (s *Server) handleCreateTenant(w http.ResponseWriter, r *http.Request) {
 // @Tags tenants
 // @Produce json
 // @Param id path string true "Tenant identifier (UUID or name)"
-// @Success 200 {object} models.TenantResponse "Tenant found"
-// @Failure 400 {object} models.ErrorResponse "Invalid tenant identifier format"
+// @Param fields query string false "Only these fields (comma-separated JSON paths, such as name,status,observed_config.compute_status)"
+// @Param If-None-Match header string false "ETag of a copy the client holds; 304 when it is current"
+// @Param If-Modified-Since header string false "Time of a copy the client holds; 304 when the tenant has not changed since"
+// @Success 200 {object} models.TenantResponse "Tenant found; only the selected fields with fields"
+// @Success 304 "Not modified"
+// @Failure 400 {object} models.ErrorResponse "Invalid tenant identifier format or fields parameter"
 // @Failure 404 {object} models.ErrorResponse "Tenant not found"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /v1/tenants/{id} [get]
@@ -154,33 +248,45 @@ func (s *Server) handleGetTenant(w http.ResponseWriter, r *http.Request) {
 
 	identifier := chi.URLParam(r, "id")
 	if strings.TrimSpace(identifier) == "" {
-		s.writeErrorResponse(w, http.StatusBadRequest, "tenant identifier is required", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "tenant identifier is required", nil, requestID)
 		return
 	}
 	if isUUIDLike(identifier) {
 		if _, err := uuid.Parse(identifier); err != nil {
-			s.writeErrorResponse(w, http.StatusBadRequest, "invalid tenant identifier format", []string{err.Error()}, requestID)
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "invalid tenant identifier format", []string{err.Error()}, requestID)
 			return
 		}
 	}
+	fields, err := parseFields(r.URL.Query().Get("fields"), tenantFields)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid fields parameter", []string{err.Error()}, requestID)
+		return
+	}
 
-	// Get tenant from database
-	t, err := s.lookupTenant(ctx, identifier)
+	t, err := s.readTenant(ctx, identifier)
 	if err != nil {
 		if errors.Is(err, tenant.ErrTenantNotFound) {
-			s.writeErrorResponse(w, http.StatusNotFound, "Tenant not found", nil, requestID)
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant not found", nil, requestID)
 			return
 		}
 		s.logger.Error("failed to get tenant", zap.Error(err), zap.String("request_id", requestID))
-		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
 		return
 	}
 
 	// Return tenant
-	resp := models.ToTenantResponse(t)
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(resp)
+	var resp interface{} = models.ToTenantResponse(t)
+	if fields != nil {
+		if resp, err = fields.apply(resp); err != nil {
+			s.logger.Error("failed to select tenant fields", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+			return
+		}
+	}
+	// Every change to a tenant bumps its version and updated_at, so both validate the response
+	if err := writeConditionalJSON(w, r, resp, tenantETag(t), t.UpdatedAt, true); err != nil {
+		s.logger.Debug("failed to write tenant", zap.Error(err), zap.String("request_id", requestID))
+	}
 }
 
 // handleListTenants lists all tenants with pagination
@@ -194,7 +300,15 @@ func (s *Server) handleGetTenant(w http.ResponseWriter, r *http.Request) {
 // @Param workflow_sub_state query string false "Filter by workflow sub-state (comma-separated)"
 // @Param has_workflow_error query bool false "Filter tenants with workflow errors"
 // @Param min_retry_count query int false "Minimum workflow retry count"
-// @Success 200 {object} models.ListTenantsResponse "List of tenants"
+// @Param organization query string false "Only tenants in this organization"
+// @Param project query string false "Only tenants in projects with this name"
+// @Param labels query string false "Only tenants with all of these labels (comma-separated key=value pairs)"
+// @Param view query string false "full (default) or summary, which lists only id, name, status, labels and updated_at"
+// @Param fields query string false "Only these fields of each tenant (comma-separated JSON paths, such as name,status,workflow_sub_state)"
+// @Param If-None-Match header string false "ETag of a page the client holds; 304 when it is current"
+// @Param Cache-Control header string false "no-cache lists from the database rather than the list cache"
+// @Success 200 {object} models.ListTenantsResponse "List of tenants; models.ListTenantSummariesResponse with view=summary; models.ListTenantFieldsResponse with fields"
+// @Success 304 "Not modified"
 // @Failure 400 {object} models.ErrorResponse "Invalid pagination parameters"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /v1/tenants [get]
@@ -209,6 +323,8 @@ func (s *Server) handleListTenants(w http.ResponseWriter, r *http.Request) {
 	workflowSubStateStr := r.URL.Query().Get("workflow_sub_state")
 	hasWorkflowErrorStr := r.URL.Query().Get("has_workflow_error")
 	minRetryCountStr := r.URL.Query().Get("min_retry_count")
+	labelsStr := r.URL.Query().Get("labels")
+	view := r.URL.Query().Get("view")
 
 	limit := 50
 	offset := 0
@@ -216,11 +332,12 @@ func (s *Server) handleListTenants(w http.ResponseWriter, r *http.Request) {
 	workflowSubStates := []string{}
 	var hasWorkflowError *bool
 	var minRetryCount *int
+	var labels map[string]string
 
 	if limitStr != "" {
 		parsed, err := strconv.Atoi(limitStr)
 		if err != nil || parsed < 1 {
-			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid limit parameter", []string{"limit must be a positive integer"}, requestID)
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid limit parameter", []string{"limit must be a positive integer"}, requestID)
 			return
 		}
 		limit = parsed
@@ -229,7 +346,7 @@ func (s *Server) handleListTenants(w http.ResponseWriter, r *http.Request) {
 	if offsetStr != "" {
 		parsed, err := strconv.Atoi(offsetStr)
 		if err != nil || parsed < 0 {
-			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid offset parameter", []string{"offset must be a non-negative integer"}, requestID)
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid offset parameter", []string{"offset must be a non-negative integer"}, requestID)
 			return
 		}
 		offset = parsed
@@ -237,7 +354,7 @@ func (s *Server) handleListTenants(w http.ResponseWriter, r *http.Request) {
 	if includeDeletedStr != "" {
 		parsed, err := strconv.ParseBool(includeDeletedStr)
 		if err != nil {
-			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid include_deleted parameter", []string{"include_deleted must be a boolean"}, requestID)
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid include_deleted parameter", []string{"include_deleted must be a boolean"}, requestID)
 			return
 		}
 		includeDeleted = parsed
@@ -255,7 +372,7 @@ func (s *Server) handleListTenants(w http.ResponseWriter, r *http.Request) {
 	if hasWorkflowErrorStr != "" {
 		parsed, err := strconv.ParseBool(hasWorkflowErrorStr)
 		if err != nil {
-			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid has_workflow_error parameter", []string{"has_workflow_error must be a boolean"}, requestID)
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid has_workflow_error parameter", []string{"has_workflow_error must be a boolean"}, requestID)
 			return
 		}
 		hasWorkflowError = &parsed
@@ -264,12 +381,60 @@ func (s *Server) handleListTenants(w http.ResponseWriter, r *http.Request) {
 	if minRetryCountStr != "" {
 		parsed, err := strconv.Atoi(minRetryCountStr)
 		if err != nil || parsed < 0 {
-			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid min_retry_count parameter", []string{"min_retry_count must be a non-negative integer"}, requestID)
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid min_retry_count parameter", []string{"min_retry_count must be a non-negative integer"}, requestID)
 			return
 		}
 		minRetryCount = &parsed
 	}
 
+	if labelsStr != "" {
+		labels = make(map[string]string)
+		for _, pair := range strings.Split(labelsStr, ",") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || strings.TrimSpace(key) == "" {
+				s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid labels parameter", []string{"labels must be comma-separated key=value pairs"}, requestID)
+				return
+			}
+			labels[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	switch view {
+	case "", "full", "summary":
+	default:
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid view parameter", []string{"view must be full or summary"}, requestID)
+		return
+	}
+	allowedFields := tenantFields
+	if view == "summary" {
+		allowedFields = summaryFields
+	}
+	fields, err := parseFields(r.URL.Query().Get("fields"), allowedFields)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid fields parameter", []string{err.Error()}, requestID)
+		return
+	}
+
+	// Limit the list to the projects the caller may see
+	projectIDs, err := s.scopedProjectIDs(ctx, strings.TrimSpace(r.URL.Query().Get("organization")), strings.TrimSpace(r.URL.Query().Get("project")))
+	if err != nil {
+		s.logger.Error("failed to resolve project scope", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list tenants", nil, requestID)
+		return
+	}
+	if projectIDs != nil && len(projectIDs) == 0 {
+		if fields != nil {
+			s.writeTenantList(w, r, models.ListTenantFieldsResponse{Tenants: []map[string]interface{}{}, Limit: limit, Offset: offset}, time.Time{}, requestID)
+			return
+		}
+		if view == "summary" {
+			s.writeTenantList(w, r, models.ListTenantSummariesResponse{Tenants: []models.TenantSummary{}, Limit: limit, Offset: offset}, time.Time{}, requestID)
+			return
+		}
+		s.writeTenantList(w, r, models.ListTenantsResponse{Tenants: []models.TenantResponse{}, Limit: limit, Offset: offset}, time.Time{}, requestID)
+		return
+	}
+
 	// List tenants from database
 	filters := tenant.ListFilters{
 		Limit:          limit,
@@ -278,25 +443,83 @@ func (s *Server) handleListTenants(w http.ResponseWriter, r *http.Request) {
 		WorkflowSubStates: workflowSubStates,
 		HasWorkflowError:  hasWorkflowError,
 		MinRetryCount:     minRetryCount,
-	}
-	tenants, err := s.tenantRepo.ListTenants(ctx, filters)
-	if err != nil {
-		s.logger.Error("failed to list tenants", zap.Error(err), zap.String("request_id", requestID))
-		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to list tenants", nil, requestID)
-		return
+		ProjectIDs:        projectIDs,
+		Labels:            labels,
 	}
 
-	// Get total count - need to query without limit/offset
+	// Count every match from summaries, so the count does not read the tenants' JSONB columns
 	countFilters := filters
 	countFilters.Limit = 0
 	countFilters.Offset = 0
-	allTenants, err := s.tenantRepo.ListTenants(ctx, countFilters)
+	allSummaries, err := s.tenantRepo.ListTenantSummaries(ctx, countFilters)
 	if err != nil {
 		s.logger.Error("failed to count tenants", zap.Error(err), zap.String("request_id", requestID))
-		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to list tenants", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list tenants", nil, requestID)
+		return
+	}
+	total := len(allSummaries)
+
+	// Fields that summaries hold are served from them, so the tenants' configuration is not read
+	if fields != nil && fields.within(summaryFields) {
+		summaries, err := s.tenantRepo.ListTenantSummaries(ctx, filters)
+		if err != nil {
+			s.logger.Error("failed to list tenant summaries", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list tenants", nil, requestID)
+			return
+		}
+		var lastModified time.Time
+		responses := make([]interface{}, 0, len(summaries))
+		for _, summary := range summaries {
+			responses = append(responses, models.ToTenantSummary(summary))
+			lastModified = latest(lastModified, summary.UpdatedAt)
+		}
+		s.writeTenantFields(w, r, fields, responses, total, limit, offset, lastModified, requestID)
+		return
+	}
+
+	if view == "summary" {
+		summaries, err := s.tenantRepo.ListTenantSummaries(ctx, filters)
+		if err != nil {
+			s.logger.Error("failed to list tenant summaries", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list tenants", nil, requestID)
+			return
+		}
+		var lastModified time.Time
+		responses := make([]models.TenantSummary, 0, len(summaries))
+		for _, summary := range summaries {
+			responses = append(responses, models.ToTenantSummary(summary))
+			lastModified = latest(lastModified, summary.UpdatedAt)
+		}
+
+		s.writeTenantList(w, r, models.ListTenantSummariesResponse{
+			Tenants: responses,
+			Total:   total,
+			Limit:   limit,
+			Offset:  offset,
+		}, lastModified, requestID)
+		return
+	}
+
+	tenants, err := s.tenantRepo.ListTenants(ctx, filters)
+	if err != nil {
+		s.logger.Error("failed to list tenants", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list tenants", nil, requestID)
+		return
+	}
+
+	var lastModified time.Time
+	for _, t := range tenants {
+		lastModified = latest(lastModified, t.UpdatedAt)
+	}
+
+	if fields != nil {
+		responses := make([]interface{}, 0, len(tenants))
+		for _, t := range tenants {
+			responses = append(responses, models.ToTenantResponse(t))
+		}
+		s.writeTenantFields(w, r, fields, responses, total, limit, offset, lastModified, requestID)
 		return
 	}
-	total := len(allTenants)
 
 	// Convert to response format
 	responses := make([]models.TenantResponse, 0, len(tenants))
@@ -311,9 +534,48 @@ func (s *Server) handleListTenants(w http.ResponseWriter, r *http.Request) {
 		Offset:  offset,
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(resp)
+	s.writeTenantList(w, r, resp, lastModified, requestID)
+}
+
+// writeTenantList writes a page of tenants, validated by a hash of the page. Deleting a tenant
+// changes the page without moving any updated_at, so Last-Modified is informational and
+// If-Modified-Since is not honoured.
+func (s *Server) writeTenantList(w http.ResponseWriter, r *http.Request, resp interface{}, lastModified time.Time, requestID string) {
+	if s.listCacheControl != "" {
+		w.Header().Set("Cache-Control", s.listCacheControl)
+	}
+	if err := writeConditionalJSON(w, r, resp, "", lastModified, false); err != nil {
+		s.logger.Debug("failed to write tenant list", zap.Error(err), zap.String("request_id", requestID))
+	}
+}
+
+// latest returns the later of two times
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// writeTenantFields writes a page of tenants holding only the selected fields
+func (s *Server) writeTenantFields(w http.ResponseWriter, r *http.Request, fields fieldSelection, tenants []interface{}, total, limit, offset int, lastModified time.Time, requestID string) {
+	selected := make([]map[string]interface{}, 0, len(tenants))
+	for _, t := range tenants {
+		fieldsOf, err := fields.apply(t)
+		if err != nil {
+			s.logger.Error("failed to select tenant fields", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to list tenants", nil, requestID)
+			return
+		}
+		selected = append(selected, fieldsOf)
+	}
+
+	s.writeTenantList(w, r, models.ListTenantFieldsResponse{
+		Tenants: selected,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}, lastModified, requestID)
 }
 
 // handleUpdateTenant updates an existing tenant
@@ -324,6 +586,7 @@ func (s *Server) handleListTenants(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path string true "Tenant identifier (UUID or name)"
 // @Param body body models.UpdateTenantRequest true "Tenant update request"
+// @Param field_manager query string false "Actor recorded as the owner of changed compute_config fields (defaults to X-Field-Manager header, then api)"
 // @Success 200 {object} models.TenantResponse "Tenant updated successfully"
 // @Failure 400 {object} models.ErrorResponse "Invalid request or validation error"
 // @Failure 404 {object} models.ErrorResponse "Tenant not found"
@@ -335,12 +598,12 @@ func (s *Server) handleUpdateTenant(w http.ResponseWriter, r *http.Request) {
 
 	identifier := chi.URLParam(r, "id")
 	if strings.TrimSpace(identifier) == "" {
-		s.writeErrorResponse(w, http.StatusBadRequest, "tenant identifier is required", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "tenant identifier is required", nil, requestID)
 		return
 	}
 	if isUUIDLike(identifier) {
 		if _, err := uuid.Parse(identifier); err != nil {
-			s.writeErrorResponse(w, http.StatusBadRequest, "invalid tenant identifier format", []string{err.Error()}, requestID)
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "invalid tenant identifier format", []string{err.Error()}, requestID)
 			return
 		}
 	}
@@ -348,13 +611,13 @@ func (s *Server) handleUpdateTenant(w http.ResponseWriter, r *http.Request) {
 	// Parse request body
 	var req models.UpdateTenantRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeErrorResponse(w, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
 		return
 	}
 	defer r.Body.Close()
 
 	if req.ComputeConfig == nil {
-		s.writeErrorResponse(w, http.StatusBadRequest, "compute_config is required", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "compute_config is required", nil, requestID)
 		return
 	}
 
@@ -362,49 +625,101 @@ func (s *Server) handleUpdateTenant(w http.ResponseWriter, r *http.Request) {
 	t, err := s.lookupTenant(ctx, identifier)
 	if err != nil {
 		if errors.Is(err, tenant.ErrTenantNotFound) {
-			s.writeErrorResponse(w, http.StatusNotFound, "Tenant not found", nil, requestID)
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant not found", nil, requestID)
 			return
 		}
 		s.logger.Error("failed to get tenant", zap.Error(err), zap.String("request_id", requestID))
-		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+		return
+	}
+
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
 		return
 	}
+	defer release()
+
+	s.applyTenantUpdate(w, r, t, &req, tenant.ManagedFieldOperationUpdate)
+}
+
+// applyTenantUpdate validates and persists an update request against an existing tenant.
+// Shared by PUT and PATCH so both paths enforce the same provider and state checks.
+func (s *Server) applyTenantUpdate(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, req *models.UpdateTenantRequest, operation string) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
 
 	// Check for archived tenant
 	if t.Status == tenant.StatusArchived {
-		s.writeErrorResponse(w, http.StatusConflict, "Tenant is archived", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusConflict, "Tenant is archived", nil, requestID)
+		return
+	}
+	if t.Status == tenant.StatusMigrating {
+		s.writeInvalidStateError(w, r, "Cannot update tenant while it is migrating", nil, requestID)
+		return
+	}
+	if t.Status == tenant.StatusPendingApproval {
+		s.writeInvalidStateError(w, r, "Cannot update tenant while a promotion awaits approval", []string{"approve or reject the promotion first"}, requestID)
 		return
 	}
+	if _, err := tenant.PriorityFromLabels(req.Labels); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid priority class", []string{err.Error()}, requestID)
+		return
+	}
+	if err := tenant.ValidateLabels(req.Labels, t.Labels); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid labels", []string{err.Error()}, requestID)
+		return
+	}
+	if problems := s.featureFlagProblems(req.Annotations); len(problems) > 0 {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid feature flags", problems, requestID)
+		return
+	}
+	// Replacing the labels keeps the managed ones
+	if req.Labels != nil {
+		req.Labels = tenant.WithManagedLabels(req.Labels, tenant.ManagedLabels(t.Labels))
+	}
+
+	// The provider defaults annotation is the server's record, so a client replacing the annotations keeps it
+	if req.Annotations != nil {
+		delete(req.Annotations, compute.AnnotationProviderDefaults)
+		if applied, ok := t.Annotations[compute.AnnotationProviderDefaults]; ok {
+			req.Annotations[compute.AnnotationProviderDefaults] = applied
+		}
+	}
 
 	// Validate compute configuration if provided
 	if req.ComputeConfig != nil {
-		provider, _, err := s.resolveComputeProvider(req.ComputeConfig, req.Labels, req.Annotations, t)
+		provider, _, err := s.resolveComputeProvider(t.Name, req.ComputeConfig, req.Labels, req.Annotations, t)
 		if err != nil {
-			status := http.StatusBadRequest
-			message := "Compute provider not available"
-			if errors.Is(err, compute.ErrProviderNotFound) {
-				message = "Compute provider not available"
-			} else if strings.Contains(err.Error(), "registry not configured") {
-				status = http.StatusInternalServerError
-				message = "Compute provider registry not configured"
-			} else if strings.Contains(err.Error(), "multiple providers") {
-				message = "compute_provider is required when multiple compute providers are configured"
-			}
-			s.writeErrorResponse(w, status, message, []string{err.Error()}, requestID)
+			s.writeComputeProviderError(w, r, err, requestID)
 			return
 		}
 
-		configJSON, err := json.Marshal(req.ComputeConfig)
-		if err != nil {
-			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid compute configuration format", []string{err.Error()}, requestID)
+		if req.Annotations == nil {
+			req.Annotations = make(map[string]string, len(t.Annotations))
+			for key, value := range t.Annotations {
+				req.Annotations[key] = value
+			}
+		}
+		if !s.applyProviderDefaults(w, r, provider, &req.ComputeConfig, &req.Annotations, requestID) {
 			return
 		}
-		if err := compute.ValidateConfigAgainstSchema(provider, configJSON); err != nil {
-			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid compute configuration", computeSchemaErrorDetails(err), requestID)
+		if !s.validateComponentConfigs(w, r, provider, req.ComputeConfig, requestID) {
 			return
 		}
-		if err := provider.ValidateConfig(configJSON); err != nil {
-			s.writeErrorResponse(w, http.StatusBadRequest, "Invalid compute configuration", []string{err.Error()}, requestID)
+		if _, err := workflow.ParseHooks(req.ComputeConfig); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid hooks configuration", []string{err.Error()}, requestID)
+			return
+		}
+		if _, err := endpointauth.Parse(req.ComputeConfig); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid endpoint auth configuration", []string{err.Error()}, requestID)
+			return
+		}
+		if _, err := compute.ParseEgressPolicy(req.ComputeConfig); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid egress configuration", []string{err.Error()}, requestID)
+			return
+		}
+		if _, err := resource.ParseSpecs(req.ComputeConfig); err != nil {
+			s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidConfiguration, "Invalid resources configuration", []string{err.Error()}, requestID)
 			return
 		}
 	}
@@ -413,11 +728,11 @@ func (s *Server) handleUpdateTenant(w http.ResponseWriter, r *http.Request) {
 	if req.Name != nil {
 		trimmed := strings.TrimSpace(*req.Name)
 		if trimmed == "" {
-			s.writeErrorResponse(w, http.StatusBadRequest, "name cannot be empty", nil, requestID)
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "name cannot be empty", nil, requestID)
 			return
 		}
 		if len(trimmed) > 255 {
-			s.writeErrorResponse(w, http.StatusBadRequest, "name must be <= 255 characters", nil, requestID)
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "name must be <= 255 characters", nil, requestID)
 			return
 		}
 		req.Name = &trimmed
@@ -425,7 +740,7 @@ func (s *Server) handleUpdateTenant(w http.ResponseWriter, r *http.Request) {
 
 	// Validate state transition - check if tenant is in terminal failed state
 	if t.Status == tenant.StatusFailed {
-		s.writeErrorResponse(w, http.StatusConflict, "Cannot update tenant in failed state", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusConflict, "Cannot update tenant in failed state", nil, requestID)
 		return
 	}
 
@@ -433,16 +748,23 @@ func (s *Server) handleUpdateTenant(w http.ResponseWriter, r *http.Request) {
 	previousStatus := t.Status
 
 	// Apply update
-	if err := models.ApplyUpdateRequest(t, &req); err != nil {
-		s.writeErrorResponse(w, http.StatusBadRequest, "Failed to process update", []string{err.Error()}, requestID)
+	previousConfig := t.DesiredConfig
+	if err := models.ApplyUpdateRequest(t, req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to process update", []string{err.Error()}, requestID)
 		return
 	}
+	// The policy applies to the tenant as it will be after the update, including label changes
+	if !s.enforceImagePolicy(w, r, t, requestID) {
+		return
+	}
+	t.UpdateManagedFields(previousConfig, fieldManager(r), operation, time.Now())
 
 	// Set status to updating if currently ready, otherwise keep current status
 	if t.Status == tenant.StatusReady {
 		t.Status = tenant.StatusUpdating
 		t.StatusMessage = "Update requested"
 		t.WorkflowExecutionID = nil
+		t.WorkflowStartedAt = nil
 		t.WorkflowSubState = nil
 		t.WorkflowRetryCount = nil
 		t.WorkflowErrorMessage = nil
@@ -451,7 +773,7 @@ func (s *Server) handleUpdateTenant(w http.ResponseWriter, r *http.Request) {
 	// Validate state transition
 	if previousStatus != t.Status {
 		if err := tenant.ValidateTransition(previousStatus, t.Status); err != nil {
-			s.writeErrorResponse(w, http.StatusConflict, "Invalid state transition", []string{err.Error()}, requestID)
+			s.writeError(w, r, http.StatusConflict, models.ErrorCodeInvalidStateTransition, "Invalid state transition", []string{err.Error()}, requestID)
 			return
 		}
 	}
@@ -462,11 +784,11 @@ func (s *Server) handleUpdateTenant(w http.ResponseWriter, r *http.Request) {
 	// Save to database
 	if err := s.tenantRepo.UpdateTenant(ctx, t); err != nil {
 		if errors.Is(err, tenant.ErrTenantExists) {
-			s.writeErrorResponse(w, http.StatusConflict, "Tenant name already exists", nil, requestID)
+			s.writeErrorResponse(w, r, http.StatusConflict, "Tenant name already exists", nil, requestID)
 			return
 		}
 		s.logger.Error("failed to update tenant", zap.Error(err), zap.String("request_id", requestID))
-		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to update tenant", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to update tenant", nil, requestID)
 		return
 	}
 
@@ -481,13 +803,176 @@ func (s *Server) handleUpdateTenant(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(resp)
 }
 
+// handlePatchTenant applies a partial update to an existing tenant
+// @Summary Patch a tenant
+// @Description Applies a JSON Merge Patch (RFC 7386) or JSON Patch (RFC 6902) to the tenant's name, compute_config, labels, and annotations.
+// @Description Content-Type application/merge-patch+json (or application/json) selects merge patch; application/json-patch+json selects JSON Patch.
+// @Tags tenants
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Param body body object true "Merge patch document or array of JSON Patch operations"
+// @Param field_manager query string false "Actor recorded as the owner of changed compute_config fields (defaults to X-Field-Manager header, then api)"
+// @Success 200 {object} models.TenantResponse "Tenant updated successfully"
+// @Success 202 {object} models.TenantResponse "Tenant update accepted"
+// @Failure 400 {object} models.ErrorResponse "Invalid patch or validation error"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 409 {object} models.ErrorResponse "Invalid state transition or failed test operation"
+// @Failure 415 {object} models.ErrorResponse "Unsupported patch content type"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id} [patch]
+func (s *Server) handlePatchTenant(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	identifier := chi.URLParam(r, "id")
+	if strings.TrimSpace(identifier) == "" {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "tenant identifier is required", nil, requestID)
+		return
+	}
+	if isUUIDLike(identifier) {
+		if _, err := uuid.Parse(identifier); err != nil {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "invalid tenant identifier format", []string{err.Error()}, requestID)
+			return
+		}
+	}
+
+	contentType, err := patchContentType(r.Header.Get("Content-Type"))
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusUnsupportedMediaType, "Unsupported patch content type", []string{err.Error()}, requestID)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	// Get existing tenant
+	t, err := s.lookupTenant(ctx, identifier)
+	if err != nil {
+		if errors.Is(err, tenant.ErrTenantNotFound) {
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant not found", nil, requestID)
+			return
+		}
+		s.logger.Error("failed to get tenant", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+		return
+	}
+
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	doc := tenantPatchDocument(t)
+	switch contentType {
+	case jsonPatchContentType:
+		var ops []jsonPatchOperation
+		if err := json.Unmarshal(body, &ops); err != nil {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON Patch document", []string{err.Error()}, requestID)
+			return
+		}
+		patched, err := applyJSONPatch(doc, ops)
+		if err != nil {
+			if errors.Is(err, errPatchTestFailed) {
+				s.writeError(w, r, http.StatusConflict, models.ErrorCodePreconditionFailed, "Failed to apply patch", []string{err.Error()}, requestID)
+				return
+			}
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to apply patch", []string{err.Error()}, requestID)
+			return
+		}
+		doc = patched
+	default:
+		var patch interface{}
+		if err := json.Unmarshal(body, &patch); err != nil {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+			return
+		}
+		if _, ok := patch.(map[string]interface{}); !ok {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid merge patch document", []string{"merge patch must be a JSON object"}, requestID)
+			return
+		}
+		doc = applyMergePatch(doc, patch).(map[string]interface{})
+	}
+
+	req, err := updateRequestFromPatchDocument(doc)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid patched tenant", []string{err.Error()}, requestID)
+		return
+	}
+	if len(req.ComputeConfig) == 0 {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "compute_config is required", nil, requestID)
+		return
+	}
+
+	s.applyTenantUpdate(w, r, t, req, tenant.ManagedFieldOperationPatch)
+}
+
+// tenantPatchDocument renders the patchable subset of a tenant as a JSON document
+func tenantPatchDocument(t *tenant.Tenant) map[string]interface{} {
+	doc := map[string]interface{}{
+		"name":           t.Name,
+		"compute_config": copyDocument(t.DesiredConfig),
+		"labels":         copyDocument(t.Labels),
+		"annotations":    copyDocument(t.Annotations),
+	}
+	if t.DesiredConfig == nil {
+		doc["compute_config"] = map[string]interface{}{}
+	}
+	if t.Labels == nil {
+		doc["labels"] = map[string]interface{}{}
+	}
+	if t.Annotations == nil {
+		doc["annotations"] = map[string]interface{}{}
+	}
+	return doc
+}
+
+// updateRequestFromPatchDocument converts a patched document back into a full update request.
+// Labels and annotations are always set so removals in the patch are persisted.
+func updateRequestFromPatchDocument(doc map[string]interface{}) (*models.UpdateTenantRequest, error) {
+	for key := range doc {
+		switch key {
+		case "name", "compute_config", "labels", "annotations":
+		default:
+			return nil, fmt.Errorf("field %q cannot be patched", key)
+		}
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var req models.UpdateTenantRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return nil, err
+	}
+	if _, ok := doc["name"]; !ok {
+		return nil, fmt.Errorf("name cannot be removed")
+	}
+	if req.Labels == nil {
+		req.Labels = map[string]string{}
+	}
+	if req.Annotations == nil {
+		req.Annotations = map[string]string{}
+	}
+	return &req, nil
+}
+
 // handleArchiveTenant archives a tenant (removes compute but keeps record)
 // @Summary Archive a tenant
-// @Description Archives a tenant by removing compute resources and retaining the record
+// @Description Archives a tenant by removing compute resources and retaining the record.
+// @Description When approvals are enabled and the tenant is protected, a pending approval is returned instead; see /v1/approvals.
 // @Tags tenants
 // @Param id path string true "Tenant identifier (UUID or name)"
 // @Success 200 {object} models.TenantResponse "Tenant already archived"
-// @Success 202 {object} models.TenantResponse "Tenant archival initiated"
+// @Success 202 {object} models.TenantResponse "Tenant archival initiated, or an approval (models.ApprovalResponse) is pending"
 // @Failure 400 {object} models.ErrorResponse "Invalid tenant identifier format"
 // @Failure 404 {object} models.ErrorResponse "Tenant not found"
 // @Failure 409 {object} models.ErrorResponse "Invalid state transition"
@@ -499,12 +984,12 @@ func (s *Server) handleArchiveTenant(w http.ResponseWriter, r *http.Request) {
 
 	identifier := chi.URLParam(r, "id")
 	if strings.TrimSpace(identifier) == "" {
-		s.writeErrorResponse(w, http.StatusBadRequest, "tenant identifier is required", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "tenant identifier is required", nil, requestID)
 		return
 	}
 	if isUUIDLike(identifier) {
 		if _, err := uuid.Parse(identifier); err != nil {
-			s.writeErrorResponse(w, http.StatusBadRequest, "invalid tenant identifier format", []string{err.Error()}, requestID)
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "invalid tenant identifier format", []string{err.Error()}, requestID)
 			return
 		}
 	}
@@ -512,20 +997,35 @@ func (s *Server) handleArchiveTenant(w http.ResponseWriter, r *http.Request) {
 	t, err := s.lookupTenant(ctx, identifier)
 	if err != nil {
 		if errors.Is(err, tenant.ErrTenantNotFound) {
-			s.writeErrorResponse(w, http.StatusNotFound, "Tenant not found", nil, requestID)
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant not found", nil, requestID)
 			return
 		}
 		s.logger.Error("failed to get tenant", zap.Error(err), zap.String("request_id", requestID))
-		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
 		return
 	}
 
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	s.archiveTenant(w, r, t, requestID, true)
+}
+
+// archiveTenant moves a locked tenant to archiving and writes the response. Unless the
+// archival was already approved, protected tenants get a pending approval instead.
+// Returns true when the tenant is archiving or archived.
+func (s *Server) archiveTenant(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, requestID string, requireApproval bool) bool {
+	ctx := r.Context()
+
 	if t.Status == tenant.StatusArchived {
 		resp := models.ToTenantResponse(t)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(resp)
-		return
+		return true
 	}
 
 	if t.Status == tenant.StatusArchiving || t.Status == tenant.StatusDeleting {
@@ -533,30 +1033,37 @@ func (s *Server) handleArchiveTenant(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusAccepted)
 		json.NewEncoder(w).Encode(resp)
-		return
+		return true
+	}
+
+	if requireApproval && s.requestApproval(w, r, t, approval.OperationArchive, requestID) {
+		return false
 	}
 
 	previousStatus := t.Status
+	// A promotion still awaiting approval is abandoned with the tenant
+	t.ClearPromotion()
 	t.Status = tenant.StatusArchiving
 	t.StatusMessage = "Archival requested"
 	t.WorkflowExecutionID = nil
+	t.WorkflowStartedAt = nil
 	t.WorkflowSubState = nil
 	t.WorkflowRetryCount = nil
 	t.WorkflowErrorMessage = nil
 	if err := tenant.ValidateTransition(previousStatus, t.Status); err != nil {
-		s.writeInvalidStateError(w, "Invalid state transition", []string{err.Error()}, requestID)
-		return
+		s.writeInvalidStateError(w, r, "Invalid state transition", []string{err.Error()}, requestID)
+		return false
 	}
 
 	t.UpdatedAt = time.Now()
 	for attempt := 0; attempt < 2; attempt++ {
 		if err := s.tenantRepo.UpdateTenant(ctx, t); err != nil {
 			if errors.Is(err, tenant.ErrVersionConflict) {
-				fresh, fetchErr := s.lookupTenant(ctx, identifier)
+				fresh, fetchErr := s.lookupTenant(ctx, t.ID.String())
 				if fetchErr != nil {
 					s.logger.Error("failed to refetch tenant after version conflict", zap.Error(fetchErr), zap.String("request_id", requestID))
-					s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to initiate archival", nil, requestID)
-					return
+					s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to initiate archival", nil, requestID)
+					return false
 				}
 				t = fresh
 				if t.Status == tenant.StatusArchived {
@@ -564,32 +1071,33 @@ func (s *Server) handleArchiveTenant(w http.ResponseWriter, r *http.Request) {
 					w.Header().Set("Content-Type", "application/json")
 					w.WriteHeader(http.StatusOK)
 					json.NewEncoder(w).Encode(resp)
-					return
+					return true
 				}
 				if t.Status == tenant.StatusArchiving || t.Status == tenant.StatusDeleting {
 					resp := models.ToTenantResponse(t)
 					w.Header().Set("Content-Type", "application/json")
 					w.WriteHeader(http.StatusAccepted)
 					json.NewEncoder(w).Encode(resp)
-					return
+					return true
 				}
 				previousStatus = t.Status
 				t.Status = tenant.StatusArchiving
 				t.StatusMessage = "Archival requested"
 				t.WorkflowExecutionID = nil
+				t.WorkflowStartedAt = nil
 				t.WorkflowSubState = nil
 				t.WorkflowRetryCount = nil
 				t.WorkflowErrorMessage = nil
 				if err := tenant.ValidateTransition(previousStatus, t.Status); err != nil {
-					s.writeInvalidStateError(w, "Invalid state transition", []string{err.Error()}, requestID)
-					return
+					s.writeInvalidStateError(w, r, "Invalid state transition", []string{err.Error()}, requestID)
+					return false
 				}
 				t.UpdatedAt = time.Now()
 				continue
 			}
 			s.logger.Error("failed to update tenant status to archiving", zap.Error(err), zap.String("request_id", requestID))
-			s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to initiate archival", nil, requestID)
-			return
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to initiate archival", nil, requestID)
+			return false
 		}
 		break
 	}
@@ -598,14 +1106,16 @@ func (s *Server) handleArchiveTenant(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(resp)
+	return true
 }
 
 // handleDeleteTenant deletes a tenant
 // @Summary Delete a tenant
-// @Description Deletes a specific tenant resource
+// @Description Deletes a specific tenant resource.
+// @Description When approvals are enabled and the tenant is protected, a pending approval is returned instead; see /v1/approvals.
 // @Tags tenants
 // @Param id path string true "Tenant identifier (UUID or name)"
-// @Success 202 {object} models.TenantResponse "Tenant deletion initiated"
+// @Success 202 {object} models.TenantResponse "Tenant deletion initiated, or an approval (models.ApprovalResponse) is pending"
 // @Failure 400 {object} models.ErrorResponse "Invalid tenant identifier format"
 // @Failure 404 {object} models.ErrorResponse "Tenant not found"
 // @Failure 500 {object} models.ErrorResponse "Internal server error"
@@ -616,12 +1126,12 @@ func (s *Server) handleDeleteTenant(w http.ResponseWriter, r *http.Request) {
 
 	identifier := chi.URLParam(r, "id")
 	if strings.TrimSpace(identifier) == "" {
-		s.writeErrorResponse(w, http.StatusBadRequest, "tenant identifier is required", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "tenant identifier is required", nil, requestID)
 		return
 	}
 	if isUUIDLike(identifier) {
 		if _, err := uuid.Parse(identifier); err != nil {
-			s.writeErrorResponse(w, http.StatusBadRequest, "invalid tenant identifier format", []string{err.Error()}, requestID)
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "invalid tenant identifier format", []string{err.Error()}, requestID)
 			return
 		}
 	}
@@ -630,19 +1140,54 @@ func (s *Server) handleDeleteTenant(w http.ResponseWriter, r *http.Request) {
 	t, err := s.lookupTenant(ctx, identifier)
 	if err != nil {
 		if errors.Is(err, tenant.ErrTenantNotFound) {
-			s.writeErrorResponse(w, http.StatusNotFound, "Tenant not found", nil, requestID)
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant not found", nil, requestID)
 			return
 		}
 		s.logger.Error("failed to get tenant", zap.Error(err), zap.String("request_id", requestID))
-		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+		return
+	}
+
+	t, release, ok := s.lockTenant(w, r, t, requestID)
+	if !ok {
 		return
 	}
+	defer release()
+
+	s.deleteTenant(w, r, t, requestID, true)
+}
+
+// deleteTenant starts removing a locked tenant and writes the response: archived tenants are
+// deleted, others are archived first. Unless the deletion was already approved, protected
+// tenants get a pending approval instead. Returns true when the tenant is being removed.
+func (s *Server) deleteTenant(w http.ResponseWriter, r *http.Request, t *tenant.Tenant, requestID string, requireApproval bool) bool {
+	ctx := r.Context()
+
+	if t.Status == tenant.StatusArchiving {
+		resp := models.ToTenantResponse(t)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(resp)
+		return true
+	}
+	if t.Status == tenant.StatusDeleting {
+		resp := models.ToTenantResponse(t)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(resp)
+		return true
+	}
+
+	if requireApproval && s.requestApproval(w, r, t, approval.OperationDelete, requestID) {
+		return false
+	}
 
 	// Hard delete archived tenants
 	if t.Status == tenant.StatusArchived {
 		t.Status = tenant.StatusDeleting
 		t.StatusMessage = "Deletion requested"
 		t.WorkflowExecutionID = nil
+		t.WorkflowStartedAt = nil
 		t.WorkflowSubState = nil
 		t.WorkflowRetryCount = nil
 		t.WorkflowErrorMessage = nil
@@ -650,29 +1195,15 @@ func (s *Server) handleDeleteTenant(w http.ResponseWriter, r *http.Request) {
 
 		if err := s.tenantRepo.UpdateTenant(ctx, t); err != nil {
 			s.logger.Error("failed to update archived tenant to deleting", zap.Error(err), zap.String("request_id", requestID))
-			s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to initiate deletion", nil, requestID)
-			return
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to initiate deletion", nil, requestID)
+			return false
 		}
 
 		resp := models.ToTenantResponse(t)
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusAccepted)
 		json.NewEncoder(w).Encode(resp)
-		return
-	}
-	if t.Status == tenant.StatusArchiving {
-		resp := models.ToTenantResponse(t)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusAccepted)
-		json.NewEncoder(w).Encode(resp)
-		return
-	}
-	if t.Status == tenant.StatusDeleting {
-		resp := models.ToTenantResponse(t)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusAccepted)
-		json.NewEncoder(w).Encode(resp)
-		return
+		return true
 	}
 
 	// If there's an active workflow, stop it before transitioning to archiving
@@ -697,9 +1228,11 @@ func (s *Server) handleDeleteTenant(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Set status to deleting
+	t.ClearPromotion()
 	t.Status = tenant.StatusArchiving
 	t.StatusMessage = "Archival requested"
 	t.WorkflowExecutionID = nil
+	t.WorkflowStartedAt = nil
 	t.WorkflowSubState = nil
 	t.WorkflowRetryCount = nil
 	t.WorkflowErrorMessage = nil
@@ -712,8 +1245,8 @@ func (s *Server) handleDeleteTenant(w http.ResponseWriter, r *http.Request) {
 	// Update tenant status in database
 	if err := s.tenantRepo.UpdateTenant(ctx, t); err != nil {
 		s.logger.Error("failed to update tenant status to archiving", zap.Error(err), zap.String("request_id", requestID))
-		s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to initiate deletion", nil, requestID)
-		return
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to initiate deletion", nil, requestID)
+		return false
 	}
 
 	// Return tenant with HTTP 202 Accepted
@@ -721,43 +1254,97 @@ func (s *Server) handleDeleteTenant(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(resp)
+	return true
 }
 
 // writeErrorResponse writes a standardized error response
-func (s *Server) writeErrorResponse(w http.ResponseWriter, statusCode int, message string, details []string, requestID string) {
-	resp := models.ErrorResponse{
-		Error:     message,
-		Details:   details,
-		RequestID: requestID,
-	}
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(resp)
+func (s *Server) writeErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, message string, details []string, requestID string) {
+	s.writeError(w, r, statusCode, models.DefaultErrorCode(statusCode), message, details, requestID)
 }
 
 // writeWorkflowError writes a standardized error response for workflow trigger failures (500)
-func (s *Server) writeWorkflowError(w http.ResponseWriter, err error, tenantID string, requestID string) {
+func (s *Server) writeWorkflowError(w http.ResponseWriter, r *http.Request, err error, tenantID string, requestID string) {
 	s.logger.Error("failed to trigger workflow",
 		zap.Error(err),
 		zap.String("tenant_id", tenantID),
 		zap.String("request_id", requestID))
-	s.writeErrorResponse(w, http.StatusInternalServerError, "Failed to trigger workflow", []string{err.Error()}, requestID)
+	s.writeError(w, r, http.StatusInternalServerError, models.ErrorCodeWorkflowTriggerFailed, "Failed to trigger workflow", []string{err.Error()}, requestID)
 }
 
 // writeInvalidStateError writes a standardized error response for invalid state transitions (409)
-func (s *Server) writeInvalidStateError(w http.ResponseWriter, message string, details []string, requestID string) {
+func (s *Server) writeInvalidStateError(w http.ResponseWriter, r *http.Request, message string, details []string, requestID string) {
 	s.logger.Warn("invalid state transition",
 		zap.String("message", message),
 		zap.Strings("details", details),
 		zap.String("request_id", requestID))
-	s.writeErrorResponse(w, http.StatusConflict, message, details, requestID)
+	s.writeError(w, r, http.StatusConflict, models.ErrorCodeInvalidStateTransition, message, details, requestID)
 }
 
+// fieldManager identifies the actor making a tenant change for managed field tracking.
+// The field_manager query parameter takes precedence over the X-Field-Manager header, then the API key name.
+func fieldManager(r *http.Request) string {
+	if manager := strings.TrimSpace(r.URL.Query().Get("field_manager")); manager != "" {
+		return manager
+	}
+	if manager := strings.TrimSpace(r.Header.Get("X-Field-Manager")); manager != "" {
+		return manager
+	}
+	if principal := project.PrincipalFromContext(r.Context()); principal != nil {
+		return principal.Name
+	}
+	return tenant.ManagerAPI
+}
+
+// creator names who created a tenant for its created-by label. It is the API key when there is one,
+// since clients choose their field manager.
+func creator(r *http.Request) string {
+	if principal := project.PrincipalFromContext(r.Context()); principal != nil {
+		return principal.Name
+	}
+	return fieldManager(r)
+}
+
+// lookupTenant finds a tenant by ID or name, reading it from the database even when a tenant
+// cache is set, since the caller may change it. Tenants outside the caller's project scope are
+// reported as not found so their existence is not disclosed.
 func (s *Server) lookupTenant(ctx context.Context, identifier string) (*tenant.Tenant, error) {
-	if id, err := uuid.Parse(identifier); err == nil {
-		return s.tenantRepo.GetTenantByID(ctx, id)
+	return s.findTenant(ctx, s.uncachedTenants(), identifier)
+}
+
+// uncachedTenants returns the tenant repository behind the tenant cache, if one is set
+func (s *Server) uncachedTenants() tenant.Repository {
+	if s.tenantCache != nil {
+		return s.tenantCache.Repository
+	}
+	return s.tenantRepo
+}
+
+// readTenant finds a tenant like lookupTenant, serving it from the tenant cache when one is set.
+// Only handlers that do not change the tenant use it.
+func (s *Server) readTenant(ctx context.Context, identifier string) (*tenant.Tenant, error) {
+	return s.findTenant(ctx, s.tenantRepo, identifier)
+}
+
+func (s *Server) findTenant(ctx context.Context, repo tenant.Repository, identifier string) (*tenant.Tenant, error) {
+	var t *tenant.Tenant
+	var err error
+	if id, parseErr := uuid.Parse(identifier); parseErr == nil {
+		t, err = repo.GetTenantByID(ctx, id)
+	} else {
+		t, err = repo.GetTenantByName(ctx, identifier)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	inScope, err := s.tenantInScope(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	if !inScope {
+		return nil, tenant.ErrTenantNotFound
 	}
-	return s.tenantRepo.GetTenantByName(ctx, identifier)
+	return t, nil
 }
 
 var uuidLikePattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
@@ -16,6 +18,7 @@ import (
 	"github.com/jaxxstorm/landlord/internal/compute"
 	computemock "github.com/jaxxstorm/landlord/internal/compute/providers/mock"
 	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
 	"github.com/jaxxstorm/landlord/internal/workflow"
 )
 
@@ -86,6 +89,17 @@ func (m *mockTenantRepo) UpdateTenant(ctx context.Context, t *tenant.Tenant) err
 	return nil
 }
 
+func (m *mockTenantRepo) FenceWorkflowTrigger(ctx context.Context, t *tenant.Tenant, trigger func(ctx context.Context, t *tenant.Tenant) error) error {
+	if err := trigger(ctx, t); err != nil {
+		return err
+	}
+	return m.UpdateTenant(ctx, t)
+}
+
+func (m *mockTenantRepo) LockTenant(ctx context.Context, id uuid.UUID) (func(), error) {
+	return func() {}, nil
+}
+
 func newTestComputeRegistry() *compute.Registry {
 	registry := compute.NewRegistry(zap.NewNop())
 	_ = registry.Register(computemock.New())
@@ -117,6 +131,18 @@ func (m *mockTenantRepo) ListTenants(ctx context.Context, filters tenant.ListFil
 	return nil, nil
 }
 
+func (m *mockTenantRepo) ListTenantSummaries(ctx context.Context, filters tenant.ListFilters) ([]*tenant.Summary, error) {
+	tenants, err := m.ListTenants(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]*tenant.Summary, 0, len(tenants))
+	for _, t := range tenants {
+		summaries = append(summaries, &tenant.Summary{ID: t.ID, Name: t.Name, Status: t.Status, Labels: t.Labels, UpdatedAt: t.UpdatedAt})
+	}
+	return summaries, nil
+}
+
 func (m *mockTenantRepo) DeleteTenant(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
@@ -132,7 +158,7 @@ func (m *mockTenantRepo) RecordStateTransition(ctx context.Context, transition *
 	return nil
 }
 
-func (m *mockTenantRepo) GetStateHistory(ctx context.Context, tenantID uuid.UUID) ([]*tenant.StateTransition, error) {
+func (m *mockTenantRepo) GetStateHistory(ctx context.Context, tenantID uuid.UUID, filters tenant.HistoryFilters) ([]*tenant.StateTransition, error) {
 	return nil, nil
 }
 
@@ -228,6 +254,287 @@ func TestCreateTenantWorkflowTriggerFailure(t *testing.T) {
 	}
 }
 
+func TestCreateTenantRejectsInvalidHooks(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	tenantRepo := &mockTenantRepo{}
+
+	srv := &Server{
+		logger:                 logger,
+		tenantRepo:             tenantRepo,
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+	}
+
+	reqBody := models.CreateTenantRequest{
+		Name: "test-tenant",
+		ComputeConfig: map[string]interface{}{
+			"image": "nginx:latest",
+			"hooks": map[string]interface{}{
+				"pre_provision": []interface{}{
+					map[string]interface{}{"name": "schema", "type": "container"},
+				},
+			},
+		},
+	}
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/v1/tenants", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	srv.handleCreateTenant(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var errResp models.ProblemDetails
+	json.NewDecoder(w.Body).Decode(&errResp)
+	if errResp.Detail != "Invalid hooks configuration" {
+		t.Fatalf("unexpected error: %s", errResp.Detail)
+	}
+	if errResp.ErrorCode != models.ErrorCodeInvalidConfiguration {
+		t.Fatalf("expected code %s, got %s", models.ErrorCodeInvalidConfiguration, errResp.ErrorCode)
+	}
+}
+
+func TestCreateTenantRejectsInvalidPriority(t *testing.T) {
+	srv := &Server{
+		logger:                 zap.NewNop(),
+		tenantRepo:             &mockTenantRepo{},
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+	}
+
+	body, _ := json.Marshal(models.CreateTenantRequest{
+		Name:          "preview-42",
+		ComputeConfig: map[string]interface{}{"image": "nginx:latest"},
+		Labels:        map[string]string{"priority": "urgent"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/v1/tenants", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	srv.handleCreateTenant(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+	var errResp models.ProblemDetails
+	json.NewDecoder(w.Body).Decode(&errResp)
+	if errResp.Detail != "Invalid priority class" {
+		t.Fatalf("unexpected error: %s", errResp.Detail)
+	}
+}
+
+func TestTenantManagedLabels(t *testing.T) {
+	srv := &Server{
+		router:                 chi.NewRouter(),
+		logger:                 zap.NewNop(),
+		tenantRepo:             tenantmemory.New(),
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+	}
+	srv.registerRoutes()
+
+	serve := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		req.Header.Set("X-Field-Manager", "ci")
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		return rec
+	}
+	labels := func(rec *httptest.ResponseRecorder) map[string]string {
+		var resp models.TenantResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return resp.Labels
+	}
+
+	if rec := serve(http.MethodPost, "/v1/tenants", "application/json",
+		`{"name":"acme","compute_config":{"image":"nginx:latest"},"labels":{"landlord.io/project":"other/web"}}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for a reserved label, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec := serve(http.MethodPost, "/v1/tenants", "application/json", `{"name":"acme","compute_config":{"image":"nginx:latest"},"labels":{"team":"platform"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+	want := map[string]string{"team": "platform", tenant.LabelProject: "default/default", tenant.LabelCreatedBy: "ci"}
+	if got := labels(rec); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected labels %v, got %v", want, got)
+	}
+
+	if rec := serve(http.MethodPatch, "/v1/tenants/acme", "application/merge-patch+json", `{"labels":{"landlord.io/created-by":"someone-else"}}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for changing a managed label, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	// Clearing the labels keeps the managed ones
+	rec = serve(http.MethodPatch, "/v1/tenants/acme", "application/merge-patch+json", `{"labels":null}`)
+	if rec.Code != http.StatusOK && rec.Code != http.StatusAccepted {
+		t.Fatalf("expected the patch to succeed, got %d: %s", rec.Code, rec.Body.String())
+	}
+	delete(want, "team")
+	if got := labels(rec); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected labels %v, got %v", want, got)
+	}
+}
+
+func TestTenantProviderDefaults(t *testing.T) {
+	registry := compute.NewRegistry(zap.NewNop())
+	_ = registry.Register(computemock.NewWithDefaults(map[string]interface{}{"fail_provision_times": float64(0), "latency": "10ms"}))
+	srv := &Server{
+		router:                 chi.NewRouter(),
+		logger:                 zap.NewNop(),
+		tenantRepo:             tenantmemory.New(),
+		computeRegistry:        registry,
+		defaultComputeProvider: "mock",
+	}
+	srv.registerRoutes()
+
+	serve := func(method, path, body string) models.TenantResponse {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		if rec.Code >= 300 {
+			t.Fatalf("%s %s: unexpected status %d: %s", method, path, rec.Code, rec.Body.String())
+		}
+		var resp models.TenantResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		return resp
+	}
+
+	created := serve(http.MethodPost, "/v1/tenants",
+		`{"name":"acme","compute_config":{"image":"nginx:latest","latency":"1ms"},"annotations":{"landlord/provider_defaults":"image"}}`)
+	want := map[string]interface{}{"image": "nginx:latest", "latency": "1ms", "fail_provision_times": float64(0)}
+	if !reflect.DeepEqual(created.ComputeConfig, want) {
+		t.Fatalf("expected the defaults merged under the request, got %v", created.ComputeConfig)
+	}
+	if got := created.Annotations[compute.AnnotationProviderDefaults]; got != "fail_provision_times" {
+		t.Fatalf("expected the defaulted field recorded, got %q", got)
+	}
+
+	updated := serve(http.MethodPut, "/v1/tenants/acme", `{"compute_config":{"image":"nginx:1.27"},"annotations":{"team":"platform"}}`)
+	want = map[string]interface{}{"image": "nginx:1.27", "latency": "10ms", "fail_provision_times": float64(0)}
+	if !reflect.DeepEqual(updated.ComputeConfig, want) {
+		t.Fatalf("expected the defaults merged under the update, got %v", updated.ComputeConfig)
+	}
+	if got := updated.Annotations[compute.AnnotationProviderDefaults]; got != "fail_provision_times,latency" || updated.Annotations["team"] != "platform" {
+		t.Fatalf("unexpected annotations %v", updated.Annotations)
+	}
+}
+
+func TestCreateTenantComputeProviderErrorCodes(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	tests := []struct {
+		name            string
+		defaultProvider string
+		disabled        bool
+		computeConfig   map[string]interface{}
+		wantStatus      int
+		wantCode        models.ErrorCode
+	}{
+		{
+			name:          "no provider and no default",
+			computeConfig: map[string]interface{}{"image": "nginx:latest"},
+			wantStatus:    http.StatusBadRequest,
+			wantCode:      models.ErrorCodeProviderRequired,
+		},
+		{
+			name:            "unknown provider",
+			defaultProvider: "mock",
+			computeConfig:   map[string]interface{}{"image": "nginx:latest", "compute_provider": "missing"},
+			wantStatus:      http.StatusBadRequest,
+			wantCode:        models.ErrorCodeProviderNotFound,
+		},
+		{
+			name:            "disabled provider",
+			defaultProvider: "mock",
+			disabled:        true,
+			computeConfig:   map[string]interface{}{"image": "nginx:latest"},
+			wantStatus:      http.StatusConflict,
+			wantCode:        models.ErrorCodeProviderDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := newTestComputeRegistry()
+			if tt.disabled {
+				registry.SetEnabled("mock", false)
+			}
+			srv := &Server{
+				logger:                 logger,
+				tenantRepo:             &mockTenantRepo{},
+				computeRegistry:        registry,
+				defaultComputeProvider: tt.defaultProvider,
+			}
+
+			body, _ := json.Marshal(models.CreateTenantRequest{Name: "test-tenant", ComputeConfig: tt.computeConfig})
+			req := httptest.NewRequest(http.MethodPost, "/v1/tenants", strings.NewReader(string(body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			srv.handleCreateTenant(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			var errResp models.ProblemDetails
+			json.NewDecoder(w.Body).Decode(&errResp)
+			if errResp.ErrorCode != tt.wantCode {
+				t.Fatalf("expected code %s, got %s", tt.wantCode, errResp.ErrorCode)
+			}
+		})
+	}
+}
+
+func TestCreateTenantRejectsInvalidResources(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	tenantRepo := &mockTenantRepo{}
+
+	srv := &Server{
+		logger:                 logger,
+		tenantRepo:             tenantRepo,
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+	}
+
+	reqBody := models.CreateTenantRequest{
+		Name: "test-tenant",
+		ComputeConfig: map[string]interface{}{
+			"image": "nginx:latest",
+			"resources": []interface{}{
+				map[string]interface{}{"name": "db", "type": "postgres"},
+				map[string]interface{}{"name": "db", "type": "bucket"},
+			},
+		},
+	}
+
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/v1/tenants", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	srv.handleCreateTenant(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var errResp models.ProblemDetails
+	json.NewDecoder(w.Body).Decode(&errResp)
+	if errResp.Detail != "Invalid resources configuration" {
+		t.Fatalf("unexpected error: %s", errResp.Detail)
+	}
+}
+
 func TestCreateTenantRequiresComputeConfig(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	tenantRepo := &mockTenantRepo{}
@@ -254,10 +561,10 @@ func TestCreateTenantRequiresComputeConfig(t *testing.T) {
 		t.Fatalf("expected status 400, got %d", w.Code)
 	}
 
-	var errResp models.ErrorResponse
+	var errResp models.ProblemDetails
 	json.NewDecoder(w.Body).Decode(&errResp)
-	if errResp.Error != "compute_config is required" {
-		t.Fatalf("expected compute_config required error, got %s", errResp.Error)
+	if errResp.Detail != "compute_config is required" {
+		t.Fatalf("expected compute_config required error, got %s", errResp.Detail)
 	}
 }
 
@@ -368,10 +675,10 @@ func TestUpdateTenantRequiresComputeConfig(t *testing.T) {
 		t.Fatalf("expected status 400, got %d", w.Code)
 	}
 
-	var errResp models.ErrorResponse
+	var errResp models.ProblemDetails
 	json.NewDecoder(w.Body).Decode(&errResp)
-	if errResp.Error != "compute_config is required" {
-		t.Fatalf("expected compute_config required error, got %s", errResp.Error)
+	if errResp.Detail != "compute_config is required" {
+		t.Fatalf("expected compute_config required error, got %s", errResp.Detail)
 	}
 }
 
@@ -704,10 +1011,10 @@ func TestUpdateTenantArchivedReturns409(t *testing.T) {
 	}
 
 	// Verify error response
-	var errResp models.ErrorResponse
+	var errResp models.ProblemDetails
 	json.NewDecoder(w.Body).Decode(&errResp)
-	if errResp.Error != "Tenant is archived" {
-		t.Errorf("expected 'Tenant is archived', got %s", errResp.Error)
+	if errResp.Detail != "Tenant is archived" {
+		t.Errorf("expected 'Tenant is archived', got %s", errResp.Detail)
 	}
 }
 
@@ -799,10 +1106,10 @@ func TestUpdateTenantInvalidStateTransition(t *testing.T) {
 	}
 
 	// Verify error response
-	var errResp models.ErrorResponse
+	var errResp models.ProblemDetails
 	json.NewDecoder(w.Body).Decode(&errResp)
-	if errResp.Error != "Cannot update tenant in failed state" {
-		t.Errorf("expected conflict error, got %s", errResp.Error)
+	if errResp.Detail != "Cannot update tenant in failed state" {
+		t.Errorf("expected conflict error, got %s", errResp.Detail)
 	}
 }
 
@@ -886,7 +1193,7 @@ func TestListTenantsWorkflowFilters(t *testing.T) {
 		defaultComputeProvider: "mock",
 	}
 
-	req := httptest.NewRequest(http.MethodGet, "/v1/tenants?workflow_sub_state=backing-off,waiting&has_workflow_error=true&min_retry_count=2", nil)
+	req := httptest.NewRequest(http.MethodGet, "/v1/tenants?workflow_sub_state=backing-off,waiting&has_workflow_error=true&min_retry_count=2&labels=team=platform,env=prod", nil)
 	w := httptest.NewRecorder()
 
 	srv.handleListTenants(w, req)
@@ -908,6 +1215,70 @@ func TestListTenantsWorkflowFilters(t *testing.T) {
 	if filters.MinRetryCount == nil || *filters.MinRetryCount != 2 {
 		t.Fatalf("expected min_retry_count 2")
 	}
+	if len(filters.Labels) != 2 || filters.Labels["team"] != "platform" || filters.Labels["env"] != "prod" {
+		t.Fatalf("expected labels team=platform and env=prod, got %v", filters.Labels)
+	}
+
+	w = httptest.NewRecorder()
+	srv.handleListTenants(w, httptest.NewRequest(http.MethodGet, "/v1/tenants?labels=team", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for a label without a value, got %d", w.Code)
+	}
+}
+
+func TestListTenantsSummaryView(t *testing.T) {
+	repo := tenantmemory.New()
+	ctx := context.Background()
+	for _, name := range []string{"alpha", "beta"} {
+		if err := repo.CreateTenant(ctx, &tenant.Tenant{
+			Name:          name,
+			Status:        tenant.StatusReady,
+			Labels:        map[string]string{"team": name},
+			DesiredConfig: map[string]interface{}{"image": "nginx:latest"},
+		}); err != nil {
+			t.Fatalf("create tenant: %v", err)
+		}
+	}
+
+	srv := &Server{logger: zap.NewNop(), tenantRepo: repo}
+
+	w := httptest.NewRecorder()
+	srv.handleListTenants(w, httptest.NewRequest(http.MethodGet, "/v1/tenants?view=summary&limit=1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var raw struct {
+		Tenants []map[string]interface{} `json:"tenants"`
+	}
+	body := w.Body.Bytes()
+	if err := json.Unmarshal(body, &raw); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(raw.Tenants) != 1 {
+		t.Fatalf("expected 1 tenant, got %d", len(raw.Tenants))
+	}
+	if _, ok := raw.Tenants[0]["compute_config"]; ok {
+		t.Fatal("expected the summary view to leave out compute_config")
+	}
+
+	var resp models.ListTenantSummariesResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.Total != 2 || resp.Limit != 1 {
+		t.Fatalf("expected total 2 and limit 1, got %d and %d", resp.Total, resp.Limit)
+	}
+	summary := resp.Tenants[0]
+	if summary.Status != string(tenant.StatusReady) || summary.Labels["team"] != summary.Name || summary.UpdatedAt.IsZero() {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+
+	w = httptest.NewRecorder()
+	srv.handleListTenants(w, httptest.NewRequest(http.MethodGet, "/v1/tenants?view=compact", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for an unknown view, got %d", w.Code)
+	}
 }
 
 func TestGetTenantIncludesWorkflowStatusFields(t *testing.T) {
@@ -970,6 +1341,252 @@ func TestGetTenantIncludesWorkflowStatusFields(t *testing.T) {
 	}
 }
 
+// TestPatchTenantMergePatch tests that a merge patch updates a single env var and keeps the rest of compute_config
+func TestPatchTenantMergePatch(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	tenantID := uuid.New()
+	existingTenant := &tenant.Tenant{
+		ID:     tenantID,
+		Name:   "test-tenant",
+		Status: tenant.StatusReady,
+		DesiredConfig: map[string]interface{}{
+			"image": "nginx:1.0",
+			"env":   map[string]interface{}{"A": "1", "B": "2"},
+		},
+		Labels: map[string]string{"team": "platform", "tier": "free"},
+	}
+
+	var saved *tenant.Tenant
+	tenantRepo := &mockTenantRepo{
+		getByIDFunc: func(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+			return existingTenant.Clone(), nil
+		},
+		updateFunc: func(ctx context.Context, t *tenant.Tenant) error {
+			saved = t
+			return nil
+		},
+	}
+
+	srv := &Server{
+		logger:                 logger,
+		tenantRepo:             tenantRepo,
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+	}
+
+	body := `{"compute_config": {"env": {"B": "3"}}, "labels": {"tier": null}}`
+	req := httptest.NewRequest(http.MethodPatch, "/v1/tenants/"+tenantID.String(), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+		URLParams: chi.RouteParams{Keys: []string{"id"}, Values: []string{tenantID.String()}},
+	}))
+
+	w := httptest.NewRecorder()
+	srv.handlePatchTenant(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if saved == nil {
+		t.Fatal("expected tenant to be saved")
+	}
+	if saved.DesiredConfig["image"] != "nginx:1.0" {
+		t.Fatalf("expected image to be preserved, got %v", saved.DesiredConfig["image"])
+	}
+	env, _ := saved.DesiredConfig["env"].(map[string]interface{})
+	if env["A"] != "1" || env["B"] != "3" {
+		t.Fatalf("unexpected env after patch: %v", env)
+	}
+	if _, ok := saved.Labels["tier"]; ok {
+		t.Fatalf("expected tier label to be removed, got %v", saved.Labels)
+	}
+	if saved.Labels["team"] != "platform" {
+		t.Fatalf("expected team label to be preserved, got %v", saved.Labels)
+	}
+	if saved.Status != tenant.StatusUpdating {
+		t.Fatalf("expected status updating, got %s", saved.Status)
+	}
+}
+
+// TestUpdateTenantRecordsManagedFields tests that changed compute_config fields are attributed to the field manager
+func TestUpdateTenantRecordsManagedFields(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	tenantID := uuid.New()
+	createdAt := time.Now().Add(-time.Hour)
+	existingTenant := &tenant.Tenant{
+		ID:     tenantID,
+		Name:   "test-tenant",
+		Status: tenant.StatusReady,
+		DesiredConfig: map[string]interface{}{
+			"image": "nginx:1.0",
+			"env":   map[string]interface{}{"A": "1"},
+		},
+		ManagedFields: map[string]tenant.ManagedField{
+			"image": {Manager: "api", Operation: tenant.ManagedFieldOperationCreate, Time: createdAt},
+			"env":   {Manager: "api", Operation: tenant.ManagedFieldOperationCreate, Time: createdAt},
+		},
+	}
+
+	tenantRepo := &mockTenantRepo{
+		getByIDFunc: func(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+			return existingTenant.Clone(), nil
+		},
+	}
+
+	srv := &Server{
+		logger:                 logger,
+		tenantRepo:             tenantRepo,
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+	}
+
+	body := `{"compute_config": {"env": {"A": "2"}}}`
+	req := httptest.NewRequest(http.MethodPatch, "/v1/tenants/"+tenantID.String()+"?field_manager=gitops", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+		URLParams: chi.RouteParams{Keys: []string{"id"}, Values: []string{tenantID.String()}},
+	}))
+
+	w := httptest.NewRecorder()
+	srv.handlePatchTenant(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var respBody models.TenantResponse
+	if err := json.NewDecoder(w.Body).Decode(&respBody); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if got := respBody.ManagedFields["env"]; got.Manager != "gitops" || got.Operation != tenant.ManagedFieldOperationPatch {
+		t.Fatalf("expected env to be managed by gitops via patch, got %+v", got)
+	}
+	if got := respBody.ManagedFields["image"]; got.Manager != "api" || got.Operation != tenant.ManagedFieldOperationCreate {
+		t.Fatalf("expected image to keep its original manager, got %+v", got)
+	}
+}
+
+// TestPatchTenantJSONPatch tests RFC 6902 operations including a failing test op
+func TestPatchTenantJSONPatch(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	tenantID := uuid.New()
+	existingTenant := &tenant.Tenant{
+		ID:     tenantID,
+		Name:   "test-tenant",
+		Status: tenant.StatusReady,
+		DesiredConfig: map[string]interface{}{
+			"image": "nginx:1.0",
+		},
+	}
+
+	var saved *tenant.Tenant
+	tenantRepo := &mockTenantRepo{
+		getByIDFunc: func(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+			return existingTenant.Clone(), nil
+		},
+		updateFunc: func(ctx context.Context, t *tenant.Tenant) error {
+			saved = t
+			return nil
+		},
+	}
+
+	srv := &Server{
+		logger:                 logger,
+		tenantRepo:             tenantRepo,
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+	}
+
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPatch, "/v1/tenants/"+tenantID.String(), strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json-patch+json")
+		return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+			URLParams: chi.RouteParams{Keys: []string{"id"}, Values: []string{tenantID.String()}},
+		}))
+	}
+
+	w := httptest.NewRecorder()
+	srv.handlePatchTenant(w, newRequest(`[{"op": "test", "path": "/compute_config/image", "value": "nginx:0.9"}]`))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected status 409 for failed test op, got %d", w.Code)
+	}
+	var errResp models.ProblemDetails
+	json.NewDecoder(w.Body).Decode(&errResp)
+	if errResp.ErrorCode != models.ErrorCodePreconditionFailed {
+		t.Fatalf("expected code %s, got %s", models.ErrorCodePreconditionFailed, errResp.ErrorCode)
+	}
+	if saved != nil {
+		t.Fatal("expected tenant not to be saved after failed test op")
+	}
+
+	w = httptest.NewRecorder()
+	srv.handlePatchTenant(w, newRequest(`[
+		{"op": "test", "path": "/compute_config/image", "value": "nginx:1.0"},
+		{"op": "replace", "path": "/compute_config/image", "value": "nginx:2.0"},
+		{"op": "add", "path": "/annotations/owner", "value": "platform"}
+	]`))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("expected status 202, got %d: %s", w.Code, w.Body.String())
+	}
+	if saved == nil || saved.DesiredConfig["image"] != "nginx:2.0" {
+		t.Fatalf("expected image to be replaced, got %#v", saved)
+	}
+	if saved.Annotations["owner"] != "platform" {
+		t.Fatalf("expected owner annotation, got %v", saved.Annotations)
+	}
+}
+
+// TestPatchTenantRejectsInvalidPatches tests content type and document validation
+func TestPatchTenantRejectsInvalidPatches(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	tenantID := uuid.New()
+	tenantRepo := &mockTenantRepo{
+		getByIDFunc: func(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+			return &tenant.Tenant{
+				ID:            tenantID,
+				Name:          "test-tenant",
+				Status:        tenant.StatusReady,
+				DesiredConfig: map[string]interface{}{"image": "nginx:1.0"},
+			}, nil
+		},
+	}
+
+	srv := &Server{
+		logger:                 logger,
+		tenantRepo:             tenantRepo,
+		computeRegistry:        newTestComputeRegistry(),
+		defaultComputeProvider: "mock",
+	}
+
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"unsupported content type", "text/plain", `{}`, http.StatusUnsupportedMediaType},
+		{"non-object merge patch", "application/merge-patch+json", `[1]`, http.StatusBadRequest},
+		{"remove compute config", "application/merge-patch+json", `{"compute_config": null}`, http.StatusBadRequest},
+		{"unknown field", "application/merge-patch+json", `{"status": "ready"}`, http.StatusBadRequest},
+		{"non-string label", "application/merge-patch+json", `{"labels": {"team": 1}}`, http.StatusBadRequest},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/v1/tenants/"+tenantID.String(), strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, &chi.Context{
+				URLParams: chi.RouteParams{Keys: []string{"id"}, Values: []string{tenantID.String()}},
+			}))
+			w := httptest.NewRecorder()
+			srv.handlePatchTenant(w, req)
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d: %s", tc.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
 // Helper function for creating string pointers
 func stringPtr(s string) *string {
 	return &s
@@ -0,0 +1,308 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/approval"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/execution"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+const (
+	defaultTimelineLimit = 50
+	maxTimelineLimit     = 500
+)
+
+// SetComputeExecutions adds the compute operations the compute manager tracks to tenant timelines
+func (s *Server) SetComputeExecutions(repo compute.ExecutionRepository) {
+	s.computeExecutions = repo
+}
+
+// handleGetTenantTimeline returns everything recorded about a tenant as one feed
+// @Summary Get a tenant's activity timeline
+// @Description Merges the tenant's state transitions, events, workflow executions, compute executions and audit entries into one feed, newest first.
+// @Description Workflow executions come from the steps workers report, compute executions from the compute manager's tracking, and audit entries from approvals; sources that are not configured are left out.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant ID or name"
+// @Param types query string false "Only these entry types (comma-separated): state_transition, event, workflow_execution, compute_execution, audit"
+// @Param limit query int false "Maximum number of entries (default 50, max 500)"
+// @Param offset query int false "Number of entries to skip (default 0)"
+// @Success 200 {object} models.TenantTimelineResponse "Timeline"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants/{id}/timeline [get]
+func (s *Server) handleGetTenantTimeline(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+
+	identifier := chi.URLParam(r, "id")
+	if strings.TrimSpace(identifier) == "" {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "tenant identifier is required", nil, requestID)
+		return
+	}
+	if isUUIDLike(identifier) {
+		if _, err := uuid.Parse(identifier); err != nil {
+			s.writeErrorResponse(w, r, http.StatusBadRequest, "invalid tenant identifier format", []string{err.Error()}, requestID)
+			return
+		}
+	}
+
+	query := r.URL.Query()
+	var problems []string
+	types := map[string]bool{}
+	if raw := strings.TrimSpace(query.Get("types")); raw != "" {
+		known := map[string]bool{}
+		for _, typ := range models.TimelineTypes {
+			known[typ] = true
+		}
+		for _, typ := range strings.Split(raw, ",") {
+			typ = strings.TrimSpace(typ)
+			if !known[typ] {
+				problems = append(problems, fmt.Sprintf("unknown type %q: expected %s", typ, strings.Join(models.TimelineTypes, ", ")))
+				continue
+			}
+			types[typ] = true
+		}
+	}
+	limit := defaultTimelineLimit
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxTimelineLimit {
+			problems = append(problems, fmt.Sprintf("limit must be between 1 and %d", maxTimelineLimit))
+		}
+		limit = parsed
+	}
+	offset := 0
+	if raw := query.Get("offset"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			problems = append(problems, "offset must be a non-negative integer")
+		}
+		offset = parsed
+	}
+	if len(problems) > 0 {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid timeline parameters", problems, requestID)
+		return
+	}
+
+	t, err := s.readTenant(ctx, identifier)
+	if err != nil {
+		if errors.Is(err, tenant.ErrTenantNotFound) {
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Tenant not found", nil, requestID)
+			return
+		}
+		s.logger.Error("failed to get tenant", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant", nil, requestID)
+		return
+	}
+
+	entries, err := s.tenantTimeline(ctx, t, types)
+	if err != nil {
+		s.logger.Error("failed to build tenant timeline", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to retrieve tenant timeline", nil, requestID)
+		return
+	}
+
+	resp := models.TenantTimelineResponse{Entries: []models.TimelineEntry{}, Total: len(entries), Limit: limit, Offset: offset}
+	if offset < len(entries) {
+		resp.Entries = entries[offset:min(offset+limit, len(entries))]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// tenantTimeline collects a tenant's entries of the given types, or of every type when types is
+// empty, newest first
+func (s *Server) tenantTimeline(ctx context.Context, t *tenant.Tenant, types map[string]bool) ([]models.TimelineEntry, error) {
+	wanted := func(typ string) bool { return len(types) == 0 || types[typ] }
+	var entries []models.TimelineEntry
+
+	if wanted(models.TimelineTypeStateTransition) || wanted(models.TimelineTypeEvent) {
+		transitions, err := s.tenantRepo.GetStateHistory(ctx, t.ID, tenant.HistoryFilters{})
+		if err != nil {
+			return nil, fmt.Errorf("get state history: %w", err)
+		}
+		for _, transition := range transitions {
+			entry := transitionTimelineEntry(transition)
+			if wanted(entry.Type) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	if wanted(models.TimelineTypeWorkflowExecution) && s.executions != nil {
+		steps, err := s.executions.ListTenantSteps(ctx, t.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list execution steps: %w", err)
+		}
+		entries = append(entries, workflowTimelineEntries(steps, time.Now())...)
+	}
+
+	if wanted(models.TimelineTypeComputeExecution) && s.computeExecutions != nil {
+		// The compute manager tracks operations by the tenant ID workflows pass it, the tenant's name
+		executions, err := s.computeExecutions.ListComputeExecutions(ctx, t.Name, compute.ExecutionListFilters{})
+		if err != nil {
+			return nil, fmt.Errorf("list compute executions: %w", err)
+		}
+		for _, exec := range executions {
+			entries = append(entries, computeTimelineEntry(exec))
+		}
+	}
+
+	if wanted(models.TimelineTypeAudit) && s.approvals != nil {
+		approvals, err := s.approvals.List(ctx, approval.ListFilters{TenantID: &t.ID})
+		if err != nil {
+			return nil, fmt.Errorf("list approvals: %w", err)
+		}
+		for _, a := range approvals {
+			entries = append(entries, approvalTimelineEntries(a)...)
+		}
+	}
+
+	// Entries recorded at the same moment keep a stable order across pages
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.After(entries[j].Time)
+		}
+		if entries[i].Type != entries[j].Type {
+			return entries[i].Type < entries[j].Type
+		}
+		return entries[i].ID < entries[j].ID
+	})
+	return entries, nil
+}
+
+func transitionTimelineEntry(transition *tenant.StateTransition) models.TimelineEntry {
+	entry := models.TimelineEntry{
+		Time:       transition.CreatedAt,
+		Type:       models.TimelineTypeEvent,
+		ID:         transition.ID.String(),
+		Summary:    transition.Reason,
+		Actor:      transition.TriggeredBy,
+		Transition: transition,
+	}
+	if transition.FromStatus == nil || *transition.FromStatus != transition.ToStatus {
+		entry.Type = models.TimelineTypeStateTransition
+		from := "(none)"
+		if transition.FromStatus != nil {
+			from = string(*transition.FromStatus)
+		}
+		entry.Summary = fmt.Sprintf("%s → %s: %s", from, transition.ToStatus, transition.Reason)
+	}
+	return entry
+}
+
+// workflowTimelineEntries groups steps, oldest first, into one entry per execution
+func workflowTimelineEntries(steps []*execution.Step, now time.Time) []models.TimelineEntry {
+	byID := map[string]*models.TimelineWorkflowExecution{}
+	var order []string
+	for _, step := range steps {
+		exec, ok := byID[step.ExecutionID]
+		if !ok {
+			exec = &models.TimelineWorkflowExecution{ExecutionID: step.ExecutionID, StartedAt: step.StartedAt}
+			byID[step.ExecutionID] = exec
+			order = append(order, step.ExecutionID)
+		}
+		if step.StartedAt.Before(exec.StartedAt) {
+			exec.StartedAt = step.StartedAt
+		}
+		exec.Steps = append(exec.Steps, models.ToExecutionStepResponse(step, now))
+	}
+
+	entries := make([]models.TimelineEntry, 0, len(order))
+	for _, id := range order {
+		exec := byID[id]
+		exec.Status = string(workflow.StepSucceeded)
+		var failed string
+		for _, step := range exec.Steps {
+			switch workflow.StepStatus(step.Status) {
+			case workflow.StepRunning:
+				exec.Status = step.Status
+			case workflow.StepFailed:
+				if exec.Status != string(workflow.StepRunning) {
+					exec.Status = step.Status
+				}
+				failed = step.Name
+			}
+			if step.FinishedAt != nil && (exec.FinishedAt == nil || step.FinishedAt.After(*exec.FinishedAt)) {
+				exec.FinishedAt = step.FinishedAt
+			}
+		}
+		if exec.Status == string(workflow.StepRunning) {
+			exec.FinishedAt = nil
+		}
+		summary := fmt.Sprintf("Workflow execution %s", exec.Status)
+		if exec.Status == string(workflow.StepFailed) {
+			summary = fmt.Sprintf("Workflow execution failed at step %s", failed)
+		}
+		entries = append(entries, models.TimelineEntry{
+			Time:              exec.StartedAt,
+			Type:              models.TimelineTypeWorkflowExecution,
+			ID:                exec.ExecutionID,
+			Summary:           summary,
+			Actor:             "workflow",
+			WorkflowExecution: exec,
+		})
+	}
+	return entries
+}
+
+func computeTimelineEntry(exec *compute.ComputeExecution) models.TimelineEntry {
+	summary := fmt.Sprintf("Compute %s %s", exec.OperationType, exec.Status)
+	if exec.ErrorMessage != nil && *exec.ErrorMessage != "" {
+		summary = fmt.Sprintf("%s: %s", summary, *exec.ErrorMessage)
+	}
+	return models.TimelineEntry{
+		Time:             exec.CreatedAt,
+		Type:             models.TimelineTypeComputeExecution,
+		ID:               exec.ExecutionID,
+		Summary:          summary,
+		Actor:            "compute",
+		ComputeExecution: exec,
+	}
+}
+
+// approvalTimelineEntries records the request for an approval and, once made, its decision
+func approvalTimelineEntries(a *approval.Approval) []models.TimelineEntry {
+	entries := []models.TimelineEntry{{
+		Time:    a.CreatedAt,
+		Type:    models.TimelineTypeAudit,
+		ID:      a.ID.String(),
+		Summary: fmt.Sprintf("Requested approval to %s", a.Operation),
+		Actor:   a.RequestedBy,
+		Audit:   &models.TimelineAuditEntry{Action: "approval.requested", Operation: string(a.Operation)},
+	}}
+	if a.DecidedAt != nil {
+		summary := fmt.Sprintf("%s %s", strings.ToUpper(string(a.Status[:1]))+string(a.Status[1:]), a.Operation)
+		if a.Reason != "" {
+			summary = fmt.Sprintf("%s: %s", summary, a.Reason)
+		}
+		entries = append(entries, models.TimelineEntry{
+			Time:    *a.DecidedAt,
+			Type:    models.TimelineTypeAudit,
+			ID:      a.ID.String(),
+			Summary: summary,
+			Actor:   a.DecidedBy,
+			Audit:   &models.TimelineAuditEntry{Action: "approval." + string(a.Status), Operation: string(a.Operation), Reason: a.Reason},
+		})
+	}
+	return entries
+}
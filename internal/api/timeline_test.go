@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/approval"
+	approvalmemory "github.com/jaxxstorm/landlord/internal/approval/memory"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/execution"
+	executionmemory "github.com/jaxxstorm/landlord/internal/execution/memory"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// computeExecutions lists a fixed set of compute executions
+type computeExecutions struct {
+	compute.ExecutionRepository
+	executions []*compute.ComputeExecution
+}
+
+func (c *computeExecutions) ListComputeExecutions(ctx context.Context, tenantID string, filters compute.ExecutionListFilters) ([]*compute.ComputeExecution, error) {
+	var matched []*compute.ComputeExecution
+	for _, exec := range c.executions {
+		if exec.TenantID == tenantID {
+			matched = append(matched, exec)
+		}
+	}
+	return matched, nil
+}
+
+func TestGetTenantTimeline(t *testing.T) {
+	ctx := context.Background()
+	repo := tenantmemory.New()
+	tn := &tenant.Tenant{Name: "acme", Status: tenant.StatusRequested}
+	if err := repo.CreateTenant(ctx, tn); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	if err := repo.RecordStateTransition(ctx, tenant.NewStateTransition(tn, tenant.StatusProvisioning, "Workflow started", "reconciler")); err != nil {
+		t.Fatalf("record transition: %v", err)
+	}
+	tn.Status = tenant.StatusProvisioning
+	if err := repo.RecordStateTransition(ctx, tenant.NewStateTransition(tn, tenant.StatusProvisioning, "provision hook \"seed\" succeeded after 1 attempt(s)", "workflow:hook")); err != nil {
+		t.Fatalf("record event: %v", err)
+	}
+
+	now := time.Now()
+	steps := executionmemory.New()
+	finished := now.Add(2 * time.Second)
+	for _, step := range []*execution.Step{
+		{ExecutionID: "exec-1", TenantID: tn.ID, Name: "validate", Status: workflow.StepSucceeded, StartedAt: now.Add(time.Second), FinishedAt: &finished},
+		{ExecutionID: "exec-1", TenantID: tn.ID, Name: "provision", Status: workflow.StepFailed, Error: "image not found", StartedAt: now.Add(2 * time.Second), FinishedAt: &finished},
+	} {
+		if err := steps.RecordStep(ctx, step); err != nil {
+			t.Fatalf("record step: %v", err)
+		}
+	}
+
+	approvals := approvalmemory.New()
+	request := &approval.Approval{TenantID: tn.ID, TenantName: tn.Name, Operation: approval.OperationArchive, Status: approval.StatusPending, RequestedBy: "alice"}
+	if err := approvals.Create(ctx, request); err != nil {
+		t.Fatalf("create approval: %v", err)
+	}
+	if err := request.Reject("bob", "still in use", now.Add(4*time.Second)); err != nil {
+		t.Fatalf("reject approval: %v", err)
+	}
+	if err := approvals.Decide(ctx, request); err != nil {
+		t.Fatalf("decide approval: %v", err)
+	}
+
+	message := "image not found"
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo, executions: steps, approvals: approvals}
+	srv.SetComputeExecutions(&computeExecutions{executions: []*compute.ComputeExecution{
+		{ExecutionID: "compute-1", TenantID: "acme", OperationType: compute.OperationTypeProvision, Status: compute.ExecutionStatusFailed, ErrorMessage: &message, CreatedAt: now.Add(3 * time.Second)},
+		{ExecutionID: "compute-2", TenantID: "other", OperationType: compute.OperationTypeProvision, Status: compute.ExecutionStatusSucceeded, CreatedAt: now},
+	}})
+	srv.registerRoutes()
+
+	get := func(url string) (int, models.TenantTimelineResponse) {
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		var resp models.TenantTimelineResponse
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	code, resp := get("/v1/tenants/acme/timeline")
+	if code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", code)
+	}
+	var types []string
+	for i, entry := range resp.Entries {
+		types = append(types, entry.Type)
+		if i > 0 && entry.Time.After(resp.Entries[i-1].Time) {
+			t.Errorf("entry %d is newer than the entry before it", i)
+		}
+	}
+	want := []string{"audit", "compute_execution", "workflow_execution", "audit", "event", "state_transition"}
+	if resp.Total != len(want) || len(types) != len(want) {
+		t.Fatalf("expected entries %v, got %v (total %d)", want, types, resp.Total)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("expected entries %v, got %v", want, types)
+		}
+	}
+
+	rejected := resp.Entries[0]
+	if rejected.Actor != "bob" || rejected.Audit == nil || rejected.Audit.Action != "approval.rejected" || rejected.Audit.Reason != "still in use" {
+		t.Errorf("unexpected rejection entry %+v", rejected)
+	}
+	exec := resp.Entries[2].WorkflowExecution
+	if exec == nil || exec.ExecutionID != "exec-1" || exec.Status != "failed" || len(exec.Steps) != 2 || exec.FinishedAt == nil {
+		t.Errorf("unexpected workflow execution %+v", exec)
+	}
+	if resp.Entries[2].Summary != "Workflow execution failed at step provision" {
+		t.Errorf("unexpected summary %q", resp.Entries[2].Summary)
+	}
+	if transition := resp.Entries[5]; transition.Transition == nil || transition.Transition.ToStatus != tenant.StatusProvisioning || transition.Actor != "reconciler" {
+		t.Errorf("unexpected transition entry %+v", transition)
+	}
+
+	// Filtering and pagination
+	code, resp = get("/v1/tenants/acme/timeline?types=state_transition,event&limit=1&offset=1")
+	if code != http.StatusOK || resp.Total != 2 || len(resp.Entries) != 1 || resp.Entries[0].Type != "state_transition" {
+		t.Errorf("unexpected filtered page %d %+v", code, resp)
+	}
+	if code, resp = get("/v1/tenants/acme/timeline?offset=10"); code != http.StatusOK || len(resp.Entries) != 0 || resp.Total != 6 {
+		t.Errorf("expected an empty page past the end, got %d %+v", code, resp)
+	}
+
+	for _, url := range []string{
+		"/v1/tenants/acme/timeline?types=logs",
+		"/v1/tenants/acme/timeline?limit=0",
+		"/v1/tenants/acme/timeline?limit=1000",
+		"/v1/tenants/acme/timeline?offset=-1",
+	} {
+		if code, _ := get(url); code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", url, code)
+		}
+	}
+	if code, _ := get("/v1/tenants/missing/timeline"); code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", code)
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+)
+
+// requestTimeoutKey holds the timeout applied to a request in its context
+type requestTimeoutKey struct{}
+
+// timeoutRequests bounds each request with its route's timeout. Repository and provider calls take
+// the request context, so a stalled database or workflow engine fails them once the deadline passes
+// and the handler answers 504 instead of holding the connection open.
+func (s *Server) timeoutRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := s.routeTimeout(r)
+		// A long poll waits on top of its route's timeout, and needs the write deadline moved with it
+		if wait := s.longPollWait(r); wait > 0 {
+			if timeout > 0 {
+				timeout += wait
+			}
+			if s.server != nil && s.server.WriteTimeout > 0 {
+				_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(wait + s.server.WriteTimeout))
+			}
+		}
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		r = r.WithContext(context.WithValue(ctx, requestTimeoutKey{}, timeout))
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		// A handler that gave up on a cancelled call without responding still gets a structured error
+		if ww.Status() == 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			s.writeErrorResponse(ww, r, http.StatusGatewayTimeout, "Request timed out", nil, r.Header.Get("X-Request-ID"))
+		}
+	})
+}
+
+// routeTimeout returns the timeout for r's route: a "METHOD /pattern" override, then a "/pattern"
+// override, then the server-wide request timeout
+func (s *Server) routeTimeout(r *http.Request) time.Duration {
+	if len(s.routeTimeouts) > 0 {
+		if pattern := s.routePattern(r); pattern != "" {
+			if timeout, ok := s.routeTimeouts[r.Method+" "+pattern]; ok {
+				return timeout
+			}
+			if timeout, ok := s.routeTimeouts[pattern]; ok {
+				return timeout
+			}
+		}
+	}
+	return s.requestTimeout
+}
+
+// timeoutError rewrites a server error written after the request's deadline passed into a 504,
+// keeping the handler's message as a detail. ok is false for any other error.
+func timeoutError(r *http.Request, statusCode int, message string, details []string) (string, []string, bool) {
+	if r == nil || statusCode < http.StatusInternalServerError || !errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+		return "", nil, false
+	}
+	timeout, _ := r.Context().Value(requestTimeoutKey{}).(time.Duration)
+	detail := "Request timed out"
+	if timeout > 0 {
+		detail = fmt.Sprintf("Request did not complete within %s", timeout)
+	}
+	if message != "" && !strings.HasPrefix(message, "Request timed out") {
+		details = append([]string{message}, details...)
+	}
+	return detail, details, true
+}
+
+// logRequestTimeout records a request that ran past its deadline
+func (s *Server) logRequestTimeout(r *http.Request, message string) {
+	s.logger.Warn("request timed out",
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path),
+		zap.String("error", message),
+		zap.String("request_id", r.Header.Get("X-Request-ID")))
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+// stallingRepository blocks tenant lookups until the caller's context ends, like a hung database
+type stallingRepository struct {
+	*tenantmemory.Repository
+}
+
+func (r stallingRepository) GetTenantByName(ctx context.Context, name string) (*tenant.Tenant, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func newTimeoutServer(cfg *config.HTTPConfig) *Server {
+	repo := stallingRepository{Repository: tenantmemory.New()}
+	return New(cfg, nil, newTestComputeRegistry(), "mock", repo, nil, zap.NewNop())
+}
+
+func TestRequestTimeoutReturnsGatewayTimeout(t *testing.T) {
+	srv := newTimeoutServer(&config.HTTPConfig{RequestTimeout: 20 * time.Millisecond})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/tenants/acme", nil)
+	rec := httptest.NewRecorder()
+	start := time.Now()
+	srv.Handler().ServeHTTP(rec, req)
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the request to be cut off, took %s", elapsed)
+	}
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected status 504, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var problem models.ProblemDetails
+	if err := json.NewDecoder(rec.Body).Decode(&problem); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	if problem.ErrorCode != models.ErrorCodeTimeout || problem.Detail != "Request did not complete within 20ms" {
+		t.Fatalf("unexpected problem: %+v", problem)
+	}
+	if len(problem.Errors) != 1 || problem.Errors[0] != "Failed to retrieve tenant" {
+		t.Fatalf("expected the handler's message as a detail, got %v", problem.Errors)
+	}
+}
+
+func TestRouteTimeoutOverridesRequestTimeout(t *testing.T) {
+	srv := newTimeoutServer(&config.HTTPConfig{
+		RequestTimeout: time.Hour,
+		RouteTimeouts: map[string]time.Duration{
+			"GET /v1/tenants/{id}": 20 * time.Millisecond,
+			"/v1/tenants":          time.Minute,
+		},
+	})
+
+	tests := []struct {
+		method string
+		path   string
+		want   time.Duration
+	}{
+		{method: http.MethodGet, path: "/v1/tenants/acme", want: 20 * time.Millisecond},
+		{method: http.MethodDelete, path: "/v1/tenants/acme", want: time.Hour},
+		{method: http.MethodPost, path: "/v1/tenants", want: time.Minute},
+		{method: http.MethodGet, path: "/health", want: time.Hour},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if got := srv.routeTimeout(req); got != tt.want {
+			t.Fatalf("%s %s: expected timeout %s, got %s", tt.method, tt.path, tt.want, got)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/tenants/acme", nil)
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected status 504, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTimeoutWithoutResponseWritesGatewayTimeout(t *testing.T) {
+	srv := &Server{logger: zap.NewNop(), requestTimeout: 10 * time.Millisecond}
+	handler := srv.timeoutRequests(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tenants/acme", nil))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("expected status 504, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/uptime"
+)
+
+// handleGetTenantUptime returns a tenant's recent uptime checks
+// @Summary Get tenant uptime
+// @Description Returns the availability and latency of the tenant's recent uptime checks. A tenant that has not been checked yet, such as one that is not ready, reports no checks.
+// @Tags tenants
+// @Produce json
+// @Param id path string true "Tenant identifier (UUID or name)"
+// @Success 200 {object} models.TenantUptimeResponse "Uptime report"
+// @Failure 400 {object} models.ErrorResponse "Invalid tenant identifier format"
+// @Failure 404 {object} models.ErrorResponse "Tenant not found"
+// @Failure 503 {object} models.ErrorResponse "Uptime checks not configured"
+// @Router /v1/tenants/{id}/uptime [get]
+func (s *Server) handleGetTenantUptime(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+
+	if s.uptime == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Uptime checks not configured", nil, requestID)
+		return
+	}
+	t, ok := s.readTenantFromPath(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	report, ok := s.uptime.Report(t.ID)
+	if !ok {
+		report = &uptime.Report{History: []uptime.Check{}}
+	}
+	resp := models.TenantUptimeResponse{
+		TenantID:   t.ID.String(),
+		TenantName: t.Name,
+		Report:     *report,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleMetrics serves the build, component versions, tenant uptime, tenant cache and list cache counters in the Prometheus text
+// exposition format. It covers every tenant, so it needs an admin API key when keys are configured.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+
+	if !project.PrincipalFromContext(r.Context()).Unrestricted() {
+		s.writeErrorResponse(w, r, http.StatusForbidden, "Metrics require an admin API key", nil, requestID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if s.versions != nil {
+		if err := s.versions.WriteMetrics(w); err != nil {
+			s.logger.Warn("failed to write metrics", zap.Error(err), zap.String("request_id", requestID))
+		}
+	}
+	if s.uptime != nil {
+		if err := s.uptime.WriteMetrics(w); err != nil {
+			s.logger.Warn("failed to write metrics", zap.Error(err), zap.String("request_id", requestID))
+		}
+	}
+	if s.tenantCache != nil {
+		if err := s.tenantCache.WriteMetrics(w); err != nil {
+			s.logger.Warn("failed to write metrics", zap.Error(err), zap.String("request_id", requestID))
+		}
+	}
+	if s.listCache != nil {
+		if err := s.listCache.WriteMetrics(w); err != nil {
+			s.logger.Warn("failed to write metrics", zap.Error(err), zap.String("request_id", requestID))
+		}
+	}
+	if s.callbackAuth != nil {
+		if err := s.callbackAuth.WriteMetrics(w); err != nil {
+			s.logger.Warn("failed to write metrics", zap.Error(err), zap.String("request_id", requestID))
+		}
+	}
+}
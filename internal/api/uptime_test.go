@@ -0,0 +1,82 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+	"github.com/jaxxstorm/landlord/internal/uptime"
+)
+
+func TestGetTenantUptime(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer target.Close()
+
+	repo := tenantmemory.New()
+	ctx := context.Background()
+	web := &tenant.Tenant{
+		ID:             uuid.New(),
+		Name:           "web",
+		Status:         tenant.StatusReady,
+		ObservedConfig: map[string]interface{}{"endpoints": []interface{}{map[string]interface{}{"type": "http", "url": target.URL}}},
+	}
+	if err := repo.CreateTenant(ctx, web); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	if err := repo.CreateTenant(ctx, &tenant.Tenant{ID: uuid.New(), Name: "idle", Status: tenant.StatusReady}); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), tenantRepo: repo}
+	srv.registerRoutes()
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/tenants/web/uptime", nil)
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503 without uptime checks, got %d", rec.Code)
+	}
+
+	checker := uptime.NewChecker(repo, config.UptimeConfig{Enabled: true, Interval: time.Minute, Timeout: time.Second, FailureThreshold: 3, HistorySize: 10}, zap.NewNop())
+	srv.SetUptime(checker)
+	if err := checker.CheckAll(ctx); err != nil {
+		t.Fatalf("check all: %v", err)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tenants/web/uptime", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp models.TenantUptimeResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if resp.TenantName != "web" || resp.Checks != 1 || resp.Availability != 1 || resp.LastCheck == nil || !resp.LastCheck.Up {
+		t.Errorf("unexpected uptime %+v", resp)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/tenants/idle/uptime", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"checks":0`) {
+		t.Errorf("expected an unchecked tenant to report no checks, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `landlord_tenant_up{tenant_id="`+web.ID.String()+`",tenant_name="web"} 1`) {
+		t.Errorf("unexpected metrics %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/endpointauth"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/resource"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// handleValidateTenant runs every create-time check against a tenant spec without persisting it
+// @Summary Validate a tenant spec
+// @Description Runs the same checks as tenant creation (naming, project scope and quota, templates, provider selection, compute_config schema, provider validation, hooks, resources and provider capacity) without creating anything.
+// @Description Every violation is returned rather than only the first, so CI can gate tenant spec changes.
+// @Tags tenants
+// @Accept json
+// @Produce json
+// @Param body body models.CreateTenantRequest true "Tenant spec to validate"
+// @Success 200 {object} models.ValidateTenantResponse "Validation result; valid is false when violations were found"
+// @Failure 400 {object} models.ErrorResponse "Request body is not valid JSON"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /v1/tenants:validate [post]
+func (s *Server) handleValidateTenant(w http.ResponseWriter, r *http.Request) {
+	requestID := r.Header.Get("X-Request-ID")
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	var req models.CreateTenantRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return
+	}
+
+	violations, err := s.validateTenantSpec(r, &req)
+	if err != nil {
+		s.logger.Error("failed to validate tenant", zap.Error(err), zap.String("request_id", requestID))
+		s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to validate tenant", nil, requestID)
+		return
+	}
+
+	resp := models.ValidateTenantResponse{Valid: len(violations) == 0, Violations: violations}
+	if resp.Violations == nil {
+		resp.Violations = []models.Violation{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// validateTenantSpec collects every violation in a create request. The error is only set when a check
+// could not run, such as a failed repository lookup.
+func (s *Server) validateTenantSpec(r *http.Request, req *models.CreateTenantRequest) ([]models.Violation, error) {
+	var violations []models.Violation
+	add := func(field, check, message string) {
+		violations = append(violations, models.Violation{Field: field, Check: check, Message: message})
+	}
+
+	name := strings.TrimSpace(req.Name)
+	switch {
+	case name == "":
+		add("name", "request", "name is required")
+	case len(name) > 255:
+		add("name", "naming", "name must be <= 255 characters")
+	default:
+		_, err := s.tenantRepo.GetTenantByName(r.Context(), name)
+		switch {
+		case err == nil:
+			add("name", "naming", fmt.Sprintf("tenant name %q already exists", name))
+		case !errors.Is(err, tenant.ErrTenantNotFound):
+			return nil, fmt.Errorf("look up tenant name: %w", err)
+		}
+	}
+
+	if err := tenant.ValidateLabels(req.Labels, nil); err != nil {
+		add("labels", "labels", err.Error())
+	}
+
+	p, err := s.resolveTenantProject(r.Context(), req)
+	switch {
+	case errors.Is(err, errProjectForbidden):
+		add("project", "scope", err.Error())
+	case errors.Is(err, project.ErrInvalid), errors.Is(err, project.ErrProjectNotFound):
+		add("project", "project", err.Error())
+	case err != nil:
+		return nil, fmt.Errorf("resolve project: %w", err)
+	default:
+		if err := applyProjectTemplate(p, req); err != nil {
+			add("template", "template", err.Error())
+		}
+		if err := applyProjectPolicies(p, req); err != nil {
+			add("labels", "labels", err.Error())
+		}
+		if err := s.checkProjectQuota(r.Context(), p); err != nil {
+			if !errors.Is(err, project.ErrQuotaExceeded) {
+				return nil, err
+			}
+			add("project", "quota", err.Error())
+		}
+	}
+
+	for _, problem := range s.featureFlagProblems(req.Annotations) {
+		add("annotations", "feature_flags", problem)
+	}
+
+	if req.ComputeConfig == nil {
+		add("compute_config", "request", "compute_config is required")
+		return violations, nil
+	}
+
+	if _, err := workflow.ParseHooks(req.ComputeConfig); err != nil {
+		add("compute_config.hooks", "hooks", err.Error())
+	}
+	configs, err := componentConfigs(req.ComputeConfig)
+	if err != nil {
+		add("compute_config."+tenant.ComponentsConfigKey, "components", err.Error())
+	}
+	for _, config := range configs {
+		if _, err := workflow.ParseReadinessProbe(config.values); err != nil {
+			add(config.field+".readiness_probe", "readiness_probe", err.Error())
+		}
+	}
+	if _, err := endpointauth.Parse(req.ComputeConfig); err != nil {
+		add("compute_config.endpoint_auth", "endpoint_auth", err.Error())
+	}
+	if _, err := compute.ParseEgressPolicy(req.ComputeConfig); err != nil {
+		add("compute_config.egress", "egress", err.Error())
+	}
+	if _, err := resource.ParseSpecs(req.ComputeConfig); err != nil {
+		add("compute_config.resources", "resources", err.Error())
+	}
+	for _, v := range s.imagePolicy.Check(r.Context(), req.Labels, req.ComputeConfig) {
+		add("compute_config.image", "image_policy", v.String())
+	}
+
+	provider, providerName, err := s.resolveComputeProvider(req.Name, req.ComputeConfig, req.Labels, req.Annotations, nil)
+	if err != nil {
+		if errors.Is(err, errComputeRegistryNotConfigured) {
+			return nil, err
+		}
+		add("compute_config.compute_provider", "provider", err.Error())
+		return violations, nil
+	}
+	if !s.computeRegistry.Enabled(providerName) {
+		add("compute_config.compute_provider", "provider", fmt.Sprintf("%s: %s", compute.ErrProviderDisabled, providerName))
+	}
+
+	// The provider validates the config the tenant would be created with, including its defaults
+	defaulted, _, err := compute.ApplyConfigDefaults(provider, req.ComputeConfig)
+	if err != nil {
+		return nil, err
+	}
+	req.ComputeConfig = defaulted
+	if configs, err = componentConfigs(req.ComputeConfig); err != nil {
+		return violations, nil
+	}
+	for _, config := range configs {
+		if err := compute.ValidateConfigAgainstSchema(provider, config.raw); err != nil {
+			for _, detail := range computeSchemaErrorDetails(err) {
+				add(config.field, "schema", detail)
+			}
+			// Provider validation assumes a config that matches the schema
+			continue
+		}
+		if err := provider.ValidateConfig(config.raw); err != nil {
+			check := "provider_config"
+			if errors.Is(err, compute.ErrQuotaExceeded) {
+				check = "quota"
+			}
+			add(config.field, check, err.Error())
+		}
+	}
+
+	return violations, nil
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// rejectingComputeProvider fails provider-specific validation with a fixed error
+type rejectingComputeProvider struct {
+	testComputeProvider
+	err error
+}
+
+func (p *rejectingComputeProvider) ValidateConfig(config json.RawMessage) error { return p.err }
+
+func TestValidateTenant(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		existing       bool
+		wantViolations []models.Violation
+	}{
+		{
+			name: "valid spec",
+			body: `{"name":"acme","compute_config":{"compute_provider":"docker","image":"nginx"}}`,
+		},
+		{
+			name:     "reports every violation",
+			body:     `{"name":"acme","compute_config":{"compute_provider":"ecs","image":"nginx"}}`,
+			existing: true,
+			wantViolations: []models.Violation{
+				{Field: "name", Check: "naming"},
+				{Field: "compute_config", Check: "schema"},
+			},
+		},
+		{
+			name: "missing name and config",
+			body: `{}`,
+			wantViolations: []models.Violation{
+				{Field: "name", Check: "request"},
+				{Field: "compute_config", Check: "request"},
+			},
+		},
+		{
+			name: "validates each component",
+			body: `{"name":"acme","compute_config":{"compute_provider":"ecs","image":"nginx",` +
+				`"components":{"app":{},"worker":{"task_role_arn":"arn:aws:iam::1:role/worker"}}}}`,
+			wantViolations: []models.Violation{{Field: "compute_config.components.app", Check: "schema"}},
+		},
+		{
+			name:           "invalid components",
+			body:           `{"name":"acme","compute_config":{"compute_provider":"docker","components":{"Web_1":{}}}}`,
+			wantViolations: []models.Violation{{Field: "compute_config.components", Check: "components"}},
+		},
+		{
+			name: "invalid feature flags",
+			body: `{"name":"acme","annotations":{"landlord.io/flags/skip-health-check":"yes","landlord.io/flags/fast-boot":"true"},` +
+				`"compute_config":{"compute_provider":"docker","image":"nginx"}}`,
+			wantViolations: []models.Violation{{Field: "annotations", Check: "feature_flags"}},
+		},
+		{
+			name:           "unknown provider",
+			body:           `{"name":"acme","compute_config":{"compute_provider":"nomad"}}`,
+			wantViolations: []models.Violation{{Field: "compute_config.compute_provider", Check: "provider"}},
+		},
+		{
+			name:           "provider rejects config",
+			body:           `{"name":"acme","compute_config":{"compute_provider":"kubernetes"}}`,
+			wantViolations: []models.Violation{{Field: "compute_config", Check: "provider_config"}},
+		},
+		{
+			name:           "provider capacity exceeded",
+			body:           `{"name":"acme","compute_config":{"compute_provider":"small"}}`,
+			wantViolations: []models.Violation{{Field: "compute_config", Check: "quota"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := compute.NewRegistry(zap.NewNop())
+			_ = registry.Register(&testComputeProvider{name: "docker", schema: json.RawMessage(`{"type":"object"}`)})
+			_ = registry.Register(&testComputeProvider{name: "ecs", schema: json.RawMessage(`{"type":"object","required":["task_role_arn"]}`)})
+			_ = registry.Register(&rejectingComputeProvider{
+				testComputeProvider: testComputeProvider{name: "kubernetes", schema: json.RawMessage(`{"type":"object"}`)},
+				err:                 fmt.Errorf("%w: namespace is required", compute.ErrInvalidConfig),
+			})
+			_ = registry.Register(&rejectingComputeProvider{
+				testComputeProvider: testComputeProvider{name: "small", schema: json.RawMessage(`{"type":"object"}`)},
+				err:                 compute.Mark(fmt.Errorf("%w: memory limit exceeds host capacity", compute.ErrInvalidConfig), compute.ErrQuotaExceeded),
+			})
+
+			created := false
+			srv := &Server{
+				router: chi.NewRouter(),
+				logger: zap.NewNop(),
+				tenantRepo: &mockTenantRepo{
+					getByNameFunc: func(ctx context.Context, name string) (*tenant.Tenant, error) {
+						if tt.existing {
+							return &tenant.Tenant{Name: name}, nil
+						}
+						return nil, tenant.ErrTenantNotFound
+					},
+					createFunc: func(ctx context.Context, t *tenant.Tenant) error {
+						created = true
+						return nil
+					},
+				},
+				computeRegistry: registry,
+			}
+			srv.registerRoutes()
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/tenants:validate", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			srv.router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			if created {
+				t.Fatal("expected validation not to create the tenant")
+			}
+
+			var resp models.ValidateTenantResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if resp.Valid != (len(tt.wantViolations) == 0) {
+				t.Fatalf("expected valid=%v, got %+v", len(tt.wantViolations) == 0, resp)
+			}
+			for _, want := range tt.wantViolations {
+				found := false
+				for _, got := range resp.Violations {
+					if got.Field == want.Field && got.Check == want.Check && got.Message != "" {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("expected a %s violation on %s, got %+v", want.Check, want.Field, resp.Violations)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateTenantInvalidJSON(t *testing.T) {
+	srv := &Server{logger: zap.NewNop(), tenantRepo: &mockTenantRepo{}, computeRegistry: newTestComputeRegistry()}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/tenants:validate", strings.NewReader(`{"name":`))
+	w := httptest.NewRecorder()
+	srv.handleValidateTenant(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
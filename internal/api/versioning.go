@@ -5,11 +5,12 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/jaxxstorm/landlord/internal/api/models"
 	"github.com/jaxxstorm/landlord/internal/apiversion"
 )
 
 func (s *Server) handleVersionRequired(w http.ResponseWriter, r *http.Request) {
-	s.writeVersionError(w, r, "version_required")
+	s.writeVersionError(w, r, "version_required", models.ErrorCodeVersionRequired)
 }
 
 func (s *Server) handleUnsupportedVersion(w http.ResponseWriter, r *http.Request) {
@@ -18,10 +19,10 @@ func (s *Server) handleUnsupportedVersion(w http.ResponseWriter, r *http.Request
 		http.NotFound(w, r)
 		return
 	}
-	s.writeVersionError(w, r, "unsupported_version")
+	s.writeVersionError(w, r, "unsupported_version", models.ErrorCodeUnsupportedVersion)
 }
 
-func (s *Server) writeVersionError(w http.ResponseWriter, r *http.Request, code string) {
+func (s *Server) writeVersionError(w http.ResponseWriter, r *http.Request, message string, code models.ErrorCode) {
 	requestID := r.Header.Get("X-Request-ID")
-	s.writeErrorResponse(w, http.StatusBadRequest, code, apiversion.SupportedVersions(), requestID)
+	s.writeError(w, r, http.StatusBadRequest, code, message, apiversion.SupportedVersions(), requestID)
 }
@@ -29,16 +29,19 @@ func TestVersionRequiredForUnversionedPaths(t *testing.T) {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
 
-	var resp models.ErrorResponse
+	var resp models.ProblemDetails
 	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
 		t.Fatalf("decode response: %v", err)
 	}
 
-	if resp.Error != "version_required" {
-		t.Fatalf("expected error code version_required, got %q", resp.Error)
+	if resp.Detail != "version_required" {
+		t.Fatalf("expected error code version_required, got %q", resp.Detail)
 	}
-	if len(resp.Details) == 0 || resp.Details[0] != "v1" {
-		t.Fatalf("expected supported versions list to include v1, got %#v", resp.Details)
+	if resp.ErrorCode != models.ErrorCodeVersionRequired {
+		t.Fatalf("expected code %s, got %q", models.ErrorCodeVersionRequired, resp.ErrorCode)
+	}
+	if len(resp.Errors) == 0 || resp.Errors[0] != "v1" {
+		t.Fatalf("expected supported versions list to include v1, got %#v", resp.Errors)
 	}
 }
 
@@ -53,15 +56,15 @@ func TestUnsupportedVersionReturnsError(t *testing.T) {
 		t.Fatalf("expected status 400, got %d", rec.Code)
 	}
 
-	var resp models.ErrorResponse
+	var resp models.ProblemDetails
 	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
 		t.Fatalf("decode response: %v", err)
 	}
 
-	if resp.Error != "unsupported_version" {
-		t.Fatalf("expected error code unsupported_version, got %q", resp.Error)
+	if resp.Detail != "unsupported_version" {
+		t.Fatalf("expected error code unsupported_version, got %q", resp.Detail)
 	}
-	if len(resp.Details) == 0 || resp.Details[0] != "v1" {
-		t.Fatalf("expected supported versions list to include v1, got %#v", resp.Details)
+	if len(resp.Errors) == 0 || resp.Errors[0] != "v1" {
+		t.Fatalf("expected supported versions list to include v1, got %#v", resp.Errors)
 	}
 }
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/callbackauth"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/providers/webhook"
+)
+
+// maxWorkflowCallbackBytes bounds a callback body, which is read before its signature is checked
+const maxWorkflowCallbackBytes = 1 << 20
+
+// SetWorkflowCallbacks accepts status updates for the webhook provider's executions at
+// /v1/workflow-callbacks. It must be the provider instance the workflow client starts executions with.
+func (s *Server) SetWorkflowCallbacks(provider *webhook.Provider) {
+	s.workflowCallbacks = provider
+}
+
+// SetCallbackAuth sets the verifier that authenticates inbound callbacks; without one they are refused
+func (s *Server) SetCallbackAuth(verifier *callbackauth.Verifier) {
+	s.callbackAuth = verifier
+}
+
+// handleWorkflowCallback applies a status update from an external orchestrator
+// @Summary Report a webhook workflow execution's status
+// @Description Called by external orchestrators running executions started by the webhook workflow provider. Requests are authenticated by the X-Landlord-Signature header rather than an API key: "t=<unix seconds>,v1=<hex HMAC-SHA256 of '<unix seconds>.<body>'>", keyed with one of the secrets of the callback_auth source named by X-Landlord-Source (default "webhook").
+// @Description Signatures older or newer than callback_auth.tolerance are rejected, as is a signature that was already accepted. Once an execution has finished, callbacks reporting another state are rejected.
+// @Tags executions
+// @Accept json
+// @Param X-Landlord-Signature header string true "HMAC signature of the body"
+// @Param X-Landlord-Source header string false "Callback source (default webhook)"
+// @Param body body models.WorkflowCallbackRequest true "Execution status"
+// @Success 204 "Status applied"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 401 {object} models.ErrorResponse "Unknown source, invalid signature or stale timestamp"
+// @Failure 404 {object} models.ErrorResponse "Execution not found"
+// @Failure 409 {object} models.ErrorResponse "Callback replayed or execution already finished"
+// @Failure 503 {object} models.ErrorResponse "Webhook workflow provider or callback authentication not configured"
+// @Router /v1/workflow-callbacks [post]
+func (s *Server) handleWorkflowCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := r.Header.Get("X-Request-ID")
+	if s.workflowCallbacks == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Webhook workflow provider not configured", nil, requestID)
+		return
+	}
+	if s.callbackAuth == nil {
+		s.writeErrorResponse(w, r, http.StatusServiceUnavailable, "Callback authentication not configured", nil, requestID)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWorkflowCallbackBytes+1))
+	if err != nil || len(body) > maxWorkflowCallbackBytes {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Failed to read request body", nil, requestID)
+		return
+	}
+	defer r.Body.Close()
+
+	source := r.Header.Get(callbackauth.SourceHeader)
+	if err := s.callbackAuth.Verify(source, r.Header.Get(callbackauth.SignatureHeader), body); err != nil {
+		s.logger.Warn("rejected workflow callback", zap.Error(err), zap.String("source", source), zap.String("request_id", requestID))
+		if errors.Is(err, callbackauth.ErrReplayed) {
+			s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Callback already received", []string{err.Error()}, requestID)
+			return
+		}
+		s.writeError(w, r, http.StatusUnauthorized, models.ErrorCodeUnauthorized, "Invalid signature", []string{err.Error()}, requestID)
+		return
+	}
+
+	var req models.WorkflowCallbackRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeErrorResponse(w, r, http.StatusBadRequest, "Invalid JSON format", []string{err.Error()}, requestID)
+		return
+	}
+	state := workflow.ExecutionState(req.State)
+	var problems []string
+	if strings.TrimSpace(req.ExecutionID) == "" {
+		problems = append(problems, "execution_id is required")
+	}
+	switch state {
+	case workflow.StatePending, workflow.StateRunning, workflow.StateSucceeded, workflow.StateFailed, workflow.StateTimedOut, workflow.StateCancelled:
+	default:
+		problems = append(problems, "state must be pending, running, succeeded, failed, timed_out or cancelled")
+	}
+	if len(problems) > 0 {
+		s.writeError(w, r, http.StatusBadRequest, models.ErrorCodeInvalidRequest, "Invalid callback", problems, requestID)
+		return
+	}
+
+	callback := &webhook.Callback{
+		ExecutionID: req.ExecutionID,
+		State:       state,
+		Output:      req.Output,
+		Message:     req.Message,
+	}
+	if req.Error != nil {
+		callback.Error = &workflow.ExecutionError{Code: req.Error.Code, Message: req.Error.Message}
+	}
+	if err := s.workflowCallbacks.HandleCallback(ctx, callback); err != nil {
+		switch {
+		case errors.Is(err, workflow.ErrExecutionNotFound):
+			s.writeErrorResponse(w, r, http.StatusNotFound, "Execution not found", nil, requestID)
+		case errors.Is(err, webhook.ErrExecutionFinished):
+			s.writeError(w, r, http.StatusConflict, models.ErrorCodeConflict, "Execution already finished", []string{err.Error()}, requestID)
+		default:
+			s.logger.Error("failed to apply workflow callback", zap.Error(err), zap.String("request_id", requestID))
+			s.writeErrorResponse(w, r, http.StatusInternalServerError, "Failed to apply callback", nil, requestID)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/callbackauth"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/providers/webhook"
+)
+
+func TestWorkflowCallbacks(t *testing.T) {
+	const (
+		secret      = "0123456789abcdef"
+		otherSecret = "fedcba9876543210"
+	)
+	orchestrator := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer orchestrator.Close()
+
+	// API keys are configured, but callbacks are authenticated by their signature
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop(), apiKeys: []apiKey{{key: []byte("operator-key")}}}
+	srv.registerRoutes()
+
+	post := func(source, body, signature string) int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/workflow-callbacks", strings.NewReader(body))
+		if signature != "" {
+			req.Header.Set(callbackauth.SignatureHeader, signature)
+		}
+		if source != "" {
+			req.Header.Set(callbackauth.SourceHeader, source)
+		}
+		rec := httptest.NewRecorder()
+		srv.router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	signed := func(body string) int {
+		return post("", body, callbackauth.Sign([]byte(secret), time.Now(), []byte(body)))
+	}
+
+	if code := signed(`{"execution_id":"wh-1","state":"running"}`); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without the webhook provider, got %d", code)
+	}
+
+	provider, err := webhook.New(config.WebhookConfig{URL: orchestrator.URL, Secret: secret, Timeout: time.Second}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("create provider: %v", err)
+	}
+	srv.SetWorkflowCallbacks(provider)
+	if code := signed(`{"execution_id":"wh-1","state":"running"}`); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 without callback authentication, got %d", code)
+	}
+	srv.SetCallbackAuth(callbackauth.New(time.Minute, []config.CallbackSourceConfig{
+		{Name: callbackauth.DefaultSource, Secrets: []string{secret}},
+		{Name: "airflow", Secrets: []string{otherSecret, secret}},
+	}))
+	started, err := provider.Invoke(context.Background(), "tenant-provisioning", &workflow.ProvisionRequest{TenantID: "acme", Operation: "provision"})
+	if err != nil {
+		t.Fatalf("start execution: %v", err)
+	}
+
+	running := `{"execution_id":"` + started.ExecutionID + `","state":"running","message":"submitted"}`
+	if code := post("", running, ""); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a signature, got %d", code)
+	}
+	if code := post("", running, callbackauth.Sign([]byte(otherSecret), time.Now(), []byte(running))); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with the wrong secret, got %d", code)
+	}
+	if code := post("", running, callbackauth.Sign([]byte(secret), time.Now().Add(-time.Hour), []byte(running))); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with a stale timestamp, got %d", code)
+	}
+	if code := post("argo", running, callbackauth.Sign([]byte(secret), time.Now(), []byte(running))); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 from an unknown source, got %d", code)
+	}
+	for _, body := range []string{
+		`{"state":"running"}`,
+		`{"execution_id":"` + started.ExecutionID + `","state":"done"}`,
+		`not json`,
+	} {
+		if code := signed(body); code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", body, code)
+		}
+	}
+	if code := signed(`{"execution_id":"wh-missing","state":"running"}`); code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown execution, got %d", code)
+	}
+
+	signature := callbackauth.Sign([]byte(secret), time.Now(), []byte(running))
+	if code := post("", running, signature); code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", code)
+	}
+	if code := post("", running, signature); code != http.StatusConflict {
+		t.Errorf("expected 409 for a replayed callback, got %d", code)
+	}
+	// Another source may sign with any of its secrets
+	progress := `{"execution_id":"` + started.ExecutionID + `","state":"running","message":"provisioning"}`
+	if code := post("airflow", progress, callbackauth.Sign([]byte(otherSecret), time.Now(), []byte(progress))); code != http.StatusNoContent {
+		t.Fatalf("expected 204 from the airflow source, got %d", code)
+	}
+	failed := `{"execution_id":"` + started.ExecutionID + `","state":"failed","error":{"code":"DAG_FAILED","message":"step provision-db failed"}}`
+	if code := signed(failed); code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", code)
+	}
+	status, err := provider.GetExecutionStatus(context.Background(), started.ExecutionID)
+	if err != nil {
+		t.Fatalf("get status: %v", err)
+	}
+	if status.State != workflow.StateFailed || status.Error == nil || status.Error.Code != "DAG_FAILED" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if code := signed(`{"execution_id":"` + started.ExecutionID + `","state":"succeeded"}`); code != http.StatusConflict {
+		t.Errorf("expected 409 once the execution finished, got %d", code)
+	}
+}
+
+func TestCallbackMetrics(t *testing.T) {
+	srv := &Server{router: chi.NewRouter(), logger: zap.NewNop()}
+	srv.SetCallbackAuth(callbackauth.New(time.Minute, nil))
+	srv.registerRoutes()
+	_ = srv.callbackAuth.Verify("argo", "", nil)
+
+	rec := httptest.NewRecorder()
+	srv.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), `landlord_callbacks_rejected_total{source="unknown",reason="unknown_source"} 1`) {
+		t.Errorf("expected rejected callbacks in metrics, got:\n%s", rec.Body.String())
+	}
+}
@@ -0,0 +1,168 @@
+// Package approval holds destructive tenant operations until a second principal signs off.
+// Archiving or deleting a protected tenant records a pending approval instead of acting;
+// the operation only reaches the tenant, and so the reconciler, once another principal approves.
+package approval
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// Operation is a tenant operation that can require approval
+type Operation string
+
+const (
+	OperationArchive Operation = "archive"
+	OperationDelete  Operation = "delete"
+)
+
+// Status is where an approval is in its lifecycle
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusRejected Status = "rejected"
+)
+
+var (
+	// ErrNotFound is returned when no approval has the ID
+	ErrNotFound = errors.New("approval not found")
+
+	// ErrNotPending is returned when deciding an approval that was already approved or rejected
+	ErrNotPending = errors.New("approval is not pending")
+
+	// ErrSelfApproval is returned when the principal that requested an operation tries to approve it
+	ErrSelfApproval = errors.New("approval must come from a different principal than the request")
+)
+
+// Approval is a request to run a destructive operation on a tenant
+type Approval struct {
+	ID         uuid.UUID `json:"id"`
+	TenantID   uuid.UUID `json:"tenant_id"`
+	TenantName string    `json:"tenant_name"`
+	Operation  Operation `json:"operation"`
+	Status     Status    `json:"status"`
+
+	// RequestedBy is the principal that asked for the operation
+	RequestedBy string `json:"requested_by"`
+
+	// DecidedBy is the principal that approved or rejected it
+	DecidedBy string `json:"decided_by,omitempty"`
+
+	// Reason is the explanation given when the approval was decided
+	Reason string `json:"reason,omitempty"`
+
+	CreatedAt time.Time  `json:"created_at"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
+// Approve records approval by a principal other than the requester
+func (a *Approval) Approve(by string, now time.Time) error {
+	if a.Status != StatusPending {
+		return ErrNotPending
+	}
+	if by == a.RequestedBy {
+		return ErrSelfApproval
+	}
+	a.decide(StatusApproved, by, "", now)
+	return nil
+}
+
+// Reject records a rejection; the requester may reject their own request to withdraw it
+func (a *Approval) Reject(by, reason string, now time.Time) error {
+	if a.Status != StatusPending {
+		return ErrNotPending
+	}
+	a.decide(StatusRejected, by, reason, now)
+	return nil
+}
+
+func (a *Approval) decide(status Status, by, reason string, now time.Time) {
+	a.Status = status
+	a.DecidedBy = by
+	a.Reason = reason
+	a.DecidedAt = &now
+}
+
+// ListFilters narrows List; zero values match everything
+type ListFilters struct {
+	TenantID *uuid.UUID
+	Status   Status
+}
+
+// Store persists approvals
+type Store interface {
+	// Create persists a new approval, populating ID and CreatedAt
+	Create(ctx context.Context, a *Approval) error
+
+	// Get retrieves an approval by ID
+	// Returns ErrNotFound if not found
+	Get(ctx context.Context, id uuid.UUID) (*Approval, error)
+
+	// List returns the approvals matching filters, newest first
+	List(ctx context.Context, filters ListFilters) ([]*Approval, error)
+
+	// Decide saves the decision on an approval that is still pending in the store
+	// Returns ErrNotFound if not found and ErrNotPending if it was decided meanwhile
+	Decide(ctx context.Context, a *Approval) error
+}
+
+// DefaultProtectedLabel is the label that marks a tenant as protected when none is configured
+const DefaultProtectedLabel = "protected"
+
+// Policy decides which tenant operations need approval
+type Policy struct {
+	operations     map[Operation]bool
+	selector       map[string]string
+	protectedLabel string
+}
+
+// NewPolicy creates a policy from configuration; nil when approvals are disabled
+func NewPolicy(cfg config.ApprovalConfig) *Policy {
+	if !cfg.Enabled {
+		return nil
+	}
+	p := &Policy{
+		operations:     map[Operation]bool{},
+		selector:       cfg.Selector,
+		protectedLabel: cfg.ProtectedLabel,
+	}
+	if p.protectedLabel == "" {
+		p.protectedLabel = DefaultProtectedLabel
+	}
+	operations := cfg.Operations
+	if len(operations) == 0 {
+		operations = []string{string(OperationArchive), string(OperationDelete)}
+	}
+	for _, op := range operations {
+		p.operations[Operation(op)] = true
+	}
+	return p
+}
+
+// Required reports whether op on t must be approved first. A tenant is protected when its
+// protected label is "true" or its labels match the configured selector.
+func (p *Policy) Required(t *tenant.Tenant, op Operation) bool {
+	if p == nil || !p.operations[op] {
+		return false
+	}
+	if t.Labels[p.protectedLabel] == "true" {
+		return true
+	}
+	if len(p.selector) == 0 {
+		return false
+	}
+	for key, value := range p.selector {
+		if t.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package approval
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+func TestPolicyRequired(t *testing.T) {
+	protected := &tenant.Tenant{Labels: map[string]string{"protected": "true"}}
+	prod := &tenant.Tenant{Labels: map[string]string{"env": "prod", "team": "web"}}
+	plain := &tenant.Tenant{Labels: map[string]string{"env": "staging", "protected": "false"}}
+
+	if NewPolicy(config.ApprovalConfig{}).Required(protected, OperationDelete) {
+		t.Fatalf("disabled policy required approval")
+	}
+
+	policy := NewPolicy(config.ApprovalConfig{Enabled: true, Selector: map[string]string{"env": "prod"}})
+	cases := []struct {
+		name   string
+		tenant *tenant.Tenant
+		op     Operation
+		want   bool
+	}{
+		{"protected label archive", protected, OperationArchive, true},
+		{"protected label delete", protected, OperationDelete, true},
+		{"selector match", prod, OperationDelete, true},
+		{"unprotected", plain, OperationDelete, false},
+	}
+	for _, tc := range cases {
+		if got := policy.Required(tc.tenant, tc.op); got != tc.want {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+
+	deleteOnly := NewPolicy(config.ApprovalConfig{Enabled: true, Operations: []string{"delete"}, ProtectedLabel: "landlord/protected"})
+	guarded := &tenant.Tenant{Labels: map[string]string{"landlord/protected": "true"}}
+	if deleteOnly.Required(guarded, OperationArchive) || !deleteOnly.Required(guarded, OperationDelete) {
+		t.Fatalf("expected only delete to require approval")
+	}
+	if deleteOnly.Required(protected, OperationDelete) {
+		t.Fatalf("the default label protected a tenant under a custom label")
+	}
+}
+
+func TestApprovalDecisions(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	a := &Approval{Status: StatusPending, RequestedBy: "alice"}
+	if err := a.Approve("alice", now); !errors.Is(err, ErrSelfApproval) {
+		t.Fatalf("expected self-approval to fail, got %v", err)
+	}
+	if err := a.Approve("bob", now); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	if a.Status != StatusApproved || a.DecidedBy != "bob" || a.DecidedAt == nil || !a.DecidedAt.Equal(now) {
+		t.Fatalf("unexpected approval: %+v", a)
+	}
+	if err := a.Reject("carol", "", now); !errors.Is(err, ErrNotPending) {
+		t.Fatalf("expected a decided approval to stay decided, got %v", err)
+	}
+
+	withdrawn := &Approval{Status: StatusPending, RequestedBy: "alice"}
+	if err := withdrawn.Reject("alice", "wrong tenant", now); err != nil {
+		t.Fatalf("requester withdraw: %v", err)
+	}
+	if withdrawn.Status != StatusRejected || withdrawn.Reason != "wrong tenant" {
+		t.Fatalf("unexpected rejection: %+v", withdrawn)
+	}
+}
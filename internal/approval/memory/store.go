@@ -0,0 +1,84 @@
+// Package memory provides an in-memory approval store for tests and local harnesses.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/approval"
+)
+
+// Store implements approval.Store in memory.
+// Approvals are copied on the way in and out, so callers never share state with the store.
+type Store struct {
+	mu        sync.RWMutex
+	approvals map[uuid.UUID]approval.Approval
+}
+
+var _ approval.Store = (*Store)(nil)
+
+// New creates an empty in-memory store
+func New() *Store {
+	return &Store{approvals: make(map[uuid.UUID]approval.Approval)}
+}
+
+func (s *Store) Create(ctx context.Context, a *approval.Approval) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a.ID = uuid.New()
+	a.CreatedAt = time.Now()
+	s.approvals[a.ID] = *a
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, id uuid.UUID) (*approval.Approval, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a, ok := s.approvals[id]
+	if !ok {
+		return nil, approval.ErrNotFound
+	}
+	return &a, nil
+}
+
+func (s *Store) List(ctx context.Context, filters approval.ListFilters) ([]*approval.Approval, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	approvals := []*approval.Approval{}
+	for _, a := range s.approvals {
+		if filters.TenantID != nil && a.TenantID != *filters.TenantID {
+			continue
+		}
+		if filters.Status != "" && a.Status != filters.Status {
+			continue
+		}
+		a := a
+		approvals = append(approvals, &a)
+	}
+	sort.Slice(approvals, func(i, j int) bool {
+		return approvals[i].CreatedAt.After(approvals[j].CreatedAt)
+	})
+	return approvals, nil
+}
+
+func (s *Store) Decide(ctx context.Context, a *approval.Approval) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.approvals[a.ID]
+	if !ok {
+		return approval.ErrNotFound
+	}
+	if stored.Status != approval.StatusPending {
+		return approval.ErrNotPending
+	}
+	s.approvals[a.ID] = *a
+	return nil
+}
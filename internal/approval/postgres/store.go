@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/approval"
+)
+
+// Store implements approval.Store for PostgreSQL
+type Store struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+var _ approval.Store = (*Store)(nil)
+
+// New creates a PostgreSQL approval store
+// Accepts interface{} to satisfy provider abstraction, type asserts to *pgxpool.Pool
+func New(pool interface{}, logger *zap.Logger) (*Store, error) {
+	pgPool, ok := pool.(*pgxpool.Pool)
+	if !ok {
+		return nil, fmt.Errorf("expected *pgxpool.Pool, got %T", pool)
+	}
+	return &Store{
+		pool:   pgPool,
+		logger: logger.With(zap.String("component", "approval-postgres-store")),
+	}, nil
+}
+
+const createApprovalQuery = `
+INSERT INTO approvals (id, tenant_id, tenant_name, operation, status, requested_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING created_at
+`
+
+func (s *Store) Create(ctx context.Context, a *approval.Approval) error {
+	a.ID = uuid.New()
+	if err := s.pool.QueryRow(ctx, createApprovalQuery,
+		a.ID,
+		a.TenantID,
+		a.TenantName,
+		a.Operation,
+		a.Status,
+		a.RequestedBy,
+	).Scan(&a.CreatedAt); err != nil {
+		return fmt.Errorf("create approval: %w", err)
+	}
+
+	s.logger.Info("approval requested",
+		zap.String("id", a.ID.String()),
+		zap.String("tenant_id", a.TenantID.String()),
+		zap.String("operation", string(a.Operation)))
+	return nil
+}
+
+const approvalColumns = `id, tenant_id, tenant_name, operation, status, requested_by, COALESCE(decided_by, ''), COALESCE(reason, ''), created_at, decided_at`
+
+func (s *Store) Get(ctx context.Context, id uuid.UUID) (*approval.Approval, error) {
+	a, err := scanApproval(s.pool.QueryRow(ctx, `SELECT `+approvalColumns+` FROM approvals WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, approval.ErrNotFound
+		}
+		return nil, fmt.Errorf("get approval: %w", err)
+	}
+	return a, nil
+}
+
+func (s *Store) List(ctx context.Context, filters approval.ListFilters) ([]*approval.Approval, error) {
+	query := `SELECT ` + approvalColumns + ` FROM approvals WHERE 1=1`
+	var args []interface{}
+	if filters.TenantID != nil {
+		args = append(args, *filters.TenantID)
+		query += fmt.Sprintf(" AND tenant_id = $%d", len(args))
+	}
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	query += " ORDER BY created_at DESC"
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list approvals: %w", err)
+	}
+	defer rows.Close()
+
+	approvals := []*approval.Approval{}
+	for rows.Next() {
+		a, err := scanApproval(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan approval: %w", err)
+		}
+		approvals = append(approvals, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate approvals: %w", err)
+	}
+	return approvals, nil
+}
+
+const decideApprovalQuery = `
+UPDATE approvals
+SET status = $2, decided_by = $3, reason = $4, decided_at = $5
+WHERE id = $1 AND status = 'pending'
+`
+
+func (s *Store) Decide(ctx context.Context, a *approval.Approval) error {
+	tag, err := s.pool.Exec(ctx, decideApprovalQuery, a.ID, a.Status, a.DecidedBy, a.Reason, a.DecidedAt)
+	if err != nil {
+		return fmt.Errorf("decide approval: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := s.Get(ctx, a.ID); err != nil {
+			return err
+		}
+		return approval.ErrNotPending
+	}
+
+	s.logger.Info("approval decided",
+		zap.String("id", a.ID.String()),
+		zap.String("status", string(a.Status)),
+		zap.String("decided_by", a.DecidedBy))
+	return nil
+}
+
+func scanApproval(row pgx.Row) (*approval.Approval, error) {
+	a := &approval.Approval{}
+	if err := row.Scan(&a.ID, &a.TenantID, &a.TenantName, &a.Operation, &a.Status, &a.RequestedBy, &a.DecidedBy, &a.Reason, &a.CreatedAt, &a.DecidedAt); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
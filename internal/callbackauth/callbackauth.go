@@ -0,0 +1,229 @@
+// Package callbackauth authenticates inbound callbacks. Each source that may send callbacks has its
+// own secrets; a callback names its source and carries an HMAC-SHA256 signature over a timestamp and
+// its body. Callbacks whose timestamp is outside the tolerance are rejected, and so is a signature
+// seen before, so a captured callback cannot be replayed.
+package callbackauth
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+const (
+	// SignatureHeader carries a request's signature: "t=<unix seconds>,v1=<hex>", where v1 is the
+	// HMAC-SHA256 of "<unix seconds>.<body>"
+	SignatureHeader = "X-Landlord-Signature"
+
+	// SourceHeader names the source a callback comes from; DefaultSource is assumed without it
+	SourceHeader = "X-Landlord-Source"
+
+	// DefaultSource is the webhook workflow provider's orchestrator
+	DefaultSource = "webhook"
+)
+
+// DefaultTolerance applies when the configured tolerance is zero
+const DefaultTolerance = 5 * time.Minute
+
+var (
+	// ErrUnknownSource is returned for a callback from a source with no configured secrets
+	ErrUnknownSource = errors.New("unknown callback source")
+
+	// ErrInvalidSignature is returned when a callback's signature is missing, malformed or wrong
+	ErrInvalidSignature = errors.New("invalid callback signature")
+
+	// ErrStaleTimestamp is returned when a callback's signature timestamp is outside the tolerance
+	ErrStaleTimestamp = errors.New("callback timestamp outside tolerance")
+
+	// ErrReplayed is returned when a callback's signature was already accepted
+	ErrReplayed = errors.New("callback already received")
+)
+
+// Reasons callbacks are rejected, as reported in metrics
+const (
+	reasonUnknownSource    = "unknown_source"
+	reasonInvalidSignature = "invalid_signature"
+	reasonStale            = "stale"
+	reasonReplayed         = "replayed"
+)
+
+// Sign returns the SignatureHeader value for body sent at timestamp
+func Sign(secret []byte, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", unix, hex.EncodeToString(mac(secret, unix, body)))
+}
+
+// Verifier checks the signatures of inbound callbacks
+type Verifier struct {
+	tolerance time.Duration
+	sources   map[string][][]byte
+	now       func() time.Time
+
+	mu sync.Mutex
+	// seen holds accepted signatures until their timestamp leaves the tolerance
+	seen      map[string]time.Time
+	nextPrune time.Time
+	accepted  map[string]uint64
+	rejected  map[[2]string]uint64
+}
+
+// New creates a verifier for sources. A zero tolerance uses DefaultTolerance.
+func New(tolerance time.Duration, sources []config.CallbackSourceConfig) *Verifier {
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+	v := &Verifier{
+		tolerance: tolerance,
+		sources:   make(map[string][][]byte, len(sources)),
+		now:       time.Now,
+		seen:      make(map[string]time.Time),
+		accepted:  make(map[string]uint64),
+		rejected:  make(map[[2]string]uint64),
+	}
+	for _, source := range sources {
+		for _, secret := range source.Secrets {
+			v.sources[source.Name] = append(v.sources[source.Name], []byte(secret))
+		}
+	}
+	return v
+}
+
+// Verify authenticates a callback from source, which is DefaultSource when empty, whose
+// SignatureHeader is header. It returns one of the package's errors when it is rejected.
+func (v *Verifier) Verify(source, header string, body []byte) error {
+	if source == "" {
+		source = DefaultSource
+	}
+	now := v.now()
+
+	secrets, ok := v.sources[source]
+	if !ok {
+		// Unknown names are counted together, so callers cannot add metric series
+		v.reject("unknown", reasonUnknownSource)
+		return fmt.Errorf("%w: %q", ErrUnknownSource, source)
+	}
+
+	unix, signatures := parseSignature(header)
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		v.reject(source, reasonInvalidSignature)
+		return fmt.Errorf("%w: expected t=<timestamp>,v1=<signature>", ErrInvalidSignature)
+	}
+	signedAt := time.Unix(seconds, 0)
+	if age := now.Sub(signedAt); age > v.tolerance || age < -v.tolerance {
+		v.reject(source, reasonStale)
+		return fmt.Errorf("%w: signed at %s, tolerance %s", ErrStaleTimestamp, signedAt.UTC().Format(time.RFC3339), v.tolerance)
+	}
+
+	var matched []byte
+	for _, secret := range secrets {
+		expected := mac(secret, unix, body)
+		for _, signature := range signatures {
+			if hmac.Equal(signature, expected) {
+				matched = signature
+			}
+		}
+	}
+	if matched == nil {
+		v.reject(source, reasonInvalidSignature)
+		return fmt.Errorf("%w: signature does not match", ErrInvalidSignature)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.prune(now)
+	key := source + ":" + hex.EncodeToString(matched)
+	if _, replayed := v.seen[key]; replayed {
+		v.rejected[[2]string{source, reasonReplayed}]++
+		return ErrReplayed
+	}
+	v.seen[key] = signedAt.Add(v.tolerance)
+	v.accepted[source]++
+	return nil
+}
+
+// WriteMetrics writes callback counts in the Prometheus text format
+func (v *Verifier) WriteMetrics(w io.Writer) error {
+	v.mu.Lock()
+	accepted := make([]string, 0, len(v.accepted))
+	for source, count := range v.accepted {
+		accepted = append(accepted, fmt.Sprintf("landlord_callbacks_accepted_total{source=%q} %d\n", source, count))
+	}
+	rejected := make([]string, 0, len(v.rejected))
+	for key, count := range v.rejected {
+		rejected = append(rejected, fmt.Sprintf("landlord_callbacks_rejected_total{source=%q,reason=%q} %d\n", key[0], key[1], count))
+	}
+	v.mu.Unlock()
+	sort.Strings(accepted)
+	sort.Strings(rejected)
+
+	out := bufio.NewWriter(w)
+	fmt.Fprint(out, "# HELP landlord_callbacks_accepted_total Inbound callbacks whose signature was verified\n# TYPE landlord_callbacks_accepted_total counter\n")
+	for _, line := range accepted {
+		fmt.Fprint(out, line)
+	}
+	fmt.Fprint(out, "# HELP landlord_callbacks_rejected_total Inbound callbacks rejected, by reason\n# TYPE landlord_callbacks_rejected_total counter\n")
+	for _, line := range rejected {
+		fmt.Fprint(out, line)
+	}
+	return out.Flush()
+}
+
+func (v *Verifier) reject(source, reason string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.rejected[[2]string{source, reason}]++
+}
+
+// prune forgets signatures too old to be accepted again; callers hold v.mu
+func (v *Verifier) prune(now time.Time) {
+	if now.Before(v.nextPrune) {
+		return
+	}
+	for key, expires := range v.seen {
+		if now.After(expires) {
+			delete(v.seen, key)
+		}
+	}
+	v.nextPrune = now.Add(v.tolerance)
+}
+
+// parseSignature splits a SignatureHeader value into its timestamp and signatures
+func parseSignature(header string) (string, [][]byte) {
+	var unix string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			if decoded, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, decoded)
+			}
+		}
+	}
+	return unix, signatures
+}
+
+func mac(secret []byte, unix string, body []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(unix))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,114 @@
+package callbackauth
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+const (
+	oldSecret = "0123456789abcdef"
+	newSecret = "fedcba9876543210"
+)
+
+func newVerifier(now time.Time) *Verifier {
+	v := New(5*time.Minute, []config.CallbackSourceConfig{
+		{Name: DefaultSource, Secrets: []string{oldSecret}},
+		{Name: "airflow", Secrets: []string{newSecret, oldSecret}},
+	})
+	v.now = func() time.Time { return now }
+	return v
+}
+
+func TestVerifyRejectsBadCallbacks(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	v := newVerifier(now)
+	body := []byte(`{"execution_id":"wh-1","state":"running"}`)
+	header := Sign([]byte(oldSecret), now, body)
+
+	for name, tc := range map[string]struct {
+		source, header string
+		body           []byte
+		want           error
+	}{
+		"unknown source": {"argo", header, body, ErrUnknownSource},
+		"missing":        {"", "", body, ErrInvalidSignature},
+		"malformed":      {"", "t=abc,v1=zz", body, ErrInvalidSignature},
+		"tampered body":  {"", header, []byte(`{"execution_id":"wh-1","state":"succeeded"}`), ErrInvalidSignature},
+		"wrong secret":   {"", Sign([]byte(newSecret), now, body), body, ErrInvalidSignature},
+		"stale":          {"", Sign([]byte(oldSecret), now.Add(-10*time.Minute), body), body, ErrStaleTimestamp},
+		"future":         {"", Sign([]byte(oldSecret), now.Add(10*time.Minute), body), body, ErrStaleTimestamp},
+	} {
+		if err := v.Verify(tc.source, tc.header, tc.body); !errors.Is(err, tc.want) {
+			t.Errorf("%s: expected %v, got %v", name, tc.want, err)
+		}
+	}
+}
+
+func TestVerifyRejectsReplays(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	v := newVerifier(now)
+	body := []byte(`{"execution_id":"wh-1","state":"running"}`)
+	header := Sign([]byte(oldSecret), now.Add(-time.Minute), body)
+
+	if err := v.Verify("", header, body); err != nil {
+		t.Fatalf("expected the first callback to be accepted: %v", err)
+	}
+	if err := v.Verify("", header, body); !errors.Is(err, ErrReplayed) {
+		t.Fatalf("expected the replayed callback to be rejected, got %v", err)
+	}
+
+	// Once the timestamp is outside the tolerance, the replay cache can forget the signature
+	v.now = func() time.Time { return now.Add(10 * time.Minute) }
+	if err := v.Verify("", header, body); !errors.Is(err, ErrStaleTimestamp) {
+		t.Fatalf("expected a stale timestamp, got %v", err)
+	}
+	fresh := Sign([]byte(oldSecret), now.Add(10*time.Minute), body)
+	if err := v.Verify("", fresh, body); err != nil {
+		t.Fatalf("expected a fresh callback to be accepted: %v", err)
+	}
+	if len(v.seen) != 1 {
+		t.Errorf("expected expired signatures to be pruned, have %d", len(v.seen))
+	}
+}
+
+func TestVerifyAcceptsRotatedSecrets(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	v := newVerifier(now)
+	for _, secret := range []string{oldSecret, newSecret} {
+		body := []byte(`{"execution_id":"wh-1","secret":"` + secret + `"}`)
+		if err := v.Verify("airflow", Sign([]byte(secret), now, body), body); err != nil {
+			t.Errorf("expected a callback signed with %s to be accepted: %v", secret, err)
+		}
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	v := newVerifier(now)
+	body := []byte(`{}`)
+	header := Sign([]byte(oldSecret), now, body)
+	_ = v.Verify("", header, body)
+	_ = v.Verify("", header, body)
+	_ = v.Verify("argo", header, body)
+	_ = v.Verify("airflow", "", body)
+
+	var buf bytes.Buffer
+	if err := v.WriteMetrics(&buf); err != nil {
+		t.Fatalf("write metrics: %v", err)
+	}
+	for _, want := range []string{
+		`landlord_callbacks_accepted_total{source="webhook"} 1`,
+		`landlord_callbacks_rejected_total{source="webhook",reason="replayed"} 1`,
+		`landlord_callbacks_rejected_total{source="unknown",reason="unknown_source"} 1`,
+		`landlord_callbacks_rejected_total{source="airflow",reason="invalid_signature"} 1`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, buf.String())
+		}
+	}
+}
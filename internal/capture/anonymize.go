@@ -0,0 +1,239 @@
+package capture
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/redact"
+	"github.com/jaxxstorm/landlord/internal/retention"
+)
+
+// Kinds of names replaced with pseudonyms
+const (
+	KindTenant       = "tenant"
+	KindOrganization = "org"
+	KindProject      = "project"
+	KindActor        = "actor"
+)
+
+// fieldManagerHeader names the actor making a change
+const fieldManagerHeader = "X-Field-Manager"
+
+// RecordedHeaders are the request headers that change how the API handles a request. Credentials
+// and client details are never recorded.
+var RecordedHeaders = []string{"Content-Type", fieldManagerHeader}
+
+// secretQueryKey matches query parameters whose values are secrets
+var secretQueryKey = regexp.MustCompile(`(?i)(password|secret|token|key|credential|signature)`)
+
+// Anonymizer strips credentials and secrets from records and replaces the names of tenants,
+// organizations, projects and actors with pseudonyms. A pseudonym is an HMAC of the name, so a
+// name gets the same pseudonym in every record anonymized with the same key, and replayed requests
+// refer to each other as the originals did.
+//
+// Names are learned from the places that define them: route parameters, the name of a created
+// tenant, organization or project, the name in a tenant response, and the field manager. Once
+// learned, a name is replaced wherever it appears as a whole path segment, query value or JSON
+// string.
+type Anonymizer struct {
+	key []byte
+
+	mu    sync.RWMutex
+	names map[string]string
+}
+
+// NewAnonymizer returns an anonymizer keyed by key, or by a random key when key is empty
+func NewAnonymizer(key string) (*Anonymizer, error) {
+	a := &Anonymizer{key: []byte(key), names: make(map[string]string)}
+	if key == "" {
+		a.key = make([]byte, 32)
+		if _, err := rand.Read(a.key); err != nil {
+			return nil, fmt.Errorf("generate pseudonym key: %w", err)
+		}
+	}
+	return a, nil
+}
+
+// Pseudonym returns the pseudonym of a name of the given kind
+func (a *Anonymizer) Pseudonym(kind, name string) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(kind + "\x00" + name))
+	return kind + "-" + hex.EncodeToString(mac.Sum(nil)[:5])
+}
+
+// Anonymize rewrites rec in place. params are the route parameters of the request.
+func (a *Anonymizer) Anonymize(rec *Record, params map[string]string) {
+	a.learn(rec, params)
+
+	rec.Path = a.rewritePath(rec.Path)
+	rec.Query = a.rewriteQuery(rec.Query)
+	for name, value := range rec.Headers {
+		if name == fieldManagerHeader {
+			rec.Headers[name] = a.replace(value)
+		}
+	}
+	rec.RequestBody, rec.RequestText = a.rewriteBody(rec.RequestBody, rec.RequestText)
+	rec.ResponseBody, _ = a.rewriteBody(rec.ResponseBody, "")
+}
+
+// learn records the names rec defines
+func (a *Anonymizer) learn(rec *Record, params map[string]string) {
+	isTenantRoute := strings.Contains(rec.Route, "/tenants/{id}")
+	if id := params["id"]; id != "" && isTenantRoute && !isUUID(id) {
+		a.add(KindTenant, id)
+	}
+	if org := params["org"]; org != "" {
+		a.add(KindOrganization, org)
+	}
+	if project := params["project"]; project != "" {
+		a.add(KindProject, project)
+	}
+	if manager := rec.Headers[fieldManagerHeader]; manager != "" {
+		a.add(KindActor, manager)
+	}
+	if query, err := url.ParseQuery(rec.Query); err == nil && query.Get("field_manager") != "" {
+		a.add(KindActor, query.Get("field_manager"))
+	}
+
+	created := map[string]string{
+		"/v1/tenants":                                KindTenant,
+		"/v1/organizations":                          KindOrganization,
+		"/v1/organizations/{org}/projects":           KindProject,
+		"/v1/organizations/{org}/projects/{project}": KindProject,
+	}
+	if kind, ok := created[rec.Route]; ok {
+		if name := topLevelString(rec.RequestBody, "name"); name != "" {
+			a.add(kind, name)
+		}
+	}
+	if rec.Route == "/v1/tenants" || isTenantRoute {
+		if name := topLevelString(rec.ResponseBody, "name"); name != "" && !isUUID(name) {
+			a.add(KindTenant, name)
+		}
+	}
+}
+
+func (a *Anonymizer) add(kind, name string) {
+	a.mu.RLock()
+	_, known := a.names[name]
+	a.mu.RUnlock()
+	if known {
+		return
+	}
+	pseudonym := a.Pseudonym(kind, name)
+	a.mu.Lock()
+	a.names[name] = pseudonym
+	a.mu.Unlock()
+}
+
+// replace returns the pseudonym of value if it is a known name, and value otherwise
+func (a *Anonymizer) replace(value string) string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if pseudonym, ok := a.names[value]; ok {
+		return pseudonym
+	}
+	return value
+}
+
+func (a *Anonymizer) rewritePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			if replaced := a.replace(unescaped); replaced != unescaped {
+				segments[i] = url.PathEscape(replaced)
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func (a *Anonymizer) rewriteQuery(query string) string {
+	if query == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		redacted, _ := redact.Line(query)
+		return redacted
+	}
+	for key, list := range values {
+		for i, value := range list {
+			if secretQueryKey.MatchString(key) && value != "" {
+				list[i] = retention.Redacted
+				continue
+			}
+			list[i] = a.replace(value)
+		}
+	}
+	return values.Encode()
+}
+
+// rewriteBody redacts and pseudonymizes a JSON body, or a text body when it is not JSON
+func (a *Anonymizer) rewriteBody(body json.RawMessage, text string) (json.RawMessage, string) {
+	if len(body) == 0 {
+		if text != "" {
+			text, _ = redact.Line(text)
+		}
+		return nil, text
+	}
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		text, _ = redact.Line(string(body))
+		return nil, text
+	}
+	redact.JSON(doc)
+	doc = a.replaceJSON(doc)
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, ""
+	}
+	return json.RawMessage(bytes.TrimRight(buf.Bytes(), "\n")), ""
+}
+
+func (a *Anonymizer) replaceJSON(doc interface{}) interface{} {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			v[key] = a.replaceJSON(value)
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = a.replaceJSON(value)
+		}
+	case string:
+		return a.replace(v)
+	}
+	return doc
+}
+
+func topLevelString(body json.RawMessage, key string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return ""
+	}
+	value, _ := doc[key].(string)
+	return value
+}
+
+func isUUID(value string) bool {
+	_, err := uuid.Parse(value)
+	return err == nil
+}
@@ -0,0 +1,205 @@
+// Package capture records the API's mutating requests and their responses, anonymized, so a
+// production sequence can be replayed against a test instance to reproduce a reconciler bug.
+// Each API process appends records to its own JSON Lines file; Replayer re-issues them in order.
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+// FileExtension is the extension of capture files
+const FileExtension = ".jsonl"
+
+// Record is one captured request and its response
+type Record struct {
+	// Seq orders the records of one capture file
+	Seq  int64     `json:"seq"`
+	Time time.Time `json:"time"`
+
+	Method string `json:"method"`
+
+	// Route is the matched route pattern, such as /v1/tenants/{id}
+	Route string `json:"route"`
+
+	// Path is the anonymized request path
+	Path  string `json:"path"`
+	Query string `json:"query,omitempty"`
+
+	// Headers are the request headers that change how the API handles a request
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// RequestBody is the anonymized JSON request body. RequestText holds a body that is not JSON.
+	RequestBody json.RawMessage `json:"request_body,omitempty"`
+	RequestText string          `json:"request_text,omitempty"`
+
+	Status       int             `json:"status"`
+	ResponseBody json.RawMessage `json:"response_body,omitempty"`
+
+	DurationMs int64 `json:"duration_ms"`
+
+	// Truncated is set when the request body was longer than capture.max_body_bytes. It was not
+	// recorded, so the record cannot be replayed.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Recorder appends records to a capture file. It is safe for concurrent use.
+type Recorder struct {
+	anonymizer   *Anonymizer
+	maxBodyBytes int
+	maxRecords   int
+	logger       *zap.Logger
+
+	mu   sync.Mutex
+	file *os.File
+	path string
+	seq  int64
+}
+
+// NewRecorder creates the capture directory and a capture file in it named for the process
+func NewRecorder(cfg config.CaptureConfig, logger *zap.Logger) (*Recorder, error) {
+	if err := os.MkdirAll(cfg.Directory, 0o700); err != nil {
+		return nil, fmt.Errorf("create capture directory: %w", err)
+	}
+	anonymizer, err := NewAnonymizer(cfg.PseudonymKey)
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("capture-%s-%d%s", time.Now().UTC().Format("20060102T150405Z"), os.Getpid(), FileExtension)
+	path := filepath.Join(cfg.Directory, name)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open capture file: %w", err)
+	}
+
+	logger = logger.With(zap.String("component", "capture"))
+	logger.Warn("capture mode enabled; mutating requests are recorded", zap.String("path", path))
+	return &Recorder{
+		anonymizer:   anonymizer,
+		maxBodyBytes: cfg.MaxBodyBytes,
+		maxRecords:   cfg.MaxRecords,
+		logger:       logger,
+		file:         file,
+		path:         path,
+	}, nil
+}
+
+// MaxBodyBytes is the most of a body the recorder keeps
+func (r *Recorder) MaxBodyBytes() int {
+	return r.maxBodyBytes
+}
+
+// Path is the capture file's path
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Record anonymizes rec and appends it to the capture file. Failures are logged: capture must not
+// fail the request it records.
+func (r *Recorder) Record(rec Record, params map[string]string) {
+	r.anonymizer.Anonymize(&rec, params)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return
+	}
+	if r.seq >= int64(r.maxRecords) {
+		r.logger.Warn("capture stopped after reaching max_records", zap.Int("max_records", r.maxRecords))
+		r.closeLocked()
+		return
+	}
+	r.seq++
+	rec.Seq = r.seq
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		r.logger.Error("failed to encode capture record", zap.Error(err))
+		return
+	}
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		r.logger.Error("failed to write capture record", zap.Error(err))
+	}
+}
+
+// Close closes the capture file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closeLocked()
+}
+
+func (r *Recorder) closeLocked() error {
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// ReadDir reads the records of every capture file in dir, ordered by time. Records from different
+// files, such as those of several API replicas, are interleaved; records with the same time keep
+// their file's order.
+func ReadDir(dir string) ([]Record, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+FileExtension))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no %s capture files in %s", FileExtension, dir)
+	}
+	sort.Strings(paths)
+
+	var records []Record
+	for _, path := range paths {
+		fileRecords, err := ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, fileRecords...)
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records, nil
+}
+
+// ReadFile reads the records of one capture file, in file order
+func ReadFile(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return records, nil
+}
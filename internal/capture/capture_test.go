@@ -0,0 +1,207 @@
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+func TestAnonymize(t *testing.T) {
+	a, err := NewAnonymizer("test-key")
+	require.NoError(t, err)
+
+	create := Record{
+		Method:       http.MethodPost,
+		Route:        "/v1/tenants",
+		Path:         "/v1/tenants",
+		Query:        "field_manager=alice&token=abc123",
+		Headers:      map[string]string{"Content-Type": "application/json"},
+		RequestBody:  json.RawMessage(`{"name":"acme","image":"nginx","compute_config":{"env":{"DATABASE_PASSWORD":"hunter2","URL":"postgres://app:s3cret@db/app"}}}`),
+		Status:       http.StatusCreated,
+		ResponseBody: json.RawMessage(`{"id":"3f2b8a62-7c1e-4c59-9f3a-0c2f7d1b9e11","name":"acme","status":"requested"}`),
+	}
+	a.Anonymize(&create, nil)
+
+	tenant := a.Pseudonym(KindTenant, "acme")
+	actor := a.Pseudonym(KindActor, "alice")
+	assert.Regexp(t, `^tenant-[0-9a-f]{10}$`, tenant)
+	assert.Contains(t, create.Query, "field_manager="+actor)
+	assert.Contains(t, create.Query, "token=%3Credacted%3E")
+	assert.NotContains(t, string(create.RequestBody), "acme")
+	assert.NotContains(t, string(create.RequestBody), "hunter2")
+	assert.NotContains(t, string(create.RequestBody), "s3cret")
+	assert.Contains(t, string(create.RequestBody), `"name":"`+tenant+`"`)
+	assert.Contains(t, string(create.RequestBody), `"image":"nginx"`)
+	assert.Contains(t, string(create.ResponseBody), `"id":"3f2b8a62-7c1e-4c59-9f3a-0c2f7d1b9e11"`)
+	assert.Contains(t, string(create.ResponseBody), `"name":"`+tenant+`"`)
+
+	// A name learned once is replaced in later records, including in paths
+	update := Record{
+		Method:      http.MethodPut,
+		Route:       "/v1/tenants/{id}",
+		Path:        "/v1/tenants/acme",
+		Headers:     map[string]string{"X-Field-Manager": "alice"},
+		RequestBody: json.RawMessage(`{"image":"nginx:2"}`),
+	}
+	a.Anonymize(&update, map[string]string{"id": "acme"})
+	assert.Equal(t, "/v1/tenants/"+tenant, update.Path)
+	assert.Equal(t, actor, update.Headers["X-Field-Manager"])
+
+	// Organizations and projects are learned from route parameters
+	project := Record{
+		Method: http.MethodDelete,
+		Route:  "/v1/organizations/{org}/projects/{project}",
+		Path:   "/v1/organizations/globex/projects/payments",
+	}
+	a.Anonymize(&project, map[string]string{"org": "globex", "project": "payments"})
+	assert.Equal(t, "/v1/organizations/"+a.Pseudonym(KindOrganization, "globex")+"/projects/"+a.Pseudonym(KindProject, "payments"), project.Path)
+
+	// Text bodies are redacted
+	text := Record{Method: http.MethodPost, Route: "/v1/tenants", Path: "/v1/tenants", RequestText: "password=hunter2"}
+	a.Anonymize(&text, nil)
+	assert.NotContains(t, text.RequestText, "hunter2")
+}
+
+func TestPseudonymsAreStableForAKey(t *testing.T) {
+	a, err := NewAnonymizer("key")
+	require.NoError(t, err)
+	b, err := NewAnonymizer("key")
+	require.NoError(t, err)
+	c, err := NewAnonymizer("")
+	require.NoError(t, err)
+
+	assert.Equal(t, a.Pseudonym(KindTenant, "acme"), b.Pseudonym(KindTenant, "acme"))
+	assert.NotEqual(t, a.Pseudonym(KindTenant, "acme"), a.Pseudonym(KindProject, "acme"))
+	assert.NotEqual(t, a.Pseudonym(KindTenant, "acme"), c.Pseudonym(KindTenant, "acme"))
+}
+
+func TestRecorder(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "capture")
+	rec, err := NewRecorder(config.CaptureConfig{Directory: dir, MaxBodyBytes: 1024, MaxRecords: 2, PseudonymKey: "k"}, zap.NewNop())
+	require.NoError(t, err)
+
+	info, err := os.Stat(rec.Path())
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec.Record(Record{Time: time.Now(), Method: http.MethodDelete, Route: "/v1/tenants/{id}", Path: "/v1/tenants/acme", Status: http.StatusAccepted}, map[string]string{"id": "acme"})
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, rec.Close())
+
+	records, err := ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, records, 2, "capture stops at max_records")
+	assert.ElementsMatch(t, []int64{1, 2}, []int64{records[0].Seq, records[1].Seq})
+	for _, record := range records {
+		assert.NotContains(t, record.Path, "acme")
+	}
+}
+
+func TestReadDirInterleavesFilesByTime(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	write := func(name string, records ...Record) {
+		var lines []string
+		for _, record := range records {
+			line, err := json.Marshal(record)
+			require.NoError(t, err)
+			lines = append(lines, string(line))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+	}
+	write("capture-a.jsonl", Record{Seq: 1, Time: base, Path: "/a1"}, Record{Seq: 2, Time: base.Add(2 * time.Second), Path: "/a2"})
+	write("capture-b.jsonl", Record{Seq: 1, Time: base.Add(time.Second), Path: "/b1"})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o600))
+
+	records, err := ReadDir(dir)
+	require.NoError(t, err)
+	var paths []string
+	for _, record := range records {
+		paths = append(paths, record.Path)
+	}
+	assert.Equal(t, []string{"/a1", "/b1", "/a2"}, paths)
+
+	_, err = ReadDir(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestReplayMapsIDs(t *testing.T) {
+	const recordedID = "3f2b8a62-7c1e-4c59-9f3a-0c2f7d1b9e11"
+	const replayedID = "9a0d1c44-2b6f-4e7a-8c3d-5e6f7a8b9c0d"
+
+	var mu sync.Mutex
+	var got []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		got = append(got, r.Method+" "+r.URL.RequestURI()+" "+string(body)+" "+r.Header.Get("Authorization"))
+		mu.Unlock()
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"` + replayedID + `","name":"tenant-1"}`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	records := []Record{
+		{Seq: 1, Method: http.MethodPost, Path: "/v1/tenants", RequestBody: json.RawMessage(`{"name":"tenant-1"}`), Status: http.StatusCreated, ResponseBody: json.RawMessage(`{"id":"` + recordedID + `","name":"tenant-1"}`)},
+		{Seq: 2, Method: http.MethodPut, Path: "/v1/tenants/" + recordedID, Query: "field_manager=actor-1", RequestBody: json.RawMessage(`{"image":"nginx:2"}`), Status: http.StatusOK},
+		{Seq: 3, Method: http.MethodPost, Path: "/v1/tenants", Truncated: true, Status: http.StatusCreated},
+		{Seq: 4, Method: http.MethodDelete, Path: "/v1/tenants/" + recordedID, Status: http.StatusAccepted},
+		{Seq: 5, Method: http.MethodDelete, Path: "/v1/tenants/" + recordedID, Status: http.StatusAccepted},
+	}
+
+	replayer := NewReplayer(ReplayOptions{BaseURL: server.URL + "/v1/", APIKey: "test"})
+	results, err := replayer.Replay(context.Background(), records, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	assert.Equal(t, []string{
+		"POST /v1/tenants {\"name\":\"tenant-1\"} Bearer test",
+		"PUT /v1/tenants/" + replayedID + "?field_manager=actor-1 {\"image\":\"nginx:2\"} Bearer test",
+		"DELETE /v1/tenants/" + replayedID + "  Bearer test",
+		"DELETE /v1/tenants/" + replayedID + "  Bearer test",
+	}, got)
+	assert.Equal(t, Summary{Matched: 2, Mismatched: 2, Skipped: 1}, Summarize(results))
+
+	// StopOnMismatch stops at the first mismatch
+	got = nil
+	replayer = NewReplayer(ReplayOptions{BaseURL: server.URL, StopOnMismatch: true})
+	results, err = replayer.Replay(context.Background(), records, nil)
+	assert.Error(t, err)
+	assert.Len(t, results, 4)
+
+	// A dry run sends nothing
+	got = nil
+	replayer = NewReplayer(ReplayOptions{BaseURL: server.URL, DryRun: true})
+	results, err = replayer.Replay(context.Background(), records, nil)
+	require.NoError(t, err)
+	assert.Len(t, results, 5)
+	assert.Empty(t, got)
+}
@@ -0,0 +1,212 @@
+package capture
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ReplayOptions configures a Replayer
+type ReplayOptions struct {
+	// BaseURL is the test instance's URL, with or without the /v1 suffix
+	BaseURL string
+
+	// APIKey, when set, is sent as a bearer token with every request
+	APIKey string
+
+	// Delay is how long to wait between requests, giving the reconciler time to act on each
+	Delay time.Duration
+
+	// StopOnMismatch stops the replay at the first response whose status differs from the
+	// recorded one
+	StopOnMismatch bool
+
+	// DryRun reports the requests that would be sent without sending them
+	DryRun bool
+
+	HTTPClient *http.Client
+}
+
+// ReplayResult is the outcome of replaying one record
+type ReplayResult struct {
+	Record Record `json:"-"`
+
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	RecordedStatus int `json:"recorded_status"`
+	Status         int `json:"status,omitempty"`
+
+	// Skipped explains why the record was not sent
+	Skipped string `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Matched reports whether the record was sent and its status matched the recorded one
+func (r ReplayResult) Matched() bool {
+	return r.Skipped == "" && r.Error == "" && r.Status == r.RecordedStatus
+}
+
+// Replayer re-issues captured requests in order. The test instance assigns its own IDs, so the
+// replayer maps each ID in a recorded response to the ID in the replayed response and rewrites
+// later requests to use it.
+type Replayer struct {
+	opts    ReplayOptions
+	baseURL string
+	client  *http.Client
+	ids     map[string]string
+}
+
+// NewReplayer returns a replayer for opts
+func NewReplayer(opts ReplayOptions) *Replayer {
+	baseURL := strings.TrimSuffix(strings.TrimRight(opts.BaseURL, "/"), "/v1")
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Replayer{opts: opts, baseURL: baseURL, client: client, ids: make(map[string]string)}
+}
+
+// Replay re-issues records in order, calling report after each. It stops early when ctx is done,
+// or at the first mismatch when StopOnMismatch is set.
+func (r *Replayer) Replay(ctx context.Context, records []Record, report func(ReplayResult)) ([]ReplayResult, error) {
+	results := make([]ReplayResult, 0, len(records))
+	for i, rec := range records {
+		if i > 0 && r.opts.Delay > 0 && !r.opts.DryRun {
+			select {
+			case <-ctx.Done():
+				return results, ctx.Err()
+			case <-time.After(r.opts.Delay):
+			}
+		}
+
+		result := r.replay(ctx, rec)
+		results = append(results, result)
+		if report != nil {
+			report(result)
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		if r.opts.StopOnMismatch && !r.opts.DryRun && result.Skipped == "" && !result.Matched() {
+			return results, fmt.Errorf("record %d (%s %s): got status %d, recorded %d", rec.Seq, result.Method, result.Path, result.Status, result.RecordedStatus)
+		}
+	}
+	return results, nil
+}
+
+func (r *Replayer) replay(ctx context.Context, rec Record) ReplayResult {
+	result := ReplayResult{
+		Record:         rec,
+		Method:         rec.Method,
+		Path:           r.mapIDs(rec.Path),
+		RecordedStatus: rec.Status,
+	}
+	if rec.Truncated {
+		result.Skipped = "request body was not recorded"
+		return result
+	}
+	if r.opts.DryRun {
+		result.Skipped = "dry run"
+		return result
+	}
+
+	target := r.baseURL + result.Path
+	if rec.Query != "" {
+		target += "?" + r.mapIDs(rec.Query)
+	}
+	var body io.Reader
+	switch {
+	case len(rec.RequestBody) > 0:
+		body = strings.NewReader(r.mapIDs(string(rec.RequestBody)))
+	case rec.RequestText != "":
+		body = strings.NewReader(r.mapIDs(rec.RequestText))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, rec.Method, target, body)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	for name, value := range rec.Headers {
+		req.Header.Set(name, value)
+	}
+	if r.opts.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.opts.APIKey)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+	result.Status = resp.StatusCode
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Error = fmt.Sprintf("read response: %v", err)
+		return result
+	}
+	r.learnIDs(rec.ResponseBody, respBody)
+	return result
+}
+
+// learnIDs maps the IDs in a recorded response to those in the replayed response. IDs are the
+// top-level id field and fields ending in _id.
+func (r *Replayer) learnIDs(recorded json.RawMessage, replayed []byte) {
+	var before, after map[string]interface{}
+	if json.Unmarshal(recorded, &before) != nil || json.Unmarshal(replayed, &after) != nil {
+		return
+	}
+	for key, value := range before {
+		if key != "id" && !strings.HasSuffix(key, "_id") {
+			continue
+		}
+		oldID, _ := value.(string)
+		newID, _ := after[key].(string)
+		if oldID != "" && newID != "" && oldID != newID && isUUID(oldID) {
+			r.ids[oldID] = newID
+		}
+	}
+}
+
+// mapIDs replaces recorded IDs in s with the IDs the test instance assigned
+func (r *Replayer) mapIDs(s string) string {
+	for oldID, newID := range r.ids {
+		if strings.Contains(s, oldID) {
+			s = strings.ReplaceAll(s, oldID, newID)
+		}
+	}
+	return s
+}
+
+// Summary counts replay results
+type Summary struct {
+	Matched    int
+	Mismatched int
+	Failed     int
+	Skipped    int
+}
+
+// Summarize counts results by outcome
+func Summarize(results []ReplayResult) Summary {
+	var summary Summary
+	for _, result := range results {
+		switch {
+		case result.Skipped != "":
+			summary.Skipped++
+		case result.Error != "":
+			summary.Failed++
+		case result.Matched():
+			summary.Matched++
+		default:
+			summary.Mismatched++
+		}
+	}
+	return summary
+}
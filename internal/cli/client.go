@@ -7,12 +7,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
+	"strconv"
 	"time"
 	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jaxxstorm/landlord/internal/api/models"
 	"github.com/jaxxstorm/landlord/internal/apiversion"
+	"github.com/jaxxstorm/landlord/internal/version"
 )
 
 type Client struct {
@@ -25,7 +28,8 @@ func NewClient(baseURL string) *Client {
 	return &Client{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 15 * time.Second,
+			Timeout:   15 * time.Second,
+			Transport: version.Transport(version.ComponentCLI, nil),
 		},
 	}
 }
@@ -61,6 +65,38 @@ func (c *Client) CreateTenant(ctx context.Context, req models.CreateTenantReques
 	return &tenant, nil
 }
 
+// ValidateTenant runs the server's create-time checks against a tenant spec without creating it
+func (c *Client) ValidateTenant(ctx context.Context, req models.CreateTenantRequest) (*models.ValidateTenantResponse, error) {
+	url := fmt.Sprintf("%s/tenants:validate", c.baseURL)
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := handleErrorResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var result models.ValidateTenantResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
 func (c *Client) ListTenants(ctx context.Context, includeDeleted bool) (*models.ListTenantsResponse, error) {
 	url := fmt.Sprintf("%s/tenants", c.baseURL)
 	if includeDeleted {
@@ -153,6 +189,37 @@ func (c *Client) ArchiveTenant(ctx context.Context, tenantID string) (*models.Te
 	return &tenant, nil
 }
 
+// RetryTenant asks the server to start a new workflow for a failed tenant
+func (c *Client) RetryTenant(ctx context.Context, tenantID string) (*models.TenantResponse, error) {
+	id, err := c.resolveTenantID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/tenants/%s/retry", c.baseURL, id)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := handleErrorResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var tenant models.TenantResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tenant); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &tenant, nil
+}
+
 func (c *Client) GetTenant(ctx context.Context, tenantID string) (*models.TenantResponse, error) {
 	id, err := c.resolveTenantID(ctx, tenantID)
 	if err != nil {
@@ -183,6 +250,41 @@ func (c *Client) GetTenant(ctx context.Context, tenantID string) (*models.Tenant
 	return &tenant, nil
 }
 
+// WaitForTenantStatus returns the tenant's status once its version differs from version, letting the
+// server wait up to wait for a change. It returns nil if the tenant did not change in that time.
+// A negative version returns the current status straight away. wait must be shorter than the
+// client's 15s timeout.
+func (c *Client) WaitForTenantStatus(ctx context.Context, tenantID string, version int, wait time.Duration) (*models.TenantStatusResponse, error) {
+	url := fmt.Sprintf("%s/tenants/%s/status?wait=%s", c.baseURL, neturl.PathEscape(tenantID), wait)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	if version >= 0 {
+		httpReq.Header.Set("If-None-Match", strconv.Quote(strconv.Itoa(version)))
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, nil
+	}
+	if err := handleErrorResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var status models.TenantStatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &status, nil
+}
+
 func (c *Client) UpdateTenant(ctx context.Context, tenantID string, method string, req models.UpdateTenantRequest) (*models.TenantResponse, error) {
 	id, err := c.resolveTenantID(ctx, tenantID)
 	if err != nil {
@@ -247,6 +349,118 @@ func (c *Client) GetComputeConfigDiscovery(ctx context.Context, provider string)
 	return &discovery, nil
 }
 
+// Doctor runs the server's diagnostics; the checks bound themselves within the client's 15s timeout
+func (c *Client) Doctor(ctx context.Context) (*models.DoctorReportResponse, error) {
+	url := fmt.Sprintf("%s/admin/doctor", c.baseURL)
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := handleErrorResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var report models.DoctorReportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return &report, nil
+}
+
+// GetTenantHistory returns a page of the tenant's state history, newest first; cursor is the
+// previous page's next_cursor, or "" for the first page
+func (c *Client) GetTenantHistory(ctx context.Context, tenantID string, limit int, cursor string) (*models.TenantHistoryResponse, error) {
+	query := neturl.Values{"limit": {strconv.Itoa(limit)}}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	var history models.TenantHistoryResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/tenants/%s/history?%s", c.baseURL, neturl.PathEscape(tenantID), query.Encode()), &history); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+// GetTenantTimeline returns the tenant's latest timeline entries of the given types, newest first
+func (c *Client) GetTenantTimeline(ctx context.Context, tenantID string, types []string, limit int) (*models.TenantTimelineResponse, error) {
+	query := neturl.Values{"limit": {strconv.Itoa(limit)}}
+	if len(types) > 0 {
+		query.Set("types", strings.Join(types, ","))
+	}
+	var timeline models.TenantTimelineResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/tenants/%s/timeline?%s", c.baseURL, neturl.PathEscape(tenantID), query.Encode()), &timeline); err != nil {
+		return nil, err
+	}
+	return &timeline, nil
+}
+
+// GetTenantResolution explains which compute provider the tenant resolves to
+func (c *Client) GetTenantResolution(ctx context.Context, tenantID string) (*models.TenantResolutionResponse, error) {
+	var resolution models.TenantResolutionResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/tenants/%s/resolution", c.baseURL, neturl.PathEscape(tenantID)), &resolution); err != nil {
+		return nil, err
+	}
+	return &resolution, nil
+}
+
+// GetTenantEffectiveConfig returns the configuration the tenant's compute runs with, secret env values redacted
+func (c *Client) GetTenantEffectiveConfig(ctx context.Context, tenantID string) (*models.TenantEffectiveConfigResponse, error) {
+	var effective models.TenantEffectiveConfigResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/tenants/%s/effective-config", c.baseURL, neturl.PathEscape(tenantID)), &effective); err != nil {
+		return nil, err
+	}
+	return &effective, nil
+}
+
+// GetTenantUptime returns the tenant's recent uptime checks
+func (c *Client) GetTenantUptime(ctx context.Context, tenantID string) (*models.TenantUptimeResponse, error) {
+	var uptime models.TenantUptimeResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/tenants/%s/uptime", c.baseURL, neturl.PathEscape(tenantID)), &uptime); err != nil {
+		return nil, err
+	}
+	return &uptime, nil
+}
+
+// GetVersion returns the server's build and the component versions it has seen
+func (c *Client) GetVersion(ctx context.Context) (*models.VersionResponse, error) {
+	var versions models.VersionResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/meta/version", c.baseURL), &versions); err != nil {
+		return nil, err
+	}
+	return &versions, nil
+}
+
+// getJSON decodes the response to a GET of url into out
+func (c *Client) getJSON(ctx context.Context, url string, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := handleErrorResponse(resp); err != nil {
+		return err
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
 func (c *Client) resolveTenantID(ctx context.Context, tenantID string) (string, error) {
 	if _, err := uuid.Parse(tenantID); err == nil {
 		return tenantID, nil
@@ -263,7 +477,7 @@ func (c *Client) resolveTenantID(ctx context.Context, tenantID string) (string,
 		}
 	}
 
-	return "", fmt.Errorf("tenant not found: %s", tenantID)
+	return "", fmt.Errorf("%w: %s", ErrTenantNotFound, tenantID)
 }
 
 func handleErrorResponse(resp *http.Response) error {
@@ -271,19 +485,31 @@ func handleErrorResponse(resp *http.Response) error {
 		return nil
 	}
 
+	apiErr := &APIError{StatusCode: resp.StatusCode}
 	body, _ := io.ReadAll(resp.Body)
 	if len(body) == 0 {
-		return fmt.Errorf("api error: status %d", resp.StatusCode)
+		return apiErr
 	}
 
-	var apiErr models.ErrorResponse
-	if err := json.Unmarshal(body, &apiErr); err != nil {
-		return fmt.Errorf("api error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	// Problem details carry the message in detail; legacy responses carry it in error
+	var decoded struct {
+		models.ProblemDetails
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		apiErr.Message = fmt.Sprintf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return apiErr
 	}
 
-	if apiErr.Error != "" {
-		return fmt.Errorf("api error: %s", apiErr.Error)
+	apiErr.Code = string(decoded.ErrorCode)
+	switch {
+	case decoded.Detail != "":
+		apiErr.Message = decoded.Detail
+	case decoded.Error != "":
+		apiErr.Message = decoded.Error
+	case decoded.Title != "":
+		apiErr.Message = decoded.Title
 	}
 
-	return fmt.Errorf("api error: status %d", resp.StatusCode)
+	return apiErr
 }
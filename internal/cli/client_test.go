@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -37,6 +38,10 @@ func TestClientCreateListDelete(t *testing.T) {
 		case r.Method == http.MethodPost && r.URL.Path == "/v1/tenants/123/archive":
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write([]byte(`{"id":"123","name":"demo","status":"archiving","desired_config":{"image":"nginx:alpine"},"compute_config":{"image":"nginx:alpine"}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/tenants/123/retry":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"id":"123","name":"demo","status":"provisioning","desired_config":{"image":"nginx:alpine"},"compute_config":{"image":"nginx:alpine"}}`))
 		case r.Method == http.MethodGet && r.URL.Path == "/v1/tenants":
 			w.Header().Set("Content-Type", "application/json")
 			_, _ = w.Write([]byte(`{"tenants":[{"id":"123","name":"demo","status":"archived","desired_config":{"image":"nginx:alpine"},"compute_config":{"image":"nginx:alpine"}}],"total":1,"limit":50,"offset":0}`))
@@ -65,6 +70,10 @@ func TestClientCreateListDelete(t *testing.T) {
 		t.Fatalf("archive tenant failed: %v", err)
 	}
 
+	if _, err := client.RetryTenant(context.Background(), "demo"); err != nil {
+		t.Fatalf("retry tenant failed: %v", err)
+	}
+
 	if _, err := client.DeleteTenant(context.Background(), "demo"); err != nil {
 		t.Fatalf("delete tenant failed: %v", err)
 	}
@@ -86,6 +95,41 @@ func TestClientHandlesErrors(t *testing.T) {
 	}
 }
 
+func TestClientHandlesProblemDetails(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"type":"urn:landlord:problem:not-found","title":"Not found","status":404,"detail":"Tenant not found","error_code":"NOT_FOUND"}`))
+	}))
+
+	client := NewClient(server.URL)
+	_, err := client.ListTenants(context.Background(), false)
+	if err == nil || err.Error() != "api error: Tenant not found" {
+		t.Fatalf("expected problem detail in error, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Code != "NOT_FOUND" {
+		t.Fatalf("expected a 404 APIError with its error code, got %#v", err)
+	}
+}
+
+func TestClientReportsUnknownTenantName(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"tenants":[],"total":0,"limit":50,"offset":0}`))
+	}))
+
+	client := NewClient(server.URL)
+	_, err := client.GetTenant(context.Background(), "missing")
+	if !errors.Is(err, ErrTenantNotFound) || err.Error() != "tenant not found: missing" {
+		t.Fatalf("expected ErrTenantNotFound, got %v", err)
+	}
+}
+
 func TestClientGetUpdateTenant(t *testing.T) {
 	t.Parallel()
 
@@ -149,3 +193,28 @@ func TestClientComputeConfigDiscovery(t *testing.T) {
 		t.Fatalf("expected defaults to be set")
 	}
 }
+
+func TestClientValidateTenant(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/tenants:validate" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"valid":false,"violations":[{"field":"name","check":"naming","message":"tenant name \"demo\" already exists"}]}`))
+	}))
+
+	client := NewClient(server.URL)
+	resp, err := client.ValidateTenant(context.Background(), models.CreateTenantRequest{
+		Name:          "demo",
+		ComputeConfig: map[string]interface{}{"image": "nginx:alpine"},
+	})
+	if err != nil {
+		t.Fatalf("validate tenant failed: %v", err)
+	}
+	if resp.Valid || len(resp.Violations) != 1 || resp.Violations[0].Check != "naming" {
+		t.Fatalf("expected one naming violation, got %+v", resp)
+	}
+}
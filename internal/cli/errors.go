@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTenantNotFound is returned when a tenant name matches no tenant
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// APIError is an error response from the Landlord API
+type APIError struct {
+	// StatusCode is the response's HTTP status
+	StatusCode int
+
+	// Code is the problem's error_code, when the server sent one
+	Code string
+
+	// Message explains the error; empty when the response had no body
+	Message string
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("api error: status %d", e.StatusCode)
+	}
+	return "api error: " + e.Message
+}
@@ -0,0 +1,276 @@
+package compute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+// ErrCallbackNotClaimed is returned when a queued callback is completed or failed by a worker
+// whose claim has expired, because another worker may be delivering it
+var ErrCallbackNotClaimed = errors.New("callback is not claimed by this worker")
+
+// QueuedCallback is a compute callback waiting in the outbox for delivery to a workflow provider
+type QueuedCallback struct {
+	// ExecutionID is the compute execution the callback reports; an execution is queued once
+	ExecutionID string `json:"execution_id"`
+
+	// Provider is the workflow provider the callback is delivered to
+	Provider string `json:"provider"`
+
+	// Payload is the callback body
+	Payload *CallbackPayload `json:"payload"`
+
+	// Attempts counts the deliveries claimed so far
+	Attempts int `json:"attempts"`
+
+	// LastError is the error of the last failed delivery
+	LastError string `json:"last_error,omitempty"`
+
+	// ClaimToken identifies the worker's current claim; completing or failing needs it
+	ClaimToken string `json:"-"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
+
+// CallbackQueue stores compute callbacks until a worker delivers them. Enqueueing is idempotent
+// per execution and a delivered callback is never claimed again, so each callback is delivered
+// by one worker; a worker that stops between delivery and completion has its claim expire, and
+// the callback is delivered again with the same execution ID.
+type CallbackQueue interface {
+	// EnqueueCallback stores the callback for delivery. A callback already queued for the
+	// execution is kept unchanged.
+	EnqueueCallback(ctx context.Context, cb *QueuedCallback) error
+
+	// ClaimCallbacks claims up to limit undelivered callbacks for the given providers, oldest
+	// first, for claimTimeout. Callbacks claimed by another worker are skipped until the claim
+	// expires, and callbacks that have used maxAttempts deliveries are not claimed.
+	ClaimCallbacks(ctx context.Context, providers []string, limit int, claimTimeout time.Duration, maxAttempts int) ([]*QueuedCallback, error)
+
+	// CompleteCallback marks a claimed callback delivered
+	// Returns ErrCallbackNotClaimed if the claim has expired
+	CompleteCallback(ctx context.Context, executionID, claimToken string) error
+
+	// FailCallback records a failed delivery and releases the claim, so the callback is
+	// claimed again no sooner than retryAt
+	// Returns ErrCallbackNotClaimed if the claim has expired
+	FailCallback(ctx context.Context, executionID, claimToken, lastError string, retryAt time.Time) error
+}
+
+// CallbackCollector deletes queued callbacks that are no longer needed, in batches so no delete
+// holds locks or builds up dead rows for long. Queues that support it implement it next to CallbackQueue.
+type CallbackCollector interface {
+	// DeleteDeliveredCallbacks deletes up to limit callbacks delivered before before, returning how many it deleted
+	DeleteDeliveredCallbacks(ctx context.Context, before time.Time, limit int) (int, error)
+
+	// DeleteFailedCallbacks deletes up to limit undelivered callbacks queued before before that
+	// have used maxAttempts deliveries, returning how many it deleted. Claimed callbacks are kept.
+	DeleteFailedCallbacks(ctx context.Context, before time.Time, maxAttempts, limit int) (int, error)
+}
+
+// OutboxTransport is a WorkflowProvider that stores callbacks in a CallbackQueue instead of
+// posting them, for a CallbackDispatcher to deliver to the named workflow provider
+type OutboxTransport struct {
+	queue    CallbackQueue
+	provider string
+}
+
+// NewOutboxTransport creates a transport that queues callbacks for the named workflow provider
+func NewOutboxTransport(queue CallbackQueue, provider string) *OutboxTransport {
+	return &OutboxTransport{queue: queue, provider: provider}
+}
+
+// PostComputeCallback queues the callback; it is delivered even if the workflow engine is down now
+func (t *OutboxTransport) PostComputeCallback(ctx context.Context, executionID string, payload *CallbackPayload, opts *CallbackOptions) error {
+	if executionID == "" {
+		return fmt.Errorf("execution ID is required")
+	}
+	if payload == nil {
+		return fmt.Errorf("callback payload is required")
+	}
+	return t.queue.EnqueueCallback(ctx, &QueuedCallback{
+		ExecutionID: executionID,
+		Provider:    t.provider,
+		Payload:     payload,
+	})
+}
+
+// NewCallbackTransport returns what callbacks for the named workflow provider are posted to:
+// the provider itself, or an outbox transport when cfg sends the provider's callbacks through queue
+func NewCallbackTransport(cfg config.CallbackConfig, name string, provider WorkflowProvider, queue CallbackQueue) (WorkflowProvider, error) {
+	switch cfg.Transport(name) {
+	case config.CallbackTransportDirect:
+		return provider, nil
+	case config.CallbackTransportOutbox:
+		if queue == nil {
+			return nil, fmt.Errorf("callback outbox for %s requires a callback queue", name)
+		}
+		return NewOutboxTransport(queue, name), nil
+	default:
+		return nil, fmt.Errorf("unknown callback transport %q for %s", cfg.Transport(name), name)
+	}
+}
+
+// callbackRetryDelay is the delay after the first failed delivery; it doubles up to maxCallbackRetryDelay
+const (
+	callbackRetryDelay    = time.Second
+	maxCallbackRetryDelay = 5 * time.Minute
+)
+
+// CallbackDispatcher delivers queued callbacks to their workflow providers. Several workers may
+// run dispatchers against one queue; each callback is claimed by one of them at a time.
+type CallbackDispatcher struct {
+	queue     CallbackQueue
+	providers map[string]WorkflowProvider
+	names     []string
+	cfg       config.CallbackConfig
+	logger    *zap.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewCallbackDispatcher creates a dispatcher delivering the queued callbacks of providers, keyed by provider name
+func NewCallbackDispatcher(queue CallbackQueue, providers map[string]WorkflowProvider, cfg config.CallbackConfig, logger *zap.Logger) *CallbackDispatcher {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	return &CallbackDispatcher{
+		queue:     queue,
+		providers: providers,
+		names:     names,
+		cfg:       cfg,
+		logger:    logger.With(zap.String("component", "callback-dispatcher")),
+	}
+}
+
+// Start delivers queued callbacks in the background until Stop is called
+func (d *CallbackDispatcher) Start() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cfg.PollInterval <= 0 {
+		return fmt.Errorf("callback dispatcher poll interval must be positive")
+	}
+	if d.cancel != nil {
+		return fmt.Errorf("callback dispatcher already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+	d.done = make(chan struct{})
+	go d.run(ctx, d.done)
+
+	d.logger.Info("callback dispatcher started", zap.Strings("providers", d.names), zap.Duration("poll_interval", d.cfg.PollInterval))
+	return nil
+}
+
+// Stop stops the dispatcher and waits for deliveries in progress to finish
+func (d *CallbackDispatcher) Stop() {
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.cancel, d.done = nil, nil
+	d.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	d.logger.Info("callback dispatcher stopped")
+}
+
+func (d *CallbackDispatcher) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		// A full batch suggests more are waiting, so the next one is claimed straight away
+		delivered, err := d.DispatchOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.logger.Warn("callback dispatch failed", zap.Error(err))
+		}
+		if err == nil && delivered >= d.cfg.BatchSize {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce claims one batch of queued callbacks and delivers them, returning how many it claimed
+func (d *CallbackDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	callbacks, err := d.queue.ClaimCallbacks(ctx, d.names, d.cfg.BatchSize, d.cfg.ClaimTimeout, d.cfg.MaxAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("claim callbacks: %w", err)
+	}
+	for _, cb := range callbacks {
+		d.deliver(ctx, cb)
+	}
+	return len(callbacks), nil
+}
+
+func (d *CallbackDispatcher) deliver(ctx context.Context, cb *QueuedCallback) {
+	logger := d.logger.With(
+		zap.String("execution_id", cb.ExecutionID),
+		zap.String("provider", cb.Provider),
+		zap.Int("attempt", cb.Attempts),
+	)
+
+	provider, ok := d.providers[cb.Provider]
+	if !ok {
+		// Claims are limited to d.names, so this only happens if the queue misbehaves
+		logger.Error("queued callback has no provider")
+		return
+	}
+
+	// The queue retries, so each claim delivers once; the claim bounds how long a delivery may take
+	deliverCtx, cancel := context.WithTimeout(ctx, d.cfg.ClaimTimeout)
+	err := provider.PostComputeCallback(deliverCtx, cb.ExecutionID, cb.Payload, &CallbackOptions{BackoffType: "exponential"})
+	cancel()
+
+	// Recording the outcome must not be cut short by Stop
+	recordCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err == nil {
+		if err := d.queue.CompleteCallback(recordCtx, cb.ExecutionID, cb.ClaimToken); err != nil {
+			logger.Warn("failed to mark callback delivered", zap.Error(err))
+			return
+		}
+		logger.Info("compute callback delivered")
+		return
+	}
+
+	retryAt := time.Now().Add(callbackBackoff(cb.Attempts))
+	if cb.Attempts >= d.cfg.MaxAttempts {
+		logger.Error("giving up on compute callback", zap.Error(err))
+	} else {
+		logger.Warn("callback delivery failed, will retry", zap.Time("retry_at", retryAt), zap.Error(err))
+	}
+	if failErr := d.queue.FailCallback(recordCtx, cb.ExecutionID, cb.ClaimToken, err.Error(), retryAt); failErr != nil {
+		logger.Warn("failed to record callback failure", zap.Error(failErr))
+	}
+}
+
+// callbackBackoff returns the delay before the delivery after the given attempt
+func callbackBackoff(attempt int) time.Duration {
+	delay := callbackRetryDelay
+	for i := 1; i < attempt && delay < maxCallbackRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxCallbackRetryDelay)
+}
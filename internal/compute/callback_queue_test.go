@@ -0,0 +1,195 @@
+package compute_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	callbackmemory "github.com/jaxxstorm/landlord/internal/compute/callbackqueue/memory"
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+// recordingProvider counts the callbacks posted to it, failing while down is set
+type recordingProvider struct {
+	mu        sync.Mutex
+	down      bool
+	delivered map[string]int
+}
+
+func newRecordingProvider() *recordingProvider {
+	return &recordingProvider{delivered: make(map[string]int)}
+}
+
+func (p *recordingProvider) PostComputeCallback(ctx context.Context, executionID string, payload *compute.CallbackPayload, opts *compute.CallbackOptions) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down {
+		return errors.New("workflow engine unavailable")
+	}
+	p.delivered[executionID]++
+	return nil
+}
+
+func (p *recordingProvider) setDown(down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.down = down
+}
+
+func (p *recordingProvider) deliveries(executionID string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.delivered[executionID]
+}
+
+func outboxConfig() config.CallbackConfig {
+	return config.CallbackConfig{
+		Transports:   map[string]string{"restate": config.CallbackTransportOutbox},
+		PollInterval: 10 * time.Millisecond,
+		BatchSize:    5,
+		ClaimTimeout: time.Minute,
+		MaxAttempts:  3,
+	}
+}
+
+func payload(executionID string) *compute.CallbackPayload {
+	return &compute.CallbackPayload{ExecutionID: executionID, TenantID: "tenant-1", Status: compute.ExecutionStatusSucceeded}
+}
+
+func TestNewCallbackTransport(t *testing.T) {
+	provider := newRecordingProvider()
+	queue := callbackmemory.New()
+	cfg := outboxConfig()
+
+	direct, err := compute.NewCallbackTransport(cfg, "step-functions", provider, queue)
+	require.NoError(t, err)
+	assert.Same(t, provider, direct)
+
+	outbox, err := compute.NewCallbackTransport(cfg, "restate", provider, queue)
+	require.NoError(t, err)
+	require.IsType(t, &compute.OutboxTransport{}, outbox)
+
+	_, err = compute.NewCallbackTransport(cfg, "restate", provider, nil)
+	assert.ErrorContains(t, err, "requires a callback queue")
+
+	// Posting only queues the callback, once per execution
+	ctx := context.Background()
+	require.NoError(t, outbox.PostComputeCallback(ctx, "exec-1", payload("exec-1"), nil))
+	require.NoError(t, outbox.PostComputeCallback(ctx, "exec-1", payload("exec-1"), nil))
+	callbacks := queue.Callbacks()
+	require.Len(t, callbacks, 1)
+	assert.Equal(t, "restate", callbacks[0].Provider)
+	assert.Equal(t, 0, provider.deliveries("exec-1"))
+}
+
+func TestCallbackDispatcherSurvivesEngineDowntime(t *testing.T) {
+	ctx := context.Background()
+	provider := newRecordingProvider()
+	provider.setDown(true)
+	queue := callbackmemory.New()
+	outbox := compute.NewOutboxTransport(queue, "restate")
+	require.NoError(t, outbox.PostComputeCallback(ctx, "exec-1", payload("exec-1"), nil))
+
+	dispatcher := compute.NewCallbackDispatcher(queue, map[string]compute.WorkflowProvider{"restate": provider}, outboxConfig(), zap.NewNop())
+	claimed, err := dispatcher.DispatchOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+
+	callbacks := queue.Callbacks()
+	require.Len(t, callbacks, 1)
+	assert.Nil(t, callbacks[0].DeliveredAt)
+	assert.Equal(t, 1, callbacks[0].Attempts)
+	assert.Equal(t, "workflow engine unavailable", callbacks[0].LastError)
+
+	// The failed callback waits for its retry instead of being claimed again straight away
+	claimed, err = dispatcher.DispatchOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, claimed)
+
+	provider.setDown(false)
+	require.NoError(t, dispatcher.Start())
+	defer dispatcher.Stop()
+	assert.Eventually(t, func() bool { return provider.deliveries("exec-1") == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { return queue.Callbacks()[0].DeliveredAt != nil }, time.Second, 10*time.Millisecond)
+}
+
+func TestCallbackDispatcherGivesUpAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	provider := newRecordingProvider()
+	provider.setDown(true)
+	queue := callbackmemory.New()
+	require.NoError(t, queue.EnqueueCallback(ctx, &compute.QueuedCallback{ExecutionID: "exec-1", Provider: "restate", Payload: payload("exec-1")}))
+
+	cfg := outboxConfig()
+	cfg.MaxAttempts = 1
+	dispatcher := compute.NewCallbackDispatcher(queue, map[string]compute.WorkflowProvider{"restate": provider}, cfg, zap.NewNop())
+	_, err := dispatcher.DispatchOnce(ctx)
+	require.NoError(t, err)
+
+	// Even once it is due again, a callback that used its attempts is not claimed
+	claimed, err := queue.ClaimCallbacks(ctx, []string{"restate"}, 10, time.Minute, cfg.MaxAttempts)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestCallbackDispatchersDeliverEachCallbackOnce(t *testing.T) {
+	ctx := context.Background()
+	provider := newRecordingProvider()
+	queue := callbackmemory.New()
+	outbox := compute.NewOutboxTransport(queue, "restate")
+	const callbacks = 40
+	for i := 0; i < callbacks; i++ {
+		id := fmt.Sprintf("exec-%d", i)
+		require.NoError(t, outbox.PostComputeCallback(ctx, id, payload(id), nil))
+	}
+	// Queued for a provider no dispatcher serves, so never claimed
+	require.NoError(t, queue.EnqueueCallback(ctx, &compute.QueuedCallback{ExecutionID: "other", Provider: "step-functions", Payload: payload("other")}))
+
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		dispatcher := compute.NewCallbackDispatcher(queue, map[string]compute.WorkflowProvider{"restate": provider}, outboxConfig(), zap.NewNop())
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				claimed, err := dispatcher.DispatchOnce(ctx)
+				if err != nil || claimed == 0 {
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < callbacks; i++ {
+		assert.Equal(t, 1, provider.deliveries(fmt.Sprintf("exec-%d", i)))
+	}
+	assert.Equal(t, 0, provider.deliveries("other"))
+}
+
+func TestCallbackQueueRejectsExpiredClaims(t *testing.T) {
+	ctx := context.Background()
+	queue := callbackmemory.New()
+	require.NoError(t, queue.EnqueueCallback(ctx, &compute.QueuedCallback{ExecutionID: "exec-1", Provider: "restate", Payload: payload("exec-1")}))
+
+	first, err := queue.ClaimCallbacks(ctx, []string{"restate"}, 10, time.Millisecond, 5)
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	time.Sleep(5 * time.Millisecond)
+
+	// Another worker takes over the expired claim; the first can no longer complete it
+	second, err := queue.ClaimCallbacks(ctx, []string{"restate"}, 10, time.Minute, 5)
+	require.NoError(t, err)
+	require.Len(t, second, 1)
+	assert.ErrorIs(t, queue.CompleteCallback(ctx, "exec-1", first[0].ClaimToken), compute.ErrCallbackNotClaimed)
+	require.NoError(t, queue.CompleteCallback(ctx, "exec-1", second[0].ClaimToken))
+	assert.Equal(t, 2, queue.Callbacks()[0].Attempts)
+}
@@ -0,0 +1,176 @@
+// Package memory provides an in-memory compute callback queue for tests and local harnesses.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// Queue implements compute.CallbackQueue in memory
+type Queue struct {
+	mu        sync.Mutex
+	callbacks map[string]*entry
+}
+
+type entry struct {
+	callback      compute.QueuedCallback
+	nextAttemptAt time.Time
+	claimedUntil  time.Time
+}
+
+var (
+	_ compute.CallbackQueue     = (*Queue)(nil)
+	_ compute.CallbackCollector = (*Queue)(nil)
+)
+
+// New creates an empty in-memory queue
+func New() *Queue {
+	return &Queue{callbacks: make(map[string]*entry)}
+}
+
+func (q *Queue) EnqueueCallback(ctx context.Context, cb *compute.QueuedCallback) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, exists := q.callbacks[cb.ExecutionID]; exists {
+		return nil
+	}
+	now := time.Now()
+	stored := *cb
+	stored.Attempts = 0
+	stored.LastError = ""
+	stored.ClaimToken = ""
+	stored.CreatedAt = now
+	stored.DeliveredAt = nil
+	q.callbacks[cb.ExecutionID] = &entry{callback: stored, nextAttemptAt: now}
+	return nil
+}
+
+func (q *Queue) ClaimCallbacks(ctx context.Context, providers []string, limit int, claimTimeout time.Duration, maxAttempts int) ([]*compute.QueuedCallback, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	wanted := make(map[string]bool, len(providers))
+	for _, provider := range providers {
+		wanted[provider] = true
+	}
+
+	now := time.Now()
+	var due []*entry
+	for _, e := range q.callbacks {
+		if e.callback.DeliveredAt != nil || !wanted[e.callback.Provider] || e.callback.Attempts >= maxAttempts {
+			continue
+		}
+		if now.Before(e.nextAttemptAt) || now.Before(e.claimedUntil) {
+			continue
+		}
+		due = append(due, e)
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].callback.CreatedAt.Before(due[j].callback.CreatedAt) })
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+
+	claimed := make([]*compute.QueuedCallback, 0, len(due))
+	for _, e := range due {
+		e.callback.Attempts++
+		e.callback.ClaimToken = uuid.NewString()
+		e.claimedUntil = now.Add(claimTimeout)
+		cb := e.callback
+		claimed = append(claimed, &cb)
+	}
+	return claimed, nil
+}
+
+func (q *Queue) CompleteCallback(ctx context.Context, executionID, claimToken string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	e, err := q.claimed(executionID, claimToken)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	e.callback.DeliveredAt = &now
+	e.callback.ClaimToken = ""
+	e.claimedUntil = time.Time{}
+	return nil
+}
+
+func (q *Queue) FailCallback(ctx context.Context, executionID, claimToken, lastError string, retryAt time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	e, err := q.claimed(executionID, claimToken)
+	if err != nil {
+		return err
+	}
+	e.callback.LastError = lastError
+	e.callback.ClaimToken = ""
+	e.claimedUntil = time.Time{}
+	e.nextAttemptAt = retryAt
+	return nil
+}
+
+func (q *Queue) DeleteDeliveredCallbacks(ctx context.Context, before time.Time, limit int) (int, error) {
+	return q.deleteWhere(limit, func(e *entry) bool {
+		return e.callback.DeliveredAt != nil && e.callback.DeliveredAt.Before(before)
+	}), nil
+}
+
+func (q *Queue) DeleteFailedCallbacks(ctx context.Context, before time.Time, maxAttempts, limit int) (int, error) {
+	now := time.Now()
+	return q.deleteWhere(limit, func(e *entry) bool {
+		return e.callback.DeliveredAt == nil && e.callback.Attempts >= maxAttempts &&
+			e.callback.CreatedAt.Before(before) && !now.Before(e.claimedUntil)
+	}), nil
+}
+
+// deleteWhere deletes up to limit of the oldest callbacks matching match, returning how many it deleted
+func (q *Queue) deleteWhere(limit int, match func(*entry) bool) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var matched []*entry
+	for _, e := range q.callbacks {
+		if match(e) {
+			matched = append(matched, e)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].callback.CreatedAt.Before(matched[j].callback.CreatedAt) })
+	if limit > 0 && len(matched) > limit {
+		matched = matched[:limit]
+	}
+	for _, e := range matched {
+		delete(q.callbacks, e.callback.ExecutionID)
+	}
+	return len(matched)
+}
+
+// Callbacks returns every queued callback, delivered or not, oldest first
+func (q *Queue) Callbacks() []compute.QueuedCallback {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	callbacks := make([]compute.QueuedCallback, 0, len(q.callbacks))
+	for _, e := range q.callbacks {
+		callbacks = append(callbacks, e.callback)
+	}
+	sort.Slice(callbacks, func(i, j int) bool { return callbacks[i].CreatedAt.Before(callbacks[j].CreatedAt) })
+	return callbacks
+}
+
+// claimed returns the entry if claimToken still holds its claim; q.mu must be held
+func (q *Queue) claimed(executionID, claimToken string) (*entry, error) {
+	e, ok := q.callbacks[executionID]
+	if !ok || claimToken == "" || e.callback.ClaimToken != claimToken || time.Now().After(e.claimedUntil) {
+		return nil, compute.ErrCallbackNotClaimed
+	}
+	return e, nil
+}
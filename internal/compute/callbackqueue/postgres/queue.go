@@ -0,0 +1,192 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// Queue implements compute.CallbackQueue as an outbox table in PostgreSQL
+type Queue struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+var (
+	_ compute.CallbackQueue     = (*Queue)(nil)
+	_ compute.CallbackCollector = (*Queue)(nil)
+)
+
+// New creates a PostgreSQL callback queue
+// Accepts interface{} to satisfy provider abstraction, type asserts to *pgxpool.Pool
+func New(db interface{}, logger *zap.Logger) (*Queue, error) {
+	pgPool, ok := db.(*pgxpool.Pool)
+	if !ok {
+		return nil, fmt.Errorf("expected *pgxpool.Pool, got %T", db)
+	}
+	return &Queue{
+		pool:   pgPool,
+		logger: logger.With(zap.String("component", "callback-postgres-queue")),
+	}, nil
+}
+
+const enqueueCallbackQuery = `
+INSERT INTO compute_callbacks (execution_id, provider, payload)
+VALUES ($1, $2, $3)
+ON CONFLICT (execution_id) DO NOTHING
+`
+
+func (q *Queue) EnqueueCallback(ctx context.Context, cb *compute.QueuedCallback) error {
+	payload, err := json.Marshal(cb.Payload)
+	if err != nil {
+		return fmt.Errorf("encode callback payload: %w", err)
+	}
+	tag, err := q.pool.Exec(ctx, enqueueCallbackQuery, cb.ExecutionID, cb.Provider, payload)
+	if err != nil {
+		return fmt.Errorf("enqueue callback: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		q.logger.Debug("callback already queued", zap.String("execution_id", cb.ExecutionID))
+	}
+	return nil
+}
+
+// claimCallbacksQuery claims the oldest due callbacks. SKIP LOCKED lets concurrent workers pass
+// over callbacks another worker is claiming instead of waiting on them.
+const claimCallbacksQuery = `
+UPDATE compute_callbacks
+SET attempts = attempts + 1,
+    claim_token = gen_random_uuid(),
+    claimed_until = CURRENT_TIMESTAMP + $3 * interval '1 millisecond'
+WHERE execution_id IN (
+    SELECT execution_id FROM compute_callbacks
+    WHERE delivered_at IS NULL
+      AND provider = ANY($1::text[])
+      AND attempts < $4
+      AND next_attempt_at <= CURRENT_TIMESTAMP
+      AND (claimed_until IS NULL OR claimed_until < CURRENT_TIMESTAMP)
+    ORDER BY created_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING execution_id, provider, payload, attempts, COALESCE(last_error, ''), claim_token, created_at
+`
+
+func (q *Queue) ClaimCallbacks(ctx context.Context, providers []string, limit int, claimTimeout time.Duration, maxAttempts int) ([]*compute.QueuedCallback, error) {
+	rows, err := q.pool.Query(ctx, claimCallbacksQuery, providers, limit, claimTimeout.Milliseconds(), maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("claim callbacks: %w", err)
+	}
+	defer rows.Close()
+
+	var callbacks []*compute.QueuedCallback
+	for rows.Next() {
+		var cb compute.QueuedCallback
+		var payload []byte
+		var token uuid.UUID
+		if err := rows.Scan(&cb.ExecutionID, &cb.Provider, &payload, &cb.Attempts, &cb.LastError, &token, &cb.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan callback: %w", err)
+		}
+		if err := json.Unmarshal(payload, &cb.Payload); err != nil {
+			return nil, fmt.Errorf("decode callback payload: %w", err)
+		}
+		cb.ClaimToken = token.String()
+		callbacks = append(callbacks, &cb)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claim callbacks: %w", err)
+	}
+	return callbacks, nil
+}
+
+const completeCallbackQuery = `
+UPDATE compute_callbacks
+SET delivered_at = CURRENT_TIMESTAMP, claim_token = NULL, claimed_until = NULL
+WHERE execution_id = $1 AND claim_token = $2 AND claimed_until >= CURRENT_TIMESTAMP
+`
+
+func (q *Queue) CompleteCallback(ctx context.Context, executionID, claimToken string) error {
+	token, err := uuid.Parse(claimToken)
+	if err != nil {
+		return compute.ErrCallbackNotClaimed
+	}
+	tag, err := q.pool.Exec(ctx, completeCallbackQuery, executionID, token)
+	if err != nil {
+		return fmt.Errorf("complete callback: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return compute.ErrCallbackNotClaimed
+	}
+	return nil
+}
+
+const failCallbackQuery = `
+UPDATE compute_callbacks
+SET last_error = $3, next_attempt_at = $4, claim_token = NULL, claimed_until = NULL
+WHERE execution_id = $1 AND claim_token = $2 AND claimed_until >= CURRENT_TIMESTAMP
+`
+
+func (q *Queue) FailCallback(ctx context.Context, executionID, claimToken, lastError string, retryAt time.Time) error {
+	token, err := uuid.Parse(claimToken)
+	if err != nil {
+		return compute.ErrCallbackNotClaimed
+	}
+	tag, err := q.pool.Exec(ctx, failCallbackQuery, executionID, token, lastError, retryAt)
+	if err != nil {
+		return fmt.Errorf("fail callback: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return compute.ErrCallbackNotClaimed
+	}
+	return nil
+}
+
+// deleteDeliveredCallbacksQuery deletes one batch of the oldest delivered callbacks. SKIP LOCKED
+// passes over rows another transaction holds instead of waiting on them.
+const deleteDeliveredCallbacksQuery = `
+DELETE FROM compute_callbacks
+WHERE execution_id IN (
+    SELECT execution_id FROM compute_callbacks
+    WHERE delivered_at < $1
+    ORDER BY delivered_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+`
+
+func (q *Queue) DeleteDeliveredCallbacks(ctx context.Context, before time.Time, limit int) (int, error) {
+	tag, err := q.pool.Exec(ctx, deleteDeliveredCallbacksQuery, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete delivered callbacks: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+const deleteFailedCallbacksQuery = `
+DELETE FROM compute_callbacks
+WHERE execution_id IN (
+    SELECT execution_id FROM compute_callbacks
+    WHERE delivered_at IS NULL
+      AND attempts >= $2
+      AND created_at < $1
+      AND (claimed_until IS NULL OR claimed_until < CURRENT_TIMESTAMP)
+    ORDER BY created_at
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+`
+
+func (q *Queue) DeleteFailedCallbacks(ctx context.Context, before time.Time, maxAttempts, limit int) (int, error) {
+	tag, err := q.pool.Exec(ctx, deleteFailedCallbacksQuery, before, maxAttempts, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete failed callbacks: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
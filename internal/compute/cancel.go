@@ -0,0 +1,79 @@
+package compute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// runOperation runs a tracked compute operation for a workflow execution. The operation records
+// its heartbeats against the compute execution and is cancelled by CancelExecution; its error
+// then wraps ErrExecutionCancelled.
+func (m *Manager) runOperation(ctx context.Context, workflowExecutionID, executionID string, fn func(ctx context.Context) error) error {
+	opCtx, done := m.trackExecution(ctx, workflowExecutionID)
+	defer done()
+
+	err := fn(m.trackHeartbeats(opCtx, executionID))
+	if err == nil {
+		return nil
+	}
+	// Providers usually report a bare context error; the cause says the execution was stopped
+	if cause := context.Cause(opCtx); errors.Is(cause, ErrExecutionCancelled) && !errors.Is(err, ErrExecutionCancelled) {
+		return fmt.Errorf("%w: %v", cause, err)
+	}
+	return err
+}
+
+// trackExecution returns a context that CancelExecution cancels, and a func that stops tracking
+// it once the operation is done
+func (m *Manager) trackExecution(ctx context.Context, workflowExecutionID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	if workflowExecutionID == "" {
+		return ctx, func() { cancel(nil) }
+	}
+
+	m.inflightMu.Lock()
+	if m.inflight == nil {
+		m.inflight = make(map[string]map[uint64]context.CancelCauseFunc)
+	}
+	m.inflightNextID++
+	id := m.inflightNextID
+	if m.inflight[workflowExecutionID] == nil {
+		m.inflight[workflowExecutionID] = make(map[uint64]context.CancelCauseFunc)
+	}
+	m.inflight[workflowExecutionID][id] = cancel
+	m.inflightMu.Unlock()
+
+	return ctx, func() {
+		m.inflightMu.Lock()
+		delete(m.inflight[workflowExecutionID], id)
+		if len(m.inflight[workflowExecutionID]) == 0 {
+			delete(m.inflight, workflowExecutionID)
+		}
+		m.inflightMu.Unlock()
+		cancel(nil)
+	}
+}
+
+// CancelExecution cancels the compute operations a workflow execution has in flight, so a
+// stopped execution does not keep provisioning. Providers stop at their next context check and
+// remove what they created partway. Returns how many operations were cancelled.
+func (m *Manager) CancelExecution(workflowExecutionID, reason string) int {
+	m.inflightMu.Lock()
+	defer m.inflightMu.Unlock()
+
+	operations := m.inflight[workflowExecutionID]
+	for _, cancel := range operations {
+		cancel(fmt.Errorf("%w: %s", ErrExecutionCancelled, reason))
+	}
+	if len(operations) > 0 {
+		m.logger.Info("cancelled in-flight compute operations",
+			zap.String("workflow_execution_id", workflowExecutionID),
+			zap.Int("operations", len(operations)),
+			zap.String("reason", reason),
+		)
+	}
+	return len(operations)
+}
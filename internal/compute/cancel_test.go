@@ -0,0 +1,60 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestManagerCancelExecution(t *testing.T) {
+	repo := new(MockExecutionRepository)
+	repo.On("CreateComputeExecution", mock.Anything, mock.Anything).Return(nil)
+	repo.On("UpdateComputeExecution", mock.Anything, mock.Anything).Return(nil)
+	provider := new(MockComputeProviderForTracking)
+	registry := NewRegistry(zap.NewNop())
+	assert.NoError(t, registry.Register(provider))
+	manager := NewWithTracking(registry, repo, zap.NewNop())
+
+	spec := &TenantComputeSpec{
+		TenantID:       "tenant-001",
+		ProviderType:   "mock-tracking",
+		ProviderConfig: json.RawMessage(`{}`),
+		Containers:     []ContainerSpec{{Name: "app", Image: "nginx:1.27"}},
+		Resources:      ResourceRequirements{CPU: 256, Memory: 512},
+	}
+	started := make(chan struct{})
+	provider.On("Provision", mock.Anything, spec).Run(func(args mock.Arguments) {
+		close(started)
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil, context.Canceled)
+
+	assert.Equal(t, 0, manager.CancelExecution("wf-exec-001", "nothing running"))
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := manager.ProvisionTenantWithTracking(context.Background(), spec, "wf-exec-001")
+		result <- err
+	}()
+	<-started
+
+	// Other executions' operations are left alone
+	assert.Equal(t, 0, manager.CancelExecution("wf-exec-002", "Configuration updated"))
+	assert.Equal(t, 1, manager.CancelExecution("wf-exec-001", "Configuration updated"))
+
+	select {
+	case err := <-result:
+		assert.True(t, errors.Is(err, ErrExecutionCancelled), "expected a cancelled execution, got %v", err)
+		assert.ErrorContains(t, err, "Configuration updated")
+	case <-time.After(5 * time.Second):
+		t.Fatal("provision was not cancelled")
+	}
+
+	// Finished operations are no longer tracked
+	assert.Equal(t, 0, manager.CancelExecution("wf-exec-001", "again"))
+}
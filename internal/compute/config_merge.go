@@ -1,6 +1,16 @@
 package compute
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// AnnotationProviderDefaults records the top-level compute_config fields a tenant's compute provider
+// defaults filled in or added to when the tenant was admitted
+const AnnotationProviderDefaults = "landlord/provider_defaults"
 
 // MergeConfigMaps merges override onto base recursively for map values.
 // Non-map values in override replace base values.
@@ -47,3 +57,44 @@ func MergeConfigJSON(base map[string]interface{}, override json.RawMessage) (jso
 
 	return json.Marshal(merged)
 }
+
+// ApplyConfigDefaults merges provider's ConfigDefaults under config, so values config sets win,
+// including nested ones. It returns the merged config and the top-level fields the defaults filled in
+// or added to. config itself is not modified.
+func ApplyConfigDefaults(provider Provider, config map[string]interface{}) (map[string]interface{}, []string, error) {
+	raw := provider.ConfigDefaults()
+	if len(raw) == 0 {
+		return config, nil, nil
+	}
+	var defaults map[string]interface{}
+	if err := json.Unmarshal(raw, &defaults); err != nil {
+		return nil, nil, fmt.Errorf("decode %s config defaults: %w", provider.Name(), err)
+	}
+	if len(defaults) == 0 {
+		return config, nil, nil
+	}
+
+	merged := MergeConfigMaps(defaults, config)
+	var fields []string
+	for key, value := range merged {
+		if before, set := config[key]; !set || !reflect.DeepEqual(before, value) {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return merged, fields, nil
+}
+
+// AnnotateProviderDefaults records fields in the provider defaults annotation, removing it when no
+// field was defaulted, and allocates annotations if needed
+func AnnotateProviderDefaults(annotations map[string]string, fields []string) map[string]string {
+	if len(fields) == 0 {
+		delete(annotations, AnnotationProviderDefaults)
+		return annotations
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationProviderDefaults] = strings.Join(fields, ",")
+	return annotations
+}
@@ -0,0 +1,60 @@
+package compute
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// defaultsProvider is a test provider with config defaults
+type defaultsProvider struct {
+	testProvider
+	defaults json.RawMessage
+}
+
+func (p *defaultsProvider) ConfigDefaults() json.RawMessage {
+	return p.defaults
+}
+
+func TestApplyConfigDefaults(t *testing.T) {
+	provider := &defaultsProvider{
+		testProvider: testProvider{name: "docker"},
+		defaults:     json.RawMessage(`{"image":"nginx:1.27","env":{"LOG_LEVEL":"info","REGION":"eu"},"restart_policy":"always"}`),
+	}
+	config := map[string]interface{}{
+		"env":            map[string]interface{}{"REGION": "us"},
+		"restart_policy": "always",
+	}
+
+	merged, fields, err := ApplyConfigDefaults(provider, config)
+	if err != nil {
+		t.Fatalf("ApplyConfigDefaults() error = %v", err)
+	}
+	want := map[string]interface{}{
+		"image":          "nginx:1.27",
+		"env":            map[string]interface{}{"LOG_LEVEL": "info", "REGION": "us"},
+		"restart_policy": "always",
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Fatalf("expected %v, got %v", want, merged)
+	}
+	if !reflect.DeepEqual(fields, []string{"env", "image"}) {
+		t.Fatalf("expected env and image defaulted, got %v", fields)
+	}
+	if _, ok := config["image"]; ok {
+		t.Fatal("expected the config not to be modified")
+	}
+
+	annotations := AnnotateProviderDefaults(nil, fields)
+	if annotations[AnnotationProviderDefaults] != "env,image" {
+		t.Fatalf("unexpected annotation %q", annotations[AnnotationProviderDefaults])
+	}
+	if annotations = AnnotateProviderDefaults(annotations, nil); len(annotations) != 0 {
+		t.Fatalf("expected the annotation removed, got %v", annotations)
+	}
+
+	none := &defaultsProvider{testProvider: testProvider{name: "mock"}}
+	if merged, fields, err := ApplyConfigDefaults(none, config); err != nil || !reflect.DeepEqual(merged, config) || fields != nil {
+		t.Fatalf("expected a provider without defaults to leave the config alone, got %v %v %v", merged, fields, err)
+	}
+}
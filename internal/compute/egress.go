@@ -0,0 +1,111 @@
+package compute
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// EgressConfigKey is the compute_config key holding the tenant's egress policy
+const EgressConfigKey = "egress"
+
+// maxEgressAllow bounds the allow-list so a policy stays a handful of firewall rules
+const maxEgressAllow = 64
+
+var validEgressDomain = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,63}$`)
+
+// EgressPolicy restricts the tenant's outbound traffic. Declaring a policy denies all egress except
+// to the destinations in Allow, and traffic to the tenant's own network.
+type EgressPolicy struct {
+	// Allow lists permitted destinations: CIDRs ("10.0.0.0/8"), IPs, or domains, which are resolved
+	// when the policy is applied
+	Allow []string `json:"allow,omitempty"`
+}
+
+// EgressReporter is implemented by compute providers that enforce egress policies and can count
+// what they block. The egress monitor records growth in the count as tenant events.
+type EgressReporter interface {
+	// DeniedEgress returns how many outbound packets the tenant's egress policy has dropped
+	// since it was applied. Returns ErrTenantNotFound when no policy is applied for the tenant.
+	DeniedEgress(ctx context.Context, tenantID string) (int64, error)
+}
+
+// ParseEgressPolicy extracts and validates the egress policy from a desired config.
+// Returns nil when the tenant's egress is unrestricted.
+func ParseEgressPolicy(desiredConfig map[string]interface{}) (*EgressPolicy, error) {
+	raw, ok := desiredConfig[EgressConfigKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("encode egress policy: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var policy EgressPolicy
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("invalid egress policy: %w", err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("egress: %w", err)
+	}
+	return &policy, nil
+}
+
+// Validate checks the policy
+func (p *EgressPolicy) Validate() error {
+	if len(p.Allow) > maxEgressAllow {
+		return fmt.Errorf("allow must have at most %d entries", maxEgressAllow)
+	}
+	for i, entry := range p.Allow {
+		if _, _, err := net.ParseCIDR(entry); err == nil {
+			continue
+		}
+		if net.ParseIP(entry) != nil {
+			continue
+		}
+		if !validEgressDomain.MatchString(entry) {
+			return fmt.Errorf("allow[%d]: %q must be a CIDR, an IP address or a lower-case domain name", i, entry)
+		}
+	}
+	return nil
+}
+
+// Networks returns the allowed CIDRs and IPs, with IPs as single-address CIDRs
+func (p *EgressPolicy) Networks() []string {
+	var networks []string
+	for _, entry := range p.Allow {
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			networks = append(networks, ipNet.String())
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			if ip.To4() != nil {
+				networks = append(networks, ip.String()+"/32")
+			} else {
+				networks = append(networks, ip.String()+"/128")
+			}
+		}
+	}
+	return networks
+}
+
+// Domains returns the allowed domain names
+func (p *EgressPolicy) Domains() []string {
+	var domains []string
+	for _, entry := range p.Allow {
+		if strings.Contains(entry, "/") || net.ParseIP(entry) != nil {
+			continue
+		}
+		domains = append(domains, entry)
+	}
+	return domains
+}
@@ -0,0 +1,42 @@
+package compute
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEgressPolicy(t *testing.T) {
+	policy, err := ParseEgressPolicy(map[string]interface{}{
+		EgressConfigKey: map[string]interface{}{"allow": []interface{}{"10.0.0.0/8", "1.1.1.1", "2606:4700::1111", "api.stripe.com"}},
+	})
+	if err != nil {
+		t.Fatalf("ParseEgressPolicy() error = %v", err)
+	}
+	if want := []string{"10.0.0.0/8", "1.1.1.1/32", "2606:4700::1111/128"}; !reflect.DeepEqual(policy.Networks(), want) {
+		t.Fatalf("Networks() = %v, want %v", policy.Networks(), want)
+	}
+	if want := []string{"api.stripe.com"}; !reflect.DeepEqual(policy.Domains(), want) {
+		t.Fatalf("Domains() = %v, want %v", policy.Domains(), want)
+	}
+
+	denyAll, err := ParseEgressPolicy(map[string]interface{}{EgressConfigKey: map[string]interface{}{}})
+	if err != nil || denyAll == nil || len(denyAll.Allow) != 0 {
+		t.Fatalf("expected an empty policy to deny all egress, got %+v, %v", denyAll, err)
+	}
+	if none, err := ParseEgressPolicy(map[string]interface{}{"image": "nginx:latest"}); err != nil || none != nil {
+		t.Fatalf("expected no policy, got %+v, %v", none, err)
+	}
+
+	for name, raw := range map[string]interface{}{
+		"wildcard domain": map[string]interface{}{"allow": []interface{}{"*.stripe.com"}},
+		"bad cidr":        map[string]interface{}{"allow": []interface{}{"10.0.0.0/33"}},
+		"unknown field":   map[string]interface{}{"deny": []interface{}{"10.0.0.0/8"}},
+		"not an object":   "deny-all",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseEgressPolicy(map[string]interface{}{EgressConfigKey: raw}); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package compute
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+)
 
 var (
 	// ErrProviderNotFound is returned when a provider is not registered
@@ -20,4 +25,120 @@ var (
 
 	// ErrTenantNotFound is returned when tenant compute resources don't exist
 	ErrTenantNotFound = errors.New("tenant compute resources not found")
+
+	// ErrInvalidConfig is returned when provider configuration is rejected
+	ErrInvalidConfig = errors.New("invalid compute configuration")
+
+	// ErrQuotaExceeded is returned when the provider has no capacity or quota left
+	ErrQuotaExceeded = errors.New("compute quota exceeded")
+
+	// ErrProviderUnavailable is returned when the provider backend cannot be reached
+	ErrProviderUnavailable = errors.New("compute provider unavailable")
+
+	// ErrProviderDisabled is returned when a disabled provider is asked to place new tenants
+	ErrProviderDisabled = errors.New("compute provider disabled")
+
+	// ErrReconfigureUnsupported is returned when a provider cannot change its defaults at runtime
+	ErrReconfigureUnsupported = errors.New("compute provider does not support reconfiguration")
+
+	// ErrAlreadyProvisioned is returned when provisioning a tenant whose compute already exists
+	ErrAlreadyProvisioned = errors.New("tenant compute already provisioned")
+
+	// ErrPartiallyProvisioned is returned when a failed provision's resources could not be torn
+	// down, so they are left behind until something removes them
+	ErrPartiallyProvisioned = errors.New("tenant compute partially provisioned")
+
+	// ErrExecutionCancelled is returned by compute operations cancelled because the workflow
+	// execution that started them was stopped
+	ErrExecutionCancelled = errors.New("workflow execution cancelled")
+
+	// ErrRetriable marks an error as safe to retry; tag errors with Retriable
+	ErrRetriable = errors.New("retriable compute error")
+)
+
+// Error codes reported in ComputeError.Code
+const (
+	ErrorCodeTimeout              = "PROVIDER_TIMEOUT"
+	ErrorCodeUnavailable          = "PROVIDER_UNAVAILABLE"
+	ErrorCodeResourceExhausted    = "RESOURCE_EXHAUSTED"
+	ErrorCodeInvalidConfiguration = "INVALID_CONFIGURATION"
+	ErrorCodeNotFound             = "RESOURCE_NOT_FOUND"
+	ErrorCodeUnknown              = "UNKNOWN_ERROR"
 )
+
+// Error implements the error interface so a ComputeError can be returned and matched with errors.As
+func (e *ComputeError) Error() string {
+	return e.Message
+}
+
+// markedError tags an error with a sentinel so errors.Is matches both
+type markedError struct {
+	err      error
+	sentinel error
+}
+
+func (e *markedError) Error() string { return e.err.Error() }
+
+func (e *markedError) Unwrap() []error { return []error{e.err, e.sentinel} }
+
+// Mark tags err with a sentinel such as ErrQuotaExceeded without changing its message
+func Mark(err, sentinel error) error {
+	if err == nil {
+		return nil
+	}
+	return &markedError{err: err, sentinel: sentinel}
+}
+
+// Retriable marks err as safe to retry without changing its message
+func Retriable(err error) error {
+	return Mark(err, ErrRetriable)
+}
+
+// ClassifyError maps an error to a ComputeError using the sentinel errors above.
+// Errors that match none of them are reported as UNKNOWN_ERROR and are retriable only when marked with Retriable.
+func ClassifyError(err error) *ComputeError {
+	if err == nil {
+		return nil
+	}
+
+	var existing *ComputeError
+	if errors.As(err, &existing) {
+		return existing
+	}
+
+	code := ErrorCodeUnknown
+	retriable := errors.Is(err, ErrRetriable)
+
+	switch {
+	case isTimeout(err):
+		code = ErrorCodeTimeout
+		retriable = true
+	case errors.Is(err, ErrProviderUnavailable):
+		code = ErrorCodeUnavailable
+		retriable = true
+	case errors.Is(err, ErrQuotaExceeded):
+		code = ErrorCodeResourceExhausted
+		retriable = true
+	case errors.Is(err, ErrInvalidConfig), errors.Is(err, ErrInvalidSpec):
+		code = ErrorCodeInvalidConfiguration
+		retriable = false
+	case errors.Is(err, ErrTenantNotFound), errors.Is(err, ErrProviderNotFound):
+		code = ErrorCodeNotFound
+		retriable = false
+	}
+
+	return &ComputeError{
+		Code:          code,
+		Message:       err.Error(),
+		IsRetriable:   retriable,
+		ProviderError: err.Error(),
+	}
+}
+
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
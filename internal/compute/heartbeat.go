@@ -0,0 +1,32 @@
+package compute
+
+import "context"
+
+// HeartbeatFunc is told that a long-running compute operation is still making progress. detail
+// describes what it is doing, e.g. "pulling image nginx:1.27".
+type HeartbeatFunc func(detail string)
+
+type heartbeatKey struct{}
+
+// WithHeartbeat returns a context whose compute operations report their progress to fn, as well
+// as to any heartbeat already on ctx, so the compute manager and the workflow worker can both
+// follow an operation
+func WithHeartbeat(ctx context.Context, fn HeartbeatFunc) context.Context {
+	if parent, ok := ctx.Value(heartbeatKey{}).(HeartbeatFunc); ok {
+		next := fn
+		fn = func(detail string) {
+			next(detail)
+			parent(detail)
+		}
+	}
+	return context.WithValue(ctx, heartbeatKey{}, fn)
+}
+
+// Heartbeat reports that the operation running under ctx is still making progress. Providers
+// call it during steps that can outlast a workflow step timeout, such as image pulls, so the
+// worker can tell a slow operation from a stalled one. It does nothing when no one is listening.
+func Heartbeat(ctx context.Context, detail string) {
+	if fn, ok := ctx.Value(heartbeatKey{}).(HeartbeatFunc); ok {
+		fn(detail)
+	}
+}
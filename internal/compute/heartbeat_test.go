@@ -0,0 +1,46 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestHeartbeat(t *testing.T) {
+	// Heartbeats without a listener are ignored
+	Heartbeat(context.Background(), "ignored")
+
+	var worker, manager []string
+	ctx := WithHeartbeat(context.Background(), func(detail string) { worker = append(worker, detail) })
+	ctx = WithHeartbeat(ctx, func(detail string) { manager = append(manager, detail) })
+	Heartbeat(ctx, "pulling image nginx:1.27")
+
+	assert.Equal(t, []string{"pulling image nginx:1.27"}, worker)
+	assert.Equal(t, []string{"pulling image nginx:1.27"}, manager)
+}
+
+func TestManagerRecordsExecutionHeartbeats(t *testing.T) {
+	repo := new(MockExecutionRepository)
+	var recorded []*ComputeExecutionHistory
+	repo.On("AddExecutionHistory", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		recorded = append(recorded, args.Get(1).(*ComputeExecutionHistory))
+	}).Return(nil)
+	m := &Manager{executionRepository: repo, logger: zap.NewNop()}
+
+	ctx := m.trackHeartbeats(context.Background(), "exec-1")
+	Heartbeat(ctx, "pulling image nginx:1.27")
+	Heartbeat(ctx, "pulling image nginx:1.27")
+
+	// Heartbeats within the interval are not recorded again
+	if assert.Len(t, recorded, 1) {
+		assert.Equal(t, "exec-1", recorded[0].ComputeExecutionID)
+		assert.Equal(t, ExecutionStatusRunning, recorded[0].Status)
+		var details map[string]string
+		assert.NoError(t, json.Unmarshal(recorded[0].Details, &details))
+		assert.Equal(t, "pulling image nginx:1.27", details["heartbeat"])
+	}
+}
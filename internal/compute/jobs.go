@@ -0,0 +1,39 @@
+package compute
+
+import (
+	"context"
+	"time"
+)
+
+// JobSpec describes a run-to-completion container for a tenant, such as a hook or migration job
+type JobSpec struct {
+	// Name identifies the job within the tenant (used in container names and labels)
+	Name string `json:"name"`
+
+	// Image is the container image to run
+	Image string `json:"image"`
+
+	// Command overrides the image entrypoint/cmd
+	Command []string `json:"command,omitempty"`
+
+	// Env holds environment variables for the job container
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// JobResult is the outcome of a completed job
+type JobResult struct {
+	// ExitCode is the container exit code; non-zero means the job failed
+	ExitCode int `json:"exit_code"`
+
+	// Output is the (possibly truncated) combined stdout/stderr of the job
+	Output string `json:"output,omitempty"`
+
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// JobRunner is implemented by compute providers that can run one-off containers for a tenant.
+// RunJob blocks until the job exits or ctx is done, and removes the job container afterwards.
+type JobRunner interface {
+	RunJob(ctx context.Context, tenantID string, job *JobSpec) (*JobResult, error)
+}
@@ -0,0 +1,161 @@
+package compute
+
+import (
+	"container/list"
+	"context"
+	"expvar"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+// limiterMetrics report each limited provider operation, keyed "<provider>/<operation>", served
+// from /debug/vars as "compute_concurrency"
+var limiterMetrics = expvar.NewMap("compute_concurrency")
+
+// Limiter enforces each provider's concurrency limits, so a backlog of tenants does not overwhelm
+// its backend. Updates count against the provision limit, since they usually recreate compute.
+// Operations over a limit wait in a first-in, first-out queue, so a burst of work cannot starve
+// operations queued before it.
+//
+// Only the workflow worker takes slots: an operation that acquired one per layer would wait on
+// itself under a limit of 1.
+type Limiter struct {
+	limits map[string]config.ComputeLimitsConfig
+
+	mu     sync.Mutex
+	queues map[string]*operationQueue
+}
+
+// NewLimiter creates a limiter for the providers in limits; other providers are not limited
+func NewLimiter(limits map[string]config.ComputeLimitsConfig) *Limiter {
+	return &Limiter{
+		limits: limits,
+		queues: make(map[string]*operationQueue),
+	}
+}
+
+// Acquire waits until the provider may start another operation of the given type, and returns
+// a func that releases the slot once the operation is done. It returns the context's error if
+// the context ends first. A nil Limiter does not limit anything.
+func (l *Limiter) Acquire(ctx context.Context, provider string, operation ComputeOperationType) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	queue := l.queue(provider, operation)
+	if queue == nil {
+		return func() {}, nil
+	}
+	if err := queue.acquire(ctx); err != nil {
+		return nil, fmt.Errorf("waiting for a %s %s slot: %w", provider, operation, err)
+	}
+	var once sync.Once
+	return func() { once.Do(queue.release) }, nil
+}
+
+// queue returns the operation's queue, or nil when it is not limited
+func (l *Limiter) queue(provider string, operation ComputeOperationType) *operationQueue {
+	limits := l.limits[provider]
+	limit := limits.MaxConcurrentProvisions
+	class := OperationTypeProvision
+	if operation == OperationTypeDelete {
+		limit = limits.MaxConcurrentDestroys
+		class = OperationTypeDelete
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	key := provider + "/" + string(class)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	queue, ok := l.queues[key]
+	if !ok {
+		queue = newOperationQueue(limit)
+		l.queues[key] = queue
+		limiterMetrics.Set(key, queue.metrics)
+	}
+	return queue
+}
+
+// operationQueue is a counting semaphore that grants slots in the order they were asked for
+type operationQueue struct {
+	limit int
+
+	mu      sync.Mutex
+	active  int
+	waiters *list.List // of chan struct{}
+
+	metrics    *expvar.Map
+	inFlight   expvar.Int
+	queued     expvar.Int
+	waited     expvar.Int
+	waitMillis expvar.Int
+}
+
+func newOperationQueue(limit int) *operationQueue {
+	q := &operationQueue{limit: limit, waiters: list.New(), metrics: new(expvar.Map).Init()}
+	limitVar := new(expvar.Int)
+	limitVar.Set(int64(limit))
+	q.metrics.Set("limit", limitVar)
+	q.metrics.Set("in_flight", &q.inFlight)
+	q.metrics.Set("queued", &q.queued)
+	q.metrics.Set("queued_total", &q.waited)
+	q.metrics.Set("queue_wait_ms_total", &q.waitMillis)
+	return q
+}
+
+func (q *operationQueue) acquire(ctx context.Context) error {
+	q.mu.Lock()
+	if q.active < q.limit && q.waiters.Len() == 0 {
+		q.active++
+		q.inFlight.Set(int64(q.active))
+		q.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	waiter := q.waiters.PushBack(ready)
+	q.queued.Set(int64(q.waiters.Len()))
+	q.waited.Add(1)
+	q.mu.Unlock()
+
+	started := time.Now()
+	defer func() { q.waitMillis.Add(time.Since(started).Milliseconds()) }()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		select {
+		case <-ready:
+			// The slot was granted as the context ended; hand it to the next waiter
+			q.releaseLocked()
+		default:
+			q.waiters.Remove(waiter)
+			q.queued.Set(int64(q.waiters.Len()))
+		}
+		return ctx.Err()
+	}
+}
+
+func (q *operationQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.releaseLocked()
+}
+
+// releaseLocked frees a slot, granting it straight to the longest waiting operation if there is one
+func (q *operationQueue) releaseLocked() {
+	if front := q.waiters.Front(); front != nil {
+		q.waiters.Remove(front)
+		q.queued.Set(int64(q.waiters.Len()))
+		close(front.Value.(chan struct{}))
+		return
+	}
+	q.active--
+	q.inFlight.Set(int64(q.active))
+}
@@ -0,0 +1,71 @@
+package compute
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+func TestLimiter(t *testing.T) {
+	limiter := NewLimiter(map[string]config.ComputeLimitsConfig{"docker": {MaxConcurrentProvisions: 1, MaxConcurrentDestroys: 2}})
+	ctx := context.Background()
+
+	// Providers and operations without a limit are not queued
+	var unlimited *Limiter
+	release, err := unlimited.Acquire(ctx, "docker", OperationTypeProvision)
+	require.NoError(t, err)
+	release()
+	for i := 0; i < 3; i++ {
+		_, err := limiter.Acquire(ctx, "ecs", OperationTypeProvision)
+		require.NoError(t, err)
+	}
+
+	first, err := limiter.Acquire(ctx, "docker", OperationTypeProvision)
+	require.NoError(t, err)
+
+	// Destroys have their own limit
+	destroy, err := limiter.Acquire(ctx, "docker", OperationTypeDelete)
+	require.NoError(t, err)
+	destroy()
+
+	// A waiter whose context ends leaves the queue
+	short, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err = limiter.Acquire(short, "docker", OperationTypeUpdate)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Waiters are granted slots in the order they queued
+	order := make(chan int, 3)
+	for i := 1; i <= 3; i++ {
+		go func() {
+			release, err := limiter.Acquire(ctx, "docker", OperationTypeProvision)
+			if assert.NoError(t, err) {
+				order <- i
+				release()
+			}
+		}()
+		require.Eventually(t, func() bool {
+			return limiter.queue("docker", OperationTypeProvision).queued.Value() == int64(i)
+		}, time.Second, time.Millisecond)
+	}
+	first()
+	first() // releasing twice frees one slot
+	for want := 1; want <= 3; want++ {
+		select {
+		case got := <-order:
+			assert.Equal(t, want, got)
+		case <-time.After(time.Second):
+			t.Fatal("queued provision was not granted a slot")
+		}
+	}
+
+	queue := limiter.queue("docker", OperationTypeProvision)
+	require.Eventually(t, func() bool { return queue.inFlight.Value() == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, int64(0), queue.queued.Value())
+	assert.Equal(t, int64(4), queue.waited.Value())
+}
@@ -5,12 +5,15 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/version"
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
 )
 
 // WorkflowProvider defines the minimal interface for workflow callback posting
@@ -28,6 +31,11 @@ type Manager struct {
 	// failedCallbacks stores callbacks that failed delivery for manual retry
 	failedCallbacks   map[string]*FailedCallback
 	failedCallbacksMu sync.RWMutex
+
+	// inflight holds the cancel funcs of the operations each workflow execution has in flight
+	inflight       map[string]map[uint64]context.CancelCauseFunc
+	inflightNextID uint64
+	inflightMu     sync.Mutex
 }
 
 // New creates a new compute manager
@@ -93,6 +101,14 @@ func (m *Manager) ProvisionTenant(ctx context.Context, spec *TenantComputeSpec)
 			zap.String("tenant_id", spec.TenantID),
 			zap.Error(err),
 		)
+		// Tear down what the provision created so a retry does not trip over it
+		if rollbackErr := RollbackProvision(ctx, provider, spec.TenantID, err); rollbackErr != nil {
+			m.logger.Error("failed to roll back partial provisioning",
+				zap.String("tenant_id", spec.TenantID),
+				zap.Error(rollbackErr),
+			)
+			return nil, errors.Join(err, rollbackErr)
+		}
 		return nil, err
 	}
 
@@ -279,8 +295,12 @@ func (m *Manager) ProvisionTenantWithTracking(ctx context.Context, spec *TenantC
 	history.Status = ExecutionStatusRunning
 	_ = m.executionRepository.AddExecutionHistory(ctx, history)
 
-	// Call provider
-	result, err := m.ProvisionTenant(ctx, spec)
+	// Call provider, recording its heartbeats and cancelling it if the workflow execution is stopped
+	var result *ProvisionResult
+	err := m.runOperation(ctx, workflowExecutionID, executionID, func(ctx context.Context) (err error) {
+		result, err = m.ProvisionTenant(ctx, spec)
+		return err
+	})
 	if err != nil {
 		// Mark as failed
 		errCode := "PROVISIONING_FAILED"
@@ -290,8 +310,11 @@ func (m *Manager) ProvisionTenantWithTracking(ctx context.Context, spec *TenantC
 		exec.ErrorMessage = &errMsg
 		_ = m.executionRepository.UpdateComputeExecution(ctx, exec)
 
-		// Add failure history
+		// Add failure history, noting resources a failed rollback left behind for cleanup
 		failureDetails := map[string]string{"error": err.Error()}
+		if errors.Is(err, ErrPartiallyProvisioned) {
+			failureDetails["partially_provisioned"] = "true"
+		}
 		detailsJSON, _ := json.Marshal(failureDetails)
 		history.Status = ExecutionStatusFailed
 		history.Details = detailsJSON
@@ -335,6 +358,34 @@ func (m *Manager) ProvisionTenantWithTracking(ctx context.Context, spec *TenantC
 	return exec, nil
 }
 
+// executionHeartbeatInterval is the most often a running execution's heartbeats are recorded
+const executionHeartbeatInterval = 30 * time.Second
+
+// trackHeartbeats records the heartbeats of the operation running under the returned context in
+// the execution's history, at most once per executionHeartbeatInterval, so a slow execution can
+// be told from one that stopped making progress
+func (m *Manager) trackHeartbeats(ctx context.Context, executionID string) context.Context {
+	var mu sync.Mutex
+	var last time.Time
+	return WithHeartbeat(ctx, func(detail string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if time.Since(last) < executionHeartbeatInterval {
+			return
+		}
+		last = time.Now()
+
+		details, _ := json.Marshal(map[string]string{"heartbeat": detail})
+		if err := m.executionRepository.AddExecutionHistory(ctx, &ComputeExecutionHistory{
+			ComputeExecutionID: executionID,
+			Status:             ExecutionStatusRunning,
+			Details:            details,
+		}); err != nil {
+			m.logger.Warn("failed to record execution heartbeat", zap.String("execution_id", executionID), zap.Error(err))
+		}
+	})
+}
+
 // UpdateTenantWithTracking updates compute with execution tracking
 func (m *Manager) UpdateTenantWithTracking(ctx context.Context, tenantID string, spec *TenantComputeSpec, workflowExecutionID string) (*ComputeExecution, error) {
 	if m.executionRepository == nil {
@@ -367,8 +418,12 @@ func (m *Manager) UpdateTenantWithTracking(ctx context.Context, tenantID string,
 	exec.Status = ExecutionStatusRunning
 	_ = m.executionRepository.UpdateComputeExecution(ctx, exec)
 
-	// Call provider
-	result, err := m.UpdateTenant(ctx, tenantID, spec)
+	// Call provider, recording its heartbeats and cancelling it if the workflow execution is stopped
+	var result *UpdateResult
+	err := m.runOperation(ctx, workflowExecutionID, executionID, func(ctx context.Context) (err error) {
+		result, err = m.UpdateTenant(ctx, tenantID, spec)
+		return err
+	})
 	if err != nil {
 		errCode := "UPDATE_FAILED"
 		errMsg := err.Error()
@@ -427,8 +482,10 @@ func (m *Manager) DeleteTenantWithTracking(ctx context.Context, tenantID, provid
 	exec.Status = ExecutionStatusRunning
 	_ = m.executionRepository.UpdateComputeExecution(ctx, exec)
 
-	// Call provider
-	err := m.DestroyTenant(ctx, tenantID, providerType)
+	// Call provider, recording its heartbeats and cancelling it if the workflow execution is stopped
+	err := m.runOperation(ctx, workflowExecutionID, executionID, func(ctx context.Context) error {
+		return m.DestroyTenant(ctx, tenantID, providerType)
+	})
 	if err != nil {
 		errCode := "DELETE_FAILED"
 		errMsg := err.Error()
@@ -464,40 +521,7 @@ func (m *Manager) GetComputeExecution(ctx context.Context, executionID string) (
 
 // MapProviderErrorToComputeError converts provider errors to standardized ComputeError
 func (m *Manager) MapProviderErrorToComputeError(err error) *ComputeError {
-	if err == nil {
-		return nil
-	}
-
-	errStr := err.Error()
-
-	// Classify common error types
-	isRetriable := false
-	code := "UNKNOWN_ERROR"
-
-	// Check for timeout/temporary errors
-	if strings.Contains(errStr, "timeout") || strings.Contains(errStr, "Timeout") || strings.Contains(errStr, "deadline exceeded") || strings.Contains(errStr, "context deadline exceeded") {
-		code = "PROVIDER_TIMEOUT"
-		isRetriable = true
-	} else if strings.Contains(errStr, "unavailable") || strings.Contains(errStr, "Unavailable") {
-		code = "PROVIDER_UNAVAILABLE"
-		isRetriable = true
-	} else if strings.Contains(errStr, "exhausted") || strings.Contains(errStr, "quota") {
-		code = "RESOURCE_EXHAUSTED"
-		isRetriable = true
-	} else if strings.Contains(errStr, "invalid") || strings.Contains(errStr, "Invalid") {
-		code = "INVALID_CONFIGURATION"
-		isRetriable = false
-	} else if strings.Contains(errStr, "not found") || strings.Contains(errStr, "NotFound") {
-		code = "RESOURCE_NOT_FOUND"
-		isRetriable = false
-	}
-
-	return &ComputeError{
-		Code:          code,
-		Message:       errStr,
-		IsRetriable:   isRetriable,
-		ProviderError: errStr,
-	}
+	return ClassifyError(err)
 }
 
 // postCallbackWithRetry posts a callback to the workflow provider with retry logic
@@ -509,9 +533,11 @@ func (m *Manager) postCallbackWithRetry(ctx context.Context, executionID string,
 
 	// Construct callback payload
 	payload := &CallbackPayload{
-		ExecutionID: executionID,
-		TenantID:    exec.TenantID,
-		Status:      exec.Status,
+		ExecutionID:      executionID,
+		TenantID:         exec.TenantID,
+		Status:           exec.Status,
+		SchemaVersion:    schema.ComputeCallbackVersion,
+		ComponentVersion: version.Version,
 	}
 
 	// Add resource IDs if succeeded
@@ -537,6 +563,17 @@ func (m *Manager) postCallbackWithRetry(ctx context.Context, executionID string,
 		}
 	}
 
+	// An incompatible payload fails the same way on every attempt, so it is stored without retrying
+	if err := schema.Validate(schema.ComputeCallback, payload); err != nil {
+		m.logger.Error("compute callback rejected",
+			zap.String("execution_id", executionID),
+			zap.String("tenant_id", exec.TenantID),
+			zap.Error(err),
+		)
+		m.storeFailedCallback(executionID, payload, err)
+		return
+	}
+
 	// Create callback options with retry settings
 	opts := &CallbackOptions{
 		MaxRetries:  3,
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -332,7 +333,7 @@ func TestCallbackPayloadContainsErrorDetails(t *testing.T) {
 		}).
 		Return(nil)
 
-	providerErr := errors.New("provider error: timeout occurred")
+	providerErr := fmt.Errorf("provider error: %w", context.DeadlineExceeded)
 	provError := &MockComputeProvider{}
 	provError.On("Provision", mock.Anything, mock.Anything).Return(nil, providerErr)
 	registry.Register(provError)
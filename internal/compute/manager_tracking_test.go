@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -320,26 +321,43 @@ func TestComputeManagerTracking(t *testing.T) {
 			name            string
 			providerErr     error
 			expectRetriable bool
+			expectCode      string
 		}{
 			{
 				name:            "timeout error",
-				providerErr:     errors.New("context deadline exceeded"),
+				providerErr:     fmt.Errorf("create service: %w", context.DeadlineExceeded),
 				expectRetriable: true,
+				expectCode:      ErrorCodeTimeout,
 			},
 			{
 				name:            "quota exceeded",
-				providerErr:     errors.New("resource quota exceeded"),
+				providerErr:     fmt.Errorf("%w: no capacity on host", ErrQuotaExceeded),
 				expectRetriable: true,
+				expectCode:      ErrorCodeResourceExhausted,
 			},
 			{
 				name:            "invalid config",
-				providerErr:     errors.New("invalid configuration"),
+				providerErr:     fmt.Errorf("%w: image is required", ErrInvalidConfig),
 				expectRetriable: false,
+				expectCode:      ErrorCodeInvalidConfiguration,
+			},
+			{
+				name:            "marked retriable",
+				providerErr:     Retriable(errors.New("connection reset")),
+				expectRetriable: true,
+				expectCode:      ErrorCodeUnknown,
+			},
+			{
+				name:            "message alone does not classify",
+				providerErr:     errors.New("invalid timeout"),
+				expectRetriable: false,
+				expectCode:      ErrorCodeUnknown,
 			},
 			{
 				name:            "generic error",
 				providerErr:     errors.New("unknown error"),
 				expectRetriable: false,
+				expectCode:      ErrorCodeUnknown,
 			},
 		}
 
@@ -348,6 +366,7 @@ func TestComputeManagerTracking(t *testing.T) {
 				compErr := manager.MapProviderErrorToComputeError(tt.providerErr)
 				assert.NotNil(t, compErr)
 				assert.Equal(t, tt.expectRetriable, compErr.IsRetriable)
+				assert.Equal(t, tt.expectCode, compErr.Code)
 				assert.Equal(t, tt.providerErr.Error(), compErr.ProviderError)
 			})
 		}
@@ -0,0 +1,34 @@
+package compute
+
+import "context"
+
+// Phases providers report while stopping a workload gracefully on archival or deletion
+const (
+	// PhasePreStop is the workload's pre-stop hook running
+	PhasePreStop = "pre-stop"
+
+	// PhaseDraining is the wait, up to the termination grace period, for the workload to finish
+	// its connections and exit
+	PhaseDraining = "draining"
+
+	// PhaseStopping is the workload being stopped and its resources removed
+	PhaseStopping = "stopping"
+)
+
+// PhaseFunc is told when a compute operation moves to a new phase
+type PhaseFunc func(phase string)
+
+type phaseKey struct{}
+
+// WithPhases returns a context whose compute operations report the phases they move through to fn
+func WithPhases(ctx context.Context, fn PhaseFunc) context.Context {
+	return context.WithValue(ctx, phaseKey{}, fn)
+}
+
+// ReportPhase reports that the operation running under ctx moved to phase. It counts as a heartbeat.
+func ReportPhase(ctx context.Context, phase string) {
+	if fn, ok := ctx.Value(phaseKey{}).(PhaseFunc); ok {
+		fn(phase)
+	}
+	Heartbeat(ctx, phase)
+}
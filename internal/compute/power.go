@@ -0,0 +1,17 @@
+package compute
+
+import "context"
+
+// PowerManager is implemented by compute providers that can restart, stop and start a tenant's
+// workloads in place, without reprovisioning them. Scheduled restart, suspend and resume
+// actions require it.
+type PowerManager interface {
+	// Restart restarts the tenant's running workloads
+	Restart(ctx context.Context, tenantID string) error
+
+	// Suspend stops the tenant's workloads, keeping them so Resume can start them again
+	Suspend(ctx context.Context, tenantID string) error
+
+	// Resume starts workloads stopped by Suspend; resuming a running tenant is a no-op
+	Resume(ctx context.Context, tenantID string) error
+}
@@ -49,3 +49,26 @@ type Provider interface {
 	// Return nil when no defaults are available.
 	ConfigDefaults() json.RawMessage
 }
+
+// Reconfigurable is implemented by providers whose default compute_config can change at runtime
+type Reconfigurable interface {
+	// Reconfigure replaces the default compute_config merged into every tenant's config.
+	// Returns an error wrapping ErrInvalidConfig if the defaults are rejected; the old defaults stay in place.
+	Reconfigure(defaults map[string]interface{}) error
+}
+
+// Inventory is implemented by providers that can list the tenants they hold compute resources for,
+// so resources left behind by tenants that no longer exist can be found
+type Inventory interface {
+	// ListTenants returns the tenant ID of every tenant the provider has compute resources for,
+	// whether or not the resources are running
+	ListTenants(ctx context.Context) ([]string, error)
+}
+
+// ProvisionRollbacker is implemented by providers that can tear down what a provision that failed
+// partway created, such as a container that was created but never started
+type ProvisionRollbacker interface {
+	// RollbackProvision removes whatever compute resources the tenant has. Resources that do not
+	// exist are not an error.
+	RollbackProvision(ctx context.Context, tenantID string) error
+}
@@ -1,31 +1,55 @@
 package docker
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	cerrdefs "github.com/containerd/errdefs"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
 	"github.com/docker/go-connections/nat"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"go.uber.org/zap"
 
 	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/logger"
 )
 
 // Provider implements the compute.Provider interface using Docker
 type Provider struct {
 	mu     sync.RWMutex
-	client *client.Client
+	client Runtime
 	logger *zap.Logger
+	defaultsMu       sync.RWMutex
 	defaultConfig    map[string]interface{}
 	defaultConfigRaw json.RawMessage
+	// networkDriver is the driver used for per-tenant networks
+	networkDriver string
+	// networkIsolation is NetworkIsolationShared or NetworkIsolationTenant
+	networkIsolation string
+	// ingressNetwork is attached to tenant containers alongside their own network
+	ingressNetwork string
+	// firewall enforces tenant egress policies; nil when egress_firewall is not configured
+	firewall Firewall
+	// hostCPUs and hostMemory are the daemon's capacity; zero when unknown
+	hostCPUs   int
+	hostMemory int64
+	// platforms are the platforms the daemon can run, native first; empty when unknown
+	platforms []string
+	// hostOS is the daemon's OS ("linux" or "windows"); empty when unknown
+	hostOS string
+	// naming names tenant containers
+	naming NamingConfig
 	// tenantContainers maps tenant IDs to container IDs
 	tenantContainers map[string]string
 	// tenantSpecs stores the specs for provisioned tenants
@@ -49,6 +73,31 @@ type Config struct {
 	// LabelPrefix is used to label containers for identification
 	// Defaults to "landlord"
 	LabelPrefix string `json:"label_prefix,omitempty"`
+
+	// NetworkIsolation selects how tenant containers are networked
+	// "shared" (default) or "tenant", which creates a dedicated network per tenant using NetworkDriver
+	NetworkIsolation string `json:"network_isolation,omitempty"`
+
+	// IngressNetwork is an existing network attached to every tenant container when NetworkIsolation is "tenant",
+	// so a shared ingress can reach tenants that cannot reach each other
+	IngressNetwork string `json:"ingress_network,omitempty"`
+
+	// EmulatedPlatforms are platforms the daemon runs through emulation (binfmt/QEMU) in addition to its native one
+	EmulatedPlatforms []string `json:"emulated_platforms,omitempty"`
+
+	// Runtime is the container engine: "docker" (default), "podman" or "containerd".
+	// For containerd, Host is the containerd socket and nerdctl must be on PATH.
+	Runtime string `json:"runtime,omitempty"`
+
+	// Namespace is the containerd namespace tenant containers run in; containerd only
+	Namespace string `json:"namespace,omitempty"`
+
+	// EgressFirewall enforces compute_config.egress policies: "" (tenants cannot set one) or "iptables".
+	// It requires NetworkIsolation "tenant" and the Docker runtime.
+	EgressFirewall string `json:"egress_firewall,omitempty"`
+
+	// Naming controls the names of tenant containers; the default is "landlord-tenant-<tenant id>"
+	Naming NamingConfig `json:"naming,omitempty"`
 }
 
 const (
@@ -79,31 +128,83 @@ func New(cfg *Config, defaults map[string]interface{}, logger *zap.Logger) (*Pro
 	if cfg.LabelPrefix == "" {
 		cfg.LabelPrefix = defaultLabelPrefix
 	}
-
-	// Allow overriding host via environment variable for in-container scenarios
-	if env := os.Getenv("DOCKER_HOST"); env != "" {
-		cfg.Host = env
+	if cfg.NetworkIsolation == "" {
+		cfg.NetworkIsolation = NetworkIsolationShared
+	}
+	if err := validateNetworkIsolation(cfg); err != nil {
+		return nil, err
+	}
+	if err := validateEmulatedPlatforms(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Runtime == "" {
+		cfg.Runtime = RuntimeDocker
+	}
+	if err := validateRuntime(cfg); err != nil {
+		return nil, err
+	}
+	if err := validateEgressFirewall(cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Naming.Prefix == "" {
+		cfg.Naming.Prefix = defaultNamingPrefix
+	}
+	if cfg.Naming.ID == "" {
+		cfg.Naming.ID = NamingIDFull
+	}
+	if err := validateNaming(&cfg.Naming); err != nil {
+		return nil, err
+	}
+	var firewall Firewall
+	if cfg.EgressFirewall == EgressFirewallIPTables {
+		iptables, err := newIPTablesFirewall(logger)
+		if err != nil {
+			return nil, err
+		}
+		firewall = iptables
 	}
 
-	// Create Docker client
-	// If Host is empty, client.NewClientWithOpts will use the standard Docker socket
-	opts := []client.Opt{}
-	if cfg.Host != "" {
-		opts = append(opts, client.WithHost(cfg.Host))
+	// Allow overriding host via environment variable for in-container scenarios.
+	// nerdctl reads CONTAINERD_ADDRESS itself, so DOCKER_HOST does not apply to containerd.
+	if env := os.Getenv("DOCKER_HOST"); env != "" && cfg.Runtime != RuntimeContainerd {
+		cfg.Host = env
 	}
 
-	cli, err := client.NewClientWithOpts(opts...)
+	cli, err := newRuntime(cfg)
 	if err != nil {
-		logger.Error("failed to create docker client", zap.Error(err))
-		return nil, fmt.Errorf("failed to create docker client: %w", err)
+		logger.Error("failed to create container runtime client", zap.String("runtime", cfg.Runtime), zap.Error(err))
+		return nil, fmt.Errorf("failed to create %s client: %w", cfg.Runtime, err)
 	}
 
 	// Test the connection
 	_, err = cli.Ping(context.Background())
 	if err != nil {
-		logger.Error("failed to connect to docker daemon", zap.Error(err))
+		logger.Error("failed to connect to container runtime", zap.String("runtime", cfg.Runtime), zap.Error(err))
 		cli.Close()
-		return nil, fmt.Errorf("failed to connect to docker daemon: %w", err)
+		return nil, fmt.Errorf("failed to connect to %s daemon: %w", cfg.Runtime, classifyDockerError(err))
+	}
+
+	// Host capacity bounds resource limits and the host platform bounds which platforms tenants can request;
+	// without them both are passed through for the daemon to reject
+	var hostCPUs int
+	var hostMemory int64
+	var platforms []string
+	var hostOS string
+	if info, err := cli.Info(context.Background()); err != nil {
+		logger.Warn("failed to read docker host capacity, resource limits and platforms will not be checked against it", zap.Error(err))
+	} else {
+		hostCPUs, hostMemory, hostOS = info.NCPU, info.MemTotal, info.OSType
+		if native := daemonPlatform(info.OSType, info.Architecture); native != "" {
+			platforms = append(platforms, native)
+			for _, platform := range cfg.EmulatedPlatforms {
+				if !slices.Contains(platforms, platform) {
+					platforms = append(platforms, platform)
+				}
+			}
+		} else {
+			logger.Warn("unrecognised docker host architecture, platforms will not be checked against it",
+				zap.String("os", info.OSType), zap.String("architecture", info.Architecture))
+		}
 	}
 
 	p := &Provider{
@@ -111,11 +212,27 @@ func New(cfg *Config, defaults map[string]interface{}, logger *zap.Logger) (*Pro
 		logger:           logger,
 		defaultConfig:    copyConfigMap(defaults),
 		defaultConfigRaw: marshalConfigMap(defaults),
+		networkDriver:    cfg.NetworkDriver,
+		networkIsolation: cfg.NetworkIsolation,
+		ingressNetwork:   cfg.IngressNetwork,
+		firewall:         firewall,
+		hostCPUs:         hostCPUs,
+		hostMemory:       hostMemory,
+		platforms:        platforms,
+		hostOS:           hostOS,
+		naming:           cfg.Naming,
 		tenantContainers: make(map[string]string),
 		tenantSpecs:      make(map[string]*compute.TenantComputeSpec),
 	}
 
-	logger.Info("docker provider initialized", zap.String("host", cfg.Host), zap.String("network", cfg.NetworkName))
+	logger.Info("docker provider initialized",
+		zap.String("runtime", cfg.Runtime),
+		zap.String("host", cfg.Host),
+		zap.String("network", cfg.NetworkName),
+		zap.String("network_isolation", cfg.NetworkIsolation),
+		zap.String("egress_firewall", cfg.EgressFirewall),
+		zap.String("os", hostOS),
+		zap.Strings("platforms", platforms))
 	return p, nil
 }
 
@@ -130,134 +247,10 @@ func (p *Provider) Provision(ctx context.Context, spec *compute.TenantComputeSpe
 	defer p.mu.Unlock()
 
 	if _, exists := p.tenantContainers[spec.TenantID]; exists {
-		return nil, fmt.Errorf("tenant %s already provisioned", spec.TenantID)
-	}
-
-	// Each tenant gets exactly one container
-	if len(spec.Containers) != 1 {
-		return nil, fmt.Errorf("docker provider expects exactly 1 container, got %d", len(spec.Containers))
-	}
-
-	parsedConfig, err := parseProviderConfig(p.defaultConfig, spec.ProviderConfig)
-	if err != nil {
-		return nil, err
-	}
-	if err := applyProviderConfig(spec, parsedConfig); err != nil {
-		return nil, err
-	}
-
-	containerSpec := spec.Containers[0]
-
-	// Create container config
-	containerConfig := &container.Config{
-		Image: containerSpec.Image,
-		Env:   convertEnv(containerSpec.Env),
-	}
-	labels := buildContainerLabels(spec, parsedConfig)
-	if len(labels) > 0 {
-		containerConfig.Labels = labels
-	}
-
-	if len(containerSpec.Command) > 0 {
-		containerConfig.Cmd = containerSpec.Command
-	}
-	if len(containerSpec.Args) > 0 {
-		if containerConfig.Cmd == nil {
-			containerConfig.Cmd = containerSpec.Args
-		} else {
-			containerConfig.Cmd = append(containerConfig.Cmd, containerSpec.Args...)
-		}
+		return nil, fmt.Errorf("%w: %s", compute.ErrAlreadyProvisioned, spec.TenantID)
 	}
 
-	// Create port bindings
-	portMap := nat.PortMap{}
-	for _, port := range containerSpec.Ports {
-		natPort, err := nat.NewPort(port.Protocol, fmt.Sprintf("%d", port.ContainerPort))
-		if err != nil {
-			p.logger.Error("invalid port specification", zap.Int("port", port.ContainerPort), zap.Error(err))
-			continue
-		}
-		portMap[natPort] = []nat.PortBinding{
-			{
-				HostIP:   "0.0.0.0",
-				HostPort: fmt.Sprintf("%d", port.HostPort),
-			},
-		}
-	}
-
-	// Create host config with resource limits
-	hostConfig := &container.HostConfig{
-		PortBindings: portMap,
-		RestartPolicy: container.RestartPolicy{
-			Name:              "unless-stopped",
-			MaximumRetryCount: 0,
-		},
-	}
-	if parsedConfig != nil {
-		if len(parsedConfig.Volumes) > 0 {
-			hostConfig.Binds = parsedConfig.Volumes
-		}
-		if parsedConfig.NetworkMode != "" {
-			hostConfig.NetworkMode = container.NetworkMode(parsedConfig.NetworkMode)
-		}
-		if parsedConfig.RestartPolicy != "" {
-			hostConfig.RestartPolicy = container.RestartPolicy{
-				Name:              container.RestartPolicyMode(parsedConfig.RestartPolicy),
-				MaximumRetryCount: 0,
-			}
-		}
-	}
-
-	// Set resource limits
-	if spec.Resources.CPU > 0 {
-		hostConfig.CPUQuota = int64(spec.Resources.CPU)
-	}
-	if spec.Resources.Memory > 0 {
-		hostConfig.Memory = int64(spec.Resources.Memory * 1024 * 1024) // Convert MB to bytes
-	}
-
-	// Create container
-	containerName := fmt.Sprintf("%s-tenant-%s", defaultLabelPrefix, spec.TenantID)
-	resp, err := p.client.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, containerName)
-	if err != nil {
-		p.logger.Error("failed to create container", zap.String("tenant_id", spec.TenantID), zap.Error(err))
-		return nil, fmt.Errorf("failed to create container: %w", err)
-	}
-
-	containerID := resp.ID
-
-	// Start container
-	if err := p.client.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
-		p.logger.Error("failed to start container", zap.String("container_id", containerID), zap.Error(err))
-		// Clean up on start failure
-		p.client.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: true})
-		return nil, fmt.Errorf("failed to start container: %w", err)
-	}
-
-	// Store references
-	p.tenantContainers[spec.TenantID] = containerID
-	p.tenantSpecs[spec.TenantID] = spec
-
-	// Get container inspect to build endpoints
-	inspectResp, err := p.client.ContainerInspect(ctx, containerID)
-	if err != nil {
-		p.logger.Error("failed to inspect container", zap.String("container_id", containerID), zap.Error(err))
-		return nil, fmt.Errorf("failed to inspect container: %w", err)
-	}
-
-	endpoints := buildEndpoints(&containerSpec, &inspectResp)
-
-	p.logger.Info("container provisioned", zap.String("tenant_id", spec.TenantID), zap.String("container_id", containerID))
-
-	return &compute.ProvisionResult{
-		TenantID:      spec.TenantID,
-		ProviderType:  "docker",
-		Status:        compute.ProvisionStatusSuccess,
-		ResourceIDs:   map[string]string{"container_id": containerID},
-		Endpoints:     endpoints,
-		Message:       "Container provisioned successfully",
-		ProvisionedAt: time.Now(),
-	}, nil
+	return p.provisionInternal(ctx, spec)
 }
 
 // Update modifies an existing tenant's container
@@ -265,56 +258,54 @@ func (p *Provider) Update(ctx context.Context, tenantID string, spec *compute.Te
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	containerID, exists := p.tenantContainers[tenantID]
-	if !exists {
-		return nil, fmt.Errorf("%w: %s", compute.ErrTenantNotFound, tenantID)
-	}
-
-	oldSpec := p.tenantSpecs[tenantID]
-	changes := []string{}
-
-	parsedConfig, err := parseProviderConfig(p.defaultConfig, spec.ProviderConfig)
+	parsedConfig, err := parseProviderConfig(p.defaults(), spec.ProviderConfig)
 	if err != nil {
 		return nil, err
 	}
 	if err := applyProviderConfig(spec, parsedConfig); err != nil {
 		return nil, err
 	}
-
-	// Check for changes
-	if len(spec.Containers) != len(oldSpec.Containers) {
-		return nil, fmt.Errorf("cannot change number of containers")
-	}
-
-	oldContainer := oldSpec.Containers[0]
-	newContainer := spec.Containers[0]
-
-	// If image changed, we need to recreate the container
-	if oldContainer.Image != newContainer.Image {
-		changes = append(changes, "container image changed")
-	}
-
-	// If ports changed, we need to recreate the container
-	if !portMappingsEqual(oldContainer.Ports, newContainer.Ports) {
-		changes = append(changes, "port mappings changed")
-	}
-	// If environment changed, we need to recreate the container
-	if !mapsEqual(oldContainer.Env, newContainer.Env) {
-		changes = append(changes, "environment variables changed")
-	}
-	// Provider-specific config changes should trigger recreation
-	if !bytes.Equal(oldSpec.ProviderConfig, spec.ProviderConfig) {
-		changes = append(changes, "provider config changed")
+	next, err := fingerprintSpec(spec)
+	if err != nil {
+		return nil, err
 	}
 
-	// If resources changed, we can update some but may need restart
-	resourcesChanged := oldSpec.Resources.CPU != spec.Resources.CPU ||
-		oldSpec.Resources.Memory != spec.Resources.Memory
-	if resourcesChanged {
-		changes = append(changes, "resource limits changed")
+	// Check for changes against the provisioned spec, or when it is not in memory, such as after a
+	// restart or for a container another process created, against the container's labels
+	var changes []string
+	containerID, exists := p.tenantContainers[tenantID]
+	if exists {
+		current, err := fingerprintSpec(p.tenantSpecs[tenantID])
+		if err != nil {
+			return nil, err
+		}
+		changes = current.changes(next)
+	} else {
+		if containerID, err = p.findTenantContainer(ctx, tenantID); err != nil {
+			return nil, err
+		}
+		inspectResp, err := p.client.ContainerInspect(ctx, containerID)
+		if err != nil {
+			p.logger.Error("failed to inspect container", zap.String("container_id", containerID), zap.Error(err))
+			return nil, fmt.Errorf("failed to inspect container: %w", classifyDockerError(err))
+		}
+		var labels map[string]string
+		if inspectResp.Config != nil {
+			labels = inspectResp.Config.Labels
+		}
+		if current, ok := fingerprintFromLabels(labels); ok {
+			changes = current.changes(next)
+		} else {
+			changes = []string{"provisioned spec unknown"}
+		}
 	}
 
 	if len(changes) == 0 {
+		// An unchanged container found by its labels is tracked from now on
+		if !exists {
+			p.tenantContainers[tenantID] = containerID
+			p.tenantSpecs[tenantID] = spec
+		}
 		return &compute.UpdateResult{
 			TenantID:     tenantID,
 			ProviderType: "docker",
@@ -334,7 +325,7 @@ func (p *Provider) Update(ctx context.Context, tenantID string, spec *compute.Te
 
 		if err := p.client.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: true}); err != nil {
 			p.logger.Error("failed to remove container during update", zap.String("container_id", containerID), zap.Error(err))
-			return nil, fmt.Errorf("failed to remove container: %w", err)
+			return nil, fmt.Errorf("failed to remove container: %w", classifyDockerError(err))
 		}
 
 		// Re-provision with new spec
@@ -363,37 +354,97 @@ func (p *Provider) Update(ctx context.Context, tenantID string, spec *compute.Te
 	}, nil
 }
 
-// Destroy removes a tenant's container
+// Destroy removes a tenant's container, including one created by an earlier process or a workflow
+// worker
 func (p *Provider) Destroy(ctx context.Context, tenantID string) error {
 	p.mu.Lock()
-	defer p.mu.Unlock()
+	containerID, tracked := p.tenantContainers[tenantID]
+	p.mu.Unlock()
 
-	containerID, exists := p.tenantContainers[tenantID]
-	if !exists {
-		// Idempotent - don't error if already gone
-		return nil
+	if !tracked {
+		found, err := p.findTenantContainer(ctx, tenantID)
+		if err != nil && !errors.Is(err, compute.ErrTenantNotFound) {
+			return err
+		}
+		containerID = found
 	}
 
-	// Stop the container
-	stopCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-	timeout := 10 // seconds
+	// Stop the container without holding the lock, since its grace period can be long
+	if containerID != "" {
+		p.stopGracefully(ctx, tenantID, containerID)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	if err := p.client.ContainerStop(stopCtx, containerID, container.StopOptions{Timeout: &timeout}); err != nil {
-		// Log but continue with removal
-		p.logger.Warn("failed to stop container", zap.String("container_id", containerID), zap.Error(err))
+	// A concurrent Destroy may have removed a tracked container while this one stopped it
+	if tracked {
+		containerID = p.tenantContainers[tenantID]
+	}
+	if containerID == "" {
+		// Idempotent - don't error if already gone, but don't leave an isolated network or its rules behind
+		if err := p.applyEgressPolicy(ctx, tenantID, nil); err != nil {
+			return err
+		}
+		if p.isolated() {
+			return p.removeTenantNetwork(ctx, tenantID)
+		}
+		return nil
 	}
 
 	// Remove the container
-	if err := p.client.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: true}); err != nil {
+	if err := p.client.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: true}); err != nil && !cerrdefs.IsNotFound(err) {
 		p.logger.Error("failed to remove container", zap.String("container_id", containerID), zap.Error(err))
-		return fmt.Errorf("failed to remove container: %w", err)
+		return fmt.Errorf("failed to remove container: %w", classifyDockerError(err))
 	}
 
 	delete(p.tenantContainers, tenantID)
 	delete(p.tenantSpecs, tenantID)
 
 	p.logger.Info("container destroyed", zap.String("tenant_id", tenantID), zap.String("container_id", containerID))
+
+	if err := p.applyEgressPolicy(ctx, tenantID, nil); err != nil {
+		return err
+	}
+	if p.isolated() {
+		return p.removeTenantNetwork(ctx, tenantID)
+	}
+	return nil
+}
+
+// RollbackProvision removes what a provision that failed partway created: the tenant's container,
+// including one left behind by another process, its egress rules and its isolated network
+func (p *Provider) RollbackProvision(ctx context.Context, tenantID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	containerID, exists := p.tenantContainers[tenantID]
+	if !exists {
+		found, err := p.findTenantContainer(ctx, tenantID)
+		if err != nil && !errors.Is(err, compute.ErrTenantNotFound) {
+			return err
+		}
+		containerID = found
+	}
+	if containerID != "" {
+		if err := p.client.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: true}); err != nil && !cerrdefs.IsNotFound(err) {
+			p.logger.Error("failed to remove partially provisioned container", zap.String("container_id", containerID), zap.Error(err))
+			return fmt.Errorf("failed to remove container: %w", classifyDockerError(err))
+		}
+	}
+	delete(p.tenantContainers, tenantID)
+	delete(p.tenantSpecs, tenantID)
+
+	if err := p.applyEgressPolicy(ctx, tenantID, nil); err != nil {
+		return err
+	}
+	if p.isolated() {
+		if err := p.removeTenantNetwork(ctx, tenantID); err != nil {
+			return err
+		}
+	}
+
+	p.logger.Info("partial provisioning rolled back", zap.String("tenant_id", tenantID), zap.String("container_id", containerID))
 	return nil
 }
 
@@ -401,46 +452,58 @@ func (p *Provider) Destroy(ctx context.Context, tenantID string) error {
 func (p *Provider) GetStatus(ctx context.Context, tenantID string) (*compute.ComputeStatus, error) {
 	p.mu.RLock()
 	containerID, exists := p.tenantContainers[tenantID]
+	spec := p.tenantSpecs[tenantID]
 	p.mu.RUnlock()
 
 	if !exists {
-		return nil, fmt.Errorf("%w: %s", compute.ErrTenantNotFound, tenantID)
+		// The container may have been created by another process, such as a workflow worker
+		var err error
+		if containerID, err = p.findTenantContainer(ctx, tenantID); err != nil {
+			return nil, err
+		}
 	}
 
 	inspectResp, err := p.client.ContainerInspect(ctx, containerID)
 	if err != nil {
 		p.logger.Error("failed to inspect container", zap.String("container_id", containerID), zap.Error(err))
-		return nil, fmt.Errorf("failed to inspect container: %w", err)
+		return nil, fmt.Errorf("failed to inspect container: %w", classifyDockerError(err))
 	}
 
-	return buildComputeStatus(tenantID, &inspectResp), nil
+	return buildComputeStatus(tenantID, &inspectResp, spec != nil && spec.IsJob()), nil
 }
 
 // Validate validates a compute spec for Docker
 func (p *Provider) Validate(ctx context.Context, spec *compute.TenantComputeSpec) error {
-	// Docker provider expects exactly one container
-	if len(spec.Containers) != 1 {
-		return fmt.Errorf("docker provider requires exactly 1 container, got %d", len(spec.Containers))
+	// Docker provider expects exactly one workload container, after any init containers
+	if _, err := workloadIndex(spec); err != nil {
+		return compute.Mark(err, compute.ErrInvalidConfig)
 	}
 
-	parsedConfig, err := parseProviderConfig(p.defaultConfig, spec.ProviderConfig)
+	parsedConfig, err := parseProviderConfig(p.defaults(), spec.ProviderConfig)
 	if err != nil {
-		return err
+		return compute.Mark(err, compute.ErrInvalidConfig)
 	}
 	if err := applyProviderConfig(spec, parsedConfig); err != nil {
+		return compute.Mark(err, compute.ErrInvalidConfig)
+	}
+	if err := p.checkOS(parsedConfig); err != nil {
 		return err
 	}
 
-	containerSpec := spec.Containers[0]
+	for _, containerSpec := range spec.Containers {
+		// Validate container image
+		if containerSpec.Image == "" {
+			return fmt.Errorf("%w: container image is required", compute.ErrInvalidConfig)
+		}
 
-	// Validate container image
-	if containerSpec.Image == "" {
-		return fmt.Errorf("container image is required")
-	}
+		// Basic image format validation
+		if !isValidImageRef(containerSpec.Image) {
+			return fmt.Errorf("%w: invalid image reference: %s", compute.ErrInvalidConfig, containerSpec.Image)
+		}
 
-	// Basic image format validation
-	if !isValidImageRef(containerSpec.Image) {
-		return fmt.Errorf("invalid image reference: %s", containerSpec.Image)
+		if err := p.checkPlatform(containerSpec.Platform); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -454,11 +517,7 @@ func (p *Provider) Close() error {
 // Internal helper methods
 
 func (p *Provider) provisionInternal(ctx context.Context, spec *compute.TenantComputeSpec) (*compute.ProvisionResult, error) {
-	if len(spec.Containers) != 1 {
-		return nil, fmt.Errorf("docker provider expects exactly 1 container, got %d", len(spec.Containers))
-	}
-
-	parsedConfig, err := parseProviderConfig(p.defaultConfig, spec.ProviderConfig)
+	parsedConfig, err := parseProviderConfig(p.defaults(), spec.ProviderConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -466,16 +525,22 @@ func (p *Provider) provisionInternal(ctx context.Context, spec *compute.TenantCo
 		return nil, err
 	}
 
-	containerSpec := spec.Containers[0]
+	index, err := workloadIndex(spec)
+	if err != nil {
+		return nil, err
+	}
+	containerSpec := spec.Containers[index]
 
 	containerConfig := &container.Config{
 		Image: containerSpec.Image,
 		Env:   convertEnv(containerSpec.Env),
 	}
 	labels := buildContainerLabels(spec, parsedConfig)
-	if len(labels) > 0 {
-		containerConfig.Labels = labels
+	fingerprint, err := fingerprintSpec(spec)
+	if err != nil {
+		return nil, err
 	}
+	containerConfig.Labels = compute.MergeLabels(labels, gracefulStopLabels(parsedConfig), fingerprint.labels())
 
 	if len(containerSpec.Command) > 0 {
 		containerConfig.Cmd = containerSpec.Command
@@ -488,6 +553,13 @@ func (p *Provider) provisionInternal(ctx context.Context, spec *compute.TenantCo
 		}
 	}
 
+	// WinNAT publishes on every host address and does not take a host IP
+	hostIP := "0.0.0.0"
+	windows := containerOS(parsedConfig) == OSWindows
+	if windows {
+		hostIP = ""
+	}
+
 	portMap := nat.PortMap{}
 	for _, port := range containerSpec.Ports {
 		natPort, err := nat.NewPort(port.Protocol, fmt.Sprintf("%d", port.ContainerPort))
@@ -497,7 +569,7 @@ func (p *Provider) provisionInternal(ctx context.Context, spec *compute.TenantCo
 		}
 		portMap[natPort] = []nat.PortBinding{
 			{
-				HostIP:   "0.0.0.0",
+				HostIP:   hostIP,
 				HostPort: fmt.Sprintf("%d", port.HostPort),
 			},
 		}
@@ -510,6 +582,13 @@ func (p *Provider) provisionInternal(ctx context.Context, spec *compute.TenantCo
 			MaximumRetryCount: 0,
 		},
 	}
+	if containerSpec.Kind == compute.ContainerKindJob {
+		// Jobs are done when they exit; restarting them would run them again
+		hostConfig.RestartPolicy.Name = container.RestartPolicyDisabled
+	}
+	if windows {
+		applyWindowsHostConfig(hostConfig, parsedConfig)
+	}
 	if parsedConfig != nil {
 		if len(parsedConfig.Volumes) > 0 {
 			hostConfig.Binds = parsedConfig.Volumes
@@ -523,28 +602,114 @@ func (p *Provider) provisionInternal(ctx context.Context, spec *compute.TenantCo
 				MaximumRetryCount: 0,
 			}
 		}
+		devices, err := parseDeviceMappings(parsedConfig.Devices)
+		if err != nil {
+			return nil, compute.Mark(err, compute.ErrInvalidConfig)
+		}
+		hostConfig.Devices = devices
+		if parsedConfig.GPUs != nil {
+			hostConfig.DeviceRequests = []container.DeviceRequest{buildGPURequest(parsedConfig.GPUs)}
+		}
 	}
 
+	if err := p.checkOS(parsedConfig); err != nil {
+		return nil, err
+	}
+	if err := p.checkHostCapacity(spec.Resources); err != nil {
+		return nil, err
+	}
 	if spec.Resources.CPU > 0 {
-		hostConfig.CPUQuota = int64(spec.Resources.CPU)
+		hostConfig.NanoCPUs = spec.Resources.NanoCPUs()
 	}
 	if spec.Resources.Memory > 0 {
-		hostConfig.Memory = int64(spec.Resources.Memory * 1024 * 1024)
+		hostConfig.Memory = spec.Resources.MemoryBytes()
+	}
+	if parsedConfig != nil && parsedConfig.Limits != nil {
+		applyKernelLimits(hostConfig, parsedConfig.Limits)
 	}
 
-	containerName := fmt.Sprintf("%s-tenant-%s", defaultLabelPrefix, spec.TenantID)
-	resp, err := p.client.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, containerName)
+	resourceIDs := map[string]string{}
+	if p.isolated() {
+		if parsedConfig != nil && parsedConfig.NetworkMode != "" {
+			return nil, fmt.Errorf("%w: network_mode cannot be set when network isolation is %q", compute.ErrInvalidConfig, NetworkIsolationTenant)
+		}
+		networkID, err := p.ensureTenantNetwork(ctx, spec)
+		if err != nil {
+			return nil, err
+		}
+		hostConfig.NetworkMode = container.NetworkMode(tenantNetworkName(spec.TenantID))
+		resourceIDs["network_id"] = networkID
+	}
+
+	// The policy is in place before anything runs on the tenant's network, init containers included.
+	// Applying it on every provision also removes the rules of a policy an update dropped.
+	var egress *compute.EgressPolicy
+	if parsedConfig != nil {
+		egress = parsedConfig.Egress
+	}
+	if egress != nil && !p.isolated() {
+		return nil, fmt.Errorf("%w: egress requires network isolation %q", compute.ErrInvalidConfig, NetworkIsolationTenant)
+	}
+	if err := p.applyEgressPolicy(ctx, spec.TenantID, egress); err != nil {
+		return nil, err
+	}
+
+	// A requested platform is checked and pulled before anything runs, so a host that cannot run it fails fast
+	var platform *ocispec.Platform
+	if containerSpec.Platform != "" {
+		if err := p.checkPlatform(containerSpec.Platform); err != nil {
+			return nil, err
+		}
+		if platform, err = parsePlatform(containerSpec.Platform); err != nil {
+			return nil, compute.Mark(err, compute.ErrInvalidConfig)
+		}
+		if err := p.pullImage(ctx, containerSpec.Image, containerSpec.Platform); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.runInitContainers(ctx, spec); err != nil {
+		return nil, err
+	}
+
+	containerName, err := p.resolveContainerName(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	if logger.DebugFlag(p.logger, logger.FlagDockerContainerConfig) {
+		p.logger.Info("creating container",
+			zap.String("tenant_id", spec.TenantID),
+			zap.String("container_name", containerName),
+			zap.Any("container_config", containerConfig),
+			zap.Any("host_config", hostConfig),
+		)
+	}
+	resp, err := p.client.ContainerCreate(ctx, containerConfig, hostConfig, nil, platform, containerName)
 	if err != nil {
 		p.logger.Error("failed to create container", zap.String("tenant_id", spec.TenantID), zap.Error(err))
-		return nil, fmt.Errorf("failed to create container: %w", err)
+		// The daemon may have created the container before the request was cancelled
+		if ctx.Err() != nil {
+			p.removePartialContainer(ctx, containerName)
+		}
+		return nil, fmt.Errorf("failed to create container: %w", classifyDockerError(err))
 	}
 
 	containerID := resp.ID
+	resourceIDs["container_id"] = containerID
+
+	// The ingress network is attached before start so the tenant is reachable as soon as it runs
+	if p.isolated() && p.ingressNetwork != "" {
+		if err := p.client.NetworkConnect(ctx, p.ingressNetwork, containerID, nil); err != nil {
+			p.logger.Error("failed to connect container to ingress network", zap.String("container_id", containerID), zap.Error(err))
+			p.removePartialContainer(ctx, containerID)
+			return nil, fmt.Errorf("failed to connect container to ingress network: %w", classifyDockerError(err))
+		}
+	}
 
 	if err := p.client.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
 		p.logger.Error("failed to start container", zap.String("container_id", containerID), zap.Error(err))
-		p.client.ContainerRemove(ctx, containerID, container.RemoveOptions{Force: true})
-		return nil, fmt.Errorf("failed to start container: %w", err)
+		p.removePartialContainer(ctx, containerID)
+		return nil, fmt.Errorf("failed to start container: %w", classifyDockerError(err))
 	}
 
 	p.tenantContainers[spec.TenantID] = containerID
@@ -553,24 +718,45 @@ func (p *Provider) provisionInternal(ctx context.Context, spec *compute.TenantCo
 	inspectResp, err := p.client.ContainerInspect(ctx, containerID)
 	if err != nil {
 		p.logger.Error("failed to inspect container", zap.String("container_id", containerID), zap.Error(err))
-		return nil, fmt.Errorf("failed to inspect container: %w", err)
+		// A cancelled provision does not leave a running container behind for the next attempt to trip over
+		if ctx.Err() != nil {
+			p.removePartialContainer(ctx, containerID)
+			delete(p.tenantContainers, spec.TenantID)
+			delete(p.tenantSpecs, spec.TenantID)
+		}
+		return nil, fmt.Errorf("failed to inspect container: %w", classifyDockerError(err))
 	}
 
-	endpoints := buildEndpoints(&containerSpec, &inspectResp)
+	endpoints := buildEndpoints(&containerSpec, &inspectResp, p.ingressNetwork)
 
 	p.logger.Info("container provisioned", zap.String("tenant_id", spec.TenantID), zap.String("container_id", containerID))
 
+	message := "Container provisioned successfully"
+	if containerSpec.Kind == compute.ContainerKindJob {
+		message = "Job container started"
+	}
+
 	return &compute.ProvisionResult{
 		TenantID:      spec.TenantID,
 		ProviderType:  "docker",
 		Status:        compute.ProvisionStatusSuccess,
-		ResourceIDs:   map[string]string{"container_id": containerID},
+		ResourceIDs:   resourceIDs,
 		Endpoints:     endpoints,
-		Message:       "Container provisioned successfully",
+		Message:       message,
 		ProvisionedAt: time.Now(),
 	}, nil
 }
 
+// removePartialContainer removes a container a failed or cancelled provision created. It uses a
+// fresh context, since the provision's may be the one that was cancelled.
+func (p *Provider) removePartialContainer(ctx context.Context, ref string) {
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	if err := p.client.ContainerRemove(cleanupCtx, ref, container.RemoveOptions{Force: true}); err != nil && !cerrdefs.IsNotFound(err) {
+		p.logger.Warn("failed to remove partially provisioned container", zap.String("container", ref), zap.Error(err))
+	}
+}
+
 func convertEnv(envMap map[string]string) []string {
 	env := make([]string, 0, len(envMap))
 	for k, v := range envMap {
@@ -589,18 +775,23 @@ func buildContainerLabels(spec *compute.TenantComputeSpec, parsedConfig *DockerC
 		return compute.MergeLabels(providerLabels)
 	}
 
-	return compute.MergeLabels(spec.Labels, providerLabels, compute.DefaultMetadata(spec))
+	// Managed labels win over compute_config labels, so tenants cannot relabel their containers
+	return compute.MergeLabels(providerLabels, spec.Labels, compute.DefaultMetadata(spec))
 }
 
-func buildEndpoints(containerSpec *compute.ContainerSpec, inspect *types.ContainerJSON) []compute.Endpoint {
+// buildEndpoints builds tenant endpoints, preferring the address on preferredNetwork when the container is on it
+func buildEndpoints(containerSpec *compute.ContainerSpec, inspect *types.ContainerJSON, preferredNetwork string) []compute.Endpoint {
 	endpoints := []compute.Endpoint{}
 
-	// Get the container's IP address (from first network)
+	// Get the container's IP address (from the preferred network, else the first network)
 	var containerIP string
 	if inspect.NetworkSettings != nil && len(inspect.NetworkSettings.Networks) > 0 {
-		// Get first network's IP
+		if netSettings, ok := inspect.NetworkSettings.Networks[preferredNetwork]; ok && netSettings != nil {
+			containerIP = netSettings.IPAddress
+		}
+		// Otherwise get first network's IP
 		for _, netSettings := range inspect.NetworkSettings.Networks {
-			if netSettings.IPAddress != "" {
+			if containerIP == "" && netSettings.IPAddress != "" {
 				containerIP = netSettings.IPAddress
 				break
 			}
@@ -633,7 +824,8 @@ func buildEndpoints(containerSpec *compute.ContainerSpec, inspect *types.Contain
 	return endpoints
 }
 
-func buildComputeStatus(tenantID string, inspect *types.ContainerJSON) *compute.ComputeStatus {
+// buildComputeStatus maps a container to a compute status; a job that exited cleanly is completed rather than stopped
+func buildComputeStatus(tenantID string, inspect *types.ContainerJSON, job bool) *compute.ComputeStatus {
 	containerStatus := compute.ContainerStatus{
 		Name:    inspect.Name,
 		Ready:   inspect.State.Running,
@@ -664,6 +856,30 @@ func buildComputeStatus(tenantID string, inspect *types.ContainerJSON) *compute.
 		health = compute.HealthStatusHealthy
 	}
 
+	metadata := map[string]string{
+		"container_id": inspect.ID,
+		"image":        inspect.Config.Image,
+	}
+	if inspect.Image != "" {
+		metadata["image_id"] = inspect.Image
+	}
+	if ports := publishedPorts(inspect); ports != "" {
+		metadata["ports"] = ports
+	}
+
+	if job && inspect.State.Status == "exited" {
+		metadata["exit_code"] = fmt.Sprintf("%d", inspect.State.ExitCode)
+		if inspect.State.ExitCode == 0 {
+			state = compute.ComputeStateCompleted
+			health = compute.HealthStatusHealthy
+			containerStatus.State = "completed"
+		} else {
+			state = compute.ComputeStateFailed
+			health = compute.HealthStatusUnhealthy
+			containerStatus.State = "failed"
+		}
+	}
+
 	return &compute.ComputeStatus{
 		TenantID:     tenantID,
 		ProviderType: "docker",
@@ -671,11 +887,24 @@ func buildComputeStatus(tenantID string, inspect *types.ContainerJSON) *compute.
 		Containers:   []compute.ContainerStatus{containerStatus},
 		Health:       health,
 		LastUpdated:  time.Now(),
-		Metadata: map[string]string{
-			"container_id": inspect.ID,
-			"image":        inspect.Config.Image,
-		},
+		Metadata:     metadata,
+		ResourceIDs:  map[string]string{"container_id": inspect.ID},
+	}
+}
+
+// publishedPorts lists a container's published ports as "8080/tcp=0.0.0.0:32768", sorted and comma separated
+func publishedPorts(inspect *types.ContainerJSON) string {
+	if inspect.NetworkSettings == nil {
+		return ""
+	}
+	var ports []string
+	for port, bindings := range inspect.NetworkSettings.Ports {
+		for _, binding := range bindings {
+			ports = append(ports, fmt.Sprintf("%s=%s:%s", port, binding.HostIP, binding.HostPort))
+		}
 	}
+	sort.Strings(ports)
+	return strings.Join(ports, ",")
 }
 
 func mapsEqual(a, b map[string]string) bool {
@@ -736,6 +965,86 @@ type DockerComputeConfig struct {
 
 	// Labels are Docker container labels
 	Labels map[string]string `json:"labels,omitempty"`
+
+	// Devices maps host devices into the container (format: "host_path", "host_path:container_path" or "host_path:container_path:permissions")
+	Devices []string `json:"devices,omitempty"`
+
+	// GPUs requests GPUs through the NVIDIA container runtime
+	GPUs *GPUConfig `json:"gpus,omitempty"`
+
+	// Limits sets CPU, memory and kernel resource limits
+	Limits *LimitsConfig `json:"limits,omitempty"`
+
+	// Kind is "service" (default) for a long-running container or "job" for one that runs to completion
+	Kind string `json:"kind,omitempty"`
+
+	// Platform runs the container for another OS and architecture (linux/amd64 or linux/arm64)
+	Platform string `json:"platform,omitempty"`
+
+	// OS is "linux" (default) or "windows"; it must match the Docker host's OS
+	OS string `json:"os,omitempty"`
+
+	// Isolation is "process" or "hyperv" for Windows containers; empty uses the daemon's default
+	Isolation string `json:"isolation,omitempty"`
+
+	// InitContainers run to completion, in order, before the container starts
+	InitContainers []InitContainerConfig `json:"init_containers,omitempty"`
+
+	// Egress denies the container's outbound traffic except to the destinations it allows;
+	// it requires the provider's egress_firewall
+	Egress *compute.EgressPolicy `json:"egress,omitempty"`
+
+	// PreStop notifies the workload before its container is stopped on archival
+	PreStop *PreStopConfig `json:"pre_stop,omitempty"`
+
+	// TerminationGracePeriod is how long the container has to exit on archival before it is
+	// killed, such as "30s" (default 10s); it includes the time taken by PreStop
+	TerminationGracePeriod string `json:"termination_grace_period,omitempty"`
+}
+
+// InitContainerConfig represents a container run to completion before the tenant's container starts
+type InitContainerConfig struct {
+	Name    string            `json:"name"`
+	Image   string            `json:"image"`
+	Command []string          `json:"command,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// LimitsConfig represents container resource limits
+type LimitsConfig struct {
+	// CPU is the CPU limit in millicores (1000 = 1 CPU)
+	CPU int `json:"cpu,omitempty"`
+
+	// Memory is the memory limit in MiB
+	Memory int `json:"memory,omitempty"`
+
+	// PidsLimit caps the number of processes in the container; -1 is unlimited
+	PidsLimit int64 `json:"pids_limit,omitempty"`
+
+	// BlkioWeight is the relative block IO weight (10-1000)
+	BlkioWeight uint16 `json:"blkio_weight,omitempty"`
+
+	// Ulimits sets per-process limits keyed by name (e.g., "nofile")
+	Ulimits map[string]UlimitConfig `json:"ulimits,omitempty"`
+}
+
+// UlimitConfig represents a soft and hard per-process limit; -1 is unlimited
+type UlimitConfig struct {
+	Soft int64 `json:"soft"`
+	Hard int64 `json:"hard"`
+}
+
+// initContainerNamePattern matches init container names, which are used in container names
+var initContainerNamePattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// minMemoryMiB is the smallest memory limit the Docker daemon accepts
+const minMemoryMiB = 6
+
+// validUlimits are the ulimit names the Docker daemon accepts
+var validUlimits = map[string]bool{
+	"core": true, "cpu": true, "data": true, "fsize": true, "locks": true,
+	"memlock": true, "msgqueue": true, "nice": true, "nofile": true, "nproc": true,
+	"rss": true, "rtprio": true, "rttime": true, "sigpending": true, "stack": true,
 }
 
 // PortConfig represents a port mapping configuration
@@ -745,6 +1054,116 @@ type PortConfig struct {
 	Protocol      string `json:"protocol,omitempty"` // tcp or udp
 }
 
+// GPUConfig represents a GPU request
+type GPUConfig struct {
+	// Count is the number of GPUs to request; -1 requests every GPU on the host
+	Count int `json:"count,omitempty"`
+
+	// DeviceIDs requests specific GPUs by index or UUID instead of a count
+	DeviceIDs []string `json:"device_ids,omitempty"`
+
+	// Capabilities are extra driver capabilities to enable (e.g., "compute", "utility"); "gpu" is always requested
+	Capabilities []string `json:"capabilities,omitempty"`
+
+	// Driver is the device driver; defaults to "nvidia"
+	Driver string `json:"driver,omitempty"`
+}
+
+const defaultGPUDriver = "nvidia"
+
+// parseDeviceMappings converts device strings to Docker device mappings.
+// A missing container path mirrors the host path and permissions default to "rwm", as with docker run --device.
+func parseDeviceMappings(devices []string) ([]container.DeviceMapping, error) {
+	if len(devices) == 0 {
+		return nil, nil
+	}
+	mappings := make([]container.DeviceMapping, 0, len(devices))
+	for i, device := range devices {
+		parts := strings.Split(device, ":")
+		if len(parts) > 3 {
+			return nil, fmt.Errorf("devices[%d]: invalid format '%s', expected 'host_path', 'host_path:container_path' or 'host_path:container_path:permissions'", i, device)
+		}
+		mapping := container.DeviceMapping{
+			PathOnHost:        parts[0],
+			PathInContainer:   parts[0],
+			CgroupPermissions: "rwm",
+		}
+		if len(parts) >= 2 {
+			mapping.PathInContainer = parts[1]
+		}
+		if len(parts) == 3 {
+			mapping.CgroupPermissions = parts[2]
+		}
+		if !strings.HasPrefix(mapping.PathOnHost, "/") {
+			return nil, fmt.Errorf("devices[%d]: host path must be absolute, got '%s'", i, mapping.PathOnHost)
+		}
+		if !strings.HasPrefix(mapping.PathInContainer, "/") {
+			return nil, fmt.Errorf("devices[%d]: container path must be absolute, got '%s'", i, mapping.PathInContainer)
+		}
+		if !validCgroupPermissions(mapping.CgroupPermissions) {
+			return nil, fmt.Errorf("devices[%d]: permissions must be a combination of 'r', 'w' and 'm', got '%s'", i, mapping.CgroupPermissions)
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+func validCgroupPermissions(permissions string) bool {
+	if permissions == "" || len(permissions) > 3 {
+		return false
+	}
+	for _, c := range permissions {
+		if !strings.ContainsRune("rwm", c) || strings.Count(permissions, string(c)) > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// buildGPURequest converts a GPU request to a Docker device request
+func buildGPURequest(gpus *GPUConfig) container.DeviceRequest {
+	driver := gpus.Driver
+	if driver == "" {
+		driver = defaultGPUDriver
+	}
+	capabilities := []string{"gpu"}
+	for _, capability := range gpus.Capabilities {
+		if capability != "gpu" {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	request := container.DeviceRequest{
+		Driver:       driver,
+		Capabilities: [][]string{capabilities},
+	}
+	if len(gpus.DeviceIDs) > 0 {
+		request.DeviceIDs = gpus.DeviceIDs
+	} else {
+		request.Count = gpus.Count
+	}
+	return request
+}
+
+// validateGPUConfig checks a GPU request asks for either a count or specific devices
+func validateGPUConfig(gpus *GPUConfig) []string {
+	var errors []string
+	if gpus.Count < -1 {
+		errors = append(errors, fmt.Sprintf("gpus.count: must be -1 (all) or a positive number, got %d", gpus.Count))
+	}
+	if gpus.Count != 0 && len(gpus.DeviceIDs) > 0 {
+		errors = append(errors, "gpus: count and device_ids are mutually exclusive")
+	}
+	if gpus.Count == 0 && len(gpus.DeviceIDs) == 0 {
+		errors = append(errors, "gpus: count or device_ids is required")
+	}
+	for i, id := range gpus.DeviceIDs {
+		if strings.TrimSpace(id) == "" {
+			errors = append(errors, fmt.Sprintf("gpus.device_ids[%d]: must not be empty", i))
+		}
+	}
+	return errors
+}
+
 func parseProviderConfig(defaults map[string]interface{}, raw json.RawMessage) (*DockerComputeConfig, error) {
 	mergedRaw, err := compute.MergeConfigJSON(defaults, raw)
 	if err != nil {
@@ -765,11 +1184,12 @@ func applyProviderConfig(spec *compute.TenantComputeSpec, dockerConfig *DockerCo
 	if spec == nil || dockerConfig == nil {
 		return nil
 	}
-	if len(spec.Containers) != 1 {
-		return fmt.Errorf("docker provider expects exactly 1 container, got %d", len(spec.Containers))
+	index, err := workloadIndex(spec)
+	if err != nil {
+		return err
 	}
 
-	containerSpec := &spec.Containers[0]
+	containerSpec := &spec.Containers[index]
 	if dockerConfig.Image != "" {
 		containerSpec.Image = dockerConfig.Image
 	}
@@ -779,10 +1199,120 @@ func applyProviderConfig(spec *compute.TenantComputeSpec, dockerConfig *DockerCo
 	if len(dockerConfig.Ports) > 0 {
 		containerSpec.Ports = toPortMappings(dockerConfig.Ports)
 	}
+	if dockerConfig.Limits != nil {
+		if dockerConfig.Limits.CPU > 0 {
+			spec.Resources.CPU = dockerConfig.Limits.CPU
+		}
+		if dockerConfig.Limits.Memory > 0 {
+			spec.Resources.Memory = dockerConfig.Limits.Memory
+		}
+	}
+	if dockerConfig.Kind != "" {
+		containerSpec.Kind = compute.ContainerKind(dockerConfig.Kind)
+	}
+	if dockerConfig.Platform != "" {
+		containerSpec.Platform = dockerConfig.Platform
+	}
+
+	// Configured init containers replace any on the spec, so applying the config twice is harmless
+	if len(dockerConfig.InitContainers) > 0 {
+		containers := make([]compute.ContainerSpec, 0, len(dockerConfig.InitContainers)+1)
+		for _, init := range dockerConfig.InitContainers {
+			containers = append(containers, compute.ContainerSpec{
+				Name:    init.Name,
+				Image:   init.Image,
+				Command: init.Command,
+				Env:     init.Env,
+				Kind:    compute.ContainerKindInit,
+			})
+		}
+		spec.Containers = append(containers, *containerSpec)
+	}
 
 	return nil
 }
 
+// workloadIndex returns the index of the spec's single service or job container
+func workloadIndex(spec *compute.TenantComputeSpec) (int, error) {
+	index, count := -1, 0
+	for i, c := range spec.Containers {
+		if c.Kind == compute.ContainerKindInit {
+			continue
+		}
+		if index < 0 {
+			index = i
+		}
+		count++
+	}
+	if count != 1 {
+		return -1, fmt.Errorf("docker provider expects exactly 1 container besides init containers, got %d", count)
+	}
+	return index, nil
+}
+
+// applyKernelLimits sets the process, block IO and ulimit limits on a host config
+func applyKernelLimits(hostConfig *container.HostConfig, limits *LimitsConfig) {
+	if limits.PidsLimit != 0 {
+		pidsLimit := limits.PidsLimit
+		hostConfig.PidsLimit = &pidsLimit
+	}
+	if limits.BlkioWeight > 0 {
+		hostConfig.BlkioWeight = limits.BlkioWeight
+	}
+	if len(limits.Ulimits) > 0 {
+		names := make([]string, 0, len(limits.Ulimits))
+		for name := range limits.Ulimits {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			limit := limits.Ulimits[name]
+			hostConfig.Ulimits = append(hostConfig.Ulimits, &container.Ulimit{Name: name, Soft: limit.Soft, Hard: limit.Hard})
+		}
+	}
+}
+
+// checkHostCapacity rejects limits larger than the Docker host
+func (p *Provider) checkHostCapacity(resources compute.ResourceRequirements) error {
+	if p.hostCPUs > 0 && resources.CPU > p.hostCPUs*1000 {
+		return fmt.Errorf("%w: cpu limit %dm exceeds host capacity of %d CPUs", compute.ErrInvalidConfig, resources.CPU, p.hostCPUs)
+	}
+	if p.hostMemory > 0 && resources.MemoryBytes() > p.hostMemory {
+		return fmt.Errorf("%w: memory limit %dMiB exceeds host capacity of %dMiB", compute.ErrInvalidConfig, resources.Memory, p.hostMemory/(1024*1024))
+	}
+	return nil
+}
+
+// validateLimitsConfig checks resource limits are in range
+func validateLimitsConfig(limits *LimitsConfig) []string {
+	var errors []string
+	if limits.CPU < 0 {
+		errors = append(errors, fmt.Sprintf("limits.cpu: must be positive millicores, got %d", limits.CPU))
+	}
+	if limits.Memory < 0 || (limits.Memory > 0 && limits.Memory < minMemoryMiB) {
+		errors = append(errors, fmt.Sprintf("limits.memory: must be at least %d MiB, got %d", minMemoryMiB, limits.Memory))
+	}
+	if limits.PidsLimit < -1 {
+		errors = append(errors, fmt.Sprintf("limits.pids_limit: must be -1 (unlimited) or positive, got %d", limits.PidsLimit))
+	}
+	if limits.BlkioWeight != 0 && (limits.BlkioWeight < 10 || limits.BlkioWeight > 1000) {
+		errors = append(errors, fmt.Sprintf("limits.blkio_weight: must be between 10 and 1000, got %d", limits.BlkioWeight))
+	}
+	for name, limit := range limits.Ulimits {
+		if !validUlimits[name] {
+			errors = append(errors, fmt.Sprintf("limits.ulimits.%s: unknown ulimit", name))
+			continue
+		}
+		if limit.Soft < -1 || limit.Hard < -1 {
+			errors = append(errors, fmt.Sprintf("limits.ulimits.%s: limits must be -1 (unlimited) or non-negative", name))
+		}
+		if limit.Hard != -1 && (limit.Soft == -1 || limit.Soft > limit.Hard) {
+			errors = append(errors, fmt.Sprintf("limits.ulimits.%s: soft limit %d exceeds hard limit %d", name, limit.Soft, limit.Hard))
+		}
+	}
+	return errors
+}
+
 func toPortMappings(ports []PortConfig) []compute.PortMapping {
 	if len(ports) == 0 {
 		return nil
@@ -855,6 +1385,100 @@ var dockerConfigSchema = json.RawMessage(`{
     "labels": {
       "type": "object",
       "additionalProperties": { "type": "string" }
+    },
+    "devices": {
+      "type": "array",
+      "items": { "type": "string" }
+    },
+    "gpus": {
+      "type": "object",
+      "properties": {
+        "count": { "type": "integer", "minimum": -1 },
+        "device_ids": {
+          "type": "array",
+          "items": { "type": "string" }
+        },
+        "capabilities": {
+          "type": "array",
+          "items": { "type": "string" }
+        },
+        "driver": { "type": "string" }
+      },
+      "additionalProperties": false
+    },
+    "kind": { "type": "string", "enum": ["service", "job"] },
+    "platform": { "type": "string", "enum": ["linux/amd64", "linux/arm64"] },
+    "os": { "type": "string", "enum": ["linux", "windows"] },
+    "isolation": { "type": "string", "enum": ["process", "hyperv"] },
+    "egress": {
+      "type": "object",
+      "properties": {
+        "allow": {
+          "type": "array",
+          "items": { "type": "string" },
+          "maxItems": 64
+        }
+      },
+      "additionalProperties": false
+    },
+    "pre_stop": {
+      "type": "object",
+      "properties": {
+        "http": {
+          "type": "object",
+          "properties": {
+            "path": { "type": "string" },
+            "port": { "type": "integer", "minimum": 1, "maximum": 65535 }
+          },
+          "required": ["port"],
+          "additionalProperties": false
+        },
+        "signal": { "type": "string", "pattern": "^SIG[A-Z0-9+-]+$" }
+      },
+      "additionalProperties": false
+    },
+    "termination_grace_period": { "type": "string" },
+    "init_containers": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "name": { "type": "string", "pattern": "^[a-z0-9-]+$" },
+          "image": { "type": "string" },
+          "command": {
+            "type": "array",
+            "items": { "type": "string" }
+          },
+          "env": {
+            "type": "object",
+            "additionalProperties": { "type": "string" }
+          }
+        },
+        "required": ["name", "image"],
+        "additionalProperties": false
+      }
+    },
+    "limits": {
+      "type": "object",
+      "properties": {
+        "cpu": { "type": "integer", "minimum": 1 },
+        "memory": { "type": "integer", "minimum": 6 },
+        "pids_limit": { "type": "integer", "minimum": -1 },
+        "blkio_weight": { "type": "integer", "minimum": 10, "maximum": 1000 },
+        "ulimits": {
+          "type": "object",
+          "additionalProperties": {
+            "type": "object",
+            "properties": {
+              "soft": { "type": "integer", "minimum": -1 },
+              "hard": { "type": "integer", "minimum": -1 }
+            },
+            "required": ["soft", "hard"],
+            "additionalProperties": false
+          }
+        }
+      },
+      "additionalProperties": false
     }
   },
   "required": ["image"],
@@ -863,9 +1487,57 @@ var dockerConfigSchema = json.RawMessage(`{
 
 // ValidateConfig validates Docker-specific configuration
 func (p *Provider) ValidateConfig(config json.RawMessage) error {
-	parsedConfig, err := parseProviderConfig(p.defaultConfig, config)
+	if err := validateConfig(p.defaults(), config); err != nil {
+		return err
+	}
+	parsedConfig, err := parseProviderConfig(p.defaults(), config)
+	if err != nil {
+		return compute.Mark(err, compute.ErrInvalidConfig)
+	}
+	if parsedConfig == nil {
+		return nil
+	}
+	if p.isolated() && parsedConfig.NetworkMode != "" {
+		return fmt.Errorf("%w: Docker configuration validation failed: network_mode cannot be set when network isolation is %q", compute.ErrInvalidConfig, NetworkIsolationTenant)
+	}
+	if parsedConfig.Egress != nil && p.firewall == nil {
+		return fmt.Errorf("%w: Docker configuration validation failed: egress requires the provider's egress_firewall", compute.ErrInvalidConfig)
+	}
+	if err := p.checkOS(parsedConfig); err != nil {
+		return err
+	}
+	if err := p.checkPlatform(parsedConfig.Platform); err != nil {
+		return err
+	}
+	if parsedConfig.Limits != nil {
+		// Marked so callers can tell a host capacity problem from a malformed config
+		return compute.Mark(p.checkHostCapacity(compute.ResourceRequirements{CPU: parsedConfig.Limits.CPU, Memory: parsedConfig.Limits.Memory}), compute.ErrQuotaExceeded)
+	}
+	return nil
+}
+
+// Reconfigure replaces the default compute_config merged into every tenant's config
+func (p *Provider) Reconfigure(defaults map[string]interface{}) error {
+	if err := validateConfig(defaults, nil); err != nil {
+		return err
+	}
+	p.defaultsMu.Lock()
+	defer p.defaultsMu.Unlock()
+	p.defaultConfig = copyConfigMap(defaults)
+	p.defaultConfigRaw = marshalConfigMap(defaults)
+	return nil
+}
+
+func (p *Provider) defaults() map[string]interface{} {
+	p.defaultsMu.RLock()
+	defer p.defaultsMu.RUnlock()
+	return p.defaultConfig
+}
+
+func validateConfig(defaults map[string]interface{}, config json.RawMessage) error {
+	parsedConfig, err := parseProviderConfig(defaults, config)
 	if err != nil {
-		return fmt.Errorf("invalid JSON structure: %w", err)
+		return compute.Mark(fmt.Errorf("invalid JSON structure: %w", err), compute.ErrInvalidConfig)
 	}
 	if parsedConfig == nil {
 		// Empty config is valid - will use defaults
@@ -878,20 +1550,26 @@ func (p *Provider) ValidateConfig(config json.RawMessage) error {
 		errors = append(errors, "image is required")
 	}
 
+	// Validate os and isolation; Windows volumes and network modes are checked there
+	errors = append(errors, validateOSConfig(parsedConfig)...)
+	windows := containerOS(parsedConfig) == OSWindows
+
 	// Validate volumes format
-	for i, vol := range parsedConfig.Volumes {
-		parts := strings.Split(vol, ":")
-		if len(parts) < 2 || len(parts) > 3 {
-			errors = append(errors, fmt.Sprintf("volumes[%d]: invalid format '%s', expected 'host_path:container_path' or 'host_path:container_path:mode'", i, vol))
-		}
-		// Check container path is absolute
-		if len(parts) >= 2 && !strings.HasPrefix(parts[1], "/") {
-			errors = append(errors, fmt.Sprintf("volumes[%d]: container path must be absolute, got '%s'", i, parts[1]))
+	if !windows {
+		for i, vol := range parsedConfig.Volumes {
+			parts := strings.Split(vol, ":")
+			if len(parts) < 2 || len(parts) > 3 {
+				errors = append(errors, fmt.Sprintf("volumes[%d]: invalid format '%s', expected 'host_path:container_path' or 'host_path:container_path:mode'", i, vol))
+			}
+			// Check container path is absolute
+			if len(parts) >= 2 && !strings.HasPrefix(parts[1], "/") {
+				errors = append(errors, fmt.Sprintf("volumes[%d]: container path must be absolute, got '%s'", i, parts[1]))
+			}
 		}
 	}
 
 	// Validate network mode
-	if parsedConfig.NetworkMode != "" {
+	if parsedConfig.NetworkMode != "" && !windows {
 		validModes := []string{"bridge", "host", "none"}
 		isValid := false
 		for _, mode := range validModes {
@@ -944,8 +1622,60 @@ func (p *Provider) ValidateConfig(config json.RawMessage) error {
 		}
 	}
 
+	// Validate devices and GPU requests
+	if _, err := parseDeviceMappings(parsedConfig.Devices); err != nil {
+		errors = append(errors, err.Error())
+	}
+	if parsedConfig.GPUs != nil {
+		errors = append(errors, validateGPUConfig(parsedConfig.GPUs)...)
+	}
+
+	// Validate resource limits
+	if parsedConfig.Limits != nil {
+		errors = append(errors, validateLimitsConfig(parsedConfig.Limits)...)
+	}
+
+	// Validate the egress policy
+	if parsedConfig.Egress != nil {
+		if err := parsedConfig.Egress.Validate(); err != nil {
+			errors = append(errors, "egress: "+err.Error())
+		}
+	}
+
+	// Validate the pre-stop hook and grace period
+	errors = append(errors, validatePreStopConfig(parsedConfig)...)
+
+	// Validate kind and init containers
+	switch parsedConfig.Kind {
+	case "", string(compute.ContainerKindService):
+	case string(compute.ContainerKindJob):
+		if parsedConfig.RestartPolicy == "always" || parsedConfig.RestartPolicy == "unless-stopped" {
+			errors = append(errors, fmt.Sprintf("restart_policy: '%s' would rerun a job, use 'no' or 'on-failure'", parsedConfig.RestartPolicy))
+		}
+	default:
+		errors = append(errors, fmt.Sprintf("kind: invalid value '%s', must be one of: service, job", parsedConfig.Kind))
+	}
+	if parsedConfig.Platform != "" {
+		if _, err := parsePlatform(parsedConfig.Platform); err != nil {
+			errors = append(errors, "platform: "+err.Error())
+		}
+	}
+
+	initNames := map[string]bool{}
+	for i, init := range parsedConfig.InitContainers {
+		if !initContainerNamePattern.MatchString(init.Name) {
+			errors = append(errors, fmt.Sprintf("init_containers[%d].name: must match ^[a-z0-9-]+$, got '%s'", i, init.Name))
+		} else if initNames[init.Name] {
+			errors = append(errors, fmt.Sprintf("init_containers[%d].name: duplicate name '%s'", i, init.Name))
+		}
+		initNames[init.Name] = true
+		if init.Image == "" || !isValidImageRef(init.Image) {
+			errors = append(errors, fmt.Sprintf("init_containers[%d].image: invalid image reference '%s'", i, init.Image))
+		}
+	}
+
 	if len(errors) > 0 {
-		return fmt.Errorf("Docker configuration validation failed: %s", strings.Join(errors, "; "))
+		return fmt.Errorf("%w: Docker configuration validation failed: %s", compute.ErrInvalidConfig, strings.Join(errors, "; "))
 	}
 
 	return nil
@@ -958,5 +1688,15 @@ func (p *Provider) ConfigSchema() json.RawMessage {
 
 // ConfigDefaults returns defaults for Docker compute_config (none defined).
 func (p *Provider) ConfigDefaults() json.RawMessage {
+	p.defaultsMu.RLock()
+	defer p.defaultsMu.RUnlock()
 	return p.defaultConfigRaw
 }
+
+// classifyDockerError tags daemon errors with compute sentinels so callers can tell transient failures apart
+func classifyDockerError(err error) error {
+	if client.IsErrConnectionFailed(err) {
+		return compute.Mark(err, compute.ErrProviderUnavailable)
+	}
+	return err
+}
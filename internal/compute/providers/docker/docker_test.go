@@ -2,13 +2,28 @@ package docker
 
 import (
 	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
 	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/pkg/computetest"
 )
 
 // TestNewProvider tests creating a new Docker provider
@@ -55,6 +70,44 @@ func TestNewProvider(t *testing.T) {
 	})
 }
 
+// TestConformance runs the compute conformance suite against the Docker daemon. It pulls
+// nginx:alpine and runs containers, so it is skipped when no daemon answers.
+func TestConformance(t *testing.T) {
+	defaults := map[string]interface{}{"image": "nginx:alpine"}
+	newProvider := func(t *testing.T) compute.Provider {
+		provider, err := New(&Config{}, defaults, zap.NewNop())
+		require.NoError(t, err)
+		t.Cleanup(func() { provider.Close() })
+		return provider
+	}
+
+	probe, err := New(&Config{}, defaults, zap.NewNop())
+	if err != nil {
+		t.Skip("Docker daemon not available:", err)
+	}
+	probe.Close()
+
+	computetest.Run(t, computetest.Config{
+		NewProvider: newProvider,
+		Labels: func(ctx context.Context, provider compute.Provider, tenantID string) (map[string]string, error) {
+			p := provider.(*Provider)
+			containerID, err := p.findTenantContainer(ctx, tenantID)
+			if err != nil {
+				return nil, err
+			}
+			inspect, err := p.client.ContainerInspect(ctx, containerID)
+			if err != nil {
+				return nil, err
+			}
+			return inspect.Config.Labels, nil
+		},
+		// A new provider finds containers by their labels, as after a restart
+		Restart: func(t *testing.T, _ compute.Provider) compute.Provider {
+			return newProvider(t)
+		},
+	})
+}
+
 // TestValidate tests spec validation
 func TestValidate(t *testing.T) {
 	logger := zap.NewNop()
@@ -237,11 +290,13 @@ func TestHelperFunctions(t *testing.T) {
 				"custom":                    "value",
 				compute.MetadataOwnerKey:    "override",
 				compute.MetadataTenantIDKey: "override",
+				"landlord.io/project":       "acme/web",
 			},
 		}
 		parsedConfig := &DockerComputeConfig{
 			Labels: map[string]string{
-				"provider_label": "from-config",
+				"provider_label":      "from-config",
+				"landlord.io/project": "other/web",
 			},
 		}
 
@@ -251,5 +306,738 @@ func TestHelperFunctions(t *testing.T) {
 		assert.Equal(t, "docker", labels[compute.MetadataProviderKey])
 		assert.Equal(t, "value", labels["custom"])
 		assert.Equal(t, "from-config", labels["provider_label"])
+		assert.Equal(t, "acme/web", labels["landlord.io/project"])
+	})
+
+	t.Run("buildEndpoints prefers ingress network", func(t *testing.T) {
+		containerSpec := &compute.ContainerSpec{Ports: []compute.PortMapping{{ContainerPort: 80, Protocol: "tcp"}}}
+		inspect := &types.ContainerJSON{NetworkSettings: &container.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				tenantNetworkName("tenant-123"): {IPAddress: "10.1.0.2"},
+				"ingress":                       {IPAddress: "10.9.0.7"},
+			},
+		}}
+
+		endpoints := buildEndpoints(containerSpec, inspect, "ingress")
+		require.Len(t, endpoints, 1)
+		assert.Equal(t, "10.9.0.7", endpoints[0].Address)
+
+		endpoints = buildEndpoints(containerSpec, inspect, "")
+		require.Len(t, endpoints, 1)
+		assert.NotEqual(t, "127.0.0.1", endpoints[0].Address)
+	})
+}
+
+// TestDevicePassthrough tests device mappings and GPU requests
+func TestDevicePassthrough(t *testing.T) {
+	t.Run("parses device mappings", func(t *testing.T) {
+		devices, err := parseDeviceMappings([]string{"/dev/fuse", "/dev/nvidia0:/dev/gpu0", "/dev/snd:/dev/snd:rw"})
+		require.NoError(t, err)
+		assert.Equal(t, []container.DeviceMapping{
+			{PathOnHost: "/dev/fuse", PathInContainer: "/dev/fuse", CgroupPermissions: "rwm"},
+			{PathOnHost: "/dev/nvidia0", PathInContainer: "/dev/gpu0", CgroupPermissions: "rwm"},
+			{PathOnHost: "/dev/snd", PathInContainer: "/dev/snd", CgroupPermissions: "rw"},
+		}, devices)
+	})
+
+	t.Run("rejects invalid device mappings", func(t *testing.T) {
+		for _, device := range []string{"dev/fuse", "/dev/fuse:fuse", "/dev/fuse:/dev/fuse:rwx", "/dev/fuse:/dev/fuse:rr", "/a:/b:r:w"} {
+			_, err := parseDeviceMappings([]string{device})
+			assert.Error(t, err, device)
+		}
+	})
+
+	t.Run("builds GPU requests", func(t *testing.T) {
+		request := buildGPURequest(&GPUConfig{Count: -1})
+		assert.Equal(t, container.DeviceRequest{Driver: "nvidia", Count: -1, Capabilities: [][]string{{"gpu"}}}, request)
+
+		request = buildGPURequest(&GPUConfig{DeviceIDs: []string{"0", "2"}, Capabilities: []string{"gpu", "compute", "utility"}})
+		assert.Equal(t, []string{"0", "2"}, request.DeviceIDs)
+		assert.Zero(t, request.Count)
+		assert.Equal(t, [][]string{{"gpu", "compute", "utility"}}, request.Capabilities)
+	})
+
+	t.Run("validates compute_config", func(t *testing.T) {
+		defaults := map[string]interface{}{"image": "pytorch/pytorch:latest"}
+		assert.NoError(t, validateConfig(defaults, []byte(`{"gpus": {"count": 1}, "devices": ["/dev/fuse"]}`)))
+		assert.ErrorIs(t, validateConfig(defaults, []byte(`{"gpus": {}}`)), compute.ErrInvalidConfig)
+		assert.ErrorIs(t, validateConfig(defaults, []byte(`{"gpus": {"count": 2, "device_ids": ["0"]}}`)), compute.ErrInvalidConfig)
+		assert.ErrorIs(t, validateConfig(defaults, []byte(`{"gpus": {"count": -2}}`)), compute.ErrInvalidConfig)
+		assert.ErrorIs(t, validateConfig(defaults, []byte(`{"devices": ["fuse"]}`)), compute.ErrInvalidConfig)
+	})
+}
+
+// TestResourceLimits tests CPU, memory and kernel limits
+func TestResourceLimits(t *testing.T) {
+	t.Run("applies cpu and memory to the spec", func(t *testing.T) {
+		spec := &compute.TenantComputeSpec{Containers: []compute.ContainerSpec{{Name: "app"}}}
+		parsed, err := parseProviderConfig(nil, []byte(`{"image": "nginx:latest", "limits": {"cpu": 1500, "memory": 512}}`))
+		require.NoError(t, err)
+		require.NoError(t, applyProviderConfig(spec, parsed))
+
+		assert.Equal(t, int64(1_500_000_000), spec.Resources.NanoCPUs())
+		assert.Equal(t, int64(512*1024*1024), spec.Resources.MemoryBytes())
+	})
+
+	t.Run("applies kernel limits", func(t *testing.T) {
+		hostConfig := &container.HostConfig{}
+		applyKernelLimits(hostConfig, &LimitsConfig{
+			PidsLimit:   256,
+			BlkioWeight: 500,
+			Ulimits: map[string]UlimitConfig{
+				"nproc":  {Soft: 512, Hard: 1024},
+				"nofile": {Soft: 1024, Hard: 4096},
+			},
+		})
+
+		require.NotNil(t, hostConfig.PidsLimit)
+		assert.Equal(t, int64(256), *hostConfig.PidsLimit)
+		assert.Equal(t, uint16(500), hostConfig.BlkioWeight)
+		assert.Equal(t, []*container.Ulimit{
+			{Name: "nofile", Soft: 1024, Hard: 4096},
+			{Name: "nproc", Soft: 512, Hard: 1024},
+		}, hostConfig.Ulimits)
+	})
+
+	t.Run("validates limits", func(t *testing.T) {
+		defaults := map[string]interface{}{"image": "nginx:latest"}
+		assert.NoError(t, validateConfig(defaults, []byte(`{"limits": {"cpu": 500, "memory": 256, "pids_limit": -1, "ulimits": {"nofile": {"soft": 1024, "hard": -1}}}}`)))
+		for _, config := range []string{
+			`{"limits": {"memory": 2}}`,
+			`{"limits": {"pids_limit": -5}}`,
+			`{"limits": {"blkio_weight": 5}}`,
+			`{"limits": {"ulimits": {"files": {"soft": 1, "hard": 2}}}}`,
+			`{"limits": {"ulimits": {"nofile": {"soft": 4096, "hard": 1024}}}}`,
+		} {
+			assert.ErrorIs(t, validateConfig(defaults, []byte(config)), compute.ErrInvalidConfig, config)
+		}
+	})
+
+	t.Run("checks host capacity", func(t *testing.T) {
+		provider := &Provider{hostCPUs: 2, hostMemory: 1024 * 1024 * 1024}
+
+		assert.NoError(t, provider.ValidateConfig([]byte(`{"image": "nginx:latest", "limits": {"cpu": 2000, "memory": 1024}}`)))
+		assert.ErrorIs(t, provider.ValidateConfig([]byte(`{"image": "nginx:latest", "limits": {"cpu": 2500}}`)), compute.ErrInvalidConfig)
+		assert.ErrorIs(t, provider.ValidateConfig([]byte(`{"image": "nginx:latest", "limits": {"memory": 2048}}`)), compute.ErrInvalidConfig)
+		assert.ErrorIs(t, provider.ValidateConfig([]byte(`{"image": "nginx:latest", "limits": {"memory": 2048}}`)), compute.ErrQuotaExceeded)
+	})
+}
+
+// TestInitContainersAndJobs tests init containers and run-to-completion tenants
+func TestInitContainersAndJobs(t *testing.T) {
+	t.Run("applies init containers and kind from compute_config", func(t *testing.T) {
+		spec := &compute.TenantComputeSpec{Containers: []compute.ContainerSpec{{Name: "app"}}}
+		parsed, err := parseProviderConfig(nil, []byte(`{
+			"image": "example/report:1.0",
+			"kind": "job",
+			"init_containers": [{"name": "fetch", "image": "busybox:latest", "command": ["wget", "http://example.com/data"]}]
+		}`))
+		require.NoError(t, err)
+
+		// Applying twice must not duplicate init containers
+		require.NoError(t, applyProviderConfig(spec, parsed))
+		require.NoError(t, applyProviderConfig(spec, parsed))
+
+		require.Len(t, spec.Containers, 2)
+		assert.Equal(t, compute.ContainerKindInit, spec.Containers[0].Kind)
+		assert.Equal(t, "fetch", spec.Containers[0].Name)
+		assert.Equal(t, compute.ContainerKindJob, spec.Containers[1].Kind)
+		assert.Equal(t, "example/report:1.0", spec.Containers[1].Image)
+		assert.True(t, spec.IsJob())
+
+		index, err := workloadIndex(spec)
+		require.NoError(t, err)
+		assert.Equal(t, 1, index)
+	})
+
+	t.Run("reports job completion", func(t *testing.T) {
+		inspect := func(exitCode int) *types.ContainerJSON {
+			return &types.ContainerJSON{
+				ContainerJSONBase: &container.ContainerJSONBase{
+					ID:    "abc",
+					State: &container.State{Status: "exited", ExitCode: exitCode},
+				},
+				Config: &container.Config{Image: "example/report:1.0"},
+			}
+		}
+
+		status := buildComputeStatus("tenant-1", inspect(0), true)
+		assert.Equal(t, compute.ComputeStateCompleted, status.State)
+		assert.Equal(t, compute.HealthStatusHealthy, status.Health)
+		assert.True(t, status.IsReady())
+
+		status = buildComputeStatus("tenant-1", inspect(3), true)
+		assert.Equal(t, compute.ComputeStateFailed, status.State)
+		assert.Equal(t, "3", status.Metadata["exit_code"])
+		assert.False(t, status.IsReady())
+
+		status = buildComputeStatus("tenant-1", inspect(0), false)
+		assert.Equal(t, compute.ComputeStateStopped, status.State)
+	})
+
+	t.Run("reports running image and ports", func(t *testing.T) {
+		status := buildComputeStatus("tenant-1", &types.ContainerJSON{
+			ContainerJSONBase: &container.ContainerJSONBase{
+				ID:    "abc",
+				Image: "sha256:def",
+				State: &container.State{Status: "running", Running: true},
+			},
+			Config: &container.Config{Image: "nginx:1.27"},
+			NetworkSettings: &container.NetworkSettings{NetworkSettingsBase: container.NetworkSettingsBase{Ports: nat.PortMap{
+				"8080/tcp": {{HostIP: "0.0.0.0", HostPort: "32768"}},
+				"443/tcp":  {{HostIP: "0.0.0.0", HostPort: "32769"}},
+			}}},
+		}, false)
+		assert.Equal(t, "sha256:def", status.Metadata["image_id"])
+		assert.Equal(t, "443/tcp=0.0.0.0:32769,8080/tcp=0.0.0.0:32768", status.Metadata["ports"])
+		assert.Equal(t, map[string]string{"container_id": "abc"}, status.ResourceIDs)
+	})
+
+	t.Run("validates kind and init containers", func(t *testing.T) {
+		defaults := map[string]interface{}{"image": "nginx:latest"}
+		assert.NoError(t, validateConfig(defaults, []byte(`{"kind": "job", "restart_policy": "on-failure"}`)))
+		for _, config := range []string{
+			`{"kind": "cron"}`,
+			`{"kind": "job", "restart_policy": "always"}`,
+			`{"init_containers": [{"name": "Fetch", "image": "busybox"}]}`,
+			`{"init_containers": [{"name": "fetch", "image": ""}]}`,
+			`{"init_containers": [{"name": "fetch", "image": "busybox"}, {"name": "fetch", "image": "busybox"}]}`,
+		} {
+			assert.ErrorIs(t, validateConfig(defaults, []byte(config)), compute.ErrInvalidConfig, config)
+		}
+	})
+}
+
+// TestNetworkIsolation tests the network isolation settings
+func TestNetworkIsolation(t *testing.T) {
+	t.Run("validates isolation mode", func(t *testing.T) {
+		assert.NoError(t, validateNetworkIsolation(&Config{NetworkIsolation: NetworkIsolationShared}))
+		assert.NoError(t, validateNetworkIsolation(&Config{NetworkIsolation: NetworkIsolationTenant}))
+		assert.NoError(t, validateNetworkIsolation(&Config{NetworkIsolation: NetworkIsolationTenant, IngressNetwork: "ingress"}))
+		assert.Error(t, validateNetworkIsolation(&Config{NetworkIsolation: "vlan"}))
+		assert.Error(t, validateNetworkIsolation(&Config{NetworkIsolation: NetworkIsolationShared, IngressNetwork: "ingress"}))
+	})
+
+	t.Run("rejects network_mode for isolated tenants", func(t *testing.T) {
+		provider := &Provider{networkIsolation: NetworkIsolationTenant}
+
+		err := provider.ValidateConfig([]byte(`{"image": "nginx:latest", "network_mode": "host"}`))
+		assert.ErrorIs(t, err, compute.ErrInvalidConfig)
+		assert.NoError(t, provider.ValidateConfig([]byte(`{"image": "nginx:latest"}`)))
+	})
+}
+
+// inspectOnlyRuntime answers ContainerInspect from containers, keyed by name
+type inspectOnlyRuntime struct {
+	Runtime
+	containers map[string]container.InspectResponse
+}
+
+func (r *inspectOnlyRuntime) ContainerInspect(_ context.Context, name string) (container.InspectResponse, error) {
+	if c, ok := r.containers[name]; ok {
+		return c, nil
+	}
+	return container.InspectResponse{}, cerrdefs.ErrNotFound
+}
+
+func TestContainerNaming(t *testing.T) {
+	const id = "3f2b8a62-7c1e-4c59-9f3a-0c2f7d1b9e11"
+	labels := map[string]string{
+		tenant.LabelTenantID:          id,
+		tenant.LabelTenantName:        "Acme Corp_EU",
+		tenant.LabelTenantEnvironment: "prod",
+	}
+	spec := &compute.TenantComputeSpec{TenantID: id, Labels: labels}
+	component := &compute.TenantComputeSpec{TenantID: id + "-worker", Labels: labels}
+
+	t.Run("validates options", func(t *testing.T) {
+		assert.NoError(t, validateNaming(&NamingConfig{Prefix: "landlord-tenant", ID: NamingIDFull}))
+		assert.NoError(t, validateNaming(&NamingConfig{Prefix: "acme", IncludeName: true, ID: NamingIDNone}))
+		assert.Error(t, validateNaming(&NamingConfig{Prefix: "-acme", ID: NamingIDFull}))
+		assert.Error(t, validateNaming(&NamingConfig{Prefix: "acme/eu", ID: NamingIDFull}))
+		assert.Error(t, validateNaming(&NamingConfig{Prefix: "acme", ID: NamingIDShort}))
+		assert.Error(t, validateNaming(&NamingConfig{Prefix: "acme", IncludeName: true, ID: "uuid"}))
+	})
+
+	t.Run("builds names", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			naming NamingConfig
+			spec   *compute.TenantComputeSpec
+			want   string
+		}{
+			{name: "default", naming: NamingConfig{Prefix: defaultNamingPrefix, ID: NamingIDFull}, spec: spec, want: "landlord-tenant-" + id},
+			{name: "name and environment", naming: NamingConfig{Prefix: "acme", IncludeName: true, IncludeEnvironment: true, ID: NamingIDFull}, spec: spec, want: "acme-prod-acme-corp-eu-" + id},
+			{name: "short id", naming: NamingConfig{Prefix: "acme", IncludeName: true, ID: NamingIDShort}, spec: spec, want: "acme-acme-corp-eu-3f2b8a62"},
+			{name: "no id", naming: NamingConfig{Prefix: "acme", IncludeName: true, ID: NamingIDNone}, spec: spec, want: "acme-acme-corp-eu"},
+			{name: "component keeps its suffix", naming: NamingConfig{Prefix: "acme", IncludeName: true, ID: NamingIDShort}, spec: component, want: "acme-acme-corp-eu-3f2b8a62-worker"},
+			{name: "missing name", naming: NamingConfig{Prefix: "acme", IncludeName: true, IncludeEnvironment: true, ID: NamingIDNone}, spec: &compute.TenantComputeSpec{TenantID: id}, want: "acme-" + id},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.want, tt.naming.containerName(tt.spec))
+			})
+		}
+
+		assert.Equal(t, "a-b-c", slug("  A__B  c!"))
+		assert.Len(t, slug(strings.Repeat("x", 100)), maxSlugLength)
+	})
+
+	t.Run("falls back to the full ID on a collision", func(t *testing.T) {
+		taken := func(tenantID string) container.InspectResponse {
+			return container.InspectResponse{Config: &container.Config{Labels: map[string]string{
+				compute.MetadataOwnerKey:    compute.MetadataOwnerValue,
+				compute.MetadataTenantIDKey: tenantID,
+			}}}
+		}
+		runtime := &inspectOnlyRuntime{containers: map[string]container.InspectResponse{}}
+		p := &Provider{client: runtime, logger: zap.NewNop(), naming: NamingConfig{Prefix: "acme", IncludeName: true, ID: NamingIDNone}}
+
+		name, err := p.resolveContainerName(context.Background(), spec)
+		require.NoError(t, err)
+		assert.Equal(t, "acme-acme-corp-eu", name)
+
+		// The tenant's own container keeps the name
+		runtime.containers["acme-acme-corp-eu"] = taken(id)
+		name, err = p.resolveContainerName(context.Background(), spec)
+		require.NoError(t, err)
+		assert.Equal(t, "acme-acme-corp-eu", name)
+
+		// Another tenant whose name has the same slug, or a container Landlord did not create, does not
+		runtime.containers["acme-acme-corp-eu"] = taken("9a0d1c44-2b6f-4e7a-8c3d-5e6f7a8b9c0d")
+		name, err = p.resolveContainerName(context.Background(), spec)
+		require.NoError(t, err)
+		assert.Equal(t, "acme-acme-corp-eu-"+id, name)
+
+		runtime.containers["acme-acme-corp-eu"] = container.InspectResponse{Config: &container.Config{}}
+		name, err = p.resolveContainerName(context.Background(), spec)
+		require.NoError(t, err)
+		assert.Equal(t, "acme-acme-corp-eu-"+id, name)
+	})
+}
+
+// TestReconfigure tests replacing the default compute_config at runtime
+func TestReconfigure(t *testing.T) {
+	logger := zap.NewNop()
+	provider, err := New(&Config{}, map[string]interface{}{"image": "nginx:latest"}, logger)
+	if err != nil {
+		t.Skip("Docker daemon not available:", err)
+	}
+	defer provider.Close()
+
+	err = provider.Reconfigure(map[string]interface{}{"restart_policy": "sometimes"})
+	assert.ErrorIs(t, err, compute.ErrInvalidConfig)
+	assert.JSONEq(t, `{"image":"nginx:latest"}`, string(provider.ConfigDefaults()))
+
+	err = provider.Reconfigure(map[string]interface{}{"image": "nginx:alpine"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"image":"nginx:alpine"}`, string(provider.ConfigDefaults()))
+}
+
+// TestPlatforms tests platform selection and checks against the daemon's platform
+func TestPlatforms(t *testing.T) {
+	t.Run("applies platform from compute_config", func(t *testing.T) {
+		spec := &compute.TenantComputeSpec{Containers: []compute.ContainerSpec{{Name: "app"}}}
+		parsed, err := parseProviderConfig(nil, []byte(`{"image": "nginx:latest", "platform": "linux/arm64"}`))
+		require.NoError(t, err)
+		require.NoError(t, applyProviderConfig(spec, parsed))
+		assert.Equal(t, "linux/arm64", spec.Containers[0].Platform)
+
+		platform, err := parsePlatform(spec.Containers[0].Platform)
+		require.NoError(t, err)
+		assert.Equal(t, "linux", platform.OS)
+		assert.Equal(t, "arm64", platform.Architecture)
+	})
+
+	t.Run("validates platform", func(t *testing.T) {
+		defaults := map[string]interface{}{"image": "nginx:latest"}
+		assert.NoError(t, validateConfig(defaults, []byte(`{"platform": "linux/amd64"}`)))
+		assert.ErrorIs(t, validateConfig(defaults, []byte(`{"platform": "windows/amd64"}`)), compute.ErrInvalidConfig)
+		assert.Error(t, validateEmulatedPlatforms(&Config{EmulatedPlatforms: []string{"linux/s390x"}}))
+	})
+
+	t.Run("maps daemon architectures", func(t *testing.T) {
+		assert.Equal(t, "linux/amd64", daemonPlatform("linux", "x86_64"))
+		assert.Equal(t, "linux/arm64", daemonPlatform("linux", "aarch64"))
+		assert.Empty(t, daemonPlatform("linux", "s390x"))
+	})
+
+	t.Run("rejects platforms the host cannot run", func(t *testing.T) {
+		provider := &Provider{platforms: []string{"linux/amd64"}}
+
+		assert.NoError(t, provider.ValidateConfig([]byte(`{"image": "nginx:latest", "platform": "linux/amd64"}`)))
+		err := provider.ValidateConfig([]byte(`{"image": "nginx:latest", "platform": "linux/arm64"}`))
+		assert.ErrorIs(t, err, compute.ErrInvalidConfig)
+		assert.ErrorContains(t, err, "platform linux/arm64 is not available on this docker host, which can run linux/amd64")
+
+		spec := &compute.TenantComputeSpec{
+			TenantID:       "tenant-1",
+			Containers:     []compute.ContainerSpec{{Name: "app", Image: "nginx:latest"}},
+			ProviderConfig: []byte(`{"image": "nginx:latest", "platform": "linux/arm64"}`),
+		}
+		assert.ErrorIs(t, provider.Validate(context.Background(), spec), compute.ErrInvalidConfig)
+
+		emulated := &Provider{platforms: []string{"linux/amd64", "linux/arm64"}}
+		assert.NoError(t, emulated.Validate(context.Background(), spec))
+
+		// An unknown daemon platform leaves the check to the daemon
+		assert.NoError(t, (&Provider{}).checkPlatform("linux/arm64"))
+	})
+}
+
+// TestWindowsContainers tests OS selection, isolation and the Windows defaults
+func TestWindowsContainers(t *testing.T) {
+	defaults := map[string]interface{}{"image": "mcr.microsoft.com/windows/servercore/iis:latest"}
+
+	t.Run("validates windows config", func(t *testing.T) {
+		assert.NoError(t, validateConfig(defaults, []byte(`{
+			"os": "windows",
+			"isolation": "hyperv",
+			"volumes": ["C:\\data:C:\\inetpub\\data:ro", "D:/logs:C:/logs"],
+			"network_mode": "nat"
+		}`)))
+		for _, config := range []string{
+			`{"os": "macos"}`,
+			`{"isolation": "hyperv"}`,
+			`{"os": "windows", "isolation": "vm"}`,
+			`{"os": "windows", "platform": "linux/amd64"}`,
+			`{"os": "windows", "volumes": ["/data:/data"]}`,
+			`{"os": "windows", "network_mode": "bridge"}`,
+			`{"os": "windows", "devices": ["/dev/fuse"]}`,
+		} {
+			assert.ErrorIs(t, validateConfig(defaults, []byte(config)), compute.ErrInvalidConfig, config)
+		}
+		// Linux volume rules are unchanged
+		assert.ErrorIs(t, validateConfig(defaults, []byte(`{"volumes": ["C:\\data:C:\\data"]}`)), compute.ErrInvalidConfig)
+	})
+
+	t.Run("rejects windows tenants on linux hosts", func(t *testing.T) {
+		provider := &Provider{hostOS: OSLinux}
+
+		err := provider.ValidateConfig([]byte(`{"image": "mcr.microsoft.com/windows/nanoserver:ltsc2022", "os": "windows"}`))
+		assert.ErrorIs(t, err, compute.ErrInvalidConfig)
+		assert.ErrorContains(t, err, "windows containers need a windows docker host, this host runs linux")
+		assert.NoError(t, provider.ValidateConfig([]byte(`{"image": "nginx:latest"}`)))
+
+		windowsHost := &Provider{hostOS: OSWindows}
+		assert.NoError(t, windowsHost.ValidateConfig([]byte(`{"image": "mcr.microsoft.com/windows/nanoserver:ltsc2022", "os": "windows"}`)))
+		assert.ErrorIs(t, windowsHost.ValidateConfig([]byte(`{"image": "nginx:latest"}`)), compute.ErrInvalidConfig)
+	})
+
+	t.Run("applies isolation and restart defaults", func(t *testing.T) {
+		hostConfig := &container.HostConfig{RestartPolicy: container.RestartPolicy{Name: "unless-stopped"}}
+		applyWindowsHostConfig(hostConfig, &DockerComputeConfig{OS: OSWindows, Isolation: IsolationHyperV})
+		assert.Equal(t, container.IsolationHyperV, hostConfig.Isolation)
+		assert.Equal(t, container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: windowsMaxRestarts}, hostConfig.RestartPolicy)
+
+		// Jobs are never restarted
+		job := &container.HostConfig{RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyDisabled}}
+		applyWindowsHostConfig(job, &DockerComputeConfig{OS: OSWindows})
+		assert.Equal(t, container.RestartPolicyDisabled, job.RestartPolicy.Name)
+		assert.True(t, job.Isolation.IsDefault())
+	})
+}
+
+// TestRuntimes tests runtime selection and the Podman and containerd adapters
+func TestRuntimes(t *testing.T) {
+	t.Run("validates runtime", func(t *testing.T) {
+		assert.NoError(t, validateRuntime(&Config{Runtime: RuntimeDocker}))
+		assert.NoError(t, validateRuntime(&Config{Runtime: RuntimePodman}))
+		assert.NoError(t, validateRuntime(&Config{Runtime: RuntimeContainerd, Namespace: "landlord"}))
+		assert.Error(t, validateRuntime(&Config{Runtime: "cri-o"}))
+		assert.Error(t, validateRuntime(&Config{Runtime: RuntimeDocker, Namespace: "landlord"}))
+		assert.Error(t, validateRuntime(&Config{Runtime: RuntimeContainerd, IngressNetwork: "ingress"}))
+	})
+
+	t.Run("qualifies short image names for podman", func(t *testing.T) {
+		assert.Equal(t, "docker.io/library/nginx:latest", qualifyImage("nginx:latest"))
+		assert.Equal(t, "docker.io/bitnami/redis:7", qualifyImage("bitnami/redis:7"))
+		assert.Equal(t, "ghcr.io/acme/app:1.0", qualifyImage("ghcr.io/acme/app:1.0"))
+		assert.Equal(t, "localhost/app", qualifyImage("localhost/app"))
+		assert.Equal(t, "registry:5000/app", qualifyImage("registry:5000/app"))
+	})
+
+	t.Run("translates container create to nerdctl flags", func(t *testing.T) {
+		pids := int64(100)
+		args, err := nerdctlCreateArgs(
+			&container.Config{
+				Image:  "nginx:latest",
+				Env:    []string{"PORT=8080"},
+				Labels: map[string]string{"landlord.tenant_id": "t1", "app": "web"},
+				Cmd:    []string{"nginx", "-g", "daemon off;"},
+			},
+			&container.HostConfig{
+				PortBindings: nat.PortMap{
+					"8080/tcp": {{HostIP: "0.0.0.0", HostPort: "9090"}},
+					"53/udp":   {{HostPort: "0"}},
+				},
+				RestartPolicy: container.RestartPolicy{Name: container.RestartPolicyOnFailure, MaximumRetryCount: 3},
+				Resources: container.Resources{
+					NanoCPUs:       1_500_000_000,
+					Memory:         512 * 1024 * 1024,
+					PidsLimit:      &pids,
+					Devices:        []container.DeviceMapping{{PathOnHost: "/dev/fuse", PathInContainer: "/dev/fuse", CgroupPermissions: "rwm"}},
+					DeviceRequests: []container.DeviceRequest{buildGPURequest(&GPUConfig{Count: -1, Capabilities: []string{"compute"}})},
+					Ulimits:        []*container.Ulimit{{Name: "nofile", Soft: 1024, Hard: 4096}},
+				},
+				Binds:       []string{"/data:/app/data:ro"},
+				NetworkMode: "landlord-tenant-t1-net",
+			},
+			&ocispec.Platform{OS: "linux", Architecture: "arm64"},
+			"landlord-tenant-t1",
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"create", "--name", "landlord-tenant-t1",
+			"--platform", "linux/arm64",
+			"--env", "PORT=8080",
+			"--label", "app=web", "--label", "landlord.tenant_id=t1",
+			"--publish", ":53/udp", "--publish", "0.0.0.0:9090:8080/tcp",
+			"--restart", "on-failure:3",
+			"--cpus", "1.5",
+			"--memory", "536870912",
+			"--volume", "/data:/app/data:ro",
+			"--network", "landlord-tenant-t1-net",
+			"--device", "/dev/fuse:/dev/fuse:rwm",
+			"--gpus", `driver=nvidia,"capabilities=gpu,compute",count=all`,
+			"--pids-limit", "100",
+			"--ulimit", "nofile=1024:4096",
+			"nginx:latest", "nginx", "-g", "daemon off;",
+		}, args)
+
+		_, err = nerdctlCreateArgs(&container.Config{Image: "app"}, &container.HostConfig{Isolation: container.IsolationHyperV}, nil, "x")
+		assert.ErrorIs(t, err, compute.ErrInvalidConfig)
+	})
+
+	t.Run("classifies nerdctl errors", func(t *testing.T) {
+		assert.True(t, cerrdefs.IsNotFound(nerdctlError("network", "network \"x\" not found\n", nil)))
+		assert.ErrorIs(t, nerdctlError("version", "cannot access containerd socket \"/run/containerd/containerd.sock\"", nil), compute.ErrProviderUnavailable)
+		assert.EqualError(t, nerdctlError("start", "", errors.New("exit status 1")), "nerdctl start: exit status 1")
+	})
+}
+
+// TestEgressPolicy tests egress policy validation and the iptables rules that enforce it
+func TestEgressPolicy(t *testing.T) {
+	t.Run("validates egress firewall", func(t *testing.T) {
+		assert.NoError(t, validateEgressFirewall(&Config{NetworkIsolation: NetworkIsolationShared, Runtime: RuntimeDocker}))
+		assert.NoError(t, validateEgressFirewall(&Config{EgressFirewall: EgressFirewallIPTables, NetworkIsolation: NetworkIsolationTenant, Runtime: RuntimeDocker}))
+		assert.Error(t, validateEgressFirewall(&Config{EgressFirewall: "nftables", NetworkIsolation: NetworkIsolationTenant, Runtime: RuntimeDocker}))
+		assert.Error(t, validateEgressFirewall(&Config{EgressFirewall: EgressFirewallIPTables, NetworkIsolation: NetworkIsolationShared, Runtime: RuntimeDocker}))
+		assert.Error(t, validateEgressFirewall(&Config{EgressFirewall: EgressFirewallIPTables, NetworkIsolation: NetworkIsolationTenant, Runtime: RuntimePodman}))
+		assert.Error(t, validateEgressFirewall(&Config{EgressFirewall: EgressFirewallIPTables, NetworkIsolation: NetworkIsolationTenant, Runtime: RuntimeDocker, IngressNetwork: "traefik"}))
+	})
+
+	t.Run("requires a firewall", func(t *testing.T) {
+		config := []byte(`{"image": "nginx:latest", "egress": {"allow": ["10.0.0.0/8"]}}`)
+		assert.ErrorIs(t, (&Provider{networkIsolation: NetworkIsolationTenant}).ValidateConfig(config), compute.ErrInvalidConfig)
+
+		enforced := &Provider{networkIsolation: NetworkIsolationTenant, firewall: &iptablesFirewall{}}
+		assert.NoError(t, enforced.ValidateConfig(config))
+		assert.ErrorIs(t, enforced.ValidateConfig([]byte(`{"image": "nginx:latest", "egress": {"allow": ["*.example.com"]}}`)), compute.ErrInvalidConfig)
+	})
+
+	t.Run("applies and removes tenant rules", func(t *testing.T) {
+		chains := egressChains("t1")
+		// live are the chains DOCKER-USER jumps to, and exists the chains that have been created
+		var live []string
+		exists := map[string]bool{}
+		var commands []string
+		firewall := &iptablesFirewall{
+			run: func(ctx context.Context, args ...string) ([]byte, error) {
+				command := strings.Join(args, " ")
+				commands = append(commands, command)
+				switch {
+				case command == "-S DOCKER-USER":
+					out := "-N DOCKER-USER\n"
+					for _, chain := range live {
+						out += "-A DOCKER-USER -s 172.20.0.0/16 -j " + chain + "\n"
+					}
+					return []byte(out + "-A DOCKER-USER -j RETURN\n"), nil
+				case args[0] == "-F" && !exists[args[1]]:
+					return nil, errors.New("exit status 1")
+				case args[0] == "-N":
+					exists[args[1]] = true
+				case args[0] == "-X":
+					delete(exists, args[1])
+				case args[0] == "-C":
+					return nil, errors.New("exit status 1")
+				case args[0] == "-I":
+					live = append([]string{args[len(args)-1]}, live...)
+				case args[0] == "-D":
+					for i, chain := range live {
+						if chain == args[len(args)-1] {
+							live = append(live[:i], live[i+1:]...)
+							break
+						}
+					}
+				}
+				return nil, nil
+			},
+			resolve: func(ctx context.Context, host string) ([]net.IPAddr, error) {
+				return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}, {IP: net.ParseIP("2606:2800:220:1::")}}, nil
+			},
+			logger: zap.NewNop(),
+		}
+		rules := func(chain string) []string {
+			return []string{
+				"-A " + chain + " -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN",
+				"-A " + chain + " -d 172.20.0.0/16 -j RETURN",
+				"-A " + chain + " -d 10.0.0.0/8 -j RETURN",
+				"-A " + chain + " -d 93.184.216.34/32 -j RETURN",
+				"-A " + chain + " -m limit --limit 10/min -j LOG --log-prefix landlord-egress-denied: ",
+				"-A " + chain + " -j DROP",
+			}
+		}
+
+		require.NoError(t, firewall.Apply(context.Background(), "t1", "172.20.0.0/16", []string{"10.0.0.0/8", "example.com"}))
+		expected := append([]string{"-S DOCKER-USER", "-F " + chains[0], "-N " + chains[0]}, rules(chains[0])...)
+		expected = append(expected,
+			"-C DOCKER-USER -s 172.20.0.0/16 -j "+chains[0],
+			"-I DOCKER-USER -s 172.20.0.0/16 -j "+chains[0],
+			"-S DOCKER-USER",
+			"-F "+chains[1],
+		)
+		assert.Equal(t, expected, commands)
+
+		// Re-applying builds the other chain and only retires the live one once traffic jumps to the new one
+		commands = nil
+		require.NoError(t, firewall.Apply(context.Background(), "t1", "172.20.0.0/16", []string{"10.0.0.0/8", "example.com"}))
+		expected = append([]string{"-S DOCKER-USER", "-F " + chains[1], "-N " + chains[1]}, rules(chains[1])...)
+		expected = append(expected,
+			"-C DOCKER-USER -s 172.20.0.0/16 -j "+chains[1],
+			"-I DOCKER-USER -s 172.20.0.0/16 -j "+chains[1],
+			"-S DOCKER-USER",
+			"-D DOCKER-USER -s 172.20.0.0/16 -j "+chains[0],
+			"-F "+chains[0],
+			"-X "+chains[0],
+		)
+		assert.Equal(t, expected, commands)
+		assert.Equal(t, []string{chains[1]}, live)
+
+		commands = nil
+		require.NoError(t, firewall.Remove(context.Background(), "t1"))
+		assert.Equal(t, []string{
+			"-S DOCKER-USER",
+			"-F " + chains[0],
+			"-S DOCKER-USER",
+			"-D DOCKER-USER -s 172.20.0.0/16 -j " + chains[1],
+			"-F " + chains[1],
+			"-X " + chains[1],
+		}, commands)
+		assert.Empty(t, exists)
+	})
+
+	t.Run("counts dropped packets", func(t *testing.T) {
+		firewall := &iptablesFirewall{run: func(ctx context.Context, args ...string) ([]byte, error) {
+			return []byte(`Chain LL-EGRESS-x (1 references)
+    pkts      bytes target     prot opt in     out     source               destination
+     120     9000 RETURN     all  --  *      *       0.0.0.0/0            0.0.0.0/0            ctstate RELATED,ESTABLISHED
+       7      420 LOG        all  --  *      *       0.0.0.0/0            0.0.0.0/0            limit: avg 10/min burst 5 LOG flags 0 level 4 prefix "landlord-egress-denied: "
+       7      420 DROP       all  --  *      *       0.0.0.0/0            0.0.0.0/0
+`), nil
+		}}
+		denied, err := firewall.Denied(context.Background(), "t1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), denied)
+
+		_, err = (&Provider{}).DeniedEgress(context.Background(), "t1")
+		assert.ErrorIs(t, err, compute.ErrTenantNotFound)
+	})
+}
+
+func TestSpecFingerprint(t *testing.T) {
+	spec := &compute.TenantComputeSpec{
+		TenantID: "t1",
+		Containers: []compute.ContainerSpec{{
+			Name:  "app",
+			Image: "nginx:1.27",
+			Ports: []compute.PortMapping{{ContainerPort: 80, HostPort: 8080, Protocol: "tcp"}},
+			Env:   map[string]string{"API_KEY": "secret-value", "MODE": "prod"},
+		}},
+		Resources:      compute.ResourceRequirements{CPU: 500, Memory: 256},
+		ProviderConfig: []byte(`{"restart_policy":"always"}`),
+	}
+	current, err := fingerprintSpec(spec)
+	require.NoError(t, err)
+
+	// The labels record the fingerprint without the values of env vars
+	labels := current.labels()
+	for _, value := range labels {
+		assert.NotContains(t, value, "secret-value")
+	}
+	restored, ok := fingerprintFromLabels(labels)
+	require.True(t, ok)
+	assert.Empty(t, restored.changes(current))
+
+	next := *spec
+	next.Containers = []compute.ContainerSpec{spec.Containers[0]}
+	next.Containers[0].Image = "nginx:1.28"
+	next.Containers[0].Env = map[string]string{"API_KEY": "rotated", "MODE": "prod"}
+	next.Resources.Memory = 512
+	changed, err := fingerprintSpec(&next)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"container image changed", "environment variables changed", "resource limits changed"}, restored.changes(changed))
+
+	// Containers labelled before fingerprints existed, or with tampered labels, cannot be diffed
+	_, ok = fingerprintFromLabels(map[string]string{compute.MetadataTenantIDKey: "t1"})
+	assert.False(t, ok)
+	labels[specImageLabel] = "nginx:1.28"
+	_, ok = fingerprintFromLabels(labels)
+	assert.False(t, ok)
+}
+
+// TestGracefulStop tests pre-stop hook validation, the labels that carry it to Destroy, and the HTTP hook
+func TestGracefulStop(t *testing.T) {
+	defaults := map[string]interface{}{"image": "nginx:latest"}
+
+	t.Run("validates pre_stop and termination_grace_period", func(t *testing.T) {
+		assert.NoError(t, validateConfig(defaults, []byte(`{"pre_stop": {"http": {"path": "/drain", "port": 8080}}, "termination_grace_period": "2m"}`)))
+		assert.NoError(t, validateConfig(defaults, []byte(`{"pre_stop": {"signal": "SIGUSR1"}}`)))
+		for _, config := range []string{
+			`{"pre_stop": {}}`,
+			`{"pre_stop": {"http": {"port": 8080}, "signal": "SIGTERM"}}`,
+			`{"pre_stop": {"http": {"port": 0}}}`,
+			`{"pre_stop": {"http": {"path": "drain", "port": 8080}}}`,
+			`{"pre_stop": {"signal": "TERM"}}`,
+			`{"termination_grace_period": "soon"}`,
+			`{"termination_grace_period": "2h"}`,
+		} {
+			assert.ErrorIs(t, validateConfig(defaults, []byte(config)), compute.ErrInvalidConfig, config)
+		}
+	})
+
+	t.Run("round-trips through container labels", func(t *testing.T) {
+		hook, grace := gracefulStopFromLabels(gracefulStopLabels(&DockerComputeConfig{
+			PreStop:                &PreStopConfig{HTTP: &PreStopHTTPConfig{Path: "/drain", Port: 8080}},
+			TerminationGracePeriod: "45s",
+		}))
+		require.NotNil(t, hook)
+		assert.Equal(t, &PreStopHTTPConfig{Path: "/drain", Port: 8080}, hook.HTTP)
+		assert.Equal(t, 45*time.Second, grace)
+
+		// Containers created without a hook get the default grace period
+		hook, grace = gracefulStopFromLabels(gracefulStopLabels(&DockerComputeConfig{}))
+		assert.Nil(t, hook)
+		assert.Equal(t, defaultTerminationGracePeriod, grace)
+	})
+
+	t.Run("posts the HTTP hook to the container", func(t *testing.T) {
+		var drained string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			drained = r.Method + " " + r.URL.Path
+		}))
+		defer server.Close()
+		host, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+		require.NoError(t, err)
+		portNumber, err := strconv.Atoi(port)
+		require.NoError(t, err)
+
+		inspect := &types.ContainerJSON{NetworkSettings: &container.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{"bridge": {IPAddress: host}},
+		}}
+		p := &Provider{logger: zap.NewNop()}
+		hook := &PreStopConfig{HTTP: &PreStopHTTPConfig{Path: "/drain", Port: portNumber}}
+		require.NoError(t, p.runPreStopHook(context.Background(), "c1", inspect, hook))
+		assert.Equal(t, "POST /drain", drained)
 	})
 }
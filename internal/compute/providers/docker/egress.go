@@ -0,0 +1,292 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/network"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// EgressFirewallIPTables enforces egress policies with iptables rules in the DOCKER-USER chain
+const EgressFirewallIPTables = "iptables"
+
+// dockerUserChain is the chain Docker evaluates before its own forwarding rules
+const dockerUserChain = "DOCKER-USER"
+
+// validateEgressFirewall checks the firewall and the network settings it relies on
+func validateEgressFirewall(cfg *Config) error {
+	switch cfg.EgressFirewall {
+	case "":
+	case EgressFirewallIPTables:
+		// Rules match the tenant's own subnet, and DOCKER-USER only exists for the Docker daemon
+		if cfg.NetworkIsolation != NetworkIsolationTenant {
+			return fmt.Errorf("egress_firewall requires network_isolation %q", NetworkIsolationTenant)
+		}
+		if cfg.Runtime != RuntimeDocker {
+			return fmt.Errorf("egress_firewall requires runtime %q", RuntimeDocker)
+		}
+		// Containers also reach the network through the ingress network, which the subnet rules never see
+		if cfg.IngressNetwork != "" {
+			return fmt.Errorf("egress_firewall cannot be combined with ingress_network")
+		}
+	default:
+		return fmt.Errorf("invalid egress_firewall %q, must be %q", cfg.EgressFirewall, EgressFirewallIPTables)
+	}
+	return nil
+}
+
+// Firewall enforces tenant egress policies on the Docker host
+type Firewall interface {
+	// Apply replaces the tenant's rules so traffic from subnet may only reach allow and subnet itself
+	Apply(ctx context.Context, tenantID, subnet string, allow []string) error
+
+	// Remove deletes the tenant's rules; a tenant without rules is not an error
+	Remove(ctx context.Context, tenantID string) error
+
+	// Denied returns how many packets the tenant's rules have dropped
+	Denied(ctx context.Context, tenantID string) (int64, error)
+}
+
+// iptablesFirewall keeps each tenant's rules in a chain of its own, jumped to from DOCKER-USER for
+// traffic from the tenant's subnet. The chain returns allowed traffic to Docker's rules, and logs
+// and drops the rest. Each tenant has two chains that take turns: new rules are built in the idle
+// one and the jump is moved to it, so the tenant is never left without rules while they change.
+type iptablesFirewall struct {
+	run     func(ctx context.Context, args ...string) ([]byte, error)
+	resolve func(ctx context.Context, host string) ([]net.IPAddr, error)
+	logger  *zap.Logger
+}
+
+func newIPTablesFirewall(logger *zap.Logger) (*iptablesFirewall, error) {
+	binary, err := exec.LookPath("iptables")
+	if err != nil {
+		return nil, fmt.Errorf("egress_firewall %q needs iptables on PATH: %w", EgressFirewallIPTables, err)
+	}
+	return &iptablesFirewall{
+		run: func(ctx context.Context, args ...string) ([]byte, error) {
+			cmd := exec.CommandContext(ctx, binary, append([]string{"-w"}, args...)...)
+			var stderr bytes.Buffer
+			cmd.Stderr = &stderr
+			out, err := cmd.Output()
+			if err != nil {
+				return nil, fmt.Errorf("iptables %s: %s: %w", strings.Join(args, " "), strings.TrimSpace(stderr.String()), err)
+			}
+			return out, nil
+		},
+		resolve: net.DefaultResolver.LookupIPAddr,
+		logger:  logger,
+	}, nil
+}
+
+// egressChains returns the names of the tenant's two chains; iptables limits chain names to 28 characters
+func egressChains(tenantID string) [2]string {
+	sum := sha256.Sum256([]byte(tenantID))
+	base := "LL-EGRESS-" + hex.EncodeToString(sum[:])[:12]
+	return [2]string{base + "-A", base + "-B"}
+}
+
+// jumps returns the DOCKER-USER rules that jump to chain, as arguments that append them
+func (f *iptablesFirewall) jumps(ctx context.Context, chain string) ([][]string, error) {
+	out, err := f.run(ctx, "-S", dockerUserChain)
+	if err != nil {
+		return nil, err
+	}
+	var rules [][]string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "-A" && fields[len(fields)-1] == chain {
+			rules = append(rules, fields)
+		}
+	}
+	return rules, nil
+}
+
+// retire deletes the jumps to chain, then the chain itself; a chain that does not exist is not an error
+func (f *iptablesFirewall) retire(ctx context.Context, chain string) (bool, error) {
+	// The tenant's subnet is gone by the time it is destroyed, so jumps are found by their target
+	jumps, err := f.jumps(ctx, chain)
+	if err != nil {
+		return false, err
+	}
+	for _, jump := range jumps {
+		jump[0] = "-D"
+		if _, err := f.run(ctx, jump...); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err := f.run(ctx, "-F", chain); err != nil {
+		return false, nil
+	}
+	if _, err := f.run(ctx, "-X", chain); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Apply implements Firewall
+func (f *iptablesFirewall) Apply(ctx context.Context, tenantID, subnet string, allow []string) error {
+	chains := egressChains(tenantID)
+
+	// Domains are resolved now; a tenant that needs fresh addresses is updated to re-apply its policy
+	destinations := []string{subnet}
+	for _, entry := range allow {
+		if strings.Contains(entry, "/") {
+			destinations = append(destinations, entry)
+			continue
+		}
+		addrs, err := f.resolve(ctx, entry)
+		if err != nil {
+			return fmt.Errorf("resolve egress destination %s: %w", entry, err)
+		}
+		for _, addr := range addrs {
+			// The rules are IPv4; Docker only adds IPv6 to networks created with it
+			if ip4 := addr.IP.To4(); ip4 != nil {
+				destinations = append(destinations, ip4.String()+"/32")
+			}
+		}
+	}
+
+	// The new rules go in whichever chain traffic is not jumping to
+	live, err := f.jumps(ctx, chains[0])
+	if err != nil {
+		return err
+	}
+	chain, previous := chains[0], chains[1]
+	if len(live) > 0 {
+		chain, previous = chains[1], chains[0]
+	}
+	if _, err := f.run(ctx, "-F", chain); err != nil {
+		if _, err := f.run(ctx, "-N", chain); err != nil {
+			return err
+		}
+	}
+	rules := [][]string{{"-m", "conntrack", "--ctstate", "ESTABLISHED,RELATED", "-j", "RETURN"}}
+	for _, destination := range destinations {
+		if strings.Contains(destination, ":") {
+			continue
+		}
+		rules = append(rules, []string{"-d", destination, "-j", "RETURN"})
+	}
+	rules = append(rules,
+		[]string{"-m", "limit", "--limit", "10/min", "-j", "LOG", "--log-prefix", "landlord-egress-denied: "},
+		[]string{"-j", "DROP"},
+	)
+	for _, rule := range rules {
+		if _, err := f.run(ctx, append([]string{"-A", chain}, rule...)...); err != nil {
+			return err
+		}
+	}
+
+	jump := []string{dockerUserChain, "-s", subnet, "-j", chain}
+	if _, err := f.run(ctx, append([]string{"-C"}, jump...)...); err != nil {
+		if _, err := f.run(ctx, append([]string{"-I"}, jump...)...); err != nil {
+			return err
+		}
+	}
+	// Traffic now reaches the new rules first, so the old ones can go
+	if _, err := f.retire(ctx, previous); err != nil {
+		return err
+	}
+
+	f.logger.Info("egress policy applied", zap.String("tenant_id", tenantID), zap.String("chain", chain), zap.Strings("destinations", destinations))
+	return nil
+}
+
+// Remove implements Firewall
+func (f *iptablesFirewall) Remove(ctx context.Context, tenantID string) error {
+	for _, chain := range egressChains(tenantID) {
+		removed, err := f.retire(ctx, chain)
+		if err != nil {
+			return err
+		}
+		if removed {
+			f.logger.Info("egress policy removed", zap.String("tenant_id", tenantID), zap.String("chain", chain))
+		}
+	}
+	return nil
+}
+
+// Denied implements Firewall
+func (f *iptablesFirewall) Denied(ctx context.Context, tenantID string) (int64, error) {
+	// Only one of the tenant's chains exists outside of an Apply
+	for _, chain := range egressChains(tenantID) {
+		out, err := f.run(ctx, "-L", chain, "-n", "-v", "-x")
+		if err != nil {
+			continue
+		}
+		// Rows are: pkts bytes target prot opt in out source destination
+		scanner := bufio.NewScanner(bytes.NewReader(out))
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) < 3 || fields[2] != "DROP" {
+				continue
+			}
+			return strconv.ParseInt(fields[0], 10, 64)
+		}
+		return 0, fmt.Errorf("egress chain for %s has no DROP rule", tenantID)
+	}
+	return 0, fmt.Errorf("%w: no egress policy applied for %s", compute.ErrTenantNotFound, tenantID)
+}
+
+var _ compute.EgressReporter = (*Provider)(nil)
+
+// DeniedEgress returns how many outbound packets a tenant's egress policy has dropped
+func (p *Provider) DeniedEgress(ctx context.Context, tenantID string) (int64, error) {
+	if p.firewall == nil {
+		return 0, fmt.Errorf("%w: egress is not enforced by this provider", compute.ErrTenantNotFound)
+	}
+	return p.firewall.Denied(ctx, tenantID)
+}
+
+// applyEgressPolicy applies, replaces or removes the tenant's egress rules to match policy
+func (p *Provider) applyEgressPolicy(ctx context.Context, tenantID string, policy *compute.EgressPolicy) error {
+	if policy == nil {
+		if p.firewall == nil {
+			return nil
+		}
+		if err := p.firewall.Remove(ctx, tenantID); err != nil {
+			return fmt.Errorf("failed to remove egress policy: %w", err)
+		}
+		return nil
+	}
+	if p.firewall == nil {
+		return fmt.Errorf("%w: egress requires the docker provider's egress_firewall", compute.ErrInvalidConfig)
+	}
+
+	subnet, err := p.tenantSubnet(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := p.firewall.Apply(ctx, tenantID, subnet, append(policy.Networks(), policy.Domains()...)); err != nil {
+		p.logger.Error("failed to apply egress policy", zap.String("tenant_id", tenantID), zap.Error(err))
+		return fmt.Errorf("failed to apply egress policy: %w", err)
+	}
+	return nil
+}
+
+// tenantSubnet returns the IPv4 subnet of the tenant's dedicated network
+func (p *Provider) tenantSubnet(ctx context.Context, tenantID string) (string, error) {
+	inspect, err := p.client.NetworkInspect(ctx, tenantNetworkName(tenantID), network.InspectOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to inspect tenant network: %w", classifyDockerError(err))
+	}
+	for _, ipam := range inspect.IPAM.Config {
+		if _, ipNet, err := net.ParseCIDR(ipam.Subnet); err == nil && ipNet.IP.To4() != nil {
+			return ipNet.String(), nil
+		}
+	}
+	return "", fmt.Errorf("tenant network %s has no IPv4 subnet", tenantNetworkName(tenantID))
+}
@@ -0,0 +1,134 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// Labels recording what a tenant's container was provisioned from, so an update can tell what
+// changed when the provisioned spec is not in memory, such as after a restart or for a container
+// another process created. Env and provider config may hold secrets and are stored as hashes.
+const (
+	specHashLabel       = defaultLabelPrefix + ".spec.hash"
+	specImageLabel      = defaultLabelPrefix + ".spec.image"
+	specPlatformLabel   = defaultLabelPrefix + ".spec.platform"
+	specPortsLabel      = defaultLabelPrefix + ".spec.ports"
+	specEnvHashLabel    = defaultLabelPrefix + ".spec.env_hash"
+	specConfigHashLabel = defaultLabelPrefix + ".spec.config_hash"
+	specCPULabel        = defaultLabelPrefix + ".spec.cpu"
+	specMemoryLabel     = defaultLabelPrefix + ".spec.memory"
+)
+
+// specFingerprint is the part of a tenant's spec that decides whether its container must be recreated
+type specFingerprint struct {
+	Image      string
+	Platform   string
+	Ports      string
+	EnvHash    string
+	ConfigHash string
+	CPU        string
+	Memory     string
+}
+
+// fingerprintSpec fingerprints the spec's workload container, its resources and its provider config
+func fingerprintSpec(spec *compute.TenantComputeSpec) (specFingerprint, error) {
+	index, err := workloadIndex(spec)
+	if err != nil {
+		return specFingerprint{}, err
+	}
+	workload := spec.Containers[index]
+
+	ports := make([]string, 0, len(workload.Ports))
+	for _, port := range workload.Ports {
+		ports = append(ports, fmt.Sprintf("%d:%d/%s", port.HostPort, port.ContainerPort, port.Protocol))
+	}
+	env := convertEnv(workload.Env)
+	sort.Strings(env)
+
+	return specFingerprint{
+		Image:      workload.Image,
+		Platform:   workload.Platform,
+		Ports:      strings.Join(ports, ","),
+		EnvHash:    hashValues(env...),
+		ConfigHash: hashValues(string(spec.ProviderConfig)),
+		CPU:        strconv.Itoa(spec.Resources.CPU),
+		Memory:     strconv.Itoa(spec.Resources.Memory),
+	}, nil
+}
+
+// fingerprintFromLabels reads the fingerprint a container was labelled with at provision. Reports
+// false for containers provisioned before the labels were added.
+func fingerprintFromLabels(labels map[string]string) (specFingerprint, bool) {
+	f := specFingerprint{
+		Image:      labels[specImageLabel],
+		Platform:   labels[specPlatformLabel],
+		Ports:      labels[specPortsLabel],
+		EnvHash:    labels[specEnvHashLabel],
+		ConfigHash: labels[specConfigHashLabel],
+		CPU:        labels[specCPULabel],
+		Memory:     labels[specMemoryLabel],
+	}
+	hash, ok := labels[specHashLabel]
+	return f, ok && hash == f.hash()
+}
+
+// labels returns the container labels that record the fingerprint
+func (f specFingerprint) labels() map[string]string {
+	return map[string]string{
+		specHashLabel:       f.hash(),
+		specImageLabel:      f.Image,
+		specPlatformLabel:   f.Platform,
+		specPortsLabel:      f.Ports,
+		specEnvHashLabel:    f.EnvHash,
+		specConfigHashLabel: f.ConfigHash,
+		specCPULabel:        f.CPU,
+		specMemoryLabel:     f.Memory,
+	}
+}
+
+func (f specFingerprint) hash() string {
+	return hashValues(f.Image, f.Platform, f.Ports, f.EnvHash, f.ConfigHash, f.CPU, f.Memory)
+}
+
+// changes describes what differs between the provisioned fingerprint f and next
+func (f specFingerprint) changes(next specFingerprint) []string {
+	changes := []string{}
+	if f.hash() == next.hash() {
+		return changes
+	}
+	if f.Image != next.Image {
+		changes = append(changes, "container image changed")
+	}
+	if f.Platform != next.Platform {
+		changes = append(changes, "platform changed")
+	}
+	if f.Ports != next.Ports {
+		changes = append(changes, "port mappings changed")
+	}
+	if f.EnvHash != next.EnvHash {
+		changes = append(changes, "environment variables changed")
+	}
+	if f.ConfigHash != next.ConfigHash {
+		changes = append(changes, "provider config changed")
+	}
+	if f.CPU != next.CPU || f.Memory != next.Memory {
+		changes = append(changes, "resource limits changed")
+	}
+	return changes
+}
+
+// hashValues hashes values, keeping them apart so ("ab", "c") and ("a", "bc") differ
+func hashValues(values ...string) string {
+	h := sha256.New()
+	for _, value := range values {
+		h.Write([]byte(value))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
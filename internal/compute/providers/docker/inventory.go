@@ -0,0 +1,74 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/doctor"
+)
+
+var _ compute.Inventory = (*Provider)(nil)
+
+// ListTenants returns the tenants with a landlord container on the host, running or not. It asks
+// the runtime rather than the containers this process created, so containers left behind by an
+// earlier process are found too.
+func (p *Provider) ListTenants(ctx context.Context) ([]string, error) {
+	containers, err := p.client.ContainerList(ctx, container.ListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("label", compute.MetadataOwnerKey+"="+compute.MetadataOwnerValue)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", classifyDockerError(err))
+	}
+
+	seen := make(map[string]bool)
+	var tenantIDs []string
+	for _, c := range containers {
+		tenantID := c.Labels[compute.MetadataTenantIDKey]
+		if tenantID == "" || seen[tenantID] {
+			continue
+		}
+		seen[tenantID] = true
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	sort.Strings(tenantIDs)
+	return tenantIDs, nil
+}
+
+// findTenantContainer returns the ID of a tenant's container from its labels, running or not,
+// for tenants whose container this process did not create
+func (p *Provider) findTenantContainer(ctx context.Context, tenantID string) (string, error) {
+	containers, err := p.client.ContainerList(ctx, container.ListOptions{
+		All: true,
+		Filters: filters.NewArgs(
+			filters.Arg("label", compute.MetadataOwnerKey+"="+compute.MetadataOwnerValue),
+			filters.Arg("label", compute.MetadataTenantIDKey+"="+tenantID),
+		),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list containers: %w", classifyDockerError(err))
+	}
+	for _, c := range containers {
+		// Job containers carry the tenant's label too, but are not the tenant's workload
+		if _, job := c.Labels[defaultLabelPrefix+".job"]; !job {
+			return c.ID, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", compute.ErrTenantNotFound, tenantID)
+}
+
+var _ doctor.Diagnoser = (*Provider)(nil)
+
+// Diagnose checks that the container runtime answers
+func (p *Provider) Diagnose(ctx context.Context) []doctor.Result {
+	if _, err := p.client.Ping(ctx); err != nil {
+		return []doctor.Result{doctor.Fail("", doctor.SeverityCritical, fmt.Sprintf("container runtime is unreachable: %v", classifyDockerError(err)),
+			"Check that the container runtime is running and compute.docker.host (or DOCKER_HOST) points at it")}
+	}
+	return []doctor.Result{doctor.Pass("", "container runtime is reachable")}
+}
@@ -0,0 +1,140 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// maxJobOutputBytes caps how much job output is kept in results and state history
+const maxJobOutputBytes = 4096
+
+var _ compute.JobRunner = (*Provider)(nil)
+
+// RunJob runs a one-off container for a tenant and waits for it to exit.
+// The container is always removed, even when ctx is cancelled.
+func (p *Provider) RunJob(ctx context.Context, tenantID string, job *compute.JobSpec) (*compute.JobResult, error) {
+	if job == nil {
+		return nil, fmt.Errorf("job spec is required")
+	}
+	if job.Image == "" {
+		return nil, fmt.Errorf("job image is required")
+	}
+	if !isValidImageRef(job.Image) {
+		return nil, fmt.Errorf("invalid job image reference %q", job.Image)
+	}
+
+	containerConfig := &container.Config{
+		Image: job.Image,
+		Env:   convertEnv(job.Env),
+		Labels: map[string]string{
+			defaultLabelPrefix + ".tenant_id": tenantID,
+			defaultLabelPrefix + ".job":       job.Name,
+		},
+	}
+	if len(job.Command) > 0 {
+		containerConfig.Cmd = job.Command
+	}
+
+	// Jobs share the tenant's network so they can reach the tenant and nothing else
+	hostConfig := &container.HostConfig{}
+	if p.isolated() {
+		hostConfig.NetworkMode = container.NetworkMode(tenantNetworkName(tenantID))
+	}
+
+	containerName := fmt.Sprintf("%s-tenant-%s-job-%s-%d", defaultLabelPrefix, tenantID, job.Name, time.Now().UnixNano())
+	resp, err := p.client.ContainerCreate(ctx, containerConfig, hostConfig, nil, nil, containerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job container: %w", err)
+	}
+	defer func() {
+		// Use a fresh context so cleanup still happens after cancellation
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := p.client.ContainerRemove(cleanupCtx, resp.ID, container.RemoveOptions{Force: true}); err != nil {
+			p.logger.Warn("failed to remove job container", zap.String("container_id", resp.ID), zap.Error(err))
+		}
+	}()
+
+	startedAt := time.Now()
+	if err := p.client.ContainerStart(ctx, resp.ID, container.StartOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to start job container: %w", err)
+	}
+
+	p.logger.Info("job started",
+		zap.String("tenant_id", tenantID),
+		zap.String("job", job.Name),
+		zap.String("container_id", resp.ID),
+	)
+
+	waitCh, errCh := p.client.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
+	var exitCode int64
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("job %s: %w", job.Name, ctx.Err())
+	case err := <-errCh:
+		return nil, fmt.Errorf("failed waiting for job container: %w", err)
+	case status := <-waitCh:
+		if status.Error != nil && status.Error.Message != "" {
+			return nil, fmt.Errorf("job container wait error: %s", status.Error.Message)
+		}
+		exitCode = status.StatusCode
+	}
+
+	return &compute.JobResult{
+		ExitCode:    int(exitCode),
+		Output:      p.jobOutput(ctx, resp.ID),
+		StartedAt:   startedAt,
+		CompletedAt: time.Now(),
+	}, nil
+}
+
+// runInitContainers runs the spec's init containers in order, stopping at the first that does not exit cleanly
+func (p *Provider) runInitContainers(ctx context.Context, spec *compute.TenantComputeSpec) error {
+	for _, init := range spec.InitContainers() {
+		command := append(append([]string{}, init.Command...), init.Args...)
+		result, err := p.RunJob(ctx, spec.TenantID, &compute.JobSpec{
+			Name:    init.Name,
+			Image:   init.Image,
+			Command: command,
+			Env:     init.Env,
+		})
+		if err != nil {
+			return fmt.Errorf("init container %s: %w", init.Name, err)
+		}
+		if result.ExitCode != 0 {
+			return fmt.Errorf("init container %s exited with code %d: %s", init.Name, result.ExitCode, result.Output)
+		}
+		p.logger.Info("init container completed", zap.String("tenant_id", spec.TenantID), zap.String("init_container", init.Name))
+	}
+	return nil
+}
+
+// jobOutput returns the tail of a job container's combined output; errors are logged and ignored
+func (p *Provider) jobOutput(ctx context.Context, containerID string) string {
+	logs, err := p.client.ContainerLogs(ctx, containerID, container.LogsOptions{ShowStdout: true, ShowStderr: true})
+	if err != nil {
+		p.logger.Debug("failed to read job logs", zap.String("container_id", containerID), zap.Error(err))
+		return ""
+	}
+	defer logs.Close()
+
+	var combined bytes.Buffer
+	if _, err := stdcopy.StdCopy(&combined, &combined, io.LimitReader(logs, 16*maxJobOutputBytes)); err != nil {
+		p.logger.Debug("failed to demultiplex job logs", zap.String("container_id", containerID), zap.Error(err))
+	}
+
+	output := combined.Bytes()
+	if len(output) > maxJobOutputBytes {
+		output = output[len(output)-maxJobOutputBytes:]
+	}
+	return string(output)
+}
@@ -0,0 +1,169 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+const (
+	// NamingIDFull ends container names with the tenant's full ID (default)
+	NamingIDFull = "full"
+
+	// NamingIDShort ends container names with the first 8 characters of the tenant's UUID
+	NamingIDShort = "short"
+
+	// NamingIDNone leaves the tenant's ID out of container names
+	NamingIDNone = "none"
+
+	defaultNamingPrefix = "landlord-tenant"
+	shortIDLength       = 8
+	maxSlugLength       = 40
+)
+
+// NamingConfig controls the names of tenant containers, which are
+// <prefix>[-<environment>][-<tenant name>][-<id>]. The default is landlord-tenant-<tenant id>.
+type NamingConfig struct {
+	// Prefix starts every container name. Defaults to "landlord-tenant"
+	Prefix string `json:"prefix,omitempty"`
+
+	// IncludeName adds a slug of the tenant's name
+	IncludeName bool `json:"include_name,omitempty"`
+
+	// IncludeEnvironment adds a slug of the tenant's env label, when it has one
+	IncludeEnvironment bool `json:"include_environment,omitempty"`
+
+	// ID is how much of the tenant's ID ends the name: "full" (default), "short" or "none".
+	// "short" and "none" require IncludeName.
+	ID string `json:"id,omitempty"`
+}
+
+// containerNamePattern is what Docker accepts as a container name
+var containerNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// validateNaming checks the naming options
+func validateNaming(cfg *NamingConfig) error {
+	if !containerNamePattern.MatchString(cfg.Prefix) {
+		return fmt.Errorf("invalid naming prefix %q: must start with a letter or digit and contain only letters, digits, '_', '.' and '-'", cfg.Prefix)
+	}
+	switch cfg.ID {
+	case NamingIDFull:
+	case NamingIDShort, NamingIDNone:
+		if !cfg.IncludeName {
+			return fmt.Errorf("naming id %q requires include_name", cfg.ID)
+		}
+	default:
+		return fmt.Errorf("invalid naming id %q, must be %q, %q or %q", cfg.ID, NamingIDFull, NamingIDShort, NamingIDNone)
+	}
+	return nil
+}
+
+// containerName returns the name the naming options give spec's container. A tenant whose name
+// the request did not carry, such as one sent by an older controller, ends with its full ID.
+func (n NamingConfig) containerName(spec *compute.TenantComputeSpec) string {
+	parts := []string{n.Prefix}
+	if n.IncludeEnvironment {
+		if env := slug(spec.Labels[tenant.LabelTenantEnvironment]); env != "" {
+			parts = append(parts, env)
+		}
+	}
+	var name string
+	if n.IncludeName {
+		name = slug(spec.Labels[tenant.LabelTenantName])
+	}
+	if name == "" {
+		return strings.Join(append(parts, spec.TenantID), "-")
+	}
+	parts = append(parts, name)
+
+	switch n.ID {
+	case NamingIDShort, NamingIDNone:
+		// A component other than the default one runs as <uuid>-<component>, and keeps its suffix
+		// so a tenant's components do not share a name
+		uuid := spec.Labels[tenant.LabelTenantID]
+		if uuid == "" || !strings.HasPrefix(spec.TenantID, uuid) {
+			return strings.Join(append(parts, spec.TenantID), "-")
+		}
+		if n.ID == NamingIDShort {
+			parts = append(parts, uuid[:min(shortIDLength, len(uuid))])
+		}
+		if component := strings.TrimPrefix(strings.TrimPrefix(spec.TenantID, uuid), "-"); component != "" {
+			parts = append(parts, component)
+		}
+		return strings.Join(parts, "-")
+	default:
+		return strings.Join(append(parts, spec.TenantID), "-")
+	}
+}
+
+// fallbackName is the name used when spec's container name is taken by another container. The
+// full ID keeps it unique.
+func (n NamingConfig) fallbackName(spec *compute.TenantComputeSpec) string {
+	full := n
+	full.ID = NamingIDFull
+	return full.containerName(spec)
+}
+
+// resolveContainerName returns the name spec's container is created with. When the configured
+// name is held by a container that is not the tenant's, the name ends with the tenant's full ID instead.
+func (p *Provider) resolveContainerName(ctx context.Context, spec *compute.TenantComputeSpec) (string, error) {
+	name := p.naming.containerName(spec)
+	fallback := p.naming.fallbackName(spec)
+	if name == fallback {
+		// Names that end with the full ID cannot collide
+		return name, nil
+	}
+
+	existing, err := p.client.ContainerInspect(ctx, name)
+	if err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return name, nil
+		}
+		return "", fmt.Errorf("failed to inspect container %s: %w", name, classifyDockerError(err))
+	}
+	var labels map[string]string
+	if existing.Config != nil {
+		labels = existing.Config.Labels
+	}
+	if labels[compute.MetadataOwnerKey] == compute.MetadataOwnerValue && labels[compute.MetadataTenantIDKey] == spec.TenantID {
+		// The tenant's own container, left by an earlier attempt; creating it again reports the conflict
+		return name, nil
+	}
+
+	p.logger.Warn("container name is taken by another container, using the tenant ID",
+		zap.String("tenant_id", spec.TenantID),
+		zap.String("container_name", name),
+		zap.String("owner", labels[compute.MetadataTenantIDKey]),
+		zap.String("fallback_name", fallback),
+	)
+	return fallback, nil
+}
+
+// slug lowercases s and replaces every run of characters Docker does not accept in names with '-'
+func slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	out := strings.TrimSuffix(b.String(), "-")
+	if len(out) > maxSlugLength {
+		out = strings.TrimSuffix(out[:maxSlugLength], "-")
+	}
+	return out
+}
@@ -0,0 +1,430 @@
+package docker
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/system"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// nerdctlRuntime drives containerd through the nerdctl CLI, whose commands and inspect output
+// follow Docker's, and adapts it to the Docker API types the provider uses
+type nerdctlRuntime struct {
+	binary string
+	// globalArgs select the containerd address and namespace for every command
+	globalArgs []string
+}
+
+var _ Runtime = (*nerdctlRuntime)(nil)
+
+// newNerdctlRuntime finds nerdctl on PATH; an empty address or namespace uses nerdctl's defaults
+func newNerdctlRuntime(address, namespace string) (*nerdctlRuntime, error) {
+	binary, err := exec.LookPath("nerdctl")
+	if err != nil {
+		return nil, fmt.Errorf("runtime %q needs nerdctl on PATH: %w", RuntimeContainerd, err)
+	}
+	r := &nerdctlRuntime{binary: binary}
+	if address != "" {
+		r.globalArgs = append(r.globalArgs, "--address", strings.TrimPrefix(address, "unix://"))
+	}
+	if namespace != "" {
+		r.globalArgs = append(r.globalArgs, "--namespace", namespace)
+	}
+	return r, nil
+}
+
+// run runs a nerdctl command and returns its stdout
+func (r *nerdctlRuntime) run(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, r.binary, append(slices.Clone(r.globalArgs), args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, nerdctlError(args[0], stderr.String(), err)
+	}
+	return out, nil
+}
+
+// nerdctlError turns a failed command into an error the provider can classify like a Docker API error
+func nerdctlError(command, stderr string, err error) error {
+	message := strings.TrimSpace(stderr)
+	if message == "" {
+		message = err.Error()
+	}
+	switch {
+	case strings.Contains(message, "not found") || strings.Contains(message, "no such"):
+		return fmt.Errorf("nerdctl %s: %w: %s", command, cerrdefs.ErrNotFound, message)
+	case strings.Contains(message, "connection refused") || strings.Contains(message, "cannot access containerd socket"):
+		return compute.Mark(fmt.Errorf("nerdctl %s: %s", command, message), compute.ErrProviderUnavailable)
+	}
+	return fmt.Errorf("nerdctl %s: %s", command, message)
+}
+
+func (r *nerdctlRuntime) Ping(ctx context.Context) (types.Ping, error) {
+	_, err := r.run(ctx, "version")
+	return types.Ping{}, err
+}
+
+func (r *nerdctlRuntime) Info(ctx context.Context) (system.Info, error) {
+	var info system.Info
+	out, err := r.run(ctx, "info", "--format", "{{json .}}")
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(out, &info); err != nil {
+		return info, fmt.Errorf("decode nerdctl info: %w", err)
+	}
+	return info, nil
+}
+
+// ImagePull pulls synchronously; failures are returned directly rather than in the progress stream
+func (r *nerdctlRuntime) ImagePull(ctx context.Context, ref string, options image.PullOptions) (io.ReadCloser, error) {
+	args := []string{"pull", "--quiet"}
+	if options.Platform != "" {
+		args = append(args, "--platform", options.Platform)
+	}
+	if _, err := r.run(ctx, append(args, ref)...); err != nil {
+		return nil, err
+	}
+	return io.NopCloser(strings.NewReader("")), nil
+}
+
+func (r *nerdctlRuntime) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (container.CreateResponse, error) {
+	args, err := nerdctlCreateArgs(config, hostConfig, platform, containerName)
+	if err != nil {
+		return container.CreateResponse{}, err
+	}
+	out, err := r.run(ctx, args...)
+	if err != nil {
+		return container.CreateResponse{}, err
+	}
+	return container.CreateResponse{ID: strings.TrimSpace(string(out))}, nil
+}
+
+func (r *nerdctlRuntime) ContainerStart(ctx context.Context, containerID string, options container.StartOptions) error {
+	_, err := r.run(ctx, "start", containerID)
+	return err
+}
+
+func (r *nerdctlRuntime) ContainerStop(ctx context.Context, containerID string, options container.StopOptions) error {
+	_, err := r.run(ctx, append(append([]string{"stop"}, stopTimeoutArgs(options)...), containerID)...)
+	return err
+}
+
+func (r *nerdctlRuntime) ContainerRestart(ctx context.Context, containerID string, options container.StopOptions) error {
+	_, err := r.run(ctx, append(append([]string{"restart"}, stopTimeoutArgs(options)...), containerID)...)
+	return err
+}
+
+func (r *nerdctlRuntime) ContainerKill(ctx context.Context, containerID, signal string) error {
+	_, err := r.run(ctx, "kill", "--signal", signal, containerID)
+	return err
+}
+
+func stopTimeoutArgs(options container.StopOptions) []string {
+	if options.Timeout == nil {
+		return nil
+	}
+	return []string{"--time", strconv.Itoa(*options.Timeout)}
+}
+
+func (r *nerdctlRuntime) ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error {
+	args := []string{"rm"}
+	if options.Force {
+		args = append(args, "--force")
+	}
+	if options.RemoveVolumes {
+		args = append(args, "--volumes")
+	}
+	_, err := r.run(ctx, append(args, containerID)...)
+	return err
+}
+
+func (r *nerdctlRuntime) ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error) {
+	out, err := r.run(ctx, "container", "inspect", containerID)
+	if err != nil {
+		return container.InspectResponse{}, err
+	}
+	var inspected []container.InspectResponse
+	if err := json.Unmarshal(out, &inspected); err != nil {
+		return container.InspectResponse{}, fmt.Errorf("decode nerdctl container inspect: %w", err)
+	}
+	if len(inspected) == 0 {
+		return container.InspectResponse{}, fmt.Errorf("%w: container %s", cerrdefs.ErrNotFound, containerID)
+	}
+	return inspected[0], nil
+}
+
+// ContainerList finds containers with ps and reads them with inspect; only label filters are applied
+func (r *nerdctlRuntime) ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error) {
+	args := []string{"ps", "--quiet", "--no-trunc"}
+	if options.All {
+		args = append(args, "--all")
+	}
+	for _, label := range options.Filters.Get("label") {
+		args = append(args, "--filter", "label="+label)
+	}
+	out, err := r.run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	ids := strings.Fields(string(out))
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	out, err = r.run(ctx, append([]string{"container", "inspect"}, ids...)...)
+	if err != nil {
+		return nil, err
+	}
+	var inspected []container.InspectResponse
+	if err := json.Unmarshal(out, &inspected); err != nil {
+		return nil, fmt.Errorf("decode nerdctl container inspect: %w", err)
+	}
+	summaries := make([]container.Summary, 0, len(inspected))
+	for _, c := range inspected {
+		if c.ContainerJSONBase == nil {
+			continue
+		}
+		summary := container.Summary{ID: c.ID, Names: []string{c.Name}}
+		if c.State != nil {
+			summary.State = c.State.Status
+		}
+		if c.Config != nil {
+			summary.Image = c.Config.Image
+			summary.Labels = c.Config.Labels
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// ContainerWait waits for the container to stop; nerdctl has no other wait conditions
+func (r *nerdctlRuntime) ContainerWait(ctx context.Context, containerID string, condition container.WaitCondition) (<-chan container.WaitResponse, <-chan error) {
+	waitCh := make(chan container.WaitResponse, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		out, err := r.run(ctx, "wait", containerID)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		code, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+		if err != nil {
+			errCh <- fmt.Errorf("parse nerdctl wait output %q: %w", out, err)
+			return
+		}
+		waitCh <- container.WaitResponse{StatusCode: code}
+	}()
+	return waitCh, errCh
+}
+
+func (r *nerdctlRuntime) ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error) {
+	args := []string{"logs"}
+	if options.Tail != "" {
+		args = append(args, "--tail", options.Tail)
+	}
+	cmd := exec.CommandContext(ctx, r.binary, append(slices.Clone(r.globalArgs), append(args, containerID)...)...)
+
+	// nerdctl writes the container's streams to its own; frame them as Docker would
+	var logs lockedBuffer
+	if options.ShowStdout {
+		cmd.Stdout = stdcopy.NewStdWriter(&logs, stdcopy.Stdout)
+	}
+	if options.ShowStderr {
+		cmd.Stderr = stdcopy.NewStdWriter(&logs, stdcopy.Stderr)
+	}
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("nerdctl logs: %w", err)
+	}
+	return io.NopCloser(&logs.buf), nil
+}
+
+// lockedBuffer is written from both of a command's output streams at once
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (r *nerdctlRuntime) NetworkCreate(ctx context.Context, name string, options network.CreateOptions) (network.CreateResponse, error) {
+	args := []string{"network", "create"}
+	if options.Driver != "" {
+		args = append(args, "--driver", options.Driver)
+	}
+	for _, key := range sortedKeys(options.Labels) {
+		args = append(args, "--label", key+"="+options.Labels[key])
+	}
+	out, err := r.run(ctx, append(args, name)...)
+	if err != nil {
+		return network.CreateResponse{}, err
+	}
+	return network.CreateResponse{ID: strings.TrimSpace(string(out))}, nil
+}
+
+func (r *nerdctlRuntime) NetworkInspect(ctx context.Context, networkID string, options network.InspectOptions) (network.Inspect, error) {
+	out, err := r.run(ctx, "network", "inspect", networkID)
+	if err != nil {
+		return network.Inspect{}, err
+	}
+	var inspected []network.Inspect
+	if err := json.Unmarshal(out, &inspected); err != nil {
+		return network.Inspect{}, fmt.Errorf("decode nerdctl network inspect: %w", err)
+	}
+	if len(inspected) == 0 {
+		return network.Inspect{}, fmt.Errorf("%w: network %s", cerrdefs.ErrNotFound, networkID)
+	}
+	return inspected[0], nil
+}
+
+func (r *nerdctlRuntime) NetworkRemove(ctx context.Context, networkID string) error {
+	_, err := r.run(ctx, "network", "rm", networkID)
+	return err
+}
+
+func (r *nerdctlRuntime) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+	return fmt.Errorf("%w: nerdctl cannot connect a network to an existing container", cerrdefs.ErrNotImplemented)
+}
+
+func (r *nerdctlRuntime) Close() error {
+	return nil
+}
+
+// nerdctlCreateArgs translates a Docker container create request into nerdctl create flags.
+// Settings nerdctl cannot express are rejected rather than dropped.
+func nerdctlCreateArgs(config *container.Config, hostConfig *container.HostConfig, platform *ocispec.Platform, name string) ([]string, error) {
+	args := []string{"create", "--name", name}
+	if platform != nil {
+		args = append(args, "--platform", platform.OS+"/"+platform.Architecture)
+	}
+	for _, env := range config.Env {
+		args = append(args, "--env", env)
+	}
+	for _, key := range sortedKeys(config.Labels) {
+		args = append(args, "--label", key+"="+config.Labels[key])
+	}
+
+	if hostConfig != nil {
+		if !hostConfig.Isolation.IsDefault() {
+			return nil, fmt.Errorf("%w: isolation is not supported with runtime %q", compute.ErrInvalidConfig, RuntimeContainerd)
+		}
+
+		ports := make([]nat.Port, 0, len(hostConfig.PortBindings))
+		for port := range hostConfig.PortBindings {
+			ports = append(ports, port)
+		}
+		slices.Sort(ports)
+		for _, port := range ports {
+			for _, binding := range hostConfig.PortBindings[port] {
+				hostPort := binding.HostPort
+				if hostPort == "0" {
+					hostPort = ""
+				}
+				publish := hostPort + ":" + string(port)
+				if binding.HostIP != "" {
+					publish = binding.HostIP + ":" + publish
+				}
+				args = append(args, "--publish", publish)
+			}
+		}
+
+		if policy := hostConfig.RestartPolicy; policy.Name != "" {
+			restart := string(policy.Name)
+			if policy.MaximumRetryCount > 0 {
+				restart += ":" + strconv.Itoa(policy.MaximumRetryCount)
+			}
+			args = append(args, "--restart", restart)
+		}
+		if hostConfig.NanoCPUs > 0 {
+			args = append(args, "--cpus", strconv.FormatFloat(float64(hostConfig.NanoCPUs)/1e9, 'f', -1, 64))
+		}
+		if hostConfig.Memory > 0 {
+			args = append(args, "--memory", strconv.FormatInt(hostConfig.Memory, 10))
+		}
+		for _, bind := range hostConfig.Binds {
+			args = append(args, "--volume", bind)
+		}
+		if mode := hostConfig.NetworkMode; mode != "" && mode != "default" {
+			args = append(args, "--network", string(mode))
+		}
+		for _, device := range hostConfig.Devices {
+			args = append(args, "--device", device.PathOnHost+":"+device.PathInContainer+":"+device.CgroupPermissions)
+		}
+		for _, request := range hostConfig.DeviceRequests {
+			gpus, err := nerdctlGPUs(request)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, "--gpus", gpus)
+		}
+		if hostConfig.PidsLimit != nil {
+			args = append(args, "--pids-limit", strconv.FormatInt(*hostConfig.PidsLimit, 10))
+		}
+		if hostConfig.BlkioWeight > 0 {
+			args = append(args, "--blkio-weight", strconv.Itoa(int(hostConfig.BlkioWeight)))
+		}
+		for _, ulimit := range hostConfig.Ulimits {
+			args = append(args, "--ulimit", fmt.Sprintf("%s=%d:%d", ulimit.Name, ulimit.Soft, ulimit.Hard))
+		}
+	}
+
+	args = append(args, config.Image)
+	return append(args, config.Cmd...), nil
+}
+
+// nerdctlGPUs formats a GPU request as the CSV --gpus takes
+func nerdctlGPUs(request container.DeviceRequest) (string, error) {
+	fields := []string{"driver=" + request.Driver}
+	for _, capabilities := range request.Capabilities {
+		fields = append(fields, "capabilities="+strings.Join(capabilities, ","))
+	}
+	switch {
+	case len(request.DeviceIDs) > 0:
+		fields = append(fields, "device="+strings.Join(request.DeviceIDs, ","))
+	case request.Count < 0:
+		fields = append(fields, "count=all")
+	default:
+		fields = append(fields, "count="+strconv.Itoa(request.Count))
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(fields); err != nil {
+		return "", fmt.Errorf("format gpus: %w", err)
+	}
+	w.Flush()
+	return strings.TrimSpace(buf.String()), w.Error()
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
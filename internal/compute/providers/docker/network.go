@@ -0,0 +1,84 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/api/types/network"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+const (
+	// NetworkIsolationShared puts every tenant container on the configured network
+	NetworkIsolationShared = "shared"
+
+	// NetworkIsolationTenant gives every tenant a dedicated network, so tenants cannot reach each other
+	NetworkIsolationTenant = "tenant"
+)
+
+// validateNetworkIsolation checks the isolation mode and its ingress network
+func validateNetworkIsolation(cfg *Config) error {
+	switch cfg.NetworkIsolation {
+	case NetworkIsolationShared:
+		if cfg.IngressNetwork != "" {
+			return fmt.Errorf("ingress_network requires network_isolation %q", NetworkIsolationTenant)
+		}
+	case NetworkIsolationTenant:
+	default:
+		return fmt.Errorf("invalid network_isolation %q, must be %q or %q", cfg.NetworkIsolation, NetworkIsolationShared, NetworkIsolationTenant)
+	}
+	return nil
+}
+
+// tenantNetworkName returns the name of a tenant's dedicated network
+func tenantNetworkName(tenantID string) string {
+	return fmt.Sprintf("%s-tenant-%s-net", defaultLabelPrefix, tenantID)
+}
+
+// isolated reports whether tenants get dedicated networks
+func (p *Provider) isolated() bool {
+	return p.networkIsolation == NetworkIsolationTenant
+}
+
+// ensureTenantNetwork creates the tenant's network if it does not exist and returns its ID
+func (p *Provider) ensureTenantNetwork(ctx context.Context, spec *compute.TenantComputeSpec) (string, error) {
+	name := tenantNetworkName(spec.TenantID)
+
+	existing, err := p.client.NetworkInspect(ctx, name, network.InspectOptions{})
+	if err == nil {
+		return existing.ID, nil
+	}
+	if !cerrdefs.IsNotFound(err) {
+		return "", fmt.Errorf("failed to inspect tenant network: %w", classifyDockerError(err))
+	}
+
+	resp, err := p.client.NetworkCreate(ctx, name, network.CreateOptions{
+		Driver: p.networkDriver,
+		Labels: compute.MergeLabels(spec.Labels, compute.DefaultMetadata(spec)),
+	})
+	if err != nil {
+		p.logger.Error("failed to create tenant network", zap.String("tenant_id", spec.TenantID), zap.Error(err))
+		return "", fmt.Errorf("failed to create tenant network: %w", classifyDockerError(err))
+	}
+
+	p.logger.Info("tenant network created", zap.String("tenant_id", spec.TenantID), zap.String("network", name), zap.String("network_id", resp.ID))
+	return resp.ID, nil
+}
+
+// removeTenantNetwork removes the tenant's network; a missing network is not an error
+func (p *Provider) removeTenantNetwork(ctx context.Context, tenantID string) error {
+	name := tenantNetworkName(tenantID)
+	if err := p.client.NetworkRemove(ctx, name); err != nil {
+		if cerrdefs.IsNotFound(err) {
+			return nil
+		}
+		p.logger.Error("failed to remove tenant network", zap.String("tenant_id", tenantID), zap.Error(err))
+		return fmt.Errorf("failed to remove tenant network: %w", classifyDockerError(err))
+	}
+
+	p.logger.Info("tenant network removed", zap.String("tenant_id", tenantID), zap.String("network", name))
+	return nil
+}
@@ -0,0 +1,112 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/pkg/jsonmessage"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// SupportedPlatforms are the platforms tenants can request
+var SupportedPlatforms = []string{"linux/amd64", "linux/arm64"}
+
+// daemonArchitectures maps the architecture names the daemon reports to OCI architectures
+var daemonArchitectures = map[string]string{
+	"x86_64":  "amd64",
+	"amd64":   "amd64",
+	"aarch64": "arm64",
+	"arm64":   "arm64",
+}
+
+// parsePlatform converts a supported platform string to an OCI platform
+func parsePlatform(platform string) (*ocispec.Platform, error) {
+	if !slices.Contains(SupportedPlatforms, platform) {
+		return nil, fmt.Errorf("invalid platform '%s', must be one of: %s", platform, strings.Join(SupportedPlatforms, ", "))
+	}
+	osName, arch, _ := strings.Cut(platform, "/")
+	return &ocispec.Platform{OS: osName, Architecture: arch}, nil
+}
+
+// validateEmulatedPlatforms checks the configured emulated platforms are ones tenants can request
+func validateEmulatedPlatforms(cfg *Config) error {
+	for _, platform := range cfg.EmulatedPlatforms {
+		if _, err := parsePlatform(platform); err != nil {
+			return fmt.Errorf("emulated_platforms: %w", err)
+		}
+	}
+	return nil
+}
+
+// daemonPlatform returns the daemon's native platform, or "" when its architecture is not one tenants can request
+func daemonPlatform(osType, architecture string) string {
+	arch, ok := daemonArchitectures[architecture]
+	if !ok {
+		return ""
+	}
+	return osType + "/" + arch
+}
+
+// checkPlatform reports whether the daemon can run platform.
+// When the daemon's platform is unknown every supported platform is allowed and the daemon has the final say.
+func (p *Provider) checkPlatform(platform string) error {
+	if platform == "" {
+		return nil
+	}
+	if _, err := parsePlatform(platform); err != nil {
+		return compute.Mark(err, compute.ErrInvalidConfig)
+	}
+	if len(p.platforms) == 0 || slices.Contains(p.platforms, platform) {
+		return nil
+	}
+	return fmt.Errorf("%w: platform %s is not available on this docker host, which can run %s",
+		compute.ErrInvalidConfig, platform, strings.Join(p.platforms, ", "))
+}
+
+// pullImage pulls the platform's variant of an image so the container is created from it rather than
+// from whichever variant is already cached
+func (p *Provider) pullImage(ctx context.Context, ref, platform string) error {
+	stream, err := p.client.ImagePull(ctx, ref, image.PullOptions{Platform: platform})
+	if err == nil {
+		defer stream.Close()
+		// Pull failures such as a missing manifest arrive in the progress stream, not the response.
+		// Each read of progress is a heartbeat, so a large pull is not mistaken for a stall.
+		progress := &heartbeatReader{ctx: ctx, reader: stream, detail: "pulling image " + ref}
+		err = jsonmessage.DisplayJSONMessagesStream(progress, io.Discard, 0, false, nil)
+	}
+	if err != nil {
+		p.logger.Error("failed to pull image", zap.String("image", ref), zap.String("platform", platform), zap.Error(err))
+		// Docker and Podman report "no matching manifest", nerdctl "no match for platform"
+		if strings.Contains(err.Error(), "no matching manifest") || strings.Contains(err.Error(), "no match for platform") {
+			return fmt.Errorf("%w: image %s has no %s variant", compute.ErrInvalidConfig, ref, platform)
+		}
+		return fmt.Errorf("failed to pull image %s for %s: %w", ref, platform, classifyDockerError(err))
+	}
+	return nil
+}
+
+// heartbeatReader reports a compute heartbeat whenever a progress stream delivers data, and stops
+// reading once the operation is cancelled so buffered progress does not delay the abort
+type heartbeatReader struct {
+	ctx    context.Context
+	reader io.Reader
+	detail string
+}
+
+func (r *heartbeatReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		compute.Heartbeat(r.ctx, r.detail)
+	}
+	return n, err
+}
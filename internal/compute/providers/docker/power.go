@@ -0,0 +1,68 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/container"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// powerStopTimeout is how long, in seconds, a container is given to exit before it is killed
+const powerStopTimeout = 10
+
+var _ compute.PowerManager = (*Provider)(nil)
+
+// Restart restarts a tenant's container in place
+func (p *Provider) Restart(ctx context.Context, tenantID string) error {
+	containerID, err := p.tenantContainer(tenantID)
+	if err != nil {
+		return err
+	}
+	timeout := powerStopTimeout
+	if err := p.client.ContainerRestart(ctx, containerID, container.StopOptions{Timeout: &timeout}); err != nil {
+		return fmt.Errorf("failed to restart container: %w", classifyDockerError(err))
+	}
+	p.logger.Info("container restarted", zap.String("tenant_id", tenantID), zap.String("container_id", containerID))
+	return nil
+}
+
+// Suspend stops a tenant's container without removing it
+func (p *Provider) Suspend(ctx context.Context, tenantID string) error {
+	containerID, err := p.tenantContainer(tenantID)
+	if err != nil {
+		return err
+	}
+	timeout := powerStopTimeout
+	if err := p.client.ContainerStop(ctx, containerID, container.StopOptions{Timeout: &timeout}); err != nil {
+		return fmt.Errorf("failed to stop container: %w", classifyDockerError(err))
+	}
+	p.logger.Info("container suspended", zap.String("tenant_id", tenantID), zap.String("container_id", containerID))
+	return nil
+}
+
+// Resume starts a tenant's stopped container; starting a running container is a no-op
+func (p *Provider) Resume(ctx context.Context, tenantID string) error {
+	containerID, err := p.tenantContainer(tenantID)
+	if err != nil {
+		return err
+	}
+	if err := p.client.ContainerStart(ctx, containerID, container.StartOptions{}); err != nil {
+		return fmt.Errorf("failed to start container: %w", classifyDockerError(err))
+	}
+	p.logger.Info("container resumed", zap.String("tenant_id", tenantID), zap.String("container_id", containerID))
+	return nil
+}
+
+func (p *Provider) tenantContainer(tenantID string) (string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	containerID, exists := p.tenantContainers[tenantID]
+	if !exists {
+		return "", fmt.Errorf("%w: %s", compute.ErrTenantNotFound, tenantID)
+	}
+	return containerID, nil
+}
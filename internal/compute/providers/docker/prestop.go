@@ -0,0 +1,207 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types/container"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// defaultTerminationGracePeriod is how long a container is given to exit when it is destroyed,
+// unless its compute_config sets termination_grace_period
+const defaultTerminationGracePeriod = 10 * time.Second
+
+// maxTerminationGracePeriod bounds termination_grace_period, so an archival cannot wait forever
+const maxTerminationGracePeriod = time.Hour
+
+// drainHeartbeatInterval is how often a container that is draining is reported as making progress
+const drainHeartbeatInterval = 10 * time.Second
+
+// The pre-stop hook and grace period are recorded on the container, so a container is stopped
+// the way it was provisioned even by a process that did not provision it
+const (
+	preStopLabel     = defaultLabelPrefix + ".pre_stop"
+	gracePeriodLabel = defaultLabelPrefix + ".termination_grace_period"
+)
+
+// PreStopConfig notifies a tenant's workload that it is about to be stopped, so it can stop taking
+// new work and drain its connections. Exactly one of HTTP and Signal is set.
+type PreStopConfig struct {
+	// HTTP is a request sent to the container
+	HTTP *PreStopHTTPConfig `json:"http,omitempty"`
+
+	// Signal is sent to the container's main process, such as "SIGTERM" or "SIGUSR1"
+	Signal string `json:"signal,omitempty"`
+}
+
+// PreStopHTTPConfig is a POST to the container's address on Port
+type PreStopHTTPConfig struct {
+	Path string `json:"path,omitempty"` // default /
+	Port int    `json:"port"`
+}
+
+// validatePreStopConfig returns the problems with compute_config's pre_stop and termination_grace_period
+func validatePreStopConfig(cfg *DockerComputeConfig) []string {
+	var errors []string
+	if cfg.TerminationGracePeriod != "" {
+		if grace, err := time.ParseDuration(cfg.TerminationGracePeriod); err != nil || grace < 0 || grace > maxTerminationGracePeriod {
+			errors = append(errors, fmt.Sprintf("termination_grace_period: must be a duration between 0s and %s, got '%s'", maxTerminationGracePeriod, cfg.TerminationGracePeriod))
+		}
+	}
+	hook := cfg.PreStop
+	if hook == nil {
+		return errors
+	}
+	if (hook.HTTP == nil) == (hook.Signal == "") {
+		return append(errors, "pre_stop: set exactly one of http and signal")
+	}
+	if hook.HTTP != nil {
+		if hook.HTTP.Port < 1 || hook.HTTP.Port > 65535 {
+			errors = append(errors, fmt.Sprintf("pre_stop.http.port: must be between 1 and 65535, got %d", hook.HTTP.Port))
+		}
+		if hook.HTTP.Path != "" && !strings.HasPrefix(hook.HTTP.Path, "/") {
+			errors = append(errors, fmt.Sprintf("pre_stop.http.path: must start with '/', got '%s'", hook.HTTP.Path))
+		}
+	}
+	if hook.Signal != "" && !strings.HasPrefix(hook.Signal, "SIG") {
+		errors = append(errors, fmt.Sprintf("pre_stop.signal: must be a signal name such as SIGTERM, got '%s'", hook.Signal))
+	}
+	return errors
+}
+
+// gracefulStopLabels records the pre-stop hook and grace period of parsedConfig on the container
+func gracefulStopLabels(parsedConfig *DockerComputeConfig) map[string]string {
+	labels := map[string]string{}
+	if parsedConfig == nil {
+		return labels
+	}
+	if parsedConfig.PreStop != nil {
+		if raw, err := json.Marshal(parsedConfig.PreStop); err == nil {
+			labels[preStopLabel] = string(raw)
+		}
+	}
+	if parsedConfig.TerminationGracePeriod != "" {
+		labels[gracePeriodLabel] = parsedConfig.TerminationGracePeriod
+	}
+	return labels
+}
+
+// gracefulStopFromLabels reads the pre-stop hook and grace period recorded on a container
+func gracefulStopFromLabels(labels map[string]string) (*PreStopConfig, time.Duration) {
+	grace := defaultTerminationGracePeriod
+	if value, ok := labels[gracePeriodLabel]; ok {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			grace = parsed
+		}
+	}
+	var hook *PreStopConfig
+	if value, ok := labels[preStopLabel]; ok {
+		var parsed PreStopConfig
+		if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+			hook = &parsed
+		}
+	}
+	return hook, grace
+}
+
+// stopGracefully stops a container that is being destroyed. A container with a pre-stop hook is
+// notified and given until the end of its grace period to exit by itself; whatever time is left
+// is then the stop timeout, after which it is killed. Failures are logged, since the container is
+// removed regardless.
+func (p *Provider) stopGracefully(ctx context.Context, tenantID, containerID string) {
+	log := p.logger.With(zap.String("tenant_id", tenantID), zap.String("container_id", containerID))
+
+	var hook *PreStopConfig
+	grace := defaultTerminationGracePeriod
+	inspect, err := p.client.ContainerInspect(ctx, containerID)
+	if err != nil {
+		log.Warn("failed to inspect container before stopping it", zap.Error(err))
+	} else if inspect.Config != nil {
+		hook, grace = gracefulStopFromLabels(inspect.Config.Labels)
+	}
+	deadline := time.Now().Add(grace)
+
+	running := err == nil && inspect.State != nil && inspect.State.Running
+	if hook != nil && running {
+		compute.ReportPhase(ctx, compute.PhasePreStop)
+		hookCtx, cancel := context.WithDeadline(ctx, deadline)
+		if err := p.runPreStopHook(hookCtx, containerID, &inspect, hook); err != nil {
+			log.Warn("pre-stop hook failed", zap.Error(err))
+		} else {
+			log.Info("pre-stop hook ran")
+		}
+		cancel()
+
+		compute.ReportPhase(ctx, compute.PhaseDraining)
+		p.waitForExit(ctx, containerID, deadline)
+	}
+
+	compute.ReportPhase(ctx, compute.PhaseStopping)
+	timeout := int(max(time.Until(deadline), 0).Round(time.Second) / time.Second)
+	stopCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second+10*time.Second)
+	defer cancel()
+	if err := p.client.ContainerStop(stopCtx, containerID, container.StopOptions{Timeout: &timeout}); err != nil {
+		log.Warn("failed to stop container", zap.Error(err))
+	}
+}
+
+// runPreStopHook notifies the container it is about to be stopped
+func (p *Provider) runPreStopHook(ctx context.Context, containerID string, inspect *container.InspectResponse, hook *PreStopConfig) error {
+	if hook.Signal != "" {
+		if err := p.client.ContainerKill(ctx, containerID, hook.Signal); err != nil {
+			return fmt.Errorf("send %s: %w", hook.Signal, classifyDockerError(err))
+		}
+		return nil
+	}
+
+	endpoints := buildEndpoints(&compute.ContainerSpec{Ports: []compute.PortMapping{{ContainerPort: hook.HTTP.Port}}}, inspect, p.ingressNetwork)
+	path := hook.HTTP.Path
+	if path == "" {
+		path = "/"
+	}
+	url := "http://" + net.JoinHostPort(endpoints[0].Address, strconv.Itoa(hook.HTTP.Port)) + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return fmt.Errorf("build pre-stop request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("POST %s: %w", url, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("POST %s: status %d", url, resp.StatusCode)
+	}
+	return nil
+}
+
+// waitForExit waits until the container exits or the deadline passes, heartbeating while it drains
+func (p *Provider) waitForExit(ctx context.Context, containerID string, deadline time.Time) {
+	waitCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	waitCh, errCh := p.client.ContainerWait(waitCtx, containerID, container.WaitConditionNotRunning)
+
+	ticker := time.NewTicker(drainHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-waitCh:
+			return
+		case <-errCh:
+			return
+		case <-waitCtx.Done():
+			return
+		case <-ticker.C:
+			compute.Heartbeat(ctx, fmt.Sprintf("draining: waiting up to %s for the container to exit", time.Until(deadline).Round(time.Second)))
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/system"
+	"github.com/docker/docker/client"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// RuntimeDocker talks to a Docker daemon; the default
+	RuntimeDocker = "docker"
+
+	// RuntimePodman talks to Podman's Docker-compatible API socket
+	RuntimePodman = "podman"
+
+	// RuntimeContainerd drives containerd through the nerdctl CLI
+	RuntimeContainerd = "containerd"
+)
+
+// Runtime is the container engine API the provider uses. It is the subset of the Docker
+// client the provider needs, so a *client.Client satisfies it directly and other engines
+// adapt to Docker's types.
+type Runtime interface {
+	Ping(ctx context.Context) (types.Ping, error)
+	Info(ctx context.Context) (system.Info, error)
+	ImagePull(ctx context.Context, ref string, options image.PullOptions) (io.ReadCloser, error)
+
+	ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (container.CreateResponse, error)
+	ContainerStart(ctx context.Context, containerID string, options container.StartOptions) error
+	ContainerStop(ctx context.Context, containerID string, options container.StopOptions) error
+	ContainerRestart(ctx context.Context, containerID string, options container.StopOptions) error
+	ContainerKill(ctx context.Context, containerID, signal string) error
+	ContainerRemove(ctx context.Context, containerID string, options container.RemoveOptions) error
+	ContainerInspect(ctx context.Context, containerID string) (container.InspectResponse, error)
+	ContainerList(ctx context.Context, options container.ListOptions) ([]container.Summary, error)
+	ContainerWait(ctx context.Context, containerID string, condition container.WaitCondition) (<-chan container.WaitResponse, <-chan error)
+
+	// ContainerLogs returns stdout and stderr multiplexed as Docker does for containers without a TTY
+	ContainerLogs(ctx context.Context, containerID string, options container.LogsOptions) (io.ReadCloser, error)
+
+	NetworkCreate(ctx context.Context, name string, options network.CreateOptions) (network.CreateResponse, error)
+	NetworkInspect(ctx context.Context, networkID string, options network.InspectOptions) (network.Inspect, error)
+	NetworkRemove(ctx context.Context, networkID string) error
+	NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error
+
+	Close() error
+}
+
+var _ Runtime = (*client.Client)(nil)
+
+// validateRuntime checks the runtime and the settings it does not support
+func validateRuntime(cfg *Config) error {
+	switch cfg.Runtime {
+	case RuntimeDocker, RuntimePodman:
+		if cfg.Namespace != "" {
+			return fmt.Errorf("namespace requires runtime %q", RuntimeContainerd)
+		}
+	case RuntimeContainerd:
+		// nerdctl cannot attach a network to an existing container
+		if cfg.IngressNetwork != "" {
+			return fmt.Errorf("ingress_network is not supported with runtime %q", RuntimeContainerd)
+		}
+	default:
+		return fmt.Errorf("invalid runtime %q, must be %q, %q or %q", cfg.Runtime, RuntimeDocker, RuntimePodman, RuntimeContainerd)
+	}
+	return nil
+}
+
+// newRuntime connects to the configured container engine
+func newRuntime(cfg *Config) (Runtime, error) {
+	switch cfg.Runtime {
+	case RuntimeContainerd:
+		return newNerdctlRuntime(cfg.Host, cfg.Namespace)
+	case RuntimePodman:
+		host := cfg.Host
+		if host == "" {
+			host = defaultPodmanHost()
+		}
+		// Podman's compat API reports an older API version than the client defaults to
+		cli, err := client.NewClientWithOpts(client.WithHost(host), client.WithAPIVersionNegotiation())
+		if err != nil {
+			return nil, err
+		}
+		return &podmanRuntime{Client: cli}, nil
+	default:
+		// If Host is empty, client.NewClientWithOpts will use the standard Docker socket
+		opts := []client.Opt{}
+		if cfg.Host != "" {
+			opts = append(opts, client.WithHost(cfg.Host))
+		}
+		return client.NewClientWithOpts(opts...)
+	}
+}
+
+// defaultPodmanHost returns the rootless Podman socket when there is one, otherwise the rootful socket
+func defaultPodmanHost() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		socket := filepath.Join(dir, "podman", "podman.sock")
+		if _, err := os.Stat(socket); err == nil {
+			return "unix://" + socket
+		}
+	}
+	return "unix:///run/podman/podman.sock"
+}
+
+// podmanRuntime is the Docker client pointed at Podman's compat socket, with Podman's quirks handled
+type podmanRuntime struct {
+	*client.Client
+}
+
+// qualifyImage prefixes short image names with docker.io, as the Docker daemon does implicitly.
+// Podman resolves short names through its registries.conf, which fails without a TTY when
+// short-name enforcement is on.
+func qualifyImage(ref string) string {
+	first, _, hasPath := strings.Cut(ref, "/")
+	if !hasPath {
+		return "docker.io/library/" + ref
+	}
+	// A first component that looks like a host is a registry
+	if strings.ContainsAny(first, ".:") || first == "localhost" {
+		return ref
+	}
+	return "docker.io/" + ref
+}
+
+func (r *podmanRuntime) ImagePull(ctx context.Context, ref string, options image.PullOptions) (io.ReadCloser, error) {
+	return r.Client.ImagePull(ctx, qualifyImage(ref), options)
+}
+
+func (r *podmanRuntime) ContainerCreate(ctx context.Context, config *container.Config, hostConfig *container.HostConfig, networkingConfig *network.NetworkingConfig, platform *ocispec.Platform, containerName string) (container.CreateResponse, error) {
+	qualified := *config
+	qualified.Image = qualifyImage(config.Image)
+	return r.Client.ContainerCreate(ctx, &qualified, hostConfig, networkingConfig, platform, containerName)
+}
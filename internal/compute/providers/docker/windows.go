@@ -0,0 +1,104 @@
+package docker
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+const (
+	// OSLinux runs Linux containers; the default
+	OSLinux = "linux"
+
+	// OSWindows runs Windows containers, which need a Windows Docker host
+	OSWindows = "windows"
+
+	// IsolationProcess shares the host kernel; the container's Windows version must match the host's
+	IsolationProcess = "process"
+
+	// IsolationHyperV runs each container in a lightweight VM
+	IsolationHyperV = "hyperv"
+)
+
+// windowsMaxRestarts caps restarts of a failing Windows container; restarting a Hyper-V
+// container boots a new utility VM, so a crash loop is far more expensive than on Linux
+const windowsMaxRestarts = 5
+
+// windowsNetworkModes are the network modes a Windows daemon accepts, besides container:<name>
+var windowsNetworkModes = []string{"default", "nat", "transparent", "l2bridge", "none"}
+
+// windowsVolumePattern matches "host_path:container_path[:ro|rw]" where both paths are drive-letter paths
+var windowsVolumePattern = regexp.MustCompile(`^([A-Za-z]:[\\/][^:]*):([A-Za-z]:[\\/][^:]*)(?::(ro|rw))?$`)
+
+// containerOS returns the OS a tenant's containers run, defaulting to Linux
+func containerOS(cfg *DockerComputeConfig) string {
+	if cfg == nil || cfg.OS == "" {
+		return OSLinux
+	}
+	return cfg.OS
+}
+
+// validateOSConfig checks the os and isolation settings and the Windows-specific forms of
+// volumes and network modes; Linux volumes and network modes are checked by validateConfig
+func validateOSConfig(cfg *DockerComputeConfig) []string {
+	var errors []string
+	switch cfg.OS {
+	case "", OSLinux:
+		if cfg.Isolation != "" {
+			errors = append(errors, fmt.Sprintf("isolation: '%s' requires os 'windows'", cfg.Isolation))
+		}
+		return errors
+	case OSWindows:
+	default:
+		return append(errors, fmt.Sprintf("os: invalid value '%s', must be one of: linux, windows", cfg.OS))
+	}
+
+	switch cfg.Isolation {
+	case "", IsolationProcess, IsolationHyperV:
+	default:
+		errors = append(errors, fmt.Sprintf("isolation: invalid value '%s', must be one of: process, hyperv", cfg.Isolation))
+	}
+	if cfg.Platform != "" {
+		errors = append(errors, "platform: only applies to linux containers")
+	}
+	if len(cfg.Devices) > 0 || cfg.GPUs != nil {
+		errors = append(errors, "devices and gpus are not supported for windows containers")
+	}
+	for i, vol := range cfg.Volumes {
+		if !windowsVolumePattern.MatchString(vol) {
+			errors = append(errors, fmt.Sprintf("volumes[%d]: invalid format '%s', expected 'C:\\host_path:C:\\container_path' with an optional ':ro' or ':rw'", i, vol))
+		}
+	}
+	if cfg.NetworkMode != "" && !strings.HasPrefix(cfg.NetworkMode, "container:") && !slices.Contains(windowsNetworkModes, cfg.NetworkMode) {
+		errors = append(errors, fmt.Sprintf("network_mode: invalid value '%s', must be one of: %s, or container:<name>", cfg.NetworkMode, strings.Join(windowsNetworkModes, ", ")))
+	}
+	return errors
+}
+
+// checkOS reports whether the daemon runs the tenant's container OS; an unknown daemon OS leaves it to the daemon
+func (p *Provider) checkOS(cfg *DockerComputeConfig) error {
+	want := containerOS(cfg)
+	if p.hostOS == "" || p.hostOS == want {
+		return nil
+	}
+	return fmt.Errorf("%w: %s containers need a %s docker host, this host runs %s", compute.ErrInvalidConfig, want, want, p.hostOS)
+}
+
+// applyWindowsHostConfig sets the isolation mode and Windows restart defaults. An explicit
+// restart_policy is applied afterwards and wins.
+func applyWindowsHostConfig(hostConfig *container.HostConfig, cfg *DockerComputeConfig) {
+	if cfg.Isolation != "" {
+		hostConfig.Isolation = container.Isolation(cfg.Isolation)
+	}
+	if hostConfig.RestartPolicy.Name != container.RestartPolicyDisabled {
+		hostConfig.RestartPolicy = container.RestartPolicy{
+			Name:              container.RestartPolicyOnFailure,
+			MaximumRetryCount: windowsMaxRestarts,
+		}
+	}
+}
@@ -94,3 +94,26 @@ func TestConfigSchemaIncludesRequiredFields(t *testing.T) {
 		t.Fatalf("expected anyOf to require service_name or service_name_prefix")
 	}
 }
+
+func TestReconfigureReplacesDefaults(t *testing.T) {
+	p := New(nil, nil)
+
+	if err := p.Reconfigure(map[string]interface{}{"cluster_arn": "arn"}); err == nil {
+		t.Fatalf("expected incomplete defaults to be rejected")
+	}
+	if p.ConfigDefaults() != nil {
+		t.Fatalf("expected rejected defaults to leave the old defaults in place")
+	}
+
+	defaults := map[string]interface{}{
+		"cluster_arn":         "arn:aws:ecs:us-west-2:123456789012:cluster/example",
+		"task_definition_arn": "arn:aws:ecs:us-west-2:123456789012:task-definition/example:1",
+		"service_name_prefix": "landlord-tenant-",
+	}
+	if err := p.Reconfigure(defaults); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	if err := p.ValidateConfig(nil); err != nil {
+		t.Fatalf("expected empty config to validate against new defaults: %v", err)
+	}
+}
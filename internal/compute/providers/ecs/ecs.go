@@ -23,6 +23,7 @@ type Provider struct {
 	logger           *zap.Logger
 	loadAWSConfig    func(ctx context.Context, opts awsconfig.Options) (aws.Config, error)
 	tenantConfigs    map[string]*ComputeConfig
+	defaultsMu       sync.RWMutex
 	defaultConfig    map[string]interface{}
 	defaultConfigRaw json.RawMessage
 }
@@ -52,7 +53,7 @@ func (p *Provider) Provision(ctx context.Context, spec *compute.TenantComputeSpe
 		return nil, err
 	}
 
-	cfg, err := parseComputeConfig(spec.ProviderConfig, p.defaultConfig)
+	cfg, err := parseComputeConfig(spec.ProviderConfig, p.defaults())
 	if err != nil {
 		return nil, err
 	}
@@ -106,7 +107,7 @@ func (p *Provider) Update(ctx context.Context, tenantID string, spec *compute.Te
 		return nil, err
 	}
 
-	cfg, err := parseComputeConfig(spec.ProviderConfig, p.defaultConfig)
+	cfg, err := parseComputeConfig(spec.ProviderConfig, p.defaults())
 	if err != nil {
 		return nil, err
 	}
@@ -190,7 +191,7 @@ func (p *Provider) Destroy(ctx context.Context, tenantID string) error {
 		Force:   aws.Bool(true),
 	})
 	if err != nil {
-		return err
+		return classifyAPIError(err)
 	}
 
 	p.deleteConfig(tenantID)
@@ -261,14 +262,32 @@ func (p *Provider) Validate(ctx context.Context, spec *compute.TenantComputeSpec
 	if spec.ProviderType != "" && spec.ProviderType != p.Name() {
 		return fmt.Errorf("provider_type must be %s", p.Name())
 	}
-	_, err := parseComputeConfig(spec.ProviderConfig, p.defaultConfig)
-	return err
+	_, err := parseComputeConfig(spec.ProviderConfig, p.defaults())
+	return compute.Mark(err, compute.ErrInvalidConfig)
 }
 
 // ValidateConfig validates provider-specific configuration.
 func (p *Provider) ValidateConfig(config json.RawMessage) error {
-	_, err := parseComputeConfig(config, p.defaultConfig)
-	return err
+	_, err := parseComputeConfig(config, p.defaults())
+	return compute.Mark(err, compute.ErrInvalidConfig)
+}
+
+// Reconfigure replaces the default compute_config merged into every tenant's config.
+func (p *Provider) Reconfigure(defaults map[string]interface{}) error {
+	if _, err := parseComputeConfig(nil, defaults); err != nil {
+		return compute.Mark(err, compute.ErrInvalidConfig)
+	}
+	p.defaultsMu.Lock()
+	defer p.defaultsMu.Unlock()
+	p.defaultConfig = copyConfigMap(defaults)
+	p.defaultConfigRaw = marshalConfigMap(defaults)
+	return nil
+}
+
+func (p *Provider) defaults() map[string]interface{} {
+	p.defaultsMu.RLock()
+	defer p.defaultsMu.RUnlock()
+	return p.defaultConfig
 }
 
 // ConfigSchema returns the JSON Schema for ECS compute_config.
@@ -278,6 +297,8 @@ func (p *Provider) ConfigSchema() json.RawMessage {
 
 // ConfigDefaults returns no defaults for ECS compute_config.
 func (p *Provider) ConfigDefaults() json.RawMessage {
+	p.defaultsMu.RLock()
+	defer p.defaultsMu.RUnlock()
 	return p.defaultConfigRaw
 }
 
@@ -287,7 +308,7 @@ func (p *Provider) describeService(ctx context.Context, client *ecs.Client, clus
 		Services: []string{serviceName},
 	})
 	if err != nil {
-		return nil, err
+		return nil, classifyAPIError(err)
 	}
 	if len(resp.Services) > 0 {
 		return &resp.Services[0], nil
@@ -323,7 +344,7 @@ func (p *Provider) createService(ctx context.Context, client *ecs.Client, cfg *C
 	}
 
 	_, err := client.CreateService(ctx, input)
-	return err
+	return classifyAPIError(err)
 }
 
 func (p *Provider) updateService(ctx context.Context, client *ecs.Client, cfg *ComputeConfig, serviceName string, service *ecstypes.Service) (compute.UpdateStatus, error) {
@@ -353,7 +374,7 @@ func (p *Provider) updateService(ctx context.Context, client *ecs.Client, cfg *C
 
 	_, err := client.UpdateService(ctx, input)
 	if err != nil {
-		return compute.UpdateStatusFailed, err
+		return compute.UpdateStatusFailed, classifyAPIError(err)
 	}
 
 	return compute.UpdateStatusSuccess, nil
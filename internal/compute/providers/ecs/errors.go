@@ -0,0 +1,31 @@
+package ecs
+
+import (
+	"errors"
+
+	"github.com/aws/smithy-go"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// classifyAPIError tags ECS API errors with compute sentinels so callers can classify them without parsing messages
+func classifyAPIError(err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch apiErr.ErrorCode() {
+	case "ThrottlingException", "RequestLimitExceeded":
+		return compute.Retriable(err)
+	case "LimitExceededException":
+		return compute.Mark(err, compute.ErrQuotaExceeded)
+	case "InvalidParameterException", "ClientException", "PlatformUnknownException", "PlatformTaskDefinitionIncompatibilityException":
+		return compute.Mark(err, compute.ErrInvalidConfig)
+	case "ClusterNotFoundException", "ServiceNotFoundException":
+		return compute.Mark(err, compute.ErrTenantNotFound)
+	case "ServerException", "ServiceUnavailableException":
+		return compute.Mark(err, compute.ErrProviderUnavailable)
+	}
+	return err
+}
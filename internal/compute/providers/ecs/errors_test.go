@@ -0,0 +1,44 @@
+package ecs
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/smithy-go"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+func TestClassifyAPIError(t *testing.T) {
+	tests := []struct {
+		code     string
+		sentinel error
+	}{
+		{"ThrottlingException", compute.ErrRetriable},
+		{"LimitExceededException", compute.ErrQuotaExceeded},
+		{"InvalidParameterException", compute.ErrInvalidConfig},
+		{"ServiceNotFoundException", compute.ErrTenantNotFound},
+		{"ServerException", compute.ErrProviderUnavailable},
+	}
+
+	for _, tt := range tests {
+		err := classifyAPIError(fmt.Errorf("create service: %w", &smithy.GenericAPIError{Code: tt.code, Message: "boom"}))
+		if !errors.Is(err, tt.sentinel) {
+			t.Errorf("%s: expected error to match %v, got %v", tt.code, tt.sentinel, err)
+		}
+	}
+
+	plain := errors.New("boom")
+	if err := classifyAPIError(plain); err != plain {
+		t.Errorf("expected non-API error to pass through, got %v", err)
+	}
+}
+
+func TestValidateConfigReportsInvalidConfig(t *testing.T) {
+	p := New(nil, nil)
+
+	if err := p.ValidateConfig([]byte(`{"cluster_arn":"arn"}`)); !errors.Is(err, compute.ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+package firecracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+const (
+	// defaultBootArgs send the kernel console to the serial port, which is captured to the console log
+	defaultBootArgs = "console=ttyS0 reboot=k panic=1 pci=off"
+
+	// defaultMemoryMiB is used when a tenant sets no memory requirement
+	defaultMemoryMiB = 128
+
+	// maxVCPUs is the most vCPUs Firecracker gives a microVM
+	maxVCPUs = 32
+)
+
+// ComputeConfig represents the microVM settings stored in a tenant's compute_config
+type ComputeConfig struct {
+	// KernelImagePath is the uncompressed guest kernel (vmlinux) on the worker host; firecracker mode only
+	KernelImagePath string `json:"kernel_image_path,omitempty"`
+
+	// RootfsPath is the root filesystem image on the worker host; firecracker mode only.
+	// It is copied per tenant unless ReadOnlyRootfs is set.
+	RootfsPath string `json:"rootfs_path,omitempty"`
+
+	// ReadOnlyRootfs attaches the root filesystem read-only and shares it between tenants
+	ReadOnlyRootfs bool `json:"read_only_rootfs,omitempty"`
+
+	// BootArgs is the kernel command line; defaults to a serial console setup
+	BootArgs string `json:"boot_args,omitempty"`
+
+	// Resources sizes the VM when the tenant spec carries no resource requirements
+	Resources *compute.ResourceRequirements `json:"resources,omitempty"`
+
+	// Ports are the tenant's service ports, reported as endpoints; in kata mode they are
+	// published on the host
+	Ports []compute.PortMapping `json:"ports,omitempty"`
+
+	// Network attaches the VM to a pre-created tap device; firecracker mode only
+	Network *NetworkConfig `json:"network,omitempty"`
+
+	// Image is the container image the Kata VM runs; kata mode only
+	Image string `json:"image,omitempty"`
+
+	// Env is environment variables for the Kata container; kata mode only
+	Env map[string]string `json:"env,omitempty"`
+}
+
+// NetworkConfig connects a microVM to the host
+type NetworkConfig struct {
+	// TapDevice is an existing tap device on the worker host
+	TapDevice string `json:"tap_device"`
+
+	// GuestMAC is the guest interface's MAC address; Firecracker picks one when empty
+	GuestMAC string `json:"guest_mac,omitempty"`
+
+	// GuestIP is the address the guest configures; tenant endpoints use it
+	GuestIP string `json:"guest_ip,omitempty"`
+}
+
+var firecrackerConfigSchema = json.RawMessage(`{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "kernel_image_path": { "type": "string" },
+    "rootfs_path": { "type": "string" },
+    "read_only_rootfs": { "type": "boolean" },
+    "boot_args": { "type": "string" },
+    "resources": {
+      "type": "object",
+      "properties": {
+        "cpu": { "type": "integer", "minimum": 0 },
+        "memory": { "type": "integer", "minimum": 0 }
+      }
+    },
+    "ports": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "container_port": { "type": "integer", "minimum": 1, "maximum": 65535 },
+          "host_port": { "type": "integer", "minimum": 0, "maximum": 65535 },
+          "protocol": { "type": "string", "enum": ["tcp", "udp"] },
+          "name": { "type": "string" }
+        },
+        "required": ["container_port"]
+      }
+    },
+    "network": {
+      "type": "object",
+      "properties": {
+        "tap_device": { "type": "string" },
+        "guest_mac": { "type": "string" },
+        "guest_ip": { "type": "string" }
+      },
+      "required": ["tap_device"],
+      "additionalProperties": false
+    },
+    "image": { "type": "string" },
+    "env": {
+      "type": "object",
+      "additionalProperties": { "type": "string" }
+    }
+  },
+  "additionalProperties": true
+}`)
+
+// tapDevicePattern matches Linux interface names
+var tapDevicePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,15}$`)
+
+func parseComputeConfig(defaults map[string]interface{}, raw json.RawMessage) (*ComputeConfig, error) {
+	mergedRaw, err := compute.MergeConfigJSON(defaults, raw)
+	if err != nil {
+		return nil, fmt.Errorf("merge firecracker config: %w", err)
+	}
+	var cfg ComputeConfig
+	if len(mergedRaw) == 0 {
+		return &cfg, nil
+	}
+	if err := json.Unmarshal(mergedRaw, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid JSON structure: %w", err)
+	}
+	return &cfg, nil
+}
+
+// validateComputeConfig checks a tenant's config for the provider's mode
+func validateComputeConfig(cfg *ComputeConfig, mode string) error {
+	var errors []string
+	switch mode {
+	case ModeKata:
+		if cfg.Image == "" {
+			errors = append(errors, "image is required in kata mode")
+		}
+		if cfg.KernelImagePath != "" || cfg.RootfsPath != "" || cfg.Network != nil {
+			errors = append(errors, "kernel_image_path, rootfs_path and network only apply in firecracker mode")
+		}
+	default:
+		if cfg.KernelImagePath == "" {
+			errors = append(errors, "kernel_image_path is required")
+		}
+		if cfg.RootfsPath == "" {
+			errors = append(errors, "rootfs_path is required")
+		}
+		if cfg.Image != "" || len(cfg.Env) > 0 {
+			errors = append(errors, "image and env only apply in kata mode")
+		}
+	}
+	if network := cfg.Network; network != nil {
+		if !tapDevicePattern.MatchString(network.TapDevice) {
+			errors = append(errors, fmt.Sprintf("network.tap_device: invalid interface name '%s'", network.TapDevice))
+		}
+		if network.GuestMAC != "" {
+			if _, err := net.ParseMAC(network.GuestMAC); err != nil {
+				errors = append(errors, fmt.Sprintf("network.guest_mac: invalid MAC address '%s'", network.GuestMAC))
+			}
+		}
+		if network.GuestIP != "" && net.ParseIP(network.GuestIP) == nil {
+			errors = append(errors, fmt.Sprintf("network.guest_ip: invalid IP address '%s'", network.GuestIP))
+		}
+	}
+	if cfg.Resources != nil && (cfg.Resources.CPU < 0 || cfg.Resources.Memory < 0) {
+		errors = append(errors, "resources: cpu and memory must be non-negative")
+	}
+	for i, port := range cfg.Ports {
+		if port.ContainerPort < 1 || port.ContainerPort > 65535 {
+			errors = append(errors, fmt.Sprintf("ports[%d].container_port: must be between 1 and 65535", i))
+		}
+		if port.HostPort < 0 || port.HostPort > 65535 {
+			errors = append(errors, fmt.Sprintf("ports[%d].host_port: must be between 0 and 65535", i))
+		}
+		if port.Protocol != "" && port.Protocol != "tcp" && port.Protocol != "udp" {
+			errors = append(errors, fmt.Sprintf("ports[%d].protocol: must be tcp or udp", i))
+		}
+	}
+	for key := range cfg.Env {
+		if key == "" || strings.Contains(key, "=") {
+			errors = append(errors, fmt.Sprintf("env: invalid environment variable name '%s'", key))
+		}
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("%w: Firecracker configuration validation failed: %s", compute.ErrInvalidConfig, strings.Join(errors, "; "))
+	}
+	return nil
+}
+
+// machineSize converts resource requirements to whole vCPUs and MiB of memory, rounding CPU up
+// so a tenant never gets less than it asked for
+func machineSize(resources compute.ResourceRequirements) (int, int, error) {
+	vcpus := 1
+	if resources.CPU > 0 {
+		vcpus = (resources.CPU + 999) / 1000
+	}
+	if vcpus > maxVCPUs {
+		return 0, 0, fmt.Errorf("%w: %d millicores needs %d vCPUs, a microVM has at most %d", compute.ErrInvalidConfig, resources.CPU, vcpus, maxVCPUs)
+	}
+	memory := defaultMemoryMiB
+	if resources.Memory > 0 {
+		memory = resources.Memory
+	}
+	return vcpus, memory, nil
+}
+
+func copyConfigMap(input map[string]interface{}) map[string]interface{} {
+	if len(input) == 0 {
+		return nil
+	}
+	output := make(map[string]interface{}, len(input))
+	for key, value := range input {
+		output[key] = value
+	}
+	return output
+}
+
+func marshalConfigMap(input map[string]interface{}) json.RawMessage {
+	if len(input) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
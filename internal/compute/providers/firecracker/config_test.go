@@ -0,0 +1,132 @@
+package firecracker
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+func TestValidateComputeConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		config  string
+		wantErr string
+	}{
+		{
+			name:   "firecracker",
+			mode:   ModeFirecracker,
+			config: `{"kernel_image_path": "/vm/vmlinux", "rootfs_path": "/vm/rootfs.ext4", "network": {"tap_device": "tap0", "guest_mac": "06:00:ac:10:00:02", "guest_ip": "172.16.0.2"}}`,
+		},
+		{
+			name:    "firecracker without kernel",
+			mode:    ModeFirecracker,
+			config:  `{"rootfs_path": "/vm/rootfs.ext4"}`,
+			wantErr: "kernel_image_path is required",
+		},
+		{
+			name:    "firecracker with image",
+			mode:    ModeFirecracker,
+			config:  `{"kernel_image_path": "/vm/vmlinux", "rootfs_path": "/vm/rootfs.ext4", "image": "nginx:latest"}`,
+			wantErr: "image and env only apply in kata mode",
+		},
+		{
+			name:    "bad network",
+			mode:    ModeFirecracker,
+			config:  `{"kernel_image_path": "/vm/vmlinux", "rootfs_path": "/vm/rootfs.ext4", "network": {"tap_device": "tap 0", "guest_mac": "nope", "guest_ip": "10.0.0"}}`,
+			wantErr: "network.tap_device: invalid interface name 'tap 0'; network.guest_mac: invalid MAC address 'nope'; network.guest_ip: invalid IP address '10.0.0'",
+		},
+		{
+			name:    "bad port",
+			mode:    ModeFirecracker,
+			config:  `{"kernel_image_path": "/vm/vmlinux", "rootfs_path": "/vm/rootfs.ext4", "ports": [{"container_port": 0, "protocol": "sctp"}]}`,
+			wantErr: "ports[0].container_port: must be between 1 and 65535; ports[0].protocol: must be tcp or udp",
+		},
+		{
+			name:   "kata",
+			mode:   ModeKata,
+			config: `{"image": "nginx:latest", "env": {"PORT": "8080"}, "ports": [{"container_port": 8080, "host_port": 18080}]}`,
+		},
+		{
+			name:    "kata without image",
+			mode:    ModeKata,
+			config:  `{"env": {"PORT": "8080"}}`,
+			wantErr: "image is required in kata mode",
+		},
+		{
+			name:    "kata with kernel",
+			mode:    ModeKata,
+			config:  `{"image": "nginx:latest", "kernel_image_path": "/vm/vmlinux"}`,
+			wantErr: "kernel_image_path, rootfs_path and network only apply in firecracker mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := parseComputeConfig(nil, json.RawMessage(tt.config))
+			if err != nil {
+				t.Fatalf("parseComputeConfig() error = %v", err)
+			}
+			err = validateComputeConfig(cfg, tt.mode)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("expected config to be valid, got %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, compute.ErrInvalidConfig) {
+				t.Fatalf("expected ErrInvalidConfig, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMachineSize(t *testing.T) {
+	tests := []struct {
+		name       string
+		resources  compute.ResourceRequirements
+		wantVCPUs  int
+		wantMemory int
+		wantErr    bool
+	}{
+		{name: "defaults", resources: compute.ResourceRequirements{}, wantVCPUs: 1, wantMemory: defaultMemoryMiB},
+		{name: "fractional cpu rounds up", resources: compute.ResourceRequirements{CPU: 1500, Memory: 512}, wantVCPUs: 2, wantMemory: 512},
+		{name: "small cpu", resources: compute.ResourceRequirements{CPU: 250}, wantVCPUs: 1, wantMemory: defaultMemoryMiB},
+		{name: "too many vcpus", resources: compute.ResourceRequirements{CPU: 33000}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcpus, memory, err := machineSize(tt.resources)
+			if tt.wantErr {
+				if !errors.Is(err, compute.ErrInvalidConfig) {
+					t.Fatalf("expected ErrInvalidConfig, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("machineSize() error = %v", err)
+			}
+			if vcpus != tt.wantVCPUs || memory != tt.wantMemory {
+				t.Fatalf("expected %d vCPUs and %d MiB, got %d and %d", tt.wantVCPUs, tt.wantMemory, vcpus, memory)
+			}
+		})
+	}
+}
+
+func TestParseComputeConfigMergesDefaults(t *testing.T) {
+	defaults := map[string]interface{}{"kernel_image_path": "/vm/vmlinux", "boot_args": "console=ttyS0"}
+	cfg, err := parseComputeConfig(defaults, json.RawMessage(`{"rootfs_path": "/vm/rootfs.ext4", "boot_args": "console=ttyS0 quiet"}`))
+	if err != nil {
+		t.Fatalf("parseComputeConfig() error = %v", err)
+	}
+	if cfg.KernelImagePath != "/vm/vmlinux" || cfg.RootfsPath != "/vm/rootfs.ext4" || cfg.BootArgs != "console=ttyS0 quiet" {
+		t.Fatalf("unexpected merged config: %+v", cfg)
+	}
+}
@@ -0,0 +1,444 @@
+package firecracker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+const (
+	// ModeFirecracker launches a Firecracker VM per tenant from a kernel and root filesystem; the default
+	ModeFirecracker = "firecracker"
+
+	// ModeKata runs the tenant's container image under the Kata containerd runtime, which wraps it in a microVM
+	ModeKata = "kata"
+)
+
+const (
+	defaultStateDir    = "/var/lib/landlord/firecracker"
+	defaultKataRuntime = "io.containerd.kata.v2"
+
+	// statusConsoleBytes caps the console output included in the status of a VM that is not running
+	statusConsoleBytes = 1024
+)
+
+// Provider implements the compute.Provider interface with one microVM per tenant
+type Provider struct {
+	mu               sync.Mutex
+	logger           *zap.Logger
+	mode             string
+	backend          backend
+	defaultsMu       sync.RWMutex
+	defaultConfig    map[string]interface{}
+	defaultConfigRaw json.RawMessage
+	// tenants records what each tenant's VM was launched with, so Update can tell what changed
+	tenants map[string]*machine
+}
+
+// Config represents Firecracker provider configuration
+type Config struct {
+	// Mode is "firecracker" (default) or "kata"
+	Mode string `json:"mode,omitempty"`
+
+	// BinaryPath is the firecracker binary, or nerdctl in kata mode; looked up on PATH when not absolute
+	BinaryPath string `json:"binary_path,omitempty"`
+
+	// StateDir holds each tenant's API socket, pid file, console log and root filesystem copy; firecracker mode only
+	StateDir string `json:"state_dir,omitempty"`
+
+	// KataRuntime is the containerd runtime class tenants run under; kata mode only
+	KataRuntime string `json:"kata_runtime,omitempty"`
+
+	// Namespace is the containerd namespace for tenant containers; kata mode only
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// New creates a new Firecracker provider
+func New(cfg *Config, defaults map[string]interface{}, logger *zap.Logger) (*Provider, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	logger = logger.With(zap.String("component", "firecracker-provider"))
+
+	if cfg.Mode == "" {
+		cfg.Mode = ModeFirecracker
+	}
+	var b backend
+	switch cfg.Mode {
+	case ModeFirecracker:
+		if cfg.BinaryPath == "" {
+			cfg.BinaryPath = "firecracker"
+		}
+		if cfg.StateDir == "" {
+			cfg.StateDir = defaultStateDir
+		}
+		if cfg.Namespace != "" || cfg.KataRuntime != "" {
+			return nil, fmt.Errorf("namespace and kata_runtime require mode %q", ModeKata)
+		}
+		// Firecracker needs KVM; without it every tenant would fail to boot
+		if _, err := os.Stat("/dev/kvm"); err != nil {
+			return nil, fmt.Errorf("%w: firecracker needs /dev/kvm: %v", compute.ErrProviderUnavailable, err)
+		}
+	case ModeKata:
+		if cfg.BinaryPath == "" {
+			cfg.BinaryPath = "nerdctl"
+		}
+		if cfg.KataRuntime == "" {
+			cfg.KataRuntime = defaultKataRuntime
+		}
+	default:
+		return nil, fmt.Errorf("invalid mode %q, must be %q or %q", cfg.Mode, ModeFirecracker, ModeKata)
+	}
+
+	binary, err := exec.LookPath(cfg.BinaryPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", compute.ErrProviderUnavailable, err)
+	}
+
+	if cfg.Mode == ModeKata {
+		b = newKataBackend(binary, cfg.Namespace, cfg.KataRuntime, logger)
+	} else {
+		if err := os.MkdirAll(cfg.StateDir, 0o750); err != nil {
+			return nil, fmt.Errorf("create state directory: %w", err)
+		}
+		b = &firecrackerBackend{binary: binary, stateDir: cfg.StateDir, logger: logger}
+	}
+
+	p, err := newProvider(cfg.Mode, b, defaults, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("firecracker provider initialized",
+		zap.String("mode", cfg.Mode),
+		zap.String("binary", binary),
+		zap.String("state_dir", cfg.StateDir),
+		zap.String("kata_runtime", cfg.KataRuntime))
+	return p, nil
+}
+
+func newProvider(mode string, b backend, defaults map[string]interface{}, logger *zap.Logger) (*Provider, error) {
+	if err := validateDefaults(defaults); err != nil {
+		return nil, err
+	}
+	return &Provider{
+		logger:           logger,
+		mode:             mode,
+		backend:          b,
+		defaultConfig:    copyConfigMap(defaults),
+		defaultConfigRaw: marshalConfigMap(defaults),
+		tenants:          make(map[string]*machine),
+	}, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "firecracker"
+}
+
+// Provision boots a microVM for a tenant
+func (p *Provider) Provision(ctx context.Context, spec *compute.TenantComputeSpec) (*compute.ProvisionResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.tenants[spec.TenantID]; exists {
+		return nil, fmt.Errorf("%w: %s", compute.ErrAlreadyProvisioned, spec.TenantID)
+	}
+
+	vm, err := p.machine(spec)
+	if err != nil {
+		return nil, err
+	}
+	resourceIDs, err := p.start(ctx, vm)
+	if err != nil {
+		return nil, err
+	}
+	p.tenants[spec.TenantID] = vm
+
+	return &compute.ProvisionResult{
+		TenantID:      spec.TenantID,
+		ProviderType:  p.Name(),
+		Status:        compute.ProvisionStatusSuccess,
+		ResourceIDs:   resourceIDs,
+		Endpoints:     p.endpoints(vm),
+		Message:       fmt.Sprintf("microVM started with %d vCPUs and %d MiB", vm.VCPUs, vm.MemoryMiB),
+		ProvisionedAt: time.Now(),
+	}, nil
+}
+
+// Update recreates a tenant's microVM when its size or configuration changed; a VM cannot be
+// resized or reconfigured once booted
+func (p *Provider) Update(ctx context.Context, tenantID string, spec *compute.TenantComputeSpec) (*compute.UpdateResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	current, exists := p.tenants[tenantID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", compute.ErrTenantNotFound, tenantID)
+	}
+
+	vm, err := p.machine(spec)
+	if err != nil {
+		return nil, err
+	}
+	vm.TenantID = tenantID
+
+	changes := []string{}
+	if vm.VCPUs != current.VCPUs {
+		changes = append(changes, "vCPU count changed")
+	}
+	if vm.MemoryMiB != current.MemoryMiB {
+		changes = append(changes, "memory changed")
+	}
+	if !reflect.DeepEqual(vm.Config, current.Config) || !reflect.DeepEqual(vm.Ports, current.Ports) {
+		changes = append(changes, "compute config changed")
+	}
+	if len(changes) == 0 {
+		return &compute.UpdateResult{
+			TenantID:     tenantID,
+			ProviderType: p.Name(),
+			Status:       compute.UpdateStatusNoChanges,
+			Changes:      changes,
+			Message:      "No changes detected",
+			UpdatedAt:    time.Now(),
+		}, nil
+	}
+
+	if err := p.backend.stop(ctx, tenantID); err != nil {
+		return nil, fmt.Errorf("failed to stop microVM: %w", err)
+	}
+	delete(p.tenants, tenantID)
+	if _, err := p.start(ctx, vm); err != nil {
+		return nil, err
+	}
+	p.tenants[tenantID] = vm
+
+	return &compute.UpdateResult{
+		TenantID:     tenantID,
+		ProviderType: p.Name(),
+		Status:       compute.UpdateStatusSuccess,
+		Changes:      changes,
+		Message:      "microVM recreated",
+		UpdatedAt:    time.Now(),
+	}, nil
+}
+
+// Destroy stops a tenant's microVM and removes its state
+func (p *Provider) Destroy(ctx context.Context, tenantID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Idempotent - the backend ignores a VM that is already gone
+	if err := p.backend.stop(ctx, tenantID); err != nil {
+		p.logger.Error("failed to destroy microVM", zap.String("tenant_id", tenantID), zap.Error(err))
+		return fmt.Errorf("failed to destroy microVM: %w", err)
+	}
+	delete(p.tenants, tenantID)
+
+	p.logger.Info("microVM destroyed", zap.String("tenant_id", tenantID))
+	return nil
+}
+
+// GetStatus returns the current status of a tenant's microVM. A VM that is not running
+// includes the end of its console output, which usually says why the guest stopped.
+func (p *Provider) GetStatus(ctx context.Context, tenantID string) (*compute.ComputeStatus, error) {
+	status, err := p.backend.status(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	health := compute.HealthStatusUnknown
+	switch status.State {
+	case compute.ComputeStateRunning:
+		health = compute.HealthStatusHealthy
+	case compute.ComputeStateStopped, compute.ComputeStateFailed:
+		health = compute.HealthStatusUnhealthy
+	}
+
+	message := status.Message
+	if status.State != compute.ComputeStateRunning && status.State != compute.ComputeStateStarting {
+		if console, err := p.backend.consoleLog(ctx, tenantID); err == nil && strings.TrimSpace(console) != "" {
+			console = strings.TrimSpace(console)
+			if len(console) > statusConsoleBytes {
+				console = console[len(console)-statusConsoleBytes:]
+			}
+			message = fmt.Sprintf("%s; console: %s", message, console)
+		}
+	}
+
+	metadata := map[string]string{"mode": p.mode}
+	for key, value := range status.Metadata {
+		metadata[key] = value
+	}
+
+	return &compute.ComputeStatus{
+		TenantID:     tenantID,
+		ProviderType: p.Name(),
+		State:        status.State,
+		Containers: []compute.ContainerStatus{{
+			Name:    "microvm",
+			State:   string(status.State),
+			Ready:   status.State == compute.ComputeStateRunning,
+			Message: message,
+		}},
+		Health:      health,
+		LastUpdated: time.Now(),
+		Metadata:    metadata,
+	}, nil
+}
+
+// ConsoleLog returns the end of a tenant's serial console output (kata mode: the container's logs)
+func (p *Provider) ConsoleLog(ctx context.Context, tenantID string) (string, error) {
+	return p.backend.consoleLog(ctx, tenantID)
+}
+
+// Validate validates a compute spec for the configured mode
+func (p *Provider) Validate(ctx context.Context, spec *compute.TenantComputeSpec) error {
+	_, err := p.machine(spec)
+	return err
+}
+
+// ValidateConfig validates Firecracker-specific configuration
+func (p *Provider) ValidateConfig(config json.RawMessage) error {
+	cfg, err := parseComputeConfig(p.defaults(), config)
+	if err != nil {
+		return compute.Mark(err, compute.ErrInvalidConfig)
+	}
+	if err := validateComputeConfig(cfg, p.mode); err != nil {
+		return err
+	}
+	if cfg.Resources != nil {
+		_, _, err = machineSize(*cfg.Resources)
+	}
+	return err
+}
+
+// ConfigSchema returns the JSON Schema for Firecracker compute_config.
+func (p *Provider) ConfigSchema() json.RawMessage {
+	return firecrackerConfigSchema
+}
+
+// ConfigDefaults returns the configured default compute_config.
+func (p *Provider) ConfigDefaults() json.RawMessage {
+	p.defaultsMu.RLock()
+	defer p.defaultsMu.RUnlock()
+	return p.defaultConfigRaw
+}
+
+// Reconfigure replaces the default compute_config merged into every tenant's config
+func (p *Provider) Reconfigure(defaults map[string]interface{}) error {
+	if err := validateDefaults(defaults); err != nil {
+		return err
+	}
+	p.defaultsMu.Lock()
+	defer p.defaultsMu.Unlock()
+	p.defaultConfig = copyConfigMap(defaults)
+	p.defaultConfigRaw = marshalConfigMap(defaults)
+	return nil
+}
+
+// Close releases provider resources; tenant VMs keep running
+func (p *Provider) Close() error {
+	return nil
+}
+
+func (p *Provider) defaults() map[string]interface{} {
+	p.defaultsMu.RLock()
+	defer p.defaultsMu.RUnlock()
+	return p.defaultConfig
+}
+
+// validateDefaults checks that defaults decode; required fields may come from each tenant's config instead
+func validateDefaults(defaults map[string]interface{}) error {
+	if _, err := parseComputeConfig(defaults, nil); err != nil {
+		return compute.Mark(err, compute.ErrInvalidConfig)
+	}
+	return nil
+}
+
+// machine resolves a tenant spec into the VM to launch. The spec's resource requirements size
+// the VM, falling back to the config's resources.
+func (p *Provider) machine(spec *compute.TenantComputeSpec) (*machine, error) {
+	cfg, err := parseComputeConfig(p.defaults(), spec.ProviderConfig)
+	if err != nil {
+		return nil, compute.Mark(err, compute.ErrInvalidConfig)
+	}
+	if err := validateComputeConfig(cfg, p.mode); err != nil {
+		return nil, err
+	}
+
+	resources := spec.Resources
+	if resources.CPU == 0 && resources.Memory == 0 && cfg.Resources != nil {
+		resources = *cfg.Resources
+	}
+	vcpus, memory, err := machineSize(resources)
+	if err != nil {
+		return nil, err
+	}
+
+	ports := append([]compute.PortMapping{}, cfg.Ports...)
+	for _, container := range spec.WorkloadContainers() {
+		ports = append(ports, container.Ports...)
+	}
+
+	return &machine{
+		TenantID:  spec.TenantID,
+		Config:    cfg,
+		VCPUs:     vcpus,
+		MemoryMiB: memory,
+		Ports:     ports,
+		Labels:    spec.Labels,
+	}, nil
+}
+
+// start launches a VM, cleaning up whatever a failed launch left behind
+func (p *Provider) start(ctx context.Context, vm *machine) (map[string]string, error) {
+	resourceIDs, err := p.backend.start(ctx, vm)
+	if err != nil {
+		p.logger.Error("failed to start microVM", zap.String("tenant_id", vm.TenantID), zap.Error(err))
+		if cleanupErr := p.backend.stop(context.Background(), vm.TenantID); cleanupErr != nil {
+			p.logger.Warn("failed to clean up microVM", zap.String("tenant_id", vm.TenantID), zap.Error(cleanupErr))
+		}
+		return nil, fmt.Errorf("failed to start microVM: %w", err)
+	}
+	return resourceIDs, nil
+}
+
+// endpoints lists the tenant's ports at the guest IP, or in kata mode at their published host ports
+func (p *Provider) endpoints(vm *machine) []compute.Endpoint {
+	endpoints := []compute.Endpoint{}
+	for _, port := range vm.Ports {
+		address, number := "", port.ContainerPort
+		switch {
+		case p.mode == ModeKata && port.HostPort > 0:
+			address, number = "127.0.0.1", port.HostPort
+		case p.mode == ModeFirecracker && vm.Config.Network != nil && vm.Config.Network.GuestIP != "":
+			address = vm.Config.Network.GuestIP
+		}
+		if address == "" {
+			continue
+		}
+		endpoint := compute.Endpoint{
+			Type:    "tcp",
+			Address: address,
+			Port:    number,
+			URL:     fmt.Sprintf("tcp://%s:%d", address, number),
+		}
+		if port.Name != "" {
+			endpoint.Type = port.Name
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
@@ -0,0 +1,455 @@
+package firecracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// fakeBackend records the VMs the provider starts and stops
+type fakeBackend struct {
+	running  map[string]*machine
+	started  []*machine
+	stopped  []string
+	state    compute.ComputeState
+	console  string
+	startErr error
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{running: map[string]*machine{}, state: compute.ComputeStateRunning}
+}
+
+func (b *fakeBackend) start(ctx context.Context, vm *machine) (map[string]string, error) {
+	if b.startErr != nil {
+		return nil, b.startErr
+	}
+	b.running[vm.TenantID] = vm
+	b.started = append(b.started, vm)
+	return map[string]string{"vm_id": vm.TenantID}, nil
+}
+
+func (b *fakeBackend) stop(ctx context.Context, tenantID string) error {
+	delete(b.running, tenantID)
+	b.stopped = append(b.stopped, tenantID)
+	return nil
+}
+
+func (b *fakeBackend) status(ctx context.Context, tenantID string) (*vmStatus, error) {
+	if _, ok := b.running[tenantID]; !ok {
+		return nil, fmt.Errorf("%w: %s", compute.ErrTenantNotFound, tenantID)
+	}
+	status := &vmStatus{State: b.state}
+	if b.state != compute.ComputeStateRunning {
+		status.Message = "microVM exited"
+	}
+	return status, nil
+}
+
+func (b *fakeBackend) consoleLog(ctx context.Context, tenantID string) (string, error) {
+	return b.console, nil
+}
+
+func testProvider(t *testing.T, mode string, defaults map[string]interface{}) (*Provider, *fakeBackend) {
+	t.Helper()
+	b := newFakeBackend()
+	p, err := newProvider(mode, b, defaults, zap.NewNop())
+	if err != nil {
+		t.Fatalf("newProvider() error = %v", err)
+	}
+	return p, b
+}
+
+func vmSpec(tenantID, config string, resources compute.ResourceRequirements) *compute.TenantComputeSpec {
+	return &compute.TenantComputeSpec{
+		TenantID:       tenantID,
+		ProviderType:   "firecracker",
+		Resources:      resources,
+		ProviderConfig: json.RawMessage(config),
+	}
+}
+
+const vmConfig = `{"kernel_image_path": "/vm/vmlinux", "rootfs_path": "/vm/rootfs.ext4", "network": {"tap_device": "tap0", "guest_ip": "172.16.0.2"}, "ports": [{"container_port": 8080, "name": "http"}]}`
+
+func TestProvisionSizesVM(t *testing.T) {
+	p, b := testProvider(t, ModeFirecracker, nil)
+
+	result, err := p.Provision(context.Background(), vmSpec("tenant-1", vmConfig, compute.ResourceRequirements{CPU: 1500, Memory: 512}))
+	if err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	vm := b.running["tenant-1"]
+	if vm == nil || vm.VCPUs != 2 || vm.MemoryMiB != 512 {
+		t.Fatalf("expected a 2 vCPU, 512 MiB VM, got %+v", vm)
+	}
+	want := []compute.Endpoint{{Type: "http", Address: "172.16.0.2", Port: 8080, URL: "tcp://172.16.0.2:8080"}}
+	if !reflect.DeepEqual(result.Endpoints, want) {
+		t.Fatalf("expected endpoints %+v, got %+v", want, result.Endpoints)
+	}
+
+	if _, err := p.Provision(context.Background(), vmSpec("tenant-1", vmConfig, compute.ResourceRequirements{})); err == nil {
+		t.Fatalf("expected provisioning an existing tenant to fail")
+	}
+}
+
+func TestProvisionUsesConfigResources(t *testing.T) {
+	p, b := testProvider(t, ModeFirecracker, map[string]interface{}{"resources": map[string]interface{}{"cpu": 2000, "memory": 1024}})
+
+	if _, err := p.Provision(context.Background(), vmSpec("tenant-1", vmConfig, compute.ResourceRequirements{})); err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	if vm := b.running["tenant-1"]; vm.VCPUs != 2 || vm.MemoryMiB != 1024 {
+		t.Fatalf("expected config resources to size the VM, got %d vCPUs and %d MiB", vm.VCPUs, vm.MemoryMiB)
+	}
+}
+
+func TestProvisionRejectsInvalidConfig(t *testing.T) {
+	p, b := testProvider(t, ModeFirecracker, nil)
+
+	_, err := p.Provision(context.Background(), vmSpec("tenant-1", `{"rootfs_path": "/vm/rootfs.ext4"}`, compute.ResourceRequirements{}))
+	if !errors.Is(err, compute.ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+	if len(b.started) != 0 {
+		t.Fatalf("expected no VM to be started")
+	}
+}
+
+func TestProvisionCleansUpFailedStart(t *testing.T) {
+	p, b := testProvider(t, ModeFirecracker, nil)
+	b.startErr = errors.New("boot failed")
+
+	if _, err := p.Provision(context.Background(), vmSpec("tenant-1", vmConfig, compute.ResourceRequirements{})); err == nil {
+		t.Fatalf("expected Provision to fail")
+	}
+	if !reflect.DeepEqual(b.stopped, []string{"tenant-1"}) {
+		t.Fatalf("expected the failed VM to be cleaned up, stopped %v", b.stopped)
+	}
+}
+
+func TestUpdateRecreatesVM(t *testing.T) {
+	p, b := testProvider(t, ModeFirecracker, nil)
+	ctx := context.Background()
+
+	if _, err := p.Provision(ctx, vmSpec("tenant-1", vmConfig, compute.ResourceRequirements{CPU: 1000, Memory: 256})); err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+
+	result, err := p.Update(ctx, "tenant-1", vmSpec("tenant-1", vmConfig, compute.ResourceRequirements{CPU: 1000, Memory: 256}))
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if result.Status != compute.UpdateStatusNoChanges || len(b.stopped) != 0 {
+		t.Fatalf("expected no changes, got %s with %d stops", result.Status, len(b.stopped))
+	}
+
+	result, err = p.Update(ctx, "tenant-1", vmSpec("tenant-1", vmConfig, compute.ResourceRequirements{CPU: 2000, Memory: 256}))
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if result.Status != compute.UpdateStatusSuccess || !reflect.DeepEqual(result.Changes, []string{"vCPU count changed"}) {
+		t.Fatalf("expected the vCPU change, got %s %v", result.Status, result.Changes)
+	}
+	if len(b.stopped) != 1 || len(b.started) != 2 || b.running["tenant-1"].VCPUs != 2 {
+		t.Fatalf("expected the VM to be recreated with 2 vCPUs")
+	}
+
+	if _, err := p.Update(ctx, "missing", vmSpec("missing", vmConfig, compute.ResourceRequirements{})); !errors.Is(err, compute.ErrTenantNotFound) {
+		t.Fatalf("expected ErrTenantNotFound, got %v", err)
+	}
+}
+
+func TestStatusIncludesConsoleWhenStopped(t *testing.T) {
+	p, b := testProvider(t, ModeFirecracker, nil)
+	ctx := context.Background()
+
+	if _, err := p.GetStatus(ctx, "tenant-1"); !errors.Is(err, compute.ErrTenantNotFound) {
+		t.Fatalf("expected ErrTenantNotFound, got %v", err)
+	}
+	if _, err := p.Provision(ctx, vmSpec("tenant-1", vmConfig, compute.ResourceRequirements{})); err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+
+	status, err := p.GetStatus(ctx, "tenant-1")
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if status.State != compute.ComputeStateRunning || status.Health != compute.HealthStatusHealthy || status.Metadata["mode"] != ModeFirecracker {
+		t.Fatalf("expected a healthy running VM, got %+v", status)
+	}
+
+	b.state = compute.ComputeStateStopped
+	b.console = "Kernel panic - not syncing: VFS: Unable to mount root fs\n"
+	status, err = p.GetStatus(ctx, "tenant-1")
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if status.Health != compute.HealthStatusUnhealthy {
+		t.Fatalf("expected a stopped VM to be unhealthy, got %s", status.Health)
+	}
+	if message := status.Containers[0].Message; message != "microVM exited; console: Kernel panic - not syncing: VFS: Unable to mount root fs" {
+		t.Fatalf("unexpected message %q", message)
+	}
+}
+
+func TestDestroyIsIdempotent(t *testing.T) {
+	p, b := testProvider(t, ModeFirecracker, nil)
+	ctx := context.Background()
+
+	if _, err := p.Provision(ctx, vmSpec("tenant-1", vmConfig, compute.ResourceRequirements{})); err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := p.Destroy(ctx, "tenant-1"); err != nil {
+			t.Fatalf("Destroy() error = %v", err)
+		}
+	}
+	if _, exists := b.running["tenant-1"]; exists {
+		t.Fatalf("expected the VM to be stopped")
+	}
+}
+
+func TestReconfigureRejectsBadDefaults(t *testing.T) {
+	p, _ := testProvider(t, ModeFirecracker, nil)
+
+	if err := p.Reconfigure(map[string]interface{}{"read_only_rootfs": "yes"}); !errors.Is(err, compute.ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+	if err := p.Reconfigure(map[string]interface{}{"kernel_image_path": "/vm/vmlinux", "rootfs_path": "/vm/rootfs.ext4"}); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	if err := p.ValidateConfig(json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("expected defaults to complete the config, got %v", err)
+	}
+}
+
+// fakeAPI serves the Firecracker API on a unix socket and records the requests it receives
+type fakeAPI struct {
+	mu       sync.Mutex
+	requests []string
+	bodies   map[string]map[string]interface{}
+	fail     string
+}
+
+func serveFakeAPI(t *testing.T, socket string) *fakeAPI {
+	t.Helper()
+	api := &fakeAPI{bodies: map[string]map[string]interface{}{}}
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen on %s: %v", socket, err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		raw, _ := io.ReadAll(r.Body)
+		json.Unmarshal(raw, &body)
+
+		api.mu.Lock()
+		defer api.mu.Unlock()
+		api.requests = append(api.requests, r.Method+" "+r.URL.Path)
+		api.bodies[r.URL.Path] = body
+		if r.URL.Path == api.fail {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"fault_message": "The kernel file cannot be opened"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+	return api
+}
+
+func TestFirecrackerConfiguresVM(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, socketFile)
+	api := serveFakeAPI(t, socket)
+	b := &firecrackerBackend{binary: "firecracker", stateDir: dir, logger: zap.NewNop()}
+
+	vm := &machine{
+		TenantID: "tenant-1",
+		Config: &ComputeConfig{
+			KernelImagePath: "/vm/vmlinux",
+			RootfsPath:      "/vm/rootfs.ext4",
+			Network:         &NetworkConfig{TapDevice: "tap0", GuestMAC: "06:00:ac:10:00:02"},
+		},
+		VCPUs:     2,
+		MemoryMiB: 512,
+	}
+	if err := b.configure(context.Background(), socket, vm, "/state/rootfs.ext4"); err != nil {
+		t.Fatalf("configure() error = %v", err)
+	}
+
+	wantRequests := []string{
+		"PUT /machine-config",
+		"PUT /boot-source",
+		"PUT /drives/rootfs",
+		"PUT /network-interfaces/eth0",
+		"PUT /actions",
+	}
+	if !reflect.DeepEqual(api.requests, wantRequests) {
+		t.Fatalf("expected requests %v, got %v", wantRequests, api.requests)
+	}
+	if body := api.bodies["/machine-config"]; body["vcpu_count"] != float64(2) || body["mem_size_mib"] != float64(512) {
+		t.Fatalf("unexpected machine config %v", body)
+	}
+	if body := api.bodies["/boot-source"]; body["boot_args"] != defaultBootArgs {
+		t.Fatalf("expected default boot args, got %v", body)
+	}
+	if body := api.bodies["/drives/rootfs"]; body["path_on_host"] != "/state/rootfs.ext4" || body["is_root_device"] != true {
+		t.Fatalf("unexpected drive %v", body)
+	}
+	if body := api.bodies["/network-interfaces/eth0"]; body["host_dev_name"] != "tap0" || body["guest_mac"] != "06:00:ac:10:00:02" {
+		t.Fatalf("unexpected network interface %v", body)
+	}
+	if body := api.bodies["/actions"]; body["action_type"] != "InstanceStart" {
+		t.Fatalf("unexpected action %v", body)
+	}
+}
+
+func TestFirecrackerAPIFault(t *testing.T) {
+	dir := t.TempDir()
+	socket := filepath.Join(dir, socketFile)
+	api := serveFakeAPI(t, socket)
+	api.fail = "/boot-source"
+	b := &firecrackerBackend{binary: "firecracker", stateDir: dir, logger: zap.NewNop()}
+
+	vm := &machine{TenantID: "tenant-1", Config: &ComputeConfig{KernelImagePath: "/vm/vmlinux", RootfsPath: "/vm/rootfs.ext4"}, VCPUs: 1, MemoryMiB: 128}
+	err := b.configure(context.Background(), socket, vm, "/vm/rootfs.ext4")
+	if !errors.Is(err, compute.ErrInvalidConfig) || !strings.Contains(err.Error(), "The kernel file cannot be opened") {
+		t.Fatalf("expected the API fault as ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestFirecrackerStatusFromPIDFile(t *testing.T) {
+	dir := t.TempDir()
+	b := &firecrackerBackend{binary: "firecracker", stateDir: dir, logger: zap.NewNop()}
+	ctx := context.Background()
+
+	if _, err := b.status(ctx, "tenant-1"); !errors.Is(err, compute.ErrTenantNotFound) {
+		t.Fatalf("expected ErrTenantNotFound, got %v", err)
+	}
+
+	vmDir := b.dir("tenant-1")
+	if err := os.MkdirAll(vmDir, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	// The test process stands in for a live VM
+	if err := os.WriteFile(filepath.Join(vmDir, pidFile), []byte(strconv.Itoa(os.Getpid())), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	status, err := b.status(ctx, "tenant-1")
+	if err != nil {
+		t.Fatalf("status() error = %v", err)
+	}
+	if status.State != compute.ComputeStateRunning {
+		t.Fatalf("expected running, got %s", status.State)
+	}
+
+	if err := os.WriteFile(filepath.Join(vmDir, pidFile), []byte("999999999"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(vmDir, consoleFile), []byte(strings.Repeat("x", 2*maxConsoleBytes)+"reboot: System halted\n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	status, err = b.status(ctx, "tenant-1")
+	if err != nil {
+		t.Fatalf("status() error = %v", err)
+	}
+	if status.State != compute.ComputeStateStopped {
+		t.Fatalf("expected stopped, got %s", status.State)
+	}
+	console, err := b.consoleLog(ctx, "tenant-1")
+	if err != nil {
+		t.Fatalf("consoleLog() error = %v", err)
+	}
+	if len(console) != maxConsoleBytes || !strings.HasSuffix(console, "reboot: System halted\n") {
+		t.Fatalf("expected the last %d bytes of the console, got %d bytes", maxConsoleBytes, len(console))
+	}
+
+	if err := b.stop(ctx, "tenant-1"); err != nil {
+		t.Fatalf("stop() error = %v", err)
+	}
+	if _, err := os.Stat(vmDir); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected the state directory to be removed, got %v", err)
+	}
+}
+
+func TestKataRunArgs(t *testing.T) {
+	var calls [][]string
+	b := &kataBackend{
+		runtimeClass: defaultKataRuntime,
+		logger:       zap.NewNop(),
+		run: func(ctx context.Context, args ...string) ([]byte, error) {
+			calls = append(calls, args)
+			switch args[0] {
+			case "run":
+				return []byte("abc123\n"), nil
+			case "inspect":
+				return []byte(`{"Status": "exited", "Running": false, "ExitCode": 137}`), nil
+			default:
+				return []byte("Error: no such container: landlord-tenant-tenant-1"), errors.New("exit status 1")
+			}
+		},
+	}
+	ctx := context.Background()
+
+	vm := &machine{
+		TenantID:  "tenant-1",
+		Config:    &ComputeConfig{Image: "nginx:latest", Env: map[string]string{"B": "2", "A": "1"}},
+		VCPUs:     2,
+		MemoryMiB: 512,
+		Ports:     []compute.PortMapping{{ContainerPort: 80, HostPort: 8080}, {ContainerPort: 53, Protocol: "udp"}},
+		Labels:    map[string]string{"landlord.io/tenant-id": "3f1c", "landlord.io/project": "acme/web"},
+	}
+	ids, err := b.start(ctx, vm)
+	if err != nil {
+		t.Fatalf("start() error = %v", err)
+	}
+	want := []string{
+		"run", "-d",
+		"--name", "landlord-tenant-tenant-1",
+		"--runtime", "io.containerd.kata.v2",
+		"--cpus", "2",
+		"--memory", "512m",
+		"--label", "landlord.tenant_id=tenant-1",
+		"--label", "landlord.io/project=acme/web",
+		"--label", "landlord.io/tenant-id=3f1c",
+		"-e", "A=1", "-e", "B=2",
+		"-p", "8080:80", "-p", "53/udp",
+		"nginx:latest",
+	}
+	if !reflect.DeepEqual(calls[0], want) {
+		t.Fatalf("expected args %v, got %v", want, calls[0])
+	}
+	if ids["container_id"] != "abc123" {
+		t.Fatalf("expected container ID abc123, got %q", ids["container_id"])
+	}
+
+	status, err := b.status(ctx, "tenant-1")
+	if err != nil {
+		t.Fatalf("status() error = %v", err)
+	}
+	if status.State != compute.ComputeStateFailed || status.Message != "exited with code 137" {
+		t.Fatalf("expected a failed container, got %+v", status)
+	}
+
+	// Removing a container that is already gone is not an error
+	if err := b.stop(ctx, "tenant-1"); err != nil {
+		t.Fatalf("stop() error = %v", err)
+	}
+}
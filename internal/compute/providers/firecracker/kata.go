@@ -0,0 +1,182 @@
+package firecracker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// kataBackend runs each tenant as a containerd container under the Kata runtime class, which
+// boots the container inside its own microVM. It drives containerd through the nerdctl CLI.
+type kataBackend struct {
+	runtimeClass string
+	logger       *zap.Logger
+
+	// run executes nerdctl with the given arguments and returns its combined output
+	run func(ctx context.Context, args ...string) ([]byte, error)
+}
+
+func newKataBackend(binary, namespace, runtimeClass string, logger *zap.Logger) *kataBackend {
+	var global []string
+	if namespace != "" {
+		global = append(global, "--namespace", namespace)
+	}
+	return &kataBackend{
+		runtimeClass: runtimeClass,
+		logger:       logger,
+		run: func(ctx context.Context, args ...string) ([]byte, error) {
+			return exec.CommandContext(ctx, binary, append(global, args...)...).CombinedOutput()
+		},
+	}
+}
+
+// containerName names a tenant's Kata container
+func containerName(tenantID string) string {
+	return "landlord-tenant-" + vmID(tenantID)
+}
+
+func (b *kataBackend) start(ctx context.Context, vm *machine) (map[string]string, error) {
+	name := containerName(vm.TenantID)
+	args := []string{
+		"run", "-d",
+		"--name", name,
+		"--runtime", b.runtimeClass,
+		"--cpus", strconv.Itoa(vm.VCPUs),
+		"--memory", fmt.Sprintf("%dm", vm.MemoryMiB),
+		"--label", "landlord.tenant_id=" + vm.TenantID,
+	}
+	labels := make([]string, 0, len(vm.Labels))
+	for key := range vm.Labels {
+		labels = append(labels, key)
+	}
+	sort.Strings(labels)
+	for _, key := range labels {
+		args = append(args, "--label", key+"="+vm.Labels[key])
+	}
+	keys := make([]string, 0, len(vm.Config.Env))
+	for key := range vm.Config.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "-e", key+"="+vm.Config.Env[key])
+	}
+	for _, port := range vm.Ports {
+		args = append(args, "-p", publishSpec(port))
+	}
+	args = append(args, vm.Config.Image)
+
+	out, err := b.run(ctx, args...)
+	if err != nil {
+		return nil, kataError("run", out, err)
+	}
+	b.logger.Info("kata container started",
+		zap.String("tenant_id", vm.TenantID),
+		zap.String("container", name),
+		zap.Int("vcpus", vm.VCPUs),
+		zap.Int("memory_mib", vm.MemoryMiB))
+
+	// The container ID is the last line, after any image pull progress
+	resourceIDs := map[string]string{"container": name}
+	if fields := strings.Fields(string(out)); len(fields) > 0 {
+		resourceIDs["container_id"] = fields[len(fields)-1]
+	}
+	return resourceIDs, nil
+}
+
+func (b *kataBackend) stop(ctx context.Context, tenantID string) error {
+	out, err := b.run(ctx, "rm", "-f", containerName(tenantID))
+	if err != nil {
+		if err := kataError("rm", out, err); !errors.Is(err, compute.ErrTenantNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *kataBackend) status(ctx context.Context, tenantID string) (*vmStatus, error) {
+	name := containerName(tenantID)
+	out, err := b.run(ctx, "inspect", "--format", "{{json .State}}", name)
+	if err != nil {
+		return nil, kataError("inspect", out, err)
+	}
+	var state struct {
+		Status   string `json:"Status"`
+		Running  bool   `json:"Running"`
+		ExitCode int    `json:"ExitCode"`
+		Error    string `json:"Error"`
+	}
+	if err := json.Unmarshal(out, &state); err != nil {
+		return nil, fmt.Errorf("parse container state: %w", err)
+	}
+
+	status := &vmStatus{
+		State:    compute.ComputeStateStopped,
+		Metadata: map[string]string{"container": name, "runtime": b.runtimeClass},
+	}
+	switch {
+	case state.Running:
+		status.State = compute.ComputeStateRunning
+	case state.Status == "created":
+		status.State = compute.ComputeStateStarting
+	case state.ExitCode != 0 || state.Error != "":
+		status.State = compute.ComputeStateFailed
+		status.Message = fmt.Sprintf("exited with code %d", state.ExitCode)
+		if state.Error != "" {
+			status.Message += ": " + state.Error
+		}
+	default:
+		status.Message = "exited"
+	}
+	return status, nil
+}
+
+func (b *kataBackend) consoleLog(ctx context.Context, tenantID string) (string, error) {
+	out, err := b.run(ctx, "logs", "--tail", "100", containerName(tenantID))
+	if err != nil {
+		return "", kataError("logs", out, err)
+	}
+	if len(out) > maxConsoleBytes {
+		out = out[len(out)-maxConsoleBytes:]
+	}
+	return string(out), nil
+}
+
+// publishSpec formats a port for nerdctl -p; without a host port nerdctl picks one
+func publishSpec(port compute.PortMapping) string {
+	spec := strconv.Itoa(port.ContainerPort)
+	if port.HostPort > 0 {
+		spec = fmt.Sprintf("%d:%d", port.HostPort, port.ContainerPort)
+	}
+	if port.Protocol == "udp" {
+		spec += "/udp"
+	}
+	return spec
+}
+
+// kataError turns a failed nerdctl command into an error, marking a missing container
+// and an unreachable containerd
+func kataError(command string, out []byte, err error) error {
+	message := strings.TrimSpace(string(out))
+	if message == "" {
+		message = err.Error()
+	}
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "no such container"), strings.Contains(lower, "not found"):
+		return fmt.Errorf("%w: nerdctl %s: %s", compute.ErrTenantNotFound, command, message)
+	case strings.Contains(lower, "connection refused"), strings.Contains(lower, "no such file or directory"):
+		return fmt.Errorf("%w: nerdctl %s: %s", compute.ErrProviderUnavailable, command, message)
+	default:
+		return fmt.Errorf("nerdctl %s: %s", command, message)
+	}
+}
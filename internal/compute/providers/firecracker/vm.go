@@ -0,0 +1,401 @@
+package firecracker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+const (
+	socketFile  = "firecracker.sock"
+	consoleFile = "console.log"
+	pidFile     = "firecracker.pid"
+	rootfsFile  = "rootfs.ext4"
+
+	// socketWait bounds how long a new Firecracker process has to open its API socket
+	socketWait = 5 * time.Second
+
+	// stopTimeout is how long a guest is given to shut down before the VM is killed
+	stopTimeout = 10 * time.Second
+
+	// maxConsoleBytes caps how much of the console log is returned
+	maxConsoleBytes = 4096
+)
+
+// signalZero checks a process exists without signalling it
+const signalZero = syscall.Signal(0)
+
+// vmIDPattern matches characters Firecracker rejects in instance IDs
+var vmIDPattern = regexp.MustCompile(`[^A-Za-z0-9-]`)
+
+// machine is a tenant microVM to launch
+type machine struct {
+	TenantID  string
+	Config    *ComputeConfig
+	VCPUs     int
+	MemoryMiB int
+	Ports     []compute.PortMapping
+	// Labels are the tenant's managed labels; only the Kata backend applies them
+	Labels map[string]string
+}
+
+// vmStatus is the observed state of a tenant's VM
+type vmStatus struct {
+	State compute.ComputeState
+
+	// Message explains a VM that is not running
+	Message string
+
+	Metadata map[string]string
+}
+
+// backend launches and manages microVMs. Methods other than start work from what is on the
+// host, so a restarted worker can still stop and report on VMs it launched earlier.
+type backend interface {
+	start(ctx context.Context, vm *machine) (map[string]string, error)
+
+	// stop removes the tenant's VM; a missing VM is not an error
+	stop(ctx context.Context, tenantID string) error
+
+	// status returns ErrTenantNotFound when the tenant has no VM
+	status(ctx context.Context, tenantID string) (*vmStatus, error)
+
+	// consoleLog returns the tail of the VM's console output
+	consoleLog(ctx context.Context, tenantID string) (string, error)
+}
+
+// firecrackerBackend runs one Firecracker process per tenant, configured through its API socket.
+// Each tenant has a directory under stateDir holding the socket, pid file, console log and,
+// unless the root filesystem is read-only, the tenant's copy of it.
+type firecrackerBackend struct {
+	binary   string
+	stateDir string
+	logger   *zap.Logger
+}
+
+func (b *firecrackerBackend) dir(tenantID string) string {
+	return filepath.Join(b.stateDir, vmID(tenantID))
+}
+
+// vmID derives a Firecracker instance ID from a tenant ID
+func vmID(tenantID string) string {
+	id := vmIDPattern.ReplaceAllString(tenantID, "-")
+	if len(id) > 64 {
+		id = id[:64]
+	}
+	return id
+}
+
+func (b *firecrackerBackend) start(ctx context.Context, vm *machine) (map[string]string, error) {
+	cfg := vm.Config
+	for _, path := range []string{cfg.KernelImagePath, cfg.RootfsPath} {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("%w: %v", compute.ErrInvalidConfig, err)
+		}
+	}
+
+	dir := b.dir(vm.TenantID)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create vm state directory: %w", err)
+	}
+
+	rootfs := cfg.RootfsPath
+	if !cfg.ReadOnlyRootfs {
+		// Tenants never share a writable disk
+		rootfs = filepath.Join(dir, rootfsFile)
+		if err := copyFile(cfg.RootfsPath, rootfs); err != nil {
+			return nil, fmt.Errorf("copy rootfs: %w", err)
+		}
+	}
+
+	socket := filepath.Join(dir, socketFile)
+	if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("remove stale api socket: %w", err)
+	}
+	console, err := os.OpenFile(filepath.Join(dir, consoleFile), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("open console log: %w", err)
+	}
+
+	// The VM must outlive the request that started it, so it is not bound to ctx.
+	// The serial console is the process's stdout.
+	cmd := exec.Command(b.binary, "--api-sock", socket, "--id", vmID(vm.TenantID))
+	cmd.Stdout = console
+	cmd.Stderr = console
+	if err := cmd.Start(); err != nil {
+		console.Close()
+		return nil, fmt.Errorf("start firecracker: %w", err)
+	}
+	go func() {
+		// Reap the process so a stopped VM does not linger as a zombie that still looks alive
+		cmd.Wait()
+		console.Close()
+	}()
+	if err := os.WriteFile(filepath.Join(dir, pidFile), []byte(strconv.Itoa(cmd.Process.Pid)), 0o640); err != nil {
+		cmd.Process.Kill()
+		return nil, fmt.Errorf("write pid file: %w", err)
+	}
+
+	if err := b.configure(ctx, socket, vm, rootfs); err != nil {
+		cmd.Process.Kill()
+		return nil, err
+	}
+
+	b.logger.Info("microvm started",
+		zap.String("tenant_id", vm.TenantID),
+		zap.Int("pid", cmd.Process.Pid),
+		zap.Int("vcpus", vm.VCPUs),
+		zap.Int("memory_mib", vm.MemoryMiB))
+
+	return map[string]string{
+		"vm_id":       vmID(vm.TenantID),
+		"pid":         strconv.Itoa(cmd.Process.Pid),
+		"console_log": filepath.Join(dir, consoleFile),
+	}, nil
+}
+
+// configure sizes the VM, attaches its kernel, disk and network, and boots it
+func (b *firecrackerBackend) configure(ctx context.Context, socket string, vm *machine, rootfs string) error {
+	if err := waitForSocket(ctx, socket); err != nil {
+		return err
+	}
+	api := newAPIClient(socket)
+
+	bootArgs := vm.Config.BootArgs
+	if bootArgs == "" {
+		bootArgs = defaultBootArgs
+	}
+	requests := []struct {
+		path string
+		body interface{}
+	}{
+		{"/machine-config", map[string]interface{}{"vcpu_count": vm.VCPUs, "mem_size_mib": vm.MemoryMiB}},
+		{"/boot-source", map[string]interface{}{"kernel_image_path": vm.Config.KernelImagePath, "boot_args": bootArgs}},
+		{"/drives/rootfs", map[string]interface{}{
+			"drive_id":       "rootfs",
+			"path_on_host":   rootfs,
+			"is_root_device": true,
+			"is_read_only":   vm.Config.ReadOnlyRootfs,
+		}},
+	}
+	if network := vm.Config.Network; network != nil {
+		iface := map[string]interface{}{"iface_id": "eth0", "host_dev_name": network.TapDevice}
+		if network.GuestMAC != "" {
+			iface["guest_mac"] = network.GuestMAC
+		}
+		requests = append(requests, struct {
+			path string
+			body interface{}
+		}{"/network-interfaces/eth0", iface})
+	}
+	requests = append(requests, struct {
+		path string
+		body interface{}
+	}{"/actions", map[string]string{"action_type": "InstanceStart"}})
+
+	for _, request := range requests {
+		if err := api.put(ctx, request.path, request.body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *firecrackerBackend) stop(ctx context.Context, tenantID string) error {
+	dir := b.dir(tenantID)
+	if process, ok := b.process(tenantID); ok {
+		// Ask the guest to shut down, then kill the VM if it has not exited in time.
+		// CtrlAltDel is x86 only, so a failure here just means going straight to the kill.
+		api := newAPIClient(filepath.Join(dir, socketFile))
+		if err := api.put(ctx, "/actions", map[string]string{"action_type": "SendCtrlAltDel"}); err == nil {
+			waitForExit(ctx, process, stopTimeout)
+		}
+		if alive(process) {
+			if err := process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+				return fmt.Errorf("kill firecracker: %w", err)
+			}
+		}
+		b.logger.Info("microvm stopped", zap.String("tenant_id", tenantID), zap.Int("pid", process.Pid))
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("remove vm state directory: %w", err)
+	}
+	return nil
+}
+
+func (b *firecrackerBackend) status(ctx context.Context, tenantID string) (*vmStatus, error) {
+	dir := b.dir(tenantID)
+	if _, err := os.Stat(filepath.Join(dir, pidFile)); err != nil {
+		return nil, fmt.Errorf("%w: %s", compute.ErrTenantNotFound, tenantID)
+	}
+	status := &vmStatus{
+		State:    compute.ComputeStateStopped,
+		Metadata: map[string]string{"vm_id": vmID(tenantID), "console_log": filepath.Join(dir, consoleFile)},
+	}
+	if process, ok := b.process(tenantID); ok {
+		status.State = compute.ComputeStateRunning
+		status.Metadata["pid"] = strconv.Itoa(process.Pid)
+		return status, nil
+	}
+	status.Message = "microVM exited"
+	return status, nil
+}
+
+func (b *firecrackerBackend) consoleLog(ctx context.Context, tenantID string) (string, error) {
+	return tailFile(filepath.Join(b.dir(tenantID), consoleFile), maxConsoleBytes)
+}
+
+// process returns the tenant's running Firecracker process
+func (b *firecrackerBackend) process(tenantID string) (*os.Process, bool) {
+	raw, err := os.ReadFile(filepath.Join(b.dir(tenantID), pidFile))
+	if err != nil {
+		return nil, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(raw)))
+	if err != nil {
+		return nil, false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil || !alive(process) {
+		return nil, false
+	}
+	return process, true
+}
+
+// alive reports whether a process is still running
+func alive(process *os.Process) bool {
+	return process.Signal(signalZero) == nil
+}
+
+func waitForExit(ctx context.Context, process *os.Process, timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for alive(process) && time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+func waitForSocket(ctx context.Context, socket string) error {
+	deadline := time.Now().Add(socketWait)
+	for {
+		if _, err := os.Stat(socket); err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("firecracker did not open its api socket within %s", socketWait)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// apiClient talks to a Firecracker process's API over its unix socket
+type apiClient struct {
+	http *http.Client
+}
+
+func newAPIClient(socket string) *apiClient {
+	return &apiClient{http: &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		},
+	}}
+}
+
+// put sends a configuration or action request; Firecracker answers 204 on success
+func (c *apiClient) put(ctx context.Context, path string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", path, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, "http://firecracker"+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create %s request: %w", path, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("firecracker %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 300 {
+		return nil
+	}
+
+	var fault struct {
+		FaultMessage string `json:"fault_message"`
+	}
+	json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&fault)
+	if fault.FaultMessage == "" {
+		fault.FaultMessage = resp.Status
+	}
+	if resp.StatusCode == http.StatusBadRequest {
+		return fmt.Errorf("%w: firecracker %s: %s", compute.ErrInvalidConfig, path, fault.FaultMessage)
+	}
+	return fmt.Errorf("firecracker %s: %s", path, fault.FaultMessage)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// tailFile returns up to the last max bytes of a file
+func tailFile(path string, max int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if offset := info.Size() - max; offset > 0 {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return "", err
+		}
+	}
+	data, err := io.ReadAll(f)
+	return string(data), err
+}
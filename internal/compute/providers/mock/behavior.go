@@ -0,0 +1,104 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// Behavior simulates failures and latency. It is read from the tenant's provider config,
+// merged over the provider defaults, so tests and demos can drive retry and failure paths.
+type Behavior struct {
+	// FailProvisionTimes fails the first N provision attempts for a tenant, then succeeds
+	FailProvisionTimes int `json:"fail_provision_times,omitempty"`
+
+	// InvalidConfig, when set, permanently fails Validate and Provision with this message
+	InvalidConfig string `json:"invalid_config,omitempty"`
+
+	// MinLatency and MaxLatency delay every call by a random duration in [min, max]
+	MinLatency string `json:"min_latency,omitempty"`
+	MaxLatency string `json:"max_latency,omitempty"`
+
+	// DestroyDelay is added to Destroy, on top of any latency
+	DestroyDelay string `json:"destroy_delay,omitempty"`
+
+	minLatency   time.Duration
+	maxLatency   time.Duration
+	destroyDelay time.Duration
+}
+
+// parseBehavior merges raw over defaults and validates the result
+func parseBehavior(defaults map[string]interface{}, raw json.RawMessage) (*Behavior, error) {
+	merged, err := compute.MergeConfigJSON(defaults, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: merge provider config: %v", compute.ErrInvalidSpec, err)
+	}
+
+	behavior := &Behavior{}
+	if len(merged) > 0 {
+		if err := json.Unmarshal(merged, behavior); err != nil {
+			return nil, fmt.Errorf("%w: invalid provider config: %v", compute.ErrInvalidSpec, err)
+		}
+	}
+
+	if behavior.FailProvisionTimes < 0 {
+		return nil, fmt.Errorf("%w: fail_provision_times must be non-negative", compute.ErrInvalidSpec)
+	}
+	if behavior.minLatency, err = parseDuration(behavior.MinLatency); err != nil {
+		return nil, fmt.Errorf("%w: invalid min_latency: %v", compute.ErrInvalidSpec, err)
+	}
+	if behavior.maxLatency, err = parseDuration(behavior.MaxLatency); err != nil {
+		return nil, fmt.Errorf("%w: invalid max_latency: %v", compute.ErrInvalidSpec, err)
+	}
+	if behavior.maxLatency == 0 {
+		behavior.maxLatency = behavior.minLatency
+	}
+	if behavior.maxLatency < behavior.minLatency {
+		return nil, fmt.Errorf("%w: max_latency must not be less than min_latency", compute.ErrInvalidSpec)
+	}
+	if behavior.destroyDelay, err = parseDuration(behavior.DestroyDelay); err != nil {
+		return nil, fmt.Errorf("%w: invalid destroy_delay: %v", compute.ErrInvalidSpec, err)
+	}
+	return behavior, nil
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("must be non-negative")
+	}
+	return d, nil
+}
+
+// latency returns a random delay in [minLatency, maxLatency]
+func (b *Behavior) latency() time.Duration {
+	if b.maxLatency <= b.minLatency {
+		return b.minLatency
+	}
+	return b.minLatency + rand.N(b.maxLatency-b.minLatency+1)
+}
+
+// sleep waits for d, returning early if ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,145 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+func behaviorSpec(tenantID, providerConfig string) *compute.TenantComputeSpec {
+	return &compute.TenantComputeSpec{
+		TenantID:       tenantID,
+		ProviderType:   "mock",
+		Containers:     []compute.ContainerSpec{{Name: "app", Image: "nginx:latest"}},
+		ProviderConfig: json.RawMessage(providerConfig),
+	}
+}
+
+func TestFailProvisionTimes(t *testing.T) {
+	provider := New()
+	spec := behaviorSpec("test-tenant", `{"fail_provision_times": 2}`)
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		_, err := provider.Provision(context.Background(), spec)
+		if !errors.Is(err, compute.ErrProvisionFailed) {
+			t.Fatalf("attempt %d: expected ErrProvisionFailed, got %v", attempt, err)
+		}
+	}
+
+	result, err := provider.Provision(context.Background(), spec)
+	if err != nil {
+		t.Fatalf("expected third attempt to succeed, got %v", err)
+	}
+	if result.Status != compute.ProvisionStatusSuccess {
+		t.Errorf("expected status %s, got %s", compute.ProvisionStatusSuccess, result.Status)
+	}
+}
+
+func TestInvalidConfigFailsPermanently(t *testing.T) {
+	provider := New()
+	config := `{"invalid_config": "image is not allowed"}`
+
+	if err := provider.ValidateConfig(json.RawMessage(config)); err != nil {
+		t.Fatalf("expected ValidateConfig to accept invalid_config, got %v", err)
+	}
+
+	spec := behaviorSpec("test-tenant", config)
+	if err := provider.Validate(context.Background(), spec); !errors.Is(err, compute.ErrInvalidSpec) {
+		t.Fatalf("expected Validate to return ErrInvalidSpec, got %v", err)
+	}
+	for attempt := 1; attempt <= 3; attempt++ {
+		if _, err := provider.Provision(context.Background(), spec); !errors.Is(err, compute.ErrInvalidSpec) {
+			t.Fatalf("attempt %d: expected ErrInvalidSpec, got %v", attempt, err)
+		}
+	}
+}
+
+func TestLatencyAndSlowDestroy(t *testing.T) {
+	provider := New()
+	spec := behaviorSpec("test-tenant", `{"min_latency": "20ms", "max_latency": "40ms", "destroy_delay": "50ms"}`)
+
+	start := time.Now()
+	if _, err := provider.Provision(context.Background(), spec); err != nil {
+		t.Fatalf("Provision failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("expected provision to take at least 20ms, took %s", elapsed)
+	}
+
+	start = time.Now()
+	if err := provider.Destroy(context.Background(), "test-tenant"); err != nil {
+		t.Fatalf("Destroy failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 70*time.Millisecond {
+		t.Errorf("expected destroy to take at least 70ms, took %s", elapsed)
+	}
+}
+
+func TestLatencyHonorsContext(t *testing.T) {
+	provider := New()
+	spec := behaviorSpec("test-tenant", `{"min_latency": "1m"}`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := provider.Provision(ctx, spec); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context deadline error, got %v", err)
+	}
+}
+
+func TestBehaviorDefaults(t *testing.T) {
+	provider := NewWithDefaults(map[string]interface{}{"fail_provision_times": 1})
+	spec := behaviorSpec("test-tenant", `{"image": "nginx:latest"}`)
+
+	if _, err := provider.Provision(context.Background(), spec); !errors.Is(err, compute.ErrProvisionFailed) {
+		t.Fatalf("expected default behavior to fail first provision, got %v", err)
+	}
+	if _, err := provider.Provision(context.Background(), spec); err != nil {
+		t.Fatalf("expected second provision to succeed, got %v", err)
+	}
+
+	// Tenant config overrides the defaults
+	override := behaviorSpec("other-tenant", `{"fail_provision_times": 0}`)
+	if _, err := provider.Provision(context.Background(), override); err != nil {
+		t.Fatalf("expected override to disable failures, got %v", err)
+	}
+}
+
+func TestValidateConfigRejectsBadBehavior(t *testing.T) {
+	provider := New()
+	tests := map[string]string{
+		"negative failures": `{"fail_provision_times": -1}`,
+		"bad duration":      `{"min_latency": "soon"}`,
+		"max below min":     `{"min_latency": "2s", "max_latency": "1s"}`,
+		"wrong type":        `{"fail_provision_times": "two"}`,
+	}
+	for name, config := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := provider.ValidateConfig(json.RawMessage(config)); !errors.Is(err, compute.ErrInvalidSpec) {
+				t.Fatalf("expected ErrInvalidSpec, got %v", err)
+			}
+		})
+	}
+
+	if err := provider.ValidateConfig(json.RawMessage(`{"image": "nginx:latest", "env": {"A": "1"}}`)); err != nil {
+		t.Fatalf("expected unknown keys to be ignored, got %v", err)
+	}
+}
+
+func TestReconfigureDefaults(t *testing.T) {
+	provider := New()
+	spec := behaviorSpec("test-tenant", `{"image": "nginx:latest"}`)
+
+	if err := provider.Reconfigure(map[string]interface{}{"fail_provision_times": -1}); !errors.Is(err, compute.ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig for bad defaults, got %v", err)
+	}
+	if err := provider.Reconfigure(map[string]interface{}{"fail_provision_times": 1}); err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	if _, err := provider.Provision(context.Background(), spec); !errors.Is(err, compute.ErrProvisionFailed) {
+		t.Fatalf("expected reconfigured defaults to fail first provision, got %v", err)
+	}
+}
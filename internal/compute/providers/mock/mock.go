@@ -4,28 +4,41 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/jaxxstorm/landlord/internal/compute"
 )
 
-// Provider is an in-memory mock provider for testing
+// Provider is an in-memory mock provider for testing.
+// Failures and latency can be simulated through the tenant's provider config, see Behavior.
 type Provider struct {
-	mu      sync.RWMutex
-	tenants map[string]*tenantState
+	mu                sync.RWMutex
+	tenants           map[string]*tenantState
+	provisionAttempts map[string]int
+	defaults          map[string]interface{}
 }
 
 type tenantState struct {
 	Spec          *compute.TenantComputeSpec
 	ProvisionedAt time.Time
 	UpdatedAt     time.Time
+	Suspended     bool
+	Restarts      int
 }
 
 // New creates a new mock provider
 func New() *Provider {
+	return NewWithDefaults(nil)
+}
+
+// NewWithDefaults creates a mock provider whose Behavior defaults apply to every tenant
+func NewWithDefaults(defaults map[string]interface{}) *Provider {
 	return &Provider{
-		tenants: make(map[string]*tenantState),
+		tenants:           make(map[string]*tenantState),
+		provisionAttempts: make(map[string]int),
+		defaults:          defaults,
 	}
 }
 
@@ -36,12 +49,29 @@ func (p *Provider) Name() string {
 
 // Provision creates a new tenant in memory
 func (p *Provider) Provision(ctx context.Context, spec *compute.TenantComputeSpec) (*compute.ProvisionResult, error) {
+	behavior, err := parseBehavior(p.behaviorDefaults(), spec.ProviderConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := sleep(ctx, behavior.latency()); err != nil {
+		return nil, err
+	}
+	if behavior.InvalidConfig != "" {
+		return nil, fmt.Errorf("%w: %s", compute.ErrInvalidSpec, behavior.InvalidConfig)
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	if _, exists := p.tenants[spec.TenantID]; exists {
-		return nil, fmt.Errorf("tenant %s already exists", spec.TenantID)
+		return nil, fmt.Errorf("%w: %s", compute.ErrAlreadyProvisioned, spec.TenantID)
+	}
+
+	p.provisionAttempts[spec.TenantID]++
+	if attempt := p.provisionAttempts[spec.TenantID]; attempt <= behavior.FailProvisionTimes {
+		return nil, fmt.Errorf("%w: simulated failure %d of %d", compute.ErrProvisionFailed, attempt, behavior.FailProvisionTimes)
 	}
+	delete(p.provisionAttempts, spec.TenantID)
 
 	p.tenants[spec.TenantID] = &tenantState{
 		Spec:          spec,
@@ -73,6 +103,14 @@ func (p *Provider) Provision(ctx context.Context, spec *compute.TenantComputeSpe
 
 // Update modifies an existing tenant
 func (p *Provider) Update(ctx context.Context, tenantID string, spec *compute.TenantComputeSpec) (*compute.UpdateResult, error) {
+	behavior, err := parseBehavior(p.behaviorDefaults(), spec.ProviderConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := sleep(ctx, behavior.latency()); err != nil {
+		return nil, err
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
@@ -110,21 +148,27 @@ func (p *Provider) Update(ctx context.Context, tenantID string, spec *compute.Te
 	}, nil
 }
 
-// Destroy removes a tenant
+// Destroy removes a tenant. Like every provider's, it is idempotent: destroying a tenant that is
+// not provisioned succeeds.
 func (p *Provider) Destroy(ctx context.Context, tenantID string) error {
+	behavior := p.tenantBehavior(tenantID)
+	if err := sleep(ctx, behavior.latency()+behavior.destroyDelay); err != nil {
+		return err
+	}
+
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	if _, exists := p.tenants[tenantID]; !exists {
-		return fmt.Errorf("%w: %s", compute.ErrTenantNotFound, tenantID)
-	}
-
 	delete(p.tenants, tenantID)
 	return nil
 }
 
 // GetStatus returns current status of a tenant
 func (p *Provider) GetStatus(ctx context.Context, tenantID string) (*compute.ComputeStatus, error) {
+	if err := sleep(ctx, p.tenantBehavior(tenantID).latency()); err != nil {
+		return nil, err
+	}
+
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
@@ -133,47 +177,182 @@ func (p *Provider) GetStatus(ctx context.Context, tenantID string) (*compute.Com
 		return nil, fmt.Errorf("%w: %s", compute.ErrTenantNotFound, tenantID)
 	}
 
+	// Job tenants complete as soon as they are provisioned
+	computeState, containerState, message := compute.ComputeStateRunning, "running", "Mock container running"
+	if state.Spec.IsJob() {
+		computeState, containerState, message = compute.ComputeStateCompleted, "completed", "Mock job completed"
+	} else if state.Suspended {
+		computeState, containerState, message = compute.ComputeStateStopped, "stopped", "Mock container suspended"
+	}
+
 	containers := make([]compute.ContainerStatus, 0, len(state.Spec.Containers))
-	for _, c := range state.Spec.Containers {
+	for _, c := range state.Spec.WorkloadContainers() {
 		containers = append(containers, compute.ContainerStatus{
 			Name:         c.Name,
-			State:        "running",
-			Ready:        true,
-			RestartCount: 0,
-			Message:      "Mock container running",
+			State:        containerState,
+			Ready:        !state.Suspended,
+			RestartCount: state.Restarts,
+			Message:      message,
 		})
 	}
 
 	return &compute.ComputeStatus{
 		TenantID:     tenantID,
 		ProviderType: "mock",
-		State:        compute.ComputeStateRunning,
+		State:        computeState,
 		Containers:   containers,
 		Health:       compute.HealthStatusHealthy,
 		LastUpdated:  time.Now(),
 		Metadata:     map[string]string{"mock": "true"},
+		ResourceIDs:  map[string]string{"tenant": tenantID},
 	}, nil
 }
 
 // Validate performs provider-specific validation
 func (p *Provider) Validate(ctx context.Context, spec *compute.TenantComputeSpec) error {
-	// Mock provider accepts all valid specs
+	behavior, err := parseBehavior(p.behaviorDefaults(), spec.ProviderConfig)
+	if err != nil {
+		return err
+	}
+	if behavior.InvalidConfig != "" {
+		return fmt.Errorf("%w: %s", compute.ErrInvalidSpec, behavior.InvalidConfig)
+	}
 	return nil
 }
 
-// ValidateConfig validates provider-specific configuration
-// Mock provider accepts any configuration
+// ValidateConfig validates provider-specific configuration.
+// Unknown keys are ignored; only the Behavior fields are checked, and invalid_config is
+// left for Validate and Provision so the tenant is accepted and fails during provisioning.
 func (p *Provider) ValidateConfig(config json.RawMessage) error {
-	// Mock provider accepts all configurations
+	_, err := parseBehavior(nil, config)
+	return err
+}
+
+// tenantBehavior returns the behavior for a provisioned tenant, or the defaults if it is unknown or invalid
+func (p *Provider) tenantBehavior(tenantID string) *Behavior {
+	var raw json.RawMessage
+	p.mu.RLock()
+	if state, exists := p.tenants[tenantID]; exists {
+		raw = state.Spec.ProviderConfig
+	}
+	p.mu.RUnlock()
+
+	if behavior, err := parseBehavior(p.behaviorDefaults(), raw); err == nil {
+		return behavior
+	}
+	if behavior, err := parseBehavior(p.behaviorDefaults(), nil); err == nil {
+		return behavior
+	}
+	return &Behavior{}
+}
+
+// Reconfigure replaces the Behavior defaults applied to every tenant
+func (p *Provider) Reconfigure(defaults map[string]interface{}) error {
+	if _, err := parseBehavior(defaults, nil); err != nil {
+		return compute.Mark(err, compute.ErrInvalidConfig)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.defaults = defaults
 	return nil
 }
 
+func (p *Provider) behaviorDefaults() map[string]interface{} {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.defaults
+}
+
 // ConfigSchema returns an empty schema for mock provider.
 func (p *Provider) ConfigSchema() json.RawMessage {
 	return json.RawMessage(`{}`)
 }
 
-// ConfigDefaults returns no defaults for mock provider.
+// ConfigDefaults returns the configured behavior defaults.
 func (p *Provider) ConfigDefaults() json.RawMessage {
+	defaults := p.behaviorDefaults()
+	if len(defaults) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(defaults)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
+
+var _ compute.Inventory = (*Provider)(nil)
+
+// ListTenants returns the tenants provisioned in memory, sorted
+func (p *Provider) ListTenants(ctx context.Context) ([]string, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	tenantIDs := make([]string, 0, len(p.tenants))
+	for tenantID := range p.tenants {
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	sort.Strings(tenantIDs)
+	return tenantIDs, nil
+}
+
+var _ compute.PowerManager = (*Provider)(nil)
+
+// Restart counts a restart of a running tenant
+func (p *Provider) Restart(ctx context.Context, tenantID string) error {
+	return p.setPower(ctx, tenantID, func(state *tenantState) error {
+		if state.Suspended {
+			return fmt.Errorf("tenant %s is suspended", tenantID)
+		}
+		state.Restarts++
+		return nil
+	})
+}
+
+// Suspend marks a tenant stopped; GetStatus reports it as stopped until it is resumed
+func (p *Provider) Suspend(ctx context.Context, tenantID string) error {
+	return p.setPower(ctx, tenantID, func(state *tenantState) error {
+		state.Suspended = true
+		return nil
+	})
+}
+
+// Resume marks a suspended tenant running again
+func (p *Provider) Resume(ctx context.Context, tenantID string) error {
+	return p.setPower(ctx, tenantID, func(state *tenantState) error {
+		state.Suspended = false
+		return nil
+	})
+}
+
+func (p *Provider) setPower(ctx context.Context, tenantID string, change func(*tenantState) error) error {
+	if err := sleep(ctx, p.tenantBehavior(tenantID).latency()); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	state, exists := p.tenants[tenantID]
+	if !exists {
+		return fmt.Errorf("%w: %s", compute.ErrTenantNotFound, tenantID)
+	}
+	if err := change(state); err != nil {
+		return err
+	}
+	state.UpdatedAt = time.Now()
 	return nil
 }
+
+// RunJob simulates a job run; a command of ["false"] exits with code 1
+func (p *Provider) RunJob(ctx context.Context, tenantID string, job *compute.JobSpec) (*compute.JobResult, error) {
+	if job == nil || job.Image == "" {
+		return nil, fmt.Errorf("job image is required")
+	}
+	now := time.Now()
+	result := &compute.JobResult{StartedAt: now, CompletedAt: now}
+	if len(job.Command) == 1 && job.Command[0] == "false" {
+		result.ExitCode = 1
+	}
+	return result, nil
+}
@@ -3,10 +3,29 @@ package mock
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/pkg/computetest"
 )
 
+func TestConformance(t *testing.T) {
+	computetest.Run(t, computetest.Config{
+		NewProvider: func(t *testing.T) compute.Provider { return New() },
+		Labels: func(ctx context.Context, provider compute.Provider, tenantID string) (map[string]string, error) {
+			p := provider.(*Provider)
+			p.mu.RLock()
+			defer p.mu.RUnlock()
+			state, ok := p.tenants[tenantID]
+			if !ok {
+				return nil, compute.ErrTenantNotFound
+			}
+			return state.Spec.Labels, nil
+		},
+		PollInterval: 10 * time.Millisecond,
+	})
+}
+
 func TestProvisionTenant(t *testing.T) {
 	provider := New()
 
@@ -226,6 +245,52 @@ func TestGetStatus(t *testing.T) {
 	}
 }
 
+func TestPowerManagement(t *testing.T) {
+	ctx := context.Background()
+	provider := New()
+
+	spec := &compute.TenantComputeSpec{
+		TenantID:     "test-tenant",
+		ProviderType: "mock",
+		Containers:   []compute.ContainerSpec{{Name: "app", Image: "nginx:latest"}},
+	}
+	if _, err := provider.Provision(ctx, spec); err != nil {
+		t.Fatalf("Provision failed: %v", err)
+	}
+
+	if err := provider.Restart(ctx, "test-tenant"); err != nil {
+		t.Fatalf("Restart failed: %v", err)
+	}
+	if err := provider.Suspend(ctx, "test-tenant"); err != nil {
+		t.Fatalf("Suspend failed: %v", err)
+	}
+	status, err := provider.GetStatus(ctx, "test-tenant")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.State != compute.ComputeStateStopped || status.Containers[0].RestartCount != 1 {
+		t.Fatalf("expected a stopped tenant restarted once, got %s with %d restarts", status.State, status.Containers[0].RestartCount)
+	}
+	if err := provider.Restart(ctx, "test-tenant"); err == nil {
+		t.Fatal("expected restart of a suspended tenant to fail")
+	}
+
+	if err := provider.Resume(ctx, "test-tenant"); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	status, err = provider.GetStatus(ctx, "test-tenant")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.State != compute.ComputeStateRunning {
+		t.Fatalf("expected running after resume, got %s", status.State)
+	}
+
+	if err := provider.Suspend(ctx, "missing"); err == nil {
+		t.Fatal("expected suspend of an unknown tenant to fail")
+	}
+}
+
 func TestValidate(t *testing.T) {
 	provider := New()
 
@@ -249,3 +314,34 @@ func TestValidate(t *testing.T) {
 		t.Fatalf("Validate failed: %v", err)
 	}
 }
+
+func TestJobTenantCompletes(t *testing.T) {
+	provider := New()
+
+	spec := &compute.TenantComputeSpec{
+		TenantID:     "batch-tenant",
+		ProviderType: "mock",
+		Containers: []compute.ContainerSpec{
+			{Name: "migrate", Image: "busybox:latest", Kind: compute.ContainerKindInit},
+			{Name: "report", Image: "busybox:latest", Kind: compute.ContainerKindJob},
+		},
+	}
+
+	if _, err := provider.Provision(context.Background(), spec); err != nil {
+		t.Fatalf("Provision failed: %v", err)
+	}
+
+	status, err := provider.GetStatus(context.Background(), "batch-tenant")
+	if err != nil {
+		t.Fatalf("GetStatus failed: %v", err)
+	}
+	if status.State != compute.ComputeStateCompleted {
+		t.Errorf("expected state %s, got %s", compute.ComputeStateCompleted, status.State)
+	}
+	if !status.IsReady() {
+		t.Error("expected completed job to be ready")
+	}
+	if len(status.Containers) != 1 || status.Containers[0].Name != "report" {
+		t.Errorf("expected only the job container in status, got %+v", status.Containers)
+	}
+}
@@ -0,0 +1,104 @@
+// Package memory provides an in-memory pool slot store for tests and local harnesses.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute/providers/pool"
+)
+
+// Store implements pool.Store in memory
+type Store struct {
+	mu sync.Mutex
+	// slots maps slot names to their assignment
+	slots map[string]*pool.Assignment
+}
+
+var _ pool.Store = (*Store)(nil)
+
+// New creates an empty in-memory store
+func New() *Store {
+	return &Store{slots: make(map[string]*pool.Assignment)}
+}
+
+func (s *Store) Sync(ctx context.Context, slots []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	configured := make(map[string]bool, len(slots))
+	for _, name := range slots {
+		configured[name] = true
+		if _, exists := s.slots[name]; !exists {
+			s.slots[name] = &pool.Assignment{Slot: name}
+		}
+	}
+	for name, assignment := range s.slots {
+		if !configured[name] && assignment.TenantID == "" {
+			delete(s.slots, name)
+		}
+	}
+	return nil
+}
+
+func (s *Store) Claim(ctx context.Context, tenantID string, candidates []string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if name, ok := s.assigned(tenantID); ok {
+		return name, nil
+	}
+	for _, name := range candidates {
+		if assignment, exists := s.slots[name]; exists && assignment.TenantID == "" {
+			now := time.Now()
+			assignment.TenantID = tenantID
+			assignment.AssignedAt = &now
+			return name, nil
+		}
+	}
+	return "", pool.ErrNoFreeSlot
+}
+
+func (s *Store) Assigned(ctx context.Context, tenantID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if name, ok := s.assigned(tenantID); ok {
+		return name, nil
+	}
+	return "", pool.ErrNotAssigned
+}
+
+func (s *Store) Release(ctx context.Context, tenantID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if name, ok := s.assigned(tenantID); ok {
+		s.slots[name].TenantID = ""
+		s.slots[name].AssignedAt = nil
+	}
+	return nil
+}
+
+func (s *Store) List(ctx context.Context) ([]pool.Assignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assignments := make([]pool.Assignment, 0, len(s.slots))
+	for _, assignment := range s.slots {
+		assignments = append(assignments, *assignment)
+	}
+	sort.Slice(assignments, func(i, j int) bool { return assignments[i].Slot < assignments[j].Slot })
+	return assignments, nil
+}
+
+func (s *Store) assigned(tenantID string) (string, bool) {
+	for name, assignment := range s.slots {
+		if assignment.TenantID == tenantID {
+			return name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,458 @@
+// Package pool implements a compute provider that assigns tenants to a fixed fleet of
+// pre-provisioned slots, such as VMs or ports on a shared host, instead of creating infrastructure.
+package pool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+var (
+	// ErrNoFreeSlot is returned by Store.Claim when every candidate slot is taken
+	ErrNoFreeSlot = errors.New("no free pool slot")
+
+	// ErrNotAssigned is returned by Store.Assigned when the tenant holds no slot
+	ErrNotAssigned = errors.New("tenant holds no pool slot")
+)
+
+// probeTimeout bounds the connection attempt GetStatus makes to a slot's first port
+const probeTimeout = 2 * time.Second
+
+// Slot is a pre-provisioned place a tenant can run
+type Slot struct {
+	// Name identifies the slot; assignments are stored by name
+	Name string `json:"name"`
+
+	// Address is the slot's host name or IP
+	Address string `json:"address"`
+
+	// Ports are the tenant's service ports on Address
+	Ports []int `json:"ports,omitempty"`
+
+	// Labels let tenants select slots with compute_config.selector
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// Assignment is a slot and the tenant holding it
+type Assignment struct {
+	Slot string `json:"slot"`
+
+	// TenantID is empty when the slot is free
+	TenantID string `json:"tenant_id,omitempty"`
+
+	AssignedAt *time.Time `json:"assigned_at,omitempty"`
+}
+
+// Store persists which tenant holds which slot, so assignments survive worker restarts and are
+// shared between workers
+type Store interface {
+	// Sync records the configured slots so they can be claimed. Slots that are no longer
+	// configured are removed once free and kept while a tenant holds them.
+	Sync(ctx context.Context, slots []string) error
+
+	// Claim assigns the first free slot of candidates, in order, to the tenant.
+	// A tenant that already holds a slot keeps it, whether or not it is a candidate.
+	// Returns ErrNoFreeSlot if every candidate is taken.
+	Claim(ctx context.Context, tenantID string, candidates []string) (string, error)
+
+	// Assigned returns the slot the tenant holds
+	// Returns ErrNotAssigned if it holds none
+	Assigned(ctx context.Context, tenantID string) (string, error)
+
+	// Release frees the tenant's slot; a tenant without a slot is not an error
+	Release(ctx context.Context, tenantID string) error
+
+	// List returns every slot and its holder, sorted by slot name
+	List(ctx context.Context) ([]Assignment, error)
+}
+
+// ComputeConfig represents the pool settings stored in a tenant's compute_config
+type ComputeConfig struct {
+	// Slot pins the tenant to a named slot
+	Slot string `json:"slot,omitempty"`
+
+	// Selector limits the tenant to slots carrying all of these labels
+	Selector map[string]string `json:"selector,omitempty"`
+}
+
+var poolConfigSchema = json.RawMessage(`{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "properties": {
+    "slot": { "type": "string" },
+    "selector": {
+      "type": "object",
+      "additionalProperties": { "type": "string" }
+    }
+  },
+  "additionalProperties": true
+}`)
+
+// Provider implements the compute.Provider interface over a static pool of slots
+type Provider struct {
+	store            Store
+	logger           *zap.Logger
+	slots            []Slot
+	slotsByName      map[string]*Slot
+	defaultsMu       sync.RWMutex
+	defaultConfig    map[string]interface{}
+	defaultConfigRaw json.RawMessage
+}
+
+// New creates a pool provider over slots, recording them in store
+func New(slots []Slot, store Store, defaults map[string]interface{}, logger *zap.Logger) (*Provider, error) {
+	logger = logger.With(zap.String("component", "pool-provider"))
+
+	if len(slots) == 0 {
+		return nil, fmt.Errorf("at least one slot is required")
+	}
+	slots = append([]Slot(nil), slots...)
+	p := &Provider{
+		store:            store,
+		logger:           logger,
+		slots:            slots,
+		slotsByName:      make(map[string]*Slot, len(slots)),
+		defaultConfig:    copyConfigMap(defaults),
+		defaultConfigRaw: marshalConfigMap(defaults),
+	}
+	names := make([]string, 0, len(slots))
+	for i := range slots {
+		slot := &slots[i]
+		if slot.Name == "" || slot.Address == "" {
+			return nil, fmt.Errorf("slots[%d]: name and address are required", i)
+		}
+		if _, exists := p.slotsByName[slot.Name]; exists {
+			return nil, fmt.Errorf("slots[%d]: duplicate slot name %q", i, slot.Name)
+		}
+		p.slotsByName[slot.Name] = slot
+		names = append(names, slot.Name)
+	}
+	if err := p.validate(defaults, nil); err != nil {
+		return nil, err
+	}
+
+	if err := store.Sync(context.Background(), names); err != nil {
+		return nil, fmt.Errorf("sync pool slots: %w", err)
+	}
+
+	logger.Info("pool provider initialized", zap.Strings("slots", names))
+	return p, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "pool"
+}
+
+// Provision assigns a free slot to a tenant. A tenant that already holds a slot keeps it,
+// so a retried provision returns the same slot.
+func (p *Provider) Provision(ctx context.Context, spec *compute.TenantComputeSpec) (*compute.ProvisionResult, error) {
+	cfg, err := p.parse(spec.ProviderConfig)
+	if err != nil {
+		return nil, err
+	}
+	slot, err := p.claim(ctx, spec.TenantID, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logger.Info("pool slot assigned", zap.String("tenant_id", spec.TenantID), zap.String("slot", slot.Name))
+	return &compute.ProvisionResult{
+		TenantID:      spec.TenantID,
+		ProviderType:  p.Name(),
+		Status:        compute.ProvisionStatusSuccess,
+		ResourceIDs:   map[string]string{"slot": slot.Name, "address": slot.Address},
+		Endpoints:     endpoints(slot),
+		Message:       fmt.Sprintf("assigned slot %s", slot.Name),
+		ProvisionedAt: time.Now(),
+	}, nil
+}
+
+// Update moves a tenant to another slot when its slot no longer satisfies its compute_config
+func (p *Provider) Update(ctx context.Context, tenantID string, spec *compute.TenantComputeSpec) (*compute.UpdateResult, error) {
+	cfg, err := p.parse(spec.ProviderConfig)
+	if err != nil {
+		return nil, err
+	}
+	current, err := p.store.Assigned(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, ErrNotAssigned) {
+			return nil, fmt.Errorf("%w: %s", compute.ErrTenantNotFound, tenantID)
+		}
+		return nil, fmt.Errorf("look up pool slot: %w", err)
+	}
+
+	result := &compute.UpdateResult{
+		TenantID:     tenantID,
+		ProviderType: p.Name(),
+		Status:       compute.UpdateStatusNoChanges,
+		Changes:      []string{},
+		Message:      "No changes detected",
+		UpdatedAt:    time.Now(),
+	}
+	if slot, ok := p.slotsByName[current]; ok && matches(slot, cfg) {
+		return result, nil
+	}
+
+	if err := p.store.Release(ctx, tenantID); err != nil {
+		return nil, fmt.Errorf("release pool slot: %w", err)
+	}
+	slot, err := p.claim(ctx, tenantID, cfg)
+	if err != nil {
+		// Keep the tenant where it was rather than leave it without a slot
+		if _, restoreErr := p.store.Claim(ctx, tenantID, []string{current}); restoreErr != nil {
+			p.logger.Error("failed to restore pool slot", zap.String("tenant_id", tenantID), zap.String("slot", current), zap.Error(restoreErr))
+		}
+		return nil, err
+	}
+
+	p.logger.Info("pool slot reassigned", zap.String("tenant_id", tenantID), zap.String("from", current), zap.String("to", slot.Name))
+	result.Status = compute.UpdateStatusSuccess
+	result.Changes = append(result.Changes, fmt.Sprintf("slot changed from %s to %s", current, slot.Name))
+	result.Message = fmt.Sprintf("assigned slot %s", slot.Name)
+	return result, nil
+}
+
+// Destroy frees a tenant's slot
+func (p *Provider) Destroy(ctx context.Context, tenantID string) error {
+	// Idempotent - releasing a tenant without a slot is a no-op
+	if err := p.store.Release(ctx, tenantID); err != nil {
+		return fmt.Errorf("release pool slot: %w", err)
+	}
+	p.logger.Info("pool slot released", zap.String("tenant_id", tenantID))
+	return nil
+}
+
+// GetStatus reports the tenant's slot. The slot is healthy when its first port accepts a connection.
+func (p *Provider) GetStatus(ctx context.Context, tenantID string) (*compute.ComputeStatus, error) {
+	name, err := p.store.Assigned(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, ErrNotAssigned) {
+			return nil, fmt.Errorf("%w: %s", compute.ErrTenantNotFound, tenantID)
+		}
+		return nil, fmt.Errorf("look up pool slot: %w", err)
+	}
+
+	status := &compute.ComputeStatus{
+		TenantID:     tenantID,
+		ProviderType: p.Name(),
+		State:        compute.ComputeStateRunning,
+		Health:       compute.HealthStatusUnknown,
+		LastUpdated:  time.Now(),
+		Metadata:     map[string]string{"slot": name},
+	}
+	container := compute.ContainerStatus{Name: name, State: string(compute.ComputeStateRunning), Ready: true}
+
+	slot, ok := p.slotsByName[name]
+	switch {
+	case !ok:
+		// The slot was removed from the configuration while the tenant held it
+		status.State = compute.ComputeStateUnknown
+		container.State = string(compute.ComputeStateUnknown)
+		container.Ready = false
+		container.Message = fmt.Sprintf("slot %s is no longer configured", name)
+	case len(slot.Ports) > 0:
+		status.Metadata["address"] = slot.Address
+		if err := probe(ctx, slot); err != nil {
+			status.Health = compute.HealthStatusUnhealthy
+			container.Ready = false
+			container.Message = err.Error()
+		} else {
+			status.Health = compute.HealthStatusHealthy
+		}
+	default:
+		status.Metadata["address"] = slot.Address
+	}
+	status.Containers = []compute.ContainerStatus{container}
+	return status, nil
+}
+
+// Validate validates a compute spec against the pool
+func (p *Provider) Validate(ctx context.Context, spec *compute.TenantComputeSpec) error {
+	_, err := p.parse(spec.ProviderConfig)
+	return err
+}
+
+// ValidateConfig validates pool-specific configuration
+func (p *Provider) ValidateConfig(config json.RawMessage) error {
+	_, err := p.parse(config)
+	return err
+}
+
+// ConfigSchema returns the JSON Schema for pool compute_config.
+func (p *Provider) ConfigSchema() json.RawMessage {
+	return poolConfigSchema
+}
+
+// ConfigDefaults returns the configured default compute_config.
+func (p *Provider) ConfigDefaults() json.RawMessage {
+	p.defaultsMu.RLock()
+	defer p.defaultsMu.RUnlock()
+	return p.defaultConfigRaw
+}
+
+// Reconfigure replaces the default compute_config merged into every tenant's config
+func (p *Provider) Reconfigure(defaults map[string]interface{}) error {
+	if err := p.validate(defaults, nil); err != nil {
+		return err
+	}
+	p.defaultsMu.Lock()
+	defer p.defaultsMu.Unlock()
+	p.defaultConfig = copyConfigMap(defaults)
+	p.defaultConfigRaw = marshalConfigMap(defaults)
+	return nil
+}
+
+// Assignments lists every slot and the tenant holding it
+func (p *Provider) Assignments(ctx context.Context) ([]Assignment, error) {
+	return p.store.List(ctx)
+}
+
+func (p *Provider) defaults() map[string]interface{} {
+	p.defaultsMu.RLock()
+	defer p.defaultsMu.RUnlock()
+	return p.defaultConfig
+}
+
+func (p *Provider) parse(raw json.RawMessage) (*ComputeConfig, error) {
+	return p.parseWith(p.defaults(), raw)
+}
+
+func (p *Provider) validate(defaults map[string]interface{}, raw json.RawMessage) error {
+	_, err := p.parseWith(defaults, raw)
+	return err
+}
+
+// parseWith merges raw over defaults and checks the result can be satisfied by a configured slot
+func (p *Provider) parseWith(defaults map[string]interface{}, raw json.RawMessage) (*ComputeConfig, error) {
+	merged, err := compute.MergeConfigJSON(defaults, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: merge pool config: %v", compute.ErrInvalidConfig, err)
+	}
+	cfg := &ComputeConfig{}
+	if len(merged) > 0 {
+		if err := json.Unmarshal(merged, cfg); err != nil {
+			return nil, fmt.Errorf("%w: invalid JSON structure: %v", compute.ErrInvalidConfig, err)
+		}
+	}
+
+	if cfg.Slot != "" {
+		slot, ok := p.slotsByName[cfg.Slot]
+		if !ok {
+			return nil, fmt.Errorf("%w: slot: unknown slot %q", compute.ErrInvalidConfig, cfg.Slot)
+		}
+		if !matches(slot, cfg) {
+			return nil, fmt.Errorf("%w: slot: %q does not match selector", compute.ErrInvalidConfig, cfg.Slot)
+		}
+	} else if len(p.candidates(cfg)) == 0 {
+		return nil, fmt.Errorf("%w: selector: no slot matches %s", compute.ErrInvalidConfig, formatLabels(cfg.Selector))
+	}
+	return cfg, nil
+}
+
+// candidates returns the names of the configured slots that satisfy cfg, in configured order
+func (p *Provider) candidates(cfg *ComputeConfig) []string {
+	var names []string
+	for i := range p.slots {
+		if matches(&p.slots[i], cfg) {
+			names = append(names, p.slots[i].Name)
+		}
+	}
+	return names
+}
+
+func (p *Provider) claim(ctx context.Context, tenantID string, cfg *ComputeConfig) (*Slot, error) {
+	name, err := p.store.Claim(ctx, tenantID, p.candidates(cfg))
+	if err != nil {
+		if errors.Is(err, ErrNoFreeSlot) {
+			return nil, fmt.Errorf("%w: no free slot for tenant %s", compute.ErrQuotaExceeded, tenantID)
+		}
+		return nil, fmt.Errorf("claim pool slot: %w", err)
+	}
+	slot, ok := p.slotsByName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: tenant %s holds slot %s, which is no longer configured", compute.ErrProvisionFailed, tenantID, name)
+	}
+	return slot, nil
+}
+
+// matches reports whether a slot satisfies a tenant's slot pin and selector
+func matches(slot *Slot, cfg *ComputeConfig) bool {
+	if cfg.Slot != "" && cfg.Slot != slot.Name {
+		return false
+	}
+	for key, value := range cfg.Selector {
+		if slot.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func endpoints(slot *Slot) []compute.Endpoint {
+	endpoints := []compute.Endpoint{}
+	for _, port := range slot.Ports {
+		endpoints = append(endpoints, compute.Endpoint{
+			Type:    "tcp",
+			Address: slot.Address,
+			Port:    port,
+			URL:     fmt.Sprintf("tcp://%s", net.JoinHostPort(slot.Address, strconv.Itoa(port))),
+		})
+	}
+	return endpoints
+}
+
+// probe connects to the slot's first port
+func probe(ctx context.Context, slot *Slot) error {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(slot.Address, strconv.Itoa(slot.Ports[0])))
+	if err != nil {
+		return fmt.Errorf("slot %s is not accepting connections: %v", slot.Name, err)
+	}
+	return conn.Close()
+}
+
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func copyConfigMap(input map[string]interface{}) map[string]interface{} {
+	if len(input) == 0 {
+		return nil
+	}
+	output := make(map[string]interface{}, len(input))
+	for key, value := range input {
+		output[key] = value
+	}
+	return output
+}
+
+func marshalConfigMap(input map[string]interface{}) json.RawMessage {
+	if len(input) == 0 {
+		return nil
+	}
+	raw, err := json.Marshal(input)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
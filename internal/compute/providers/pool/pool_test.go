@@ -0,0 +1,234 @@
+package pool_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/compute/providers/pool"
+	poolmemory "github.com/jaxxstorm/landlord/internal/compute/providers/pool/memory"
+)
+
+func testSlots() []pool.Slot {
+	return []pool.Slot{
+		{Name: "vm-1", Address: "10.0.0.1", Ports: []int{8080}, Labels: map[string]string{"zone": "a"}},
+		{Name: "vm-2", Address: "10.0.0.2", Ports: []int{8080}, Labels: map[string]string{"zone": "b"}},
+		{Name: "vm-3", Address: "10.0.0.3", Ports: []int{8080}, Labels: map[string]string{"zone": "b"}},
+	}
+}
+
+func newProvider(t *testing.T, store pool.Store, slots []pool.Slot) *pool.Provider {
+	t.Helper()
+	provider, err := pool.New(slots, store, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return provider
+}
+
+func poolSpec(tenantID, config string) *compute.TenantComputeSpec {
+	return &compute.TenantComputeSpec{TenantID: tenantID, ProviderType: "pool", ProviderConfig: json.RawMessage(config)}
+}
+
+func TestProvisionAssignsFreeSlots(t *testing.T) {
+	provider := newProvider(t, poolmemory.New(), testSlots())
+	ctx := context.Background()
+
+	result, err := provider.Provision(ctx, poolSpec("tenant-1", ""))
+	if err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	if result.ResourceIDs["slot"] != "vm-1" {
+		t.Fatalf("expected vm-1, got %s", result.ResourceIDs["slot"])
+	}
+	if len(result.Endpoints) != 1 || result.Endpoints[0].URL != "tcp://10.0.0.1:8080" {
+		t.Fatalf("unexpected endpoints %+v", result.Endpoints)
+	}
+
+	// A retried provision keeps the tenant's slot
+	result, err = provider.Provision(ctx, poolSpec("tenant-1", ""))
+	if err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	if result.ResourceIDs["slot"] != "vm-1" {
+		t.Fatalf("expected a retry to keep vm-1, got %s", result.ResourceIDs["slot"])
+	}
+
+	result, err = provider.Provision(ctx, poolSpec("tenant-2", `{"selector": {"zone": "b"}}`))
+	if err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	if result.ResourceIDs["slot"] != "vm-2" {
+		t.Fatalf("expected vm-2 for zone b, got %s", result.ResourceIDs["slot"])
+	}
+	if _, err := provider.Provision(ctx, poolSpec("tenant-3", "")); err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+
+	_, err = provider.Provision(ctx, poolSpec("tenant-4", ""))
+	if !errors.Is(err, compute.ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded from a full pool, got %v", err)
+	}
+
+	if err := provider.Destroy(ctx, "tenant-2"); err != nil {
+		t.Fatalf("Destroy() error = %v", err)
+	}
+	result, err = provider.Provision(ctx, poolSpec("tenant-4", ""))
+	if err != nil {
+		t.Fatalf("expected a released slot to be reused, got %v", err)
+	}
+	if result.ResourceIDs["slot"] != "vm-2" {
+		t.Fatalf("expected vm-2, got %s", result.ResourceIDs["slot"])
+	}
+}
+
+func TestAssignmentsSurviveRestart(t *testing.T) {
+	store := poolmemory.New()
+	ctx := context.Background()
+
+	provider := newProvider(t, store, testSlots())
+	if _, err := provider.Provision(ctx, poolSpec("tenant-1", `{"slot": "vm-3"}`)); err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+
+	// A new provider over the same store, with vm-3 and vm-2 removed from the config
+	restarted := newProvider(t, store, testSlots()[:1])
+	status, err := restarted.GetStatus(ctx, "tenant-1")
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if status.State != compute.ComputeStateUnknown || status.Metadata["slot"] != "vm-3" {
+		t.Fatalf("expected the removed slot to be reported as unknown, got %+v", status)
+	}
+
+	assignments, err := restarted.Assignments(ctx)
+	if err != nil {
+		t.Fatalf("Assignments() error = %v", err)
+	}
+	if len(assignments) != 2 || assignments[0].Slot != "vm-1" || assignments[1].Slot != "vm-3" || assignments[1].TenantID != "tenant-1" {
+		t.Fatalf("expected the free removed slot to be dropped and the held one kept, got %+v", assignments)
+	}
+}
+
+func TestUpdateMovesTenant(t *testing.T) {
+	provider := newProvider(t, poolmemory.New(), testSlots())
+	ctx := context.Background()
+
+	if _, err := provider.Provision(ctx, poolSpec("tenant-1", "")); err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+
+	result, err := provider.Update(ctx, "tenant-1", poolSpec("tenant-1", `{"selector": {"zone": "a"}}`))
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if result.Status != compute.UpdateStatusNoChanges {
+		t.Fatalf("expected no changes while vm-1 matches, got %s", result.Status)
+	}
+
+	result, err = provider.Update(ctx, "tenant-1", poolSpec("tenant-1", `{"slot": "vm-3"}`))
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if result.Status != compute.UpdateStatusSuccess || len(result.Changes) != 1 || result.Changes[0] != "slot changed from vm-1 to vm-3" {
+		t.Fatalf("expected a move to vm-3, got %s %v", result.Status, result.Changes)
+	}
+
+	// Moving to a taken slot fails and leaves the tenant where it was
+	if _, err := provider.Provision(ctx, poolSpec("tenant-2", `{"slot": "vm-1"}`)); err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	if _, err := provider.Update(ctx, "tenant-1", poolSpec("tenant-1", `{"slot": "vm-1"}`)); !errors.Is(err, compute.ErrQuotaExceeded) {
+		t.Fatalf("expected ErrQuotaExceeded, got %v", err)
+	}
+	status, err := provider.GetStatus(ctx, "tenant-1")
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if status.Metadata["slot"] != "vm-3" {
+		t.Fatalf("expected tenant-1 to keep vm-3, got %s", status.Metadata["slot"])
+	}
+
+	if _, err := provider.Update(ctx, "missing", poolSpec("missing", "")); !errors.Is(err, compute.ErrTenantNotFound) {
+		t.Fatalf("expected ErrTenantNotFound, got %v", err)
+	}
+}
+
+func TestStatusProbesFirstPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	slots := []pool.Slot{{Name: "local", Address: "127.0.0.1", Ports: []int{port}}}
+	provider := newProvider(t, poolmemory.New(), slots)
+	ctx := context.Background()
+
+	if _, err := provider.GetStatus(ctx, "tenant-1"); !errors.Is(err, compute.ErrTenantNotFound) {
+		t.Fatalf("expected ErrTenantNotFound, got %v", err)
+	}
+	if _, err := provider.Provision(ctx, poolSpec("tenant-1", "")); err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+
+	status, err := provider.GetStatus(ctx, "tenant-1")
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if status.State != compute.ComputeStateRunning || status.Health != compute.HealthStatusHealthy {
+		t.Fatalf("expected a healthy slot, got %s/%s", status.State, status.Health)
+	}
+
+	listener.Close()
+	status, err = provider.GetStatus(ctx, "tenant-1")
+	if err != nil {
+		t.Fatalf("GetStatus() error = %v", err)
+	}
+	if status.Health != compute.HealthStatusUnhealthy || status.Containers[0].Ready {
+		t.Fatalf("expected an unhealthy slot once the port is closed, got %+v", status)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	provider := newProvider(t, poolmemory.New(), testSlots())
+
+	valid := []string{``, `{"slot": "vm-2"}`, `{"selector": {"zone": "b"}}`, `{"slot": "vm-2", "selector": {"zone": "b"}}`}
+	for _, config := range valid {
+		if err := provider.ValidateConfig(json.RawMessage(config)); err != nil {
+			t.Errorf("expected %q to be valid, got %v", config, err)
+		}
+	}
+
+	invalid := []string{`{"slot": "vm-9"}`, `{"selector": {"zone": "c"}}`, `{"slot": "vm-1", "selector": {"zone": "b"}}`, `{"slot": 1}`}
+	for _, config := range invalid {
+		if err := provider.ValidateConfig(json.RawMessage(config)); !errors.Is(err, compute.ErrInvalidConfig) {
+			t.Errorf("expected ErrInvalidConfig for %q, got %v", config, err)
+		}
+	}
+
+	if err := provider.Reconfigure(map[string]interface{}{"selector": map[string]interface{}{"zone": "c"}}); !errors.Is(err, compute.ErrInvalidConfig) {
+		t.Fatalf("expected defaults no slot satisfies to be rejected, got %v", err)
+	}
+}
+
+func TestNewRejectsBadSlots(t *testing.T) {
+	tests := map[string][]pool.Slot{
+		"no slots":        nil,
+		"missing address": {{Name: "vm-1"}},
+		"duplicate name":  {{Name: "vm-1", Address: "10.0.0.1"}, {Name: "vm-1", Address: "10.0.0.2"}},
+	}
+	for name, slots := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := pool.New(slots, poolmemory.New(), nil, zap.NewNop()); err == nil {
+				t.Fatalf("expected an error")
+			}
+		})
+	}
+}
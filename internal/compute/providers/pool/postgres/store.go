@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute/providers/pool"
+)
+
+// Store implements pool.Store for PostgreSQL
+type Store struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+var _ pool.Store = (*Store)(nil)
+
+// New creates a PostgreSQL pool slot store
+// Accepts interface{} to satisfy provider abstraction, type asserts to *pgxpool.Pool
+func New(db interface{}, logger *zap.Logger) (*Store, error) {
+	pgPool, ok := db.(*pgxpool.Pool)
+	if !ok {
+		return nil, fmt.Errorf("expected *pgxpool.Pool, got %T", db)
+	}
+	return &Store{
+		pool:   pgPool,
+		logger: logger.With(zap.String("component", "pool-postgres-store")),
+	}, nil
+}
+
+const insertSlotsQuery = `
+INSERT INTO compute_pool_slots (name)
+SELECT unnest($1::text[])
+ON CONFLICT (name) DO NOTHING
+`
+
+const deleteUnconfiguredSlotsQuery = `
+DELETE FROM compute_pool_slots
+WHERE tenant_id IS NULL AND NOT (name = ANY($1::text[]))
+`
+
+func (s *Store) Sync(ctx context.Context, slots []string) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, insertSlotsQuery, slots); err != nil {
+		return fmt.Errorf("insert pool slots: %w", err)
+	}
+	removed, err := tx.Exec(ctx, deleteUnconfiguredSlotsQuery, slots)
+	if err != nil {
+		return fmt.Errorf("remove pool slots: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit pool slots: %w", err)
+	}
+
+	s.logger.Debug("pool slots synced", zap.Int("slots", len(slots)), zap.Int64("removed", removed.RowsAffected()))
+	return nil
+}
+
+// claimSlotQuery takes the first free candidate in the caller's order. SKIP LOCKED lets
+// concurrent claims pass over a slot another worker is taking instead of waiting on it.
+const claimSlotQuery = `
+UPDATE compute_pool_slots
+SET tenant_id = $1, assigned_at = CURRENT_TIMESTAMP
+WHERE name = (
+    SELECT name FROM compute_pool_slots
+    WHERE tenant_id IS NULL AND name = ANY($2::text[])
+    ORDER BY array_position($2::text[], name::text)
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING name
+`
+
+func (s *Store) Claim(ctx context.Context, tenantID string, candidates []string) (string, error) {
+	if name, err := s.Assigned(ctx, tenantID); err == nil {
+		return name, nil
+	} else if !errors.Is(err, pool.ErrNotAssigned) {
+		return "", err
+	}
+
+	var name string
+	if err := s.pool.QueryRow(ctx, claimSlotQuery, tenantID, candidates).Scan(&name); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", pool.ErrNoFreeSlot
+		}
+		// A concurrent claim for the same tenant won; it keeps that slot
+		if isUniqueViolation(err) {
+			return s.Assigned(ctx, tenantID)
+		}
+		return "", fmt.Errorf("claim pool slot: %w", err)
+	}
+	return name, nil
+}
+
+func (s *Store) Assigned(ctx context.Context, tenantID string) (string, error) {
+	var name string
+	if err := s.pool.QueryRow(ctx, `SELECT name FROM compute_pool_slots WHERE tenant_id = $1`, tenantID).Scan(&name); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", pool.ErrNotAssigned
+		}
+		return "", fmt.Errorf("get pool slot: %w", err)
+	}
+	return name, nil
+}
+
+func (s *Store) Release(ctx context.Context, tenantID string) error {
+	if _, err := s.pool.Exec(ctx, `UPDATE compute_pool_slots SET tenant_id = NULL, assigned_at = NULL WHERE tenant_id = $1`, tenantID); err != nil {
+		return fmt.Errorf("release pool slot: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) List(ctx context.Context) ([]pool.Assignment, error) {
+	rows, err := s.pool.Query(ctx, `SELECT name, COALESCE(tenant_id, ''), assigned_at FROM compute_pool_slots ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list pool slots: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []pool.Assignment{}
+	for rows.Next() {
+		var assignment pool.Assignment
+		if err := rows.Scan(&assignment.Slot, &assignment.TenantID, &assignment.AssignedAt); err != nil {
+			return nil, fmt.Errorf("scan pool slot: %w", err)
+		}
+		assignments = append(assignments, assignment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate pool slots: %w", err)
+	}
+	return assignments, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
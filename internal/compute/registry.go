@@ -11,6 +11,7 @@ import (
 // Registry manages registered compute providers
 type Registry struct {
 	providers map[string]Provider
+	disabled  map[string]bool
 	mu        sync.RWMutex
 	logger    *zap.Logger
 }
@@ -19,6 +20,7 @@ type Registry struct {
 func NewRegistry(logger *zap.Logger) *Registry {
 	return &Registry{
 		providers: make(map[string]Provider),
+		disabled:  make(map[string]bool),
 		logger:    logger.With(zap.String("component", "compute-registry")),
 	}
 }
@@ -78,6 +80,69 @@ func (r *Registry) Has(providerType string) bool {
 	return exists
 }
 
+// SetEnabled enables or disables a provider for new tenants.
+// Disabled providers stay registered so existing tenants can still be updated and deleted.
+// Returns ErrProviderNotFound if provider not registered
+func (r *Registry) SetEnabled(providerType string, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.providers[providerType]; !exists {
+		return fmt.Errorf("%w: %s", ErrProviderNotFound, providerType)
+	}
+
+	if enabled {
+		delete(r.disabled, providerType)
+	} else {
+		r.disabled[providerType] = true
+	}
+	r.logger.Info("compute provider placement changed", zap.String("provider", providerType), zap.Bool("enabled", enabled))
+	return nil
+}
+
+// Enabled reports whether a registered provider accepts new tenants
+func (r *Registry) Enabled(providerType string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, exists := r.providers[providerType]
+	return exists && !r.disabled[providerType]
+}
+
+// GetForPlacement retrieves a provider that may receive new tenants
+// Returns ErrProviderNotFound if provider not registered, ErrProviderDisabled if it is disabled
+func (r *Registry) GetForPlacement(providerType string) (Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider, exists := r.providers[providerType]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, providerType)
+	}
+	if r.disabled[providerType] {
+		return nil, fmt.Errorf("%w: %s", ErrProviderDisabled, providerType)
+	}
+	return provider, nil
+}
+
+// Reconfigure replaces a provider's default compute_config
+// Returns ErrReconfigureUnsupported if the provider does not implement Reconfigurable
+func (r *Registry) Reconfigure(providerType string, defaults map[string]interface{}) error {
+	provider, err := r.Get(providerType)
+	if err != nil {
+		return err
+	}
+	reconfigurable, ok := provider.(Reconfigurable)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrReconfigureUnsupported, providerType)
+	}
+	if err := reconfigurable.Reconfigure(defaults); err != nil {
+		return err
+	}
+	r.logger.Info("compute provider reconfigured", zap.String("provider", providerType))
+	return nil
+}
+
 // GetProviderSchema returns the config schema and defaults for a provider.
 func (r *Registry) GetProviderSchema(providerType string) (schema json.RawMessage, defaults json.RawMessage, err error) {
 	provider, err := r.Get(providerType)
@@ -150,3 +150,47 @@ func TestRegistryEmptyProviderName(t *testing.T) {
 		t.Error("expected error when registering provider with empty name")
 	}
 }
+
+func TestRegistrySetEnabled(t *testing.T) {
+	registry := NewRegistry(zap.NewNop())
+	registry.Register(&testProvider{name: "test"})
+
+	if !registry.Enabled("test") {
+		t.Fatal("expected registered provider to be enabled")
+	}
+
+	if err := registry.SetEnabled("test", false); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	if registry.Enabled("test") {
+		t.Error("expected provider to be disabled")
+	}
+	if _, err := registry.GetForPlacement("test"); !errors.Is(err, ErrProviderDisabled) {
+		t.Errorf("expected ErrProviderDisabled, got: %v", err)
+	}
+	// Existing tenants still reach a disabled provider
+	if _, err := registry.Get("test"); err != nil {
+		t.Errorf("expected disabled provider to stay registered, got: %v", err)
+	}
+
+	if err := registry.SetEnabled("test", true); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	if _, err := registry.GetForPlacement("test"); err != nil {
+		t.Errorf("expected re-enabled provider to accept placement, got: %v", err)
+	}
+
+	if err := registry.SetEnabled("nonexistent", false); !errors.Is(err, ErrProviderNotFound) {
+		t.Errorf("expected ErrProviderNotFound, got: %v", err)
+	}
+}
+
+func TestRegistryReconfigureUnsupported(t *testing.T) {
+	registry := NewRegistry(zap.NewNop())
+	registry.Register(&testProvider{name: "test"})
+
+	err := registry.Reconfigure("test", map[string]interface{}{"image": "nginx"})
+	if !errors.Is(err, ErrReconfigureUnsupported) {
+		t.Errorf("expected ErrReconfigureUnsupported, got: %v", err)
+	}
+}
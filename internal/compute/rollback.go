@@ -0,0 +1,36 @@
+package compute
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// rollbackTimeout bounds tearing down a failed provision
+const rollbackTimeout = 30 * time.Second
+
+// RollbackProvision tears down what a failed provision left behind, so a retry starts from a clean
+// slate rather than failing because the tenant is already provisioned. Provisions that failed
+// because the tenant was already provisioned or its spec was rejected created nothing and are left
+// alone, as are tenants of providers that do not implement ProvisionRollbacker.
+//
+// The teardown runs on a fresh context, since the provision's may be the one that was cancelled.
+// When it fails, the returned error wraps ErrPartiallyProvisioned so callers can record the
+// leftover resources.
+func RollbackProvision(ctx context.Context, provider Provider, tenantID string, cause error) error {
+	if errors.Is(cause, ErrAlreadyProvisioned) || errors.Is(cause, ErrInvalidConfig) || errors.Is(cause, ErrInvalidSpec) {
+		return nil
+	}
+	rollbacker, ok := provider.(ProvisionRollbacker)
+	if !ok {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+	if err := rollbacker.RollbackProvision(ctx, tenantID); err != nil {
+		return fmt.Errorf("%w: rollback of tenant %s failed: %v", ErrPartiallyProvisioned, tenantID, err)
+	}
+	return nil
+}
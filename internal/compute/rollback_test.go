@@ -0,0 +1,63 @@
+package compute
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// rollbackProvider is a tracking mock that can also roll back a failed provision
+type rollbackProvider struct {
+	MockComputeProviderForTracking
+}
+
+func (m *rollbackProvider) RollbackProvision(ctx context.Context, tenantID string) error {
+	args := m.Called(ctx, tenantID)
+	return args.Error(0)
+}
+
+func TestManagerRollsBackFailedProvision(t *testing.T) {
+	provider := new(rollbackProvider)
+	registry := NewRegistry(zap.NewNop())
+	assert.NoError(t, registry.Register(provider))
+	manager := New(registry, zap.NewNop())
+
+	spec := &TenantComputeSpec{
+		TenantID:       "tenant-001",
+		ProviderType:   "mock-tracking",
+		ProviderConfig: json.RawMessage(`{}`),
+		Containers:     []ContainerSpec{{Name: "app", Image: "nginx:1.27"}},
+		Resources:      ResourceRequirements{CPU: 256, Memory: 512},
+	}
+
+	// A provision that fails partway is rolled back
+	provider.ProvisionError = errors.New("failed to start container")
+	provider.On("RollbackProvision", mock.Anything, "tenant-001").Return(nil).Once()
+	_, err := manager.ProvisionTenant(context.Background(), spec)
+	assert.EqualError(t, err, "failed to start container")
+	provider.AssertNumberOfCalls(t, "RollbackProvision", 1)
+
+	// Provisions that created nothing are left alone
+	for _, cause := range []error{ErrAlreadyProvisioned, ErrInvalidConfig} {
+		provider.ProvisionError = cause
+		_, err = manager.ProvisionTenant(context.Background(), spec)
+		assert.ErrorIs(t, err, cause)
+	}
+	provider.AssertNumberOfCalls(t, "RollbackProvision", 1)
+
+	// A rollback that fails reports the leftover resources along with the provision's error
+	provider.ProvisionError = errors.New("failed to start container")
+	provider.On("RollbackProvision", mock.Anything, "tenant-001").Return(errors.New("container is in use")).Once()
+	_, err = manager.ProvisionTenant(context.Background(), spec)
+	assert.ErrorIs(t, err, ErrPartiallyProvisioned)
+	assert.ErrorContains(t, err, "failed to start container")
+	assert.ErrorContains(t, err, "container is in use")
+
+	// Providers that cannot roll back are not asked to
+	assert.NoError(t, RollbackProvision(context.Background(), &testProvider{name: "plain"}, "tenant-001", errors.New("failed")))
+}
@@ -59,6 +59,72 @@ type ContainerSpec struct {
 
 	// Resources specifies container-specific resource limits
 	Resources *ResourceRequirements `json:"resources,omitempty"`
+
+	// Kind is how the container runs; empty means ContainerKindService
+	Kind ContainerKind `json:"kind,omitempty"`
+
+	// Platform is the OS and architecture to run, such as "linux/arm64"; empty means the host's own
+	Platform string `json:"platform,omitempty"`
+}
+
+// ContainerKind describes how a container runs
+type ContainerKind string
+
+const (
+	// ContainerKindService runs until stopped and is expected to keep running
+	ContainerKindService ContainerKind = "service"
+
+	// ContainerKindInit runs to completion, in order, before the workload containers start
+	ContainerKindInit ContainerKind = "init"
+
+	// ContainerKindJob runs to completion; the tenant is done when it exits successfully
+	ContainerKindJob ContainerKind = "job"
+)
+
+// IsValid checks if a kind is known; empty is treated as ContainerKindService
+func (k ContainerKind) IsValid() bool {
+	switch k {
+	case "", ContainerKindService, ContainerKindInit, ContainerKindJob:
+		return true
+	default:
+		return false
+	}
+}
+
+// InitContainers returns the spec's init containers in run order
+func (s *TenantComputeSpec) InitContainers() []ContainerSpec {
+	var containers []ContainerSpec
+	for _, c := range s.Containers {
+		if c.Kind == ContainerKindInit {
+			containers = append(containers, c)
+		}
+	}
+	return containers
+}
+
+// WorkloadContainers returns the spec's service and job containers
+func (s *TenantComputeSpec) WorkloadContainers() []ContainerSpec {
+	var containers []ContainerSpec
+	for _, c := range s.Containers {
+		if c.Kind != ContainerKindInit {
+			containers = append(containers, c)
+		}
+	}
+	return containers
+}
+
+// IsJob reports whether the tenant runs to completion rather than staying running
+func (s *TenantComputeSpec) IsJob() bool {
+	workloads := s.WorkloadContainers()
+	if len(workloads) == 0 {
+		return false
+	}
+	for _, c := range workloads {
+		if c.Kind != ContainerKindJob {
+			return false
+		}
+	}
+	return true
 }
 
 // PortMapping defines how a container port is exposed
@@ -104,17 +170,28 @@ type HealthCheckConfig struct {
 }
 
 // ResourceRequirements defines compute resource limits
+// Zero values mean no limit.
 type ResourceRequirements struct {
 	// CPU in millicores (1000 = 1 CPU)
 	CPU int `json:"cpu"`
 
-	// Memory in megabytes
+	// Memory in mebibytes
 	Memory int `json:"memory"`
 
-	// Storage in megabytes (ephemeral)
+	// Storage in mebibytes (ephemeral)
 	Storage int `json:"storage,omitempty"`
 }
 
+// NanoCPUs returns the CPU limit in billionths of a CPU
+func (r ResourceRequirements) NanoCPUs() int64 {
+	return int64(r.CPU) * 1_000_000
+}
+
+// MemoryBytes returns the memory limit in bytes
+func (r ResourceRequirements) MemoryBytes() int64 {
+	return int64(r.Memory) * 1024 * 1024
+}
+
 // NetworkConfig defines networking parameters
 type NetworkConfig struct {
 	// PublicIP whether to assign a public IP
@@ -256,6 +333,10 @@ type ComputeStatus struct {
 
 	// Metadata provider-specific status information
 	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// ResourceIDs maps resource types to the provider-specific IDs currently backing the tenant,
+	// using the same keys as ProvisionResult.ResourceIDs
+	ResourceIDs map[string]string `json:"resource_ids,omitempty"`
 }
 
 // ComputeState represents deployment state
@@ -268,8 +349,19 @@ const (
 	ComputeStateStopping ComputeState = "stopping"
 	ComputeStateFailed   ComputeState = "failed"
 	ComputeStateUnknown  ComputeState = "unknown"
+
+	// ComputeStateCompleted means a job tenant ran to completion successfully
+	ComputeStateCompleted ComputeState = "completed"
 )
 
+// IsReady reports whether the tenant's compute is serving, or for a job has completed successfully
+func (s *ComputeStatus) IsReady() bool {
+	if s == nil || s.Health == HealthStatusUnhealthy {
+		return false
+	}
+	return s.State == ComputeStateRunning || s.State == ComputeStateCompleted
+}
+
 // ContainerStatus shows state of a single container
 type ContainerStatus struct {
 	// Name of the container
@@ -407,6 +499,12 @@ type CallbackPayload struct {
 
 	// IsRetriable indicates if the operation can be retried
 	IsRetriable bool `json:"is_retriable"`
+
+	// SchemaVersion is the compute-callback schema version the payload was written against
+	SchemaVersion string `json:"schema_version,omitempty"`
+
+	// ComponentVersion is the landlord version of the process that ran the compute execution
+	ComponentVersion string `json:"component_version,omitempty"`
 }
 
 // CallbackOptions controls callback delivery behavior
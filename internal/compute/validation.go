@@ -38,6 +38,30 @@ func ValidateComputeSpec(spec *TenantComputeSpec) error {
 		return errors.New("at least one container required")
 	}
 
+	// Validate container kinds
+	var services, jobs int
+	for i, c := range spec.Containers {
+		if !c.Kind.IsValid() {
+			return fmt.Errorf("container[%d]: kind must be 'service', 'init', or 'job'", i)
+		}
+		switch c.Kind {
+		case ContainerKindInit:
+			if len(c.Ports) > 0 {
+				return fmt.Errorf("container[%d]: init containers cannot expose ports", i)
+			}
+		case ContainerKindJob:
+			jobs++
+		default:
+			services++
+		}
+	}
+	if services+jobs == 0 {
+		return errors.New("at least one service or job container required")
+	}
+	if services > 0 && jobs > 0 {
+		return errors.New("service and job containers cannot be mixed")
+	}
+
 	// Validate container names are unique
 	names := make(map[string]bool)
 	for i, c := range spec.Containers {
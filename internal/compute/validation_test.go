@@ -0,0 +1,74 @@
+package compute
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateComputeSpecContainerKinds(t *testing.T) {
+	container := func(name string, kind ContainerKind) ContainerSpec {
+		return ContainerSpec{Name: name, Image: "busybox:latest", Kind: kind}
+	}
+
+	tests := []struct {
+		name       string
+		containers []ContainerSpec
+		wantErr    string
+	}{
+		{name: "service", containers: []ContainerSpec{container("app", "")}},
+		{name: "init before service", containers: []ContainerSpec{container("migrate", ContainerKindInit), container("app", ContainerKindService)}},
+		{name: "init before job", containers: []ContainerSpec{container("fetch", ContainerKindInit), container("report", ContainerKindJob)}},
+		{name: "unknown kind", containers: []ContainerSpec{container("app", "daemon")}, wantErr: "kind must be"},
+		{name: "only init", containers: []ContainerSpec{container("migrate", ContainerKindInit)}, wantErr: "service or job container required"},
+		{name: "service and job", containers: []ContainerSpec{container("app", ContainerKindService), container("report", ContainerKindJob)}, wantErr: "cannot be mixed"},
+		{
+			name: "init with ports",
+			containers: []ContainerSpec{
+				{Name: "migrate", Image: "busybox:latest", Kind: ContainerKindInit, Ports: []PortMapping{{ContainerPort: 80}}},
+				container("app", ""),
+			},
+			wantErr: "cannot expose ports",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &TenantComputeSpec{
+				TenantID:     "tenant-1",
+				ProviderType: "mock",
+				Containers:   tt.containers,
+				Resources:    ResourceRequirements{CPU: 256, Memory: 256},
+			}
+			err := ValidateComputeSpec(spec)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestComputeStatusIsReady(t *testing.T) {
+	tests := []struct {
+		status *ComputeStatus
+		want   bool
+	}{
+		{status: &ComputeStatus{State: ComputeStateRunning, Health: HealthStatusHealthy}, want: true},
+		{status: &ComputeStatus{State: ComputeStateCompleted, Health: HealthStatusHealthy}, want: true},
+		{status: &ComputeStatus{State: ComputeStateRunning, Health: HealthStatusUnhealthy}, want: false},
+		{status: &ComputeStatus{State: ComputeStateStopped, Health: HealthStatusUnknown}, want: false},
+		{status: &ComputeStatus{State: ComputeStateFailed, Health: HealthStatusUnhealthy}, want: false},
+		{status: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.status.IsReady(); got != tt.want {
+			t.Errorf("IsReady(%+v) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
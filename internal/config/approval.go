@@ -0,0 +1,30 @@
+package config
+
+import "fmt"
+
+// ApprovalConfig configures which destructive tenant operations need a second principal's approval
+type ApprovalConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Operations are the operations that need approval on protected tenants: archive, delete (default both)
+	Operations []string `mapstructure:"operations"`
+
+	// ProtectedLabel marks a tenant as protected when set to "true" (default "protected")
+	ProtectedLabel string `mapstructure:"protected_label"`
+
+	// Selector protects every tenant whose labels match all of its entries
+	Selector map[string]string `mapstructure:"selector"`
+}
+
+// Validate validates approval configuration
+func (c *ApprovalConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	for i, op := range c.Operations {
+		if op != "archive" && op != "delete" {
+			return fmt.Errorf("operations[%d]: unknown operation %q, must be archive or delete", i, op)
+		}
+	}
+	return nil
+}
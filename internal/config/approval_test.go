@@ -0,0 +1,21 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApprovalConfigValidate(t *testing.T) {
+	disabled := ApprovalConfig{Operations: []string{"scale"}}
+	assert.NoError(t, disabled.Validate())
+
+	valid := ApprovalConfig{Enabled: true, Operations: []string{"archive", "delete"}, Selector: map[string]string{"env": "prod"}}
+	assert.NoError(t, valid.Validate())
+
+	defaults := ApprovalConfig{Enabled: true}
+	assert.NoError(t, defaults.Validate())
+
+	unknown := ApprovalConfig{Enabled: true, Operations: []string{"archive", "update"}}
+	assert.ErrorContains(t, unknown.Validate(), `operations[1]: unknown operation "update"`)
+}
@@ -0,0 +1,64 @@
+package config
+
+import "fmt"
+
+// AuthConfig configures API authentication. With no API keys the API is unauthenticated
+// and every caller may act on every organization.
+type AuthConfig struct {
+	APIKeys []APIKeyConfig `mapstructure:"api_keys"`
+}
+
+// APIKeyConfig is a static API key and the organization and projects it is scoped to
+type APIKeyConfig struct {
+	// Name identifies the principal in logs and managed fields
+	Name string `mapstructure:"name"`
+
+	// Key is sent as a bearer token or in the X-API-Key header
+	Key string `mapstructure:"key"`
+
+	// Admin keys may act on every organization and manage providers
+	Admin bool `mapstructure:"admin"`
+
+	// Organization scopes a non-admin key; required unless Admin is set
+	Organization string `mapstructure:"organization"`
+
+	// Projects limits the key to these projects of its organization; empty allows all of them
+	Projects []string `mapstructure:"projects"`
+}
+
+// Enabled reports whether any API keys are configured
+func (a *AuthConfig) Enabled() bool {
+	return len(a.APIKeys) > 0
+}
+
+// Validate validates auth configuration
+func (a *AuthConfig) Validate() error {
+	names := make(map[string]bool)
+	keys := make(map[string]bool)
+	for i, key := range a.APIKeys {
+		if key.Name == "" {
+			return fmt.Errorf("api_keys[%d]: name is required", i)
+		}
+		if names[key.Name] {
+			return fmt.Errorf("api_keys[%d]: duplicate name %q", i, key.Name)
+		}
+		names[key.Name] = true
+		if len(key.Key) < 16 {
+			return fmt.Errorf("api_keys[%d] (%s): key must be at least 16 characters", i, key.Name)
+		}
+		if keys[key.Key] {
+			return fmt.Errorf("api_keys[%d] (%s): key is already used by another principal", i, key.Name)
+		}
+		keys[key.Key] = true
+		if key.Admin {
+			if key.Organization != "" || len(key.Projects) > 0 {
+				return fmt.Errorf("api_keys[%d] (%s): admin keys cannot be scoped to an organization or projects", i, key.Name)
+			}
+			continue
+		}
+		if key.Organization == "" {
+			return fmt.Errorf("api_keys[%d] (%s): organization is required for non-admin keys", i, key.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthConfigValidate(t *testing.T) {
+	valid := AuthConfig{APIKeys: []APIKeyConfig{
+		{Name: "ops", Key: "0123456789abcdef", Admin: true},
+		{Name: "web-team", Key: "fedcba9876543210", Organization: "acme", Projects: []string{"web"}},
+	}}
+	assert.NoError(t, valid.Validate())
+	assert.True(t, valid.Enabled())
+	assert.False(t, (&AuthConfig{}).Enabled())
+
+	cases := map[string]struct {
+		keys []APIKeyConfig
+		err  string
+	}{
+		"missing name":   {[]APIKeyConfig{{Key: "0123456789abcdef", Admin: true}}, "name is required"},
+		"short key":      {[]APIKeyConfig{{Name: "ops", Key: "short", Admin: true}}, "at least 16 characters"},
+		"missing org":    {[]APIKeyConfig{{Name: "ops", Key: "0123456789abcdef"}}, "organization is required"},
+		"scoped admin":   {[]APIKeyConfig{{Name: "ops", Key: "0123456789abcdef", Admin: true, Organization: "acme"}}, "admin keys cannot be scoped"},
+		"duplicate name": {[]APIKeyConfig{{Name: "ops", Key: "0123456789abcdef", Admin: true}, {Name: "ops", Key: "fedcba9876543210", Admin: true}}, "duplicate name"},
+		"duplicate key":  {[]APIKeyConfig{{Name: "ops", Key: "0123456789abcdef", Admin: true}, {Name: "web", Key: "0123456789abcdef", Organization: "acme"}}, "already used"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, (&AuthConfig{APIKeys: tc.keys}).Validate(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// callbackSourceName is the form of a callback source name, as sent in X-Landlord-Source
+var callbackSourceName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// CallbackAuthConfig configures how inbound callbacks, such as the status updates external
+// orchestrators post to /v1/workflow-callbacks, are authenticated
+type CallbackAuthConfig struct {
+	// Tolerance is how far a callback's signature timestamp may be from the server's clock. Signatures
+	// are remembered for this long, so a callback replayed within it is rejected too.
+	Tolerance time.Duration `mapstructure:"tolerance" env:"CALLBACK_AUTH_TOLERANCE" default:"5m"`
+
+	// Sources are the systems allowed to send callbacks, each with its own secrets
+	Sources []CallbackSourceConfig `mapstructure:"sources"`
+}
+
+// CallbackSourceConfig is one system allowed to send callbacks
+type CallbackSourceConfig struct {
+	// Name identifies the source in the X-Landlord-Source header and in metrics
+	Name string `mapstructure:"name"`
+
+	// Secrets verify the source's HMAC signatures. While rotating, list the new secret alongside the old one.
+	Secrets []string `mapstructure:"secrets"`
+}
+
+// Validate validates callback authentication configuration
+func (c *CallbackAuthConfig) Validate() error {
+	if c.Tolerance < 0 {
+		return fmt.Errorf("tolerance must be non-negative")
+	}
+	seen := map[string]bool{}
+	for i, source := range c.Sources {
+		if !callbackSourceName.MatchString(source.Name) {
+			return fmt.Errorf("sources[%d]: name must be lowercase letters, digits and hyphens, got %q", i, source.Name)
+		}
+		if seen[source.Name] {
+			return fmt.Errorf("sources[%d]: duplicate name %q", i, source.Name)
+		}
+		seen[source.Name] = true
+		if len(source.Secrets) == 0 {
+			return fmt.Errorf("sources[%d]: at least one secret is required", i)
+		}
+		for _, secret := range source.Secrets {
+			if len(secret) < 16 {
+				return fmt.Errorf("sources[%d]: secrets must be at least 16 characters", i)
+			}
+		}
+	}
+	return nil
+}
+
+// CallbackSources returns the configured callback sources, plus the webhook workflow provider's
+// secret as the "webhook" source unless a source of that name is configured
+func (c *Config) CallbackSources() []CallbackSourceConfig {
+	sources := append([]CallbackSourceConfig(nil), c.CallbackAuth.Sources...)
+	if c.Workflow.Webhook.Secret == "" {
+		return sources
+	}
+	for _, source := range sources {
+		if source.Name == "webhook" {
+			return sources
+		}
+	}
+	return append(sources, CallbackSourceConfig{Name: "webhook", Secrets: []string{c.Workflow.Webhook.Secret}})
+}
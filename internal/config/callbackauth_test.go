@@ -0,0 +1,49 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallbackAuthConfigValidate(t *testing.T) {
+	assert.NoError(t, (&CallbackAuthConfig{}).Validate())
+
+	valid := CallbackAuthConfig{Sources: []CallbackSourceConfig{
+		{Name: "airflow", Secrets: []string{"0123456789abcdef", "fedcba9876543210"}},
+	}}
+	assert.NoError(t, valid.Validate())
+
+	for name, tc := range map[string]struct {
+		cfg  CallbackAuthConfig
+		want string
+	}{
+		"negative tolerance": {CallbackAuthConfig{Tolerance: -1}, "non-negative"},
+		"invalid name":       {CallbackAuthConfig{Sources: []CallbackSourceConfig{{Name: "Airflow", Secrets: []string{"0123456789abcdef"}}}}, "lowercase"},
+		"duplicate name": {CallbackAuthConfig{Sources: []CallbackSourceConfig{
+			{Name: "airflow", Secrets: []string{"0123456789abcdef"}},
+			{Name: "airflow", Secrets: []string{"fedcba9876543210"}},
+		}}, "duplicate"},
+		"no secrets":   {CallbackAuthConfig{Sources: []CallbackSourceConfig{{Name: "airflow"}}}, "at least one secret"},
+		"short secret": {CallbackAuthConfig{Sources: []CallbackSourceConfig{{Name: "airflow", Secrets: []string{"short"}}}}, "at least 16 characters"},
+	} {
+		assert.ErrorContains(t, tc.cfg.Validate(), tc.want, name)
+	}
+}
+
+func TestCallbackSources(t *testing.T) {
+	cfg := &Config{}
+	assert.Empty(t, cfg.CallbackSources())
+
+	// The webhook provider's secret authenticates its orchestrator's callbacks
+	cfg.Workflow.Webhook.Secret = "0123456789abcdef"
+	cfg.CallbackAuth.Sources = []CallbackSourceConfig{{Name: "airflow", Secrets: []string{"fedcba9876543210"}}}
+	assert.Equal(t, []CallbackSourceConfig{
+		{Name: "airflow", Secrets: []string{"fedcba9876543210"}},
+		{Name: "webhook", Secrets: []string{"0123456789abcdef"}},
+	}, cfg.CallbackSources())
+
+	// A configured webhook source takes over, so its secret can be rotated
+	cfg.CallbackAuth.Sources = []CallbackSourceConfig{{Name: "webhook", Secrets: []string{"0123456789abcdef", "fedcba9876543210"}}}
+	assert.Equal(t, cfg.CallbackAuth.Sources, cfg.CallbackSources())
+}
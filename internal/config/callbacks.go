@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// CallbackTransportDirect posts compute callbacks to the workflow provider as they happen
+	CallbackTransportDirect = "direct"
+
+	// CallbackTransportOutbox stores compute callbacks in the database for the worker to deliver,
+	// so they survive workflow engine downtime
+	CallbackTransportOutbox = "outbox"
+)
+
+// CallbackConfig configures how compute callbacks reach each workflow provider
+type CallbackConfig struct {
+	// Transports maps workflow provider names to their callback transport, direct or outbox.
+	// Providers that are not listed use direct.
+	Transports map[string]string `mapstructure:"transports"`
+
+	// PollInterval is how often the worker looks for queued callbacks (default 1s)
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+
+	// BatchSize is the most queued callbacks the worker claims at once (default 50)
+	BatchSize int `mapstructure:"batch_size"`
+
+	// ClaimTimeout is how long a claimed callback is held by one worker before another may
+	// deliver it, so callbacks claimed by a worker that stopped are not lost (default 1m)
+	ClaimTimeout time.Duration `mapstructure:"claim_timeout"`
+
+	// MaxAttempts is how many deliveries are tried before a queued callback is given up on (default 10)
+	MaxAttempts int `mapstructure:"max_attempts"`
+}
+
+// Transport returns the callback transport of the named workflow provider
+func (c *CallbackConfig) Transport(provider string) string {
+	if transport := c.Transports[provider]; transport != "" {
+		return transport
+	}
+	return CallbackTransportDirect
+}
+
+// OutboxProviders returns the workflow providers whose callbacks go through the outbox, sorted
+func (c *CallbackConfig) OutboxProviders() []string {
+	var providers []string
+	for provider := range c.Transports {
+		if c.Transport(provider) == CallbackTransportOutbox {
+			providers = append(providers, provider)
+		}
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// Validate validates callback configuration
+func (c *CallbackConfig) Validate() error {
+	for provider, transport := range c.Transports {
+		switch transport {
+		case "", CallbackTransportDirect, CallbackTransportOutbox:
+		default:
+			return fmt.Errorf("invalid transport %q for %s (must be direct or outbox)", transport, provider)
+		}
+	}
+	if len(c.OutboxProviders()) == 0 {
+		return nil
+	}
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("poll_interval must be positive")
+	}
+	if c.BatchSize < 1 {
+		return fmt.Errorf("batch_size must be at least 1")
+	}
+	if c.ClaimTimeout <= 0 {
+		return fmt.Errorf("claim_timeout must be positive")
+	}
+	if c.MaxAttempts < 1 {
+		return fmt.Errorf("max_attempts must be at least 1")
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallbackConfigValidate(t *testing.T) {
+	direct := CallbackConfig{}
+	assert.NoError(t, direct.Validate())
+	assert.Equal(t, CallbackTransportDirect, direct.Transport("restate"))
+
+	valid := CallbackConfig{
+		Transports:   map[string]string{"restate": "outbox", "step-functions": "direct"},
+		PollInterval: time.Second,
+		BatchSize:    50,
+		ClaimTimeout: time.Minute,
+		MaxAttempts:  10,
+	}
+	assert.NoError(t, valid.Validate())
+	assert.Equal(t, CallbackTransportOutbox, valid.Transport("restate"))
+	assert.Equal(t, []string{"restate"}, valid.OutboxProviders())
+
+	unknown := valid
+	unknown.Transports = map[string]string{"restate": "sqs"}
+	assert.ErrorContains(t, unknown.Validate(), `invalid transport "sqs" for restate`)
+
+	noInterval := valid
+	noInterval.PollInterval = 0
+	assert.ErrorContains(t, noInterval.Validate(), "poll_interval must be positive")
+
+	noAttempts := valid
+	noAttempts.MaxAttempts = 0
+	assert.ErrorContains(t, noAttempts.Validate(), "max_attempts must be at least 1")
+
+	// Settings only matter once a provider uses the outbox
+	assert.NoError(t, (&CallbackConfig{Transports: map[string]string{"restate": "direct"}}).Validate())
+}
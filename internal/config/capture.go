@@ -0,0 +1,43 @@
+package config
+
+import "fmt"
+
+// CaptureConfig configures capture mode, which records anonymized request and response pairs of
+// the API's mutating endpoints so `landlord-cli replay` can re-issue them against a test instance
+type CaptureConfig struct {
+	// Enabled records mutating requests
+	Enabled bool `mapstructure:"enabled"`
+
+	// Directory is where capture files are written; it is created if missing
+	Directory string `mapstructure:"directory"`
+
+	// MaxBodyBytes bounds how much of each request and response body is recorded (default 1MiB).
+	// Requests with longer bodies are recorded without them and cannot be replayed.
+	MaxBodyBytes int `mapstructure:"max_body_bytes"`
+
+	// MaxRecords stops capturing after this many records per process (default 100000), so a
+	// forgotten capture cannot fill the disk
+	MaxRecords int `mapstructure:"max_records"`
+
+	// PseudonymKey keys the pseudonyms tenant, organization, project and actor names are replaced
+	// with. Records captured with the same key use the same pseudonyms, across restarts and replicas.
+	// When empty, each process uses a random key.
+	PseudonymKey string `mapstructure:"pseudonym_key"`
+}
+
+// Validate validates capture configuration
+func (c *CaptureConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Directory == "" {
+		return fmt.Errorf("directory is required when capture is enabled")
+	}
+	if c.MaxBodyBytes < 1 {
+		return fmt.Errorf("max_body_bytes must be at least 1")
+	}
+	if c.MaxRecords < 1 {
+		return fmt.Errorf("max_records must be at least 1")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureConfigValidate(t *testing.T) {
+	disabled := CaptureConfig{}
+	assert.NoError(t, disabled.Validate())
+
+	valid := CaptureConfig{Enabled: true, Directory: "/var/lib/landlord/captures", MaxBodyBytes: 1 << 20, MaxRecords: 100000}
+	assert.NoError(t, valid.Validate())
+
+	noDirectory := valid
+	noDirectory.Directory = ""
+	assert.ErrorContains(t, noDirectory.Validate(), "directory is required")
+
+	noBody := valid
+	noBody.MaxBodyBytes = 0
+	assert.ErrorContains(t, noBody.Validate(), "max_body_bytes must be at least 1")
+
+	noRecords := valid
+	noRecords.MaxRecords = 0
+	assert.ErrorContains(t, noRecords.Validate(), "max_records must be at least 1")
+}
@@ -2,18 +2,39 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 )
 
+// ErrNoComputeProviders is returned when no built-in compute provider is configured
+var ErrNoComputeProviders = errors.New("at least one compute provider must be configured")
+
 // ComputeConfig holds compute provisioning configuration
 type ComputeConfig struct {
-	Docker  *DockerProviderConfig `mapstructure:"docker"`
-	ECS     *ECSProviderConfig    `mapstructure:"ecs"`
-	Mock    *MockProviderConfig   `mapstructure:"mock"`
+	Docker      *DockerProviderConfig      `mapstructure:"docker"`
+	ECS         *ECSProviderConfig         `mapstructure:"ecs"`
+	Firecracker *FirecrackerProviderConfig `mapstructure:"firecracker"`
+	Mock        *MockProviderConfig        `mapstructure:"mock"`
+	Pool        *PoolProviderConfig        `mapstructure:"pool"`
+
+	// Limits caps how many operations each provider runs at once, keyed by provider name
+	Limits map[string]ComputeLimitsConfig `mapstructure:"limits"`
+
 	Unknown map[string]interface{} `mapstructure:",remain"`
 }
 
+// ComputeLimitsConfig caps a provider's concurrent operations; zero means no limit
+type ComputeLimitsConfig struct {
+	// MaxConcurrentProvisions caps the provisions and updates the provider runs at once
+	MaxConcurrentProvisions int `mapstructure:"max_concurrent_provisions"`
+
+	// MaxConcurrentDestroys caps the destroys the provider runs at once
+	MaxConcurrentDestroys int `mapstructure:"max_concurrent_destroys"`
+}
+
 // DockerProviderConfig holds Docker provider configuration
 type DockerProviderConfig struct {
 	// Host is the Docker API endpoint (e.g., "unix:///var/run/docker.sock", "tcp://localhost:2375")
@@ -32,15 +53,109 @@ type DockerProviderConfig struct {
 	// Defaults to "landlord"
 	LabelPrefix string `mapstructure:"label_prefix" default:"landlord"`
 
+	// NetworkIsolation selects how tenant containers are networked
+	// "shared" (default) puts every tenant on NetworkName; "tenant" creates a dedicated network per tenant
+	NetworkIsolation string `mapstructure:"network_isolation" default:"shared"`
+
+	// IngressNetwork is an existing network attached to every tenant container when NetworkIsolation is "tenant"
+	IngressNetwork string `mapstructure:"ingress_network"`
+
+	// EmulatedPlatforms are platforms the daemon can run through emulation (binfmt/QEMU),
+	// such as "linux/arm64" on an amd64 host. The daemon's native platform is always available.
+	EmulatedPlatforms []string `mapstructure:"emulated_platforms"`
+
+	// Runtime is the container engine: "docker" (default), "podman" or "containerd".
+	// For containerd, Host is the containerd socket and nerdctl must be on PATH.
+	Runtime string `mapstructure:"runtime" default:"docker"`
+
+	// Namespace is the containerd namespace tenant containers run in; containerd only
+	Namespace string `mapstructure:"namespace"`
+
+	// EgressFirewall enforces tenants' compute_config.egress policies: "" (disabled) or "iptables".
+	// It requires network_isolation "tenant" and runtime "docker", and iptables on the worker's PATH.
+	EgressFirewall string `mapstructure:"egress_firewall"`
+
+	// Naming controls the names of tenant containers; the default is "landlord-tenant-<tenant id>"
+	Naming DockerNamingConfig `mapstructure:"naming"`
+
 	// Defaults holds provider-specific compute_config defaults (e.g., image).
 	Defaults map[string]interface{} `mapstructure:",remain"`
 }
 
+// DockerNamingConfig controls the names of tenant containers, which are
+// <prefix>[-<environment>][-<tenant name>][-<id>]
+type DockerNamingConfig struct {
+	// Prefix starts every container name. Defaults to "landlord-tenant"
+	Prefix string `mapstructure:"prefix" default:"landlord-tenant"`
+
+	// IncludeName adds a slug of the tenant's name
+	IncludeName bool `mapstructure:"include_name"`
+
+	// IncludeEnvironment adds a slug of the tenant's env label, when it has one
+	IncludeEnvironment bool `mapstructure:"include_environment"`
+
+	// ID is how much of the tenant's ID ends the name: "full" (default), "short" (the first 8
+	// characters of its UUID) or "none". "short" and "none" require include_name.
+	ID string `mapstructure:"id" default:"full"`
+}
+
+// dockerContainerNamePattern is what Docker accepts as a container name
+var dockerContainerNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// DockerPlatforms are the platforms the Docker provider can run tenants on
+var DockerPlatforms = []string{"linux/amd64", "linux/arm64"}
+
 // ECSProviderConfig holds ECS provider configuration defaults.
 type ECSProviderConfig struct {
 	Defaults map[string]interface{} `mapstructure:",remain"`
 }
 
+// FirecrackerProviderConfig holds Firecracker microVM provider configuration
+type FirecrackerProviderConfig struct {
+	// Mode is "firecracker" (default), which boots a kernel and root filesystem per tenant,
+	// or "kata", which runs the tenant's image under the Kata containerd runtime
+	Mode string `mapstructure:"mode" default:"firecracker"`
+
+	// BinaryPath is the firecracker binary, or nerdctl in kata mode
+	BinaryPath string `mapstructure:"binary_path"`
+
+	// StateDir holds per-tenant API sockets, console logs and root filesystem copies; firecracker mode only
+	StateDir string `mapstructure:"state_dir" default:"/var/lib/landlord/firecracker"`
+
+	// KataRuntime is the containerd runtime class; kata mode only
+	KataRuntime string `mapstructure:"kata_runtime"`
+
+	// Namespace is the containerd namespace; kata mode only
+	Namespace string `mapstructure:"namespace"`
+
+	// Defaults holds provider-specific compute_config defaults (e.g., kernel_image_path, rootfs_path).
+	Defaults map[string]interface{} `mapstructure:",remain"`
+}
+
+// PoolProviderConfig holds static pool provider configuration
+type PoolProviderConfig struct {
+	// Slots are the pre-provisioned VMs or ports tenants are assigned to, in assignment order
+	Slots []PoolSlotConfig `mapstructure:"slots"`
+
+	// Defaults holds provider-specific compute_config defaults (e.g., selector).
+	Defaults map[string]interface{} `mapstructure:",remain"`
+}
+
+// PoolSlotConfig describes one slot of the static pool
+type PoolSlotConfig struct {
+	// Name identifies the slot; assignments are stored by name, so renaming a slot frees it
+	Name string `mapstructure:"name"`
+
+	// Address is the slot's host name or IP
+	Address string `mapstructure:"address"`
+
+	// Ports are the tenant's service ports on Address
+	Ports []int `mapstructure:"ports"`
+
+	// Labels let tenants select slots with compute_config.selector
+	Labels map[string]string `mapstructure:"labels"`
+}
+
 // MockProviderConfig holds mock provider configuration defaults.
 type MockProviderConfig struct {
 	Defaults map[string]interface{} `mapstructure:",remain"`
@@ -68,7 +183,7 @@ func (c *ComputeConfig) Validate() error {
 	}
 
 	if len(c.EnabledProviders()) == 0 {
-		return fmt.Errorf("at least one compute provider must be configured")
+		return ErrNoComputeProviders
 	}
 
 	if c.Docker != nil {
@@ -81,11 +196,29 @@ func (c *ComputeConfig) Validate() error {
 			return fmt.Errorf("ecs config: %w", err)
 		}
 	}
+	if c.Firecracker != nil {
+		if err := c.Firecracker.Validate(); err != nil {
+			return fmt.Errorf("firecracker config: %w", err)
+		}
+	}
 	if c.Mock != nil {
 		if err := c.Mock.Validate(); err != nil {
 			return fmt.Errorf("mock config: %w", err)
 		}
 	}
+	if c.Pool != nil {
+		if err := c.Pool.Validate(); err != nil {
+			return fmt.Errorf("pool config: %w", err)
+		}
+	}
+	for name, limits := range c.Limits {
+		if limits.MaxConcurrentProvisions < 0 {
+			return fmt.Errorf("compute.limits.%s.max_concurrent_provisions must be non-negative", name)
+		}
+		if limits.MaxConcurrentDestroys < 0 {
+			return fmt.Errorf("compute.limits.%s.max_concurrent_destroys must be non-negative", name)
+		}
+	}
 
 	return nil
 }
@@ -99,9 +232,15 @@ func (c *ComputeConfig) EnabledProviders() []string {
 	if c.ECS != nil {
 		providers = append(providers, "ecs")
 	}
+	if c.Firecracker != nil {
+		providers = append(providers, "firecracker")
+	}
 	if c.Mock != nil {
 		providers = append(providers, "mock")
 	}
+	if c.Pool != nil {
+		providers = append(providers, "pool")
+	}
 	return providers
 }
 
@@ -130,6 +269,56 @@ func (d *DockerProviderConfig) Validate() error {
 	if !ok || strings.TrimSpace(imageStr) == "" {
 		return fmt.Errorf("compute.docker.image must be a non-empty string")
 	}
+	switch d.NetworkIsolation {
+	case "", "shared":
+		if d.IngressNetwork != "" {
+			return fmt.Errorf("compute.docker.ingress_network requires network_isolation \"tenant\"")
+		}
+	case "tenant":
+	default:
+		return fmt.Errorf("compute.docker.network_isolation must be \"shared\" or \"tenant\", got %q", d.NetworkIsolation)
+	}
+	switch d.Runtime {
+	case "", "docker", "podman":
+		if d.Namespace != "" {
+			return fmt.Errorf("compute.docker.namespace requires runtime \"containerd\"")
+		}
+	case "containerd":
+		if d.IngressNetwork != "" {
+			return fmt.Errorf("compute.docker.ingress_network is not supported with runtime \"containerd\"")
+		}
+	default:
+		return fmt.Errorf("compute.docker.runtime must be \"docker\", \"podman\" or \"containerd\", got %q", d.Runtime)
+	}
+	switch d.EgressFirewall {
+	case "":
+	case "iptables":
+		if d.NetworkIsolation != "tenant" {
+			return fmt.Errorf("compute.docker.egress_firewall requires network_isolation \"tenant\"")
+		}
+		if d.Runtime != "" && d.Runtime != "docker" {
+			return fmt.Errorf("compute.docker.egress_firewall requires runtime \"docker\"")
+		}
+	default:
+		return fmt.Errorf("compute.docker.egress_firewall must be \"iptables\", got %q", d.EgressFirewall)
+	}
+	if d.Naming.Prefix != "" && !dockerContainerNamePattern.MatchString(d.Naming.Prefix) {
+		return fmt.Errorf("compute.docker.naming.prefix %q must start with a letter or digit and contain only letters, digits, '_', '.' and '-'", d.Naming.Prefix)
+	}
+	switch d.Naming.ID {
+	case "", "full":
+	case "short", "none":
+		if !d.Naming.IncludeName {
+			return fmt.Errorf("compute.docker.naming.id %q requires include_name", d.Naming.ID)
+		}
+	default:
+		return fmt.Errorf("compute.docker.naming.id must be \"full\", \"short\" or \"none\", got %q", d.Naming.ID)
+	}
+	for _, platform := range d.EmulatedPlatforms {
+		if !slices.Contains(DockerPlatforms, platform) {
+			return fmt.Errorf("compute.docker.emulated_platforms: unsupported platform %q, must be one of %s", platform, strings.Join(DockerPlatforms, ", "))
+		}
+	}
 	return nil
 }
 
@@ -177,6 +366,61 @@ func (e *ECSProviderConfig) Validate() error {
 	return nil
 }
 
+// Validate validates Firecracker configuration defaults.
+func (f *FirecrackerProviderConfig) Validate() error {
+	if f == nil {
+		return nil
+	}
+	var required []string
+	switch f.Mode {
+	case "", "firecracker":
+		if f.KataRuntime != "" || f.Namespace != "" {
+			return fmt.Errorf("compute.firecracker.kata_runtime and compute.firecracker.namespace require mode \"kata\"")
+		}
+		required = []string{"kernel_image_path", "rootfs_path"}
+	case "kata":
+		required = []string{"image"}
+	default:
+		return fmt.Errorf("compute.firecracker.mode must be \"firecracker\" or \"kata\", got %q", f.Mode)
+	}
+	for _, key := range required {
+		value, ok := f.Defaults[key].(string)
+		if !ok || strings.TrimSpace(value) == "" {
+			return fmt.Errorf("compute.firecracker.%s is required", key)
+		}
+	}
+	return nil
+}
+
+// Validate validates the pool's slots.
+func (p *PoolProviderConfig) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if len(p.Slots) == 0 {
+		return fmt.Errorf("compute.pool.slots must include at least one slot")
+	}
+	names := make(map[string]bool, len(p.Slots))
+	for i, slot := range p.Slots {
+		if strings.TrimSpace(slot.Name) == "" {
+			return fmt.Errorf("compute.pool.slots[%d].name is required", i)
+		}
+		if names[slot.Name] {
+			return fmt.Errorf("compute.pool.slots[%d].name %q is used by another slot", i, slot.Name)
+		}
+		names[slot.Name] = true
+		if strings.TrimSpace(slot.Address) == "" {
+			return fmt.Errorf("compute.pool.slots[%d].address is required", i)
+		}
+		for _, port := range slot.Ports {
+			if port < 1 || port > 65535 {
+				return fmt.Errorf("compute.pool.slots[%d].ports: %d is not a valid port", i, port)
+			}
+		}
+	}
+	return nil
+}
+
 // Validate validates mock configuration defaults.
 func (m *MockProviderConfig) Validate() error {
 	return nil
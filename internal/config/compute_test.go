@@ -74,6 +74,226 @@ func TestComputeConfigValidate_DockerDefaultsRequired(t *testing.T) {
 	require.Contains(t, err.Error(), "compute.docker")
 }
 
+func TestComputeConfigValidate_DockerNetworkIsolation(t *testing.T) {
+	tests := []struct {
+		name      string
+		isolation string
+		ingress   string
+		wantErr   string
+	}{
+		{name: "default", isolation: ""},
+		{name: "tenant with ingress", isolation: "tenant", ingress: "ingress"},
+		{name: "unknown mode", isolation: "vlan", wantErr: "network_isolation"},
+		{name: "ingress without isolation", isolation: "shared", ingress: "ingress", wantErr: "ingress_network"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := ComputeConfig{
+				Docker: &DockerProviderConfig{
+					NetworkIsolation: tt.isolation,
+					IngressNetwork:   tt.ingress,
+					Defaults:         map[string]interface{}{"image": "nginx:latest"},
+				},
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestComputeConfigValidate_DockerEmulatedPlatforms(t *testing.T) {
+	cfg := ComputeConfig{
+		Docker: &DockerProviderConfig{
+			EmulatedPlatforms: []string{"linux/arm64"},
+			Defaults:          map[string]interface{}{"image": "nginx:latest"},
+		},
+	}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Docker.EmulatedPlatforms = []string{"linux/riscv64"}
+	err := cfg.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "emulated_platforms")
+}
+
+func TestComputeConfigValidate_DockerRuntime(t *testing.T) {
+	tests := []struct {
+		name      string
+		runtime   string
+		namespace string
+		isolation string
+		ingress   string
+		wantErr   string
+	}{
+		{name: "default", runtime: ""},
+		{name: "podman", runtime: "podman"},
+		{name: "containerd with namespace", runtime: "containerd", namespace: "landlord"},
+		{name: "unknown runtime", runtime: "cri-o", wantErr: "compute.docker.runtime"},
+		{name: "namespace without containerd", runtime: "docker", namespace: "landlord", wantErr: "compute.docker.namespace"},
+		{name: "containerd with ingress", runtime: "containerd", isolation: "tenant", ingress: "ingress", wantErr: "ingress_network"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := ComputeConfig{
+				Docker: &DockerProviderConfig{
+					Runtime:          tt.runtime,
+					Namespace:        tt.namespace,
+					NetworkIsolation: tt.isolation,
+					IngressNetwork:   tt.ingress,
+					Defaults:         map[string]interface{}{"image": "nginx:latest"},
+				},
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestComputeConfigValidate_DockerEgressFirewall(t *testing.T) {
+	tests := []struct {
+		name      string
+		firewall  string
+		isolation string
+		runtime   string
+		wantErr   string
+	}{
+		{name: "disabled", firewall: ""},
+		{name: "iptables", firewall: "iptables", isolation: "tenant"},
+		{name: "unknown firewall", firewall: "nftables", isolation: "tenant", wantErr: "compute.docker.egress_firewall must be"},
+		{name: "shared network", firewall: "iptables", isolation: "shared", wantErr: "network_isolation"},
+		{name: "podman", firewall: "iptables", isolation: "tenant", runtime: "podman", wantErr: "runtime"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := ComputeConfig{
+				Docker: &DockerProviderConfig{
+					EgressFirewall:   tt.firewall,
+					NetworkIsolation: tt.isolation,
+					Runtime:          tt.runtime,
+					Defaults:         map[string]interface{}{"image": "nginx:latest"},
+				},
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestComputeConfigValidate_DockerNaming(t *testing.T) {
+	tests := []struct {
+		name    string
+		naming  DockerNamingConfig
+		wantErr string
+	}{
+		{name: "default"},
+		{name: "name without id", naming: DockerNamingConfig{Prefix: "acme", IncludeName: true, IncludeEnvironment: true, ID: "none"}},
+		{name: "invalid prefix", naming: DockerNamingConfig{Prefix: "acme/eu"}, wantErr: "compute.docker.naming.prefix"},
+		{name: "short id without name", naming: DockerNamingConfig{ID: "short"}, wantErr: "requires include_name"},
+		{name: "unknown id", naming: DockerNamingConfig{IncludeName: true, ID: "uuid"}, wantErr: "compute.docker.naming.id must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := ComputeConfig{
+				Docker: &DockerProviderConfig{
+					Naming:   tt.naming,
+					Defaults: map[string]interface{}{"image": "nginx:latest"},
+				},
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestComputeConfigValidate_Firecracker(t *testing.T) {
+	vmDefaults := map[string]interface{}{"kernel_image_path": "/vm/vmlinux", "rootfs_path": "/vm/rootfs.ext4"}
+	tests := []struct {
+		name    string
+		config  FirecrackerProviderConfig
+		wantErr string
+	}{
+		{name: "firecracker", config: FirecrackerProviderConfig{Defaults: vmDefaults}},
+		{name: "kata", config: FirecrackerProviderConfig{Mode: "kata", Namespace: "landlord", Defaults: map[string]interface{}{"image": "nginx:latest"}}},
+		{name: "missing rootfs", config: FirecrackerProviderConfig{Defaults: map[string]interface{}{"kernel_image_path": "/vm/vmlinux"}}, wantErr: "compute.firecracker.rootfs_path is required"},
+		{name: "kata without image", config: FirecrackerProviderConfig{Mode: "kata", Defaults: vmDefaults}, wantErr: "compute.firecracker.image is required"},
+		{name: "namespace without kata", config: FirecrackerProviderConfig{Namespace: "landlord", Defaults: vmDefaults}, wantErr: "require mode"},
+		{name: "unknown mode", config: FirecrackerProviderConfig{Mode: "qemu", Defaults: vmDefaults}, wantErr: "compute.firecracker.mode"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.config
+			cfg := ComputeConfig{Firecracker: &config}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				require.Equal(t, []string{"firecracker"}, cfg.EnabledProviders())
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestComputeConfigValidate_Pool(t *testing.T) {
+	tests := []struct {
+		name    string
+		slots   []PoolSlotConfig
+		wantErr string
+	}{
+		{name: "valid", slots: []PoolSlotConfig{{Name: "vm-1", Address: "10.0.0.1", Ports: []int{8080}}, {Name: "vm-2", Address: "10.0.0.2"}}},
+		{name: "no slots", wantErr: "compute.pool.slots must include at least one slot"},
+		{name: "missing address", slots: []PoolSlotConfig{{Name: "vm-1"}}, wantErr: "compute.pool.slots[0].address is required"},
+		{name: "duplicate name", slots: []PoolSlotConfig{{Name: "vm-1", Address: "10.0.0.1"}, {Name: "vm-1", Address: "10.0.0.2"}}, wantErr: "compute.pool.slots[1].name"},
+		{name: "bad port", slots: []PoolSlotConfig{{Name: "vm-1", Address: "10.0.0.1", Ports: []int{70000}}}, wantErr: "not a valid port"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := ComputeConfig{Pool: &PoolProviderConfig{Slots: tt.slots}}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				require.Equal(t, []string{"pool"}, cfg.EnabledProviders())
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
 func TestComputeConfigValidate_ECSDefaultsRequired(t *testing.T) {
 	cfg := ComputeConfig{
 		ECS: &ECSProviderConfig{
@@ -85,3 +305,18 @@ func TestComputeConfigValidate_ECSDefaultsRequired(t *testing.T) {
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "compute.ecs")
 }
+
+func TestComputeConfigLimits(t *testing.T) {
+	v := NewViperInstance()
+	setComputeDefaults(v)
+	v.Set("compute.limits.docker.max_concurrent_provisions", 5)
+	v.Set("compute.limits.docker.max_concurrent_destroys", 10)
+
+	// Limits are not mistaken for a provider
+	cfg, err := LoadFromViper(v)
+	require.NoError(t, err)
+	require.Equal(t, ComputeLimitsConfig{MaxConcurrentProvisions: 5, MaxConcurrentDestroys: 10}, cfg.Compute.Limits["docker"])
+
+	cfg.Compute.Limits["docker"] = ComputeLimitsConfig{MaxConcurrentProvisions: -1}
+	require.EqualError(t, cfg.Compute.Validate(), "compute.limits.docker.max_concurrent_provisions must be non-negative")
+}
@@ -1,15 +1,42 @@
 package config
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // Config holds all application configuration
 type Config struct {
-	Database   DatabaseConfig   `mapstructure:"database"`
-	HTTP       HTTPConfig       `mapstructure:"http"`
-	Log        LogConfig        `mapstructure:"log"`
-	Compute    ComputeConfig    `mapstructure:"compute"`
-	Workflow   WorkflowConfig   `mapstructure:"workflow"`
-	Controller ControllerConfig `mapstructure:"controller"`
+	Database          DatabaseConfig          `mapstructure:"database"`
+	HTTP              HTTPConfig              `mapstructure:"http"`
+	Log               LogConfig               `mapstructure:"log"`
+	Compute           ComputeConfig           `mapstructure:"compute"`
+	Workflow          WorkflowConfig          `mapstructure:"workflow"`
+	Controller        ControllerConfig        `mapstructure:"controller"`
+	DataPlane         DataPlaneConfig         `mapstructure:"dataplane"`
+	Plugins           PluginsConfig           `mapstructure:"plugins"`
+	Auth              AuthConfig              `mapstructure:"auth"`
+	ImagePolicy       ImagePolicyConfig       `mapstructure:"image_policy"`
+	ImageUpdate       ImageUpdateConfig       `mapstructure:"image_update"`
+	VulnerabilityScan VulnerabilityScanConfig `mapstructure:"vulnerability_scan"`
+	Approvals         ApprovalConfig          `mapstructure:"approvals"`
+	Emergency         EmergencyConfig         `mapstructure:"emergency"`
+	Schedules         ScheduleConfig          `mapstructure:"schedules"`
+	WarmPools         WarmPoolConfig          `mapstructure:"warm_pools"`
+	Uptime            UptimeConfig            `mapstructure:"uptime"`
+	EndpointAuth      EndpointAuthConfig      `mapstructure:"endpoint_auth"`
+	EgressMonitor     EgressMonitorConfig     `mapstructure:"egress_monitor"`
+	ComputeResolution ComputeResolutionConfig `mapstructure:"compute_resolution"`
+	Cost              CostConfig              `mapstructure:"cost"`
+	TenantCache       TenantCacheConfig       `mapstructure:"tenant_cache"`
+	ListCache         ListCacheConfig         `mapstructure:"list_cache"`
+	Doctor            DoctorConfig            `mapstructure:"doctor"`
+	Observe           ObserveConfig           `mapstructure:"observe"`
+	CallbackAuth      CallbackAuthConfig      `mapstructure:"callback_auth"`
+	Retention         RetentionConfig         `mapstructure:"retention"`
+	Housekeeping      HousekeepingConfig      `mapstructure:"housekeeping"`
+	FeatureFlags      FeatureFlagsConfig      `mapstructure:"feature_flags"`
+	Capture           CaptureConfig           `mapstructure:"capture"`
 }
 
 // Validate performs validation on the configuration
@@ -23,7 +50,8 @@ func (c *Config) Validate() error {
 	if err := c.Log.Validate(); err != nil {
 		return fmt.Errorf("log config: %w", err)
 	}
-	if err := c.Compute.Validate(); err != nil {
+	// Compute providers may come entirely from plugins
+	if err := c.Compute.Validate(); err != nil && !(errors.Is(err, ErrNoComputeProviders) && c.Plugins.Enabled()) {
 		return fmt.Errorf("compute config: %w", err)
 	}
 	if err := c.Workflow.Validate(); err != nil {
@@ -32,5 +60,77 @@ func (c *Config) Validate() error {
 	if err := c.Controller.Validate(); err != nil {
 		return fmt.Errorf("controller config: %w", err)
 	}
+	if err := c.DataPlane.Validate(); err != nil {
+		return fmt.Errorf("dataplane config: %w", err)
+	}
+	if err := c.Plugins.Validate(); err != nil {
+		return fmt.Errorf("plugins config: %w", err)
+	}
+	if err := c.Auth.Validate(); err != nil {
+		return fmt.Errorf("auth config: %w", err)
+	}
+	if err := c.ImagePolicy.Validate(); err != nil {
+		return fmt.Errorf("image policy config: %w", err)
+	}
+	if err := c.ImageUpdate.Validate(); err != nil {
+		return fmt.Errorf("image update config: %w", err)
+	}
+	if err := c.VulnerabilityScan.Validate(); err != nil {
+		return fmt.Errorf("vulnerability scan config: %w", err)
+	}
+	if err := c.Approvals.Validate(); err != nil {
+		return fmt.Errorf("approvals config: %w", err)
+	}
+	if err := c.Emergency.Validate(); err != nil {
+		return fmt.Errorf("emergency config: %w", err)
+	}
+	if err := c.Schedules.Validate(); err != nil {
+		return fmt.Errorf("schedules config: %w", err)
+	}
+	if err := c.WarmPools.Validate(); err != nil {
+		return fmt.Errorf("warm pools config: %w", err)
+	}
+	if err := c.Uptime.Validate(); err != nil {
+		return fmt.Errorf("uptime config: %w", err)
+	}
+	if err := c.EndpointAuth.Validate(); err != nil {
+		return fmt.Errorf("endpoint auth config: %w", err)
+	}
+	if err := c.EgressMonitor.Validate(); err != nil {
+		return fmt.Errorf("egress monitor config: %w", err)
+	}
+	if err := c.ComputeResolution.Validate(); err != nil {
+		return fmt.Errorf("compute resolution config: %w", err)
+	}
+	if err := c.Cost.Validate(); err != nil {
+		return fmt.Errorf("cost config: %w", err)
+	}
+	if err := c.TenantCache.Validate(); err != nil {
+		return fmt.Errorf("tenant cache config: %w", err)
+	}
+	if err := c.ListCache.Validate(); err != nil {
+		return fmt.Errorf("list cache config: %w", err)
+	}
+	if err := c.Doctor.Validate(); err != nil {
+		return fmt.Errorf("doctor config: %w", err)
+	}
+	if err := c.Observe.Validate(); err != nil {
+		return fmt.Errorf("observe config: %w", err)
+	}
+	if err := c.CallbackAuth.Validate(); err != nil {
+		return fmt.Errorf("callback auth config: %w", err)
+	}
+	if err := c.Retention.Validate(); err != nil {
+		return fmt.Errorf("retention config: %w", err)
+	}
+	if err := c.Housekeeping.Validate(); err != nil {
+		return fmt.Errorf("housekeeping config: %w", err)
+	}
+	if err := c.FeatureFlags.Validate(); err != nil {
+		return fmt.Errorf("feature flags config: %w", err)
+	}
+	if err := c.Capture.Validate(); err != nil {
+		return fmt.Errorf("capture config: %w", err)
+	}
 	return nil
 }
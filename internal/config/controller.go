@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"slices"
 	"time"
 )
 
@@ -10,6 +12,10 @@ type ControllerConfig struct {
 	// Enabled controls whether the controller is started
 	Enabled bool `mapstructure:"enabled"`
 
+	// Standalone runs the controller only in its own process, the controller command, so servers
+	// that would embed it, such as the all-in-one binary, leave it stopped
+	Standalone bool `mapstructure:"standalone"`
+
 	// WorkflowProvider overrides the workflow provider used by the controller
 	// If empty, workflow.default_provider is used.
 	WorkflowProvider string `mapstructure:"workflow_provider"`
@@ -31,6 +37,167 @@ type ControllerConfig struct {
 
 	// MaxRetries is the maximum number of retry attempts before marking a tenant as failed
 	MaxRetries int `mapstructure:"max_retries"`
+
+	// RetryPolicies controls how reconciles that fail are retried, keyed by action (provision,
+	// update, archive). Actions without an entry, and fields left unset, retry up to MaxRetries
+	// times with a backoff from 1s doubling to 5m, whatever the error.
+	RetryPolicies map[string]RetryPolicy `mapstructure:"retry_policies"`
+
+	// WorkflowTimeouts bounds how long a workflow execution may run, keyed by operation
+	// (provision, update, migrate, delete, archive). Operations without an entry never time out.
+	WorkflowTimeouts map[string]time.Duration `mapstructure:"workflow_timeouts"`
+
+	// WorkflowTimeoutBackoff is how long the controller waits before retrying an execution that
+	// timed out. It doubles with each consecutive timeout, up to MaxWorkflowTimeoutBackoff.
+	WorkflowTimeoutBackoff time.Duration `mapstructure:"workflow_timeout_backoff"`
+
+	// WorkflowConcurrency caps how many workflows tenants of each priority class (critical, high,
+	// default, batch) may have running at once. Classes without an entry are not capped.
+	WorkflowConcurrency map[string]int `mapstructure:"workflow_concurrency"`
+
+	// Admission holds new provisioning back while workflows are failing or backed up
+	Admission AdmissionConfig `mapstructure:"admission"`
+
+	// Chaos injects faults into reconciliation to surface race conditions; never enable in production
+	Chaos ChaosConfig `mapstructure:"chaos"`
+
+	// TriggerOutbox records workflow triggers with the tenant update and publishes them separately
+	TriggerOutbox TriggerOutboxConfig `mapstructure:"trigger_outbox"`
+
+	// Admin serves the controller's metrics, and optionally its profiles, on a listener of its own
+	Admin ControllerAdminConfig `mapstructure:"admin"`
+}
+
+// ControllerAdminConfig configures the controller's admin listener. It is kept apart from the API
+// listener so profiles can be opened to operators without exposing them to API clients.
+type ControllerAdminConfig struct {
+	// Enabled starts the admin listener, which serves /debug/vars and the /healthz and /readyz
+	// probes. The controller command always starts it.
+	Enabled bool `mapstructure:"enabled"`
+
+	// Address is the host:port the admin listener binds
+	Address string `mapstructure:"address"`
+
+	// Pprof also serves the runtime profiles and execution traces under /debug/pprof/
+	Pprof bool `mapstructure:"pprof"`
+}
+
+// RetryPolicy controls how the controller retries a tenant action whose reconcile fails. Tenants
+// can override it with the landlord/retry-policy annotation.
+type RetryPolicy struct {
+	// MaxAttempts is how many reconciles may fail before the tenant is marked failed
+	MaxAttempts int `mapstructure:"max_attempts"`
+
+	// InitialBackoff is the wait before the first retry. Each retry waits BackoffMultiplier times
+	// longer than the last, up to MaxBackoff.
+	InitialBackoff    time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff        time.Duration `mapstructure:"max_backoff"`
+	BackoffMultiplier float64       `mapstructure:"backoff_multiplier"`
+
+	// RetriableErrorCodes are the compute error codes (PROVIDER_TIMEOUT, PROVIDER_UNAVAILABLE,
+	// RESOURCE_EXHAUSTED, INVALID_CONFIGURATION, RESOURCE_NOT_FOUND, UNKNOWN_ERROR) that are
+	// retried. Errors with any other code fail the tenant at once. Empty retries every error.
+	RetriableErrorCodes []string `mapstructure:"retriable_error_codes"`
+}
+
+// RetryPolicyActions are the actions RetryPolicies may configure
+var RetryPolicyActions = []string{"provision", "update", "archive"}
+
+// Validate checks the retry policy
+func (p *RetryPolicy) Validate() error {
+	if p.MaxAttempts < 0 {
+		return fmt.Errorf("max_attempts must be non-negative")
+	}
+	if p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+		return fmt.Errorf("initial_backoff and max_backoff must be non-negative")
+	}
+	if p.InitialBackoff > 0 && p.MaxBackoff > 0 && p.MaxBackoff < p.InitialBackoff {
+		return fmt.Errorf("max_backoff must be at least initial_backoff")
+	}
+	if p.BackoffMultiplier != 0 && p.BackoffMultiplier < 1 {
+		return fmt.Errorf("backoff_multiplier must be at least 1")
+	}
+	for _, code := range p.RetriableErrorCodes {
+		if code == "" {
+			return fmt.Errorf("retriable_error_codes must not contain empty codes")
+		}
+	}
+	return nil
+}
+
+// WorkflowTimeoutOperations are the operations WorkflowTimeouts may bound
+var WorkflowTimeoutOperations = []string{"provision", "update", "migrate", "delete", "archive"}
+
+// PriorityClasses are the tenant priority classes WorkflowConcurrency may cap, highest first
+var PriorityClasses = []string{"critical", "high", "default", "batch"}
+
+// MaxWorkflowTimeoutBackoff caps the wait before retrying a timed out workflow
+const MaxWorkflowTimeoutBackoff = 10 * time.Minute
+
+// AdmissionConfig configures backpressure on new provisioning. While the share of failed workflow
+// triggers and executions, or the number of workflows in flight, is above its threshold, requested
+// tenants wait in the pending-capacity sub-state instead of starting workflows that are likely to fail.
+type AdmissionConfig struct {
+	// Enabled turns on admission control
+	Enabled bool `mapstructure:"enabled"`
+
+	// Window is how far back workflow trigger and execution results are counted
+	Window time.Duration `mapstructure:"window"`
+
+	// MaxErrorRate is the fraction of failed results in Window above which provisioning is held
+	MaxErrorRate float64 `mapstructure:"max_error_rate"`
+
+	// MinSamples is how many results Window must hold before its error rate is acted on
+	MinSamples int `mapstructure:"min_samples"`
+
+	// MaxInFlight holds provisioning while this many workflows are running or queued in the
+	// workflow engine; 0 disables the check
+	MaxInFlight int `mapstructure:"max_in_flight"`
+}
+
+// ChaosConfig configures fault injection and invariant checking for the reconciler
+type ChaosConfig struct {
+	// Enabled turns on chaos mode
+	Enabled bool `mapstructure:"enabled"`
+
+	// Seed makes injected faults reproducible; 0 picks a random seed
+	Seed int64 `mapstructure:"seed"`
+
+	// DelayRate is the fraction of reconciles delayed by a random duration up to MaxDelay
+	DelayRate float64       `mapstructure:"delay_rate"`
+	MaxDelay  time.Duration `mapstructure:"max_delay"`
+
+	// DropCallbackRate is the fraction of workflow status results discarded as if they never arrived
+	DropCallbackRate float64 `mapstructure:"drop_callback_rate"`
+
+	// VersionConflictRate is the fraction of tenant updates rejected with a version conflict
+	VersionConflictRate float64 `mapstructure:"version_conflict_rate"`
+
+	// StuckThreshold is how long a tenant may stay in one non-terminal status before it is reported stuck
+	StuckThreshold time.Duration `mapstructure:"stuck_threshold"`
+
+	// CheckInterval is how often invariants are checked
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+}
+
+// TriggerOutboxConfig configures the workflow trigger outbox. When enabled, the reconciler does
+// not start workflows itself: it writes the trigger to the outbox in the same transaction as the
+// tenant update, and a dispatcher starts the workflow from there, retrying until it succeeds.
+type TriggerOutboxConfig struct {
+	// Enabled turns on the trigger outbox; the tenant repository must support it
+	Enabled bool `mapstructure:"enabled"`
+
+	// PollInterval is how often the dispatcher claims pending triggers
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+
+	// BatchSize is how many triggers the dispatcher claims at once
+	BatchSize int `mapstructure:"batch_size"`
+
+	// ClaimTimeout is how long a claimed trigger is reserved for one dispatcher before another may take it
+	ClaimTimeout time.Duration `mapstructure:"claim_timeout"`
+
+	// MaxAttempts is how many times a trigger is published before the tenant is marked failed
+	MaxAttempts int `mapstructure:"max_attempts"`
 }
 
 // Validate checks the controller configuration
@@ -60,6 +227,126 @@ func (c *ControllerConfig) Validate() error {
 		if c.MaxRetries < 0 {
 			return fmt.Errorf("max_retries must be non-negative")
 		}
+		for action, policy := range c.RetryPolicies {
+			if !slices.Contains(RetryPolicyActions, action) {
+				return fmt.Errorf("retry_policies: unknown action %q", action)
+			}
+			if err := policy.Validate(); err != nil {
+				return fmt.Errorf("retry_policies.%s: %w", action, err)
+			}
+		}
+		for operation, timeout := range c.WorkflowTimeouts {
+			if !slices.Contains(WorkflowTimeoutOperations, operation) {
+				return fmt.Errorf("workflow_timeouts: unknown operation %q", operation)
+			}
+			if timeout < 0 {
+				return fmt.Errorf("workflow_timeouts.%s must be non-negative", operation)
+			}
+		}
+		if c.WorkflowTimeoutBackoff < 0 {
+			return fmt.Errorf("workflow_timeout_backoff must be non-negative")
+		}
+		for class, limit := range c.WorkflowConcurrency {
+			if !slices.Contains(PriorityClasses, class) {
+				return fmt.Errorf("workflow_concurrency: unknown priority class %q", class)
+			}
+			if limit <= 0 {
+				return fmt.Errorf("workflow_concurrency.%s must be positive", class)
+			}
+		}
+		if err := c.Admission.Validate(); err != nil {
+			return fmt.Errorf("admission: %w", err)
+		}
+		if err := c.Chaos.Validate(); err != nil {
+			return fmt.Errorf("chaos: %w", err)
+		}
+		if err := c.TriggerOutbox.Validate(); err != nil {
+			return fmt.Errorf("trigger_outbox: %w", err)
+		}
+		if err := c.Admin.Validate(); err != nil {
+			return fmt.Errorf("admin: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the admission configuration
+func (c *AdmissionConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Window <= 0 {
+		return fmt.Errorf("window must be positive")
+	}
+	if c.MaxErrorRate <= 0 || c.MaxErrorRate > 1 {
+		return fmt.Errorf("max_error_rate must be greater than 0 and at most 1")
+	}
+	if c.MinSamples <= 0 {
+		return fmt.Errorf("min_samples must be positive")
+	}
+	if c.MaxInFlight < 0 {
+		return fmt.Errorf("max_in_flight must be non-negative")
+	}
+	return nil
+}
+
+// Validate checks the chaos configuration
+func (c *ChaosConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	rates := []struct {
+		name string
+		rate float64
+	}{
+		{"delay_rate", c.DelayRate},
+		{"drop_callback_rate", c.DropCallbackRate},
+		{"version_conflict_rate", c.VersionConflictRate},
+	}
+	for _, r := range rates {
+		if r.rate < 0 || r.rate > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", r.name)
+		}
+	}
+	if c.DelayRate > 0 && c.MaxDelay <= 0 {
+		return fmt.Errorf("max_delay must be positive when delay_rate is set")
+	}
+	if c.StuckThreshold <= 0 {
+		return fmt.Errorf("stuck_threshold must be positive")
+	}
+	if c.CheckInterval <= 0 {
+		return fmt.Errorf("check_interval must be positive")
+	}
+	return nil
+}
+
+// Validate checks the trigger outbox configuration
+func (c *TriggerOutboxConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("poll_interval must be positive")
+	}
+	if c.BatchSize <= 0 {
+		return fmt.Errorf("batch_size must be positive")
+	}
+	if c.ClaimTimeout <= 0 {
+		return fmt.Errorf("claim_timeout must be positive")
+	}
+	if c.MaxAttempts <= 0 {
+		return fmt.Errorf("max_attempts must be positive")
+	}
+	return nil
+}
+
+// Validate checks the admin listener configuration
+func (c *ControllerAdminConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(c.Address); err != nil {
+		return fmt.Errorf("address must be host:port: %w", err)
 	}
 	return nil
 }
@@ -84,4 +371,43 @@ func (c *ControllerConfig) SetDefaults() {
 	if c.MaxRetries == 0 {
 		c.MaxRetries = 5
 	}
+	if c.WorkflowTimeouts == nil {
+		c.WorkflowTimeouts = map[string]time.Duration{
+			"provision": 15 * time.Minute,
+			"archive":   10 * time.Minute,
+		}
+	}
+	if c.WorkflowTimeoutBackoff == 0 {
+		c.WorkflowTimeoutBackoff = 30 * time.Second
+	}
+	if c.Admission.Window == 0 {
+		c.Admission.Window = 5 * time.Minute
+	}
+	if c.Admission.MaxErrorRate == 0 {
+		c.Admission.MaxErrorRate = 0.5
+	}
+	if c.Admission.MinSamples == 0 {
+		c.Admission.MinSamples = 10
+	}
+	if c.Chaos.StuckThreshold == 0 {
+		c.Chaos.StuckThreshold = 10 * time.Minute
+	}
+	if c.Chaos.CheckInterval == 0 {
+		c.Chaos.CheckInterval = 30 * time.Second
+	}
+	if c.TriggerOutbox.PollInterval == 0 {
+		c.TriggerOutbox.PollInterval = time.Second
+	}
+	if c.TriggerOutbox.BatchSize == 0 {
+		c.TriggerOutbox.BatchSize = 50
+	}
+	if c.TriggerOutbox.ClaimTimeout == 0 {
+		c.TriggerOutbox.ClaimTimeout = time.Minute
+	}
+	if c.TriggerOutbox.MaxAttempts == 0 {
+		c.TriggerOutbox.MaxAttempts = 10
+	}
+	if c.Admin.Address == "" {
+		c.Admin.Address = "127.0.0.1:9091"
+	}
 }
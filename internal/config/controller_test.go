@@ -0,0 +1,141 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControllerConfigValidateWorkflowTimeouts(t *testing.T) {
+	cfg := ControllerConfig{Enabled: true}
+	cfg.SetDefaults()
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, 15*time.Minute, cfg.WorkflowTimeouts["provision"])
+	assert.Equal(t, 10*time.Minute, cfg.WorkflowTimeouts["archive"])
+
+	cfg.WorkflowTimeouts = map[string]time.Duration{"restart": time.Minute}
+	assert.ErrorContains(t, cfg.Validate(), `workflow_timeouts: unknown operation "restart"`)
+
+	cfg.WorkflowTimeouts = map[string]time.Duration{"update": -time.Minute}
+	assert.ErrorContains(t, cfg.Validate(), "workflow_timeouts.update must be non-negative")
+
+	cfg.WorkflowTimeouts = nil
+	cfg.WorkflowTimeoutBackoff = -time.Second
+	assert.ErrorContains(t, cfg.Validate(), "workflow_timeout_backoff must be non-negative")
+}
+
+func TestLoadFromViper_WorkflowTimeouts(t *testing.T) {
+	v := NewViperInstance()
+	setComputeDefaults(v)
+	v.Set("controller.workflow_timeouts.update", "20m")
+
+	cfg, err := LoadFromViper(v)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Duration{
+		"provision": 15 * time.Minute,
+		"update":    20 * time.Minute,
+		"archive":   10 * time.Minute,
+	}, cfg.Controller.WorkflowTimeouts)
+	assert.Equal(t, 30*time.Second, cfg.Controller.WorkflowTimeoutBackoff)
+}
+
+func TestControllerConfigValidateRetryPolicies(t *testing.T) {
+	cfg := ControllerConfig{Enabled: true}
+	cfg.SetDefaults()
+	cfg.RetryPolicies = map[string]RetryPolicy{
+		"provision": {MaxAttempts: 10, InitialBackoff: 5 * time.Second, MaxBackoff: time.Minute, BackoffMultiplier: 3},
+		"archive":   {RetriableErrorCodes: []string{"PROVIDER_TIMEOUT"}},
+	}
+	require.NoError(t, cfg.Validate())
+
+	cfg.RetryPolicies = map[string]RetryPolicy{"migrate": {MaxAttempts: 1}}
+	assert.ErrorContains(t, cfg.Validate(), `retry_policies: unknown action "migrate"`)
+
+	cfg.RetryPolicies = map[string]RetryPolicy{"update": {InitialBackoff: time.Minute, MaxBackoff: time.Second}}
+	assert.ErrorContains(t, cfg.Validate(), "retry_policies.update: max_backoff must be at least initial_backoff")
+
+	cfg.RetryPolicies = map[string]RetryPolicy{"update": {BackoffMultiplier: 0.5}}
+	assert.ErrorContains(t, cfg.Validate(), "retry_policies.update: backoff_multiplier must be at least 1")
+}
+
+func TestLoadFromViper_RetryPolicies(t *testing.T) {
+	v := NewViperInstance()
+	setComputeDefaults(v)
+	v.Set("controller.retry_policies", map[string]interface{}{
+		"provision": map[string]interface{}{
+			"max_attempts":          8,
+			"initial_backoff":       "2s",
+			"max_backoff":           "1m",
+			"retriable_error_codes": []string{"PROVIDER_TIMEOUT", "PROVIDER_UNAVAILABLE"},
+		},
+	})
+
+	cfg, err := LoadFromViper(v)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]RetryPolicy{
+		"provision": {
+			MaxAttempts:         8,
+			InitialBackoff:      2 * time.Second,
+			MaxBackoff:          time.Minute,
+			RetriableErrorCodes: []string{"PROVIDER_TIMEOUT", "PROVIDER_UNAVAILABLE"},
+		},
+	}, cfg.Controller.RetryPolicies)
+}
+
+func TestControllerConfigValidateWorkflowConcurrency(t *testing.T) {
+	cfg := ControllerConfig{Enabled: true}
+	cfg.SetDefaults()
+	cfg.WorkflowConcurrency = map[string]int{"batch": 2, "default": 10}
+	require.NoError(t, cfg.Validate())
+
+	cfg.WorkflowConcurrency = map[string]int{"preview": 2}
+	assert.ErrorContains(t, cfg.Validate(), `workflow_concurrency: unknown priority class "preview"`)
+
+	cfg.WorkflowConcurrency = map[string]int{"batch": 0}
+	assert.ErrorContains(t, cfg.Validate(), "workflow_concurrency.batch must be positive")
+}
+
+func TestControllerConfigValidateAdmission(t *testing.T) {
+	cfg := ControllerConfig{Enabled: true, Admission: AdmissionConfig{Enabled: true}}
+	cfg.SetDefaults()
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, 5*time.Minute, cfg.Admission.Window)
+	assert.Equal(t, 0.5, cfg.Admission.MaxErrorRate)
+	assert.Equal(t, 10, cfg.Admission.MinSamples)
+
+	cfg.Admission.MaxErrorRate = 1.5
+	assert.ErrorContains(t, cfg.Validate(), "admission: max_error_rate must be greater than 0 and at most 1")
+
+	cfg.Admission.MaxErrorRate = 0.5
+	cfg.Admission.MaxInFlight = -1
+	assert.ErrorContains(t, cfg.Validate(), "admission: max_in_flight must be non-negative")
+}
+
+func TestControllerConfigValidateTriggerOutbox(t *testing.T) {
+	cfg := ControllerConfig{Enabled: true, TriggerOutbox: TriggerOutboxConfig{Enabled: true}}
+	cfg.SetDefaults()
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, time.Second, cfg.TriggerOutbox.PollInterval)
+	assert.Equal(t, 50, cfg.TriggerOutbox.BatchSize)
+	assert.Equal(t, time.Minute, cfg.TriggerOutbox.ClaimTimeout)
+	assert.Equal(t, 10, cfg.TriggerOutbox.MaxAttempts)
+
+	cfg.TriggerOutbox.BatchSize = -1
+	assert.ErrorContains(t, cfg.Validate(), "trigger_outbox: batch_size must be positive")
+
+	cfg.TriggerOutbox.BatchSize = 50
+	cfg.TriggerOutbox.MaxAttempts = -1
+	assert.ErrorContains(t, cfg.Validate(), "trigger_outbox: max_attempts must be positive")
+}
+
+func TestControllerConfigValidateAdmin(t *testing.T) {
+	cfg := ControllerConfig{Enabled: true, Admin: ControllerAdminConfig{Enabled: true}}
+	cfg.SetDefaults()
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, "127.0.0.1:9091", cfg.Admin.Address)
+
+	cfg.Admin.Address = "9091"
+	assert.ErrorContains(t, cfg.Validate(), "admin: address must be host:port")
+}
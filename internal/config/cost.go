@@ -0,0 +1,35 @@
+package config
+
+import "fmt"
+
+// CostConfig prices tenants for cost estimates, such as those returned by /v1/simulate
+type CostConfig struct {
+	// Currency labels estimates (default USD)
+	Currency string `mapstructure:"currency"`
+
+	// Rates prices each compute provider's tenants, keyed by provider name. Tenants on providers
+	// without rates are not estimated.
+	Rates map[string]CostRateConfig `mapstructure:"rates"`
+}
+
+// CostRateConfig is what a compute provider charges per hour
+type CostRateConfig struct {
+	// ComponentHour is the flat hourly price of each running component
+	ComponentHour float64 `mapstructure:"component_hour"`
+
+	// CPUHour is the hourly price of one CPU of a component's limits.cpu
+	CPUHour float64 `mapstructure:"cpu_hour"`
+
+	// MemoryGBHour is the hourly price of one GiB of a component's limits.memory
+	MemoryGBHour float64 `mapstructure:"memory_gb_hour"`
+}
+
+// Validate validates cost configuration
+func (c *CostConfig) Validate() error {
+	for provider, rate := range c.Rates {
+		if rate.ComponentHour < 0 || rate.CPUHour < 0 || rate.MemoryGBHour < 0 {
+			return fmt.Errorf("rates.%s: prices must be >= 0", provider)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostConfigValidate(t *testing.T) {
+	valid := CostConfig{Rates: map[string]CostRateConfig{"docker": {ComponentHour: 0.01, CPUHour: 0.04, MemoryGBHour: 0.005}}}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, (&CostConfig{}).Validate())
+
+	negative := CostConfig{Rates: map[string]CostRateConfig{"ecs": {CPUHour: -1}}}
+	assert.ErrorContains(t, negative.Validate(), "rates.ecs: prices must be >= 0")
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// DataPlaneConfig holds tenant data plane module configuration.
+// A nil module config disables that module.
+type DataPlaneConfig struct {
+	Postgres *PostgresDataPlaneConfig `mapstructure:"postgres"`
+	Bucket   *BucketDataPlaneConfig   `mapstructure:"bucket"`
+}
+
+// PostgresDataPlaneConfig configures per-tenant Postgres schemas or databases
+type PostgresDataPlaneConfig struct {
+	// AdminURL is a connection URL for a role allowed to create roles, schemas and databases
+	AdminURL string `mapstructure:"admin_url" env:"DATAPLANE_POSTGRES_ADMIN_URL"`
+
+	// TenantHost overrides the host in tenant connection strings (e.g., the address reachable from tenant containers)
+	TenantHost string `mapstructure:"tenant_host"`
+
+	// Mode is "schema" (one schema per tenant in the admin database) or "database" (one database per tenant)
+	Mode string `mapstructure:"mode" default:"schema"`
+
+	// NamePrefix is prepended to tenant role, schema and database names
+	NamePrefix string `mapstructure:"name_prefix" default:"tenant_"`
+
+	// PasswordSecret derives stable per-tenant role passwords, so repeated provisioning does not rotate credentials
+	PasswordSecret string `mapstructure:"password_secret" env:"DATAPLANE_POSTGRES_PASSWORD_SECRET"`
+
+	// ArchivePolicy is "retain" (disable login, keep data) or "drop" (remove the schema or database and role)
+	ArchivePolicy string `mapstructure:"archive_policy" default:"retain"`
+
+	// EnvVar is the environment variable the connection string is injected as
+	EnvVar string `mapstructure:"env_var" default:"DATABASE_URL"`
+}
+
+// BucketDataPlaneConfig configures per-tenant object storage buckets or prefixes
+type BucketDataPlaneConfig struct {
+	// Provider is the object storage backend; only "s3" (including S3-compatible endpoints) is supported
+	Provider string `mapstructure:"provider" default:"s3"`
+
+	// Region is the bucket region
+	Region string `mapstructure:"region"`
+
+	// Endpoint overrides the S3 endpoint (e.g., MinIO); requests use path-style addressing
+	Endpoint string `mapstructure:"endpoint"`
+
+	// IAMEndpoint overrides the IAM endpoint used to manage tenant credentials
+	IAMEndpoint string `mapstructure:"iam_endpoint"`
+
+	// Mode is "bucket" (one bucket per tenant) or "prefix" (one prefix per tenant in SharedBucket)
+	Mode string `mapstructure:"mode" default:"bucket"`
+
+	// SharedBucket is the existing bucket tenant prefixes are created in; required in prefix mode
+	SharedBucket string `mapstructure:"shared_bucket"`
+
+	// NamePrefix is prepended to tenant bucket names, prefixes and IAM user names
+	NamePrefix string `mapstructure:"name_prefix" default:"landlord-"`
+
+	// CredentialsMode is "access_key" (scoped IAM user per tenant) or "none" (rely on the compute identity)
+	CredentialsMode string `mapstructure:"credentials_mode" default:"access_key"`
+
+	// ArchivePolicy is "retain" (revoke credentials, keep objects) or "drop" (also delete objects and bucket)
+	ArchivePolicy string `mapstructure:"archive_policy" default:"retain"`
+}
+
+// Validate validates data plane configuration
+func (d *DataPlaneConfig) Validate() error {
+	if d.Postgres != nil {
+		if err := d.Postgres.Validate(); err != nil {
+			return fmt.Errorf("postgres: %w", err)
+		}
+	}
+	if d.Bucket != nil {
+		if err := d.Bucket.Validate(); err != nil {
+			return fmt.Errorf("bucket: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate validates bucket data plane configuration
+func (b *BucketDataPlaneConfig) Validate() error {
+	switch b.Provider {
+	case "", "s3":
+	default:
+		return fmt.Errorf("invalid provider: %s (supported: s3)", b.Provider)
+	}
+	if b.Region == "" {
+		return fmt.Errorf("region is required")
+	}
+	switch b.Mode {
+	case "", "bucket":
+	case "prefix":
+		if b.SharedBucket == "" {
+			return fmt.Errorf("shared_bucket is required in prefix mode")
+		}
+	default:
+		return fmt.Errorf("invalid mode: %s (supported: bucket, prefix)", b.Mode)
+	}
+	switch b.CredentialsMode {
+	case "", "access_key", "none":
+	default:
+		return fmt.Errorf("invalid credentials_mode: %s (supported: access_key, none)", b.CredentialsMode)
+	}
+	switch b.ArchivePolicy {
+	case "", "retain", "drop":
+	default:
+		return fmt.Errorf("invalid archive_policy: %s (supported: retain, drop)", b.ArchivePolicy)
+	}
+	return nil
+}
+
+// Validate validates Postgres data plane configuration
+func (p *PostgresDataPlaneConfig) Validate() error {
+	if p.AdminURL == "" {
+		return fmt.Errorf("admin_url is required")
+	}
+	parsed, err := url.Parse(p.AdminURL)
+	if err != nil || (parsed.Scheme != "postgres" && parsed.Scheme != "postgresql") {
+		return fmt.Errorf("admin_url must be a postgres:// URL")
+	}
+	if p.PasswordSecret == "" {
+		return fmt.Errorf("password_secret is required")
+	}
+	switch p.Mode {
+	case "", "schema", "database":
+	default:
+		return fmt.Errorf("invalid mode: %s (supported: schema, database)", p.Mode)
+	}
+	switch p.ArchivePolicy {
+	case "", "retain", "drop":
+	default:
+		return fmt.Errorf("invalid archive_policy: %s (supported: retain, drop)", p.ArchivePolicy)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketDataPlaneConfigValidate(t *testing.T) {
+	valid := BucketDataPlaneConfig{Region: "us-west-2"}
+	assert.NoError(t, valid.Validate())
+
+	cases := map[string]struct {
+		config BucketDataPlaneConfig
+		err    string
+	}{
+		"missing region":       {BucketDataPlaneConfig{}, "region is required"},
+		"gcs not supported":    {BucketDataPlaneConfig{Provider: "gcs", Region: "us"}, "invalid provider"},
+		"prefix without share": {BucketDataPlaneConfig{Region: "us-west-2", Mode: "prefix"}, "shared_bucket is required"},
+		"bad credentials mode": {BucketDataPlaneConfig{Region: "us-west-2", CredentialsMode: "role"}, "invalid credentials_mode"},
+		"bad archive policy":   {BucketDataPlaneConfig{Region: "us-west-2", ArchivePolicy: "expire"}, "invalid archive_policy"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, tc.config.Validate(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DoctorConfig configures the diagnostics served from /v1/admin/doctor
+type DoctorConfig struct {
+	// StuckThreshold is how long a tenant may stay in an in-progress status before it is reported stuck (default 30m)
+	StuckThreshold time.Duration `mapstructure:"stuck_threshold"`
+
+	// CheckTimeout bounds each check; a check that runs longer is reported failed (default 10s)
+	CheckTimeout time.Duration `mapstructure:"check_timeout"`
+}
+
+// Validate validates doctor configuration
+func (c *DoctorConfig) Validate() error {
+	if c.StuckThreshold <= 0 {
+		return fmt.Errorf("stuck_threshold must be positive")
+	}
+	if c.CheckTimeout <= 0 {
+		return fmt.Errorf("check_timeout must be positive")
+	}
+	return nil
+}
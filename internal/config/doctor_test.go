@@ -0,0 +1,21 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoctorConfigValidate(t *testing.T) {
+	valid := DoctorConfig{StuckThreshold: 30 * time.Minute, CheckTimeout: 10 * time.Second}
+	assert.NoError(t, valid.Validate())
+
+	noThreshold := valid
+	noThreshold.StuckThreshold = 0
+	assert.ErrorContains(t, noThreshold.Validate(), "stuck_threshold must be positive")
+
+	noTimeout := valid
+	noTimeout.CheckTimeout = 0
+	assert.ErrorContains(t, noTimeout.Validate(), "check_timeout must be positive")
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// EgressMonitorConfig configures the controller that records tenants' denied egress as events
+type EgressMonitorConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Interval is how often the denied traffic of tenants with an egress policy is read (default 1m)
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// Validate validates egress monitor configuration
+func (c *EgressMonitorConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEgressMonitorConfigValidate(t *testing.T) {
+	disabled := EgressMonitorConfig{}
+	assert.NoError(t, disabled.Validate())
+
+	valid := EgressMonitorConfig{Enabled: true, Interval: time.Minute}
+	assert.NoError(t, valid.Validate())
+
+	noInterval := valid
+	noInterval.Interval = 0
+	assert.ErrorContains(t, noInterval.Validate(), "interval must be positive")
+}
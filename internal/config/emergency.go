@@ -0,0 +1,41 @@
+package config
+
+import "fmt"
+
+// EmergencyConfig configures emergency compute operations, which act on a tenant's compute directly
+// instead of through the workflow engine
+type EmergencyConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Actions are the emergency operations admins may run: destroy, stop (default both)
+	Actions []string `mapstructure:"actions"`
+}
+
+// Validate validates emergency configuration
+func (c *EmergencyConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	for i, action := range c.Actions {
+		if action != "destroy" && action != "stop" {
+			return fmt.Errorf("actions[%d]: unknown action %q, must be destroy or stop", i, action)
+		}
+	}
+	return nil
+}
+
+// Allows reports whether admins may run action; every action is allowed when Actions is empty
+func (c *EmergencyConfig) Allows(action string) bool {
+	if !c.Enabled {
+		return false
+	}
+	if len(c.Actions) == 0 {
+		return true
+	}
+	for _, allowed := range c.Actions {
+		if allowed == action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmergencyConfig(t *testing.T) {
+	disabled := EmergencyConfig{Actions: []string{"scale"}}
+	assert.NoError(t, disabled.Validate())
+	assert.False(t, disabled.Allows("destroy"))
+
+	defaults := EmergencyConfig{Enabled: true}
+	assert.NoError(t, defaults.Validate())
+	assert.True(t, defaults.Allows("destroy"))
+	assert.True(t, defaults.Allows("stop"))
+
+	stopOnly := EmergencyConfig{Enabled: true, Actions: []string{"stop"}}
+	assert.NoError(t, stopOnly.Validate())
+	assert.False(t, stopOnly.Allows("destroy"))
+	assert.True(t, stopOnly.Allows("stop"))
+
+	unknown := EmergencyConfig{Enabled: true, Actions: []string{"stop", "restart"}}
+	assert.ErrorContains(t, unknown.Validate(), `actions[1]: unknown action "restart"`)
+}
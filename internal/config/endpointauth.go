@@ -0,0 +1,35 @@
+package config
+
+import "fmt"
+
+// EndpointAuthConfig configures the credentials landlord generates for tenants that protect their endpoints.
+// The API server and the workflow worker must share the same secret.
+type EndpointAuthConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Secret derives per-tenant credentials, so every workflow injects the same credentials until they are rotated
+	Secret string `mapstructure:"secret" env:"ENDPOINT_AUTH_SECRET"`
+
+	// Username is the basic auth username for tenants that do not set one (default "landlord")
+	Username string `mapstructure:"username"`
+
+	// TraefikLabels also adds a Traefik basic auth middleware label to tenant containers
+	TraefikLabels bool `mapstructure:"traefik_labels"`
+}
+
+// Validate validates endpoint auth configuration
+func (c *EndpointAuthConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Secret == "" {
+		return fmt.Errorf("secret is required")
+	}
+	if len(c.Secret) < 16 {
+		return fmt.Errorf("secret must be at least 16 characters")
+	}
+	if c.Username == "" {
+		return fmt.Errorf("username is required")
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointAuthConfigValidate(t *testing.T) {
+	disabled := EndpointAuthConfig{}
+	assert.NoError(t, disabled.Validate())
+
+	valid := EndpointAuthConfig{Enabled: true, Secret: "0123456789abcdef", Username: "landlord"}
+	assert.NoError(t, valid.Validate())
+
+	noSecret := valid
+	noSecret.Secret = ""
+	assert.ErrorContains(t, noSecret.Validate(), "secret is required")
+
+	shortSecret := valid
+	shortSecret.Secret = "short"
+	assert.ErrorContains(t, shortSecret.Validate(), "at least 16 characters")
+
+	noUsername := valid
+	noUsername.Username = ""
+	assert.ErrorContains(t, noUsername.Validate(), "username is required")
+}
@@ -0,0 +1,43 @@
+package config
+
+import "fmt"
+
+// FeatureFlagsConfig declares the feature flags tenants may set with landlord.io/flags/<name>
+// annotations, besides the built-in ones
+type FeatureFlagsConfig struct {
+	// Flags are passed to workflows with the tenant's other flags, for workflows and providers that
+	// branch on them
+	Flags []FeatureFlagConfig `mapstructure:"flags"`
+}
+
+// FeatureFlagConfig declares one feature flag
+type FeatureFlagConfig struct {
+	// Name is the flag's name, the annotation key after landlord.io/flags/
+	Name string `mapstructure:"name"`
+
+	// Type is the type of the flag's value: bool, string or int
+	Type string `mapstructure:"type"`
+
+	// Description says what the flag does
+	Description string `mapstructure:"description"`
+}
+
+// Validate validates feature flag configuration
+func (c *FeatureFlagsConfig) Validate() error {
+	seen := make(map[string]bool, len(c.Flags))
+	for i, flag := range c.Flags {
+		if flag.Name == "" {
+			return fmt.Errorf("flags[%d]: name is required", i)
+		}
+		if seen[flag.Name] {
+			return fmt.Errorf("flags[%d]: duplicate flag %q", i, flag.Name)
+		}
+		seen[flag.Name] = true
+		switch flag.Type {
+		case "bool", "string", "int":
+		default:
+			return fmt.Errorf("flags[%d]: type must be bool, string or int", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// HousekeepingConfig configures the deletion of operational rows that are no longer needed, such as
+// published outbox entries and delivered compute callbacks
+type HousekeepingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Interval is how often old rows are deleted (default 1h)
+	Interval time.Duration `mapstructure:"interval"`
+
+	// BatchSize is the most rows one delete statement removes (default 500)
+	BatchSize int `mapstructure:"batch_size"`
+
+	// BatchPause is how long to wait between batches, so deletes do not saturate the database (default 100ms)
+	BatchPause time.Duration `mapstructure:"batch_pause"`
+
+	// PublishedOutboxAfter is how long published outbox entries are kept (default 168h, 7 days). 0 keeps them.
+	PublishedOutboxAfter time.Duration `mapstructure:"published_outbox_after"`
+
+	// DeliveredCallbacksAfter is how long delivered compute callbacks are kept (default 168h). 0 keeps them.
+	DeliveredCallbacksAfter time.Duration `mapstructure:"delivered_callbacks_after"`
+
+	// FailedAfter is how long outbox entries and compute callbacks that were given up on are kept
+	// after they were queued (default 720h, 30 days). 0 keeps them.
+	FailedAfter time.Duration `mapstructure:"failed_after"`
+}
+
+// Validate validates housekeeping configuration
+func (c *HousekeepingConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	if c.BatchSize < 1 {
+		return fmt.Errorf("batch_size must be at least 1")
+	}
+	if c.BatchPause < 0 {
+		return fmt.Errorf("batch_pause must not be negative")
+	}
+	if c.PublishedOutboxAfter < 0 || c.DeliveredCallbacksAfter < 0 || c.FailedAfter < 0 {
+		return fmt.Errorf("published_outbox_after, delivered_callbacks_after and failed_after must not be negative")
+	}
+	return nil
+}
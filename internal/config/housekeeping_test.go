@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHousekeepingConfigValidate(t *testing.T) {
+	disabled := HousekeepingConfig{}
+	assert.NoError(t, disabled.Validate())
+
+	valid := HousekeepingConfig{Enabled: true, Interval: time.Hour, BatchSize: 500, BatchPause: 100 * time.Millisecond, PublishedOutboxAfter: 168 * time.Hour, FailedAfter: 720 * time.Hour}
+	assert.NoError(t, valid.Validate())
+
+	noInterval := valid
+	noInterval.Interval = 0
+	assert.ErrorContains(t, noInterval.Validate(), "interval must be positive")
+
+	noBatch := valid
+	noBatch.BatchSize = 0
+	assert.ErrorContains(t, noBatch.Validate(), "batch_size must be at least 1")
+
+	negativePause := valid
+	negativePause.BatchPause = -time.Second
+	assert.ErrorContains(t, negativePause.Validate(), "batch_pause must not be negative")
+
+	negativeAge := valid
+	negativeAge.FailedAfter = -time.Hour
+	assert.ErrorContains(t, negativeAge.Validate(), "must not be negative")
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -13,8 +14,30 @@ type HTTPConfig struct {
 	WriteTimeout    time.Duration `mapstructure:"write_timeout" env:"HTTP_WRITE_TIMEOUT" default:"10s"`
 	IdleTimeout     time.Duration `mapstructure:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" default:"120s"`
 	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT" default:"30s"`
+
+	// RequestTimeout bounds how long a request may run before it fails with 504 Gateway Timeout.
+	// It must be shorter than WriteTimeout so the response can still be written. Zero disables it.
+	RequestTimeout time.Duration `mapstructure:"request_timeout" env:"HTTP_REQUEST_TIMEOUT" default:"8s"`
+
+	// RouteTimeouts overrides RequestTimeout for individual routes, keyed by "METHOD /pattern"
+	// (for example "POST /v1/tenants/{id}/migrate") or by "/pattern" for every method.
+	RouteTimeouts map[string]time.Duration `mapstructure:"route_timeouts"`
+
+	// ErrorFormat selects the error response body: problem (RFC 7807) or legacy.
+	// Clients that send Accept: application/problem+json always get problem details.
+	ErrorFormat string `mapstructure:"error_format" env:"HTTP_ERROR_FORMAT" default:"problem"`
+
+	// CompressionLevel is the gzip level (1-9) for responses to clients that accept gzip or
+	// deflate. Zero turns compression off.
+	CompressionLevel int `mapstructure:"compression_level" env:"HTTP_COMPRESSION_LEVEL" default:"5"`
 }
 
+// Error response formats
+const (
+	ErrorFormatProblem = "problem"
+	ErrorFormatLegacy  = "legacy"
+)
+
 // Validate validates HTTP configuration
 func (h *HTTPConfig) Validate() error {
 	if h.Port < 1 || h.Port > 65535 {
@@ -29,6 +52,36 @@ func (h *HTTPConfig) Validate() error {
 	if h.ShutdownTimeout < 0 {
 		return fmt.Errorf("shutdown timeout must be non-negative")
 	}
+	if err := h.validateRequestTimeout("request timeout", h.RequestTimeout); err != nil {
+		return err
+	}
+	for route, timeout := range h.RouteTimeouts {
+		if !strings.HasPrefix(route, "/") && !strings.Contains(route, " /") {
+			return fmt.Errorf("invalid route timeout key %q (must be \"METHOD /pattern\" or \"/pattern\")", route)
+		}
+		if err := h.validateRequestTimeout(fmt.Sprintf("route timeout for %q", route), timeout); err != nil {
+			return err
+		}
+	}
+	if h.CompressionLevel < 0 || h.CompressionLevel > 9 {
+		return fmt.Errorf("invalid compression level: %d (must be 0-9)", h.CompressionLevel)
+	}
+	switch h.ErrorFormat {
+	case "", ErrorFormatProblem, ErrorFormatLegacy:
+	default:
+		return fmt.Errorf("invalid error format: %q (must be %s or %s)", h.ErrorFormat, ErrorFormatProblem, ErrorFormatLegacy)
+	}
+	return nil
+}
+
+// validateRequestTimeout checks that a request timeout leaves time to write the 504 response
+func (h *HTTPConfig) validateRequestTimeout(name string, timeout time.Duration) error {
+	if timeout < 0 {
+		return fmt.Errorf("%s must be non-negative", name)
+	}
+	if timeout > 0 && h.WriteTimeout > 0 && timeout >= h.WriteTimeout {
+		return fmt.Errorf("%s (%s) must be shorter than write timeout (%s)", name, timeout, h.WriteTimeout)
+	}
 	return nil
 }
 
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPConfigValidateRequestTimeouts(t *testing.T) {
+	cfg := HTTPConfig{Port: 8080, WriteTimeout: 10 * time.Second, RequestTimeout: 8 * time.Second}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.RequestTimeout = 10 * time.Second
+	assert.ErrorContains(t, cfg.Validate(), "request timeout (10s) must be shorter than write timeout (10s)")
+
+	cfg.RequestTimeout = 0
+	cfg.RouteTimeouts = map[string]time.Duration{"POST /v1/tenants/{id}/migrate": 9 * time.Second, "/v1/tenants": time.Second}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.RouteTimeouts = map[string]time.Duration{"tenants": time.Second}
+	assert.ErrorContains(t, cfg.Validate(), `invalid route timeout key "tenants"`)
+
+	cfg.RouteTimeouts = map[string]time.Duration{"GET /v1/tenants": -time.Second}
+	assert.ErrorContains(t, cfg.Validate(), `route timeout for "GET /v1/tenants" must be non-negative`)
+
+	// Without a write timeout any request timeout is allowed
+	cfg = HTTPConfig{Port: 8080, RequestTimeout: time.Hour}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestHTTPConfigValidateCompressionLevel(t *testing.T) {
+	cfg := HTTPConfig{Port: 8080, CompressionLevel: 9}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.CompressionLevel = 10
+	assert.ErrorContains(t, cfg.Validate(), "invalid compression level: 10 (must be 0-9)")
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ImagePolicyConfig restricts which container images tenants may run.
+// The zero value allows every image.
+type ImagePolicyConfig struct {
+	// AllowedRepositories are glob patterns matched against an image's registry and repository,
+	// such as "ghcr.io/acme/*" or "docker.io/library/**". Empty allows every repository.
+	AllowedRepositories []string `mapstructure:"allowed_repositories"`
+
+	// TagRules restrict the tags of images run by tenants selected by label
+	TagRules []ImageTagRuleConfig `mapstructure:"tag_rules"`
+
+	// Signature requires images to carry a valid cosign signature
+	Signature ImageSignatureConfig `mapstructure:"signature"`
+
+	// ScanInterval is how often existing tenants are re-checked against the policy; 0 disables the scan
+	ScanInterval time.Duration `mapstructure:"scan_interval"`
+}
+
+// ImageTagRuleConfig is a tag restriction for the tenants whose labels match Selector
+type ImageTagRuleConfig struct {
+	// Name identifies the rule in violations
+	Name string `mapstructure:"name"`
+
+	// Selector matches tenant labels; empty applies the rule to every tenant
+	Selector map[string]string `mapstructure:"selector"`
+
+	// DenyTags are glob patterns of tags the selected tenants may not run, such as "latest"
+	DenyTags []string `mapstructure:"deny_tags"`
+
+	// RequireDigest requires images to be pinned by digest (image@sha256:...)
+	RequireDigest bool `mapstructure:"require_digest"`
+}
+
+// ImageSignatureConfig verifies image signatures by running cosign
+type ImageSignatureConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Selector matches tenant labels; empty verifies every tenant's images
+	Selector map[string]string `mapstructure:"selector"`
+
+	// Key is passed to "cosign verify --key": a public key file, KMS URI or k8s secret reference
+	Key string `mapstructure:"key"`
+
+	// CosignPath is the cosign binary (default "cosign" from PATH)
+	CosignPath string `mapstructure:"cosign_path"`
+
+	// Timeout bounds each verification (default 30s)
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// Enabled reports whether the policy restricts anything
+func (c *ImagePolicyConfig) Enabled() bool {
+	return len(c.AllowedRepositories) > 0 || len(c.TagRules) > 0 || c.Signature.Enabled
+}
+
+// Validate validates image policy configuration
+func (c *ImagePolicyConfig) Validate() error {
+	for i, pattern := range c.AllowedRepositories {
+		if pattern == "" {
+			return fmt.Errorf("allowed_repositories[%d]: pattern is empty", i)
+		}
+	}
+	names := make(map[string]bool)
+	for i, rule := range c.TagRules {
+		if rule.Name == "" {
+			return fmt.Errorf("tag_rules[%d]: name is required", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("tag_rules[%d]: duplicate name %q", i, rule.Name)
+		}
+		names[rule.Name] = true
+		if len(rule.DenyTags) == 0 && !rule.RequireDigest {
+			return fmt.Errorf("tag_rules[%d] (%s): deny_tags or require_digest is required", i, rule.Name)
+		}
+	}
+	if c.Signature.Enabled && c.Signature.Key == "" {
+		return fmt.Errorf("signature: key is required when verification is enabled")
+	}
+	if c.Signature.Timeout < 0 {
+		return fmt.Errorf("signature: timeout must not be negative")
+	}
+	if c.ScanInterval < 0 {
+		return fmt.Errorf("scan_interval must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImagePolicyConfigValidate(t *testing.T) {
+	var empty ImagePolicyConfig
+	assert.NoError(t, empty.Validate())
+	assert.False(t, empty.Enabled())
+
+	valid := ImagePolicyConfig{
+		AllowedRepositories: []string{"ghcr.io/acme/*"},
+		TagRules:            []ImageTagRuleConfig{{Name: "prod", Selector: map[string]string{"env": "prod"}, DenyTags: []string{"latest"}}},
+		Signature:           ImageSignatureConfig{Enabled: true, Key: "cosign.pub"},
+		ScanInterval:        time.Minute,
+	}
+	assert.NoError(t, valid.Validate())
+	assert.True(t, valid.Enabled())
+
+	cases := map[string]struct {
+		config ImagePolicyConfig
+		err    string
+	}{
+		"empty pattern":      {ImagePolicyConfig{AllowedRepositories: []string{""}}, "pattern is empty"},
+		"unnamed rule":       {ImagePolicyConfig{TagRules: []ImageTagRuleConfig{{DenyTags: []string{"latest"}}}}, "name is required"},
+		"duplicate rule":     {ImagePolicyConfig{TagRules: []ImageTagRuleConfig{{Name: "a", RequireDigest: true}, {Name: "a", RequireDigest: true}}}, "duplicate name"},
+		"rule without check": {ImagePolicyConfig{TagRules: []ImageTagRuleConfig{{Name: "a"}}}, "deny_tags or require_digest"},
+		"signature no key":   {ImagePolicyConfig{Signature: ImageSignatureConfig{Enabled: true}}, "key is required"},
+		"negative interval":  {ImagePolicyConfig{ScanInterval: -time.Second}, "scan_interval"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, tc.config.Validate(), tc.err)
+		})
+	}
+}
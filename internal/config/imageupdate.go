@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ImageUpdateConfig configures the controller that moves opted-in tenants to new image tags and digests
+type ImageUpdateConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Interval is how often registries are polled for new tags and digests (default 5m)
+	Interval time.Duration `mapstructure:"interval"`
+
+	// Registries are the registries watched for updates; images hosted elsewhere are never updated
+	Registries []ImageRegistryConfig `mapstructure:"registries"`
+}
+
+// ImageRegistryConfig is a container registry the image update controller may query
+type ImageRegistryConfig struct {
+	// Host is the registry as it appears in image references, such as "ghcr.io" or "docker.io"
+	Host string `mapstructure:"host"`
+
+	// Username and Password authenticate to the registry; empty uses anonymous access
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+
+	// Insecure talks plain HTTP to the registry, for local development registries
+	Insecure bool `mapstructure:"insecure"`
+}
+
+// Validate validates image update configuration
+func (c *ImageUpdateConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	if len(c.Registries) == 0 {
+		return fmt.Errorf("at least one registry is required when enabled")
+	}
+	hosts := make(map[string]bool)
+	for i, registry := range c.Registries {
+		if registry.Host == "" {
+			return fmt.Errorf("registries[%d]: host is required", i)
+		}
+		if hosts[registry.Host] {
+			return fmt.Errorf("registries[%d]: duplicate host %q", i, registry.Host)
+		}
+		hosts[registry.Host] = true
+		if (registry.Username == "") != (registry.Password == "") {
+			return fmt.Errorf("registries[%d] (%s): username and password must be set together", i, registry.Host)
+		}
+	}
+	return nil
+}
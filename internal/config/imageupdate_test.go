@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageUpdateConfigValidate(t *testing.T) {
+	var disabled ImageUpdateConfig
+	assert.NoError(t, disabled.Validate())
+
+	valid := ImageUpdateConfig{
+		Enabled:    true,
+		Interval:   5 * time.Minute,
+		Registries: []ImageRegistryConfig{{Host: "docker.io"}, {Host: "ghcr.io", Username: "bot", Password: "token"}},
+	}
+	assert.NoError(t, valid.Validate())
+
+	cases := map[string]struct {
+		config ImageUpdateConfig
+		err    string
+	}{
+		"no interval":      {ImageUpdateConfig{Enabled: true, Registries: []ImageRegistryConfig{{Host: "ghcr.io"}}}, "interval must be positive"},
+		"no registries":    {ImageUpdateConfig{Enabled: true, Interval: time.Minute}, "at least one registry"},
+		"empty host":       {ImageUpdateConfig{Enabled: true, Interval: time.Minute, Registries: []ImageRegistryConfig{{}}}, "host is required"},
+		"duplicate host":   {ImageUpdateConfig{Enabled: true, Interval: time.Minute, Registries: []ImageRegistryConfig{{Host: "ghcr.io"}, {Host: "ghcr.io"}}}, "duplicate host"},
+		"half credentials": {ImageUpdateConfig{Enabled: true, Interval: time.Minute, Registries: []ImageRegistryConfig{{Host: "ghcr.io", Username: "bot"}}}, "set together"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, tc.config.Validate(), tc.err)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// maxListCacheTTL bounds the list cache's TTL; it absorbs identical polls, not slow readers
+const maxListCacheTTL = time.Second
+
+// ListCacheConfig configures how tenant lists are cached, by clients through Cache-Control and
+// in-process by the API server
+type ListCacheConfig struct {
+	// MaxAge lets clients reuse a tenant list for this long without asking again. Zero, the
+	// default, sends Cache-Control: no-cache, so clients revalidate every list with its ETag.
+	MaxAge time.Duration `mapstructure:"max_age"`
+
+	// StaleWhileRevalidate lets clients use a list this long past MaxAge while they fetch a new one
+	StaleWhileRevalidate time.Duration `mapstructure:"stale_while_revalidate"`
+
+	// Enabled serves identical list requests from memory for TTL
+	Enabled bool `mapstructure:"enabled"`
+
+	// TTL bounds how long a cached list is served; at most 1s (default 500ms)
+	TTL time.Duration `mapstructure:"ttl"`
+
+	// MaxEntries bounds how many distinct lists are cached (default 1000)
+	MaxEntries int `mapstructure:"max_entries"`
+}
+
+// Validate validates list cache configuration
+func (c *ListCacheConfig) Validate() error {
+	if c.MaxAge < 0 {
+		return fmt.Errorf("max_age must be non-negative")
+	}
+	if c.StaleWhileRevalidate < 0 {
+		return fmt.Errorf("stale_while_revalidate must be non-negative")
+	}
+	if !c.Enabled {
+		return nil
+	}
+	if c.TTL <= 0 || c.TTL > maxListCacheTTL {
+		return fmt.Errorf("ttl must be positive and at most %s", maxListCacheTTL)
+	}
+	if c.MaxEntries < 1 {
+		return fmt.Errorf("max_entries must be at least 1")
+	}
+	return nil
+}
+
+// CacheControl returns the Cache-Control header for tenant lists. Lists depend on the caller's
+// API key, so shared caches must not store them.
+func (c *ListCacheConfig) CacheControl() string {
+	if c.MaxAge <= 0 && c.StaleWhileRevalidate <= 0 {
+		return "no-cache"
+	}
+	value := fmt.Sprintf("private, max-age=%d", int(c.MaxAge.Seconds()))
+	if c.StaleWhileRevalidate > 0 {
+		value += fmt.Sprintf(", stale-while-revalidate=%d", int(c.StaleWhileRevalidate.Seconds()))
+	}
+	return value
+}
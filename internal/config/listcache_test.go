@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListCacheConfigValidate(t *testing.T) {
+	disabled := ListCacheConfig{}
+	assert.NoError(t, disabled.Validate())
+
+	valid := ListCacheConfig{Enabled: true, TTL: 500 * time.Millisecond, MaxEntries: 100}
+	assert.NoError(t, valid.Validate())
+
+	longTTL := valid
+	longTTL.TTL = 2 * time.Second
+	assert.ErrorContains(t, longTTL.Validate(), "ttl must be positive and at most 1s")
+
+	noEntries := valid
+	noEntries.MaxEntries = 0
+	assert.ErrorContains(t, noEntries.Validate(), "max_entries must be at least 1")
+
+	negativeAge := disabled
+	negativeAge.MaxAge = -time.Second
+	assert.ErrorContains(t, negativeAge.Validate(), "max_age must be non-negative")
+}
+
+func TestListCacheConfigCacheControl(t *testing.T) {
+	assert.Equal(t, "no-cache", (&ListCacheConfig{}).CacheControl())
+	assert.Equal(t, "private, max-age=5", (&ListCacheConfig{MaxAge: 5 * time.Second}).CacheControl())
+	assert.Equal(t, "private, max-age=0, stale-while-revalidate=30", (&ListCacheConfig{StaleWhileRevalidate: 30 * time.Second}).CacheControl())
+}
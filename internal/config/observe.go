@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ObserveConfig configures the loop that refreshes ready tenants' observed state from their
+// compute providers
+type ObserveConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Interval is how often each ready tenant's compute status is read (default 5m)
+	Interval time.Duration `mapstructure:"interval"`
+
+	// ProviderIntervals overrides Interval for tenants of a compute provider, keyed by provider
+	// name. An interval of 0 stops refreshing that provider's tenants.
+	ProviderIntervals map[string]time.Duration `mapstructure:"provider_intervals"`
+
+	// Timeout bounds each compute status read (default 10s)
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// IntervalFor returns how often tenants of provider are refreshed, 0 meaning never
+func (c *ObserveConfig) IntervalFor(provider string) time.Duration {
+	if interval, ok := c.ProviderIntervals[provider]; ok {
+		return interval
+	}
+	return c.Interval
+}
+
+// Validate validates observed state refresh configuration
+func (c *ObserveConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+	for provider, interval := range c.ProviderIntervals {
+		if interval < 0 {
+			return fmt.Errorf("provider_intervals.%s must be non-negative", provider)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveConfigValidate(t *testing.T) {
+	disabled := ObserveConfig{}
+	assert.NoError(t, disabled.Validate())
+
+	valid := ObserveConfig{Enabled: true, Interval: 5 * time.Minute, Timeout: 10 * time.Second, ProviderIntervals: map[string]time.Duration{"ecs": 0}}
+	assert.NoError(t, valid.Validate())
+	assert.Equal(t, 5*time.Minute, valid.IntervalFor("docker"))
+	assert.Equal(t, time.Duration(0), valid.IntervalFor("ecs"))
+
+	noInterval := valid
+	noInterval.Interval = 0
+	assert.ErrorContains(t, noInterval.Validate(), "interval must be positive")
+
+	noTimeout := valid
+	noTimeout.Timeout = 0
+	assert.ErrorContains(t, noTimeout.Validate(), "timeout must be positive")
+
+	negative := valid
+	negative.ProviderIntervals = map[string]time.Duration{"docker": -time.Second}
+	assert.ErrorContains(t, negative.Validate(), "provider_intervals.docker must be non-negative")
+}
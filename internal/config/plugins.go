@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// PluginsConfig configures out-of-process compute and workflow provider plugins
+type PluginsConfig struct {
+	// Dir is scanned for plugin executables at startup. Empty disables plugins.
+	Dir string `mapstructure:"dir"`
+
+	// StartTimeout bounds how long a plugin may take to complete its handshake
+	StartTimeout time.Duration `mapstructure:"start_timeout"`
+}
+
+// Enabled reports whether a plugins directory is configured
+func (p *PluginsConfig) Enabled() bool {
+	return p.Dir != ""
+}
+
+// Validate validates plugins configuration
+func (p *PluginsConfig) Validate() error {
+	if !p.Enabled() {
+		return nil
+	}
+	info, err := os.Stat(p.Dir)
+	if err != nil {
+		return fmt.Errorf("dir: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("dir: %s is not a directory", p.Dir)
+	}
+	if p.StartTimeout <= 0 {
+		return fmt.Errorf("start_timeout must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"fmt"
+	"path"
+)
+
+// ComputeResolutionConfig chooses a compute provider for tenants that do not name one.
+// Rules are tried in order and the first match wins; tenants no rule matches get the default provider.
+type ComputeResolutionConfig struct {
+	Rules []ComputeResolutionRuleConfig `mapstructure:"rules"`
+}
+
+// ComputeResolutionRuleConfig sends the tenants it matches to Provider. Every matcher that is set
+// must match; a rule without matchers matches every tenant.
+type ComputeResolutionRuleConfig struct {
+	// Name identifies the rule in resolution explanations
+	Name string `mapstructure:"name"`
+
+	// Selector matches tenant labels
+	Selector map[string]string `mapstructure:"selector"`
+
+	// Annotations matches tenant annotations
+	Annotations map[string]string `mapstructure:"annotations"`
+
+	// NamePattern is a glob matched against the tenant name, such as "prod-*"
+	NamePattern string `mapstructure:"name_pattern"`
+
+	// Provider is the compute provider the matched tenants run on
+	Provider string `mapstructure:"provider"`
+}
+
+// Enabled reports whether any rules are configured
+func (c *ComputeResolutionConfig) Enabled() bool {
+	return len(c.Rules) > 0
+}
+
+// Validate validates compute resolution configuration
+func (c *ComputeResolutionConfig) Validate() error {
+	names := make(map[string]bool)
+	for i, rule := range c.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rules[%d]: name is required", i)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rules[%d]: duplicate name %q", i, rule.Name)
+		}
+		names[rule.Name] = true
+		if rule.Provider == "" {
+			return fmt.Errorf("rules[%d] (%s): provider is required", i, rule.Name)
+		}
+		if _, err := path.Match(rule.NamePattern, ""); err != nil {
+			return fmt.Errorf("rules[%d] (%s): invalid name_pattern %q: %w", i, rule.Name, rule.NamePattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeResolutionConfigValidate(t *testing.T) {
+	cfg := ComputeResolutionConfig{Rules: []ComputeResolutionRuleConfig{
+		{Name: "gpu", Selector: map[string]string{"tier": "gpu"}, Provider: "kubernetes"},
+		{Name: "prod", NamePattern: "prod-*", Provider: "ecs"},
+	}}
+	assert.True(t, cfg.Enabled())
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Rules[1].Name = "gpu"
+	assert.ErrorContains(t, cfg.Validate(), `rules[1]: duplicate name "gpu"`)
+
+	cfg.Rules[1].Name = ""
+	assert.ErrorContains(t, cfg.Validate(), "rules[1]: name is required")
+
+	cfg.Rules[1] = ComputeResolutionRuleConfig{Name: "prod", NamePattern: "prod-*"}
+	assert.ErrorContains(t, cfg.Validate(), "rules[1] (prod): provider is required")
+
+	cfg.Rules[1] = ComputeResolutionRuleConfig{Name: "prod", NamePattern: "prod-[", Provider: "ecs"}
+	assert.ErrorContains(t, cfg.Validate(), `rules[1] (prod): invalid name_pattern "prod-["`)
+
+	assert.False(t, (&ComputeResolutionConfig{}).Enabled())
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RetentionConfig configures the scrubbing of personal data from old state history snapshots and
+// provider audit entries
+type RetentionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Interval is how often records are scrubbed (default 1h)
+	Interval time.Duration `mapstructure:"interval"`
+
+	// ScrubAfter is how old a record must be before it is scrubbed (default 720h, 30 days)
+	ScrubAfter time.Duration `mapstructure:"scrub_after"`
+
+	// HistoryPaths are the dot-separated JSON paths redacted from state history snapshots, such as
+	// "env.*" for every env value. A "*" segment matches every field of an object or element of an array.
+	HistoryPaths []string `mapstructure:"history_paths"`
+
+	// AuditPaths are the paths redacted from the configuration recorded in provider audit entries
+	AuditPaths []string `mapstructure:"audit_paths"`
+}
+
+// Validate validates retention configuration
+func (c *RetentionConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	if c.ScrubAfter <= 0 {
+		return fmt.Errorf("scrub_after must be positive")
+	}
+	if len(c.HistoryPaths) == 0 && len(c.AuditPaths) == 0 {
+		return fmt.Errorf("history_paths or audit_paths must name at least one path")
+	}
+	for _, path := range append(append([]string(nil), c.HistoryPaths...), c.AuditPaths...) {
+		for _, segment := range strings.Split(path, ".") {
+			if strings.TrimSpace(segment) == "" {
+				return fmt.Errorf("%q is not a valid path", path)
+			}
+		}
+	}
+	return nil
+}
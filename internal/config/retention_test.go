@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetentionConfigValidate(t *testing.T) {
+	disabled := RetentionConfig{}
+	assert.NoError(t, disabled.Validate())
+
+	valid := RetentionConfig{Enabled: true, Interval: time.Hour, ScrubAfter: 720 * time.Hour, HistoryPaths: []string{"env.*"}}
+	assert.NoError(t, valid.Validate())
+
+	noInterval := valid
+	noInterval.Interval = 0
+	assert.ErrorContains(t, noInterval.Validate(), "interval must be positive")
+
+	noScrubAfter := valid
+	noScrubAfter.ScrubAfter = 0
+	assert.ErrorContains(t, noScrubAfter.Validate(), "scrub_after must be positive")
+
+	noPaths := valid
+	noPaths.HistoryPaths = nil
+	assert.ErrorContains(t, noPaths.Validate(), "at least one path")
+
+	emptySegment := valid
+	emptySegment.AuditPaths = []string{"config..token"}
+	assert.ErrorContains(t, emptySegment.Validate(), "not a valid path")
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScheduleConfig configures the controller that runs tenant schedules
+type ScheduleConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Interval is how often the controller looks for due schedules (default 30s)
+	Interval time.Duration `mapstructure:"interval"`
+
+	// RunTimeout bounds a single run; a run still marked running after it is considered
+	// abandoned and no longer blocks the schedule (default 30m)
+	RunTimeout time.Duration `mapstructure:"run_timeout"`
+}
+
+// Validate validates schedule configuration
+func (c *ScheduleConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	if c.RunTimeout <= 0 {
+		return fmt.Errorf("run_timeout must be positive")
+	}
+	return nil
+}
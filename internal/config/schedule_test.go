@@ -0,0 +1,22 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduleConfigValidate(t *testing.T) {
+	disabled := ScheduleConfig{}
+	assert.NoError(t, disabled.Validate())
+
+	valid := ScheduleConfig{Enabled: true, Interval: 30 * time.Second, RunTimeout: 30 * time.Minute}
+	assert.NoError(t, valid.Validate())
+
+	noInterval := ScheduleConfig{Enabled: true, RunTimeout: time.Minute}
+	assert.ErrorContains(t, noInterval.Validate(), "interval must be positive")
+
+	noTimeout := ScheduleConfig{Enabled: true, Interval: time.Second}
+	assert.ErrorContains(t, noTimeout.Validate(), "run_timeout must be positive")
+}
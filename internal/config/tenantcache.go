@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// TenantCacheConfig configures the in-process cache of tenants read by ID or name
+type TenantCacheConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// TTL bounds how long a cached tenant is served; changes made by other processes are normally
+	// seen sooner, through database notifications (default 5s)
+	TTL time.Duration `mapstructure:"ttl"`
+
+	// MaxEntries bounds how many tenants are cached; the ones closest to expiry are evicted first (default 10000)
+	MaxEntries int `mapstructure:"max_entries"`
+}
+
+// Validate validates tenant cache configuration
+func (c *TenantCacheConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.TTL <= 0 {
+		return fmt.Errorf("ttl must be positive")
+	}
+	if c.MaxEntries < 1 {
+		return fmt.Errorf("max_entries must be at least 1")
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantCacheConfigValidate(t *testing.T) {
+	disabled := TenantCacheConfig{}
+	assert.NoError(t, disabled.Validate())
+
+	valid := TenantCacheConfig{Enabled: true, TTL: 5 * time.Second, MaxEntries: 100}
+	assert.NoError(t, valid.Validate())
+
+	noTTL := valid
+	noTTL.TTL = 0
+	assert.ErrorContains(t, noTTL.Validate(), "ttl must be positive")
+
+	noEntries := valid
+	noEntries.MaxEntries = 0
+	assert.ErrorContains(t, noEntries.Validate(), "max_entries must be at least 1")
+}
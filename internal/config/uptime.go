@@ -0,0 +1,43 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// UptimeConfig configures the controller that probes ready tenants' endpoints
+type UptimeConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Interval is how often every ready tenant is checked (default 1m)
+	Interval time.Duration `mapstructure:"interval"`
+
+	// Timeout bounds each endpoint probe (default 5s)
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// FailureThreshold is the number of consecutive failed checks that marks a tenant EndpointUnhealthy (default 3)
+	FailureThreshold int `mapstructure:"failure_threshold"`
+
+	// HistorySize is how many checks per tenant are kept to compute availability (default 60)
+	HistorySize int `mapstructure:"history_size"`
+}
+
+// Validate validates uptime check configuration
+func (c *UptimeConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+	if c.FailureThreshold < 1 {
+		return fmt.Errorf("failure_threshold must be at least 1")
+	}
+	if c.HistorySize < 1 {
+		return fmt.Errorf("history_size must be at least 1")
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUptimeConfigValidate(t *testing.T) {
+	disabled := UptimeConfig{}
+	assert.NoError(t, disabled.Validate())
+
+	valid := UptimeConfig{Enabled: true, Interval: time.Minute, Timeout: 5 * time.Second, FailureThreshold: 3, HistorySize: 60}
+	assert.NoError(t, valid.Validate())
+
+	noInterval := valid
+	noInterval.Interval = 0
+	assert.ErrorContains(t, noInterval.Validate(), "interval must be positive")
+
+	noThreshold := valid
+	noThreshold.FailureThreshold = 0
+	assert.ErrorContains(t, noThreshold.Validate(), "failure_threshold must be at least 1")
+
+	noHistory := valid
+	noHistory.HistorySize = 0
+	assert.ErrorContains(t, noHistory.Validate(), "history_size must be at least 1")
+}
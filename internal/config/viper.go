@@ -29,6 +29,9 @@ func NewViperInstance() *viper.Viper {
 	v.SetDefault("http.write_timeout", "10s")
 	v.SetDefault("http.idle_timeout", "120s")
 	v.SetDefault("http.shutdown_timeout", "30s")
+	v.SetDefault("http.request_timeout", "8s")
+	v.SetDefault("http.error_format", ErrorFormatProblem)
+	v.SetDefault("http.compression_level", 5)
 
 	v.SetDefault("log.level", "info")
 	v.SetDefault("log.format", "development")
@@ -37,6 +40,75 @@ func NewViperInstance() *viper.Viper {
 	v.SetDefault("workflow.step_functions.region", "us-west-2")
 	v.SetDefault("workflow.restate.worker_register_on_startup", true)
 	v.SetDefault("workflow.restate.worker_compute_cache_ttl", "5m")
+	v.SetDefault("workflow.restate.worker_landlord_api_timeout", "10s")
+	v.SetDefault("workflow.restate.worker_landlord_api_retries", 3)
+	v.SetDefault("workflow.restate.worker_landlord_api_negative_cache_ttl", "30s")
+	v.SetDefault("workflow.restate.worker_heartbeat_timeout", "2m")
+	v.SetDefault("workflow.restate.worker_operation_timeout", "30m")
+	v.SetDefault("workflow.webhook.timeout", "10s")
+	v.SetDefault("workflow.callbacks.poll_interval", "1s")
+	v.SetDefault("workflow.callbacks.batch_size", 50)
+	v.SetDefault("workflow.callbacks.claim_timeout", "1m")
+	v.SetDefault("workflow.callbacks.max_attempts", 10)
+
+	v.SetDefault("controller.workflow_timeouts.provision", "15m")
+	v.SetDefault("controller.workflow_timeouts.archive", "10m")
+	v.SetDefault("controller.workflow_timeout_backoff", "30s")
+	v.SetDefault("controller.admission.window", "5m")
+	v.SetDefault("controller.admission.max_error_rate", 0.5)
+	v.SetDefault("controller.admission.min_samples", 10)
+	v.SetDefault("controller.chaos.stuck_threshold", "10m")
+	v.SetDefault("controller.chaos.check_interval", "30s")
+	v.SetDefault("controller.trigger_outbox.poll_interval", "1s")
+	v.SetDefault("controller.trigger_outbox.batch_size", 50)
+	v.SetDefault("controller.trigger_outbox.claim_timeout", "1m")
+	v.SetDefault("controller.trigger_outbox.max_attempts", 10)
+	v.SetDefault("controller.admin.address", "127.0.0.1:9091")
+
+	v.SetDefault("plugins.start_timeout", "10s")
+
+	v.SetDefault("image_update.interval", "5m")
+
+	v.SetDefault("schedules.interval", "30s")
+	v.SetDefault("schedules.run_timeout", "30m")
+
+	v.SetDefault("warm_pools.interval", "30s")
+
+	v.SetDefault("cost.currency", "USD")
+	v.SetDefault("uptime.interval", "1m")
+	v.SetDefault("uptime.timeout", "5s")
+	v.SetDefault("uptime.failure_threshold", 3)
+	v.SetDefault("uptime.history_size", 60)
+
+	v.SetDefault("endpoint_auth.username", "landlord")
+	v.SetDefault("callback_auth.tolerance", "5m")
+
+	v.SetDefault("egress_monitor.interval", "1m")
+
+	v.SetDefault("retention.interval", "1h")
+	v.SetDefault("retention.scrub_after", "720h")
+
+	v.SetDefault("housekeeping.interval", "1h")
+	v.SetDefault("housekeeping.batch_size", 500)
+	v.SetDefault("housekeeping.batch_pause", "100ms")
+	v.SetDefault("housekeeping.published_outbox_after", "168h")
+	v.SetDefault("housekeeping.delivered_callbacks_after", "168h")
+	v.SetDefault("housekeeping.failed_after", "720h")
+
+	v.SetDefault("tenant_cache.ttl", "5s")
+	v.SetDefault("tenant_cache.max_entries", 10000)
+
+	v.SetDefault("list_cache.ttl", "500ms")
+	v.SetDefault("list_cache.max_entries", 1000)
+
+	v.SetDefault("doctor.stuck_threshold", "30m")
+	v.SetDefault("doctor.check_timeout", "10s")
+
+	v.SetDefault("observe.interval", "5m")
+	v.SetDefault("observe.timeout", "10s")
+
+	v.SetDefault("capture.max_body_bytes", 1<<20)
+	v.SetDefault("capture.max_records", 100000)
 
 	return v
 }
@@ -100,6 +172,15 @@ func BindEnvironmentVariables(v *viper.Viper) error {
 	if err := v.BindEnv("http.shutdown_timeout", "HTTP_SHUTDOWN_TIMEOUT"); err != nil {
 		return fmt.Errorf("failed to bind HTTP_SHUTDOWN_TIMEOUT: %w", err)
 	}
+	if err := v.BindEnv("http.request_timeout", "HTTP_REQUEST_TIMEOUT"); err != nil {
+		return fmt.Errorf("failed to bind HTTP_REQUEST_TIMEOUT: %w", err)
+	}
+	if err := v.BindEnv("http.error_format", "HTTP_ERROR_FORMAT"); err != nil {
+		return fmt.Errorf("failed to bind HTTP_ERROR_FORMAT: %w", err)
+	}
+	if err := v.BindEnv("http.compression_level", "HTTP_COMPRESSION_LEVEL"); err != nil {
+		return fmt.Errorf("failed to bind HTTP_COMPRESSION_LEVEL: %w", err)
+	}
 
 	// Logging configuration
 	if err := v.BindEnv("log.level", "LOG_LEVEL"); err != nil {
@@ -119,6 +200,21 @@ func BindEnvironmentVariables(v *viper.Viper) error {
 	if err := v.BindEnv("workflow.step_functions.role_arn", "WORKFLOW_SFN_ROLE_ARN"); err != nil {
 		return fmt.Errorf("failed to bind WORKFLOW_SFN_ROLE_ARN: %w", err)
 	}
+	if err := v.BindEnv("workflow.webhook.url", "WORKFLOW_WEBHOOK_URL"); err != nil {
+		return fmt.Errorf("failed to bind WORKFLOW_WEBHOOK_URL: %w", err)
+	}
+	if err := v.BindEnv("workflow.webhook.secret", "WORKFLOW_WEBHOOK_SECRET"); err != nil {
+		return fmt.Errorf("failed to bind WORKFLOW_WEBHOOK_SECRET: %w", err)
+	}
+	if err := v.BindEnv("workflow.webhook.callback_url", "WORKFLOW_WEBHOOK_CALLBACK_URL"); err != nil {
+		return fmt.Errorf("failed to bind WORKFLOW_WEBHOOK_CALLBACK_URL: %w", err)
+	}
+	if err := v.BindEnv("workflow.webhook.timeout", "WORKFLOW_WEBHOOK_TIMEOUT"); err != nil {
+		return fmt.Errorf("failed to bind WORKFLOW_WEBHOOK_TIMEOUT: %w", err)
+	}
+	if err := v.BindEnv("callback_auth.tolerance", "CALLBACK_AUTH_TOLERANCE"); err != nil {
+		return fmt.Errorf("failed to bind CALLBACK_AUTH_TOLERANCE: %w", err)
+	}
 	if err := v.BindEnv("workflow.restate.endpoint", "WORKFLOW_RESTATE_ENDPOINT"); err != nil {
 		return fmt.Errorf("failed to bind WORKFLOW_RESTATE_ENDPOINT: %w", err)
 	}
@@ -164,10 +260,49 @@ func BindEnvironmentVariables(v *viper.Viper) error {
 	if err := v.BindEnv("workflow.restate.worker_compute_cache_ttl", "WORKFLOW_RESTATE_WORKER_COMPUTE_CACHE_TTL"); err != nil {
 		return fmt.Errorf("failed to bind WORKFLOW_RESTATE_WORKER_COMPUTE_CACHE_TTL: %w", err)
 	}
+	if err := v.BindEnv("workflow.restate.worker_landlord_api_key", "WORKFLOW_RESTATE_WORKER_LANDLORD_API_KEY"); err != nil {
+		return fmt.Errorf("failed to bind WORKFLOW_RESTATE_WORKER_LANDLORD_API_KEY: %w", err)
+	}
+	if err := v.BindEnv("workflow.restate.worker_landlord_api_timeout", "WORKFLOW_RESTATE_WORKER_LANDLORD_API_TIMEOUT"); err != nil {
+		return fmt.Errorf("failed to bind WORKFLOW_RESTATE_WORKER_LANDLORD_API_TIMEOUT: %w", err)
+	}
+	if err := v.BindEnv("workflow.restate.worker_landlord_api_retries", "WORKFLOW_RESTATE_WORKER_LANDLORD_API_RETRIES"); err != nil {
+		return fmt.Errorf("failed to bind WORKFLOW_RESTATE_WORKER_LANDLORD_API_RETRIES: %w", err)
+	}
+	if err := v.BindEnv("workflow.restate.worker_landlord_api_negative_cache_ttl", "WORKFLOW_RESTATE_WORKER_LANDLORD_API_NEGATIVE_CACHE_TTL"); err != nil {
+		return fmt.Errorf("failed to bind WORKFLOW_RESTATE_WORKER_LANDLORD_API_NEGATIVE_CACHE_TTL: %w", err)
+	}
+	if err := v.BindEnv("workflow.restate.worker_heartbeat_timeout", "WORKFLOW_RESTATE_WORKER_HEARTBEAT_TIMEOUT"); err != nil {
+		return fmt.Errorf("failed to bind WORKFLOW_RESTATE_WORKER_HEARTBEAT_TIMEOUT: %w", err)
+	}
+	if err := v.BindEnv("workflow.restate.worker_operation_timeout", "WORKFLOW_RESTATE_WORKER_OPERATION_TIMEOUT"); err != nil {
+		return fmt.Errorf("failed to bind WORKFLOW_RESTATE_WORKER_OPERATION_TIMEOUT: %w", err)
+	}
+	if err := v.BindEnv("workflow.restate.worker_abort_timeout", "WORKFLOW_RESTATE_WORKER_ABORT_TIMEOUT"); err != nil {
+		return fmt.Errorf("failed to bind WORKFLOW_RESTATE_WORKER_ABORT_TIMEOUT: %w", err)
+	}
 	if err := v.BindEnv("workflow.restate.worker_advertised_url", "WORKFLOW_RESTATE_WORKER_ADVERTISED_URL"); err != nil {
 		return fmt.Errorf("failed to bind WORKFLOW_RESTATE_WORKER_ADVERTISED_URL: %w", err)
 	}
 
+	// Data plane configuration
+	if err := v.BindEnv("dataplane.postgres.admin_url", "DATAPLANE_POSTGRES_ADMIN_URL"); err != nil {
+		return fmt.Errorf("failed to bind DATAPLANE_POSTGRES_ADMIN_URL: %w", err)
+	}
+	if err := v.BindEnv("dataplane.postgres.password_secret", "DATAPLANE_POSTGRES_PASSWORD_SECRET"); err != nil {
+		return fmt.Errorf("failed to bind DATAPLANE_POSTGRES_PASSWORD_SECRET: %w", err)
+	}
+
+	// Endpoint auth configuration
+	if err := v.BindEnv("endpoint_auth.secret", "ENDPOINT_AUTH_SECRET"); err != nil {
+		return fmt.Errorf("failed to bind ENDPOINT_AUTH_SECRET: %w", err)
+	}
+
+	// Plugins configuration
+	if err := v.BindEnv("plugins.dir", "PLUGINS_DIR"); err != nil {
+		return fmt.Errorf("failed to bind PLUGINS_DIR: %w", err)
+	}
+
 	return nil
 }
 
@@ -262,6 +262,31 @@ func TestLoadFromViper_DefaultValues(t *testing.T) {
 	assert.Equal(t, 8080, cfg.HTTP.Port)
 }
 
+func TestLoadFromViper_DataPlanePostgres(t *testing.T) {
+	v := NewViperInstance()
+	setComputeDefaults(v)
+	require.NoError(t, BindEnvironmentVariables(v))
+
+	cfg, err := LoadFromViper(v)
+	require.NoError(t, err)
+	assert.Nil(t, cfg.DataPlane.Postgres)
+
+	t.Setenv("DATAPLANE_POSTGRES_ADMIN_URL", "postgres://admin:secret@db:5432/postgres")
+	t.Setenv("DATAPLANE_POSTGRES_PASSWORD_SECRET", "derive-me")
+	v.Set("dataplane.postgres.mode", "database")
+
+	cfg, err = LoadFromViper(v)
+	require.NoError(t, err)
+	require.NotNil(t, cfg.DataPlane.Postgres)
+	assert.Equal(t, "postgres://admin:secret@db:5432/postgres", cfg.DataPlane.Postgres.AdminURL)
+	assert.Equal(t, "derive-me", cfg.DataPlane.Postgres.PasswordSecret)
+	assert.Equal(t, "database", cfg.DataPlane.Postgres.Mode)
+
+	v.Set("dataplane.postgres.archive_policy", "shred")
+	_, err = LoadFromViper(v)
+	assert.ErrorContains(t, err, "invalid archive_policy")
+}
+
 func TestConfigPrecedence_CLIOverridesEnv(t *testing.T) {
 	// This test demonstrates CLI flag precedence over env vars
 	// The actual precedence is tested through integration tests
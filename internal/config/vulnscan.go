@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Vulnerability scanner backends
+const (
+	VulnerabilityScannerTrivy = "trivy"
+	VulnerabilityScannerHTTP  = "http"
+)
+
+// VulnerabilityScanConfig configures the image scan that runs before a tenant is provisioned or updated
+type VulnerabilityScanConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Scanner is "trivy" to run the trivy CLI, or "http" to call a scanner API (default trivy)
+	Scanner string `mapstructure:"scanner"`
+
+	// TrivyPath is the trivy binary (default "trivy" from PATH)
+	TrivyPath string `mapstructure:"trivy_path"`
+
+	// URL is the scanner API endpoint, for the http scanner
+	URL string `mapstructure:"url"`
+
+	// Token is sent as a bearer token to the scanner API
+	Token string `mapstructure:"token"`
+
+	// Timeout bounds each scan (default 5m)
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// MaxCritical is the most critical vulnerabilities an image may have before it exceeds the threshold
+	MaxCritical int `mapstructure:"max_critical"`
+
+	// BlockProvisioning fails the workflow when an image exceeds the threshold, or cannot be scanned.
+	// Otherwise findings are only recorded on the tenant.
+	BlockProvisioning bool `mapstructure:"block_provisioning"`
+}
+
+// Validate validates vulnerability scan configuration
+func (c *VulnerabilityScanConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	switch c.Scanner {
+	case "", VulnerabilityScannerTrivy:
+	case VulnerabilityScannerHTTP:
+		if c.URL == "" {
+			return fmt.Errorf("url is required for the http scanner")
+		}
+	default:
+		return fmt.Errorf("invalid scanner %q (must be %s or %s)", c.Scanner, VulnerabilityScannerTrivy, VulnerabilityScannerHTTP)
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative")
+	}
+	if c.MaxCritical < 0 {
+		return fmt.Errorf("max_critical must not be negative")
+	}
+	return nil
+}
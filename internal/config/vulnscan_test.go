@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVulnerabilityScanConfigValidate(t *testing.T) {
+	var disabled VulnerabilityScanConfig
+	assert.NoError(t, disabled.Validate())
+	assert.NoError(t, (&VulnerabilityScanConfig{Enabled: true}).Validate())
+	assert.NoError(t, (&VulnerabilityScanConfig{Enabled: true, Scanner: VulnerabilityScannerHTTP, URL: "https://scanner.internal/scan"}).Validate())
+
+	cases := map[string]struct {
+		config VulnerabilityScanConfig
+		err    string
+	}{
+		"unknown scanner":  {VulnerabilityScanConfig{Enabled: true, Scanner: "grype"}, "invalid scanner"},
+		"http without url": {VulnerabilityScanConfig{Enabled: true, Scanner: VulnerabilityScannerHTTP}, "url is required"},
+		"negative maximum": {VulnerabilityScanConfig{Enabled: true, MaxCritical: -1}, "max_critical"},
+		"negative timeout": {VulnerabilityScanConfig{Enabled: true, Timeout: -1}, "timeout"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorContains(t, tc.config.Validate(), tc.err)
+		})
+	}
+}
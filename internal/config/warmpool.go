@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// WarmPoolConfig configures the controller that keeps project warm pools filled
+type WarmPoolConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// Interval is how often the controller refills warm pools (default 30s). Each pass starts
+	// at most a pool's refill_rate tenants.
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// Validate validates warm pool configuration
+func (c *WarmPoolConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarmPoolConfigValidate(t *testing.T) {
+	disabled := WarmPoolConfig{}
+	assert.NoError(t, disabled.Validate())
+
+	valid := WarmPoolConfig{Enabled: true, Interval: 30 * time.Second}
+	assert.NoError(t, valid.Validate())
+
+	noInterval := WarmPoolConfig{Enabled: true}
+	assert.ErrorContains(t, noInterval.Validate(), "interval must be positive")
+}
@@ -11,6 +11,8 @@ type WorkflowConfig struct {
 	DefaultProvider string              `mapstructure:"default_provider" env:"WORKFLOW_DEFAULT_PROVIDER" default:"mock"`
 	StepFunctions   StepFunctionsConfig `mapstructure:"step_functions"`
 	Restate         RestateConfig       `mapstructure:"restate"`
+	Webhook         WebhookConfig       `mapstructure:"webhook"`
+	Callbacks       CallbackConfig      `mapstructure:"callbacks"`
 }
 
 // StepFunctionsConfig holds AWS Step Functions provider configuration
@@ -38,14 +40,74 @@ type RestateConfig struct {
 	WorkerComputeProvider   string        `mapstructure:"worker_compute_provider" env:"WORKFLOW_RESTATE_WORKER_COMPUTE_PROVIDER"`
 	WorkerComputeCacheTTL   time.Duration `mapstructure:"worker_compute_cache_ttl" env:"WORKFLOW_RESTATE_WORKER_COMPUTE_CACHE_TTL" default:"5m"`
 	WorkerAdvertisedURL     string        `mapstructure:"worker_advertised_url" env:"WORKFLOW_RESTATE_WORKER_ADVERTISED_URL"`
+	// WorkerRegistrationInterval controls how often the worker verifies its deployment is still
+	// registered with Restate and re-registers it if missing (0 disables the check)
+	WorkerRegistrationInterval time.Duration `mapstructure:"worker_registration_interval" env:"WORKFLOW_RESTATE_WORKER_REGISTRATION_INTERVAL" default:"1m"`
+	// WorkerLandlordAPIKey is sent as a bearer token when the worker looks up tenants in the landlord API
+	WorkerLandlordAPIKey string `mapstructure:"worker_landlord_api_key" env:"WORKFLOW_RESTATE_WORKER_LANDLORD_API_KEY"`
+	// WorkerLandlordAPITimeout bounds each landlord API request
+	WorkerLandlordAPITimeout time.Duration `mapstructure:"worker_landlord_api_timeout" env:"WORKFLOW_RESTATE_WORKER_LANDLORD_API_TIMEOUT" default:"10s"`
+	// WorkerLandlordAPIRetries is how many times a tenant lookup is retried after a network error, 429 or 5xx
+	WorkerLandlordAPIRetries int `mapstructure:"worker_landlord_api_retries" env:"WORKFLOW_RESTATE_WORKER_LANDLORD_API_RETRIES" default:"3"`
+	// WorkerLandlordAPINegativeCacheTTL is how long a failed tenant lookup is remembered before the API is asked again (0 disables)
+	WorkerLandlordAPINegativeCacheTTL time.Duration `mapstructure:"worker_landlord_api_negative_cache_ttl" env:"WORKFLOW_RESTATE_WORKER_LANDLORD_API_NEGATIVE_CACHE_TTL" default:"30s"`
+	// WorkerHeartbeatTimeout is how long a compute operation that reports heartbeats may go without
+	// one before it is treated as stalled and cancelled (0 disables stall detection)
+	WorkerHeartbeatTimeout time.Duration `mapstructure:"worker_heartbeat_timeout" env:"WORKFLOW_RESTATE_WORKER_HEARTBEAT_TIMEOUT" default:"2m"`
+	// WorkerOperationTimeout bounds each compute operation however often it heartbeats (0 disables)
+	WorkerOperationTimeout time.Duration `mapstructure:"worker_operation_timeout" env:"WORKFLOW_RESTATE_WORKER_OPERATION_TIMEOUT" default:"30m"`
+	// WorkerAbortTimeout registers an abort timeout just above WorkerOperationTimeout with the
+	// worker's service, so Restate does not abort operations the worker still considers alive.
+	// Needs restate-server 1.4 or later.
+	WorkerAbortTimeout bool `mapstructure:"worker_abort_timeout" env:"WORKFLOW_RESTATE_WORKER_ABORT_TIMEOUT"`
+}
+
+// WebhookConfig holds configuration for the webhook workflow provider, which hands executions to an
+// external orchestrator such as Argo or Airflow and takes their status from signed callbacks
+type WebhookConfig struct {
+	// URL receives a signed POST for each execution to start or stop
+	URL string `mapstructure:"url" env:"WORKFLOW_WEBHOOK_URL"`
+	// Secret signs requests to URL with HMAC-SHA256. Unless callback_auth configures a "webhook"
+	// source, it also verifies the orchestrator's callbacks to /v1/workflow-callbacks.
+	Secret string `mapstructure:"secret" env:"WORKFLOW_WEBHOOK_SECRET"`
+	// CallbackURL is where the orchestrator posts status updates, passed with each execution
+	CallbackURL string `mapstructure:"callback_url" env:"WORKFLOW_WEBHOOK_CALLBACK_URL"`
+	// Timeout bounds each request to URL
+	Timeout time.Duration `mapstructure:"timeout" env:"WORKFLOW_WEBHOOK_TIMEOUT" default:"10s"`
+}
+
+// Validate validates webhook provider configuration
+func (c *WebhookConfig) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("url is required for the webhook provider")
+	}
+	if err := validateEndpointURL(c.URL); err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if c.CallbackURL != "" {
+		if err := validateEndpointURL(c.CallbackURL); err != nil {
+			return fmt.Errorf("invalid callback_url: %w", err)
+		}
+	}
+	if len(c.Secret) < 16 {
+		return fmt.Errorf("secret must be at least 16 characters")
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+	return nil
 }
 
 // Validate validates workflow configuration
 func (w *WorkflowConfig) Validate() error {
 	// Validate default provider value
-	validProviders := map[string]bool{"mock": true, "step-functions": true, "restate": true}
+	validProviders := map[string]bool{"mock": true, "step-functions": true, "restate": true, "webhook": true}
 	if !validProviders[w.DefaultProvider] {
-		return fmt.Errorf("invalid default_provider: %s (must be mock, step-functions, or restate)", w.DefaultProvider)
+		return fmt.Errorf("invalid default_provider: %s (must be mock, step-functions, restate, or webhook)", w.DefaultProvider)
+	}
+
+	if err := w.Callbacks.Validate(); err != nil {
+		return fmt.Errorf("callbacks config: %w", err)
 	}
 
 	// If Step Functions is the default provider, ensure RoleARN is configured
@@ -62,6 +124,13 @@ func (w *WorkflowConfig) Validate() error {
 		}
 	}
 
+	// The webhook provider is validated when it is the default or configured
+	if w.DefaultProvider == "webhook" || w.Webhook.URL != "" {
+		if err := w.Webhook.Validate(); err != nil {
+			return fmt.Errorf("webhook config: %w", err)
+		}
+	}
+
 	// Always validate Restate config if it's provided, even if not default
 	if w.Restate.Endpoint != "" {
 		if err := w.Restate.Validate(); err != nil {
@@ -155,6 +224,34 @@ func (r *RestateConfig) Validate() error {
 		return fmt.Errorf("worker_compute_cache_ttl must be non-negative")
 	}
 
+	if r.WorkerLandlordAPITimeout < 0 {
+		return fmt.Errorf("worker_landlord_api_timeout must be non-negative")
+	}
+
+	if r.WorkerLandlordAPIRetries < 0 {
+		return fmt.Errorf("worker_landlord_api_retries must be non-negative")
+	}
+
+	if r.WorkerLandlordAPINegativeCacheTTL < 0 {
+		return fmt.Errorf("worker_landlord_api_negative_cache_ttl must be non-negative")
+	}
+
+	if r.WorkerRegistrationInterval < 0 {
+		return fmt.Errorf("worker_registration_interval must be non-negative")
+	}
+
+	if r.WorkerHeartbeatTimeout < 0 {
+		return fmt.Errorf("worker_heartbeat_timeout must be non-negative")
+	}
+
+	if r.WorkerOperationTimeout < 0 {
+		return fmt.Errorf("worker_operation_timeout must be non-negative")
+	}
+
+	if r.WorkerAbortTimeout && r.WorkerOperationTimeout == 0 {
+		return fmt.Errorf("worker_abort_timeout requires worker_operation_timeout")
+	}
+
 	return nil
 }
 
@@ -156,6 +156,33 @@ func TestRestateWorkerConfigValidation(t *testing.T) {
 	}
 }
 
+func TestRestateWorkerHeartbeatValidation(t *testing.T) {
+	base := config.RestateConfig{
+		Endpoint:           "http://localhost:8080",
+		ExecutionMechanism: "local",
+		AuthType:           "none",
+		Timeout:            30 * time.Minute,
+	}
+
+	cfg := base
+	cfg.WorkerHeartbeatTimeout = 2 * time.Minute
+	cfg.WorkerOperationTimeout = 30 * time.Minute
+	cfg.WorkerAbortTimeout = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg = base
+	cfg.WorkerHeartbeatTimeout = -time.Second
+	assert.ErrorContains(t, cfg.Validate(), "worker_heartbeat_timeout must be non-negative")
+
+	cfg = base
+	cfg.WorkerOperationTimeout = -time.Second
+	assert.ErrorContains(t, cfg.Validate(), "worker_operation_timeout must be non-negative")
+
+	cfg = base
+	cfg.WorkerAbortTimeout = true
+	assert.ErrorContains(t, cfg.Validate(), "worker_abort_timeout requires worker_operation_timeout")
+}
+
 // TestEndpointURLValidation tests URL format validation
 func TestEndpointURLValidation(t *testing.T) {
 	tests := []struct {
@@ -450,3 +477,31 @@ func TestProviderConfigurationIsolation(t *testing.T) {
 	assert.Equal(t, cfg.Restate.ExecutionMechanism, "local")
 	assert.Equal(t, cfg.Restate.AuthType, "none")
 }
+
+// TestWebhookConfigValidation tests WebhookConfig.Validate() and when it applies
+func TestWebhookConfigValidation(t *testing.T) {
+	valid := config.WebhookConfig{
+		URL:         "https://argo.example.com/landlord",
+		Secret:      "0123456789abcdef",
+		CallbackURL: "https://landlord.example.com/v1/workflow-callbacks",
+		Timeout:     10 * time.Second,
+	}
+	assert.NoError(t, valid.Validate())
+
+	for name, mutate := range map[string]func(c *config.WebhookConfig){
+		"missing url":          func(c *config.WebhookConfig) { c.URL = "" },
+		"invalid url":          func(c *config.WebhookConfig) { c.URL = "argo.example.com" },
+		"invalid callback url": func(c *config.WebhookConfig) { c.CallbackURL = "ftp://landlord.example.com" },
+		"short secret":         func(c *config.WebhookConfig) { c.Secret = "secret" },
+		"zero timeout":         func(c *config.WebhookConfig) { c.Timeout = 0 },
+	} {
+		cfg := valid
+		mutate(&cfg)
+		assert.Error(t, cfg.Validate(), name)
+	}
+
+	// The webhook block is checked when it is the default provider or configured
+	assert.Error(t, (&config.WorkflowConfig{DefaultProvider: "webhook"}).Validate())
+	assert.NoError(t, (&config.WorkflowConfig{DefaultProvider: "webhook", Webhook: valid}).Validate())
+	assert.Error(t, (&config.WorkflowConfig{DefaultProvider: "mock", Webhook: config.WebhookConfig{URL: valid.URL}}).Validate())
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+// adminHandler serves /debug/vars, which holds the controller_workqueue and controller_reconcile
+// metrics, the /healthz and /readyz probes, and the pprof endpoints when they are enabled
+func adminHandler(cfg config.ControllerAdminConfig, ready func() bool) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !ready() {
+			http.Error(w, "controller is not running", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok\n"))
+	})
+	if cfg.Pprof {
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+	return mux
+}
+
+// startAdmin binds the admin listener and serves it until stopAdmin is called
+func (r *Reconciler) startAdmin() error {
+	listener, err := net.Listen("tcp", r.config.Admin.Address)
+	if err != nil {
+		return fmt.Errorf("listen on admin address %s: %w", r.config.Admin.Address, err)
+	}
+	r.admin = &http.Server{
+		Handler:           adminHandler(r.config.Admin, r.IsReady),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	r.adminAddr = listener.Addr().String()
+
+	r.logger.Info("starting controller admin listener",
+		zap.String("address", r.adminAddr),
+		zap.Bool("pprof", r.config.Admin.Pprof))
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		if err := r.admin.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			r.logger.Error("controller admin listener failed", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// stopAdmin shuts the admin listener down, if it was started
+func (r *Reconciler) stopAdmin() {
+	if r.admin == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.ShutdownTimeout)
+	defer cancel()
+	if err := r.admin.Shutdown(ctx); err != nil {
+		r.logger.Warn("controller admin listener shutdown failed", zap.Error(err))
+	}
+}
+
+// AdminAddr returns the address the admin listener is bound to, or "" when it is not running
+func (r *Reconciler) AdminAddr() string {
+	return r.adminAddr
+}
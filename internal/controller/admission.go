@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// admission holds new provisioning back while the workflow engine or compute providers are
+// saturated. It watches recent workflow trigger and execution results and the number of workflows
+// in flight. A nil admission admits everything.
+type admission struct {
+	cfg config.AdmissionConfig
+	now func() time.Time
+
+	mu       sync.Mutex
+	results  []admissionResult
+	inFlight int
+}
+
+// admissionResult is one workflow trigger or execution outcome
+type admissionResult struct {
+	at     time.Time
+	failed bool
+}
+
+// newAdmission creates admission control, returning nil when it is disabled
+func newAdmission(cfg config.AdmissionConfig) *admission {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &admission{cfg: cfg, now: time.Now}
+}
+
+// record notes a workflow trigger or execution outcome
+func (a *admission) record(failed bool) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.results = append(a.pruneLocked(), admissionResult{at: a.now(), failed: failed})
+}
+
+// observe replaces the count of workflows in flight with those recorded on tenants
+func (a *admission) observe(tenants []*tenant.Tenant) {
+	if a == nil {
+		return
+	}
+	inFlight := 0
+	for _, t := range tenants {
+		if isInFlightStatus(t.Status) && t.WorkflowExecutionID != nil && *t.WorkflowExecutionID != "" {
+			inFlight++
+		}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inFlight = inFlight
+}
+
+// admit reports whether a new provisioning workflow may start, and if not, why
+func (a *admission) admit() (string, bool) {
+	if a == nil {
+		return "", true
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cfg.MaxInFlight > 0 && a.inFlight >= a.cfg.MaxInFlight {
+		return fmt.Sprintf("%d workflows in flight, limit is %d", a.inFlight, a.cfg.MaxInFlight), false
+	}
+
+	a.results = a.pruneLocked()
+	if len(a.results) < a.cfg.MinSamples {
+		return "", true
+	}
+	failed := 0
+	for _, result := range a.results {
+		if result.failed {
+			failed++
+		}
+	}
+	if rate := float64(failed) / float64(len(a.results)); rate > a.cfg.MaxErrorRate {
+		return fmt.Sprintf("%d of the last %d workflows failed in %s", failed, len(a.results), a.cfg.Window), false
+	}
+	return "", true
+}
+
+// pruneLocked drops results older than the window
+func (a *admission) pruneLocked() []admissionResult {
+	cutoff := a.now().Add(-a.cfg.Window)
+	i := 0
+	for i < len(a.results) && a.results[i].at.Before(cutoff) {
+		i++
+	}
+	return a.results[i:]
+}
+
+// holdForCapacity parks a requested tenant in the pending-capacity sub-state. It is admitted on a
+// later poll once the workflow engine and compute providers recover.
+func (r *Reconciler) holdForCapacity(ctx context.Context, t *tenant.Tenant, reason string) error {
+	pending := string(workflow.SubStatePendingCapacity)
+	message := "Waiting for capacity: " + reason
+	if t.WorkflowSubState != nil && *t.WorkflowSubState == pending && t.StatusMessage == message {
+		return nil
+	}
+
+	r.logger.Info("holding provisioning until capacity recovers",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("tenant_name", t.Name),
+		zap.String("reason", reason))
+
+	t.WorkflowSubState = &pending
+	t.StatusMessage = message
+	if err := r.tenantRepo.UpdateTenant(ctx, t); err != nil && !errors.Is(err, tenant.ErrVersionConflict) {
+		return fmt.Errorf("update tenant: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAdmissionHoldsOnErrorRateAndInFlight(t *testing.T) {
+	now := time.Now()
+	a := newAdmission(config.AdmissionConfig{Enabled: true, Window: time.Minute, MaxErrorRate: 0.5, MinSamples: 4, MaxInFlight: 2})
+	a.now = func() time.Time { return now }
+
+	// Too few results to judge the error rate
+	a.record(true)
+	a.record(true)
+	a.record(true)
+	_, ok := a.admit()
+	require.True(t, ok)
+
+	a.record(false)
+	reason, ok := a.admit()
+	require.False(t, ok)
+	require.Equal(t, "3 of the last 4 workflows failed in 1m0s", reason)
+
+	// Failures age out of the window
+	now = now.Add(2 * time.Minute)
+	_, ok = a.admit()
+	require.True(t, ok)
+
+	executionID := "exec-1"
+	running := &tenant.Tenant{Status: tenant.StatusProvisioning, WorkflowExecutionID: &executionID}
+	a.observe([]*tenant.Tenant{running, running, {Status: tenant.StatusFailed, WorkflowExecutionID: &executionID}})
+	reason, ok = a.admit()
+	require.False(t, ok)
+	require.Equal(t, "2 workflows in flight, limit is 2", reason)
+
+	var disabled *admission
+	_, ok = disabled.admit()
+	require.True(t, ok)
+}
+
+func TestReconciler_HoldsProvisioningWhileSaturated(t *testing.T) {
+	repo := newMemoryTenantRepo()
+	client := &stoppingWorkflowClient{}
+	reconciler := NewReconciler(repo, &WorkflowClient{}, config.ControllerConfig{
+		Enabled:                true,
+		ReconciliationInterval: 100 * time.Millisecond,
+		StatusPollInterval:     100 * time.Millisecond,
+		Workers:                1,
+		WorkflowTriggerTimeout: 5 * time.Second,
+		ShutdownTimeout:        5 * time.Second,
+		MaxRetries:             3,
+		Admission:              config.AdmissionConfig{Enabled: true, Window: time.Minute, MaxErrorRate: 0.5, MinSamples: 2},
+	}, zaptest.NewLogger(t))
+	reconciler.workflowClient = client
+	now := time.Now()
+	reconciler.admission.now = func() time.Time { return now }
+
+	id := uuid.New()
+	require.NoError(t, repo.CreateTenant(context.Background(), &tenant.Tenant{
+		ID:            id,
+		Name:          "new-tenant",
+		Status:        tenant.StatusRequested,
+		DesiredConfig: map[string]interface{}{"image": "nginx:latest"},
+	}))
+
+	reconciler.admission.record(true)
+	reconciler.admission.record(true)
+	require.NoError(t, reconciler.reconcile(id.String()))
+	require.Zero(t, client.triggered)
+
+	held, err := repo.GetTenantByID(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusRequested, held.Status)
+	require.Equal(t, string(workflow.SubStatePendingCapacity), *held.WorkflowSubState)
+	require.Equal(t, "Waiting for capacity: 2 of the last 2 workflows failed in 1m0s", held.StatusMessage)
+
+	// Once the failures leave the window the tenant is admitted on its next reconcile
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, reconciler.reconcile(id.String()))
+	require.Equal(t, 1, client.triggered)
+
+	admitted, err := repo.GetTenantByID(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusProvisioning, admitted.Status)
+	require.Equal(t, string(workflow.SubStateRunning), *admitted.WorkflowSubState)
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/execution"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// archivalPhases maps the phases of a graceful stop, reported by workers as execution steps, to sub-states
+var archivalPhases = map[string]workflow.WorkflowSubState{
+	compute.PhasePreStop:  workflow.SubStatePreStop,
+	compute.PhaseDraining: workflow.SubStateDraining,
+	compute.PhaseStopping: workflow.SubStateStopping,
+}
+
+// SetExecutionSteps lets the reconciler show which phase of a graceful stop an archiving or
+// deleting tenant's workflow is in, from the steps workers report
+func (r *Reconciler) SetExecutionSteps(store execution.Store) {
+	r.executionSteps = store
+}
+
+// archivalPhase returns the sub-state of an archiving or deleting tenant whose compute is being
+// stopped gracefully, from its execution's latest running step
+func (r *Reconciler) archivalPhase(ctx context.Context, t *tenant.Tenant) (workflow.WorkflowSubState, bool) {
+	if r.executionSteps == nil || t.WorkflowExecutionID == nil {
+		return "", false
+	}
+	if t.Status != tenant.StatusArchiving && t.Status != tenant.StatusDeleting {
+		return "", false
+	}
+
+	steps, err := r.executionSteps.ListSteps(ctx, *t.WorkflowExecutionID)
+	if err != nil {
+		if !errors.Is(err, execution.ErrNotFound) {
+			r.logger.Warn("failed to list execution steps",
+				zap.String("tenant_id", t.ID.String()),
+				zap.String("execution_id", *t.WorkflowExecutionID),
+				zap.Error(err))
+		}
+		return "", false
+	}
+	for i := len(steps) - 1; i >= 0; i-- {
+		if steps[i].Status != workflow.StepRunning {
+			continue
+		}
+		subState, ok := archivalPhases[steps[i].Name]
+		return subState, ok
+	}
+	return "", false
+}
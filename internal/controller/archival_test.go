@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/execution"
+	executionmemory "github.com/jaxxstorm/landlord/internal/execution/memory"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+func TestArchivalPhase(t *testing.T) {
+	ctx := context.Background()
+	r := &Reconciler{logger: zaptest.NewLogger(t)}
+	executionID := "inv_archive"
+	archiving := &tenant.Tenant{ID: uuid.New(), Status: tenant.StatusArchiving, WorkflowExecutionID: &executionID}
+
+	// Without an execution store there is nothing to read phases from
+	_, ok := r.archivalPhase(ctx, archiving)
+	require.False(t, ok)
+
+	store := executionmemory.New()
+	r.SetExecutionSteps(store)
+	_, ok = r.archivalPhase(ctx, archiving)
+	require.False(t, ok)
+
+	record := func(name string, status workflow.StepStatus) {
+		step := &execution.Step{ExecutionID: executionID, TenantID: archiving.ID, Name: name, Status: status, StartedAt: time.Now()}
+		if status != workflow.StepRunning {
+			finished := time.Now()
+			step.FinishedAt = &finished
+		}
+		require.NoError(t, store.RecordStep(ctx, step))
+	}
+
+	// Steps that are not phases of a graceful stop leave the sub-state alone
+	record(workflow.StepDestroy, workflow.StepRunning)
+	_, ok = r.archivalPhase(ctx, archiving)
+	require.False(t, ok)
+
+	record(workflow.StepDestroy, workflow.StepSucceeded)
+	record(compute.PhasePreStop, workflow.StepRunning)
+	phase, ok := r.archivalPhase(ctx, archiving)
+	require.True(t, ok)
+	require.Equal(t, workflow.SubStatePreStop, phase)
+
+	record(compute.PhasePreStop, workflow.StepSucceeded)
+	record(compute.PhaseDraining, workflow.StepRunning)
+	phase, ok = r.archivalPhase(ctx, archiving)
+	require.True(t, ok)
+	require.Equal(t, workflow.SubStateDraining, phase)
+
+	// Only archiving and deleting tenants stop their compute gracefully
+	provisioning := *archiving
+	provisioning.Status = tenant.StatusProvisioning
+	_, ok = r.archivalPhase(ctx, &provisioning)
+	require.False(t, ok)
+
+	record(compute.PhaseDraining, workflow.StepSucceeded)
+	record(compute.PhaseStopping, workflow.StepRunning)
+	phase, ok = r.archivalPhase(ctx, archiving)
+	require.True(t, ok)
+	require.Equal(t, workflow.SubStateStopping, phase)
+}
@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// Invariants checked in chaos mode
+const (
+	InvariantTenantStuck      = "tenant_stuck"
+	InvariantDuplicateTrigger = "duplicate_workflow_trigger"
+)
+
+// errDroppedCallback stands in for a workflow status result that never arrived
+var errDroppedCallback = errors.New("chaos: workflow status dropped")
+
+// Violation is a broken reconciler invariant found in chaos mode
+type Violation struct {
+	Invariant  string    `json:"invariant"`
+	TenantID   string    `json:"tenant_id"`
+	Detail     string    `json:"detail"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// chaos injects faults into reconciliation. A nil *chaos injects nothing, so callers need no checks.
+type chaos struct {
+	cfg    config.ChaosConfig
+	logger *zap.Logger
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func newChaos(cfg config.ChaosConfig, logger *zap.Logger) *chaos {
+	if !cfg.Enabled {
+		return nil
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	logger = logger.With(zap.String("component", "chaos"))
+	logger.Warn("chaos mode enabled, reconciliation faults will be injected",
+		zap.Int64("seed", seed),
+		zap.Float64("delay_rate", cfg.DelayRate),
+		zap.Duration("max_delay", cfg.MaxDelay),
+		zap.Float64("drop_callback_rate", cfg.DropCallbackRate),
+		zap.Float64("version_conflict_rate", cfg.VersionConflictRate))
+
+	return &chaos{
+		cfg:    cfg,
+		logger: logger,
+		rng:    rand.New(rand.NewSource(seed)),
+	}
+}
+
+// roll reports whether a fault with the given rate should fire
+func (c *chaos) roll(rate float64) bool {
+	if c == nil || rate <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rng.Float64() < rate
+}
+
+// delay sleeps for a random duration up to MaxDelay at DelayRate
+func (c *chaos) delay(ctx context.Context, tenantID string) error {
+	if c == nil || !c.roll(c.cfg.DelayRate) {
+		return nil
+	}
+	c.mu.Lock()
+	d := time.Duration(c.rng.Int63n(int64(c.cfg.MaxDelay) + 1))
+	c.mu.Unlock()
+
+	c.logger.Info("injecting reconcile delay", zap.String("tenant_id", tenantID), zap.Duration("delay", d))
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// chaosRepository rejects tenant updates and fenced triggers with version conflicts at VersionConflictRate
+type chaosRepository struct {
+	tenant.Repository
+	chaos *chaos
+}
+
+func (r *chaosRepository) UpdateTenant(ctx context.Context, t *tenant.Tenant) error {
+	if r.chaos.roll(r.chaos.cfg.VersionConflictRate) {
+		r.chaos.logger.Info("injecting version conflict", zap.String("tenant_id", t.ID.String()), zap.Int("version", t.Version))
+		return tenant.ErrVersionConflict
+	}
+	return r.Repository.UpdateTenant(ctx, t)
+}
+
+func (r *chaosRepository) FenceWorkflowTrigger(ctx context.Context, t *tenant.Tenant, trigger func(ctx context.Context, t *tenant.Tenant) error) error {
+	if r.chaos.roll(r.chaos.cfg.VersionConflictRate) {
+		r.chaos.logger.Info("injecting fenced trigger version conflict", zap.String("tenant_id", t.ID.String()), zap.Int("version", t.Version))
+		return tenant.ErrVersionConflict
+	}
+	return r.Repository.FenceWorkflowTrigger(ctx, t, trigger)
+}
+
+// chaosWorkflowClient drops workflow status results at DropCallbackRate and records triggers for invariant checks
+type chaosWorkflowClient struct {
+	workflowClientInterface
+	chaos      *chaos
+	invariants *invariants
+}
+
+func (c *chaosWorkflowClient) TriggerWorkflow(ctx context.Context, t *tenant.Tenant, action string) (string, error) {
+	executionID, err := c.workflowClientInterface.TriggerWorkflow(ctx, t, action)
+	if err == nil {
+		c.invariants.recordTrigger(t, action, executionID)
+	}
+	return executionID, err
+}
+
+func (c *chaosWorkflowClient) TriggerWorkflowWithSource(ctx context.Context, t *tenant.Tenant, action, source string) (string, error) {
+	executionID, err := c.workflowClientInterface.TriggerWorkflowWithSource(ctx, t, action, source)
+	if err == nil {
+		c.invariants.recordTrigger(t, action, executionID)
+	}
+	return executionID, err
+}
+
+func (c *chaosWorkflowClient) GetExecutionStatus(ctx context.Context, executionID string) (*workflow.ExecutionStatus, error) {
+	status, err := c.workflowClientInterface.GetExecutionStatus(ctx, executionID)
+	if err == nil && c.chaos.roll(c.chaos.cfg.DropCallbackRate) {
+		c.chaos.logger.Info("dropping workflow status", zap.String("execution_id", executionID))
+		return nil, errDroppedCallback
+	}
+	return status, err
+}
+
+// invariants tracks reconciler behaviour and records violations
+type invariants struct {
+	stuckThreshold time.Duration
+	logger         *zap.Logger
+
+	mu         sync.Mutex
+	triggers   map[string]string // tenant ID, version and action -> first execution ID
+	statuses   map[string]observedStatus
+	violations []Violation
+}
+
+// observedStatus is when a tenant was first seen in its current status
+type observedStatus struct {
+	status   tenant.Status
+	since    time.Time
+	reported bool
+}
+
+func newInvariants(stuckThreshold time.Duration, logger *zap.Logger) *invariants {
+	return &invariants{
+		stuckThreshold: stuckThreshold,
+		logger:         logger.With(zap.String("component", "chaos-invariants")),
+		triggers:       make(map[string]string),
+		statuses:       make(map[string]observedStatus),
+	}
+}
+
+// recordTrigger flags a second workflow trigger for the same tenant version and action.
+// Each trigger must be followed by a tenant update, so the same version triggering twice means
+// two reconciles acted on the same state.
+func (i *invariants) recordTrigger(t *tenant.Tenant, action, executionID string) {
+	key := fmt.Sprintf("%s/%d/%s", t.ID, t.Version, action)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	first, seen := i.triggers[key]
+	if !seen {
+		i.triggers[key] = executionID
+		return
+	}
+	i.violate(InvariantDuplicateTrigger, t.ID.String(), fmt.Sprintf(
+		"%s workflow triggered twice at version %d (executions %s and %s)", action, t.Version, first, executionID))
+}
+
+// checkStuck flags tenants that stayed in one reconcilable status for longer than the threshold
+func (i *invariants) checkStuck(tenants []*tenant.Tenant, now time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	seen := make(map[string]bool, len(tenants))
+	for _, t := range tenants {
+		id := t.ID.String()
+		seen[id] = true
+
+		observed, ok := i.statuses[id]
+		if !ok || observed.status != t.Status {
+			i.statuses[id] = observedStatus{status: t.Status, since: now}
+			continue
+		}
+		if !observed.reported && tenant.ShouldReconcile(t.Status) && now.Sub(observed.since) > i.stuckThreshold {
+			observed.reported = true
+			i.statuses[id] = observed
+			i.violate(InvariantTenantStuck, id, fmt.Sprintf(
+				"tenant %s in %s for %s", t.Name, t.Status, now.Sub(observed.since).Round(time.Second)))
+		}
+	}
+	for id := range i.statuses {
+		if !seen[id] {
+			delete(i.statuses, id)
+		}
+	}
+}
+
+// violate records a violation; callers hold i.mu
+func (i *invariants) violate(invariant, tenantID, detail string) {
+	i.violations = append(i.violations, Violation{
+		Invariant:  invariant,
+		TenantID:   tenantID,
+		Detail:     detail,
+		DetectedAt: time.Now(),
+	})
+	i.logger.Error("reconciler invariant violated",
+		zap.String("invariant", invariant),
+		zap.String("tenant_id", tenantID),
+		zap.String("detail", detail))
+}
+
+func (i *invariants) list() []Violation {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return append([]Violation(nil), i.violations...)
+}
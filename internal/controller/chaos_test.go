@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+func TestChaos_DisabledInjectsNothing(t *testing.T) {
+	c := newChaos(config.ChaosConfig{DelayRate: 1, MaxDelay: time.Hour}, zap.NewNop())
+	if c != nil {
+		t.Fatal("expected nil chaos when disabled")
+	}
+	if c.roll(1) {
+		t.Error("nil chaos should never fire")
+	}
+	if err := c.delay(context.Background(), "tenant"); err != nil {
+		t.Errorf("nil chaos delay returned %v", err)
+	}
+}
+
+func TestChaos_VersionConflict(t *testing.T) {
+	updates := 0
+	inner := &mockTenantRepository{
+		updateTenantFunc: func(ctx context.Context, t *tenant.Tenant) error {
+			updates++
+			return nil
+		},
+	}
+
+	always := &chaosRepository{
+		Repository: inner,
+		chaos:      newChaos(config.ChaosConfig{Enabled: true, Seed: 1, VersionConflictRate: 1}, zap.NewNop()),
+	}
+	if err := always.UpdateTenant(context.Background(), &tenant.Tenant{ID: uuid.New()}); err != tenant.ErrVersionConflict {
+		t.Errorf("expected injected version conflict, got %v", err)
+	}
+
+	never := &chaosRepository{
+		Repository: inner,
+		chaos:      newChaos(config.ChaosConfig{Enabled: true, Seed: 1}, zap.NewNop()),
+	}
+	if err := never.UpdateTenant(context.Background(), &tenant.Tenant{ID: uuid.New()}); err != nil {
+		t.Errorf("expected update to pass through, got %v", err)
+	}
+	if updates != 1 {
+		t.Errorf("expected 1 update to reach the repository, got %d", updates)
+	}
+}
+
+func TestChaos_DroppedCallbackLeavesTenantUntouched(t *testing.T) {
+	provisioning := &tenant.Tenant{
+		ID:                  uuid.New(),
+		Name:                "test-tenant",
+		Status:              tenant.StatusProvisioning,
+		WorkflowExecutionID: stringPtr("exec-123"),
+	}
+
+	updates := 0
+	repo := &mockTenantRepository{
+		getTenantByIDFunc: func(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+			return provisioning, nil
+		},
+		updateTenantFunc: func(ctx context.Context, t *tenant.Tenant) error {
+			updates++
+			return nil
+		},
+	}
+	wfClient := &mockWorkflowClientForController{
+		getStatusFunc: func(ctx context.Context, executionID string) (*workflow.ExecutionStatus, error) {
+			return &workflow.ExecutionStatus{ExecutionID: executionID, State: workflow.StateSucceeded}, nil
+		},
+	}
+
+	c := newChaos(config.ChaosConfig{Enabled: true, Seed: 1, DropCallbackRate: 1}, zap.NewNop())
+	reconciler := &Reconciler{
+		tenantRepo:     repo,
+		workflowClient: &chaosWorkflowClient{workflowClientInterface: wfClient, chaos: c, invariants: newInvariants(time.Minute, zap.NewNop())},
+		logger:         zap.NewNop(),
+		ctx:            context.Background(),
+		chaos:          c,
+	}
+
+	if err := reconciler.reconcile(provisioning.ID.String()); err != nil {
+		t.Fatalf("reconcile should tolerate a dropped status: %v", err)
+	}
+	if updates != 0 {
+		t.Errorf("expected no tenant update when the status is dropped, got %d", updates)
+	}
+}
+
+func TestInvariants_DuplicateTrigger(t *testing.T) {
+	inv := newInvariants(time.Minute, zap.NewNop())
+	tn := &tenant.Tenant{ID: uuid.New(), Version: 3}
+
+	inv.recordTrigger(tn, "provision", "exec-1")
+	if got := inv.list(); len(got) != 0 {
+		t.Fatalf("expected no violations after first trigger, got %v", got)
+	}
+
+	inv.recordTrigger(tn, "provision", "exec-2")
+	got := inv.list()
+	if len(got) != 1 || got[0].Invariant != InvariantDuplicateTrigger {
+		t.Fatalf("expected one duplicate trigger violation, got %v", got)
+	}
+
+	// A new version may trigger again
+	tn.Version = 4
+	inv.recordTrigger(tn, "provision", "exec-3")
+	if got := inv.list(); len(got) != 1 {
+		t.Errorf("expected trigger at a new version to be allowed, got %v", got)
+	}
+}
+
+func TestInvariants_CheckStuck(t *testing.T) {
+	inv := newInvariants(time.Minute, zap.NewNop())
+	start := time.Now()
+
+	stuck := &tenant.Tenant{ID: uuid.New(), Name: "stuck", Status: tenant.StatusProvisioning}
+	moving := &tenant.Tenant{ID: uuid.New(), Name: "moving", Status: tenant.StatusProvisioning}
+	ready := &tenant.Tenant{ID: uuid.New(), Name: "ready", Status: tenant.StatusReady}
+
+	inv.checkStuck([]*tenant.Tenant{stuck, moving, ready}, start)
+
+	moving.Status = tenant.StatusReady
+	inv.checkStuck([]*tenant.Tenant{stuck, moving, ready}, start.Add(2*time.Minute))
+	inv.checkStuck([]*tenant.Tenant{stuck, moving, ready}, start.Add(3*time.Minute))
+
+	got := inv.list()
+	if len(got) != 1 {
+		t.Fatalf("expected one stuck violation reported once, got %v", got)
+	}
+	if got[0].Invariant != InvariantTenantStuck || got[0].TenantID != stuck.ID.String() {
+		t.Errorf("unexpected violation %+v", got[0])
+	}
+}
+
+func TestReconciler_ViolationsWithoutChaos(t *testing.T) {
+	reconciler := &Reconciler{}
+	if got := reconciler.Violations(); got != nil {
+		t.Errorf("expected no violations when chaos is disabled, got %v", got)
+	}
+}
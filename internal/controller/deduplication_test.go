@@ -3,6 +3,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"go.uber.org/zap"
@@ -18,6 +19,7 @@ type mockTenantRepository struct {
 	getTenantByNameFunc func(ctx context.Context, name string) (*tenant.Tenant, error)
 	updateTenantFunc    func(ctx context.Context, t *tenant.Tenant) error
 	deleteTenantFunc    func(ctx context.Context, id uuid.UUID) error
+	fenceTriggerFunc    func(ctx context.Context, t *tenant.Tenant, trigger func(ctx context.Context, t *tenant.Tenant) error) error
 }
 
 func (m *mockTenantRepository) GetTenantByID(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
@@ -41,6 +43,20 @@ func (m *mockTenantRepository) UpdateTenant(ctx context.Context, t *tenant.Tenan
 	return nil
 }
 
+func (m *mockTenantRepository) FenceWorkflowTrigger(ctx context.Context, t *tenant.Tenant, trigger func(ctx context.Context, t *tenant.Tenant) error) error {
+	if m.fenceTriggerFunc != nil {
+		return m.fenceTriggerFunc(ctx, t, trigger)
+	}
+	if err := trigger(ctx, t); err != nil {
+		return err
+	}
+	return m.UpdateTenant(ctx, t)
+}
+
+func (m *mockTenantRepository) LockTenant(ctx context.Context, id uuid.UUID) (func(), error) {
+	return func() {}, nil
+}
+
 func (m *mockTenantRepository) CreateTenant(ctx context.Context, t *tenant.Tenant) error {
 	return nil
 }
@@ -49,6 +65,10 @@ func (m *mockTenantRepository) ListTenants(ctx context.Context, filters tenant.L
 	return nil, nil
 }
 
+func (m *mockTenantRepository) ListTenantSummaries(ctx context.Context, filters tenant.ListFilters) ([]*tenant.Summary, error) {
+	return nil, nil
+}
+
 func (m *mockTenantRepository) ListTenantsForReconciliation(ctx context.Context) ([]*tenant.Tenant, error) {
 	return nil, nil
 }
@@ -57,7 +77,7 @@ func (m *mockTenantRepository) RecordStateTransition(ctx context.Context, transi
 	return nil
 }
 
-func (m *mockTenantRepository) GetStateHistory(ctx context.Context, tenantID uuid.UUID) ([]*tenant.StateTransition, error) {
+func (m *mockTenantRepository) GetStateHistory(ctx context.Context, tenantID uuid.UUID, filters tenant.HistoryFilters) ([]*tenant.StateTransition, error) {
 	return nil, nil
 }
 
@@ -518,6 +538,98 @@ func TestControllerNoExecutionIDTriggers(t *testing.T) {
 	}
 }
 
+// TestControllerSkipsFencedTrigger verifies that a tenant whose trigger lock is held by another
+// worker is skipped without triggering a second execution or counting as a failure
+func TestControllerSkipsFencedTrigger(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	for _, fenceErr := range []error{tenant.ErrWorkflowTriggerLocked, tenant.ErrVersionConflict} {
+		newTenant := &tenant.Tenant{
+			ID:     uuid.New(),
+			Name:   "test-tenant",
+			Status: tenant.StatusRequested,
+		}
+
+		triggerCount := 0
+		wfClient := &mockWorkflowClientForController{
+			triggerWithSourceFunc: func(ctx context.Context, t *tenant.Tenant, action, source string) (string, error) {
+				triggerCount++
+				return "exec-123", nil
+			},
+		}
+
+		updateCount := 0
+		tenantRepo := &mockTenantRepository{
+			getTenantByIDFunc: func(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+				return newTenant, nil
+			},
+			updateTenantFunc: func(ctx context.Context, t *tenant.Tenant) error {
+				updateCount++
+				return nil
+			},
+			fenceTriggerFunc: func(ctx context.Context, t *tenant.Tenant, trigger func(ctx context.Context, t *tenant.Tenant) error) error {
+				return fenceErr
+			},
+		}
+
+		reconciler := &Reconciler{
+			tenantRepo:     tenantRepo,
+			workflowClient: wfClient,
+			logger:         logger,
+			ctx:            context.Background(),
+		}
+
+		if err := reconciler.reconcile(newTenant.ID.String()); err != nil {
+			t.Errorf("reconcile with %v should not fail: %v", fenceErr, err)
+		}
+		if triggerCount != 0 {
+			t.Errorf("expected no trigger when fenced by %v, got %d", fenceErr, triggerCount)
+		}
+		if updateCount != 0 {
+			t.Errorf("expected no tenant update when fenced by %v, got %d", fenceErr, updateCount)
+		}
+	}
+}
+
+// TestControllerReportsUnrecordedExecution verifies that a trigger whose execution ID could not be
+// written is reported as an error naming the execution
+func TestControllerReportsUnrecordedExecution(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	newTenant := &tenant.Tenant{
+		ID:     uuid.New(),
+		Name:   "test-tenant",
+		Status: tenant.StatusRequested,
+	}
+
+	wfClient := &mockWorkflowClientForController{
+		triggerWithSourceFunc: func(ctx context.Context, t *tenant.Tenant, action, source string) (string, error) {
+			return "exec-123", nil
+		},
+	}
+
+	tenantRepo := &mockTenantRepository{
+		getTenantByIDFunc: func(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+			return newTenant, nil
+		},
+		updateTenantFunc: func(ctx context.Context, t *tenant.Tenant) error {
+			return tenant.ErrVersionConflict
+		},
+	}
+
+	reconciler := &Reconciler{
+		tenantRepo:     tenantRepo,
+		workflowClient: wfClient,
+		logger:         logger,
+		ctx:            context.Background(),
+	}
+
+	err := reconciler.reconcile(newTenant.ID.String())
+	if err == nil || !strings.Contains(err.Error(), "exec-123") {
+		t.Errorf("expected error naming execution exec-123, got %v", err)
+	}
+}
+
 // Helper function
 func stringPtr(s string) *string {
 	return &s
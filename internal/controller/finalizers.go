@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// Finalizer cleans up what a component created for a tenant, such as DNS records or ingress routes,
+// before the tenant is deleted or archived. Components outside the controller register finalizers on
+// tenants through the API and remove them when they are done instead.
+type Finalizer interface {
+	// Name is the finalizer's name on tenants
+	Name() string
+
+	// Finalize cleans up after t. It is retried until it succeeds, so it must be idempotent.
+	Finalize(ctx context.Context, t *tenant.Tenant) error
+}
+
+// RegisterFinalizer runs f for every tenant the reconciler deletes or archives, once the tenant's
+// workflow has succeeded. Must be called before Start.
+func (r *Reconciler) RegisterFinalizer(f Finalizer) error {
+	if err := tenant.ValidateFinalizerName(f.Name()); err != nil {
+		return err
+	}
+	for _, registered := range r.finalizers {
+		if registered.Name() == f.Name() {
+			return fmt.Errorf("finalizer %q is already registered", f.Name())
+		}
+	}
+	r.finalizers = append(r.finalizers, f)
+	return nil
+}
+
+// isFinalizing reports whether t's removal is waiting for its finalizers
+func isFinalizing(t *tenant.Tenant) bool {
+	return (t.Status == tenant.StatusDeleting || t.Status == tenant.StatusArchiving) &&
+		(t.WorkflowExecutionID == nil || *t.WorkflowExecutionID == "") &&
+		t.WorkflowSubState != nil && *t.WorkflowSubState == string(workflow.SubStateFinalizing)
+}
+
+// completeRemoval deletes or archives a tenant whose workflow has succeeded. Tenants with finalizers
+// move into finalization instead, and are removed once every finalizer is gone.
+func (r *Reconciler) completeRemoval(ctx context.Context, t *tenant.Tenant, message string) error {
+	for _, f := range r.finalizers {
+		t.AddFinalizer(f.Name())
+	}
+	if len(t.Finalizers) > 0 {
+		finalizing := string(workflow.SubStateFinalizing)
+		t.WorkflowExecutionID = nil
+		t.WorkflowSubState = &finalizing
+		t.WorkflowRetryCount = nil
+		t.WorkflowErrorMessage = nil
+		return r.finalize(ctx, t, true)
+	}
+	return r.removeTenant(ctx, t, message)
+}
+
+// finalize runs the registered finalizers t still has, then removes t when none are left. A failed
+// finalizer is recorded on t and run again on the next pass. changed reports whether t was changed
+// before, so it is written even when no finalizer is done.
+func (r *Reconciler) finalize(ctx context.Context, t *tenant.Tenant, changed bool) error {
+	var failures []string
+	for _, f := range r.finalizers {
+		if !t.HasFinalizer(f.Name()) {
+			continue
+		}
+		if err := f.Finalize(ctx, t); err != nil {
+			r.logger.Warn("finalizer failed, will retry",
+				zap.String("tenant_id", t.ID.String()),
+				zap.String("tenant_name", t.Name),
+				zap.String("finalizer", f.Name()),
+				zap.Error(err))
+			failures = append(failures, fmt.Sprintf("%s: %v", f.Name(), err))
+			continue
+		}
+		t.RemoveFinalizer(f.Name())
+		r.logger.Info("finalizer completed",
+			zap.String("tenant_id", t.ID.String()),
+			zap.String("tenant_name", t.Name),
+			zap.String("finalizer", f.Name()))
+	}
+
+	if len(t.Finalizers) == 0 {
+		succeeded := string(workflow.SubStateSucceeded)
+		t.WorkflowSubState = &succeeded
+		t.WorkflowErrorMessage = nil
+		return r.removeTenant(ctx, t, "Finalizers completed")
+	}
+
+	message := fmt.Sprintf("Waiting for finalizers: %s", strings.Join(t.Finalizers, ", "))
+	var errMsg *string
+	if len(failures) > 0 {
+		joined := strings.Join(failures, "; ")
+		errMsg = &joined
+	}
+	if !changed && t.StatusMessage == message && stringPtrEqual(t.WorkflowErrorMessage, errMsg) {
+		return nil
+	}
+	t.StatusMessage = message
+	t.WorkflowErrorMessage = errMsg
+	if err := r.tenantRepo.UpdateTenant(ctx, t); err != nil {
+		return fmt.Errorf("update tenant: %w", err)
+	}
+	return nil
+}
+
+// removeTenant deletes a deleting tenant, or one archived on the way to deletion, and archives an
+// archiving one
+func (r *Reconciler) removeTenant(ctx context.Context, t *tenant.Tenant, message string) error {
+	if t.Status == tenant.StatusDeleting {
+		if err := r.tenantRepo.DeleteTenant(ctx, t.ID); err != nil {
+			return fmt.Errorf("delete tenant after workflow: %w", err)
+		}
+		r.logger.Info("tenant deleted after workflow completion",
+			zap.String("tenant_id", t.ID.String()),
+			zap.String("tenant_name", t.Name),
+		)
+		return nil
+	}
+	if t.Annotations != nil && t.Annotations["landlord/delete_after_archive"] == "true" {
+		if err := r.tenantRepo.DeleteTenant(ctx, t.ID); err != nil {
+			return fmt.Errorf("delete tenant after archive workflow: %w", err)
+		}
+		r.logger.Info("tenant deleted after archive workflow completion",
+			zap.String("tenant_id", t.ID.String()),
+			zap.String("tenant_name", t.Name),
+		)
+		return nil
+	}
+
+	t.Status = tenant.StatusArchived
+	t.StatusMessage = message
+	if err := r.tenantRepo.UpdateTenant(ctx, t); err != nil {
+		return fmt.Errorf("update tenant: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/tenant/memory"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// testFinalizer fails while err is set and counts its runs
+type testFinalizer struct {
+	name string
+	err  error
+	runs int
+}
+
+func (f *testFinalizer) Name() string { return f.name }
+
+func (f *testFinalizer) Finalize(ctx context.Context, t *tenant.Tenant) error {
+	f.runs++
+	return f.err
+}
+
+func newFinalizerReconciler(t *testing.T) (*Reconciler, *memory.Repository) {
+	t.Helper()
+	repo := memory.New()
+	reconciler := NewReconciler(repo, &WorkflowClient{}, config.ControllerConfig{
+		Enabled:                true,
+		ReconciliationInterval: 100 * time.Millisecond,
+		StatusPollInterval:     100 * time.Millisecond,
+		Workers:                1,
+		WorkflowTriggerTimeout: 5 * time.Second,
+		ShutdownTimeout:        5 * time.Second,
+		MaxRetries:             3,
+	}, zaptest.NewLogger(t))
+	reconciler.workflowClient = &stubWorkflowClient{
+		execStatus: &workflow.ExecutionStatus{ExecutionID: "exec-remove", State: workflow.StateSucceeded},
+	}
+	return reconciler, repo
+}
+
+func createRemovedTenant(t *testing.T, repo tenant.Repository, status tenant.Status, finalizers ...string) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	executionID := "exec-remove"
+	require.NoError(t, repo.CreateTenant(context.Background(), &tenant.Tenant{
+		ID:                  id,
+		Name:                "finalize-" + id.String()[:8],
+		Status:              status,
+		DesiredConfig:       map[string]interface{}{"image": "nginx:latest"},
+		WorkflowExecutionID: &executionID,
+		Finalizers:          finalizers,
+	}))
+	return id
+}
+
+func TestReconciler_RegisterFinalizer(t *testing.T) {
+	reconciler, _ := newFinalizerReconciler(t)
+
+	require.NoError(t, reconciler.RegisterFinalizer(&testFinalizer{name: "dns"}))
+	require.ErrorContains(t, reconciler.RegisterFinalizer(&testFinalizer{name: "dns"}), "already registered")
+	require.ErrorContains(t, reconciler.RegisterFinalizer(&testFinalizer{name: "Ingress/Routes"}), "invalid finalizer name")
+}
+
+func TestReconciler_DeletionWaitsForFinalizers(t *testing.T) {
+	ctx := context.Background()
+	reconciler, repo := newFinalizerReconciler(t)
+	routes := &testFinalizer{name: "routes", err: errors.New("gateway unavailable")}
+	require.NoError(t, reconciler.RegisterFinalizer(routes))
+	id := createRemovedTenant(t, repo, tenant.StatusDeleting, "dns")
+
+	// The workflow succeeded, but the registered finalizer fails and an external one is still set
+	require.NoError(t, reconciler.reconcile(id.String()))
+	waiting, err := repo.GetTenantByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusDeleting, waiting.Status)
+	require.Nil(t, waiting.WorkflowExecutionID)
+	require.Equal(t, string(workflow.SubStateFinalizing), *waiting.WorkflowSubState)
+	require.Equal(t, []string{"dns", "routes"}, waiting.Finalizers)
+	require.Equal(t, "Waiting for finalizers: dns, routes", waiting.StatusMessage)
+	require.Equal(t, "routes: gateway unavailable", *waiting.WorkflowErrorMessage)
+
+	// Another pass retries the failed finalizer, and does not start another workflow
+	routes.err = nil
+	require.NoError(t, reconciler.reconcile(id.String()))
+	require.Equal(t, 2, routes.runs)
+	waiting, err = repo.GetTenantByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusDeleting, waiting.Status)
+	require.Nil(t, waiting.WorkflowExecutionID)
+	require.Equal(t, []string{"dns"}, waiting.Finalizers)
+	require.Equal(t, "Waiting for finalizers: dns", waiting.StatusMessage)
+	require.Nil(t, waiting.WorkflowErrorMessage)
+
+	// The tenant is deleted once the external component removes its finalizer
+	require.True(t, waiting.RemoveFinalizer("dns"))
+	require.NoError(t, repo.UpdateTenant(ctx, waiting))
+	require.NoError(t, reconciler.reconcile(id.String()))
+	require.Equal(t, 2, routes.runs)
+	_, err = repo.GetTenantByID(ctx, id)
+	require.ErrorIs(t, err, tenant.ErrTenantNotFound)
+}
+
+func TestReconciler_ArchivalWaitsForFinalizers(t *testing.T) {
+	ctx := context.Background()
+	reconciler, repo := newFinalizerReconciler(t)
+	id := createRemovedTenant(t, repo, tenant.StatusArchiving, "dns")
+
+	require.NoError(t, reconciler.reconcile(id.String()))
+	waiting, err := repo.GetTenantByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusArchiving, waiting.Status)
+	require.Equal(t, "Waiting for finalizers: dns", waiting.StatusMessage)
+
+	// A pass with nothing done leaves the tenant alone
+	version := waiting.Version
+	require.NoError(t, reconciler.reconcile(id.String()))
+	waiting, err = repo.GetTenantByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, version, waiting.Version)
+
+	require.True(t, waiting.RemoveFinalizer("dns"))
+	require.NoError(t, repo.UpdateTenant(ctx, waiting))
+	require.NoError(t, reconciler.reconcile(id.String()))
+	archived, err := repo.GetTenantByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusArchived, archived.Status)
+	require.Equal(t, "Finalizers completed", archived.StatusMessage)
+	require.Equal(t, string(workflow.SubStateSucceeded), *archived.WorkflowSubState)
+	require.Empty(t, archived.Finalizers)
+}
+
+func TestReconciler_ArchivalWithoutFinalizers(t *testing.T) {
+	ctx := context.Background()
+	reconciler, repo := newFinalizerReconciler(t)
+	id := createRemovedTenant(t, repo, tenant.StatusArchiving)
+
+	require.NoError(t, reconciler.reconcile(id.String()))
+	archived, err := repo.GetTenantByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusArchived, archived.Status)
+	require.Equal(t, "Workflow execution completed: exec-remove", archived.StatusMessage)
+}
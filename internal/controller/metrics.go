@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"math"
+	"sync"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// queueMetrics report each controller work queue, keyed by queue name, served from /debug/vars as
+// "controller_workqueue"
+var queueMetrics = expvar.NewMap("controller_workqueue")
+
+// reconcileMetrics count reconciliations and their errors by reason, served from /debug/vars as
+// "controller_reconcile"
+var reconcileMetrics = newReconcileStats(expvar.NewMap("controller_reconcile"))
+
+// errWorkflowTrigger marks errors returned by the workflow provider when triggering a workflow
+var errWorkflowTrigger = errors.New("trigger workflow")
+
+// Reconcile error reasons
+const (
+	reasonTimeout          = "timeout"
+	reasonCanceled         = "canceled"
+	reasonConflict         = "conflict"
+	reasonWorkflowTrigger  = "workflow_trigger"
+	reasonWorkflowProvider = "workflow_provider"
+	reasonChaos            = "chaos"
+	reasonOther            = "other"
+)
+
+// reconcileErrorReason classifies a reconcile error for the errors_by_reason metric
+func reconcileErrorReason(err error) string {
+	switch {
+	case errors.Is(err, errDroppedCallback):
+		return reasonChaos
+	case errors.Is(err, context.DeadlineExceeded):
+		return reasonTimeout
+	case errors.Is(err, context.Canceled):
+		return reasonCanceled
+	case errors.Is(err, tenant.ErrVersionConflict), errors.Is(err, tenant.ErrTenantLocked), errors.Is(err, tenant.ErrWorkflowTriggerLocked):
+		return reasonConflict
+	case errors.Is(err, workflow.ErrProviderNotFound), errors.Is(err, workflow.ErrProviderDisabled):
+		return reasonWorkflowProvider
+	case errors.Is(err, errWorkflowTrigger):
+		return reasonWorkflowTrigger
+	default:
+		return reasonOther
+	}
+}
+
+// reconcileStats tracks reconciliations across all of the process's reconcilers
+type reconcileStats struct {
+	reconciles      expvar.Int
+	errors          expvar.Int
+	retriesExceeded expvar.Int
+	nonRetriable    expvar.Int
+	durationMillis  expvar.Int
+	errorsByReason  *expvar.Map
+}
+
+func newReconcileStats(vars *expvar.Map) *reconcileStats {
+	s := &reconcileStats{errorsByReason: new(expvar.Map).Init()}
+	vars.Set("reconciles_total", &s.reconciles)
+	vars.Set("errors_total", &s.errors)
+	vars.Set("max_retries_exceeded_total", &s.retriesExceeded)
+	vars.Set("non_retriable_errors_total", &s.nonRetriable)
+	vars.Set("duration_ms_total", &s.durationMillis)
+	vars.Set("errors_by_reason", s.errorsByReason)
+	return s
+}
+
+// recordReconcile counts a reconciliation that took millis and failed with err, if not nil
+func (s *reconcileStats) recordReconcile(millis int64, err error) {
+	s.reconciles.Add(1)
+	s.durationMillis.Add(millis)
+	if err != nil {
+		s.errors.Add(1)
+		s.errorsByReason.Add(reconcileErrorReason(err), 1)
+	}
+}
+
+// queueMetricsProvider publishes the metrics client-go's workqueue keeps under queueMetrics
+type queueMetricsProvider struct {
+	mu     sync.Mutex
+	queues map[string]*expvar.Map
+}
+
+var workqueueMetrics = &queueMetricsProvider{queues: make(map[string]*expvar.Map)}
+
+// vars returns the named queue's metrics. A queue created again under the same name, as each
+// reconciler's is, replaces the previous one's.
+func (p *queueMetricsProvider) vars(name string) *expvar.Map {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	vars, ok := p.queues[name]
+	if !ok {
+		vars = new(expvar.Map).Init()
+		p.queues[name] = vars
+		queueMetrics.Set(name, vars)
+	}
+	return vars
+}
+
+func (p *queueMetricsProvider) publish(name, key string, v expvar.Var) {
+	p.vars(name).Set(key, v)
+}
+
+func (p *queueMetricsProvider) NewDepthMetric(name string) workqueue.GaugeMetric {
+	g := &gauge{}
+	p.publish(name, "depth", &g.value)
+	return g
+}
+
+func (p *queueMetricsProvider) NewAddsMetric(name string) workqueue.CounterMetric {
+	c := &gauge{}
+	p.publish(name, "adds_total", &c.value)
+	return c
+}
+
+func (p *queueMetricsProvider) NewLatencyMetric(name string) workqueue.HistogramMetric {
+	return p.newHistogram(name, "queue_latency_seconds")
+}
+
+func (p *queueMetricsProvider) NewWorkDurationMetric(name string) workqueue.HistogramMetric {
+	return p.newHistogram(name, "work_duration_seconds")
+}
+
+func (p *queueMetricsProvider) NewUnfinishedWorkSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	g := &settableGauge{}
+	p.publish(name, "unfinished_work_seconds", &g.value)
+	return g
+}
+
+func (p *queueMetricsProvider) NewLongestRunningProcessorSecondsMetric(name string) workqueue.SettableGaugeMetric {
+	g := &settableGauge{}
+	p.publish(name, "longest_running_processor_seconds", &g.value)
+	return g
+}
+
+func (p *queueMetricsProvider) NewRetriesMetric(name string) workqueue.CounterMetric {
+	c := &gauge{}
+	p.publish(name, "retries_total", &c.value)
+	return c
+}
+
+func (p *queueMetricsProvider) newHistogram(name, key string) workqueue.HistogramMetric {
+	h := &histogram{vars: new(expvar.Map).Init()}
+	h.vars.Set("count", &h.count)
+	h.vars.Set("sum", &h.sum)
+	h.vars.Set("max", &h.max)
+	p.publish(name, key, h.vars)
+	return h
+}
+
+// gauge is a workqueue gauge or counter
+type gauge struct {
+	value expvar.Int
+}
+
+func (g *gauge) Inc() { g.value.Add(1) }
+func (g *gauge) Dec() { g.value.Add(-1) }
+
+// settableGauge is a workqueue gauge set to its latest value
+type settableGauge struct {
+	value expvar.Float
+}
+
+func (g *settableGauge) Set(v float64) { g.value.Set(v) }
+
+// histogram summarizes workqueue observations as their count, sum and maximum, so rates and
+// means can be derived from successive scrapes
+type histogram struct {
+	vars  *expvar.Map
+	count expvar.Int
+	sum   expvar.Float
+
+	mu  sync.Mutex
+	max expvar.Float
+}
+
+func (h *histogram) Observe(v float64) {
+	h.count.Add(1)
+	h.sum.Add(v)
+	h.mu.Lock()
+	h.max.Set(math.Max(h.max.Value(), v))
+	h.mu.Unlock()
+}
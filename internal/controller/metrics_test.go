@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+func TestReconcileErrorReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("fetch tenant: %w", context.DeadlineExceeded), reasonTimeout},
+		{fmt.Errorf("update tenant: %w", tenant.ErrVersionConflict), reasonConflict},
+		{fmt.Errorf("%w: %w", errWorkflowTrigger, errors.New("connection refused")), reasonWorkflowTrigger},
+		{fmt.Errorf("%w: %w", errWorkflowTrigger, workflow.ErrProviderDisabled), reasonWorkflowProvider},
+		{errDroppedCallback, reasonChaos},
+		{errors.New("boom"), reasonOther},
+	}
+	for _, tt := range tests {
+		if got := reconcileErrorReason(tt.err); got != tt.want {
+			t.Errorf("reconcileErrorReason(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+	if got := fmt.Errorf("%w: %w", errWorkflowTrigger, errors.New("connection refused")).Error(); got != "trigger workflow: connection refused" {
+		t.Errorf("unexpected trigger error message %q", got)
+	}
+}
+
+func TestQueueMetrics(t *testing.T) {
+	q := NewRateLimitingQueue()
+	defer q.ShutDown()
+
+	q.Add("a")
+	q.Add("b")
+	metric := func(name string) string {
+		t.Helper()
+		vars, ok := queueMetrics.Get(reconcileQueueName).(*expvar.Map)
+		if !ok {
+			t.Fatal("expected the reconcile queue metrics to be published")
+		}
+		v := vars.Get(name)
+		if v == nil {
+			t.Fatalf("expected metric %s", name)
+		}
+		return v.String()
+	}
+	if depth := metric("depth"); depth != "2" {
+		t.Errorf("expected depth 2, got %s", depth)
+	}
+
+	item, _ := q.Get()
+	q.Done(item)
+	q.AddRateLimited(item)
+	if depth, adds := metric("depth"), metric("adds_total"); depth != "1" || adds != "2" {
+		t.Errorf("expected depth 1 and 2 adds, got %s and %s", depth, adds)
+	}
+	if retries := metric("retries_total"); retries != "1" {
+		t.Errorf("expected one retry, got %s", retries)
+	}
+	var latency struct{ Count int }
+	if err := json.Unmarshal([]byte(metric("queue_latency_seconds")), &latency); err != nil || latency.Count != 1 {
+		t.Errorf("expected one queue latency observation, got %s (%v)", metric("queue_latency_seconds"), err)
+	}
+}
+
+func TestAdminHandler(t *testing.T) {
+	reconcileMetrics.recordReconcile(5, fmt.Errorf("update tenant: %w", tenant.ErrVersionConflict))
+
+	get := func(handler http.Handler, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	ready := true
+	handler := adminHandler(config.ControllerAdminConfig{}, func() bool { return ready })
+	rec := get(handler, "/debug/vars")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected /debug/vars to be served, got %d", rec.Code)
+	}
+	var vars struct {
+		Reconcile struct {
+			ErrorsByReason map[string]int `json:"errors_by_reason"`
+		} `json:"controller_reconcile"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&vars); err != nil {
+		t.Fatalf("decode /debug/vars: %v", err)
+	}
+	if vars.Reconcile.ErrorsByReason[reasonConflict] < 1 {
+		t.Errorf("expected the conflict counted, got %v", vars.Reconcile.ErrorsByReason)
+	}
+	if rec := get(handler, "/debug/pprof/"); rec.Code != http.StatusNotFound {
+		t.Errorf("expected pprof to be off by default, got %d", rec.Code)
+	}
+
+	if rec := get(adminHandler(config.ControllerAdminConfig{Pprof: true}, func() bool { return true }), "/debug/pprof/"); rec.Code != http.StatusOK {
+		t.Errorf("expected pprof to be served when enabled, got %d", rec.Code)
+	}
+
+	for _, path := range []string{"/healthz", "/readyz"} {
+		if rec := get(handler, path); rec.Code != http.StatusOK {
+			t.Errorf("expected %s to pass, got %d", path, rec.Code)
+		}
+	}
+	ready = false
+	if rec := get(handler, "/healthz"); rec.Code != http.StatusOK {
+		t.Errorf("expected /healthz to pass while stopping, got %d", rec.Code)
+	}
+	if rec := get(handler, "/readyz"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected /readyz to fail while stopping, got %d", rec.Code)
+	}
+}
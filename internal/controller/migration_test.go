@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+func newMigratingTenant() *tenant.Tenant {
+	tn := &tenant.Tenant{
+		ID:                  uuid.New(),
+		Name:                "moving-tenant",
+		Status:              tenant.StatusMigrating,
+		DesiredConfig:       map[string]interface{}{"image": "nginx:latest", "compute_provider": "docker"},
+		ObservedConfig:      map[string]interface{}{"resource_ids": map[string]interface{}{"container_id": "old"}},
+		ObservedResourceIDs: map[string]string{"container_id": "old"},
+	}
+	tn.StartMigration("docker", "kubernetes")
+	return tn
+}
+
+func TestReconciler_MigrationRunsPhasesInOrder(t *testing.T) {
+	migrating := newMigratingTenant()
+
+	var phases []tenant.MigrationPhase
+	var providers []interface{}
+	wfClient := &mockWorkflowClientForController{
+		determineActionFunc: newTestWorkflowClient().DetermineAction,
+		triggerWithSourceFunc: func(ctx context.Context, tn *tenant.Tenant, action, source string) (string, error) {
+			if action != "migrate" {
+				t.Fatalf("expected migrate action, got %s", action)
+			}
+			phases = append(phases, tn.Migration().Phase)
+			providers = append(providers, tn.DesiredConfig["compute_provider"])
+			return "exec-" + string(tn.Migration().Phase), nil
+		},
+		getStatusFunc: func(ctx context.Context, executionID string) (*workflow.ExecutionStatus, error) {
+			output := json.RawMessage(`{"status":"ok"}`)
+			if executionID == "exec-"+string(tenant.MigrationPhaseProvisionTarget) {
+				output = json.RawMessage(`{"resource_ids":{"deployment":"new"}}`)
+			}
+			return &workflow.ExecutionStatus{ExecutionID: executionID, State: workflow.StateSucceeded, Output: output}, nil
+		},
+	}
+	repo := &mockTenantRepository{
+		getTenantByIDFunc: func(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+			return migrating, nil
+		},
+	}
+	reconciler := &Reconciler{
+		tenantRepo:     repo,
+		workflowClient: wfClient,
+		logger:         zap.NewNop(),
+		ctx:            context.Background(),
+	}
+
+	// Each phase takes one reconcile to trigger and one to observe its result
+	for i := 0; i < 6 && migrating.Status == tenant.StatusMigrating; i++ {
+		if err := reconciler.reconcile(migrating.ID.String()); err != nil {
+			t.Fatalf("reconcile %d: %v", i, err)
+		}
+	}
+
+	want := []tenant.MigrationPhase{tenant.MigrationPhaseProvisionTarget, tenant.MigrationPhaseSwitchover, tenant.MigrationPhaseDestroySource}
+	if len(phases) != len(want) {
+		t.Fatalf("expected phases %v, got %v", want, phases)
+	}
+	for i := range want {
+		if phases[i] != want[i] {
+			t.Fatalf("expected phases %v, got %v", want, phases)
+		}
+	}
+
+	// The tenant only moves to the target once the switchover phase has succeeded
+	wantProviders := []interface{}{"docker", "docker", "kubernetes"}
+	for i := range wantProviders {
+		if providers[i] != wantProviders[i] {
+			t.Fatalf("expected compute_provider %v at each phase, got %v", wantProviders, providers)
+		}
+	}
+
+	if migrating.Status != tenant.StatusReady {
+		t.Fatalf("expected ready after migration, got %s (%s)", migrating.Status, migrating.StatusMessage)
+	}
+	if migrating.Migration() != nil {
+		t.Errorf("expected migration record to be cleared, got %+v", migrating.Migration())
+	}
+	if migrating.DesiredConfig["compute_provider"] != "kubernetes" {
+		t.Errorf("expected compute_provider kubernetes, got %v", migrating.DesiredConfig["compute_provider"])
+	}
+	if manager := migrating.ManagedFields["compute_provider"].Manager; manager != migrationFieldManager {
+		t.Errorf("expected compute_provider managed by %s, got %q", migrationFieldManager, manager)
+	}
+	if migrating.ObservedResourceIDs["deployment"] != "new" || migrating.ObservedResourceIDs["container_id"] != "" {
+		t.Errorf("expected target resource IDs after switchover, got %v", migrating.ObservedResourceIDs)
+	}
+	if _, held := migrating.ObservedConfig[migrationTargetKey]; held {
+		t.Errorf("expected held target state to be promoted, got %v", migrating.ObservedConfig)
+	}
+}
+
+func TestReconciler_MigrationFailureKeepsPhase(t *testing.T) {
+	migrating := newMigratingTenant()
+	migrating.SetMigrationPhase(tenant.MigrationPhaseSwitchover)
+	migrating.WorkflowExecutionID = stringPtr("exec-switch")
+
+	wfClient := &mockWorkflowClientForController{
+		getStatusFunc: func(ctx context.Context, executionID string) (*workflow.ExecutionStatus, error) {
+			return &workflow.ExecutionStatus{
+				ExecutionID: executionID,
+				State:       workflow.StateFailed,
+				Error:       &workflow.ExecutionError{Message: "target compute is not ready for switchover"},
+			}, nil
+		},
+	}
+	repo := &mockTenantRepository{
+		getTenantByIDFunc: func(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+			return migrating, nil
+		},
+	}
+	reconciler := &Reconciler{
+		tenantRepo:     repo,
+		workflowClient: wfClient,
+		logger:         zap.NewNop(),
+		ctx:            context.Background(),
+	}
+
+	if err := reconciler.reconcile(migrating.ID.String()); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if migrating.Status != tenant.StatusFailed {
+		t.Fatalf("expected failed, got %s", migrating.Status)
+	}
+	if m := migrating.Migration(); m == nil || m.Phase != tenant.MigrationPhaseSwitchover {
+		t.Errorf("expected migration to stay at %s, got %+v", tenant.MigrationPhaseSwitchover, m)
+	}
+	if !strings.Contains(migrating.StatusMessage, "switching-endpoints") {
+		t.Errorf("expected failed phase in status message, got %q", migrating.StatusMessage)
+	}
+	if migrating.DesiredConfig["compute_provider"] != "docker" {
+		t.Errorf("expected tenant to stay on docker, got %v", migrating.DesiredConfig["compute_provider"])
+	}
+}
@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// outboxKindWorkflowTrigger is the outbox entry kind of a queued workflow trigger
+const outboxKindWorkflowTrigger = "workflow.trigger"
+
+// outboxRetryDelay is the delay after the first failed publish; it doubles up to maxOutboxRetryDelay
+const (
+	outboxRetryDelay    = time.Second
+	maxOutboxRetryDelay = 5 * time.Minute
+)
+
+// SetTriggerOutbox routes workflow triggers through outbox when controller.trigger_outbox is enabled.
+// It is usually the tenant repository itself; decorators such as the tenant cache hide the
+// optional interface, so it is passed separately.
+func (r *Reconciler) SetTriggerOutbox(outbox tenant.Outbox) {
+	r.triggerOutbox = outbox
+}
+
+// outboxEnabled reports whether workflow triggers go through the outbox
+func (r *Reconciler) outboxEnabled() bool {
+	return r.config.TriggerOutbox.Enabled && r.triggerOutbox != nil
+}
+
+// isTriggerQueued reports whether t waits for a queued workflow trigger to be published
+func isTriggerQueued(t *tenant.Tenant) bool {
+	return t.WorkflowSubState != nil && *t.WorkflowSubState == string(workflow.SubStateQueued) &&
+		(t.WorkflowExecutionID == nil || *t.WorkflowExecutionID == "")
+}
+
+// queueWorkflowTrigger records the trigger in the outbox in the same transaction as moving t into
+// the queued sub-state, so it is published even if this process stops right after. The dedup key
+// names the tenant version the trigger was decided on, so a retried reconcile cannot queue it twice.
+func (r *Reconciler) queueWorkflowTrigger(ctx context.Context, t *tenant.Tenant, action string) error {
+	tenantID := t.ID.String()
+	entry := &tenant.OutboxEntry{
+		TenantID: t.ID,
+		Kind:     outboxKindWorkflowTrigger,
+		DedupKey: fmt.Sprintf("workflow-trigger:%s:%d", tenantID, t.Version),
+		Payload:  map[string]interface{}{"action": action},
+	}
+
+	queued := string(workflow.SubStateQueued)
+	t.WorkflowSubState = &queued
+	t.WorkflowErrorMessage = nil
+	t.StatusMessage = fmt.Sprintf("Workflow %s queued", action)
+
+	err := r.triggerOutbox.UpdateTenantWithOutbox(ctx, t, entry)
+	if err != nil {
+		r.workflowSlots.release(tenantID)
+	}
+	if errors.Is(err, tenant.ErrVersionConflict) {
+		// The tenant changed since it was read; the next pass sees its latest state
+		r.logger.Info("workflow trigger not queued, tenant changed",
+			zap.String("tenant_id", tenantID),
+			zap.String("tenant_name", t.Name),
+			zap.String("action", action))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("queue workflow trigger: %w", err)
+	}
+
+	r.logger.Info("workflow trigger queued",
+		zap.String("tenant_id", tenantID),
+		zap.String("tenant_name", t.Name),
+		zap.String("action", action),
+		zap.String("dedup_key", entry.DedupKey))
+	return nil
+}
+
+// outboxLoop publishes queued workflow triggers until the reconciler stops
+func (r *Reconciler) outboxLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.TriggerOutbox.PollInterval)
+	defer ticker.Stop()
+
+	r.logger.Info("trigger outbox loop started")
+
+	for {
+		// A full batch suggests more are waiting, so the next one is claimed straight away
+		published, err := r.publishOutbox(r.ctx)
+		if err != nil && r.ctx.Err() == nil {
+			r.logger.Warn("trigger outbox publish failed", zap.Error(err))
+		}
+		if err == nil && published >= r.config.TriggerOutbox.BatchSize {
+			continue
+		}
+		select {
+		case <-r.ctx.Done():
+			r.logger.Info("trigger outbox loop stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishOutbox claims one batch of outbox entries and publishes them, returning how many it claimed
+func (r *Reconciler) publishOutbox(ctx context.Context) (int, error) {
+	cfg := r.config.TriggerOutbox
+	entries, err := r.triggerOutbox.ClaimOutboxEntries(ctx, cfg.BatchSize, cfg.ClaimTimeout, cfg.MaxAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("claim outbox entries: %w", err)
+	}
+	for _, entry := range entries {
+		r.publishOutboxEntry(ctx, entry)
+	}
+	return len(entries), nil
+}
+
+func (r *Reconciler) publishOutboxEntry(ctx context.Context, entry *tenant.OutboxEntry) {
+	logger := r.logger.With(
+		zap.String("tenant_id", entry.TenantID.String()),
+		zap.String("kind", entry.Kind),
+		zap.String("dedup_key", entry.DedupKey),
+		zap.Int("attempt", entry.Attempts),
+	)
+
+	// The claim bounds how long a publish may take, so no other dispatcher takes the entry meanwhile
+	publishCtx, cancel := context.WithTimeout(ctx, r.config.TriggerOutbox.ClaimTimeout)
+	var err error
+	switch entry.Kind {
+	case outboxKindWorkflowTrigger:
+		err = r.publishWorkflowTrigger(publishCtx, entry)
+	default:
+		err = fmt.Errorf("unknown outbox entry kind %q", entry.Kind)
+	}
+	cancel()
+
+	// Recording the outcome must not be cut short by Stop
+	recordCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err == nil {
+		if err := r.triggerOutbox.CompleteOutboxEntry(recordCtx, entry.ID, entry.ClaimToken); err != nil {
+			logger.Warn("failed to mark outbox entry published", zap.Error(err))
+			return
+		}
+		logger.Debug("outbox entry published")
+		return
+	}
+
+	retryAt := time.Now().Add(outboxBackoff(entry.Attempts))
+	if entry.Attempts >= r.config.TriggerOutbox.MaxAttempts {
+		logger.Error("giving up on outbox entry", zap.Error(err))
+		r.failQueuedTrigger(recordCtx, entry, err)
+	} else {
+		logger.Warn("outbox publish failed, will retry", zap.Time("retry_at", retryAt), zap.Error(err))
+	}
+	if failErr := r.triggerOutbox.FailOutboxEntry(recordCtx, entry.ID, entry.ClaimToken, err.Error(), retryAt); failErr != nil {
+		logger.Warn("failed to record outbox publish failure", zap.Error(failErr))
+	}
+}
+
+// publishWorkflowTrigger starts the queued workflow and records its execution on the tenant, fenced
+// like a direct trigger. A trigger the tenant no longer waits for, because it was deleted or changed
+// through the API since, is dropped. A publish retried after a crash reaches the provider with the
+// same execution name, which the workflow providers deduplicate.
+func (r *Reconciler) publishWorkflowTrigger(ctx context.Context, entry *tenant.OutboxEntry) error {
+	t, err := r.tenantRepo.GetTenantByID(ctx, entry.TenantID)
+	if errors.Is(err, tenant.ErrTenantNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("fetch tenant: %w", err)
+	}
+	if !isTriggerQueued(t) {
+		r.logger.Info("queued workflow trigger superseded, dropping",
+			zap.String("tenant_id", t.ID.String()),
+			zap.String("tenant_name", t.Name),
+			zap.String("dedup_key", entry.DedupKey))
+		return nil
+	}
+
+	action, ok := entry.Payload["action"].(string)
+	if !ok || action == "" {
+		return fmt.Errorf("outbox entry has no workflow action")
+	}
+
+	var executionID string
+	err = r.tenantRepo.FenceWorkflowTrigger(ctx, t, func(ctx context.Context, t *tenant.Tenant) error {
+		id, err := r.workflowClient.TriggerWorkflowWithSource(ctx, t, action, "controller:outbox")
+		if err != nil {
+			return fmt.Errorf("trigger workflow: %w", err)
+		}
+		executionID = id
+		r.recordTriggeredExecution(t, executionID, action, "controller:outbox")
+		return nil
+	})
+	if err != nil {
+		if executionID == "" && !errors.Is(err, tenant.ErrWorkflowTriggerLocked) && !errors.Is(err, tenant.ErrVersionConflict) {
+			r.admission.record(true)
+		}
+		return err
+	}
+	r.admission.record(false)
+
+	r.logger.Info("queued workflow trigger published",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("tenant_name", t.Name),
+		zap.String("action", action),
+		zap.String("execution_id", executionID))
+	return nil
+}
+
+// failQueuedTrigger marks a tenant failed once its queued trigger has used every publish attempt
+func (r *Reconciler) failQueuedTrigger(ctx context.Context, entry *tenant.OutboxEntry, cause error) {
+	if entry.Kind != outboxKindWorkflowTrigger {
+		return
+	}
+	r.workflowSlots.release(entry.TenantID.String())
+
+	t, err := r.tenantRepo.GetTenantByID(ctx, entry.TenantID)
+	if err != nil || !isTriggerQueued(t) {
+		return
+	}
+	failed := string(workflow.SubStateFailed)
+	message := cause.Error()
+	t.Status = tenant.StatusFailed
+	t.WorkflowSubState = &failed
+	t.WorkflowErrorMessage = &message
+	t.StatusMessage = fmt.Sprintf("Workflow trigger failed after %d attempts: %s", entry.Attempts, message)
+	if err := r.tenantRepo.UpdateTenant(ctx, t); err != nil {
+		r.logger.Warn("failed to mark tenant failed after queued trigger gave up",
+			zap.String("tenant_id", t.ID.String()),
+			zap.Error(err))
+	}
+}
+
+// outboxBackoff returns the delay before the publish after the given attempt
+func outboxBackoff(attempt int) time.Duration {
+	delay := outboxRetryDelay
+	for i := 1; i < attempt && delay < maxOutboxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxOutboxRetryDelay)
+}
@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/tenant/memory"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// outboxWorkflowClient counts triggers by source, failing while err is set
+type outboxWorkflowClient struct {
+	stubWorkflowClient
+	err     error
+	sources []string
+}
+
+func (c *outboxWorkflowClient) TriggerWorkflow(ctx context.Context, t *tenant.Tenant, action string) (string, error) {
+	return c.TriggerWorkflowWithSource(ctx, t, action, "controller")
+}
+
+func (c *outboxWorkflowClient) TriggerWorkflowWithSource(ctx context.Context, t *tenant.Tenant, action, source string) (string, error) {
+	if c.err != nil {
+		return "", c.err
+	}
+	c.sources = append(c.sources, source)
+	return "exec-outbox", nil
+}
+
+func newOutboxReconciler(t *testing.T, maxAttempts int) (*Reconciler, *memory.Repository, *outboxWorkflowClient) {
+	t.Helper()
+	repo := memory.New()
+	// Once a trigger is published the running loop polls the execution, which is still running
+	client := &outboxWorkflowClient{stubWorkflowClient: stubWorkflowClient{
+		execStatus: &workflow.ExecutionStatus{ExecutionID: "exec-outbox", State: workflow.StateRunning},
+	}}
+	reconciler := NewReconciler(repo, &WorkflowClient{}, config.ControllerConfig{
+		Enabled:                true,
+		ReconciliationInterval: 100 * time.Millisecond,
+		StatusPollInterval:     100 * time.Millisecond,
+		Workers:                1,
+		WorkflowTriggerTimeout: 5 * time.Second,
+		ShutdownTimeout:        5 * time.Second,
+		MaxRetries:             3,
+		TriggerOutbox: config.TriggerOutboxConfig{
+			Enabled:      true,
+			PollInterval: 10 * time.Millisecond,
+			BatchSize:    10,
+			ClaimTimeout: time.Minute,
+			MaxAttempts:  maxAttempts,
+		},
+	}, zaptest.NewLogger(t))
+	reconciler.workflowClient = client
+	reconciler.SetTriggerOutbox(repo)
+	return reconciler, repo, client
+}
+
+func createRequestedTenant(t *testing.T, repo tenant.Repository) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	require.NoError(t, repo.CreateTenant(context.Background(), &tenant.Tenant{
+		ID:            id,
+		Name:          "outbox-" + id.String()[:8],
+		Status:        tenant.StatusRequested,
+		DesiredConfig: map[string]interface{}{"image": "nginx:latest"},
+	}))
+	return id
+}
+
+func TestReconciler_QueuesTriggerInOutbox(t *testing.T) {
+	ctx := context.Background()
+	reconciler, repo, client := newOutboxReconciler(t, 3)
+	id := createRequestedTenant(t, repo)
+
+	require.NoError(t, reconciler.reconcile(id.String()))
+	require.Empty(t, client.sources)
+
+	queued, err := repo.GetTenantByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusRequested, queued.Status)
+	require.Equal(t, string(workflow.SubStateQueued), *queued.WorkflowSubState)
+	require.Equal(t, "Workflow provision queued", queued.StatusMessage)
+	require.Nil(t, queued.LastAction)
+
+	// Another pass leaves the queued trigger to the dispatcher
+	require.NoError(t, reconciler.reconcile(id.String()))
+	entries := repo.OutboxEntries()
+	require.Len(t, entries, 1)
+	require.Equal(t, outboxKindWorkflowTrigger, entries[0].Kind)
+	require.Equal(t, "provision", entries[0].Payload["action"])
+
+	published, err := reconciler.publishOutbox(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+	require.Equal(t, []string{"controller:outbox"}, client.sources)
+
+	started, err := repo.GetTenantByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusProvisioning, started.Status)
+	require.Equal(t, "exec-outbox", *started.WorkflowExecutionID)
+	require.Equal(t, string(workflow.SubStateRunning), *started.WorkflowSubState)
+	require.Equal(t, &tenant.ActionRecord{Action: tenant.ActionProvision, Source: "controller:outbox", ExecutionID: "exec-outbox", At: started.LastAction.At}, started.LastAction)
+	require.NotNil(t, repo.OutboxEntries()[0].PublishedAt)
+
+	// A published entry is never claimed again
+	published, err = reconciler.publishOutbox(ctx)
+	require.NoError(t, err)
+	require.Zero(t, published)
+}
+
+func TestReconciler_OutboxRetriesUntilEngineRecovers(t *testing.T) {
+	ctx := context.Background()
+	reconciler, repo, client := newOutboxReconciler(t, 3)
+	id := createRequestedTenant(t, repo)
+	client.err = errors.New("workflow engine unavailable")
+
+	require.NoError(t, reconciler.reconcile(id.String()))
+	_, err := reconciler.publishOutbox(ctx)
+	require.NoError(t, err)
+
+	entries := repo.OutboxEntries()
+	require.Nil(t, entries[0].PublishedAt)
+	require.Equal(t, 1, entries[0].Attempts)
+	require.Contains(t, entries[0].LastError, "workflow engine unavailable")
+
+	waiting, err := repo.GetTenantByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, string(workflow.SubStateQueued), *waiting.WorkflowSubState)
+
+	// The failed trigger waits out its backoff, then the running loop publishes it
+	client.err = nil
+	require.NoError(t, reconciler.Start())
+	defer reconciler.Stop()
+	require.Eventually(t, func() bool {
+		started, err := repo.GetTenantByID(ctx, id)
+		return err == nil && started.Status == tenant.StatusProvisioning
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestReconciler_OutboxFailsTenantAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	reconciler, repo, client := newOutboxReconciler(t, 1)
+	id := createRequestedTenant(t, repo)
+	client.err = errors.New("workflow engine unavailable")
+
+	require.NoError(t, reconciler.reconcile(id.String()))
+	_, err := reconciler.publishOutbox(ctx)
+	require.NoError(t, err)
+
+	failed, err := repo.GetTenantByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusFailed, failed.Status)
+	require.Equal(t, string(workflow.SubStateFailed), *failed.WorkflowSubState)
+	require.Contains(t, failed.StatusMessage, "Workflow trigger failed after 1 attempts")
+}
+
+func TestReconciler_OutboxDropsSupersededTrigger(t *testing.T) {
+	ctx := context.Background()
+	reconciler, repo, client := newOutboxReconciler(t, 3)
+	id := createRequestedTenant(t, repo)
+	require.NoError(t, reconciler.reconcile(id.String()))
+
+	// The API resets the workflow fields when the tenant changes, superseding the queued trigger
+	changed, err := repo.GetTenantByID(ctx, id)
+	require.NoError(t, err)
+	changed.WorkflowSubState = nil
+	require.NoError(t, repo.UpdateTenant(ctx, changed))
+
+	_, err = reconciler.publishOutbox(ctx)
+	require.NoError(t, err)
+	require.Empty(t, client.sources)
+	require.NotNil(t, repo.OutboxEntries()[0].PublishedAt)
+}
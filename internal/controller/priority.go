@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// workflowSlots caps how many workflows each priority class may have running, so batch tenants
+// cannot take every slot from production repairs. A nil workflowSlots caps nothing.
+type workflowSlots struct {
+	limits map[tenant.PriorityClass]int
+
+	mu      sync.Mutex
+	running map[string]tenant.PriorityClass
+}
+
+// newWorkflowSlots creates slots from per-class limits, returning nil when no class is capped
+func newWorkflowSlots(limits map[string]int) *workflowSlots {
+	if len(limits) == 0 {
+		return nil
+	}
+	s := &workflowSlots{
+		limits:  make(map[tenant.PriorityClass]int, len(limits)),
+		running: make(map[string]tenant.PriorityClass),
+	}
+	for class, limit := range limits {
+		s.limits[tenant.PriorityClass(class)] = limit
+	}
+	return s
+}
+
+// acquire takes a slot for a tenant about to start a workflow, reporting false when its class is full.
+// A slot the tenant already holds is given up first, since its previous workflow is over.
+func (s *workflowSlots) acquire(tenantID string, class tenant.PriorityClass) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.running, tenantID)
+	if limit, ok := s.limits[class]; ok && s.countLocked(class) >= limit {
+		return false
+	}
+	s.running[tenantID] = class
+	return true
+}
+
+// release gives up a tenant's slot
+func (s *workflowSlots) release(tenantID string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, tenantID)
+}
+
+// sync replaces the running workflows with those recorded on tenants, so slots held by workflows
+// that finished without the reconciler seeing it, or that started before a restart, are corrected
+func (s *workflowSlots) sync(tenants []*tenant.Tenant) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.running)
+	for _, t := range tenants {
+		if isInFlightStatus(t.Status) && t.WorkflowExecutionID != nil && *t.WorkflowExecutionID != "" {
+			s.running[t.ID.String()] = t.Priority()
+		}
+	}
+}
+
+// limit returns the class's cap and whether it has one
+func (s *workflowSlots) limit(class tenant.PriorityClass) (int, bool) {
+	if s == nil {
+		return 0, false
+	}
+	limit, ok := s.limits[class]
+	return limit, ok
+}
+
+func (s *workflowSlots) countLocked(class tenant.PriorityClass) int {
+	count := 0
+	for _, running := range s.running {
+		if running == class {
+			count++
+		}
+	}
+	return count
+}
+
+// waitForWorkflowSlot records on t that it waits for a workflow of its priority class to finish.
+// The tenant is reconciled again on the next poll.
+func (r *Reconciler) waitForWorkflowSlot(ctx context.Context, t *tenant.Tenant) error {
+	class := t.Priority()
+	limit, _ := r.workflowSlots.limit(class)
+	message := fmt.Sprintf("Waiting for a workflow slot: %s priority tenants may run %d workflows at once", class, limit)
+
+	r.logger.Debug("priority class at workflow capacity, skipping trigger",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("tenant_name", t.Name),
+		zap.String("priority", string(class)),
+		zap.Int("limit", limit))
+
+	if t.StatusMessage == message {
+		return nil
+	}
+	t.StatusMessage = message
+	if err := r.tenantRepo.UpdateTenant(ctx, t); err != nil && !errors.Is(err, tenant.ErrVersionConflict) {
+		return fmt.Errorf("update tenant: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestQueueHandsOutHigherPriorityFirst(t *testing.T) {
+	q := NewRateLimitingQueue()
+	defer q.ShutDown()
+
+	q.AddWithPriority("batch-1", tenant.PriorityBatch)
+	q.AddWithPriority("default-1", tenant.PriorityDefault)
+	q.Add("unlabelled")
+	q.AddWithPriority("critical-1", tenant.PriorityCritical)
+	q.AddWithPriority("batch-2", tenant.PriorityBatch)
+	q.AddWithPriority("high-1", tenant.PriorityHigh)
+
+	// An item already waiting moves when its class changes
+	q.AddWithPriority("batch-2", tenant.PriorityCritical)
+	require.Equal(t, 6, q.Len())
+
+	var order []interface{}
+	for q.Len() > 0 {
+		item, _ := q.Get()
+		order = append(order, item)
+		q.Done(item)
+	}
+	require.Equal(t, []interface{}{"critical-1", "batch-2", "high-1", "default-1", "unlabelled", "batch-1"}, order)
+}
+
+func TestReconciler_CapsRunningWorkflowsPerPriorityClass(t *testing.T) {
+	repo := newMemoryTenantRepo()
+	client := &stoppingWorkflowClient{}
+	reconciler := NewReconciler(repo, &WorkflowClient{}, config.ControllerConfig{
+		Enabled:                true,
+		ReconciliationInterval: 100 * time.Millisecond,
+		StatusPollInterval:     100 * time.Millisecond,
+		Workers:                1,
+		WorkflowTriggerTimeout: 5 * time.Second,
+		ShutdownTimeout:        5 * time.Second,
+		MaxRetries:             3,
+		WorkflowConcurrency:    map[string]int{"batch": 1},
+	}, zaptest.NewLogger(t))
+	reconciler.workflowClient = client
+
+	create := func(name string, class tenant.PriorityClass) uuid.UUID {
+		id := uuid.New()
+		require.NoError(t, repo.CreateTenant(context.Background(), &tenant.Tenant{
+			ID:            id,
+			Name:          name,
+			Status:        tenant.StatusRequested,
+			Labels:        map[string]string{tenant.LabelPriority: string(class)},
+			DesiredConfig: map[string]interface{}{"image": "nginx:latest"},
+		}))
+		return id
+	}
+	preview1 := create("preview-1", tenant.PriorityBatch)
+	preview2 := create("preview-2", tenant.PriorityBatch)
+	prod := create("prod", tenant.PriorityCritical)
+
+	require.NoError(t, reconciler.reconcile(preview1.String()))
+	require.NoError(t, reconciler.reconcile(preview2.String()))
+	require.NoError(t, reconciler.reconcile(prod.String()))
+	require.Equal(t, 2, client.triggered)
+
+	waiting, err := repo.GetTenantByID(context.Background(), preview2)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusRequested, waiting.Status)
+	require.Nil(t, waiting.WorkflowExecutionID)
+	require.Equal(t, "Waiting for a workflow slot: batch priority tenants may run 1 workflows at once", waiting.StatusMessage)
+
+	// Once the status poll sees the first preview's workflow has finished, the second may start
+	finished, err := repo.GetTenantByID(context.Background(), preview1)
+	require.NoError(t, err)
+	finished.Status = tenant.StatusReady
+	require.NoError(t, repo.UpdateTenant(context.Background(), finished))
+	inFlight, err := repo.ListTenants(context.Background(), tenant.ListFilters{Statuses: []tenant.Status{tenant.StatusProvisioning}})
+	require.NoError(t, err)
+	reconciler.workflowSlots.sync(inFlight)
+
+	require.NoError(t, reconciler.reconcile(preview2.String()))
+	require.Equal(t, 3, client.triggered)
+	started, err := repo.GetTenantByID(context.Background(), preview2)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusProvisioning, started.Status)
+}
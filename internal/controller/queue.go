@@ -1,26 +1,52 @@
 package controller
 
 import (
+	"slices"
+	"sync"
 	"time"
 
 	"k8s.io/client-go/util/workqueue"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
 )
 
-// Queue wraps a rate-limiting workqueue for tenant reconciliation
+// Queue wraps a rate-limiting workqueue for tenant reconciliation.
+// Items are handed out highest priority class first, and in the order they were added within a class.
 type Queue struct {
-	queue workqueue.RateLimitingInterface
+	queue      workqueue.RateLimitingInterface
+	priorities *priorityFIFO
+	limiter    *delayRateLimiter
 }
 
+// reconcileQueueName names the reconcile queue in the controller_workqueue metrics
+const reconcileQueueName = "reconcile"
+
 // NewRateLimitingQueue creates a new workqueue with exponential backoff
 // Base delay: 1 second, max delay: 5 minutes
 func NewRateLimitingQueue() *Queue {
-	rateLimiter := workqueue.NewItemExponentialFailureRateLimiter(
-		1*time.Second, // base delay
-		5*time.Minute, // max delay
-	)
+	rateLimiter := &delayRateLimiter{
+		RateLimiter: workqueue.NewItemExponentialFailureRateLimiter(
+			1*time.Second, // base delay
+			5*time.Minute, // max delay
+		),
+		delays: make(map[interface{}]time.Duration),
+	}
 
+	priorities := newPriorityFIFO()
 	return &Queue{
-		queue: workqueue.NewRateLimitingQueue(rateLimiter),
+		queue: workqueue.NewRateLimitingQueueWithConfig(rateLimiter, workqueue.RateLimitingQueueConfig{
+			DelayingQueue: workqueue.NewDelayingQueueWithConfig(workqueue.DelayingQueueConfig{
+				Name:            reconcileQueueName,
+				MetricsProvider: workqueueMetrics,
+				Queue: workqueue.NewWithConfig(workqueue.QueueConfig{
+					Name:            reconcileQueueName,
+					MetricsProvider: workqueueMetrics,
+					Queue:           priorities,
+				}),
+			}),
+		}),
+		priorities: priorities,
+		limiter:    rateLimiter,
 	}
 }
 
@@ -29,6 +55,14 @@ func (q *Queue) Add(item interface{}) {
 	q.queue.Add(item)
 }
 
+// AddWithPriority adds an item to the queue in its priority class. An item already waiting moves
+// to the new class. The class applies until the item is next handed out; items added without one,
+// such as retries, wait with the default class.
+func (q *Queue) AddWithPriority(item interface{}, class tenant.PriorityClass) {
+	q.priorities.setPriority(item, class)
+	q.queue.Add(item)
+}
+
 // Get retrieves an item from the queue (blocks if empty)
 func (q *Queue) Get() (item interface{}, shutdown bool) {
 	return q.queue.Get()
@@ -44,6 +78,13 @@ func (q *Queue) AddRateLimited(item interface{}) {
 	q.queue.AddRateLimited(item)
 }
 
+// AddRateLimitedAfter adds an item with rate limiting, waiting delay instead of the queue's own
+// backoff (for retries whose policy sets the backoff)
+func (q *Queue) AddRateLimitedAfter(item interface{}, delay time.Duration) {
+	q.limiter.setDelay(item, delay)
+	q.queue.AddRateLimited(item)
+}
+
 // Forget indicates successful processing (resets backoff for this item)
 func (q *Queue) Forget(item interface{}) {
 	q.queue.Forget(item)
@@ -63,3 +104,114 @@ func (q *Queue) ShuttingDown() bool {
 func (q *Queue) Len() int {
 	return q.queue.Len()
 }
+
+// delayRateLimiter backs off with its RateLimiter, except for items given a delay of their own
+type delayRateLimiter struct {
+	workqueue.RateLimiter
+
+	mu     sync.Mutex
+	delays map[interface{}]time.Duration
+}
+
+func (l *delayRateLimiter) setDelay(item interface{}, delay time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.delays[item] = delay
+}
+
+// When returns the item's own delay once, or the RateLimiter's backoff
+func (l *delayRateLimiter) When(item interface{}) time.Duration {
+	l.mu.Lock()
+	delay, ok := l.delays[item]
+	delete(l.delays, item)
+	l.mu.Unlock()
+	if ok {
+		return delay
+	}
+	return l.RateLimiter.When(item)
+}
+
+// priorityFIFO is the workqueue's backing store: one FIFO per priority class, drained highest first.
+// The workqueue calls Push, Pop, Touch and Len under its own lock; the mutex guards the recorded
+// classes, which are set from outside it.
+type priorityFIFO struct {
+	mu       sync.Mutex
+	classes  map[interface{}]tenant.PriorityClass
+	queued   map[interface{}]int
+	bands    [][]interface{}
+	queueLen int
+}
+
+func newPriorityFIFO() *priorityFIFO {
+	return &priorityFIFO{
+		classes: make(map[interface{}]tenant.PriorityClass),
+		queued:  make(map[interface{}]int),
+		bands:   make([][]interface{}, len(tenant.PriorityClasses)),
+	}
+}
+
+func (f *priorityFIFO) setPriority(item interface{}, class tenant.PriorityClass) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.classes[item] = class
+}
+
+// rank returns the band an item belongs in; items without a recorded class rank as default
+func (f *priorityFIFO) rank(item interface{}) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	class, ok := f.classes[item]
+	if !ok {
+		class = tenant.PriorityDefault
+	}
+	return class.Rank()
+}
+
+// Touch moves an item that is already waiting into the band of its current class
+func (f *priorityFIFO) Touch(item interface{}) {
+	current, ok := f.queued[item]
+	if !ok {
+		return
+	}
+	rank := f.rank(item)
+	if rank == current {
+		return
+	}
+	f.bands[current] = slices.DeleteFunc(f.bands[current], func(queued interface{}) bool { return queued == item })
+	f.bands[rank] = append(f.bands[rank], item)
+	f.queued[item] = rank
+}
+
+// Push adds an item to the back of its class's band
+func (f *priorityFIFO) Push(item interface{}) {
+	rank := f.rank(item)
+	f.bands[rank] = append(f.bands[rank], item)
+	f.queued[item] = rank
+	f.queueLen++
+}
+
+// Len returns the number of waiting items
+func (f *priorityFIFO) Len() int {
+	return f.queueLen
+}
+
+// Pop removes the oldest item of the highest non-empty band and forgets its class.
+// The workqueue only calls it when Len is positive.
+func (f *priorityFIFO) Pop() interface{} {
+	for rank, band := range f.bands {
+		if len(band) == 0 {
+			continue
+		}
+		item := band[0]
+		band[0] = nil
+		f.bands[rank] = band[1:]
+		delete(f.queued, item)
+		f.queueLen--
+
+		f.mu.Lock()
+		delete(f.classes, item)
+		f.mu.Unlock()
+		return item
+	}
+	return nil
+}
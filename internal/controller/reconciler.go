@@ -3,7 +3,9 @@ package controller
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"sync"
 	"time"
 
@@ -11,10 +13,21 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/execution"
+	"github.com/jaxxstorm/landlord/internal/resource"
 	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/vulnscan"
 	"github.com/jaxxstorm/landlord/internal/workflow"
 )
 
+const (
+	// migrationTargetKey holds the target provider's observed state in observed_config until switchover
+	migrationTargetKey = "migration_target"
+
+	// migrationFieldManager owns compute_provider after the controller switches a migrated tenant over
+	migrationFieldManager = "controller:migrate"
+)
+
 // workflowClientInterface defines methods used by reconciler
 type workflowClientInterface interface {
 	TriggerWorkflow(ctx context.Context, t *tenant.Tenant, action string) (string, error)
@@ -36,9 +49,36 @@ type Reconciler struct {
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 
-	// Retry tracking per tenant
-	retryCount map[string]int
-	retryMu    sync.RWMutex
+	// Retry and workflow timeout tracking per tenant
+	retryCount       map[string]int
+	workflowTimeouts map[string]workflowTimeoutState
+	retryMu          sync.RWMutex
+
+	// Running workflows per priority class, nil unless workflow_concurrency caps a class
+	workflowSlots *workflowSlots
+
+	// Backpressure on new provisioning, nil unless admission control is enabled
+	admission *admission
+
+	// Execution statuses queried together by the status loop
+	statusBatch *statusBatch
+
+	// Outbox workflow triggers are queued in, nil unless set with SetTriggerOutbox
+	triggerOutbox tenant.Outbox
+
+	// Execution steps graceful stops are read from, nil unless set with SetExecutionSteps
+	executionSteps execution.Store
+
+	// Finalizers run before deleted and archived tenants are removed, added with RegisterFinalizer
+	finalizers []Finalizer
+
+	// Fault injection and invariant checks, nil unless chaos mode is enabled
+	chaos      *chaos
+	invariants *invariants
+
+	// Admin listener serving metrics and profiles, nil unless enabled
+	admin     *http.Server
+	adminAddr string
 }
 
 // NewReconciler creates a new reconciler instance
@@ -50,16 +90,29 @@ func NewReconciler(
 ) *Reconciler {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Reconciler{
-		tenantRepo:     tenantRepo,
-		workflowClient: workflowClient,
-		queue:          NewRateLimitingQueue(),
-		config:         cfg,
-		logger:         logger.With(zap.String("component", "reconciler")),
-		ctx:            ctx,
-		cancel:         cancel,
-		retryCount:     make(map[string]int),
+	r := &Reconciler{
+		tenantRepo:       tenantRepo,
+		workflowClient:   workflowClient,
+		queue:            NewRateLimitingQueue(),
+		config:           cfg,
+		logger:           logger.With(zap.String("component", "reconciler")),
+		ctx:              ctx,
+		cancel:           cancel,
+		retryCount:       make(map[string]int),
+		workflowTimeouts: make(map[string]workflowTimeoutState),
+		workflowSlots:    newWorkflowSlots(cfg.WorkflowConcurrency),
+		admission:        newAdmission(cfg.Admission),
+		statusBatch:      newStatusBatch(cfg.StatusPollInterval),
+	}
+
+	if c := newChaos(cfg.Chaos, r.logger); c != nil {
+		r.chaos = c
+		r.invariants = newInvariants(cfg.Chaos.StuckThreshold, r.logger)
+		r.tenantRepo = &chaosRepository{Repository: tenantRepo, chaos: c}
+		r.workflowClient = &chaosWorkflowClient{workflowClientInterface: workflowClient, chaos: c, invariants: r.invariants}
 	}
+
+	return r
 }
 
 // Start begins the reconciliation loop and workers
@@ -74,6 +127,12 @@ func (r *Reconciler) Start() error {
 		zap.Duration("status_interval", r.config.StatusPollInterval),
 		zap.Int("workers", r.config.Workers))
 
+	if r.config.Admin.Enabled {
+		if err := r.startAdmin(); err != nil {
+			return err
+		}
+	}
+
 	// Start polling loops
 	r.wg.Add(1)
 	go r.pollInvocationLoop()
@@ -87,6 +146,20 @@ func (r *Reconciler) Start() error {
 		go r.runWorker(i)
 	}
 
+	if r.invariants != nil {
+		r.wg.Add(1)
+		go r.invariantLoop()
+	}
+
+	if r.config.TriggerOutbox.Enabled {
+		if r.triggerOutbox == nil {
+			r.logger.Warn("trigger outbox enabled but no outbox set, triggering workflows directly")
+		} else {
+			r.wg.Add(1)
+			go r.outboxLoop()
+		}
+	}
+
 	return nil
 }
 
@@ -98,6 +171,7 @@ func (r *Reconciler) Stop() error {
 	// Signal shutdown
 	r.cancel()
 	r.queue.ShutDown()
+	r.stopAdmin()
 
 	// Wait for workers with timeout
 	done := make(chan struct{})
@@ -151,27 +225,68 @@ func (r *Reconciler) pollStatusLoop() {
 			r.logger.Info("status poll loop stopped")
 			return
 		case <-ticker.C:
-			r.pollTenantsByStatus([]tenant.Status{tenant.StatusProvisioning, tenant.StatusUpdating, tenant.StatusDeleting, tenant.StatusArchiving, tenant.StatusFailed})
+			tenants, err := r.pollTenantsByStatus([]tenant.Status{tenant.StatusProvisioning, tenant.StatusUpdating, tenant.StatusMigrating, tenant.StatusDeleting, tenant.StatusArchiving, tenant.StatusFailed})
+			if err == nil {
+				// Every running workflow belongs to one of these tenants
+				r.workflowSlots.sync(tenants)
+				r.admission.observe(tenants)
+			}
+		}
+	}
+}
+
+// invariantLoop periodically checks for tenants stuck in a non-terminal status
+func (r *Reconciler) invariantLoop() {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.config.Chaos.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(r.ctx, 10*time.Second)
+			tenants, err := r.tenantRepo.ListTenants(ctx, tenant.ListFilters{})
+			cancel()
+			if err != nil {
+				r.logger.Error("failed to list tenants for invariant check", zap.Error(err))
+				continue
+			}
+			r.invariants.checkStuck(tenants, time.Now())
 		}
 	}
 }
 
-// pollTenantsByStatus queries database and enqueues tenants for reconciliation
-func (r *Reconciler) pollTenantsByStatus(statuses []tenant.Status) {
+// Violations returns the invariant violations found so far in chaos mode
+func (r *Reconciler) Violations() []Violation {
+	if r.invariants == nil {
+		return nil
+	}
+	return r.invariants.list()
+}
+
+// pollTenantsByStatus queries database and enqueues tenants for reconciliation, highest priority first
+func (r *Reconciler) pollTenantsByStatus(statuses []tenant.Status) ([]*tenant.Tenant, error) {
 	ctx, cancel := context.WithTimeout(r.ctx, 10*time.Second)
 	defer cancel()
 
 	tenants, err := r.tenantRepo.ListTenants(ctx, tenant.ListFilters{Statuses: statuses})
 	if err != nil {
 		r.logger.Error("failed to list tenants for reconciliation", zap.Error(err))
-		return
+		return nil, err
 	}
 
 	r.logger.Debug("polled tenants", zap.Int("count", len(tenants)))
 
+	// Query in-flight executions before the workers take the tenants
+	r.prefetchStatuses(ctx, tenants)
+
 	for _, t := range tenants {
-		r.queue.Add(t.ID.String())
+		r.queue.AddWithPriority(t.ID.String(), t.Priority())
 	}
+	return tenants, nil
 }
 
 // runWorker processes items from the queue
@@ -201,7 +316,9 @@ func (r *Reconciler) processItem(item interface{}) {
 		return
 	}
 
+	started := time.Now()
 	err := r.reconcile(tenantID)
+	reconcileMetrics.recordReconcile(time.Since(started).Milliseconds(), err)
 	if err != nil {
 		r.handleReconcileError(tenantID, err)
 	} else {
@@ -220,6 +337,10 @@ func (r *Reconciler) reconcile(tenantID string) error {
 
 	r.logger.Info("reconciling tenant", zap.String("tenant_id", tenantID))
 
+	if err := r.chaos.delay(ctx, tenantID); err != nil {
+		return err
+	}
+
 	tenantUUID, err := uuid.Parse(tenantID)
 	if err != nil {
 		return fmt.Errorf("invalid tenant id %q: %w", tenantID, err)
@@ -246,7 +367,7 @@ func (r *Reconciler) reconcile(tenantID string) error {
 
 	// If a workflow execution is in-flight, poll status and update tenant
 	if isInFlightStatus(t.Status) && t.WorkflowExecutionID != nil && *t.WorkflowExecutionID != "" {
-		execStatus, err := r.workflowClient.GetExecutionStatus(ctx, *t.WorkflowExecutionID)
+		execStatus, err := r.executionStatus(ctx, *t.WorkflowExecutionID)
 		if err != nil {
 			r.logger.Warn("failed to check workflow status, will retry later",
 				zap.String("tenant_id", tenantID),
@@ -303,7 +424,21 @@ func (r *Reconciler) reconcile(tenantID string) error {
 					retryCount = &zero
 				}
 
+				if timeout := r.workflowDeadlineExceeded(t, time.Now()); timeout > 0 {
+					return r.handleWorkflowTimeout(ctx, t, timeout)
+				}
+
+				if phase, ok := r.archivalPhase(ctx, t); ok && subState == workflow.SubStateRunning {
+					subState = phase
+				}
+
 				changed := updateWorkflowStatusFields(t, subState, retryCount, errMsg)
+				if t.WorkflowStartedAt == nil {
+					// Executions triggered before start times were recorded are timed from when they are first seen
+					now := time.Now()
+					t.WorkflowStartedAt = &now
+					changed = true
+				}
 				if changed {
 					t.StatusMessage = fmt.Sprintf("Workflow execution %s: %s", subState, execStatus.ExecutionID)
 					if err := r.tenantRepo.UpdateTenant(ctx, t); err != nil {
@@ -336,8 +471,9 @@ func (r *Reconciler) reconcile(tenantID string) error {
 
 			// Handle workflow failure - but check for config change first
 			if execStatus.State == workflow.StateFailed || execStatus.State == workflow.StateTimedOut {
-				// Check if config changed since workflow failed -> trigger new workflow with updated config
-				if hasConfigChanged(t) {
+				// Check if config changed since workflow failed -> trigger new workflow with updated config.
+				// A failed migration phase is resumed through the API instead, since reprovisioning would target the wrong provider.
+				if hasConfigChanged(t) && t.Status != tenant.StatusMigrating {
 					oldHash := ""
 					if t.WorkflowConfigHash != nil {
 						oldHash = *t.WorkflowConfigHash
@@ -353,6 +489,7 @@ func (r *Reconciler) reconcile(tenantID string) error {
 					
 					// Clear execution ID and move back to requested to trigger new workflow
 					t.WorkflowExecutionID = nil
+					t.WorkflowStartedAt = nil
 					t.WorkflowSubState = nil
 					t.WorkflowRetryCount = nil
 					t.WorkflowErrorMessage = nil
@@ -382,37 +519,125 @@ func (r *Reconciler) reconcile(tenantID string) error {
 		}
 	}
 
+	// A removed tenant whose workflow succeeded waits for its finalizers, not another workflow
+	if isFinalizing(t) {
+		return r.finalize(ctx, t, false)
+	}
+
+	// A queued trigger is started by the outbox loop, not by another pass
+	if r.outboxEnabled() && isTriggerQueued(t) {
+		r.logger.Debug("workflow trigger queued, skipping",
+			zap.String("tenant_id", tenantID),
+			zap.String("tenant_name", t.Name))
+		return nil
+	}
+
+	// A tenant whose last execution timed out waits out its backoff before another starts
+	if retryAt := r.workflowRetryAt(tenantID, t.Status, time.Now()); !retryAt.IsZero() {
+		r.logger.Debug("workflow timeout backoff in effect, skipping trigger",
+			zap.String("tenant_id", tenantID),
+			zap.String("tenant_name", t.Name),
+			zap.Time("retry_at", retryAt))
+		return nil
+	}
+
 	// Determine action for new or retried workflow invocation
 	action, err := r.workflowClient.DetermineAction(t.Status)
 	if err != nil {
 		return fmt.Errorf("determine action: %w", err)
 	}
 
-	// Trigger workflow
-	executionID, err := r.workflowClient.TriggerWorkflow(ctx, t, action)
+	// New provisioning waits while workflows are failing or backed up, rather than starting one likely to fail
+	if t.Status == tenant.StatusRequested || t.Status == tenant.StatusPlanning {
+		if reason, ok := r.admission.admit(); !ok {
+			return r.holdForCapacity(ctx, t, reason)
+		}
+	}
+
+	// A tenant whose priority class already has all its workflows running waits for one to finish
+	if !r.workflowSlots.acquire(tenantID, t.Priority()) {
+		return r.waitForWorkflowSlot(ctx, t)
+	}
+
+	if r.outboxEnabled() {
+		return r.queueWorkflowTrigger(ctx, t, action)
+	}
+
+	// Trigger the workflow under the tenant's trigger lock. The execution ID is written in the same
+	// transaction, so a second worker or a retried trigger acting on the same tenant version is fenced
+	// off instead of starting a duplicate execution.
+	previousStatus := t.Status
+	var executionID string
+	err = r.tenantRepo.FenceWorkflowTrigger(ctx, t, func(ctx context.Context, t *tenant.Tenant) error {
+		id, err := r.workflowClient.TriggerWorkflow(ctx, t, action)
+		if err != nil {
+			return fmt.Errorf("%w: %w", errWorkflowTrigger, err)
+		}
+		executionID = id
+
+		r.logger.Info("workflow triggered with new execution ID",
+			zap.String("tenant_id", tenantID),
+			zap.String("tenant_name", t.Name),
+			zap.String("new_execution_id", executionID),
+			zap.String("action", action))
+
+		r.recordTriggeredExecution(t, executionID, action, "controller")
+		return nil
+	})
+	if err != nil && executionID != "" {
+		return fmt.Errorf("record workflow execution %s: %w", executionID, err)
+	}
+	if err != nil {
+		r.workflowSlots.release(tenantID)
+	}
+	if errors.Is(err, tenant.ErrWorkflowTriggerLocked) || errors.Is(err, tenant.ErrVersionConflict) {
+		// Another worker owns this trigger, or the tenant changed; the next pass sees its latest state
+		r.logger.Info("workflow trigger fenced, skipping",
+			zap.String("tenant_id", tenantID),
+			zap.String("tenant_name", t.Name),
+			zap.String("action", action),
+			zap.Error(err))
+		return nil
+	}
 	if err != nil {
-		return fmt.Errorf("trigger workflow: %w", err)
+		r.admission.record(true)
+		return err
 	}
+	r.admission.record(false)
 
-	r.logger.Info("workflow triggered with new execution ID",
+	duration := time.Since(startTime)
+	r.logger.Info("tenant reconciled successfully",
 		zap.String("tenant_id", tenantID),
 		zap.String("tenant_name", t.Name),
-		zap.String("new_execution_id", executionID),
-		zap.String("action", action))
+		zap.String("status", string(t.Status)),
+		zap.String("previous_status", string(previousStatus)),
+		zap.String("execution_id", executionID),
+		zap.Duration("duration", duration))
 
-	// Update tenant with execution ID and move into provisioning where appropriate
-	previousStatus := t.Status
+	return nil
+}
+
+// recordTriggeredExecution stores a newly triggered execution on t and moves it into provisioning where appropriate
+func (r *Reconciler) recordTriggeredExecution(t *tenant.Tenant, executionID, action, source string) {
+	recordLastAction(t, executionID, action, source)
 	if t.Status == tenant.StatusRequested || t.Status == tenant.StatusPlanning {
 		t.Status = tenant.StatusProvisioning
 	}
 	t.StatusMessage = fmt.Sprintf("Workflow execution started: %s", executionID)
+	if migration := t.Migration(); t.Status == tenant.StatusMigrating && migration != nil {
+		t.StatusMessage = fmt.Sprintf("Migrating to %s (%s): workflow execution started: %s", migration.Target, migration.Phase, executionID)
+	}
 	t.WorkflowExecutionID = &executionID
+	startedAt := time.Now()
+	t.WorkflowStartedAt = &startedAt
+	workflowVersion := workflow.LatestWorkflowVersion
+	t.WorkflowVersion = &workflowVersion
 
 	// Compute and store config hash for change detection
 	configHash, err := tenant.ComputeConfigHash(t.DesiredConfig)
 	if err != nil {
 		r.logger.Warn("failed to compute config hash",
-			zap.String("tenant_id", tenantID),
+			zap.String("tenant_id", t.ID.String()),
 			zap.Error(err))
 	} else if configHash != "" {
 		t.WorkflowConfigHash = &configHash
@@ -423,59 +648,37 @@ func (r *Reconciler) reconcile(tenantID string) error {
 	t.WorkflowSubState = &running
 	t.WorkflowRetryCount = &zero
 	t.WorkflowErrorMessage = nil
+}
 
-	if err := r.tenantRepo.UpdateTenant(ctx, t); err != nil {
-		return fmt.Errorf("update tenant: %w", err)
+// recordLastAction records the workflow just started for t. The action is named for t's status, so
+// an archival is recorded as archive rather than as the delete workflow it runs.
+func recordLastAction(t *tenant.Tenant, executionID, action, source string) {
+	if named := tenant.ActionForStatus(t.Status); named != "" {
+		action = named
 	}
-
-	duration := time.Since(startTime)
-	r.logger.Info("tenant reconciled successfully",
-		zap.String("tenant_id", tenantID),
-		zap.String("tenant_name", t.Name),
-		zap.String("status", string(t.Status)),
-		zap.String("previous_status", string(previousStatus)),
-		zap.String("execution_id", executionID),
-		zap.Duration("duration", duration))
-
-	return nil
+	t.LastAction = &tenant.ActionRecord{Action: action, Source: source, ExecutionID: executionID, At: time.Now()}
 }
 
 func isInFlightStatus(status tenant.Status) bool {
 	return status == tenant.StatusProvisioning ||
 		status == tenant.StatusUpdating ||
+		status == tenant.StatusMigrating ||
 		status == tenant.StatusDeleting ||
 		status == tenant.StatusArchiving
 }
 
 func (r *Reconciler) handleWorkflowSuccess(ctx context.Context, t *tenant.Tenant, execStatus *workflow.ExecutionStatus) error {
-	if t.Status == tenant.StatusDeleting {
-		if err := r.tenantRepo.DeleteTenant(ctx, t.ID); err != nil {
-			return fmt.Errorf("delete tenant after workflow: %w", err)
-		}
-		r.logger.Info("tenant deleted after workflow completion",
-			zap.String("tenant_id", t.ID.String()),
-			zap.String("tenant_name", t.Name),
-		)
-		return nil
+	t.WorkflowStartedAt = nil
+	r.workflowSlots.release(t.ID.String())
+	r.admission.record(false)
+	r.clearDegraded(t, time.Now())
+
+	if t.Status == tenant.StatusDeleting || t.Status == tenant.StatusArchiving {
+		return r.completeRemoval(ctx, t, fmt.Sprintf("Workflow execution completed: %s", execStatus.ExecutionID))
 	}
-	if t.Status == tenant.StatusArchiving {
-		if t.Annotations != nil && t.Annotations["landlord/delete_after_archive"] == "true" {
-			if err := r.tenantRepo.DeleteTenant(ctx, t.ID); err != nil {
-				return fmt.Errorf("delete tenant after archive workflow: %w", err)
-			}
-			r.logger.Info("tenant deleted after archive workflow completion",
-				zap.String("tenant_id", t.ID.String()),
-				zap.String("tenant_name", t.Name),
-			)
-			return nil
-		}
 
-		t.Status = tenant.StatusArchived
-		t.StatusMessage = fmt.Sprintf("Workflow execution completed: %s", execStatus.ExecutionID)
-		if err := r.tenantRepo.UpdateTenant(ctx, t); err != nil {
-			return fmt.Errorf("update tenant: %w", err)
-		}
-		return nil
+	if t.Status == tenant.StatusMigrating {
+		return r.handleMigrationSuccess(ctx, t, execStatus)
 	}
 
 	next, err := nextStatus(t.Status)
@@ -492,7 +695,15 @@ func (r *Reconciler) handleWorkflowSuccess(ctx context.Context, t *tenant.Tenant
 			observed["compute_result"] = string(execStatus.Output)
 			t.ObservedConfig = observed
 		} else {
+			hookResults := observed[workflow.HooksConfigKey]
+			delete(observed, workflow.HooksConfigKey)
 			t.ObservedConfig = observed
+			if ids := observedResourceIDs(observed); len(ids) > 0 {
+				t.ObservedResourceIDs = ids
+			}
+			r.recordHookResults(ctx, t, hookResults)
+			r.recordVulnerabilityScan(t, observed)
+			r.recordReadinessProbe(t, observed)
 		}
 	}
 
@@ -512,20 +723,211 @@ func (r *Reconciler) handleWorkflowSuccess(ctx context.Context, t *tenant.Tenant
 	return nil
 }
 
+// observedResourceIDs collects compute resource IDs and tenant resource outputs (as "<resource>.<key>") from workflow output
+func observedResourceIDs(observed map[string]interface{}) map[string]string {
+	ids := make(map[string]string)
+	if computeIDs, ok := observed["resource_ids"].(map[string]interface{}); ok {
+		for key, value := range computeIDs {
+			if str, ok := value.(string); ok {
+				ids[key] = str
+			}
+		}
+	}
+	if resources, ok := observed[resource.ResourcesConfigKey].(map[string]interface{}); ok {
+		for name, entry := range resources {
+			fields, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			outputs, ok := fields["outputs"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for key, value := range outputs {
+				if str, ok := value.(string); ok {
+					ids[name+"."+key] = str
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// recordVulnerabilityScan sets the tenant's VulnerabilityScanPassed condition from the scan summary in
+// the workflow output. The summary itself stays in the observed config.
+func (r *Reconciler) recordVulnerabilityScan(t *tenant.Tenant, observed map[string]interface{}) {
+	summary, err := vulnscan.SummaryFromOutput(observed)
+	if err != nil {
+		r.logger.Warn("failed to read vulnerability scan from workflow output",
+			zap.String("tenant_id", t.ID.String()),
+			zap.Error(err))
+		return
+	}
+	if summary != nil {
+		vulnscan.SetCondition(t, summary, time.Now())
+	}
+}
+
+// recordReadinessProbe sets the tenant's ReadinessProbePassed condition from the probe result in
+// the workflow output. The result itself stays in the observed config.
+func (r *Reconciler) recordReadinessProbe(t *tenant.Tenant, observed map[string]interface{}) {
+	result, err := workflow.ProbeResultFromOutput(observed)
+	if err != nil {
+		r.logger.Warn("failed to read readiness probe from workflow output",
+			zap.String("tenant_id", t.ID.String()),
+			zap.Error(err))
+		return
+	}
+	if result != nil {
+		workflow.SetReadinessCondition(t, result, time.Now())
+	}
+}
+
+// recordHookResults writes one state history entry per hook step reported by the workflow
+func (r *Reconciler) recordHookResults(ctx context.Context, t *tenant.Tenant, raw interface{}) {
+	if raw == nil {
+		return
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return
+	}
+	var results []workflow.HookResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		r.logger.Warn("failed to decode hook results",
+			zap.String("tenant_id", t.ID.String()),
+			zap.Error(err))
+		return
+	}
+
+	for _, result := range results {
+		reason := fmt.Sprintf("%s hook %q %s after %d attempt(s)", result.Phase, result.Name, result.Status, result.Attempts)
+		if result.Error != "" {
+			reason = fmt.Sprintf("%s: %s", reason, result.Error)
+		}
+
+		code := tenant.ReasonHookFailed
+		if result.Status == workflow.HookStatusSucceeded {
+			code = tenant.ReasonHookSucceeded
+		}
+		transition := tenant.NewStateTransition(t, t.Status, reason, "workflow:hook").WithReasonCode(code)
+		transition.ObservedStateSnapshot = map[string]interface{}{
+			"name":         result.Name,
+			"phase":        result.Phase,
+			"type":         result.Type,
+			"status":       result.Status,
+			"attempts":     result.Attempts,
+			"started_at":   result.StartedAt,
+			"completed_at": result.CompletedAt,
+			"error":        result.Error,
+			"output":       result.Output,
+		}
+		if err := r.tenantRepo.RecordStateTransition(ctx, transition); err != nil {
+			r.logger.Warn("failed to record hook result",
+				zap.String("tenant_id", t.ID.String()),
+				zap.String("hook", result.Name),
+				zap.Error(err))
+		}
+	}
+}
+
+// handleMigrationSuccess records a finished migration phase and moves to the next one, or back to ready
+// once the source is destroyed. Clearing the execution ID lets the next reconcile start the next phase.
+func (r *Reconciler) handleMigrationSuccess(ctx context.Context, t *tenant.Tenant, execStatus *workflow.ExecutionStatus) error {
+	migration := t.Migration()
+	if migration == nil {
+		return fmt.Errorf("tenant %s is migrating without a recorded migration", t.Name)
+	}
+
+	output := make(map[string]interface{})
+	if len(execStatus.Output) > 0 {
+		if err := json.Unmarshal(execStatus.Output, &output); err != nil {
+			r.logger.Warn("failed to unmarshal migration output",
+				zap.String("tenant_id", t.ID.String()),
+				zap.Error(err))
+			output = map[string]interface{}{"compute_result": string(execStatus.Output)}
+		}
+	}
+
+	switch migration.Phase {
+	case tenant.MigrationPhaseProvisionTarget:
+		// The source keeps serving, so hold the target's observed state until switchover
+		if t.ObservedConfig == nil {
+			t.ObservedConfig = make(map[string]interface{})
+		}
+		t.ObservedConfig[migrationTargetKey] = output
+	case tenant.MigrationPhaseSwitchover:
+		observed, ok := t.ObservedConfig[migrationTargetKey].(map[string]interface{})
+		if !ok {
+			observed = output
+		}
+		t.ObservedConfig = observed
+		t.ObservedResourceIDs = observedResourceIDs(observed)
+
+		previous := t.DesiredConfig
+		desired := make(map[string]interface{}, len(previous)+1)
+		for key, value := range previous {
+			desired[key] = value
+		}
+		desired["compute_provider"] = migration.Target
+		t.DesiredConfig = desired
+		t.UpdateManagedFields(previous, migrationFieldManager, tenant.ManagedFieldOperationUpdate, time.Now())
+	}
+
+	succeeded := string(workflow.SubStateSucceeded)
+	t.WorkflowSubState = &succeeded
+	t.WorkflowRetryCount = nil
+	t.WorkflowErrorMessage = nil
+	t.WorkflowExecutionID = nil
+
+	if next, ok := migration.Phase.Next(); ok {
+		t.SetMigrationPhase(next)
+		t.StatusMessage = fmt.Sprintf("Migrating to %s (%s)", migration.Target, next)
+	} else {
+		t.ClearMigration()
+		t.Status = tenant.StatusReady
+		t.StatusMessage = fmt.Sprintf("Migrated from %s to %s: %s", migration.Source, migration.Target, execStatus.ExecutionID)
+	}
+
+	if err := r.tenantRepo.UpdateTenant(ctx, t); err != nil {
+		return fmt.Errorf("update tenant: %w", err)
+	}
+
+	r.logger.Info("tenant migration phase completed",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("tenant_name", t.Name),
+		zap.String("source", migration.Source),
+		zap.String("target", migration.Target),
+		zap.String("phase", string(migration.Phase)),
+		zap.String("execution_id", execStatus.ExecutionID))
+	return nil
+}
+
 func (r *Reconciler) handleWorkflowFailure(ctx context.Context, t *tenant.Tenant, execStatus *workflow.ExecutionStatus) error {
 	message := fmt.Sprintf("Workflow execution failed: %s", execStatus.ExecutionID)
 	if execStatus.Error != nil && execStatus.Error.Message != "" {
 		message = fmt.Sprintf("%s: %s", message, execStatus.Error.Message)
 	}
 
+	if migration := t.Migration(); t.Status == tenant.StatusMigrating && migration != nil {
+		// The migration record is kept so the migration can resume from this phase
+		message = fmt.Sprintf("Migration to %s failed during %s: %s", migration.Target, migration.Phase, message)
+	}
+
 	t.Status = tenant.StatusFailed
 	t.StatusMessage = message
+	t.WorkflowStartedAt = nil
+	r.clearWorkflowTimeouts(t.ID.String())
+	r.workflowSlots.release(t.ID.String())
+	r.admission.record(true)
 
 	failed := string(workflow.SubStateFailed)
 	t.WorkflowSubState = &failed
 	if execStatus.Error != nil && execStatus.Error.Message != "" {
 		msg := execStatus.Error.Message
 		t.WorkflowErrorMessage = &msg
+		workflow.SetReadinessFailure(t, msg, time.Now())
 	}
 	if _, retryCount, _ := workflow.ExtractWorkflowDetails(execStatus); retryCount != nil {
 		t.WorkflowRetryCount = retryCount
@@ -538,55 +940,71 @@ func (r *Reconciler) handleWorkflowFailure(ctx context.Context, t *tenant.Tenant
 	return nil
 }
 
-// handleReconcileError handles errors during reconciliation
+// handleReconcileError handles errors during reconciliation. The tenant's retry policy decides
+// whether and when the reconcile is retried; once it gives up the tenant is marked failed.
 func (r *Reconciler) handleReconcileError(tenantID string, err error) {
 	retryCount := r.incrementRetryCount(tenantID)
 
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// A tenant that cannot be read is retried with the default policy
+	var t *tenant.Tenant
+	tenantUUID, fetchErr := uuid.Parse(tenantID)
+	if fetchErr == nil {
+		t, fetchErr = r.tenantRepo.GetTenantByID(ctx, tenantUUID)
+	}
+	if fetchErr != nil {
+		r.logger.Warn("failed to fetch tenant for retry policy",
+			zap.String("tenant_id", tenantID),
+			zap.Error(fetchErr))
+		t = nil
+	}
+	policy, policyErr := r.retryPolicy(t)
+	if policyErr != nil {
+		r.logger.Warn("ignoring invalid tenant retry policy",
+			zap.String("tenant_id", tenantID),
+			zap.Error(policyErr))
+	}
+	code, retriable := retriableCode(policy, err)
+
 	r.logger.Error("reconciliation failed",
 		zap.String("tenant_id", tenantID),
 		zap.Error(err),
-		zap.Int("retry_count", retryCount))
+		zap.String("error_code", code),
+		zap.Int("retry_count", retryCount),
+		zap.Int("max_attempts", policy.MaxAttempts))
+
+	if retriable && retryCount < policy.MaxAttempts {
+		r.queue.AddRateLimitedAfter(tenantID, retryBackoff(policy, retryCount))
+		return
+	}
 
-	// Check if exceeded max retries
-	if retryCount >= r.config.MaxRetries {
+	message := fmt.Sprintf("Reconciliation failed after %d retries: %v", retryCount, err)
+	if retriable {
 		r.logger.Error("max retries exceeded, marking tenant as failed",
 			zap.String("tenant_id", tenantID))
-
-		// Mark tenant as failed
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		tenantUUID, parseErr := uuid.Parse(tenantID)
-		if parseErr != nil {
-			r.logger.Error("failed to parse tenant id for failure update",
-				zap.String("tenant_id", tenantID),
-				zap.Error(parseErr))
-			return
-		}
-
-		t, err := r.tenantRepo.GetTenantByID(ctx, tenantUUID)
-		if err != nil {
-			r.logger.Error("failed to fetch tenant for failure update",
-				zap.String("tenant_id", tenantID),
-				zap.Error(err))
-			return
-		}
-
-		t.Status = tenant.StatusFailed
-		t.StatusMessage = fmt.Sprintf("Reconciliation failed after %d retries: %v", retryCount, err)
-
-		if err := r.tenantRepo.UpdateTenant(ctx, t); err != nil {
-			r.logger.Error("failed to update tenant to failed status",
-				zap.String("tenant_id", tenantID),
-				zap.Error(err))
-		}
-
-		r.resetRetryCount(tenantID)
+		reconcileMetrics.retriesExceeded.Add(1)
+	} else {
+		r.logger.Error("non-retriable error, marking tenant as failed",
+			zap.String("tenant_id", tenantID),
+			zap.String("error_code", code))
+		reconcileMetrics.nonRetriable.Add(1)
+		message = fmt.Sprintf("Reconciliation failed with non-retriable error %s: %v", code, err)
+	}
+	r.resetRetryCount(tenantID)
+	if t == nil {
 		return
 	}
 
-	// Requeue with rate limiting
-	r.queue.AddRateLimited(tenantID)
+	t.Status = tenant.StatusFailed
+	t.StatusMessage = message
+
+	if err := r.tenantRepo.UpdateTenant(ctx, t); err != nil {
+		r.logger.Error("failed to update tenant to failed status",
+			zap.String("tenant_id", tenantID),
+			zap.Error(err))
+	}
 }
 
 // incrementRetryCount increments the retry counter for a tenant
@@ -707,6 +1125,7 @@ stopped:
 
 	// Clear old execution ID and error state
 	reloadedTenant.WorkflowExecutionID = nil
+	reloadedTenant.WorkflowStartedAt = nil
 	reloadedTenant.WorkflowErrorMessage = nil
 	retryCount := 0
 	reloadedTenant.WorkflowRetryCount = &retryCount
@@ -725,31 +1144,44 @@ stopped:
 		return fmt.Errorf("failed to determine action for new workflow: %w", err)
 	}
 
-	// Trigger new workflow with config-change source to differentiate from normal triggers
-	newExecutionID, err := r.workflowClient.TriggerWorkflowWithSource(ctx, reloadedTenant, action, "controller:config-change")
-	if err != nil {
-		return fmt.Errorf("failed to trigger new workflow: %w", err)
-	}
-
-	// Reload tenant again to get latest version after trigger
+	// Reload tenant to get latest version before claiming the trigger
 	reloadedTenant, err = r.tenantRepo.GetTenantByID(ctx, reloadedTenant.ID)
 	if err != nil {
-		return fmt.Errorf("failed to reload tenant after trigger: %w", err)
+		return fmt.Errorf("failed to reload tenant before trigger: %w", err)
 	}
 
-	// Update tenant with new execution ID and config hash
-	reloadedTenant.WorkflowExecutionID = &newExecutionID
-	configHash, err := tenant.ComputeConfigHash(reloadedTenant.DesiredConfig)
+	// Trigger new workflow with config-change source to differentiate from normal triggers,
+	// fenced like reconcile so the new execution ID is written with the trigger
+	var newExecutionID string
+	err = r.tenantRepo.FenceWorkflowTrigger(ctx, reloadedTenant, func(ctx context.Context, t *tenant.Tenant) error {
+		id, err := r.workflowClient.TriggerWorkflowWithSource(ctx, t, action, "controller:config-change")
+		if err != nil {
+			return fmt.Errorf("failed to trigger new workflow: %w", err)
+		}
+		newExecutionID = id
+
+		// Update tenant with new execution ID and config hash
+		recordLastAction(t, newExecutionID, action, "controller:config-change")
+		t.WorkflowExecutionID = &newExecutionID
+		startedAt := time.Now()
+		t.WorkflowStartedAt = &startedAt
+		workflowVersion := workflow.LatestWorkflowVersion
+		t.WorkflowVersion = &workflowVersion
+		configHash, err := tenant.ComputeConfigHash(t.DesiredConfig)
+		if err != nil {
+			r.logger.Warn("failed to compute config hash for new workflow",
+				zap.String("tenant_id", t.ID.String()),
+				zap.Error(err))
+		} else {
+			t.WorkflowConfigHash = &configHash
+		}
+		return nil
+	})
 	if err != nil {
-		r.logger.Warn("failed to compute config hash for new workflow",
-			zap.String("tenant_id", reloadedTenant.ID.String()),
-			zap.Error(err))
-	} else {
-		reloadedTenant.WorkflowConfigHash = &configHash
-	}
-
-	if err := r.tenantRepo.UpdateTenant(ctx, reloadedTenant); err != nil {
-		return fmt.Errorf("failed to update tenant with new workflow execution: %w", err)
+		if newExecutionID != "" {
+			return fmt.Errorf("failed to update tenant with new workflow execution %s: %w", newExecutionID, err)
+		}
+		return err
 	}
 
 	r.logger.Info("new workflow triggered after config change",
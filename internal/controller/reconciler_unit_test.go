@@ -10,6 +10,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jaxxstorm/landlord/internal/config"
 	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/vulnscan"
 	"github.com/jaxxstorm/landlord/internal/workflow"
 	workflowmock "github.com/jaxxstorm/landlord/internal/workflow/providers/mock"
 	"github.com/stretchr/testify/require"
@@ -105,6 +106,24 @@ func (m *memoryTenantRepo) UpdateTenant(ctx context.Context, t *tenant.Tenant) e
 	return nil
 }
 
+func (m *memoryTenantRepo) FenceWorkflowTrigger(ctx context.Context, t *tenant.Tenant, trigger func(ctx context.Context, t *tenant.Tenant) error) error {
+	current, err := m.GetTenantByID(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	if t.Version != 0 && t.Version != current.Version {
+		return tenant.ErrVersionConflict
+	}
+	if err := trigger(ctx, t); err != nil {
+		return err
+	}
+	return m.UpdateTenant(ctx, t)
+}
+
+func (m *memoryTenantRepo) LockTenant(ctx context.Context, id uuid.UUID) (func(), error) {
+	return func() {}, nil
+}
+
 func (m *memoryTenantRepo) ListTenants(ctx context.Context, filters tenant.ListFilters) ([]*tenant.Tenant, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -160,6 +179,18 @@ func (m *memoryTenantRepo) ListTenants(ctx context.Context, filters tenant.ListF
 	return results, nil
 }
 
+func (m *memoryTenantRepo) ListTenantSummaries(ctx context.Context, filters tenant.ListFilters) ([]*tenant.Summary, error) {
+	tenants, err := m.ListTenants(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]*tenant.Summary, 0, len(tenants))
+	for _, t := range tenants {
+		summaries = append(summaries, &tenant.Summary{ID: t.ID, Name: t.Name, Status: t.Status, Labels: t.Labels, UpdatedAt: t.UpdatedAt})
+	}
+	return summaries, nil
+}
+
 func (m *memoryTenantRepo) ListTenantsForReconciliation(ctx context.Context) ([]*tenant.Tenant, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -200,7 +231,7 @@ func (m *memoryTenantRepo) RecordStateTransition(ctx context.Context, transition
 	return nil
 }
 
-func (m *memoryTenantRepo) GetStateHistory(ctx context.Context, tenantID uuid.UUID) ([]*tenant.StateTransition, error) {
+func (m *memoryTenantRepo) GetStateHistory(ctx context.Context, tenantID uuid.UUID, filters tenant.HistoryFilters) ([]*tenant.StateTransition, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -234,6 +265,10 @@ func cloneTenant(t *tenant.Tenant) *tenant.Tenant {
 		msg := *t.WorkflowErrorMessage
 		clone.WorkflowErrorMessage = &msg
 	}
+	if t.WorkflowStartedAt != nil {
+		startedAt := *t.WorkflowStartedAt
+		clone.WorkflowStartedAt = &startedAt
+	}
 	clone.DesiredConfig = copyInterfaceMap(t.DesiredConfig)
 	clone.ObservedConfig = copyInterfaceMap(t.ObservedConfig)
 	clone.Labels = copyStringMap(t.Labels)
@@ -410,6 +445,12 @@ func TestReconciler_InvokesWorkflowForRequestedTenant(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, tenant.StatusProvisioning, updated.Status)
 	require.NotNil(t, updated.WorkflowExecutionID)
+	require.NotNil(t, updated.WorkflowVersion)
+	require.Equal(t, workflow.LatestWorkflowVersion, *updated.WorkflowVersion)
+	require.NotNil(t, updated.LastAction)
+	require.Equal(t, tenant.ActionProvision, updated.LastAction.Action)
+	require.Equal(t, "controller", updated.LastAction.Source)
+	require.Equal(t, *updated.WorkflowExecutionID, updated.LastAction.ExecutionID)
 }
 
 func TestReconciler_UpdatesTenantOnWorkflowSuccess(t *testing.T) {
@@ -475,3 +516,103 @@ func TestReconciler_UpdatesTenantOnWorkflowSuccess(t *testing.T) {
 	require.Equal(t, "success", updated.ObservedConfig["result"])
 	require.Equal(t, true, updated.ObservedConfig["mock"])
 }
+
+func TestReconciler_RecordsWorkflowOutputDetails(t *testing.T) {
+	repo := newMemoryTenantRepo()
+	logger := zaptest.NewLogger(t)
+	reconciler := NewReconciler(repo, nil, config.ControllerConfig{}, logger)
+
+	tenantID := uuid.New()
+	tnt := &tenant.Tenant{
+		ID:     tenantID,
+		Name:   "hooked-tenant",
+		Status: tenant.StatusProvisioning,
+	}
+	require.NoError(t, repo.CreateTenant(context.Background(), tnt))
+
+	output, err := json.Marshal(map[string]interface{}{
+		"status":       "success",
+		"resource_ids": map[string]string{"container": "abc123"},
+		"resources": map[string]interface{}{
+			"files": map[string]interface{}{
+				"type":    "bucket",
+				"outputs": map[string]string{"name": "landlord-hooked", "mode": "bucket"},
+			},
+		},
+		"hooks": []workflow.HookResult{
+			{Name: "schema", Phase: workflow.HookPhasePreProvision, Type: workflow.HookTypeContainer, Status: workflow.HookStatusSucceeded, Attempts: 1},
+			{Name: "smoke", Phase: workflow.HookPhasePostProvision, Type: workflow.HookTypeHTTP, Status: workflow.HookStatusSucceeded, Attempts: 2},
+		},
+	})
+	require.NoError(t, err)
+
+	err = reconciler.handleWorkflowSuccess(context.Background(), tnt, &workflow.ExecutionStatus{
+		ExecutionID: "exec-hooks",
+		State:       workflow.StateSucceeded,
+		Output:      output,
+	})
+	require.NoError(t, err)
+
+	updated, err := repo.GetTenantByID(context.Background(), tenantID)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusReady, updated.Status)
+	require.NotContains(t, updated.ObservedConfig, "hooks")
+	require.Contains(t, updated.ObservedConfig, "resources")
+
+	require.Equal(t, map[string]string{
+		"container":   "abc123",
+		"files.name":  "landlord-hooked",
+		"files.mode":  "bucket",
+	}, updated.ObservedResourceIDs)
+
+	history := repo.history[tenantID]
+	require.Len(t, history, 2)
+	require.Equal(t, "workflow:hook", history[0].TriggeredBy)
+	require.Equal(t, "schema", history[0].ObservedStateSnapshot["name"])
+	require.Contains(t, history[1].Reason, `post_provision hook "smoke" succeeded after 2 attempt(s)`)
+}
+
+func TestReconciler_RecordsVulnerabilityScan(t *testing.T) {
+	repo := newMemoryTenantRepo()
+	logger := zaptest.NewLogger(t)
+	reconciler := NewReconciler(repo, nil, config.ControllerConfig{}, logger)
+
+	tenantID := uuid.New()
+	tnt := &tenant.Tenant{
+		ID:     tenantID,
+		Name:   "scanned-tenant",
+		Status: tenant.StatusProvisioning,
+	}
+	require.NoError(t, repo.CreateTenant(context.Background(), tnt))
+
+	output, err := json.Marshal(map[string]interface{}{
+		"status": "success",
+		vulnscan.OutputKey: vulnscan.Summary{
+			Image:       "nginx:1.27",
+			Scanner:     "trivy",
+			Critical:    2,
+			High:        5,
+			CriticalIDs: []string{"CVE-2024-0001", "CVE-2024-0002"},
+			MaxCritical: 1,
+		},
+	})
+	require.NoError(t, err)
+
+	err = reconciler.handleWorkflowSuccess(context.Background(), tnt, &workflow.ExecutionStatus{
+		ExecutionID: "exec-scan",
+		State:       workflow.StateSucceeded,
+		Output:      output,
+	})
+	require.NoError(t, err)
+
+	updated, err := repo.GetTenantByID(context.Background(), tenantID)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusReady, updated.Status)
+	require.Contains(t, updated.ObservedConfig, vulnscan.OutputKey)
+
+	condition := updated.Condition(vulnscan.ConditionPassed)
+	require.NotNil(t, condition)
+	require.Equal(t, tenant.ConditionFalse, condition.Status)
+	require.Equal(t, vulnscan.ReasonThresholdExceeded, condition.Reason)
+	require.Contains(t, condition.Message, "2 critical")
+}
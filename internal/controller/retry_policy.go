@@ -0,0 +1,162 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// AnnotationRetryPolicy overrides the configured retry policies for one tenant. Its value is a JSON
+// object keyed by action, whose fields replace the configured ones:
+//
+//	{"provision": {"max_attempts": 10, "initial_backoff": "5s", "retriable_error_codes": ["PROVIDER_TIMEOUT"]}}
+const AnnotationRetryPolicy = "landlord/retry-policy"
+
+// Backoff applied when neither the configuration nor the tenant sets one
+const (
+	defaultRetryInitialBackoff = time.Second
+	defaultRetryMaxBackoff     = 5 * time.Minute
+	defaultRetryMultiplier     = 2
+)
+
+// retryAction names the action a reconcile of a tenant in status performs, as keyed in retry_policies
+func retryAction(status tenant.Status) string {
+	switch status {
+	case tenant.StatusRequested, tenant.StatusPlanning, tenant.StatusProvisioning:
+		return "provision"
+	case tenant.StatusUpdating:
+		return "update"
+	case tenant.StatusArchiving, tenant.StatusDeleting:
+		return "archive"
+	default:
+		return ""
+	}
+}
+
+// retryPolicyOverride is one action's entry in AnnotationRetryPolicy; unset fields keep the configured value
+type retryPolicyOverride struct {
+	MaxAttempts         *int     `json:"max_attempts,omitempty"`
+	InitialBackoff      string   `json:"initial_backoff,omitempty"`
+	MaxBackoff          string   `json:"max_backoff,omitempty"`
+	BackoffMultiplier   *float64 `json:"backoff_multiplier,omitempty"`
+	RetriableErrorCodes []string `json:"retriable_error_codes,omitempty"`
+}
+
+// parseRetryPolicyAnnotation parses an AnnotationRetryPolicy value into a policy per action,
+// holding only the fields the tenant sets
+func parseRetryPolicyAnnotation(value string) (map[string]config.RetryPolicy, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.DisallowUnknownFields()
+	var overrides map[string]retryPolicyOverride
+	if err := decoder.Decode(&overrides); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", AnnotationRetryPolicy, err)
+	}
+
+	policies := make(map[string]config.RetryPolicy, len(overrides))
+	for action, override := range overrides {
+		if !slices.Contains(config.RetryPolicyActions, action) {
+			return nil, fmt.Errorf("invalid %s annotation: unknown action %q", AnnotationRetryPolicy, action)
+		}
+		var policy config.RetryPolicy
+		if override.MaxAttempts != nil {
+			policy.MaxAttempts = *override.MaxAttempts
+		}
+		if override.BackoffMultiplier != nil {
+			policy.BackoffMultiplier = *override.BackoffMultiplier
+		}
+		for _, d := range []struct {
+			name  string
+			value string
+			into  *time.Duration
+		}{
+			{"initial_backoff", override.InitialBackoff, &policy.InitialBackoff},
+			{"max_backoff", override.MaxBackoff, &policy.MaxBackoff},
+		} {
+			if d.value == "" {
+				continue
+			}
+			parsed, err := time.ParseDuration(d.value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s annotation: %s.%s: %w", AnnotationRetryPolicy, action, d.name, err)
+			}
+			*d.into = parsed
+		}
+		policy.RetriableErrorCodes = override.RetriableErrorCodes
+		if err := policy.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid %s annotation: %s: %w", AnnotationRetryPolicy, action, err)
+		}
+		policies[action] = policy
+	}
+	return policies, nil
+}
+
+// mergeRetryPolicy returns base with the fields override sets replaced
+func mergeRetryPolicy(base, override config.RetryPolicy) config.RetryPolicy {
+	if override.MaxAttempts > 0 {
+		base.MaxAttempts = override.MaxAttempts
+	}
+	if override.InitialBackoff > 0 {
+		base.InitialBackoff = override.InitialBackoff
+	}
+	if override.MaxBackoff > 0 {
+		base.MaxBackoff = override.MaxBackoff
+	}
+	if override.BackoffMultiplier > 0 {
+		base.BackoffMultiplier = override.BackoffMultiplier
+	}
+	if override.RetriableErrorCodes != nil {
+		base.RetriableErrorCodes = override.RetriableErrorCodes
+	}
+	return base
+}
+
+// retryPolicy returns the policy for the action a reconcile of t performs: the configured policy
+// for the action over the defaults, then the tenant's annotation over that. A nil tenant gets the
+// defaults. An invalid annotation is reported and otherwise ignored.
+func (r *Reconciler) retryPolicy(t *tenant.Tenant) (config.RetryPolicy, error) {
+	policy := config.RetryPolicy{
+		MaxAttempts:       r.config.MaxRetries,
+		InitialBackoff:    defaultRetryInitialBackoff,
+		MaxBackoff:        defaultRetryMaxBackoff,
+		BackoffMultiplier: defaultRetryMultiplier,
+	}
+	if t == nil {
+		return policy, nil
+	}
+	action := retryAction(t.Status)
+	policy = mergeRetryPolicy(policy, r.config.RetryPolicies[action])
+
+	value, ok := t.Annotations[AnnotationRetryPolicy]
+	if !ok {
+		return policy, nil
+	}
+	overrides, err := parseRetryPolicyAnnotation(value)
+	if err != nil {
+		return policy, err
+	}
+	return mergeRetryPolicy(policy, overrides[action]), nil
+}
+
+// retryBackoff returns how long to wait before retrying after the given failed attempt
+func retryBackoff(policy config.RetryPolicy, attempt int) time.Duration {
+	backoff := policy.InitialBackoff
+	for i := 1; i < attempt && backoff < policy.MaxBackoff; i++ {
+		backoff = time.Duration(float64(backoff) * policy.BackoffMultiplier)
+	}
+	return min(backoff, policy.MaxBackoff)
+}
+
+// retriableCode returns the compute error code of err, and whether policy retries it
+func retriableCode(policy config.RetryPolicy, err error) (string, bool) {
+	code := compute.ClassifyError(err).Code
+	if len(policy.RetriableErrorCodes) == 0 {
+		return code, true
+	}
+	return code, slices.Contains(policy.RetriableErrorCodes, code)
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRetryPolicyResolution(t *testing.T) {
+	r := &Reconciler{config: config.ControllerConfig{
+		MaxRetries: 5,
+		RetryPolicies: map[string]config.RetryPolicy{
+			"provision": {MaxAttempts: 8, InitialBackoff: 2 * time.Second},
+			"archive":   {RetriableErrorCodes: []string{compute.ErrorCodeTimeout}},
+		},
+	}}
+
+	policy, err := r.retryPolicy(nil)
+	require.NoError(t, err)
+	require.Equal(t, config.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Minute, BackoffMultiplier: 2}, policy)
+
+	policy, err = r.retryPolicy(&tenant.Tenant{Status: tenant.StatusRequested})
+	require.NoError(t, err)
+	require.Equal(t, 8, policy.MaxAttempts)
+	require.Equal(t, 2*time.Second, policy.InitialBackoff)
+	require.Equal(t, 5*time.Minute, policy.MaxBackoff)
+
+	// The annotation replaces only the fields it sets, for the tenant's current action
+	annotated := &tenant.Tenant{
+		Status: tenant.StatusProvisioning,
+		Annotations: map[string]string{
+			AnnotationRetryPolicy: `{"provision": {"max_backoff": "10s", "retriable_error_codes": ["RESOURCE_EXHAUSTED"]}, "update": {"max_attempts": 1}}`,
+		},
+	}
+	policy, err = r.retryPolicy(annotated)
+	require.NoError(t, err)
+	require.Equal(t, config.RetryPolicy{
+		MaxAttempts:         8,
+		InitialBackoff:      2 * time.Second,
+		MaxBackoff:          10 * time.Second,
+		BackoffMultiplier:   2,
+		RetriableErrorCodes: []string{compute.ErrorCodeResourceExhausted},
+	}, policy)
+
+	annotated.Status = tenant.StatusDeleting
+	policy, err = r.retryPolicy(annotated)
+	require.NoError(t, err)
+	require.Equal(t, 5, policy.MaxAttempts)
+	require.Equal(t, []string{compute.ErrorCodeTimeout}, policy.RetriableErrorCodes)
+
+	for _, value := range []string{
+		`{"migrate": {"max_attempts": 2}}`,
+		`{"provision": {"max_attempts": 2, "jitter": true}}`,
+		`{"provision": {"initial_backoff": "soon"}}`,
+		`{"provision": {"backoff_multiplier": 0.5}}`,
+	} {
+		annotated.Annotations[AnnotationRetryPolicy] = value
+		_, err := r.retryPolicy(annotated)
+		require.Error(t, err, value)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := config.RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second, BackoffMultiplier: 3}
+	var got []time.Duration
+	for attempt := 1; attempt <= 4; attempt++ {
+		got = append(got, retryBackoff(policy, attempt))
+	}
+	require.Equal(t, []time.Duration{time.Second, 3 * time.Second, 9 * time.Second, 10 * time.Second}, got)
+}
+
+func TestHandleReconcileErrorAppliesRetryPolicy(t *testing.T) {
+	repo := newMemoryTenantRepo()
+	cfg := config.ControllerConfig{
+		MaxRetries: 5,
+		RetryPolicies: map[string]config.RetryPolicy{
+			"provision": {RetriableErrorCodes: []string{compute.ErrorCodeTimeout, compute.ErrorCodeUnavailable}},
+		},
+	}
+	r := NewReconciler(repo, &WorkflowClient{}, cfg, zaptest.NewLogger(t))
+	defer r.queue.ShutDown()
+
+	id := uuid.New()
+	require.NoError(t, repo.CreateTenant(context.Background(), &tenant.Tenant{ID: id, Name: "retry-policy", Status: tenant.StatusRequested}))
+
+	// A retriable error requeues the tenant after the policy's backoff
+	r.handleReconcileError(id.String(), compute.ErrProviderUnavailable)
+	updated, err := repo.GetTenantByID(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusRequested, updated.Status)
+	require.Eventually(t, func() bool { return r.queue.Len() == 1 }, 3*time.Second, 10*time.Millisecond)
+
+	// An error whose code the policy does not list fails the tenant at once
+	r.handleReconcileError(id.String(), errors.New("workflow engine rejected the request"))
+	updated, err = repo.GetTenantByID(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusFailed, updated.Status)
+	require.Contains(t, updated.StatusMessage, "non-retriable error UNKNOWN_ERROR")
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// batchStatusClient is implemented by workflow clients that can query many executions at once
+type batchStatusClient interface {
+	GetExecutionStatuses(ctx context.Context, executionIDs []string) (map[string]*workflow.ExecutionStatus, error)
+}
+
+// statusBatch holds the execution statuses queried together when the status loop polls tenants,
+// until each tenant's reconcile takes its own. Statuses older than maxAge are not handed out, so a
+// tenant that waited in the queue is queried again. A nil statusBatch holds nothing.
+type statusBatch struct {
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu       sync.Mutex
+	statuses map[string]batchedStatus
+}
+
+type batchedStatus struct {
+	status    *workflow.ExecutionStatus
+	fetchedAt time.Time
+}
+
+// newStatusBatch creates a status batch, returning nil when maxAge is not positive
+func newStatusBatch(maxAge time.Duration) *statusBatch {
+	if maxAge <= 0 {
+		return nil
+	}
+	return &statusBatch{maxAge: maxAge, now: time.Now, statuses: make(map[string]batchedStatus)}
+}
+
+// store replaces the held statuses with statuses
+func (b *statusBatch) store(statuses map[string]*workflow.ExecutionStatus) {
+	if b == nil {
+		return
+	}
+	now := b.now()
+	held := make(map[string]batchedStatus, len(statuses))
+	for executionID, status := range statuses {
+		if status != nil {
+			held[executionID] = batchedStatus{status: status, fetchedAt: now}
+		}
+	}
+	b.mu.Lock()
+	b.statuses = held
+	b.mu.Unlock()
+}
+
+// take removes and returns the held status of an execution, or nil when there is none or it is stale
+func (b *statusBatch) take(executionID string) *workflow.ExecutionStatus {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	held, ok := b.statuses[executionID]
+	if !ok {
+		return nil
+	}
+	delete(b.statuses, executionID)
+	if b.now().Sub(held.fetchedAt) > b.maxAge {
+		return nil
+	}
+	return held.status
+}
+
+// prefetchStatuses queries the executions of in-flight tenants in one batch, so each tenant's
+// reconcile does not query the workflow provider on its own
+func (r *Reconciler) prefetchStatuses(ctx context.Context, tenants []*tenant.Tenant) {
+	if r.statusBatch == nil {
+		return
+	}
+	client, ok := r.workflowClient.(batchStatusClient)
+	if !ok {
+		return
+	}
+
+	var executionIDs []string
+	for _, t := range tenants {
+		if isInFlightStatus(t.Status) && t.WorkflowExecutionID != nil && *t.WorkflowExecutionID != "" {
+			executionIDs = append(executionIDs, *t.WorkflowExecutionID)
+		}
+	}
+	if len(executionIDs) == 0 {
+		return
+	}
+
+	statuses, err := client.GetExecutionStatuses(ctx, executionIDs)
+	if err != nil {
+		r.logger.Warn("failed to batch workflow status queries, tenants will be queried one at a time",
+			zap.Int("executions", len(executionIDs)),
+			zap.Error(err))
+		return
+	}
+	r.statusBatch.store(statuses)
+	r.logger.Debug("batched workflow status queries",
+		zap.Int("executions", len(executionIDs)),
+		zap.Int("statuses", len(statuses)))
+}
+
+// executionStatus returns an execution's status from the last batch, or queries it when the batch has none
+func (r *Reconciler) executionStatus(ctx context.Context, executionID string) (*workflow.ExecutionStatus, error) {
+	if status := r.statusBatch.take(executionID); status != nil {
+		return status, nil
+	}
+	return r.workflowClient.GetExecutionStatus(ctx, executionID)
+}
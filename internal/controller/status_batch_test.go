@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+type batchStubWorkflowClient struct {
+	stubWorkflowClient
+
+	mu      sync.Mutex
+	batches int
+	singles int
+}
+
+func (b *batchStubWorkflowClient) GetExecutionStatus(ctx context.Context, executionID string) (*workflow.ExecutionStatus, error) {
+	b.mu.Lock()
+	b.singles++
+	b.mu.Unlock()
+	return &workflow.ExecutionStatus{ExecutionID: executionID, State: workflow.StateRunning}, nil
+}
+
+func (b *batchStubWorkflowClient) GetExecutionStatuses(ctx context.Context, executionIDs []string) (map[string]*workflow.ExecutionStatus, error) {
+	b.mu.Lock()
+	b.batches++
+	b.mu.Unlock()
+	statuses := make(map[string]*workflow.ExecutionStatus, len(executionIDs))
+	for _, id := range executionIDs {
+		statuses[id] = &workflow.ExecutionStatus{ExecutionID: id, State: workflow.StateRunning}
+	}
+	return statuses, nil
+}
+
+func TestReconciler_BatchesStatusQueriesOnResync(t *testing.T) {
+	repo := newMemoryTenantRepo()
+	client := &batchStubWorkflowClient{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reconciler := &Reconciler{
+		tenantRepo:     repo,
+		workflowClient: client,
+		config:         config.ControllerConfig{StatusPollInterval: time.Minute},
+		logger:         zap.NewNop(),
+		retryCount:     make(map[string]int),
+		queue:          NewRateLimitingQueue(),
+		ctx:            ctx,
+		cancel:         cancel,
+		statusBatch:    newStatusBatch(time.Minute),
+	}
+
+	for i := 0; i < 3; i++ {
+		executionID := fmt.Sprintf("exec-%d", i)
+		require.NoError(t, repo.CreateTenant(context.Background(), &tenant.Tenant{
+			ID:                  uuid.New(),
+			Name:                fmt.Sprintf("tenant-%d", i),
+			Status:              tenant.StatusProvisioning,
+			WorkflowExecutionID: &executionID,
+			DesiredConfig:       map[string]interface{}{"image": "nginx:latest"},
+		}))
+	}
+
+	tenants, err := reconciler.pollTenantsByStatus([]tenant.Status{tenant.StatusProvisioning})
+	require.NoError(t, err)
+	require.Len(t, tenants, 3)
+	for _, tn := range tenants {
+		require.NoError(t, reconciler.reconcile(tn.ID.String()))
+	}
+	require.Equal(t, 1, client.batches)
+	require.Equal(t, 0, client.singles, "expected every tenant to use the batched status")
+
+	// A batched status is used once; the next reconcile queries the provider
+	require.NoError(t, reconciler.reconcile(tenants[0].ID.String()))
+	require.Equal(t, 1, client.singles)
+}
+
+func TestStatusBatchTakeSkipsStaleStatuses(t *testing.T) {
+	now := time.Now()
+	batch := newStatusBatch(time.Second)
+	batch.now = func() time.Time { return now }
+	batch.store(map[string]*workflow.ExecutionStatus{
+		"fresh": {ExecutionID: "fresh"},
+		"stale": {ExecutionID: "stale"},
+	})
+
+	require.NotNil(t, batch.take("fresh"))
+	require.Nil(t, batch.take("fresh"), "expected a status to be taken once")
+
+	now = now.Add(2 * time.Second)
+	require.Nil(t, batch.take("stale"))
+	require.Nil(t, newStatusBatch(0), "expected no batch without a status poll interval")
+}
@@ -2,13 +2,21 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/featureflag"
+	"github.com/jaxxstorm/landlord/internal/resource"
 	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/version"
 	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
 )
 
 // WorkflowClient wraps the workflow manager and compute integration for controller use
@@ -18,6 +26,9 @@ type WorkflowClient struct {
 	logger        *zap.Logger
 	timeout       time.Duration
 	providerType  string
+
+	// Feature flags tenants may set; nil declares only the built-in flags
+	flags *featureflag.Registry
 }
 
 // NewWorkflowClient creates a workflow client
@@ -41,6 +52,11 @@ func NewWorkflowClientWithCompute(manager *workflow.Manager, computeClient *work
 	}
 }
 
+// SetFeatureFlags sets the feature flags passed to workflows from tenants' annotations
+func (wc *WorkflowClient) SetFeatureFlags(registry *featureflag.Registry) {
+	wc.flags = registry
+}
+
 // TriggerWorkflow triggers a workflow based on tenant status
 // Returns execution ID and error
 func (wc *WorkflowClient) TriggerWorkflow(ctx context.Context, t *tenant.Tenant, action string) (string, error) {
@@ -78,22 +94,49 @@ func (wc *WorkflowClient) TriggerWorkflowWithSource(ctx context.Context, t *tena
 	}
 
 	request := &workflow.ProvisionRequest{
-		TenantID:      t.Name,
+		TenantID:      t.ComputeName(),
 		TenantUUID:    t.ID.String(),
 		Operation:     action,
 		DesiredConfig: t.DesiredConfig,
 		Metadata:      make(map[string]string),
+		// New executions always start on the latest definition
+		WorkflowVersion: workflow.LatestWorkflowVersion,
+		LandlordVersion: version.Version,
 	}
 	
 	// Add config hash to metadata if computed successfully
 	if configHash != "" {
 		request.Metadata["config_hash"] = configHash
 	}
+	// Managed labels ride in the metadata, which every schema version accepts, so workers can put
+	// them on the tenant's compute
+	for key, value := range tenant.ComputeLabels(t) {
+		request.Metadata[key] = value
+	}
+	// The API rejects invalid flags, but the registry may have changed since; those are left out
+	flags, err := wc.flags.Parse(t.Annotations)
+	if err != nil {
+		wc.logger.Warn("ignoring invalid feature flags",
+			zap.String("tenant_name", t.Name),
+			zap.Error(err))
+	}
+	if !flags.IsZero() {
+		request.Metadata[featureflag.MetadataKey] = flags.Encode()
+	}
 	if provider, ok := t.DesiredConfig["compute_provider"]; ok {
 		if value, ok := provider.(string); ok {
 			request.ComputeProvider = value
 		}
 	}
+	request.PreviousResources = previousResources(t.ObservedConfig)
+	request.PreviousEndpoints = previousEndpoints(t.ObservedConfig)
+	request.PreviousComponents = previousComponents(t.ObservedConfig)
+	request.SchemaVersion = provisionRequestVersion(t.DesiredConfig, request.PreviousComponents)
+	if migration := t.Migration(); action == "migrate" && migration != nil {
+		request.ComputeProvider = migration.Target
+		request.SourceComputeProvider = migration.Source
+		request.MigrationPhase = string(migration.Phase)
+	}
 
 	// Start workflow execution
 	// TODO: Get provider type from configuration or tenant
@@ -115,6 +158,14 @@ func (wc *WorkflowClient) TriggerWorkflowWithSource(ctx context.Context, t *tena
 	return result.ExecutionID, nil
 }
 
+// ListWorkflows returns the registered workflow definitions per provider
+func (wc *WorkflowClient) ListWorkflows(ctx context.Context) (map[string][]workflow.WorkflowDefinition, error) {
+	if wc.manager == nil {
+		return nil, fmt.Errorf("workflow manager not initialized")
+	}
+	return wc.manager.ListWorkflows(ctx)
+}
+
 // GetExecutionStatus queries the status of a workflow execution
 func (wc *WorkflowClient) GetExecutionStatus(ctx context.Context, executionID string) (*workflow.ExecutionStatus, error) {
 	if wc.manager == nil {
@@ -152,6 +203,26 @@ func (wc *WorkflowClient) GetExecutionStatus(ctx context.Context, executionID st
 	return status, nil
 }
 
+// GetExecutionStatuses queries the status of many workflow executions at once, keyed by execution ID
+// Executions whose status could not be read are left out of the result
+func (wc *WorkflowClient) GetExecutionStatuses(ctx context.Context, executionIDs []string) (map[string]*workflow.ExecutionStatus, error) {
+	if wc.manager == nil {
+		return nil, fmt.Errorf("workflow manager not initialized")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, wc.timeout)
+	defer cancel()
+
+	statuses, err := wc.manager.GetExecutionStatuses(ctx, executionIDs, wc.providerType)
+	if err != nil {
+		wc.logger.Error("failed to get execution statuses",
+			zap.Int("executions", len(executionIDs)),
+			zap.Error(err))
+		return nil, err
+	}
+	return statuses, nil
+}
+
 // DetermineAction determines the workflow action based on tenant status
 func (wc *WorkflowClient) DetermineAction(status tenant.Status) (string, error) {
 	switch status {
@@ -159,6 +230,8 @@ func (wc *WorkflowClient) DetermineAction(status tenant.Status) (string, error)
 		return "provision", nil
 	case tenant.StatusUpdating:
 		return "update", nil
+	case tenant.StatusMigrating:
+		return "migrate", nil
 	case tenant.StatusDeleting:
 		return "delete", nil
 	case tenant.StatusArchiving:
@@ -167,6 +240,8 @@ func (wc *WorkflowClient) DetermineAction(status tenant.Status) (string, error)
 		return "provision", nil
 	case tenant.StatusReady, tenant.StatusArchived, tenant.StatusFailed:
 		return "", fmt.Errorf("no action for terminal status: %s", status)
+	case tenant.StatusPendingApproval:
+		return "", fmt.Errorf("no action while awaiting approval: %s", status)
 	default:
 		return "", fmt.Errorf("unknown status: %s", status)
 	}
@@ -185,6 +260,8 @@ func IsRetryableError(err error) bool {
 		return true
 	case err == context.Canceled:
 		return false // Explicit cancellation is not retryable
+	case errors.Is(err, schema.ErrIncompatible):
+		return false // The same payload is rejected on every attempt
 	default:
 		// Default to retryable for unknown errors
 		return true
@@ -275,6 +352,13 @@ func (wc *WorkflowClient) StopExecution(ctx context.Context, t *tenant.Tenant, e
 		zap.String("tenant_id", t.ID.String()))
 
 	err = provider.StopExecution(ctx, executionID, reason)
+
+	// Compute operations the execution started in this process are cancelled even if the
+	// provider could not stop it, so they do not outlive a restart
+	if wc.computeClient != nil {
+		wc.computeClient.CancelExecution(executionID, reason)
+	}
+
 	if err != nil {
 		wc.logger.Error("failed to stop workflow execution",
 			zap.String("execution_id", executionID),
@@ -287,3 +371,69 @@ func (wc *WorkflowClient) StopExecution(ctx context.Context, t *tenant.Tenant, e
 
 	return nil
 }
+
+// previousResources lists the resources recorded by the last successful workflow, sorted by name
+func previousResources(observed map[string]interface{}) []resource.Ref {
+	entries, ok := observed[resource.ResourcesConfigKey].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	refs := make([]resource.Ref, 0, len(entries))
+	for name, entry := range entries {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if resourceType, ok := fields["type"].(string); ok && resourceType != "" {
+			refs = append(refs, resource.Ref{Name: name, Type: resourceType})
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Name < refs[j].Name })
+	return refs
+}
+
+// provisionRequestVersion returns the provision-request schema version to write. Tenants without
+// components stay on v1, so workers that predate components keep running them during a rolling upgrade.
+func provisionRequestVersion(desiredConfig map[string]interface{}, previous []workflow.ComponentRecord) string {
+	if !tenant.HasComponents(desiredConfig) && len(previous) == 0 {
+		return schema.V1
+	}
+	return schema.ProvisionRequestVersion
+}
+
+// previousComponents lists the components recorded by the last successful workflow, sorted by name.
+// Outputs from before components record none, and their tenant ran as the default component.
+func previousComponents(observed map[string]interface{}) []workflow.ComponentRecord {
+	entries, ok := observed[tenant.ComponentsConfigKey].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	records := make([]workflow.ComponentRecord, 0, len(entries))
+	for name, entry := range entries {
+		record := workflow.ComponentRecord{Name: name}
+		if fields, ok := entry.(map[string]interface{}); ok {
+			record.Endpoints = previousEndpoints(fields)
+		}
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	return records
+}
+
+// previousEndpoints decodes the compute endpoints recorded in a tenant's observed config
+func previousEndpoints(observed map[string]interface{}) []compute.Endpoint {
+	raw, ok := observed["endpoints"]
+	if !ok || raw == nil {
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var endpoints []compute.Endpoint
+	if err := json.Unmarshal(data, &endpoints); err != nil {
+		return nil
+	}
+	return endpoints
+}
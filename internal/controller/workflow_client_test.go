@@ -3,10 +3,13 @@ package controller
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
 	"go.uber.org/zap"
 )
 
@@ -138,6 +141,13 @@ func TestIsRetryableError_GenericError(t *testing.T) {
 	}
 }
 
+func TestIsRetryableError_IncompatiblePayload(t *testing.T) {
+	err := fmt.Errorf("%w: unknown provision-request schema version \"v2\"", schema.ErrIncompatible)
+	if IsRetryableError(err) {
+		t.Error("IsRetryableError(incompatible payload) = true, want false")
+	}
+}
+
 func TestDetermineAction_AllNonTerminalStates(t *testing.T) {
 	wc := newTestWorkflowClient()
 
@@ -149,6 +159,7 @@ func TestDetermineAction_AllNonTerminalStates(t *testing.T) {
 		{tenant.StatusPlanning, "provision"},
 		{tenant.StatusProvisioning, "provision"},
 		{tenant.StatusUpdating, "update"},
+		{tenant.StatusMigrating, "migrate"},
 		{tenant.StatusDeleting, "delete"},
 	}
 
@@ -220,3 +231,76 @@ func TestTriggerWorkflow_ComputesConfigHash(t *testing.T) {
 		t.Errorf("Config hash not deterministic: %s != %s", expectedHash, hash2)
 	}
 }
+
+func TestPreviousResources(t *testing.T) {
+	observed := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"files": map[string]interface{}{"type": "bucket", "outputs": map[string]interface{}{"name": "landlord-files"}},
+			"db":    map[string]interface{}{"type": "postgres"},
+			"bad":   "not-an-object",
+		},
+	}
+
+	refs := previousResources(observed)
+	if len(refs) != 2 || refs[0].Name != "db" || refs[0].Type != "postgres" || refs[1].Name != "files" {
+		t.Fatalf("unexpected previous resources: %+v", refs)
+	}
+	if refs := previousResources(nil); refs != nil {
+		t.Fatalf("expected no previous resources, got %+v", refs)
+	}
+}
+
+func TestPreviousEndpoints(t *testing.T) {
+	observed := map[string]interface{}{
+		"endpoints": []interface{}{
+			map[string]interface{}{"type": "http", "address": "172.17.0.2", "port": float64(8080), "url": "tcp://localhost:18080"},
+		},
+	}
+
+	endpoints := previousEndpoints(observed)
+	if len(endpoints) != 1 || endpoints[0].Address != "172.17.0.2" || endpoints[0].Port != 8080 {
+		t.Fatalf("unexpected previous endpoints: %+v", endpoints)
+	}
+	if endpoints := previousEndpoints(map[string]interface{}{"endpoints": "bad"}); endpoints != nil {
+		t.Fatalf("expected no previous endpoints, got %+v", endpoints)
+	}
+}
+
+func TestPreviousComponents(t *testing.T) {
+	observed := map[string]interface{}{
+		"components": map[string]interface{}{
+			"worker": map[string]interface{}{"compute_id": "tenant-1-worker"},
+			"app": map[string]interface{}{
+				"compute_id": "tenant-1",
+				"endpoints": []interface{}{
+					map[string]interface{}{"type": "http", "address": "172.17.0.2", "port": float64(8080)},
+				},
+			},
+		},
+	}
+
+	records := previousComponents(observed)
+	if len(records) != 2 || records[0].Name != "app" || records[1].Name != "worker" {
+		t.Fatalf("unexpected previous components: %+v", records)
+	}
+	if len(records[0].Endpoints) != 1 || records[0].Endpoints[0].Port != 8080 || records[1].Endpoints != nil {
+		t.Fatalf("unexpected previous component endpoints: %+v", records)
+	}
+	if records := previousComponents(map[string]interface{}{"endpoints": []interface{}{}}); records != nil {
+		t.Fatalf("expected no previous components, got %+v", records)
+	}
+}
+
+func TestProvisionRequestVersion(t *testing.T) {
+	if got := provisionRequestVersion(map[string]interface{}{"image": "nginx"}, nil); got != schema.V1 {
+		t.Fatalf("expected a tenant without components to stay on v1, got %s", got)
+	}
+	components := map[string]interface{}{"components": map[string]interface{}{"app": map[string]interface{}{}}}
+	if got := provisionRequestVersion(components, nil); got != schema.V2 {
+		t.Fatalf("expected a tenant with components to use v2, got %s", got)
+	}
+	// A tenant that dropped its components still has some to destroy
+	if got := provisionRequestVersion(map[string]interface{}{"image": "nginx"}, []workflow.ComponentRecord{{Name: "worker"}}); got != schema.V2 {
+		t.Fatalf("expected a tenant with previous components to use v2, got %s", got)
+	}
+}
@@ -0,0 +1,168 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// ConditionDegraded is the tenant condition set while its workflow keeps timing out
+const ConditionDegraded = "Degraded"
+
+// workflowTimeoutState tracks consecutive timed out executions for a tenant
+type workflowTimeoutState struct {
+	// attempts counts executions that timed out in a row
+	attempts int
+
+	// status is the tenant status the executions ran for; a status change ends the backoff
+	status tenant.Status
+
+	// retryAt is when the next execution may start
+	retryAt time.Time
+}
+
+// workflowOperation names the operation a tenant's in-flight workflow performs, as keyed in workflow_timeouts
+func workflowOperation(status tenant.Status) string {
+	switch status {
+	case tenant.StatusProvisioning:
+		return "provision"
+	case tenant.StatusUpdating:
+		return "update"
+	case tenant.StatusMigrating:
+		return "migrate"
+	case tenant.StatusDeleting:
+		return "delete"
+	case tenant.StatusArchiving:
+		return "archive"
+	default:
+		return ""
+	}
+}
+
+// workflowDeadlineExceeded returns the timeout t's current execution has run past, or zero
+func (r *Reconciler) workflowDeadlineExceeded(t *tenant.Tenant, now time.Time) time.Duration {
+	timeout := r.config.WorkflowTimeouts[workflowOperation(t.Status)]
+	if timeout <= 0 || t.WorkflowStartedAt == nil {
+		return 0
+	}
+	if now.Sub(*t.WorkflowStartedAt) <= timeout {
+		return 0
+	}
+	return timeout
+}
+
+// handleWorkflowTimeout stops t's execution after it ran past timeout. The tenant keeps its
+// status and is marked Degraded, and a new execution starts after a backoff that doubles with
+// each consecutive timeout. Once more than MaxRetries executions have timed out the tenant fails.
+func (r *Reconciler) handleWorkflowTimeout(ctx context.Context, t *tenant.Tenant, timeout time.Duration) error {
+	executionID := *t.WorkflowExecutionID
+	operation := workflowOperation(t.Status)
+	reason := fmt.Sprintf("%s workflow exceeded its %s timeout", operation, timeout)
+
+	if err := r.workflowClient.StopExecution(ctx, t, executionID, reason); err != nil {
+		return fmt.Errorf("stop timed out workflow execution %s: %w", executionID, err)
+	}
+
+	now := time.Now()
+	attempts, retryAt := r.recordWorkflowTimeout(t.ID.String(), t.Status, now)
+
+	t.WorkflowExecutionID = nil
+	t.WorkflowStartedAt = nil
+	t.WorkflowErrorMessage = &reason
+	r.workflowSlots.release(t.ID.String())
+	r.admission.record(true)
+	t.SetCondition(tenant.Condition{
+		Type:    ConditionDegraded,
+		Status:  tenant.ConditionTrue,
+		Reason:  tenant.ReasonWorkflowTimeout,
+		Message: fmt.Sprintf("%s; %d consecutive execution(s) stopped", reason, attempts),
+	}, now)
+
+	if attempts > r.config.MaxRetries {
+		r.clearWorkflowTimeouts(t.ID.String())
+		failed := string(workflow.SubStateFailed)
+		t.WorkflowSubState = &failed
+		t.Status = tenant.StatusFailed
+		t.StatusMessage = fmt.Sprintf("Workflow execution %s stopped: %s, %d times in a row", executionID, reason, attempts)
+	} else {
+		backingOff := string(workflow.SubStateBackingOff)
+		t.WorkflowSubState = &backingOff
+		t.StatusMessage = fmt.Sprintf("Workflow execution %s stopped: %s; retrying at %s", executionID, reason, retryAt.UTC().Format(time.RFC3339))
+	}
+
+	if err := r.tenantRepo.UpdateTenant(ctx, t); err != nil {
+		return fmt.Errorf("update tenant: %w", err)
+	}
+
+	r.logger.Warn("workflow execution timed out",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("tenant_name", t.Name),
+		zap.String("execution_id", executionID),
+		zap.String("operation", operation),
+		zap.Duration("timeout", timeout),
+		zap.Int("attempts", attempts),
+		zap.String("status", string(t.Status)))
+	return nil
+}
+
+// recordWorkflowTimeout counts a timed out execution and returns the count and when the next may start
+func (r *Reconciler) recordWorkflowTimeout(tenantID string, status tenant.Status, now time.Time) (int, time.Time) {
+	r.retryMu.Lock()
+	defer r.retryMu.Unlock()
+
+	state := r.workflowTimeouts[tenantID]
+	if state.status != status {
+		state = workflowTimeoutState{status: status}
+	}
+	state.attempts++
+
+	backoff := r.config.WorkflowTimeoutBackoff
+	for i := 1; i < state.attempts && backoff < config.MaxWorkflowTimeoutBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, config.MaxWorkflowTimeoutBackoff)
+	state.retryAt = now.Add(backoff)
+
+	r.workflowTimeouts[tenantID] = state
+	return state.attempts, state.retryAt
+}
+
+// workflowRetryAt returns when a tenant whose execution timed out may start another, or the
+// zero time when it may start now
+func (r *Reconciler) workflowRetryAt(tenantID string, status tenant.Status, now time.Time) time.Time {
+	r.retryMu.RLock()
+	defer r.retryMu.RUnlock()
+
+	state, ok := r.workflowTimeouts[tenantID]
+	if !ok || state.status != status || !now.Before(state.retryAt) {
+		return time.Time{}
+	}
+	return state.retryAt
+}
+
+// clearWorkflowTimeouts forgets a tenant's timed out executions
+func (r *Reconciler) clearWorkflowTimeouts(tenantID string) {
+	r.retryMu.Lock()
+	defer r.retryMu.Unlock()
+	delete(r.workflowTimeouts, tenantID)
+}
+
+// clearDegraded resolves the Degraded condition once t's workflow succeeds
+func (r *Reconciler) clearDegraded(t *tenant.Tenant, now time.Time) {
+	r.clearWorkflowTimeouts(t.ID.String())
+	if degraded := t.Condition(ConditionDegraded); degraded == nil || degraded.Status != tenant.ConditionTrue {
+		return
+	}
+	t.SetCondition(tenant.Condition{
+		Type:    ConditionDegraded,
+		Status:  tenant.ConditionFalse,
+		Reason:  tenant.ReasonWorkflowSucceeded,
+		Message: "Workflow execution completed",
+	}, now)
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// stoppingWorkflowClient records stopped executions and triggers
+type stoppingWorkflowClient struct {
+	stubWorkflowClient
+	stopped   []string
+	triggered int
+}
+
+func (s *stoppingWorkflowClient) TriggerWorkflow(ctx context.Context, t *tenant.Tenant, action string) (string, error) {
+	s.triggered++
+	return "exec-retry", nil
+}
+
+func (s *stoppingWorkflowClient) StopExecution(ctx context.Context, t *tenant.Tenant, executionID string, reason string) error {
+	s.stopped = append(s.stopped, executionID)
+	return nil
+}
+
+func newTimeoutReconciler(t *testing.T, maxRetries int) (*Reconciler, *memoryTenantRepo, *stoppingWorkflowClient) {
+	t.Helper()
+	repo := newMemoryTenantRepo()
+	client := &stoppingWorkflowClient{stubWorkflowClient: stubWorkflowClient{
+		execStatus: &workflow.ExecutionStatus{ExecutionID: "exec-hung", State: workflow.StateRunning},
+	}}
+	cfg := config.ControllerConfig{
+		Enabled:                true,
+		ReconciliationInterval: 100 * time.Millisecond,
+		StatusPollInterval:     100 * time.Millisecond,
+		Workers:                1,
+		WorkflowTriggerTimeout: 5 * time.Second,
+		ShutdownTimeout:        5 * time.Second,
+		MaxRetries:             maxRetries,
+		WorkflowTimeouts:       map[string]time.Duration{"provision": 15 * time.Minute},
+		WorkflowTimeoutBackoff: time.Minute,
+	}
+	return NewReconciler(repo, &WorkflowClient{}, cfg, zaptest.NewLogger(t)), repo, client
+}
+
+// createHungTenant stores a provisioning tenant whose execution started an hour ago
+func createHungTenant(t *testing.T, repo *memoryTenantRepo) uuid.UUID {
+	t.Helper()
+	executionID := "exec-hung"
+	startedAt := time.Now().Add(-time.Hour)
+	id := uuid.New()
+	require.NoError(t, repo.CreateTenant(context.Background(), &tenant.Tenant{
+		ID:                  id,
+		Name:                "hung-tenant",
+		Status:              tenant.StatusProvisioning,
+		WorkflowExecutionID: &executionID,
+		WorkflowStartedAt:   &startedAt,
+		DesiredConfig:       map[string]interface{}{"image": "nginx:latest"},
+	}))
+	return id
+}
+
+func TestReconciler_StopsTimedOutWorkflowAndBacksOff(t *testing.T) {
+	reconciler, repo, client := newTimeoutReconciler(t, 3)
+	reconciler.workflowClient = client
+	id := createHungTenant(t, repo)
+
+	require.NoError(t, reconciler.reconcile(id.String()))
+	require.Equal(t, []string{"exec-hung"}, client.stopped)
+
+	updated, err := repo.GetTenantByID(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusProvisioning, updated.Status)
+	require.Nil(t, updated.WorkflowExecutionID)
+	require.Nil(t, updated.WorkflowStartedAt)
+	require.NotNil(t, updated.WorkflowSubState)
+	require.Equal(t, string(workflow.SubStateBackingOff), *updated.WorkflowSubState)
+	degraded := updated.Condition(ConditionDegraded)
+	require.NotNil(t, degraded)
+	require.Equal(t, tenant.ConditionTrue, degraded.Status)
+	require.Equal(t, tenant.ReasonWorkflowTimeout, degraded.Reason)
+
+	// No new execution starts until the backoff has passed
+	require.NoError(t, reconciler.reconcile(id.String()))
+	require.Zero(t, client.triggered)
+
+	reconciler.workflowTimeouts[id.String()] = workflowTimeoutState{
+		attempts: 1,
+		status:   tenant.StatusProvisioning,
+		retryAt:  time.Now().Add(-time.Second),
+	}
+	require.NoError(t, reconciler.reconcile(id.String()))
+	require.Equal(t, 1, client.triggered)
+
+	updated, err = repo.GetTenantByID(context.Background(), id)
+	require.NoError(t, err)
+	require.NotNil(t, updated.WorkflowExecutionID)
+	require.Equal(t, "exec-retry", *updated.WorkflowExecutionID)
+	require.NotNil(t, updated.WorkflowStartedAt)
+
+	// A successful execution clears the Degraded condition
+	client.execStatus = &workflow.ExecutionStatus{ExecutionID: "exec-retry", State: workflow.StateSucceeded}
+	require.NoError(t, reconciler.reconcile(id.String()))
+
+	updated, err = repo.GetTenantByID(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusReady, updated.Status)
+	degraded = updated.Condition(ConditionDegraded)
+	require.NotNil(t, degraded)
+	require.Equal(t, tenant.ConditionFalse, degraded.Status)
+	require.NotContains(t, reconciler.workflowTimeouts, id.String())
+}
+
+func TestReconciler_FailsTenantAfterRepeatedWorkflowTimeouts(t *testing.T) {
+	reconciler, repo, client := newTimeoutReconciler(t, 0)
+	reconciler.workflowClient = client
+	id := createHungTenant(t, repo)
+
+	require.NoError(t, reconciler.reconcile(id.String()))
+
+	updated, err := repo.GetTenantByID(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, tenant.StatusFailed, updated.Status)
+	require.NotNil(t, updated.WorkflowSubState)
+	require.Equal(t, string(workflow.SubStateFailed), *updated.WorkflowSubState)
+	require.NotNil(t, updated.WorkflowErrorMessage)
+	require.Contains(t, *updated.WorkflowErrorMessage, "provision workflow exceeded its 15m0s timeout")
+}
+
+func TestReconciler_BackfillsWorkflowStartTime(t *testing.T) {
+	reconciler, repo, client := newTimeoutReconciler(t, 3)
+	reconciler.workflowClient = client
+	executionID := "exec-hung"
+	id := uuid.New()
+	require.NoError(t, repo.CreateTenant(context.Background(), &tenant.Tenant{
+		ID:                  id,
+		Name:                "legacy-tenant",
+		Status:              tenant.StatusProvisioning,
+		WorkflowExecutionID: &executionID,
+	}))
+
+	require.NoError(t, reconciler.reconcile(id.String()))
+	require.Empty(t, client.stopped)
+
+	updated, err := repo.GetTenantByID(context.Background(), id)
+	require.NoError(t, err)
+	require.NotNil(t, updated.WorkflowStartedAt)
+}
+
+func TestRecordWorkflowTimeoutDoublesBackoff(t *testing.T) {
+	reconciler, _, _ := newTimeoutReconciler(t, 10)
+	now := time.Now()
+
+	var backoffs []time.Duration
+	for range 6 {
+		_, retryAt := reconciler.recordWorkflowTimeout("tenant", tenant.StatusProvisioning, now)
+		backoffs = append(backoffs, retryAt.Sub(now))
+	}
+	require.Equal(t, []time.Duration{
+		time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute,
+		config.MaxWorkflowTimeoutBackoff, config.MaxWorkflowTimeoutBackoff,
+	}, backoffs)
+
+	// A different status starts counting again
+	attempts, _ := reconciler.recordWorkflowTimeout("tenant", tenant.StatusArchiving, now)
+	require.Equal(t, 1, attempts)
+}
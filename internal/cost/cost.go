@@ -0,0 +1,101 @@
+// Package cost estimates what tenants cost to run from per-provider hourly rates. Each component is
+// charged a flat hourly price plus the CPU and memory of its limits, so components without limits
+// only pay the flat price.
+package cost
+
+import (
+	"math"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// HoursPerMonth is the average number of hours in a month, used for monthly estimates
+const HoursPerMonth = 730
+
+// Estimate is the running cost of one or more tenants
+type Estimate struct {
+	Currency string  `json:"currency"`
+	Hourly   float64 `json:"hourly"`
+	Monthly  float64 `json:"monthly"`
+
+	// Unsized lists components that declare no CPU or memory limits, so only their flat price is included
+	Unsized []string `json:"unsized_components,omitempty"`
+}
+
+// Add adds other's prices to e. Unsized components are left to the caller, which knows whose they are.
+func (e *Estimate) Add(other *Estimate) {
+	e.Hourly = round(e.Hourly + other.Hourly)
+	e.Monthly = round(e.Monthly + other.Monthly)
+}
+
+// Estimator prices tenants. A nil Estimator prices nothing.
+type Estimator struct {
+	currency string
+	rates    map[string]config.CostRateConfig
+}
+
+// New creates an estimator, returning nil when no rates are configured
+func New(cfg config.CostConfig) *Estimator {
+	if len(cfg.Rates) == 0 {
+		return nil
+	}
+	currency := cfg.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+	return &Estimator{currency: currency, rates: cfg.Rates}
+}
+
+// Currency returns the currency estimates are in
+func (e *Estimator) Currency() string {
+	if e == nil {
+		return ""
+	}
+	return e.currency
+}
+
+// Estimate prices a tenant's components on provider. It returns false when the provider has no rates.
+func (e *Estimator) Estimate(provider string, components []tenant.Component) (*Estimate, bool) {
+	if e == nil {
+		return nil, false
+	}
+	rate, ok := e.rates[provider]
+	if !ok {
+		return nil, false
+	}
+	estimate := &Estimate{Currency: e.currency}
+	for _, component := range components {
+		hourly := rate.ComponentHour
+		cpu, memory := limits(component.Config)
+		if cpu == 0 && memory == 0 {
+			estimate.Unsized = append(estimate.Unsized, component.Name)
+		}
+		hourly += cpu / 1000 * rate.CPUHour
+		hourly += memory / 1024 * rate.MemoryGBHour
+		estimate.Hourly += hourly
+	}
+	estimate.Monthly = round(estimate.Hourly * HoursPerMonth)
+	estimate.Hourly = round(estimate.Hourly)
+	return estimate, true
+}
+
+// limits returns a component's CPU limit in millicores and memory limit in mebibytes, as declared
+// under limits in its compute config
+func limits(config map[string]interface{}) (cpu, memory float64) {
+	declared, _ := config["limits"].(map[string]interface{})
+	cpu, _ = declared["cpu"].(float64)
+	memory, _ = declared["memory"].(float64)
+	if value, ok := declared["cpu"].(int); ok {
+		cpu = float64(value)
+	}
+	if value, ok := declared["memory"].(int); ok {
+		memory = float64(value)
+	}
+	return cpu, memory
+}
+
+// round rounds to a hundredth of a cent, so sums do not accumulate float noise
+func round(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
@@ -0,0 +1,53 @@
+package cost
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+func TestEstimate(t *testing.T) {
+	if New(config.CostConfig{}) != nil {
+		t.Fatal("expected no estimator without rates")
+	}
+	var disabled *Estimator
+	if _, ok := disabled.Estimate("docker", nil); ok {
+		t.Fatal("expected a nil estimator to price nothing")
+	}
+
+	estimator := New(config.CostConfig{Rates: map[string]config.CostRateConfig{
+		"docker": {ComponentHour: 0.01, CPUHour: 0.04, MemoryGBHour: 0.005},
+	}})
+	if estimator.Currency() != "USD" {
+		t.Fatalf("expected the default currency, got %s", estimator.Currency())
+	}
+
+	components, err := tenant.ParseComponents(map[string]interface{}{
+		"image":  "nginx:1.27",
+		"limits": map[string]interface{}{"cpu": float64(500), "memory": float64(2048)},
+		"components": map[string]interface{}{
+			"app":  map[string]interface{}{},
+			"cron": map[string]interface{}{"limits": nil},
+		},
+	})
+	if err != nil {
+		t.Fatalf("parse components: %v", err)
+	}
+	estimate, ok := estimator.Estimate("docker", components)
+	if !ok {
+		t.Fatal("expected docker to be priced")
+	}
+	// app: 0.01 + 0.5 CPU * 0.04 + 2 GiB * 0.005 = 0.04; cron: 0.01
+	if estimate.Hourly != 0.05 || estimate.Monthly != 36.5 {
+		t.Fatalf("unexpected estimate %+v", estimate)
+	}
+	if !reflect.DeepEqual(estimate.Unsized, []string{"cron"}) {
+		t.Fatalf("expected cron to be unsized, got %v", estimate.Unsized)
+	}
+
+	if _, ok := estimator.Estimate("ecs", components); ok {
+		t.Fatal("expected a provider without rates not to be priced")
+	}
+}
@@ -1,14 +1,21 @@
 package database
 
 import (
+	"context"
 	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
@@ -66,3 +73,57 @@ func RunMigrations(connString string, logger *zap.Logger) error {
 	logger.Info("migrations applied successfully", zap.Uint("new_version", newVersion))
 	return nil
 }
+
+// ErrMigrationStatusUnavailable is returned by MigrationVersion for databases whose schema is not
+// managed by RunMigrations
+var ErrMigrationStatusUnavailable = errors.New("migration status is not available for this database")
+
+// LatestMigrationVersion returns the version of the newest migration built into this binary
+func LatestMigrationVersion() (uint, error) {
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return 0, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	var latest uint
+	for _, entry := range entries {
+		prefix, _, ok := strings.Cut(entry.Name(), "_")
+		if !ok {
+			continue
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		latest = max(latest, uint(version))
+	}
+	return latest, nil
+}
+
+// MigrationVersion returns the migration version the database is at, and whether the last
+// migration failed part way and left it dirty. A database no migration has run against is at
+// version 0.
+func MigrationVersion(ctx context.Context, provider Provider) (uint, bool, error) {
+	pool, ok := provider.Pool().(*pgxpool.Pool)
+	if !ok {
+		return 0, false, ErrMigrationStatusUnavailable
+	}
+
+	var exists bool
+	if err := pool.QueryRow(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists); err != nil {
+		return 0, false, fmt.Errorf("failed to look up migration table: %w", err)
+	}
+	if !exists {
+		return 0, false, nil
+	}
+
+	var version int64
+	var dirty bool
+	err := pool.QueryRow(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
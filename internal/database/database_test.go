@@ -89,3 +89,13 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func TestLatestMigrationVersion(t *testing.T) {
+	latest, err := LatestMigrationVersion()
+	if err != nil {
+		t.Fatalf("LatestMigrationVersion() error = %v", err)
+	}
+	if latest < 24 {
+		t.Errorf("expected the latest migration to be at least 24, got %d", latest)
+	}
+}
@@ -0,0 +1,2 @@
+-- Remove managed_fields from tenants table
+ALTER TABLE tenants DROP COLUMN managed_fields;
@@ -0,0 +1,3 @@
+-- Add managed_fields to track which actor last set each top-level desired_config field
+ALTER TABLE tenants
+ADD COLUMN managed_fields JSON NOT NULL DEFAULT '{}';
@@ -0,0 +1,2 @@
+-- Remove workflow_version field from tenants table
+ALTER TABLE tenants DROP COLUMN workflow_version;
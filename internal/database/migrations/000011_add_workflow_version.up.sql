@@ -0,0 +1,3 @@
+-- Add workflow_version to tenants so in-flight executions are tracked against the definition they started on
+ALTER TABLE tenants
+ADD COLUMN workflow_version VARCHAR(32);
@@ -0,0 +1,3 @@
+-- Remove runtime provider settings and their audit trail
+DROP TABLE IF EXISTS provider_settings_audit;
+DROP TABLE IF EXISTS provider_settings;
@@ -0,0 +1,30 @@
+-- Runtime provider changes made through the admin API, applied over the startup configuration
+CREATE TABLE provider_settings (
+    kind VARCHAR(20) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    -- NULL keeps the provider's startup configuration
+    config JSONB,
+    updated_by VARCHAR(255),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (kind, name),
+    CHECK (kind IN ('compute', 'workflow'))
+);
+
+-- Audit trail of provider changes
+CREATE TABLE provider_settings_audit (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind VARCHAR(20) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actor VARCHAR(255),
+    request_id VARCHAR(255),
+    enabled BOOLEAN NOT NULL,
+    config JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CHECK (action IN ('enable', 'disable', 'reconfigure'))
+);
+
+CREATE INDEX idx_provider_settings_audit_provider ON provider_settings_audit(kind, name, created_at DESC);
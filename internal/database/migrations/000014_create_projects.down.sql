@@ -0,0 +1,5 @@
+-- Remove organizations and projects; tenants lose their project
+DROP INDEX IF EXISTS idx_tenants_project_id;
+ALTER TABLE tenants DROP COLUMN IF EXISTS project_id;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,33 @@
+-- Organizations and projects group tenants so landlord can be shared between teams
+CREATE TABLE organizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(63) NOT NULL UNIQUE,
+    display_name VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE projects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    organization_id UUID NOT NULL REFERENCES organizations(id),
+    name VARCHAR(63) NOT NULL,
+    display_name VARCHAR(255),
+    -- Quota, notification and template settings
+    settings JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (organization_id, name)
+);
+
+-- Existing tenants move to the default project of the default organization
+INSERT INTO organizations (id, name, display_name)
+VALUES ('00000000-0000-0000-0000-000000000001', 'default', 'Default organization');
+
+INSERT INTO projects (id, organization_id, name, display_name)
+VALUES ('00000000-0000-0000-0000-000000000002', '00000000-0000-0000-0000-000000000001', 'default', 'Default project');
+
+ALTER TABLE tenants
+ADD COLUMN project_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000002' REFERENCES projects(id);
+
+CREATE INDEX idx_tenants_project_id ON tenants(project_id);
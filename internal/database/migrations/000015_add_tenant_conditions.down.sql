@@ -0,0 +1,2 @@
+-- Remove tenant conditions
+ALTER TABLE tenants DROP COLUMN IF EXISTS conditions;
@@ -0,0 +1,2 @@
+-- Conditions record observations about a tenant alongside its status, such as image policy compliance
+ALTER TABLE tenants ADD COLUMN conditions JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,2 @@
+-- Remove the approvals table
+DROP TABLE IF EXISTS approvals;
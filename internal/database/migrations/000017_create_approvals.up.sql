@@ -0,0 +1,16 @@
+-- Destructive operations on protected tenants wait here for a second principal's approval
+CREATE TABLE approvals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    tenant_name VARCHAR(255) NOT NULL,
+    operation VARCHAR(20) NOT NULL CHECK (operation IN ('archive', 'delete')),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    requested_by VARCHAR(255) NOT NULL,
+    decided_by VARCHAR(255),
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    decided_at TIMESTAMP
+);
+
+CREATE INDEX idx_approvals_tenant_id ON approvals(tenant_id);
+CREATE INDEX idx_approvals_status ON approvals(status, created_at DESC);
@@ -0,0 +1,3 @@
+-- Remove the schedule tables
+DROP TABLE IF EXISTS schedule_runs;
+DROP TABLE IF EXISTS schedules;
@@ -0,0 +1,37 @@
+-- Cron schedules that run tenant actions, and the history of each run
+CREATE TABLE schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    cron VARCHAR(255) NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    action VARCHAR(20) NOT NULL CHECK (action IN ('restart', 'suspend', 'resume', 'run_hook')),
+    hook VARCHAR(255) NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP,
+    last_run_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, name)
+);
+
+CREATE INDEX idx_schedules_next_run_at ON schedules(next_run_at) WHERE enabled;
+
+CREATE TABLE schedule_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- seq orders runs that started at the same instant
+    seq BIGSERIAL NOT NULL,
+    schedule_id UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'succeeded', 'failed', 'skipped')),
+    scheduled_at TIMESTAMP NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    message TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    output TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_schedule_runs_schedule_id ON schedule_runs(schedule_id, started_at DESC);
+CREATE INDEX idx_schedule_runs_running ON schedule_runs(schedule_id) WHERE status = 'running';
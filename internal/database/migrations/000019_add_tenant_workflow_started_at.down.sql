@@ -0,0 +1,2 @@
+-- Remove tenant workflow start times
+ALTER TABLE tenants DROP COLUMN IF EXISTS workflow_started_at;
@@ -0,0 +1,2 @@
+-- When the current workflow execution started, so executions that outlive their timeout can be stopped
+ALTER TABLE tenants ADD COLUMN workflow_started_at TIMESTAMP;
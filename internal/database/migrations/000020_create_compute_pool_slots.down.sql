@@ -0,0 +1,2 @@
+-- Remove the compute pool slots table
+DROP TABLE IF EXISTS compute_pool_slots;
@@ -0,0 +1,7 @@
+-- Slots of the static pool compute provider and the tenant holding each one
+CREATE TABLE compute_pool_slots (
+    name VARCHAR(255) PRIMARY KEY,
+    tenant_id VARCHAR(255) UNIQUE,
+    assigned_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,15 @@
+-- Remove the tenant filter indexes and restore JSON labels and annotations
+DROP INDEX IF EXISTS idx_tenants_workflow_error;
+DROP INDEX IF EXISTS idx_tenants_workflow_sub_state;
+DROP INDEX IF EXISTS idx_tenants_unarchived_created_at;
+DROP INDEX IF EXISTS idx_tenants_reconcile;
+DROP INDEX IF EXISTS idx_tenants_annotations;
+DROP INDEX IF EXISTS idx_tenants_labels;
+
+ALTER TABLE tenants
+    ALTER COLUMN labels DROP DEFAULT,
+    ALTER COLUMN labels TYPE JSON USING labels::json,
+    ALTER COLUMN labels SET DEFAULT '{}',
+    ALTER COLUMN annotations DROP DEFAULT,
+    ALTER COLUMN annotations TYPE JSON USING annotations::json,
+    ALTER COLUMN annotations SET DEFAULT '{}';
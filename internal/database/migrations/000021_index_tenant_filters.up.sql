@@ -0,0 +1,25 @@
+-- Index the columns tenant list filters and the reconciler query, so they stay fast with many tenants.
+-- GIN indexes need JSONB, so labels and annotations are converted from JSON first; the conversion
+-- rewrites the tenants table.
+ALTER TABLE tenants
+    ALTER COLUMN labels DROP DEFAULT,
+    ALTER COLUMN labels TYPE JSONB USING labels::jsonb,
+    ALTER COLUMN labels SET DEFAULT '{}',
+    ALTER COLUMN annotations DROP DEFAULT,
+    ALTER COLUMN annotations TYPE JSONB USING annotations::jsonb,
+    ALTER COLUMN annotations SET DEFAULT '{}';
+
+-- Label and annotation selectors (labels @> '{"team":"platform"}')
+CREATE INDEX idx_tenants_labels ON tenants USING GIN (labels jsonb_path_ops);
+CREATE INDEX idx_tenants_annotations ON tenants USING GIN (annotations jsonb_path_ops);
+
+-- ListTenantsForReconciliation; the predicate must match the query's status list
+CREATE INDEX idx_tenants_reconcile ON tenants(created_at)
+    WHERE status IN ('requested', 'planning', 'provisioning', 'updating', 'deleting', 'archiving');
+
+-- Default tenant list, which hides archived tenants, newest first
+CREATE INDEX idx_tenants_unarchived_created_at ON tenants(created_at DESC) WHERE status != 'archived';
+
+-- Workflow filters
+CREATE INDEX idx_tenants_workflow_sub_state ON tenants(workflow_sub_state) WHERE workflow_sub_state IS NOT NULL;
+CREATE INDEX idx_tenants_workflow_error ON tenants(created_at DESC) WHERE workflow_error_message IS NOT NULL;
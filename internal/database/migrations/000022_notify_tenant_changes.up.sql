@@ -0,0 +1,16 @@
+-- Notify listeners on the tenant_changes channel when a tenant is updated or deleted, so processes
+-- that cache tenants drop their copy. The payload is the tenant ID.
+CREATE OR REPLACE FUNCTION notify_tenant_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM pg_notify('tenant_changes', OLD.id::text);
+    ELSE
+        PERFORM pg_notify('tenant_changes', NEW.id::text);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tenants_notify_change
+    AFTER UPDATE OR DELETE ON tenants
+    FOR EACH ROW EXECUTE FUNCTION notify_tenant_change();
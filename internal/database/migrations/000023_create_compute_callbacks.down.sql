@@ -0,0 +1,2 @@
+-- Remove the compute callback outbox
+DROP TABLE IF EXISTS compute_callbacks;
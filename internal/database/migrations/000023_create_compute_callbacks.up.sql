@@ -0,0 +1,18 @@
+-- Outbox of compute callbacks waiting for delivery to a workflow provider. Callbacks are queued
+-- once per execution and claimed by one worker at a time until delivered.
+CREATE TABLE compute_callbacks (
+    execution_id VARCHAR(255) PRIMARY KEY,
+    provider VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    claim_token UUID,
+    claimed_until TIMESTAMPTZ,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMPTZ
+);
+
+-- Undelivered callbacks, in the order workers claim them
+CREATE INDEX idx_compute_callbacks_pending ON compute_callbacks(provider, created_at)
+    WHERE delivered_at IS NULL;
@@ -0,0 +1,2 @@
+-- Remove the tenant outbox
+DROP TABLE IF EXISTS tenant_outbox;
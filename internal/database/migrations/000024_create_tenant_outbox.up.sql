@@ -0,0 +1,20 @@
+-- Outbox of side effects of tenant writes, such as workflow triggers. Entries are written in the
+-- same transaction as the tenant update and claimed by one dispatcher at a time until published.
+CREATE TABLE tenant_outbox (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL,
+    kind VARCHAR(255) NOT NULL,
+    dedup_key VARCHAR(512) NOT NULL UNIQUE,
+    payload JSONB NOT NULL DEFAULT '{}',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    claim_token UUID,
+    claimed_until TIMESTAMPTZ,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMPTZ
+);
+
+-- Unpublished entries, in the order dispatchers claim them
+CREATE INDEX idx_tenant_outbox_pending ON tenant_outbox(created_at)
+    WHERE published_at IS NULL;
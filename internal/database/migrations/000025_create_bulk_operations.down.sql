@@ -0,0 +1,2 @@
+-- Remove the bulk_operations table
+DROP TABLE IF EXISTS bulk_operations;
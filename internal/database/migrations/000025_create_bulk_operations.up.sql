@@ -0,0 +1,17 @@
+-- Bulk tenant actions run in the background; each operation tracks the outcome per tenant
+CREATE TABLE bulk_operations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    action VARCHAR(20) NOT NULL CHECK (action IN ('archive', 'suspend', 'resume', 'restart', 'retry')),
+    selector JSONB NOT NULL DEFAULT '{}',
+    component VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'failed')),
+    requested_by VARCHAR(255) NOT NULL,
+    tenants JSONB NOT NULL DEFAULT '[]',
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    pending_approval INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX idx_bulk_operations_created_at ON bulk_operations(created_at DESC);
@@ -0,0 +1,2 @@
+-- Remove the execution_steps table
+DROP TABLE IF EXISTS execution_steps;
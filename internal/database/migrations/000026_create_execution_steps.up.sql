@@ -0,0 +1,15 @@
+-- Steps of workflow executions, reported by workers as they start and finish each one
+CREATE TABLE execution_steps (
+    id BIGSERIAL PRIMARY KEY,
+    execution_id VARCHAR(255) NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 1,
+    error TEXT,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ,
+    UNIQUE (execution_id, name)
+);
+
+CREATE INDEX idx_execution_steps_tenant_id ON execution_steps(tenant_id);
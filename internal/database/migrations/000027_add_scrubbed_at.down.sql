@@ -0,0 +1,5 @@
+-- Remove the scrubbed_at columns
+DROP INDEX IF EXISTS idx_provider_settings_audit_unscrubbed;
+DROP INDEX IF EXISTS idx_tenant_state_history_unscrubbed;
+ALTER TABLE provider_settings_audit DROP COLUMN IF EXISTS scrubbed_at;
+ALTER TABLE tenant_state_history DROP COLUMN IF EXISTS scrubbed_at;
@@ -0,0 +1,7 @@
+-- When retention or a request to forget a tenant removed personal data from a record's payload
+ALTER TABLE tenant_state_history ADD COLUMN scrubbed_at TIMESTAMP;
+ALTER TABLE provider_settings_audit ADD COLUMN scrubbed_at TIMESTAMP;
+
+-- Retention scans for old records it has not scrubbed yet
+CREATE INDEX idx_tenant_state_history_unscrubbed ON tenant_state_history(created_at) WHERE scrubbed_at IS NULL;
+CREATE INDEX idx_provider_settings_audit_unscrubbed ON provider_settings_audit(created_at) WHERE scrubbed_at IS NULL;
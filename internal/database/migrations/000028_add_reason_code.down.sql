@@ -0,0 +1,3 @@
+-- Remove the reason_code column
+DROP INDEX IF EXISTS idx_tenant_state_history_reason_code;
+ALTER TABLE tenant_state_history DROP COLUMN IF EXISTS reason_code;
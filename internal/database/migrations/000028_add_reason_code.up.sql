@@ -0,0 +1,4 @@
+-- Machine-readable reason codes alongside the free-text reason, so alerting can filter on them
+ALTER TABLE tenant_state_history ADD COLUMN reason_code TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_tenant_state_history_reason_code ON tenant_state_history(reason_code) WHERE reason_code <> '';
@@ -0,0 +1,3 @@
+-- Restore the tenant_id index
+CREATE INDEX IF NOT EXISTS idx_tenant_state_history_tenant_id ON tenant_state_history(tenant_id);
+DROP INDEX IF EXISTS idx_tenant_state_history_tenant_created;
@@ -0,0 +1,5 @@
+-- Read a tenant's history a page at a time, newest first, without sorting every transition it has
+CREATE INDEX idx_tenant_state_history_tenant_created ON tenant_state_history(tenant_id, created_at DESC, id DESC);
+
+-- The new index covers lookups by tenant
+DROP INDEX IF EXISTS idx_tenant_state_history_tenant_id;
@@ -0,0 +1,2 @@
+-- Remove tenant finalizers
+ALTER TABLE tenants DROP COLUMN IF EXISTS finalizers;
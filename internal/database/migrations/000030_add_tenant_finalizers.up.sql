@@ -0,0 +1,2 @@
+-- Finalizers name the components that must clean up after a tenant before it is deleted or archived
+ALTER TABLE tenants ADD COLUMN finalizers JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_compute_callbacks_delivered;
+DROP INDEX IF EXISTS idx_tenant_outbox_published;
@@ -0,0 +1,5 @@
+-- Published outbox entries and delivered callbacks, in the order housekeeping deletes them
+CREATE INDEX idx_tenant_outbox_published ON tenant_outbox(published_at)
+    WHERE published_at IS NOT NULL;
+CREATE INDEX idx_compute_callbacks_delivered ON compute_callbacks(delivered_at)
+    WHERE delivered_at IS NOT NULL;
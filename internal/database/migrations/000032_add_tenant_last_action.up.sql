@@ -0,0 +1,2 @@
+-- The last workflow the controller started for each tenant: its action, trigger source, execution and start time
+ALTER TABLE tenants ADD COLUMN last_action JSONB;
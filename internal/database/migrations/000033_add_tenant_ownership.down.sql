@@ -0,0 +1,3 @@
+-- Remove tenant owners and collaborators
+ALTER TABLE tenants DROP COLUMN IF EXISTS collaborators;
+ALTER TABLE tenants DROP COLUMN IF EXISTS owner;
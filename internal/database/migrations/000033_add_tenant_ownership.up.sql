@@ -0,0 +1,3 @@
+-- The API key that created a tenant and the API keys it is shared with; only they and admins may change it
+ALTER TABLE tenants ADD COLUMN owner TEXT NOT NULL DEFAULT '';
+ALTER TABLE tenants ADD COLUMN collaborators JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,347 @@
+// Package objectstore provisions a per-tenant object storage bucket (or prefix in a shared bucket) with scoped credentials.
+package objectstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/resource"
+	"go.uber.org/zap"
+)
+
+// ResourceType is the resource type tenants declare to request object storage
+const ResourceType = "bucket"
+
+// Isolation modes
+const (
+	ModeBucket = "bucket"
+	ModePrefix = "prefix"
+)
+
+// Credential modes
+const (
+	CredentialsAccessKey = "access_key"
+	CredentialsNone      = "none"
+)
+
+// Archive policies
+const (
+	ArchiveRetain = "retain"
+	ArchiveDrop   = "drop"
+)
+
+const (
+	maxBucketNameLength    = 63
+	maxPrincipalNameLength = 64
+	credentialsObjectKey   = ".landlord/credentials.json"
+)
+
+// ErrObjectNotFound is returned by Backend.GetObject when the key does not exist
+var ErrObjectNotFound = errors.New("object not found")
+
+var (
+	invalidBucketChars    = regexp.MustCompile(`[^a-z0-9-]`)
+	invalidPrincipalChars = regexp.MustCompile(`[^A-Za-z0-9+=,.@_-]`)
+)
+
+// Scope is the storage a tenant principal is allowed to access
+type Scope struct {
+	Bucket string
+	// Prefix is empty when the principal owns the whole bucket
+	Prefix string
+}
+
+// Credentials are scoped access keys for a tenant principal
+type Credentials struct {
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+}
+
+// Backend performs object storage and identity operations for a cloud provider
+type Backend interface {
+	// BucketExists reports whether the bucket exists
+	BucketExists(ctx context.Context, bucket string) (bool, error)
+	// EnsureBucket creates the bucket if it does not exist and applies tags
+	EnsureBucket(ctx context.Context, bucket string, tags map[string]string) error
+	// DeleteBucket deletes an empty bucket; a missing bucket is not an error
+	DeleteBucket(ctx context.Context, bucket string) error
+	// DeletePrefix deletes every object under prefix ("" deletes all objects)
+	DeletePrefix(ctx context.Context, bucket, prefix string) error
+
+	GetObject(ctx context.Context, bucket, key string) ([]byte, error)
+	PutObject(ctx context.Context, bucket, key string, body []byte) error
+
+	// EnsurePrincipal creates the tenant principal if needed and limits it to scope
+	EnsurePrincipal(ctx context.Context, name string, scope Scope) error
+	// HasCredentials reports whether accessKeyID is still a key of the principal
+	HasCredentials(ctx context.Context, name, accessKeyID string) (bool, error)
+	// RotateCredentials deletes the principal's existing keys and issues a new one
+	RotateCredentials(ctx context.Context, name string) (*Credentials, error)
+	// DeletePrincipal deletes the principal, its keys and policies; a missing principal is not an error
+	DeletePrincipal(ctx context.Context, name string) error
+}
+
+// Module implements resource.Provider for object storage
+type Module struct {
+	backend Backend
+	config  config.BucketDataPlaneConfig
+	logger  *zap.Logger
+}
+
+var _ resource.Provider = (*Module)(nil)
+
+// New creates an object storage data plane module backed by the configured provider
+func New(ctx context.Context, cfg config.BucketDataPlaneConfig, logger *zap.Logger) (*Module, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bucket dataplane config: %w", err)
+	}
+
+	backend, err := NewS3Backend(ctx, S3Options{
+		Region:      cfg.Region,
+		Endpoint:    cfg.Endpoint,
+		IAMEndpoint: cfg.IAMEndpoint,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewWithBackend(cfg, backend, logger), nil
+}
+
+// NewWithBackend creates a module with an explicit backend
+func NewWithBackend(cfg config.BucketDataPlaneConfig, backend Backend, logger *zap.Logger) *Module {
+	if cfg.Mode == "" {
+		cfg.Mode = ModeBucket
+	}
+	if cfg.NamePrefix == "" {
+		cfg.NamePrefix = "landlord-"
+	}
+	if cfg.CredentialsMode == "" {
+		cfg.CredentialsMode = CredentialsAccessKey
+	}
+	if cfg.ArchivePolicy == "" {
+		cfg.ArchivePolicy = ArchiveRetain
+	}
+
+	return &Module{
+		backend: backend,
+		config:  cfg,
+		logger:  logger.With(zap.String("component", "dataplane-bucket")),
+	}
+}
+
+// Type returns the resource type
+func (m *Module) Type() string {
+	return ResourceType
+}
+
+// Provision creates the tenant bucket or prefix and its scoped credentials
+func (m *Module) Provision(ctx context.Context, req *resource.Request) (*resource.Result, error) {
+	instanceID := req.InstanceID()
+	scope := m.scope(instanceID)
+
+	if m.config.Mode == ModeBucket {
+		tags := map[string]string{"landlord:tenant_id": req.TenantID, "landlord:resource": req.Name}
+		if err := m.backend.EnsureBucket(ctx, scope.Bucket, tags); err != nil {
+			return nil, fmt.Errorf("ensure bucket: %w", err)
+		}
+	}
+
+	result := &resource.Result{
+		Outputs: m.outputs(scope),
+		Env: map[string]string{
+			"BUCKET_NAME":   scope.Bucket,
+			"BUCKET_REGION": m.config.Region,
+		},
+	}
+	if scope.Prefix != "" {
+		result.Env["BUCKET_PREFIX"] = scope.Prefix
+	}
+	if m.config.Endpoint != "" {
+		result.Env["BUCKET_ENDPOINT"] = m.config.Endpoint
+	}
+
+	if m.config.CredentialsMode == CredentialsAccessKey {
+		principal := m.principal(instanceID)
+		creds, err := m.ensureCredentials(ctx, principal, scope)
+		if err != nil {
+			return nil, err
+		}
+		result.Outputs["principal"] = principal
+		// S3 credentials use the standard AWS SDK variable names so tenant SDKs pick them up
+		result.Secrets = []resource.Secret{
+			{Name: "bucket-access-key-id", EnvVar: "AWS_ACCESS_KEY_ID", Value: creds.AccessKeyID},
+			{Name: "bucket-secret-access-key", EnvVar: "AWS_SECRET_ACCESS_KEY", Value: creds.SecretAccessKey},
+		}
+	}
+
+	m.logger.Info("tenant bucket provisioned",
+		zap.String("tenant_id", req.TenantID),
+		zap.String("resource", req.Name),
+		zap.String("mode", m.config.Mode),
+		zap.String("bucket", scope.Bucket),
+		zap.String("prefix", scope.Prefix),
+	)
+	return result, nil
+}
+
+// Update re-applies provisioning; stored credentials are reused while they remain valid
+func (m *Module) Update(ctx context.Context, req *resource.Request) (*resource.Result, error) {
+	return m.Provision(ctx, req)
+}
+
+// Status reports whether the tenant bucket exists and its stored credentials are still valid
+func (m *Module) Status(ctx context.Context, req *resource.Request) (*resource.Status, error) {
+	instanceID := req.InstanceID()
+	scope := m.scope(instanceID)
+
+	exists, err := m.backend.BucketExists(ctx, scope.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("check bucket: %w", err)
+	}
+	if !exists {
+		return &resource.Status{State: resource.StateMissing}, nil
+	}
+
+	outputs := m.outputs(scope)
+	if m.config.CredentialsMode != CredentialsAccessKey {
+		return &resource.Status{State: resource.StateReady, Outputs: outputs}, nil
+	}
+
+	principal := m.principal(instanceID)
+	outputs["principal"] = principal
+	stored, err := m.backend.GetObject(ctx, scope.Bucket, scope.Prefix+credentialsObjectKey)
+	if errors.Is(err, ErrObjectNotFound) {
+		return &resource.Status{State: resource.StateDegraded, Message: "no stored credentials", Outputs: outputs}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load stored credentials: %w", err)
+	}
+	var creds Credentials
+	if err := json.Unmarshal(stored, &creds); err != nil || creds.AccessKeyID == "" {
+		return &resource.Status{State: resource.StateDegraded, Message: "stored credentials are unreadable", Outputs: outputs}, nil
+	}
+	valid, err := m.backend.HasCredentials(ctx, principal, creds.AccessKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("check credentials: %w", err)
+	}
+	if !valid {
+		return &resource.Status{State: resource.StateDegraded, Message: "stored credentials have been revoked", Outputs: outputs}, nil
+	}
+	return &resource.Status{State: resource.StateReady, Outputs: outputs}, nil
+}
+
+// Destroy revokes the tenant's credentials and, with the drop policy, deletes its objects and bucket
+func (m *Module) Destroy(ctx context.Context, req *resource.Request) error {
+	instanceID := req.InstanceID()
+	scope := m.scope(instanceID)
+
+	if m.config.CredentialsMode == CredentialsAccessKey {
+		if err := m.backend.DeletePrincipal(ctx, m.principal(instanceID)); err != nil {
+			return fmt.Errorf("delete principal: %w", err)
+		}
+	}
+
+	if m.config.ArchivePolicy != ArchiveDrop {
+		m.logger.Info("tenant bucket retained", zap.String("tenant_id", req.TenantID), zap.String("bucket", scope.Bucket))
+		return nil
+	}
+
+	if m.config.Mode == ModePrefix {
+		if err := m.backend.DeletePrefix(ctx, scope.Bucket, scope.Prefix); err != nil {
+			return fmt.Errorf("delete prefix: %w", err)
+		}
+	} else {
+		if err := m.backend.DeletePrefix(ctx, scope.Bucket, ""); err != nil {
+			return fmt.Errorf("empty bucket: %w", err)
+		}
+		if err := m.backend.DeleteBucket(ctx, scope.Bucket); err != nil {
+			return fmt.Errorf("delete bucket: %w", err)
+		}
+	}
+
+	m.logger.Info("tenant bucket dropped", zap.String("tenant_id", req.TenantID), zap.String("bucket", scope.Bucket))
+	return nil
+}
+
+// ensureCredentials reuses the stored access key while it is still valid, otherwise rotates it.
+// Keys are stored inside the tenant's own scope, so reprovisioning does not churn credentials.
+func (m *Module) ensureCredentials(ctx context.Context, principal string, scope Scope) (*Credentials, error) {
+	if err := m.backend.EnsurePrincipal(ctx, principal, scope); err != nil {
+		return nil, fmt.Errorf("ensure principal: %w", err)
+	}
+
+	key := scope.Prefix + credentialsObjectKey
+	stored, err := m.backend.GetObject(ctx, scope.Bucket, key)
+	if err != nil && !errors.Is(err, ErrObjectNotFound) {
+		return nil, fmt.Errorf("load stored credentials: %w", err)
+	}
+	if err == nil {
+		var creds Credentials
+		if json.Unmarshal(stored, &creds) == nil && creds.AccessKeyID != "" {
+			valid, err := m.backend.HasCredentials(ctx, principal, creds.AccessKeyID)
+			if err != nil {
+				return nil, fmt.Errorf("check credentials: %w", err)
+			}
+			if valid {
+				return &creds, nil
+			}
+		}
+	}
+
+	creds, err := m.backend.RotateCredentials(ctx, principal)
+	if err != nil {
+		return nil, fmt.Errorf("issue credentials: %w", err)
+	}
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return nil, fmt.Errorf("encode credentials: %w", err)
+	}
+	if err := m.backend.PutObject(ctx, scope.Bucket, key, data); err != nil {
+		return nil, fmt.Errorf("store credentials: %w", err)
+	}
+	return creds, nil
+}
+
+func (m *Module) outputs(scope Scope) map[string]string {
+	outputs := map[string]string{
+		"mode":   m.config.Mode,
+		"name":   scope.Bucket,
+		"region": m.config.Region,
+	}
+	if scope.Prefix != "" {
+		outputs["prefix"] = scope.Prefix
+	}
+	return outputs
+}
+
+// scope maps a resource instance to its bucket and prefix
+func (m *Module) scope(instanceID string) Scope {
+	name := bucketName(m.config.NamePrefix + instanceID)
+	if m.config.Mode == ModePrefix {
+		return Scope{Bucket: m.config.SharedBucket, Prefix: name + "/"}
+	}
+	return Scope{Bucket: name}
+}
+
+// principal maps a resource instance to its IAM user name
+func (m *Module) principal(instanceID string) string {
+	name := invalidPrincipalChars.ReplaceAllString(m.config.NamePrefix+instanceID, "-")
+	if len(name) > maxPrincipalNameLength {
+		name = name[:maxPrincipalNameLength]
+	}
+	return name
+}
+
+// bucketName lowercases and strips a name to the characters S3 allows
+func bucketName(name string) string {
+	name = invalidBucketChars.ReplaceAllString(strings.ToLower(name), "-")
+	if len(name) > maxBucketNameLength {
+		name = name[:maxBucketNameLength]
+	}
+	return strings.Trim(name, "-")
+}
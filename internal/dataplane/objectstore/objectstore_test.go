@@ -0,0 +1,289 @@
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/resource"
+	"go.uber.org/zap/zaptest"
+)
+
+type memoryBackend struct {
+	buckets    map[string]map[string][]byte
+	principals map[string]Scope
+	keys       map[string]string
+	issued     int
+}
+
+func newMemoryBackend() *memoryBackend {
+	return &memoryBackend{
+		buckets:    map[string]map[string][]byte{},
+		principals: map[string]Scope{},
+		keys:       map[string]string{},
+	}
+}
+
+func (m *memoryBackend) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	_, ok := m.buckets[bucket]
+	return ok, nil
+}
+
+func (m *memoryBackend) EnsureBucket(ctx context.Context, bucket string, tags map[string]string) error {
+	if _, ok := m.buckets[bucket]; !ok {
+		m.buckets[bucket] = map[string][]byte{}
+	}
+	return nil
+}
+
+func (m *memoryBackend) DeleteBucket(ctx context.Context, bucket string) error {
+	if len(m.buckets[bucket]) > 0 {
+		return fmt.Errorf("BucketNotEmpty")
+	}
+	delete(m.buckets, bucket)
+	return nil
+}
+
+func (m *memoryBackend) DeletePrefix(ctx context.Context, bucket, prefix string) error {
+	for key := range m.buckets[bucket] {
+		if strings.HasPrefix(key, prefix) {
+			delete(m.buckets[bucket], key)
+		}
+	}
+	return nil
+}
+
+func (m *memoryBackend) GetObject(ctx context.Context, bucket, key string) ([]byte, error) {
+	body, ok := m.buckets[bucket][key]
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	return body, nil
+}
+
+func (m *memoryBackend) PutObject(ctx context.Context, bucket, key string, body []byte) error {
+	if _, ok := m.buckets[bucket]; !ok {
+		return fmt.Errorf("NoSuchBucket")
+	}
+	m.buckets[bucket][key] = body
+	return nil
+}
+
+func (m *memoryBackend) EnsurePrincipal(ctx context.Context, name string, scope Scope) error {
+	m.principals[name] = scope
+	return nil
+}
+
+func (m *memoryBackend) HasCredentials(ctx context.Context, name, accessKeyID string) (bool, error) {
+	return m.keys[name] == accessKeyID, nil
+}
+
+func (m *memoryBackend) RotateCredentials(ctx context.Context, name string) (*Credentials, error) {
+	m.issued++
+	id := fmt.Sprintf("AKIA%d", m.issued)
+	m.keys[name] = id
+	return &Credentials{AccessKeyID: id, SecretAccessKey: "secret-" + id}, nil
+}
+
+func (m *memoryBackend) DeletePrincipal(ctx context.Context, name string) error {
+	delete(m.principals, name)
+	delete(m.keys, name)
+	return nil
+}
+
+func secretValue(t *testing.T, secrets map[string]string, envVar string) string {
+	t.Helper()
+	value, ok := secrets[envVar]
+	if !ok {
+		t.Fatalf("missing secret %s", envVar)
+	}
+	return value
+}
+
+func TestModuleProvisionsBucketWithStableCredentials(t *testing.T) {
+	backend := newMemoryBackend()
+	module := NewWithBackend(config.BucketDataPlaneConfig{Region: "us-west-2"}, backend, zaptest.NewLogger(t))
+	ctx := context.Background()
+
+	result, err := module.Provision(ctx, &resource.Request{TenantID: "Tenant_A", Name: "files"})
+	if err != nil {
+		t.Fatalf("provision: %v", err)
+	}
+	if _, ok := backend.buckets["landlord-tenant-a-files"]; !ok {
+		t.Fatalf("expected bucket landlord-tenant-a-files, got %v", backend.buckets)
+	}
+	if result.Env["BUCKET_NAME"] != "landlord-tenant-a-files" || result.Outputs["principal"] != "landlord-Tenant_A-files" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if scope := backend.principals["landlord-Tenant_A-files"]; scope.Bucket != "landlord-tenant-a-files" || scope.Prefix != "" {
+		t.Fatalf("unexpected principal scope: %+v", scope)
+	}
+
+	secrets := map[string]string{}
+	for _, secret := range result.Secrets {
+		secrets[secret.EnvVar] = secret.Value
+	}
+	firstKey := secretValue(t, secrets, "AWS_ACCESS_KEY_ID")
+
+	again, err := module.Provision(ctx, &resource.Request{TenantID: "Tenant_A", Name: "files"})
+	if err != nil {
+		t.Fatalf("re-provision: %v", err)
+	}
+	if again.Secrets[0].Value != firstKey || backend.issued != 1 {
+		t.Fatalf("expected stored credentials to be reused, issued %d keys", backend.issued)
+	}
+
+	// A key revoked out of band is replaced
+	backend.keys["landlord-Tenant_A-files"] = "revoked"
+	rotated, err := module.Provision(ctx, &resource.Request{TenantID: "Tenant_A", Name: "files"})
+	if err != nil {
+		t.Fatalf("provision after revocation: %v", err)
+	}
+	if rotated.Secrets[0].Value == firstKey || backend.issued != 2 {
+		t.Fatalf("expected credentials to rotate, issued %d keys", backend.issued)
+	}
+}
+
+func TestModuleProvisionsPrefixInSharedBucket(t *testing.T) {
+	backend := newMemoryBackend()
+	backend.buckets["shared"] = map[string][]byte{}
+	module := NewWithBackend(config.BucketDataPlaneConfig{
+		Region:       "us-west-2",
+		Mode:         ModePrefix,
+		SharedBucket: "shared",
+		Endpoint:     "http://minio:9000",
+	}, backend, zaptest.NewLogger(t))
+
+	result, err := module.Provision(context.Background(), &resource.Request{TenantID: "tenant-b", Name: "files"})
+	if err != nil {
+		t.Fatalf("provision: %v", err)
+	}
+	if len(backend.buckets) != 1 {
+		t.Fatalf("expected no bucket to be created, got %v", backend.buckets)
+	}
+	if result.Env["BUCKET_PREFIX"] != "landlord-tenant-b-files/" || result.Env["BUCKET_ENDPOINT"] != "http://minio:9000" {
+		t.Fatalf("unexpected env: %+v", result.Env)
+	}
+	if _, ok := backend.buckets["shared"]["landlord-tenant-b-files/"+credentialsObjectKey]; !ok {
+		t.Fatal("expected credentials stored under the tenant prefix")
+	}
+}
+
+func TestModuleWithoutCredentials(t *testing.T) {
+	backend := newMemoryBackend()
+	module := NewWithBackend(config.BucketDataPlaneConfig{Region: "us-west-2", CredentialsMode: CredentialsNone}, backend, zaptest.NewLogger(t))
+
+	result, err := module.Provision(context.Background(), &resource.Request{TenantID: "tenant-c", Name: "files"})
+	if err != nil {
+		t.Fatalf("provision: %v", err)
+	}
+	if len(result.Secrets) != 0 || len(backend.principals) != 0 {
+		t.Fatalf("expected no credentials, got %+v", result.Secrets)
+	}
+}
+
+func TestModuleDestroy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("retain", func(t *testing.T) {
+		backend := newMemoryBackend()
+		module := NewWithBackend(config.BucketDataPlaneConfig{Region: "us-west-2"}, backend, zaptest.NewLogger(t))
+		if _, err := module.Provision(ctx, &resource.Request{TenantID: "tenant-d", Name: "files"}); err != nil {
+			t.Fatalf("provision: %v", err)
+		}
+		backend.buckets["landlord-tenant-d-files"]["data.csv"] = []byte("1,2,3")
+
+		if err := module.Destroy(ctx, &resource.Request{TenantID: "tenant-d", Name: "files"}); err != nil {
+			t.Fatalf("destroy: %v", err)
+		}
+		if len(backend.principals) != 0 {
+			t.Fatal("expected principal to be deleted")
+		}
+		if _, ok := backend.buckets["landlord-tenant-d-files"]["data.csv"]; !ok {
+			t.Fatal("expected objects to be retained")
+		}
+	})
+
+	t.Run("drop", func(t *testing.T) {
+		backend := newMemoryBackend()
+		module := NewWithBackend(config.BucketDataPlaneConfig{Region: "us-west-2", ArchivePolicy: ArchiveDrop}, backend, zaptest.NewLogger(t))
+		if _, err := module.Provision(ctx, &resource.Request{TenantID: "tenant-e", Name: "files"}); err != nil {
+			t.Fatalf("provision: %v", err)
+		}
+		backend.buckets["landlord-tenant-e-files"]["data.csv"] = []byte("1,2,3")
+
+		if err := module.Destroy(ctx, &resource.Request{TenantID: "tenant-e", Name: "files"}); err != nil {
+			t.Fatalf("destroy: %v", err)
+		}
+		if _, ok := backend.buckets["landlord-tenant-e-files"]; ok {
+			t.Fatal("expected bucket to be deleted")
+		}
+	})
+
+	t.Run("drop prefix", func(t *testing.T) {
+		backend := newMemoryBackend()
+		backend.buckets["shared"] = map[string][]byte{"landlord-other/keep": []byte("x")}
+		module := NewWithBackend(config.BucketDataPlaneConfig{
+			Region:        "us-west-2",
+			Mode:          ModePrefix,
+			SharedBucket:  "shared",
+			ArchivePolicy: ArchiveDrop,
+		}, backend, zaptest.NewLogger(t))
+		if _, err := module.Provision(ctx, &resource.Request{TenantID: "tenant-f", Name: "files"}); err != nil {
+			t.Fatalf("provision: %v", err)
+		}
+
+		if err := module.Destroy(ctx, &resource.Request{TenantID: "tenant-f", Name: "files"}); err != nil {
+			t.Fatalf("destroy: %v", err)
+		}
+		if len(backend.buckets["shared"]) != 1 {
+			t.Fatalf("expected only other tenants' objects to remain, got %v", backend.buckets["shared"])
+		}
+	})
+}
+
+func TestModuleStatus(t *testing.T) {
+	backend := newMemoryBackend()
+	module := NewWithBackend(config.BucketDataPlaneConfig{Region: "us-west-2"}, backend, zaptest.NewLogger(t))
+	ctx := context.Background()
+	req := &resource.Request{TenantID: "tenant-g", Name: "files"}
+
+	status, err := module.Status(ctx, req)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.State != resource.StateMissing {
+		t.Fatalf("expected missing before provisioning, got %+v", status)
+	}
+
+	if _, err := module.Provision(ctx, req); err != nil {
+		t.Fatalf("provision: %v", err)
+	}
+	status, err = module.Status(ctx, req)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.State != resource.StateReady || status.Outputs["name"] != "landlord-tenant-g-files" {
+		t.Fatalf("expected ready, got %+v", status)
+	}
+
+	backend.keys["landlord-tenant-g-files"] = "revoked"
+	status, err = module.Status(ctx, req)
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if status.State != resource.StateDegraded {
+		t.Fatalf("expected degraded after revocation, got %+v", status)
+	}
+}
+
+func TestBucketName(t *testing.T) {
+	if got := bucketName("landlord-" + strings.Repeat("x", 80)); len(got) != maxBucketNameLength {
+		t.Fatalf("expected bucket name truncated to %d, got %d", maxBucketNameLength, len(got))
+	}
+	if got := bucketName("Landlord_Acme.Corp-"); got != "landlord-acme-corp" {
+		t.Fatalf("unexpected bucket name: %s", got)
+	}
+}
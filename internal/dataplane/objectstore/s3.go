@@ -0,0 +1,426 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/jaxxstorm/landlord/internal/cloud/awsconfig"
+)
+
+const (
+	iamAPIVersion   = "2010-05-08"
+	iamSigningName  = "iam"
+	iamRegion       = "us-east-1"
+	defaultIAMURL   = "https://iam.amazonaws.com/"
+	principalPath   = "/landlord/"
+	principalPolicy = "landlord-bucket-access"
+	maxDeleteBatch  = 1000
+)
+
+// S3Options configures the S3 backend
+type S3Options struct {
+	Region      string
+	Endpoint    string
+	IAMEndpoint string
+
+	// Credentials overrides the default AWS credential chain
+	Credentials aws.CredentialsProvider
+	HTTPClient  *http.Client
+}
+
+// S3Backend implements Backend with signed requests to the S3 and IAM REST APIs
+type S3Backend struct {
+	region      string
+	endpoint    string
+	iamEndpoint string
+	credentials aws.CredentialsProvider
+	signer      *v4.Signer
+	httpClient  *http.Client
+}
+
+var _ Backend = (*S3Backend)(nil)
+
+// NewS3Backend creates an S3 backend using the default AWS credential chain
+func NewS3Backend(ctx context.Context, opts S3Options) (*S3Backend, error) {
+	creds := opts.Credentials
+	if creds == nil {
+		cfg, err := awsconfig.Load(ctx, awsconfig.Options{Region: opts.Region})
+		if err != nil {
+			return nil, fmt.Errorf("load aws config: %w", err)
+		}
+		creds = cfg.Credentials
+	}
+
+	endpoint := strings.TrimSuffix(opts.Endpoint, "/")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", opts.Region)
+	}
+	iamEndpoint := opts.IAMEndpoint
+	if iamEndpoint == "" {
+		iamEndpoint = defaultIAMURL
+	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &S3Backend{
+		region:      opts.Region,
+		endpoint:    endpoint,
+		iamEndpoint: iamEndpoint,
+		credentials: creds,
+		signer:      v4.NewSigner(),
+		httpClient:  httpClient,
+	}, nil
+}
+
+// s3Error is the XML error body returned by S3 and IAM
+type s3Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (e *s3Error) Error() string {
+	return fmt.Sprintf("%s (status %d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// BucketExists issues a HEAD request for the bucket
+func (b *S3Backend) BucketExists(ctx context.Context, bucket string) (bool, error) {
+	_, err := b.s3Request(ctx, http.MethodHead, bucket, "", nil, nil)
+	if apiErr, ok := err.(*s3Error); ok && apiErr.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// EnsureBucket creates the bucket if needed and applies tags
+func (b *S3Backend) EnsureBucket(ctx context.Context, bucket string, tags map[string]string) error {
+	var body []byte
+	if b.region != "us-east-1" {
+		body = []byte(fmt.Sprintf(`<CreateBucketConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><LocationConstraint>%s</LocationConstraint></CreateBucketConfiguration>`, b.region))
+	}
+	if _, err := b.s3Request(ctx, http.MethodPut, bucket, "", nil, body); err != nil {
+		if apiErr, ok := err.(*s3Error); !ok || apiErr.Code != "BucketAlreadyOwnedByYou" {
+			return err
+		}
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+	var tagging bytes.Buffer
+	tagging.WriteString(`<Tagging><TagSet>`)
+	for key, value := range tags {
+		tagging.WriteString("<Tag><Key>")
+		_ = xml.EscapeText(&tagging, []byte(key))
+		tagging.WriteString("</Key><Value>")
+		_ = xml.EscapeText(&tagging, []byte(value))
+		tagging.WriteString("</Value></Tag>")
+	}
+	tagging.WriteString(`</TagSet></Tagging>`)
+	_, err := b.s3Request(ctx, http.MethodPut, bucket, "", url.Values{"tagging": {""}}, tagging.Bytes())
+	return err
+}
+
+// DeleteBucket deletes an empty bucket
+func (b *S3Backend) DeleteBucket(ctx context.Context, bucket string) error {
+	_, err := b.s3Request(ctx, http.MethodDelete, bucket, "", nil, nil)
+	if apiErr, ok := err.(*s3Error); ok && apiErr.Code == "NoSuchBucket" {
+		return nil
+	}
+	return err
+}
+
+// DeletePrefix deletes every object under prefix, one list page at a time
+func (b *S3Backend) DeletePrefix(ctx context.Context, bucket, prefix string) error {
+	for {
+		query := url.Values{"list-type": {"2"}, "max-keys": {strconv.Itoa(maxDeleteBatch)}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		resp, err := b.s3Request(ctx, http.MethodGet, bucket, "", query, nil)
+		if apiErr, ok := err.(*s3Error); ok && apiErr.Code == "NoSuchBucket" {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var listing struct {
+			Contents []struct {
+				Key string `xml:"Key"`
+			} `xml:"Contents"`
+		}
+		if err := xml.Unmarshal(resp, &listing); err != nil {
+			return fmt.Errorf("decode object listing: %w", err)
+		}
+		if len(listing.Contents) == 0 {
+			return nil
+		}
+
+		var request bytes.Buffer
+		request.WriteString(`<Delete><Quiet>true</Quiet>`)
+		for _, object := range listing.Contents {
+			request.WriteString("<Object><Key>")
+			_ = xml.EscapeText(&request, []byte(object.Key))
+			request.WriteString("</Key></Object>")
+		}
+		request.WriteString(`</Delete>`)
+		if _, err := b.s3Request(ctx, http.MethodPost, bucket, "", url.Values{"delete": {""}}, request.Bytes()); err != nil {
+			return err
+		}
+	}
+}
+
+// GetObject reads an object
+func (b *S3Backend) GetObject(ctx context.Context, bucket, key string) ([]byte, error) {
+	body, err := b.s3Request(ctx, http.MethodGet, bucket, key, nil, nil)
+	if apiErr, ok := err.(*s3Error); ok && (apiErr.Code == "NoSuchKey" || apiErr.StatusCode == http.StatusNotFound) {
+		return nil, ErrObjectNotFound
+	}
+	return body, err
+}
+
+// PutObject writes an object with server-side encryption
+func (b *S3Backend) PutObject(ctx context.Context, bucket, key string, body []byte) error {
+	_, err := b.s3Request(ctx, http.MethodPut, bucket, key, nil, body)
+	return err
+}
+
+// EnsurePrincipal creates the IAM user if needed and (re)applies its inline policy
+func (b *S3Backend) EnsurePrincipal(ctx context.Context, name string, scope Scope) error {
+	_, err := b.iamRequest(ctx, "CreateUser", url.Values{
+		"UserName":            {name},
+		"Path":                {principalPath},
+		"Tags.member.1.Key":   {"landlord:managed"},
+		"Tags.member.1.Value": {"true"},
+	})
+	if apiErr, ok := err.(*s3Error); ok && apiErr.Code == "EntityAlreadyExists" {
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	policy, err := json.Marshal(scopePolicy(scope))
+	if err != nil {
+		return fmt.Errorf("encode policy: %w", err)
+	}
+	_, err = b.iamRequest(ctx, "PutUserPolicy", url.Values{
+		"UserName":       {name},
+		"PolicyName":     {principalPolicy},
+		"PolicyDocument": {string(policy)},
+	})
+	return err
+}
+
+// HasCredentials reports whether the access key still belongs to the IAM user
+func (b *S3Backend) HasCredentials(ctx context.Context, name, accessKeyID string) (bool, error) {
+	keys, err := b.listAccessKeys(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	for _, key := range keys {
+		if key == accessKeyID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RotateCredentials deletes the user's access keys and creates a new one
+func (b *S3Backend) RotateCredentials(ctx context.Context, name string) (*Credentials, error) {
+	if err := b.deleteAccessKeys(ctx, name); err != nil {
+		return nil, err
+	}
+
+	resp, err := b.iamRequest(ctx, "CreateAccessKey", url.Values{"UserName": {name}})
+	if err != nil {
+		return nil, err
+	}
+	var created struct {
+		AccessKeyID     string `xml:"CreateAccessKeyResult>AccessKey>AccessKeyId"`
+		SecretAccessKey string `xml:"CreateAccessKeyResult>AccessKey>SecretAccessKey"`
+	}
+	if err := xml.Unmarshal(resp, &created); err != nil {
+		return nil, fmt.Errorf("decode access key: %w", err)
+	}
+	return &Credentials{AccessKeyID: created.AccessKeyID, SecretAccessKey: created.SecretAccessKey}, nil
+}
+
+// DeletePrincipal removes the IAM user with its keys and inline policy
+func (b *S3Backend) DeletePrincipal(ctx context.Context, name string) error {
+	if err := b.deleteAccessKeys(ctx, name); err != nil {
+		if apiErr, ok := err.(*s3Error); ok && apiErr.Code == "NoSuchEntity" {
+			return nil
+		}
+		return err
+	}
+	if _, err := b.iamRequest(ctx, "DeleteUserPolicy", url.Values{"UserName": {name}, "PolicyName": {principalPolicy}}); err != nil {
+		if apiErr, ok := err.(*s3Error); !ok || apiErr.Code != "NoSuchEntity" {
+			return err
+		}
+	}
+	if _, err := b.iamRequest(ctx, "DeleteUser", url.Values{"UserName": {name}}); err != nil {
+		if apiErr, ok := err.(*s3Error); !ok || apiErr.Code != "NoSuchEntity" {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *S3Backend) listAccessKeys(ctx context.Context, name string) ([]string, error) {
+	resp, err := b.iamRequest(ctx, "ListAccessKeys", url.Values{"UserName": {name}})
+	if err != nil {
+		return nil, err
+	}
+	var listing struct {
+		Keys []string `xml:"ListAccessKeysResult>AccessKeyMetadata>member>AccessKeyId"`
+	}
+	if err := xml.Unmarshal(resp, &listing); err != nil {
+		return nil, fmt.Errorf("decode access keys: %w", err)
+	}
+	return listing.Keys, nil
+}
+
+func (b *S3Backend) deleteAccessKeys(ctx context.Context, name string) error {
+	keys, err := b.listAccessKeys(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, err := b.iamRequest(ctx, "DeleteAccessKey", url.Values{"UserName": {name}, "AccessKeyId": {key}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scopePolicy limits a principal to its bucket, or to its prefix in a shared bucket
+func scopePolicy(scope Scope) map[string]interface{} {
+	bucketARN := "arn:aws:s3:::" + scope.Bucket
+	listStatement := map[string]interface{}{
+		"Effect":   "Allow",
+		"Action":   []string{"s3:ListBucket", "s3:GetBucketLocation"},
+		"Resource": bucketARN,
+	}
+	if scope.Prefix != "" {
+		listStatement["Condition"] = map[string]interface{}{
+			"StringLike": map[string]interface{}{"s3:prefix": scope.Prefix + "*"},
+		}
+	}
+	return map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []interface{}{
+			listStatement,
+			map[string]interface{}{
+				"Effect":   "Allow",
+				"Action":   []string{"s3:GetObject", "s3:PutObject", "s3:DeleteObject", "s3:AbortMultipartUpload", "s3:ListMultipartUploadParts"},
+				"Resource": bucketARN + "/" + scope.Prefix + "*",
+			},
+		},
+	}
+}
+
+// s3Request sends a signed path-style request to S3
+func (b *S3Backend) s3Request(ctx context.Context, method, bucket, key string, query url.Values, body []byte) ([]byte, error) {
+	target := b.endpoint + "/" + bucket
+	if key != "" {
+		target += "/" + (&url.URL{Path: key}).EscapedPath()
+	}
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if len(body) > 0 {
+		// DeleteObjects and PutBucketTagging require Content-MD5
+		sum := md5.Sum(body)
+		req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+		req.Header.Set("Content-Type", "application/xml")
+	}
+	if method == http.MethodPut && key != "" {
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.Header.Set("X-Amz-Server-Side-Encryption", "AES256")
+	}
+	return b.do(ctx, req, body, "s3", b.region)
+}
+
+// iamRequest sends a signed IAM query API request
+func (b *S3Backend) iamRequest(ctx context.Context, action string, params url.Values) ([]byte, error) {
+	params.Set("Action", action)
+	params.Set("Version", iamAPIVersion)
+	body := []byte(params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.iamEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	return b.do(ctx, req, body, iamSigningName, iamRegion)
+}
+
+func (b *S3Backend) do(ctx context.Context, req *http.Request, body []byte, service, region string) ([]byte, error) {
+	creds, err := b.credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("retrieve aws credentials: %w", err)
+	}
+
+	hash := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(hash[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if err := b.signer.SignHTTP(ctx, creds, req, payloadHash, service, region, time.Now()); err != nil {
+		return nil, fmt.Errorf("sign request: %w", err)
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", service, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read %s response: %w", service, err)
+	}
+	if resp.StatusCode >= 300 {
+		apiErr := &s3Error{StatusCode: resp.StatusCode}
+		// S3 returns <Error>; IAM wraps it in <ErrorResponse><Error>
+		var wrapped struct {
+			Error s3Error `xml:"Error"`
+		}
+		if xml.Unmarshal(respBody, &wrapped) == nil && wrapped.Error.Code != "" {
+			apiErr.Code, apiErr.Message = wrapped.Error.Code, wrapped.Error.Message
+		} else {
+			_ = xml.Unmarshal(respBody, apiErr)
+			apiErr.StatusCode = resp.StatusCode
+		}
+		if apiErr.Code == "" {
+			apiErr.Code = http.StatusText(resp.StatusCode)
+		}
+		return nil, apiErr
+	}
+	return respBody, nil
+}
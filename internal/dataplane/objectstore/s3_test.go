@@ -0,0 +1,208 @@
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/credentials"
+)
+
+type recordedRequest struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   string
+}
+
+type fakeAWS struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+	handle   func(w http.ResponseWriter, req recordedRequest)
+}
+
+func (f *fakeAWS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	req := recordedRequest{method: r.Method, path: r.URL.Path, query: r.URL.Query(), header: r.Header, body: string(body)}
+	if r.Method == http.MethodPost && r.URL.Path == "/iam/" {
+		req.query, _ = url.ParseQuery(req.body)
+	}
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+	f.handle(w, req)
+}
+
+func newTestS3Backend(t *testing.T, region string, handle func(w http.ResponseWriter, req recordedRequest)) (*S3Backend, *fakeAWS) {
+	t.Helper()
+	fake := &fakeAWS{handle: handle}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	backend, err := NewS3Backend(context.Background(), S3Options{
+		Region:      region,
+		Endpoint:    server.URL,
+		IAMEndpoint: server.URL + "/iam/",
+		Credentials: credentials.NewStaticCredentialsProvider("AKIDADMIN", "admin-secret", ""),
+	})
+	if err != nil {
+		t.Fatalf("new backend: %v", err)
+	}
+	return backend, fake
+}
+
+func TestS3BackendEnsureBucket(t *testing.T) {
+	backend, fake := newTestS3Backend(t, "eu-west-1", func(w http.ResponseWriter, req recordedRequest) {
+		if _, ok := req.query["tagging"]; !ok && req.method == http.MethodPut {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = io.WriteString(w, `<Error><Code>BucketAlreadyOwnedByYou</Code><Message>owned</Message></Error>`)
+		}
+	})
+
+	if err := backend.EnsureBucket(context.Background(), "landlord-a", map[string]string{"landlord:tenant_id": "a"}); err != nil {
+		t.Fatalf("ensure bucket: %v", err)
+	}
+	if len(fake.requests) != 2 {
+		t.Fatalf("expected create and tagging requests, got %d", len(fake.requests))
+	}
+
+	create := fake.requests[0]
+	if create.path != "/landlord-a" || !strings.Contains(create.body, "<LocationConstraint>eu-west-1</LocationConstraint>") {
+		t.Fatalf("unexpected create request: %+v", create)
+	}
+	if !strings.HasPrefix(create.header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDADMIN/") ||
+		!strings.Contains(create.header.Get("Authorization"), "/eu-west-1/s3/aws4_request") {
+		t.Fatalf("expected signed request, got %q", create.header.Get("Authorization"))
+	}
+
+	tagging := fake.requests[1]
+	if tagging.header.Get("Content-MD5") == "" || !strings.Contains(tagging.body, "<Key>landlord:tenant_id</Key>") {
+		t.Fatalf("unexpected tagging request: %+v", tagging)
+	}
+}
+
+func TestS3BackendDeletePrefixPages(t *testing.T) {
+	listings := []string{
+		`<ListBucketResult><Contents><Key>t/a</Key></Contents><Contents><Key>t/b&amp;c</Key></Contents></ListBucketResult>`,
+		`<ListBucketResult></ListBucketResult>`,
+	}
+	backend, fake := newTestS3Backend(t, "us-east-1", func(w http.ResponseWriter, req recordedRequest) {
+		if req.method == http.MethodGet {
+			_, _ = io.WriteString(w, listings[0])
+			listings = listings[1:]
+		}
+	})
+
+	if err := backend.DeletePrefix(context.Background(), "shared", "t/"); err != nil {
+		t.Fatalf("delete prefix: %v", err)
+	}
+	if len(fake.requests) != 3 {
+		t.Fatalf("expected list, delete, list; got %d requests", len(fake.requests))
+	}
+	if fake.requests[0].query.Get("prefix") != "t/" {
+		t.Fatalf("expected prefix filter, got %v", fake.requests[0].query)
+	}
+	deleteReq := fake.requests[1]
+	if _, ok := deleteReq.query["delete"]; !ok || !strings.Contains(deleteReq.body, "<Key>t/b&amp;c</Key>") {
+		t.Fatalf("unexpected delete request: %+v", deleteReq)
+	}
+}
+
+func TestS3BackendGetObjectNotFound(t *testing.T) {
+	backend, _ := newTestS3Backend(t, "us-east-1", func(w http.ResponseWriter, req recordedRequest) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `<Error><Code>NoSuchKey</Code></Error>`)
+	})
+
+	if _, err := backend.GetObject(context.Background(), "bucket", "missing"); !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("expected ErrObjectNotFound, got %v", err)
+	}
+}
+
+func TestS3BackendBucketExists(t *testing.T) {
+	backend, fake := newTestS3Backend(t, "us-east-1", func(w http.ResponseWriter, req recordedRequest) {
+		if req.path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	exists, err := backend.BucketExists(context.Background(), "present")
+	if err != nil || !exists {
+		t.Fatalf("expected bucket to exist, got %v, %v", exists, err)
+	}
+	if fake.requests[0].method != http.MethodHead {
+		t.Fatalf("expected HEAD request, got %s", fake.requests[0].method)
+	}
+	exists, err = backend.BucketExists(context.Background(), "missing")
+	if err != nil || exists {
+		t.Fatalf("expected missing bucket, got %v, %v", exists, err)
+	}
+}
+
+func TestS3BackendRotateCredentials(t *testing.T) {
+	backend, fake := newTestS3Backend(t, "us-east-1", func(w http.ResponseWriter, req recordedRequest) {
+		switch req.query.Get("Action") {
+		case "ListAccessKeys":
+			_, _ = io.WriteString(w, `<ListAccessKeysResponse><ListAccessKeysResult><AccessKeyMetadata><member><AccessKeyId>AKIAOLD</AccessKeyId></member></AccessKeyMetadata></ListAccessKeysResult></ListAccessKeysResponse>`)
+		case "CreateAccessKey":
+			_, _ = io.WriteString(w, `<CreateAccessKeyResponse><CreateAccessKeyResult><AccessKey><AccessKeyId>AKIANEW</AccessKeyId><SecretAccessKey>new-secret</SecretAccessKey></AccessKey></CreateAccessKeyResult></CreateAccessKeyResponse>`)
+		}
+	})
+
+	creds, err := backend.RotateCredentials(context.Background(), "landlord-a")
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if creds.AccessKeyID != "AKIANEW" || creds.SecretAccessKey != "new-secret" {
+		t.Fatalf("unexpected credentials: %+v", creds)
+	}
+
+	actions := []string{}
+	for _, req := range fake.requests {
+		actions = append(actions, req.query.Get("Action"))
+	}
+	if strings.Join(actions, ",") != "ListAccessKeys,DeleteAccessKey,CreateAccessKey" {
+		t.Fatalf("unexpected actions: %v", actions)
+	}
+	if fake.requests[1].query.Get("AccessKeyId") != "AKIAOLD" {
+		t.Fatalf("expected old key deleted, got %v", fake.requests[1].query)
+	}
+	if !strings.Contains(fake.requests[0].header.Get("Authorization"), "/us-east-1/iam/aws4_request") {
+		t.Fatalf("expected IAM signing scope, got %q", fake.requests[0].header.Get("Authorization"))
+	}
+}
+
+func TestS3BackendEnsurePrincipalScopesPrefix(t *testing.T) {
+	backend, fake := newTestS3Backend(t, "us-east-1", func(w http.ResponseWriter, req recordedRequest) {
+		if req.query.Get("Action") == "CreateUser" {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = io.WriteString(w, `<ErrorResponse><Error><Code>EntityAlreadyExists</Code></Error></ErrorResponse>`)
+		}
+	})
+
+	if err := backend.EnsurePrincipal(context.Background(), "landlord-a", Scope{Bucket: "shared", Prefix: "landlord-a/"}); err != nil {
+		t.Fatalf("ensure principal: %v", err)
+	}
+	policy := fake.requests[1].query.Get("PolicyDocument")
+	if !strings.Contains(policy, `"s3:prefix":"landlord-a/*"`) || !strings.Contains(policy, `"arn:aws:s3:::shared/landlord-a/*"`) {
+		t.Fatalf("unexpected policy: %s", policy)
+	}
+}
+
+func TestS3BackendDeletePrincipalMissing(t *testing.T) {
+	backend, _ := newTestS3Backend(t, "us-east-1", func(w http.ResponseWriter, req recordedRequest) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, `<ErrorResponse><Error><Code>NoSuchEntity</Code></Error></ErrorResponse>`)
+	})
+
+	if err := backend.DeletePrincipal(context.Background(), "landlord-gone"); err != nil {
+		t.Fatalf("expected missing principal to be ignored, got %v", err)
+	}
+}
@@ -0,0 +1,313 @@
+// Package postgres provisions an isolated Postgres schema or database, owned by a dedicated role, for each tenant.
+package postgres
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/resource"
+	"go.uber.org/zap"
+)
+
+// ResourceType is the resource type tenants declare to request a Postgres schema or database
+const ResourceType = "postgres"
+
+// Isolation modes
+const (
+	ModeSchema   = "schema"
+	ModeDatabase = "database"
+)
+
+// Archive policies
+const (
+	ArchiveRetain = "retain"
+	ArchiveDrop   = "drop"
+)
+
+const (
+	maxIdentifierLength = 63
+	secretName          = "postgres-connection"
+)
+
+var invalidIdentifierChars = regexp.MustCompile(`[^a-z0-9_]`)
+
+// Module implements resource.Provider for Postgres
+type Module struct {
+	pool     *pgxpool.Pool
+	adminURL *url.URL
+	config   config.PostgresDataPlaneConfig
+	logger   *zap.Logger
+}
+
+var _ resource.Provider = (*Module)(nil)
+
+// New creates a Postgres data plane module; connections are opened lazily
+func New(ctx context.Context, cfg config.PostgresDataPlaneConfig, logger *zap.Logger) (*Module, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid postgres dataplane config: %w", err)
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = ModeSchema
+	}
+	if cfg.NamePrefix == "" {
+		cfg.NamePrefix = "tenant_"
+	}
+	if cfg.ArchivePolicy == "" {
+		cfg.ArchivePolicy = ArchiveRetain
+	}
+	if cfg.EnvVar == "" {
+		cfg.EnvVar = "DATABASE_URL"
+	}
+
+	adminURL, err := url.Parse(cfg.AdminURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse admin_url: %w", err)
+	}
+
+	pool, err := pgxpool.New(ctx, cfg.AdminURL)
+	if err != nil {
+		return nil, fmt.Errorf("create admin pool: %w", err)
+	}
+
+	return &Module{
+		pool:     pool,
+		adminURL: adminURL,
+		config:   cfg,
+		logger:   logger.With(zap.String("component", "dataplane-postgres")),
+	}, nil
+}
+
+// Type returns the resource type
+func (m *Module) Type() string {
+	return ResourceType
+}
+
+// Close releases the admin connection pool
+func (m *Module) Close() {
+	m.pool.Close()
+}
+
+// Provision creates the tenant role and schema (or database) if missing and returns its connection string
+func (m *Module) Provision(ctx context.Context, req *resource.Request) (*resource.Result, error) {
+	name := m.identifier(req.InstanceID())
+	password := m.password(name)
+
+	if err := m.ensureRole(ctx, name, password); err != nil {
+		return nil, err
+	}
+
+	switch m.config.Mode {
+	case ModeDatabase:
+		if err := m.ensureDatabase(ctx, name); err != nil {
+			return nil, err
+		}
+	default:
+		if _, err := m.pool.Exec(ctx, fmt.Sprintf("CREATE SCHEMA IF NOT EXISTS %s AUTHORIZATION %s", quoteIdent(name), quoteIdent(name))); err != nil {
+			return nil, fmt.Errorf("create schema: %w", err)
+		}
+	}
+
+	m.logger.Info("tenant database provisioned",
+		zap.String("tenant_id", req.TenantID),
+		zap.String("resource", req.Name),
+		zap.String("mode", m.config.Mode),
+		zap.String("name", name),
+	)
+
+	return &resource.Result{
+		Outputs: m.outputs(name),
+		Secrets: []resource.Secret{{
+			Name:   secretName,
+			EnvVar: m.config.EnvVar,
+			Value:  m.connectionString(name, password),
+		}},
+	}, nil
+}
+
+// Update re-applies provisioning; the module has no per-resource settings to change
+func (m *Module) Update(ctx context.Context, req *resource.Request) (*resource.Result, error) {
+	return m.Provision(ctx, req)
+}
+
+// Status reports whether the tenant role and schema (or database) exist and the role can log in
+func (m *Module) Status(ctx context.Context, req *resource.Request) (*resource.Status, error) {
+	name := m.identifier(req.InstanceID())
+
+	var canLogin *bool
+	if err := m.pool.QueryRow(ctx, "SELECT rolcanlogin FROM pg_roles WHERE rolname = $1", name).Scan(&canLogin); err != nil && err != pgx.ErrNoRows {
+		return nil, fmt.Errorf("check role: %w", err)
+	}
+
+	query := "SELECT EXISTS (SELECT 1 FROM pg_namespace WHERE nspname = $1)"
+	if m.config.Mode == ModeDatabase {
+		query = "SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)"
+	}
+	exists, err := m.exists(ctx, query, name)
+	if err != nil {
+		return nil, fmt.Errorf("check %s: %w", m.config.Mode, err)
+	}
+
+	switch {
+	case canLogin == nil || !exists:
+		return &resource.Status{State: resource.StateMissing}, nil
+	case !*canLogin:
+		return &resource.Status{State: resource.StateDegraded, Message: "role login is disabled", Outputs: m.outputs(name)}, nil
+	default:
+		return &resource.Status{State: resource.StateReady, Outputs: m.outputs(name)}, nil
+	}
+}
+
+// Destroy applies the archive policy: retain disables the tenant role's login, drop removes its data and role
+func (m *Module) Destroy(ctx context.Context, req *resource.Request) error {
+	name := m.identifier(req.InstanceID())
+
+	roleExists, err := m.exists(ctx, "SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)", name)
+	if err != nil {
+		return fmt.Errorf("check role: %w", err)
+	}
+
+	if roleExists {
+		if _, err := m.pool.Exec(ctx, fmt.Sprintf("ALTER ROLE %s NOLOGIN", quoteIdent(name))); err != nil {
+			return fmt.Errorf("disable role login: %w", err)
+		}
+		if _, err := m.pool.Exec(ctx, "SELECT pg_terminate_backend(pid) FROM pg_stat_activity WHERE usename = $1", name); err != nil {
+			return fmt.Errorf("terminate tenant sessions: %w", err)
+		}
+	}
+
+	if m.config.ArchivePolicy != ArchiveDrop {
+		m.logger.Info("tenant database retained", zap.String("tenant_id", req.TenantID), zap.String("name", name))
+		return nil
+	}
+
+	switch m.config.Mode {
+	case ModeDatabase:
+		if _, err := m.pool.Exec(ctx, fmt.Sprintf("DROP DATABASE IF EXISTS %s WITH (FORCE)", quoteIdent(name))); err != nil {
+			return fmt.Errorf("drop database: %w", err)
+		}
+	default:
+		if _, err := m.pool.Exec(ctx, fmt.Sprintf("DROP SCHEMA IF EXISTS %s CASCADE", quoteIdent(name))); err != nil {
+			return fmt.Errorf("drop schema: %w", err)
+		}
+	}
+
+	if roleExists {
+		if _, err := m.pool.Exec(ctx, fmt.Sprintf("DROP OWNED BY %s", quoteIdent(name))); err != nil {
+			return fmt.Errorf("drop role objects: %w", err)
+		}
+		if _, err := m.pool.Exec(ctx, fmt.Sprintf("DROP ROLE IF EXISTS %s", quoteIdent(name))); err != nil {
+			return fmt.Errorf("drop role: %w", err)
+		}
+	}
+
+	m.logger.Info("tenant database dropped", zap.String("tenant_id", req.TenantID), zap.String("name", name))
+	return nil
+}
+
+func (m *Module) outputs(name string) map[string]string {
+	return map[string]string{
+		"mode":        m.config.Mode,
+		"role":        name,
+		m.config.Mode: name,
+	}
+}
+
+func (m *Module) ensureRole(ctx context.Context, name, password string) error {
+	exists, err := m.exists(ctx, "SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)", name)
+	if err != nil {
+		return fmt.Errorf("check role: %w", err)
+	}
+
+	// Passwords are derived, so re-applying one is a no-op unless the role was disabled by archival
+	stmt := "CREATE ROLE %s WITH LOGIN PASSWORD %s"
+	if exists {
+		stmt = "ALTER ROLE %s WITH LOGIN PASSWORD %s"
+	}
+	if _, err := m.pool.Exec(ctx, fmt.Sprintf(stmt, quoteIdent(name), quoteLiteral(password))); err != nil {
+		return fmt.Errorf("ensure role: %w", err)
+	}
+	return nil
+}
+
+func (m *Module) ensureDatabase(ctx context.Context, name string) error {
+	exists, err := m.exists(ctx, "SELECT EXISTS (SELECT 1 FROM pg_database WHERE datname = $1)", name)
+	if err != nil {
+		return fmt.Errorf("check database: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	// CREATE DATABASE cannot run in a transaction, so it is not guarded by IF NOT EXISTS
+	if _, err := m.pool.Exec(ctx, fmt.Sprintf("CREATE DATABASE %s OWNER %s", quoteIdent(name), quoteIdent(name))); err != nil {
+		return fmt.Errorf("create database: %w", err)
+	}
+	if _, err := m.pool.Exec(ctx, fmt.Sprintf("REVOKE CONNECT ON DATABASE %s FROM PUBLIC", quoteIdent(name))); err != nil {
+		return fmt.Errorf("restrict database access: %w", err)
+	}
+	return nil
+}
+
+func (m *Module) exists(ctx context.Context, query, name string) (bool, error) {
+	var exists bool
+	if err := m.pool.QueryRow(ctx, query, name).Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// identifier maps a resource instance ID to a valid, stable Postgres identifier
+func (m *Module) identifier(instanceID string) string {
+	name := strings.ToLower(m.config.NamePrefix + instanceID)
+	name = invalidIdentifierChars.ReplaceAllString(name, "_")
+	if len(name) > maxIdentifierLength {
+		name = name[:maxIdentifierLength]
+	}
+	return name
+}
+
+// password derives the role password from the configured secret
+func (m *Module) password(name string) string {
+	mac := hmac.New(sha256.New, []byte(m.config.PasswordSecret))
+	mac.Write([]byte(name))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// connectionString builds the tenant's connection URL from the admin URL
+func (m *Module) connectionString(name, password string) string {
+	u := *m.adminURL
+	u.User = url.UserPassword(name, password)
+	if m.config.TenantHost != "" {
+		if port := u.Port(); port != "" {
+			u.Host = m.config.TenantHost + ":" + port
+		} else {
+			u.Host = m.config.TenantHost
+		}
+	}
+
+	query := u.Query()
+	if m.config.Mode == ModeDatabase {
+		u.Path = "/" + name
+	} else {
+		query.Set("options", "-c search_path="+name)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func quoteIdent(name string) string {
+	return pgx.Identifier{name}.Sanitize()
+}
+
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/resource"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.uber.org/zap/zaptest"
+)
+
+func newTestModule(t *testing.T, cfg config.PostgresDataPlaneConfig) *Module {
+	t.Helper()
+	if cfg.PasswordSecret == "" {
+		cfg.PasswordSecret = "test-secret"
+	}
+	module, err := New(context.Background(), cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("new module: %v", err)
+	}
+	t.Cleanup(module.Close)
+	return module
+}
+
+func TestIdentifier(t *testing.T) {
+	module := newTestModule(t, config.PostgresDataPlaneConfig{AdminURL: "postgres://admin:pw@localhost:5432/postgres"})
+
+	if got := module.identifier("3F2504E0-4F89-11D3-9A0C-0305E82C3301"); got != "tenant_3f2504e0_4f89_11d3_9a0c_0305e82c3301" {
+		t.Fatalf("unexpected identifier: %s", got)
+	}
+	if got := module.identifier(strings.Repeat("a", 100)); len(got) != maxIdentifierLength {
+		t.Fatalf("expected identifier truncated to %d chars, got %d", maxIdentifierLength, len(got))
+	}
+}
+
+func TestPasswordIsStable(t *testing.T) {
+	module := newTestModule(t, config.PostgresDataPlaneConfig{AdminURL: "postgres://admin:pw@localhost:5432/postgres"})
+	other := newTestModule(t, config.PostgresDataPlaneConfig{AdminURL: "postgres://admin:pw@localhost:5432/postgres", PasswordSecret: "rotated"})
+
+	if module.password("tenant_a") != module.password("tenant_a") {
+		t.Fatal("expected derived password to be stable")
+	}
+	if module.password("tenant_a") == module.password("tenant_b") {
+		t.Fatal("expected distinct passwords per tenant")
+	}
+	if module.password("tenant_a") == other.password("tenant_a") {
+		t.Fatal("expected password to depend on the secret")
+	}
+}
+
+func TestConnectionString(t *testing.T) {
+	schema := newTestModule(t, config.PostgresDataPlaneConfig{
+		AdminURL:   "postgres://admin:pw@10.0.0.5:5432/landlord_data?sslmode=require",
+		TenantHost: "db.internal",
+	})
+	parsed, err := url.Parse(schema.connectionString("tenant_a", "secret"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if parsed.Host != "db.internal:5432" || parsed.Path != "/landlord_data" || parsed.User.Username() != "tenant_a" {
+		t.Fatalf("unexpected schema connection string: %s", parsed)
+	}
+	if parsed.Query().Get("options") != "-c search_path=tenant_a" || parsed.Query().Get("sslmode") != "require" {
+		t.Fatalf("unexpected query: %s", parsed.RawQuery)
+	}
+
+	database := newTestModule(t, config.PostgresDataPlaneConfig{
+		AdminURL: "postgres://admin:pw@localhost:5432/postgres",
+		Mode:     ModeDatabase,
+	})
+	parsed, err = url.Parse(database.connectionString("tenant_a", "secret"))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if parsed.Path != "/tenant_a" || parsed.Query().Has("options") {
+		t.Fatalf("unexpected database connection string: %s", parsed)
+	}
+}
+
+func setupPostgres(t *testing.T) string {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "postgres:16-alpine",
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_USER":     "admin",
+				"POSTGRES_PASSWORD": "adminpass",
+				"POSTGRES_DB":       "landlord_data",
+			},
+			WaitingFor: wait.ForListeningPort("5432/tcp"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to start container: %s", err)
+	}
+	t.Cleanup(func() { _ = container.Terminate(ctx) })
+
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("failed to get container host: %s", err)
+	}
+	port, err := container.MappedPort(ctx, "5432")
+	if err != nil {
+		t.Fatalf("failed to get container port: %s", err)
+	}
+	return "postgres://admin:adminpass@" + host + ":" + port.Port() + "/landlord_data?sslmode=disable"
+}
+
+func TestModuleLifecycle(t *testing.T) {
+	adminURL := setupPostgres(t)
+	ctx := context.Background()
+
+	for _, mode := range []string{ModeSchema, ModeDatabase} {
+		t.Run(mode, func(t *testing.T) {
+			module := newTestModule(t, config.PostgresDataPlaneConfig{
+				AdminURL:      adminURL,
+				Mode:          mode,
+				NamePrefix:    mode + "_",
+				ArchivePolicy: ArchiveRetain,
+			})
+			req := &resource.Request{TenantID: "tenant-1", Name: "db"}
+
+			status, err := module.Status(ctx, req)
+			if err != nil {
+				t.Fatalf("status: %v", err)
+			}
+			if status.State != resource.StateMissing {
+				t.Fatalf("expected missing before provisioning, got %+v", status)
+			}
+
+			result, err := module.Provision(ctx, req)
+			if err != nil {
+				t.Fatalf("provision: %v", err)
+			}
+			if status, err := module.Status(ctx, req); err != nil || status.State != resource.StateReady {
+				t.Fatalf("expected ready after provisioning, got %+v err=%v", status, err)
+			}
+			// Provisioning is idempotent and keeps the same credentials
+			again, err := module.Provision(ctx, req)
+			if err != nil {
+				t.Fatalf("re-provision: %v", err)
+			}
+			if result.Secrets[0].Value != again.Secrets[0].Value {
+				t.Fatal("expected stable connection string")
+			}
+
+			conn, err := pgx.Connect(ctx, result.Secrets[0].Value)
+			if err != nil {
+				t.Fatalf("connect as tenant: %v", err)
+			}
+			if _, err := conn.Exec(ctx, "CREATE TABLE widgets (id int)"); err != nil {
+				t.Fatalf("create table as tenant: %v", err)
+			}
+			conn.Close(ctx)
+
+			if err := module.Destroy(ctx, req); err != nil {
+				t.Fatalf("destroy: %v", err)
+			}
+			if conn, err := pgx.Connect(ctx, result.Secrets[0].Value); err == nil {
+				conn.Close(ctx)
+				t.Fatal("expected login to be disabled after archival")
+			}
+			if status, err := module.Status(ctx, req); err != nil || status.State != resource.StateDegraded {
+				t.Fatalf("expected degraded after archival, got %+v err=%v", status, err)
+			}
+
+			// Re-provisioning an archived tenant restores access to retained data
+			if _, err := module.Provision(ctx, req); err != nil {
+				t.Fatalf("restore: %v", err)
+			}
+			conn, err = pgx.Connect(ctx, result.Secrets[0].Value)
+			if err != nil {
+				t.Fatalf("reconnect as tenant: %v", err)
+			}
+			if _, err := conn.Exec(ctx, "SELECT * FROM widgets"); err != nil {
+				t.Fatalf("expected retained table: %v", err)
+			}
+			conn.Close(ctx)
+
+			module.config.ArchivePolicy = ArchiveDrop
+			if err := module.Destroy(ctx, req); err != nil {
+				t.Fatalf("drop: %v", err)
+			}
+			name := module.identifier(req.InstanceID())
+			if exists, err := module.exists(ctx, "SELECT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = $1)", name); err != nil || exists {
+				t.Fatalf("expected role dropped, exists=%v err=%v", exists, err)
+			}
+			// Destroying again is a no-op
+			if err := module.Destroy(ctx, req); err != nil {
+				t.Fatalf("repeat destroy: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package doctor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/database"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// maxDetails bounds how many affected items a result lists
+const maxDetails = 20
+
+// DatabaseCheck checks that the database is reachable and migrated to this binary's schema
+func DatabaseCheck(provider database.Provider) Check {
+	const name = "database"
+	return Check{Name: name, Run: func(ctx context.Context) []Result {
+		if err := provider.Health(ctx); err != nil {
+			return []Result{Fail(name, SeverityCritical, fmt.Sprintf("database is unreachable: %v", err),
+				"Check the database settings (DB_HOST, DB_PORT, DB_USER, DB_PASSWORD) and that the database is running")}
+		}
+		results := []Result{Pass(name, "database is reachable")}
+
+		latest, err := database.LatestMigrationVersion()
+		if err != nil {
+			return append(results, Fail(name+"/migrations", SeverityWarning, err.Error(), "Report this as a bug"))
+		}
+		version, dirty, err := database.MigrationVersion(ctx, provider)
+		switch {
+		case errors.Is(err, database.ErrMigrationStatusUnavailable):
+			results = append(results, Skip(name+"/migrations", err.Error()))
+		case err != nil:
+			results = append(results, Fail(name+"/migrations", SeverityCritical, err.Error(),
+				"Check that the database user can read the schema_migrations table"))
+		case dirty:
+			results = append(results, Fail(name+"/migrations", SeverityCritical, fmt.Sprintf("migration %d failed part way and left the schema dirty", version),
+				fmt.Sprintf("Finish or undo migration %d by hand, then clear the flag with `migrate force %d` and restart the server", version, version)))
+		case version < latest:
+			results = append(results, Fail(name+"/migrations", SeverityCritical, fmt.Sprintf("database is at migration %d of %d", version, latest),
+				"Restart the server, which applies pending migrations on startup"))
+		case version > latest:
+			results = append(results, Warn(name+"/migrations", fmt.Sprintf("database is at migration %d, newer than this binary's %d", version, latest),
+				"Upgrade this landlord binary to the version that migrated the database"))
+		default:
+			results = append(results, Pass(name+"/migrations", fmt.Sprintf("database is at the latest migration (%d)", version)))
+		}
+		return results
+	}}
+}
+
+// ReadinessChecker reports whether a component has started and is doing its work
+type ReadinessChecker interface {
+	IsReady() bool
+}
+
+// ControllerCheck checks that the reconciliation controller is running
+func ControllerCheck(controller ReadinessChecker) Check {
+	const name = "controller"
+	return Check{Name: name, Run: func(ctx context.Context) []Result {
+		if !controller.IsReady() {
+			return []Result{Fail(name, SeverityCritical, "reconciliation controller is not running, so tenants do not progress",
+				"Set controller.enabled (CONTROLLER_ENABLED) and check the server log for controller start errors")}
+		}
+		return []Result{Pass(name, "reconciliation controller is running")}
+	}}
+}
+
+// ComputeProvidersCheck checks every registered compute provider that can diagnose itself
+func ComputeProvidersCheck(registry *compute.Registry) Check {
+	return Check{Name: "compute", Run: func(ctx context.Context) []Result {
+		names := registry.List()
+		if len(names) == 0 {
+			return []Result{Fail("compute", SeverityCritical, "no compute providers are registered",
+				"Configure at least one provider under compute (see docs/configuration.md)")}
+		}
+		var results []Result
+		for _, providerName := range names {
+			provider, err := registry.Get(providerName)
+			if err != nil {
+				continue
+			}
+			results = append(results, diagnoseProvider(ctx, "compute/"+providerName, provider, registry.Enabled(providerName))...)
+		}
+		return results
+	}}
+}
+
+// WorkflowProvidersCheck checks every registered workflow provider that can diagnose itself,
+// such as Restate's ingress, admin API and worker deployment
+func WorkflowProvidersCheck(registry *workflow.Registry) Check {
+	return Check{Name: "workflow", Run: func(ctx context.Context) []Result {
+		names := registry.List()
+		if len(names) == 0 {
+			return []Result{Fail("workflow", SeverityCritical, "no workflow providers are registered",
+				"Set workflow.default_provider and its settings (see docs/configuration.md)")}
+		}
+		var results []Result
+		for _, providerName := range names {
+			provider, err := registry.Get(providerName)
+			if err != nil {
+				continue
+			}
+			results = append(results, diagnoseProvider(ctx, "workflow/"+providerName, provider, registry.Enabled(providerName))...)
+		}
+		return results
+	}}
+}
+
+// diagnoseProvider runs a provider's own checks, naming each result after the provider
+func diagnoseProvider(ctx context.Context, name string, provider any, enabled bool) []Result {
+	if !enabled {
+		return []Result{Skip(name, "provider is disabled")}
+	}
+	diagnoser, ok := provider.(Diagnoser)
+	if !ok {
+		return []Result{Skip(name, "provider has no health check")}
+	}
+	results := diagnoser.Diagnose(ctx)
+	for i := range results {
+		if results[i].Check == "" {
+			results[i].Check = name
+		} else {
+			results[i].Check = name + "/" + results[i].Check
+		}
+	}
+	if len(results) == 0 {
+		results = []Result{Pass(name, "provider is healthy")}
+	}
+	return results
+}
+
+// ComputeInventoryCheck compares the compute resources of each provider that can list them with
+// the tenant records. Resources of tenants that are archived or have no record are orphaned, and
+// ready tenants without resources have lost their compute. Tenants that name no compute provider
+// are expected on defaultProvider.
+func ComputeInventoryCheck(registry *compute.Registry, defaultProvider string, tenants tenant.Repository) Check {
+	const name = "compute-inventory"
+	return Check{Name: name, Run: func(ctx context.Context) []Result {
+		records, err := tenants.ListTenants(ctx, tenant.ListFilters{IncludeDeleted: true})
+		if err != nil {
+			return []Result{Fail(name, SeverityWarning, fmt.Sprintf("failed to list tenants: %v", err), "Check the database check above")}
+		}
+		// Providers know tenants by ID or by compute name, depending on the workflow engine
+		live := make(map[string]*tenant.Tenant)
+		for _, t := range records {
+			if t.IsArchived() {
+				continue
+			}
+			live[t.ID.String()] = t
+			live[t.ComputeName()] = t
+		}
+
+		var results []Result
+		listed := false
+		for _, providerName := range registry.List() {
+			provider, err := registry.Get(providerName)
+			if err != nil {
+				continue
+			}
+			inventory, ok := provider.(compute.Inventory)
+			if !ok {
+				continue
+			}
+			listed = true
+			check := name + "/" + providerName
+
+			tenantIDs, err := inventory.ListTenants(ctx)
+			if err != nil {
+				results = append(results, Fail(check, SeverityWarning, fmt.Sprintf("failed to list compute resources: %v", err),
+					"Check the compute provider's health result"))
+				continue
+			}
+			held := make(map[*tenant.Tenant]bool)
+			var orphaned []string
+			for _, tenantID := range tenantIDs {
+				if t, ok := live[tenantID]; ok {
+					held[t] = true
+					continue
+				}
+				orphaned = append(orphaned, tenantID)
+			}
+
+			var missing []string
+			for _, t := range records {
+				if t.Status != tenant.StatusReady || held[t] || tenantComputeProvider(t, defaultProvider) != providerName {
+					continue
+				}
+				missing = append(missing, fmt.Sprintf("%s (%s)", t.Name, t.ID))
+			}
+			sort.Strings(missing)
+
+			if len(orphaned) > 0 {
+				results = append(results, withDetails(Fail(check, SeverityWarning,
+					fmt.Sprintf("%d tenant(s) have compute resources but no live tenant record", len(orphaned)),
+					fmt.Sprintf("Remove the resources labelled %s=<tenant> with the %s provider's tooling, after checking nothing still uses them", compute.MetadataTenantIDKey, providerName)),
+					orphaned))
+			}
+			if len(missing) > 0 {
+				results = append(results, withDetails(Fail(check, SeverityCritical,
+					fmt.Sprintf("%d ready tenant(s) have no compute resources", len(missing)),
+					"Update each tenant's config to provision it again, or archive it"),
+					missing))
+			}
+			if len(orphaned) == 0 && len(missing) == 0 {
+				results = append(results, Pass(check, fmt.Sprintf("compute resources of %d tenant(s) match the tenant records", len(tenantIDs))))
+			}
+		}
+		if !listed {
+			return []Result{Skip(name, "no compute provider can list its resources")}
+		}
+		return results
+	}}
+}
+
+// StuckTenantsCheck reports tenants that have stayed in an in-progress status for longer than
+// threshold without being updated, and tenants that failed
+func StuckTenantsCheck(tenants tenant.Repository, threshold time.Duration) Check {
+	const name = "tenants"
+	return Check{Name: name, Run: func(ctx context.Context) []Result {
+		records, err := tenants.ListTenants(ctx, tenant.ListFilters{})
+		if err != nil {
+			return []Result{Fail(name, SeverityWarning, fmt.Sprintf("failed to list tenants: %v", err), "Check the database check above")}
+		}
+
+		now := time.Now()
+		var stuck, failed []string
+		for _, t := range records {
+			switch {
+			case t.Status == tenant.StatusFailed:
+				failed = append(failed, fmt.Sprintf("%s (%s)", t.Name, t.ID))
+			case tenant.ShouldReconcile(t.Status) && now.Sub(t.UpdatedAt) > threshold:
+				stuck = append(stuck, fmt.Sprintf("%s (%s) %s for %s", t.Name, t.ID, t.Status, now.Sub(t.UpdatedAt).Truncate(time.Second)))
+			}
+		}
+		sort.Strings(stuck)
+		sort.Strings(failed)
+
+		var results []Result
+		if len(stuck) > 0 {
+			results = append(results, withDetails(Fail(name+"/stuck", SeverityWarning,
+				fmt.Sprintf("%d tenant(s) have not progressed in %s", len(stuck), threshold),
+				"Check each tenant's workflow execution with `landlord get`; if the execution is gone, retry or archive the tenant"),
+				stuck))
+		}
+		if len(failed) > 0 {
+			results = append(results, withDetails(Warn(name+"/failed",
+				fmt.Sprintf("%d tenant(s) have failed", len(failed)),
+				"Read each tenant's status message with `landlord get`, fix the cause, then retry or archive it"),
+				failed))
+		}
+		if len(results) == 0 {
+			results = append(results, Pass(name, fmt.Sprintf("no tenant has been in progress for longer than %s", threshold)))
+		}
+		return results
+	}}
+}
+
+// withDetails attaches up to maxDetails affected items to a result
+func withDetails(r Result, details []string) Result {
+	if len(details) > maxDetails {
+		details = append(details[:maxDetails:maxDetails], fmt.Sprintf("and %d more", len(details)-maxDetails))
+	}
+	r.Details = details
+	return r
+}
+
+// tenantComputeProvider returns the compute provider a tenant names, or defaultProvider
+func tenantComputeProvider(t *tenant.Tenant, defaultProvider string) string {
+	for _, key := range []string{"compute_provider", "compute_provider_type"} {
+		if name, ok := t.DesiredConfig[key].(string); ok && name != "" {
+			return name
+		}
+	}
+	if name := t.Labels["compute_provider"]; name != "" {
+		return name
+	}
+	if name := t.Annotations["compute_provider"]; name != "" {
+		return name
+	}
+	return defaultProvider
+}
@@ -0,0 +1,199 @@
+// Package doctor runs connectivity and consistency checks against a Landlord deployment and
+// reports what is wrong, most serious first, with a suggested fix for each problem.
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+// Status is the outcome of a check
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+	// StatusSkip means the check could not run here, such as a provider without a health check
+	StatusSkip Status = "skip"
+)
+
+// Severity is how urgently a problem needs fixing
+type Severity string
+
+const (
+	// SeverityCritical problems stop tenants from being provisioned or served
+	SeverityCritical Severity = "critical"
+	// SeverityWarning problems affect some tenants or will get worse if left
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Result is one finding of a check
+type Result struct {
+	// Check names what was checked, such as "database" or "compute/docker"
+	Check    string   `json:"check"`
+	Status   Status   `json:"status"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+
+	// Fix suggests how to resolve a problem; empty for passing results
+	Fix string `json:"fix,omitempty"`
+
+	// Details lists the affected items, such as tenant IDs
+	Details []string `json:"details,omitempty"`
+}
+
+// Pass returns a passing result
+func Pass(check, message string) Result {
+	return Result{Check: check, Status: StatusPass, Severity: SeverityInfo, Message: message}
+}
+
+// Skip returns a result for a check that could not run
+func Skip(check, message string) Result {
+	return Result{Check: check, Status: StatusSkip, Severity: SeverityInfo, Message: message}
+}
+
+// Fail returns a failed result with a suggested fix
+func Fail(check string, severity Severity, message, fix string) Result {
+	return Result{Check: check, Status: StatusFail, Severity: severity, Message: message, Fix: fix}
+}
+
+// Warn returns a warning with a suggested fix
+func Warn(check, message, fix string) Result {
+	return Result{Check: check, Status: StatusWarn, Severity: SeverityWarning, Message: message, Fix: fix}
+}
+
+// Check is a named diagnostic. Run returns one result per thing it checked.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) []Result
+}
+
+// Diagnoser is implemented by compute and workflow providers that can check the services they depend on
+type Diagnoser interface {
+	// Diagnose checks the provider's dependencies, returning one result for each
+	Diagnose(ctx context.Context) []Result
+}
+
+// Report is the outcome of a doctor run, most serious problems first
+type Report struct {
+	// Status is the worst status of any result
+	Status    Status    `json:"status"`
+	Results   []Result  `json:"results"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Doctor runs registered checks
+type Doctor struct {
+	timeout time.Duration
+	logger  *zap.Logger
+
+	mu     sync.RWMutex
+	checks []Check
+}
+
+// New creates a doctor with no checks
+func New(cfg config.DoctorConfig, logger *zap.Logger) *Doctor {
+	return &Doctor{
+		timeout: cfg.CheckTimeout,
+		logger:  logger.With(zap.String("component", "doctor")),
+	}
+}
+
+// Register adds checks to every run
+func (d *Doctor) Register(checks ...Check) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.checks = append(d.checks, checks...)
+}
+
+// Run runs every check concurrently, each bounded by the check timeout, and returns the report
+func (d *Doctor) Run(ctx context.Context) *Report {
+	d.mu.RLock()
+	checks := append([]Check(nil), d.checks...)
+	d.mu.RUnlock()
+
+	results := make([][]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = d.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusPass, CheckedAt: time.Now().UTC()}
+	for _, checkResults := range results {
+		report.Results = append(report.Results, checkResults...)
+	}
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		a, b := report.Results[i], report.Results[j]
+		if priority(a) != priority(b) {
+			return priority(a) < priority(b)
+		}
+		return a.Check < b.Check
+	})
+	for _, result := range report.Results {
+		if statusRank[result.Status] < statusRank[report.Status] {
+			report.Status = result.Status
+		}
+	}
+	return report
+}
+
+// run runs one check, turning a panic or an overrun into a failed result
+func (d *Doctor) run(ctx context.Context, check Check) (results []Result) {
+	checkCtx := ctx
+	if d.timeout > 0 {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+	}
+
+	done := make(chan []Result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				d.logger.Error("doctor check panicked", zap.String("check", check.Name), zap.Any("panic", r))
+				done <- []Result{Fail(check.Name, SeverityWarning, fmt.Sprintf("check panicked: %v", r), "Report this as a bug; the other checks are unaffected")}
+			}
+		}()
+		done <- check.Run(checkCtx)
+	}()
+
+	select {
+	case results = <-done:
+	case <-checkCtx.Done():
+		results = []Result{Fail(check.Name, SeverityWarning, fmt.Sprintf("check did not finish: %v", checkCtx.Err()),
+			"Check that the service behind it is responding, or raise doctor.check_timeout")}
+	}
+	if len(results) == 0 {
+		results = []Result{Pass(check.Name, "ok")}
+	}
+	for i := range results {
+		if results[i].Check == "" {
+			results[i].Check = check.Name
+		}
+	}
+	return results
+}
+
+// statusRank orders statuses from worst to best; a report takes its worst result's status
+var statusRank = map[Status]int{StatusFail: 0, StatusWarn: 1, StatusPass: 2, StatusSkip: 3}
+
+var severityRank = map[Severity]int{SeverityCritical: 0, SeverityWarning: 1, SeverityInfo: 2}
+
+// priority orders results for the report: failures before warnings, then by severity
+func priority(r Result) int {
+	return statusRank[r.Status]*len(severityRank) + severityRank[r.Severity]
+}
@@ -0,0 +1,138 @@
+package doctor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	computemock "github.com/jaxxstorm/landlord/internal/compute/providers/mock"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/doctor"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+// fakeDatabase is a database provider without a pool, so migrations cannot be checked
+type fakeDatabase struct{ err error }
+
+func (fakeDatabase) Pool() interface{} { return nil }
+
+func (d fakeDatabase) Health(ctx context.Context) error { return d.err }
+
+func (fakeDatabase) Close() {}
+
+type readiness bool
+
+func (r readiness) IsReady() bool { return bool(r) }
+
+func newDoctor(checks ...doctor.Check) *doctor.Doctor {
+	d := doctor.New(config.DoctorConfig{StuckThreshold: time.Minute, CheckTimeout: time.Second}, zap.NewNop())
+	d.Register(checks...)
+	return d
+}
+
+func byCheck(report *doctor.Report) map[string]doctor.Result {
+	results := make(map[string]doctor.Result)
+	for _, result := range report.Results {
+		results[result.Check] = result
+	}
+	return results
+}
+
+func TestRunOrdersResultsByPriority(t *testing.T) {
+	d := newDoctor(
+		doctor.DatabaseCheck(fakeDatabase{}),
+		doctor.ControllerCheck(readiness(false)),
+		doctor.Check{Name: "custom", Run: func(ctx context.Context) []doctor.Result {
+			return []doctor.Result{doctor.Warn("", "something is off", "fix it")}
+		}},
+	)
+
+	report := d.Run(context.Background())
+	assert.Equal(t, doctor.StatusFail, report.Status)
+	require.Len(t, report.Results, 4)
+	assert.Equal(t, "controller", report.Results[0].Check)
+	assert.Equal(t, doctor.SeverityCritical, report.Results[0].Severity)
+	assert.Equal(t, "custom", report.Results[1].Check)
+	assert.Equal(t, "database", report.Results[2].Check)
+	assert.Equal(t, doctor.StatusPass, report.Results[2].Status)
+	assert.Equal(t, doctor.StatusSkip, report.Results[3].Status)
+}
+
+func TestRunBoundsEachCheck(t *testing.T) {
+	d := doctor.New(config.DoctorConfig{StuckThreshold: time.Minute, CheckTimeout: 20 * time.Millisecond}, zap.NewNop())
+	d.Register(
+		doctor.Check{Name: "slow", Run: func(ctx context.Context) []doctor.Result {
+			<-ctx.Done()
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		}},
+		doctor.Check{Name: "broken", Run: func(ctx context.Context) []doctor.Result { panic("boom") }},
+		doctor.DatabaseCheck(fakeDatabase{err: errors.New("connection refused")}),
+	)
+
+	results := byCheck(d.Run(context.Background()))
+	assert.Equal(t, doctor.StatusFail, results["slow"].Status)
+	assert.Contains(t, results["slow"].Message, "did not finish")
+	assert.Equal(t, doctor.StatusFail, results["broken"].Status)
+	assert.Contains(t, results["broken"].Message, "boom")
+	assert.Equal(t, doctor.SeverityCritical, results["database"].Severity)
+	assert.NotEmpty(t, results["database"].Fix)
+}
+
+func TestComputeInventoryCheck(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	registry := compute.NewRegistry(zap.NewNop())
+	provider := computemock.New()
+	require.NoError(t, registry.Register(provider))
+
+	served := &tenant.Tenant{Name: "served", Status: tenant.StatusReady}
+	lost := &tenant.Tenant{Name: "lost", Status: tenant.StatusReady}
+	archived := &tenant.Tenant{Name: "archived", Status: tenant.StatusArchived}
+	for _, tn := range []*tenant.Tenant{served, lost, archived} {
+		require.NoError(t, repo.CreateTenant(ctx, tn))
+	}
+	for _, tenantID := range []string{"served", archived.ID.String(), "ghost"} {
+		_, err := provider.Provision(ctx, &compute.TenantComputeSpec{TenantID: tenantID, ProviderType: "mock"})
+		require.NoError(t, err)
+	}
+
+	d := newDoctor(doctor.ComputeInventoryCheck(registry, "mock", repo))
+	report := d.Run(ctx)
+	require.Len(t, report.Results, 2)
+
+	missing, orphaned := report.Results[0], report.Results[1]
+	assert.Equal(t, doctor.SeverityCritical, missing.Severity)
+	assert.Equal(t, []string{"lost (" + lost.ID.String() + ")"}, missing.Details)
+	assert.Equal(t, doctor.SeverityWarning, orphaned.Severity)
+	assert.Equal(t, []string{archived.ID.String(), "ghost"}, orphaned.Details)
+}
+
+func TestStuckTenantsCheck(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	for _, tn := range []*tenant.Tenant{
+		{Name: "provisioning", Status: tenant.StatusProvisioning},
+		{Name: "failed", Status: tenant.StatusFailed},
+		{Name: "ready", Status: tenant.StatusReady},
+	} {
+		require.NoError(t, repo.CreateTenant(ctx, tn))
+	}
+
+	results := byCheck(newDoctor(doctor.StuckTenantsCheck(repo, time.Hour)).Run(ctx))
+	assert.NotContains(t, results, "tenants/stuck")
+	assert.Equal(t, doctor.StatusWarn, results["tenants/failed"].Status)
+
+	time.Sleep(20 * time.Millisecond)
+	results = byCheck(newDoctor(doctor.StuckTenantsCheck(repo, 10*time.Millisecond)).Run(ctx))
+	require.Contains(t, results, "tenants/stuck")
+	assert.Len(t, results["tenants/stuck"].Details, 1)
+	assert.Contains(t, results["tenants/stuck"].Details[0], "provisioning")
+}
@@ -0,0 +1,211 @@
+// Package egress watches the traffic that tenants' egress policies deny. Compute providers enforce
+// compute_config.egress; the monitor reads how much each policy has dropped from the providers that
+// can report it and records new violations in the tenant's state history.
+package egress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// Manager is the history trigger recorded for egress violation events
+const Manager = "egress-monitor"
+
+// Monitor periodically reads the denied egress of every ready tenant with an egress policy. Counts
+// are kept in memory, so the first read of a tenant after the monitor starts, or after its policy
+// is applied, sets the baseline later violations are counted from.
+type Monitor struct {
+	tenants  tenant.Repository
+	registry *compute.Registry
+	interval time.Duration
+	logger   *zap.Logger
+
+	deniedMu sync.Mutex
+	denied   map[uuid.UUID]int64
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewMonitor creates an egress monitor reading from the providers in registry
+func NewMonitor(tenants tenant.Repository, registry *compute.Registry, cfg config.EgressMonitorConfig, logger *zap.Logger) *Monitor {
+	return &Monitor{
+		tenants:  tenants,
+		registry: registry,
+		interval: cfg.Interval,
+		logger:   logger.With(zap.String("component", "egress-monitor")),
+		denied:   make(map[uuid.UUID]int64),
+	}
+}
+
+// Start monitors tenants in the background until Stop is called
+func (m *Monitor) Start() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.interval <= 0 {
+		return fmt.Errorf("egress monitor interval must be positive")
+	}
+	if m.cancel != nil {
+		return fmt.Errorf("egress monitor already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.run(ctx, m.done)
+
+	m.logger.Info("egress monitor started", zap.Duration("interval", m.interval))
+	return nil
+}
+
+// Stop stops the background monitoring and waits for a running pass to finish
+func (m *Monitor) Stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	m.logger.Info("egress monitor stopped")
+}
+
+func (m *Monitor) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		if err := m.CheckAll(ctx); err != nil && ctx.Err() == nil {
+			m.logger.Warn("egress monitor pass failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll reads the denied egress of every ready tenant with an egress policy once, and records
+// an event for each tenant whose count grew since the last read
+func (m *Monitor) CheckAll(ctx context.Context) error {
+	reporters := m.reporters()
+	if len(reporters) == 0 {
+		return nil
+	}
+
+	tenants, err := m.tenants.ListTenants(ctx, tenant.ListFilters{Statuses: []tenant.Status{tenant.StatusReady}})
+	if err != nil {
+		return fmt.Errorf("list tenants: %w", err)
+	}
+
+	watched := make(map[uuid.UUID]bool, len(tenants))
+	for _, t := range tenants {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		policy, err := compute.ParseEgressPolicy(t.DesiredConfig)
+		if err != nil || policy == nil {
+			continue
+		}
+
+		denied, ok := m.read(ctx, reporters, t)
+		if !ok {
+			continue
+		}
+		watched[t.ID] = true
+		if grown := m.record(t.ID, denied); grown > 0 {
+			m.recordViolation(ctx, t, policy, grown, denied)
+		}
+	}
+
+	m.deniedMu.Lock()
+	for id := range m.denied {
+		if !watched[id] {
+			delete(m.denied, id)
+		}
+	}
+	m.deniedMu.Unlock()
+	return nil
+}
+
+// reporters returns the registered providers that can report denied egress
+func (m *Monitor) reporters() []compute.EgressReporter {
+	var reporters []compute.EgressReporter
+	for _, name := range m.registry.List() {
+		provider, err := m.registry.Get(name)
+		if err != nil {
+			continue
+		}
+		if reporter, ok := provider.(compute.EgressReporter); ok {
+			reporters = append(reporters, reporter)
+		}
+	}
+	return reporters
+}
+
+// read returns the tenant's denied egress from the first provider enforcing its policy
+func (m *Monitor) read(ctx context.Context, reporters []compute.EgressReporter, t *tenant.Tenant) (int64, bool) {
+	for _, reporter := range reporters {
+		denied, err := reporter.DeniedEgress(ctx, t.ComputeName())
+		if err == nil {
+			return denied, true
+		}
+		if !errors.Is(err, compute.ErrTenantNotFound) {
+			m.logger.Warn("failed to read denied egress", zap.String("tenant_id", t.ID.String()), zap.Error(err))
+		}
+	}
+	return 0, false
+}
+
+// record keeps the tenant's latest count and returns how much it grew. A count lower than the last
+// one means the policy was re-applied and its counters reset.
+func (m *Monitor) record(id uuid.UUID, denied int64) int64 {
+	m.deniedMu.Lock()
+	defer m.deniedMu.Unlock()
+
+	last, seen := m.denied[id]
+	m.denied[id] = denied
+	switch {
+	case !seen:
+		return 0
+	case denied < last:
+		return denied
+	default:
+		return denied - last
+	}
+}
+
+func (m *Monitor) recordViolation(ctx context.Context, t *tenant.Tenant, policy *compute.EgressPolicy, grown, total int64) {
+	message := fmt.Sprintf("Egress policy denied %d outbound packet(s)", grown)
+	transition := tenant.NewStateTransition(t, t.Status, message, Manager).WithReasonCode(tenant.ReasonEgressDenied)
+	transition.DesiredStateSnapshot = map[string]interface{}{compute.EgressConfigKey: policy}
+	transition.ObservedStateSnapshot = map[string]interface{}{
+		"denied_packets":       grown,
+		"denied_packets_total": total,
+	}
+	if err := m.tenants.RecordStateTransition(ctx, transition); err != nil {
+		m.logger.Warn("failed to record egress violation", zap.String("tenant_id", t.ID.String()), zap.Error(err))
+		return
+	}
+	m.logger.Info("tenant egress denied",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("tenant_name", t.Name),
+		zap.Int64("denied_packets", grown))
+}
@@ -0,0 +1,119 @@
+package egress_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	computemock "github.com/jaxxstorm/landlord/internal/compute/providers/mock"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/egress"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+// enforcingProvider is a mock provider that reports the denied egress set on it
+type enforcingProvider struct {
+	*computemock.Provider
+	denied map[string]int64
+}
+
+func (p *enforcingProvider) DeniedEgress(ctx context.Context, tenantID string) (int64, error) {
+	denied, ok := p.denied[tenantID]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", compute.ErrTenantNotFound, tenantID)
+	}
+	return denied, nil
+}
+
+func createTenant(t *testing.T, repo *memory.Repository, name string, desired map[string]interface{}) *tenant.Tenant {
+	t.Helper()
+	now := time.Now()
+	tn := &tenant.Tenant{
+		ID:            uuid.New(),
+		Name:          name,
+		Status:        tenant.StatusReady,
+		DesiredConfig: desired,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Version:       1,
+	}
+	if err := repo.CreateTenant(context.Background(), tn); err != nil {
+		t.Fatalf("CreateTenant() error = %v", err)
+	}
+	return tn
+}
+
+func violations(t *testing.T, repo *memory.Repository, id uuid.UUID) []*tenant.StateTransition {
+	t.Helper()
+	history, err := repo.GetStateHistory(context.Background(), id, tenant.HistoryFilters{})
+	if err != nil {
+		t.Fatalf("GetStateHistory() error = %v", err)
+	}
+	// History is newest first
+	var events []*tenant.StateTransition
+	for _, transition := range history {
+		if transition.TriggeredBy == egress.Manager {
+			events = append(events, transition)
+		}
+	}
+	return events
+}
+
+func TestCheckAllRecordsNewViolations(t *testing.T) {
+	repo := memory.New()
+	ctx := context.Background()
+	provider := &enforcingProvider{Provider: computemock.New(), denied: map[string]int64{}}
+	registry := compute.NewRegistry(zap.NewNop())
+	if err := registry.Register(provider); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	monitor := egress.NewMonitor(repo, registry, config.EgressMonitorConfig{Enabled: true, Interval: time.Minute}, zap.NewNop())
+
+	restricted := createTenant(t, repo, "restricted", map[string]interface{}{
+		"image":                 "nginx:latest",
+		compute.EgressConfigKey: map[string]interface{}{"allow": []interface{}{"10.0.0.0/8"}},
+	})
+	open := createTenant(t, repo, "open", map[string]interface{}{"image": "nginx:latest"})
+	provider.denied["restricted"] = 5
+	provider.denied["open"] = 5
+
+	// The first read is the baseline
+	if err := monitor.CheckAll(ctx); err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+	if events := violations(t, repo, restricted.ID); len(events) != 0 {
+		t.Fatalf("expected no events for the baseline, got %d", len(events))
+	}
+
+	provider.denied["restricted"] = 8
+	if err := monitor.CheckAll(ctx); err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+	if err := monitor.CheckAll(ctx); err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+	events := violations(t, repo, restricted.ID)
+	if len(events) != 1 || events[0].ObservedStateSnapshot["denied_packets"] != int64(3) {
+		t.Fatalf("expected one event for 3 denied packets, got %+v", events)
+	}
+
+	// Re-applying the policy resets its counters
+	provider.denied["restricted"] = 2
+	if err := monitor.CheckAll(ctx); err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+	events = violations(t, repo, restricted.ID)
+	if len(events) != 2 || events[0].ObservedStateSnapshot["denied_packets"] != int64(2) {
+		t.Fatalf("expected an event for 2 denied packets after the reset, got %+v", events)
+	}
+
+	if events := violations(t, repo, open.ID); len(events) != 0 {
+		t.Fatalf("expected tenants without an egress policy to be skipped, got %d events", len(events))
+	}
+}
@@ -0,0 +1,247 @@
+// Package endpointauth generates the credentials that protect a tenant's endpoints. Credentials are
+// derived from a shared secret, the tenant ID and a rotation counter kept in the tenant's desired
+// config, so the API and the workflow worker agree on them without storing them anywhere.
+package endpointauth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+// ConfigKey is the desired_config key that turns on endpoint auth for a tenant
+const ConfigKey = "endpoint_auth"
+
+// Endpoint auth types
+const (
+	TypeBasic = "basic"
+	TypeToken = "token"
+)
+
+// Environment variables the credentials are injected as
+const (
+	EnvUsername = "LANDLORD_AUTH_USERNAME"
+	EnvPassword = "LANDLORD_AUTH_PASSWORD"
+	EnvToken    = "LANDLORD_AUTH_TOKEN"
+)
+
+// Labels added to the tenant's compute config for the ingress in front of it
+const (
+	LabelType        = "landlord.auth.type"
+	LabelRotation    = "landlord.auth.rotation"
+	LabelUsers       = "landlord.auth.users"        // htpasswd line, basic auth
+	LabelTokenSHA256 = "landlord.auth.token-sha256" // hex SHA-256 of the token, token auth
+)
+
+// secretSource is the secret reference source for injected credentials
+const secretSource = "landlord-endpoint-auth"
+
+var validUsername = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Spec turns on endpoint auth in desired_config.endpoint_auth
+type Spec struct {
+	Type     string `json:"type,omitempty"`     // basic or token (default basic)
+	Username string `json:"username,omitempty"` // basic auth username (default from configuration)
+	Rotation int    `json:"rotation,omitempty"` // bumped by POST /v1/tenants/{id}/credentials/rotate
+}
+
+// Credentials are a tenant's current endpoint credentials
+type Credentials struct {
+	Type     string `json:"type"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+	Rotation int    `json:"rotation"`
+}
+
+// Parse extracts and validates the endpoint auth declaration from a desired config.
+// Returns nil when the tenant does not protect its endpoints.
+func Parse(desiredConfig map[string]interface{}) (*Spec, error) {
+	raw, ok := desiredConfig[ConfigKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("encode endpoint auth: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var spec Spec
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("invalid endpoint auth: %w", err)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("endpoint_auth: %w", err)
+	}
+	return &spec, nil
+}
+
+// Validate checks the declaration
+func (s *Spec) Validate() error {
+	switch s.Type {
+	case "", TypeBasic:
+		if s.Username != "" && !validUsername.MatchString(s.Username) {
+			return fmt.Errorf("username must be 1-64 letters, digits, dots, underscores or hyphens")
+		}
+	case TypeToken:
+		if s.Username != "" {
+			return fmt.Errorf("username is only used by basic auth")
+		}
+	default:
+		return fmt.Errorf("type must be %s or %s", TypeBasic, TypeToken)
+	}
+	if s.Rotation < 0 {
+		return fmt.Errorf("rotation must not be negative")
+	}
+	return nil
+}
+
+// Rotate returns a copy of desiredConfig with the endpoint auth rotation bumped, which changes the
+// tenant's credentials on its next workflow. Returns a nil spec when the tenant does not protect its
+// endpoints.
+func Rotate(desiredConfig map[string]interface{}) (map[string]interface{}, *Spec, error) {
+	spec, err := Parse(desiredConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if spec == nil {
+		return nil, nil, nil
+	}
+	spec.Rotation++
+
+	rotated := make(map[string]interface{}, len(desiredConfig))
+	for key, value := range desiredConfig {
+		rotated[key] = value
+	}
+	fields := map[string]interface{}{"rotation": spec.Rotation}
+	if existing, ok := desiredConfig[ConfigKey].(map[string]interface{}); ok {
+		for key, value := range existing {
+			if key != "rotation" {
+				fields[key] = value
+			}
+		}
+	}
+	rotated[ConfigKey] = fields
+	return rotated, spec, nil
+}
+
+// Generator derives tenant credentials from the configured secret
+type Generator struct {
+	secret        []byte
+	username      string
+	traefikLabels bool
+}
+
+// New creates a credential generator
+func New(cfg config.EndpointAuthConfig) *Generator {
+	return &Generator{
+		secret:        []byte(cfg.Secret),
+		username:      cfg.Username,
+		traefikLabels: cfg.TraefikLabels,
+	}
+}
+
+// Credentials returns the credentials for a tenant, identified by its UUID
+func (g *Generator) Credentials(tenantUUID string, spec *Spec) *Credentials {
+	creds := &Credentials{Type: spec.Type, Rotation: spec.Rotation}
+	if creds.Type == "" {
+		creds.Type = TypeBasic
+	}
+
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write([]byte("endpoint-auth\x00" + tenantUUID + "\x00" + creds.Type + "\x00" + strconv.Itoa(spec.Rotation)))
+	value := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+
+	if creds.Type == TypeToken {
+		creds.Token = value
+		return creds
+	}
+	creds.Username = spec.Username
+	if creds.Username == "" {
+		creds.Username = g.username
+	}
+	creds.Password = value
+	return creds
+}
+
+// Inject returns desiredConfig with the tenant's credentials added to its env and to labels for the
+// ingress, plus secret references for the compute spec. desiredConfig is returned unchanged when the
+// tenant does not protect its endpoints.
+func (g *Generator) Inject(tenantUUID, computeName string, desiredConfig map[string]interface{}) (map[string]interface{}, []compute.SecretReference, error) {
+	spec, err := Parse(desiredConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	if spec == nil {
+		return desiredConfig, nil, nil
+	}
+	if tenantUUID == "" {
+		return nil, nil, fmt.Errorf("tenant UUID is required for endpoint auth")
+	}
+	creds := g.Credentials(tenantUUID, spec)
+
+	env := copyMap(desiredConfig["env"])
+	labels := copyMap(desiredConfig["labels"])
+	labels[LabelType] = creds.Type
+	labels[LabelRotation] = strconv.Itoa(creds.Rotation)
+
+	var refs []compute.SecretReference
+	if creds.Type == TypeToken {
+		env[EnvToken] = creds.Token
+		sum := sha256.Sum256([]byte(creds.Token))
+		labels[LabelTokenSHA256] = hex.EncodeToString(sum[:])
+		refs = append(refs, compute.SecretReference{Name: "endpoint-token", Source: secretSource, Key: "token", EnvVar: EnvToken})
+	} else {
+		env[EnvUsername] = creds.Username
+		env[EnvPassword] = creds.Password
+		users := Htpasswd(creds.Username, creds.Password)
+		labels[LabelUsers] = users
+		if g.traefikLabels {
+			labels["traefik.http.middlewares."+computeName+"-auth.basicauth.users"] = users
+		}
+		refs = append(refs, compute.SecretReference{Name: "endpoint-password", Source: secretSource, Key: "password", EnvVar: EnvPassword})
+	}
+
+	injected := make(map[string]interface{}, len(desiredConfig)+2)
+	for key, value := range desiredConfig {
+		injected[key] = value
+	}
+	injected["env"] = env
+	injected["labels"] = labels
+	return injected, refs, nil
+}
+
+// Htpasswd returns an htpasswd line for the credentials, using the {SHA} scheme most proxies accept
+func Htpasswd(username, password string) string {
+	sum := sha1.Sum([]byte(password))
+	return username + ":{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func copyMap(raw interface{}) map[string]interface{} {
+	copied := make(map[string]interface{})
+	switch existing := raw.(type) {
+	case map[string]interface{}:
+		for key, value := range existing {
+			copied[key] = value
+		}
+	case map[string]string:
+		for key, value := range existing {
+			copied[key] = value
+		}
+	}
+	return copied
+}
@@ -0,0 +1,119 @@
+package endpointauth
+
+import (
+	"testing"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+func newGenerator(traefik bool) *Generator {
+	return New(config.EndpointAuthConfig{Enabled: true, Secret: "0123456789abcdef", Username: "landlord", TraefikLabels: traefik})
+}
+
+func TestParseRejectsInvalidSpecs(t *testing.T) {
+	cases := map[string]interface{}{
+		"unknown type":      map[string]interface{}{"type": "oidc"},
+		"bad username":      map[string]interface{}{"username": "no spaces"},
+		"token username":    map[string]interface{}{"type": "token", "username": "preview"},
+		"negative rotation": map[string]interface{}{"rotation": -1},
+		"unknown field":     map[string]interface{}{"password": "hunter2"},
+		"not an object":     "basic",
+	}
+
+	for name, spec := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse(map[string]interface{}{ConfigKey: spec}); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+
+	if spec, err := Parse(map[string]interface{}{"image": "nginx:latest"}); err != nil || spec != nil {
+		t.Fatalf("expected no spec, got %+v, %v", spec, err)
+	}
+}
+
+func TestCredentialsChangeOnlyWhenRotated(t *testing.T) {
+	g := newGenerator(false)
+
+	first := g.Credentials("tenant-a", &Spec{})
+	if first.Type != TypeBasic || first.Username != "landlord" || len(first.Password) < 32 {
+		t.Fatalf("unexpected credentials %+v", first)
+	}
+	if again := g.Credentials("tenant-a", &Spec{}); again.Password != first.Password {
+		t.Fatal("expected credentials to be stable")
+	}
+	if other := g.Credentials("tenant-b", &Spec{}); other.Password == first.Password {
+		t.Fatal("expected tenants to get different credentials")
+	}
+	if rotated := g.Credentials("tenant-a", &Spec{Rotation: 1}); rotated.Password == first.Password {
+		t.Fatal("expected rotation to change the credentials")
+	}
+
+	token := g.Credentials("tenant-a", &Spec{Type: TypeToken})
+	if token.Token == "" || token.Password != "" || token.Username != "" {
+		t.Fatalf("unexpected token credentials %+v", token)
+	}
+}
+
+func TestInjectAddsEnvAndIngressLabels(t *testing.T) {
+	g := newGenerator(true)
+	desired := map[string]interface{}{
+		"image":   "nginx:latest",
+		"env":     map[string]interface{}{"MODE": "preview"},
+		"labels":  map[string]interface{}{"team": "web"},
+		ConfigKey: map[string]interface{}{"username": "preview"},
+	}
+
+	injected, refs, err := g.Inject("tenant-a", "acme", desired)
+	if err != nil {
+		t.Fatalf("Inject() error = %v", err)
+	}
+	creds := g.Credentials("tenant-a", &Spec{Username: "preview"})
+
+	env := injected["env"].(map[string]interface{})
+	if env["MODE"] != "preview" || env[EnvUsername] != "preview" || env[EnvPassword] != creds.Password {
+		t.Fatalf("unexpected env %+v", env)
+	}
+	labels := injected["labels"].(map[string]interface{})
+	users := Htpasswd("preview", creds.Password)
+	if labels["team"] != "web" || labels[LabelUsers] != users || labels["traefik.http.middlewares.acme-auth.basicauth.users"] != users {
+		t.Fatalf("unexpected labels %+v", labels)
+	}
+	if len(refs) != 1 || refs[0].EnvVar != EnvPassword {
+		t.Fatalf("unexpected secret references %+v", refs)
+	}
+	if _, ok := desired["env"].(map[string]interface{})[EnvPassword]; ok {
+		t.Fatal("expected the desired config to be left unchanged")
+	}
+
+	if unchanged, refs, err := g.Inject("tenant-a", "acme", map[string]interface{}{"image": "nginx:latest"}); err != nil || refs != nil || unchanged["env"] != nil {
+		t.Fatalf("expected tenants without endpoint auth to be left alone, got %+v, %+v, %v", unchanged, refs, err)
+	}
+}
+
+func TestRotateBumpsRotation(t *testing.T) {
+	desired := map[string]interface{}{
+		"image":   "nginx:latest",
+		ConfigKey: map[string]interface{}{"type": "token", "rotation": float64(2)},
+	}
+
+	rotated, spec, err := Rotate(desired)
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if spec.Rotation != 3 || spec.Type != TypeToken {
+		t.Fatalf("unexpected spec %+v", spec)
+	}
+	fields := rotated[ConfigKey].(map[string]interface{})
+	if fields["rotation"] != 3 || fields["type"] != TypeToken {
+		t.Fatalf("unexpected endpoint auth %+v", fields)
+	}
+	if desired[ConfigKey].(map[string]interface{})["rotation"] != float64(2) {
+		t.Fatal("expected the desired config to be left unchanged")
+	}
+
+	if _, spec, err := Rotate(map[string]interface{}{"image": "nginx:latest"}); err != nil || spec != nil {
+		t.Fatalf("expected no spec without endpoint auth, got %+v, %v", spec, err)
+	}
+}
@@ -0,0 +1,86 @@
+// Package execution records the steps of workflow executions. Workers report each step's
+// start and finish as they run a tenant workflow, so a running or failed execution shows
+// where it is rather than only its final outcome.
+package execution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// ErrNotFound is returned when no steps are recorded for an execution
+var ErrNotFound = errors.New("execution not found")
+
+// Step is one step of a workflow execution
+type Step struct {
+	ExecutionID string              `json:"execution_id"`
+	TenantID    uuid.UUID           `json:"tenant_id"`
+	Name        string              `json:"name"`
+	Status      workflow.StepStatus `json:"status"`
+
+	// Attempts counts how many times the step started; Restate retries an execution from its first step
+	Attempts int `json:"attempts"`
+
+	// Error is why the step's latest attempt failed
+	Error string `json:"error,omitempty"`
+
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Duration is how long the step's latest attempt ran, or has been running as of now
+func (s *Step) Duration(now time.Time) time.Duration {
+	if s.FinishedAt != nil {
+		return s.FinishedAt.Sub(s.StartedAt)
+	}
+	return now.Sub(s.StartedAt)
+}
+
+// Store persists execution steps
+type Store interface {
+	// RecordStep saves a step's start or finish. A step is keyed by its execution and name;
+	// starting a recorded step again counts another attempt.
+	RecordStep(ctx context.Context, s *Step) error
+
+	// ListSteps returns an execution's steps in the order they first started
+	// Returns ErrNotFound if the execution has no steps
+	ListSteps(ctx context.Context, executionID string) ([]*Step, error)
+
+	// ListTenantSteps returns the steps of every execution of a tenant, in the order they first started
+	ListTenantSteps(ctx context.Context, tenantID uuid.UUID) ([]*Step, error)
+}
+
+// Reporter records the steps workers report straight into a store, for workers with database access
+type Reporter struct {
+	store Store
+}
+
+var _ workflow.StepReporter = (*Reporter)(nil)
+
+// NewReporter creates a reporter that records steps in store
+func NewReporter(store Store) *Reporter {
+	return &Reporter{store: store}
+}
+
+// ReportStep records report. Workers report tenants by UUID.
+func (r *Reporter) ReportStep(ctx context.Context, report workflow.StepReport) error {
+	tenantID, err := uuid.Parse(report.TenantID)
+	if err != nil {
+		return fmt.Errorf("tenant ID must be a UUID: %w", err)
+	}
+	return r.store.RecordStep(ctx, &Step{
+		ExecutionID: report.ExecutionID,
+		TenantID:    tenantID,
+		Name:        report.Step,
+		Status:      report.Status,
+		Error:       report.Error,
+		StartedAt:   report.StartedAt,
+		FinishedAt:  report.FinishedAt,
+	})
+}
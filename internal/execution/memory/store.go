@@ -0,0 +1,84 @@
+// Package memory provides an in-memory execution step store for tests and local harnesses.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/execution"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// Store implements execution.Store in memory.
+// Steps are copied on the way in and out, so callers never share state with the store.
+type Store struct {
+	mu         sync.RWMutex
+	executions map[string][]execution.Step
+}
+
+var _ execution.Store = (*Store)(nil)
+
+// New creates an empty in-memory store
+func New() *Store {
+	return &Store{executions: make(map[string][]execution.Step)}
+}
+
+func (s *Store) RecordStep(ctx context.Context, step *execution.Step) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	steps := s.executions[step.ExecutionID]
+	for i := range steps {
+		if steps[i].Name != step.Name {
+			continue
+		}
+		attempts := steps[i].Attempts
+		if step.Status == workflow.StepRunning {
+			attempts++
+		}
+		steps[i] = *step
+		steps[i].Attempts = attempts
+		step.Attempts = attempts
+		return nil
+	}
+
+	step.Attempts = 1
+	s.executions[step.ExecutionID] = append(steps, *step)
+	return nil
+}
+
+func (s *Store) ListSteps(ctx context.Context, executionID string) ([]*execution.Step, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	recorded, ok := s.executions[executionID]
+	if !ok {
+		return nil, execution.ErrNotFound
+	}
+	steps := make([]*execution.Step, 0, len(recorded))
+	for _, step := range recorded {
+		step := step
+		steps = append(steps, &step)
+	}
+	return steps, nil
+}
+
+func (s *Store) ListTenantSteps(ctx context.Context, tenantID uuid.UUID) ([]*execution.Step, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	steps := []*execution.Step{}
+	for _, recorded := range s.executions {
+		for _, step := range recorded {
+			if step.TenantID == tenantID {
+				step := step
+				steps = append(steps, &step)
+			}
+		}
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].StartedAt.Before(steps[j].StartedAt) })
+	return steps, nil
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/execution"
+)
+
+// Store implements execution.Store for PostgreSQL
+type Store struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+var _ execution.Store = (*Store)(nil)
+
+// New creates a PostgreSQL execution step store
+// Accepts interface{} to satisfy provider abstraction, type asserts to *pgxpool.Pool
+func New(pool interface{}, logger *zap.Logger) (*Store, error) {
+	pgPool, ok := pool.(*pgxpool.Pool)
+	if !ok {
+		return nil, fmt.Errorf("expected *pgxpool.Pool, got %T", pool)
+	}
+	return &Store{
+		pool:   pgPool,
+		logger: logger.With(zap.String("component", "execution-postgres-store")),
+	}, nil
+}
+
+// A step started again counts another attempt; finishing it keeps the count
+const recordStepQuery = `
+INSERT INTO execution_steps (execution_id, tenant_id, name, status, error, started_at, finished_at)
+VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+ON CONFLICT (execution_id, name) DO UPDATE
+SET status = EXCLUDED.status,
+    error = EXCLUDED.error,
+    started_at = EXCLUDED.started_at,
+    finished_at = EXCLUDED.finished_at,
+    attempts = execution_steps.attempts + CASE WHEN EXCLUDED.status = 'running' THEN 1 ELSE 0 END
+RETURNING attempts
+`
+
+func (s *Store) RecordStep(ctx context.Context, step *execution.Step) error {
+	if err := s.pool.QueryRow(ctx, recordStepQuery,
+		step.ExecutionID,
+		step.TenantID,
+		step.Name,
+		step.Status,
+		step.Error,
+		step.StartedAt,
+		step.FinishedAt,
+	).Scan(&step.Attempts); err != nil {
+		return fmt.Errorf("record execution step: %w", err)
+	}
+	return nil
+}
+
+const listStepsQuery = `
+SELECT execution_id, tenant_id, name, status, attempts, COALESCE(error, ''), started_at, finished_at
+FROM execution_steps
+WHERE execution_id = $1
+ORDER BY id
+`
+
+func (s *Store) ListSteps(ctx context.Context, executionID string) ([]*execution.Step, error) {
+	rows, err := s.pool.Query(ctx, listStepsQuery, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("list execution steps: %w", err)
+	}
+	defer rows.Close()
+
+	steps := []*execution.Step{}
+	for rows.Next() {
+		step := &execution.Step{}
+		if err := rows.Scan(&step.ExecutionID, &step.TenantID, &step.Name, &step.Status, &step.Attempts, &step.Error, &step.StartedAt, &step.FinishedAt); err != nil {
+			return nil, fmt.Errorf("scan execution step: %w", err)
+		}
+		steps = append(steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate execution steps: %w", err)
+	}
+	if len(steps) == 0 {
+		return nil, execution.ErrNotFound
+	}
+	return steps, nil
+}
+
+const listTenantStepsQuery = `
+SELECT execution_id, tenant_id, name, status, attempts, COALESCE(error, ''), started_at, finished_at
+FROM execution_steps
+WHERE tenant_id = $1
+ORDER BY id
+`
+
+func (s *Store) ListTenantSteps(ctx context.Context, tenantID uuid.UUID) ([]*execution.Step, error) {
+	rows, err := s.pool.Query(ctx, listTenantStepsQuery, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list tenant execution steps: %w", err)
+	}
+	defer rows.Close()
+
+	steps := []*execution.Step{}
+	for rows.Next() {
+		step := &execution.Step{}
+		if err := rows.Scan(&step.ExecutionID, &step.TenantID, &step.Name, &step.Status, &step.Attempts, &step.Error, &step.StartedAt, &step.FinishedAt); err != nil {
+			return nil, fmt.Errorf("scan execution step: %w", err)
+		}
+		steps = append(steps, step)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate execution steps: %w", err)
+	}
+	return steps, nil
+}
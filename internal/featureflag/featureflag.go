@@ -0,0 +1,231 @@
+// Package featureflag parses the feature flags tenants set with landlord.io/flags/<name> annotations.
+// Flags are declared in a registry, the built-in ones and those the configuration adds, so a
+// misspelt flag or a value of the wrong type is rejected instead of silently ignored. The controller
+// passes a tenant's flags to workflows in the provision request's metadata, where workflows and the
+// compute providers they call can branch on them.
+package featureflag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+// AnnotationPrefix prefixes the annotations that set feature flags, such as landlord.io/flags/skip-health-check
+const AnnotationPrefix = "landlord.io/flags/"
+
+// MetadataKey is the provision request metadata entry holding a tenant's flags, encoded as JSON
+const MetadataKey = "feature_flags"
+
+// Type is the type of a flag's value
+type Type string
+
+// Flag value types
+const (
+	TypeBool   Type = "bool"
+	TypeString Type = "string"
+	TypeInt    Type = "int"
+)
+
+// Types are the flag value types, in the order they are documented
+var Types = []Type{TypeBool, TypeString, TypeInt}
+
+// Definition declares a flag tenants may set
+type Definition struct {
+	Name        string `json:"name"`
+	Type        Type   `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+// Built-in flags
+const (
+	// SkipHealthCheck skips the readiness probes after provision and update, so a tenant whose probe
+	// cannot pass yet is still reported ready
+	SkipHealthCheck = "skip-health-check"
+)
+
+// Builtin are the flags every registry declares
+var Builtin = []Definition{
+	{Name: SkipHealthCheck, Type: TypeBool, Description: "Skip the readiness probes after provision and update"},
+}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// Registry holds the flags tenants may set. A nil Registry declares only the built-in flags.
+type Registry struct {
+	definitions map[string]Definition
+}
+
+// NewRegistry creates a registry of the built-in flags and those cfg declares
+func NewRegistry(cfg config.FeatureFlagsConfig) (*Registry, error) {
+	r := &Registry{definitions: make(map[string]Definition, len(Builtin)+len(cfg.Flags))}
+	for _, def := range Builtin {
+		r.definitions[def.Name] = def
+	}
+	for _, flag := range cfg.Flags {
+		if _, ok := r.definitions[flag.Name]; ok {
+			return nil, fmt.Errorf("feature flag %q is already declared", flag.Name)
+		}
+		def := Definition{Name: flag.Name, Type: Type(flag.Type), Description: flag.Description}
+		if err := def.validate(); err != nil {
+			return nil, err
+		}
+		r.definitions[def.Name] = def
+	}
+	return r, nil
+}
+
+func (d Definition) validate() error {
+	if !namePattern.MatchString(d.Name) {
+		return fmt.Errorf("feature flag name %q must be lowercase letters, digits and dashes", d.Name)
+	}
+	for _, typ := range Types {
+		if d.Type == typ {
+			return nil
+		}
+	}
+	return fmt.Errorf("feature flag %q: unknown type %q", d.Name, d.Type)
+}
+
+// Definitions returns the declared flags, sorted by name
+func (r *Registry) Definitions() []Definition {
+	if r == nil {
+		return append([]Definition(nil), Builtin...)
+	}
+	defs := make([]Definition, 0, len(r.definitions))
+	for _, def := range r.definitions {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
+
+func (r *Registry) lookup(name string) (Definition, bool) {
+	if r == nil {
+		for _, def := range Builtin {
+			if def.Name == name {
+				return def, true
+			}
+		}
+		return Definition{}, false
+	}
+	def, ok := r.definitions[name]
+	return def, ok
+}
+
+// Parse reads the flags set in annotations. Annotations naming undeclared flags, or holding values
+// that are not of the flag's type, are reported together in the error; the flags returned hold the
+// valid ones, so callers that cannot reject the tenant can still use them.
+func (r *Registry) Parse(annotations map[string]string) (FeatureFlags, error) {
+	var flags FeatureFlags
+	var errs []error
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		if strings.HasPrefix(key, AnnotationPrefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, AnnotationPrefix)
+		def, ok := r.lookup(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown feature flag %q", key, name))
+			continue
+		}
+		value, err := canonical(def.Type, annotations[key])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		flags.set(name, value)
+	}
+	return flags, errors.Join(errs...)
+}
+
+// canonical checks raw is a value of typ and returns it in canonical form
+func canonical(typ Type, raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	switch typ {
+	case TypeBool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", fmt.Errorf("expected true or false, got %q", raw)
+		}
+		return strconv.FormatBool(value), nil
+	case TypeInt:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("expected an integer, got %q", raw)
+		}
+		return strconv.FormatInt(value, 10), nil
+	default:
+		return raw, nil
+	}
+}
+
+// FeatureFlags are the flags set on a tenant. Built-in flags have fields of their own; flags the
+// configuration declares are read with Bool, Int and String.
+type FeatureFlags struct {
+	// SkipHealthCheck is the skip-health-check flag
+	SkipHealthCheck bool `json:"skip_health_check,omitempty"`
+
+	// Values holds every flag set, built-in or not, keyed by name in canonical form
+	Values map[string]string `json:"values,omitempty"`
+}
+
+func (f *FeatureFlags) set(name, value string) {
+	if f.Values == nil {
+		f.Values = make(map[string]string)
+	}
+	f.Values[name] = value
+	if name == SkipHealthCheck {
+		f.SkipHealthCheck = value == "true"
+	}
+}
+
+// IsZero reports whether no flags are set
+func (f FeatureFlags) IsZero() bool {
+	return len(f.Values) == 0
+}
+
+// Bool returns the named boolean flag, false when it is not set
+func (f FeatureFlags) Bool(name string) bool {
+	return f.Values[name] == "true"
+}
+
+// Int returns the named integer flag, 0 when it is not set
+func (f FeatureFlags) Int(name string) int64 {
+	value, _ := strconv.ParseInt(f.Values[name], 10, 64)
+	return value
+}
+
+// String returns the named flag, "" when it is not set
+func (f FeatureFlags) String(name string) string {
+	return f.Values[name]
+}
+
+// Encode returns the flags as they are carried in MetadataKey
+func (f FeatureFlags) Encode() string {
+	data, _ := json.Marshal(f)
+	return string(data)
+}
+
+// FromMetadata returns the flags carried in a provision request's metadata. Requests from servers
+// that predate flags, or whose entry cannot be decoded, have none.
+func FromMetadata(metadata map[string]string) FeatureFlags {
+	var flags FeatureFlags
+	if raw := metadata[MetadataKey]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &flags); err != nil {
+			return FeatureFlags{}
+		}
+	}
+	return flags
+}
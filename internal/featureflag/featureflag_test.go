@@ -0,0 +1,74 @@
+package featureflag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+func TestParse(t *testing.T) {
+	registry, err := NewRegistry(config.FeatureFlagsConfig{Flags: []config.FeatureFlagConfig{
+		{Name: "experimental-network", Type: "bool"},
+		{Name: "boot-timeout", Type: "int"},
+		{Name: "network-mode", Type: "string"},
+	}})
+	if err != nil {
+		t.Fatalf("new registry: %v", err)
+	}
+
+	flags, err := registry.Parse(map[string]string{
+		"landlord.io/flags/skip-health-check":    "TRUE",
+		"landlord.io/flags/experimental-network": "1",
+		"landlord.io/flags/boot-timeout":         " 90 ",
+		"landlord.io/flags/network-mode":         "bridge",
+		"team":                                   "payments",
+	})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !flags.SkipHealthCheck || !flags.Bool("experimental-network") || flags.Int("boot-timeout") != 90 || flags.String("network-mode") != "bridge" {
+		t.Errorf("unexpected flags %+v", flags)
+	}
+	if flags.Values[SkipHealthCheck] != "true" {
+		t.Errorf("expected values in canonical form, got %+v", flags.Values)
+	}
+
+	decoded := FromMetadata(map[string]string{MetadataKey: flags.Encode()})
+	if !decoded.SkipHealthCheck || decoded.Int("boot-timeout") != 90 || len(decoded.Values) != 4 {
+		t.Errorf("expected the flags to round trip through metadata, got %+v", decoded)
+	}
+	if !FromMetadata(map[string]string{MetadataKey: "{"}).IsZero() || !FromMetadata(nil).IsZero() {
+		t.Error("expected no flags from missing or invalid metadata")
+	}
+}
+
+func TestParseRejectsInvalidFlags(t *testing.T) {
+	var registry *Registry
+	flags, err := registry.Parse(map[string]string{
+		"landlord.io/flags/skip-health-check":    "true",
+		"landlord.io/flags/experimental-network": "true",
+	})
+	if err == nil || !strings.Contains(err.Error(), `unknown feature flag "experimental-network"`) {
+		t.Fatalf("expected an unknown flag error, got %v", err)
+	}
+	if !flags.SkipHealthCheck {
+		t.Error("expected the valid flags to be returned with the error")
+	}
+
+	if _, err := registry.Parse(map[string]string{"landlord.io/flags/skip-health-check": "sometimes"}); err == nil {
+		t.Error("expected a value of the wrong type to be rejected")
+	}
+}
+
+func TestNewRegistryRejectsInvalidDefinitions(t *testing.T) {
+	for name, flag := range map[string]config.FeatureFlagConfig{
+		"builtin": {Name: SkipHealthCheck, Type: "bool"},
+		"name":    {Name: "Fast_Boot", Type: "bool"},
+		"type":    {Name: "fast-boot", Type: "float"},
+	} {
+		if _, err := NewRegistry(config.FeatureFlagsConfig{Flags: []config.FeatureFlagConfig{flag}}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Package housekeeping deletes operational rows once they are no longer needed: published tenant
+// outbox entries, delivered compute callbacks, and the entries and callbacks that were given up on.
+// Rows are deleted in small batches so no delete holds locks or leaves dead rows for long.
+package housekeeping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+const (
+	// KindPublishedOutbox is published tenant outbox entries
+	KindPublishedOutbox = "published_outbox"
+
+	// KindFailedOutbox is tenant outbox entries whose dispatcher gave up on them
+	KindFailedOutbox = "failed_outbox"
+
+	// KindDeliveredCallbacks is delivered compute callbacks
+	KindDeliveredCallbacks = "delivered_callbacks"
+
+	// KindFailedCallbacks is compute callbacks whose dispatcher gave up on them
+	KindFailedCallbacks = "failed_callbacks"
+)
+
+// Tables are the stores housekeeping deletes from. A nil store is skipped.
+type Tables struct {
+	// Outbox is the tenant outbox, and OutboxMaxAttempts the publishes after which the controller
+	// gives up on an entry
+	Outbox            tenant.OutboxCollector
+	OutboxMaxAttempts int
+
+	// Callbacks is the compute callback queue, and CallbackMaxAttempts the deliveries after which
+	// the worker gives up on a callback
+	Callbacks           compute.CallbackCollector
+	CallbackMaxAttempts int
+}
+
+// sweep deletes one kind of row once it is older than after
+type sweep struct {
+	kind   string
+	after  time.Duration
+	delete func(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+// Collector periodically deletes old operational rows
+type Collector struct {
+	sweeps     []sweep
+	interval   time.Duration
+	batchSize  int
+	batchPause time.Duration
+	logger     *zap.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewCollector creates a collector. Kinds whose retention is 0 are kept.
+func NewCollector(tables Tables, cfg config.HousekeepingConfig, logger *zap.Logger) *Collector {
+	c := &Collector{
+		interval:   cfg.Interval,
+		batchSize:  cfg.BatchSize,
+		batchPause: cfg.BatchPause,
+		logger:     logger.With(zap.String("component", "housekeeping")),
+	}
+	if outbox := tables.Outbox; outbox != nil {
+		c.add(KindPublishedOutbox, cfg.PublishedOutboxAfter, outbox.DeletePublishedOutboxEntries)
+		c.add(KindFailedOutbox, cfg.FailedAfter, func(ctx context.Context, before time.Time, limit int) (int, error) {
+			return outbox.DeleteFailedOutboxEntries(ctx, before, tables.OutboxMaxAttempts, limit)
+		})
+	}
+	if callbacks := tables.Callbacks; callbacks != nil {
+		c.add(KindDeliveredCallbacks, cfg.DeliveredCallbacksAfter, callbacks.DeleteDeliveredCallbacks)
+		c.add(KindFailedCallbacks, cfg.FailedAfter, func(ctx context.Context, before time.Time, limit int) (int, error) {
+			return callbacks.DeleteFailedCallbacks(ctx, before, tables.CallbackMaxAttempts, limit)
+		})
+	}
+	return c
+}
+
+func (c *Collector) add(kind string, after time.Duration, del func(ctx context.Context, before time.Time, limit int) (int, error)) {
+	if after > 0 {
+		c.sweeps = append(c.sweeps, sweep{kind: kind, after: after, delete: del})
+	}
+}
+
+// Start deletes old rows in the background until Stop is called
+func (c *Collector) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.interval <= 0 {
+		return fmt.Errorf("housekeeping interval must be positive")
+	}
+	if c.batchSize < 1 {
+		return fmt.Errorf("housekeeping batch size must be at least 1")
+	}
+	if c.cancel != nil {
+		return fmt.Errorf("housekeeping already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.run(ctx, c.done)
+
+	kinds := make([]string, 0, len(c.sweeps))
+	for _, s := range c.sweeps {
+		kinds = append(kinds, s.kind)
+	}
+	c.logger.Info("housekeeping started", zap.Duration("interval", c.interval), zap.Strings("kinds", kinds))
+	return nil
+}
+
+// Stop stops the background deletes and waits for a running pass to finish
+func (c *Collector) Stop() {
+	c.mu.Lock()
+	cancel, done := c.cancel, c.done
+	c.cancel, c.done = nil, nil
+	c.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	c.logger.Info("housekeeping stopped")
+}
+
+func (c *Collector) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if err := c.CollectAll(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Warn("housekeeping pass failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CollectAll deletes every row older than its kind's retention. A kind that fails does not stop
+// the others.
+func (c *Collector) CollectAll(ctx context.Context) error {
+	now := time.Now()
+	var errs []error
+	for _, s := range c.sweeps {
+		deleted, err := c.collect(ctx, s, now.Add(-s.after))
+		if deleted > 0 {
+			c.logger.Info("deleted old rows", zap.String("kind", s.kind), zap.Int("rows", deleted))
+		}
+		if err != nil {
+			metrics.errors.Add(1)
+			errs = append(errs, fmt.Errorf("delete %s: %w", s.kind, err))
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	metrics.passes.Add(1)
+	metrics.lastPassUnix.Set(now.Unix())
+	return errors.Join(errs...)
+}
+
+// collect deletes s's rows older than before one batch at a time, pausing between batches, until
+// a batch comes back short
+func (c *Collector) collect(ctx context.Context, s sweep, before time.Time) (int, error) {
+	total := 0
+	for {
+		deleted, err := s.delete(ctx, before, c.batchSize)
+		total += deleted
+		metrics.vars.Add(s.kind+"_deleted_total", int64(deleted))
+		if err != nil || deleted < c.batchSize {
+			return total, err
+		}
+		if c.batchPause <= 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return total, ctx.Err()
+		case <-time.After(c.batchPause):
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package housekeeping_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	callbackmemory "github.com/jaxxstorm/landlord/internal/compute/callbackqueue/memory"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/housekeeping"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func housekeepingConfig() config.HousekeepingConfig {
+	return config.HousekeepingConfig{
+		Enabled:                 true,
+		Interval:                time.Hour,
+		BatchSize:               1,
+		PublishedOutboxAfter:    time.Millisecond,
+		DeliveredCallbacksAfter: time.Millisecond,
+		FailedAfter:             time.Millisecond,
+	}
+}
+
+// queueOutboxEntries adds one outbox entry per key to a new tenant
+func queueOutboxEntries(t *testing.T, repo *memory.Repository, keys ...string) {
+	t.Helper()
+	ctx := context.Background()
+	tn := &tenant.Tenant{ID: uuid.New(), Name: "acme-" + uuid.NewString()[:8], Status: tenant.StatusReady}
+	require.NoError(t, repo.CreateTenant(ctx, tn))
+	entries := make([]*tenant.OutboxEntry, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, &tenant.OutboxEntry{TenantID: tn.ID, Kind: "workflow.trigger", DedupKey: key})
+	}
+	require.NoError(t, repo.UpdateTenantWithOutbox(ctx, tn, entries...))
+}
+
+func TestCollectAllDeletesOldOutboxEntries(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	queueOutboxEntries(t, repo, "published-1", "published-2", "failed")
+
+	// Publish two entries and give up on the third after one attempt
+	claimed, err := repo.ClaimOutboxEntries(ctx, 10, time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 3)
+	for _, e := range claimed {
+		if e.DedupKey == "failed" {
+			require.NoError(t, repo.FailOutboxEntry(ctx, e.ID, e.ClaimToken, "engine down", time.Now()))
+			continue
+		}
+		require.NoError(t, repo.CompleteOutboxEntry(ctx, e.ID, e.ClaimToken))
+	}
+	// Entries still being published or retried are kept
+	queueOutboxEntries(t, repo, "pending", "claimed")
+	claimed, err = repo.ClaimOutboxEntries(ctx, 1, time.Minute, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	time.Sleep(5 * time.Millisecond)
+	published := housekeeping.Metrics().Deleted(housekeeping.KindPublishedOutbox)
+	failed := housekeeping.Metrics().Deleted(housekeeping.KindFailedOutbox)
+	collector := housekeeping.NewCollector(housekeeping.Tables{Outbox: repo, OutboxMaxAttempts: 1}, housekeepingConfig(), zap.NewNop())
+	require.NoError(t, collector.CollectAll(ctx))
+
+	var kept []string
+	for _, e := range repo.OutboxEntries() {
+		kept = append(kept, e.DedupKey)
+	}
+	require.Equal(t, []string{"pending", "claimed"}, kept)
+	require.Equal(t, published+2, housekeeping.Metrics().Deleted(housekeeping.KindPublishedOutbox))
+	require.Equal(t, failed+1, housekeeping.Metrics().Deleted(housekeeping.KindFailedOutbox))
+}
+
+func TestCollectAllDeletesOldCallbacks(t *testing.T) {
+	ctx := context.Background()
+	queue := callbackmemory.New()
+	for _, id := range []string{"delivered", "failed", "retrying"} {
+		require.NoError(t, queue.EnqueueCallback(ctx, &compute.QueuedCallback{ExecutionID: id, Provider: "restate", Payload: &compute.CallbackPayload{}}))
+	}
+	claimed, err := queue.ClaimCallbacks(ctx, []string{"restate"}, 10, time.Minute, 2)
+	require.NoError(t, err)
+	require.Len(t, claimed, 3)
+	for _, cb := range claimed {
+		if cb.ExecutionID == "delivered" {
+			require.NoError(t, queue.CompleteCallback(ctx, cb.ExecutionID, cb.ClaimToken))
+			continue
+		}
+		require.NoError(t, queue.FailCallback(ctx, cb.ExecutionID, cb.ClaimToken, "engine down", time.Now()))
+	}
+	// One more attempt uses up the failed callback's deliveries; the other still has one left
+	claimed, err = queue.ClaimCallbacks(ctx, []string{"restate"}, 1, time.Minute, 2)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.NoError(t, queue.FailCallback(ctx, claimed[0].ExecutionID, claimed[0].ClaimToken, "engine down", time.Now()))
+	exhausted := claimed[0].ExecutionID
+
+	time.Sleep(5 * time.Millisecond)
+	cfg := housekeepingConfig()
+	cfg.BatchSize = 10
+	collector := housekeeping.NewCollector(housekeeping.Tables{Callbacks: queue, CallbackMaxAttempts: 2}, cfg, zap.NewNop())
+	require.NoError(t, collector.CollectAll(ctx))
+
+	callbacks := queue.Callbacks()
+	require.Len(t, callbacks, 1)
+	require.NotEqual(t, exhausted, callbacks[0].ExecutionID)
+	require.NotEqual(t, "delivered", callbacks[0].ExecutionID)
+}
+
+func TestCollectAllKeepsRowsWithoutRetention(t *testing.T) {
+	ctx := context.Background()
+	queue := callbackmemory.New()
+	require.NoError(t, queue.EnqueueCallback(ctx, &compute.QueuedCallback{ExecutionID: "delivered", Provider: "restate", Payload: &compute.CallbackPayload{}}))
+	claimed, err := queue.ClaimCallbacks(ctx, []string{"restate"}, 1, time.Minute, 1)
+	require.NoError(t, err)
+	require.NoError(t, queue.CompleteCallback(ctx, claimed[0].ExecutionID, claimed[0].ClaimToken))
+
+	time.Sleep(5 * time.Millisecond)
+	cfg := housekeepingConfig()
+	cfg.DeliveredCallbacksAfter = 0
+	collector := housekeeping.NewCollector(housekeeping.Tables{Callbacks: queue, CallbackMaxAttempts: 1}, cfg, zap.NewNop())
+	require.NoError(t, collector.CollectAll(ctx))
+	require.Len(t, queue.Callbacks(), 1)
+}
+
+// failingCollector fails every delete
+type failingCollector struct{}
+
+func (failingCollector) DeleteDeliveredCallbacks(ctx context.Context, before time.Time, limit int) (int, error) {
+	return 0, errors.New("database unavailable")
+}
+
+func (failingCollector) DeleteFailedCallbacks(ctx context.Context, before time.Time, maxAttempts, limit int) (int, error) {
+	return 0, errors.New("database unavailable")
+}
+
+func TestCollectAllContinuesAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	queueOutboxEntries(t, repo, "published")
+	claimed, err := repo.ClaimOutboxEntries(ctx, 1, time.Minute, 1)
+	require.NoError(t, err)
+	require.NoError(t, repo.CompleteOutboxEntry(ctx, claimed[0].ID, claimed[0].ClaimToken))
+
+	time.Sleep(5 * time.Millisecond)
+	errorsBefore := housekeeping.Metrics().Errors()
+	collector := housekeeping.NewCollector(housekeeping.Tables{Outbox: repo, OutboxMaxAttempts: 1, Callbacks: failingCollector{}, CallbackMaxAttempts: 1}, housekeepingConfig(), zap.NewNop())
+	err = collector.CollectAll(ctx)
+	require.ErrorContains(t, err, "delete delivered_callbacks: database unavailable")
+	require.ErrorContains(t, err, "delete failed_callbacks: database unavailable")
+	require.Equal(t, errorsBefore+2, housekeeping.Metrics().Errors())
+	require.Empty(t, repo.OutboxEntries())
+}
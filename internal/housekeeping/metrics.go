@@ -0,0 +1,48 @@
+package housekeeping
+
+import (
+	"expvar"
+)
+
+// metrics count the rows housekeeping deleted, keyed "<kind>_deleted_total", and its passes,
+// served from /debug/vars as "housekeeping"
+var metrics = newStats(expvar.NewMap("housekeeping"))
+
+// Stats counts housekeeping passes and the rows they deleted
+type Stats struct {
+	vars         *expvar.Map
+	passes       expvar.Int
+	errors       expvar.Int
+	lastPassUnix expvar.Int
+}
+
+func newStats(vars *expvar.Map) *Stats {
+	s := &Stats{vars: vars}
+	vars.Set("passes_total", &s.passes)
+	vars.Set("errors_total", &s.errors)
+	vars.Set("last_pass_unix", &s.lastPassUnix)
+	return s
+}
+
+// Metrics returns the process-wide housekeeping metrics
+func Metrics() *Stats {
+	return metrics
+}
+
+// Deleted returns how many rows of kind, such as "published_outbox", have been deleted
+func (s *Stats) Deleted(kind string) int64 {
+	if v, ok := s.vars.Get(kind + "_deleted_total").(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// Passes returns how many housekeeping passes have run
+func (s *Stats) Passes() int64 {
+	return s.passes.Value()
+}
+
+// Errors returns how many deletes have failed
+func (s *Stats) Errors() int64 {
+	return s.errors.Value()
+}
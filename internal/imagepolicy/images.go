@@ -0,0 +1,40 @@
+package imagepolicy
+
+import "sort"
+
+// imageKey is the field that holds an image reference wherever it appears in a compute config
+const imageKey = "image"
+
+// Images returns every image a compute config refers to, sorted and without duplicates.
+// Images are found by field name at any depth, so the tenant's container, init containers,
+// container hooks and plugin provider configs are all covered.
+func Images(config map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	collectImages(config, seen)
+
+	images := make([]string, 0, len(seen))
+	for image := range seen {
+		images = append(images, image)
+	}
+	sort.Strings(images)
+	return images
+}
+
+func collectImages(value interface{}, seen map[string]bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if image, ok := child.(string); ok && key == imageKey {
+				if image != "" {
+					seen[image] = true
+				}
+				continue
+			}
+			collectImages(child, seen)
+		}
+	case []interface{}:
+		for _, child := range v {
+			collectImages(child, seen)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Package imagepolicy restricts which container images tenants may run: allowed registries and
+// repositories, tag pinning rules and cosign signature verification. Policies are enforced when
+// tenants are created or updated and re-checked by a periodic compliance scan.
+package imagepolicy
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+// Rule names reported in violations for the built-in checks
+const (
+	RuleInvalidReference    = "reference"
+	RuleAllowedRepositories = "allowed_repositories"
+	RuleSignature           = "signature"
+)
+
+// Violation is an image that breaks the policy
+type Violation struct {
+	// Image is the image reference as written in the compute config
+	Image string `json:"image"`
+
+	// Rule is the broken rule: a built-in rule name or a tag rule's name
+	Rule string `json:"rule"`
+
+	// Message explains the violation
+	Message string `json:"message"`
+}
+
+// String formats the violation for error details and condition messages
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s (%s)", v.Image, v.Message, v.Rule)
+}
+
+// Policy checks images against an image policy configuration.
+// A nil Policy allows every image.
+type Policy struct {
+	repositories []*regexp.Regexp
+	patterns     []string
+	tagRules     []tagRule
+	signature    config.ImageSignatureConfig
+	verifier     Verifier
+	logger       *zap.Logger
+}
+
+type tagRule struct {
+	config.ImageTagRuleConfig
+	denyTags []*regexp.Regexp
+}
+
+// New creates a policy from configuration. Signatures are verified with cosign when enabled.
+func New(cfg config.ImagePolicyConfig, logger *zap.Logger) *Policy {
+	p := &Policy{
+		patterns:  cfg.AllowedRepositories,
+		signature: cfg.Signature,
+		logger:    logger.With(zap.String("component", "image-policy")),
+	}
+	for _, pattern := range cfg.AllowedRepositories {
+		p.repositories = append(p.repositories, compileGlob(pattern))
+	}
+	for _, rule := range cfg.TagRules {
+		compiled := tagRule{ImageTagRuleConfig: rule}
+		for _, pattern := range rule.DenyTags {
+			compiled.denyTags = append(compiled.denyTags, compileGlob(pattern))
+		}
+		p.tagRules = append(p.tagRules, compiled)
+	}
+	if cfg.Signature.Enabled {
+		p.verifier = NewCosignVerifier(cfg.Signature)
+	}
+	return p
+}
+
+// SetVerifier replaces the signature verifier, for tests and alternative verification backends
+func (p *Policy) SetVerifier(v Verifier) {
+	p.verifier = v
+}
+
+// Check returns the policy violations of every image in the compute config of a tenant with the given labels
+func (p *Policy) Check(ctx context.Context, labels map[string]string, computeConfig map[string]interface{}) []Violation {
+	if p == nil {
+		return nil
+	}
+	var violations []Violation
+	for _, image := range Images(computeConfig) {
+		violations = append(violations, p.CheckImage(ctx, labels, image)...)
+	}
+	return violations
+}
+
+// CheckImage returns the policy violations of one image run by a tenant with the given labels
+func (p *Policy) CheckImage(ctx context.Context, labels map[string]string, image string) []Violation {
+	if p == nil {
+		return nil
+	}
+	ref, err := ParseReference(image)
+	if err != nil {
+		return []Violation{{Image: image, Rule: RuleInvalidReference, Message: err.Error()}}
+	}
+
+	var violations []Violation
+	if len(p.repositories) > 0 && !p.repositoryAllowed(ref) {
+		violations = append(violations, Violation{
+			Image:   image,
+			Rule:    RuleAllowedRepositories,
+			Message: fmt.Sprintf("repository %s is not in the allowed repositories (%s)", ref.Name(), strings.Join(p.patterns, ", ")),
+		})
+	}
+
+	for _, rule := range p.tagRules {
+		if !selectorMatches(rule.Selector, labels) {
+			continue
+		}
+		if rule.RequireDigest && ref.Digest == "" {
+			violations = append(violations, Violation{Image: image, Rule: rule.Name, Message: "image must be pinned by digest"})
+		}
+		if ref.Tag == "" {
+			continue
+		}
+		for i, pattern := range rule.denyTags {
+			if pattern.MatchString(ref.Tag) {
+				violations = append(violations, Violation{
+					Image:   image,
+					Rule:    rule.Name,
+					Message: fmt.Sprintf("tag %q is denied by pattern %q", ref.Tag, rule.DenyTags[i]),
+				})
+				break
+			}
+		}
+	}
+
+	// Signatures are only checked for images that pass everything else, to avoid pointless registry calls
+	if len(violations) == 0 && p.verifier != nil && selectorMatches(p.signature.Selector, labels) {
+		if err := p.verifier.Verify(ctx, image); err != nil {
+			p.logger.Info("image signature verification failed", zap.String("image", image), zap.Error(err))
+			violations = append(violations, Violation{Image: image, Rule: RuleSignature, Message: err.Error()})
+		}
+	}
+	return violations
+}
+
+func (p *Policy) repositoryAllowed(ref Reference) bool {
+	for _, pattern := range p.repositories {
+		if pattern.MatchString(ref.Name()) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectorMatches reports whether labels carry every selector entry; an empty selector matches everything
+func selectorMatches(selector, labels map[string]string) bool {
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// compileGlob turns a glob into an anchored regular expression. "*" matches within one path
+// segment, "**" matches across segments and "?" matches one character other than "/".
+func compileGlob(pattern string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
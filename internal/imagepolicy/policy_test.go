@@ -0,0 +1,146 @@
+package imagepolicy
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+type fakeVerifier struct {
+	invalid map[string]bool
+	calls   []string
+}
+
+func (f *fakeVerifier) Verify(ctx context.Context, image string) error {
+	f.calls = append(f.calls, image)
+	if f.invalid[image] {
+		return errors.New("no matching signatures")
+	}
+	return nil
+}
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		image string
+		want  Reference
+	}{
+		{"nginx", Reference{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"}},
+		{"acme/web:1.2", Reference{Registry: "docker.io", Repository: "acme/web", Tag: "1.2"}},
+		{"ghcr.io/acme/web:1.2", Reference{Registry: "ghcr.io", Repository: "acme/web", Tag: "1.2"}},
+		{"registry.local:5000/app@sha256:abc", Reference{Registry: "registry.local:5000", Repository: "app", Digest: "sha256:abc"}},
+		{"localhost/app:v1@sha256:abc", Reference{Registry: "localhost", Repository: "app", Tag: "v1", Digest: "sha256:abc"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseReference(tt.image)
+		if err != nil {
+			t.Fatalf("ParseReference(%q) error = %v", tt.image, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseReference(%q) = %+v, want %+v", tt.image, got, tt.want)
+		}
+	}
+
+	for _, image := range []string{"", "nginx:", "nginx@abc", "ghcr.io/"} {
+		if _, err := ParseReference(image); err == nil {
+			t.Errorf("ParseReference(%q) expected error", image)
+		}
+	}
+}
+
+func TestImages(t *testing.T) {
+	cfg := map[string]interface{}{
+		"image": "nginx:1.27",
+		"hooks": map[string]interface{}{
+			"pre_provision": []interface{}{
+				map[string]interface{}{"type": "container", "image": "busybox"},
+				map[string]interface{}{"type": "container", "image": "nginx:1.27"},
+			},
+		},
+		"env": map[string]interface{}{"image": 3},
+	}
+	want := []string{"busybox", "nginx:1.27"}
+	if got := Images(cfg); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Images() = %v, want %v", got, want)
+	}
+}
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"ghcr.io/acme/*", "ghcr.io/acme/web", true},
+		{"ghcr.io/acme/*", "ghcr.io/acme/team/web", false},
+		{"ghcr.io/acme/**", "ghcr.io/acme/team/web", true},
+		{"docker.io/library/nginx", "docker.io/library/nginx", true},
+		{"docker.io/library/ngin?", "docker.io/library/nginx", true},
+		{"latest", "latest-rc", false},
+		{"*-rc*", "1.2-rc1", true},
+	}
+	for _, tt := range tests {
+		if got := compileGlob(tt.pattern).MatchString(tt.name); got != tt.match {
+			t.Errorf("glob %q on %q = %v, want %v", tt.pattern, tt.name, got, tt.match)
+		}
+	}
+}
+
+func TestPolicyCheck(t *testing.T) {
+	verifier := &fakeVerifier{invalid: map[string]bool{"ghcr.io/acme/unsigned:1.0": true}}
+	policy := New(config.ImagePolicyConfig{
+		AllowedRepositories: []string{"ghcr.io/acme/*", "docker.io/library/nginx"},
+		TagRules: []config.ImageTagRuleConfig{
+			{Name: "no-latest-in-prod", Selector: map[string]string{"env": "prod"}, DenyTags: []string{"latest"}},
+			{Name: "pinned-in-pci", Selector: map[string]string{"pci": "true"}, RequireDigest: true},
+		},
+		Signature: config.ImageSignatureConfig{Enabled: true, Key: "cosign.pub"},
+	}, zap.NewNop())
+	policy.SetVerifier(verifier)
+
+	ctx := context.Background()
+	prod := map[string]string{"env": "prod"}
+	tests := []struct {
+		name   string
+		labels map[string]string
+		image  string
+		rules  []string
+	}{
+		{"allowed", prod, "ghcr.io/acme/web:1.0", nil},
+		{"latest outside prod", nil, "nginx", nil},
+		{"implicit latest in prod", prod, "nginx", []string{"no-latest-in-prod"}},
+		{"repository not allowed", nil, "quay.io/evil/miner:1.0", []string{RuleAllowedRepositories}},
+		{"digest required", map[string]string{"pci": "true"}, "ghcr.io/acme/web:1.0", []string{"pinned-in-pci"}},
+		{"unsigned", nil, "ghcr.io/acme/unsigned:1.0", []string{RuleSignature}},
+		{"invalid", nil, "nginx:", []string{RuleInvalidReference}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+			for _, v := range policy.Check(ctx, tt.labels, map[string]interface{}{"image": tt.image}) {
+				rules = append(rules, v.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.rules) {
+				t.Fatalf("violated rules = %v, want %v", rules, tt.rules)
+			}
+		})
+	}
+
+	// Images that already violate the policy are not sent for verification
+	for _, image := range verifier.calls {
+		if image == "quay.io/evil/miner:1.0" {
+			t.Fatalf("expected disallowed image to skip signature verification")
+		}
+	}
+}
+
+func TestNilPolicyAllowsEverything(t *testing.T) {
+	var policy *Policy
+	if v := policy.Check(context.Background(), nil, map[string]interface{}{"image": "anything:latest"}); v != nil {
+		t.Fatalf("expected no violations, got %v", v)
+	}
+}
@@ -0,0 +1,88 @@
+package imagepolicy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultRegistry is the registry of image references that do not name one
+const defaultRegistry = "docker.io"
+
+// Reference is a parsed container image reference
+type Reference struct {
+	// Registry is the registry host, such as "ghcr.io"; "docker.io" when the reference names none
+	Registry string
+
+	// Repository is the path within the registry; official Docker Hub images get the "library/" prefix
+	Repository string
+
+	// Tag is the image tag; "latest" when the reference has neither a tag nor a digest
+	Tag string
+
+	// Digest is the content digest, such as "sha256:...", when the reference is pinned
+	Digest string
+}
+
+// ParseReference parses and normalizes an image reference such as "nginx",
+// "ghcr.io/acme/web:1.2" or "registry.local:5000/app@sha256:..."
+func ParseReference(image string) (Reference, error) {
+	ref := Reference{}
+	remainder := strings.TrimSpace(image)
+	if remainder == "" {
+		return ref, fmt.Errorf("image reference is empty")
+	}
+
+	if at := strings.Index(remainder, "@"); at >= 0 {
+		ref.Digest = remainder[at+1:]
+		remainder = remainder[:at]
+		if !strings.Contains(ref.Digest, ":") {
+			return ref, fmt.Errorf("image %q: digest must be algorithm:hex", image)
+		}
+	}
+
+	// A colon after the last slash separates the tag; earlier colons belong to a registry port
+	if colon := strings.LastIndex(remainder, ":"); colon > strings.LastIndex(remainder, "/") {
+		ref.Tag = remainder[colon+1:]
+		remainder = remainder[:colon]
+		if ref.Tag == "" {
+			return ref, fmt.Errorf("image %q: tag is empty", image)
+		}
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+
+	// The first component is a registry when it looks like a host
+	parts := strings.SplitN(remainder, "/", 2)
+	if len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		ref.Registry = parts[0]
+		ref.Repository = parts[1]
+	} else {
+		ref.Registry = defaultRegistry
+		ref.Repository = remainder
+	}
+	if ref.Registry == defaultRegistry && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	if ref.Repository == "" || strings.HasPrefix(ref.Repository, "/") || strings.HasSuffix(ref.Repository, "/") {
+		return ref, fmt.Errorf("image %q: repository is invalid", image)
+	}
+	return ref, nil
+}
+
+// Name returns the registry and repository, such as "docker.io/library/nginx"
+func (r Reference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the normalized reference
+func (r Reference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
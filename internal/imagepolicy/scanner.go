@@ -0,0 +1,162 @@
+package imagepolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// ConditionCompliant is the tenant condition recording whether its images comply with the policy
+const ConditionCompliant = "ImagePolicyCompliant"
+
+// Reasons set on the ImagePolicyCompliant condition
+const (
+	ReasonCompliant = tenant.ReasonPolicyCompliant
+	ReasonViolation = tenant.ReasonPolicyViolation
+)
+
+// SetCondition records the outcome of a policy check as t's ImagePolicyCompliant condition.
+// Returns true when the condition changed.
+func SetCondition(t *tenant.Tenant, violations []Violation, now time.Time) bool {
+	if len(violations) == 0 {
+		return t.SetCondition(tenant.Condition{
+			Type:    ConditionCompliant,
+			Status:  tenant.ConditionTrue,
+			Reason:  ReasonCompliant,
+			Message: "All images comply with the image policy",
+		}, now)
+	}
+
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		messages = append(messages, v.String())
+	}
+	return t.SetCondition(tenant.Condition{
+		Type:    ConditionCompliant,
+		Status:  tenant.ConditionFalse,
+		Reason:  ReasonViolation,
+		Message: strings.Join(messages, "; "),
+	}, now)
+}
+
+// Scanner periodically re-checks existing tenants against the policy, so tenants created before
+// a policy change, or whose image signatures stop verifying, are flagged through their
+// ImagePolicyCompliant condition. Non-compliant tenants keep running.
+type Scanner struct {
+	policy   *Policy
+	repo     tenant.Repository
+	interval time.Duration
+	logger   *zap.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewScanner creates a compliance scanner that runs every interval once started
+func NewScanner(policy *Policy, repo tenant.Repository, interval time.Duration, logger *zap.Logger) *Scanner {
+	return &Scanner{
+		policy:   policy,
+		repo:     repo,
+		interval: interval,
+		logger:   logger.With(zap.String("component", "image-policy-scanner")),
+	}
+}
+
+// Start runs the scan in the background until Stop is called
+func (s *Scanner) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.interval <= 0 {
+		return fmt.Errorf("image policy scan interval must be positive")
+	}
+	if s.cancel != nil {
+		return fmt.Errorf("image policy scanner already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.run(ctx, s.done)
+
+	s.logger.Info("image policy scanner started", zap.Duration("interval", s.interval))
+	return nil
+}
+
+// Stop stops the background scan and waits for a running pass to finish
+func (s *Scanner) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	s.logger.Info("image policy scanner stopped")
+}
+
+func (s *Scanner) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.Scan(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Warn("image policy scan failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan checks every live tenant once and updates conditions that changed
+func (s *Scanner) Scan(ctx context.Context) error {
+	tenants, err := s.repo.ListTenants(ctx, tenant.ListFilters{})
+	if err != nil {
+		return fmt.Errorf("list tenants: %w", err)
+	}
+
+	for _, t := range tenants {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Tenants on their way out are not worth flagging
+		if t.Status == tenant.StatusDeleting || t.Status == tenant.StatusArchiving || t.Status == tenant.StatusArchived {
+			continue
+		}
+
+		violations := s.policy.Check(ctx, t.Labels, t.DesiredConfig)
+		if !SetCondition(t, violations, time.Now()) {
+			continue
+		}
+		if err := s.repo.UpdateTenant(ctx, t); err != nil {
+			if errors.Is(err, tenant.ErrVersionConflict) || errors.Is(err, tenant.ErrTenantNotFound) {
+				// The tenant changed underneath the scan; the next pass sees its new state
+				continue
+			}
+			s.logger.Warn("failed to record image policy condition", zap.String("tenant_id", t.ID.String()), zap.Error(err))
+			continue
+		}
+		if len(violations) > 0 {
+			s.logger.Warn("tenant violates image policy",
+				zap.String("tenant_id", t.ID.String()),
+				zap.String("tenant_name", t.Name),
+				zap.Int("violations", len(violations)))
+		}
+	}
+	return nil
+}
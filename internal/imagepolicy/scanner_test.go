@@ -0,0 +1,68 @@
+package imagepolicy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestScannerFlagsViolations(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+
+	tenants := map[string]*tenant.Tenant{
+		"compliant": {Name: "compliant", Status: tenant.StatusReady, DesiredConfig: map[string]interface{}{"image": "ghcr.io/acme/web:1.0"}},
+		"violating": {Name: "violating", Status: tenant.StatusReady, DesiredConfig: map[string]interface{}{"image": "nginx:latest"}},
+		"archived":  {Name: "archived", Status: tenant.StatusArchived, DesiredConfig: map[string]interface{}{"image": "nginx:latest"}},
+	}
+	for _, tn := range tenants {
+		if err := repo.CreateTenant(ctx, tn); err != nil {
+			t.Fatalf("create tenant: %v", err)
+		}
+	}
+
+	policy := New(config.ImagePolicyConfig{AllowedRepositories: []string{"ghcr.io/acme/*"}}, zap.NewNop())
+	scanner := NewScanner(policy, repo, time.Minute, zap.NewNop())
+	if err := scanner.Scan(ctx); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	conditionOf := func(name string) *tenant.Condition {
+		stored, err := repo.GetTenantByName(ctx, name)
+		if err != nil {
+			t.Fatalf("get tenant %s: %v", name, err)
+		}
+		return stored.Condition(ConditionCompliant)
+	}
+	if c := conditionOf("compliant"); c == nil || c.Status != tenant.ConditionTrue {
+		t.Fatalf("expected compliant tenant to have a True condition, got %+v", c)
+	}
+	if c := conditionOf("violating"); c == nil || c.Status != tenant.ConditionFalse || c.Reason != ReasonViolation {
+		t.Fatalf("expected violating tenant to have a False condition, got %+v", c)
+	}
+	if c := conditionOf("archived"); c != nil {
+		t.Fatalf("expected archived tenant to be skipped, got %+v", c)
+	}
+
+	// A second pass with nothing changed leaves tenants untouched
+	before, err := repo.GetTenantByName(ctx, "violating")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if err := scanner.Scan(ctx); err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	after, err := repo.GetTenantByName(ctx, "violating")
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if after.Version != before.Version {
+		t.Fatalf("expected unchanged tenant to keep version %d, got %d", before.Version, after.Version)
+	}
+}
@@ -0,0 +1,65 @@
+package imagepolicy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+const (
+	defaultCosignPath    = "cosign"
+	defaultVerifyTimeout = 30 * time.Second
+
+	// maxVerifyOutput bounds the cosign output quoted in a violation
+	maxVerifyOutput = 512
+)
+
+// Verifier checks an image's signature; a nil error means the signature is valid
+type Verifier interface {
+	Verify(ctx context.Context, image string) error
+}
+
+// CosignVerifier verifies signatures by running "cosign verify --key <key> <image>"
+type CosignVerifier struct {
+	path    string
+	key     string
+	timeout time.Duration
+}
+
+// NewCosignVerifier creates a verifier from signature configuration
+func NewCosignVerifier(cfg config.ImageSignatureConfig) *CosignVerifier {
+	v := &CosignVerifier{path: cfg.CosignPath, key: cfg.Key, timeout: cfg.Timeout}
+	if v.path == "" {
+		v.path = defaultCosignPath
+	}
+	if v.timeout <= 0 {
+		v.timeout = defaultVerifyTimeout
+	}
+	return v
+}
+
+func (v *CosignVerifier) Verify(ctx context.Context, image string) error {
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, v.path, "verify", "--key", v.key, image)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		detail := strings.TrimSpace(output.String())
+		if len(detail) > maxVerifyOutput {
+			detail = detail[:maxVerifyOutput] + "..."
+		}
+		if detail == "" {
+			return fmt.Errorf("signature verification failed: %w", err)
+		}
+		return fmt.Errorf("signature verification failed: %s", detail)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Package imageupdate moves opted-in tenants to new image tags and digests as they are pushed.
+// An Updater polls the configured registries, rewrites the image in the tenant's desired_config
+// and marks the tenant updating, so the normal update workflow rolls it out.
+package imageupdate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AnnotationPolicy opts a tenant into automated image updates. Its value is the update policy:
+//
+//	semver:<range>  move to the highest tag within the range, such as "semver:^1.2" or "semver:~1.27.0"
+//	digest          keep the tag and pin the image to the tag's latest digest
+const AnnotationPolicy = "landlord/image-update"
+
+// Manager is the field manager and history trigger recorded for automated updates
+const Manager = "image-updater"
+
+// Update policy kinds
+const (
+	PolicySemver = "semver"
+	PolicyDigest = "digest"
+)
+
+// UpdatePolicy is a tenant's parsed AnnotationPolicy
+type UpdatePolicy struct {
+	// Kind is PolicySemver or PolicyDigest
+	Kind string
+
+	// Range is the semver range, for semver policies
+	Range *constraint
+}
+
+// ParsePolicy parses an AnnotationPolicy value
+func ParsePolicy(value string) (*UpdatePolicy, error) {
+	value = strings.TrimSpace(value)
+	switch {
+	case value == PolicyDigest:
+		return &UpdatePolicy{Kind: PolicyDigest}, nil
+	case strings.HasPrefix(value, PolicySemver+":"):
+		r, err := parseConstraint(strings.TrimSpace(strings.TrimPrefix(value, PolicySemver+":")))
+		if err != nil {
+			return nil, err
+		}
+		return &UpdatePolicy{Kind: PolicySemver, Range: r}, nil
+	}
+	return nil, fmt.Errorf("invalid %s policy %q: expected \"semver:<range>\" or \"digest\"", AnnotationPolicy, value)
+}
+
+// String formats the policy as it is written in the annotation
+func (p *UpdatePolicy) String() string {
+	if p.Kind == PolicySemver {
+		return PolicySemver + ":" + p.Range.String()
+	}
+	return p.Kind
+}
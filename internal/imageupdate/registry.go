@@ -0,0 +1,280 @@
+package imageupdate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/imagepolicy"
+)
+
+// dockerHubEndpoint serves the registry API for images whose registry is "docker.io"
+const dockerHubEndpoint = "registry-1.docker.io"
+
+// maxTagPages bounds tag list pagination, so a misbehaving registry cannot loop forever
+const maxTagPages = 50
+
+// manifestMediaTypes are accepted when resolving a tag's digest; multi-platform indexes come first
+// so the digest is the one "docker pull" would pin
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Registry looks up the tags and digests of images in one container registry
+type Registry interface {
+	// Tags lists the tags of ref's repository
+	Tags(ctx context.Context, ref imagepolicy.Reference) ([]string, error)
+
+	// Digest returns the current digest of ref's tag
+	Digest(ctx context.Context, ref imagepolicy.Reference) (string, error)
+}
+
+// NewRegistries creates a client for each configured registry, keyed by host
+func NewRegistries(cfgs []config.ImageRegistryConfig) map[string]Registry {
+	registries := make(map[string]Registry, len(cfgs))
+	for _, cfg := range cfgs {
+		registries[cfg.Host] = NewClient(cfg, &http.Client{Timeout: 30 * time.Second})
+	}
+	return registries
+}
+
+// Client speaks the OCI distribution (Docker Registry v2) API, with basic and bearer token auth
+type Client struct {
+	endpoint   string
+	username   string
+	password   string
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// NewClient creates a registry client from configuration
+func NewClient(cfg config.ImageRegistryConfig, httpClient *http.Client) *Client {
+	host := cfg.Host
+	if host == "docker.io" {
+		host = dockerHubEndpoint
+	}
+	scheme := "https"
+	if cfg.Insecure {
+		scheme = "http"
+	}
+	return &Client{
+		endpoint:   scheme + "://" + host,
+		username:   cfg.Username,
+		password:   cfg.Password,
+		httpClient: httpClient,
+		tokens:     make(map[string]string),
+	}
+}
+
+// Tags lists the tags of ref's repository, following pagination
+func (c *Client) Tags(ctx context.Context, ref imagepolicy.Reference) ([]string, error) {
+	var tags []string
+	next := fmt.Sprintf("%s/v2/%s/tags/list", c.endpoint, ref.Repository)
+	for page := 0; next != "" && page < maxTagPages; page++ {
+		resp, err := c.do(ctx, http.MethodGet, next, ref.Repository, nil)
+		if err != nil {
+			return nil, err
+		}
+		var body struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		link := resp.Header.Get("Link")
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode tags of %s: %w", ref.Name(), err)
+		}
+		tags = append(tags, body.Tags...)
+
+		next, err = c.nextPage(next, link)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// Digest returns the digest of ref's tag from the registry's Docker-Content-Digest header
+func (c *Client) Digest(ctx context.Context, ref imagepolicy.Reference) (string, error) {
+	if ref.Tag == "" {
+		return "", fmt.Errorf("image %s has no tag to resolve", ref)
+	}
+	headers := http.Header{"Accept": {strings.Join(manifestMediaTypes, ", ")}}
+	resp, err := c.do(ctx, http.MethodHead, fmt.Sprintf("%s/v2/%s/manifests/%s", c.endpoint, ref.Repository, ref.Tag), ref.Repository, headers)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry returned no digest for %s", ref)
+	}
+	return digest, nil
+}
+
+// nextPage resolves the "next" URL of a Link header against the current page
+func (c *Client) nextPage(current, link string) (string, error) {
+	if link == "" {
+		return "", nil
+	}
+	start, end := strings.Index(link, "<"), strings.Index(link, ">")
+	if start < 0 || end < start || !strings.Contains(link[end:], `rel="next"`) {
+		return "", nil
+	}
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+	next, err := base.Parse(link[start+1 : end])
+	if err != nil {
+		return "", fmt.Errorf("parse tag list link %q: %w", link, err)
+	}
+	return next.String(), nil
+}
+
+// do sends a request, answering an authentication challenge once
+func (c *Client) do(ctx context.Context, method, rawURL, repository string, headers http.Header) (*http.Response, error) {
+	scope := "repository:" + repository + ":pull"
+	send := func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range headers {
+			req.Header[key] = values
+		}
+		c.mu.Lock()
+		token := c.tokens[scope]
+		c.mu.Unlock()
+		switch {
+		case token != "":
+			req.Header.Set("Authorization", "Bearer "+token)
+		case c.username != "":
+			req.SetBasicAuth(c.username, c.password)
+		}
+		return c.httpClient.Do(req)
+	}
+
+	resp, err := send()
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, rawURL, err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := c.authenticate(ctx, challenge, scope); err != nil {
+			return nil, err
+		}
+		if resp, err = send(); err != nil {
+			return nil, fmt.Errorf("%s %s: %w", method, rawURL, err)
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s %s: registry returned %s: %s", method, rawURL, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// authenticate fetches a bearer token for scope from the realm named in a challenge
+func (c *Client) authenticate(ctx context.Context, challenge, scope string) error {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return fmt.Errorf("registry %s rejected the credentials", c.endpoint)
+	}
+	params := parseChallenge(challenge[len("bearer "):])
+	realm := params["realm"]
+	if realm == "" {
+		return fmt.Errorf("registry %s sent a bearer challenge without a realm", c.endpoint)
+	}
+
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return fmt.Errorf("parse token realm %q: %w", realm, err)
+	}
+	query := tokenURL.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch registry token: %s", resp.Status)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("decode registry token: %w", err)
+	}
+	token := body.Token
+	if token == "" {
+		token = body.AccessToken
+	}
+	if token == "" {
+		return fmt.Errorf("registry token response has no token")
+	}
+
+	c.mu.Lock()
+	c.tokens[scope] = token
+	c.mu.Unlock()
+	return nil
+}
+
+// parseChallenge parses the comma-separated key="value" pairs of a WWW-Authenticate challenge
+func parseChallenge(s string) map[string]string {
+	params := make(map[string]string)
+	for s != "" {
+		eq := strings.Index(s, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.Index(s[1:], `"`)
+			if end < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+		} else if comma := strings.Index(s, ","); comma >= 0 {
+			value, s = s[:comma], s[comma:]
+		} else {
+			value, s = s, ""
+		}
+		params[key] = value
+		s = strings.TrimLeft(s, ", ")
+	}
+	return params
+}
@@ -0,0 +1,96 @@
+package imageupdate
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/imagepolicy"
+)
+
+// newTestRegistry serves two pages of tags and a digest behind bearer token auth
+func newTestRegistry(t *testing.T) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("scope") != "repository:acme/web:pull" {
+				http.Error(w, "bad scope", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"token": "secret-token"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret-token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test-registry"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/v2/acme/web/tags/list" && r.URL.Query().Get("last") == "":
+			w.Header().Set("Link", `</v2/acme/web/tags/list?last=1.1&n=2>; rel="next"`)
+			fmt.Fprint(w, `{"name": "acme/web", "tags": ["1.0", "1.1"]}`)
+		case r.URL.Path == "/v2/acme/web/tags/list":
+			fmt.Fprint(w, `{"name": "acme/web", "tags": ["1.2"]}`)
+		case r.URL.Path == "/v2/acme/web/manifests/1.2" && r.Method == http.MethodHead:
+			if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+				http.Error(w, "missing accept", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:abc")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientTagsAndDigest(t *testing.T) {
+	srv := newTestRegistry(t)
+	host := strings.TrimPrefix(srv.URL, "http://")
+	client := NewClient(config.ImageRegistryConfig{Host: host, Insecure: true}, srv.Client())
+
+	ref, err := imagepolicy.ParseReference(host + "/acme/web:1.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err := client.Tags(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("Tags() error = %v", err)
+	}
+	if want := []string{"1.0", "1.1", "1.2"}; !reflect.DeepEqual(tags, want) {
+		t.Fatalf("Tags() = %v, want %v", tags, want)
+	}
+
+	digest, err := client.Digest(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("Digest() error = %v", err)
+	}
+	if digest != "sha256:abc" {
+		t.Fatalf("Digest() = %q, want sha256:abc", digest)
+	}
+
+	missing := ref
+	missing.Repository = "acme/missing"
+	if _, err := client.Tags(context.Background(), missing); err == nil {
+		t.Fatal("expected an error for a missing repository")
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	got := parseChallenge(`realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"`)
+	want := map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/nginx:pull",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseChallenge() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,199 @@
+package imageupdate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// version is a release version parsed from an image tag. Tags with fewer than three
+// components, such as "1.27", are padded with zeros; pre-release tags are not versions.
+type version struct {
+	major, minor, patch int
+}
+
+func parseVersion(tag string) (version, bool) {
+	s := strings.TrimPrefix(tag, "v")
+	if s == "" || strings.ContainsAny(s, "-+") {
+		return version{}, false
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return version{}, false
+	}
+	var nums [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (len(part) > 1 && part[0] == '0') {
+			return version{}, false
+		}
+		nums[i] = n
+	}
+	return version{nums[0], nums[1], nums[2]}, true
+}
+
+func (v version) compare(o version) int {
+	switch {
+	case v.major != o.major:
+		return sign(v.major - o.major)
+	case v.minor != o.minor:
+		return sign(v.minor - o.minor)
+	default:
+		return sign(v.patch - o.patch)
+	}
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// constraint is a semver range: alternatives separated by "||", each a set of comparisons that
+// must all hold. Supported comparisons are =, >, >=, <, <=, caret (^1.2), tilde (~1.2.3) and
+// wildcards (1.x, 1.2.*).
+type constraint struct {
+	source       string
+	alternatives [][]comparison
+}
+
+type comparison struct {
+	op      string
+	version version
+}
+
+func (c comparison) matches(v version) bool {
+	cmp := v.compare(c.version)
+	switch c.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	default:
+		return cmp == 0
+	}
+}
+
+func parseConstraint(s string) (*constraint, error) {
+	c := &constraint{source: s}
+	for _, alternative := range strings.Split(s, "||") {
+		fields := strings.Fields(alternative)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("semver range %q has an empty alternative", s)
+		}
+		var comparisons []comparison
+		for _, field := range fields {
+			parsed, err := parseComparison(field)
+			if err != nil {
+				return nil, fmt.Errorf("semver range %q: %w", s, err)
+			}
+			comparisons = append(comparisons, parsed...)
+		}
+		c.alternatives = append(c.alternatives, comparisons)
+	}
+	return c, nil
+}
+
+// parseComparison expands one range term into the comparisons it stands for
+func parseComparison(term string) ([]comparison, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(term, prefix) {
+			op = prefix
+			break
+		}
+	}
+	raw := strings.TrimPrefix(strings.TrimPrefix(term, op), "v")
+
+	// Count the components given before any wildcard, so 1.x and ^1 both mean "within 1"
+	parts := strings.Split(raw, ".")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid version %q", term)
+	}
+	var nums [3]int
+	given := 0
+	for _, part := range parts {
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q", term)
+		}
+		nums[given] = n
+		given++
+	}
+	if given == 0 && raw != "*" && raw != "x" && raw != "X" {
+		return nil, fmt.Errorf("invalid version %q", term)
+	}
+	lower := version{nums[0], nums[1], nums[2]}
+	if given == 0 {
+		// "*", "^x" and friends match every version; "<*" has no meaning
+		if op == "" || op == "=" || op == "^" || op == "~" {
+			return []comparison{{">=", version{}}}, nil
+		}
+		return nil, fmt.Errorf("invalid version %q", term)
+	}
+
+	switch op {
+	case ">", ">=", "<", "<=":
+		return []comparison{{op, lower}}, nil
+	case "^":
+		// Allow changes that do not modify the left-most non-zero component
+		switch {
+		case lower.major > 0 || given == 1:
+			return between(lower, version{lower.major + 1, 0, 0}), nil
+		case lower.minor > 0 || given == 2:
+			return between(lower, version{0, lower.minor + 1, 0}), nil
+		default:
+			return between(lower, version{0, 0, lower.patch + 1}), nil
+		}
+	case "~":
+		// Allow patch changes, or minor changes when only the major version is given
+		if given == 1 {
+			return between(lower, version{lower.major + 1, 0, 0}), nil
+		}
+		return between(lower, version{lower.major, lower.minor + 1, 0}), nil
+	}
+
+	// A bare or "=" version with wildcards or missing components matches everything within it
+	switch given {
+	case 1:
+		return between(lower, version{lower.major + 1, 0, 0}), nil
+	case 2:
+		return between(lower, version{lower.major, lower.minor + 1, 0}), nil
+	}
+	return []comparison{{"=", lower}}, nil
+}
+
+func between(lower, upper version) []comparison {
+	return []comparison{{">=", lower}, {"<", upper}}
+}
+
+func (c *constraint) matches(v version) bool {
+	for _, comparisons := range c.alternatives {
+		ok := true
+		for _, cmp := range comparisons {
+			if !cmp.matches(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *constraint) String() string {
+	return c.source
+}
@@ -0,0 +1,105 @@
+package imageupdate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConstraintMatches(t *testing.T) {
+	tests := []struct {
+		constraint string
+		matches    []string
+		rejects    []string
+	}{
+		{"^1.2", []string{"1.2.0", "1.9.9", "v1.3"}, []string{"1.1.9", "2.0.0"}},
+		{"^0.2.1", []string{"0.2.1", "0.2.9"}, []string{"0.3.0", "0.2.0"}},
+		{"~1.27.0", []string{"1.27.0", "1.27.5"}, []string{"1.28.0", "1.26.9"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"1.x", []string{"1.0.0", "1.99.0"}, []string{"0.9.0", "2.0.0"}},
+		{"1.2.*", []string{"1.2.0", "1.2.7"}, []string{"1.3.0"}},
+		{">=1.2.0 <1.4", []string{"1.2.0", "1.3.9"}, []string{"1.4.0", "1.1.0"}},
+		{"^1.0 || ^3.0", []string{"1.5.0", "3.1.0"}, []string{"2.0.0"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.4"}},
+		{"*", []string{"0.0.1", "9.0.0"}, nil},
+	}
+	for _, tt := range tests {
+		c, err := parseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("parseConstraint(%q) error = %v", tt.constraint, err)
+		}
+		for _, tag := range tt.matches {
+			v, _ := parseVersion(tag)
+			if !c.matches(v) {
+				t.Errorf("%q should match %s", tt.constraint, tag)
+			}
+		}
+		for _, tag := range tt.rejects {
+			v, _ := parseVersion(tag)
+			if c.matches(v) {
+				t.Errorf("%q should not match %s", tt.constraint, tag)
+			}
+		}
+	}
+
+	for _, bad := range []string{"", "^a.b", "1.2.3.4", "^1.0 ||"} {
+		if _, err := parseConstraint(bad); err == nil {
+			t.Errorf("parseConstraint(%q) expected error", bad)
+		}
+	}
+}
+
+func TestParseVersionRejectsNonReleases(t *testing.T) {
+	for _, tag := range []string{"latest", "1.2.3-rc1", "1.2.3+build", "alpine", "01.2", "1.2.3.4"} {
+		if _, ok := parseVersion(tag); ok {
+			t.Errorf("parseVersion(%q) should not be a version", tag)
+		}
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	r, err := parseConstraint("^1.25")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags := []string{"latest", "1.25", "1.26", "1.27", "1.27.0", "1.28-rc1", "2.0", "alpine"}
+
+	want := []string{"1.27.0", "1.27", "1.26"}
+	if got := candidates(tags, "1.25", r); !reflect.DeepEqual(got, want) {
+		t.Fatalf("candidates() = %v, want %v", got, want)
+	}
+	want = []string{"1.27.0", "1.27", "1.26", "1.25"}
+	if got := candidates(tags, "latest", r); !reflect.DeepEqual(got, want) {
+		t.Fatalf("candidates() from a non-version tag = %v, want %v", got, want)
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	if p, err := ParsePolicy("digest"); err != nil || p.Kind != PolicyDigest {
+		t.Fatalf("ParsePolicy(digest) = %+v, %v", p, err)
+	}
+	p, err := ParsePolicy("semver: ^1.2")
+	if err != nil || p.Kind != PolicySemver || p.String() != "semver:^1.2" {
+		t.Fatalf("ParsePolicy(semver) = %+v, %v", p, err)
+	}
+	for _, bad := range []string{"", "latest", "semver:", "semver:^y"} {
+		if _, err := ParsePolicy(bad); err == nil {
+			t.Errorf("ParsePolicy(%q) expected error", bad)
+		}
+	}
+}
+
+func TestWithTag(t *testing.T) {
+	tests := []struct {
+		image, tag, digest, want string
+	}{
+		{"nginx:1.26", "1.27", "", "nginx:1.27"},
+		{"nginx", "1.27", "", "nginx:1.27"},
+		{"registry.local:5000/app:1.0@sha256:old", "1.1", "sha256:new", "registry.local:5000/app:1.1@sha256:new"},
+		{"ghcr.io/acme/web:1.0", "1.0", "sha256:abc", "ghcr.io/acme/web:1.0@sha256:abc"},
+	}
+	for _, tt := range tests {
+		if got := withTag(tt.image, tt.tag, tt.digest); got != tt.want {
+			t.Errorf("withTag(%q, %q, %q) = %q, want %q", tt.image, tt.tag, tt.digest, got, tt.want)
+		}
+	}
+}
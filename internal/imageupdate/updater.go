@@ -0,0 +1,314 @@
+package imageupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/imagepolicy"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// imageField is the desired_config field rewritten by updates
+const imageField = "image"
+
+// Updater periodically moves opted-in tenants to new images. Only ready tenants are updated,
+// so an update never interrupts a running workflow; tenants that are busy are picked up by a
+// later pass.
+type Updater struct {
+	repo       tenant.Repository
+	registries map[string]Registry
+	policy     *imagepolicy.Policy
+	interval   time.Duration
+	logger     *zap.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewUpdater creates an image updater that queries registries by host. Candidate images that
+// break policy are skipped; policy may be nil.
+func NewUpdater(repo tenant.Repository, registries map[string]Registry, policy *imagepolicy.Policy, interval time.Duration, logger *zap.Logger) *Updater {
+	return &Updater{
+		repo:       repo,
+		registries: registries,
+		policy:     policy,
+		interval:   interval,
+		logger:     logger.With(zap.String("component", "image-updater")),
+	}
+}
+
+// Start runs update passes in the background until Stop is called
+func (u *Updater) Start() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.interval <= 0 {
+		return fmt.Errorf("image update interval must be positive")
+	}
+	if u.cancel != nil {
+		return fmt.Errorf("image updater already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	u.cancel = cancel
+	u.done = make(chan struct{})
+	go u.run(ctx, u.done)
+
+	u.logger.Info("image updater started", zap.Duration("interval", u.interval), zap.Int("registries", len(u.registries)))
+	return nil
+}
+
+// Stop stops the background passes and waits for a running pass to finish
+func (u *Updater) Stop() {
+	u.mu.Lock()
+	cancel, done := u.cancel, u.done
+	u.cancel, u.done = nil, nil
+	u.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	u.logger.Info("image updater stopped")
+}
+
+func (u *Updater) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(u.interval)
+	defer ticker.Stop()
+	for {
+		if err := u.Update(ctx); err != nil && ctx.Err() == nil {
+			u.logger.Warn("image update pass failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Update checks every opted-in tenant once and updates those with a newer image
+func (u *Updater) Update(ctx context.Context) error {
+	tenants, err := u.repo.ListTenants(ctx, tenant.ListFilters{})
+	if err != nil {
+		return fmt.Errorf("list tenants: %w", err)
+	}
+
+	for _, t := range tenants {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		value, ok := t.Annotations[AnnotationPolicy]
+		if !ok || t.Status != tenant.StatusReady {
+			continue
+		}
+		log := u.logger.With(zap.String("tenant_id", t.ID.String()), zap.String("tenant_name", t.Name))
+
+		policy, err := ParsePolicy(value)
+		if err != nil {
+			log.Warn("ignoring invalid image update policy", zap.Error(err))
+			continue
+		}
+		current, _ := t.DesiredConfig[imageField].(string)
+		if current == "" {
+			continue
+		}
+
+		next, err := u.nextImage(ctx, t, policy, current)
+		if err != nil {
+			log.Warn("failed to check for image update", zap.String("image", current), zap.Error(err))
+			continue
+		}
+		if next == "" || next == current {
+			continue
+		}
+		if err := u.apply(ctx, t, policy, current, next); err != nil {
+			if errors.Is(err, tenant.ErrVersionConflict) || errors.Is(err, tenant.ErrTenantLocked) {
+				// The tenant is changing underneath the pass; the next pass sees its new state
+				continue
+			}
+			log.Warn("failed to update tenant image", zap.String("image", next), zap.Error(err))
+			continue
+		}
+		log.Info("tenant image updated", zap.String("from", current), zap.String("to", next), zap.String("policy", policy.String()))
+	}
+	return nil
+}
+
+// nextImage returns the image the tenant should move to, or "" when it is up to date
+func (u *Updater) nextImage(ctx context.Context, t *tenant.Tenant, policy *UpdatePolicy, current string) (string, error) {
+	ref, err := imagepolicy.ParseReference(current)
+	if err != nil {
+		return "", err
+	}
+	registry, ok := u.registries[ref.Registry]
+	if !ok {
+		u.logger.Debug("image registry is not watched", zap.String("tenant_id", t.ID.String()), zap.String("registry", ref.Registry))
+		return "", nil
+	}
+
+	if policy.Kind == PolicyDigest {
+		digest, err := registry.Digest(ctx, ref)
+		if err != nil {
+			return "", err
+		}
+		if digest == ref.Digest {
+			return "", nil
+		}
+		next := withTag(current, ref.Tag, digest)
+		if violations := u.policy.CheckImage(ctx, t.Labels, next); len(violations) > 0 {
+			u.logViolations(t, next, violations)
+			return "", nil
+		}
+		return next, nil
+	}
+
+	tags, err := registry.Tags(ctx, ref)
+	if err != nil {
+		return "", err
+	}
+	for _, tag := range candidates(tags, ref.Tag, policy.Range) {
+		// Images pinned by digest stay pinned
+		digest := ""
+		if ref.Digest != "" {
+			candidate := ref
+			candidate.Tag = tag
+			if digest, err = registry.Digest(ctx, candidate); err != nil {
+				return "", err
+			}
+		}
+		next := withTag(current, tag, digest)
+		violations := u.policy.CheckImage(ctx, t.Labels, next)
+		if len(violations) == 0 {
+			return next, nil
+		}
+		u.logViolations(t, next, violations)
+	}
+	return "", nil
+}
+
+func (u *Updater) logViolations(t *tenant.Tenant, image string, violations []imagepolicy.Violation) {
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		messages = append(messages, v.String())
+	}
+	u.logger.Info("skipping image update that violates image policy",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("image", image),
+		zap.Strings("violations", messages))
+}
+
+// apply writes the new image and hands the tenant to the update workflow, recording the change in its history
+func (u *Updater) apply(ctx context.Context, t *tenant.Tenant, policy *UpdatePolicy, current, next string) error {
+	// Hold the same lock as API changes so an update never interleaves with a user's request
+	release, err := u.repo.LockTenant(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	now := time.Now()
+	previousConfig := t.DesiredConfig
+	desired := make(map[string]interface{}, len(previousConfig))
+	for key, value := range previousConfig {
+		desired[key] = value
+	}
+	desired[imageField] = next
+
+	reason := fmt.Sprintf("Image updated from %s to %s by policy %s", current, next, policy.String())
+	transition := tenant.NewStateTransition(t, tenant.StatusUpdating, reason, Manager).WithReasonCode(tenant.ReasonImageUpdated)
+
+	t.DesiredConfig = desired
+	t.UpdateManagedFields(previousConfig, Manager, tenant.ManagedFieldOperationUpdate, now)
+	if u.policy != nil {
+		imagepolicy.SetCondition(t, nil, now)
+	}
+	t.Status = tenant.StatusUpdating
+	t.StatusMessage = fmt.Sprintf("Image update to %s", next)
+	t.WorkflowExecutionID = nil
+	t.WorkflowStartedAt = nil
+	t.WorkflowSubState = nil
+	t.WorkflowRetryCount = nil
+	t.WorkflowErrorMessage = nil
+	t.UpdatedAt = now
+
+	if err := u.repo.UpdateTenant(ctx, t); err != nil {
+		return err
+	}
+
+	transition.DesiredStateSnapshot = desired
+	transition.ObservedStateSnapshot = map[string]interface{}{
+		"previous_image": current,
+		"image":          next,
+		"policy":         policy.String(),
+	}
+	if err := u.repo.RecordStateTransition(ctx, transition); err != nil {
+		u.logger.Warn("failed to record image update", zap.String("tenant_id", t.ID.String()), zap.Error(err))
+	}
+	return nil
+}
+
+// candidates returns the tags within r that are newer than current, newest first.
+// When current is not a version every tag within r is a candidate.
+func candidates(tags []string, current string, r *constraint) []string {
+	floor, hasFloor := parseVersion(current)
+
+	type candidate struct {
+		tag     string
+		version version
+	}
+	var matched []candidate
+	for _, tag := range tags {
+		v, ok := parseVersion(tag)
+		if !ok || !r.matches(v) {
+			continue
+		}
+		if hasFloor && v.compare(floor) <= 0 {
+			continue
+		}
+		matched = append(matched, candidate{tag, v})
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if cmp := matched[i].version.compare(matched[j].version); cmp != 0 {
+			return cmp > 0
+		}
+		// "1.27.0" and "1.27" are the same version; prefer the more specific tag
+		return len(matched[i].tag) > len(matched[j].tag)
+	})
+
+	result := make([]string, 0, len(matched))
+	for _, c := range matched {
+		result = append(result, c.tag)
+	}
+	return result
+}
+
+// withTag rewrites image with a new tag and optional digest, keeping the registry and
+// repository as written so "nginx:1.26" becomes "nginx:1.27" rather than a normalized name
+func withTag(image, tag, digest string) string {
+	name := strings.TrimSpace(image)
+	if at := strings.Index(name, "@"); at >= 0 {
+		name = name[:at]
+	}
+	if colon := strings.LastIndex(name, ":"); colon > strings.LastIndex(name, "/") {
+		name = name[:colon]
+	}
+	if tag != "" {
+		name += ":" + tag
+	}
+	if digest != "" {
+		name += "@" + digest
+	}
+	return name
+}
@@ -0,0 +1,115 @@
+package imageupdate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/imagepolicy"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+type fakeRegistry struct {
+	tags    map[string][]string
+	digests map[string]string
+}
+
+func (f *fakeRegistry) Tags(ctx context.Context, ref imagepolicy.Reference) ([]string, error) {
+	return f.tags[ref.Repository], nil
+}
+
+func (f *fakeRegistry) Digest(ctx context.Context, ref imagepolicy.Reference) (string, error) {
+	return f.digests[ref.Repository+":"+ref.Tag], nil
+}
+
+func TestUpdaterMovesOptedInTenants(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	registry := &fakeRegistry{
+		tags: map[string][]string{
+			"library/nginx": {"1.26", "1.27", "1.28", "2.0", "latest"},
+			"acme/web":      {"1.0", "1.1"},
+		},
+		digests: map[string]string{
+			"acme/web:1.0": "sha256:new",
+			"acme/web:1.1": "sha256:web11",
+		},
+	}
+
+	create := func(name, image string, status tenant.Status, annotations map[string]string) {
+		t.Helper()
+		if err := repo.CreateTenant(ctx, &tenant.Tenant{
+			Name:          name,
+			Status:        status,
+			DesiredConfig: map[string]interface{}{"image": image, "replicas": float64(1)},
+			Annotations:   annotations,
+		}); err != nil {
+			t.Fatalf("create tenant %s: %v", name, err)
+		}
+	}
+	create("semver", "nginx:1.26", tenant.StatusReady, map[string]string{AnnotationPolicy: "semver:~1.26 || ~1.27"})
+	create("digest", "ghcr.io/acme/web:1.0@sha256:old", tenant.StatusReady, map[string]string{AnnotationPolicy: "digest"})
+	create("pinned-semver", "ghcr.io/acme/web:1.0@sha256:old", tenant.StatusReady, map[string]string{AnnotationPolicy: "semver:^1"})
+	create("opted-out", "nginx:1.26", tenant.StatusReady, nil)
+	create("busy", "nginx:1.26", tenant.StatusUpdating, map[string]string{AnnotationPolicy: "semver:^1"})
+	create("unwatched", "quay.io/acme/web:1.0", tenant.StatusReady, map[string]string{AnnotationPolicy: "semver:^1"})
+
+	// docker.io/library/nginx:1.27 breaks policy, so the semver tenant stays put even though 1.27 is in range
+	policy := imagepolicy.New(config.ImagePolicyConfig{
+		TagRules: []config.ImageTagRuleConfig{{Name: "no-1.27", DenyTags: []string{"1.27"}}},
+	}, zap.NewNop())
+	updater := NewUpdater(repo, map[string]Registry{"docker.io": registry, "ghcr.io": registry}, policy, time.Minute, zap.NewNop())
+	if err := updater.Update(ctx); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	expect := func(name, image string, status tenant.Status) *tenant.Tenant {
+		t.Helper()
+		stored, err := repo.GetTenantByName(ctx, name)
+		if err != nil {
+			t.Fatalf("get tenant %s: %v", name, err)
+		}
+		if got := stored.DesiredConfig["image"]; got != image {
+			t.Fatalf("tenant %s: expected image %s, got %v", name, image, got)
+		}
+		if stored.Status != status {
+			t.Fatalf("tenant %s: expected status %s, got %s", name, status, stored.Status)
+		}
+		return stored
+	}
+	expect("semver", "nginx:1.26", tenant.StatusReady)
+	expect("opted-out", "nginx:1.26", tenant.StatusReady)
+	expect("busy", "nginx:1.26", tenant.StatusUpdating)
+	expect("unwatched", "quay.io/acme/web:1.0", tenant.StatusReady)
+	expect("pinned-semver", "ghcr.io/acme/web:1.1@sha256:web11", tenant.StatusUpdating)
+
+	updated := expect("digest", "ghcr.io/acme/web:1.0@sha256:new", tenant.StatusUpdating)
+	if field := updated.ManagedFields["image"]; field.Manager != Manager {
+		t.Errorf("expected image to be managed by %s, got %+v", Manager, field)
+	}
+	if _, owned := updated.ManagedFields["replicas"]; owned {
+		t.Errorf("expected unchanged replicas to keep no owner")
+	}
+
+	history, err := repo.GetStateHistory(ctx, updated.ID, tenant.HistoryFilters{})
+	if err != nil {
+		t.Fatalf("get history: %v", err)
+	}
+	if len(history) == 0 || history[0].TriggeredBy != Manager || history[0].ToStatus != tenant.StatusUpdating {
+		t.Fatalf("expected an image update history record, got %+v", history)
+	}
+	if got := history[0].ObservedStateSnapshot["previous_image"]; got != "ghcr.io/acme/web:1.0@sha256:old" {
+		t.Errorf("expected previous image in history, got %v", got)
+	}
+
+	// Once the policy allows it the semver tenant moves to the highest tag in range
+	updater.policy = nil
+	if err := updater.Update(ctx); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	expect("semver", "nginx:1.27", tenant.StatusUpdating)
+}
@@ -0,0 +1,306 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Bounds on runtime overrides. Overrides always expire, so a level bumped mid-incident cannot be
+// forgotten at debug.
+const (
+	DefaultOverrideDuration = 15 * time.Minute
+	MaxOverrideDuration     = 24 * time.Hour
+)
+
+// Debug flags turn on diagnostic output that is too verbose or sensitive to log at any level by
+// default. The code that owns a flag checks it with DebugFlag.
+const (
+	// FlagRestatePayloads logs the input of each Restate invocation and each admin query
+	FlagRestatePayloads = "restate-payloads"
+
+	// FlagDockerContainerConfig logs the container and host config of each container the Docker
+	// provider creates, env included
+	FlagDockerContainerConfig = "docker-container-config"
+)
+
+// DebugFlagInfo describes a debug flag
+type DebugFlagInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// DebugFlags are the debug flags landlord checks
+var DebugFlags = []DebugFlagInfo{
+	{FlagRestatePayloads, "Log the input of each Restate invocation and each Restate admin query"},
+	{FlagDockerContainerConfig, "Log the container and host config, env included, of each container the Docker provider creates"},
+}
+
+// Override is a runtime change to a component's log level or a debug flag that reverts at ExpiresAt
+type Override struct {
+	// Name is the component or debug flag
+	Name      string    `json:"name"`
+	Level     string    `json:"level,omitempty"`
+	SetBy     string    `json:"set_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	level zapcore.Level
+	timer *time.Timer
+}
+
+// Levels holds the log level of a process's loggers and the runtime overrides of it. Loggers built
+// by New consult it on every entry: an entry from a logger with a component field is written at the
+// component's overridden level, if it has one, and otherwise at the base level.
+type Levels struct {
+	base   zap.AtomicLevel
+	logger *zap.Logger
+
+	mu         sync.RWMutex
+	components map[string]bool
+	levels     map[string]*Override
+	flags      map[string]*Override
+}
+
+func newLevels(base zapcore.Level) *Levels {
+	return &Levels{
+		base:       zap.NewAtomicLevelAt(base),
+		logger:     zap.NewNop(),
+		components: make(map[string]bool),
+		levels:     make(map[string]*Override),
+		flags:      make(map[string]*Override),
+	}
+}
+
+// LevelsOf returns the levels of a logger built by New, or nil for other loggers
+func LevelsOf(logger *zap.Logger) *Levels {
+	if core, ok := logger.Core().(*levelCore); ok {
+		return core.levels
+	}
+	return nil
+}
+
+// DebugFlag reports whether the debug flag is on for logger's process. It is always off for
+// loggers not built by New.
+func DebugFlag(logger *zap.Logger, flag string) bool {
+	levels := LevelsOf(logger)
+	if levels == nil {
+		return false
+	}
+	levels.mu.RLock()
+	defer levels.mu.RUnlock()
+	_, ok := levels.flags[flag]
+	return ok
+}
+
+// Base returns the level of loggers without an override
+func (l *Levels) Base() zapcore.Level {
+	return l.base.Level()
+}
+
+// Components returns the components loggers have been created for, sorted
+func (l *Levels) Components() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	components := make([]string, 0, len(l.components))
+	for component := range l.components {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+	return components
+}
+
+// LevelOverrides returns the active log level overrides, sorted by component
+func (l *Levels) LevelOverrides() []Override {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return sortedOverrides(l.levels)
+}
+
+// FlagOverrides returns the debug flags that are on, sorted by name
+func (l *Levels) FlagOverrides() []Override {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return sortedOverrides(l.flags)
+}
+
+// SetLevel sets the component's log level for duration, replacing any override it has. The component
+// must have a logger.
+func (l *Levels) SetLevel(component string, level zapcore.Level, duration time.Duration, setBy string) (Override, error) {
+	if err := checkDuration(duration); err != nil {
+		return Override{}, err
+	}
+	l.mu.Lock()
+	if !l.components[component] {
+		l.mu.Unlock()
+		return Override{}, fmt.Errorf("unknown component %q", component)
+	}
+	override := l.set(l.levels, "log level", component, duration, setBy, func(o *Override) {
+		o.Level, o.level = level.String(), level
+	})
+	l.mu.Unlock()
+
+	l.logger.Info("log level overridden",
+		zap.String("target", component),
+		zap.String("level", override.Level),
+		zap.String("set_by", setBy),
+		zap.Time("expires_at", override.ExpiresAt),
+	)
+	return override, nil
+}
+
+// ResetLevel removes the component's log level override, returning false if it had none
+func (l *Levels) ResetLevel(component string) bool {
+	return l.reset(l.levels, "log level", component)
+}
+
+// SetFlag turns the debug flag on for duration, extending it if it is already on
+func (l *Levels) SetFlag(flag string, duration time.Duration, setBy string) (Override, error) {
+	if err := checkDuration(duration); err != nil {
+		return Override{}, err
+	}
+	if !KnownDebugFlag(flag) {
+		return Override{}, fmt.Errorf("unknown debug flag %q", flag)
+	}
+	l.mu.Lock()
+	override := l.set(l.flags, "debug flag", flag, duration, setBy, nil)
+	l.mu.Unlock()
+
+	l.logger.Info("debug flag enabled",
+		zap.String("target", flag),
+		zap.String("set_by", setBy),
+		zap.Time("expires_at", override.ExpiresAt),
+	)
+	return override, nil
+}
+
+// ResetFlag turns the debug flag off, returning false if it was not on
+func (l *Levels) ResetFlag(flag string) bool {
+	return l.reset(l.flags, "debug flag", flag)
+}
+
+// KnownDebugFlag reports whether flag is one landlord checks
+func KnownDebugFlag(flag string) bool {
+	for _, info := range DebugFlags {
+		if info.Name == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// set replaces name's override in overrides with one that expires after duration, applying init
+// to it before it takes effect. l.mu must be held.
+func (l *Levels) set(overrides map[string]*Override, kind, name string, duration time.Duration, setBy string, init func(*Override)) Override {
+	if previous, ok := overrides[name]; ok {
+		previous.timer.Stop()
+	}
+	override := &Override{Name: name, SetBy: setBy, ExpiresAt: time.Now().Add(duration)}
+	if init != nil {
+		init(override)
+	}
+	override.timer = time.AfterFunc(duration, func() {
+		l.mu.Lock()
+		expired := overrides[name] == override
+		if expired {
+			delete(overrides, name)
+		}
+		l.mu.Unlock()
+		if expired {
+			l.logger.Info(kind+" override expired", zap.String("target", name))
+		}
+	})
+	overrides[name] = override
+	return *override
+}
+
+// reset removes name's override from overrides. Loggers consult the levels, so l.mu is never held
+// while logging.
+func (l *Levels) reset(overrides map[string]*Override, kind, name string) bool {
+	l.mu.Lock()
+	override, ok := overrides[name]
+	if ok {
+		override.timer.Stop()
+		delete(overrides, name)
+	}
+	l.mu.Unlock()
+	if ok {
+		l.logger.Info(kind+" override removed", zap.String("target", name))
+	}
+	return ok
+}
+
+func (l *Levels) register(component string) {
+	l.mu.RLock()
+	known := l.components[component]
+	l.mu.RUnlock()
+	if known {
+		return
+	}
+	l.mu.Lock()
+	l.components[component] = true
+	l.mu.Unlock()
+}
+
+func (l *Levels) enabled(component string, level zapcore.Level) bool {
+	if component != "" {
+		l.mu.RLock()
+		override, ok := l.levels[component]
+		l.mu.RUnlock()
+		if ok {
+			return override.level.Enabled(level)
+		}
+	}
+	return l.base.Enabled(level)
+}
+
+func checkDuration(duration time.Duration) error {
+	if duration <= 0 || duration > MaxOverrideDuration {
+		return fmt.Errorf("duration must be greater than 0 and at most %s", MaxOverrideDuration)
+	}
+	return nil
+}
+
+func sortedOverrides(overrides map[string]*Override) []Override {
+	sorted := make([]Override, 0, len(overrides))
+	for _, override := range overrides {
+		sorted = append(sorted, *override)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
+
+// levelCore filters entries by Levels. The core it wraps is built at the lowest level, so overrides
+// can lower a component's level below the base level. With remembers the component field, so every
+// logger derived from a component logger is filtered at the component's level.
+type levelCore struct {
+	zapcore.Core
+	levels    *Levels
+	component string
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.levels.enabled(c.component, level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	component := c.component
+	for _, field := range fields {
+		if field.Key == "component" && field.Type == zapcore.StringType {
+			component = field.String
+			c.levels.register(component)
+		}
+	}
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels, component: component}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(entry.Level) {
+		return checked
+	}
+	// The wrapped core adds itself, so its sampling still applies
+	return c.Core.Check(entry, checked)
+}
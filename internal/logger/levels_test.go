@@ -0,0 +1,138 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newObservedLogger(base zapcore.Level) (*zap.Logger, *Levels, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	levels := newLevels(base)
+	return zap.New(&levelCore{Core: core, levels: levels}), levels, logs
+}
+
+func TestLevelsOverrideComponent(t *testing.T) {
+	log, levels, logs := newObservedLogger(zapcore.InfoLevel)
+	reconciler := log.With(zap.String("component", "reconciler"))
+	api := log.With(zap.String("component", "api"))
+	request := reconciler.With(zap.String("tenant_id", "acme"))
+
+	reconciler.Debug("hidden")
+	if logs.Len() != 0 {
+		t.Fatalf("expected debug entries to be dropped at info, got %d", logs.Len())
+	}
+
+	if _, err := levels.SetLevel("scheduler", zapcore.DebugLevel, time.Minute, "alice"); err == nil {
+		t.Fatal("expected an error for a component without a logger")
+	}
+	if _, err := levels.SetLevel("reconciler", zapcore.DebugLevel, 48*time.Hour, "alice"); err == nil {
+		t.Fatal("expected an error for a duration above the maximum")
+	}
+	override, err := levels.SetLevel("reconciler", zapcore.DebugLevel, time.Minute, "alice")
+	if err != nil {
+		t.Fatalf("set level: %v", err)
+	}
+	if override.Level != "debug" || override.SetBy != "alice" {
+		t.Errorf("unexpected override %+v", override)
+	}
+
+	reconciler.Debug("reconciler debug")
+	request.Debug("derived debug")
+	api.Debug("api debug")
+	if got := logs.FilterMessageSnippet("debug").Len(); got != 2 {
+		t.Fatalf("expected the reconciler's debug entries only, got %d: %+v", got, logs.All())
+	}
+
+	if _, err := levels.SetLevel("api", zapcore.ErrorLevel, time.Minute, "alice"); err != nil {
+		t.Fatalf("set level: %v", err)
+	}
+	api.Warn("api warn")
+	if logs.FilterMessage("api warn").Len() != 0 {
+		t.Error("expected warn entries to be dropped when the component is raised to error")
+	}
+
+	if !levels.ResetLevel("reconciler") || levels.ResetLevel("reconciler") {
+		t.Error("expected the override to be removed once")
+	}
+	reconciler.Debug("after reset")
+	if logs.FilterMessage("after reset").Len() != 0 {
+		t.Error("expected debug entries to be dropped after the override is removed")
+	}
+	if got := levels.LevelOverrides(); len(got) != 1 || got[0].Name != "api" {
+		t.Errorf("expected only the api override, got %+v", got)
+	}
+	if got := levels.Components(); len(got) != 2 || got[0] != "api" || got[1] != "reconciler" {
+		t.Errorf("unexpected components %v", got)
+	}
+}
+
+func TestLevelsOverrideExpires(t *testing.T) {
+	log, levels, logs := newObservedLogger(zapcore.InfoLevel)
+	reconciler := log.With(zap.String("component", "reconciler"))
+
+	if _, err := levels.SetLevel("reconciler", zapcore.DebugLevel, 20*time.Millisecond, "alice"); err != nil {
+		t.Fatalf("set level: %v", err)
+	}
+	if _, err := levels.SetFlag(FlagRestatePayloads, 20*time.Millisecond, "alice"); err != nil {
+		t.Fatalf("set flag: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(levels.LevelOverrides()) > 0 || DebugFlag(log, FlagRestatePayloads) {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the overrides to expire")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	reconciler.Debug("expired")
+	if logs.FilterMessage("expired").Len() != 0 {
+		t.Error("expected debug entries to be dropped after the override expires")
+	}
+}
+
+func TestDebugFlags(t *testing.T) {
+	log, levels, _ := newObservedLogger(zapcore.InfoLevel)
+
+	if DebugFlag(log, FlagDockerContainerConfig) {
+		t.Fatal("expected the flag to be off")
+	}
+	if _, err := levels.SetFlag("verbose-everything", time.Minute, "alice"); err == nil {
+		t.Fatal("expected an error for an unknown flag")
+	}
+	if _, err := levels.SetFlag(FlagDockerContainerConfig, time.Minute, "alice"); err != nil {
+		t.Fatalf("set flag: %v", err)
+	}
+	if !DebugFlag(log.With(zap.String("component", "docker-provider")), FlagDockerContainerConfig) {
+		t.Error("expected the flag to be on for derived loggers")
+	}
+	if DebugFlag(zap.NewNop(), FlagDockerContainerConfig) {
+		t.Error("expected flags to be off for loggers not built by New")
+	}
+	if !levels.ResetFlag(FlagDockerContainerConfig) || DebugFlag(log, FlagDockerContainerConfig) {
+		t.Error("expected the flag to be turned off")
+	}
+}
+
+func TestNewExposesLevels(t *testing.T) {
+	log, err := New("production", "warn")
+	if err != nil {
+		t.Fatalf("new logger: %v", err)
+	}
+	levels := LevelsOf(log.With(zap.String("component", "reconciler")))
+	if levels == nil {
+		t.Fatal("expected loggers built by New to expose their levels")
+	}
+	if levels.Base() != zapcore.WarnLevel {
+		t.Errorf("expected base level warn, got %s", levels.Base())
+	}
+	if log.Core().Enabled(zapcore.InfoLevel) {
+		t.Error("expected info entries to be dropped at warn")
+	}
+	if LevelsOf(zap.NewNop()) != nil {
+		t.Error("expected no levels for loggers not built by New")
+	}
+}
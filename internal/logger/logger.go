@@ -12,7 +12,8 @@ type contextKey string
 
 const loggerKey contextKey = "logger"
 
-// New creates a new logger based on the given format and level
+// New creates a new logger based on the given format and level. Its level can be overridden per
+// component at runtime through LevelsOf.
 func New(format string, level string) (*zap.Logger, error) {
 	var config zap.Config
 	switch format {
@@ -30,12 +31,18 @@ func New(format string, level string) (*zap.Logger, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid log level: %w", err)
 	}
-	config.Level = zap.NewAtomicLevelAt(zapLevel)
+	// The encoder core writes every level; levelCore filters by the base level and any runtime
+	// overrides, which LevelsOf exposes
+	config.Level = zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	levels := newLevels(zapLevel)
 
-	logger, err := config.Build()
+	logger, err := config.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &levelCore{Core: core, levels: levels}
+	}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to build logger: %w", err)
 	}
+	levels.logger = logger.With(zap.String("component", "log-levels"))
 
 	return logger, nil
 }
@@ -0,0 +1,283 @@
+// Package observe keeps ready tenants' observed state current. It reads each tenant's compute
+// status from its provider on a schedule and records what is actually running in the tenant's
+// observed_config and observed_resource_ids. It never changes compute resources.
+package observe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/resolution"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// ComputeStatusKey is the observed_config key holding the tenant's last read compute status
+const ComputeStatusKey = "compute_status"
+
+// maxConcurrentRefreshes bounds how many tenants' statuses are read at once
+const maxConcurrentRefreshes = 16
+
+// Refresher periodically reads the compute status of every ready tenant. A tenant is written only
+// when its status changed since the last read, so observed_config.compute_status.last_updated is
+// when the current status was first seen. Tenants whose compute resources are gone get a status
+// in the unknown state saying so.
+type Refresher struct {
+	tenants         tenant.Repository
+	registry        *compute.Registry
+	defaultProvider string
+	resolver        *resolution.Resolver
+	cfg             config.ObserveConfig
+	logger          *zap.Logger
+
+	refreshedMu sync.Mutex
+	refreshed   map[uuid.UUID]time.Time
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRefresher creates a refresher. Tenants that do not name a compute provider are resolved with
+// resolver, then defaultProvider, as the API does; resolver may be nil.
+func NewRefresher(tenants tenant.Repository, registry *compute.Registry, defaultProvider string, resolver *resolution.Resolver, cfg config.ObserveConfig, logger *zap.Logger) *Refresher {
+	return &Refresher{
+		tenants:         tenants,
+		registry:        registry,
+		defaultProvider: defaultProvider,
+		resolver:        resolver,
+		cfg:             cfg,
+		logger:          logger.With(zap.String("component", "observe-refresher")),
+		refreshed:       make(map[uuid.UUID]time.Time),
+	}
+}
+
+// Start refreshes tenants in the background until Stop is called
+func (r *Refresher) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tick := r.tick()
+	if tick <= 0 {
+		return fmt.Errorf("observe interval must be positive")
+	}
+	if r.cancel != nil {
+		return fmt.Errorf("observe refresher already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go r.run(ctx, r.done, tick)
+
+	r.logger.Info("observe refresher started", zap.Duration("interval", r.cfg.Interval), zap.Duration("tick", tick))
+	return nil
+}
+
+// Stop stops the background refreshes and waits for a running pass to finish
+func (r *Refresher) Stop() {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	r.logger.Info("observe refresher stopped")
+}
+
+// tick is the shortest refresh interval, so every provider's tenants are refreshed on time
+func (r *Refresher) tick() time.Duration {
+	tick := r.cfg.Interval
+	for _, interval := range r.cfg.ProviderIntervals {
+		if interval > 0 && (tick <= 0 || interval < tick) {
+			tick = interval
+		}
+	}
+	return tick
+}
+
+func (r *Refresher) run(ctx context.Context, done chan struct{}, tick time.Duration) {
+	defer close(done)
+
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	for {
+		if err := r.RefreshAll(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Warn("observe refresh pass failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshAll refreshes every ready tenant whose provider's interval has passed since it was last
+// refreshed. Tenants of providers with an interval of 0 are left alone.
+func (r *Refresher) RefreshAll(ctx context.Context) error {
+	tenants, err := r.tenants.ListTenants(ctx, tenant.ListFilters{Statuses: []tenant.Status{tenant.StatusReady}})
+	if err != nil {
+		return fmt.Errorf("list tenants: %w", err)
+	}
+
+	now := time.Now()
+	ready := make(map[uuid.UUID]bool, len(tenants))
+	sem := make(chan struct{}, maxConcurrentRefreshes)
+	var wg sync.WaitGroup
+	for _, t := range tenants {
+		ready[t.ID] = true
+		providerName := r.providerName(t)
+		interval := r.cfg.IntervalFor(providerName)
+		if interval <= 0 || !r.due(t.ID, now, interval) {
+			continue
+		}
+		provider, err := r.registry.Get(providerName)
+		if err != nil {
+			r.logger.Debug("skipping tenant with unavailable compute provider",
+				zap.String("tenant_id", t.ID.String()), zap.String("provider", providerName), zap.Error(err))
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(t *tenant.Tenant) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := r.Refresh(ctx, t, provider)
+			if err == nil || ctx.Err() != nil || errors.Is(err, tenant.ErrVersionConflict) || errors.Is(err, tenant.ErrTenantNotFound) {
+				// A tenant that changed underneath the refresh is read again next interval
+				return
+			}
+			r.logger.Warn("failed to refresh observed state", zap.String("tenant_id", t.ID.String()), zap.Error(err))
+		}(t)
+	}
+	wg.Wait()
+
+	r.refreshedMu.Lock()
+	for id := range r.refreshed {
+		if !ready[id] {
+			delete(r.refreshed, id)
+		}
+	}
+	r.refreshedMu.Unlock()
+	return ctx.Err()
+}
+
+// due reports whether a tenant's interval has passed, marking it refreshed at now if so. Failed
+// refreshes count, so an unreachable provider is not asked again until the next interval.
+func (r *Refresher) due(id uuid.UUID, now time.Time, interval time.Duration) bool {
+	r.refreshedMu.Lock()
+	defer r.refreshedMu.Unlock()
+
+	if last, ok := r.refreshed[id]; ok && now.Sub(last) < interval {
+		return false
+	}
+	r.refreshed[id] = now
+	return true
+}
+
+// providerName returns the compute provider holding t
+func (r *Refresher) providerName(t *tenant.Tenant) string {
+	if name, _ := resolution.ExplicitProvider(t.DesiredConfig, t.Labels, t.Annotations); name != "" {
+		return name
+	}
+	return r.resolver.Resolve(resolution.Subject{
+		Name:        t.Name,
+		Config:      t.DesiredConfig,
+		Labels:      t.Labels,
+		Annotations: t.Annotations,
+	}, r.defaultProvider)
+}
+
+// Refresh reads t's compute status from provider and records it, writing t only when the status changed
+func (r *Refresher) Refresh(ctx context.Context, t *tenant.Tenant, provider compute.Provider) error {
+	statusCtx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	status, err := provider.GetStatus(statusCtx, t.ComputeName())
+	cancel()
+	if errors.Is(err, compute.ErrTenantNotFound) {
+		status = &compute.ComputeStatus{
+			TenantID:     t.ComputeName(),
+			ProviderType: provider.Name(),
+			State:        compute.ComputeStateUnknown,
+			Health:       compute.HealthStatusUnknown,
+			LastUpdated:  time.Now(),
+			Metadata:     map[string]string{"error": "compute resources not found"},
+		}
+	} else if err != nil {
+		return fmt.Errorf("get compute status: %w", err)
+	}
+
+	changed, err := apply(t, status)
+	if err != nil || !changed {
+		return err
+	}
+	if err := r.tenants.UpdateTenant(ctx, t); err != nil {
+		return err
+	}
+	r.logger.Debug("observed state changed",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("state", string(status.State)),
+		zap.String("health", string(status.Health)))
+	return nil
+}
+
+// apply records status in t's observed state, reporting whether anything but the read time changed
+func apply(t *tenant.Tenant, status *compute.ComputeStatus) (bool, error) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return false, fmt.Errorf("encode compute status: %w", err)
+	}
+	var observed map[string]interface{}
+	if err := json.Unmarshal(data, &observed); err != nil {
+		return false, fmt.Errorf("decode compute status: %w", err)
+	}
+
+	changed := false
+	previous, _ := t.ObservedConfig[ComputeStatusKey].(map[string]interface{})
+	if !reflect.DeepEqual(withoutReadTime(previous), withoutReadTime(observed)) {
+		if t.ObservedConfig == nil {
+			t.ObservedConfig = make(map[string]interface{})
+		}
+		t.ObservedConfig[ComputeStatusKey] = observed
+		changed = true
+	}
+	for key, id := range status.ResourceIDs {
+		if t.ObservedResourceIDs[key] == id {
+			continue
+		}
+		if t.ObservedResourceIDs == nil {
+			t.ObservedResourceIDs = make(map[string]string)
+		}
+		t.ObservedResourceIDs[key] = id
+		changed = true
+	}
+	return changed, nil
+}
+
+// withoutReadTime copies a recorded status without its last_updated time
+func withoutReadTime(status map[string]interface{}) map[string]interface{} {
+	if status == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(status))
+	for key, value := range status {
+		if key != "last_updated" {
+			copied[key] = value
+		}
+	}
+	return copied
+}
@@ -0,0 +1,111 @@
+package observe_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	computemock "github.com/jaxxstorm/landlord/internal/compute/providers/mock"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/observe"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func newRefresher(t *testing.T, cfg config.ObserveConfig) (*observe.Refresher, *memory.Repository, *computemock.Provider) {
+	t.Helper()
+	repo := memory.New()
+	registry := compute.NewRegistry(zap.NewNop())
+	provider := computemock.New()
+	require.NoError(t, registry.Register(provider))
+	cfg.Enabled = true
+	cfg.Timeout = time.Second
+	return observe.NewRefresher(repo, registry, "mock", nil, cfg, zap.NewNop()), repo, provider
+}
+
+func computeStatus(t *testing.T, tn *tenant.Tenant) map[string]interface{} {
+	t.Helper()
+	status, ok := tn.ObservedConfig[observe.ComputeStatusKey].(map[string]interface{})
+	require.True(t, ok, "observed_config has no compute status")
+	return status
+}
+
+func TestRefreshAllRecordsComputeStatus(t *testing.T) {
+	ctx := context.Background()
+	refresher, repo, provider := newRefresher(t, config.ObserveConfig{Interval: time.Nanosecond})
+
+	web := &tenant.Tenant{Name: "web", Status: tenant.StatusReady, ObservedConfig: map[string]interface{}{"endpoints": []interface{}{}}}
+	gone := &tenant.Tenant{Name: "gone", Status: tenant.StatusReady}
+	provisioning := &tenant.Tenant{Name: "provisioning", Status: tenant.StatusProvisioning}
+	for _, tn := range []*tenant.Tenant{web, gone, provisioning} {
+		require.NoError(t, repo.CreateTenant(ctx, tn))
+	}
+	for _, name := range []string{"web", "provisioning"} {
+		_, err := provider.Provision(ctx, &compute.TenantComputeSpec{
+			TenantID:     name,
+			ProviderType: "mock",
+			Containers:   []compute.ContainerSpec{{Name: "app", Image: "nginx:latest"}},
+		})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, refresher.RefreshAll(ctx))
+	got, err := repo.GetTenantByID(ctx, web.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "running", computeStatus(t, got)["state"])
+	assert.Contains(t, got.ObservedConfig, "endpoints", "the workflow's observed config is kept")
+	assert.Equal(t, "web", got.ObservedResourceIDs["tenant"])
+	version := got.Version
+
+	got, err = repo.GetTenantByID(ctx, gone.ID)
+	require.NoError(t, err)
+	status := computeStatus(t, got)
+	assert.Equal(t, "unknown", status["state"])
+	assert.Equal(t, map[string]interface{}{"error": "compute resources not found"}, status["metadata"])
+
+	got, err = repo.GetTenantByID(ctx, provisioning.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, got.ObservedConfig, observe.ComputeStatusKey, "only ready tenants are refreshed")
+
+	// An unchanged status is not written again
+	require.NoError(t, refresher.RefreshAll(ctx))
+	got, err = repo.GetTenantByID(ctx, web.ID)
+	require.NoError(t, err)
+	assert.Equal(t, version, got.Version)
+
+	require.NoError(t, provider.Suspend(ctx, "web"))
+	require.NoError(t, refresher.RefreshAll(ctx))
+	got, err = repo.GetTenantByID(ctx, web.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "stopped", computeStatus(t, got)["state"])
+	assert.Greater(t, got.Version, version)
+}
+
+func TestRefreshAllHonorsProviderIntervals(t *testing.T) {
+	ctx := context.Background()
+	web := &tenant.Tenant{Name: "web", Status: tenant.StatusReady}
+
+	refresher, repo, _ := newRefresher(t, config.ObserveConfig{Interval: time.Nanosecond, ProviderIntervals: map[string]time.Duration{"mock": 0}})
+	require.NoError(t, repo.CreateTenant(ctx, web))
+	require.NoError(t, refresher.RefreshAll(ctx))
+	got, err := repo.GetTenantByID(ctx, web.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, got.ObservedConfig, observe.ComputeStatusKey, "an interval of 0 turns refresh off")
+
+	refresher, repo, provider := newRefresher(t, config.ObserveConfig{Interval: time.Nanosecond, ProviderIntervals: map[string]time.Duration{"mock": time.Hour}})
+	require.NoError(t, repo.CreateTenant(ctx, web))
+	require.NoError(t, refresher.RefreshAll(ctx))
+	_, err = provider.Provision(ctx, &compute.TenantComputeSpec{TenantID: "web", ProviderType: "mock"})
+	require.NoError(t, err)
+
+	// The tenant was read moments ago, so its new compute is not seen until the hour is up
+	require.NoError(t, refresher.RefreshAll(ctx))
+	got, err = repo.GetTenantByID(ctx, web.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "unknown", computeStatus(t, got)["state"])
+}
@@ -0,0 +1,68 @@
+// Package memory provides an in-memory operation store for tests and local harnesses.
+package memory
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/operation"
+)
+
+// Store implements operation.Store in memory.
+// Operations are copied on the way in and out, so callers never share state with the store.
+type Store struct {
+	mu         sync.RWMutex
+	operations map[uuid.UUID]operation.Operation
+}
+
+var _ operation.Store = (*Store)(nil)
+
+// New creates an empty in-memory store
+func New() *Store {
+	return &Store{operations: make(map[uuid.UUID]operation.Operation)}
+}
+
+func (s *Store) Create(ctx context.Context, o *operation.Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o.ID = uuid.New()
+	o.CreatedAt = time.Now()
+	s.operations[o.ID] = copyOperation(o)
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, id uuid.UUID) (*operation.Operation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	o, ok := s.operations[id]
+	if !ok {
+		return nil, operation.ErrNotFound
+	}
+	copied := copyOperation(&o)
+	return &copied, nil
+}
+
+func (s *Store) Update(ctx context.Context, o *operation.Operation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.operations[o.ID]; !ok {
+		return operation.ErrNotFound
+	}
+	s.operations[o.ID] = copyOperation(o)
+	return nil
+}
+
+func copyOperation(o *operation.Operation) operation.Operation {
+	copied := *o
+	copied.Selector = maps.Clone(o.Selector)
+	copied.Tenants = slices.Clone(o.Tenants)
+	return copied
+}
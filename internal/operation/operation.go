@@ -0,0 +1,143 @@
+// Package operation records bulk tenant operations. A bulk action selects tenants by label
+// and runs the same action on each of them in the background; its operation tracks the
+// outcome for every tenant so callers can poll for progress.
+package operation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Action is what a bulk operation does to each of its tenants
+type Action string
+
+const (
+	// ActionArchive archives each tenant, or requests approval for protected ones
+	ActionArchive Action = "archive"
+
+	// ActionSuspend stops each tenant's workloads without deprovisioning them
+	ActionSuspend Action = "suspend"
+
+	// ActionResume starts workloads stopped by a suspend
+	ActionResume Action = "resume"
+
+	// ActionRestart restarts each tenant's workloads in place
+	ActionRestart Action = "restart"
+
+	// ActionRetry re-runs the provisioning or update each failed tenant stopped in
+	ActionRetry Action = "retry"
+)
+
+// IsValid reports whether a is a known action
+func (a Action) IsValid() bool {
+	switch a {
+	case ActionArchive, ActionSuspend, ActionResume, ActionRestart, ActionRetry:
+		return true
+	default:
+		return false
+	}
+}
+
+// Status is where an operation is in its lifecycle
+type Status string
+
+const (
+	// StatusRunning means tenants are still being processed
+	StatusRunning Status = "running"
+
+	// StatusSucceeded means the action succeeded, or is awaiting approval, on every tenant
+	StatusSucceeded Status = "succeeded"
+
+	// StatusFailed means the action failed on at least one tenant
+	StatusFailed Status = "failed"
+)
+
+// TenantStatus is the outcome of an operation's action on one tenant
+type TenantStatus string
+
+const (
+	TenantPending         TenantStatus = "pending"
+	TenantSucceeded       TenantStatus = "succeeded"
+	TenantFailed          TenantStatus = "failed"
+	TenantPendingApproval TenantStatus = "pending_approval"
+)
+
+// ErrNotFound is returned when no operation has the ID
+var ErrNotFound = errors.New("operation not found")
+
+// TenantResult is the outcome of an operation's action on one tenant
+type TenantResult struct {
+	TenantID   uuid.UUID    `json:"tenant_id"`
+	TenantName string       `json:"tenant_name"`
+	Status     TenantStatus `json:"status"`
+
+	// Message is the error for failed tenants, or the approval awaiting a decision
+	Message string `json:"message,omitempty"`
+}
+
+// Operation is a bulk action on the tenants matching a label selector
+type Operation struct {
+	ID     uuid.UUID `json:"id"`
+	Action Action    `json:"action"`
+
+	// Selector is the label selector the tenants were chosen by
+	Selector map[string]string `json:"selector"`
+
+	// Component limits a restart to one of each tenant's components
+	Component string `json:"component,omitempty"`
+
+	Status Status `json:"status"`
+
+	// RequestedBy is the principal that started the operation
+	RequestedBy string `json:"requested_by"`
+
+	// Tenants holds the outcome for each selected tenant, in the order they are processed
+	Tenants []TenantResult `json:"tenants"`
+
+	Succeeded       int `json:"succeeded"`
+	Failed          int `json:"failed"`
+	PendingApproval int `json:"pending_approval"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Record sets the outcome for the i-th tenant
+func (o *Operation) Record(i int, status TenantStatus, message string) {
+	o.Tenants[i].Status = status
+	o.Tenants[i].Message = message
+	switch status {
+	case TenantSucceeded:
+		o.Succeeded++
+	case TenantFailed:
+		o.Failed++
+	case TenantPendingApproval:
+		o.PendingApproval++
+	}
+}
+
+// Complete marks the operation done once every tenant has an outcome
+func (o *Operation) Complete(now time.Time) {
+	o.Status = StatusSucceeded
+	if o.Failed > 0 {
+		o.Status = StatusFailed
+	}
+	o.CompletedAt = &now
+}
+
+// Store persists operations
+type Store interface {
+	// Create persists a new operation, populating ID and CreatedAt
+	Create(ctx context.Context, o *Operation) error
+
+	// Get retrieves an operation by ID
+	// Returns ErrNotFound if not found
+	Get(ctx context.Context, id uuid.UUID) (*Operation, error)
+
+	// Update saves an operation's progress
+	// Returns ErrNotFound if not found
+	Update(ctx context.Context, o *Operation) error
+}
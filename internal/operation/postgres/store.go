@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/operation"
+)
+
+// Store implements operation.Store for PostgreSQL
+type Store struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+var _ operation.Store = (*Store)(nil)
+
+// New creates a PostgreSQL operation store
+// Accepts interface{} to satisfy provider abstraction, type asserts to *pgxpool.Pool
+func New(pool interface{}, logger *zap.Logger) (*Store, error) {
+	pgPool, ok := pool.(*pgxpool.Pool)
+	if !ok {
+		return nil, fmt.Errorf("expected *pgxpool.Pool, got %T", pool)
+	}
+	return &Store{
+		pool:   pgPool,
+		logger: logger.With(zap.String("component", "operation-postgres-store")),
+	}, nil
+}
+
+const createOperationQuery = `
+INSERT INTO bulk_operations (id, action, selector, component, status, requested_by, tenants, succeeded, failed, pending_approval)
+VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10)
+RETURNING created_at
+`
+
+func (s *Store) Create(ctx context.Context, o *operation.Operation) error {
+	selector, tenants, err := marshalOperation(o)
+	if err != nil {
+		return err
+	}
+	o.ID = uuid.New()
+	if err := s.pool.QueryRow(ctx, createOperationQuery,
+		o.ID,
+		o.Action,
+		selector,
+		o.Component,
+		o.Status,
+		o.RequestedBy,
+		tenants,
+		o.Succeeded,
+		o.Failed,
+		o.PendingApproval,
+	).Scan(&o.CreatedAt); err != nil {
+		return fmt.Errorf("create operation: %w", err)
+	}
+
+	s.logger.Info("bulk operation started",
+		zap.String("id", o.ID.String()),
+		zap.String("action", string(o.Action)),
+		zap.Int("tenants", len(o.Tenants)))
+	return nil
+}
+
+const getOperationQuery = `
+SELECT id, action, selector, COALESCE(component, ''), status, requested_by, tenants, succeeded, failed, pending_approval, created_at, completed_at
+FROM bulk_operations
+WHERE id = $1
+`
+
+func (s *Store) Get(ctx context.Context, id uuid.UUID) (*operation.Operation, error) {
+	o := &operation.Operation{}
+	var selector, tenants []byte
+	if err := s.pool.QueryRow(ctx, getOperationQuery, id).Scan(
+		&o.ID, &o.Action, &selector, &o.Component, &o.Status, &o.RequestedBy, &tenants,
+		&o.Succeeded, &o.Failed, &o.PendingApproval, &o.CreatedAt, &o.CompletedAt,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, operation.ErrNotFound
+		}
+		return nil, fmt.Errorf("get operation: %w", err)
+	}
+	if err := json.Unmarshal(selector, &o.Selector); err != nil {
+		return nil, fmt.Errorf("decode operation selector: %w", err)
+	}
+	if err := json.Unmarshal(tenants, &o.Tenants); err != nil {
+		return nil, fmt.Errorf("decode operation tenants: %w", err)
+	}
+	return o, nil
+}
+
+const updateOperationQuery = `
+UPDATE bulk_operations
+SET status = $2, tenants = $3, succeeded = $4, failed = $5, pending_approval = $6, completed_at = $7
+WHERE id = $1
+`
+
+func (s *Store) Update(ctx context.Context, o *operation.Operation) error {
+	_, tenants, err := marshalOperation(o)
+	if err != nil {
+		return err
+	}
+	tag, err := s.pool.Exec(ctx, updateOperationQuery, o.ID, o.Status, tenants, o.Succeeded, o.Failed, o.PendingApproval, o.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("update operation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return operation.ErrNotFound
+	}
+	return nil
+}
+
+func marshalOperation(o *operation.Operation) (selector, tenants []byte, err error) {
+	if selector, err = json.Marshal(o.Selector); err != nil {
+		return nil, nil, fmt.Errorf("encode operation selector: %w", err)
+	}
+	if o.Tenants == nil {
+		return selector, []byte("[]"), nil
+	}
+	if tenants, err = json.Marshal(o.Tenants); err != nil {
+		return nil, nil, fmt.Errorf("encode operation tenants: %w", err)
+	}
+	return selector, tenants, nil
+}
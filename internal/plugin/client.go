@@ -0,0 +1,195 @@
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	"go.uber.org/zap"
+)
+
+const killTimeout = 2 * time.Second
+
+// Client is a running plugin process
+type Client struct {
+	path   string
+	kind   string
+	name   string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	rpc    *rpc.Client
+	exited chan struct{}
+	logger *zap.Logger
+}
+
+// Launch starts the plugin binary at path and completes the handshake.
+// The plugin must print its handshake within startTimeout.
+func Launch(ctx context.Context, path string, startTimeout time.Duration, logger *zap.Logger) (*Client, error) {
+	logger = logger.With(zap.String("component", "plugin"), zap.String("plugin_path", path))
+
+	cmd := exec.Command(path)
+	cmd.Env = append(os.Environ(), MagicCookieKey+"="+MagicCookieValue)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin stdout: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin stderr: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start plugin %s: %w", path, err)
+	}
+
+	c := &Client{
+		path:   path,
+		cmd:    cmd,
+		stdin:  stdin,
+		exited: make(chan struct{}),
+		logger: logger,
+	}
+
+	// The first stdout line is the handshake; everything after it, and all of stderr, is plugin log output
+	lines := make(chan string, 1)
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go func() {
+		defer readers.Done()
+		scanner := bufio.NewScanner(stdout)
+		first := true
+		for scanner.Scan() {
+			if first {
+				lines <- scanner.Text()
+				first = false
+				continue
+			}
+			logger.Info(scanner.Text(), zap.String("stream", "stdout"))
+		}
+		if first {
+			close(lines)
+		}
+	}()
+	go func() {
+		defer readers.Done()
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			logger.Info(scanner.Text(), zap.String("stream", "stderr"))
+		}
+	}()
+	go func() {
+		// Wait closes the pipes, so it must not run until the readers have drained them
+		readers.Wait()
+		err := cmd.Wait()
+		logger.Info("plugin exited", zap.Error(err))
+		close(c.exited)
+	}()
+
+	var line string
+	var ok bool
+	select {
+	case line, ok = <-lines:
+		if !ok {
+			c.Kill()
+			return nil, fmt.Errorf("%w: %s exited before completing the handshake", ErrHandshake, path)
+		}
+	case <-time.After(startTimeout):
+		c.Kill()
+		return nil, fmt.Errorf("%w: %s did not complete the handshake within %s", ErrHandshake, path, startTimeout)
+	case <-ctx.Done():
+		c.Kill()
+		return nil, ctx.Err()
+	}
+
+	h, err := parseHandshake(line)
+	if err != nil {
+		c.Kill()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	conn, err := net.DialTimeout(h.Network, h.Address, startTimeout)
+	if err != nil {
+		c.Kill()
+		return nil, fmt.Errorf("connect to plugin %s: %w", path, err)
+	}
+	c.rpc = jsonrpc.NewClient(conn)
+	c.kind, c.name = h.Kind, h.Name
+	c.logger = logger.With(zap.String("plugin_kind", h.Kind), zap.String("plugin_name", h.Name))
+
+	c.logger.Info("plugin started", zap.Int("pid", cmd.Process.Pid))
+	return c, nil
+}
+
+// Kind returns the plugin kind (compute or workflow)
+func (c *Client) Kind() string {
+	return c.kind
+}
+
+// Name returns the provider name the plugin registered
+func (c *Client) Name() string {
+	return c.name
+}
+
+// ComputeProvider returns a compute.Provider backed by the plugin
+func (c *Client) ComputeProvider() (compute.Provider, error) {
+	if c.kind != KindCompute {
+		return nil, fmt.Errorf("plugin %s is a %s plugin, not a compute plugin", c.name, c.kind)
+	}
+	return newComputeClient(c.rpc)
+}
+
+// WorkflowProvider returns a workflow.Provider backed by the plugin
+func (c *Client) WorkflowProvider() (workflow.Provider, error) {
+	if c.kind != KindWorkflow {
+		return nil, fmt.Errorf("plugin %s is a %s plugin, not a workflow plugin", c.name, c.kind)
+	}
+	return newWorkflowClient(c.rpc)
+}
+
+// Exited is closed when the plugin process exits
+func (c *Client) Exited() <-chan struct{} {
+	return c.exited
+}
+
+// Kill stops the plugin: closing stdin asks it to exit, and it is killed if it has not exited after a grace period
+func (c *Client) Kill() {
+	if c.rpc != nil {
+		c.rpc.Close()
+	}
+	c.stdin.Close()
+
+	select {
+	case <-c.exited:
+	case <-time.After(killTimeout):
+		c.logger.Warn("plugin did not exit, killing it")
+		_ = c.cmd.Process.Kill()
+		<-c.exited
+	}
+}
+
+// call invokes an RPC method, giving up when ctx is done
+func call(ctx context.Context, client *rpc.Client, method string, args, reply interface{}) error {
+	pending := client.Go(method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case done := <-pending.Done:
+		if done.Error != nil {
+			return fmt.Errorf("plugin call %s: %w", method, done.Error)
+		}
+		return nil
+	}
+}
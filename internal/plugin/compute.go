@@ -0,0 +1,197 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"net/rpc"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// ComputeSpecArgs is the request for Compute.Provision, Compute.Update and Compute.Validate.
+// RPC messages are exported because net/rpc only registers methods with exported argument types.
+type ComputeSpecArgs struct {
+	callContext
+	TenantID string                     `json:"tenant_id,omitempty"`
+	Spec     *compute.TenantComputeSpec `json:"spec,omitempty"`
+}
+
+// ComputeTenantArgs is the request for Compute.Destroy and Compute.GetStatus
+type ComputeTenantArgs struct {
+	callContext
+	TenantID string `json:"tenant_id"`
+}
+
+// ComputeConfigArgs is the request for Compute.ValidateConfig
+type ComputeConfigArgs struct {
+	Config json.RawMessage `json:"config"`
+}
+
+// ComputeProvisionReply is the response to Compute.Provision
+type ComputeProvisionReply struct {
+	Result *compute.ProvisionResult `json:"result,omitempty"`
+	Error  *RemoteError             `json:"error,omitempty"`
+}
+
+// ComputeUpdateReply is the response to Compute.Update
+type ComputeUpdateReply struct {
+	Result *compute.UpdateResult `json:"result,omitempty"`
+	Error  *RemoteError          `json:"error,omitempty"`
+}
+
+// ComputeStatusReply is the response to Compute.GetStatus
+type ComputeStatusReply struct {
+	Status *compute.ComputeStatus `json:"status,omitempty"`
+	Error  *RemoteError           `json:"error,omitempty"`
+}
+
+// ErrorReply is the response to calls that only return an error
+type ErrorReply struct {
+	Error *RemoteError `json:"error,omitempty"`
+}
+
+// ComputeDescribeReply is the response to Compute.Describe
+type ComputeDescribeReply struct {
+	Name           string          `json:"name"`
+	ConfigSchema   json.RawMessage `json:"config_schema,omitempty"`
+	ConfigDefaults json.RawMessage `json:"config_defaults,omitempty"`
+}
+
+// computeServer exposes a compute.Provider over RPC inside the plugin process
+type computeServer struct {
+	provider compute.Provider
+}
+
+func (s *computeServer) Describe(_ struct{}, reply *ComputeDescribeReply) error {
+	reply.Name = s.provider.Name()
+	reply.ConfigSchema = s.provider.ConfigSchema()
+	reply.ConfigDefaults = s.provider.ConfigDefaults()
+	return nil
+}
+
+func (s *computeServer) Provision(args ComputeSpecArgs, reply *ComputeProvisionReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	result, err := s.provider.Provision(ctx, args.Spec)
+	reply.Result, reply.Error = result, toRemoteError(err)
+	return nil
+}
+
+func (s *computeServer) Update(args ComputeSpecArgs, reply *ComputeUpdateReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	result, err := s.provider.Update(ctx, args.TenantID, args.Spec)
+	reply.Result, reply.Error = result, toRemoteError(err)
+	return nil
+}
+
+func (s *computeServer) Destroy(args ComputeTenantArgs, reply *ErrorReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	reply.Error = toRemoteError(s.provider.Destroy(ctx, args.TenantID))
+	return nil
+}
+
+func (s *computeServer) GetStatus(args ComputeTenantArgs, reply *ComputeStatusReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	status, err := s.provider.GetStatus(ctx, args.TenantID)
+	reply.Status, reply.Error = status, toRemoteError(err)
+	return nil
+}
+
+func (s *computeServer) Validate(args ComputeSpecArgs, reply *ErrorReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	reply.Error = toRemoteError(s.provider.Validate(ctx, args.Spec))
+	return nil
+}
+
+func (s *computeServer) ValidateConfig(args ComputeConfigArgs, reply *ErrorReply) error {
+	reply.Error = toRemoteError(s.provider.ValidateConfig(args.Config))
+	return nil
+}
+
+// computeClient implements compute.Provider by calling a plugin
+type computeClient struct {
+	rpc            *rpc.Client
+	name           string
+	configSchema   json.RawMessage
+	configDefaults json.RawMessage
+}
+
+var _ compute.Provider = (*computeClient)(nil)
+
+func newComputeClient(client *rpc.Client) (*computeClient, error) {
+	var describe ComputeDescribeReply
+	if err := call(context.Background(), client, "Compute.Describe", struct{}{}, &describe); err != nil {
+		return nil, err
+	}
+	// Schema and defaults are static, so they are fetched once instead of on every request
+	return &computeClient{
+		rpc:            client,
+		name:           describe.Name,
+		configSchema:   describe.ConfigSchema,
+		configDefaults: describe.ConfigDefaults,
+	}, nil
+}
+
+func (c *computeClient) Name() string {
+	return c.name
+}
+
+func (c *computeClient) Provision(ctx context.Context, spec *compute.TenantComputeSpec) (*compute.ProvisionResult, error) {
+	var reply ComputeProvisionReply
+	if err := call(ctx, c.rpc, "Compute.Provision", ComputeSpecArgs{callContext: newCallContext(ctx), Spec: spec}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Result, fromRemoteError(reply.Error)
+}
+
+func (c *computeClient) Update(ctx context.Context, tenantID string, spec *compute.TenantComputeSpec) (*compute.UpdateResult, error) {
+	var reply ComputeUpdateReply
+	if err := call(ctx, c.rpc, "Compute.Update", ComputeSpecArgs{callContext: newCallContext(ctx), TenantID: tenantID, Spec: spec}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Result, fromRemoteError(reply.Error)
+}
+
+func (c *computeClient) Destroy(ctx context.Context, tenantID string) error {
+	var reply ErrorReply
+	if err := call(ctx, c.rpc, "Compute.Destroy", ComputeTenantArgs{callContext: newCallContext(ctx), TenantID: tenantID}, &reply); err != nil {
+		return err
+	}
+	return fromRemoteError(reply.Error)
+}
+
+func (c *computeClient) GetStatus(ctx context.Context, tenantID string) (*compute.ComputeStatus, error) {
+	var reply ComputeStatusReply
+	if err := call(ctx, c.rpc, "Compute.GetStatus", ComputeTenantArgs{callContext: newCallContext(ctx), TenantID: tenantID}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Status, fromRemoteError(reply.Error)
+}
+
+func (c *computeClient) Validate(ctx context.Context, spec *compute.TenantComputeSpec) error {
+	var reply ErrorReply
+	if err := call(ctx, c.rpc, "Compute.Validate", ComputeSpecArgs{callContext: newCallContext(ctx), Spec: spec}, &reply); err != nil {
+		return err
+	}
+	return fromRemoteError(reply.Error)
+}
+
+func (c *computeClient) ValidateConfig(config json.RawMessage) error {
+	var reply ErrorReply
+	if err := call(context.Background(), c.rpc, "Compute.ValidateConfig", ComputeConfigArgs{Config: config}, &reply); err != nil {
+		return err
+	}
+	return fromRemoteError(reply.Error)
+}
+
+func (c *computeClient) ConfigSchema() json.RawMessage {
+	return c.configSchema
+}
+
+func (c *computeClient) ConfigDefaults() json.RawMessage {
+	return c.configDefaults
+}
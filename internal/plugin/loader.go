@@ -0,0 +1,119 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	"go.uber.org/zap"
+)
+
+// Set is the collection of plugins launched from the plugins directory
+type Set struct {
+	clients []*Client
+	logger  *zap.Logger
+}
+
+// Discover returns the executable regular files in dir, sorted by name.
+// Hidden files are skipped so editors and package managers can leave files alongside plugins.
+func Discover(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read plugins dir: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		if entry.Name()[0] == '.' {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("stat plugin %s: %w", entry.Name(), err)
+		}
+		if !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Load launches every plugin in the configured directory.
+// A plugin that fails to start fails the whole load, and any plugins already started are stopped.
+func Load(ctx context.Context, cfg config.PluginsConfig, logger *zap.Logger) (*Set, error) {
+	set := &Set{logger: logger.With(zap.String("component", "plugin-loader"))}
+	if !cfg.Enabled() {
+		return set, nil
+	}
+
+	paths, err := Discover(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		client, err := Launch(ctx, path, cfg.StartTimeout, logger)
+		if err != nil {
+			set.Close()
+			return nil, err
+		}
+		set.clients = append(set.clients, client)
+	}
+
+	set.logger.Info("plugins loaded", zap.String("dir", cfg.Dir), zap.Int("count", len(set.clients)))
+	return set, nil
+}
+
+// Register adds the plugins' providers to the registries.
+// A nil registry skips plugins of that kind, e.g. workers have no workflow provider registry.
+func (s *Set) Register(computeRegistry *compute.Registry, workflowRegistry *workflow.Registry) error {
+	for _, client := range s.clients {
+		switch client.Kind() {
+		case KindCompute:
+			if computeRegistry == nil {
+				s.logger.Info("skipping compute plugin", zap.String("name", client.Name()))
+				continue
+			}
+			provider, err := client.ComputeProvider()
+			if err != nil {
+				return err
+			}
+			if err := computeRegistry.Register(provider); err != nil {
+				return fmt.Errorf("register compute plugin %s: %w", provider.Name(), err)
+			}
+		case KindWorkflow:
+			if workflowRegistry == nil {
+				s.logger.Info("skipping workflow plugin", zap.String("name", client.Name()))
+				continue
+			}
+			provider, err := client.WorkflowProvider()
+			if err != nil {
+				return err
+			}
+			if err := workflowRegistry.Register(provider); err != nil {
+				return fmt.Errorf("register workflow plugin %s: %w", provider.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Clients returns the running plugins
+func (s *Set) Clients() []*Client {
+	return s.clients
+}
+
+// Close stops every plugin
+func (s *Set) Close() {
+	for _, client := range s.clients {
+		client.Kill()
+	}
+	s.clients = nil
+}
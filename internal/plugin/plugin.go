@@ -0,0 +1,211 @@
+// Package plugin runs compute and workflow providers out of process.
+//
+// A plugin is an executable that calls Serve with a single provider. Landlord launches every
+// executable in the configured plugins directory, checks the handshake the plugin prints on
+// stdout, and talks to it with JSON-RPC over a local socket. The launched plugins then
+// register like compiled-in providers.
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
+)
+
+const (
+	// CoreProtocolVersion versions the handshake line and transport
+	CoreProtocolVersion = 1
+
+	// ProtocolVersion versions the provider RPC surface; bump it on incompatible changes
+	ProtocolVersion = 1
+
+	// MagicCookieKey and MagicCookieValue tell a plugin binary it was launched by Landlord.
+	// They are a UX guard against running a plugin by hand, not a security measure.
+	MagicCookieKey   = "LANDLORD_PLUGIN_MAGIC_COOKIE"
+	MagicCookieValue = "4c8a1f0e3b7d9a2c5e6f8b1d0a3c7e9f"
+
+	// transportJSONRPC is the only transport; see docs/plugins.md for why it is not gRPC
+	transportJSONRPC = "jsonrpc"
+)
+
+// Plugin kinds
+const (
+	KindCompute  = "compute"
+	KindWorkflow = "workflow"
+)
+
+var (
+	// ErrHandshake is returned when a plugin does not complete a valid handshake
+	ErrHandshake = errors.New("plugin handshake failed")
+
+	// ErrIncompatibleVersion is returned when a plugin speaks a different protocol version
+	ErrIncompatibleVersion = errors.New("incompatible plugin protocol version")
+)
+
+// handshake is the line a plugin prints on stdout once it is listening:
+// CORE-VERSION|PROTOCOL-VERSION|NETWORK|ADDRESS|TRANSPORT|KIND|NAME
+type handshake struct {
+	CoreVersion     int
+	ProtocolVersion int
+	Network         string
+	Address         string
+	Transport       string
+	Kind            string
+	Name            string
+}
+
+func (h handshake) String() string {
+	return strings.Join([]string{
+		strconv.Itoa(h.CoreVersion),
+		strconv.Itoa(h.ProtocolVersion),
+		h.Network,
+		h.Address,
+		h.Transport,
+		h.Kind,
+		h.Name,
+	}, "|")
+}
+
+func parseHandshake(line string) (*handshake, error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 7 {
+		return nil, fmt.Errorf("%w: expected 7 fields, got %q", ErrHandshake, line)
+	}
+
+	core, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid core version %q", ErrHandshake, parts[0])
+	}
+	if core != CoreProtocolVersion {
+		return nil, fmt.Errorf("%w: core version %d, expected %d", ErrIncompatibleVersion, core, CoreProtocolVersion)
+	}
+	version, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid protocol version %q", ErrHandshake, parts[1])
+	}
+	if version != ProtocolVersion {
+		return nil, fmt.Errorf("%w: plugin speaks version %d, expected %d", ErrIncompatibleVersion, version, ProtocolVersion)
+	}
+
+	h := &handshake{
+		CoreVersion:     core,
+		ProtocolVersion: version,
+		Network:         parts[2],
+		Address:         parts[3],
+		Transport:       parts[4],
+		Kind:            parts[5],
+		Name:            parts[6],
+	}
+	if h.Network != "unix" && h.Network != "tcp" {
+		return nil, fmt.Errorf("%w: unsupported network %q", ErrHandshake, h.Network)
+	}
+	if h.Transport != transportJSONRPC {
+		return nil, fmt.Errorf("%w: unsupported transport %q", ErrHandshake, h.Transport)
+	}
+	if h.Kind != KindCompute && h.Kind != KindWorkflow {
+		return nil, fmt.Errorf("%w: unknown plugin kind %q", ErrHandshake, h.Kind)
+	}
+	if h.Name == "" {
+		return nil, fmt.Errorf("%w: plugin name is empty", ErrHandshake)
+	}
+	return h, nil
+}
+
+// sentinels are the provider errors callers match with errors.Is; they keep their identity across
+// the wire. Errors are matched in order, so an error wrapping several sentinels reports the first.
+var sentinels = []struct {
+	code string
+	err  error
+}{
+	{"compute_tenant_not_found", compute.ErrTenantNotFound},
+	{"compute_already_provisioned", compute.ErrAlreadyProvisioned},
+	{"compute_partially_provisioned", compute.ErrPartiallyProvisioned},
+	{"compute_invalid_spec", compute.ErrInvalidSpec},
+	{"compute_invalid_config", compute.ErrInvalidConfig},
+	{"compute_quota_exceeded", compute.ErrQuotaExceeded},
+	{"compute_provider_unavailable", compute.ErrProviderUnavailable},
+	{"compute_provider_disabled", compute.ErrProviderDisabled},
+	{"compute_reconfigure_unsupported", compute.ErrReconfigureUnsupported},
+	{"compute_retriable", compute.ErrRetriable},
+	{"compute_provision_failed", compute.ErrProvisionFailed},
+	{"compute_update_failed", compute.ErrUpdateFailed},
+	{"workflow_invalid_spec", workflow.ErrInvalidSpec},
+	{"workflow_not_found", workflow.ErrWorkflowNotFound},
+	{"workflow_execution_not_found", workflow.ErrExecutionNotFound},
+	{"workflow_provider_disabled", workflow.ErrProviderDisabled},
+	{"workflow_landlord_tenant_not_found", workflow.ErrLandlordTenantNotFound},
+	{"workflow_reconfigure_unsupported", workflow.ErrReconfigureUnsupported},
+	{"workflow_readiness_probe_failed", workflow.ErrReadinessProbeFailed},
+	{"workflow_execution_failed", workflow.ErrExecutionFailed},
+	{"schema_incompatible", schema.ErrIncompatible},
+}
+
+// RemoteError is an error returned by a plugin's provider
+type RemoteError struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *RemoteError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the provider sentinel the plugin reported, so errors.Is works across the wire
+func (e *RemoteError) Unwrap() error {
+	for _, sentinel := range sentinels {
+		if sentinel.code == e.Code {
+			return sentinel.err
+		}
+	}
+	return nil
+}
+
+// toRemoteError encodes a provider error for the reply
+func toRemoteError(err error) *RemoteError {
+	if err == nil {
+		return nil
+	}
+	remote := &RemoteError{Message: err.Error()}
+	for _, sentinel := range sentinels {
+		if errors.Is(err, sentinel.err) {
+			remote.Code = sentinel.code
+			break
+		}
+	}
+	return remote
+}
+
+// fromRemoteError turns a reply error back into an error value
+func fromRemoteError(remote *RemoteError) error {
+	if remote == nil {
+		return nil
+	}
+	return remote
+}
+
+// callContext carries the caller's deadline to the plugin
+type callContext struct {
+	Deadline *time.Time `json:"deadline,omitempty"`
+}
+
+func newCallContext(ctx context.Context) callContext {
+	if deadline, ok := ctx.Deadline(); ok {
+		return callContext{Deadline: &deadline}
+	}
+	return callContext{}
+}
+
+// context rebuilds a context with the caller's deadline on the plugin side
+func (c callContext) context() (context.Context, context.CancelFunc) {
+	if c.Deadline != nil {
+		return context.WithDeadline(context.Background(), *c.Deadline)
+	}
+	return context.WithCancel(context.Background())
+}
@@ -0,0 +1,241 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	computemock "github.com/jaxxstorm/landlord/internal/compute/providers/mock"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	workflowmock "github.com/jaxxstorm/landlord/internal/workflow/providers/mock"
+	"github.com/jaxxstorm/landlord/pkg/computetest"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+const testPluginEnv = "LANDLORD_TEST_PLUGIN"
+
+// TestMain lets the test binary double as a plugin binary
+func TestMain(m *testing.M) {
+	switch os.Getenv(testPluginEnv) {
+	case "":
+		os.Exit(m.Run())
+	case KindCompute:
+		Serve(&ServeConfig{Compute: computemock.New()})
+	case KindWorkflow:
+		Serve(&ServeConfig{Workflow: workflowmock.New(zap.NewNop())})
+	case "old-version":
+		fmt.Println(handshake{CoreVersion: CoreProtocolVersion, ProtocolVersion: ProtocolVersion + 1, Network: "unix", Address: "/tmp/x", Transport: transportJSONRPC, Kind: KindCompute, Name: "old"})
+		time.Sleep(time.Minute)
+	case "silent":
+		time.Sleep(time.Minute)
+	}
+	os.Exit(0)
+}
+
+// writePlugin writes an executable wrapper that runs this test binary as the given plugin
+func writePlugin(t *testing.T, dir, name, kind string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	script := fmt.Sprintf("#!/bin/sh\n%s=%s exec %q\n", testPluginEnv, kind, os.Args[0])
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+	return path
+}
+
+func TestParseHandshake(t *testing.T) {
+	valid := handshake{CoreVersion: 1, ProtocolVersion: ProtocolVersion, Network: "unix", Address: "/tmp/p.sock", Transport: "jsonrpc", Kind: KindCompute, Name: "nomad"}
+	parsed, err := parseHandshake(valid.String() + "\n")
+	require.NoError(t, err)
+	require.Equal(t, valid, *parsed)
+
+	tests := map[string]struct {
+		line string
+		err  error
+	}{
+		"too few fields":   {"1|1|unix", ErrHandshake},
+		"core version":     {"2|1|unix|/tmp/p.sock|jsonrpc|compute|nomad", ErrIncompatibleVersion},
+		"protocol version": {"1|99|unix|/tmp/p.sock|jsonrpc|compute|nomad", ErrIncompatibleVersion},
+		"network":          {"1|1|udp|/tmp/p.sock|jsonrpc|compute|nomad", ErrHandshake},
+		"transport":        {"1|1|unix|/tmp/p.sock|grpc|compute|nomad", ErrHandshake},
+		"kind":             {"1|1|unix|/tmp/p.sock|jsonrpc|dns|nomad", ErrHandshake},
+		"empty name":       {"1|1|unix|/tmp/p.sock|jsonrpc|compute|", ErrHandshake},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseHandshake(tt.line)
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestRemoteErrorPreservesSentinel(t *testing.T) {
+	remote := toRemoteError(fmt.Errorf("%w: tenant-1", compute.ErrTenantNotFound))
+	require.Equal(t, "compute_tenant_not_found", remote.Code)
+	require.ErrorIs(t, fromRemoteError(remote), compute.ErrTenantNotFound)
+
+	for _, sentinel := range sentinels {
+		remote := toRemoteError(fmt.Errorf("provider: %w", sentinel.err))
+		require.Equal(t, sentinel.code, remote.Code)
+		require.ErrorIs(t, fromRemoteError(remote), sentinel.err)
+	}
+
+	plain := toRemoteError(errors.New("boom"))
+	require.Empty(t, plain.Code)
+	require.EqualError(t, fromRemoteError(plain), "boom")
+	require.NoError(t, fromRemoteError(toRemoteError(nil)))
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b-plugin"), []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a-plugin"), []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("docs"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("#!/bin/sh\n"), 0o755))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "subdir"), 0o755))
+
+	paths, err := Discover(dir)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "a-plugin"), filepath.Join(dir, "b-plugin")}, paths)
+}
+
+func TestComputePlugin(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "landlord-compute-mock", KindCompute)
+	ctx := context.Background()
+
+	set, err := Load(ctx, config.PluginsConfig{Dir: dir, StartTimeout: 10 * time.Second}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer set.Close()
+
+	registry := compute.NewRegistry(zaptest.NewLogger(t))
+	require.NoError(t, set.Register(registry, nil))
+	provider, err := registry.Get("mock")
+	require.NoError(t, err)
+
+	spec := &compute.TenantComputeSpec{
+		TenantID:     "tenant-1",
+		ProviderType: "mock",
+		Containers:   []compute.ContainerSpec{{Name: "app", Image: "nginx:latest", Ports: []compute.PortMapping{{ContainerPort: 80}}}},
+	}
+	result, err := provider.Provision(ctx, spec)
+	require.NoError(t, err)
+	require.Equal(t, "tenant-1", result.TenantID)
+
+	status, err := provider.GetStatus(ctx, "tenant-1")
+	require.NoError(t, err)
+	require.Equal(t, "tenant-1", status.TenantID)
+
+	require.NoError(t, provider.Destroy(ctx, "tenant-1"))
+	_, err = provider.GetStatus(ctx, "tenant-1")
+	require.ErrorIs(t, err, compute.ErrTenantNotFound)
+
+	client := set.Clients()[0]
+	set.Close()
+	select {
+	case <-client.Exited():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected plugin to exit after Close")
+	}
+}
+
+// TestComputePluginConformance runs the compute conformance suite over the plugin transport, so
+// sentinel errors and results survive the round trip
+func TestComputePluginConformance(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "landlord-compute-mock", KindCompute)
+
+	computetest.Run(t, computetest.Config{
+		NewProvider: func(t *testing.T) compute.Provider {
+			set, err := Load(context.Background(), config.PluginsConfig{Dir: dir, StartTimeout: 10 * time.Second}, zap.NewNop())
+			require.NoError(t, err)
+			t.Cleanup(func() { set.Close() })
+
+			registry := compute.NewRegistry(zap.NewNop())
+			require.NoError(t, set.Register(registry, nil))
+			provider, err := registry.Get("mock")
+			require.NoError(t, err)
+			return provider
+		},
+		PollInterval: 10 * time.Millisecond,
+	})
+}
+
+func TestWorkflowPlugin(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "landlord-workflow-mock", KindWorkflow)
+	ctx := context.Background()
+
+	set, err := Load(ctx, config.PluginsConfig{Dir: dir, StartTimeout: 10 * time.Second}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	defer set.Close()
+
+	registry := workflow.NewRegistry(zaptest.NewLogger(t))
+	require.NoError(t, set.Register(compute.NewRegistry(zaptest.NewLogger(t)), registry))
+	provider, err := registry.Get("mock")
+	require.NoError(t, err)
+
+	request := &workflow.ProvisionRequest{
+		TenantID:      "tenant-1",
+		Operation:     "provision",
+		DesiredConfig: map[string]interface{}{"image": "nginx:latest", "replicas": float64(2)},
+	}
+	_, err = provider.Invoke(ctx, "tenant-provisioning", request)
+	require.ErrorIs(t, err, workflow.ErrWorkflowNotFound)
+
+	_, err = provider.CreateWorkflow(ctx, &workflow.WorkflowSpec{
+		WorkflowID:   "tenant-provisioning",
+		ProviderType: "mock",
+		Name:         "Tenant provisioning",
+		Definition:   []byte(`{}`),
+	})
+	require.NoError(t, err)
+
+	result, err := provider.Invoke(ctx, "tenant-provisioning", request)
+	require.NoError(t, err)
+	require.NotEmpty(t, result.ExecutionID)
+
+	status, err := provider.GetExecutionStatus(ctx, result.ExecutionID)
+	require.NoError(t, err)
+	require.Equal(t, result.ExecutionID, status.ExecutionID)
+}
+
+func TestLaunchFailures(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	t.Run("incompatible version", func(t *testing.T) {
+		path := writePlugin(t, t.TempDir(), "old", "old-version")
+		_, err := Launch(context.Background(), path, 10*time.Second, logger)
+		require.ErrorIs(t, err, ErrIncompatibleVersion)
+	})
+
+	t.Run("no handshake", func(t *testing.T) {
+		path := writePlugin(t, t.TempDir(), "silent", "silent")
+		_, err := Launch(context.Background(), path, 200*time.Millisecond, logger)
+		require.ErrorIs(t, err, ErrHandshake)
+	})
+
+	t.Run("exits early", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "false")
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\nexit 3\n"), 0o755))
+		_, err := Launch(context.Background(), path, 10*time.Second, logger)
+		require.ErrorIs(t, err, ErrHandshake)
+		require.Contains(t, err.Error(), "exited before completing the handshake")
+	})
+}
+
+func TestServeRequiresMagicCookie(t *testing.T) {
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), testPluginEnv+"="+KindCompute)
+	output, err := cmd.CombinedOutput()
+	require.Error(t, err)
+	require.True(t, strings.Contains(string(output), "is a Landlord plugin"), string(output))
+}
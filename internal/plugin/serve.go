@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// ServeConfig declares the provider a plugin binary serves. Set exactly one field.
+type ServeConfig struct {
+	Compute  compute.Provider
+	Workflow workflow.Provider
+}
+
+// Serve runs the plugin's RPC server and blocks until Landlord stops the plugin.
+// Call it from the plugin binary's main function; it exits the process on failure.
+func Serve(cfg *ServeConfig) {
+	if err := serve(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "landlord plugin: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func serve(cfg *ServeConfig) error {
+	if os.Getenv(MagicCookieKey) != MagicCookieValue {
+		return fmt.Errorf("this binary is a Landlord plugin; place it in the configured plugins directory instead of running it directly")
+	}
+	if cfg == nil || (cfg.Compute == nil) == (cfg.Workflow == nil) {
+		return fmt.Errorf("exactly one of Compute or Workflow must be set")
+	}
+
+	server := rpc.NewServer()
+	var kind, name string
+	var err error
+	if cfg.Compute != nil {
+		kind, name = KindCompute, cfg.Compute.Name()
+		err = server.RegisterName("Compute", &computeServer{provider: cfg.Compute})
+	} else {
+		kind, name = KindWorkflow, cfg.Workflow.Name()
+		err = server.RegisterName("Workflow", &workflowServer{provider: cfg.Workflow})
+	}
+	if err != nil {
+		return fmt.Errorf("register rpc server: %w", err)
+	}
+
+	listener, cleanup, err := listen()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	// Stop on a signal, or when Landlord closes our stdin (including when it dies)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		stop()
+	}()
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	fmt.Fprintln(os.Stdout, handshake{
+		CoreVersion:     CoreProtocolVersion,
+		ProtocolVersion: ProtocolVersion,
+		Network:         listener.Addr().Network(),
+		Address:         listener.Addr().String(),
+		Transport:       transportJSONRPC,
+		Kind:            kind,
+		Name:            name,
+	}.String())
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("accept: %w", err)
+		}
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// listen opens a unix socket in a private temp directory, falling back to loopback TCP
+func listen() (net.Listener, func(), error) {
+	dir, err := os.MkdirTemp("", "landlord-plugin-")
+	if err == nil {
+		listener, err := net.Listen("unix", filepath.Join(dir, "plugin.sock"))
+		if err == nil {
+			return listener, func() { os.RemoveAll(dir) }, nil
+		}
+		os.RemoveAll(dir)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, nil, fmt.Errorf("listen: %w", err)
+	}
+	return listener, func() {}, nil
+}
@@ -0,0 +1,244 @@
+package plugin
+
+import (
+	"context"
+	"net/rpc"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// WorkflowInvokeArgs is the request for Workflow.Invoke
+type WorkflowInvokeArgs struct {
+	callContext
+	WorkflowID string                     `json:"workflow_id"`
+	Request    *workflow.ProvisionRequest `json:"request,omitempty"`
+}
+
+// WorkflowSpecArgs is the request for Workflow.CreateWorkflow and Workflow.Validate
+type WorkflowSpecArgs struct {
+	callContext
+	Spec *workflow.WorkflowSpec `json:"spec,omitempty"`
+}
+
+// WorkflowStartArgs is the request for Workflow.StartExecution
+type WorkflowStartArgs struct {
+	callContext
+	WorkflowID string                   `json:"workflow_id"`
+	Input      *workflow.ExecutionInput `json:"input,omitempty"`
+}
+
+// WorkflowIDArgs is the request for calls addressed by workflow or execution ID
+type WorkflowIDArgs struct {
+	callContext
+	ID     string `json:"id"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// WorkflowCallbackArgs is the request for Workflow.PostComputeCallback
+type WorkflowCallbackArgs struct {
+	callContext
+	ExecutionID string                   `json:"execution_id"`
+	Payload     *compute.CallbackPayload `json:"payload,omitempty"`
+	Options     *compute.CallbackOptions `json:"options,omitempty"`
+}
+
+// WorkflowExecutionResultReply is the response to Workflow.Invoke and Workflow.StartExecution
+type WorkflowExecutionResultReply struct {
+	Result *workflow.ExecutionResult `json:"result,omitempty"`
+	Error  *RemoteError              `json:"error,omitempty"`
+}
+
+// WorkflowStatusReply is the response to Workflow.GetWorkflowStatus
+type WorkflowStatusReply struct {
+	Status *workflow.WorkflowStatus `json:"status,omitempty"`
+	Error  *RemoteError             `json:"error,omitempty"`
+}
+
+// WorkflowCreateReply is the response to Workflow.CreateWorkflow
+type WorkflowCreateReply struct {
+	Result *workflow.CreateWorkflowResult `json:"result,omitempty"`
+	Error  *RemoteError                   `json:"error,omitempty"`
+}
+
+// WorkflowExecutionStatusReply is the response to Workflow.GetExecutionStatus
+type WorkflowExecutionStatusReply struct {
+	Status *workflow.ExecutionStatus `json:"status,omitempty"`
+	Error  *RemoteError              `json:"error,omitempty"`
+}
+
+// WorkflowDescribeReply is the response to Workflow.Describe
+type WorkflowDescribeReply struct {
+	Name string `json:"name"`
+}
+
+// workflowServer exposes a workflow.Provider over RPC inside the plugin process
+type workflowServer struct {
+	provider workflow.Provider
+}
+
+func (s *workflowServer) Describe(_ struct{}, reply *WorkflowDescribeReply) error {
+	reply.Name = s.provider.Name()
+	return nil
+}
+
+func (s *workflowServer) Invoke(args WorkflowInvokeArgs, reply *WorkflowExecutionResultReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	result, err := s.provider.Invoke(ctx, args.WorkflowID, args.Request)
+	reply.Result, reply.Error = result, toRemoteError(err)
+	return nil
+}
+
+func (s *workflowServer) GetWorkflowStatus(args WorkflowIDArgs, reply *WorkflowStatusReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	status, err := s.provider.GetWorkflowStatus(ctx, args.ID)
+	reply.Status, reply.Error = status, toRemoteError(err)
+	return nil
+}
+
+func (s *workflowServer) CreateWorkflow(args WorkflowSpecArgs, reply *WorkflowCreateReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	result, err := s.provider.CreateWorkflow(ctx, args.Spec)
+	reply.Result, reply.Error = result, toRemoteError(err)
+	return nil
+}
+
+func (s *workflowServer) StartExecution(args WorkflowStartArgs, reply *WorkflowExecutionResultReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	result, err := s.provider.StartExecution(ctx, args.WorkflowID, args.Input)
+	reply.Result, reply.Error = result, toRemoteError(err)
+	return nil
+}
+
+func (s *workflowServer) GetExecutionStatus(args WorkflowIDArgs, reply *WorkflowExecutionStatusReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	status, err := s.provider.GetExecutionStatus(ctx, args.ID)
+	reply.Status, reply.Error = status, toRemoteError(err)
+	return nil
+}
+
+func (s *workflowServer) StopExecution(args WorkflowIDArgs, reply *ErrorReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	reply.Error = toRemoteError(s.provider.StopExecution(ctx, args.ID, args.Reason))
+	return nil
+}
+
+func (s *workflowServer) DeleteWorkflow(args WorkflowIDArgs, reply *ErrorReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	reply.Error = toRemoteError(s.provider.DeleteWorkflow(ctx, args.ID))
+	return nil
+}
+
+func (s *workflowServer) Validate(args WorkflowSpecArgs, reply *ErrorReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	reply.Error = toRemoteError(s.provider.Validate(ctx, args.Spec))
+	return nil
+}
+
+func (s *workflowServer) PostComputeCallback(args WorkflowCallbackArgs, reply *ErrorReply) error {
+	ctx, cancel := args.context()
+	defer cancel()
+	reply.Error = toRemoteError(s.provider.PostComputeCallback(ctx, args.ExecutionID, args.Payload, args.Options))
+	return nil
+}
+
+// workflowClient implements workflow.Provider by calling a plugin
+type workflowClient struct {
+	rpc  *rpc.Client
+	name string
+}
+
+var _ workflow.Provider = (*workflowClient)(nil)
+
+func newWorkflowClient(client *rpc.Client) (*workflowClient, error) {
+	var describe WorkflowDescribeReply
+	if err := call(context.Background(), client, "Workflow.Describe", struct{}{}, &describe); err != nil {
+		return nil, err
+	}
+	return &workflowClient{rpc: client, name: describe.Name}, nil
+}
+
+func (c *workflowClient) Name() string {
+	return c.name
+}
+
+func (c *workflowClient) Invoke(ctx context.Context, workflowID string, request *workflow.ProvisionRequest) (*workflow.ExecutionResult, error) {
+	var reply WorkflowExecutionResultReply
+	if err := call(ctx, c.rpc, "Workflow.Invoke", WorkflowInvokeArgs{callContext: newCallContext(ctx), WorkflowID: workflowID, Request: request}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Result, fromRemoteError(reply.Error)
+}
+
+func (c *workflowClient) GetWorkflowStatus(ctx context.Context, executionID string) (*workflow.WorkflowStatus, error) {
+	var reply WorkflowStatusReply
+	if err := call(ctx, c.rpc, "Workflow.GetWorkflowStatus", WorkflowIDArgs{callContext: newCallContext(ctx), ID: executionID}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Status, fromRemoteError(reply.Error)
+}
+
+func (c *workflowClient) CreateWorkflow(ctx context.Context, spec *workflow.WorkflowSpec) (*workflow.CreateWorkflowResult, error) {
+	var reply WorkflowCreateReply
+	if err := call(ctx, c.rpc, "Workflow.CreateWorkflow", WorkflowSpecArgs{callContext: newCallContext(ctx), Spec: spec}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Result, fromRemoteError(reply.Error)
+}
+
+func (c *workflowClient) StartExecution(ctx context.Context, workflowID string, input *workflow.ExecutionInput) (*workflow.ExecutionResult, error) {
+	var reply WorkflowExecutionResultReply
+	if err := call(ctx, c.rpc, "Workflow.StartExecution", WorkflowStartArgs{callContext: newCallContext(ctx), WorkflowID: workflowID, Input: input}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Result, fromRemoteError(reply.Error)
+}
+
+func (c *workflowClient) GetExecutionStatus(ctx context.Context, executionID string) (*workflow.ExecutionStatus, error) {
+	var reply WorkflowExecutionStatusReply
+	if err := call(ctx, c.rpc, "Workflow.GetExecutionStatus", WorkflowIDArgs{callContext: newCallContext(ctx), ID: executionID}, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Status, fromRemoteError(reply.Error)
+}
+
+func (c *workflowClient) StopExecution(ctx context.Context, executionID string, reason string) error {
+	var reply ErrorReply
+	if err := call(ctx, c.rpc, "Workflow.StopExecution", WorkflowIDArgs{callContext: newCallContext(ctx), ID: executionID, Reason: reason}, &reply); err != nil {
+		return err
+	}
+	return fromRemoteError(reply.Error)
+}
+
+func (c *workflowClient) DeleteWorkflow(ctx context.Context, workflowID string) error {
+	var reply ErrorReply
+	if err := call(ctx, c.rpc, "Workflow.DeleteWorkflow", WorkflowIDArgs{callContext: newCallContext(ctx), ID: workflowID}, &reply); err != nil {
+		return err
+	}
+	return fromRemoteError(reply.Error)
+}
+
+func (c *workflowClient) Validate(ctx context.Context, spec *workflow.WorkflowSpec) error {
+	var reply ErrorReply
+	if err := call(ctx, c.rpc, "Workflow.Validate", WorkflowSpecArgs{callContext: newCallContext(ctx), Spec: spec}, &reply); err != nil {
+		return err
+	}
+	return fromRemoteError(reply.Error)
+}
+
+func (c *workflowClient) PostComputeCallback(ctx context.Context, executionID string, payload *compute.CallbackPayload, opts *compute.CallbackOptions) error {
+	var reply ErrorReply
+	args := WorkflowCallbackArgs{callContext: newCallContext(ctx), ExecutionID: executionID, Payload: payload, Options: opts}
+	if err := call(ctx, c.rpc, "Workflow.PostComputeCallback", args, &reply); err != nil {
+		return err
+	}
+	return fromRemoteError(reply.Error)
+}
@@ -0,0 +1,230 @@
+// Package memory provides an in-memory organization and project store for tests and local harnesses.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/project"
+)
+
+// Store implements project.Store in memory.
+// Projects are copied on the way in and out, so callers never share state with the store.
+type Store struct {
+	mu            sync.RWMutex
+	organizations map[uuid.UUID]project.Organization
+	projects      map[uuid.UUID]project.Project
+}
+
+var _ project.Store = (*Store)(nil)
+
+// New creates a store holding the default organization and project, like a migrated database
+func New() *Store {
+	now := time.Now()
+	return &Store{
+		organizations: map[uuid.UUID]project.Organization{
+			project.DefaultOrganizationID: {
+				ID:          project.DefaultOrganizationID,
+				Name:        project.DefaultName,
+				DisplayName: "Default organization",
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			},
+		},
+		projects: map[uuid.UUID]project.Project{
+			project.DefaultProjectID: {
+				ID:             project.DefaultProjectID,
+				OrganizationID: project.DefaultOrganizationID,
+				Name:           project.DefaultName,
+				DisplayName:    "Default project",
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			},
+		},
+	}
+}
+
+func (s *Store) CreateOrganization(ctx context.Context, org *project.Organization) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.organizations {
+		if existing.Name == org.Name {
+			return project.ErrOrganizationExists
+		}
+	}
+
+	if org.ID == uuid.Nil {
+		org.ID = uuid.New()
+	}
+	now := time.Now()
+	org.CreatedAt = now
+	org.UpdatedAt = now
+	s.organizations[org.ID] = *org
+	return nil
+}
+
+func (s *Store) GetOrganization(ctx context.Context, name string) (*project.Organization, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	org, ok := s.organizationByName(name)
+	if !ok {
+		return nil, project.ErrOrganizationNotFound
+	}
+	return &org, nil
+}
+
+func (s *Store) ListOrganizations(ctx context.Context) ([]*project.Organization, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	orgs := make([]*project.Organization, 0, len(s.organizations))
+	for _, org := range s.organizations {
+		org := org
+		orgs = append(orgs, &org)
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].Name < orgs[j].Name })
+	return orgs, nil
+}
+
+func (s *Store) CreateProject(ctx context.Context, p *project.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	org, ok := s.organizations[p.OrganizationID]
+	if !ok {
+		return project.ErrOrganizationNotFound
+	}
+	for _, existing := range s.projects {
+		if existing.OrganizationID == p.OrganizationID && existing.Name == p.Name {
+			return project.ErrProjectExists
+		}
+	}
+
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	now := time.Now()
+	p.Organization = org.Name
+	p.CreatedAt = now
+	p.UpdatedAt = now
+
+	stored, err := clone(p)
+	if err != nil {
+		return fmt.Errorf("create project: %w", err)
+	}
+	s.projects[p.ID] = *stored
+	return nil
+}
+
+func (s *Store) GetProject(ctx context.Context, organization, name string) (*project.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	org, ok := s.organizationByName(organization)
+	if !ok {
+		return nil, project.ErrProjectNotFound
+	}
+	for _, p := range s.projects {
+		if p.OrganizationID == org.ID && p.Name == name {
+			return s.withOrganization(p)
+		}
+	}
+	return nil, project.ErrProjectNotFound
+}
+
+func (s *Store) GetProjectByID(ctx context.Context, id uuid.UUID) (*project.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.projects[id]
+	if !ok {
+		return nil, project.ErrProjectNotFound
+	}
+	return s.withOrganization(p)
+}
+
+func (s *Store) ListProjects(ctx context.Context, organization string) ([]*project.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	projects := []*project.Project{}
+	org, ok := s.organizationByName(organization)
+	if !ok {
+		return projects, nil
+	}
+	for _, p := range s.projects {
+		if p.OrganizationID != org.ID {
+			continue
+		}
+		copied, err := s.withOrganization(p)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, copied)
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	return projects, nil
+}
+
+func (s *Store) UpdateProject(ctx context.Context, p *project.Project) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.projects[p.ID]
+	if !ok {
+		return project.ErrProjectNotFound
+	}
+	existing.DisplayName = p.DisplayName
+	existing.Settings = p.Settings
+	existing.UpdatedAt = time.Now()
+
+	stored, err := clone(&existing)
+	if err != nil {
+		return fmt.Errorf("update project: %w", err)
+	}
+	s.projects[p.ID] = *stored
+	p.UpdatedAt = existing.UpdatedAt
+	return nil
+}
+
+// organizationByName must be called with the lock held
+func (s *Store) organizationByName(name string) (project.Organization, bool) {
+	for _, org := range s.organizations {
+		if org.Name == name {
+			return org, true
+		}
+	}
+	return project.Organization{}, false
+}
+
+// withOrganization returns a copy of p with its organization name filled in; the lock must be held
+func (s *Store) withOrganization(p project.Project) (*project.Project, error) {
+	copied, err := clone(&p)
+	if err != nil {
+		return nil, err
+	}
+	copied.Organization = s.organizations[p.OrganizationID].Name
+	return copied, nil
+}
+
+// clone deep-copies a project through JSON so settings maps are not shared
+func clone(p *project.Project) (*project.Project, error) {
+	settings, err := json.Marshal(p.Settings)
+	if err != nil {
+		return nil, err
+	}
+	copied := *p
+	copied.Settings = project.Settings{}
+	if err := json.Unmarshal(settings, &copied.Settings); err != nil {
+		return nil, err
+	}
+	return &copied, nil
+}
@@ -0,0 +1,142 @@
+package project
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// notificationTimeout bounds each webhook call
+const notificationTimeout = 5 * time.Second
+
+// StatusChange is the JSON body posted to project webhooks
+type StatusChange struct {
+	Event         string        `json:"event"`
+	TenantID      string        `json:"tenant_id"`
+	TenantName    string        `json:"tenant_name"`
+	Organization  string        `json:"organization"`
+	Project       string        `json:"project"`
+	FromStatus    tenant.Status `json:"from_status"`
+	ToStatus      tenant.Status `json:"to_status"`
+	StatusMessage string        `json:"status_message,omitempty"`
+	Timestamp     time.Time     `json:"timestamp"`
+}
+
+// Notifier posts tenant status changes to the webhooks of the tenant's project.
+// Delivery is best effort: failures are logged and not retried.
+type Notifier struct {
+	store  Store
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewNotifier creates a notifier that reads webhook settings from store
+func NewNotifier(store Store, logger *zap.Logger) *Notifier {
+	return &Notifier{
+		store:  store,
+		client: &http.Client{Timeout: notificationTimeout},
+		logger: logger.With(zap.String("component", "project-notifier")),
+	}
+}
+
+// Notify sends the change to every webhook of the tenant's project that subscribes to its new status
+func (n *Notifier) Notify(ctx context.Context, t *tenant.Tenant, from tenant.Status) {
+	p, err := n.store.GetProjectByID(ctx, t.ProjectID)
+	if err != nil {
+		n.logger.Warn("failed to load project for notification", zap.String("tenant_id", t.ID.String()), zap.Error(err))
+		return
+	}
+
+	body, err := json.Marshal(StatusChange{
+		Event:         "tenant.status_changed",
+		TenantID:      t.ID.String(),
+		TenantName:    t.Name,
+		Organization:  p.Organization,
+		Project:       p.Name,
+		FromStatus:    from,
+		ToStatus:      t.Status,
+		StatusMessage: t.StatusMessage,
+		Timestamp:     time.Now().UTC(),
+	})
+	if err != nil {
+		n.logger.Error("failed to encode notification", zap.Error(err))
+		return
+	}
+
+	for _, webhook := range p.Settings.Notifications.Webhooks {
+		if !webhook.Wants(t.Status) {
+			continue
+		}
+		if err := n.post(ctx, webhook.URL, body); err != nil {
+			n.logger.Warn("tenant notification failed",
+				zap.String("tenant_id", t.ID.String()),
+				zap.String("url", webhook.URL),
+				zap.Error(err))
+		}
+	}
+}
+
+func (n *Notifier) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// NotifyingRepository wraps a tenant repository and notifies project webhooks when an update
+// changes a tenant's status. Notifications are sent in the background after the update commits.
+type NotifyingRepository struct {
+	tenant.Repository
+	notifier *Notifier
+}
+
+// NewNotifyingRepository wraps repo so status changes are sent through notifier
+func NewNotifyingRepository(repo tenant.Repository, notifier *Notifier) *NotifyingRepository {
+	return &NotifyingRepository{Repository: repo, notifier: notifier}
+}
+
+// UpdateTenant updates the tenant and notifies its project when the status changed
+func (r *NotifyingRepository) UpdateTenant(ctx context.Context, t *tenant.Tenant) error {
+	previous, err := r.Repository.GetTenantByID(ctx, t.ID)
+	if err != nil {
+		return r.Repository.UpdateTenant(ctx, t)
+	}
+	if err := r.Repository.UpdateTenant(ctx, t); err != nil {
+		return err
+	}
+	if previous.Status != t.Status {
+		// The caller keeps using t, so the notification gets its own copy
+		go r.notifier.Notify(context.Background(), t.Clone(), previous.Status)
+	}
+	return nil
+}
+
+// FenceWorkflowTrigger runs the fenced trigger and notifies the tenant's project when the write changed its status
+func (r *NotifyingRepository) FenceWorkflowTrigger(ctx context.Context, t *tenant.Tenant, trigger func(ctx context.Context, t *tenant.Tenant) error) error {
+	previous := t.Status
+	if err := r.Repository.FenceWorkflowTrigger(ctx, t, trigger); err != nil {
+		return err
+	}
+	if previous != t.Status {
+		go r.notifier.Notify(context.Background(), t.Clone(), previous)
+	}
+	return nil
+}
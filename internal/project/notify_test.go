@@ -0,0 +1,75 @@
+package project_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/project/memory"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestNotifyingRepositorySendsStatusChanges(t *testing.T) {
+	received := make(chan project.StatusChange, 4)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var change project.StatusChange
+		if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+			t.Errorf("decode notification: %v", err)
+		}
+		received <- change
+	}))
+	defer hook.Close()
+
+	ctx := context.Background()
+	store := memory.New()
+	p, err := store.GetProjectByID(ctx, project.DefaultProjectID)
+	if err != nil {
+		t.Fatalf("GetProjectByID() error = %v", err)
+	}
+	p.Settings.Notifications.Webhooks = []project.Webhook{{URL: hook.URL, Statuses: []tenant.Status{tenant.StatusReady}}}
+	if err := store.UpdateProject(ctx, p); err != nil {
+		t.Fatalf("UpdateProject() error = %v", err)
+	}
+
+	repo := project.NewNotifyingRepository(tenantmemory.New(), project.NewNotifier(store, zap.NewNop()))
+	tn := &tenant.Tenant{Name: "acme", Status: tenant.StatusRequested}
+	if err := repo.CreateTenant(ctx, tn); err != nil {
+		t.Fatalf("CreateTenant() error = %v", err)
+	}
+
+	// Changes into statuses the webhook does not subscribe to are not sent
+	tn.Status = tenant.StatusProvisioning
+	if err := repo.UpdateTenant(ctx, tn); err != nil {
+		t.Fatalf("UpdateTenant() error = %v", err)
+	}
+	tn.Status = tenant.StatusReady
+	tn.StatusMessage = "all replicas running"
+	if err := repo.UpdateTenant(ctx, tn); err != nil {
+		t.Fatalf("UpdateTenant() error = %v", err)
+	}
+
+	select {
+	case change := <-received:
+		if change.Event != "tenant.status_changed" || change.TenantName != "acme" ||
+			change.Organization != project.DefaultName || change.Project != project.DefaultName ||
+			change.FromStatus != tenant.StatusProvisioning || change.ToStatus != tenant.StatusReady ||
+			change.StatusMessage != "all replicas running" {
+			t.Errorf("unexpected notification %+v", change)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for notification")
+	}
+
+	select {
+	case change := <-received:
+		t.Errorf("unexpected extra notification %+v", change)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
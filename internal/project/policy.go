@@ -0,0 +1,155 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// Annotations recording the defaults a tenant received from project policies at create time
+const (
+	AnnotationAppliedPolicies = "landlord/applied_policies"
+	AnnotationAppliedDefaults = "landlord/applied_defaults"
+)
+
+// ErrMissingLabels is returned when a tenant lacks a label that a matching policy requires
+var ErrMissingLabels = errors.New("required labels missing")
+
+// Policy supplies defaults to the project's tenants whose labels match Selector.
+// Defaults only fill what the create request left unset, including nested compute_config fields;
+// they never override it.
+type Policy struct {
+	// Name identifies the policy in the applied-defaults annotation
+	Name string `json:"name"`
+
+	// Selector matches tenant labels exactly; an empty selector matches every tenant
+	Selector map[string]string `json:"selector,omitempty"`
+
+	// ComputeProvider is used when the request names no compute provider
+	ComputeProvider string `json:"compute_provider,omitempty"`
+
+	// ComputeConfig is merged under the request's compute_config
+	ComputeConfig map[string]interface{} `json:"compute_config,omitempty"`
+
+	// RequiredLabels must be present on every selected tenant
+	RequiredLabels []string `json:"required_labels,omitempty"`
+}
+
+// Matches reports whether the policy selects a tenant with these labels
+func (p Policy) Matches(labels map[string]string) bool {
+	for key, value := range p.Selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Applied records what project policies contributed to a create request
+type Applied struct {
+	// Policies are the names of the matching policies, in the order they were applied
+	Policies []string
+
+	// Fields are the top-level compute_config fields that policy defaults filled in or added to
+	Fields []string
+}
+
+// Annotate records the applied policies and fields in annotations, allocating the map if needed
+func (a Applied) Annotate(annotations map[string]string) map[string]string {
+	if len(a.Policies) == 0 {
+		return annotations
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationAppliedPolicies] = strings.Join(a.Policies, ",")
+	if len(a.Fields) > 0 {
+		annotations[AnnotationAppliedDefaults] = strings.Join(a.Fields, ",")
+	}
+	return annotations
+}
+
+// ApplyPolicies fills config from every policy that selects labels, in order, with earlier policies
+// taking precedence over later ones. hasProvider reports whether the request already chose a compute
+// provider, through compute_config, labels or annotations. The returned config is a new map; config
+// itself is not modified. ErrMissingLabels lists every required label that is absent.
+func (s *Settings) ApplyPolicies(labels map[string]string, config map[string]interface{}, hasProvider bool) (map[string]interface{}, Applied, error) {
+	var applied Applied
+	var missing []string
+	merged := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		merged[key] = value
+	}
+	filled := map[string]bool{}
+
+	for _, policy := range s.Policies {
+		if !policy.Matches(labels) {
+			continue
+		}
+		applied.Policies = append(applied.Policies, policy.Name)
+
+		for _, label := range policy.RequiredLabels {
+			if _, ok := labels[label]; !ok && !containsString(missing, label) {
+				missing = append(missing, label)
+			}
+		}
+		if policy.ComputeProvider != "" && !hasProvider {
+			merged["compute_provider"] = policy.ComputeProvider
+			filled["compute_provider"] = true
+			hasProvider = true
+		}
+		if len(policy.ComputeConfig) > 0 {
+			next := compute.MergeConfigMaps(policy.ComputeConfig, merged)
+			for key, value := range next {
+				if before, set := merged[key]; !set || !reflect.DeepEqual(before, value) {
+					filled[key] = true
+				}
+			}
+			merged = next
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, applied, fmt.Errorf("%w: %s", ErrMissingLabels, strings.Join(missing, ", "))
+	}
+	for key := range filled {
+		applied.Fields = append(applied.Fields, key)
+	}
+	sort.Strings(applied.Fields)
+	return merged, applied, nil
+}
+
+func validatePolicies(policies []Policy) error {
+	names := map[string]bool{}
+	for i, policy := range policies {
+		if err := ValidateName("policy", policy.Name); err != nil {
+			return fmt.Errorf("policies[%d]: %w", i, err)
+		}
+		if names[policy.Name] {
+			return fmt.Errorf("%w: policies[%d]: duplicate policy name %q", ErrInvalid, i, policy.Name)
+		}
+		names[policy.Name] = true
+		for _, label := range policy.RequiredLabels {
+			if strings.TrimSpace(label) == "" {
+				return fmt.Errorf("%w: policies[%d]: required label names cannot be empty", ErrInvalid, i)
+			}
+		}
+		if policy.ComputeProvider == "" && len(policy.ComputeConfig) == 0 && len(policy.RequiredLabels) == 0 {
+			return fmt.Errorf("%w: policies[%d]: policy %q sets no defaults or required labels", ErrInvalid, i, policy.Name)
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
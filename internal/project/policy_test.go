@@ -0,0 +1,109 @@
+package project
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApplyPolicies(t *testing.T) {
+	settings := Settings{Policies: []Policy{
+		{
+			Name:            "production",
+			Selector:        map[string]string{"env": "production"},
+			ComputeProvider: "ecs",
+			ComputeConfig:   map[string]interface{}{"resources": map[string]interface{}{"cpu": 1024, "memory": 2048}},
+			RequiredLabels:  []string{"team", "cost-center"},
+		},
+		{
+			Name:            "baseline",
+			ComputeProvider: "docker",
+			ComputeConfig: map[string]interface{}{
+				"resources": map[string]interface{}{"cpu": 256, "memory": 512},
+				"env":       map[string]interface{}{"LOG_LEVEL": "info"},
+			},
+		},
+	}}
+
+	t.Run("fills unset fields in policy order", func(t *testing.T) {
+		request := map[string]interface{}{"image": "nginx", "resources": map[string]interface{}{"memory": 4096}}
+		labels := map[string]string{"env": "production", "team": "web", "cost-center": "42"}
+
+		config, applied, err := settings.ApplyPolicies(labels, request, false)
+		if err != nil {
+			t.Fatalf("ApplyPolicies() error = %v", err)
+		}
+		want := map[string]interface{}{
+			"image":            "nginx",
+			"compute_provider": "ecs",
+			"resources":        map[string]interface{}{"cpu": 1024, "memory": 4096},
+			"env":              map[string]interface{}{"LOG_LEVEL": "info"},
+		}
+		if !reflect.DeepEqual(config, want) {
+			t.Errorf("ApplyPolicies() config = %v, want %v", config, want)
+		}
+		if !reflect.DeepEqual(applied.Policies, []string{"production", "baseline"}) {
+			t.Errorf("applied policies = %v", applied.Policies)
+		}
+		if !reflect.DeepEqual(applied.Fields, []string{"compute_provider", "env", "resources"}) {
+			t.Errorf("applied fields = %v", applied.Fields)
+		}
+		if _, ok := request["compute_provider"]; ok {
+			t.Error("ApplyPolicies() modified the request config")
+		}
+	})
+
+	t.Run("keeps the requested provider", func(t *testing.T) {
+		config, applied, err := settings.ApplyPolicies(nil, map[string]interface{}{"env": map[string]interface{}{"LOG_LEVEL": "debug"}}, true)
+		if err != nil {
+			t.Fatalf("ApplyPolicies() error = %v", err)
+		}
+		if _, ok := config["compute_provider"]; ok {
+			t.Errorf("expected no default provider, got %v", config["compute_provider"])
+		}
+		if !reflect.DeepEqual(applied.Fields, []string{"resources"}) {
+			t.Errorf("applied fields = %v", applied.Fields)
+		}
+	})
+
+	t.Run("reports missing required labels", func(t *testing.T) {
+		_, _, err := settings.ApplyPolicies(map[string]string{"env": "production"}, nil, false)
+		if !errors.Is(err, ErrMissingLabels) {
+			t.Fatalf("ApplyPolicies() error = %v, want ErrMissingLabels", err)
+		}
+	})
+
+	annotations := Applied{Policies: []string{"production", "baseline"}, Fields: []string{"compute_provider", "env"}}.Annotate(nil)
+	if annotations[AnnotationAppliedPolicies] != "production,baseline" || annotations[AnnotationAppliedDefaults] != "compute_provider,env" {
+		t.Errorf("Annotate() = %v", annotations)
+	}
+	if got := (Applied{}).Annotate(nil); got != nil {
+		t.Errorf("Annotate() with nothing applied = %v, want nil", got)
+	}
+}
+
+func TestValidatePolicies(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies []Policy
+		wantErr  bool
+	}{
+		{name: "valid", policies: []Policy{{Name: "baseline", RequiredLabels: []string{"team"}}}},
+		{name: "invalid name", policies: []Policy{{Name: "Base Line", ComputeProvider: "docker"}}, wantErr: true},
+		{name: "duplicate name", policies: []Policy{{Name: "a", ComputeProvider: "docker"}, {Name: "a", ComputeProvider: "ecs"}}, wantErr: true},
+		{name: "empty label", policies: []Policy{{Name: "a", RequiredLabels: []string{" "}}}, wantErr: true},
+		{name: "no effect", policies: []Policy{{Name: "a", Selector: map[string]string{"env": "dev"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := Settings{Policies: tt.policies}
+			err := settings.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalid) {
+				t.Errorf("Validate() error = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/project"
+)
+
+// Store implements project.Store for PostgreSQL
+type Store struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+var _ project.Store = (*Store)(nil)
+
+// New creates a PostgreSQL organization and project store
+// Accepts interface{} to satisfy provider abstraction, type asserts to *pgxpool.Pool
+func New(pool interface{}, logger *zap.Logger) (*Store, error) {
+	pgPool, ok := pool.(*pgxpool.Pool)
+	if !ok {
+		return nil, fmt.Errorf("expected *pgxpool.Pool, got %T", pool)
+	}
+	return &Store{
+		pool:   pgPool,
+		logger: logger.With(zap.String("component", "project-postgres-store")),
+	}, nil
+}
+
+const createOrganizationQuery = `
+INSERT INTO organizations (id, name, display_name)
+VALUES ($1, $2, $3)
+RETURNING created_at, updated_at
+`
+
+func (s *Store) CreateOrganization(ctx context.Context, org *project.Organization) error {
+	if org.ID == uuid.Nil {
+		org.ID = uuid.New()
+	}
+
+	err := s.pool.QueryRow(ctx, createOrganizationQuery, org.ID, org.Name, org.DisplayName).Scan(&org.CreatedAt, &org.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return project.ErrOrganizationExists
+		}
+		return fmt.Errorf("create organization: %w", err)
+	}
+
+	s.logger.Info("organization created", zap.String("id", org.ID.String()), zap.String("name", org.Name))
+	return nil
+}
+
+const organizationColumns = `id, name, COALESCE(display_name, ''), created_at, updated_at`
+
+func (s *Store) GetOrganization(ctx context.Context, name string) (*project.Organization, error) {
+	org, err := scanOrganization(s.pool.QueryRow(ctx, `SELECT `+organizationColumns+` FROM organizations WHERE name = $1`, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, project.ErrOrganizationNotFound
+		}
+		return nil, fmt.Errorf("get organization: %w", err)
+	}
+	return org, nil
+}
+
+func (s *Store) ListOrganizations(ctx context.Context) ([]*project.Organization, error) {
+	rows, err := s.pool.Query(ctx, `SELECT `+organizationColumns+` FROM organizations ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	orgs := []*project.Organization{}
+	for rows.Next() {
+		org, err := scanOrganization(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan organization: %w", err)
+		}
+		orgs = append(orgs, org)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate organizations: %w", err)
+	}
+	return orgs, nil
+}
+
+const createProjectQuery = `
+WITH inserted AS (
+    INSERT INTO projects (id, organization_id, name, display_name, settings)
+    VALUES ($1, $2, $3, $4, $5)
+    RETURNING organization_id, created_at, updated_at
+)
+SELECT o.name, inserted.created_at, inserted.updated_at
+FROM inserted JOIN organizations o ON o.id = inserted.organization_id
+`
+
+func (s *Store) CreateProject(ctx context.Context, p *project.Project) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	settings, err := json.Marshal(p.Settings)
+	if err != nil {
+		return fmt.Errorf("marshal project settings: %w", err)
+	}
+
+	err = s.pool.QueryRow(ctx, createProjectQuery, p.ID, p.OrganizationID, p.Name, p.DisplayName, settings).
+		Scan(&p.Organization, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case isUniqueViolation(err):
+			return project.ErrProjectExists
+		case errors.As(err, &pgErr) && pgErr.Code == "23503":
+			// Foreign key violation: the organization does not exist
+			return project.ErrOrganizationNotFound
+		}
+		return fmt.Errorf("create project: %w", err)
+	}
+
+	s.logger.Info("project created",
+		zap.String("id", p.ID.String()),
+		zap.String("organization", p.Organization),
+		zap.String("name", p.Name))
+	return nil
+}
+
+// projectColumns is the column list shared by every project SELECT; keep in sync with scanProject
+const projectColumns = `
+    p.id, p.organization_id, o.name, p.name, COALESCE(p.display_name, ''),
+    p.settings, p.created_at, p.updated_at
+FROM projects p JOIN organizations o ON o.id = p.organization_id`
+
+func (s *Store) GetProject(ctx context.Context, organization, name string) (*project.Project, error) {
+	p, err := scanProject(s.pool.QueryRow(ctx, `SELECT `+projectColumns+` WHERE o.name = $1 AND p.name = $2`, organization, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, project.ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("get project: %w", err)
+	}
+	return p, nil
+}
+
+func (s *Store) GetProjectByID(ctx context.Context, id uuid.UUID) (*project.Project, error) {
+	p, err := scanProject(s.pool.QueryRow(ctx, `SELECT `+projectColumns+` WHERE p.id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, project.ErrProjectNotFound
+		}
+		return nil, fmt.Errorf("get project by ID: %w", err)
+	}
+	return p, nil
+}
+
+func (s *Store) ListProjects(ctx context.Context, organization string) ([]*project.Project, error) {
+	rows, err := s.pool.Query(ctx, `SELECT `+projectColumns+` WHERE o.name = $1 ORDER BY p.name`, organization)
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	defer rows.Close()
+
+	projects := []*project.Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate projects: %w", err)
+	}
+	return projects, nil
+}
+
+const updateProjectQuery = `
+UPDATE projects SET
+    display_name = $2,
+    settings = $3,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING updated_at
+`
+
+func (s *Store) UpdateProject(ctx context.Context, p *project.Project) error {
+	settings, err := json.Marshal(p.Settings)
+	if err != nil {
+		return fmt.Errorf("marshal project settings: %w", err)
+	}
+
+	if err := s.pool.QueryRow(ctx, updateProjectQuery, p.ID, p.DisplayName, settings).Scan(&p.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return project.ErrProjectNotFound
+		}
+		return fmt.Errorf("update project: %w", err)
+	}
+
+	s.logger.Info("project updated", zap.String("id", p.ID.String()), zap.String("name", p.Name))
+	return nil
+}
+
+func scanOrganization(row pgx.Row) (*project.Organization, error) {
+	org := &project.Organization{}
+	if err := row.Scan(&org.ID, &org.Name, &org.DisplayName, &org.CreatedAt, &org.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// scanProject scans a row selected with projectColumns into a project
+func scanProject(row pgx.Row) (*project.Project, error) {
+	p := &project.Project{}
+	var settingsJSON []byte
+	if err := row.Scan(&p.ID, &p.OrganizationID, &p.Organization, &p.Name, &p.DisplayName, &settingsJSON, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan project: %w", err)
+	}
+	if len(settingsJSON) > 0 {
+		if err := json.Unmarshal(settingsJSON, &p.Settings); err != nil {
+			return nil, fmt.Errorf("unmarshal project settings: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// isUniqueViolation checks if error is unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,82 @@
+package project
+
+import "context"
+
+// Principal is an authenticated API caller and the part of the hierarchy it may act on.
+// A nil principal means authentication is disabled and every request is unrestricted.
+type Principal struct {
+	// Name identifies the caller in logs and managed fields
+	Name string
+
+	// Admin principals may act on every organization and manage providers
+	Admin bool
+
+	// Organization is the organization a non-admin principal is scoped to
+	Organization string
+
+	// Projects limits a non-admin principal to these projects of its organization; empty allows all of them
+	Projects []string
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the principal
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the request's principal, or nil when authentication is disabled
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Unrestricted reports whether the principal may act on everything
+func (p *Principal) Unrestricted() bool {
+	return p == nil || p.Admin
+}
+
+// CanAccessOrganization reports whether the principal may see the organization
+func (p *Principal) CanAccessOrganization(organization string) bool {
+	return p.Unrestricted() || p.Organization == organization
+}
+
+// CanManageOrganization reports whether the principal may create and change the organization's projects
+func (p *Principal) CanManageOrganization(organization string) bool {
+	return p.Unrestricted() || (p.Organization == organization && len(p.Projects) == 0)
+}
+
+// CanAccessProject reports whether the principal may see and change the project and its tenants
+func (p *Principal) CanAccessProject(organization, project string) bool {
+	if p.Unrestricted() {
+		return true
+	}
+	if p.Organization != organization {
+		return false
+	}
+	if len(p.Projects) == 0 {
+		return true
+	}
+	for _, allowed := range p.Projects {
+		if allowed == project {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultScope returns the organization and project a create request lands in when it names none.
+// The project is empty when the principal spans several projects and must choose one.
+func (p *Principal) DefaultScope() (organization, project string) {
+	if p.Unrestricted() {
+		return DefaultName, DefaultName
+	}
+	switch len(p.Projects) {
+	case 0:
+		return p.Organization, DefaultName
+	case 1:
+		return p.Organization, p.Projects[0]
+	default:
+		return p.Organization, ""
+	}
+}
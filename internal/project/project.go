@@ -0,0 +1,256 @@
+// Package project groups tenants into projects owned by organizations.
+// Every tenant belongs to a project; API principals are scoped to an organization and
+// optionally to some of its projects, and quotas, notifications and compute_config
+// templates are defined per project.
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// DefaultName is the name of the organization and project that tenants belong to when none is given
+const DefaultName = "default"
+
+var (
+	// DefaultOrganizationID is the organization created by the migrations and the in-memory store
+	DefaultOrganizationID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	// DefaultProjectID is the project created by the migrations and the in-memory store
+	DefaultProjectID = tenant.DefaultProjectID
+)
+
+var (
+	// ErrOrganizationNotFound is returned when an organization doesn't exist
+	ErrOrganizationNotFound = errors.New("organization not found")
+
+	// ErrOrganizationExists is returned when creating an organization with a duplicate name
+	ErrOrganizationExists = errors.New("organization already exists")
+
+	// ErrProjectNotFound is returned when a project doesn't exist in its organization
+	ErrProjectNotFound = errors.New("project not found")
+
+	// ErrProjectExists is returned when creating a project with a duplicate name in its organization
+	ErrProjectExists = errors.New("project already exists")
+
+	// ErrInvalid is returned when an organization or project fails validation
+	ErrInvalid = errors.New("invalid project")
+
+	// ErrQuotaExceeded is returned when a project has no room for another tenant
+	ErrQuotaExceeded = errors.New("project quota exceeded")
+)
+
+// namePattern validates organization, project and template names
+var namePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Organization owns projects
+type Organization struct {
+	ID          uuid.UUID
+	Name        string
+	DisplayName string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Project groups tenants within an organization
+type Project struct {
+	ID             uuid.UUID
+	OrganizationID uuid.UUID
+
+	// Organization is the name of the owning organization, filled in by the store
+	Organization string
+
+	Name        string
+	DisplayName string
+	Settings    Settings
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// Settings are the per-project policies applied to the project's tenants
+type Settings struct {
+	// Quota limits the tenants the project can hold
+	Quota Quota `json:"quota"`
+
+	// Notifications are sent when a tenant in the project changes status
+	Notifications Notifications `json:"notifications"`
+
+	// Templates are named compute_config bases a create request can start from
+	Templates map[string]map[string]interface{} `json:"templates,omitempty"`
+
+	// Policies supply create-time defaults and required labels to tenants whose labels they select
+	Policies []Policy `json:"policies,omitempty"`
+
+	// WarmPools keep tenants pre-provisioned from templates for create requests to claim
+	WarmPools []WarmPool `json:"warm_pools,omitempty"`
+}
+
+// WarmPool keeps unclaimed tenants provisioned from a template. A create request naming the
+// template takes one of them instead of waiting for new compute.
+type WarmPool struct {
+	// Template is the project template the pool's tenants are provisioned from
+	Template string `json:"template"`
+
+	// Size is the number of unclaimed tenants the pool keeps
+	Size int `json:"size"`
+
+	// RefillRate caps the tenants started per refill pass; 0 starts every missing tenant at once
+	RefillRate int `json:"refill_rate,omitempty"`
+}
+
+// Quota limits the tenants a project can hold
+type Quota struct {
+	// MaxTenants caps the tenants that are not archived; 0 is unlimited
+	MaxTenants int `json:"max_tenants,omitempty"`
+}
+
+// Notifications configure where tenant status changes are sent
+type Notifications struct {
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+}
+
+// Webhook receives a JSON POST for each matching tenant status change
+type Webhook struct {
+	// URL is the http or https endpoint to call
+	URL string `json:"url"`
+
+	// Statuses limits notifications to changes into these statuses; empty sends every change
+	Statuses []tenant.Status `json:"statuses,omitempty"`
+}
+
+// Wants reports whether the webhook subscribes to changes into status
+func (w Webhook) Wants(status tenant.Status) bool {
+	if len(w.Statuses) == 0 {
+		return true
+	}
+	for _, s := range w.Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Template returns the named compute_config template
+func (p *Project) Template(name string) (map[string]interface{}, bool) {
+	template, ok := p.Settings.Templates[name]
+	return template, ok
+}
+
+// WarmPool returns the warm pool for the named template
+func (s *Settings) WarmPool(template string) (WarmPool, bool) {
+	for _, pool := range s.WarmPools {
+		if pool.Template == template {
+			return pool, true
+		}
+	}
+	return WarmPool{}, false
+}
+
+// ValidateName checks an organization, project or template name
+func ValidateName(kind, name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%w: %s name %q must be 1-63 lowercase letters, digits or hyphens, starting and ending with a letter or digit", ErrInvalid, kind, name)
+	}
+	return nil
+}
+
+// Validate checks the organization before it is stored
+func (o *Organization) Validate() error {
+	return ValidateName("organization", o.Name)
+}
+
+// Validate checks the project before it is stored
+func (p *Project) Validate() error {
+	if err := ValidateName("project", p.Name); err != nil {
+		return err
+	}
+	return p.Settings.Validate()
+}
+
+// Validate checks quota, notification, template and warm pool settings
+func (s *Settings) Validate() error {
+	if s.Quota.MaxTenants < 0 {
+		return fmt.Errorf("%w: quota.max_tenants must be >= 0", ErrInvalid)
+	}
+	for i, webhook := range s.Notifications.Webhooks {
+		parsed, err := url.Parse(webhook.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%w: notifications.webhooks[%d].url must be an http or https URL", ErrInvalid, i)
+		}
+		for _, status := range webhook.Statuses {
+			if _, ok := tenant.ValidTransitions[status]; !ok {
+				return fmt.Errorf("%w: notifications.webhooks[%d] has unknown status %q", ErrInvalid, i, status)
+			}
+		}
+	}
+	if err := validatePolicies(s.Policies); err != nil {
+		return err
+	}
+	for name, template := range s.Templates {
+		if err := ValidateName("template", name); err != nil {
+			return err
+		}
+		if len(template) == 0 {
+			return fmt.Errorf("%w: template %q is empty", ErrInvalid, name)
+		}
+	}
+	pools := make(map[string]bool, len(s.WarmPools))
+	for i, pool := range s.WarmPools {
+		if _, ok := s.Templates[pool.Template]; !ok {
+			return fmt.Errorf("%w: warm_pools[%d] names unknown template %q", ErrInvalid, i, pool.Template)
+		}
+		if pools[pool.Template] {
+			return fmt.Errorf("%w: warm_pools[%d] repeats template %q", ErrInvalid, i, pool.Template)
+		}
+		pools[pool.Template] = true
+		if pool.Size < 1 {
+			return fmt.Errorf("%w: warm_pools[%d].size must be >= 1", ErrInvalid, i)
+		}
+		if pool.RefillRate < 0 {
+			return fmt.Errorf("%w: warm_pools[%d].refill_rate must be >= 0", ErrInvalid, i)
+		}
+	}
+	return nil
+}
+
+// Store persists organizations and projects
+type Store interface {
+	// CreateOrganization persists a new organization, populating ID and timestamps
+	// Returns ErrOrganizationExists if the name is taken
+	CreateOrganization(ctx context.Context, org *Organization) error
+
+	// GetOrganization retrieves an organization by name
+	// Returns ErrOrganizationNotFound if not found
+	GetOrganization(ctx context.Context, name string) (*Organization, error)
+
+	// ListOrganizations returns every organization sorted by name
+	ListOrganizations(ctx context.Context) ([]*Organization, error)
+
+	// CreateProject persists a new project, populating ID, Organization and timestamps
+	// Returns ErrOrganizationNotFound if OrganizationID does not exist and ErrProjectExists if the name is taken
+	CreateProject(ctx context.Context, p *Project) error
+
+	// GetProject retrieves a project by organization and project name
+	// Returns ErrProjectNotFound if not found
+	GetProject(ctx context.Context, organization, name string) (*Project, error)
+
+	// GetProjectByID retrieves a project by ID
+	// Returns ErrProjectNotFound if not found
+	GetProjectByID(ctx context.Context, id uuid.UUID) (*Project, error)
+
+	// ListProjects returns the organization's projects sorted by name
+	ListProjects(ctx context.Context, organization string) ([]*Project, error)
+
+	// UpdateProject replaces a project's display name and settings
+	// Returns ErrProjectNotFound if not found
+	UpdateProject(ctx context.Context, p *Project) error
+}
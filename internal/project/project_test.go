@@ -0,0 +1,129 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+func TestPrincipalScope(t *testing.T) {
+	var anonymous *Principal
+	admin := &Principal{Name: "root", Admin: true}
+	org := &Principal{Name: "acme-ops", Organization: "acme"}
+	web := &Principal{Name: "acme-web", Organization: "acme", Projects: []string{"web"}}
+	multi := &Principal{Name: "acme-apps", Organization: "acme", Projects: []string{"web", "api"}}
+
+	tests := []struct {
+		name         string
+		principal    *Principal
+		organization string
+		project      string
+		access       bool
+		manage       bool
+	}{
+		{name: "anonymous", principal: anonymous, organization: "other", project: "web", access: true, manage: true},
+		{name: "admin", principal: admin, organization: "other", project: "web", access: true, manage: true},
+		{name: "organization key in its organization", principal: org, organization: "acme", project: "anything", access: true, manage: true},
+		{name: "organization key elsewhere", principal: org, organization: "other", project: "web", access: false, manage: false},
+		{name: "project key in its project", principal: web, organization: "acme", project: "web", access: true, manage: false},
+		{name: "project key in another project", principal: web, organization: "acme", project: "api", access: false, manage: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.principal.CanAccessProject(tt.organization, tt.project); got != tt.access {
+				t.Errorf("CanAccessProject() = %v, want %v", got, tt.access)
+			}
+			if got := tt.principal.CanManageOrganization(tt.organization); got != tt.manage {
+				t.Errorf("CanManageOrganization() = %v, want %v", got, tt.manage)
+			}
+		})
+	}
+
+	scopes := []struct {
+		principal    *Principal
+		organization string
+		project      string
+	}{
+		{anonymous, DefaultName, DefaultName},
+		{admin, DefaultName, DefaultName},
+		{org, "acme", DefaultName},
+		{web, "acme", "web"},
+		{multi, "acme", ""},
+	}
+	for _, scope := range scopes {
+		organization, project := scope.principal.DefaultScope()
+		if organization != scope.organization || project != scope.project {
+			t.Errorf("DefaultScope() for %+v = %s/%s, want %s/%s", scope.principal, organization, project, scope.organization, scope.project)
+		}
+	}
+
+	ctx := WithPrincipal(context.Background(), web)
+	if PrincipalFromContext(ctx) != web {
+		t.Error("PrincipalFromContext() did not return the stored principal")
+	}
+	if PrincipalFromContext(context.Background()) != nil {
+		t.Error("PrincipalFromContext() without a principal should be nil")
+	}
+}
+
+func TestProjectValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		project Project
+		wantErr bool
+	}{
+		{name: "minimal", project: Project{Name: "web"}},
+		{name: "full settings", project: Project{Name: "web", Settings: Settings{
+			Quota:         Quota{MaxTenants: 10},
+			Notifications: Notifications{Webhooks: []Webhook{{URL: "https://hooks.example.com", Statuses: []tenant.Status{tenant.StatusReady}}}},
+			Templates:     map[string]map[string]interface{}{"small": {"image": "nginx"}},
+			WarmPools:     []WarmPool{{Template: "small", Size: 3, RefillRate: 1}},
+		}}},
+		{name: "invalid name", project: Project{Name: "Web_App"}, wantErr: true},
+		{name: "negative quota", project: Project{Name: "web", Settings: Settings{Quota: Quota{MaxTenants: -1}}}, wantErr: true},
+		{name: "webhook without scheme", project: Project{Name: "web", Settings: Settings{
+			Notifications: Notifications{Webhooks: []Webhook{{URL: "hooks.example.com"}}},
+		}}, wantErr: true},
+		{name: "webhook with unknown status", project: Project{Name: "web", Settings: Settings{
+			Notifications: Notifications{Webhooks: []Webhook{{URL: "https://hooks.example.com", Statuses: []tenant.Status{"sleeping"}}}},
+		}}, wantErr: true},
+		{name: "empty template", project: Project{Name: "web", Settings: Settings{
+			Templates: map[string]map[string]interface{}{"small": {}},
+		}}, wantErr: true},
+		{name: "warm pool for unknown template", project: Project{Name: "web", Settings: Settings{
+			WarmPools: []WarmPool{{Template: "small", Size: 1}},
+		}}, wantErr: true},
+		{name: "empty warm pool", project: Project{Name: "web", Settings: Settings{
+			Templates: map[string]map[string]interface{}{"small": {"image": "nginx"}},
+			WarmPools: []WarmPool{{Template: "small"}},
+		}}, wantErr: true},
+		{name: "duplicate warm pool", project: Project{Name: "web", Settings: Settings{
+			Templates: map[string]map[string]interface{}{"small": {"image": "nginx"}},
+			WarmPools: []WarmPool{{Template: "small", Size: 1}, {Template: "small", Size: 2}},
+		}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.project.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalid) {
+				t.Errorf("Validate() error = %v, want ErrInvalid", err)
+			}
+		})
+	}
+}
+
+func TestWebhookWants(t *testing.T) {
+	all := Webhook{URL: "https://hooks.example.com"}
+	if !all.Wants(tenant.StatusFailed) {
+		t.Error("webhook without statuses should receive every change")
+	}
+	ready := Webhook{URL: "https://hooks.example.com", Statuses: []tenant.Status{tenant.StatusReady}}
+	if !ready.Wants(tenant.StatusReady) || ready.Wants(tenant.StatusFailed) {
+		t.Error("webhook with statuses should receive only those changes")
+	}
+}
@@ -0,0 +1,357 @@
+package providerconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// Status is the current runtime state of a registered provider
+type Status struct {
+	Kind    Kind
+	Name    string
+	Enabled bool
+
+	// Reconfigurable reports whether the provider accepts configuration changes at runtime
+	Reconfigurable bool
+
+	// Config is the runtime configuration, or nil when the provider runs with its startup configuration
+	Config map[string]interface{}
+
+	// UpdatedBy and UpdatedAt describe the last runtime change, if any
+	UpdatedBy string
+	UpdatedAt *time.Time
+}
+
+// Manager applies provider changes to the registries and persists them.
+// Either registry may be nil when the process does not host that kind of provider.
+type Manager struct {
+	compute  *compute.Registry
+	workflow *workflow.Registry
+	store    Store
+	logger   *zap.Logger
+
+	// mu serialises changes so the registry and the store agree on the latest one
+	mu sync.Mutex
+}
+
+// NewManager creates a provider manager
+func NewManager(computeRegistry *compute.Registry, workflowRegistry *workflow.Registry, store Store, logger *zap.Logger) *Manager {
+	return &Manager{
+		compute:  computeRegistry,
+		workflow: workflowRegistry,
+		store:    store,
+		logger:   logger.With(zap.String("component", "provider-config")),
+	}
+}
+
+// Apply loads persisted settings into the registries, typically once at startup.
+// Settings for providers this process does not register are skipped.
+func (m *Manager) Apply(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	settings, err := m.store.ListSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("list provider settings: %w", err)
+	}
+
+	for _, setting := range settings {
+		if !m.registered(setting.Kind, setting.Name) {
+			m.logger.Debug("skipping setting for unregistered provider",
+				zap.String("kind", string(setting.Kind)),
+				zap.String("provider", setting.Name))
+			continue
+		}
+		if setting.Config != nil {
+			if err := m.reconfigure(setting.Kind, setting.Name, setting.Config); err != nil {
+				return fmt.Errorf("apply %s provider %s config: %w", setting.Kind, setting.Name, err)
+			}
+		}
+		if err := m.setEnabled(setting.Kind, setting.Name, setting.Enabled); err != nil {
+			return fmt.Errorf("apply %s provider %s state: %w", setting.Kind, setting.Name, err)
+		}
+		m.logger.Info("applied persisted provider setting",
+			zap.String("kind", string(setting.Kind)),
+			zap.String("provider", setting.Name),
+			zap.Bool("enabled", setting.Enabled),
+			zap.Bool("reconfigured", setting.Config != nil))
+	}
+	return nil
+}
+
+// List returns the status of every registered provider, compute providers first
+func (m *Manager) List(ctx context.Context) ([]*Status, error) {
+	settings, err := m.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []*Status
+	for _, kind := range []Kind{KindCompute, KindWorkflow} {
+		names := m.names(kind)
+		sort.Strings(names)
+		for _, name := range names {
+			statuses = append(statuses, m.status(kind, name, settings[settingKey(kind, name)]))
+		}
+	}
+	return statuses, nil
+}
+
+// Get returns the status of one provider
+// Returns ErrProviderNotFound if it is not registered
+func (m *Manager) Get(ctx context.Context, kind Kind, name string) (*Status, error) {
+	if !m.registered(kind, name) {
+		return nil, fmt.Errorf("%w: %s provider %s", ErrProviderNotFound, kind, name)
+	}
+	settings, err := m.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m.status(kind, name, settings[settingKey(kind, name)]), nil
+}
+
+// SetEnabled enables or disables a provider.
+// Disabled compute providers receive no new tenants; disabled workflow providers start no new executions.
+func (m *Manager) SetEnabled(ctx context.Context, kind Kind, name string, enabled bool, actor, requestID string) (*Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	setting, err := m.current(ctx, kind, name)
+	if err != nil {
+		return nil, err
+	}
+	previous := setting.Enabled
+
+	if err := m.setEnabled(kind, name, enabled); err != nil {
+		return nil, err
+	}
+
+	setting.Enabled = enabled
+	action := ActionDisable
+	if enabled {
+		action = ActionEnable
+	}
+	if err := m.save(ctx, setting, action, actor, requestID); err != nil {
+		if rollbackErr := m.setEnabled(kind, name, previous); rollbackErr != nil {
+			m.logger.Error("failed to roll back provider state", zap.String("provider", name), zap.Error(rollbackErr))
+		}
+		return nil, err
+	}
+	return m.status(kind, name, setting), nil
+}
+
+// Reconfigure replaces a provider's configuration.
+// For compute providers the configuration is the default compute_config merged into every tenant's config.
+func (m *Manager) Reconfigure(ctx context.Context, kind Kind, name string, config map[string]interface{}, actor, requestID string) (*Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	setting, err := m.current(ctx, kind, name)
+	if err != nil {
+		return nil, err
+	}
+	previous := setting.Config
+	if previous == nil && kind == KindCompute {
+		previous = m.computeDefaults(name)
+	}
+
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	if err := m.reconfigure(kind, name, config); err != nil {
+		return nil, err
+	}
+
+	setting.Config = config
+	if err := m.save(ctx, setting, ActionReconfigure, actor, requestID); err != nil {
+		if previous == nil {
+			m.logger.Error("provider reconfigured but not persisted; it reverts on restart", zap.String("provider", name))
+		} else if rollbackErr := m.reconfigure(kind, name, previous); rollbackErr != nil {
+			m.logger.Error("failed to roll back provider config", zap.String("provider", name), zap.Error(rollbackErr))
+		}
+		return nil, err
+	}
+	return m.status(kind, name, setting), nil
+}
+
+// Audit returns the change history for a provider, newest first
+func (m *Manager) Audit(ctx context.Context, kind Kind, name string) ([]*AuditEntry, error) {
+	if !m.registered(kind, name) {
+		return nil, fmt.Errorf("%w: %s provider %s", ErrProviderNotFound, kind, name)
+	}
+	entries, err := m.store.ListAudit(ctx, kind, name)
+	if err != nil {
+		return nil, fmt.Errorf("list provider audit: %w", err)
+	}
+	return entries, nil
+}
+
+// current returns the persisted setting for a provider, or one describing its startup state
+func (m *Manager) current(ctx context.Context, kind Kind, name string) (*Setting, error) {
+	if !m.registered(kind, name) {
+		return nil, fmt.Errorf("%w: %s provider %s", ErrProviderNotFound, kind, name)
+	}
+	settings, err := m.settings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if setting, ok := settings[settingKey(kind, name)]; ok {
+		return setting, nil
+	}
+	return &Setting{Kind: kind, Name: name, Enabled: m.enabled(kind, name)}, nil
+}
+
+func (m *Manager) save(ctx context.Context, setting *Setting, action, actor, requestID string) error {
+	setting.UpdatedBy = actor
+	entry := &AuditEntry{
+		Kind:      setting.Kind,
+		Name:      setting.Name,
+		Action:    action,
+		Actor:     actor,
+		RequestID: requestID,
+		Enabled:   setting.Enabled,
+		Config:    setting.Config,
+	}
+	if err := m.store.SaveSetting(ctx, setting, entry); err != nil {
+		return fmt.Errorf("save provider setting: %w", err)
+	}
+	m.logger.Info("provider changed",
+		zap.String("kind", string(setting.Kind)),
+		zap.String("provider", setting.Name),
+		zap.String("action", action),
+		zap.String("actor", actor),
+		zap.String("request_id", requestID))
+	return nil
+}
+
+func (m *Manager) settings(ctx context.Context) (map[string]*Setting, error) {
+	settings, err := m.store.ListSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list provider settings: %w", err)
+	}
+	byKey := make(map[string]*Setting, len(settings))
+	for _, setting := range settings {
+		byKey[settingKey(setting.Kind, setting.Name)] = setting
+	}
+	return byKey, nil
+}
+
+func (m *Manager) status(kind Kind, name string, setting *Setting) *Status {
+	status := &Status{
+		Kind:           kind,
+		Name:           name,
+		Enabled:        m.enabled(kind, name),
+		Reconfigurable: m.reconfigurable(kind, name),
+	}
+	if setting != nil {
+		status.Config = setting.Config
+		status.UpdatedBy = setting.UpdatedBy
+		updatedAt := setting.UpdatedAt
+		status.UpdatedAt = &updatedAt
+	}
+	return status
+}
+
+func settingKey(kind Kind, name string) string {
+	return string(kind) + "/" + name
+}
+
+func (m *Manager) names(kind Kind) []string {
+	switch {
+	case kind == KindCompute && m.compute != nil:
+		return m.compute.List()
+	case kind == KindWorkflow && m.workflow != nil:
+		return m.workflow.List()
+	}
+	return nil
+}
+
+func (m *Manager) registered(kind Kind, name string) bool {
+	switch {
+	case kind == KindCompute && m.compute != nil:
+		return m.compute.Has(name)
+	case kind == KindWorkflow && m.workflow != nil:
+		return m.workflow.Has(name)
+	}
+	return false
+}
+
+func (m *Manager) enabled(kind Kind, name string) bool {
+	if kind == KindCompute {
+		return m.compute.Enabled(name)
+	}
+	return m.workflow.Enabled(name)
+}
+
+func (m *Manager) reconfigurable(kind Kind, name string) bool {
+	if kind == KindCompute {
+		provider, err := m.compute.Get(name)
+		_, ok := provider.(compute.Reconfigurable)
+		return err == nil && ok
+	}
+	provider, err := m.workflow.Get(name)
+	_, ok := provider.(workflow.Reconfigurable)
+	return err == nil && ok
+}
+
+func (m *Manager) setEnabled(kind Kind, name string, enabled bool) error {
+	var err error
+	if kind == KindCompute {
+		err = m.compute.SetEnabled(name, enabled)
+	} else {
+		err = m.workflow.SetEnabled(name, enabled)
+	}
+	return translateError(err)
+}
+
+func (m *Manager) reconfigure(kind Kind, name string, config map[string]interface{}) error {
+	var err error
+	if kind == KindCompute {
+		err = m.compute.Reconfigure(name, config)
+	} else {
+		err = m.workflow.Reconfigure(name, config)
+	}
+	err = translateError(err)
+	if err != nil && !errors.Is(err, ErrProviderNotFound) && !errors.Is(err, ErrReconfigureUnsupported) {
+		return compute.Mark(err, ErrInvalidConfig)
+	}
+	return err
+}
+
+// computeDefaults returns a compute provider's current defaults, so a failed change can be rolled back
+func (m *Manager) computeDefaults(name string) map[string]interface{} {
+	provider, err := m.compute.Get(name)
+	if err != nil {
+		return nil
+	}
+	defaults := map[string]interface{}{}
+	if raw := provider.ConfigDefaults(); len(raw) > 0 {
+		if err := json.Unmarshal(raw, &defaults); err != nil {
+			return nil
+		}
+	}
+	return defaults
+}
+
+// translateError tags registry errors with this package's sentinels without changing their message
+func translateError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, compute.ErrProviderNotFound), errors.Is(err, workflow.ErrProviderNotFound):
+		return compute.Mark(err, ErrProviderNotFound)
+	case errors.Is(err, compute.ErrReconfigureUnsupported), errors.Is(err, workflow.ErrReconfigureUnsupported):
+		return compute.Mark(err, ErrReconfigureUnsupported)
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+package providerconfig_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	computemock "github.com/jaxxstorm/landlord/internal/compute/providers/mock"
+	"github.com/jaxxstorm/landlord/internal/providerconfig"
+	"github.com/jaxxstorm/landlord/internal/providerconfig/memory"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	workflowmock "github.com/jaxxstorm/landlord/internal/workflow/providers/mock"
+)
+
+func newRegistries(t *testing.T) (*compute.Registry, *workflow.Registry) {
+	t.Helper()
+	computeRegistry := compute.NewRegistry(zap.NewNop())
+	if err := computeRegistry.Register(computemock.New()); err != nil {
+		t.Fatalf("register compute provider: %v", err)
+	}
+	workflowRegistry := workflow.NewRegistry(zap.NewNop())
+	if err := workflowRegistry.Register(workflowmock.New(zap.NewNop())); err != nil {
+		t.Fatalf("register workflow provider: %v", err)
+	}
+	return computeRegistry, workflowRegistry
+}
+
+func TestManagerSetEnabled(t *testing.T) {
+	ctx := context.Background()
+	computeRegistry, workflowRegistry := newRegistries(t)
+	store := memory.New()
+	manager := providerconfig.NewManager(computeRegistry, workflowRegistry, store, zap.NewNop())
+
+	status, err := manager.SetEnabled(ctx, providerconfig.KindCompute, "mock", false, "ops", "req-1")
+	if err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	if status.Enabled || status.UpdatedBy != "ops" || status.UpdatedAt == nil {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if computeRegistry.Enabled("mock") {
+		t.Error("expected compute registry to disable the provider")
+	}
+
+	entries, err := manager.Audit(ctx, providerconfig.KindCompute, "mock")
+	if err != nil {
+		t.Fatalf("Audit() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Action != providerconfig.ActionDisable || entries[0].RequestID != "req-1" {
+		t.Fatalf("unexpected audit log %+v", entries)
+	}
+
+	if _, err := manager.SetEnabled(ctx, providerconfig.KindWorkflow, "missing", false, "ops", ""); !errors.Is(err, providerconfig.ErrProviderNotFound) {
+		t.Errorf("expected ErrProviderNotFound, got %v", err)
+	}
+}
+
+func TestManagerReconfigure(t *testing.T) {
+	ctx := context.Background()
+	computeRegistry, workflowRegistry := newRegistries(t)
+	manager := providerconfig.NewManager(computeRegistry, workflowRegistry, memory.New(), zap.NewNop())
+
+	config := map[string]interface{}{"min_latency": "1ms"}
+	status, err := manager.Reconfigure(ctx, providerconfig.KindCompute, "mock", config, "ops", "")
+	if err != nil {
+		t.Fatalf("Reconfigure() error = %v", err)
+	}
+	if !status.Reconfigurable || status.Config["min_latency"] != "1ms" {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	_, err = manager.Reconfigure(ctx, providerconfig.KindCompute, "mock", map[string]interface{}{"min_latency": "soon"}, "ops", "")
+	if !errors.Is(err, providerconfig.ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+
+	_, err = manager.Reconfigure(ctx, providerconfig.KindWorkflow, "mock", config, "ops", "")
+	if !errors.Is(err, providerconfig.ErrReconfigureUnsupported) {
+		t.Errorf("expected ErrReconfigureUnsupported, got %v", err)
+	}
+}
+
+func TestManagerApplyRestoresSettings(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New()
+
+	computeRegistry, workflowRegistry := newRegistries(t)
+	manager := providerconfig.NewManager(computeRegistry, workflowRegistry, store, zap.NewNop())
+	if _, err := manager.SetEnabled(ctx, providerconfig.KindWorkflow, "mock", false, "ops", ""); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+
+	// A restarted process starts from its startup configuration and applies the persisted changes
+	computeRegistry, workflowRegistry = newRegistries(t)
+	restarted := providerconfig.NewManager(computeRegistry, workflowRegistry, store, zap.NewNop())
+	if err := restarted.Apply(ctx); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if workflowRegistry.Enabled("mock") {
+		t.Error("expected persisted disable to be applied")
+	}
+
+	statuses, err := restarted.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(statuses) != 2 || statuses[0].Kind != providerconfig.KindCompute || statuses[1].Enabled {
+		t.Errorf("unexpected statuses %+v %+v", statuses[0], statuses[1])
+	}
+}
+
+type failingStore struct {
+	*memory.Store
+}
+
+func (failingStore) SaveSetting(ctx context.Context, setting *providerconfig.Setting, entry *providerconfig.AuditEntry) error {
+	return errors.New("database unavailable")
+}
+
+func TestManagerRollsBackWhenPersistFails(t *testing.T) {
+	ctx := context.Background()
+	computeRegistry, workflowRegistry := newRegistries(t)
+	manager := providerconfig.NewManager(computeRegistry, workflowRegistry, failingStore{memory.New()}, zap.NewNop())
+
+	if _, err := manager.SetEnabled(ctx, providerconfig.KindCompute, "mock", false, "ops", ""); err == nil {
+		t.Fatal("expected persist failure to be returned")
+	}
+	if !computeRegistry.Enabled("mock") {
+		t.Error("expected provider to stay enabled after a failed change")
+	}
+}
@@ -0,0 +1,138 @@
+// Package memory provides an in-memory provider settings store for tests and local harnesses.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/providerconfig"
+)
+
+// Store implements providerconfig.Store in memory.
+// Settings and entries are copied on the way in and out, so callers never share state with the store.
+type Store struct {
+	mu       sync.RWMutex
+	settings map[string]providerconfig.Setting
+	audit    []providerconfig.AuditEntry
+}
+
+var _ providerconfig.Store = (*Store)(nil)
+
+// New creates an empty in-memory store
+func New() *Store {
+	return &Store{settings: make(map[string]providerconfig.Setting)}
+}
+
+func (s *Store) ListSettings(ctx context.Context) ([]*providerconfig.Setting, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	settings := make([]*providerconfig.Setting, 0, len(s.settings))
+	for _, setting := range s.settings {
+		setting := setting
+		setting.Config = copyConfig(setting.Config)
+		settings = append(settings, &setting)
+	}
+	sort.Slice(settings, func(i, j int) bool {
+		if settings[i].Kind != settings[j].Kind {
+			return settings[i].Kind < settings[j].Kind
+		}
+		return settings[i].Name < settings[j].Name
+	})
+	return settings, nil
+}
+
+func (s *Store) SaveSetting(ctx context.Context, setting *providerconfig.Setting, entry *providerconfig.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	setting.UpdatedAt = now
+	entry.ID = uuid.New()
+	entry.CreatedAt = now
+
+	stored := *setting
+	stored.Config = copyConfig(setting.Config)
+	s.settings[string(setting.Kind)+"/"+setting.Name] = stored
+
+	logged := *entry
+	logged.Config = copyConfig(entry.Config)
+	s.audit = append(s.audit, logged)
+	return nil
+}
+
+func (s *Store) ListAudit(ctx context.Context, kind providerconfig.Kind, name string) ([]*providerconfig.AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := []*providerconfig.AuditEntry{}
+	for i := len(s.audit) - 1; i >= 0; i-- {
+		if s.audit[i].Kind != kind || s.audit[i].Name != name {
+			continue
+		}
+		entry := s.audit[i]
+		entry.Config = copyConfig(entry.Config)
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
+
+var _ providerconfig.AuditScrubber = (*Store)(nil)
+
+func (s *Store) ScrubAudit(ctx context.Context, cutoff time.Time, scrub func(*providerconfig.AuditEntry)) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	scrubbed := 0
+	for i := range s.audit {
+		entry := &s.audit[i]
+		if entry.ScrubbedAt != nil || !entry.CreatedAt.Before(cutoff) {
+			continue
+		}
+		// Nested values are shared with callers, so scrub a deep copy
+		config, err := deepCopyConfig(entry.Config)
+		if err != nil {
+			return scrubbed, err
+		}
+		entry.Config = config
+		scrub(entry)
+		entry.ScrubbedAt = &now
+		scrubbed++
+	}
+	return scrubbed, nil
+}
+
+// deepCopyConfig copies a config map through its JSON form
+func deepCopyConfig(config map[string]interface{}) (map[string]interface{}, error) {
+	if config == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("marshal audit config: %w", err)
+	}
+	var copied map[string]interface{}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("unmarshal audit config: %w", err)
+	}
+	return copied, nil
+}
+
+// copyConfig copies the top level of a config map; nested values are treated as immutable
+func copyConfig(config map[string]interface{}) map[string]interface{} {
+	if config == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		copied[key] = value
+	}
+	return copied
+}
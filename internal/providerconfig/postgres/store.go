@@ -0,0 +1,254 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/providerconfig"
+)
+
+// Store implements providerconfig.Store for PostgreSQL
+type Store struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+var _ providerconfig.Store = (*Store)(nil)
+
+// New creates a PostgreSQL provider settings store
+// Accepts interface{} to satisfy provider abstraction, type asserts to *pgxpool.Pool
+func New(pool interface{}, logger *zap.Logger) (*Store, error) {
+	pgPool, ok := pool.(*pgxpool.Pool)
+	if !ok {
+		return nil, fmt.Errorf("expected *pgxpool.Pool, got %T", pool)
+	}
+	return &Store{
+		pool:   pgPool,
+		logger: logger.With(zap.String("component", "provider-settings-postgres-store")),
+	}, nil
+}
+
+const listSettingsQuery = `
+SELECT kind, name, enabled, config, COALESCE(updated_by, ''), updated_at
+FROM provider_settings
+ORDER BY kind, name
+`
+
+func (s *Store) ListSettings(ctx context.Context) ([]*providerconfig.Setting, error) {
+	rows, err := s.pool.Query(ctx, listSettingsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("list provider settings: %w", err)
+	}
+	defer rows.Close()
+
+	settings := []*providerconfig.Setting{}
+	for rows.Next() {
+		setting := &providerconfig.Setting{}
+		var configJSON []byte
+		if err := rows.Scan(&setting.Kind, &setting.Name, &setting.Enabled, &configJSON, &setting.UpdatedBy, &setting.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan provider setting: %w", err)
+		}
+		if setting.Config, err = unmarshalConfig(configJSON); err != nil {
+			return nil, fmt.Errorf("unmarshal config for %s/%s: %w", setting.Kind, setting.Name, err)
+		}
+		settings = append(settings, setting)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate provider settings: %w", err)
+	}
+	return settings, nil
+}
+
+const upsertSettingQuery = `
+INSERT INTO provider_settings (kind, name, enabled, config, updated_by, updated_at)
+VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+ON CONFLICT (kind, name) DO UPDATE SET
+    enabled = EXCLUDED.enabled,
+    config = EXCLUDED.config,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = EXCLUDED.updated_at
+RETURNING updated_at
+`
+
+const insertAuditQuery = `
+INSERT INTO provider_settings_audit (kind, name, action, actor, request_id, enabled, config)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id, created_at
+`
+
+func (s *Store) SaveSetting(ctx context.Context, setting *providerconfig.Setting, entry *providerconfig.AuditEntry) error {
+	settingConfig, err := marshalConfig(setting.Config)
+	if err != nil {
+		return err
+	}
+	entryConfig, err := marshalConfig(entry.Config)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx, upsertSettingQuery,
+		setting.Kind,
+		setting.Name,
+		setting.Enabled,
+		settingConfig,
+		setting.UpdatedBy,
+	).Scan(&setting.UpdatedAt); err != nil {
+		return fmt.Errorf("save provider setting: %w", err)
+	}
+
+	if err := tx.QueryRow(ctx, insertAuditQuery,
+		entry.Kind,
+		entry.Name,
+		entry.Action,
+		entry.Actor,
+		entry.RequestID,
+		entry.Enabled,
+		entryConfig,
+	).Scan(&entry.ID, &entry.CreatedAt); err != nil {
+		return fmt.Errorf("record provider audit: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit provider setting: %w", err)
+	}
+
+	s.logger.Debug("provider setting saved",
+		zap.String("kind", string(setting.Kind)),
+		zap.String("provider", setting.Name),
+		zap.String("action", entry.Action))
+	return nil
+}
+
+const listAuditQuery = `
+SELECT id, kind, name, action, COALESCE(actor, ''), COALESCE(request_id, ''), enabled, config, created_at, scrubbed_at
+FROM provider_settings_audit
+WHERE kind = $1 AND name = $2
+ORDER BY created_at DESC
+`
+
+func (s *Store) ListAudit(ctx context.Context, kind providerconfig.Kind, name string) ([]*providerconfig.AuditEntry, error) {
+	rows, err := s.pool.Query(ctx, listAuditQuery, kind, name)
+	if err != nil {
+		return nil, fmt.Errorf("list provider audit: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []*providerconfig.AuditEntry{}
+	for rows.Next() {
+		entry := &providerconfig.AuditEntry{}
+		var configJSON []byte
+		if err := rows.Scan(&entry.ID, &entry.Kind, &entry.Name, &entry.Action, &entry.Actor, &entry.RequestID, &entry.Enabled, &configJSON, &entry.CreatedAt, &entry.ScrubbedAt); err != nil {
+			return nil, fmt.Errorf("scan provider audit: %w", err)
+		}
+		if entry.Config, err = unmarshalConfig(configJSON); err != nil {
+			return nil, fmt.Errorf("unmarshal audit config: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate provider audit: %w", err)
+	}
+	return entries, nil
+}
+
+var _ providerconfig.AuditScrubber = (*Store)(nil)
+
+// scrubBatchSize bounds how many audit entries one scrub query reads
+const scrubBatchSize = 500
+
+const selectUnscrubbedAuditQuery = `
+SELECT id, config
+FROM provider_settings_audit
+WHERE created_at < $1 AND scrubbed_at IS NULL
+ORDER BY created_at
+LIMIT $2
+`
+
+const scrubAuditQuery = `
+UPDATE provider_settings_audit
+SET config = $2, scrubbed_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (s *Store) ScrubAudit(ctx context.Context, cutoff time.Time, scrub func(*providerconfig.AuditEntry)) (int, error) {
+	scrubbed := 0
+	for {
+		batch, err := s.unscrubbedAudit(ctx, cutoff)
+		if err != nil {
+			return scrubbed, err
+		}
+		for _, entry := range batch {
+			scrub(entry)
+			configJSON, err := marshalConfig(entry.Config)
+			if err != nil {
+				return scrubbed, err
+			}
+			if _, err := s.pool.Exec(ctx, scrubAuditQuery, entry.ID, configJSON); err != nil {
+				return scrubbed, fmt.Errorf("scrub provider audit %s: %w", entry.ID, err)
+			}
+			scrubbed++
+		}
+		if len(batch) < scrubBatchSize {
+			return scrubbed, nil
+		}
+	}
+}
+
+func (s *Store) unscrubbedAudit(ctx context.Context, cutoff time.Time) ([]*providerconfig.AuditEntry, error) {
+	rows, err := s.pool.Query(ctx, selectUnscrubbedAuditQuery, cutoff, scrubBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("select provider audit to scrub: %w", err)
+	}
+	defer rows.Close()
+
+	var batch []*providerconfig.AuditEntry
+	for rows.Next() {
+		entry := &providerconfig.AuditEntry{}
+		var configJSON []byte
+		if err := rows.Scan(&entry.ID, &configJSON); err != nil {
+			return nil, fmt.Errorf("scan provider audit: %w", err)
+		}
+		if entry.Config, err = unmarshalConfig(configJSON); err != nil {
+			return nil, fmt.Errorf("unmarshal audit config: %w", err)
+		}
+		batch = append(batch, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate provider audit to scrub: %w", err)
+	}
+	return batch, nil
+}
+
+// marshalConfig encodes a config map, keeping nil as SQL NULL
+func marshalConfig(config map[string]interface{}) ([]byte, error) {
+	if config == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("marshal provider config: %w", err)
+	}
+	return data, nil
+}
+
+func unmarshalConfig(data []byte) (map[string]interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
@@ -0,0 +1,108 @@
+// Package providerconfig manages runtime changes to compute and workflow providers.
+// Enabling, disabling and reconfiguring a provider is applied to its registry, persisted so it
+// survives restarts, and recorded in an audit log.
+package providerconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kind identifies which registry a provider belongs to
+type Kind string
+
+const (
+	KindCompute  Kind = "compute"
+	KindWorkflow Kind = "workflow"
+)
+
+// Actions recorded in the audit log
+const (
+	ActionEnable      = "enable"
+	ActionDisable     = "disable"
+	ActionReconfigure = "reconfigure"
+)
+
+var (
+	// ErrInvalidKind is returned for a kind other than compute or workflow
+	ErrInvalidKind = errors.New("invalid provider kind")
+
+	// ErrProviderNotFound is returned when no provider of the kind is registered under the name
+	ErrProviderNotFound = errors.New("provider not found")
+
+	// ErrReconfigureUnsupported is returned when the provider cannot change its configuration at runtime
+	ErrReconfigureUnsupported = errors.New("provider does not support reconfiguration")
+
+	// ErrInvalidConfig is returned when the provider rejects a new configuration
+	ErrInvalidConfig = errors.New("invalid provider configuration")
+)
+
+// ParseKind validates a provider kind
+func ParseKind(value string) (Kind, error) {
+	switch kind := Kind(value); kind {
+	case KindCompute, KindWorkflow:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("%w: %q, must be compute or workflow", ErrInvalidKind, value)
+	}
+}
+
+// Setting is the persisted runtime state of a provider
+type Setting struct {
+	Kind    Kind
+	Name    string
+	Enabled bool
+
+	// Config replaces the provider's startup configuration; nil keeps the startup configuration
+	Config map[string]interface{}
+
+	UpdatedBy string
+	UpdatedAt time.Time
+}
+
+// AuditEntry records one change to a provider
+type AuditEntry struct {
+	ID        uuid.UUID
+	Kind      Kind
+	Name      string
+	Action    string
+	Actor     string
+	RequestID string
+
+	// Enabled and Config are the provider state after the change
+	Enabled bool
+	Config  map[string]interface{}
+
+	CreatedAt time.Time
+
+	// ScrubbedAt is when retention removed personal data from Config
+	ScrubbedAt *time.Time
+}
+
+// Store persists provider settings and their audit log
+type Store interface {
+	// ListSettings returns every persisted setting
+	// Returns empty slice if nothing has been changed at runtime
+	ListSettings(ctx context.Context) ([]*Setting, error)
+
+	// SaveSetting upserts a setting and appends its audit entry atomically
+	// Populates UpdatedAt on the setting and ID and CreatedAt on the entry
+	SaveSetting(ctx context.Context, setting *Setting, entry *AuditEntry) error
+
+	// ListAudit returns the audit log for a provider, newest first
+	// Returns empty slice if the provider was never changed
+	ListAudit(ctx context.Context, kind Kind, name string) ([]*AuditEntry, error)
+}
+
+// AuditScrubber removes personal data from the configuration recorded in old audit entries.
+// Stores that support it implement it next to Store.
+type AuditScrubber interface {
+	// ScrubAudit passes each entry recorded before cutoff that was not scrubbed yet to scrub,
+	// which edits its Config in place, then saves the Config and sets ScrubbedAt
+	// Returns how many entries were scrubbed
+	ScrubAudit(ctx context.Context, cutoff time.Time, scrub func(*AuditEntry)) (int, error)
+}
@@ -0,0 +1,188 @@
+// Package resolution decides which compute provider runs a tenant and explains the decision.
+// A provider named by the tenant itself wins, then the first configured rule that matches the
+// tenant's labels, annotations and name, then the default provider.
+package resolution
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+// Source says where a tenant's compute provider came from
+type Source string
+
+const (
+	// SourceExplicit means the tenant names its provider in its config, labels or annotations
+	SourceExplicit Source = "explicit"
+
+	// SourceRule means a resolution rule matched the tenant
+	SourceRule Source = "rule"
+
+	// SourceDefault means nothing matched and the default provider was used
+	SourceDefault Source = "default"
+
+	// SourceNone means no provider could be chosen
+	SourceNone Source = "none"
+)
+
+// Subject is the tenant being resolved
+type Subject struct {
+	Name        string
+	Config      map[string]interface{}
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// RuleResult records how one rule evaluated against a subject
+type RuleResult struct {
+	Rule     string `json:"rule"`
+	Provider string `json:"provider"`
+	Matched  bool   `json:"matched"`
+
+	// Reason explains why the rule did or did not match
+	Reason string `json:"reason"`
+}
+
+// Explanation is a resolved provider and how it was chosen
+type Explanation struct {
+	// Provider is the chosen compute provider, empty when Source is none
+	Provider string `json:"provider,omitempty"`
+
+	Source Source `json:"source"`
+
+	// Field is where an explicit provider was found, such as "compute_config.compute_provider"
+	Field string `json:"field,omitempty"`
+
+	// Rule names the rule that chose the provider
+	Rule string `json:"rule,omitempty"`
+
+	// Rules lists every configured rule in evaluation order
+	Rules []RuleResult `json:"rules,omitempty"`
+}
+
+// Resolver applies resolution rules. A nil Resolver has no rules.
+type Resolver struct {
+	rules []config.ComputeResolutionRuleConfig
+}
+
+// New creates a resolver from configuration, returning nil when no rules are configured
+func New(cfg config.ComputeResolutionConfig) *Resolver {
+	if !cfg.Enabled() {
+		return nil
+	}
+	return &Resolver{rules: cfg.Rules}
+}
+
+// Resolve returns the provider for s, or defaultProvider when nothing else applies
+func (r *Resolver) Resolve(s Subject, defaultProvider string) string {
+	return r.Explain(s, defaultProvider).Provider
+}
+
+// Explain resolves the provider for s and records how every rule evaluated
+func (r *Resolver) Explain(s Subject, defaultProvider string) Explanation {
+	var explanation Explanation
+	if r != nil {
+		explanation.Rules = make([]RuleResult, 0, len(r.rules))
+		for _, rule := range r.rules {
+			reason, matched := match(rule, s)
+			explanation.Rules = append(explanation.Rules, RuleResult{
+				Rule:     rule.Name,
+				Provider: rule.Provider,
+				Matched:  matched,
+				Reason:   reason,
+			})
+		}
+	}
+
+	if provider, field := ExplicitProvider(s.Config, s.Labels, s.Annotations); provider != "" {
+		explanation.Provider = provider
+		explanation.Source = SourceExplicit
+		explanation.Field = field
+		return explanation
+	}
+	for _, result := range explanation.Rules {
+		if result.Matched {
+			explanation.Provider = result.Provider
+			explanation.Source = SourceRule
+			explanation.Rule = result.Rule
+			return explanation
+		}
+	}
+	if defaultProvider != "" {
+		explanation.Provider = defaultProvider
+		explanation.Source = SourceDefault
+		return explanation
+	}
+	explanation.Source = SourceNone
+	return explanation
+}
+
+// ExplicitProvider returns the provider a tenant names itself and where it was found: the
+// compute_provider or compute_provider_type config key, then the compute_provider label or annotation
+func ExplicitProvider(cfg map[string]interface{}, labels, annotations map[string]string) (string, string) {
+	for _, key := range []string{"compute_provider", "compute_provider_type"} {
+		if value, ok := cfg[key].(string); ok {
+			return value, "compute_config." + key
+		}
+	}
+	if provider, ok := labels["compute_provider"]; ok {
+		return provider, "labels.compute_provider"
+	}
+	if provider, ok := annotations["compute_provider"]; ok {
+		return provider, "annotations.compute_provider"
+	}
+	return "", ""
+}
+
+// match reports whether rule matches s, with the reason
+func match(rule config.ComputeResolutionRuleConfig, s Subject) (string, bool) {
+	if reason, ok := mapMatches("label", rule.Selector, s.Labels); !ok {
+		return reason, false
+	}
+	if reason, ok := mapMatches("annotation", rule.Annotations, s.Annotations); !ok {
+		return reason, false
+	}
+	if rule.NamePattern != "" {
+		if ok, _ := path.Match(rule.NamePattern, s.Name); !ok {
+			return fmt.Sprintf("name %q does not match %q", s.Name, rule.NamePattern), false
+		}
+	}
+
+	var matched []string
+	if len(rule.Selector) > 0 {
+		matched = append(matched, "labels")
+	}
+	if len(rule.Annotations) > 0 {
+		matched = append(matched, "annotations")
+	}
+	if rule.NamePattern != "" {
+		matched = append(matched, "name")
+	}
+	if len(matched) == 0 {
+		return "rule matches every tenant", true
+	}
+	return "matched " + strings.Join(matched, ", "), true
+}
+
+// mapMatches reports whether values carry every wanted entry, naming the first that is missing or different
+func mapMatches(kind string, want, values map[string]string) (string, bool) {
+	keys := make([]string, 0, len(want))
+	for key := range want {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		value, ok := values[key]
+		if !ok {
+			return fmt.Sprintf("%s %s is not set", kind, key), false
+		}
+		if value != want[key] {
+			return fmt.Sprintf("%s %s is %q, not %q", kind, key, value, want[key]), false
+		}
+	}
+	return "", true
+}
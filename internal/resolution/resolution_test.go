@@ -0,0 +1,59 @@
+package resolution
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+func TestExplain(t *testing.T) {
+	resolver := New(config.ComputeResolutionConfig{Rules: []config.ComputeResolutionRuleConfig{
+		{Name: "gpu", Selector: map[string]string{"tier": "gpu"}, Provider: "kubernetes"},
+		{Name: "regulated", Annotations: map[string]string{"compliance": "pci"}, NamePattern: "bank-*", Provider: "ecs"},
+		{Name: "catch-all", Provider: "docker"},
+	}})
+
+	explanation := resolver.Explain(Subject{Name: "acme", Labels: map[string]string{"tier": "gpu"}}, "")
+	assert.Equal(t, "kubernetes", explanation.Provider)
+	assert.Equal(t, SourceRule, explanation.Source)
+	assert.Equal(t, "gpu", explanation.Rule)
+	require.Len(t, explanation.Rules, 3)
+	assert.Equal(t, "matched labels", explanation.Rules[0].Reason)
+
+	explanation = resolver.Explain(Subject{Name: "acme", Annotations: map[string]string{"compliance": "pci"}}, "")
+	assert.Equal(t, "catch-all", explanation.Rule)
+	assert.Equal(t, "label tier is not set", explanation.Rules[0].Reason)
+	assert.Equal(t, `name "acme" does not match "bank-*"`, explanation.Rules[1].Reason)
+	assert.Equal(t, "rule matches every tenant", explanation.Rules[2].Reason)
+
+	explanation = resolver.Explain(Subject{Name: "bank-one", Annotations: map[string]string{"compliance": "pci"}}, "")
+	assert.Equal(t, "ecs", explanation.Provider)
+	assert.Equal(t, "matched annotations, name", explanation.Rules[1].Reason)
+
+	// A provider the tenant names itself wins, but every rule is still reported
+	explanation = resolver.Explain(Subject{Name: "acme", Labels: map[string]string{"tier": "gpu", "compute_provider": "docker"}}, "")
+	assert.Equal(t, Explanation{
+		Provider: "docker",
+		Source:   SourceExplicit,
+		Field:    "labels.compute_provider",
+		Rules:    explanation.Rules,
+	}, explanation)
+	assert.True(t, explanation.Rules[0].Matched)
+}
+
+func TestExplainWithoutRules(t *testing.T) {
+	var resolver *Resolver
+	require.Nil(t, New(config.ComputeResolutionConfig{}))
+
+	explanation := resolver.Explain(Subject{Name: "acme"}, "docker")
+	assert.Equal(t, Explanation{Provider: "docker", Source: SourceDefault}, explanation)
+
+	explanation = resolver.Explain(Subject{Name: "acme", Config: map[string]interface{}{"compute_provider_type": "ecs"}}, "docker")
+	assert.Equal(t, Explanation{Provider: "ecs", Source: SourceExplicit, Field: "compute_config.compute_provider_type"}, explanation)
+
+	assert.Equal(t, Explanation{Source: SourceNone}, resolver.Explain(Subject{Name: "acme"}, ""))
+	assert.Empty(t, resolver.Resolve(Subject{Name: "acme"}, ""))
+}
@@ -0,0 +1,72 @@
+// Package resource defines the plugin interface for non-compute tenant resources
+// (databases, buckets, queues, DNS) that workflows orchestrate alongside compute.
+package resource
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Resource states reported by Provider.Status
+const (
+	StateReady    = "ready"
+	StateMissing  = "missing"
+	StateDegraded = "degraded"
+)
+
+// Provider provisions one type of tenant resource.
+// Implementations must be idempotent: workflows retry and re-run steps.
+type Provider interface {
+	// Type is the value tenants reference in a resource's "type" field
+	Type() string
+
+	// Provision creates the resource, or reconciles it if it already exists
+	Provision(ctx context.Context, req *Request) (*Result, error)
+
+	// Update applies a changed resource config
+	Update(ctx context.Context, req *Request) (*Result, error)
+
+	// Destroy releases the resource; destroying a missing resource is not an error
+	Destroy(ctx context.Context, req *Request) error
+
+	// Status reports whether the resource exists and is usable
+	Status(ctx context.Context, req *Request) (*Status, error)
+}
+
+// Request identifies a tenant resource and carries its config
+type Request struct {
+	TenantID string          `json:"tenant_id"`
+	Name     string          `json:"name"`
+	Config   json.RawMessage `json:"config,omitempty"`
+}
+
+// InstanceID is a stable identifier for the resource, unique per tenant and resource name
+func (r *Request) InstanceID() string {
+	return r.TenantID + "-" + r.Name
+}
+
+// Result describes a provisioned resource
+type Result struct {
+	// Outputs are non-secret identifiers reported in the tenant's observed config and resource IDs
+	Outputs map[string]string `json:"outputs,omitempty"`
+
+	// Env holds non-secret settings injected into the tenant's compute environment
+	Env map[string]string `json:"env,omitempty"`
+
+	// Secrets are credentials injected into the tenant's compute environment; never persisted
+	Secrets []Secret `json:"secrets,omitempty"`
+}
+
+// Secret is a credential produced by a resource provider
+type Secret struct {
+	Name   string `json:"name"`
+	EnvVar string `json:"env_var"`
+	Value  string `json:"value"`
+}
+
+// Status is the observed state of a resource
+type Status struct {
+	State   string            `json:"state"`
+	Message string            `json:"message,omitempty"`
+	Outputs map[string]string `json:"outputs,omitempty"`
+}
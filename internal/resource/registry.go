@@ -0,0 +1,82 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrProviderNotFound is returned when no provider is registered for a resource type
+	ErrProviderNotFound = errors.New("resource provider not found")
+
+	// ErrProviderConflict is returned when a resource type is registered twice
+	ErrProviderConflict = errors.New("resource provider already registered")
+)
+
+// Registry manages registered resource providers
+type Registry struct {
+	providers map[string]Provider
+	mu        sync.RWMutex
+	logger    *zap.Logger
+}
+
+// NewRegistry creates a new resource provider registry
+func NewRegistry(logger *zap.Logger) *Registry {
+	return &Registry{
+		providers: make(map[string]Provider),
+		logger:    logger.With(zap.String("component", "resource-registry")),
+	}
+}
+
+// Register adds a provider to the registry
+// Returns ErrProviderConflict if the type is already registered
+func (r *Registry) Register(provider Provider) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	resourceType := provider.Type()
+	if resourceType == "" {
+		return fmt.Errorf("resource type cannot be empty")
+	}
+	if _, exists := r.providers[resourceType]; exists {
+		return fmt.Errorf("%w: %s", ErrProviderConflict, resourceType)
+	}
+
+	r.providers[resourceType] = provider
+	r.logger.Info("registered resource provider", zap.String("type", resourceType))
+	return nil
+}
+
+// Get retrieves a provider by resource type
+// Returns ErrProviderNotFound if the type is not registered
+func (r *Registry) Get(resourceType string) (Provider, error) {
+	if r == nil {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, resourceType)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider, exists := r.providers[resourceType]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, resourceType)
+	}
+	return provider, nil
+}
+
+// List returns the registered resource types in sorted order
+func (r *Registry) List() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.providers))
+	for resourceType := range r.providers {
+		types = append(types, resourceType)
+	}
+	sort.Strings(types)
+	return types
+}
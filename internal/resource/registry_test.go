@@ -0,0 +1,83 @@
+package resource
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+type testProvider struct {
+	resourceType string
+}
+
+func (p *testProvider) Type() string { return p.resourceType }
+
+func (p *testProvider) Provision(ctx context.Context, req *Request) (*Result, error) {
+	return &Result{}, nil
+}
+
+func (p *testProvider) Update(ctx context.Context, req *Request) (*Result, error) {
+	return &Result{}, nil
+}
+
+func (p *testProvider) Destroy(ctx context.Context, req *Request) error { return nil }
+
+func (p *testProvider) Status(ctx context.Context, req *Request) (*Status, error) {
+	return &Status{State: StateReady}, nil
+}
+
+func TestRegistryRegister(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	registry := NewRegistry(logger)
+
+	if err := registry.Register(&testProvider{resourceType: "queue"}); err != nil {
+		t.Fatalf("failed to register provider: %v", err)
+	}
+
+	err := registry.Register(&testProvider{resourceType: "queue"})
+	if !errors.Is(err, ErrProviderConflict) {
+		t.Errorf("expected ErrProviderConflict, got: %v", err)
+	}
+
+	if err := registry.Register(&testProvider{}); err == nil {
+		t.Error("expected error for empty resource type")
+	}
+}
+
+func TestRegistryGet(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	registry := NewRegistry(logger)
+	registry.Register(&testProvider{resourceType: "queue"})
+
+	p, err := registry.Get("queue")
+	if err != nil {
+		t.Fatalf("failed to get provider: %v", err)
+	}
+	if p.Type() != "queue" {
+		t.Errorf("expected provider type 'queue', got: %s", p.Type())
+	}
+
+	if _, err := registry.Get("dns"); !errors.Is(err, ErrProviderNotFound) {
+		t.Errorf("expected ErrProviderNotFound, got: %v", err)
+	}
+
+	// A worker without resource providers configured has a nil registry
+	var empty *Registry
+	if _, err := empty.Get("queue"); !errors.Is(err, ErrProviderNotFound) {
+		t.Errorf("expected ErrProviderNotFound from nil registry, got: %v", err)
+	}
+}
+
+func TestRegistryList(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	registry := NewRegistry(logger)
+	registry.Register(&testProvider{resourceType: "queue"})
+	registry.Register(&testProvider{resourceType: "bucket"})
+
+	types := registry.List()
+	if len(types) != 2 || types[0] != "bucket" || types[1] != "queue" {
+		t.Errorf("expected sorted types [bucket queue], got: %v", types)
+	}
+}
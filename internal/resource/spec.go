@@ -0,0 +1,104 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+const (
+	// ResourcesConfigKey is the desired_config key holding the tenant's resources array
+	ResourcesConfigKey = "resources"
+
+	// DataPlaneConfigKey is the shorthand map form, keyed by resource type: {"dataplane": {"postgres": {}}}
+	DataPlaneConfigKey = "dataplane"
+)
+
+var validName = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,30}[a-z0-9])?$`)
+
+// Spec declares a tenant resource in desired_config.resources
+type Spec struct {
+	Name   string          `json:"name"`
+	Type   string          `json:"type"`
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+// Ref identifies a previously provisioned resource
+type Ref struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ParseSpecs extracts and validates the resources declared in a desired config.
+// Entries from the dataplane shorthand are named after their type and follow the resources array.
+func ParseSpecs(desiredConfig map[string]interface{}) ([]Spec, error) {
+	var specs []Spec
+
+	if raw, ok := desiredConfig[ResourcesConfigKey]; ok && raw != nil {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, fmt.Errorf("encode resources: %w", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&specs); err != nil {
+			return nil, fmt.Errorf("invalid resources: %w", err)
+		}
+	}
+
+	if raw, ok := desiredConfig[DataPlaneConfigKey]; ok && raw != nil {
+		modules, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s must be an object keyed by resource type", DataPlaneConfigKey)
+		}
+		types := make([]string, 0, len(modules))
+		for resourceType := range modules {
+			types = append(types, resourceType)
+		}
+		sort.Strings(types)
+		for _, resourceType := range types {
+			config, err := json.Marshal(modules[resourceType])
+			if err != nil {
+				return nil, fmt.Errorf("encode %s.%s: %w", DataPlaneConfigKey, resourceType, err)
+			}
+			specs = append(specs, Spec{Name: resourceType, Type: resourceType, Config: config})
+		}
+	}
+
+	seen := make(map[string]bool, len(specs))
+	for i, spec := range specs {
+		if !validName.MatchString(spec.Name) {
+			return nil, fmt.Errorf("resources[%d]: name %q must be 1-32 lowercase letters, digits or hyphens", i, spec.Name)
+		}
+		if spec.Type == "" {
+			return nil, fmt.Errorf("resources[%d]: type is required", i)
+		}
+		if seen[spec.Name] {
+			return nil, fmt.Errorf("resources[%d]: duplicate resource name %q", i, spec.Name)
+		}
+		seen[spec.Name] = true
+	}
+	return specs, nil
+}
+
+// Request builds the provider request for a tenant
+func (s Spec) Request(tenantID string) *Request {
+	return &Request{TenantID: tenantID, Name: s.Name, Config: s.Config}
+}
+
+// Removed returns the previous resources that are no longer declared
+func Removed(previous []Ref, current []Spec) []Ref {
+	declared := make(map[string]bool, len(current))
+	for _, spec := range current {
+		declared[spec.Name] = true
+	}
+	var removed []Ref
+	for _, ref := range previous {
+		if !declared[ref.Name] {
+			removed = append(removed, ref)
+		}
+	}
+	return removed
+}
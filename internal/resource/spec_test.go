@@ -0,0 +1,83 @@
+package resource
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSpecs(t *testing.T) {
+	specs, err := ParseSpecs(map[string]interface{}{
+		"image": "example:v1",
+		"resources": []interface{}{
+			map[string]interface{}{"name": "jobs", "type": "queue", "config": map[string]interface{}{"fifo": true}},
+		},
+		"dataplane": map[string]interface{}{
+			"postgres": map[string]interface{}{},
+			"bucket":   map[string]interface{}{},
+		},
+	})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	var names []string
+	for _, spec := range specs {
+		names = append(names, spec.Name+":"+spec.Type)
+	}
+	if got := strings.Join(names, ","); got != "jobs:queue,bucket:bucket,postgres:postgres" {
+		t.Fatalf("unexpected specs: %s", got)
+	}
+	if string(specs[0].Config) != `{"fifo":true}` {
+		t.Fatalf("unexpected config: %s", specs[0].Config)
+	}
+
+	req := specs[0].Request("tenant-1")
+	if req.InstanceID() != "tenant-1-jobs" {
+		t.Fatalf("unexpected instance id: %s", req.InstanceID())
+	}
+}
+
+func TestParseSpecsEmpty(t *testing.T) {
+	specs, err := ParseSpecs(map[string]interface{}{"image": "example:v1"})
+	if err != nil || len(specs) != 0 {
+		t.Fatalf("expected no specs, got %v, %v", specs, err)
+	}
+}
+
+func TestParseSpecsInvalid(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		"unknown field": {"resources": []interface{}{
+			map[string]interface{}{"name": "jobs", "type": "queue", "size": 3},
+		}},
+		"missing type": {"resources": []interface{}{
+			map[string]interface{}{"name": "jobs"},
+		}},
+		"invalid name": {"resources": []interface{}{
+			map[string]interface{}{"name": "Jobs_Queue", "type": "queue"},
+		}},
+		"duplicate name": {
+			"resources": []interface{}{map[string]interface{}{"name": "postgres", "type": "queue"}},
+			"dataplane": map[string]interface{}{"postgres": map[string]interface{}{}},
+		},
+		"not an array":         {"resources": map[string]interface{}{"name": "jobs"}},
+		"dataplane not object": {"dataplane": []interface{}{"postgres"}},
+	}
+
+	for name, desired := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseSpecs(desired); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
+
+func TestRemoved(t *testing.T) {
+	previous := []Ref{{Name: "db", Type: "postgres"}, {Name: "jobs", Type: "queue"}}
+	current := []Spec{{Name: "jobs", Type: "queue"}}
+
+	removed := Removed(previous, current)
+	if len(removed) != 1 || removed[0].Name != "db" {
+		t.Fatalf("unexpected removed resources: %+v", removed)
+	}
+}
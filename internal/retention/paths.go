@@ -0,0 +1,66 @@
+package retention
+
+import "strings"
+
+// Redacted replaces the values retention removes
+const Redacted = "<redacted>"
+
+// Paths are JSON paths whose values are redacted from a document
+type Paths [][]string
+
+// ParsePaths parses dot-separated paths such as "env.*" or "containers.*.env". A "*" segment
+// matches every field of an object or element of an array.
+func ParsePaths(paths []string) Paths {
+	parsed := make(Paths, 0, len(paths))
+	for _, path := range paths {
+		parsed = append(parsed, strings.Split(path, "."))
+	}
+	return parsed
+}
+
+// Apply replaces the value at each path in doc with Redacted. Paths that do not exist in doc are
+// skipped. Returns whether anything was redacted.
+func (p Paths) Apply(doc map[string]interface{}) bool {
+	redacted := false
+	for _, path := range p {
+		if redact(doc, path) {
+			redacted = true
+		}
+	}
+	return redacted
+}
+
+func redact(value interface{}, path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	segment, rest := path[0], path[1:]
+	redacted := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if segment != "*" && segment != key {
+				continue
+			}
+			if len(rest) == 0 {
+				v[key] = Redacted
+				redacted = true
+			} else if redact(child, rest) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		if segment != "*" {
+			return false
+		}
+		for i, child := range v {
+			if len(rest) == 0 {
+				v[i] = Redacted
+				redacted = true
+			} else if redact(child, rest) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,130 @@
+// Package retention enforces data retention. Once state history snapshots and provider audit
+// entries are older than the configured age, the scrubber redacts the configured JSON paths from
+// them, keeping the records themselves.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/providerconfig"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// Scrubber periodically redacts personal data from old state history and provider audit entries.
+// Records are scrubbed once: records scrubbed before the paths change are not scrubbed again.
+type Scrubber struct {
+	history      tenant.HistoryScrubber
+	audit        providerconfig.AuditScrubber
+	historyPaths Paths
+	auditPaths   Paths
+	interval     time.Duration
+	scrubAfter   time.Duration
+	logger       *zap.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewScrubber creates a scrubber. history or audit may be nil when the store does not keep them.
+func NewScrubber(history tenant.HistoryScrubber, audit providerconfig.AuditScrubber, cfg config.RetentionConfig, logger *zap.Logger) *Scrubber {
+	return &Scrubber{
+		history:      history,
+		audit:        audit,
+		historyPaths: ParsePaths(cfg.HistoryPaths),
+		auditPaths:   ParsePaths(cfg.AuditPaths),
+		interval:     cfg.Interval,
+		scrubAfter:   cfg.ScrubAfter,
+		logger:       logger.With(zap.String("component", "retention-scrubber")),
+	}
+}
+
+// Start scrubs in the background until Stop is called
+func (s *Scrubber) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.interval <= 0 {
+		return fmt.Errorf("retention interval must be positive")
+	}
+	if s.cancel != nil {
+		return fmt.Errorf("retention scrubber already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.run(ctx, s.done)
+
+	s.logger.Info("retention scrubber started", zap.Duration("interval", s.interval), zap.Duration("scrub_after", s.scrubAfter))
+	return nil
+}
+
+// Stop stops the background scrubbing and waits for a running pass to finish
+func (s *Scrubber) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	s.logger.Info("retention scrubber stopped")
+}
+
+func (s *Scrubber) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		if err := s.ScrubAll(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Warn("retention pass failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScrubAll scrubs every state transition and provider audit entry older than scrub_after once
+func (s *Scrubber) ScrubAll(ctx context.Context) error {
+	cutoff := time.Now().Add(-s.scrubAfter)
+
+	if s.history != nil && len(s.historyPaths) > 0 {
+		scrubbed, err := s.history.ScrubStateHistory(ctx, cutoff, func(st *tenant.StateTransition) {
+			s.historyPaths.Apply(st.DesiredStateSnapshot)
+			s.historyPaths.Apply(st.ObservedStateSnapshot)
+		})
+		if err != nil {
+			return fmt.Errorf("scrub state history: %w", err)
+		}
+		if scrubbed > 0 {
+			s.logger.Info("scrubbed state history", zap.Int("transitions", scrubbed))
+		}
+	}
+
+	if s.audit != nil && len(s.auditPaths) > 0 {
+		scrubbed, err := s.audit.ScrubAudit(ctx, cutoff, func(entry *providerconfig.AuditEntry) {
+			s.auditPaths.Apply(entry.Config)
+		})
+		if err != nil {
+			return fmt.Errorf("scrub provider audit: %w", err)
+		}
+		if scrubbed > 0 {
+			s.logger.Info("scrubbed provider audit", zap.Int("entries", scrubbed))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package retention_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/providerconfig"
+	providerconfigmemory "github.com/jaxxstorm/landlord/internal/providerconfig/memory"
+	"github.com/jaxxstorm/landlord/internal/retention"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func TestPathsApply(t *testing.T) {
+	doc := map[string]interface{}{
+		"image": "nginx:latest",
+		"env":   map[string]interface{}{"API_KEY": "secret", "REGION": "eu"},
+		"containers": []interface{}{
+			map[string]interface{}{"name": "app", "env": map[string]interface{}{"TOKEN": "t"}},
+			map[string]interface{}{"name": "sidecar"},
+		},
+		"owner": "alice@example.com",
+	}
+	if !retention.ParsePaths([]string{"env.*", "containers.*.env", "owner", "missing.path"}).Apply(doc) {
+		t.Fatal("expected values to be redacted")
+	}
+	want := map[string]interface{}{
+		"image": "nginx:latest",
+		"env":   map[string]interface{}{"API_KEY": retention.Redacted, "REGION": retention.Redacted},
+		"containers": []interface{}{
+			map[string]interface{}{"name": "app", "env": retention.Redacted},
+			map[string]interface{}{"name": "sidecar"},
+		},
+		"owner": retention.Redacted,
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("unexpected document %v", doc)
+	}
+	if retention.ParsePaths([]string{"image.tag"}).Apply(doc) {
+		t.Error("expected no redaction below a scalar")
+	}
+}
+
+func TestScrubAll(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.New()
+	desired := map[string]interface{}{"image": "nginx:latest", "env": map[string]interface{}{"API_KEY": "secret"}}
+	tn := &tenant.Tenant{ID: uuid.New(), Name: "acme", Status: tenant.StatusReady, DesiredConfig: desired}
+	if err := repo.CreateTenant(ctx, tn); err != nil {
+		t.Fatalf("CreateTenant() error = %v", err)
+	}
+	if err := repo.RecordStateTransition(ctx, tenant.NewStateTransition(tn, tenant.StatusReady, "Config updated", "api")); err != nil {
+		t.Fatalf("RecordStateTransition() error = %v", err)
+	}
+
+	settings := providerconfigmemory.New()
+	setting := &providerconfig.Setting{Kind: providerconfig.KindCompute, Name: "docker", Enabled: true, Config: map[string]interface{}{"registry_password": "hunter2"}}
+	entry := &providerconfig.AuditEntry{Kind: setting.Kind, Name: setting.Name, Action: "update", Enabled: true, Config: setting.Config}
+	if err := settings.SaveSetting(ctx, setting, entry); err != nil {
+		t.Fatalf("SaveSetting() error = %v", err)
+	}
+
+	scrubber := retention.NewScrubber(repo, settings, config.RetentionConfig{
+		Enabled:      true,
+		Interval:     time.Minute,
+		ScrubAfter:   time.Nanosecond,
+		HistoryPaths: []string{"env.*"},
+		AuditPaths:   []string{"registry_password"},
+	}, zap.NewNop())
+	time.Sleep(time.Millisecond)
+	if err := scrubber.ScrubAll(ctx); err != nil {
+		t.Fatalf("ScrubAll() error = %v", err)
+	}
+
+	history, err := repo.GetStateHistory(ctx, tn.ID, tenant.HistoryFilters{})
+	if err != nil {
+		t.Fatalf("GetStateHistory() error = %v", err)
+	}
+	if len(history) != 1 || history[0].ScrubbedAt == nil || history[0].Reason != "Config updated" {
+		t.Fatalf("expected the transition to be scrubbed, got %+v", history)
+	}
+	env := history[0].DesiredStateSnapshot["env"].(map[string]interface{})
+	if env["API_KEY"] != retention.Redacted {
+		t.Errorf("expected API_KEY to be redacted, got %v", env["API_KEY"])
+	}
+	if history[0].DesiredStateSnapshot["image"] != "nginx:latest" {
+		t.Errorf("expected image to be kept, got %v", history[0].DesiredStateSnapshot["image"])
+	}
+	if desired["env"].(map[string]interface{})["API_KEY"] != "secret" {
+		t.Error("expected the tenant's own config to be left alone")
+	}
+
+	audit, err := settings.ListAudit(ctx, providerconfig.KindCompute, "docker")
+	if err != nil {
+		t.Fatalf("ListAudit() error = %v", err)
+	}
+	if len(audit) != 1 || audit[0].ScrubbedAt == nil || audit[0].Config["registry_password"] != retention.Redacted {
+		t.Fatalf("expected the audit entry to be scrubbed, got %+v", audit)
+	}
+	current, err := settings.ListSettings(ctx)
+	if err != nil {
+		t.Fatalf("ListSettings() error = %v", err)
+	}
+	if len(current) != 1 || current[0].Config["registry_password"] != "hunter2" {
+		t.Error("expected the current setting to be left alone")
+	}
+
+	// Scrubbed records are not scrubbed again
+	scrubbedAt := *history[0].ScrubbedAt
+	if err := scrubber.ScrubAll(ctx); err != nil {
+		t.Fatalf("ScrubAll() error = %v", err)
+	}
+	history, _ = repo.GetStateHistory(ctx, tn.ID, tenant.HistoryFilters{})
+	if !history[0].ScrubbedAt.Equal(scrubbedAt) {
+		t.Error("expected the transition to be scrubbed once")
+	}
+}
@@ -0,0 +1,344 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// ConditionSuspended is the tenant condition recording whether a schedule has suspended it
+const ConditionSuspended = "Suspended"
+
+// Controller fires due schedules. Each occurrence is claimed in the store before it runs, so
+// several controllers can share a store without running an occurrence twice. A schedule never
+// overlaps itself: an occurrence that comes due while the previous run is still in progress is
+// recorded as skipped. Occurrences missed while no controller was running are not backfilled;
+// the schedule runs once and moves on to its next occurrence.
+type Controller struct {
+	store      Store
+	tenants    tenant.Repository
+	executor   Executor
+	interval   time.Duration
+	runTimeout time.Duration
+	logger     *zap.Logger
+
+	// runs tracks actions still executing in the background
+	runs sync.WaitGroup
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewController creates a schedule controller
+func NewController(store Store, tenants tenant.Repository, executor Executor, cfg config.ScheduleConfig, logger *zap.Logger) *Controller {
+	return &Controller{
+		store:      store,
+		tenants:    tenants,
+		executor:   executor,
+		interval:   cfg.Interval,
+		runTimeout: cfg.RunTimeout,
+		logger:     logger.With(zap.String("component", "schedule-controller")),
+	}
+}
+
+// Start fires due schedules in the background until Stop is called
+func (c *Controller) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.interval <= 0 {
+		return fmt.Errorf("schedule interval must be positive")
+	}
+	if c.runTimeout <= 0 {
+		return fmt.Errorf("schedule run timeout must be positive")
+	}
+	if c.cancel != nil {
+		return fmt.Errorf("schedule controller already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.run(ctx, c.done)
+
+	c.logger.Info("schedule controller started", zap.Duration("interval", c.interval), zap.Duration("run_timeout", c.runTimeout))
+	return nil
+}
+
+// Stop stops firing schedules, cancels running actions and waits for them to be recorded
+func (c *Controller) Stop() {
+	c.mu.Lock()
+	cancel, done := c.cancel, c.done
+	c.cancel, c.done = nil, nil
+	c.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	c.Wait()
+	c.logger.Info("schedule controller stopped")
+}
+
+// Wait blocks until every action started by RunDue has finished and been recorded
+func (c *Controller) Wait() {
+	c.runs.Wait()
+}
+
+func (c *Controller) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if err := c.RunDue(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Warn("schedule pass failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue fires every schedule that is due. Actions run in the background, bounded by the run
+// timeout and ctx; use Wait to wait for them.
+func (c *Controller) RunDue(ctx context.Context) error {
+	now := time.Now()
+	due, err := c.store.Due(ctx, now)
+	if err != nil {
+		return fmt.Errorf("list due schedules: %w", err)
+	}
+
+	for _, s := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := c.fire(ctx, s, now); err != nil {
+			c.logger.Warn("failed to fire schedule",
+				zap.String("schedule_id", s.ID.String()),
+				zap.String("tenant_id", s.TenantID.String()),
+				zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// fire claims s's due occurrence and starts its run, or records why it was skipped
+func (c *Controller) fire(ctx context.Context, s *Schedule, now time.Time) error {
+	if s.NextRunAt == nil {
+		return nil
+	}
+	next, err := s.Next(now)
+	if err != nil {
+		return err
+	}
+	if err := c.store.Claim(ctx, s.ID, *s.NextRunAt, next); err != nil {
+		if errors.Is(err, ErrAlreadyClaimed) {
+			return nil
+		}
+		return fmt.Errorf("claim schedule: %w", err)
+	}
+
+	run := &Run{
+		ScheduleID:  s.ID,
+		TenantID:    s.TenantID,
+		Action:      s.Action,
+		Status:      RunStatusRunning,
+		ScheduledAt: *s.NextRunAt,
+		StartedAt:   now,
+	}
+
+	reason, err := c.previousRunInProgress(ctx, s, now)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		return c.skip(ctx, s, run, reason, now)
+	}
+
+	// Power actions hold the tenant's mutation lock so they never interleave with API changes
+	// such as an archive. Hooks can run for minutes and do not change the tenant, so they don't.
+	var release func()
+	if s.Action != ActionRunHook {
+		release, err = c.tenants.LockTenant(ctx, s.TenantID)
+		if err != nil {
+			if errors.Is(err, tenant.ErrTenantLocked) {
+				return c.skip(ctx, s, run, "Another operation is in progress for the tenant", now)
+			}
+			return fmt.Errorf("lock tenant: %w", err)
+		}
+	}
+
+	t, err := c.tenants.GetTenantByID(ctx, s.TenantID)
+	if err == nil {
+		reason = skipReason(t, s)
+	} else if errors.Is(err, tenant.ErrTenantNotFound) {
+		reason = "Tenant not found"
+	} else {
+		err = fmt.Errorf("get tenant: %w", err)
+	}
+	if err != nil || reason != "" {
+		if release != nil {
+			release()
+		}
+		if reason != "" {
+			return c.skip(ctx, s, run, reason, now)
+		}
+		return err
+	}
+
+	if err := c.store.CreateRun(ctx, run); err != nil {
+		if release != nil {
+			release()
+		}
+		return fmt.Errorf("create run: %w", err)
+	}
+
+	c.runs.Add(1)
+	go func() {
+		defer c.runs.Done()
+		if release != nil {
+			defer release()
+		}
+		c.execute(ctx, t, s, run)
+	}()
+	return nil
+}
+
+// previousRunInProgress returns why s must not run again yet. Runs that have been marked
+// running for longer than the run timeout belonged to a controller that stopped mid-run;
+// they are recorded as failed and no longer block the schedule.
+func (c *Controller) previousRunInProgress(ctx context.Context, s *Schedule, now time.Time) (string, error) {
+	running, err := c.store.ListRuns(ctx, RunFilters{ScheduleID: &s.ID, Status: RunStatusRunning})
+	if err != nil {
+		return "", fmt.Errorf("list running runs: %w", err)
+	}
+	for _, r := range running {
+		if r.StartedAt.After(now.Add(-c.runTimeout)) {
+			return fmt.Sprintf("Previous run started at %s is still in progress", r.StartedAt.UTC().Format(time.RFC3339)), nil
+		}
+		r.Error = "run did not finish within the run timeout"
+		r.Finish(RunStatusFailed, "Run abandoned", now)
+		if err := c.store.UpdateRun(ctx, r); err != nil {
+			return "", fmt.Errorf("expire abandoned run: %w", err)
+		}
+	}
+	return "", nil
+}
+
+// skipReason returns why s cannot act on t now, or "" when it can
+func skipReason(t *tenant.Tenant, s *Schedule) string {
+	if t.Status != tenant.StatusReady {
+		return fmt.Sprintf("Tenant is %s", t.Status)
+	}
+	suspended := t.Condition(ConditionSuspended)
+	if (s.Action == ActionRestart || s.Action == ActionRunHook) && suspended != nil && suspended.Status == tenant.ConditionTrue {
+		return "Tenant is suspended"
+	}
+	return ""
+}
+
+func (c *Controller) skip(ctx context.Context, s *Schedule, run *Run, reason string, now time.Time) error {
+	run.Finish(RunStatusSkipped, reason, now)
+	if err := c.store.CreateRun(ctx, run); err != nil {
+		return fmt.Errorf("record skipped run: %w", err)
+	}
+	c.logger.Info("schedule run skipped",
+		zap.String("schedule_id", s.ID.String()),
+		zap.String("schedule", s.Name),
+		zap.String("tenant_id", s.TenantID.String()),
+		zap.String("reason", reason))
+	return nil
+}
+
+// execute performs the run's action and records its outcome
+func (c *Controller) execute(ctx context.Context, t *tenant.Tenant, s *Schedule, run *Run) {
+	log := c.logger.With(
+		zap.String("schedule_id", s.ID.String()),
+		zap.String("schedule", s.Name),
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("tenant_name", t.Name),
+		zap.String("action", string(s.Action)))
+
+	runCtx, cancel := context.WithTimeout(ctx, c.runTimeout)
+	result, err := c.executor.Execute(runCtx, t, s)
+	cancel()
+
+	// The outcome is recorded even when the controller is stopping
+	recordCtx := context.WithoutCancel(ctx)
+	now := time.Now()
+	if result != nil {
+		run.Output = result.Output
+	}
+	if err != nil {
+		run.Error = err.Error()
+		message := fmt.Sprintf("%s failed", s.Action)
+		if result != nil && result.Message != "" {
+			message = result.Message
+		}
+		run.Finish(RunStatusFailed, message, now)
+		log.Warn("schedule run failed", zap.Error(err))
+	} else {
+		message := ""
+		if result != nil {
+			message = result.Message
+		}
+		run.Finish(RunStatusSucceeded, message, now)
+		log.Info("schedule run succeeded")
+
+		if s.Action == ActionSuspend || s.Action == ActionResume {
+			c.recordSuspended(recordCtx, s, now)
+		}
+	}
+
+	if err := c.store.UpdateRun(recordCtx, run); err != nil {
+		log.Error("failed to record schedule run", zap.Error(err))
+	}
+}
+
+// recordSuspended sets the tenant's Suspended condition after a suspend or resume. The caller
+// holds the tenant's lock; the tenant is read again because the reconciler may have written it
+// while the action ran.
+func (c *Controller) recordSuspended(ctx context.Context, s *Schedule, now time.Time) {
+	t, err := c.tenants.GetTenantByID(ctx, s.TenantID)
+	if err != nil {
+		c.logger.Warn("failed to record suspended condition",
+			zap.String("tenant_id", s.TenantID.String()),
+			zap.String("schedule", s.Name),
+			zap.Error(err))
+		return
+	}
+
+	condition := tenant.Condition{
+		Type:    ConditionSuspended,
+		Status:  tenant.ConditionTrue,
+		Reason:  tenant.ReasonScheduledSuspend,
+		Message: fmt.Sprintf("Suspended by schedule %s", s.Name),
+	}
+	if s.Action == ActionResume {
+		condition.Status = tenant.ConditionFalse
+		condition.Reason = tenant.ReasonScheduledResume
+		condition.Message = fmt.Sprintf("Resumed by schedule %s", s.Name)
+	}
+	if !t.SetCondition(condition, now) {
+		return
+	}
+	t.UpdatedAt = now
+	if err := c.tenants.UpdateTenant(ctx, t); err != nil {
+		c.logger.Warn("failed to record suspended condition",
+			zap.String("tenant_id", t.ID.String()),
+			zap.String("schedule", s.Name),
+			zap.Error(err))
+	}
+}
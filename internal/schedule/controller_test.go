@@ -0,0 +1,234 @@
+package schedule_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/schedule"
+	schedulememory "github.com/jaxxstorm/landlord/internal/schedule/memory"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantmemory "github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+// fakeExecutor records calls; when block is set each call waits until it is closed
+type fakeExecutor struct {
+	mu      sync.Mutex
+	calls   []schedule.Action
+	err     error
+	block   chan struct{}
+	started chan struct{}
+}
+
+func (e *fakeExecutor) Execute(ctx context.Context, t *tenant.Tenant, s *schedule.Schedule) (*schedule.Result, error) {
+	e.mu.Lock()
+	e.calls = append(e.calls, s.Action)
+	e.mu.Unlock()
+	if e.started != nil {
+		e.started <- struct{}{}
+	}
+	if e.block != nil {
+		<-e.block
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &schedule.Result{Message: fmt.Sprintf("%s done", s.Action)}, nil
+}
+
+type controllerFixture struct {
+	store      *schedulememory.Store
+	tenants    *tenantmemory.Repository
+	executor   *fakeExecutor
+	controller *schedule.Controller
+	tenant     *tenant.Tenant
+}
+
+func newControllerFixture(t *testing.T, status tenant.Status) *controllerFixture {
+	t.Helper()
+	f := &controllerFixture{
+		store:    schedulememory.New(),
+		tenants:  tenantmemory.New(),
+		executor: &fakeExecutor{},
+		tenant:   &tenant.Tenant{Name: "acme", Status: status},
+	}
+	if err := f.tenants.CreateTenant(context.Background(), f.tenant); err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	f.controller = schedule.NewController(f.store, f.tenants, f.executor, config.ScheduleConfig{
+		Enabled:    true,
+		Interval:   time.Minute,
+		RunTimeout: time.Minute,
+	}, zap.NewNop())
+	return f
+}
+
+// addDue creates a schedule for the fixture's tenant that came due a minute ago
+func (f *controllerFixture) addDue(t *testing.T, name string, action schedule.Action) *schedule.Schedule {
+	t.Helper()
+	s := &schedule.Schedule{TenantID: f.tenant.ID, Name: name, Cron: "* * * * *", Action: action, Enabled: true}
+	if err := f.store.Create(context.Background(), s); err != nil {
+		t.Fatalf("create schedule: %v", err)
+	}
+	f.makeDue(t, s)
+	return s
+}
+
+func (f *controllerFixture) makeDue(t *testing.T, s *schedule.Schedule) {
+	t.Helper()
+	due := time.Now().Add(-time.Minute).Truncate(time.Minute)
+	s.NextRunAt = &due
+	if err := f.store.Update(context.Background(), s); err != nil {
+		t.Fatalf("update schedule: %v", err)
+	}
+}
+
+func (f *controllerFixture) runDue(t *testing.T) {
+	t.Helper()
+	if err := f.controller.RunDue(context.Background()); err != nil {
+		t.Fatalf("run due: %v", err)
+	}
+}
+
+func (f *controllerFixture) runs(t *testing.T, s *schedule.Schedule) []*schedule.Run {
+	t.Helper()
+	runs, err := f.store.ListRuns(context.Background(), schedule.RunFilters{ScheduleID: &s.ID})
+	if err != nil {
+		t.Fatalf("list runs: %v", err)
+	}
+	return runs
+}
+
+func TestControllerRunsDueSchedule(t *testing.T) {
+	f := newControllerFixture(t, tenant.StatusReady)
+	s := f.addDue(t, "nightly-restart", schedule.ActionRestart)
+	due := *s.NextRunAt
+
+	f.runDue(t)
+	f.controller.Wait()
+
+	runs := f.runs(t, s)
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 run, got %d", len(runs))
+	}
+	run := runs[0]
+	if run.Status != schedule.RunStatusSucceeded || run.Message != "restart done" || run.CompletedAt == nil {
+		t.Fatalf("unexpected run: %+v", run)
+	}
+	if !run.ScheduledAt.Equal(due) {
+		t.Fatalf("expected run scheduled at %s, got %s", due, run.ScheduledAt)
+	}
+
+	stored, err := f.store.Get(context.Background(), s.ID)
+	if err != nil {
+		t.Fatalf("get schedule: %v", err)
+	}
+	if stored.LastRunAt == nil || !stored.LastRunAt.Equal(due) {
+		t.Fatalf("expected last run at %s, got %v", due, stored.LastRunAt)
+	}
+	if stored.NextRunAt == nil || !stored.NextRunAt.After(time.Now()) {
+		t.Fatalf("expected next run in the future, got %v", stored.NextRunAt)
+	}
+
+	// Nothing is due until the next occurrence
+	f.runDue(t)
+	f.controller.Wait()
+	if got := len(f.runs(t, s)); got != 1 {
+		t.Fatalf("expected schedule not to fire again, got %d runs", got)
+	}
+}
+
+func TestControllerSkipsWhilePreviousRunInProgress(t *testing.T) {
+	f := newControllerFixture(t, tenant.StatusReady)
+	f.executor.block = make(chan struct{})
+	f.executor.started = make(chan struct{}, 1)
+	s := f.addDue(t, "smoke", schedule.ActionRestart)
+
+	f.runDue(t)
+	<-f.executor.started
+
+	// The next occurrence comes due before the first run finishes
+	f.makeDue(t, s)
+	f.runDue(t)
+
+	close(f.executor.block)
+	f.controller.Wait()
+
+	runs := f.runs(t, s)
+	if len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(runs))
+	}
+	if runs[0].Status != schedule.RunStatusSkipped || !strings.Contains(runs[0].Message, "still in progress") {
+		t.Fatalf("expected the overlapping run to be skipped, got %+v", runs[0])
+	}
+	if runs[1].Status != schedule.RunStatusSucceeded {
+		t.Fatalf("expected the first run to succeed, got %+v", runs[1])
+	}
+	if len(f.executor.calls) != 1 {
+		t.Fatalf("expected 1 execution, got %d", len(f.executor.calls))
+	}
+}
+
+func TestControllerSkipsTenantThatIsNotReady(t *testing.T) {
+	f := newControllerFixture(t, tenant.StatusProvisioning)
+	s := f.addDue(t, "nightly-restart", schedule.ActionRestart)
+
+	f.runDue(t)
+	f.controller.Wait()
+
+	runs := f.runs(t, s)
+	if len(runs) != 1 || runs[0].Status != schedule.RunStatusSkipped || runs[0].Message != "Tenant is provisioning" {
+		t.Fatalf("expected a skipped run, got %+v", runs)
+	}
+	if len(f.executor.calls) != 0 {
+		t.Fatalf("expected no execution, got %v", f.executor.calls)
+	}
+}
+
+func TestControllerRecordsSuspension(t *testing.T) {
+	ctx := context.Background()
+	f := newControllerFixture(t, tenant.StatusReady)
+	suspend := f.addDue(t, "evening", schedule.ActionSuspend)
+
+	f.runDue(t)
+	f.controller.Wait()
+
+	stored, err := f.tenants.GetTenantByID(ctx, f.tenant.ID)
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	condition := stored.Condition(schedule.ConditionSuspended)
+	if condition == nil || condition.Status != tenant.ConditionTrue || condition.Reason != "ScheduledSuspend" {
+		t.Fatalf("expected Suspended condition, got %+v", condition)
+	}
+
+	// Restarts are skipped while the tenant is suspended
+	restart := f.addDue(t, "nightly-restart", schedule.ActionRestart)
+	f.runDue(t)
+	f.controller.Wait()
+	runs := f.runs(t, restart)
+	if len(runs) != 1 || runs[0].Status != schedule.RunStatusSkipped || runs[0].Message != "Tenant is suspended" {
+		t.Fatalf("expected restart to be skipped, got %+v", runs)
+	}
+
+	if err := f.store.Delete(ctx, suspend.ID); err != nil {
+		t.Fatalf("delete schedule: %v", err)
+	}
+	f.addDue(t, "morning", schedule.ActionResume)
+	f.runDue(t)
+	f.controller.Wait()
+
+	stored, err = f.tenants.GetTenantByID(ctx, f.tenant.ID)
+	if err != nil {
+		t.Fatalf("get tenant: %v", err)
+	}
+	if condition := stored.Condition(schedule.ConditionSuspended); condition == nil || condition.Status != tenant.ConditionFalse {
+		t.Fatalf("expected Suspended condition to be cleared, got %+v", condition)
+	}
+}
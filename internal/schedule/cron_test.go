@@ -0,0 +1,96 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{expr: "* * * *", want: "must have 5 fields"},
+		{expr: "60 * * * *", want: "cron minute: value 60 out of range 0-59"},
+		{expr: "* 5-1 * * *", want: "cron hour: range \"5-1\" runs backwards"},
+		{expr: "*/0 * * * *", want: "cron minute: invalid step"},
+		{expr: "* * * foo *", want: "cron month: invalid value \"foo\""},
+		{expr: "@fortnightly", want: "must have 5 fields"},
+	}
+	for _, tt := range tests {
+		_, err := ParseCron(tt.expr)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("ParseCron(%q): expected error containing %q, got %v", tt.expr, tt.want, err)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	// Wednesday
+	from := time.Date(2026, time.March, 4, 18, 7, 30, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "*/15 * * * *", want: time.Date(2026, time.March, 4, 18, 15, 0, 0, time.UTC)},
+		{expr: "0 19 * * 1-5", want: time.Date(2026, time.March, 4, 19, 0, 0, 0, time.UTC)},
+		{expr: "0 7 * * mon-fri", want: time.Date(2026, time.March, 5, 7, 0, 0, 0, time.UTC)},
+		{expr: "30 2 * * sat,7", want: time.Date(2026, time.March, 7, 2, 30, 0, 0, time.UTC)},
+		{expr: "@daily", want: time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC)},
+		{expr: "@monthly", want: time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", want: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches
+		{expr: "0 0 10 * sun", want: time.Date(2026, time.March, 8, 0, 0, 0, 0, time.UTC)},
+		// A stepped wildcard day of month does not restrict the day
+		{expr: "0 12 */2 * fri", want: time.Date(2026, time.March, 13, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		c, err := ParseCron(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+		}
+		if got := c.Next(from); !got.Equal(tt.want) {
+			t.Fatalf("Next(%q): expected %s, got %s", tt.expr, tt.want, got)
+		}
+	}
+}
+
+func TestCronNextNeverMatches(t *testing.T) {
+	c, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("ParseCron: %v", err)
+	}
+	if got := c.Next(time.Now()); !got.IsZero() {
+		t.Fatalf("expected no match, got %s", got)
+	}
+}
+
+func TestScheduleNextUsesTimezone(t *testing.T) {
+	s := &Schedule{Name: "evening", Cron: "0 19 * * *", Timezone: "America/New_York", Action: ActionSuspend, Enabled: true}
+	if err := s.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	// 19:00 in New York is 23:00 UTC during daylight saving time
+	if err := s.Reschedule(time.Date(2026, time.July, 1, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("reschedule: %v", err)
+	}
+	want := time.Date(2026, time.July, 1, 23, 0, 0, 0, time.UTC)
+	if s.NextRunAt == nil || !s.NextRunAt.Equal(want) || s.NextRunAt.Location() != time.UTC {
+		t.Fatalf("expected next run at %s, got %v", want, s.NextRunAt)
+	}
+
+	s.Enabled = false
+	if err := s.Reschedule(time.Now()); err != nil {
+		t.Fatalf("reschedule disabled: %v", err)
+	}
+	if s.NextRunAt != nil {
+		t.Fatalf("expected disabled schedule to have no next run, got %s", s.NextRunAt)
+	}
+
+	s.Timezone = "Mars/Olympus_Mons"
+	if err := s.Validate(); err == nil || !strings.Contains(err.Error(), "unknown timezone") {
+		t.Fatalf("expected unknown timezone error, got %v", err)
+	}
+}
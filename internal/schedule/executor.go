@@ -0,0 +1,143 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// Result describes a completed action
+type Result struct {
+	// Message summarizes what the action did
+	Message string
+
+	// Output is the output of a hook run
+	Output string
+}
+
+// Executor performs a schedule's action on a tenant. It may return a Result alongside an
+// error, such as the output of a failed hook.
+type Executor interface {
+	Execute(ctx context.Context, t *tenant.Tenant, s *Schedule) (*Result, error)
+}
+
+// ComputeExecutor performs actions through the tenant's compute provider. Restart, suspend
+// and resume need a provider that implements compute.PowerManager; hooks run as they do in
+// provisioning workflows.
+type ComputeExecutor struct {
+	registry        *compute.Registry
+	defaultProvider string
+	hooks           *workflow.HookRunner
+}
+
+var _ Executor = (*ComputeExecutor)(nil)
+
+// NewComputeExecutor creates an executor; tenants that do not name a compute_provider use defaultProvider
+func NewComputeExecutor(registry *compute.Registry, defaultProvider string, hooks *workflow.HookRunner) *ComputeExecutor {
+	return &ComputeExecutor{
+		registry:        registry,
+		defaultProvider: defaultProvider,
+		hooks:           hooks,
+	}
+}
+
+// Execute runs s's action on t
+func (e *ComputeExecutor) Execute(ctx context.Context, t *tenant.Tenant, s *Schedule) (*Result, error) {
+	providerName := tenantComputeProvider(t)
+	if providerName == "" {
+		providerName = e.defaultProvider
+	}
+	provider, err := e.registry.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Action == ActionRunHook {
+		return e.runHook(ctx, t, s, provider)
+	}
+
+	power, ok := provider.(compute.PowerManager)
+	if !ok {
+		return nil, fmt.Errorf("compute provider %s does not support %s", providerName, s.Action)
+	}
+	switch s.Action {
+	case ActionRestart:
+		err = power.Restart(ctx, t.ComputeName())
+	case ActionSuspend:
+		err = power.Suspend(ctx, t.ComputeName())
+	case ActionResume:
+		err = power.Resume(ctx, t.ComputeName())
+	default:
+		return nil, fmt.Errorf("unknown action %q", s.Action)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Message: fmt.Sprintf("Tenant %s by schedule %s", pastTense[s.Action], s.Name)}, nil
+}
+
+var pastTense = map[Action]string{
+	ActionRestart: "restarted",
+	ActionSuspend: "suspended",
+	ActionResume:  "resumed",
+}
+
+func (e *ComputeExecutor) runHook(ctx context.Context, t *tenant.Tenant, s *Schedule, provider compute.Provider) (*Result, error) {
+	phase, spec, err := FindHook(t.DesiredConfig, s.Hook)
+	if err != nil {
+		return nil, err
+	}
+
+	jobRunner, _ := provider.(compute.JobRunner)
+	results, err := e.hooks.RunPhase(ctx, t.ComputeName(), phase, []workflow.HookSpec{*spec}, jobRunner)
+	if len(results) == 0 {
+		return nil, err
+	}
+	result := &Result{
+		Message: fmt.Sprintf("%s hook %q %s after %d attempt(s)", phase, spec.Name, results[0].Status, results[0].Attempts),
+		Output:  results[0].Output,
+	}
+	return result, err
+}
+
+// tenantComputeProvider returns the compute provider a tenant names, resolved as the API does
+func tenantComputeProvider(t *tenant.Tenant) string {
+	for _, key := range []string{"compute_provider", "compute_provider_type"} {
+		if name, ok := t.DesiredConfig[key].(string); ok {
+			return name
+		}
+	}
+	if name, ok := t.Labels["compute_provider"]; ok {
+		return name
+	}
+	if name, ok := t.Annotations["compute_provider"]; ok {
+		return name
+	}
+	return ""
+}
+
+// FindHook returns the phase and declaration of the hook named name in a tenant's desired config
+func FindHook(desiredConfig map[string]interface{}, name string) (string, *workflow.HookSpec, error) {
+	hooks, err := workflow.ParseHooks(desiredConfig)
+	if err != nil {
+		return "", nil, err
+	}
+	if hooks != nil {
+		// A name may be declared in both phases; the pre_provision hook wins
+		for _, phase := range []string{workflow.HookPhasePreProvision, workflow.HookPhasePostProvision} {
+			specs := hooks.PreProvision
+			if phase == workflow.HookPhasePostProvision {
+				specs = hooks.PostProvision
+			}
+			for i := range specs {
+				if specs[i].Name == name {
+					return phase, &specs[i], nil
+				}
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("tenant declares no hook named %q", name)
+}
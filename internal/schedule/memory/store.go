@@ -0,0 +1,222 @@
+// Package memory provides an in-memory schedule store for tests and local harnesses.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/schedule"
+)
+
+// Store implements schedule.Store in memory.
+// Schedules and runs are copied on the way in and out, so callers never share state with the store.
+type Store struct {
+	mu        sync.RWMutex
+	schedules map[uuid.UUID]schedule.Schedule
+
+	// runs are kept in creation order
+	runs []schedule.Run
+}
+
+var _ schedule.Store = (*Store)(nil)
+
+// New creates an empty in-memory store
+func New() *Store {
+	return &Store{schedules: make(map[uuid.UUID]schedule.Schedule)}
+}
+
+func (s *Store) Create(ctx context.Context, sched *schedule.Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.nameTaken(sched.TenantID, sched.Name, uuid.Nil) {
+		return schedule.ErrNameConflict
+	}
+	now := time.Now()
+	sched.ID = uuid.New()
+	sched.CreatedAt = now
+	sched.UpdatedAt = now
+	s.schedules[sched.ID] = copySchedule(sched)
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, id uuid.UUID) (*schedule.Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sched, ok := s.schedules[id]
+	if !ok {
+		return nil, schedule.ErrNotFound
+	}
+	result := copySchedule(&sched)
+	return &result, nil
+}
+
+func (s *Store) List(ctx context.Context, filters schedule.ListFilters) ([]*schedule.Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	schedules := []*schedule.Schedule{}
+	for _, sched := range s.schedules {
+		if filters.TenantID != nil && sched.TenantID != *filters.TenantID {
+			continue
+		}
+		result := copySchedule(&sched)
+		schedules = append(schedules, &result)
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].Name < schedules[j].Name
+	})
+	return schedules, nil
+}
+
+func (s *Store) Update(ctx context.Context, sched *schedule.Schedule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.schedules[sched.ID]
+	if !ok {
+		return schedule.ErrNotFound
+	}
+	if s.nameTaken(stored.TenantID, sched.Name, sched.ID) {
+		return schedule.ErrNameConflict
+	}
+	sched.TenantID = stored.TenantID
+	sched.CreatedAt = stored.CreatedAt
+	sched.LastRunAt = copyTime(stored.LastRunAt)
+	sched.UpdatedAt = time.Now()
+	s.schedules[sched.ID] = copySchedule(sched)
+	return nil
+}
+
+func (s *Store) Delete(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.schedules[id]; !ok {
+		return schedule.ErrNotFound
+	}
+	delete(s.schedules, id)
+
+	runs := s.runs[:0]
+	for _, r := range s.runs {
+		if r.ScheduleID != id {
+			runs = append(runs, r)
+		}
+	}
+	s.runs = runs
+	return nil
+}
+
+func (s *Store) Due(ctx context.Context, now time.Time) ([]*schedule.Schedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	due := []*schedule.Schedule{}
+	for _, sched := range s.schedules {
+		if !sched.Enabled || sched.NextRunAt == nil || sched.NextRunAt.After(now) {
+			continue
+		}
+		result := copySchedule(&sched)
+		due = append(due, &result)
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextRunAt.Before(*due[j].NextRunAt)
+	})
+	return due, nil
+}
+
+func (s *Store) Claim(ctx context.Context, id uuid.UUID, due, next time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sched, ok := s.schedules[id]
+	if !ok {
+		return schedule.ErrNotFound
+	}
+	if !sched.Enabled || sched.NextRunAt == nil || !sched.NextRunAt.Equal(due) {
+		return schedule.ErrAlreadyClaimed
+	}
+	sched.LastRunAt = &due
+	sched.NextRunAt = &next
+	s.schedules[id] = sched
+	return nil
+}
+
+func (s *Store) CreateRun(ctx context.Context, r *schedule.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r.ID = uuid.New()
+	s.runs = append(s.runs, copyRun(r))
+	return nil
+}
+
+func (s *Store) UpdateRun(ctx context.Context, r *schedule.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.runs {
+		if s.runs[i].ID == r.ID {
+			s.runs[i] = copyRun(r)
+			return nil
+		}
+	}
+	return schedule.ErrRunNotFound
+}
+
+func (s *Store) ListRuns(ctx context.Context, filters schedule.RunFilters) ([]*schedule.Run, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	runs := []*schedule.Run{}
+	for i := len(s.runs) - 1; i >= 0; i-- {
+		r := s.runs[i]
+		if filters.ScheduleID != nil && r.ScheduleID != *filters.ScheduleID {
+			continue
+		}
+		if filters.Status != "" && r.Status != filters.Status {
+			continue
+		}
+		result := copyRun(&r)
+		runs = append(runs, &result)
+		if filters.Limit > 0 && len(runs) == filters.Limit {
+			break
+		}
+	}
+	return runs, nil
+}
+
+func (s *Store) nameTaken(tenantID uuid.UUID, name string, except uuid.UUID) bool {
+	for id, sched := range s.schedules {
+		if id != except && sched.TenantID == tenantID && sched.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func copySchedule(sched *schedule.Schedule) schedule.Schedule {
+	result := *sched
+	result.NextRunAt = copyTime(sched.NextRunAt)
+	result.LastRunAt = copyTime(sched.LastRunAt)
+	return result
+}
+
+func copyRun(r *schedule.Run) schedule.Run {
+	result := *r
+	result.CompletedAt = copyTime(r.CompletedAt)
+	return result
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	value := *t
+	return &value
+}
@@ -0,0 +1,279 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/schedule"
+)
+
+// Store implements schedule.Store for PostgreSQL
+type Store struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+}
+
+var _ schedule.Store = (*Store)(nil)
+
+// New creates a PostgreSQL schedule store
+// Accepts interface{} to satisfy provider abstraction, type asserts to *pgxpool.Pool
+func New(pool interface{}, logger *zap.Logger) (*Store, error) {
+	pgPool, ok := pool.(*pgxpool.Pool)
+	if !ok {
+		return nil, fmt.Errorf("expected *pgxpool.Pool, got %T", pool)
+	}
+	return &Store{
+		pool:   pgPool,
+		logger: logger.With(zap.String("component", "schedule-postgres-store")),
+	}, nil
+}
+
+const createScheduleQuery = `
+INSERT INTO schedules (id, tenant_id, name, cron, timezone, action, hook, enabled, next_run_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING created_at, updated_at
+`
+
+func (s *Store) Create(ctx context.Context, sched *schedule.Schedule) error {
+	sched.ID = uuid.New()
+	if err := s.pool.QueryRow(ctx, createScheduleQuery,
+		sched.ID,
+		sched.TenantID,
+		sched.Name,
+		sched.Cron,
+		sched.Timezone,
+		sched.Action,
+		sched.Hook,
+		sched.Enabled,
+		sched.NextRunAt,
+	).Scan(&sched.CreatedAt, &sched.UpdatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return schedule.ErrNameConflict
+		}
+		return fmt.Errorf("create schedule: %w", err)
+	}
+
+	s.logger.Info("schedule created",
+		zap.String("id", sched.ID.String()),
+		zap.String("tenant_id", sched.TenantID.String()),
+		zap.String("name", sched.Name),
+		zap.String("action", string(sched.Action)))
+	return nil
+}
+
+const scheduleColumns = `id, tenant_id, name, cron, timezone, action, hook, enabled, next_run_at, last_run_at, created_at, updated_at`
+
+func (s *Store) Get(ctx context.Context, id uuid.UUID) (*schedule.Schedule, error) {
+	sched, err := scanSchedule(s.pool.QueryRow(ctx, `SELECT `+scheduleColumns+` FROM schedules WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, schedule.ErrNotFound
+		}
+		return nil, fmt.Errorf("get schedule: %w", err)
+	}
+	return sched, nil
+}
+
+func (s *Store) List(ctx context.Context, filters schedule.ListFilters) ([]*schedule.Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE 1=1`
+	var args []interface{}
+	if filters.TenantID != nil {
+		args = append(args, *filters.TenantID)
+		query += fmt.Sprintf(" AND tenant_id = $%d", len(args))
+	}
+	query += " ORDER BY name"
+	return s.querySchedules(ctx, query, args...)
+}
+
+const updateScheduleQuery = `
+UPDATE schedules
+SET name = $2, cron = $3, timezone = $4, action = $5, hook = $6, enabled = $7, next_run_at = $8, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING tenant_id, last_run_at, created_at, updated_at
+`
+
+func (s *Store) Update(ctx context.Context, sched *schedule.Schedule) error {
+	if err := s.pool.QueryRow(ctx, updateScheduleQuery,
+		sched.ID,
+		sched.Name,
+		sched.Cron,
+		sched.Timezone,
+		sched.Action,
+		sched.Hook,
+		sched.Enabled,
+		sched.NextRunAt,
+	).Scan(&sched.TenantID, &sched.LastRunAt, &sched.CreatedAt, &sched.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return schedule.ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return schedule.ErrNameConflict
+		}
+		return fmt.Errorf("update schedule: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Delete(ctx context.Context, id uuid.UUID) error {
+	// Runs are removed by the foreign key cascade
+	tag, err := s.pool.Exec(ctx, `DELETE FROM schedules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete schedule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return schedule.ErrNotFound
+	}
+	s.logger.Info("schedule deleted", zap.String("id", id.String()))
+	return nil
+}
+
+func (s *Store) Due(ctx context.Context, now time.Time) ([]*schedule.Schedule, error) {
+	return s.querySchedules(ctx,
+		`SELECT `+scheduleColumns+` FROM schedules WHERE enabled AND next_run_at <= $1 ORDER BY next_run_at`,
+		now.UTC())
+}
+
+const claimScheduleQuery = `
+UPDATE schedules
+SET next_run_at = $3, last_run_at = $2
+WHERE id = $1 AND enabled AND next_run_at = $2
+`
+
+func (s *Store) Claim(ctx context.Context, id uuid.UUID, due, next time.Time) error {
+	tag, err := s.pool.Exec(ctx, claimScheduleQuery, id, due.UTC(), next.UTC())
+	if err != nil {
+		return fmt.Errorf("claim schedule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := s.Get(ctx, id); err != nil {
+			return err
+		}
+		return schedule.ErrAlreadyClaimed
+	}
+	return nil
+}
+
+const createRunQuery = `
+INSERT INTO schedule_runs (id, schedule_id, tenant_id, action, status, scheduled_at, started_at, completed_at, message, error, output)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+`
+
+func (s *Store) CreateRun(ctx context.Context, r *schedule.Run) error {
+	r.ID = uuid.New()
+	if _, err := s.pool.Exec(ctx, createRunQuery,
+		r.ID,
+		r.ScheduleID,
+		r.TenantID,
+		r.Action,
+		r.Status,
+		r.ScheduledAt.UTC(),
+		r.StartedAt.UTC(),
+		r.CompletedAt,
+		r.Message,
+		r.Error,
+		r.Output,
+	); err != nil {
+		return fmt.Errorf("create schedule run: %w", err)
+	}
+	return nil
+}
+
+const updateRunQuery = `
+UPDATE schedule_runs
+SET status = $2, completed_at = $3, message = $4, error = $5, output = $6
+WHERE id = $1
+`
+
+func (s *Store) UpdateRun(ctx context.Context, r *schedule.Run) error {
+	tag, err := s.pool.Exec(ctx, updateRunQuery, r.ID, r.Status, r.CompletedAt, r.Message, r.Error, r.Output)
+	if err != nil {
+		return fmt.Errorf("update schedule run: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return schedule.ErrRunNotFound
+	}
+	return nil
+}
+
+const runColumns = `id, schedule_id, tenant_id, action, status, scheduled_at, started_at, completed_at, message, error, output`
+
+func (s *Store) ListRuns(ctx context.Context, filters schedule.RunFilters) ([]*schedule.Run, error) {
+	query := `SELECT ` + runColumns + ` FROM schedule_runs WHERE 1=1`
+	var args []interface{}
+	if filters.ScheduleID != nil {
+		args = append(args, *filters.ScheduleID)
+		query += fmt.Sprintf(" AND schedule_id = $%d", len(args))
+	}
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		query += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+	query += " ORDER BY started_at DESC, seq DESC"
+	if filters.Limit > 0 {
+		args = append(args, filters.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list schedule runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []*schedule.Run{}
+	for rows.Next() {
+		r := &schedule.Run{}
+		if err := rows.Scan(&r.ID, &r.ScheduleID, &r.TenantID, &r.Action, &r.Status, &r.ScheduledAt, &r.StartedAt, &r.CompletedAt, &r.Message, &r.Error, &r.Output); err != nil {
+			return nil, fmt.Errorf("scan schedule run: %w", err)
+		}
+		runs = append(runs, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate schedule runs: %w", err)
+	}
+	return runs, nil
+}
+
+func (s *Store) querySchedules(ctx context.Context, query string, args ...interface{}) ([]*schedule.Schedule, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := []*schedule.Schedule{}
+	for rows.Next() {
+		sched, err := scanSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan schedule: %w", err)
+		}
+		schedules = append(schedules, sched)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+func scanSchedule(row pgx.Row) (*schedule.Schedule, error) {
+	sched := &schedule.Schedule{}
+	if err := row.Scan(&sched.ID, &sched.TenantID, &sched.Name, &sched.Cron, &sched.Timezone, &sched.Action, &sched.Hook,
+		&sched.Enabled, &sched.NextRunAt, &sched.LastRunAt, &sched.CreatedAt, &sched.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return sched, nil
+}
+
+// isUniqueViolation checks if error is unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,249 @@
+// Package schedule runs tenant operations on cron schedules: restarting a tenant nightly,
+// suspending it out of hours and resuming it in the morning, or re-running one of its hooks.
+// Schedules and the history of their runs are persisted in a Store, and a Controller fires
+// them when they are due.
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Action is what a schedule does to its tenant
+type Action string
+
+const (
+	// ActionRestart restarts the tenant's workloads in place
+	ActionRestart Action = "restart"
+
+	// ActionSuspend stops the tenant's workloads without deprovisioning them
+	ActionSuspend Action = "suspend"
+
+	// ActionResume starts workloads stopped by a suspend
+	ActionResume Action = "resume"
+
+	// ActionRunHook runs one of the hooks declared in the tenant's desired_config.hooks
+	ActionRunHook Action = "run_hook"
+)
+
+// IsValid reports whether a is a known action
+func (a Action) IsValid() bool {
+	switch a {
+	case ActionRestart, ActionSuspend, ActionResume, ActionRunHook:
+		return true
+	default:
+		return false
+	}
+}
+
+// RunStatus is the outcome of a schedule run
+type RunStatus string
+
+const (
+	RunStatusRunning   RunStatus = "running"
+	RunStatusSucceeded RunStatus = "succeeded"
+	RunStatusFailed    RunStatus = "failed"
+
+	// RunStatusSkipped records a fire that did not run, because the previous run was still in
+	// progress or the tenant was not ready
+	RunStatusSkipped RunStatus = "skipped"
+)
+
+var (
+	// ErrNotFound is returned when no schedule has the ID
+	ErrNotFound = errors.New("schedule not found")
+
+	// ErrNameConflict is returned when the tenant already has a schedule with the name
+	ErrNameConflict = errors.New("schedule name already exists for tenant")
+
+	// ErrAlreadyClaimed is returned by Claim when another controller fired the schedule first
+	ErrAlreadyClaimed = errors.New("schedule already claimed")
+
+	// ErrRunNotFound is returned when no run has the ID
+	ErrRunNotFound = errors.New("schedule run not found")
+)
+
+// Schedule runs an action on a tenant whenever its cron expression matches
+type Schedule struct {
+	ID       uuid.UUID `json:"id"`
+	TenantID uuid.UUID `json:"tenant_id"`
+
+	// Name identifies the schedule within its tenant
+	Name string `json:"name"`
+
+	// Cron is a five-field cron expression or descriptor such as "@daily"
+	Cron string `json:"cron"`
+
+	// Timezone is the IANA time zone the expression is evaluated in (default UTC)
+	Timezone string `json:"timezone,omitempty"`
+
+	Action Action `json:"action"`
+
+	// Hook names the hook run by run_hook schedules
+	Hook string `json:"hook,omitempty"`
+
+	Enabled bool `json:"enabled"`
+
+	// NextRunAt is when the schedule fires next; nil while it is disabled
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+
+	// LastRunAt is when the schedule last fired
+	LastRunAt *time.Time `json:"last_run_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the schedule's name, expression, time zone and action
+func (s *Schedule) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if _, err := ParseCron(s.Cron); err != nil {
+		return err
+	}
+	if _, err := s.location(); err != nil {
+		return err
+	}
+	if !s.Action.IsValid() {
+		return fmt.Errorf("action must be %s, %s, %s or %s", ActionRestart, ActionSuspend, ActionResume, ActionRunHook)
+	}
+	if s.Action == ActionRunHook && strings.TrimSpace(s.Hook) == "" {
+		return fmt.Errorf("hook is required for %s schedules", ActionRunHook)
+	}
+	if s.Action != ActionRunHook && s.Hook != "" {
+		return fmt.Errorf("hook is only valid for %s schedules", ActionRunHook)
+	}
+	return nil
+}
+
+// Next returns when the schedule fires after t, in UTC
+func (s *Schedule) Next(t time.Time) (time.Time, error) {
+	cron, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := s.location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	next := cron.Next(t.In(loc))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("cron expression %q never matches", s.Cron)
+	}
+	return next.UTC(), nil
+}
+
+// Reschedule sets NextRunAt to the first fire after now, or clears it when the schedule is disabled
+func (s *Schedule) Reschedule(now time.Time) error {
+	if !s.Enabled {
+		s.NextRunAt = nil
+		return nil
+	}
+	next, err := s.Next(now)
+	if err != nil {
+		return err
+	}
+	s.NextRunAt = &next
+	return nil
+}
+
+func (s *Schedule) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", s.Timezone)
+	}
+	return loc, nil
+}
+
+// Run is one firing of a schedule
+type Run struct {
+	ID         uuid.UUID `json:"id"`
+	ScheduleID uuid.UUID `json:"schedule_id"`
+	TenantID   uuid.UUID `json:"tenant_id"`
+	Action     Action    `json:"action"`
+	Status     RunStatus `json:"status"`
+
+	// ScheduledAt is the fire time the run belongs to; StartedAt may be later
+	ScheduledAt time.Time  `json:"scheduled_at"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+
+	// Message summarizes the outcome, or why the run was skipped
+	Message string `json:"message,omitempty"`
+
+	// Error is set when the run failed
+	Error string `json:"error,omitempty"`
+
+	// Output is the (possibly truncated) output of a run_hook run
+	Output string `json:"output,omitempty"`
+}
+
+// Finish completes the run with status and message
+func (r *Run) Finish(status RunStatus, message string, now time.Time) {
+	r.Status = status
+	r.Message = message
+	r.CompletedAt = &now
+}
+
+// ListFilters narrows List; zero values match everything
+type ListFilters struct {
+	TenantID *uuid.UUID
+}
+
+// RunFilters narrows ListRuns; zero values match everything
+type RunFilters struct {
+	ScheduleID *uuid.UUID
+	Status     RunStatus
+
+	// Limit caps the number of runs returned; zero returns all of them
+	Limit int
+}
+
+// Store persists schedules and their runs
+type Store interface {
+	// Create persists a new schedule, populating ID, CreatedAt and UpdatedAt
+	// Returns ErrNameConflict if the tenant already has a schedule with the name
+	Create(ctx context.Context, s *Schedule) error
+
+	// Get retrieves a schedule by ID
+	// Returns ErrNotFound if not found
+	Get(ctx context.Context, id uuid.UUID) (*Schedule, error)
+
+	// List returns the schedules matching filters, sorted by name
+	List(ctx context.Context, filters ListFilters) ([]*Schedule, error)
+
+	// Update saves a schedule's definition and NextRunAt; LastRunAt is only written by Claim
+	// Returns ErrNotFound if not found and ErrNameConflict if the new name is taken
+	Update(ctx context.Context, s *Schedule) error
+
+	// Delete removes a schedule and its runs
+	// Returns ErrNotFound if not found
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// Due returns the enabled schedules whose NextRunAt is at or before now
+	Due(ctx context.Context, now time.Time) ([]*Schedule, error)
+
+	// Claim moves a schedule that is due at due on to next, recording due as its LastRunAt.
+	// It is atomic, so only one controller fires each occurrence.
+	// Returns ErrAlreadyClaimed if NextRunAt is no longer due.
+	Claim(ctx context.Context, id uuid.UUID, due, next time.Time) error
+
+	// CreateRun persists a new run, populating ID
+	CreateRun(ctx context.Context, r *Run) error
+
+	// UpdateRun saves a run's status and outcome
+	// Returns ErrRunNotFound if not found
+	UpdateRun(ctx context.Context, r *Run) error
+
+	// ListRuns returns the runs matching filters, newest first
+	ListRuns(ctx context.Context, filters RunFilters) ([]*Run, error)
+}
@@ -0,0 +1,227 @@
+package supportbundle
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// EnvelopeFormat identifies sealed bundles and their encryption scheme
+const EnvelopeFormat = "landlord-support-bundle/v1"
+
+// ErrUntrustedSigner is returned when a bundle was signed by a key other than the one trusted
+var ErrUntrustedSigner = errors.New("bundle is not signed by the trusted key")
+
+// Envelope is a sealed bundle. The archive is encrypted with AES-256-GCM under a key agreed with
+// X25519 between a one-time key and the recipient's key, and the envelope is signed with the
+// operator's Ed25519 key. Byte fields are base64 in JSON.
+type Envelope struct {
+	Format       string `json:"format"`
+	EphemeralKey []byte `json:"ephemeral_key"`
+	Nonce        []byte `json:"nonce"`
+	Ciphertext   []byte `json:"ciphertext"`
+	SignerKey    []byte `json:"signer_key"`
+	Signature    []byte `json:"signature"`
+}
+
+// Seal encrypts archive to recipient and signs it with signer, returning the envelope as JSON
+func Seal(archive []byte, recipient *ecdh.PublicKey, signer ed25519.PrivateKey) ([]byte, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+	aead, err := envelopeCipher(ephemeral, recipient, ephemeral.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+
+	env := Envelope{
+		Format:       EnvelopeFormat,
+		EphemeralKey: ephemeral.PublicKey().Bytes(),
+		Nonce:        make([]byte, aead.NonceSize()),
+		SignerKey:    signer.Public().(ed25519.PublicKey),
+	}
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	env.Ciphertext = aead.Seal(nil, env.Nonce, archive, env.additionalData())
+	env.Signature = ed25519.Sign(signer, env.signedData())
+	return json.Marshal(env)
+}
+
+// Open verifies that sealed was signed by trusted and decrypts it with recipient's key, returning
+// the archive
+func Open(sealed []byte, recipient *ecdh.PrivateKey, trusted ed25519.PublicKey) ([]byte, error) {
+	var env Envelope
+	if err := json.Unmarshal(sealed, &env); err != nil {
+		return nil, fmt.Errorf("decode bundle: %w", err)
+	}
+	if env.Format != EnvelopeFormat {
+		return nil, fmt.Errorf("unsupported bundle format %q", env.Format)
+	}
+	if !bytes.Equal(env.SignerKey, trusted) {
+		return nil, ErrUntrustedSigner
+	}
+	if !ed25519.Verify(trusted, env.signedData(), env.Signature) {
+		return nil, fmt.Errorf("bundle signature is invalid")
+	}
+
+	ephemeral, err := ecdh.X25519().NewPublicKey(env.EphemeralKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode ephemeral key: %w", err)
+	}
+	aead, err := envelopeCipher(recipient, ephemeral, ephemeral)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("bundle nonce is %d bytes, want %d", len(env.Nonce), aead.NonceSize())
+	}
+	archive, err := aead.Open(nil, env.Nonce, env.Ciphertext, env.additionalData())
+	if err != nil {
+		return nil, fmt.Errorf("decrypt bundle: not sealed to this key, or corrupted")
+	}
+	return archive, nil
+}
+
+// envelopeCipher derives the AES-256-GCM cipher from the X25519 agreement of private and peer.
+// The one-time public key and the recipient's public key salt the derivation.
+func envelopeCipher(private *ecdh.PrivateKey, peer, ephemeral *ecdh.PublicKey) (cipher.AEAD, error) {
+	shared, err := private.ECDH(peer)
+	if err != nil {
+		return nil, fmt.Errorf("agree key: %w", err)
+	}
+	recipient := peer
+	if peer == ephemeral {
+		recipient = private.PublicKey()
+	}
+	salt := append(append([]byte{}, ephemeral.Bytes()...), recipient.Bytes()...)
+	key, err := hkdf.Key(sha256.New, shared, salt, EnvelopeFormat, 32)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (e *Envelope) additionalData() []byte {
+	return append([]byte(e.Format+"\x00"), e.EphemeralKey...)
+}
+
+// signedData is what the signature covers: everything in the envelope but the signature
+func (e *Envelope) signedData() []byte {
+	data := e.additionalData()
+	data = append(data, e.Nonce...)
+	data = append(data, e.SignerKey...)
+	return append(data, e.Ciphertext...)
+}
+
+// GenerateRecipientKey generates the X25519 key bundles are encrypted to
+func GenerateRecipientKey() (*ecdh.PrivateKey, error) {
+	return ecdh.X25519().GenerateKey(rand.Reader)
+}
+
+// GenerateSigningKey generates the Ed25519 key operators sign bundles with
+func GenerateSigningKey() (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	return key, err
+}
+
+// MarshalPrivateKey encodes a recipient or signing key as a PKCS #8 PEM block
+func MarshalPrivateKey(key interface{}) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// MarshalPublicKey encodes a recipient or signing public key as a PKIX PEM block
+func MarshalPublicKey(key interface{}) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
+
+// ParseRecipientPublicKey reads the X25519 public key bundles are encrypted to
+func ParseRecipientPublicKey(data []byte) (*ecdh.PublicKey, error) {
+	key, err := parsePublicKey(data)
+	if err != nil {
+		return nil, err
+	}
+	recipient, ok := key.(*ecdh.PublicKey)
+	if !ok || recipient.Curve() != ecdh.X25519() {
+		return nil, fmt.Errorf("not an X25519 public key")
+	}
+	return recipient, nil
+}
+
+// ParseRecipientPrivateKey reads the X25519 private key bundles are decrypted with
+func ParseRecipientPrivateKey(data []byte) (*ecdh.PrivateKey, error) {
+	key, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	recipient, ok := key.(*ecdh.PrivateKey)
+	if !ok || recipient.Curve() != ecdh.X25519() {
+		return nil, fmt.Errorf("not an X25519 private key")
+	}
+	return recipient, nil
+}
+
+// ParseSigningPublicKey reads the Ed25519 public key bundles are verified with
+func ParseSigningPublicKey(data []byte) (ed25519.PublicKey, error) {
+	key, err := parsePublicKey(data)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an Ed25519 public key")
+	}
+	return signer, nil
+}
+
+// ParseSigningPrivateKey reads the Ed25519 private key bundles are signed with
+func ParseSigningPrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	key, err := parsePrivateKey(data)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("not an Ed25519 private key")
+	}
+	return signer, nil
+}
+
+func parsePublicKey(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("expected a PEM PUBLIC KEY block")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
+
+func parsePrivateKey(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, fmt.Errorf("expected a PEM PRIVATE KEY block")
+	}
+	return x509.ParsePKCS8PrivateKey(block.Bytes)
+}
@@ -0,0 +1,369 @@
+// Package supportbundle builds the archives operators attach to support tickets: a tenant's record,
+// state history, recent executions, compute provider status and the landlord log lines that mention
+// it. Secrets are redacted before anything is archived, and the archive is encrypted to the support
+// team's key and signed with the operator's, so it can travel over ticketing systems.
+package supportbundle
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/redact"
+)
+
+// FormatVersion is the version of the archive layout recorded in the manifest
+const FormatVersion = 1
+
+// ManifestName is the archive entry holding the manifest
+const ManifestName = "manifest.json"
+
+// Limits on what a bundle collects
+const (
+	DefaultHistoryLimit = 1000
+	DefaultExecutions   = 50
+	DefaultLogLines     = 2000
+
+	historyPageSize = 500
+	maxExecutions   = 500
+)
+
+// Source reads what a bundle holds from the landlord API. *cli.Client implements it.
+type Source interface {
+	GetTenant(ctx context.Context, tenantID string) (*models.TenantResponse, error)
+	GetTenantHistory(ctx context.Context, tenantID string, limit int, cursor string) (*models.TenantHistoryResponse, error)
+	GetTenantTimeline(ctx context.Context, tenantID string, types []string, limit int) (*models.TenantTimelineResponse, error)
+	GetTenantResolution(ctx context.Context, tenantID string) (*models.TenantResolutionResponse, error)
+	GetTenantEffectiveConfig(ctx context.Context, tenantID string) (*models.TenantEffectiveConfigResponse, error)
+	GetTenantUptime(ctx context.Context, tenantID string) (*models.TenantUptimeResponse, error)
+	GetVersion(ctx context.Context) (*models.VersionResponse, error)
+}
+
+// Options bound what Collect gathers
+type Options struct {
+	// HistoryLimit is the number of most recent state transitions collected
+	HistoryLimit int
+
+	// Executions is the number of most recent workflow and compute executions collected
+	Executions int
+
+	// LogFiles are landlord log files; the lines that mention the tenant are collected
+	LogFiles []string
+
+	// LogLines is the number of most recent matching lines collected from each log file
+	LogLines int
+
+	// CLIVersion is recorded in the manifest
+	CLIVersion string
+}
+
+// Manifest describes a bundle's contents. It is the first entry of the archive.
+type Manifest struct {
+	FormatVersion int            `json:"format_version"`
+	TenantID      string         `json:"tenant_id"`
+	TenantName    string         `json:"tenant_name"`
+	CreatedAt     time.Time      `json:"created_at"`
+	CLIVersion    string         `json:"cli_version,omitempty"`
+	Files         []ManifestFile `json:"files"`
+
+	// Redactions is the number of values replaced by retention.Redacted
+	Redactions int `json:"redactions"`
+
+	// Errors are the sections that could not be collected, keyed by the file they would have filled
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// ManifestFile is an archived file and its checksum
+type ManifestFile struct {
+	Name   string `json:"name"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// File is an entry of a bundle
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Bundle is a tenant's collected, redacted support data
+type Bundle struct {
+	Manifest Manifest
+	Files    []File
+}
+
+// Collect gathers the tenant's support data from src and the log files opts names, redacting
+// secrets as it goes. Only the tenant record is required: a section that cannot be read, such as
+// uptime on a server without uptime checks, is recorded in the manifest's errors instead.
+func Collect(ctx context.Context, src Source, tenantID string, opts Options, now time.Time) (*Bundle, error) {
+	if opts.HistoryLimit <= 0 {
+		opts.HistoryLimit = DefaultHistoryLimit
+	}
+	if opts.Executions <= 0 {
+		opts.Executions = DefaultExecutions
+	}
+	opts.Executions = min(opts.Executions, maxExecutions)
+	if opts.LogLines <= 0 {
+		opts.LogLines = DefaultLogLines
+	}
+
+	t, err := src.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant: %w", err)
+	}
+
+	b := &Bundle{Manifest: Manifest{
+		FormatVersion: FormatVersion,
+		TenantID:      t.ID,
+		TenantName:    t.Name,
+		CreatedAt:     now.UTC(),
+		CLIVersion:    opts.CLIVersion,
+	}}
+	if err := b.addJSON("tenant.json", t); err != nil {
+		return nil, err
+	}
+
+	sections := []struct {
+		name string
+		get  func() (interface{}, error)
+	}{
+		{"history.json", func() (interface{}, error) { return collectHistory(ctx, src, t.ID, opts.HistoryLimit) }},
+		{"executions.json", func() (interface{}, error) {
+			return src.GetTenantTimeline(ctx, t.ID, []string{models.TimelineTypeWorkflowExecution, models.TimelineTypeComputeExecution}, opts.Executions)
+		}},
+		{"provider/resolution.json", func() (interface{}, error) { return src.GetTenantResolution(ctx, t.ID) }},
+		{"provider/effective-config.json", func() (interface{}, error) { return src.GetTenantEffectiveConfig(ctx, t.ID) }},
+		{"provider/uptime.json", func() (interface{}, error) { return src.GetTenantUptime(ctx, t.ID) }},
+		{"version.json", func() (interface{}, error) { return src.GetVersion(ctx) }},
+	}
+	for _, section := range sections {
+		value, err := section.get()
+		if err != nil {
+			b.recordError(section.name, err)
+			continue
+		}
+		if err := b.addJSON(section.name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range opts.LogFiles {
+		name := "logs/" + filepath.Base(path)
+		lines, err := matchingLines(path, []string{t.ID, `"` + t.Name + `"`}, opts.LogLines)
+		if err != nil {
+			b.recordError(name, err)
+			continue
+		}
+		var buf bytes.Buffer
+		for _, line := range lines {
+			redacted, n := redact.Line(line)
+			b.Manifest.Redactions += n
+			buf.WriteString(redacted)
+			buf.WriteByte('\n')
+		}
+		b.add(name, buf.Bytes())
+	}
+	return b, nil
+}
+
+// collectHistory reads up to limit of the tenant's most recent state transitions
+func collectHistory(ctx context.Context, src Source, tenantID string, limit int) (*models.TenantHistoryResponse, error) {
+	history := &models.TenantHistoryResponse{Limit: limit}
+	cursor := ""
+	for len(history.Transitions) < limit {
+		page, err := src.GetTenantHistory(ctx, tenantID, min(historyPageSize, limit-len(history.Transitions)), cursor)
+		if err != nil {
+			return nil, err
+		}
+		history.Transitions = append(history.Transitions, page.Transitions...)
+		history.NextCursor = page.NextCursor
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	return history, nil
+}
+
+// matchingLines returns the last max lines of the file at path that contain any of patterns
+func matchingLines(path string, patterns []string, max int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		for _, pattern := range patterns {
+			if strings.Contains(line, pattern) {
+				lines = append(lines, line)
+				break
+			}
+		}
+		if len(lines) > 2*max {
+			lines = append(lines[:0], lines[len(lines)-max:]...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) > max {
+		lines = lines[len(lines)-max:]
+	}
+	return lines, nil
+}
+
+// addJSON redacts value and adds it as an indented JSON file
+func (b *Bundle) addJSON(name string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("decode %s: %w", name, err)
+	}
+	b.Manifest.Redactions += redact.JSON(doc)
+	data, err = encodeJSON(doc)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+	b.add(name, data)
+	return nil
+}
+
+// encodeJSON encodes value as indented JSON without escaping HTML, so redacted values read as
+// retention.Redacted rather than \u003credacted\u003e
+func encodeJSON(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (b *Bundle) add(name string, data []byte) {
+	sum := sha256.Sum256(data)
+	b.Files = append(b.Files, File{Name: name, Data: data})
+	b.Manifest.Files = append(b.Manifest.Files, ManifestFile{Name: name, Size: len(data), SHA256: hex.EncodeToString(sum[:])})
+}
+
+func (b *Bundle) recordError(name string, err error) {
+	if b.Manifest.Errors == nil {
+		b.Manifest.Errors = make(map[string]string)
+	}
+	redacted, n := redact.Line(err.Error())
+	b.Manifest.Redactions += n
+	b.Manifest.Errors[name] = redacted
+}
+
+// Archive returns the bundle as a gzipped tar archive, the manifest first
+func (b *Bundle) Archive() ([]byte, error) {
+	manifest, err := encodeJSON(b.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("encode manifest: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	files := append([]File{{Name: ManifestName, Data: manifest}}, b.Files...)
+	for _, file := range files {
+		header := &tar.Header{
+			Name:    file.Name,
+			Mode:    0o600,
+			Size:    int64(len(file.Data)),
+			ModTime: b.Manifest.CreatedAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("write %s: %w", file.Name, err)
+		}
+		if _, err := tw.Write(file.Data); err != nil {
+			return nil, fmt.Errorf("write %s: %w", file.Name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("close archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("compress archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadArchive reads the files of an archive written by Archive, checking each against the manifest
+func ReadArchive(archive []byte) (*Bundle, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("decompress archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	b := &Bundle{}
+	contents := make(map[string][]byte)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", header.Name, err)
+		}
+		if header.Name == ManifestName {
+			if err := json.Unmarshal(data, &b.Manifest); err != nil {
+				return nil, fmt.Errorf("decode manifest: %w", err)
+			}
+			continue
+		}
+		contents[header.Name] = data
+		b.Files = append(b.Files, File{Name: header.Name, Data: data})
+	}
+	if b.Manifest.FormatVersion == 0 {
+		return nil, fmt.Errorf("archive has no manifest")
+	}
+
+	for _, file := range b.Manifest.Files {
+		data, ok := contents[file.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s is listed in the manifest but missing", file.Name)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != file.SHA256 {
+			return nil, fmt.Errorf("%s does not match its checksum", file.Name)
+		}
+		delete(contents, file.Name)
+	}
+	if len(contents) > 0 {
+		extra := make([]string, 0, len(contents))
+		for name := range contents {
+			extra = append(extra, name)
+		}
+		sort.Strings(extra)
+		return nil, fmt.Errorf("files not listed in the manifest: %s", strings.Join(extra, ", "))
+	}
+	return b, nil
+}
@@ -0,0 +1,253 @@
+package supportbundle_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/retention"
+	"github.com/jaxxstorm/landlord/internal/supportbundle"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+const tenantID = "8d3c4a1e-0f6b-4a55-9d7e-2b1c9f0e6a11"
+
+type fakeSource struct {
+	transitions []tenant.StateTransition
+	cursors     []string
+}
+
+func (f *fakeSource) GetTenant(_ context.Context, id string) (*models.TenantResponse, error) {
+	if id != tenantID && id != "acme" {
+		return nil, fmt.Errorf("tenant not found")
+	}
+	return &models.TenantResponse{
+		ID:   tenantID,
+		Name: "acme",
+		ComputeConfig: map[string]interface{}{
+			"image": "nginx:latest",
+			"env": map[string]interface{}{
+				"DATABASE_PASSWORD": "hunter2",
+				"DATABASE_URL":      "postgres://app:s3cret@db:5432/app",
+				"LOG_LEVEL":         "debug",
+			},
+		},
+	}, nil
+}
+
+func (f *fakeSource) GetTenantHistory(_ context.Context, _ string, limit int, cursor string) (*models.TenantHistoryResponse, error) {
+	f.cursors = append(f.cursors, cursor)
+	start := 0
+	if cursor != "" {
+		fmt.Sscanf(cursor, "%d", &start)
+	}
+	end := min(start+limit, len(f.transitions))
+	page := &models.TenantHistoryResponse{Transitions: f.transitions[start:end], Limit: limit}
+	if end < len(f.transitions) {
+		page.NextCursor = fmt.Sprint(end)
+	}
+	return page, nil
+}
+
+func (f *fakeSource) GetTenantTimeline(_ context.Context, _ string, types []string, limit int) (*models.TenantTimelineResponse, error) {
+	return &models.TenantTimelineResponse{
+		Entries: []models.TimelineEntry{{Type: types[0], ID: "exec-1", Summary: "provision succeeded"}},
+		Total:   1,
+		Limit:   limit,
+	}, nil
+}
+
+func (f *fakeSource) GetTenantResolution(context.Context, string) (*models.TenantResolutionResponse, error) {
+	return &models.TenantResolutionResponse{TenantID: tenantID, TenantName: "acme"}, nil
+}
+
+func (f *fakeSource) GetTenantEffectiveConfig(context.Context, string) (*models.TenantEffectiveConfigResponse, error) {
+	return &models.TenantEffectiveConfigResponse{TenantID: tenantID, TenantName: "acme", ComputeProvider: "docker"}, nil
+}
+
+func (f *fakeSource) GetTenantUptime(context.Context, string) (*models.TenantUptimeResponse, error) {
+	return nil, errors.New("uptime checks are not enabled")
+}
+
+func (f *fakeSource) GetVersion(context.Context) (*models.VersionResponse, error) {
+	return &models.VersionResponse{WorkflowVersion: "v1"}, nil
+}
+
+func newSource(transitions int) *fakeSource {
+	src := &fakeSource{}
+	for i := 0; i < transitions; i++ {
+		src.transitions = append(src.transitions, tenant.StateTransition{ToStatus: tenant.StatusReady, Reason: fmt.Sprint(i)})
+	}
+	return src
+}
+
+func fileData(t *testing.T, b *supportbundle.Bundle, name string) string {
+	t.Helper()
+	for _, file := range b.Files {
+		if file.Name == name {
+			return string(file.Data)
+		}
+	}
+	t.Fatalf("bundle has no %s", name)
+	return ""
+}
+
+func TestCollect(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "landlord.log")
+	log := strings.Join([]string{
+		`{"msg":"provisioning","tenant_id":"` + tenantID + `"}`,
+		`{"msg":"other tenant","tenant_id":"b0b0b0b0-0000-0000-0000-000000000000"}`,
+		`{"msg":"calling worker","tenant":"acme","authorization":"Bearer abcdefghijklmnop"}`,
+		`{"msg":"connecting","dsn":"postgres://landlord:topsecret@db/landlord","tenant_id":"` + tenantID + `"}`,
+	}, "\n")
+	require.NoError(t, os.WriteFile(logPath, []byte(log), 0o600))
+
+	src := newSource(7)
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	b, err := supportbundle.Collect(context.Background(), src, "acme", supportbundle.Options{
+		HistoryLimit: 5,
+		LogFiles:     []string{logPath},
+		CLIVersion:   "v1.2.3",
+	}, now)
+	require.NoError(t, err)
+
+	require.Equal(t, tenantID, b.Manifest.TenantID)
+	require.Equal(t, now, b.Manifest.CreatedAt)
+	require.Equal(t, "v1.2.3", b.Manifest.CLIVersion)
+	require.Contains(t, b.Manifest.Errors, "provider/uptime.json")
+
+	var names []string
+	for _, file := range b.Manifest.Files {
+		names = append(names, file.Name)
+	}
+	require.Equal(t, []string{
+		"tenant.json",
+		"history.json",
+		"executions.json",
+		"provider/resolution.json",
+		"provider/effective-config.json",
+		"version.json",
+		"logs/landlord.log",
+	}, names)
+
+	tenantJSON := fileData(t, b, "tenant.json")
+	require.NotContains(t, tenantJSON, "hunter2")
+	require.NotContains(t, tenantJSON, "s3cret")
+	require.Contains(t, tenantJSON, `"DATABASE_PASSWORD": "`+retention.Redacted+`"`)
+	require.Contains(t, tenantJSON, `"LOG_LEVEL": "debug"`)
+
+	history := fileData(t, b, "history.json")
+	require.Equal(t, 5, strings.Count(history, `"to_status"`))
+	require.Contains(t, history, `"next_cursor": "5"`)
+
+	logs := fileData(t, b, "logs/landlord.log")
+	require.Equal(t, 3, strings.Count(logs, "\n"))
+	require.NotContains(t, logs, "b0b0b0b0")
+	require.NotContains(t, logs, "abcdefghijklmnop")
+	require.NotContains(t, logs, "topsecret")
+
+	require.Equal(t, 4, b.Manifest.Redactions)
+}
+
+func TestCollectPagesHistory(t *testing.T) {
+	src := newSource(1200)
+	b, err := supportbundle.Collect(context.Background(), src, tenantID, supportbundle.Options{HistoryLimit: 1100}, time.Now())
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"", "500", "1000"}, src.cursors)
+	require.Equal(t, 1100, strings.Count(fileData(t, b, "history.json"), `"to_status"`))
+}
+
+func TestCollectRequiresTenant(t *testing.T) {
+	_, err := supportbundle.Collect(context.Background(), newSource(0), "missing", supportbundle.Options{}, time.Now())
+	require.Error(t, err)
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	b, err := supportbundle.Collect(context.Background(), newSource(2), tenantID, supportbundle.Options{}, time.Now())
+	require.NoError(t, err)
+	archive, err := b.Archive()
+	require.NoError(t, err)
+
+	read, err := supportbundle.ReadArchive(archive)
+	require.NoError(t, err)
+	require.Equal(t, b.Manifest.Files, read.Manifest.Files)
+	require.Equal(t, b.Files, read.Files)
+
+	b.Files[0].Data = append(b.Files[0].Data, ' ')
+	tampered, err := b.Archive()
+	require.NoError(t, err)
+	_, err = supportbundle.ReadArchive(tampered)
+	require.ErrorContains(t, err, "tenant.json does not match its checksum")
+}
+
+func TestSealOpen(t *testing.T) {
+	recipient, err := supportbundle.GenerateRecipientKey()
+	require.NoError(t, err)
+	signer, err := supportbundle.GenerateSigningKey()
+	require.NoError(t, err)
+	other, err := supportbundle.GenerateSigningKey()
+	require.NoError(t, err)
+
+	archive := []byte("archive contents")
+	sealed, err := supportbundle.Seal(archive, recipient.PublicKey(), signer)
+	require.NoError(t, err)
+	require.NotContains(t, string(sealed), "archive contents")
+
+	opened, err := supportbundle.Open(sealed, recipient, signer.Public().(ed25519.PublicKey))
+	require.NoError(t, err)
+	require.Equal(t, archive, opened)
+
+	_, err = supportbundle.Open(sealed, recipient, other.Public().(ed25519.PublicKey))
+	require.ErrorIs(t, err, supportbundle.ErrUntrustedSigner)
+
+	wrong, err := supportbundle.GenerateRecipientKey()
+	require.NoError(t, err)
+	_, err = supportbundle.Open(sealed, wrong, signer.Public().(ed25519.PublicKey))
+	require.ErrorContains(t, err, "decrypt bundle")
+
+	tampered := []byte(strings.Replace(string(sealed), `"ciphertext":"`, `"ciphertext":"AAAA`, 1))
+	_, err = supportbundle.Open(tampered, recipient, signer.Public().(ed25519.PublicKey))
+	require.ErrorContains(t, err, "signature is invalid")
+}
+
+func TestKeysRoundTrip(t *testing.T) {
+	recipient, err := supportbundle.GenerateRecipientKey()
+	require.NoError(t, err)
+	signer, err := supportbundle.GenerateSigningKey()
+	require.NoError(t, err)
+
+	data, err := supportbundle.MarshalPrivateKey(recipient)
+	require.NoError(t, err)
+	parsedRecipient, err := supportbundle.ParseRecipientPrivateKey(data)
+	require.NoError(t, err)
+	require.True(t, recipient.Equal(parsedRecipient))
+
+	data, err = supportbundle.MarshalPublicKey(recipient.PublicKey())
+	require.NoError(t, err)
+	parsedPublic, err := supportbundle.ParseRecipientPublicKey(data)
+	require.NoError(t, err)
+	require.True(t, recipient.PublicKey().Equal(parsedPublic))
+
+	data, err = supportbundle.MarshalPrivateKey(signer)
+	require.NoError(t, err)
+	parsedSigner, err := supportbundle.ParseSigningPrivateKey(data)
+	require.NoError(t, err)
+	require.True(t, signer.Equal(parsedSigner))
+
+	data, err = supportbundle.MarshalPublicKey(signer.Public())
+	require.NoError(t, err)
+	_, err = supportbundle.ParseRecipientPublicKey(data)
+	require.ErrorContains(t, err, "not an X25519 public key")
+	_, err = supportbundle.ParseSigningPublicKey(data)
+	require.NoError(t, err)
+}
@@ -0,0 +1,51 @@
+package tenant
+
+import "time"
+
+// Workflow actions, as reported on tenants. Archival runs the delete workflow, but is reported as
+// archive.
+const (
+	ActionProvision = "provision"
+	ActionUpdate    = "update"
+	ActionMigrate   = "migrate"
+	ActionDelete    = "delete"
+	ActionArchive   = "archive"
+
+	// ActionFinalize is waiting for a removed tenant's finalizers
+	ActionFinalize = "finalize"
+)
+
+// ActionRecord is a workflow the controller started for a tenant
+type ActionRecord struct {
+	// Action is what the workflow does, such as provision or archive
+	Action string `json:"action"`
+
+	// Source is what started it: "controller" for a reconcile, "controller:outbox" for a queued
+	// trigger, or "controller:config-change" for a restart after the config changed
+	Source string `json:"source"`
+
+	// ExecutionID is the workflow execution
+	ExecutionID string `json:"execution_id,omitempty"`
+
+	// At is when the workflow was started
+	At time.Time `json:"at"`
+}
+
+// ActionForStatus returns the workflow action a tenant in status runs, or "" for a status that
+// runs none
+func ActionForStatus(status Status) string {
+	switch status {
+	case StatusRequested, StatusPlanning, StatusProvisioning:
+		return ActionProvision
+	case StatusUpdating:
+		return ActionUpdate
+	case StatusMigrating:
+		return ActionMigrate
+	case StatusDeleting:
+		return ActionDelete
+	case StatusArchiving:
+		return ActionArchive
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,25 @@
+package tenant
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionForStatus(t *testing.T) {
+	cases := map[Status]string{
+		StatusRequested:    ActionProvision,
+		StatusPlanning:     ActionProvision,
+		StatusProvisioning: ActionProvision,
+		StatusUpdating:     ActionUpdate,
+		StatusMigrating:    ActionMigrate,
+		StatusDeleting:     ActionDelete,
+		StatusArchiving:    ActionArchive,
+		StatusReady:        "",
+		StatusFailed:       "",
+		StatusArchived:     "",
+	}
+	for status, action := range cases {
+		require.Equal(t, action, ActionForStatus(status), status)
+	}
+}
@@ -0,0 +1,234 @@
+// Package cache serves tenants read by ID or name from memory for a short time, so hot read
+// paths such as the API's tenant lookups and the worker's compute resolution do not query the
+// database on every call.
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+const (
+	minListenBackoff = time.Second
+	maxListenBackoff = 30 * time.Second
+)
+
+// ChangeSource reports tenants changed by any process, such as the postgres repository's
+// LISTEN/NOTIFY channel
+type ChangeSource interface {
+	// ListenTenantChanges calls changed with the ID of every updated or deleted tenant
+	// until ctx is cancelled or the connection fails
+	ListenTenantChanges(ctx context.Context, changed func(id uuid.UUID)) error
+}
+
+// Cache is a tenant.Repository that serves GetTenantByID and GetTenantByName from memory until
+// the entry's TTL passes or the tenant changes. Writes made through the cache drop the tenant
+// before they return; writes made by other processes are seen through Listen, or after the TTL.
+type Cache struct {
+	tenant.Repository
+
+	ttl        time.Duration
+	maxEntries int
+	logger     *zap.Logger
+
+	mu     sync.Mutex
+	byID   map[uuid.UUID]*entry
+	byName map[string]uuid.UUID
+	// generation changes on every invalidation, so a read that raced with one is not cached
+	generation uint64
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+type entry struct {
+	tenant    *tenant.Tenant
+	expiresAt time.Time
+}
+
+// New wraps repo with a read-through cache
+func New(repo tenant.Repository, cfg config.TenantCacheConfig, logger *zap.Logger) *Cache {
+	return &Cache{
+		Repository: repo,
+		ttl:        cfg.TTL,
+		maxEntries: cfg.MaxEntries,
+		logger:     logger.With(zap.String("component", "tenant-cache")),
+		byID:       make(map[uuid.UUID]*entry),
+		byName:     make(map[string]uuid.UUID),
+	}
+}
+
+// GetTenantByID returns a copy of the cached tenant, reading it from the repository on a miss
+func (c *Cache) GetTenantByID(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+	c.mu.Lock()
+	if t := c.lookup(id); t != nil {
+		c.mu.Unlock()
+		c.hits.Add(1)
+		return t, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	c.misses.Add(1)
+	t, err := c.Repository.GetTenantByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	c.store(t, generation)
+	return t, nil
+}
+
+// GetTenantByName returns a copy of the cached tenant, reading it from the repository on a miss
+func (c *Cache) GetTenantByName(ctx context.Context, name string) (*tenant.Tenant, error) {
+	c.mu.Lock()
+	if id, ok := c.byName[name]; ok {
+		if t := c.lookup(id); t != nil {
+			c.mu.Unlock()
+			c.hits.Add(1)
+			return t, nil
+		}
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	c.misses.Add(1)
+	t, err := c.Repository.GetTenantByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	c.store(t, generation)
+	return t, nil
+}
+
+// UpdateTenant writes the tenant and drops it from the cache, whether or not the write succeeded
+func (c *Cache) UpdateTenant(ctx context.Context, t *tenant.Tenant) error {
+	defer c.Invalidate(t.ID)
+	return c.Repository.UpdateTenant(ctx, t)
+}
+
+// FenceWorkflowTrigger runs the fenced trigger and drops the tenant from the cache
+func (c *Cache) FenceWorkflowTrigger(ctx context.Context, t *tenant.Tenant, trigger func(ctx context.Context, t *tenant.Tenant) error) error {
+	defer c.Invalidate(t.ID)
+	return c.Repository.FenceWorkflowTrigger(ctx, t, trigger)
+}
+
+// DeleteTenant deletes the tenant and drops it from the cache
+func (c *Cache) DeleteTenant(ctx context.Context, id uuid.UUID) error {
+	defer c.Invalidate(id)
+	return c.Repository.DeleteTenant(ctx, id)
+}
+
+// Invalidate drops the tenant from the cache
+func (c *Cache) Invalidate(id uuid.UUID) {
+	c.invalidations.Add(1)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.remove(id)
+}
+
+// Flush drops every cached tenant
+func (c *Cache) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.byID = make(map[uuid.UUID]*entry)
+	c.byName = make(map[string]uuid.UUID)
+}
+
+// Listen drops tenants from the cache as source reports them changed, until ctx is cancelled.
+// The cache is flushed each time the listener connects, since changes made while it was
+// disconnected were missed; a failed listener is reconnected with backoff.
+func (c *Cache) Listen(ctx context.Context, source ChangeSource) {
+	backoff := minListenBackoff
+	for {
+		c.Flush()
+		started := time.Now()
+		err := source.ListenTenantChanges(ctx, c.Invalidate)
+		if ctx.Err() != nil {
+			return
+		}
+		// A listener that stayed connected for a while starts over from the shortest backoff
+		if time.Since(started) > maxListenBackoff {
+			backoff = minListenBackoff
+		}
+		c.logger.Warn("tenant change listener stopped, reconnecting", zap.Error(err), zap.Duration("backoff", backoff))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxListenBackoff)
+	}
+}
+
+// lookup returns a copy of the unexpired entry for id; c.mu must be held
+func (c *Cache) lookup(id uuid.UUID) *tenant.Tenant {
+	e, ok := c.byID[id]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(e.expiresAt) {
+		c.remove(id)
+		return nil
+	}
+	return e.tenant.Clone()
+}
+
+// store caches a copy of t unless the cache was invalidated since generation was read
+func (c *Cache) store(t *tenant.Tenant, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation != generation {
+		return
+	}
+	if _, ok := c.byID[t.ID]; !ok && len(c.byID) >= c.maxEntries {
+		c.evict()
+	}
+	c.remove(t.ID)
+	c.byID[t.ID] = &entry{tenant: t.Clone(), expiresAt: time.Now().Add(c.ttl)}
+	c.byName[t.Name] = t.ID
+}
+
+// evict makes room for one entry by dropping the expired entries, or the one closest to expiry
+// when none have expired; c.mu must be held
+func (c *Cache) evict() {
+	now := time.Now()
+	var oldest uuid.UUID
+	var oldestExpiry time.Time
+	for id, e := range c.byID {
+		if now.After(e.expiresAt) {
+			c.remove(id)
+			continue
+		}
+		if oldestExpiry.IsZero() || e.expiresAt.Before(oldestExpiry) {
+			oldest, oldestExpiry = id, e.expiresAt
+		}
+	}
+	if len(c.byID) >= c.maxEntries {
+		c.remove(oldest)
+	}
+}
+
+// remove drops the entry for id and its name; c.mu must be held
+func (c *Cache) remove(id uuid.UUID) {
+	e, ok := c.byID[id]
+	if !ok {
+		return
+	}
+	delete(c.byID, id)
+	if c.byName[e.tenant.Name] == id {
+		delete(c.byName, e.tenant.Name)
+	}
+}
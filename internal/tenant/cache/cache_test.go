@@ -0,0 +1,209 @@
+package cache_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/tenant/cache"
+	"github.com/jaxxstorm/landlord/internal/tenant/memory"
+)
+
+func newCache(t *testing.T, ttl time.Duration, maxEntries int) (*cache.Cache, *memory.Repository) {
+	t.Helper()
+	repo := memory.New()
+	return cache.New(repo, config.TenantCacheConfig{Enabled: true, TTL: ttl, MaxEntries: maxEntries}, zap.NewNop()), repo
+}
+
+func createTenant(t *testing.T, repo tenant.Repository, name string) *tenant.Tenant {
+	t.Helper()
+	tn := &tenant.Tenant{
+		Name:          name,
+		Status:        tenant.StatusReady,
+		DesiredConfig: map[string]interface{}{"image": "nginx:latest"},
+	}
+	require.NoError(t, repo.CreateTenant(context.Background(), tn))
+	return tn
+}
+
+func TestCacheServesRepeatedReads(t *testing.T) {
+	ctx := context.Background()
+	c, repo := newCache(t, time.Minute, 10)
+	tn := createTenant(t, repo, "alpha")
+
+	first, err := c.GetTenantByID(ctx, tn.ID)
+	require.NoError(t, err)
+	byName, err := c.GetTenantByName(ctx, "alpha")
+	require.NoError(t, err)
+	assert.Equal(t, tn.ID, byName.ID)
+
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, 1, stats.Entries)
+
+	// Callers get their own copy
+	first.Status = tenant.StatusFailed
+	again, err := c.GetTenantByID(ctx, tn.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tenant.StatusReady, again.Status)
+}
+
+func TestCacheDoesNotCacheMisses(t *testing.T) {
+	ctx := context.Background()
+	c, repo := newCache(t, time.Minute, 10)
+
+	_, err := c.GetTenantByName(ctx, "alpha")
+	assert.True(t, errors.Is(err, tenant.ErrTenantNotFound))
+
+	createTenant(t, repo, "alpha")
+	got, err := c.GetTenantByName(ctx, "alpha")
+	require.NoError(t, err)
+	assert.Equal(t, "alpha", got.Name)
+}
+
+func TestCacheInvalidatesOnWrite(t *testing.T) {
+	ctx := context.Background()
+	c, repo := newCache(t, time.Minute, 10)
+	tn := createTenant(t, repo, "alpha")
+
+	got, err := c.GetTenantByID(ctx, tn.ID)
+	require.NoError(t, err)
+	got.Status = tenant.StatusUpdating
+	require.NoError(t, c.UpdateTenant(ctx, got))
+
+	fresh, err := c.GetTenantByName(ctx, "alpha")
+	require.NoError(t, err)
+	assert.Equal(t, tenant.StatusUpdating, fresh.Status)
+	assert.Equal(t, got.Version, fresh.Version)
+
+	require.NoError(t, c.DeleteTenant(ctx, tn.ID))
+	_, err = c.GetTenantByID(ctx, tn.ID)
+	assert.True(t, errors.Is(err, tenant.ErrTenantNotFound))
+	assert.Equal(t, uint64(2), c.Stats().Invalidations)
+}
+
+func TestCacheExpiresEntries(t *testing.T) {
+	ctx := context.Background()
+	c, repo := newCache(t, 20*time.Millisecond, 10)
+	tn := createTenant(t, repo, "alpha")
+
+	_, err := c.GetTenantByID(ctx, tn.ID)
+	require.NoError(t, err)
+
+	// Written behind the cache's back, as another process would
+	stored, err := repo.GetTenantByID(ctx, tn.ID)
+	require.NoError(t, err)
+	stored.Status = tenant.StatusUpdating
+	require.NoError(t, repo.UpdateTenant(ctx, stored))
+
+	cached, err := c.GetTenantByID(ctx, tn.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tenant.StatusReady, cached.Status)
+
+	time.Sleep(30 * time.Millisecond)
+	fresh, err := c.GetTenantByID(ctx, tn.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tenant.StatusUpdating, fresh.Status)
+}
+
+func TestCacheBoundsEntries(t *testing.T) {
+	ctx := context.Background()
+	c, repo := newCache(t, time.Minute, 2)
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		createTenant(t, repo, name)
+		_, err := c.GetTenantByName(ctx, name)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, c.Stats().Entries)
+
+	// The first entry expires first, so it was evicted
+	_, err := c.GetTenantByName(ctx, "gamma")
+	require.NoError(t, err)
+	_, err = c.GetTenantByName(ctx, "alpha")
+	require.NoError(t, err)
+	stats := c.Stats()
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(4), stats.Misses)
+}
+
+// changeSource reports the tenant IDs sent on its channel, failing the first connection
+type changeSource struct {
+	changes  chan uuid.UUID
+	attempts int
+}
+
+func (s *changeSource) ListenTenantChanges(ctx context.Context, changed func(id uuid.UUID)) error {
+	s.attempts++
+	if s.attempts == 1 {
+		return errors.New("connection refused")
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case id := <-s.changes:
+			changed(id)
+		}
+	}
+}
+
+func TestCacheListenInvalidatesChangedTenants(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c, repo := newCache(t, time.Minute, 10)
+	tn := createTenant(t, repo, "alpha")
+
+	source := &changeSource{changes: make(chan uuid.UUID)}
+	done := make(chan struct{})
+	go func() {
+		c.Listen(ctx, source)
+		close(done)
+	}()
+
+	// The send blocks until the listener has reconnected after its first failure
+	select {
+	case source.changes <- uuid.New():
+	case <-time.After(5 * time.Second):
+		t.Fatal("listener did not reconnect")
+	}
+
+	_, err := c.GetTenantByID(ctx, tn.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, c.Stats().Entries)
+
+	source.changes <- tn.ID
+	assert.Eventually(t, func() bool { return c.Stats().Entries == 0 }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Listen did not return after cancel")
+	}
+}
+
+func TestCacheWriteMetrics(t *testing.T) {
+	ctx := context.Background()
+	c, repo := newCache(t, time.Minute, 10)
+	tn := createTenant(t, repo, "alpha")
+	_, _ = c.GetTenantByID(ctx, tn.ID)
+	_, _ = c.GetTenantByID(ctx, tn.ID)
+
+	var buf bytes.Buffer
+	require.NoError(t, c.WriteMetrics(&buf))
+	out := buf.String()
+	assert.Contains(t, out, "# TYPE landlord_tenant_cache_hits_total counter\nlandlord_tenant_cache_hits_total 1\n")
+	assert.Contains(t, out, "landlord_tenant_cache_misses_total 1\n")
+	assert.Contains(t, out, "landlord_tenant_cache_invalidations_total 0\n")
+	assert.Contains(t, out, "# TYPE landlord_tenant_cache_entries gauge\nlandlord_tenant_cache_entries 1\n")
+}
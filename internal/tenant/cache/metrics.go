@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Stats counts the cache's lookups
+type Stats struct {
+	Hits          uint64
+	Misses        uint64
+	Invalidations uint64
+	Entries       int
+}
+
+// Stats returns the cache's counters and current size
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	entries := len(c.byID)
+	c.mu.Unlock()
+	return Stats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+		Entries:       entries,
+	}
+}
+
+// WriteMetrics writes the cache's hits, misses, invalidations and size in the Prometheus text exposition format
+func (c *Cache) WriteMetrics(w io.Writer) error {
+	stats := c.Stats()
+	out := bufio.NewWriter(w)
+	for _, m := range []struct {
+		name, help, kind string
+		value            float64
+	}{
+		{"landlord_tenant_cache_hits_total", "Tenant reads served from the cache", "counter", float64(stats.Hits)},
+		{"landlord_tenant_cache_misses_total", "Tenant reads that went to the database", "counter", float64(stats.Misses)},
+		{"landlord_tenant_cache_invalidations_total", "Tenants dropped from the cache because they changed", "counter", float64(stats.Invalidations)},
+		{"landlord_tenant_cache_entries", "Tenants currently cached", "gauge", float64(stats.Entries)},
+	} {
+		fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n%s %g\n", m.name, m.help, m.name, m.kind, m.name, m.value)
+	}
+	return out.Flush()
+}
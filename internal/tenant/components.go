@@ -0,0 +1,156 @@
+package tenant
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+const (
+	// ComponentsConfigKey is the compute_config key holding a tenant's components, keyed by name
+	ComponentsConfigKey = "components"
+
+	// DefaultComponent is the component a compute_config without components describes. Its compute
+	// keeps the tenant's own compute identifier, so moving a v1 spec under components.app does not
+	// recreate it.
+	DefaultComponent = "app"
+)
+
+var componentNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]{0,31}$`)
+
+// tenantLevelKeys are compute_config keys that describe the tenant as a whole, so components do not
+// inherit them: resources and hooks are provisioned and run once per tenant
+var tenantLevelKeys = map[string]bool{
+	ComponentsConfigKey: true,
+	"resources":         true,
+	"hooks":             true,
+}
+
+// Component is one independently deployed part of a tenant, such as its app, a worker or a cron job
+type Component struct {
+	Name string
+
+	// Config is the compute config of the component: the tenant's compute_config overlaid with the
+	// component's own keys
+	Config map[string]interface{}
+}
+
+// HasComponents reports whether a compute_config declares components (a v2 spec)
+func HasComponents(computeConfig map[string]interface{}) bool {
+	_, ok := computeConfig[ComponentsConfigKey]
+	return ok
+}
+
+// ParseComponents returns a compute_config's components sorted by name. A compute_config without
+// components (a v1 spec) is a single DefaultComponent with the whole config.
+//
+// Each component inherits the tenant's keys, except resources and hooks, and overrides them with its
+// own: objects such as env are merged one level deep, and null removes an inherited key.
+func ParseComponents(computeConfig map[string]interface{}) ([]Component, error) {
+	raw, ok := computeConfig[ComponentsConfigKey]
+	if !ok {
+		return []Component{{Name: DefaultComponent, Config: computeConfig}}, nil
+	}
+	declared, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s must be an object keyed by component name", ComponentsConfigKey)
+	}
+	if len(declared) == 0 {
+		return nil, fmt.Errorf("%s must declare at least one component", ComponentsConfigKey)
+	}
+
+	base := make(map[string]interface{}, len(computeConfig))
+	for key, value := range computeConfig {
+		if !tenantLevelKeys[key] {
+			base[key] = value
+		}
+	}
+
+	components := make([]Component, 0, len(declared))
+	for name, value := range declared {
+		if !componentNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("%s: invalid component name %q: must be lowercase alphanumeric or '-', start with a letter and be at most 32 characters", ComponentsConfigKey, name)
+		}
+		overrides, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s.%s must be an object", ComponentsConfigKey, name)
+		}
+		for key := range overrides {
+			if tenantLevelKeys[key] {
+				return nil, fmt.Errorf("%s.%s: %s is declared once per tenant, not per component", ComponentsConfigKey, name, key)
+			}
+		}
+		components = append(components, Component{Name: name, Config: overlay(base, overrides)})
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].Name < components[j].Name })
+	return components, nil
+}
+
+// ComponentNames returns the names of a compute_config's components, sorted
+func ComponentNames(computeConfig map[string]interface{}) ([]string, error) {
+	components, err := ParseComponents(computeConfig)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(components))
+	for i, component := range components {
+		names[i] = component.Name
+	}
+	return names, nil
+}
+
+// RecordedComponentNames returns the names of the components a workflow output recorded, sorted.
+// Outputs from before components record none, and their tenant ran as the default component.
+func RecordedComponentNames(observedConfig map[string]interface{}) []string {
+	recorded, _ := observedConfig[ComponentsConfigKey].(map[string]interface{})
+	if len(recorded) == 0 {
+		return []string{DefaultComponent}
+	}
+	names := make([]string, 0, len(recorded))
+	for name := range recorded {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ComponentComputeID returns the identifier a component's compute is provisioned under. The default
+// component uses the tenant's, so a v1 tenant keeps its compute when it adopts components.
+func ComponentComputeID(tenantID, component string) string {
+	if component == DefaultComponent {
+		return tenantID
+	}
+	return tenantID + "-" + component
+}
+
+// overlay returns base with overrides applied
+func overlay(base, overrides map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(overrides))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		inherited, inheritedIsObject := merged[key].(map[string]interface{})
+		override, overrideIsObject := value.(map[string]interface{})
+		if inheritedIsObject && overrideIsObject {
+			object := make(map[string]interface{}, len(inherited)+len(override))
+			for k, v := range inherited {
+				object[k] = v
+			}
+			for k, v := range override {
+				if v == nil {
+					delete(object, k)
+				} else {
+					object[k] = v
+				}
+			}
+			value = object
+		}
+		merged[key] = value
+	}
+	return merged
+}
@@ -0,0 +1,97 @@
+package tenant
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseComponents(t *testing.T) {
+	v1 := map[string]interface{}{"image": "nginx:1.27", "env": map[string]interface{}{"MODE": "web"}}
+	components, err := ParseComponents(v1)
+	if err != nil {
+		t.Fatalf("ParseComponents() error = %v", err)
+	}
+	if len(components) != 1 || components[0].Name != DefaultComponent || !reflect.DeepEqual(components[0].Config, v1) {
+		t.Fatalf("expected a v1 spec to be a single %s component, got %+v", DefaultComponent, components)
+	}
+
+	v2 := map[string]interface{}{
+		"image":           "acme/app:1.0",
+		"env":             map[string]interface{}{"MODE": "web", "LOG_LEVEL": "info"},
+		"readiness_probe": map[string]interface{}{"type": "http"},
+		"resources":       []interface{}{map[string]interface{}{"name": "db", "type": "postgres"}},
+		"components": map[string]interface{}{
+			"worker": map[string]interface{}{
+				"env":             map[string]interface{}{"MODE": "worker", "LOG_LEVEL": nil},
+				"readiness_probe": nil,
+			},
+			"app":  map[string]interface{}{},
+			"cron": map[string]interface{}{"image": "acme/cron:1.0"},
+		},
+	}
+	components, err = ParseComponents(v2)
+	if err != nil {
+		t.Fatalf("ParseComponents() error = %v", err)
+	}
+	names := make([]string, len(components))
+	for i, component := range components {
+		names[i] = component.Name
+		if _, ok := component.Config["resources"]; ok {
+			t.Fatalf("component %s inherited the tenant's resources", component.Name)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"app", "cron", "worker"}) {
+		t.Fatalf("expected components sorted by name, got %v", names)
+	}
+	if components[1].Config["image"] != "acme/cron:1.0" || components[0].Config["image"] != "acme/app:1.0" {
+		t.Fatalf("unexpected images %v, %v", components[0].Config["image"], components[1].Config["image"])
+	}
+	worker := components[2].Config
+	if !reflect.DeepEqual(worker["env"], map[string]interface{}{"MODE": "worker"}) {
+		t.Fatalf("expected env merged with overrides, got %v", worker["env"])
+	}
+	if _, ok := worker["readiness_probe"]; ok {
+		t.Fatal("expected null to remove the inherited readiness probe")
+	}
+	if _, ok := components[0].Config["readiness_probe"]; !ok {
+		t.Fatal("expected app to inherit the readiness probe")
+	}
+
+	for name, tc := range map[string]struct {
+		components interface{}
+		detail     string
+	}{
+		"not an object": {[]interface{}{"app"}, "object keyed by component name"},
+		"empty":         {map[string]interface{}{}, "at least one component"},
+		"invalid name":  {map[string]interface{}{"App_1": map[string]interface{}{}}, "invalid component name"},
+		"not a spec":    {map[string]interface{}{"app": "nginx"}, "components.app must be an object"},
+		"tenant-level":  {map[string]interface{}{"app": map[string]interface{}{"hooks": map[string]interface{}{}}}, "once per tenant"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseComponents(map[string]interface{}{"components": tc.components})
+			if err == nil || !strings.Contains(err.Error(), tc.detail) {
+				t.Fatalf("expected error mentioning %q, got %v", tc.detail, err)
+			}
+		})
+	}
+}
+
+func TestComponentComputeID(t *testing.T) {
+	if got := ComponentComputeID("tenant-1", DefaultComponent); got != "tenant-1" {
+		t.Fatalf("expected the default component to keep the tenant's id, got %s", got)
+	}
+	if got := ComponentComputeID("tenant-1", "worker"); got != "tenant-1-worker" {
+		t.Fatalf("unexpected compute id %s", got)
+	}
+}
+
+func TestRecordedComponentNames(t *testing.T) {
+	if got := RecordedComponentNames(map[string]interface{}{"image": "nginx:1.27"}); !reflect.DeepEqual(got, []string{DefaultComponent}) {
+		t.Fatalf("expected an output without components to record the default component, got %v", got)
+	}
+	observed := map[string]interface{}{"components": map[string]interface{}{"worker": map[string]interface{}{}, "app": map[string]interface{}{}}}
+	if got := RecordedComponentNames(observed); !reflect.DeepEqual(got, []string{"app", "worker"}) {
+		t.Fatalf("unexpected recorded components %v", got)
+	}
+}
@@ -0,0 +1,78 @@
+package tenant
+
+import "time"
+
+// ConditionStatus is whether a condition holds
+type ConditionStatus string
+
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+// Condition is an observation about a tenant that sits alongside its lifecycle status,
+// such as whether its images comply with policy. Modelled on Kubernetes status conditions:
+// a tenant has at most one condition of each type.
+type Condition struct {
+	// Type names the condition, such as "ImagePolicyCompliant"
+	Type string `json:"type"`
+
+	// Status is True, False or Unknown
+	Status ConditionStatus `json:"status"`
+
+	// Reason is a machine-readable CamelCase explanation of the status
+	Reason ReasonCode `json:"reason,omitempty"`
+
+	// Message is a human-readable explanation of the status
+	Message string `json:"message,omitempty"`
+
+	// LastTransitionTime is when Status last changed
+	LastTransitionTime time.Time `json:"last_transition_time"`
+}
+
+// Condition returns the tenant's condition of the given type, or nil when it has none
+func (t *Tenant) Condition(conditionType string) *Condition {
+	for i := range t.Conditions {
+		if t.Conditions[i].Type == conditionType {
+			return &t.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds c or replaces the tenant's condition of the same type.
+// LastTransitionTime is set to now only when the status changes.
+// Returns true when the tenant's conditions changed.
+func (t *Tenant) SetCondition(c Condition, now time.Time) bool {
+	existing := t.Condition(c.Type)
+	if existing == nil {
+		c.LastTransitionTime = now
+		t.Conditions = append(t.Conditions, c)
+		return true
+	}
+	if existing.Status == c.Status && existing.Reason == c.Reason && existing.Message == c.Message {
+		return false
+	}
+	c.LastTransitionTime = existing.LastTransitionTime
+	if existing.Status != c.Status {
+		c.LastTransitionTime = now
+	}
+	*existing = c
+	return true
+}
+
+// RemoveCondition removes the tenant's condition of the given type.
+// Returns true when there was one.
+func (t *Tenant) RemoveCondition(conditionType string) bool {
+	for i := range t.Conditions {
+		if t.Conditions[i].Type == conditionType {
+			t.Conditions = append(t.Conditions[:i], t.Conditions[i+1:]...)
+			if len(t.Conditions) == 0 {
+				t.Conditions = nil
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package tenant
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTenant_SetCondition(t *testing.T) {
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := first.Add(time.Hour)
+	tn := &Tenant{}
+
+	if !tn.SetCondition(Condition{Type: "Ready", Status: ConditionFalse, Reason: "Pending"}, first) {
+		t.Fatal("expected adding a condition to report a change")
+	}
+	if tn.SetCondition(Condition{Type: "Ready", Status: ConditionFalse, Reason: "Pending"}, later) {
+		t.Fatal("expected an identical condition to report no change")
+	}
+
+	// A new reason with the same status keeps the transition time
+	if !tn.SetCondition(Condition{Type: "Ready", Status: ConditionFalse, Reason: "Waiting"}, later) {
+		t.Fatal("expected a new reason to report a change")
+	}
+	if got := tn.Condition("Ready"); got.Reason != "Waiting" || !got.LastTransitionTime.Equal(first) {
+		t.Fatalf("expected reason Waiting with the original transition time, got %+v", got)
+	}
+
+	if !tn.SetCondition(Condition{Type: "Ready", Status: ConditionTrue, Reason: "Done"}, later) {
+		t.Fatal("expected a status change to report a change")
+	}
+	if got := tn.Condition("Ready"); !got.LastTransitionTime.Equal(later) {
+		t.Fatalf("expected transition time to move on status change, got %v", got.LastTransitionTime)
+	}
+
+	if !tn.RemoveCondition("Ready") || tn.Condition("Ready") != nil {
+		t.Fatal("expected condition to be removed")
+	}
+	if tn.RemoveCondition("Ready") {
+		t.Fatal("expected removing a missing condition to report no change")
+	}
+}
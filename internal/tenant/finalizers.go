@@ -0,0 +1,56 @@
+package tenant
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// Finalizers name the components that must clean up what they created for a tenant before it is
+// deleted or archived, such as ingress routes or DNS records. A deleting or archiving tenant whose
+// workflow has succeeded stays in its status until every finalizer has been removed.
+
+// finalizerNamePattern is lowercase letters, digits, '.' and '-', such as "dns" or
+// "ingress.example.com". Names are path segments in the API, so they cannot contain '/'.
+var finalizerNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9.-]{0,61}[a-z0-9])?$`)
+
+// ValidateFinalizerName checks that name can name a finalizer
+func ValidateFinalizerName(name string) error {
+	if !finalizerNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid finalizer name %q: must be at most 63 lowercase letters, digits, '.' and '-', starting and ending with a letter or digit", name)
+	}
+	return nil
+}
+
+// HasFinalizer reports whether name is one of t's finalizers
+func (t *Tenant) HasFinalizer(name string) bool {
+	return slices.Contains(t.Finalizers, name)
+}
+
+// AddFinalizer adds name to t's finalizers. Reports whether it was added.
+func (t *Tenant) AddFinalizer(name string) bool {
+	if t.HasFinalizer(name) {
+		return false
+	}
+	t.Finalizers = append(t.Finalizers, name)
+	return true
+}
+
+// RemoveFinalizer removes name from t's finalizers. Reports whether it was present.
+func (t *Tenant) RemoveFinalizer(name string) bool {
+	i := slices.Index(t.Finalizers, name)
+	if i < 0 {
+		return false
+	}
+	t.Finalizers = slices.Delete(t.Finalizers, i, i+1)
+	if len(t.Finalizers) == 0 {
+		t.Finalizers = nil
+	}
+	return true
+}
+
+// IsBeingRemoved reports whether t is being deleted or archived, or already is archived. Finalizers
+// cannot be added to such a tenant.
+func (t *Tenant) IsBeingRemoved() bool {
+	return t.Status == StatusDeleting || t.Status == StatusArchiving || t.Status == StatusArchived
+}
@@ -0,0 +1,33 @@
+package tenant
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFinalizerName(t *testing.T) {
+	for _, name := range []string{"dns", "ingress.example.com", "data-plane-2", "a"} {
+		require.NoError(t, ValidateFinalizerName(name), name)
+	}
+	for _, name := range []string{"", "DNS", "example.com/ingress", "-dns", "dns.", "dns_records", strings.Repeat("a", 64)} {
+		require.Error(t, ValidateFinalizerName(name), name)
+	}
+}
+
+func TestTenantFinalizers(t *testing.T) {
+	tn := &Tenant{}
+	require.True(t, tn.AddFinalizer("dns"))
+	require.True(t, tn.AddFinalizer("ingress"))
+	require.False(t, tn.AddFinalizer("dns"))
+	require.Equal(t, []string{"dns", "ingress"}, tn.Finalizers)
+	require.True(t, tn.HasFinalizer("ingress"))
+
+	require.True(t, tn.RemoveFinalizer("dns"))
+	require.False(t, tn.RemoveFinalizer("dns"))
+	require.Equal(t, []string{"ingress"}, tn.Finalizers)
+	require.True(t, tn.RemoveFinalizer("ingress"))
+	require.Nil(t, tn.Finalizers)
+	require.False(t, tn.HasFinalizer("ingress"))
+}
@@ -0,0 +1,115 @@
+package tenant
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Managed labels are set by the server, never by clients. The API records them on the tenant at
+// admission, and workers put them on every compute resource provisioned for it, so cost and
+// observability tooling finds the same keys on every compute provider.
+const (
+	// ManagedLabelPrefix prefixes every managed label
+	ManagedLabelPrefix = "landlord.io/"
+
+	// LabelTenantID is the tenant's UUID. It is only put on compute resources, since a tenant claimed
+	// from a warm pool keeps the warm tenant's UUID.
+	LabelTenantID = ManagedLabelPrefix + "tenant-id"
+
+	// LabelTenantName is the tenant's name. Like LabelTenantID it is only put on compute resources.
+	LabelTenantName = ManagedLabelPrefix + "tenant-name"
+
+	// LabelTenantEnvironment is the tenant's env label, when it has one. It is only put on compute
+	// resources, since the tenant already carries it as env.
+	LabelTenantEnvironment = ManagedLabelPrefix + "environment"
+
+	// LabelProject is the organization/project the tenant was created in
+	LabelProject = ManagedLabelPrefix + "project"
+
+	// LabelCreatedBy is the principal that created the tenant
+	LabelCreatedBy = ManagedLabelPrefix + "created-by"
+
+	// LabelTemplate is the project template the tenant was created from, when it named one
+	LabelTemplate = ManagedLabelPrefix + "template"
+)
+
+// ReservedLabelPrefix is reserved for labels the server sets: the managed labels, and the landlord.*
+// metadata compute providers put on resources, such as landlord.tenant_id
+const ReservedLabelPrefix = "landlord."
+
+// NewManagedLabels returns the managed labels of a tenant created in project by createdBy, from
+// template when one was named
+func NewManagedLabels(project, createdBy, template string) map[string]string {
+	labels := map[string]string{
+		LabelProject:   project,
+		LabelCreatedBy: createdBy,
+	}
+	if template != "" {
+		labels[LabelTemplate] = template
+	}
+	return labels
+}
+
+// ManagedLabels returns the managed labels among labels, or nil when there are none
+func ManagedLabels(labels map[string]string) map[string]string {
+	var managed map[string]string
+	for key, value := range labels {
+		if strings.HasPrefix(key, ManagedLabelPrefix) {
+			if managed == nil {
+				managed = make(map[string]string)
+			}
+			managed[key] = value
+		}
+	}
+	return managed
+}
+
+// ComputeLabels returns the managed labels put on t's compute: its own managed labels, plus its UUID,
+// name and environment
+func ComputeLabels(t *Tenant) map[string]string {
+	labels := ManagedLabels(t.Labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[LabelTenantID] = t.ID.String()
+	labels[LabelTenantName] = t.Name
+	if env := t.Labels[LabelEnvironment]; env != "" {
+		labels[LabelTenantEnvironment] = env
+	}
+	return labels
+}
+
+// ValidateLabels rejects client labels under the reserved prefix. A managed label is accepted when it
+// has the value current already holds, so a tenant read from the API can be written back unchanged.
+func ValidateLabels(labels, current map[string]string) error {
+	var reserved []string
+	for key, value := range labels {
+		if !strings.HasPrefix(key, ReservedLabelPrefix) {
+			continue
+		}
+		if held, ok := current[key]; ok && held == value && strings.HasPrefix(key, ManagedLabelPrefix) {
+			continue
+		}
+		reserved = append(reserved, key)
+	}
+	if len(reserved) > 0 {
+		sort.Strings(reserved)
+		return fmt.Errorf("labels %s use the reserved prefix %s", strings.Join(reserved, ", "), ReservedLabelPrefix)
+	}
+	return nil
+}
+
+// WithManagedLabels returns a copy of labels whose managed labels are exactly managed
+func WithManagedLabels(labels, managed map[string]string) map[string]string {
+	merged := make(map[string]string, len(labels)+len(managed))
+	for key, value := range labels {
+		if !strings.HasPrefix(key, ManagedLabelPrefix) {
+			merged[key] = value
+		}
+	}
+	for key, value := range managed {
+		merged[key] = value
+	}
+	return merged
+}
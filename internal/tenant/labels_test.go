@@ -0,0 +1,63 @@
+package tenant
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestValidateLabels(t *testing.T) {
+	current := map[string]string{LabelProject: "acme/web", "team": "platform"}
+
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr bool
+	}{
+		{name: "client labels", labels: map[string]string{"team": "data", "landlord": "yes"}},
+		{name: "managed label unchanged", labels: map[string]string{LabelProject: "acme/web"}},
+		{name: "managed label changed", labels: map[string]string{LabelProject: "other/web"}, wantErr: true},
+		{name: "managed label added", labels: map[string]string{LabelCreatedBy: "someone"}, wantErr: true},
+		{name: "compute metadata", labels: map[string]string{"landlord.tenant_id": "acme"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateLabels(tt.labels, current); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateLabels() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithManagedLabels(t *testing.T) {
+	managed := NewManagedLabels("acme/web", "ci", "")
+	if _, ok := managed[LabelTemplate]; ok {
+		t.Fatalf("expected no template label without a template, got %v", managed)
+	}
+
+	labels := WithManagedLabels(map[string]string{"team": "platform", LabelProject: "stale"}, managed)
+	want := map[string]string{"team": "platform", LabelProject: "acme/web", LabelCreatedBy: "ci"}
+	if !reflect.DeepEqual(labels, want) {
+		t.Fatalf("expected %v, got %v", want, labels)
+	}
+	if got := ManagedLabels(labels); !reflect.DeepEqual(got, managed) {
+		t.Fatalf("expected managed labels %v, got %v", managed, got)
+	}
+	if got := WithManagedLabels(nil, nil); got == nil || len(got) != 0 {
+		t.Fatalf("expected an empty label set, got %v", got)
+	}
+}
+
+func TestComputeLabels(t *testing.T) {
+	tn := &Tenant{ID: uuid.New(), Name: "acme", Labels: map[string]string{LabelProject: "acme/web", "team": "platform"}}
+	want := map[string]string{LabelProject: "acme/web", LabelTenantID: tn.ID.String(), LabelTenantName: "acme"}
+	if labels := ComputeLabels(tn); !reflect.DeepEqual(labels, want) {
+		t.Fatalf("expected %v, got %v", want, labels)
+	}
+
+	tn.Labels[LabelEnvironment] = "prod"
+	if labels := ComputeLabels(tn); labels[LabelTenantEnvironment] != "prod" {
+		t.Fatalf("expected the environment label, got %v", labels)
+	}
+}
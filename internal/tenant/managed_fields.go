@@ -0,0 +1,60 @@
+package tenant
+
+import (
+	"reflect"
+	"time"
+)
+
+// ManagerAPI is the field manager used for API requests that do not identify one
+const ManagerAPI = "api"
+
+// Operations recorded against a managed field
+const (
+	ManagedFieldOperationCreate = "create"
+	ManagedFieldOperationUpdate = "update"
+	ManagedFieldOperationPatch  = "patch"
+)
+
+// ManagedField records which actor last set a top-level desired_config field
+// Similar in spirit to Kubernetes managedFields, used by apply/sync tooling for conflict detection
+type ManagedField struct {
+	// Manager identifies the actor (API principal, gitops sync, controller)
+	Manager string `json:"manager"`
+
+	// Operation is how the field was last set (create, update, patch)
+	Operation string `json:"operation"`
+
+	// Time is when the field was last set
+	Time time.Time `json:"time"`
+}
+
+// UpdateManagedFields records manager as the owner of every top-level desired_config
+// field that was added or changed relative to previous, and drops entries for removed fields.
+func (t *Tenant) UpdateManagedFields(previous map[string]interface{}, manager, operation string, now time.Time) {
+	if manager == "" {
+		manager = ManagerAPI
+	}
+
+	managed := make(map[string]ManagedField, len(t.DesiredConfig))
+	for field, value := range t.DesiredConfig {
+		oldValue, existed := previous[field]
+		if existed && reflect.DeepEqual(oldValue, value) {
+			// Unchanged fields keep their owner; fields written before tracking existed stay unowned
+			if existing, owned := t.ManagedFields[field]; owned {
+				managed[field] = existing
+			}
+			continue
+		}
+		managed[field] = ManagedField{
+			Manager:   manager,
+			Operation: operation,
+			Time:      now,
+		}
+	}
+
+	if len(managed) == 0 {
+		t.ManagedFields = nil
+		return
+	}
+	t.ManagedFields = managed
+}
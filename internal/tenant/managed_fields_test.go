@@ -0,0 +1,71 @@
+package tenant
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTenant_UpdateManagedFields(t *testing.T) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+
+	tn := &Tenant{
+		DesiredConfig: map[string]interface{}{
+			"image": "nginx:1.0",
+			"env":   map[string]interface{}{"A": "1"},
+		},
+	}
+	tn.UpdateManagedFields(nil, "", ManagedFieldOperationCreate, created)
+
+	if len(tn.ManagedFields) != 2 {
+		t.Fatalf("expected 2 managed fields, got %d", len(tn.ManagedFields))
+	}
+	if tn.ManagedFields["image"].Manager != ManagerAPI {
+		t.Fatalf("expected default manager %q, got %q", ManagerAPI, tn.ManagedFields["image"].Manager)
+	}
+
+	previous := tn.DesiredConfig
+	tn.DesiredConfig = map[string]interface{}{
+		"image":    "nginx:1.0",
+		"env":      map[string]interface{}{"A": "2"},
+		"replicas": float64(2),
+	}
+	tn.UpdateManagedFields(previous, "gitops", ManagedFieldOperationPatch, updated)
+
+	image := tn.ManagedFields["image"]
+	if image.Manager != ManagerAPI || !image.Time.Equal(created) || image.Operation != ManagedFieldOperationCreate {
+		t.Fatalf("expected unchanged field to keep its owner, got %+v", image)
+	}
+	for _, field := range []string{"env", "replicas"} {
+		entry := tn.ManagedFields[field]
+		if entry.Manager != "gitops" || entry.Operation != ManagedFieldOperationPatch || !entry.Time.Equal(updated) {
+			t.Fatalf("expected %s to be owned by gitops, got %+v", field, entry)
+		}
+	}
+
+	previous = tn.DesiredConfig
+	tn.DesiredConfig = map[string]interface{}{"image": "nginx:1.0"}
+	tn.UpdateManagedFields(previous, "gitops", ManagedFieldOperationUpdate, updated)
+	if _, ok := tn.ManagedFields["env"]; ok {
+		t.Fatal("expected removed field to be dropped from managed fields")
+	}
+	if len(tn.ManagedFields) != 1 {
+		t.Fatalf("expected 1 managed field, got %d", len(tn.ManagedFields))
+	}
+}
+
+func TestTenant_UpdateManagedFields_UntrackedFieldsStayUnowned(t *testing.T) {
+	tn := &Tenant{
+		DesiredConfig: map[string]interface{}{"image": "nginx:1.0", "cpu": float64(256)},
+	}
+	previous := map[string]interface{}{"image": "nginx:1.0", "cpu": float64(128)}
+
+	tn.UpdateManagedFields(previous, "ci", ManagedFieldOperationUpdate, time.Now())
+
+	if _, ok := tn.ManagedFields["image"]; ok {
+		t.Fatal("expected unchanged legacy field to remain unowned")
+	}
+	if tn.ManagedFields["cpu"].Manager != "ci" {
+		t.Fatalf("expected cpu to be owned by ci, got %+v", tn.ManagedFields["cpu"])
+	}
+}
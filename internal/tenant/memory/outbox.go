@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+type outboxEntry struct {
+	entry         tenant.OutboxEntry
+	nextAttemptAt time.Time
+	claimedUntil  time.Time
+}
+
+func (r *Repository) UpdateTenantWithOutbox(ctx context.Context, t *tenant.Tenant, entries ...*tenant.OutboxEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.updateLocked(t); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, e := range entries {
+		if r.hasDedupKeyLocked(e.DedupKey) {
+			continue
+		}
+		if e.ID == uuid.Nil {
+			e.ID = uuid.New()
+		}
+		e.CreatedAt = now
+		stored := *e
+		stored.Payload = copyPayload(e.Payload)
+		stored.Attempts = 0
+		stored.LastError = ""
+		stored.ClaimToken = ""
+		stored.PublishedAt = nil
+		r.outbox = append(r.outbox, &outboxEntry{entry: stored, nextAttemptAt: now})
+	}
+	return nil
+}
+
+func (r *Repository) ClaimOutboxEntries(ctx context.Context, limit int, claimTimeout time.Duration, maxAttempts int) ([]*tenant.OutboxEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var claimed []*tenant.OutboxEntry
+	for _, e := range r.outbox {
+		if limit > 0 && len(claimed) >= limit {
+			break
+		}
+		if e.entry.PublishedAt != nil || e.entry.Attempts >= maxAttempts {
+			continue
+		}
+		if now.Before(e.nextAttemptAt) || now.Before(e.claimedUntil) {
+			continue
+		}
+		e.entry.Attempts++
+		e.entry.ClaimToken = uuid.NewString()
+		e.claimedUntil = now.Add(claimTimeout)
+		entry := e.entry
+		entry.Payload = copyPayload(e.entry.Payload)
+		claimed = append(claimed, &entry)
+	}
+	return claimed, nil
+}
+
+func (r *Repository) CompleteOutboxEntry(ctx context.Context, id uuid.UUID, claimToken string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, err := r.claimedEntryLocked(id, claimToken)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	e.entry.PublishedAt = &now
+	e.entry.ClaimToken = ""
+	e.claimedUntil = time.Time{}
+	return nil
+}
+
+func (r *Repository) FailOutboxEntry(ctx context.Context, id uuid.UUID, claimToken, lastError string, retryAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, err := r.claimedEntryLocked(id, claimToken)
+	if err != nil {
+		return err
+	}
+	e.entry.LastError = lastError
+	e.entry.ClaimToken = ""
+	e.claimedUntil = time.Time{}
+	e.nextAttemptAt = retryAt
+	return nil
+}
+
+func (r *Repository) DeletePublishedOutboxEntries(ctx context.Context, before time.Time, limit int) (int, error) {
+	return r.deleteOutboxWhere(limit, func(e *outboxEntry) bool {
+		return e.entry.PublishedAt != nil && e.entry.PublishedAt.Before(before)
+	}), nil
+}
+
+func (r *Repository) DeleteFailedOutboxEntries(ctx context.Context, before time.Time, maxAttempts, limit int) (int, error) {
+	now := time.Now()
+	return r.deleteOutboxWhere(limit, func(e *outboxEntry) bool {
+		return e.entry.PublishedAt == nil && e.entry.Attempts >= maxAttempts &&
+			e.entry.CreatedAt.Before(before) && !now.Before(e.claimedUntil)
+	}), nil
+}
+
+// deleteOutboxWhere deletes up to limit of the oldest entries matching match, returning how many it deleted
+func (r *Repository) deleteOutboxWhere(limit int, match func(*outboxEntry) bool) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.outbox[:0]
+	deleted := 0
+	for _, e := range r.outbox {
+		if (limit <= 0 || deleted < limit) && match(e) {
+			deleted++
+			continue
+		}
+		kept = append(kept, e)
+	}
+	clear(r.outbox[len(kept):])
+	r.outbox = kept
+	return deleted
+}
+
+// OutboxEntries returns every outbox entry, published or not, in the order they were added
+func (r *Repository) OutboxEntries() []tenant.OutboxEntry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]tenant.OutboxEntry, 0, len(r.outbox))
+	for _, e := range r.outbox {
+		entry := e.entry
+		entry.Payload = copyPayload(e.entry.Payload)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// hasDedupKeyLocked reports whether an entry with key was added; r.mu must be held
+func (r *Repository) hasDedupKeyLocked(key string) bool {
+	for _, e := range r.outbox {
+		if e.entry.DedupKey == key {
+			return true
+		}
+	}
+	return false
+}
+
+// claimedEntryLocked returns the entry if claimToken still holds its claim; r.mu must be held
+func (r *Repository) claimedEntryLocked(id uuid.UUID, claimToken string) (*outboxEntry, error) {
+	for _, e := range r.outbox {
+		if e.entry.ID != id {
+			continue
+		}
+		if claimToken == "" || e.entry.ClaimToken != claimToken || time.Now().After(e.claimedUntil) {
+			return nil, tenant.ErrOutboxEntryNotClaimed
+		}
+		return e, nil
+	}
+	return nil, tenant.ErrOutboxEntryNotClaimed
+}
+
+func copyPayload(payload map[string]interface{}) map[string]interface{} {
+	if payload == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(payload))
+	for k, v := range payload {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,455 @@
+// Package memory provides an in-memory tenant repository for tests and local harnesses.
+package memory
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// Repository implements tenant.Repository in memory.
+// Tenants are copied on the way in and out, so callers never share state with the store.
+type Repository struct {
+	mu      sync.RWMutex
+	tenants map[uuid.UUID]*tenant.Tenant
+	history map[uuid.UUID][]*tenant.StateTransition
+
+	// triggering holds the tenants whose workflow trigger lock is claimed
+	triggering map[uuid.UUID]bool
+
+	// locked holds the tenants whose mutation lock is claimed
+	locked map[uuid.UUID]bool
+
+	// outbox holds the side effects recorded with tenant writes, in the order they were added
+	outbox []*outboxEntry
+}
+
+var (
+	_ tenant.Repository = (*Repository)(nil)
+	_ tenant.Outbox     = (*Repository)(nil)
+)
+
+// New creates an empty in-memory repository
+func New() *Repository {
+	return &Repository{
+		tenants:    make(map[uuid.UUID]*tenant.Tenant),
+		history:    make(map[uuid.UUID][]*tenant.StateTransition),
+		triggering: make(map[uuid.UUID]bool),
+		locked:     make(map[uuid.UUID]bool),
+	}
+}
+
+func (r *Repository) CreateTenant(ctx context.Context, t *tenant.Tenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.tenants {
+		if existing.Name == t.Name {
+			return tenant.ErrTenantExists
+		}
+	}
+
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if t.ProjectID == uuid.Nil {
+		t.ProjectID = tenant.DefaultProjectID
+	}
+	now := time.Now()
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	t.Version = 1
+
+	stored, err := clone(t)
+	if err != nil {
+		return fmt.Errorf("create tenant: %w", err)
+	}
+	r.tenants[t.ID] = stored
+	return nil
+}
+
+func (r *Repository) GetTenantByName(ctx context.Context, name string) (*tenant.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, t := range r.tenants {
+		if t.Name == name {
+			return clone(t)
+		}
+	}
+	return nil, tenant.ErrTenantNotFound
+}
+
+func (r *Repository) GetTenantByID(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	t, ok := r.tenants[id]
+	if !ok {
+		return nil, tenant.ErrTenantNotFound
+	}
+	return clone(t)
+}
+
+func (r *Repository) UpdateTenant(ctx context.Context, t *tenant.Tenant) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.updateLocked(t)
+}
+
+// updateLocked writes t with optimistic locking; r.mu must be held
+func (r *Repository) updateLocked(t *tenant.Tenant) error {
+	existing, ok := r.tenants[t.ID]
+	if !ok {
+		return tenant.ErrTenantNotFound
+	}
+	if existing.Version != t.Version {
+		return tenant.ErrVersionConflict
+	}
+	for id, other := range r.tenants {
+		if id != t.ID && other.Name == t.Name {
+			return tenant.ErrTenantExists
+		}
+	}
+
+	t.CreatedAt = existing.CreatedAt
+	t.UpdatedAt = time.Now()
+	t.Version++
+
+	stored, err := clone(t)
+	if err != nil {
+		return fmt.Errorf("update tenant: %w", err)
+	}
+	r.tenants[t.ID] = stored
+	return nil
+}
+
+// FenceWorkflowTrigger claims the tenant's trigger lock, then calls trigger without holding the
+// repository lock so trigger may read the repository. The write still checks the version, so an
+// update that lands while trigger runs fails the fenced write with ErrVersionConflict.
+func (r *Repository) FenceWorkflowTrigger(ctx context.Context, t *tenant.Tenant, trigger func(ctx context.Context, t *tenant.Tenant) error) error {
+	r.mu.Lock()
+	existing, ok := r.tenants[t.ID]
+	switch {
+	case !ok:
+		r.mu.Unlock()
+		return tenant.ErrTenantNotFound
+	case r.triggering[t.ID]:
+		r.mu.Unlock()
+		return tenant.ErrWorkflowTriggerLocked
+	case existing.Version != t.Version:
+		r.mu.Unlock()
+		return tenant.ErrVersionConflict
+	}
+	r.triggering[t.ID] = true
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.triggering, t.ID)
+		r.mu.Unlock()
+	}()
+
+	if err := trigger(ctx, t); err != nil {
+		return err
+	}
+	return r.UpdateTenant(ctx, t)
+}
+
+func (r *Repository) LockTenant(ctx context.Context, id uuid.UUID) (func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.locked[id] {
+		return nil, tenant.ErrTenantLocked
+	}
+	r.locked[id] = true
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			r.mu.Lock()
+			delete(r.locked, id)
+			r.mu.Unlock()
+		})
+	}, nil
+}
+
+func (r *Repository) ListTenants(ctx context.Context, filters tenant.ListFilters) ([]*tenant.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return cloneAll(r.list(filters))
+}
+
+func (r *Repository) ListTenantSummaries(ctx context.Context, filters tenant.ListFilters) ([]*tenant.Summary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := r.list(filters)
+	summaries := make([]*tenant.Summary, 0, len(matched))
+	for _, t := range matched {
+		summary := &tenant.Summary{ID: t.ID, Name: t.Name, Status: t.Status, UpdatedAt: t.UpdatedAt}
+		if t.Labels != nil {
+			summary.Labels = make(map[string]string, len(t.Labels))
+			for k, v := range t.Labels {
+				summary.Labels[k] = v
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// list returns the page of tenants matching filters, newest first. The caller must hold r.mu.
+func (r *Repository) list(filters tenant.ListFilters) []*tenant.Tenant {
+	var matched []*tenant.Tenant
+	for _, t := range r.tenants {
+		if matches(t, filters) {
+			matched = append(matched, t)
+		}
+	}
+
+	// Newest first, like the postgres repository
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	if filters.Offset > 0 {
+		if filters.Offset >= len(matched) {
+			matched = nil
+		} else {
+			matched = matched[filters.Offset:]
+		}
+	}
+	if filters.Limit > 0 && len(matched) > filters.Limit {
+		matched = matched[:filters.Limit]
+	}
+	return matched
+}
+
+func (r *Repository) ListTenantsForReconciliation(ctx context.Context) ([]*tenant.Tenant, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*tenant.Tenant
+	for _, t := range r.tenants {
+		if tenant.ShouldReconcile(t.Status) {
+			matched = append(matched, t)
+		}
+	}
+
+	// Oldest first, like the postgres repository
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.Before(matched[j].CreatedAt)
+	})
+
+	return cloneAll(matched)
+}
+
+func (r *Repository) DeleteTenant(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.tenants[id]; !ok {
+		return tenant.ErrTenantNotFound
+	}
+	delete(r.tenants, id)
+	delete(r.history, id)
+	return nil
+}
+
+func (r *Repository) RecordStateTransition(ctx context.Context, st *tenant.StateTransition) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st.ID = uuid.New()
+	st.CreatedAt = time.Now()
+	// Keep a tenant's history strictly ordered by time, so newest first is the order recorded
+	if recorded := r.history[st.TenantID]; len(recorded) > 0 {
+		if last := recorded[len(recorded)-1].CreatedAt; !st.CreatedAt.After(last) {
+			st.CreatedAt = last.Add(time.Nanosecond)
+		}
+	}
+
+	stored := *st
+	r.history[st.TenantID] = append(r.history[st.TenantID], &stored)
+	return nil
+}
+
+func (r *Repository) GetStateHistory(ctx context.Context, tenantID uuid.UUID, filters tenant.HistoryFilters) ([]*tenant.StateTransition, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	recorded := r.history[tenantID]
+	history := make([]*tenant.StateTransition, 0, len(recorded))
+	for i := len(recorded) - 1; i >= 0; i-- {
+		if !matchesHistory(recorded[i], filters) {
+			continue
+		}
+		st := *recorded[i]
+		history = append(history, &st)
+	}
+	sort.SliceStable(history, func(i, j int) bool { return newer(history[i].Cursor(), history[j].Cursor()) })
+	if filters.Limit > 0 && len(history) > filters.Limit {
+		history = history[:filters.Limit]
+	}
+	return history, nil
+}
+
+// matchesHistory applies the same filters as the postgres repository's history query
+func matchesHistory(st *tenant.StateTransition, filters tenant.HistoryFilters) bool {
+	if len(filters.ToStatuses) > 0 && !containsStatus(filters.ToStatuses, st.ToStatus) {
+		return false
+	}
+	if len(filters.ReasonCodes) > 0 && !slices.Contains(filters.ReasonCodes, st.ReasonCode) {
+		return false
+	}
+	if filters.CreatedAfter != nil && !st.CreatedAt.After(*filters.CreatedAfter) {
+		return false
+	}
+	if filters.CreatedBefore != nil && !st.CreatedAt.Before(*filters.CreatedBefore) {
+		return false
+	}
+	if filters.After != nil && !newer(*filters.After, st.Cursor()) {
+		return false
+	}
+	return true
+}
+
+// newer reports whether a comes before b in history order: newest first, then by ID
+func newer(a, b tenant.HistoryCursor) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return bytes.Compare(a.ID[:], b.ID[:]) > 0
+}
+
+// matches applies the same filters as the postgres repository's list query
+func matches(t *tenant.Tenant, filters tenant.ListFilters) bool {
+	if !filters.IncludeDeleted && t.Status == tenant.StatusArchived {
+		return false
+	}
+	if len(filters.Statuses) > 0 && !containsStatus(filters.Statuses, t.Status) {
+		return false
+	}
+	if len(filters.ProjectIDs) > 0 && !containsProject(filters.ProjectIDs, t.ProjectID) {
+		return false
+	}
+	if !containsAll(t.Labels, filters.Labels) || !containsAll(t.Annotations, filters.Annotations) {
+		return false
+	}
+	if filters.CreatedAfter != nil && !t.CreatedAt.After(*filters.CreatedAfter) {
+		return false
+	}
+	if filters.CreatedBefore != nil && !t.CreatedAt.Before(*filters.CreatedBefore) {
+		return false
+	}
+	if len(filters.WorkflowSubStates) > 0 {
+		if t.WorkflowSubState == nil || !containsString(filters.WorkflowSubStates, *t.WorkflowSubState) {
+			return false
+		}
+	}
+	if filters.HasWorkflowError != nil && *filters.HasWorkflowError != (t.WorkflowErrorMessage != nil) {
+		return false
+	}
+	if filters.MinRetryCount != nil {
+		retries := 0
+		if t.WorkflowRetryCount != nil {
+			retries = *t.WorkflowRetryCount
+		}
+		if retries < *filters.MinRetryCount {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAll reports whether values has every key and value in selector
+func containsAll(values, selector map[string]string) bool {
+	for key, value := range selector {
+		if got, ok := values[key]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
+
+func containsStatus(statuses []tenant.Status, status tenant.Status) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func containsProject(projects []uuid.UUID, project uuid.UUID) bool {
+	for _, candidate := range projects {
+		if candidate == project {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// clone deep-copies a tenant through its JSON form, which covers every persisted field
+func clone(t *tenant.Tenant) (*tenant.Tenant, error) {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, fmt.Errorf("marshal tenant: %w", err)
+	}
+	var copied tenant.Tenant
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("unmarshal tenant: %w", err)
+	}
+
+	// The postgres repository reads empty JSONB columns back as empty maps, not nil
+	if copied.DesiredConfig == nil {
+		copied.DesiredConfig = map[string]interface{}{}
+	}
+	if copied.ObservedConfig == nil {
+		copied.ObservedConfig = map[string]interface{}{}
+	}
+	if copied.ObservedResourceIDs == nil {
+		copied.ObservedResourceIDs = map[string]string{}
+	}
+	if copied.Labels == nil {
+		copied.Labels = map[string]string{}
+	}
+	if copied.Annotations == nil {
+		copied.Annotations = map[string]string{}
+	}
+	return &copied, nil
+}
+
+func cloneAll(tenants []*tenant.Tenant) ([]*tenant.Tenant, error) {
+	copies := make([]*tenant.Tenant, 0, len(tenants))
+	for _, t := range tenants {
+		copied, err := clone(t)
+		if err != nil {
+			return nil, err
+		}
+		copies = append(copies, copied)
+	}
+	return copies, nil
+}
@@ -0,0 +1,328 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+func newTenant(name string, status tenant.Status) *tenant.Tenant {
+	return &tenant.Tenant{
+		Name:          name,
+		Status:        status,
+		DesiredConfig: map[string]interface{}{"image": "nginx:latest"},
+	}
+}
+
+func TestRepository_CreateAndGet(t *testing.T) {
+	repo := New()
+	ctx := context.Background()
+
+	tn := newTenant("test-tenant", tenant.StatusRequested)
+	if err := repo.CreateTenant(ctx, tn); err != nil {
+		t.Fatalf("CreateTenant() error = %v", err)
+	}
+	if tn.ID == uuid.Nil {
+		t.Error("CreateTenant() did not set ID")
+	}
+	if tn.CreatedAt.IsZero() || tn.UpdatedAt.IsZero() {
+		t.Error("CreateTenant() did not set timestamps")
+	}
+	if tn.Version != 1 {
+		t.Errorf("CreateTenant() Version = %d, want 1", tn.Version)
+	}
+
+	byName, err := repo.GetTenantByName(ctx, "test-tenant")
+	if err != nil {
+		t.Fatalf("GetTenantByName() error = %v", err)
+	}
+	if byName.ID != tn.ID {
+		t.Errorf("GetTenantByName() ID = %s, want %s", byName.ID, tn.ID)
+	}
+
+	// Callers get copies, so mutating a result does not change the store
+	byName.DesiredConfig["image"] = "changed"
+	byID, err := repo.GetTenantByID(ctx, tn.ID)
+	if err != nil {
+		t.Fatalf("GetTenantByID() error = %v", err)
+	}
+	if byID.DesiredConfig["image"] != "nginx:latest" {
+		t.Errorf("stored tenant was mutated through a returned copy: %v", byID.DesiredConfig["image"])
+	}
+
+	if err := repo.CreateTenant(ctx, newTenant("test-tenant", tenant.StatusRequested)); err != tenant.ErrTenantExists {
+		t.Errorf("CreateTenant() duplicate error = %v, want %v", err, tenant.ErrTenantExists)
+	}
+	if _, err := repo.GetTenantByID(ctx, uuid.New()); err != tenant.ErrTenantNotFound {
+		t.Errorf("GetTenantByID() error = %v, want %v", err, tenant.ErrTenantNotFound)
+	}
+}
+
+func TestRepository_UpdateTenant_VersionConflict(t *testing.T) {
+	repo := New()
+	ctx := context.Background()
+
+	tn := newTenant("test-tenant", tenant.StatusRequested)
+	if err := repo.CreateTenant(ctx, tn); err != nil {
+		t.Fatalf("CreateTenant() error = %v", err)
+	}
+
+	stale, _ := repo.GetTenantByID(ctx, tn.ID)
+
+	tn.Status = tenant.StatusProvisioning
+	if err := repo.UpdateTenant(ctx, tn); err != nil {
+		t.Fatalf("UpdateTenant() error = %v", err)
+	}
+	if tn.Version != 2 {
+		t.Errorf("UpdateTenant() Version = %d, want 2", tn.Version)
+	}
+
+	stale.Status = tenant.StatusFailed
+	if err := repo.UpdateTenant(ctx, stale); err != tenant.ErrVersionConflict {
+		t.Errorf("UpdateTenant() stale error = %v, want %v", err, tenant.ErrVersionConflict)
+	}
+
+	missing := newTenant("missing", tenant.StatusRequested)
+	missing.ID = uuid.New()
+	if err := repo.UpdateTenant(ctx, missing); err != tenant.ErrTenantNotFound {
+		t.Errorf("UpdateTenant() missing error = %v, want %v", err, tenant.ErrTenantNotFound)
+	}
+}
+
+func TestRepository_FenceWorkflowTrigger(t *testing.T) {
+	repo := New()
+	ctx := context.Background()
+
+	tn := newTenant("test-tenant", tenant.StatusRequested)
+	if err := repo.CreateTenant(ctx, tn); err != nil {
+		t.Fatalf("CreateTenant() error = %v", err)
+	}
+	stale, _ := repo.GetTenantByID(ctx, tn.ID)
+
+	triggers := 0
+	err := repo.FenceWorkflowTrigger(ctx, tn, func(ctx context.Context, locked *tenant.Tenant) error {
+		triggers++
+		// A second worker acting on the same version cannot claim the lock
+		other, _ := repo.GetTenantByID(ctx, tn.ID)
+		if err := repo.FenceWorkflowTrigger(ctx, other, func(context.Context, *tenant.Tenant) error {
+			triggers++
+			return nil
+		}); err != tenant.ErrWorkflowTriggerLocked {
+			t.Errorf("concurrent FenceWorkflowTrigger() error = %v, want %v", err, tenant.ErrWorkflowTriggerLocked)
+		}
+		executionID := "exec-1"
+		locked.WorkflowExecutionID = &executionID
+		locked.Status = tenant.StatusProvisioning
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FenceWorkflowTrigger() error = %v", err)
+	}
+	if triggers != 1 {
+		t.Errorf("trigger called %d times, want 1", triggers)
+	}
+	if tn.Version != 2 {
+		t.Errorf("FenceWorkflowTrigger() Version = %d, want 2", tn.Version)
+	}
+
+	stored, _ := repo.GetTenantByID(ctx, tn.ID)
+	if stored.WorkflowExecutionID == nil || *stored.WorkflowExecutionID != "exec-1" {
+		t.Errorf("execution ID not written with the trigger: %v", stored.WorkflowExecutionID)
+	}
+
+	// A retried trigger from the version read before the first trigger is fenced off
+	if err := repo.FenceWorkflowTrigger(ctx, stale, func(context.Context, *tenant.Tenant) error {
+		t.Error("trigger called for a stale version")
+		return nil
+	}); err != tenant.ErrVersionConflict {
+		t.Errorf("stale FenceWorkflowTrigger() error = %v, want %v", err, tenant.ErrVersionConflict)
+	}
+
+	// A failed trigger writes nothing and releases the lock
+	failed := errors.New("trigger failed")
+	if err := repo.FenceWorkflowTrigger(ctx, tn, func(context.Context, *tenant.Tenant) error {
+		return failed
+	}); err != failed {
+		t.Errorf("FenceWorkflowTrigger() error = %v, want %v", err, failed)
+	}
+	if err := repo.FenceWorkflowTrigger(ctx, tn, func(context.Context, *tenant.Tenant) error { return nil }); err != nil {
+		t.Errorf("FenceWorkflowTrigger() after failed trigger error = %v", err)
+	}
+}
+
+func TestRepository_LockTenant(t *testing.T) {
+	repo := New()
+	ctx := context.Background()
+	id := uuid.New()
+
+	release, err := repo.LockTenant(ctx, id)
+	if err != nil {
+		t.Fatalf("LockTenant() error = %v", err)
+	}
+	if _, err := repo.LockTenant(ctx, id); err != tenant.ErrTenantLocked {
+		t.Errorf("LockTenant() while held error = %v, want %v", err, tenant.ErrTenantLocked)
+	}
+	if other, err := repo.LockTenant(ctx, uuid.New()); err != nil {
+		t.Errorf("LockTenant() other tenant error = %v", err)
+	} else {
+		other()
+	}
+
+	release()
+	release() // releasing twice is harmless
+	again, err := repo.LockTenant(ctx, id)
+	if err != nil {
+		t.Fatalf("LockTenant() after release error = %v", err)
+	}
+	again()
+}
+
+func TestRepository_ListTenants(t *testing.T) {
+	repo := New()
+	ctx := context.Background()
+
+	otherProject := uuid.New()
+	ready := newTenant("ready", tenant.StatusReady)
+	ready.ProjectID = otherProject
+	ready.Labels = map[string]string{"team": "platform", "env": "prod"}
+	ready.Annotations = map[string]string{"landlord/warm_pool": "small"}
+	for _, tn := range []*tenant.Tenant{
+		newTenant("requested", tenant.StatusRequested),
+		ready,
+		newTenant("archived", tenant.StatusArchived),
+	} {
+		if err := repo.CreateTenant(ctx, tn); err != nil {
+			t.Fatalf("CreateTenant() error = %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	all, err := repo.ListTenants(ctx, tenant.ListFilters{})
+	if err != nil {
+		t.Fatalf("ListTenants() error = %v", err)
+	}
+	if len(all) != 2 || all[0].Name != "ready" || all[1].Name != "requested" {
+		t.Errorf("ListTenants() = %v, want [ready requested]", names(all))
+	}
+
+	withDeleted, _ := repo.ListTenants(ctx, tenant.ListFilters{IncludeDeleted: true})
+	if len(withDeleted) != 3 {
+		t.Errorf("ListTenants(IncludeDeleted) returned %d tenants, want 3", len(withDeleted))
+	}
+
+	requested, _ := repo.ListTenants(ctx, tenant.ListFilters{Statuses: []tenant.Status{tenant.StatusRequested}})
+	if len(requested) != 1 || requested[0].Name != "requested" {
+		t.Errorf("ListTenants(Statuses) = %v, want [requested]", names(requested))
+	}
+
+	inDefault, _ := repo.ListTenants(ctx, tenant.ListFilters{ProjectIDs: []uuid.UUID{tenant.DefaultProjectID}})
+	if len(inDefault) != 1 || inDefault[0].Name != "requested" {
+		t.Errorf("ListTenants(ProjectIDs) = %v, want [requested] in the default project", names(inDefault))
+	}
+
+	labelled, _ := repo.ListTenants(ctx, tenant.ListFilters{Labels: map[string]string{"team": "platform"}})
+	if len(labelled) != 1 || labelled[0].Name != "ready" {
+		t.Errorf("ListTenants(Labels) = %v, want [ready]", names(labelled))
+	}
+	mismatched, _ := repo.ListTenants(ctx, tenant.ListFilters{Labels: map[string]string{"team": "platform", "env": "dev"}})
+	if len(mismatched) != 0 {
+		t.Errorf("ListTenants(Labels) = %v, want no tenants when one label differs", names(mismatched))
+	}
+	annotated, _ := repo.ListTenants(ctx, tenant.ListFilters{Annotations: map[string]string{"landlord/warm_pool": "small"}})
+	if len(annotated) != 1 || annotated[0].Name != "ready" {
+		t.Errorf("ListTenants(Annotations) = %v, want [ready]", names(annotated))
+	}
+
+		reconcile, _ := repo.ListTenantsForReconciliation(ctx)
+	if len(reconcile) != 1 || reconcile[0].Name != "requested" {
+		t.Errorf("ListTenantsForReconciliation() = %v, want [requested]", names(reconcile))
+	}
+}
+
+func TestRepository_ListTenantSummaries(t *testing.T) {
+	repo := New()
+	ctx := context.Background()
+
+	for _, name := range []string{"first", "second", "third"} {
+		tn := newTenant(name, tenant.StatusReady)
+		tn.Labels = map[string]string{"team": name}
+		if err := repo.CreateTenant(ctx, tn); err != nil {
+			t.Fatalf("CreateTenant() error = %v", err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	summaries, err := repo.ListTenantSummaries(ctx, tenant.ListFilters{Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatalf("ListTenantSummaries() error = %v", err)
+	}
+	if len(summaries) != 2 || summaries[0].Name != "second" || summaries[1].Name != "first" {
+		t.Fatalf("ListTenantSummaries() = %+v, want [second first]", summaries)
+	}
+	if summaries[0].Status != tenant.StatusReady || summaries[0].Labels["team"] != "second" || summaries[0].UpdatedAt.IsZero() {
+		t.Errorf("unexpected summary %+v", summaries[0])
+	}
+
+	summaries[0].Labels["team"] = "changed"
+	stored, _ := repo.GetTenantByName(ctx, "second")
+	if stored.Labels["team"] != "second" {
+		t.Error("expected summaries not to share labels with the stored tenant")
+	}
+}
+
+func TestRepository_DeleteAndHistory(t *testing.T) {
+	repo := New()
+	ctx := context.Background()
+
+	tn := newTenant("test-tenant", tenant.StatusRequested)
+	if err := repo.CreateTenant(ctx, tn); err != nil {
+		t.Fatalf("CreateTenant() error = %v", err)
+	}
+
+	for _, to := range []tenant.Status{tenant.StatusProvisioning, tenant.StatusReady} {
+		if err := repo.RecordStateTransition(ctx, &tenant.StateTransition{TenantID: tn.ID, ToStatus: to, Reason: "test"}); err != nil {
+			t.Fatalf("RecordStateTransition() error = %v", err)
+		}
+	}
+	history, err := repo.GetStateHistory(ctx, tn.ID, tenant.HistoryFilters{})
+	if err != nil {
+		t.Fatalf("GetStateHistory() error = %v", err)
+	}
+	if len(history) != 2 || history[0].ToStatus != tenant.StatusReady {
+		t.Errorf("GetStateHistory() should return newest first, got %d entries", len(history))
+	}
+
+	page, err := repo.GetStateHistory(ctx, tn.ID, tenant.HistoryFilters{Limit: 1})
+	if err != nil || len(page) != 1 || page[0].ToStatus != tenant.StatusReady {
+		t.Fatalf("GetStateHistory() with a limit = %v, %v", page, err)
+	}
+	cursor := page[0].Cursor()
+	page, err = repo.GetStateHistory(ctx, tn.ID, tenant.HistoryFilters{After: &cursor})
+	if err != nil || len(page) != 1 || page[0].ToStatus != tenant.StatusProvisioning {
+		t.Errorf("GetStateHistory() after the cursor = %v, %v", page, err)
+	}
+	filtered, err := repo.GetStateHistory(ctx, tn.ID, tenant.HistoryFilters{ToStatuses: []tenant.Status{tenant.StatusProvisioning}, CreatedBefore: &history[0].CreatedAt})
+	if err != nil || len(filtered) != 1 || filtered[0].ToStatus != tenant.StatusProvisioning {
+		t.Errorf("GetStateHistory() filtered by status and time = %v, %v", filtered, err)
+	}
+
+	if err := repo.DeleteTenant(ctx, tn.ID); err != nil {
+		t.Fatalf("DeleteTenant() error = %v", err)
+	}
+	if err := repo.DeleteTenant(ctx, tn.ID); err != tenant.ErrTenantNotFound {
+		t.Errorf("DeleteTenant() second call error = %v, want %v", err, tenant.ErrTenantNotFound)
+	}
+}
+
+func names(tenants []*tenant.Tenant) []string {
+	result := make([]string, 0, len(tenants))
+	for _, t := range tenants {
+		result = append(result, t.Name)
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+var _ tenant.HistoryScrubber = (*Repository)(nil)
+
+func (r *Repository) ScrubStateHistory(ctx context.Context, cutoff time.Time, scrub func(*tenant.StateTransition)) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	scrubbed := 0
+	for _, history := range r.history {
+		for i, st := range history {
+			if st.ScrubbedAt != nil || !st.CreatedAt.Before(cutoff) {
+				continue
+			}
+			// Snapshots may share maps with the tenant they were taken from, so scrub a copy
+			copied, err := cloneTransition(st)
+			if err != nil {
+				return scrubbed, err
+			}
+			scrub(copied)
+			copied.ScrubbedAt = &now
+			history[i] = copied
+			scrubbed++
+		}
+	}
+	return scrubbed, nil
+}
+
+func (r *Repository) ForgetStateHistory(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	history := r.history[tenantID]
+	for i, st := range history {
+		forgotten := *st
+		forgotten.DesiredStateSnapshot = nil
+		forgotten.ObservedStateSnapshot = nil
+		forgotten.ScrubbedAt = &now
+		history[i] = &forgotten
+	}
+	return len(history), nil
+}
+
+// cloneTransition deep-copies a transition through its JSON form
+func cloneTransition(st *tenant.StateTransition) (*tenant.StateTransition, error) {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return nil, fmt.Errorf("marshal transition: %w", err)
+	}
+	var copied tenant.StateTransition
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, fmt.Errorf("unmarshal transition: %w", err)
+	}
+	return &copied, nil
+}
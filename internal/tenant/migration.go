@@ -0,0 +1,96 @@
+package tenant
+
+// MigrationPhase is a step of moving a tenant to another compute provider
+type MigrationPhase string
+
+const (
+	// MigrationPhaseProvisionTarget: Compute is being provisioned on the target provider
+	// The source keeps serving traffic
+	MigrationPhaseProvisionTarget MigrationPhase = "provisioning-target"
+
+	// MigrationPhaseSwitchover: Endpoints are being switched to the target provider
+	MigrationPhaseSwitchover MigrationPhase = "switching-endpoints"
+
+	// MigrationPhaseDestroySource: Compute is being removed from the source provider
+	MigrationPhaseDestroySource MigrationPhase = "destroying-source"
+)
+
+// Annotations recording an in-progress migration. They survive failures so a migration can resume.
+const (
+	AnnotationMigrationSource = "landlord/migration_source"
+	AnnotationMigrationTarget = "landlord/migration_target"
+	AnnotationMigrationPhase  = "landlord/migration_phase"
+)
+
+// Migration describes a tenant's in-progress move between compute providers
+type Migration struct {
+	Source string         `json:"source_provider"`
+	Target string         `json:"target_provider"`
+	Phase  MigrationPhase `json:"phase"`
+}
+
+// IsValid checks if a phase is a known migration phase
+func (p MigrationPhase) IsValid() bool {
+	switch p {
+	case MigrationPhaseProvisionTarget, MigrationPhaseSwitchover, MigrationPhaseDestroySource:
+		return true
+	default:
+		return false
+	}
+}
+
+// Next returns the phase after p; false means the migration is complete
+func (p MigrationPhase) Next() (MigrationPhase, bool) {
+	switch p {
+	case MigrationPhaseProvisionTarget:
+		return MigrationPhaseSwitchover, true
+	case MigrationPhaseSwitchover:
+		return MigrationPhaseDestroySource, true
+	default:
+		return "", false
+	}
+}
+
+// Migration returns the tenant's recorded migration, or nil if none is in progress
+func (t *Tenant) Migration() *Migration {
+	if t.Annotations == nil {
+		return nil
+	}
+	phase := MigrationPhase(t.Annotations[AnnotationMigrationPhase])
+	if !phase.IsValid() || t.Annotations[AnnotationMigrationTarget] == "" {
+		return nil
+	}
+	return &Migration{
+		Source: t.Annotations[AnnotationMigrationSource],
+		Target: t.Annotations[AnnotationMigrationTarget],
+		Phase:  phase,
+	}
+}
+
+// StartMigration records a migration from source to target at its first phase
+func (t *Tenant) StartMigration(source, target string) {
+	if t.Annotations == nil {
+		t.Annotations = map[string]string{}
+	}
+	t.Annotations[AnnotationMigrationSource] = source
+	t.Annotations[AnnotationMigrationTarget] = target
+	t.Annotations[AnnotationMigrationPhase] = string(MigrationPhaseProvisionTarget)
+}
+
+// SetMigrationPhase records the phase of the in-progress migration
+func (t *Tenant) SetMigrationPhase(phase MigrationPhase) {
+	if t.Annotations == nil {
+		t.Annotations = map[string]string{}
+	}
+	t.Annotations[AnnotationMigrationPhase] = string(phase)
+}
+
+// ClearMigration removes the migration record once the tenant has moved
+func (t *Tenant) ClearMigration() {
+	delete(t.Annotations, AnnotationMigrationSource)
+	delete(t.Annotations, AnnotationMigrationTarget)
+	delete(t.Annotations, AnnotationMigrationPhase)
+	if len(t.Annotations) == 0 {
+		t.Annotations = nil
+	}
+}
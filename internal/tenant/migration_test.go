@@ -0,0 +1,55 @@
+package tenant
+
+import "testing"
+
+func TestTenant_MigrationLifecycle(t *testing.T) {
+	tn := &Tenant{Annotations: map[string]string{"owner": "platform"}}
+	if tn.Migration() != nil {
+		t.Fatal("expected no migration before one is started")
+	}
+
+	tn.StartMigration("docker", "kubernetes")
+	got := tn.Migration()
+	if got == nil {
+		t.Fatal("expected migration after StartMigration")
+	}
+	if got.Source != "docker" || got.Target != "kubernetes" || got.Phase != MigrationPhaseProvisionTarget {
+		t.Fatalf("unexpected migration %+v", got)
+	}
+
+	var phases []MigrationPhase
+	for phase, ok := got.Phase, true; ok; phase, ok = phase.Next() {
+		phases = append(phases, phase)
+		tn.SetMigrationPhase(phase)
+	}
+	want := []MigrationPhase{MigrationPhaseProvisionTarget, MigrationPhaseSwitchover, MigrationPhaseDestroySource}
+	if len(phases) != len(want) {
+		t.Fatalf("expected phases %v, got %v", want, phases)
+	}
+	for i := range want {
+		if phases[i] != want[i] {
+			t.Fatalf("expected phases %v, got %v", want, phases)
+		}
+	}
+	if tn.Migration().Phase != MigrationPhaseDestroySource {
+		t.Fatalf("expected recorded phase %s, got %s", MigrationPhaseDestroySource, tn.Migration().Phase)
+	}
+
+	tn.ClearMigration()
+	if tn.Migration() != nil {
+		t.Fatal("expected no migration after ClearMigration")
+	}
+	if tn.Annotations["owner"] != "platform" {
+		t.Fatalf("expected unrelated annotations to be kept, got %v", tn.Annotations)
+	}
+}
+
+func TestTenant_MigrationIgnoresUnknownPhase(t *testing.T) {
+	tn := &Tenant{Annotations: map[string]string{
+		AnnotationMigrationTarget: "kubernetes",
+		AnnotationMigrationPhase:  "teleporting",
+	}}
+	if tn.Migration() != nil {
+		t.Fatal("expected unknown phase to be ignored")
+	}
+}
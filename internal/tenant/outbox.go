@@ -0,0 +1,71 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrOutboxEntryNotClaimed is returned when an outbox entry is completed or failed by a
+// dispatcher whose claim has expired, because another dispatcher may be publishing it
+var ErrOutboxEntryNotClaimed = errors.New("outbox entry is not claimed by this dispatcher")
+
+// OutboxEntry is a side effect of a tenant write, such as a workflow trigger, recorded in the
+// same transaction as the write so it is published even if the writer crashes right after
+type OutboxEntry struct {
+	ID       uuid.UUID              `json:"id"`
+	TenantID uuid.UUID              `json:"tenant_id"`
+	Kind     string                 `json:"kind"`
+	Payload  map[string]interface{} `json:"payload,omitempty"`
+
+	// DedupKey identifies the side effect; an entry whose key is already in the outbox is not added again
+	DedupKey string `json:"dedup_key"`
+
+	// Attempts counts the publishes claimed so far
+	Attempts int `json:"attempts"`
+
+	// LastError is the error of the last failed publish
+	LastError string `json:"last_error,omitempty"`
+
+	// ClaimToken identifies the dispatcher's current claim; completing or failing needs it
+	ClaimToken string `json:"-"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// Outbox records side effects of tenant writes for a dispatcher to publish at least once.
+// Repositories that support it implement it next to Repository.
+type Outbox interface {
+	// UpdateTenantWithOutbox writes the tenant like UpdateTenant and adds the entries in the same transaction
+	// Entries whose DedupKey is already in the outbox are skipped
+	// Returns ErrTenantNotFound or ErrVersionConflict like UpdateTenant, adding no entries
+	UpdateTenantWithOutbox(ctx context.Context, t *Tenant, entries ...*OutboxEntry) error
+
+	// ClaimOutboxEntries claims up to limit unpublished entries, oldest first, for claimTimeout.
+	// Entries claimed by another dispatcher are skipped until the claim expires, and entries
+	// that have used maxAttempts publishes are not claimed.
+	ClaimOutboxEntries(ctx context.Context, limit int, claimTimeout time.Duration, maxAttempts int) ([]*OutboxEntry, error)
+
+	// CompleteOutboxEntry marks a claimed entry published
+	// Returns ErrOutboxEntryNotClaimed if the claim has expired
+	CompleteOutboxEntry(ctx context.Context, id uuid.UUID, claimToken string) error
+
+	// FailOutboxEntry records a failed publish and releases the claim, so the entry is claimed
+	// again no sooner than retryAt
+	// Returns ErrOutboxEntryNotClaimed if the claim has expired
+	FailOutboxEntry(ctx context.Context, id uuid.UUID, claimToken, lastError string, retryAt time.Time) error
+}
+
+// OutboxCollector deletes outbox entries that are no longer needed, in batches so no delete holds
+// locks or builds up dead rows for long. Repositories that support it implement it next to Outbox.
+type OutboxCollector interface {
+	// DeletePublishedOutboxEntries deletes up to limit entries published before before, returning how many it deleted
+	DeletePublishedOutboxEntries(ctx context.Context, before time.Time, limit int) (int, error)
+
+	// DeleteFailedOutboxEntries deletes up to limit unpublished entries created before before that
+	// have used maxAttempts publishes, returning how many it deleted. Claimed entries are kept.
+	DeleteFailedOutboxEntries(ctx context.Context, before time.Time, maxAttempts, limit int) (int, error)
+}
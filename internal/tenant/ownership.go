@@ -0,0 +1,62 @@
+package tenant
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// A tenant's owner is the API key that created it, and its collaborators are the API keys it is
+// shared with. Only they and admins may change the tenant. A tenant without an owner, created
+// while authentication was disabled or before owners were recorded, may be changed by every
+// principal that can see it until an owner is assigned.
+
+// ValidatePrincipalName checks that name can name an owner or collaborator. Collaborator names are
+// path segments in the API, so they cannot contain '/'.
+func ValidatePrincipalName(name string) error {
+	if name == "" || strings.TrimSpace(name) != name || strings.Contains(name, "/") || len(name) > 255 {
+		return fmt.Errorf("invalid principal name %q: must be at most 255 characters, without '/' or surrounding spaces", name)
+	}
+	return nil
+}
+
+// SharedWith reports whether the principal called name may change t: it owns t, t is shared with
+// it, or t has no owner
+func (t *Tenant) SharedWith(name string) bool {
+	return t.Owner == "" || t.Owner == name || t.HasCollaborator(name)
+}
+
+// HasCollaborator reports whether t is shared with the principal called name
+func (t *Tenant) HasCollaborator(name string) bool {
+	return slices.Contains(t.Collaborators, name)
+}
+
+// AddCollaborator shares t with name. Reports whether it was added; the owner is never a
+// collaborator.
+func (t *Tenant) AddCollaborator(name string) bool {
+	if name == t.Owner || t.HasCollaborator(name) {
+		return false
+	}
+	t.Collaborators = append(t.Collaborators, name)
+	return true
+}
+
+// RemoveCollaborator stops sharing t with name. Reports whether it was a collaborator.
+func (t *Tenant) RemoveCollaborator(name string) bool {
+	i := slices.Index(t.Collaborators, name)
+	if i < 0 {
+		return false
+	}
+	t.Collaborators = slices.Delete(t.Collaborators, i, i+1)
+	if len(t.Collaborators) == 0 {
+		t.Collaborators = nil
+	}
+	return true
+}
+
+// TransferOwnership makes name t's owner. A collaborator that becomes the owner is no longer a
+// collaborator; the previous owner keeps no access unless t is shared with it.
+func (t *Tenant) TransferOwnership(name string) {
+	t.RemoveCollaborator(name)
+	t.Owner = name
+}
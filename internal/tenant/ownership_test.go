@@ -0,0 +1,47 @@
+package tenant
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePrincipalName(t *testing.T) {
+	for _, name := range []string{"alice", "acme-ci", "Platform Team"} {
+		require.NoError(t, ValidatePrincipalName(name), name)
+	}
+	for _, name := range []string{"", " alice", "acme/ci", strings.Repeat("a", 256)} {
+		require.Error(t, ValidatePrincipalName(name), name)
+	}
+}
+
+func TestTenantOwnership(t *testing.T) {
+	tn := &Tenant{}
+	require.True(t, tn.SharedWith("anyone"))
+
+	tn.Owner = "alice"
+	require.True(t, tn.SharedWith("alice"))
+	require.False(t, tn.SharedWith("bob"))
+
+	require.True(t, tn.AddCollaborator("bob"))
+	require.False(t, tn.AddCollaborator("bob"))
+	require.False(t, tn.AddCollaborator("alice"))
+	require.True(t, tn.AddCollaborator("carol"))
+	require.True(t, tn.SharedWith("bob"))
+	require.Equal(t, []string{"bob", "carol"}, tn.Collaborators)
+
+	// The new owner stops being a collaborator, and the old one loses access
+	tn.TransferOwnership("bob")
+	require.Equal(t, "bob", tn.Owner)
+	require.Equal(t, []string{"carol"}, tn.Collaborators)
+	require.False(t, tn.SharedWith("alice"))
+
+	require.True(t, tn.RemoveCollaborator("carol"))
+	require.False(t, tn.RemoveCollaborator("carol"))
+	require.Nil(t, tn.Collaborators)
+
+	clone := (&Tenant{Collaborators: []string{"dave"}}).Clone()
+	clone.Collaborators[0] = "erin"
+	require.True(t, clone.HasCollaborator("erin"))
+}
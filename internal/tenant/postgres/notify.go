@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// tenantChangesChannel is the channel the tenants table trigger notifies with the ID of each
+// updated or deleted tenant
+const tenantChangesChannel = "tenant_changes"
+
+// ListenTenantChanges calls changed with the ID of every tenant updated or deleted by any process
+// until ctx is cancelled or the connection fails. It holds a dedicated connection while it listens.
+func (r *Repository) ListenTenantChanges(ctx context.Context, changed func(id uuid.UUID)) error {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer func() {
+		// Unlisten before the connection goes back to the pool; ctx is usually cancelled by now
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(cleanupCtx, "UNLISTEN *"); err != nil {
+			_ = conn.Conn().Close(cleanupCtx)
+		}
+		conn.Release()
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+tenantChangesChannel); err != nil {
+		return fmt.Errorf("listen for tenant changes: %w", err)
+	}
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("wait for tenant change: %w", err)
+		}
+		id, err := uuid.Parse(notification.Payload)
+		if err != nil {
+			r.logger.Warn("ignoring tenant change with invalid id", zap.String("payload", notification.Payload))
+			continue
+		}
+		changed(id)
+	}
+}
@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+var (
+	_ tenant.Outbox          = (*Repository)(nil)
+	_ tenant.OutboxCollector = (*Repository)(nil)
+)
+
+const insertOutboxEntryQuery = `
+INSERT INTO tenant_outbox (id, tenant_id, kind, dedup_key, payload)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (dedup_key) DO NOTHING
+RETURNING created_at
+`
+
+func (r *Repository) UpdateTenantWithOutbox(ctx context.Context, t *tenant.Tenant, entries ...*tenant.OutboxEntry) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var newVersion int
+	var updatedAt time.Time
+	if err := tx.QueryRow(ctx, updateTenantQuery, updateTenantArgs(t)...).Scan(&newVersion, &updatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return tenant.ErrTenantExists
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, getErr := r.GetTenantByID(ctx, t.ID); getErr != nil {
+				return tenant.ErrTenantNotFound
+			}
+			return tenant.ErrVersionConflict
+		}
+		return fmt.Errorf("update tenant: %w", err)
+	}
+
+	for _, e := range entries {
+		if e.ID == uuid.Nil {
+			e.ID = uuid.New()
+		}
+		err := tx.QueryRow(ctx, insertOutboxEntryQuery, e.ID, e.TenantID, e.Kind, e.DedupKey, jsonbOrEmptyInterfaceMap(e.Payload)).Scan(&e.CreatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			r.logger.Debug("outbox entry already recorded", zap.String("dedup_key", e.DedupKey))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("insert outbox entry: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tenant update: %w", err)
+	}
+	t.Version = newVersion
+	t.UpdatedAt = updatedAt
+
+	r.logger.Info("tenant updated with outbox entries",
+		zap.String("id", t.ID.String()),
+		zap.Int("new_version", t.Version),
+		zap.Int("entries", len(entries)))
+
+	return nil
+}
+
+// claimOutboxEntriesQuery claims the oldest due entries. SKIP LOCKED lets concurrent dispatchers
+// pass over entries another dispatcher is claiming instead of waiting on them.
+const claimOutboxEntriesQuery = `
+UPDATE tenant_outbox
+SET attempts = attempts + 1,
+    claim_token = gen_random_uuid(),
+    claimed_until = CURRENT_TIMESTAMP + $2 * interval '1 millisecond'
+WHERE id IN (
+    SELECT id FROM tenant_outbox
+    WHERE published_at IS NULL
+      AND attempts < $3
+      AND next_attempt_at <= CURRENT_TIMESTAMP
+      AND (claimed_until IS NULL OR claimed_until < CURRENT_TIMESTAMP)
+    ORDER BY created_at
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, tenant_id, kind, dedup_key, payload, attempts, COALESCE(last_error, ''), claim_token, created_at
+`
+
+func (r *Repository) ClaimOutboxEntries(ctx context.Context, limit int, claimTimeout time.Duration, maxAttempts int) ([]*tenant.OutboxEntry, error) {
+	rows, err := r.pool.Query(ctx, claimOutboxEntriesQuery, limit, claimTimeout.Milliseconds(), maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox entries: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*tenant.OutboxEntry
+	for rows.Next() {
+		var e tenant.OutboxEntry
+		var payload []byte
+		var token uuid.UUID
+		if err := rows.Scan(&e.ID, &e.TenantID, &e.Kind, &e.DedupKey, &payload, &e.Attempts, &e.LastError, &token, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan outbox entry: %w", err)
+		}
+		if err := json.Unmarshal(payload, &e.Payload); err != nil {
+			return nil, fmt.Errorf("decode outbox payload: %w", err)
+		}
+		e.ClaimToken = token.String()
+		entries = append(entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claim outbox entries: %w", err)
+	}
+	return entries, nil
+}
+
+const completeOutboxEntryQuery = `
+UPDATE tenant_outbox
+SET published_at = CURRENT_TIMESTAMP, claim_token = NULL, claimed_until = NULL
+WHERE id = $1 AND claim_token = $2 AND claimed_until >= CURRENT_TIMESTAMP
+`
+
+func (r *Repository) CompleteOutboxEntry(ctx context.Context, id uuid.UUID, claimToken string) error {
+	token, err := uuid.Parse(claimToken)
+	if err != nil {
+		return tenant.ErrOutboxEntryNotClaimed
+	}
+	tag, err := r.pool.Exec(ctx, completeOutboxEntryQuery, id, token)
+	if err != nil {
+		return fmt.Errorf("complete outbox entry: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return tenant.ErrOutboxEntryNotClaimed
+	}
+	return nil
+}
+
+const failOutboxEntryQuery = `
+UPDATE tenant_outbox
+SET last_error = $3, next_attempt_at = $4, claim_token = NULL, claimed_until = NULL
+WHERE id = $1 AND claim_token = $2 AND claimed_until >= CURRENT_TIMESTAMP
+`
+
+func (r *Repository) FailOutboxEntry(ctx context.Context, id uuid.UUID, claimToken, lastError string, retryAt time.Time) error {
+	token, err := uuid.Parse(claimToken)
+	if err != nil {
+		return tenant.ErrOutboxEntryNotClaimed
+	}
+	tag, err := r.pool.Exec(ctx, failOutboxEntryQuery, id, token, lastError, retryAt)
+	if err != nil {
+		return fmt.Errorf("fail outbox entry: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return tenant.ErrOutboxEntryNotClaimed
+	}
+	return nil
+}
+
+// deletePublishedOutboxEntriesQuery deletes one batch of the oldest published entries. SKIP LOCKED
+// passes over rows another transaction holds instead of waiting on them.
+const deletePublishedOutboxEntriesQuery = `
+DELETE FROM tenant_outbox
+WHERE id IN (
+    SELECT id FROM tenant_outbox
+    WHERE published_at < $1
+    ORDER BY published_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+`
+
+func (r *Repository) DeletePublishedOutboxEntries(ctx context.Context, before time.Time, limit int) (int, error) {
+	tag, err := r.pool.Exec(ctx, deletePublishedOutboxEntriesQuery, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete published outbox entries: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+const deleteFailedOutboxEntriesQuery = `
+DELETE FROM tenant_outbox
+WHERE id IN (
+    SELECT id FROM tenant_outbox
+    WHERE published_at IS NULL
+      AND attempts >= $2
+      AND created_at < $1
+      AND (claimed_until IS NULL OR claimed_until < CURRENT_TIMESTAMP)
+    ORDER BY created_at
+    LIMIT $3
+    FOR UPDATE SKIP LOCKED
+)
+`
+
+func (r *Repository) DeleteFailedOutboxEntries(ctx context.Context, before time.Time, maxAttempts, limit int) (int, error) {
+	tag, err := r.pool.Exec(ctx, deleteFailedOutboxEntriesQuery, before, maxAttempts, limit)
+	if err != nil {
+		return 0, fmt.Errorf("delete failed outbox entries: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
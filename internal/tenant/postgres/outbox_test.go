@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+func TestRepository_UpdateTenantWithOutbox(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	tn := createTestTenant(t, "outbox-tenant")
+	if err := repo.CreateTenant(ctx, tn); err != nil {
+		t.Fatalf("CreateTenant() error = %v", err)
+	}
+
+	stale := tn.Clone()
+	tn.Status = tenant.StatusPlanning
+	entry := &tenant.OutboxEntry{TenantID: tn.ID, Kind: "workflow.trigger", DedupKey: "trigger-1", Payload: map[string]interface{}{"action": "plan"}}
+	if err := repo.UpdateTenantWithOutbox(ctx, tn, entry); err != nil {
+		t.Fatalf("UpdateTenantWithOutbox() error = %v", err)
+	}
+
+	// A duplicate key is skipped and a stale write adds nothing
+	duplicate := &tenant.OutboxEntry{TenantID: tn.ID, Kind: "workflow.trigger", DedupKey: "trigger-1"}
+	if err := repo.UpdateTenantWithOutbox(ctx, tn, duplicate); err != nil {
+		t.Fatalf("UpdateTenantWithOutbox() duplicate error = %v", err)
+	}
+	rejected := &tenant.OutboxEntry{TenantID: tn.ID, Kind: "workflow.trigger", DedupKey: "trigger-2"}
+	if err := repo.UpdateTenantWithOutbox(ctx, stale, rejected); err != tenant.ErrVersionConflict {
+		t.Errorf("UpdateTenantWithOutbox() stale error = %v, want %v", err, tenant.ErrVersionConflict)
+	}
+
+	claimed, err := repo.ClaimOutboxEntries(ctx, 10, time.Minute, 5)
+	if err != nil {
+		t.Fatalf("ClaimOutboxEntries() error = %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].DedupKey != "trigger-1" || claimed[0].Payload["action"] != "plan" {
+		t.Fatalf("ClaimOutboxEntries() = %+v, want the trigger-1 entry", claimed)
+	}
+
+	// A claimed entry is not claimed again until it fails or its claim expires
+	again, err := repo.ClaimOutboxEntries(ctx, 10, time.Minute, 5)
+	if err != nil {
+		t.Fatalf("ClaimOutboxEntries() error = %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("ClaimOutboxEntries() claimed %d entries twice", len(again))
+	}
+
+	if err := repo.FailOutboxEntry(ctx, claimed[0].ID, claimed[0].ClaimToken, "engine down", time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("FailOutboxEntry() error = %v", err)
+	}
+	retried, err := repo.ClaimOutboxEntries(ctx, 10, time.Minute, 5)
+	if err != nil {
+		t.Fatalf("ClaimOutboxEntries() error = %v", err)
+	}
+	if len(retried) != 1 || retried[0].Attempts != 2 || retried[0].LastError != "engine down" {
+		t.Fatalf("ClaimOutboxEntries() after failure = %+v", retried)
+	}
+	if err := repo.CompleteOutboxEntry(ctx, retried[0].ID, claimed[0].ClaimToken); err != tenant.ErrOutboxEntryNotClaimed {
+		t.Errorf("CompleteOutboxEntry() with old claim error = %v, want %v", err, tenant.ErrOutboxEntryNotClaimed)
+	}
+	if err := repo.CompleteOutboxEntry(ctx, retried[0].ID, retried[0].ClaimToken); err != nil {
+		t.Fatalf("CompleteOutboxEntry() error = %v", err)
+	}
+}
+
+func TestRepository_DeleteOutboxEntries(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	tn := createTestTenant(t, "outbox-gc-tenant")
+	if err := repo.CreateTenant(ctx, tn); err != nil {
+		t.Fatalf("CreateTenant() error = %v", err)
+	}
+	entries := []*tenant.OutboxEntry{
+		{TenantID: tn.ID, Kind: "workflow.trigger", DedupKey: "published"},
+		{TenantID: tn.ID, Kind: "workflow.trigger", DedupKey: "failed"},
+	}
+	if err := repo.UpdateTenantWithOutbox(ctx, tn, entries...); err != nil {
+		t.Fatalf("UpdateTenantWithOutbox() error = %v", err)
+	}
+	claimed, err := repo.ClaimOutboxEntries(ctx, 10, time.Minute, 1)
+	if err != nil {
+		t.Fatalf("ClaimOutboxEntries() error = %v", err)
+	}
+	if err := repo.UpdateTenantWithOutbox(ctx, tn, &tenant.OutboxEntry{TenantID: tn.ID, Kind: "workflow.trigger", DedupKey: "pending"}); err != nil {
+		t.Fatalf("UpdateTenantWithOutbox() error = %v", err)
+	}
+	for _, e := range claimed {
+		if e.DedupKey == "published" {
+			err = repo.CompleteOutboxEntry(ctx, e.ID, e.ClaimToken)
+		} else {
+			err = repo.FailOutboxEntry(ctx, e.ID, e.ClaimToken, "engine down", time.Now())
+		}
+		if err != nil {
+			t.Fatalf("finish outbox entry %s: %v", e.DedupKey, err)
+		}
+	}
+
+	before := time.Now().Add(time.Minute)
+	deleted, err := repo.DeletePublishedOutboxEntries(ctx, before, 10)
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeletePublishedOutboxEntries() = %d, %v, want 1", deleted, err)
+	}
+	deleted, err = repo.DeleteFailedOutboxEntries(ctx, before, 1, 10)
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteFailedOutboxEntries() = %d, %v, want 1", deleted, err)
+	}
+
+	// The pending entry has publishes left, so it is still claimed
+	remaining, err := repo.ClaimOutboxEntries(ctx, 10, time.Minute, 1)
+	if err != nil {
+		t.Fatalf("ClaimOutboxEntries() error = %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].DedupKey != "pending" {
+		t.Fatalf("ClaimOutboxEntries() after delete = %+v, want the pending entry", remaining)
+	}
+}
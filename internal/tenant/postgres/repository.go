@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -38,9 +40,11 @@ const createTenantQuery = `
 INSERT INTO tenants (
     id, name, status, status_message,
     desired_config,
-    labels, annotations, workflow_config_hash
+    labels, annotations, workflow_config_hash,
+    managed_fields, project_id, conditions, finalizers,
+    owner, collaborators
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 )
 RETURNING created_at, updated_at, version
 `
@@ -50,6 +54,9 @@ func (r *Repository) CreateTenant(ctx context.Context, t *tenant.Tenant) error {
 	if t.ID == uuid.Nil {
 		t.ID = uuid.New()
 	}
+	if t.ProjectID == uuid.Nil {
+		t.ProjectID = tenant.DefaultProjectID
+	}
 
 	r.logger.Debug("creating tenant",
 		zap.String("name", t.Name),
@@ -65,6 +72,12 @@ func (r *Repository) CreateTenant(ctx context.Context, t *tenant.Tenant) error {
 		jsonbOrEmptyStringMap(t.Labels),
 		jsonbOrEmptyStringMap(t.Annotations),
 		t.WorkflowConfigHash,
+		jsonbOrEmptyManagedFields(t.ManagedFields),
+		t.ProjectID,
+		jsonbOrEmptyConditions(t.Conditions),
+		jsonbOrEmptyStrings(t.Finalizers),
+		t.Owner,
+		jsonbOrEmptyStrings(t.Collaborators),
 	)
 
 	err := row.Scan(&t.CreatedAt, &t.UpdatedAt, &t.Version)
@@ -82,45 +95,28 @@ func (r *Repository) CreateTenant(ctx context.Context, t *tenant.Tenant) error {
 	return nil
 }
 
-const getTenantQuery = `
-SELECT
+// tenantColumns is the column list shared by every tenant SELECT; keep in sync with scanTenant
+const tenantColumns = `
     id, name, status, status_message,
-    desired_config,
+    desired_config, managed_fields,
     observed_config, observed_resource_ids,
     created_at, updated_at,
 	version, labels, annotations, workflow_execution_id,
 	workflow_sub_state, workflow_retry_count, workflow_error_message,
-	workflow_config_hash
-FROM tenants
-WHERE name = $1
+	workflow_config_hash, workflow_version, project_id,
+	conditions, workflow_started_at, finalizers, last_action,
+	owner, collaborators
 `
 
-func (r *Repository) GetTenantByName(ctx context.Context, name string) (*tenant.Tenant, error) {
-	r.logger.Debug("getting tenant", zap.String("name", name))
+// summaryColumns is the column list selected for tenant summaries; keep in sync with scanSummary
+const summaryColumns = `id, name, status, labels, updated_at`
 
-	t := &tenant.Tenant{}
-	var desiredConfigJSON, observedConfigJSON, observedResourceIDsJSON, labelsJSON, annotationsJSON []byte
+const getTenantQuery = `SELECT ` + tenantColumns + ` FROM tenants WHERE name = $1`
 
-	err := r.pool.QueryRow(ctx, getTenantQuery, name).Scan(
-		&t.ID,
-		&t.Name,
-		&t.Status,
-		&t.StatusMessage,
-		&desiredConfigJSON,
-		&observedConfigJSON,
-		&observedResourceIDsJSON,
-		&t.CreatedAt,
-		&t.UpdatedAt,
-		&t.Version,
-		&labelsJSON,
-		&annotationsJSON,
-		&t.WorkflowExecutionID,
-		&t.WorkflowSubState,
-		&t.WorkflowRetryCount,
-		&t.WorkflowErrorMessage,
-		&t.WorkflowConfigHash,
-	)
+func (r *Repository) GetTenantByName(ctx context.Context, name string) (*tenant.Tenant, error) {
+	r.logger.Debug("getting tenant", zap.String("name", name))
 
+	t, err := scanTenant(r.pool.QueryRow(ctx, getTenantQuery, name))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, tenant.ErrTenantNotFound
@@ -128,65 +124,15 @@ func (r *Repository) GetTenantByName(ctx context.Context, name string) (*tenant.
 		return nil, fmt.Errorf("get tenant: %w", err)
 	}
 
-	// Unmarshal JSONB fields
-	if err := unmarshalInterfaceMap(desiredConfigJSON, &t.DesiredConfig); err != nil {
-		return nil, fmt.Errorf("unmarshal desired_config: %w", err)
-	}
-	if err := unmarshalInterfaceMap(observedConfigJSON, &t.ObservedConfig); err != nil {
-		return nil, fmt.Errorf("unmarshal observed_config: %w", err)
-	}
-	if err := unmarshalStringMap(observedResourceIDsJSON, &t.ObservedResourceIDs); err != nil {
-		return nil, fmt.Errorf("unmarshal observed_resource_ids: %w", err)
-	}
-	if err := unmarshalStringMap(labelsJSON, &t.Labels); err != nil {
-		return nil, fmt.Errorf("unmarshal labels: %w", err)
-	}
-	if err := unmarshalStringMap(annotationsJSON, &t.Annotations); err != nil {
-		return nil, fmt.Errorf("unmarshal annotations: %w", err)
-	}
-
 	return t, nil
 }
 
-const getTenantByIDQuery = `
-SELECT
-    id, name, status, status_message,
-    desired_config,
-    observed_config, observed_resource_ids,
-    created_at, updated_at,
-	version, labels, annotations, workflow_execution_id,
-	workflow_sub_state, workflow_retry_count, workflow_error_message,
-	workflow_config_hash
-FROM tenants
-WHERE id = $1
-`
+const getTenantByIDQuery = `SELECT ` + tenantColumns + ` FROM tenants WHERE id = $1`
 
 func (r *Repository) GetTenantByID(ctx context.Context, id uuid.UUID) (*tenant.Tenant, error) {
 	r.logger.Debug("getting tenant by ID", zap.String("id", id.String()))
 
-	t := &tenant.Tenant{}
-	var desiredConfigJSON, observedConfigJSON, observedResourceIDsJSON, labelsJSON, annotationsJSON []byte
-
-	err := r.pool.QueryRow(ctx, getTenantByIDQuery, id).Scan(
-		&t.ID,
-		&t.Name,
-		&t.Status,
-		&t.StatusMessage,
-		&desiredConfigJSON,
-		&observedConfigJSON,
-		&observedResourceIDsJSON,
-		&t.CreatedAt,
-		&t.UpdatedAt,
-		&t.Version,
-		&labelsJSON,
-		&annotationsJSON,
-		&t.WorkflowExecutionID,
-		&t.WorkflowSubState,
-		&t.WorkflowRetryCount,
-		&t.WorkflowErrorMessage,
-		&t.WorkflowConfigHash,
-	)
-
+	t, err := scanTenant(r.pool.QueryRow(ctx, getTenantByIDQuery, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, tenant.ErrTenantNotFound
@@ -194,23 +140,6 @@ func (r *Repository) GetTenantByID(ctx context.Context, id uuid.UUID) (*tenant.T
 		return nil, fmt.Errorf("get tenant by ID: %w", err)
 	}
 
-	// Unmarshal JSONB fields
-	if err := unmarshalInterfaceMap(desiredConfigJSON, &t.DesiredConfig); err != nil {
-		return nil, fmt.Errorf("unmarshal desired_config: %w", err)
-	}
-	if err := unmarshalInterfaceMap(observedConfigJSON, &t.ObservedConfig); err != nil {
-		return nil, fmt.Errorf("unmarshal observed_config: %w", err)
-	}
-	if err := unmarshalStringMap(observedResourceIDsJSON, &t.ObservedResourceIDs); err != nil {
-		return nil, fmt.Errorf("unmarshal observed_resource_ids: %w", err)
-	}
-	if err := unmarshalStringMap(labelsJSON, &t.Labels); err != nil {
-		return nil, fmt.Errorf("unmarshal labels: %w", err)
-	}
-	if err := unmarshalStringMap(annotationsJSON, &t.Annotations); err != nil {
-		return nil, fmt.Errorf("unmarshal annotations: %w", err)
-	}
-
 	return t, nil
 }
 
@@ -230,7 +159,15 @@ UPDATE tenants SET
 	workflow_sub_state = $11,
 	workflow_retry_count = $12,
 	workflow_error_message = $13,
-	workflow_config_hash = $15
+	workflow_config_hash = $15,
+	managed_fields = $16,
+	workflow_version = $17,
+	conditions = $18,
+	workflow_started_at = $19,
+	finalizers = $20,
+	last_action = $21,
+	owner = $22,
+	collaborators = $23
 WHERE id = $1 AND version = $14
 RETURNING version, updated_at
 `
@@ -240,7 +177,35 @@ func (r *Repository) UpdateTenant(ctx context.Context, t *tenant.Tenant) error {
 		zap.String("id", t.ID.String()),
 		zap.Int("version", t.Version))
 
-	row := r.pool.QueryRow(ctx, updateTenantQuery,
+	row := r.pool.QueryRow(ctx, updateTenantQuery, updateTenantArgs(t)...)
+
+	err := row.Scan(&t.Version, &t.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return tenant.ErrTenantExists
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Either tenant doesn't exist or version mismatch
+			// Check which one
+			_, getErr := r.GetTenantByID(ctx, t.ID)
+			if getErr != nil {
+				return tenant.ErrTenantNotFound
+			}
+			return tenant.ErrVersionConflict
+		}
+		return fmt.Errorf("update tenant: %w", err)
+	}
+
+	r.logger.Info("tenant updated",
+		zap.String("id", t.ID.String()),
+		zap.Int("new_version", t.Version))
+
+	return nil
+}
+
+// updateTenantArgs returns the parameters of updateTenantQuery for t
+func updateTenantArgs(t *tenant.Tenant) []any {
+	return []any{
 		t.ID,
 		t.Name,
 		t.Status,
@@ -256,34 +221,116 @@ func (r *Repository) UpdateTenant(ctx context.Context, t *tenant.Tenant) error {
 		t.WorkflowErrorMessage,
 		t.Version, // Optimistic locking check
 		t.WorkflowConfigHash,
-	)
+		jsonbOrEmptyManagedFields(t.ManagedFields),
+		t.WorkflowVersion,
+		jsonbOrEmptyConditions(t.Conditions),
+		t.WorkflowStartedAt,
+		jsonbOrEmptyStrings(t.Finalizers),
+		t.LastAction,
+		t.Owner,
+		jsonbOrEmptyStrings(t.Collaborators),
+	}
+}
 
-	err := row.Scan(&t.Version, &t.UpdatedAt)
+// claimWorkflowTriggerQuery takes a transaction-scoped advisory lock on the tenant without waiting.
+// The lock is released when the transaction commits or rolls back, so a crashed worker never leaves it held.
+const claimWorkflowTriggerQuery = `SELECT pg_try_advisory_xact_lock(hashtext('landlord.workflow_trigger'), hashtext($1::text))`
+
+// lockTenantQuery locks the tenant row so no other update can land between the trigger and the write
+const lockTenantQuery = `SELECT version FROM tenants WHERE id = $1 FOR UPDATE`
+
+func (r *Repository) FenceWorkflowTrigger(ctx context.Context, t *tenant.Tenant, trigger func(ctx context.Context, t *tenant.Tenant) error) error {
+	tx, err := r.pool.Begin(ctx)
 	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var claimed bool
+	if err := tx.QueryRow(ctx, claimWorkflowTriggerQuery, t.ID.String()).Scan(&claimed); err != nil {
+		return fmt.Errorf("claim workflow trigger lock: %w", err)
+	}
+	if !claimed {
+		return tenant.ErrWorkflowTriggerLocked
+	}
+
+	var version int
+	if err := tx.QueryRow(ctx, lockTenantQuery, t.ID).Scan(&version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tenant.ErrTenantNotFound
+		}
+		return fmt.Errorf("lock tenant: %w", err)
+	}
+	if version != t.Version {
+		// Another worker triggered (or someone updated the tenant) since it was read
+		return tenant.ErrVersionConflict
+	}
+
+	if err := trigger(ctx, t); err != nil {
+		return err
+	}
+
+	var newVersion int
+	var updatedAt time.Time
+	if err := tx.QueryRow(ctx, updateTenantQuery, updateTenantArgs(t)...).Scan(&newVersion, &updatedAt); err != nil {
 		if isUniqueViolation(err) {
 			return tenant.ErrTenantExists
 		}
-		if errors.Is(err, pgx.ErrNoRows) {
-			// Either tenant doesn't exist or version mismatch
-			// Check which one
-			_, getErr := r.GetTenantByID(ctx, t.ID)
-			if getErr != nil {
-				return tenant.ErrTenantNotFound
-			}
-			return tenant.ErrVersionConflict
-		}
 		return fmt.Errorf("update tenant: %w", err)
 	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit workflow trigger: %w", err)
+	}
+	t.Version = newVersion
+	t.UpdatedAt = updatedAt
 
-	r.logger.Info("tenant updated",
+	r.logger.Info("tenant updated with fenced workflow trigger",
 		zap.String("id", t.ID.String()),
 		zap.Int("new_version", t.Version))
 
 	return nil
 }
 
+// lockTenantMutationQuery takes a session-level advisory lock on the tenant without waiting.
+// It is held on a dedicated connection, so a crashed server releases it when the connection closes.
+const lockTenantMutationQuery = `SELECT pg_try_advisory_lock(hashtext('landlord.tenant_mutation'), hashtext($1::text))`
+
+const unlockTenantMutationQuery = `SELECT pg_advisory_unlock(hashtext('landlord.tenant_mutation'), hashtext($1::text))`
+
+func (r *Repository) LockTenant(ctx context.Context, id uuid.UUID) (func(), error) {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("acquire connection: %w", err)
+	}
+
+	var locked bool
+	if err := conn.QueryRow(ctx, lockTenantMutationQuery, id.String()).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, fmt.Errorf("lock tenant: %w", err)
+	}
+	if !locked {
+		conn.Release()
+		return nil, tenant.ErrTenantLocked
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			// The request context may already be cancelled; unlocking must still happen
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if _, err := conn.Exec(ctx, unlockTenantMutationQuery, id.String()); err != nil {
+				r.logger.Warn("failed to unlock tenant, closing connection", zap.String("id", id.String()), zap.Error(err))
+				// Closing the session releases its advisory locks
+				_ = conn.Conn().Close(ctx)
+			}
+			conn.Release()
+		})
+	}, nil
+}
+
 func (r *Repository) ListTenants(ctx context.Context, filters tenant.ListFilters) ([]*tenant.Tenant, error) {
-	query, args := r.buildListQuery(filters)
+	query, args := r.buildListQuery(tenantColumns, filters)
 
 	r.logger.Debug("listing tenants", zap.Any("filters", filters))
 
@@ -295,42 +342,10 @@ func (r *Repository) ListTenants(ctx context.Context, filters tenant.ListFilters
 
 	var tenants []*tenant.Tenant
 	for rows.Next() {
-		t := &tenant.Tenant{}
-		var desiredConfigJSON, observedConfigJSON, observedResourceIDsJSON, labelsJSON, annotationsJSON []byte
-
-		err := rows.Scan(
-			&t.ID, &t.Name, &t.Status, &t.StatusMessage,
-			&desiredConfigJSON,
-			&observedConfigJSON, &observedResourceIDsJSON,
-			&t.CreatedAt, &t.UpdatedAt,
-			&t.Version, &labelsJSON, &annotationsJSON,
-			&t.WorkflowExecutionID,
-			&t.WorkflowSubState,
-			&t.WorkflowRetryCount,
-			&t.WorkflowErrorMessage,
-			&t.WorkflowConfigHash,
-		)
+		t, err := scanTenant(rows)
 		if err != nil {
-			return nil, fmt.Errorf("scan tenant: %w", err)
+			return nil, err
 		}
-
-		// Unmarshal JSONB fields
-		if err := unmarshalInterfaceMap(desiredConfigJSON, &t.DesiredConfig); err != nil {
-			return nil, fmt.Errorf("unmarshal desired_config: %w", err)
-		}
-		if err := unmarshalInterfaceMap(observedConfigJSON, &t.ObservedConfig); err != nil {
-			return nil, fmt.Errorf("unmarshal observed_config: %w", err)
-		}
-		if err := unmarshalStringMap(observedResourceIDsJSON, &t.ObservedResourceIDs); err != nil {
-			return nil, fmt.Errorf("unmarshal observed_resource_ids: %w", err)
-		}
-		if err := unmarshalStringMap(labelsJSON, &t.Labels); err != nil {
-			return nil, fmt.Errorf("unmarshal labels: %w", err)
-		}
-		if err := unmarshalStringMap(annotationsJSON, &t.Annotations); err != nil {
-			return nil, fmt.Errorf("unmarshal annotations: %w", err)
-		}
-
 		tenants = append(tenants, t)
 	}
 
@@ -341,16 +356,34 @@ func (r *Repository) ListTenants(ctx context.Context, filters tenant.ListFilters
 	return tenants, nil
 }
 
-const listTenantsForReconciliationQuery = `
-SELECT
-    id, name, status, status_message,
-    desired_config,
-    observed_config, observed_resource_ids,
-    created_at, updated_at,
-	version, labels, annotations, workflow_execution_id,
-	workflow_sub_state, workflow_retry_count, workflow_error_message,
-	workflow_config_hash
-FROM tenants
+func (r *Repository) ListTenantSummaries(ctx context.Context, filters tenant.ListFilters) ([]*tenant.Summary, error) {
+	query, args := r.buildListQuery(summaryColumns, filters)
+
+	r.logger.Debug("listing tenant summaries", zap.Any("filters", filters))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list tenant summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []*tenant.Summary
+	for rows.Next() {
+		s, err := scanSummary(rows)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tenant summaries: %w", err)
+	}
+
+	return summaries, nil
+}
+
+const listTenantsForReconciliationQuery = `SELECT ` + tenantColumns + ` FROM tenants
 WHERE status IN ('requested', 'planning', 'provisioning', 'updating', 'deleting', 'archiving')
 ORDER BY created_at ASC
 `
@@ -366,42 +399,10 @@ func (r *Repository) ListTenantsForReconciliation(ctx context.Context) ([]*tenan
 
 	var tenants []*tenant.Tenant
 	for rows.Next() {
-		t := &tenant.Tenant{}
-		var desiredConfigJSON, observedConfigJSON, observedResourceIDsJSON, labelsJSON, annotationsJSON []byte
-
-		err := rows.Scan(
-			&t.ID, &t.Name, &t.Status, &t.StatusMessage,
-			&desiredConfigJSON,
-			&observedConfigJSON, &observedResourceIDsJSON,
-			&t.CreatedAt, &t.UpdatedAt,
-			&t.Version, &labelsJSON, &annotationsJSON,
-			&t.WorkflowExecutionID,
-			&t.WorkflowSubState,
-			&t.WorkflowRetryCount,
-			&t.WorkflowErrorMessage,
-			&t.WorkflowConfigHash,
-		)
+		t, err := scanTenant(rows)
 		if err != nil {
-			return nil, fmt.Errorf("scan tenant: %w", err)
-		}
-
-		// Unmarshal JSONB fields
-		if err := unmarshalInterfaceMap(desiredConfigJSON, &t.DesiredConfig); err != nil {
-			return nil, fmt.Errorf("unmarshal desired_config: %w", err)
-		}
-		if err := unmarshalInterfaceMap(observedConfigJSON, &t.ObservedConfig); err != nil {
-			return nil, fmt.Errorf("unmarshal observed_config: %w", err)
-		}
-		if err := unmarshalStringMap(observedResourceIDsJSON, &t.ObservedResourceIDs); err != nil {
-			return nil, fmt.Errorf("unmarshal observed_resource_ids: %w", err)
-		}
-		if err := unmarshalStringMap(labelsJSON, &t.Labels); err != nil {
-			return nil, fmt.Errorf("unmarshal labels: %w", err)
+			return nil, err
 		}
-		if err := unmarshalStringMap(annotationsJSON, &t.Annotations); err != nil {
-			return nil, fmt.Errorf("unmarshal annotations: %w", err)
-		}
-
 		tenants = append(tenants, t)
 	}
 
@@ -413,19 +414,9 @@ func (r *Repository) ListTenantsForReconciliation(ctx context.Context) ([]*tenan
 	return tenants, nil
 }
 
-func (r *Repository) buildListQuery(filters tenant.ListFilters) (string, []interface{}) {
-	query := `
-        SELECT
-            id, name, status, status_message,
-            desired_config,
-            observed_config, observed_resource_ids,
-            created_at, updated_at,
-			version, labels, annotations, workflow_execution_id,
-			workflow_sub_state, workflow_retry_count, workflow_error_message,
-	workflow_config_hash
-        FROM tenants
-        WHERE 1=1
-    `
+// buildListQuery selects columns from the tenants matching filters
+func (r *Repository) buildListQuery(columns string, filters tenant.ListFilters) (string, []interface{}) {
+	query := `SELECT ` + columns + ` FROM tenants WHERE 1=1`
 	args := []interface{}{}
 	argPos := 1
 
@@ -443,6 +434,25 @@ func (r *Repository) buildListQuery(filters tenant.ListFilters) (string, []inter
 		argPos++
 	}
 
+	// Filter by project
+	if len(filters.ProjectIDs) > 0 {
+		query += fmt.Sprintf(" AND project_id = ANY($%d)", argPos)
+		args = append(args, filters.ProjectIDs)
+		argPos++
+	}
+
+	// Filter by label and annotation selectors; containment uses the GIN indexes
+	if len(filters.Labels) > 0 {
+		query += fmt.Sprintf(" AND labels @> $%d::jsonb", argPos)
+		args = append(args, selectorJSON(filters.Labels))
+		argPos++
+	}
+	if len(filters.Annotations) > 0 {
+		query += fmt.Sprintf(" AND annotations @> $%d::jsonb", argPos)
+		args = append(args, selectorJSON(filters.Annotations))
+		argPos++
+	}
+
 	// Filter by created_at range
 	if filters.CreatedAfter != nil {
 		query += fmt.Sprintf(" AND created_at > $%d", argPos)
@@ -520,10 +530,10 @@ func (r *Repository) DeleteTenant(ctx context.Context, id uuid.UUID) error {
 const recordTransitionQuery = `
 INSERT INTO tenant_state_history (
     tenant_id, from_status, to_status,
-    reason, triggered_by,
+    reason, reason_code, triggered_by,
     desired_state_snapshot, observed_state_snapshot
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8
 )
 RETURNING id, created_at
 `
@@ -538,6 +548,7 @@ func (r *Repository) RecordStateTransition(ctx context.Context, st *tenant.State
 		st.FromStatus,
 		st.ToStatus,
 		st.Reason,
+		st.ReasonCode,
 		st.TriggeredBy,
 		jsonbOrEmptyInterfaceMap(st.DesiredStateSnapshot),
 		jsonbOrEmptyInterfaceMap(st.ObservedStateSnapshot),
@@ -551,21 +562,17 @@ func (r *Repository) RecordStateTransition(ctx context.Context, st *tenant.State
 	return nil
 }
 
-const getHistoryQuery = `
-SELECT
+const historyColumns = `
     id, tenant_id, from_status, to_status,
-    reason, triggered_by,
+    reason, reason_code, triggered_by,
     desired_state_snapshot, observed_state_snapshot,
-    created_at
-FROM tenant_state_history
-WHERE tenant_id = $1
-ORDER BY created_at DESC
-`
+    created_at, scrubbed_at`
 
-func (r *Repository) GetStateHistory(ctx context.Context, tenantID uuid.UUID) ([]*tenant.StateTransition, error) {
-	r.logger.Debug("getting state history", zap.String("tenant_id", tenantID.String()))
+func (r *Repository) GetStateHistory(ctx context.Context, tenantID uuid.UUID, filters tenant.HistoryFilters) ([]*tenant.StateTransition, error) {
+	r.logger.Debug("getting state history", zap.String("tenant_id", tenantID.String()), zap.Any("filters", filters))
 
-	rows, err := r.pool.Query(ctx, getHistoryQuery, tenantID)
+	query, args := buildHistoryQuery(tenantID, filters)
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("get history: %w", err)
 	}
@@ -582,10 +589,12 @@ func (r *Repository) GetStateHistory(ctx context.Context, tenantID uuid.UUID) ([
 			&st.FromStatus,
 			&st.ToStatus,
 			&st.Reason,
+			&st.ReasonCode,
 			&st.TriggeredBy,
 			&desiredSnapshotJSON,
 			&observedSnapshotJSON,
 			&st.CreatedAt,
+			&st.ScrubbedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan transition: %w", err)
@@ -609,6 +618,158 @@ func (r *Repository) GetStateHistory(ctx context.Context, tenantID uuid.UUID) ([
 	return history, nil
 }
 
+// buildHistoryQuery selects the tenant's state transitions matching filters, newest first
+func buildHistoryQuery(tenantID uuid.UUID, filters tenant.HistoryFilters) (string, []interface{}) {
+	query := `SELECT` + historyColumns + `
+FROM tenant_state_history
+WHERE tenant_id = $1`
+	args := []interface{}{tenantID}
+	argPos := 2
+
+	if len(filters.ToStatuses) > 0 {
+		query += fmt.Sprintf(" AND to_status = ANY($%d)", argPos)
+		statusStrings := make([]string, len(filters.ToStatuses))
+		for i, s := range filters.ToStatuses {
+			statusStrings[i] = string(s)
+		}
+		args = append(args, statusStrings)
+		argPos++
+	}
+	if len(filters.ReasonCodes) > 0 {
+		query += fmt.Sprintf(" AND reason_code = ANY($%d)", argPos)
+		codeStrings := make([]string, len(filters.ReasonCodes))
+		for i, code := range filters.ReasonCodes {
+			codeStrings[i] = string(code)
+		}
+		args = append(args, codeStrings)
+		argPos++
+	}
+
+	// Filter by created_at range
+	if filters.CreatedAfter != nil {
+		query += fmt.Sprintf(" AND created_at > $%d", argPos)
+		args = append(args, *filters.CreatedAfter)
+		argPos++
+	}
+	if filters.CreatedBefore != nil {
+		query += fmt.Sprintf(" AND created_at < $%d", argPos)
+		args = append(args, *filters.CreatedBefore)
+		argPos++
+	}
+
+	// Continue after the cursor; the row comparison uses idx_tenant_state_history_tenant_created
+	if filters.After != nil {
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", argPos, argPos+1)
+		args = append(args, filters.After.CreatedAt, filters.After.ID)
+		argPos += 2
+	}
+
+	query += " ORDER BY created_at DESC, id DESC"
+	if filters.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argPos)
+		args = append(args, filters.Limit)
+	}
+	return query, args
+}
+
+// scanTenant scans a row selected with tenantColumns into a tenant
+func scanTenant(row pgx.Row) (*tenant.Tenant, error) {
+	t := &tenant.Tenant{}
+	var desiredConfigJSON, managedFieldsJSON, observedConfigJSON, observedResourceIDsJSON, labelsJSON, annotationsJSON, conditionsJSON, finalizersJSON, lastActionJSON, collaboratorsJSON []byte
+
+	err := row.Scan(
+		&t.ID,
+		&t.Name,
+		&t.Status,
+		&t.StatusMessage,
+		&desiredConfigJSON,
+		&managedFieldsJSON,
+		&observedConfigJSON,
+		&observedResourceIDsJSON,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&t.Version,
+		&labelsJSON,
+		&annotationsJSON,
+		&t.WorkflowExecutionID,
+		&t.WorkflowSubState,
+		&t.WorkflowRetryCount,
+		&t.WorkflowErrorMessage,
+		&t.WorkflowConfigHash,
+		&t.WorkflowVersion,
+		&t.ProjectID,
+		&conditionsJSON,
+		&t.WorkflowStartedAt,
+		&finalizersJSON,
+		&lastActionJSON,
+		&t.Owner,
+		&collaboratorsJSON,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("scan tenant: %w", err)
+	}
+
+	// Unmarshal JSON fields
+	if err := unmarshalInterfaceMap(desiredConfigJSON, &t.DesiredConfig); err != nil {
+		return nil, fmt.Errorf("unmarshal desired_config: %w", err)
+	}
+	if err := unmarshalManagedFields(managedFieldsJSON, &t.ManagedFields); err != nil {
+		return nil, fmt.Errorf("unmarshal managed_fields: %w", err)
+	}
+	if err := unmarshalInterfaceMap(observedConfigJSON, &t.ObservedConfig); err != nil {
+		return nil, fmt.Errorf("unmarshal observed_config: %w", err)
+	}
+	if err := unmarshalStringMap(observedResourceIDsJSON, &t.ObservedResourceIDs); err != nil {
+		return nil, fmt.Errorf("unmarshal observed_resource_ids: %w", err)
+	}
+	if err := unmarshalStringMap(labelsJSON, &t.Labels); err != nil {
+		return nil, fmt.Errorf("unmarshal labels: %w", err)
+	}
+	if err := unmarshalStringMap(annotationsJSON, &t.Annotations); err != nil {
+		return nil, fmt.Errorf("unmarshal annotations: %w", err)
+	}
+	if err := unmarshalConditions(conditionsJSON, &t.Conditions); err != nil {
+		return nil, fmt.Errorf("unmarshal conditions: %w", err)
+	}
+	if err := unmarshalStrings(finalizersJSON, &t.Finalizers); err != nil {
+		return nil, fmt.Errorf("unmarshal finalizers: %w", err)
+	}
+	if err := unmarshalStrings(collaboratorsJSON, &t.Collaborators); err != nil {
+		return nil, fmt.Errorf("unmarshal collaborators: %w", err)
+	}
+	if len(lastActionJSON) > 0 {
+		if err := json.Unmarshal(lastActionJSON, &t.LastAction); err != nil {
+			return nil, fmt.Errorf("unmarshal last action: %w", err)
+		}
+	}
+
+	return t, nil
+}
+
+// scanSummary scans a row selected with summaryColumns into a tenant summary
+func scanSummary(row pgx.Row) (*tenant.Summary, error) {
+	s := &tenant.Summary{}
+	var labelsJSON []byte
+
+	if err := row.Scan(&s.ID, &s.Name, &s.Status, &labelsJSON, &s.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("scan tenant summary: %w", err)
+	}
+	if err := unmarshalStringMap(labelsJSON, &s.Labels); err != nil {
+		return nil, fmt.Errorf("unmarshal labels: %w", err)
+	}
+
+	return s, nil
+}
+
+// selectorJSON encodes a label or annotation selector for a JSONB containment query
+func selectorJSON(selector map[string]string) string {
+	encoded, _ := json.Marshal(selector)
+	return string(encoded)
+}
+
 // jsonbOrEmpty converts map to JSONB, returns empty object if nil
 func jsonbOrEmptyStringMap(m map[string]string) interface{} {
 	if len(m) == 0 {
@@ -632,6 +793,69 @@ func unmarshalStringMap(data []byte, m *map[string]string) error {
 	return json.Unmarshal(data, m)
 }
 
+func jsonbOrEmptyManagedFields(m map[string]tenant.ManagedField) interface{} {
+	if len(m) == 0 {
+		return "{}"
+	}
+	return m
+}
+
+// unmarshalManagedFields unmarshals JSONB bytes into managed field entries
+func unmarshalManagedFields(data []byte, m *map[string]tenant.ManagedField) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return err
+	}
+	if len(*m) == 0 {
+		*m = nil
+	}
+	return nil
+}
+
+func jsonbOrEmptyConditions(c []tenant.Condition) interface{} {
+	if len(c) == 0 {
+		return "[]"
+	}
+	return c
+}
+
+// unmarshalConditions unmarshals JSONB bytes into tenant conditions
+func unmarshalConditions(data []byte, c *[]tenant.Condition) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return err
+	}
+	if len(*c) == 0 {
+		*c = nil
+	}
+	return nil
+}
+
+func jsonbOrEmptyStrings(f []string) interface{} {
+	if len(f) == 0 {
+		return "[]"
+	}
+	return f
+}
+
+// unmarshalStrings unmarshals JSONB bytes into a string list such as finalizers or collaborators
+func unmarshalStrings(data []byte, f *[]string) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, f); err != nil {
+		return err
+	}
+	if len(*f) == 0 {
+		*f = nil
+	}
+	return nil
+}
+
 // unmarshalInterfaceMap unmarshals JSONB bytes into a map[string]interface{}
 func unmarshalInterfaceMap(data []byte, m *map[string]interface{}) error {
 	if len(data) == 0 {
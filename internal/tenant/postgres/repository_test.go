@@ -346,3 +346,35 @@ func TestRepository_DeleteTenant(t *testing.T) {
 		t.Fatalf("GetTenantByID() after delete error = %v, want %v", err, tenant.ErrTenantNotFound)
 	}
 }
+
+func TestRepository_ListTenantsSelectors(t *testing.T) {
+	repo, cleanup := setupTestRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	platform := createTestTenant(t, "platform-tenant")
+	platform.Labels["team"] = "platform"
+	other := createTestTenant(t, "other-tenant")
+	other.Annotations["owner"] = "someone-else"
+	for _, tn := range []*tenant.Tenant{platform, other} {
+		if err := repo.CreateTenant(ctx, tn); err != nil {
+			t.Fatalf("CreateTenant() error = %v", err)
+		}
+	}
+
+	labelled, err := repo.ListTenants(ctx, tenant.ListFilters{Labels: map[string]string{"env": "test", "team": "platform"}})
+	if err != nil {
+		t.Fatalf("ListTenants(Labels) error = %v", err)
+	}
+	if len(labelled) != 1 || labelled[0].Name != "platform-tenant" {
+		t.Fatalf("ListTenants(Labels) returned %d tenants, want platform-tenant", len(labelled))
+	}
+
+	annotated, err := repo.ListTenantSummaries(ctx, tenant.ListFilters{Annotations: map[string]string{"owner": "test-suite"}})
+	if err != nil {
+		t.Fatalf("ListTenantSummaries(Annotations) error = %v", err)
+	}
+	if len(annotated) != 1 || annotated[0].Name != "platform-tenant" || annotated[0].Labels["team"] != "platform" {
+		t.Fatalf("ListTenantSummaries(Annotations) = %+v, want platform-tenant", annotated)
+	}
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+var _ tenant.HistoryScrubber = (*Repository)(nil)
+
+// scrubBatchSize bounds how many transitions one scrub query reads
+const scrubBatchSize = 500
+
+const selectUnscrubbedHistoryQuery = `
+SELECT id, desired_state_snapshot, observed_state_snapshot
+FROM tenant_state_history
+WHERE created_at < $1 AND scrubbed_at IS NULL
+ORDER BY created_at
+LIMIT $2
+`
+
+const scrubHistoryQuery = `
+UPDATE tenant_state_history
+SET desired_state_snapshot = $2, observed_state_snapshot = $3, scrubbed_at = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+func (r *Repository) ScrubStateHistory(ctx context.Context, cutoff time.Time, scrub func(*tenant.StateTransition)) (int, error) {
+	scrubbed := 0
+	for {
+		batch, err := r.unscrubbedHistory(ctx, cutoff)
+		if err != nil {
+			return scrubbed, err
+		}
+		for _, st := range batch {
+			scrub(st)
+			if _, err := r.pool.Exec(ctx, scrubHistoryQuery, st.ID,
+				jsonbOrEmptyInterfaceMap(st.DesiredStateSnapshot),
+				jsonbOrEmptyInterfaceMap(st.ObservedStateSnapshot),
+			); err != nil {
+				return scrubbed, fmt.Errorf("scrub transition %s: %w", st.ID, err)
+			}
+			scrubbed++
+		}
+		if len(batch) < scrubBatchSize {
+			return scrubbed, nil
+		}
+	}
+}
+
+func (r *Repository) unscrubbedHistory(ctx context.Context, cutoff time.Time) ([]*tenant.StateTransition, error) {
+	rows, err := r.pool.Query(ctx, selectUnscrubbedHistoryQuery, cutoff, scrubBatchSize)
+	if err != nil {
+		return nil, fmt.Errorf("select history to scrub: %w", err)
+	}
+	defer rows.Close()
+
+	var batch []*tenant.StateTransition
+	for rows.Next() {
+		st := &tenant.StateTransition{}
+		var desiredSnapshotJSON, observedSnapshotJSON []byte
+		if err := rows.Scan(&st.ID, &desiredSnapshotJSON, &observedSnapshotJSON); err != nil {
+			return nil, fmt.Errorf("scan transition: %w", err)
+		}
+		if err := unmarshalInterfaceMap(desiredSnapshotJSON, &st.DesiredStateSnapshot); err != nil {
+			return nil, fmt.Errorf("unmarshal desired_state_snapshot: %w", err)
+		}
+		if err := unmarshalInterfaceMap(observedSnapshotJSON, &st.ObservedStateSnapshot); err != nil {
+			return nil, fmt.Errorf("unmarshal observed_state_snapshot: %w", err)
+		}
+		batch = append(batch, st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate history to scrub: %w", err)
+	}
+	return batch, nil
+}
+
+const forgetHistoryQuery = `
+UPDATE tenant_state_history
+SET desired_state_snapshot = '{}', observed_state_snapshot = '{}', scrubbed_at = CURRENT_TIMESTAMP
+WHERE tenant_id = $1
+`
+
+func (r *Repository) ForgetStateHistory(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	tag, err := r.pool.Exec(ctx, forgetHistoryQuery, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("forget history: %w", err)
+	}
+	r.logger.Info("tenant history forgotten", zap.String("tenant_id", tenantID.String()), zap.Int64("transitions", tag.RowsAffected()))
+	return int(tag.RowsAffected()), nil
+}
@@ -0,0 +1,63 @@
+package tenant
+
+import "fmt"
+
+// LabelPriority is the label naming a tenant's priority class
+const LabelPriority = "priority"
+
+// PriorityClass orders tenants for reconciliation and workflow capacity
+type PriorityClass string
+
+const (
+	// PriorityCritical is for tenants whose repairs must never wait, such as production
+	PriorityCritical PriorityClass = "critical"
+
+	PriorityHigh PriorityClass = "high"
+
+	// PriorityDefault is the class of tenants without a priority label
+	PriorityDefault PriorityClass = "default"
+
+	// PriorityBatch is for tenants that can wait, such as preview environments
+	PriorityBatch PriorityClass = "batch"
+)
+
+// PriorityClasses lists every priority class, highest first
+var PriorityClasses = []PriorityClass{PriorityCritical, PriorityHigh, PriorityDefault, PriorityBatch}
+
+// Rank orders priority classes: 0 is the highest. Unknown classes rank with default.
+func (p PriorityClass) Rank() int {
+	for i, class := range PriorityClasses {
+		if class == p {
+			return i
+		}
+	}
+	return PriorityDefault.Rank()
+}
+
+// IsValid reports whether p is a known priority class
+func (p PriorityClass) IsValid() bool {
+	for _, class := range PriorityClasses {
+		if class == p {
+			return true
+		}
+	}
+	return false
+}
+
+// PriorityFromLabels returns the priority class named by labels, or default when none is named
+func PriorityFromLabels(labels map[string]string) (PriorityClass, error) {
+	value, ok := labels[LabelPriority]
+	if !ok {
+		return PriorityDefault, nil
+	}
+	if class := PriorityClass(value); class.IsValid() {
+		return class, nil
+	}
+	return PriorityDefault, fmt.Errorf("invalid %s label %q: must be one of critical, high, default, batch", LabelPriority, value)
+}
+
+// Priority returns the tenant's priority class. A missing or invalid priority label means default.
+func (t *Tenant) Priority() PriorityClass {
+	class, _ := PriorityFromLabels(t.Labels)
+	return class
+}
@@ -0,0 +1,152 @@
+package tenant
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LabelEnvironment is the label naming a tenant's environment, such as "staging" or "prod"
+const LabelEnvironment = "env"
+
+// Annotations linking environments and recording a promotion awaiting approval
+const (
+	// AnnotationPromotesTo on a source tenant names the tenant it is promoted to
+	AnnotationPromotesTo = "landlord/promotes_to"
+
+	// AnnotationPromotionExclude on a target tenant lists compute_config fields, comma separated,
+	// that promotions never overwrite
+	AnnotationPromotionExclude = "landlord/promotion_exclude"
+
+	AnnotationPromotionSource      = "landlord/promotion_source"
+	AnnotationPromotionRequestedBy = "landlord/promotion_requested_by"
+	AnnotationPromotionRequestedAt = "landlord/promotion_requested_at"
+	AnnotationPromotionConfig      = "landlord/promotion_config"
+)
+
+// promotionAlwaysExcluded are fields that describe where a tenant runs rather than what it runs
+var promotionAlwaysExcluded = []string{"compute_provider"}
+
+// Promotion is a change copied from a tenant in another environment, waiting to be approved
+type Promotion struct {
+	// Source is the name of the tenant the change was promoted from
+	Source string `json:"source"`
+
+	// RequestedBy is the actor that requested the promotion
+	RequestedBy string `json:"requested_by,omitempty"`
+
+	// RequestedAt is when the promotion was requested
+	RequestedAt time.Time `json:"requested_at"`
+
+	// Changes are the compute_config fields approval will change
+	Changes []ConfigChange `json:"changes"`
+
+	// Config is the compute_config the tenant will have once approved
+	Config map[string]interface{} `json:"-"`
+}
+
+// ConfigChange is one top-level compute_config field that differs between two configurations
+type ConfigChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from,omitempty"`
+	To    interface{} `json:"to,omitempty"`
+}
+
+// PromotedConfig returns target's configuration with every field of source copied over it,
+// except excluded fields and those describing placement. Fields only target has are kept.
+func PromotedConfig(source, target map[string]interface{}, exclude []string) map[string]interface{} {
+	skip := make(map[string]bool, len(exclude)+len(promotionAlwaysExcluded))
+	for _, field := range exclude {
+		skip[field] = true
+	}
+	for _, field := range promotionAlwaysExcluded {
+		skip[field] = true
+	}
+
+	promoted := make(map[string]interface{}, len(target)+len(source))
+	for key, value := range target {
+		promoted[key] = value
+	}
+	for key, value := range source {
+		if !skip[key] {
+			promoted[key] = value
+		}
+	}
+	return promoted
+}
+
+// DiffConfig lists the top-level fields that differ between from and to, sorted by field
+func DiffConfig(from, to map[string]interface{}) []ConfigChange {
+	var changes []ConfigChange
+	for key, value := range to {
+		if previous, ok := from[key]; !ok || !reflect.DeepEqual(previous, value) {
+			changes = append(changes, ConfigChange{Field: key, From: from[key], To: value})
+		}
+	}
+	for key, value := range from {
+		if _, ok := to[key]; !ok {
+			changes = append(changes, ConfigChange{Field: key, From: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// PromotionExclusions returns the fields listed in the tenant's promotion exclude annotation
+func (t *Tenant) PromotionExclusions() []string {
+	var fields []string
+	for _, field := range strings.Split(t.Annotations[AnnotationPromotionExclude], ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Promotion returns the promotion awaiting approval, or nil if none is recorded
+func (t *Tenant) Promotion() *Promotion {
+	if t.Annotations == nil || t.Annotations[AnnotationPromotionSource] == "" {
+		return nil
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(t.Annotations[AnnotationPromotionConfig]), &config); err != nil {
+		return nil
+	}
+	requestedAt, _ := time.Parse(time.RFC3339, t.Annotations[AnnotationPromotionRequestedAt])
+	return &Promotion{
+		Source:      t.Annotations[AnnotationPromotionSource],
+		RequestedBy: t.Annotations[AnnotationPromotionRequestedBy],
+		RequestedAt: requestedAt,
+		Changes:     DiffConfig(t.DesiredConfig, config),
+		Config:      config,
+	}
+}
+
+// RequestPromotion records config, promoted from source, as awaiting approval
+func (t *Tenant) RequestPromotion(source string, config map[string]interface{}, requestedBy string, now time.Time) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	if t.Annotations == nil {
+		t.Annotations = map[string]string{}
+	}
+	t.Annotations[AnnotationPromotionSource] = source
+	t.Annotations[AnnotationPromotionRequestedBy] = requestedBy
+	t.Annotations[AnnotationPromotionRequestedAt] = now.UTC().Format(time.RFC3339)
+	t.Annotations[AnnotationPromotionConfig] = string(data)
+	return nil
+}
+
+// ClearPromotion removes the promotion record once it is approved or rejected
+func (t *Tenant) ClearPromotion() {
+	delete(t.Annotations, AnnotationPromotionSource)
+	delete(t.Annotations, AnnotationPromotionRequestedBy)
+	delete(t.Annotations, AnnotationPromotionRequestedAt)
+	delete(t.Annotations, AnnotationPromotionConfig)
+	if len(t.Annotations) == 0 {
+		t.Annotations = nil
+	}
+}
@@ -0,0 +1,106 @@
+package tenant
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPromotedConfigCopiesSourceExceptExcludedFields(t *testing.T) {
+	source := map[string]interface{}{
+		"image":            "registry.local/web:1.3",
+		"replicas":         float64(2),
+		"region":           "eu-staging",
+		"compute_provider": "docker",
+	}
+	target := map[string]interface{}{
+		"image":            "registry.local/web:1.2",
+		"replicas":         float64(6),
+		"region":           "eu-prod",
+		"compute_provider": "ecs",
+		"alerts":           "pager",
+	}
+
+	promoted := PromotedConfig(source, target, []string{"region", "replicas"})
+
+	want := map[string]interface{}{
+		"image":            "registry.local/web:1.3",
+		"replicas":         float64(6),
+		"region":           "eu-prod",
+		"compute_provider": "ecs",
+		"alerts":           "pager",
+	}
+	if len(promoted) != len(want) {
+		t.Fatalf("expected %v, got %v", want, promoted)
+	}
+	for key, value := range want {
+		if promoted[key] != value {
+			t.Errorf("%s: expected %v, got %v", key, value, promoted[key])
+		}
+	}
+	if target["image"] != "registry.local/web:1.2" {
+		t.Fatalf("target config was modified: %v", target)
+	}
+}
+
+func TestDiffConfig(t *testing.T) {
+	from := map[string]interface{}{"image": "web:1.2", "replicas": float64(2), "debug": true}
+	to := map[string]interface{}{"image": "web:1.3", "replicas": float64(2), "env": map[string]interface{}{"LOG": "info"}}
+
+	changes := DiffConfig(from, to)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %+v", changes)
+	}
+	if changes[0].Field != "debug" || changes[0].From != true || changes[0].To != nil {
+		t.Errorf("unexpected removal: %+v", changes[0])
+	}
+	if changes[1].Field != "env" || changes[1].From != nil {
+		t.Errorf("unexpected addition: %+v", changes[1])
+	}
+	if changes[2].Field != "image" || changes[2].From != "web:1.2" || changes[2].To != "web:1.3" {
+		t.Errorf("unexpected change: %+v", changes[2])
+	}
+
+	if changes := DiffConfig(to, to); len(changes) != 0 {
+		t.Fatalf("expected no changes for identical configs, got %+v", changes)
+	}
+}
+
+func TestTenantPromotionRecord(t *testing.T) {
+	tn := &Tenant{
+		DesiredConfig: map[string]interface{}{"image": "web:1.2"},
+		Annotations:   map[string]string{AnnotationPromotionExclude: " region, replicas ,"},
+	}
+	if tn.Promotion() != nil {
+		t.Fatalf("expected no promotion before one is requested")
+	}
+	if got := tn.PromotionExclusions(); len(got) != 2 || got[0] != "region" || got[1] != "replicas" {
+		t.Fatalf("unexpected exclusions: %v", got)
+	}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := tn.RequestPromotion("web-staging", map[string]interface{}{"image": "web:1.3"}, "release-bot", now); err != nil {
+		t.Fatalf("request promotion: %v", err)
+	}
+
+	promotion := tn.Promotion()
+	if promotion == nil {
+		t.Fatalf("expected a promotion")
+	}
+	if promotion.Source != "web-staging" || promotion.RequestedBy != "release-bot" || !promotion.RequestedAt.Equal(now) {
+		t.Fatalf("unexpected promotion: %+v", promotion)
+	}
+	if promotion.Config["image"] != "web:1.3" {
+		t.Fatalf("unexpected promoted config: %v", promotion.Config)
+	}
+	if len(promotion.Changes) != 1 || promotion.Changes[0].Field != "image" {
+		t.Fatalf("unexpected changes: %+v", promotion.Changes)
+	}
+
+	tn.ClearPromotion()
+	if tn.Promotion() != nil {
+		t.Fatalf("expected promotion to be cleared")
+	}
+	if tn.Annotations[AnnotationPromotionExclude] == "" {
+		t.Fatalf("clearing the promotion removed unrelated annotations: %v", tn.Annotations)
+	}
+}
@@ -0,0 +1,114 @@
+package tenant
+
+// ReasonCode is a machine-readable CamelCase reason for a state transition or condition. Codes are
+// stable: UIs map them to localized strings and alerting filters on them, while the accompanying
+// message stays free text for humans.
+type ReasonCode string
+
+// Reason codes recorded on state transitions and events
+const (
+	ReasonRetryRequested               ReasonCode = "RetryRequested"
+	ReasonRestarted                    ReasonCode = "Restarted"
+	ReasonManualSuspend                ReasonCode = "ManualSuspend"
+	ReasonManualResume                 ReasonCode = "ManualResume"
+	ReasonEmergencyStop                ReasonCode = "EmergencyStop"
+	ReasonEmergencyDestroy             ReasonCode = "EmergencyDestroy"
+	ReasonCredentialsRotationRequested ReasonCode = "CredentialsRotationRequested"
+	ReasonPromotionRequested           ReasonCode = "PromotionRequested"
+	ReasonPromotionApproved            ReasonCode = "PromotionApproved"
+	ReasonPromotionRejected            ReasonCode = "PromotionRejected"
+	ReasonHistoryForgotten             ReasonCode = "HistoryForgotten"
+	ReasonWarmPoolClaimed              ReasonCode = "WarmPoolClaimed"
+	ReasonWarmPoolRemoved              ReasonCode = "WarmPoolRemoved"
+	ReasonWarmPoolOversized            ReasonCode = "WarmPoolOversized"
+	ReasonWarmPoolTemplateChanged      ReasonCode = "WarmPoolTemplateChanged"
+	ReasonWarmTenantFailed             ReasonCode = "WarmTenantFailed"
+	ReasonImageUpdated                 ReasonCode = "ImageUpdated"
+	ReasonEgressDenied                 ReasonCode = "EgressDenied"
+	ReasonHookSucceeded                ReasonCode = "HookSucceeded"
+	ReasonHookFailed                   ReasonCode = "HookFailed"
+	ReasonFinalizerRemoved             ReasonCode = "FinalizerRemoved"
+	ReasonOwnershipTransferred         ReasonCode = "OwnershipTransferred"
+	ReasonTenantShared                 ReasonCode = "TenantShared"
+	ReasonTenantUnshared               ReasonCode = "TenantUnshared"
+)
+
+// Reason codes set on conditions. Conditions recorded as events, such as the EndpointUnhealthy
+// condition, use the same code on the event.
+const (
+	ReasonScheduledSuspend     ReasonCode = "ScheduledSuspend"
+	ReasonScheduledResume      ReasonCode = "ScheduledResume"
+	ReasonWorkflowTimeout      ReasonCode = "WorkflowTimeout"
+	ReasonWorkflowSucceeded    ReasonCode = "WorkflowSucceeded"
+	ReasonPolicyCompliant      ReasonCode = "Compliant"
+	ReasonPolicyViolation      ReasonCode = "PolicyViolation"
+	ReasonWithinThreshold      ReasonCode = "WithinThreshold"
+	ReasonThresholdExceeded    ReasonCode = "ThresholdExceeded"
+	ReasonProbeSucceeded       ReasonCode = "ProbeSucceeded"
+	ReasonProbeFailed          ReasonCode = "ProbeFailed"
+	ReasonUptimeCheckFailed    ReasonCode = "UptimeCheckFailed"
+	ReasonUptimeCheckRecovered ReasonCode = "UptimeCheckRecovered"
+)
+
+// ReasonCodeInfo describes a reason code
+type ReasonCodeInfo struct {
+	Code        ReasonCode `json:"code"`
+	Description string     `json:"description"`
+}
+
+// ReasonCodes are the reason codes landlord records, in the order they are documented
+var ReasonCodes = []ReasonCodeInfo{
+	{ReasonRetryRequested, "A failed tenant was retried"},
+	{ReasonRestarted, "The tenant or one of its components was restarted"},
+	{ReasonManualSuspend, "The tenant was suspended through the API"},
+	{ReasonManualResume, "The tenant was resumed through the API"},
+	{ReasonEmergencyStop, "The tenant's compute was stopped by an emergency action"},
+	{ReasonEmergencyDestroy, "The tenant's compute was destroyed by an emergency action"},
+	{ReasonCredentialsRotationRequested, "The tenant's endpoint credentials are being rotated"},
+	{ReasonPromotionRequested, "A promotion to the tenant is waiting for approval"},
+	{ReasonPromotionApproved, "A promotion to the tenant was approved"},
+	{ReasonPromotionRejected, "A promotion to the tenant was rejected"},
+	{ReasonHistoryForgotten, "The tenant's state history snapshots were removed"},
+	{ReasonWarmPoolClaimed, "The tenant was claimed from a warm pool"},
+	{ReasonWarmPoolRemoved, "A warm tenant was retired because its pool was removed"},
+	{ReasonWarmPoolOversized, "A warm tenant was retired because its pool is above its size"},
+	{ReasonWarmPoolTemplateChanged, "A warm tenant was retired because its template changed"},
+	{ReasonWarmTenantFailed, "A warm tenant was retired because it failed to provision"},
+	{ReasonImageUpdated, "An image update policy changed the tenant's image"},
+	{ReasonEgressDenied, "The tenant's egress policy denied outbound traffic"},
+	{ReasonHookSucceeded, "A workflow hook succeeded"},
+	{ReasonHookFailed, "A workflow hook failed"},
+	{ReasonFinalizerRemoved, "A component finished cleaning up after the tenant, or its finalizer was removed by hand"},
+	{ReasonOwnershipTransferred, "The tenant was given to another owner"},
+	{ReasonTenantShared, "The tenant was shared with another API key"},
+	{ReasonTenantUnshared, "The tenant was no longer shared with an API key"},
+	{ReasonScheduledSuspend, "A schedule suspended the tenant"},
+	{ReasonScheduledResume, "A schedule resumed the tenant"},
+	{ReasonWorkflowTimeout, "The tenant's workflow execution was stopped for running too long"},
+	{ReasonWorkflowSucceeded, "The tenant's workflow execution completed"},
+	{ReasonPolicyCompliant, "The tenant's images comply with the image policy"},
+	{ReasonPolicyViolation, "The tenant's images violate the image policy"},
+	{ReasonWithinThreshold, "The tenant's image is within the vulnerability threshold"},
+	{ReasonThresholdExceeded, "The tenant's image exceeds the vulnerability threshold"},
+	{ReasonProbeSucceeded, "The tenant passed its readiness probe"},
+	{ReasonProbeFailed, "The tenant failed its readiness probe"},
+	{ReasonUptimeCheckFailed, "The tenant failed consecutive uptime checks"},
+	{ReasonUptimeCheckRecovered, "The tenant's uptime checks are passing again"},
+}
+
+// KnownReasonCode reports whether code is one landlord records
+func KnownReasonCode(code ReasonCode) bool {
+	for _, info := range ReasonCodes {
+		if info.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// WithReasonCode sets the transition's reason code and returns it, so callers can chain it on
+// NewStateTransition
+func (st *StateTransition) WithReasonCode(code ReasonCode) *StateTransition {
+	st.ReasonCode = code
+	return st
+}
@@ -0,0 +1,24 @@
+package tenant
+
+import "testing"
+
+func TestReasonCodes(t *testing.T) {
+	seen := make(map[ReasonCode]bool)
+	for _, info := range ReasonCodes {
+		if seen[info.Code] {
+			t.Errorf("reason code %s is listed twice", info.Code)
+		}
+		seen[info.Code] = true
+		if info.Description == "" {
+			t.Errorf("reason code %s has no description", info.Code)
+		}
+	}
+	if !KnownReasonCode(ReasonEmergencyStop) || KnownReasonCode("emergencystop") {
+		t.Error("expected reason codes to be matched exactly")
+	}
+
+	tn := &Tenant{Status: StatusReady}
+	if st := NewStateTransition(tn, StatusUpdating, "Image updated", "image-updater").WithReasonCode(ReasonImageUpdated); st.ReasonCode != ReasonImageUpdated {
+		t.Errorf("expected reason code %s, got %q", ReasonImageUpdated, st.ReasonCode)
+	}
+}
@@ -17,8 +17,18 @@ var (
 
 	// ErrVersionConflict is returned when an optimistic locking conflict occurs
 	ErrVersionConflict = errors.New("version conflict: tenant was modified by another operation")
+
+	// ErrWorkflowTriggerLocked is returned when another worker is already triggering a workflow for the tenant
+	ErrWorkflowTriggerLocked = errors.New("workflow trigger already in progress for tenant")
+
+	// ErrTenantLocked is returned when another operation holds the tenant's mutation lock
+	ErrTenantLocked = errors.New("another operation is in progress for tenant")
 )
 
+// DefaultProjectID is the project a tenant belongs to when none is given.
+// The project is created by the database migrations and by the in-memory stores.
+var DefaultProjectID = uuid.MustParse("00000000-0000-0000-0000-000000000002")
+
 // ListFilters contains optional filters for listing tenants
 type ListFilters struct {
 	// Status filtering
@@ -37,17 +47,49 @@ type ListFilters struct {
 	Limit  int // Maximum number of results (0 = no limit)
 	Offset int // Number of results to skip
 
+	// Project filtering
+	ProjectIDs []uuid.UUID // If empty, match all projects
+
 	// IncludeDeleted includes archived tenants in results when true
 	IncludeDeleted bool
 
-	// Label filtering (optional future enhancement)
-	Labels map[string]string // Match all specified labels
+	// Label and annotation selectors
+	Labels      map[string]string // Match all specified labels
+	Annotations map[string]string // Match all specified annotations
+}
+
+// HistoryFilters contains optional filters for reading a tenant's state history
+type HistoryFilters struct {
+	// Transition filtering
+	ToStatuses  []Status     // If empty, match all statuses
+	ReasonCodes []ReasonCode // If empty, match all reason codes
+
+	// Time range filtering
+	CreatedAfter  *time.Time // If nil, no lower bound
+	CreatedBefore *time.Time // If nil, no upper bound
+
+	// Pagination
+	After *HistoryCursor // If set, only transitions older than this position
+	Limit int            // Maximum number of results (0 = no limit)
+}
+
+// HistoryCursor is a position in a tenant's state history, which is ordered newest first by
+// creation time and then by ID, so pages stay stable while new transitions are recorded
+type HistoryCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Cursor returns the position of st in its tenant's history
+func (st *StateTransition) Cursor() HistoryCursor {
+	return HistoryCursor{CreatedAt: st.CreatedAt, ID: st.ID}
 }
 
 // Repository defines the persistence layer for tenant resources
 type Repository interface {
 	// CreateTenant persists a new tenant
 	// Returns ErrTenantExists if name already exists
+	// Assigns DefaultProjectID when ProjectID is not set
 	// Populates ID, CreatedAt, UpdatedAt, and Version fields
 	CreateTenant(ctx context.Context, tenant *Tenant) error
 
@@ -65,10 +107,27 @@ type Repository interface {
 	// Updates UpdatedAt and increments Version
 	UpdateTenant(ctx context.Context, tenant *Tenant) error
 
+	// FenceWorkflowTrigger claims the tenant's workflow trigger lock, calls trigger and then
+	// writes the tenant, with the execution ID trigger set on it, in the same transaction
+	// Returns ErrWorkflowTriggerLocked if another worker holds the lock
+	// Returns ErrVersionConflict if the tenant changed since it was read; trigger is not called
+	// Returns trigger's error unchanged and writes nothing if trigger fails
+	FenceWorkflowTrigger(ctx context.Context, tenant *Tenant, trigger func(ctx context.Context, tenant *Tenant) error) error
+
+	// LockTenant claims the tenant's mutation lock without waiting, so concurrent API changes
+	// to one tenant run one after another. The lock is held until release is called
+	// Returns ErrTenantLocked if another operation holds the lock
+	LockTenant(ctx context.Context, id uuid.UUID) (release func(), err error)
+
 	// ListTenants retrieves multiple tenants with optional filtering
 	// Returns empty slice if no matches, never returns error for no results
 	ListTenants(ctx context.Context, filters ListFilters) ([]*Tenant, error)
 
+	// ListTenantSummaries retrieves the summaries of the tenants ListTenants would return
+	// Selects only the summary columns, for lists that refresh often
+	// Returns empty slice if no matches, never returns error for no results
+	ListTenantSummaries(ctx context.Context, filters ListFilters) ([]*Summary, error)
+
 	// ListTenantsForReconciliation retrieves tenants in non-terminal states requiring reconciliation
 	// Specifically returns tenants with status: requested, planning, provisioning, updating, or deleting
 	// Returns empty slice if no tenants need reconciliation
@@ -82,7 +141,7 @@ type Repository interface {
 	// Populates ID and CreatedAt fields
 	RecordStateTransition(ctx context.Context, transition *StateTransition) error
 
-	// GetStateHistory retrieves all state transitions for a tenant, newest first
+	// GetStateHistory retrieves the state transitions for a tenant matching filters, newest first
 	// Returns empty slice if no history, never returns error for empty results
-	GetStateHistory(ctx context.Context, tenantID uuid.UUID) ([]*StateTransition, error)
+	GetStateHistory(ctx context.Context, tenantID uuid.UUID, filters HistoryFilters) ([]*StateTransition, error)
 }
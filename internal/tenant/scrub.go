@@ -0,0 +1,22 @@
+package tenant
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HistoryScrubber removes personal data from recorded state transitions, for data retention and
+// requests to forget a tenant. Scrubbing changes only a transition's snapshots; its statuses,
+// reason, trigger and time are kept. Repositories that support it implement it next to Repository.
+type HistoryScrubber interface {
+	// ScrubStateHistory passes each transition recorded before cutoff that was not scrubbed yet to
+	// scrub, which edits its snapshots in place, then saves the snapshots and sets ScrubbedAt
+	// Returns how many transitions were scrubbed
+	ScrubStateHistory(ctx context.Context, cutoff time.Time, scrub func(*StateTransition)) (int, error)
+
+	// ForgetStateHistory removes the snapshots of every transition of a tenant and sets ScrubbedAt
+	// Returns how many transitions were recorded for the tenant
+	ForgetStateHistory(ctx context.Context, tenantID uuid.UUID) (int, error)
+}
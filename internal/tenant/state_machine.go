@@ -13,6 +13,8 @@ func NextStatus(current Status) (Status, error) {
 		return StatusReady, nil
 	case StatusUpdating:
 		return StatusReady, nil
+	case StatusMigrating:
+		return StatusReady, nil
 	case StatusDeleting:
 		return StatusArchived, nil
 	case StatusArchiving:
@@ -20,6 +22,9 @@ func NextStatus(current Status) (Status, error) {
 	case StatusArchived, StatusReady, StatusFailed:
 		// Already in terminal state
 		return "", fmt.Errorf("%s is a terminal state", current)
+	case StatusPendingApproval:
+		// Moves on only when a promotion is approved or rejected
+		return "", fmt.Errorf("%s waits for a promotion to be approved or rejected", current)
 	default:
 		return "", fmt.Errorf("unknown status: %s", current)
 	}
@@ -32,10 +37,12 @@ func ShouldReconcile(status Status) bool {
 		StatusPlanning,
 		StatusProvisioning,
 		StatusUpdating,
+		StatusMigrating,
 		StatusDeleting,
 		StatusArchiving:
 		return true
 	case StatusReady,
+		StatusPendingApproval,
 		StatusArchived,
 		StatusFailed:
 		return false
@@ -52,15 +59,18 @@ func IsTerminalStatus(status Status) bool {
 // ValidateTransition checks if a status transition is valid
 func ValidateTransition(from, to Status) error {
 	validTransitions := map[Status][]Status{
-		StatusRequested:    {StatusProvisioning, StatusFailed},
-		StatusPlanning:     {StatusProvisioning, StatusFailed},
-		StatusProvisioning: {StatusReady, StatusFailed},
-		StatusReady:        {StatusUpdating, StatusDeleting, StatusArchiving},
-		StatusUpdating:     {StatusReady, StatusFailed},
-		StatusDeleting:     {StatusArchived, StatusFailed},
-		StatusArchiving:    {StatusArchived, StatusFailed},
-		StatusArchived:     {},                                // Terminal, no transitions
-		StatusFailed:       {StatusDeleting, StatusArchiving}, // Allow archive/delete after failure
+		StatusRequested:       {StatusProvisioning, StatusFailed},
+		StatusPlanning:        {StatusProvisioning, StatusFailed},
+		StatusProvisioning:    {StatusReady, StatusFailed},
+		StatusReady:           {StatusUpdating, StatusMigrating, StatusPendingApproval, StatusDeleting, StatusArchiving},
+		StatusPendingApproval: {StatusUpdating, StatusReady, StatusDeleting, StatusArchiving}, // Approved, rejected, or removed while waiting
+		StatusUpdating:        {StatusReady, StatusFailed},
+		StatusMigrating:       {StatusReady, StatusFailed},
+		StatusDeleting:        {StatusArchived, StatusFailed},
+		StatusArchiving:       {StatusArchived, StatusFailed},
+		StatusArchived:        {}, // Terminal, no transitions
+		// Allow retrying, resuming a migration, archive/delete after failure
+		StatusFailed: {StatusProvisioning, StatusUpdating, StatusMigrating, StatusDeleting, StatusArchiving},
 	}
 
 	allowed, ok := validTransitions[from]
@@ -35,6 +35,12 @@ func TestNextStatus(t *testing.T) {
 			expected:    StatusReady,
 			expectError: false,
 		},
+		{
+			name:        "migrating to ready",
+			current:     StatusMigrating,
+			expected:    StatusReady,
+			expectError: false,
+		},
 		{
 			name:        "deleting to archived",
 			current:     StatusDeleting,
@@ -118,6 +124,11 @@ func TestShouldReconcile(t *testing.T) {
 			status:   StatusUpdating,
 			expected: true,
 		},
+		{
+			name:     "migrating requires reconciliation",
+			status:   StatusMigrating,
+			expected: true,
+		},
 		{
 			name:     "deleting requires reconciliation",
 			status:   StatusDeleting,
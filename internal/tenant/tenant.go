@@ -33,14 +33,24 @@ const (
 
 	// StatusReady: Tenant is fully operational and serving traffic
 	// Desired state matches observed state
-	// Next states: StatusUpdating, StatusDeleting
+	// Next states: StatusUpdating, StatusMigrating, StatusPendingApproval, StatusDeleting
 	StatusReady Status = "ready"
 
+	// StatusPendingApproval: A promotion from another environment awaits approval
+	// The tenant keeps serving its current configuration until then
+	// Next states: StatusUpdating (approved), StatusReady (rejected), StatusDeleting, StatusArchiving
+	StatusPendingApproval Status = "pending_approval"
+
 	// StatusUpdating: Tenant is being modified (image update, config change)
 	// Temporary state during reconciliation
 	// Next states: StatusReady, StatusFailed
 	StatusUpdating Status = "updating"
 
+	// StatusMigrating: Tenant is moving to another compute provider
+	// Progress is tracked by the migration phase annotations
+	// Next states: StatusReady, StatusFailed
+	StatusMigrating Status = "migrating"
+
 	// StatusDeleting: Tenant deletion in progress
 	// Resources are being torn down
 	// Next states: StatusArchived, StatusFailed
@@ -56,28 +66,31 @@ const (
 
 	// StatusFailed: Operation failed, manual intervention may be required
 	// StatusMessage contains error details
-	// Next states: Retry to previous state, or StatusDeleting
+	// Next states: Retry to previous state, StatusMigrating (resume), or StatusDeleting
 	StatusFailed Status = "failed"
 )
 
 // ValidTransitions defines allowed state transitions
 var ValidTransitions = map[Status][]Status{
-	StatusRequested:    {StatusProvisioning, StatusFailed},
-	StatusPlanning:     {StatusProvisioning, StatusFailed},
-	StatusProvisioning: {StatusReady, StatusFailed},
-	StatusReady:        {StatusUpdating, StatusDeleting, StatusArchiving},
-	StatusUpdating:     {StatusReady, StatusFailed},
-	StatusDeleting:     {StatusArchived, StatusFailed},
-	StatusArchiving:    {StatusArchived, StatusFailed},
-	StatusArchived:     {},                                // Terminal state
-	StatusFailed:       {StatusDeleting, StatusArchiving}, // Can archive or delete failed tenants
+	StatusRequested:       {StatusProvisioning, StatusFailed},
+	StatusPlanning:        {StatusProvisioning, StatusFailed},
+	StatusProvisioning:    {StatusReady, StatusFailed},
+	StatusReady:           {StatusUpdating, StatusMigrating, StatusPendingApproval, StatusDeleting, StatusArchiving},
+	StatusPendingApproval: {StatusUpdating, StatusReady, StatusDeleting, StatusArchiving},
+	StatusUpdating:        {StatusReady, StatusFailed},
+	StatusMigrating:       {StatusReady, StatusFailed},
+	StatusDeleting:        {StatusArchived, StatusFailed},
+	StatusArchiving:       {StatusArchived, StatusFailed},
+	StatusArchived:        {}, // Terminal state
+	// Can retry, resume a migration, archive or delete failed tenants
+	StatusFailed: {StatusProvisioning, StatusUpdating, StatusMigrating, StatusDeleting, StatusArchiving},
 }
 
 // IsValid checks if a status is a known valid status
 func (s Status) IsValid() bool {
 	switch s {
 	case StatusRequested, StatusPlanning, StatusProvisioning,
-		StatusReady, StatusUpdating, StatusDeleting, StatusArchiving,
+		StatusReady, StatusPendingApproval, StatusUpdating, StatusMigrating, StatusDeleting, StatusArchiving,
 		StatusArchived, StatusFailed:
 		return true
 	default:
@@ -121,6 +134,9 @@ type Tenant struct {
 	// Example: "acme-corp", "customer-123"
 	Name string `json:"name"`
 
+	// ProjectID is the project the tenant belongs to; projects group tenants within an organization
+	ProjectID uuid.UUID `json:"project_id"`
+
 	// Current Lifecycle State
 	// Status represents where the tenant is in its lifecycle
 	Status Status `json:"status"`
@@ -137,6 +153,10 @@ type Tenant struct {
 	// Used to detect config changes and trigger workflow restart for degraded workflows
 	WorkflowConfigHash *string `json:"workflow_config_hash,omitempty"`
 
+	// WorkflowVersion is the workflow definition version the current or last execution started on
+	// In-flight executions complete on this version even after a newer definition is deployed
+	WorkflowVersion *string `json:"workflow_version,omitempty"`
+
 	// WorkflowSubState provides provider-agnostic execution sub-state (running, backing-off, error)
 	WorkflowSubState *string `json:"workflow_sub_state,omitempty"`
 
@@ -146,12 +166,23 @@ type Tenant struct {
 	// WorkflowErrorMessage captures latest workflow error message
 	WorkflowErrorMessage *string `json:"workflow_error_message,omitempty"`
 
+	// WorkflowStartedAt is when the current workflow execution started
+	// Used to stop executions that run longer than their operation's timeout
+	WorkflowStartedAt *time.Time `json:"workflow_started_at,omitempty"`
+
+	// LastAction is the last workflow the controller started for the tenant
+	LastAction *ActionRecord `json:"last_action,omitempty"`
+
 	// Desired State (Declarative)
 	// DesiredConfig is tenant-specific configuration as map
 	// Schema is flexible and provider-specific
 	// Example: {"replicas": "2", "cpu": "512", "memory": "1024"}
 	DesiredConfig map[string]interface{} `json:"desired_config,omitempty"`
 
+	// ManagedFields tracks which actor last set each top-level DesiredConfig field
+	// Keyed by field name; fields set before tracking existed have no entry
+	ManagedFields map[string]ManagedField `json:"managed_fields,omitempty"`
+
 	// Observed State (Actual)
 	// ObservedConfig is the actual configuration applied to running resources
 	ObservedConfig map[string]interface{} `json:"observed_config,omitempty"`
@@ -160,6 +191,18 @@ type Tenant struct {
 	// Example: {"task_arn": "arn:aws:ecs:...", "target_group_arn": "arn:aws:elasticloadbalancing:..."}
 	ObservedResourceIDs map[string]string `json:"observed_resource_ids,omitempty"`
 
+	// Conditions are observations about the tenant alongside its status, one per type
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// Finalizers name the components that must clean up after the tenant before it is deleted or
+	// archived; see finalizers.go
+	Finalizers []string `json:"finalizers,omitempty"`
+
+	// Owner is the API key that created the tenant, and Collaborators the API keys it is shared
+	// with; only they and admins may change it. See ownership.go
+	Owner         string   `json:"owner,omitempty"`
+	Collaborators []string `json:"collaborators,omitempty"`
+
 	// Metadata
 	// CreatedAt is when the tenant was first created
 	CreatedAt time.Time `json:"created_at"`
@@ -182,6 +225,16 @@ type Tenant struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+// Summary is the part of a tenant shown in lists. It leaves out the configuration and
+// conditions, so listing summaries does not read or decode the tenant's JSONB columns.
+type Summary struct {
+	ID        uuid.UUID         `json:"id"`
+	Name      string            `json:"name"`
+	Status    Status            `json:"status"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
 // Validate checks if a tenant is valid
 func (t *Tenant) Validate() error {
 	if t.Name == "" {
@@ -202,6 +255,18 @@ func (t *Tenant) Validate() error {
 	return nil
 }
 
+// AnnotationComputeName records the name a tenant's compute was provisioned under when it differs
+// from the tenant's name, such as for a tenant claimed from a warm pool
+const AnnotationComputeName = "landlord/compute_name"
+
+// ComputeName returns the name compute providers know the tenant by
+func (t *Tenant) ComputeName() string {
+	if name := t.Annotations[AnnotationComputeName]; name != "" {
+		return name
+	}
+	return t.Name
+}
+
 // IsArchived returns true if tenant resources have been archived
 func (t *Tenant) IsArchived() bool {
 	return t.Status == StatusArchived
@@ -222,6 +287,14 @@ func (t *Tenant) Clone() *Tenant {
 		id := *t.WorkflowExecutionID
 		clone.WorkflowExecutionID = &id
 	}
+	if t.WorkflowConfigHash != nil {
+		hash := *t.WorkflowConfigHash
+		clone.WorkflowConfigHash = &hash
+	}
+	if t.WorkflowVersion != nil {
+		version := *t.WorkflowVersion
+		clone.WorkflowVersion = &version
+	}
 	if t.WorkflowSubState != nil {
 		state := *t.WorkflowSubState
 		clone.WorkflowSubState = &state
@@ -234,6 +307,41 @@ func (t *Tenant) Clone() *Tenant {
 		msg := *t.WorkflowErrorMessage
 		clone.WorkflowErrorMessage = &msg
 	}
+	if t.WorkflowStartedAt != nil {
+		startedAt := *t.WorkflowStartedAt
+		clone.WorkflowStartedAt = &startedAt
+	}
+	if t.LastAction != nil {
+		action := *t.LastAction
+		clone.LastAction = &action
+	}
+	if t.ManagedFields != nil {
+		clone.ManagedFields = make(map[string]ManagedField, len(t.ManagedFields))
+		for k, v := range t.ManagedFields {
+			clone.ManagedFields[k] = v
+		}
+	}
+	if t.DesiredConfig != nil {
+		clone.DesiredConfig = cloneConfig(t.DesiredConfig)
+	}
+	if t.ObservedConfig != nil {
+		clone.ObservedConfig = cloneConfig(t.ObservedConfig)
+	}
+	if t.ObservedResourceIDs != nil {
+		clone.ObservedResourceIDs = make(map[string]string, len(t.ObservedResourceIDs))
+		for k, v := range t.ObservedResourceIDs {
+			clone.ObservedResourceIDs[k] = v
+		}
+	}
+	if t.Conditions != nil {
+		clone.Conditions = append([]Condition(nil), t.Conditions...)
+	}
+	if t.Finalizers != nil {
+		clone.Finalizers = append([]string(nil), t.Finalizers...)
+	}
+	if t.Collaborators != nil {
+		clone.Collaborators = append([]string(nil), t.Collaborators...)
+	}
 	if t.Labels != nil {
 		clone.Labels = make(map[string]string, len(t.Labels))
 		for k, v := range t.Labels {
@@ -249,6 +357,30 @@ func (t *Tenant) Clone() *Tenant {
 	return &clone
 }
 
+// cloneConfig deep copies a decoded JSON config; other values are shared
+func cloneConfig(config map[string]interface{}) map[string]interface{} {
+	clone := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		clone[k] = cloneConfigValue(v)
+	}
+	return clone
+}
+
+func cloneConfigValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return cloneConfig(v)
+	case []interface{}:
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = cloneConfigValue(item)
+		}
+		return items
+	default:
+		return v
+	}
+}
+
 // StateTransition represents a single state change in tenant lifecycle
 // Immutable audit log entry
 type StateTransition struct {
@@ -272,6 +404,10 @@ type StateTransition struct {
 	// Examples: "User requested tenant creation", "Health check failed", "Workflow completed successfully"
 	Reason string `json:"reason"`
 
+	// ReasonCode is the machine-readable reason, for UIs to localize and alerting to filter on.
+	// Empty for records from before reason codes were introduced.
+	ReasonCode ReasonCode `json:"reason_code,omitempty"`
+
 	// TriggeredBy identifies who/what initiated the transition
 	// Examples: "user@example.com", "reconciliation-loop", "workflow:provision-tenant"
 	TriggeredBy string `json:"triggered_by,omitempty"`
@@ -288,6 +424,9 @@ type StateTransition struct {
 	// Metadata
 	// CreatedAt is when this transition was recorded
 	CreatedAt time.Time `json:"created_at"`
+
+	// ScrubbedAt is when personal data was removed from the snapshots, by retention or a request to forget the tenant
+	ScrubbedAt *time.Time `json:"scrubbed_at,omitempty"`
 }
 
 // NewStateTransition creates a new state transition record
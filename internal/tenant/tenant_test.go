@@ -17,6 +17,7 @@ func TestStatus_IsValid(t *testing.T) {
 		{"provisioning", StatusProvisioning, true},
 		{"ready", StatusReady, true},
 		{"updating", StatusUpdating, true},
+		{"migrating", StatusMigrating, true},
 		{"deleting", StatusDeleting, true},
 		{"archived", StatusArchived, true},
 		{"failed", StatusFailed, true},
@@ -86,9 +87,15 @@ func TestStatus_CanTransition(t *testing.T) {
 		{"ready -> updating", StatusReady, StatusUpdating, true},
 		{"ready -> deleting", StatusReady, StatusDeleting, true},
 		{"ready -> archiving", StatusReady, StatusArchiving, true},
+		{"ready -> migrating", StatusReady, StatusMigrating, true},
+		{"migrating -> ready", StatusMigrating, StatusReady, true},
+		{"migrating -> deleting (invalid)", StatusMigrating, StatusDeleting, false},
 		{"archived -> anything (invalid)", StatusArchived, StatusReady, false},
 		{"failed -> deleting", StatusFailed, StatusDeleting, true},
 		{"failed -> archiving", StatusFailed, StatusArchiving, true},
+		{"failed -> migrating", StatusFailed, StatusMigrating, true},
+		{"failed -> provisioning (retry)", StatusFailed, StatusProvisioning, true},
+		{"failed -> updating (retry)", StatusFailed, StatusUpdating, true},
 	}
 
 	for _, tt := range tests {
@@ -297,6 +304,11 @@ func TestTenant_Clone(t *testing.T) {
 		Annotations: map[string]string{
 			"owner": "team-a",
 		},
+		DesiredConfig: map[string]interface{}{
+			"env": map[string]interface{}{"LOG_LEVEL": "info"},
+		},
+		Conditions: []Condition{{Type: "Ready", Status: ConditionTrue}},
+		Finalizers: []string{"dns", "ingress"},
 	}
 
 	clone := original.Clone()
@@ -319,6 +331,21 @@ func TestTenant_Clone(t *testing.T) {
 	if original.Annotations["owner"] != "team-a" {
 		t.Error("Modifying clone Annotations affected original")
 	}
+
+	clone.DesiredConfig["env"].(map[string]interface{})["LOG_LEVEL"] = "debug"
+	if original.DesiredConfig["env"].(map[string]interface{})["LOG_LEVEL"] != "info" {
+		t.Error("Modifying clone DesiredConfig affected original")
+	}
+
+	clone.Conditions[0].Status = ConditionFalse
+	if original.Conditions[0].Status != ConditionTrue {
+		t.Error("Modifying clone Conditions affected original")
+	}
+
+	clone.RemoveFinalizer("dns")
+	if len(original.Finalizers) != 2 || original.Finalizers[0] != "dns" || original.Finalizers[1] != "ingress" {
+		t.Error("Modifying clone Finalizers affected original")
+	}
 }
 
 func TestStateTransition_Validate(t *testing.T) {
@@ -465,3 +492,26 @@ func TestNewStateTransition(t *testing.T) {
 		t.Error("CreatedAt should be set")
 	}
 }
+
+func TestTenantPriority(t *testing.T) {
+	tn := &Tenant{}
+	if got := tn.Priority(); got != PriorityDefault {
+		t.Fatalf("expected default priority without a label, got %q", got)
+	}
+
+	tn.Labels = map[string]string{LabelPriority: "critical"}
+	if got := tn.Priority(); got != PriorityCritical {
+		t.Fatalf("expected critical priority, got %q", got)
+	}
+	if PriorityCritical.Rank() >= PriorityBatch.Rank() {
+		t.Fatal("expected critical to rank ahead of batch")
+	}
+
+	tn.Labels[LabelPriority] = "urgent"
+	if got := tn.Priority(); got != PriorityDefault {
+		t.Fatalf("expected an invalid label to mean default, got %q", got)
+	}
+	if _, err := PriorityFromLabels(tn.Labels); err == nil {
+		t.Fatal("expected an error for an invalid priority label")
+	}
+}
@@ -0,0 +1,249 @@
+// Landlord dashboard: a hash-routed single-page app over the /v1 API.
+(function () {
+  "use strict";
+
+  var API = "/v1";
+  var KEY_STORAGE = "landlord.apiKey";
+  var REFRESH_MS = 5000;
+  var app = document.getElementById("app");
+  var refreshTimer = null;
+
+  function escape(value) {
+    if (value === undefined || value === null) {
+      return "";
+    }
+    return String(value)
+      .replace(/&/g, "&amp;")
+      .replace(/</g, "&lt;")
+      .replace(/>/g, "&gt;")
+      .replace(/"/g, "&quot;")
+      .replace(/'/g, "&#39;");
+  }
+
+  function badge(status) {
+    return '<span class="badge ' + escape(status) + '">' + escape(status) + "</span>";
+  }
+
+  function time(value) {
+    return value ? new Date(value).toLocaleString() : "";
+  }
+
+  // api calls the Landlord API with the saved key and rejects with the server's error message
+  function api(method, path, body) {
+    var headers = { "Accept": "application/json" };
+    var key = localStorage.getItem(KEY_STORAGE);
+    if (key) {
+      headers["Authorization"] = "Bearer " + key;
+    }
+    var init = { method: method, headers: headers };
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+      init.body = JSON.stringify(body);
+    }
+    return fetch(API + path, init).then(function (resp) {
+      return resp.text().then(function (text) {
+        var data = text ? JSON.parse(text) : null;
+        if (!resp.ok) {
+          var message = (data && (data.detail || data.error)) || resp.statusText;
+          var details = data && (data.errors || data.details);
+          if (details && details.length) {
+            message += ": " + details.join("; ");
+          }
+          throw new Error(message);
+        }
+        return data;
+      });
+    });
+  }
+
+  function showError(err) {
+    app.innerHTML = '<p class="error">' + escape(err.message) + "</p>" +
+      '<p><a href="#/">Back to tenants</a></p>';
+  }
+
+  function renderList() {
+    var includeArchived = sessionStorage.getItem("landlord.includeArchived") === "true";
+    var query = "?limit=500" + (includeArchived ? "&include_deleted=true" : "");
+    return api("GET", "/tenants" + query).then(function (data) {
+      var rows = data.tenants.map(function (t) {
+        var provider = (t.compute_config && t.compute_config.compute_provider) || "";
+        return "<tr>" +
+          '<td><a href="#/tenants/' + encodeURIComponent(t.id) + '">' + escape(t.name) + "</a></td>" +
+          "<td>" + badge(t.status) + "</td>" +
+          "<td>" + escape(t.workflow_sub_state) + "</td>" +
+          "<td>" + escape(provider) + "</td>" +
+          '<td class="muted">' + escape(t.status_message) + "</td>" +
+          "<td>" + escape(time(t.updated_at)) + "</td>" +
+          "</tr>";
+      });
+      app.innerHTML =
+        '<div class="toolbar"><h2>Tenants</h2><span class="muted">' + escape(data.total) + " total</span>" +
+        '<label><input id="include-archived" type="checkbox"' + (includeArchived ? " checked" : "") + "> Show archived</label></div>" +
+        "<table><thead><tr><th>Name</th><th>Status</th><th>Workflow</th><th>Provider</th><th>Message</th><th>Updated</th></tr></thead>" +
+        "<tbody>" + (rows.join("") || '<tr><td colspan="6" class="muted">No tenants</td></tr>') + "</tbody></table>";
+      document.getElementById("include-archived").addEventListener("change", function (e) {
+        sessionStorage.setItem("landlord.includeArchived", e.target.checked ? "true" : "false");
+        renderList().catch(showError);
+      });
+    });
+  }
+
+  function renderDetail(id) {
+    var path = "/tenants/" + encodeURIComponent(id);
+    return Promise.all([api("GET", path), api("GET", path + "/history")]).then(function (results) {
+      // Steps are only known for executions run by workers that report them
+      var execution = results[0].workflow_execution_id ?
+        api("GET", "/executions/" + encodeURIComponent(results[0].workflow_execution_id)).catch(function () { return null; }) :
+        null;
+      return Promise.all(results.concat([execution]));
+    }).then(function (results) {
+      var t = results[0];
+      var history = results[1].transitions;
+      var execution = results[2];
+
+      var fields = [
+        ["ID", escape(t.id)],
+        ["Status", badge(t.status)],
+        ["Message", escape(t.status_message)],
+        ["Workflow sub-state", escape(t.workflow_sub_state)],
+        ["Workflow execution", escape(t.workflow_execution_id)],
+        ["Workflow retries", escape(t.workflow_retry_count)],
+        ["Workflow error", escape(t.workflow_error_message)],
+        ["Created", escape(time(t.created_at))],
+        ["Updated", escape(time(t.updated_at))]
+      ];
+      if (t.migration) {
+        fields.push(["Migration", escape(t.migration.source_provider + " → " + t.migration.target_provider + " (" + t.migration.phase + ")")]);
+      }
+      if (t.promotion) {
+        fields.push(["Promotion", escape("from " + t.promotion.source + " by " + (t.promotion.requested_by || "unknown") + ": " +
+          t.promotion.changes.map(function (c) { return c.field; }).join(", "))]);
+      }
+
+      var actions = [];
+      var canArchive = ["archived", "archiving", "deleting", "migrating"].indexOf(t.status) === -1;
+      actions.push('<button id="archive" class="danger"' + (canArchive ? "" : " disabled") + ">Archive</button>");
+      if (t.status === "failed" && t.migration) {
+        actions.push('<button id="retry-migration">Retry migration to ' + escape(t.migration.target_provider) + "</button>");
+      }
+      if (t.status === "failed" && !t.migration) {
+        actions.push('<button id="retry">Retry</button>');
+      }
+      if (t.status === "ready") {
+        var suspended = (t.conditions || []).some(function (c) { return c.type === "Suspended" && c.status === "True"; });
+        actions.push(suspended ? '<button id="resume">Resume</button>' : '<button id="suspend">Suspend</button>');
+        if (!suspended) {
+          actions.push('<button id="restart">Restart</button>');
+        }
+      }
+      if (t.status === "pending_approval" && t.promotion) {
+        actions.push('<button id="approve-promotion">Approve promotion</button>');
+        actions.push('<button id="reject-promotion" class="danger">Reject promotion</button>');
+      }
+
+      var rows = history.map(function (h) {
+        return "<tr>" +
+          "<td>" + escape(time(h.created_at)) + "</td>" +
+          "<td>" + (h.from_status ? badge(h.from_status) : "") + " → " + badge(h.to_status) + "</td>" +
+          "<td>" + escape(h.reason) + "</td>" +
+          '<td class="muted">' + escape(h.triggered_by) + "</td>" +
+          "</tr>";
+      });
+
+      var steps = execution ? execution.steps.map(function (step) {
+        return "<tr>" +
+          "<td>" + escape(step.name) + "</td>" +
+          "<td>" + badge(step.status) + "</td>" +
+          "<td>" + escape(step.attempts) + "</td>" +
+          "<td>" + escape((step.duration_ms / 1000).toFixed(1) + "s") + "</td>" +
+          '<td class="muted">' + escape(step.error) + "</td>" +
+          "</tr>";
+      }) : [];
+
+      app.innerHTML =
+        '<p><a href="#/">← Tenants</a></p>' +
+        "<h2>" + escape(t.name) + "</h2>" +
+        "<dl>" + fields.map(function (f) { return "<dt>" + f[0] + "</dt><dd>" + f[1] + "</dd>"; }).join("") + "</dl>" +
+        '<div class="actions">' + actions.join("") + '</div><p id="action-result"></p>' +
+        "<h3>State history</h3>" +
+        "<table><thead><tr><th>When</th><th>Transition</th><th>Reason</th><th>Triggered by</th></tr></thead>" +
+        "<tbody>" + (rows.join("") || '<tr><td colspan="4" class="muted">No transitions recorded</td></tr>') + "</tbody></table>" +
+        (execution ? "<h3>Workflow steps</h3>" +
+          "<table><thead><tr><th>Step</th><th>Status</th><th>Attempts</th><th>Duration</th><th>Error</th></tr></thead>" +
+          "<tbody>" + steps.join("") + "</tbody></table>" : "") +
+        "<h3>Compute config</h3><pre>" + escape(JSON.stringify(t.compute_config || {}, null, 2)) + "</pre>";
+
+      bindAction("archive", "Archive " + t.name + "? Its compute resources will be removed.", function () {
+        return api("POST", path + "/archive");
+      });
+      bindAction("retry-migration", null, function () {
+        return api("POST", path + "/migrate", { target_provider: t.migration.target_provider });
+      });
+      bindAction("retry", null, function () {
+        return api("POST", path + "/retry");
+      });
+      bindAction("suspend", "Suspend " + t.name + "? Its workloads will be stopped until it is resumed.", function () {
+        return api("POST", path + "/suspend");
+      });
+      bindAction("resume", null, function () {
+        return api("POST", path + "/resume");
+      });
+      bindAction("restart", "Restart " + t.name + "? Its workloads will be restarted in place.", function () {
+        return api("POST", path + "/restart");
+      });
+      bindAction("approve-promotion", "Approve the promotion from " + (t.promotion && t.promotion.source) + "? " + t.name + " will be updated.", function () {
+        return api("POST", path + "/promotion/approve");
+      });
+      bindAction("reject-promotion", null, function () {
+        var reason = window.prompt("Reason for rejecting the promotion (optional)");
+        if (reason === null) {
+          return Promise.reject(new Error("Rejection cancelled"));
+        }
+        return api("POST", path + "/promotion/reject", { reason: reason });
+      });
+    });
+  }
+
+  function bindAction(id, confirmation, run) {
+    var button = document.getElementById(id);
+    if (!button) {
+      return;
+    }
+    button.addEventListener("click", function () {
+      if (confirmation && !window.confirm(confirmation)) {
+        return;
+      }
+      button.disabled = true;
+      run().then(render, function (err) {
+        button.disabled = false;
+        var result = document.getElementById("action-result");
+        result.className = "error";
+        result.textContent = err.message;
+      });
+    });
+  }
+
+  function render() {
+    clearTimeout(refreshTimer);
+    var match = location.hash.match(/^#\/tenants\/(.+)$/);
+    var view = match ? renderDetail(decodeURIComponent(match[1])) : renderList();
+    view.then(function () {
+      refreshTimer = setTimeout(render, REFRESH_MS);
+    }, showError);
+  }
+
+  var keyInput = document.getElementById("api-key");
+  keyInput.value = localStorage.getItem(KEY_STORAGE) || "";
+  document.getElementById("auth").addEventListener("submit", function (e) {
+    e.preventDefault();
+    if (keyInput.value) {
+      localStorage.setItem(KEY_STORAGE, keyInput.value);
+    } else {
+      localStorage.removeItem(KEY_STORAGE);
+    }
+    render();
+  });
+
+  window.addEventListener("hashchange", render);
+  render();
+})();
@@ -0,0 +1,22 @@
+// Package ui serves the operator dashboard: a single-page app embedded in the binary that
+// lists tenants and drives them through the public /v1 API.
+package ui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var static embed.FS
+
+// Handler serves the dashboard assets. Mount it with the /ui prefix stripped.
+func Handler() http.Handler {
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		// The embedded directory is fixed at build time
+		panic(err)
+	}
+	return http.FileServer(http.FS(assets))
+}
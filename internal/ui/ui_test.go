@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlerServesAssets(t *testing.T) {
+	handler := Handler()
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+	}{
+		{path: "/", status: http.StatusOK, contentType: "text/html"},
+		{path: "/app.js", status: http.StatusOK, contentType: "javascript"},
+		{path: "/style.css", status: http.StatusOK, contentType: "text/css"},
+		{path: "/missing.js", status: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("GET %s status = %d, want %d", tt.path, rec.Code, tt.status)
+			}
+			if tt.contentType != "" && !strings.Contains(rec.Header().Get("Content-Type"), tt.contentType) {
+				t.Errorf("GET %s Content-Type = %q, want %s", tt.path, rec.Header().Get("Content-Type"), tt.contentType)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package uptime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/schedule"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// maxConcurrentChecks bounds how many tenants are checked at once
+const maxConcurrentChecks = 16
+
+// Checker periodically probes the endpoints of every ready tenant. Results are kept in memory,
+// so availability restarts from nothing when the process restarts. A tenant that fails
+// FailureThreshold checks in a row is marked EndpointUnhealthy, and its next passing check clears
+// it; both are recorded in the tenant's state history.
+type Checker struct {
+	tenants     tenant.Repository
+	client      *http.Client
+	interval    time.Duration
+	timeout     time.Duration
+	threshold   int
+	historySize int
+	logger      *zap.Logger
+
+	statsMu sync.RWMutex
+	stats   map[uuid.UUID]*tenantStats
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// tenantStats are the checks kept for one tenant
+type tenantStats struct {
+	name        string
+	since       time.Time
+	history     []Check
+	consecutive int
+	total       int64
+	failed      int64
+}
+
+// NewChecker creates an uptime checker
+func NewChecker(tenants tenant.Repository, cfg config.UptimeConfig, logger *zap.Logger) *Checker {
+	return &Checker{
+		tenants:     tenants,
+		client:      &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }},
+		interval:    cfg.Interval,
+		timeout:     cfg.Timeout,
+		threshold:   cfg.FailureThreshold,
+		historySize: cfg.HistorySize,
+		logger:      logger.With(zap.String("component", "uptime-checker")),
+		stats:       make(map[uuid.UUID]*tenantStats),
+	}
+}
+
+// Start checks tenants in the background until Stop is called
+func (c *Checker) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.interval <= 0 {
+		return fmt.Errorf("uptime check interval must be positive")
+	}
+	if c.cancel != nil {
+		return fmt.Errorf("uptime checker already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.run(ctx, c.done)
+
+	c.logger.Info("uptime checker started", zap.Duration("interval", c.interval))
+	return nil
+}
+
+// Stop stops the background checks and waits for a running pass to finish
+func (c *Checker) Stop() {
+	c.mu.Lock()
+	cancel, done := c.cancel, c.done
+	c.cancel, c.done = nil, nil
+	c.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	c.logger.Info("uptime checker stopped")
+}
+
+func (c *Checker) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if err := c.CheckAll(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Warn("uptime check pass failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll checks every ready tenant that reports endpoints once. Tenants that are no longer
+// ready, are suspended, or have no endpoints are dropped from the kept results.
+func (c *Checker) CheckAll(ctx context.Context) error {
+	tenants, err := c.tenants.ListTenants(ctx, tenant.ListFilters{Statuses: []tenant.Status{tenant.StatusReady}})
+	if err != nil {
+		return fmt.Errorf("list tenants: %w", err)
+	}
+
+	checked := make(map[uuid.UUID]bool, len(tenants))
+	sem := make(chan struct{}, maxConcurrentChecks)
+	var wg sync.WaitGroup
+	for _, t := range tenants {
+		if suspended := t.Condition(schedule.ConditionSuspended); suspended != nil && suspended.Status == tenant.ConditionTrue {
+			// A suspended tenant is stopped on purpose, so its endpoints are expected to be down
+			continue
+		}
+		endpoints := Endpoints(t)
+		if len(endpoints) == 0 {
+			continue
+		}
+		checked[t.ID] = true
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(t *tenant.Tenant) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			c.checkTenant(ctx, t, endpoints)
+		}(t)
+	}
+	wg.Wait()
+
+	c.statsMu.Lock()
+	for id := range c.stats {
+		if !checked[id] {
+			delete(c.stats, id)
+		}
+	}
+	c.statsMu.Unlock()
+	return ctx.Err()
+}
+
+func (c *Checker) checkTenant(ctx context.Context, t *tenant.Tenant, endpoints []compute.Endpoint) {
+	check := Check{CheckedAt: time.Now(), Up: true}
+	for _, endpoint := range endpoints {
+		probeCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		result := probe(probeCtx, c.client, endpoint)
+		cancel()
+
+		check.Endpoints = append(check.Endpoints, result)
+		check.Up = check.Up && result.Up
+		if result.LatencyMS > check.LatencyMS {
+			check.LatencyMS = result.LatencyMS
+		}
+	}
+	if ctx.Err() != nil {
+		// A cancelled pass says nothing about the tenant
+		return
+	}
+
+	consecutive := c.record(t, check)
+	if err := c.updateCondition(ctx, t, &check, consecutive); err != nil {
+		if errors.Is(err, tenant.ErrVersionConflict) || errors.Is(err, tenant.ErrTenantNotFound) {
+			// The tenant changed underneath the check; the next pass sees its new state
+			return
+		}
+		c.logger.Warn("failed to record uptime condition", zap.String("tenant_id", t.ID.String()), zap.Error(err))
+	}
+}
+
+// record keeps check in t's history and returns its consecutive failures
+func (c *Checker) record(t *tenant.Tenant, check Check) int {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	stats, ok := c.stats[t.ID]
+	if !ok {
+		stats = &tenantStats{since: check.CheckedAt}
+		c.stats[t.ID] = stats
+	}
+	stats.name = t.Name
+	stats.history = append(stats.history, check)
+	if len(stats.history) > c.historySize {
+		stats.history = stats.history[len(stats.history)-c.historySize:]
+	}
+	stats.total++
+	if check.Up {
+		stats.consecutive = 0
+	} else {
+		stats.consecutive++
+		stats.failed++
+	}
+	return stats.consecutive
+}
+
+// updateCondition marks t EndpointUnhealthy once its failures reach the threshold, and clears the
+// condition once a check passes
+func (c *Checker) updateCondition(ctx context.Context, t *tenant.Tenant, check *Check, consecutive int) error {
+	unhealthy := t.Condition(ConditionEndpointUnhealthy)
+	var condition tenant.Condition
+	switch {
+	case !check.Up && consecutive >= c.threshold:
+		if unhealthy != nil && unhealthy.Status == tenant.ConditionTrue {
+			return nil
+		}
+		condition = tenant.Condition{
+			Type:    ConditionEndpointUnhealthy,
+			Status:  tenant.ConditionTrue,
+			Reason:  ReasonCheckFailed,
+			Message: fmt.Sprintf("%d consecutive uptime checks failed: %s", consecutive, check.Error()),
+		}
+	case check.Up && unhealthy != nil && unhealthy.Status == tenant.ConditionTrue:
+		condition = tenant.Condition{
+			Type:    ConditionEndpointUnhealthy,
+			Status:  tenant.ConditionFalse,
+			Reason:  ReasonCheckRecovered,
+			Message: "Uptime checks are passing again",
+		}
+	default:
+		return nil
+	}
+
+	transition := tenant.NewStateTransition(t, t.Status, condition.Message, Manager).WithReasonCode(condition.Reason)
+	t.SetCondition(condition, check.CheckedAt)
+	if err := c.tenants.UpdateTenant(ctx, t); err != nil {
+		return err
+	}
+	transition.ObservedStateSnapshot = map[string]interface{}{
+		"up":         check.Up,
+		"latency_ms": check.LatencyMS,
+		"endpoints":  check.Endpoints,
+	}
+	if err := c.tenants.RecordStateTransition(ctx, transition); err != nil {
+		c.logger.Warn("failed to record uptime event", zap.String("tenant_id", t.ID.String()), zap.Error(err))
+	}
+	c.logger.Info("tenant uptime changed",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("tenant_name", t.Name),
+		zap.String("reason", string(condition.Reason)),
+		zap.String("message", condition.Message))
+	return nil
+}
+
+// Report returns the uptime report for a tenant, or false when it has not been checked
+func (c *Checker) Report(id uuid.UUID) (*Report, bool) {
+	c.statsMu.RLock()
+	defer c.statsMu.RUnlock()
+
+	stats, ok := c.stats[id]
+	if !ok {
+		return nil, false
+	}
+	return stats.report(), true
+}
+
+func (s *tenantStats) report() *Report {
+	report := &Report{
+		Checks:              len(s.history),
+		ConsecutiveFailures: s.consecutive,
+		History:             append([]Check(nil), s.history...),
+		Since:               s.since,
+	}
+	var latency float64
+	for i := range s.history {
+		if !s.history[i].Up {
+			report.Failures++
+		}
+		latency += s.history[i].LatencyMS
+	}
+	if report.Checks > 0 {
+		report.Availability = float64(report.Checks-report.Failures) / float64(report.Checks)
+		report.AverageLatencyMS = latency / float64(report.Checks)
+		last := report.History[report.Checks-1]
+		report.LastCheck = &last
+	}
+	return report
+}
@@ -0,0 +1,220 @@
+package uptime_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/schedule"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/tenant/memory"
+	"github.com/jaxxstorm/landlord/internal/uptime"
+)
+
+func newChecker(threshold int) (*uptime.Checker, *memory.Repository) {
+	repo := memory.New()
+	checker := uptime.NewChecker(repo, config.UptimeConfig{
+		Enabled:          true,
+		Interval:         time.Minute,
+		Timeout:          time.Second,
+		FailureThreshold: threshold,
+		HistorySize:      3,
+	}, zap.NewNop())
+	return checker, repo
+}
+
+func createTenant(t *testing.T, repo *memory.Repository, name string, status tenant.Status, endpoints ...map[string]interface{}) *tenant.Tenant {
+	t.Helper()
+	observed := map[string]interface{}{}
+	if len(endpoints) > 0 {
+		list := make([]interface{}, 0, len(endpoints))
+		for _, endpoint := range endpoints {
+			list = append(list, endpoint)
+		}
+		observed["endpoints"] = list
+	}
+	now := time.Now()
+	tn := &tenant.Tenant{
+		ID:             uuid.New(),
+		Name:           name,
+		Status:         status,
+		DesiredConfig:  map[string]interface{}{"image": "nginx:latest"},
+		ObservedConfig: observed,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Version:        1,
+	}
+	if err := repo.CreateTenant(context.Background(), tn); err != nil {
+		t.Fatalf("CreateTenant() error = %v", err)
+	}
+	return tn
+}
+
+func closedPort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	return port
+}
+
+func TestCheckAllReportsAvailability(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	checker, repo := newChecker(3)
+	ctx := context.Background()
+
+	web := createTenant(t, repo, "web", tenant.StatusReady, map[string]interface{}{"type": "http", "url": server.URL})
+	createTenant(t, repo, "no-endpoints", tenant.StatusReady)
+	stopped := createTenant(t, repo, "stopped", tenant.StatusArchived, map[string]interface{}{"type": "http", "url": server.URL})
+
+	for i := 0; i < 4; i++ {
+		if err := checker.CheckAll(ctx); err != nil {
+			t.Fatalf("CheckAll() error = %v", err)
+		}
+	}
+
+	report, ok := checker.Report(web.ID)
+	if !ok {
+		t.Fatal("expected a report for the ready tenant")
+	}
+	if report.Checks != 3 || report.Availability != 1 || report.LastCheck == nil || report.LastCheck.Endpoints[0].StatusCode != http.StatusNotFound {
+		t.Fatalf("expected the last 3 passing checks to be kept, got %+v", report)
+	}
+	if _, ok := checker.Report(stopped.ID); ok {
+		t.Fatal("expected tenants that are not ready to be skipped")
+	}
+
+	var metrics bytes.Buffer
+	if err := checker.WriteMetrics(&metrics); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+	for _, want := range []string{
+		"# TYPE landlord_tenant_up gauge",
+		`landlord_tenant_up{tenant_id="` + web.ID.String() + `",tenant_name="web"} 1`,
+		`landlord_tenant_uptime_checks_total{tenant_id="` + web.ID.String() + `",tenant_name="web"} 4`,
+	} {
+		if !strings.Contains(metrics.String(), want) {
+			t.Fatalf("expected metrics to contain %q, got:\n%s", want, metrics.String())
+		}
+	}
+}
+
+func TestConsecutiveFailuresMarkTenantUnhealthy(t *testing.T) {
+	checker, repo := newChecker(2)
+	ctx := context.Background()
+
+	port := closedPort(t)
+	web := createTenant(t, repo, "web", tenant.StatusReady, map[string]interface{}{"type": "tcp", "address": "127.0.0.1", "port": port})
+
+	if err := checker.CheckAll(ctx); err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+	current, _ := repo.GetTenantByID(ctx, web.ID)
+	if current.Condition(uptime.ConditionEndpointUnhealthy) != nil {
+		t.Fatal("expected a single failure to stay below the threshold")
+	}
+
+	if err := checker.CheckAll(ctx); err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+	current, _ = repo.GetTenantByID(ctx, web.ID)
+	unhealthy := current.Condition(uptime.ConditionEndpointUnhealthy)
+	if unhealthy == nil || unhealthy.Status != tenant.ConditionTrue || unhealthy.Reason != uptime.ReasonCheckFailed {
+		t.Fatalf("expected the tenant to be unhealthy, got %+v", unhealthy)
+	}
+	report, _ := checker.Report(web.ID)
+	if report.Availability != 0 || report.ConsecutiveFailures != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+
+	// The tenant starts listening again
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		t.Skipf("port %d was taken before the test could reuse it: %v", port, err)
+	}
+	defer listener.Close()
+
+	if err := checker.CheckAll(ctx); err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+	current, _ = repo.GetTenantByID(ctx, web.ID)
+	unhealthy = current.Condition(uptime.ConditionEndpointUnhealthy)
+	if unhealthy.Status != tenant.ConditionFalse || unhealthy.Reason != uptime.ReasonCheckRecovered {
+		t.Fatalf("expected the tenant to recover, got %+v", unhealthy)
+	}
+
+	history, err := repo.GetStateHistory(ctx, web.ID, tenant.HistoryFilters{})
+	if err != nil {
+		t.Fatalf("GetStateHistory() error = %v", err)
+	}
+	var events int
+	for _, transition := range history {
+		if transition.TriggeredBy == uptime.Manager {
+			events++
+		}
+	}
+	if events != 2 {
+		t.Fatalf("expected unhealthy and recovered events, got %d", events)
+	}
+}
+
+func TestCheckAllLeavesWorkflowDegradedCondition(t *testing.T) {
+	checker, repo := newChecker(1)
+	ctx := context.Background()
+
+	web := createTenant(t, repo, "web", tenant.StatusReady, map[string]interface{}{"type": "tcp", "address": "127.0.0.1", "port": closedPort(t)})
+	web.SetCondition(tenant.Condition{Type: "Degraded", Status: tenant.ConditionTrue, Reason: "WorkflowTimeout"}, time.Now())
+	if err := repo.UpdateTenant(ctx, web); err != nil {
+		t.Fatalf("UpdateTenant() error = %v", err)
+	}
+
+	if err := checker.CheckAll(ctx); err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+	current, _ := repo.GetTenantByID(ctx, web.ID)
+	if unhealthy := current.Condition(uptime.ConditionEndpointUnhealthy); unhealthy == nil || unhealthy.Status != tenant.ConditionTrue {
+		t.Fatalf("expected the tenant to be unhealthy, got %+v", unhealthy)
+	}
+	if degraded := current.Condition("Degraded"); degraded.Status != tenant.ConditionTrue || degraded.Reason != "WorkflowTimeout" {
+		t.Fatalf("expected the workflow timeout to stay degraded, got %+v", degraded)
+	}
+}
+
+func TestCheckAllSkipsSuspendedTenants(t *testing.T) {
+	checker, repo := newChecker(1)
+	ctx := context.Background()
+
+	web := createTenant(t, repo, "web", tenant.StatusReady, map[string]interface{}{"type": "tcp", "address": "127.0.0.1", "port": closedPort(t)})
+	web.SetCondition(tenant.Condition{Type: schedule.ConditionSuspended, Status: tenant.ConditionTrue, Reason: "ScheduledSuspend"}, time.Now())
+	if err := repo.UpdateTenant(ctx, web); err != nil {
+		t.Fatalf("UpdateTenant() error = %v", err)
+	}
+
+	if err := checker.CheckAll(ctx); err != nil {
+		t.Fatalf("CheckAll() error = %v", err)
+	}
+	if _, ok := checker.Report(web.ID); ok {
+		t.Fatal("expected the suspended tenant to be skipped")
+	}
+	current, _ := repo.GetTenantByID(ctx, web.ID)
+	if unhealthy := current.Condition(uptime.ConditionEndpointUnhealthy); unhealthy != nil {
+		t.Fatalf("expected a suspended tenant not to be marked unhealthy, got %+v", unhealthy)
+	}
+}
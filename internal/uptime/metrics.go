@@ -0,0 +1,75 @@
+package uptime
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// metric is one family in the Prometheus text exposition
+type metric struct {
+	name, help, kind string
+	value            func(s *tenantStats, r *Report) float64
+}
+
+var metrics = []metric{
+	{"landlord_tenant_up", "Whether the tenant's last uptime check passed", "gauge", func(s *tenantStats, r *Report) float64 {
+		if r.LastCheck != nil && r.LastCheck.Up {
+			return 1
+		}
+		return 0
+	}},
+	{"landlord_tenant_uptime_availability_ratio", "Share of the tenant's kept uptime checks that passed", "gauge", func(s *tenantStats, r *Report) float64 {
+		return r.Availability
+	}},
+	{"landlord_tenant_uptime_latency_seconds", "Latency of the slowest endpoint in the tenant's last uptime check", "gauge", func(s *tenantStats, r *Report) float64 {
+		if r.LastCheck == nil {
+			return 0
+		}
+		return r.LastCheck.LatencyMS / 1000
+	}},
+	{"landlord_tenant_uptime_consecutive_failures", "Uptime checks the tenant has failed in a row", "gauge", func(s *tenantStats, r *Report) float64 {
+		return float64(r.ConsecutiveFailures)
+	}},
+	{"landlord_tenant_uptime_checks_total", "Uptime checks run for the tenant", "counter", func(s *tenantStats, r *Report) float64 {
+		return float64(s.total)
+	}},
+	{"landlord_tenant_uptime_failures_total", "Uptime checks the tenant failed", "counter", func(s *tenantStats, r *Report) float64 {
+		return float64(s.failed)
+	}},
+}
+
+// WriteMetrics writes every checked tenant's uptime in the Prometheus text exposition format
+func (c *Checker) WriteMetrics(w io.Writer) error {
+	c.statsMu.RLock()
+	defer c.statsMu.RUnlock()
+
+	ids := make([]uuid.UUID, 0, len(c.stats))
+	for id := range c.stats {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	reports := make([]*Report, len(ids))
+	for i, id := range ids {
+		reports[i] = c.stats[id].report()
+	}
+
+	out := bufio.NewWriter(w)
+	for _, m := range metrics {
+		fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", m.name, m.help, m.name, m.kind)
+		for i, id := range ids {
+			stats := c.stats[id]
+			fmt.Fprintf(out, "%s{tenant_id=%q,tenant_name=\"%s\"} %g\n", m.name, id.String(), escapeLabel(stats.name), m.value(stats, reports[i]))
+		}
+	}
+	return out.Flush()
+}
+
+// escapeLabel escapes a label value as the exposition format requires
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,149 @@
+// Package uptime runs synthetic checks against ready tenants' endpoints. It keeps each tenant's
+// recent checks to report availability and latency, and marks tenants EndpointUnhealthy when their
+// checks keep failing.
+package uptime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// ConditionEndpointUnhealthy is the tenant condition set once a tenant fails its uptime checks
+// consecutively. It is separate from the reconciler's Degraded condition, so each clears only
+// what it set.
+const ConditionEndpointUnhealthy = "EndpointUnhealthy"
+
+// Reasons set on the EndpointUnhealthy condition
+const (
+	ReasonCheckFailed    = tenant.ReasonUptimeCheckFailed
+	ReasonCheckRecovered = tenant.ReasonUptimeCheckRecovered
+)
+
+// Manager is the history trigger recorded for uptime events
+const Manager = "uptime-check"
+
+// EndpointResult is the outcome of probing one tenant endpoint
+type EndpointResult struct {
+	Target     string  `json:"target"`
+	Up         bool    `json:"up"`
+	LatencyMS  float64 `json:"latency_ms"`
+	StatusCode int     `json:"status_code,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// Check is one uptime check of a tenant; it passes when every endpoint is up
+type Check struct {
+	CheckedAt time.Time        `json:"checked_at"`
+	Up        bool             `json:"up"`
+	LatencyMS float64          `json:"latency_ms"` // slowest endpoint
+	Endpoints []EndpointResult `json:"endpoints"`
+}
+
+// Error returns the first endpoint error of a failed check
+func (c *Check) Error() string {
+	for _, endpoint := range c.Endpoints {
+		if !endpoint.Up {
+			return fmt.Sprintf("%s: %s", endpoint.Target, endpoint.Error)
+		}
+	}
+	return ""
+}
+
+// Report summarizes a tenant's recent uptime checks
+type Report struct {
+	// Availability is the share of kept checks that passed, from 0 to 1
+	Availability        float64   `json:"availability"`
+	Checks              int       `json:"checks"`
+	Failures            int       `json:"failures"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	AverageLatencyMS    float64   `json:"average_latency_ms"`
+	LastCheck           *Check    `json:"last_check,omitempty"`
+	History             []Check   `json:"history"` // oldest first
+	Since               time.Time `json:"since"`
+}
+
+// Endpoints returns the compute endpoints recorded in t's observed config
+func Endpoints(t *tenant.Tenant) []compute.Endpoint {
+	raw, ok := t.ObservedConfig["endpoints"]
+	if !ok || raw == nil {
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var endpoints []compute.Endpoint
+	if err := json.Unmarshal(data, &endpoints); err != nil {
+		return nil
+	}
+	return endpoints
+}
+
+// probe checks one endpoint. Endpoints with an http or https URL or type get a GET and are up on
+// any response below 500; the rest are up once a TCP connection opens.
+func probe(ctx context.Context, client *http.Client, endpoint compute.Endpoint) EndpointResult {
+	host, port := endpoint.Address, endpoint.Port
+	scheme := endpoint.Type
+	if parsed, err := url.Parse(endpoint.URL); err == nil && parsed.Host != "" {
+		host = parsed.Hostname()
+		if p, err := strconv.Atoi(parsed.Port()); err == nil {
+			port = p
+		}
+		if parsed.Scheme == "http" || parsed.Scheme == "https" {
+			scheme = parsed.Scheme
+		}
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	start := time.Now()
+	result := EndpointResult{Target: "tcp://" + address}
+	if scheme == "http" || scheme == "https" {
+		result.Target = (&url.URL{Scheme: scheme, Host: address, Path: "/"}).String()
+		if parsed, err := url.Parse(endpoint.URL); err == nil && parsed.Scheme == scheme && parsed.Path != "" {
+			result.Target = endpoint.URL
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, result.Target, nil)
+		if err == nil {
+			var resp *http.Response
+			resp, err = client.Do(req)
+			if err == nil {
+				resp.Body.Close()
+				result.StatusCode = resp.StatusCode
+				if resp.StatusCode >= http.StatusInternalServerError {
+					err = fmt.Errorf("unexpected status code %d", resp.StatusCode)
+				}
+			}
+		}
+		result.LatencyMS = milliseconds(time.Since(start))
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		result.Up = true
+		return result
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	result.LatencyMS = milliseconds(time.Since(start))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	conn.Close()
+	result.Up = true
+	return result
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
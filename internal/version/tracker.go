@@ -0,0 +1,178 @@
+package version
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxComponents bounds the component versions kept; the least recently seen is forgotten first
+	maxComponents = 100
+
+	// maxEvents bounds the skew events kept
+	maxEvents = 50
+)
+
+// Component is a component version that has reported itself
+type Component struct {
+	Component string    `json:"component"`
+	Version   string    `json:"version"`
+	Skewed    bool      `json:"skewed"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	Reports   int64     `json:"reports"`
+}
+
+// SkewEvent records a component version first seen too far from this one
+type SkewEvent struct {
+	Component string    `json:"component"`
+	Version   string    `json:"version"`
+	Expected  string    `json:"expected"`
+	Message   string    `json:"message"`
+	Time      time.Time `json:"time"`
+}
+
+// Tracker keeps the versions of the components that report to this one, and warns once for each
+// version that is skewed from it. Versions are kept in memory, so they are forgotten on restart.
+type Tracker struct {
+	self   Info
+	logger *zap.Logger
+
+	mu         sync.Mutex
+	components map[string]*Component
+	events     []SkewEvent
+	skews      int64
+}
+
+// NewTracker creates a tracker that compares reported versions with self
+func NewTracker(self Info, logger *zap.Logger) *Tracker {
+	return &Tracker{
+		self:       self,
+		logger:     logger.With(zap.String("component", "version-tracker")),
+		components: make(map[string]*Component),
+	}
+}
+
+// Self returns the build the tracker compares against
+func (t *Tracker) Self() Info {
+	return t.self
+}
+
+// Observe records that component reported version. A version skewed from this one is logged and
+// recorded as an event the first time it is seen, and only then does Observe return true.
+func (t *Tracker) Observe(component, version string) bool {
+	if component == "" || version == "" {
+		return false
+	}
+	now := time.Now()
+	key := component + "@" + version
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if seen, ok := t.components[key]; ok {
+		seen.LastSeen = now
+		seen.Reports++
+		return false
+	}
+	if len(t.components) >= maxComponents {
+		t.forgetOldest()
+	}
+
+	skewed := Skewed(t.self.Version, version)
+	t.components[key] = &Component{Component: component, Version: version, Skewed: skewed, FirstSeen: now, LastSeen: now, Reports: 1}
+	if !skewed {
+		return false
+	}
+
+	event := SkewEvent{
+		Component: component,
+		Version:   version,
+		Expected:  t.self.Version,
+		Message:   fmt.Sprintf("%s %s is more than one minor version from %s %s", component, version, t.self.Component, t.self.Version),
+		Time:      now,
+	}
+	t.events = append(t.events, event)
+	if len(t.events) > maxEvents {
+		t.events = t.events[len(t.events)-maxEvents:]
+	}
+	t.skews++
+	t.logger.Warn("component version skew detected",
+		zap.String("peer_component", component),
+		zap.String("peer_version", version),
+		zap.String("version", t.self.Version))
+	return true
+}
+
+func (t *Tracker) forgetOldest() {
+	var oldest string
+	for key, c := range t.components {
+		if oldest == "" || c.LastSeen.Before(t.components[oldest].LastSeen) {
+			oldest = key
+		}
+	}
+	delete(t.components, oldest)
+}
+
+// Components returns the reported component versions, sorted by component and version
+func (t *Tracker) Components() []Component {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	components := make([]Component, 0, len(t.components))
+	for _, c := range t.components {
+		components = append(components, *c)
+	}
+	sort.Slice(components, func(i, j int) bool {
+		if components[i].Component != components[j].Component {
+			return components[i].Component < components[j].Component
+		}
+		return components[i].Version < components[j].Version
+	})
+	return components
+}
+
+// Events returns the kept skew events, oldest first
+func (t *Tracker) Events() []SkewEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]SkewEvent(nil), t.events...)
+}
+
+// WriteMetrics writes the build and the reported component versions in the Prometheus text
+// exposition format
+func (t *Tracker) WriteMetrics(w io.Writer) error {
+	components := t.Components()
+	t.mu.Lock()
+	skews := t.skews
+	t.mu.Unlock()
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "# HELP landlord_build_info The version this process was built from\n# TYPE landlord_build_info gauge\n")
+	fmt.Fprintf(out, "landlord_build_info{component=\"%s\",version=\"%s\",commit=\"%s\"} 1\n", escapeLabel(t.self.Component), escapeLabel(t.self.Version), escapeLabel(t.self.Commit))
+
+	fmt.Fprintf(out, "# HELP landlord_component_version_skewed Whether a reported component version is more than one minor version from this one\n# TYPE landlord_component_version_skewed gauge\n")
+	for _, c := range components {
+		value := 0
+		if c.Skewed {
+			value = 1
+		}
+		fmt.Fprintf(out, "landlord_component_version_skewed{component=\"%s\",version=\"%s\"} %d\n", escapeLabel(c.Component), escapeLabel(c.Version), value)
+	}
+
+	fmt.Fprintf(out, "# HELP landlord_version_skew_events_total Component versions first seen skewed from this one\n# TYPE landlord_version_skew_events_total counter\n")
+	fmt.Fprintf(out, "landlord_version_skew_events_total %d\n", skews)
+	return out.Flush()
+}
+
+// escapeLabel escapes a label value as the exposition format requires
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
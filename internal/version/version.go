@@ -0,0 +1,110 @@
+// Package version identifies the build of each landlord binary, and detects when the server,
+// workers and clients talking to each other were built from releases too far apart.
+package version
+
+import (
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// Version and Commit are set at build time:
+//
+//	go build -ldflags "-X github.com/jaxxstorm/landlord/internal/version.Version=v0.4.0 -X github.com/jaxxstorm/landlord/internal/version.Commit=$(git rev-parse HEAD)"
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+// Headers exchanged with the API. Clients send both on every request; the server answers with
+// HeaderVersion, so each side learns the other's version.
+const (
+	HeaderVersion   = "X-Landlord-Version"
+	HeaderComponent = "X-Landlord-Component"
+)
+
+// Components that report their version
+const (
+	ComponentServer = "server"
+	ComponentWorker = "worker"
+	ComponentCLI    = "cli"
+)
+
+// Info is the build of one component
+type Info struct {
+	Component string `json:"component"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+}
+
+// Current returns this binary's build, running as component. Without a Commit set at build time,
+// the VCS revision Go embeds in the binary is used.
+func Current(component string) Info {
+	info := Info{Component: component, Version: Version, Commit: Commit}
+	if info.Commit == "" {
+		if build, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range build.Settings {
+				if setting.Key == "vcs.revision" {
+					info.Commit = setting.Value
+				}
+			}
+		}
+	}
+	return info
+}
+
+// Skewed reports whether two versions are more than one minor version apart, or differ in their
+// major version. Versions that are not semantic versions, such as "dev", are never skewed.
+func Skewed(a, b string) bool {
+	aMajor, aMinor, ok := parse(a)
+	if !ok {
+		return false
+	}
+	bMajor, bMinor, ok := parse(b)
+	if !ok {
+		return false
+	}
+	if aMajor != bMajor {
+		return true
+	}
+	return aMinor-bMinor > 1 || bMinor-aMinor > 1
+}
+
+// parse returns the major and minor parts of a version such as v1.4.2 or 1.4.0-rc.1
+func parse(version string) (int, int, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(strings.SplitN(parts[1], "-", 2)[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// Transport sets the version headers of component on every request it sends
+func Transport(component string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{info: Current(component), base: base}
+}
+
+type transport struct {
+	info Info
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	req.Header.Set(HeaderComponent, t.info.Component)
+	req.Header.Set(HeaderVersion, t.info.Version)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,93 @@
+package version
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestSkewed(t *testing.T) {
+	for _, tc := range []struct {
+		a, b   string
+		skewed bool
+	}{
+		{"v1.4.0", "v1.5.3", false},
+		{"v1.4.0", "v1.3.0", false},
+		{"v1.4.0", "v1.6.0", true},
+		{"1.6.0-rc.1", "v1.4.2", true},
+		{"v1.4.0", "v2.4.0", true},
+		{"dev", "v1.4.0", false},
+		{"v1.4.0", "abc123", false},
+	} {
+		if got := Skewed(tc.a, tc.b); got != tc.skewed {
+			t.Errorf("Skewed(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.skewed)
+		}
+	}
+}
+
+func TestTrackerObserve(t *testing.T) {
+	tracker := NewTracker(Info{Component: ComponentServer, Version: "v1.4.0", Commit: "abc"}, zap.NewNop())
+
+	if tracker.Observe(ComponentWorker, "v1.3.1") || tracker.Observe(ComponentWorker, "v1.3.1") {
+		t.Fatal("expected a worker one minor version behind not to be skewed")
+	}
+	if !tracker.Observe(ComponentCLI, "v1.1.0") {
+		t.Fatal("expected the first report of a skewed version to warn")
+	}
+	if tracker.Observe(ComponentCLI, "v1.1.0") || tracker.Observe(ComponentCLI, "") {
+		t.Fatal("expected a skewed version to warn once")
+	}
+
+	components := tracker.Components()
+	if len(components) != 2 {
+		t.Fatalf("expected 2 component versions, got %+v", components)
+	}
+	if cli := components[0]; cli.Component != ComponentCLI || !cli.Skewed || cli.Reports != 2 {
+		t.Fatalf("unexpected cli version %+v", cli)
+	}
+	if worker := components[1]; worker.Component != ComponentWorker || worker.Skewed || worker.Reports != 2 {
+		t.Fatalf("unexpected worker version %+v", worker)
+	}
+
+	events := tracker.Events()
+	if len(events) != 1 || events[0].Component != ComponentCLI || events[0].Expected != "v1.4.0" {
+		t.Fatalf("expected one skew event for the cli, got %+v", events)
+	}
+
+	var metrics bytes.Buffer
+	if err := tracker.WriteMetrics(&metrics); err != nil {
+		t.Fatalf("WriteMetrics() error = %v", err)
+	}
+	for _, want := range []string{
+		`landlord_build_info{component="server",version="v1.4.0",commit="abc"} 1`,
+		`landlord_component_version_skewed{component="cli",version="v1.1.0"} 1`,
+		`landlord_component_version_skewed{component="worker",version="v1.3.1"} 0`,
+		"landlord_version_skew_events_total 1",
+	} {
+		if !strings.Contains(metrics.String(), want) {
+			t.Fatalf("expected metrics to contain %q, got:\n%s", want, metrics.String())
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var component, version string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		component, version = r.Header.Get(HeaderComponent), r.Header.Get(HeaderVersion)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport(ComponentWorker, nil)}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if component != ComponentWorker || version != Version {
+		t.Fatalf("expected the worker's version headers, got %q %q", component, version)
+	}
+}
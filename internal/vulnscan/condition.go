@@ -0,0 +1,52 @@
+package vulnscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// ConditionPassed is the tenant condition recording whether its image is within the critical threshold
+const ConditionPassed = "VulnerabilityScanPassed"
+
+// Reasons set on the VulnerabilityScanPassed condition
+const (
+	ReasonWithinThreshold   = tenant.ReasonWithinThreshold
+	ReasonThresholdExceeded = tenant.ReasonThresholdExceeded
+)
+
+// SetCondition records a scan summary as t's VulnerabilityScanPassed condition.
+// Returns true when the condition changed.
+func SetCondition(t *tenant.Tenant, summary *Summary, now time.Time) bool {
+	condition := tenant.Condition{
+		Type:    ConditionPassed,
+		Status:  tenant.ConditionTrue,
+		Reason:  ReasonWithinThreshold,
+		Message: summary.String(),
+	}
+	if summary.ExceedsThreshold() {
+		condition.Status = tenant.ConditionFalse
+		condition.Reason = ReasonThresholdExceeded
+		condition.Message = fmt.Sprintf("%s (max %d critical)", summary, summary.MaxCritical)
+	}
+	return t.SetCondition(condition, now)
+}
+
+// SummaryFromOutput extracts the scan summary from decoded workflow output, or nil when there is none
+func SummaryFromOutput(output map[string]interface{}) (*Summary, error) {
+	raw, ok := output[OutputKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var summary Summary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", OutputKey, err)
+	}
+	return &summary, nil
+}
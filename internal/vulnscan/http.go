@@ -0,0 +1,97 @@
+package vulnscan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+// HTTPScanner asks an external scanner API for an image's findings. It POSTs {"image": "<ref>"}
+// to the configured URL and expects a summary with counts per severity in return:
+//
+//	{"critical": 1, "high": 4, "medium": 10, "low": 2, "unknown": 0, "critical_ids": ["CVE-2024-1234"]}
+type HTTPScanner struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewHTTPScanner creates a scanner API client from configuration
+func NewHTTPScanner(cfg config.VulnerabilityScanConfig) *HTTPScanner {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultScanTimeout
+	}
+	return &HTTPScanner{
+		url:    cfg.URL,
+		token:  cfg.Token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns the scanner identifier
+func (s *HTTPScanner) Name() string {
+	return config.VulnerabilityScannerHTTP
+}
+
+// Scan requests a summary for image from the scanner API
+func (s *HTTPScanner) Scan(ctx context.Context, image string) (*Summary, error) {
+	body, err := json.Marshal(map[string]string{"image": image})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("scanner api: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		text, _ := io.ReadAll(io.LimitReader(resp.Body, maxScanOutput))
+		return nil, fmt.Errorf("scanner api returned %s: %s", resp.Status, strings.TrimSpace(string(text)))
+	}
+
+	var result struct {
+		Critical    int       `json:"critical"`
+		High        int       `json:"high"`
+		Medium      int       `json:"medium"`
+		Low         int       `json:"low"`
+		Unknown     int       `json:"unknown"`
+		CriticalIDs []string  `json:"critical_ids"`
+		ScannedAt   time.Time `json:"scanned_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode scanner api response: %w", err)
+	}
+
+	summary := &Summary{
+		Critical:  result.Critical,
+		High:      result.High,
+		Medium:    result.Medium,
+		Low:       result.Low,
+		Unknown:   result.Unknown,
+		ScannedAt: result.ScannedAt,
+	}
+	for _, id := range result.CriticalIDs {
+		if len(summary.CriticalIDs) == maxCriticalIDs {
+			break
+		}
+		summary.CriticalIDs = append(summary.CriticalIDs, id)
+	}
+	return summary, nil
+}
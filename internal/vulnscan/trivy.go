@@ -0,0 +1,89 @@
+package vulnscan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+const (
+	defaultTrivyPath   = "trivy"
+	defaultScanTimeout = 5 * time.Minute
+
+	// maxScanOutput bounds the scanner output quoted in an error
+	maxScanOutput = 512
+)
+
+// TrivyScanner scans images by running "trivy image --format json"
+type TrivyScanner struct {
+	path    string
+	timeout time.Duration
+}
+
+// NewTrivyScanner creates a trivy scanner from configuration
+func NewTrivyScanner(cfg config.VulnerabilityScanConfig) *TrivyScanner {
+	s := &TrivyScanner{path: cfg.TrivyPath, timeout: cfg.Timeout}
+	if s.path == "" {
+		s.path = defaultTrivyPath
+	}
+	if s.timeout <= 0 {
+		s.timeout = defaultScanTimeout
+	}
+	return s
+}
+
+// Name returns the scanner identifier
+func (s *TrivyScanner) Name() string {
+	return config.VulnerabilityScannerTrivy
+}
+
+// Scan runs trivy against image and counts its findings by severity
+func (s *TrivyScanner) Scan(ctx context.Context, image string) (*Summary, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.path, "image", "--quiet", "--format", "json", "--scanners", "vuln", image)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		output := strings.TrimSpace(stderr.String())
+		if len(output) > maxScanOutput {
+			output = output[:maxScanOutput] + "..."
+		}
+		if output == "" {
+			return nil, fmt.Errorf("trivy: %w", err)
+		}
+		return nil, fmt.Errorf("trivy: %w: %s", err, output)
+	}
+	return parseTrivyReport(stdout.Bytes())
+}
+
+// parseTrivyReport counts the vulnerabilities in trivy's JSON report
+func parseTrivyReport(data []byte) (*Summary, error) {
+	var report struct {
+		Results []struct {
+			Vulnerabilities []struct {
+				VulnerabilityID string `json:"VulnerabilityID"`
+				Severity        string `json:"Severity"`
+			} `json:"Vulnerabilities"`
+		} `json:"Results"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("decode trivy report: %w", err)
+	}
+
+	summary := &Summary{}
+	for _, result := range report.Results {
+		for _, v := range result.Vulnerabilities {
+			summary.count(v.Severity, v.VulnerabilityID)
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,155 @@
+// Package vulnscan scans tenant images for known vulnerabilities before they are provisioned.
+// Workers run the scan with a Gate, which can block provisioning when an image has more critical
+// vulnerabilities than allowed; the findings summary travels back in the workflow output and is
+// recorded on the tenant by the controller.
+package vulnscan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+)
+
+// OutputKey is the workflow output field that carries the scan summary
+const OutputKey = "vulnerability_scan"
+
+// maxCriticalIDs bounds the critical vulnerability IDs kept in a summary
+const maxCriticalIDs = 10
+
+// ErrThresholdExceeded is returned by Gate.Check when provisioning is blocked by critical findings
+var ErrThresholdExceeded = errors.New("image exceeds the critical vulnerability threshold")
+
+// Scanner scans one image reference
+type Scanner interface {
+	Name() string
+	Scan(ctx context.Context, image string) (*Summary, error)
+}
+
+// Summary is the outcome of scanning one image
+type Summary struct {
+	Image   string `json:"image"`
+	Scanner string `json:"scanner"`
+
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+
+	// CriticalIDs lists up to ten critical vulnerability IDs, such as CVE numbers
+	CriticalIDs []string `json:"critical_ids,omitempty"`
+
+	// MaxCritical is the threshold the image was checked against
+	MaxCritical int `json:"max_critical"`
+
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+// ExceedsThreshold reports whether the image has more critical vulnerabilities than allowed
+func (s *Summary) ExceedsThreshold() bool {
+	return s.Critical > s.MaxCritical
+}
+
+// String formats the counts for status messages and conditions
+func (s *Summary) String() string {
+	return fmt.Sprintf("%s: %d critical, %d high, %d medium, %d low, %d unknown", s.Image, s.Critical, s.High, s.Medium, s.Low, s.Unknown)
+}
+
+// addCritical counts a critical finding, keeping its ID while there is room
+func (s *Summary) addCritical(id string) {
+	s.Critical++
+	if id == "" || len(s.CriticalIDs) >= maxCriticalIDs {
+		return
+	}
+	for _, existing := range s.CriticalIDs {
+		if existing == id {
+			return
+		}
+	}
+	s.CriticalIDs = append(s.CriticalIDs, id)
+}
+
+// count adds one finding of the given severity
+func (s *Summary) count(severity, id string) {
+	switch severity {
+	case "CRITICAL", "critical", "Critical":
+		s.addCritical(id)
+	case "HIGH", "high", "High":
+		s.High++
+	case "MEDIUM", "medium", "Medium":
+		s.Medium++
+	case "LOW", "low", "Low", "NEGLIGIBLE", "negligible":
+		s.Low++
+	default:
+		s.Unknown++
+	}
+}
+
+// Gate scans images and applies the configured critical threshold
+type Gate struct {
+	scanner     Scanner
+	maxCritical int
+	block       bool
+	logger      *zap.Logger
+}
+
+// New creates a gate from configuration
+func New(cfg config.VulnerabilityScanConfig, logger *zap.Logger) *Gate {
+	var scanner Scanner
+	if cfg.Scanner == config.VulnerabilityScannerHTTP {
+		scanner = NewHTTPScanner(cfg)
+	} else {
+		scanner = NewTrivyScanner(cfg)
+	}
+	return NewGate(scanner, cfg.MaxCritical, cfg.BlockProvisioning, logger)
+}
+
+// NewGate creates a gate around a scanner. When block is set, images over maxCritical and
+// images that cannot be scanned fail the check.
+func NewGate(scanner Scanner, maxCritical int, block bool, logger *zap.Logger) *Gate {
+	return &Gate{
+		scanner:     scanner,
+		maxCritical: maxCritical,
+		block:       block,
+		logger:      logger.With(zap.String("component", "vulnerability-scan")),
+	}
+}
+
+// Check scans image. The summary is nil when the scan could not run and the gate does not block.
+// A nil Gate scans nothing.
+func (g *Gate) Check(ctx context.Context, image string) (*Summary, error) {
+	if g == nil || image == "" {
+		return nil, nil
+	}
+
+	summary, err := g.scanner.Scan(ctx, image)
+	if err != nil {
+		if g.block {
+			return nil, fmt.Errorf("scan image %s: %w", image, err)
+		}
+		g.logger.Warn("image scan failed, continuing without findings", zap.String("image", image), zap.Error(err))
+		return nil, nil
+	}
+	summary.Image = image
+	summary.Scanner = g.scanner.Name()
+	summary.MaxCritical = g.maxCritical
+	if summary.ScannedAt.IsZero() {
+		summary.ScannedAt = time.Now().UTC()
+	}
+
+	g.logger.Info("image scanned",
+		zap.String("image", image),
+		zap.Int("critical", summary.Critical),
+		zap.Int("high", summary.High),
+		zap.Bool("exceeds_threshold", summary.ExceedsThreshold()))
+
+	if g.block && summary.ExceedsThreshold() {
+		return summary, fmt.Errorf("%w: %s (max %d critical)", ErrThresholdExceeded, summary, g.maxCritical)
+	}
+	return summary, nil
+}
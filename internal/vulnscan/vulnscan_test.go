@@ -0,0 +1,164 @@
+package vulnscan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+type fakeScanner struct {
+	summary *Summary
+	err     error
+}
+
+func (f *fakeScanner) Name() string { return "fake" }
+
+func (f *fakeScanner) Scan(ctx context.Context, image string) (*Summary, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	copied := *f.summary
+	return &copied, nil
+}
+
+func TestParseTrivyReport(t *testing.T) {
+	report := `{
+		"Results": [
+			{"Target": "debian", "Vulnerabilities": [
+				{"VulnerabilityID": "CVE-1", "Severity": "CRITICAL"},
+				{"VulnerabilityID": "CVE-1", "Severity": "CRITICAL"},
+				{"VulnerabilityID": "CVE-2", "Severity": "HIGH"},
+				{"VulnerabilityID": "CVE-3", "Severity": "MEDIUM"}
+			]},
+			{"Target": "app", "Vulnerabilities": [
+				{"VulnerabilityID": "CVE-4", "Severity": "LOW"},
+				{"VulnerabilityID": "CVE-5", "Severity": "UNKNOWN"}
+			]},
+			{"Target": "clean"}
+		]
+	}`
+	summary, err := parseTrivyReport([]byte(report))
+	if err != nil {
+		t.Fatalf("parseTrivyReport() error = %v", err)
+	}
+	if summary.Critical != 2 || summary.High != 1 || summary.Medium != 1 || summary.Low != 1 || summary.Unknown != 1 {
+		t.Fatalf("unexpected counts: %+v", summary)
+	}
+	if len(summary.CriticalIDs) != 1 || summary.CriticalIDs[0] != "CVE-1" {
+		t.Fatalf("expected deduplicated critical IDs, got %v", summary.CriticalIDs)
+	}
+
+	if _, err := parseTrivyReport([]byte("not json")); err == nil {
+		t.Fatal("expected an error for a malformed report")
+	}
+}
+
+func TestGateCheck(t *testing.T) {
+	ctx := context.Background()
+	dirty := &fakeScanner{summary: &Summary{Critical: 3, High: 1}}
+
+	// Recording only: the summary is returned even over the threshold
+	summary, err := NewGate(dirty, 0, false, zap.NewNop()).Check(ctx, "nginx:1.0")
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if summary.Image != "nginx:1.0" || summary.Scanner != "fake" || !summary.ExceedsThreshold() || summary.ScannedAt.IsZero() {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	// Blocking: over the threshold fails, at the threshold passes
+	if _, err := NewGate(dirty, 2, true, zap.NewNop()).Check(ctx, "nginx:1.0"); !errors.Is(err, ErrThresholdExceeded) {
+		t.Fatalf("expected ErrThresholdExceeded, got %v", err)
+	}
+	if _, err := NewGate(dirty, 3, true, zap.NewNop()).Check(ctx, "nginx:1.0"); err != nil {
+		t.Fatalf("expected image at the threshold to pass, got %v", err)
+	}
+
+	// Scan failures only fail the check when blocking
+	broken := &fakeScanner{err: errors.New("registry unreachable")}
+	if summary, err := NewGate(broken, 0, false, zap.NewNop()).Check(ctx, "nginx:1.0"); err != nil || summary != nil {
+		t.Fatalf("expected a failed scan to be skipped, got %+v, %v", summary, err)
+	}
+	if _, err := NewGate(broken, 0, true, zap.NewNop()).Check(ctx, "nginx:1.0"); err == nil || errors.Is(err, ErrThresholdExceeded) {
+		t.Fatalf("expected a scan error when blocking, got %v", err)
+	}
+
+	var gate *Gate
+	if summary, err := gate.Check(ctx, "nginx:1.0"); summary != nil || err != nil {
+		t.Fatalf("expected a nil gate to scan nothing, got %+v, %v", summary, err)
+	}
+}
+
+func TestHTTPScanner(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer scan-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["image"] != "nginx:1.27" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"critical":     1,
+			"high":         2,
+			"medium":       3,
+			"critical_ids": []string{"CVE-2024-1234"},
+		})
+	}))
+	defer srv.Close()
+
+	scanner := NewHTTPScanner(config.VulnerabilityScanConfig{URL: srv.URL, Token: "scan-token"})
+	summary, err := scanner.Scan(context.Background(), "nginx:1.27")
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if summary.Critical != 1 || summary.High != 2 || summary.Medium != 3 || len(summary.CriticalIDs) != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	scanner = NewHTTPScanner(config.VulnerabilityScanConfig{URL: srv.URL})
+	if _, err := scanner.Scan(context.Background(), "nginx:1.27"); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestConditionFromOutput(t *testing.T) {
+	raw, err := json.Marshal(map[string]interface{}{
+		OutputKey: Summary{Image: "nginx:1.27", Critical: 0, High: 4, MaxCritical: 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output map[string]interface{}
+	if err := json.Unmarshal(raw, &output); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := SummaryFromOutput(output)
+	if err != nil || summary == nil {
+		t.Fatalf("SummaryFromOutput() = %+v, %v", summary, err)
+	}
+	tn := &tenant.Tenant{}
+	if !SetCondition(tn, summary, time.Now()) {
+		t.Fatal("expected condition to be added")
+	}
+	if c := tn.Condition(ConditionPassed); c.Status != tenant.ConditionTrue || c.Reason != ReasonWithinThreshold {
+		t.Fatalf("unexpected condition: %+v", c)
+	}
+
+	if summary, err := SummaryFromOutput(map[string]interface{}{"status": "ok"}); summary != nil || err != nil {
+		t.Fatalf("expected no summary, got %+v, %v", summary, err)
+	}
+}
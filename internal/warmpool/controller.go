@@ -0,0 +1,272 @@
+package warmpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// Controller keeps every project's warm pools at their configured size. Each pass starts at most
+// a pool's refill rate of new warm tenants, and retires warm tenants that failed, that were built
+// from an older version of their template, or that a pool no longer needs. Retired tenants are
+// archived and then deleted, as a delete request would.
+type Controller struct {
+	tenants  tenant.Repository
+	projects project.Store
+	interval time.Duration
+	logger   *zap.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewController creates a warm pool controller
+func NewController(tenants tenant.Repository, projects project.Store, cfg config.WarmPoolConfig, logger *zap.Logger) *Controller {
+	return &Controller{
+		tenants:  tenants,
+		projects: projects,
+		interval: cfg.Interval,
+		logger:   logger.With(zap.String("component", "warm-pool-controller")),
+	}
+}
+
+// Start refills warm pools in the background until Stop is called
+func (c *Controller) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.interval <= 0 {
+		return fmt.Errorf("warm pool interval must be positive")
+	}
+	if c.cancel != nil {
+		return fmt.Errorf("warm pool controller already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.done = make(chan struct{})
+	go c.run(ctx, c.done)
+
+	c.logger.Info("warm pool controller started", zap.Duration("interval", c.interval))
+	return nil
+}
+
+// Stop stops the background passes and waits for a running pass to finish
+func (c *Controller) Stop() {
+	c.mu.Lock()
+	cancel, done := c.cancel, c.done
+	c.cancel, c.done = nil, nil
+	c.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	c.logger.Info("warm pool controller stopped")
+}
+
+func (c *Controller) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if err := c.Refill(ctx); err != nil && ctx.Err() == nil {
+			c.logger.Warn("warm pool pass failed", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refill runs one pass over every project's warm pools
+func (c *Controller) Refill(ctx context.Context) error {
+	tenants, err := c.tenants.ListTenants(ctx, tenant.ListFilters{})
+	if err != nil {
+		return fmt.Errorf("list tenants: %w", err)
+	}
+	warm := make(map[uuid.UUID][]*tenant.Tenant)
+	for _, t := range tenants {
+		if IsWarm(t) {
+			warm[t.ProjectID] = append(warm[t.ProjectID], t)
+		}
+	}
+
+	organizations, err := c.projects.ListOrganizations(ctx)
+	if err != nil {
+		return fmt.Errorf("list organizations: %w", err)
+	}
+	for _, org := range organizations {
+		projects, err := c.projects.ListProjects(ctx, org.Name)
+		if err != nil {
+			return fmt.Errorf("list projects of %s: %w", org.Name, err)
+		}
+		for _, p := range projects {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if len(p.Settings.WarmPools) == 0 && len(warm[p.ID]) == 0 {
+				continue
+			}
+			c.refillProject(ctx, p, warm[p.ID])
+		}
+	}
+	return nil
+}
+
+// refillProject retires the project's unusable warm tenants and starts the ones its pools are missing
+func (c *Controller) refillProject(ctx context.Context, p *project.Project, warm []*tenant.Tenant) {
+	sort.Slice(warm, func(i, j int) bool { return warm[i].CreatedAt.Before(warm[j].CreatedAt) })
+
+	live := make(map[string]int)
+	for _, t := range warm {
+		template := t.Annotations[AnnotationTemplate]
+		code, reason := c.retireReason(p, template, t, live[template])
+		if code == "" {
+			if t.Status != tenant.StatusArchiving && t.Status != tenant.StatusDeleting {
+				live[template]++
+			}
+			continue
+		}
+		if err := c.retire(ctx, t, code, reason); err != nil {
+			if errors.Is(err, tenant.ErrVersionConflict) || errors.Is(err, tenant.ErrTenantLocked) {
+				// The tenant is being claimed or changed; the next pass sees its new state
+				continue
+			}
+			c.logger.Warn("failed to retire warm tenant", zap.String("tenant_id", t.ID.String()), zap.Error(err))
+		}
+	}
+
+	for _, pool := range p.Settings.WarmPools {
+		missing := pool.Size - live[pool.Template]
+		if pool.RefillRate > 0 && missing > pool.RefillRate {
+			missing = pool.RefillRate
+		}
+		for i := 0; i < missing; i++ {
+			if err := c.start(ctx, p, pool.Template); err != nil {
+				c.logger.Warn("failed to start warm tenant",
+					zap.String("organization", p.Organization),
+					zap.String("project", p.Name),
+					zap.String("template", pool.Template),
+					zap.Error(err))
+				break
+			}
+		}
+	}
+}
+
+// retireReason returns the reason code and message for retiring t, or "" to keep it. live is the
+// number of the pool's tenants already kept.
+func (c *Controller) retireReason(p *project.Project, template string, t *tenant.Tenant, live int) (tenant.ReasonCode, string) {
+	// Only settled tenants can be archived; the rest are looked at again once they settle
+	if t.Status != tenant.StatusReady && t.Status != tenant.StatusFailed {
+		return "", ""
+	}
+	pool, ok := p.Settings.WarmPool(template)
+	if !ok {
+		return tenant.ReasonWarmPoolRemoved, fmt.Sprintf("Warm pool %s was removed", template)
+	}
+	if t.Status == tenant.StatusFailed {
+		return tenant.ReasonWarmTenantFailed, "Warm tenant failed to provision"
+	}
+	if live >= pool.Size {
+		return tenant.ReasonWarmPoolOversized, fmt.Sprintf("Warm pool %s is above its size of %d", template, pool.Size)
+	}
+	config, _ := p.Template(template)
+	want, err := tenant.ComputeConfigHash(config)
+	if err != nil {
+		return "", ""
+	}
+	if have, err := tenant.ComputeConfigHash(t.DesiredConfig); err == nil && have != want {
+		return tenant.ReasonWarmPoolTemplateChanged, fmt.Sprintf("Template %s changed", template)
+	}
+	return "", ""
+}
+
+// retire archives t and deletes it once its compute is gone
+func (c *Controller) retire(ctx context.Context, t *tenant.Tenant, code tenant.ReasonCode, reason string) error {
+	release, err := c.tenants.LockTenant(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	transition := tenant.NewStateTransition(t, tenant.StatusArchiving, reason, Manager).WithReasonCode(code)
+
+	t.Status = tenant.StatusArchiving
+	t.StatusMessage = reason
+	t.WorkflowExecutionID = nil
+	t.WorkflowStartedAt = nil
+	t.WorkflowSubState = nil
+	t.WorkflowRetryCount = nil
+	t.WorkflowErrorMessage = nil
+	t.UpdatedAt = time.Now()
+	if t.Annotations == nil {
+		t.Annotations = map[string]string{}
+	}
+	t.Annotations["landlord/delete_after_archive"] = "true"
+
+	if err := c.tenants.UpdateTenant(ctx, t); err != nil {
+		return err
+	}
+	if err := c.tenants.RecordStateTransition(ctx, transition); err != nil {
+		c.logger.Warn("failed to record warm tenant retirement", zap.String("tenant_id", t.ID.String()), zap.Error(err))
+	}
+	c.logger.Info("warm tenant retired", zap.String("tenant_id", t.ID.String()), zap.String("tenant_name", t.Name), zap.String("reason", reason))
+	return nil
+}
+
+// start creates a warm tenant from the project's template for the reconciler to provision
+func (c *Controller) start(ctx context.Context, p *project.Project, template string) error {
+	config, ok := p.Template(template)
+	if !ok {
+		return fmt.Errorf("project %s/%s has no template %q", p.Organization, p.Name, template)
+	}
+	desired := make(map[string]interface{}, len(config))
+	for key, value := range config {
+		desired[key] = value
+	}
+
+	now := time.Now()
+	t := &tenant.Tenant{
+		ID:            uuid.New(),
+		Name:          fmt.Sprintf("warm-%s-%s", template, strings.SplitN(uuid.NewString(), "-", 2)[0]),
+		ProjectID:     p.ID,
+		Status:        tenant.StatusRequested,
+		StatusMessage: fmt.Sprintf("Warming for template %s", template),
+		DesiredConfig: desired,
+		Labels:        tenant.NewManagedLabels(p.Organization+"/"+p.Name, Manager, template),
+		Annotations:   map[string]string{AnnotationTemplate: template},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Version:       1,
+	}
+	t.UpdateManagedFields(nil, Manager, tenant.ManagedFieldOperationCreate, now)
+
+	if err := c.tenants.CreateTenant(ctx, t); err != nil {
+		return err
+	}
+	c.logger.Info("warm tenant started",
+		zap.String("tenant_id", t.ID.String()),
+		zap.String("tenant_name", t.Name),
+		zap.String("organization", p.Organization),
+		zap.String("project", p.Name),
+		zap.String("template", template))
+	return nil
+}
@@ -0,0 +1,201 @@
+package warmpool_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/project"
+	projectmemory "github.com/jaxxstorm/landlord/internal/project/memory"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/tenant/memory"
+	"github.com/jaxxstorm/landlord/internal/warmpool"
+)
+
+func newController(t *testing.T, pool project.WarmPool) (*warmpool.Controller, *memory.Repository, project.Store, *project.Project) {
+	t.Helper()
+	ctx := context.Background()
+
+	projects := projectmemory.New()
+	p, err := projects.GetProjectByID(ctx, project.DefaultProjectID)
+	if err != nil {
+		t.Fatalf("GetProjectByID() error = %v", err)
+	}
+	p.Settings.Templates = map[string]map[string]interface{}{"small": {"image": "nginx:latest"}}
+	p.Settings.WarmPools = []project.WarmPool{pool}
+	if err := projects.UpdateProject(ctx, p); err != nil {
+		t.Fatalf("UpdateProject() error = %v", err)
+	}
+
+	repo := memory.New()
+	controller := warmpool.NewController(repo, projects, config.WarmPoolConfig{Enabled: true, Interval: time.Minute}, zap.NewNop())
+	return controller, repo, projects, p
+}
+
+func warmTenants(t *testing.T, repo tenant.Repository) []*tenant.Tenant {
+	t.Helper()
+	tenants, err := repo.ListTenants(context.Background(), tenant.ListFilters{})
+	if err != nil {
+		t.Fatalf("ListTenants() error = %v", err)
+	}
+	var warm []*tenant.Tenant
+	for _, candidate := range tenants {
+		if warmpool.IsWarm(candidate) && candidate.Status != tenant.StatusArchiving {
+			warm = append(warm, candidate)
+		}
+	}
+	return warm
+}
+
+// markReady stands in for the reconciler provisioning every requested warm tenant
+func markReady(t *testing.T, repo tenant.Repository) {
+	t.Helper()
+	for _, warm := range warmTenants(t, repo) {
+		if warm.Status != tenant.StatusRequested {
+			continue
+		}
+		warm.Status = tenant.StatusReady
+		if err := repo.UpdateTenant(context.Background(), warm); err != nil {
+			t.Fatalf("UpdateTenant() error = %v", err)
+		}
+	}
+}
+
+func TestRefillRespectsSizeAndRate(t *testing.T) {
+	controller, repo, _, _ := newController(t, project.WarmPool{Template: "small", Size: 3, RefillRate: 2})
+	ctx := context.Background()
+
+	if err := controller.Refill(ctx); err != nil {
+		t.Fatalf("Refill() error = %v", err)
+	}
+	warm := warmTenants(t, repo)
+	if len(warm) != 2 {
+		t.Fatalf("expected the refill rate to cap the first pass at 2 tenants, got %d", len(warm))
+	}
+	if warm[0].Status != tenant.StatusRequested || warm[0].DesiredConfig["image"] != "nginx:latest" || !strings.HasPrefix(warm[0].Name, "warm-small-") {
+		t.Fatalf("unexpected warm tenant %+v", warm[0])
+	}
+
+	if err := controller.Refill(ctx); err != nil {
+		t.Fatalf("Refill() error = %v", err)
+	}
+	if err := controller.Refill(ctx); err != nil {
+		t.Fatalf("Refill() error = %v", err)
+	}
+	if got := len(warmTenants(t, repo)); got != 3 {
+		t.Fatalf("expected the pool to stop at its size of 3, got %d", got)
+	}
+}
+
+func TestRefillRetiresFailedAndStaleTenants(t *testing.T) {
+	controller, repo, projects, p := newController(t, project.WarmPool{Template: "small", Size: 2})
+	ctx := context.Background()
+
+	if err := controller.Refill(ctx); err != nil {
+		t.Fatalf("Refill() error = %v", err)
+	}
+	markReady(t, repo)
+	warm := warmTenants(t, repo)
+	warm[0].Status = tenant.StatusFailed
+	if err := repo.UpdateTenant(ctx, warm[0]); err != nil {
+		t.Fatalf("UpdateTenant() error = %v", err)
+	}
+
+	if err := controller.Refill(ctx); err != nil {
+		t.Fatalf("Refill() error = %v", err)
+	}
+	failed, err := repo.GetTenantByID(ctx, warm[0].ID)
+	if err != nil {
+		t.Fatalf("GetTenantByID() error = %v", err)
+	}
+	if failed.Status != tenant.StatusArchiving || failed.Annotations["landlord/delete_after_archive"] != "true" {
+		t.Fatalf("expected the failed warm tenant to be retired, got %s", failed.Status)
+	}
+	if got := len(warmTenants(t, repo)); got != 2 {
+		t.Fatalf("expected a replacement for the failed tenant, got %d warm tenants", got)
+	}
+
+	// Changing the template retires ready tenants built from the old one
+	markReady(t, repo)
+	p.Settings.Templates["small"] = map[string]interface{}{"image": "nginx:1.27"}
+	if err := projects.UpdateProject(ctx, p); err != nil {
+		t.Fatalf("UpdateProject() error = %v", err)
+	}
+	if err := controller.Refill(ctx); err != nil {
+		t.Fatalf("Refill() error = %v", err)
+	}
+	for _, current := range warmTenants(t, repo) {
+		if current.DesiredConfig["image"] != "nginx:1.27" {
+			t.Fatalf("expected stale warm tenants to be replaced, found %s on %v", current.Name, current.DesiredConfig["image"])
+		}
+	}
+}
+
+func TestClaim(t *testing.T) {
+	controller, repo, _, p := newController(t, project.WarmPool{Template: "small", Size: 2})
+	ctx := context.Background()
+
+	request := &tenant.Tenant{
+		Name:          "acme",
+		Labels:        map[string]string{"team": "web"},
+		DesiredConfig: map[string]interface{}{"image": "nginx:latest"},
+		Owner:         "web-team",
+	}
+	if _, err := controller.Claim(ctx, p, "small", request, nil); !errors.Is(err, warmpool.ErrNoWarmTenant) {
+		t.Fatalf("expected ErrNoWarmTenant from an empty pool, got %v", err)
+	}
+
+	if err := controller.Refill(ctx); err != nil {
+		t.Fatalf("Refill() error = %v", err)
+	}
+	markReady(t, repo)
+	computeNames := map[string]bool{}
+	for _, warm := range warmTenants(t, repo) {
+		computeNames[warm.Name] = true
+	}
+
+	if _, err := controller.Claim(ctx, p, "small", request, func(*tenant.Tenant) bool { return false }); !errors.Is(err, warmpool.ErrNoWarmTenant) {
+		t.Fatalf("expected ErrNoWarmTenant when no warm tenant is accepted, got %v", err)
+	}
+
+	claimed, err := controller.Claim(ctx, p, "small", request, nil)
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if claimed.Name != "acme" || claimed.Status != tenant.StatusReady {
+		t.Fatalf("expected a warm tenant to become a ready acme, got %s %s", claimed.Name, claimed.Status)
+	}
+	if warmpool.IsWarm(claimed) || !computeNames[claimed.ComputeName()] || claimed.Labels["team"] != "web" {
+		t.Fatalf("unexpected claimed tenant annotations %v labels %v", claimed.Annotations, claimed.Labels)
+	}
+	if claimed.Owner != "web-team" {
+		t.Fatalf("expected the claimed tenant to be owned by web-team, got %q", claimed.Owner)
+	}
+	delete(computeNames, claimed.ComputeName())
+
+	// A config beyond the template is applied by an update
+	request = &tenant.Tenant{
+		Name:          "globex",
+		DesiredConfig: map[string]interface{}{"image": "nginx:latest", "env": map[string]interface{}{"REGION": "eu"}},
+	}
+	claimed, err = controller.Claim(ctx, p, "small", request, nil)
+	if err != nil {
+		t.Fatalf("Claim() error = %v", err)
+	}
+	if claimed.Status != tenant.StatusUpdating || !computeNames[claimed.ComputeName()] {
+		t.Fatalf("expected the second warm tenant to be updated, got %s on %s", claimed.Status, claimed.ComputeName())
+	}
+
+	if _, err := controller.Claim(ctx, p, "small", &tenant.Tenant{Name: "initech"}, nil); !errors.Is(err, warmpool.ErrNoWarmTenant) {
+		t.Fatalf("expected ErrNoWarmTenant once the pool is drained, got %v", err)
+	}
+	if _, err := controller.Claim(ctx, &project.Project{ID: uuid.New()}, "small", request, nil); !errors.Is(err, warmpool.ErrNoWarmTenant) {
+		t.Fatalf("expected ErrNoWarmTenant for a project without the pool, got %v", err)
+	}
+}
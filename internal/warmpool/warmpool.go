@@ -0,0 +1,137 @@
+// Package warmpool keeps tenants pre-provisioned from project templates. A create request that
+// names a template with a warm pool takes one of the pool's ready tenants instead of waiting for
+// new compute, and the controller starts a replacement in the background.
+package warmpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+)
+
+// AnnotationTemplate marks an unclaimed warm tenant with the template it was provisioned from
+const AnnotationTemplate = "landlord/warm_pool"
+
+// Manager is the field manager and history trigger recorded for warm pool changes
+const Manager = "warm-pool"
+
+// ErrNoWarmTenant is returned by Claim when the pool has no ready tenant to hand out
+var ErrNoWarmTenant = errors.New("no warm tenant available")
+
+// IsWarm reports whether t is an unclaimed warm tenant
+func IsWarm(t *tenant.Tenant) bool {
+	_, ok := t.Annotations[AnnotationTemplate]
+	return ok
+}
+
+// Claim hands one of the project's ready warm tenants for template to t, the tenant a create
+// request would otherwise store. The warm tenant keeps its ID and compute, which stays under the
+// warm tenant's name, and takes t's name, labels, annotations and configuration. When that
+// configuration differs from the template the tenant is updated in place; otherwise it is ready
+// at once. accept may reject warm tenants that cannot serve t, such as ones on another provider.
+// Returns ErrNoWarmTenant when no warm tenant can be claimed and ErrTenantExists when t's name is
+// taken.
+func (c *Controller) Claim(ctx context.Context, p *project.Project, template string, t *tenant.Tenant, accept func(warm *tenant.Tenant) bool) (*tenant.Tenant, error) {
+	if _, ok := p.Settings.WarmPool(template); !ok {
+		return nil, ErrNoWarmTenant
+	}
+	candidates, err := c.tenants.ListTenants(ctx, tenant.ListFilters{
+		ProjectIDs:  []uuid.UUID{p.ID},
+		Statuses:    []tenant.Status{tenant.StatusReady},
+		Annotations: map[string]string{AnnotationTemplate: template},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list warm tenants: %w", err)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].CreatedAt.Before(candidates[j].CreatedAt) })
+
+	for _, warm := range candidates {
+		if accept != nil && !accept(warm) {
+			continue
+		}
+		claimed, err := c.claim(ctx, warm, t)
+		switch {
+		case err == nil:
+			return claimed, nil
+		case errors.Is(err, tenant.ErrVersionConflict), errors.Is(err, tenant.ErrTenantLocked):
+			// Another request claimed or changed this one first
+			continue
+		default:
+			return nil, err
+		}
+	}
+	return nil, ErrNoWarmTenant
+}
+
+func (c *Controller) claim(ctx context.Context, warm, t *tenant.Tenant) (*tenant.Tenant, error) {
+	release, err := c.tenants.LockTenant(ctx, warm.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	warmHash, err := tenant.ComputeConfigHash(warm.DesiredConfig)
+	if err != nil {
+		return nil, fmt.Errorf("hash warm tenant config: %w", err)
+	}
+	hash, err := tenant.ComputeConfigHash(t.DesiredConfig)
+	if err != nil {
+		return nil, fmt.Errorf("hash tenant config: %w", err)
+	}
+
+	template := warm.Annotations[AnnotationTemplate]
+	status := tenant.StatusReady
+	reason := fmt.Sprintf("Claimed as %s from warm pool %s", t.Name, template)
+	if hash != warmHash {
+		status = tenant.StatusUpdating
+	}
+	transition := tenant.NewStateTransition(warm, status, reason, Manager).WithReasonCode(tenant.ReasonWarmPoolClaimed)
+
+	claimed := warm.Clone()
+	claimed.Name = t.Name
+	claimed.Labels = t.Labels
+	claimed.Annotations = make(map[string]string, len(t.Annotations)+1)
+	for key, value := range t.Annotations {
+		claimed.Annotations[key] = value
+	}
+	claimed.Annotations[tenant.AnnotationComputeName] = warm.ComputeName()
+	claimed.DesiredConfig = t.DesiredConfig
+	claimed.ManagedFields = t.ManagedFields
+	claimed.Owner = t.Owner
+	claimed.Collaborators = t.Collaborators
+	claimed.Status = status
+	claimed.StatusMessage = reason
+	if status == tenant.StatusUpdating {
+		claimed.StatusMessage = fmt.Sprintf("Applying tenant configuration to warm tenant from pool %s", template)
+		claimed.WorkflowExecutionID = nil
+		claimed.WorkflowStartedAt = nil
+		claimed.WorkflowSubState = nil
+		claimed.WorkflowRetryCount = nil
+		claimed.WorkflowErrorMessage = nil
+	}
+	claimed.UpdatedAt = time.Now()
+
+	if err := c.tenants.UpdateTenant(ctx, claimed); err != nil {
+		return nil, err
+	}
+
+	transition.DesiredStateSnapshot = claimed.DesiredConfig
+	if err := c.tenants.RecordStateTransition(ctx, transition); err != nil {
+		c.logger.Warn("failed to record warm tenant claim", zap.String("tenant_id", claimed.ID.String()), zap.Error(err))
+	}
+	c.logger.Info("warm tenant claimed",
+		zap.String("tenant_id", claimed.ID.String()),
+		zap.String("tenant_name", claimed.Name),
+		zap.String("compute_name", claimed.ComputeName()),
+		zap.String("template", template),
+		zap.String("status", string(claimed.Status)))
+	return claimed, nil
+}
@@ -145,6 +145,16 @@ type DeleteTenantOutput struct {
 	Error       *compute.ComputeError          `json:"error,omitempty"`
 }
 
+// CancelExecution cancels the compute operations a stopped workflow execution still has in
+// flight. Returns how many were cancelled; zero when the compute manager cannot cancel them.
+func (c *ComputeWorkflowClient) CancelExecution(workflowExecutionID, reason string) int {
+	canceller, ok := c.computeManager.(ExecutionCanceller)
+	if !ok {
+		return 0
+	}
+	return canceller.CancelExecution(workflowExecutionID, reason)
+}
+
 // GetExecutionStatusInput represents input for a status query
 type GetExecutionStatusInput struct {
 	ExecutionID string `json:"execution_id"`
@@ -29,3 +29,10 @@ type ComputeManager interface {
 	// MapProviderErrorToComputeError maps provider errors to standard compute errors
 	MapProviderErrorToComputeError(err error) *compute.ComputeError
 }
+
+// ExecutionCanceller is implemented by compute managers that can cancel the compute operations a
+// workflow execution has in flight
+type ExecutionCanceller interface {
+	// CancelExecution cancels the execution's in-flight operations and returns how many there were
+	CancelExecution(workflowExecutionID, reason string) int
+}
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/jaxxstorm/landlord/internal/resolution"
 	"github.com/jaxxstorm/landlord/internal/tenant"
 	"go.uber.org/zap"
 )
@@ -33,6 +34,7 @@ type CachedComputeProviderResolver struct {
 	client   LandlordClient
 	repo     tenant.Repository
 	override string
+	rules    *resolution.Resolver
 	ttl      time.Duration
 	logger   *zap.Logger
 
@@ -55,6 +57,11 @@ func NewCachedComputeProviderResolver(client LandlordClient, repo tenant.Reposit
 	}
 }
 
+// SetRules sets the resolution rules applied to tenants that do not name a compute provider.
+func (r *CachedComputeProviderResolver) SetRules(rules *resolution.Resolver) {
+	r.rules = rules
+}
+
 // ResolveProvider resolves the compute provider name for a tenant.
 func (r *CachedComputeProviderResolver) ResolveProvider(ctx context.Context, tenantID, tenantUUID string) (string, error) {
 	if r.override != "" {
@@ -125,7 +132,12 @@ func (r *CachedComputeProviderResolver) resolveFromRepo(ctx context.Context, ten
 	if err != nil {
 		return "", fmt.Errorf("fetch tenant from repo: %w", err)
 	}
-	return providerFromMaps(t.DesiredConfig, t.Labels, t.Annotations), nil
+	return r.rules.Resolve(resolution.Subject{
+		Name:        t.Name,
+		Config:      t.DesiredConfig,
+		Labels:      t.Labels,
+		Annotations: t.Annotations,
+	}, ""), nil
 }
 
 func (r *CachedComputeProviderResolver) resolveFromAPI(ctx context.Context, tenantUUID string) (string, error) {
@@ -133,31 +145,10 @@ func (r *CachedComputeProviderResolver) resolveFromAPI(ctx context.Context, tena
 	if err != nil {
 		return "", fmt.Errorf("fetch tenant from api: %w", err)
 	}
-	return providerFromMaps(tenantInfo.DesiredConfig, tenantInfo.Labels, tenantInfo.Annotations), nil
-}
-
-func providerFromMaps(config map[string]interface{}, labels map[string]string, annotations map[string]string) string {
-	if config != nil {
-		if provider, ok := config["compute_provider"]; ok {
-			if value, ok := provider.(string); ok {
-				return value
-			}
-		}
-		if provider, ok := config["compute_provider_type"]; ok {
-			if value, ok := provider.(string); ok {
-				return value
-			}
-		}
-	}
-	if labels != nil {
-		if provider, ok := labels["compute_provider"]; ok {
-			return provider
-		}
-	}
-	if annotations != nil {
-		if provider, ok := annotations["compute_provider"]; ok {
-			return provider
-		}
-	}
-	return ""
+	return r.rules.Resolve(resolution.Subject{
+		Name:        tenantInfo.Name,
+		Config:      tenantInfo.DesiredConfig,
+		Labels:      tenantInfo.Labels,
+		Annotations: tenantInfo.Annotations,
+	}, ""), nil
 }
@@ -31,10 +31,22 @@ func (f *fakeTenantRepo) UpdateTenant(ctx context.Context, t *tenant.Tenant) err
 	return nil
 }
 
+func (f *fakeTenantRepo) FenceWorkflowTrigger(ctx context.Context, t *tenant.Tenant, trigger func(ctx context.Context, t *tenant.Tenant) error) error {
+	return trigger(ctx, t)
+}
+
+func (f *fakeTenantRepo) LockTenant(ctx context.Context, id uuid.UUID) (func(), error) {
+	return func() {}, nil
+}
+
 func (f *fakeTenantRepo) ListTenants(ctx context.Context, filters tenant.ListFilters) ([]*tenant.Tenant, error) {
 	return nil, nil
 }
 
+func (f *fakeTenantRepo) ListTenantSummaries(ctx context.Context, filters tenant.ListFilters) ([]*tenant.Summary, error) {
+	return nil, nil
+}
+
 func (f *fakeTenantRepo) ListTenantsForReconciliation(ctx context.Context) ([]*tenant.Tenant, error) {
 	return nil, nil
 }
@@ -47,7 +59,7 @@ func (f *fakeTenantRepo) RecordStateTransition(ctx context.Context, transition *
 	return nil
 }
 
-func (f *fakeTenantRepo) GetStateHistory(ctx context.Context, tenantID uuid.UUID) ([]*tenant.StateTransition, error) {
+func (f *fakeTenantRepo) GetStateHistory(ctx context.Context, tenantID uuid.UUID, filters tenant.HistoryFilters) ([]*tenant.StateTransition, error) {
 	return nil, nil
 }
 
@@ -9,4 +9,21 @@ var (
 	ErrWorkflowNotFound  = errors.New("workflow not found")
 	ErrExecutionNotFound = errors.New("execution not found")
 	ErrExecutionFailed   = errors.New("workflow execution failed")
+
+	// ErrProviderDisabled is returned when a disabled provider is asked to start an execution
+	ErrProviderDisabled = errors.New("workflow provider disabled")
+
+	// ErrLandlordTenantNotFound is returned when the landlord API has no tenant with the requested UUID
+	ErrLandlordTenantNotFound = errors.New("tenant not found in landlord api")
+
+	// ErrReconfigureUnsupported is returned when a provider cannot change its configuration at runtime
+	ErrReconfigureUnsupported = errors.New("workflow provider does not support reconfiguration")
+
+	// ErrHeartbeatTimeout is returned when a compute operation that reports heartbeats stops
+	// reporting them, so it is treated as stalled rather than slow
+	ErrHeartbeatTimeout = errors.New("compute operation stalled")
+
+	// ErrOperationTimeout is returned when a compute operation runs past its maximum duration,
+	// however often it heartbeats
+	ErrOperationTimeout = errors.New("compute operation timed out")
 )
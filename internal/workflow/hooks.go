@@ -0,0 +1,392 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"go.uber.org/zap"
+)
+
+// HooksConfigKey is the desired_config key holding tenant hook declarations
+const HooksConfigKey = "hooks"
+
+// Hook phases
+const (
+	HookPhasePreProvision  = "pre_provision"
+	HookPhasePostProvision = "post_provision"
+)
+
+// Hook types
+const (
+	HookTypeHTTP      = "http"
+	HookTypeContainer = "container"
+)
+
+// Hook result statuses
+const (
+	HookStatusSucceeded = "succeeded"
+	HookStatusFailed    = "failed"
+)
+
+const (
+	defaultHookTimeout         = 30 * time.Second
+	defaultHookInitialInterval = time.Second
+	defaultHookMaxInterval     = 30 * time.Second
+	defaultHookBackoffRate     = 2.0
+	maxHookOutputBytes         = 4096
+)
+
+// TenantHooks are the hook steps declared for a tenant under desired_config.hooks
+type TenantHooks struct {
+	PreProvision  []HookSpec `json:"pre_provision,omitempty"`
+	PostProvision []HookSpec `json:"post_provision,omitempty"`
+}
+
+// HookSpec declares a single hook step
+type HookSpec struct {
+	Name      string             `json:"name"`
+	Type      string             `json:"type"`
+	Timeout   string             `json:"timeout,omitempty"` // Go duration, per attempt (default 30s)
+	Retry     *HookRetryPolicy   `json:"retry,omitempty"`
+	HTTP      *HTTPHookSpec      `json:"http,omitempty"`
+	Container *ContainerHookSpec `json:"container,omitempty"`
+}
+
+// HookRetryPolicy controls how failed hook attempts are retried
+type HookRetryPolicy struct {
+	MaxAttempts     int     `json:"max_attempts,omitempty"`     // default 1 (no retries)
+	InitialInterval string  `json:"initial_interval,omitempty"` // default 1s
+	MaxInterval     string  `json:"max_interval,omitempty"`     // default 30s
+	BackoffRate     float64 `json:"backoff_rate,omitempty"`     // default 2
+}
+
+// HTTPHookSpec calls an HTTP endpoint; any 2xx response (or one of ExpectedStatus) succeeds
+type HTTPHookSpec struct {
+	URL            string            `json:"url"`
+	Method         string            `json:"method,omitempty"` // default POST
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           json.RawMessage   `json:"body,omitempty"`
+	ExpectedStatus []int             `json:"expected_status,omitempty"`
+}
+
+// ContainerHookSpec runs a one-off container through the tenant's compute provider; exit code 0 succeeds
+type ContainerHookSpec struct {
+	Image   string            `json:"image"`
+	Command []string          `json:"command,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// HookResult records the outcome of a hook step
+type HookResult struct {
+	Name        string    `json:"name"`
+	Phase       string    `json:"phase"`
+	Type        string    `json:"type"`
+	Status      string    `json:"status"`
+	Attempts    int       `json:"attempts"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	Error       string    `json:"error,omitempty"`
+	Output      string    `json:"output,omitempty"`
+}
+
+// ParseHooks extracts and validates hook declarations from a desired config.
+// Returns nil when no hooks are declared.
+func ParseHooks(desiredConfig map[string]interface{}) (*TenantHooks, error) {
+	raw, ok := desiredConfig[HooksConfigKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("encode hooks: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var hooks TenantHooks
+	if err := decoder.Decode(&hooks); err != nil {
+		return nil, fmt.Errorf("invalid hooks: %w", err)
+	}
+
+	if err := hooks.Validate(); err != nil {
+		return nil, err
+	}
+	return &hooks, nil
+}
+
+// Validate checks every hook declaration
+func (h *TenantHooks) Validate() error {
+	for phase, specs := range map[string][]HookSpec{
+		HookPhasePreProvision:  h.PreProvision,
+		HookPhasePostProvision: h.PostProvision,
+	} {
+		seen := make(map[string]bool, len(specs))
+		for i := range specs {
+			if err := specs[i].Validate(); err != nil {
+				return fmt.Errorf("hooks.%s[%d]: %w", phase, i, err)
+			}
+			if seen[specs[i].Name] {
+				return fmt.Errorf("hooks.%s[%d]: duplicate hook name %q", phase, i, specs[i].Name)
+			}
+			seen[specs[i].Name] = true
+		}
+	}
+	return nil
+}
+
+// Validate checks a single hook declaration
+func (h *HookSpec) Validate() error {
+	if strings.TrimSpace(h.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	switch h.Type {
+	case HookTypeHTTP:
+		if h.HTTP == nil || h.HTTP.URL == "" {
+			return fmt.Errorf("http.url is required for http hooks")
+		}
+		if !strings.HasPrefix(h.HTTP.URL, "http://") && !strings.HasPrefix(h.HTTP.URL, "https://") {
+			return fmt.Errorf("http.url must be an http or https URL")
+		}
+	case HookTypeContainer:
+		if h.Container == nil || h.Container.Image == "" {
+			return fmt.Errorf("container.image is required for container hooks")
+		}
+	default:
+		return fmt.Errorf("type must be %q or %q", HookTypeHTTP, HookTypeContainer)
+	}
+
+	if _, err := parseHookDuration(h.Timeout, defaultHookTimeout); err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+	if h.Retry != nil {
+		if h.Retry.MaxAttempts < 0 {
+			return fmt.Errorf("retry.max_attempts must be non-negative")
+		}
+		if h.Retry.BackoffRate < 0 {
+			return fmt.Errorf("retry.backoff_rate must be non-negative")
+		}
+		if _, err := parseHookDuration(h.Retry.InitialInterval, defaultHookInitialInterval); err != nil {
+			return fmt.Errorf("invalid retry.initial_interval: %w", err)
+		}
+		if _, err := parseHookDuration(h.Retry.MaxInterval, defaultHookMaxInterval); err != nil {
+			return fmt.Errorf("invalid retry.max_interval: %w", err)
+		}
+	}
+	return nil
+}
+
+func parseHookDuration(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return d, nil
+}
+
+// HookRunner executes hook steps with per-hook timeouts and retries
+type HookRunner struct {
+	httpClient *http.Client
+	logger     *zap.Logger
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// NewHookRunner creates a hook runner; a nil httpClient uses http.DefaultClient
+func NewHookRunner(httpClient *http.Client, logger *zap.Logger) *HookRunner {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &HookRunner{
+		httpClient: httpClient,
+		logger:     logger.With(zap.String("component", "workflow-hook-runner")),
+		sleep:      sleepContext,
+	}
+}
+
+// RunPhase runs hooks in declaration order and stops at the first failure.
+// Results are returned for every hook that ran, including the failed one.
+// jobRunner may be nil when the compute provider cannot run container jobs.
+func (r *HookRunner) RunPhase(ctx context.Context, tenantID, phase string, hooks []HookSpec, jobRunner compute.JobRunner) ([]HookResult, error) {
+	results := make([]HookResult, 0, len(hooks))
+	for i := range hooks {
+		result := r.runHook(ctx, tenantID, phase, &hooks[i], jobRunner)
+		results = append(results, result)
+		if result.Status != HookStatusSucceeded {
+			return results, fmt.Errorf("%s hook %q failed after %d attempt(s): %s", phase, result.Name, result.Attempts, result.Error)
+		}
+	}
+	return results, nil
+}
+
+func (r *HookRunner) runHook(ctx context.Context, tenantID, phase string, hook *HookSpec, jobRunner compute.JobRunner) HookResult {
+	timeout, _ := parseHookDuration(hook.Timeout, defaultHookTimeout)
+	attempts, backoff, maxBackoff, rate := 1, defaultHookInitialInterval, defaultHookMaxInterval, defaultHookBackoffRate
+	if hook.Retry != nil {
+		if hook.Retry.MaxAttempts > 0 {
+			attempts = hook.Retry.MaxAttempts
+		}
+		backoff, _ = parseHookDuration(hook.Retry.InitialInterval, defaultHookInitialInterval)
+		maxBackoff, _ = parseHookDuration(hook.Retry.MaxInterval, defaultHookMaxInterval)
+		if hook.Retry.BackoffRate > 0 {
+			rate = hook.Retry.BackoffRate
+		}
+	}
+
+	result := HookResult{
+		Name:      hook.Name,
+		Phase:     phase,
+		Type:      hook.Type,
+		StartedAt: time.Now(),
+	}
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		result.Attempts = attempt
+
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		output, err := r.execute(attemptCtx, tenantID, hook, jobRunner)
+		cancel()
+
+		result.Output = truncateHookOutput(output)
+		if err == nil {
+			result.Status = HookStatusSucceeded
+			result.Error = ""
+			break
+		}
+
+		result.Status = HookStatusFailed
+		result.Error = err.Error()
+		r.logger.Warn("hook attempt failed",
+			zap.String("tenant_id", tenantID),
+			zap.String("phase", phase),
+			zap.String("hook", hook.Name),
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", attempts),
+			zap.Error(err),
+		)
+
+		if attempt == attempts || ctx.Err() != nil {
+			break
+		}
+		if err := r.sleep(ctx, backoff); err != nil {
+			break
+		}
+		backoff = time.Duration(float64(backoff) * rate)
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	result.CompletedAt = time.Now()
+	r.logger.Info("hook completed",
+		zap.String("tenant_id", tenantID),
+		zap.String("phase", phase),
+		zap.String("hook", hook.Name),
+		zap.String("status", result.Status),
+		zap.Int("attempts", result.Attempts),
+	)
+	return result
+}
+
+func (r *HookRunner) execute(ctx context.Context, tenantID string, hook *HookSpec, jobRunner compute.JobRunner) (string, error) {
+	switch hook.Type {
+	case HookTypeHTTP:
+		return r.executeHTTP(ctx, hook.HTTP)
+	case HookTypeContainer:
+		if jobRunner == nil {
+			return "", fmt.Errorf("compute provider does not support container hooks")
+		}
+		result, err := jobRunner.RunJob(ctx, tenantID, &compute.JobSpec{
+			Name:    hook.Name,
+			Image:   hook.Container.Image,
+			Command: hook.Container.Command,
+			Env:     hook.Container.Env,
+		})
+		if err != nil {
+			return "", err
+		}
+		if result.ExitCode != 0 {
+			return result.Output, fmt.Errorf("container exited with code %d", result.ExitCode)
+		}
+		return result.Output, nil
+	default:
+		return "", fmt.Errorf("unsupported hook type %q", hook.Type)
+	}
+}
+
+func (r *HookRunner) executeHTTP(ctx context.Context, spec *HTTPHookSpec) (string, error) {
+	method := spec.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	var body io.Reader
+	if len(spec.Body) > 0 {
+		body = bytes.NewReader(spec.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, spec.URL, body)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range spec.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxHookOutputBytes))
+	if !expectedHookStatus(resp.StatusCode, spec.ExpectedStatus) {
+		return string(respBody), fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return string(respBody), nil
+}
+
+func expectedHookStatus(code int, expected []int) bool {
+	if len(expected) == 0 {
+		return code >= 200 && code < 300
+	}
+	for _, want := range expected {
+		if code == want {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateHookOutput(output string) string {
+	if len(output) <= maxHookOutputBytes {
+		return output
+	}
+	return output[len(output)-maxHookOutputBytes:]
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,176 @@
+package workflow
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeJobRunner struct {
+	exitCode int
+	jobs     []*compute.JobSpec
+}
+
+func (f *fakeJobRunner) RunJob(ctx context.Context, tenantID string, job *compute.JobSpec) (*compute.JobResult, error) {
+	f.jobs = append(f.jobs, job)
+	return &compute.JobResult{ExitCode: f.exitCode, Output: "done"}, nil
+}
+
+func TestParseHooks(t *testing.T) {
+	hooks, err := ParseHooks(map[string]interface{}{
+		"image": "nginx:latest",
+		"hooks": map[string]interface{}{
+			"pre_provision": []interface{}{
+				map[string]interface{}{
+					"name":      "create-schema",
+					"type":      "container",
+					"timeout":   "2m",
+					"container": map[string]interface{}{"image": "migrate:latest", "command": []interface{}{"up"}},
+				},
+			},
+			"post_provision": []interface{}{
+				map[string]interface{}{
+					"name":  "smoke-test",
+					"type":  "http",
+					"retry": map[string]interface{}{"max_attempts": 3, "initial_interval": "5s"},
+					"http":  map[string]interface{}{"url": "https://example.com/health", "method": "GET"},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("parse hooks: %v", err)
+	}
+	if len(hooks.PreProvision) != 1 || hooks.PreProvision[0].Container.Image != "migrate:latest" {
+		t.Fatalf("unexpected pre_provision hooks: %+v", hooks.PreProvision)
+	}
+	if len(hooks.PostProvision) != 1 || hooks.PostProvision[0].Retry.MaxAttempts != 3 {
+		t.Fatalf("unexpected post_provision hooks: %+v", hooks.PostProvision)
+	}
+
+	none, err := ParseHooks(map[string]interface{}{"image": "nginx:latest"})
+	if err != nil || none != nil {
+		t.Fatalf("expected no hooks, got %+v, %v", none, err)
+	}
+}
+
+func TestParseHooksRejectsInvalidHooks(t *testing.T) {
+	cases := map[string]map[string]interface{}{
+		"missing name":   {"type": "http", "http": map[string]interface{}{"url": "http://example.com"}},
+		"unknown type":   {"name": "a", "type": "lambda"},
+		"missing url":    {"name": "a", "type": "http"},
+		"bad url":        {"name": "a", "type": "http", "http": map[string]interface{}{"url": "ftp://example.com"}},
+		"missing image":  {"name": "a", "type": "container", "container": map[string]interface{}{}},
+		"bad timeout":    {"name": "a", "type": "http", "timeout": "soon", "http": map[string]interface{}{"url": "http://example.com"}},
+		"unknown field":  {"name": "a", "type": "http", "retries": 3, "http": map[string]interface{}{"url": "http://example.com"}},
+		"negative retry": {"name": "a", "type": "http", "retry": map[string]interface{}{"max_attempts": -1}, "http": map[string]interface{}{"url": "http://example.com"}},
+	}
+
+	for name, hook := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseHooks(map[string]interface{}{
+				"hooks": map[string]interface{}{"pre_provision": []interface{}{hook}},
+			})
+			if err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestHookRunnerRetriesHTTPHook(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("seeded"))
+	}))
+	defer server.Close()
+
+	runner := NewHookRunner(server.Client(), zaptest.NewLogger(t))
+	runner.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	results, err := runner.RunPhase(context.Background(), "tenant-1", HookPhasePostProvision, []HookSpec{{
+		Name:  "seed",
+		Type:  HookTypeHTTP,
+		Retry: &HookRetryPolicy{MaxAttempts: 3},
+		HTTP:  &HTTPHookSpec{URL: server.URL, Headers: map[string]string{"X-Token": "secret"}},
+	}}, nil)
+	if err != nil {
+		t.Fatalf("run phase: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if results[0].Status != HookStatusSucceeded || results[0].Attempts != 3 || results[0].Output != "seeded" {
+		t.Fatalf("unexpected result: %+v", results[0])
+	}
+}
+
+func TestHookRunnerStopsAtFirstFailure(t *testing.T) {
+	jobs := &fakeJobRunner{exitCode: 2}
+	runner := NewHookRunner(nil, zaptest.NewLogger(t))
+	runner.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	results, err := runner.RunPhase(context.Background(), "tenant-1", HookPhasePreProvision, []HookSpec{
+		{Name: "migrate", Type: HookTypeContainer, Retry: &HookRetryPolicy{MaxAttempts: 2}, Container: &ContainerHookSpec{Image: "migrate:latest"}},
+		{Name: "never", Type: HookTypeContainer, Container: &ContainerHookSpec{Image: "other:latest"}},
+	}, jobs)
+	if err == nil {
+		t.Fatal("expected hook failure")
+	}
+	if len(results) != 1 || results[0].Status != HookStatusFailed || results[0].Attempts != 2 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+	if len(jobs.jobs) != 2 {
+		t.Fatalf("expected 2 job runs, got %d", len(jobs.jobs))
+	}
+}
+
+func TestHookRunnerAppliesTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	runner := NewHookRunner(server.Client(), zaptest.NewLogger(t))
+	results, err := runner.RunPhase(context.Background(), "tenant-1", HookPhasePreProvision, []HookSpec{{
+		Name:    "slow",
+		Type:    HookTypeHTTP,
+		Timeout: "50ms",
+		HTTP:    &HTTPHookSpec{URL: server.URL},
+	}}, nil)
+	if err == nil {
+		t.Fatal("expected timeout failure")
+	}
+	if results[0].Status != HookStatusFailed {
+		t.Fatalf("unexpected result: %+v", results[0])
+	}
+}
+
+func TestHookRunnerRequiresJobRunnerForContainerHooks(t *testing.T) {
+	runner := NewHookRunner(nil, zaptest.NewLogger(t))
+	_, err := runner.RunPhase(context.Background(), "tenant-1", HookPhasePreProvision, []HookSpec{{
+		Name:      "migrate",
+		Type:      HookTypeContainer,
+		Container: &ContainerHookSpec{Image: "migrate:latest"},
+	}}, nil)
+	if err == nil {
+		t.Fatal("expected error without job runner")
+	}
+}
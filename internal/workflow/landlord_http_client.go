@@ -1,30 +1,83 @@
 package workflow
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/jaxxstorm/landlord/internal/apiversion"
+	"github.com/jaxxstorm/landlord/internal/version"
 )
 
+const (
+	defaultLandlordAPITimeout      = 10 * time.Second
+	defaultLandlordAPIRetryBackoff = 200 * time.Millisecond
+	maxLandlordAPIRetryBackoff     = 5 * time.Second
+)
+
+// HTTPLandlordClientOptions configures an HTTPLandlordClient.
+type HTTPLandlordClientOptions struct {
+	// APIKey is sent as a bearer token when set.
+	APIKey string
+
+	// Timeout bounds each request. Defaults to 10s.
+	Timeout time.Duration
+
+	// MaxRetries is how many times a lookup is retried after a network error, 429 or 5xx.
+	MaxRetries int
+
+	// RetryBackoff is the base delay between retries. Each retry waits a random delay of up to
+	// RetryBackoff doubled per attempt, capped at 5s. Defaults to 200ms.
+	RetryBackoff time.Duration
+
+	// NegativeCacheTTL is how long a failed lookup is remembered and returned without calling the
+	// API again, so a flapping API server does not stall every compute resolution. Zero disables it.
+	NegativeCacheTTL time.Duration
+}
+
 // HTTPLandlordClient fetches tenant data from the landlord HTTP API.
 type HTTPLandlordClient struct {
 	baseURL    string
 	httpClient *http.Client
+	opts       HTTPLandlordClientOptions
 	logger     *zap.Logger
+
+	// versions warns when the API server's version is skewed from this worker's
+	versions *version.Tracker
+
+	mu       sync.Mutex
+	failures map[string]landlordLookupFailure
+}
+
+// landlordLookupFailure is a remembered failed tenant lookup
+type landlordLookupFailure struct {
+	err       error
+	expiresAt time.Time
 }
 
 // NewHTTPLandlordClient creates a new HTTP client for the landlord API.
-func NewHTTPLandlordClient(baseURL string, logger *zap.Logger) *HTTPLandlordClient {
+func NewHTTPLandlordClient(baseURL string, opts HTTPLandlordClientOptions, logger *zap.Logger) *HTTPLandlordClient {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultLandlordAPITimeout
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = defaultLandlordAPIRetryBackoff
+	}
 	return &HTTPLandlordClient{
 		baseURL:    apiversion.NormalizeBaseURL(baseURL),
-		httpClient: &http.Client{Timeout: 10 * time.Second},
+		httpClient: &http.Client{Timeout: opts.Timeout, Transport: version.Transport(version.ComponentWorker, nil)},
+		opts:       opts,
 		logger:     logger.With(zap.String("component", "landlord-http-client")),
+		versions:   version.NewTracker(version.Current(version.ComponentWorker), logger),
+		failures:   make(map[string]landlordLookupFailure),
 	}
 }
 
@@ -34,26 +87,173 @@ func (c *HTTPLandlordClient) GetTenant(ctx context.Context, tenantUUID string) (
 		return nil, fmt.Errorf("tenant UUID is required")
 	}
 
+	if err := c.cachedFailure(tenantUUID); err != nil {
+		return nil, err
+	}
+
+	tenant, err := c.getTenantWithRetry(ctx, tenantUUID)
+	if err != nil {
+		// A cancelled caller says nothing about the API's health
+		if ctx.Err() == nil {
+			c.rememberFailure(tenantUUID, err)
+		}
+		return nil, err
+	}
+
+	c.forgetFailure(tenantUUID)
+	return tenant, nil
+}
+
+// getTenantWithRetry fetches a tenant, retrying transient failures with jittered backoff
+func (c *HTTPLandlordClient) getTenantWithRetry(ctx context.Context, tenantUUID string) (*LandlordTenant, error) {
+	for attempt := 0; ; attempt++ {
+		tenant, retryable, err := c.fetchTenant(ctx, tenantUUID)
+		if err == nil {
+			return tenant, nil
+		}
+		if !retryable || attempt >= c.opts.MaxRetries {
+			if attempt > 0 {
+				return nil, fmt.Errorf("%w (after %d attempts)", err, attempt+1)
+			}
+			return nil, err
+		}
+
+		delay := c.retryDelay(attempt)
+		c.logger.Debug("tenant lookup failed, retrying",
+			zap.String("tenant_uuid", tenantUUID),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("request tenant: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// fetchTenant makes a single tenant request and reports whether a failure is worth retrying
+func (c *HTTPLandlordClient) fetchTenant(ctx context.Context, tenantUUID string) (*LandlordTenant, bool, error) {
 	url := fmt.Sprintf("%s/tenants/%s", c.baseURL, tenantUUID)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return nil, false, fmt.Errorf("create request: %w", err)
+	}
+	if c.opts.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.opts.APIKey)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request tenant: %w", err)
+		return nil, ctx.Err() == nil, fmt.Errorf("request tenant: %w", err)
 	}
 	defer resp.Body.Close()
+	c.versions.Observe(version.ComponentServer, resp.Header.Get(version.HeaderVersion))
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, false, fmt.Errorf("%w: %s", ErrLandlordTenantNotFound, tenantUUID)
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		return nil, true, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	default:
+		return nil, false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	var tenant LandlordTenant
 	if err := json.NewDecoder(resp.Body).Decode(&tenant); err != nil {
-		return nil, fmt.Errorf("decode tenant: %w", err)
+		return nil, false, fmt.Errorf("decode tenant: %w", err)
+	}
+
+	return &tenant, false, nil
+}
+
+// ReportStep posts the start or finish of a workflow step to the landlord API. It is not
+// retried, so a slow or unavailable API server delays the workflow by at most one request.
+func (c *HTTPLandlordClient) ReportStep(ctx context.Context, report StepReport) error {
+	if report.ExecutionID == "" {
+		return fmt.Errorf("execution ID is required")
 	}
+	body, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("encode step: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/executions/%s/steps", c.baseURL, url.PathEscape(report.ExecutionID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.opts.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.opts.APIKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("report step: %w", err)
+	}
+	defer resp.Body.Close()
+	c.versions.Observe(version.ComponentServer, resp.Header.Get(version.HeaderVersion))
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ServerVersions returns the API server versions this client has seen
+func (c *HTTPLandlordClient) ServerVersions() []version.Component {
+	return c.versions.Components()
+}
+
+// retryDelay returns a random delay of up to the base backoff doubled per attempt ("full jitter")
+func (c *HTTPLandlordClient) retryDelay(attempt int) time.Duration {
+	ceiling := c.opts.RetryBackoff
+	for i := 0; i < attempt && ceiling < maxLandlordAPIRetryBackoff; i++ {
+		ceiling *= 2
+	}
+	ceiling = min(ceiling, maxLandlordAPIRetryBackoff)
+	return time.Duration(rand.Int64N(int64(ceiling))) + 1
+}
+
+// cachedFailure returns the remembered error for a tenant whose lookup recently failed
+func (c *HTTPLandlordClient) cachedFailure(tenantUUID string) error {
+	if c.opts.NegativeCacheTTL <= 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	failure, ok := c.failures[tenantUUID]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(failure.expiresAt) {
+		delete(c.failures, tenantUUID)
+		return nil
+	}
+	return fmt.Errorf("tenant lookup failed recently, retrying after %s: %w", failure.expiresAt.Format(time.RFC3339), failure.err)
+}
+
+func (c *HTTPLandlordClient) rememberFailure(tenantUUID string, err error) {
+	if c.opts.NegativeCacheTTL <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.failures[tenantUUID] = landlordLookupFailure{
+		err:       err,
+		expiresAt: time.Now().Add(c.opts.NegativeCacheTTL),
+	}
+}
 
-	return &tenant, nil
+func (c *HTTPLandlordClient) forgetFailure(tenantUUID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.failures, tenantUUID)
 }
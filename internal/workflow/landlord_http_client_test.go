@@ -0,0 +1,120 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/jaxxstorm/landlord/internal/version"
+)
+
+// flappingLandlordAPI answers tenant lookups with the given statuses in turn, then 200
+func flappingLandlordAPI(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(calls.Add(1))
+		if r.Header.Get("Authorization") != "Bearer secret" || r.URL.Path != "/v1/tenants/tenant-uuid" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if call <= len(statuses) {
+			w.WriteHeader(statuses[call-1])
+			return
+		}
+		json.NewEncoder(w).Encode(LandlordTenant{
+			Name:          "acme",
+			DesiredConfig: map[string]interface{}{"compute_provider": "docker"},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestHTTPLandlordClientRetriesTransientFailures(t *testing.T) {
+	server, calls := flappingLandlordAPI(t, http.StatusBadGateway, http.StatusTooManyRequests)
+	client := NewHTTPLandlordClient(server.URL, HTTPLandlordClientOptions{
+		APIKey:       "secret",
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	}, zaptest.NewLogger(t))
+
+	tenant, err := client.GetTenant(context.Background(), "tenant-uuid")
+	require.NoError(t, err)
+	require.Equal(t, "acme", tenant.Name)
+	require.Equal(t, int32(3), calls.Load())
+}
+
+func TestHTTPLandlordClientDoesNotRetryNotFound(t *testing.T) {
+	server, calls := flappingLandlordAPI(t, http.StatusNotFound)
+	client := NewHTTPLandlordClient(server.URL, HTTPLandlordClientOptions{
+		APIKey:       "secret",
+		MaxRetries:   3,
+		RetryBackoff: time.Millisecond,
+	}, zaptest.NewLogger(t))
+
+	_, err := client.GetTenant(context.Background(), "tenant-uuid")
+	require.ErrorIs(t, err, ErrLandlordTenantNotFound)
+	require.Equal(t, int32(1), calls.Load())
+}
+
+func TestHTTPLandlordClientCachesFailures(t *testing.T) {
+	server, calls := flappingLandlordAPI(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+	client := NewHTTPLandlordClient(server.URL, HTTPLandlordClientOptions{
+		APIKey:           "secret",
+		MaxRetries:       1,
+		RetryBackoff:     time.Millisecond,
+		NegativeCacheTTL: time.Minute,
+	}, zaptest.NewLogger(t))
+
+	_, err := client.GetTenant(context.Background(), "tenant-uuid")
+	require.ErrorContains(t, err, "unexpected status code: 503 (after 2 attempts)")
+	require.Equal(t, int32(2), calls.Load())
+
+	// The API has recovered, but the failure is served from the cache without a request
+	_, err = client.GetTenant(context.Background(), "tenant-uuid")
+	require.ErrorContains(t, err, "tenant lookup failed recently")
+	require.Equal(t, int32(2), calls.Load())
+
+	client.failures["tenant-uuid"] = landlordLookupFailure{err: err, expiresAt: time.Now().Add(-time.Second)}
+	tenant, err := client.GetTenant(context.Background(), "tenant-uuid")
+	require.NoError(t, err)
+	require.Equal(t, "acme", tenant.Name)
+	require.Empty(t, client.failures)
+}
+
+func TestHTTPLandlordClientRetryDelayIsCapped(t *testing.T) {
+	client := NewHTTPLandlordClient("http://landlord", HTTPLandlordClientOptions{RetryBackoff: time.Second}, zaptest.NewLogger(t))
+	for attempt := range 10 {
+		delay := client.retryDelay(attempt)
+		require.Positive(t, delay)
+		require.LessOrEqual(t, delay, maxLandlordAPIRetryBackoff)
+	}
+}
+
+func TestHTTPLandlordClientExchangesVersions(t *testing.T) {
+	var component string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		component = r.Header.Get(version.HeaderComponent)
+		w.Header().Set(version.HeaderVersion, "v1.4.0")
+		json.NewEncoder(w).Encode(LandlordTenant{Name: "acme"})
+	}))
+	defer server.Close()
+
+	client := NewHTTPLandlordClient(server.URL, HTTPLandlordClientOptions{}, zaptest.NewLogger(t))
+	_, err := client.GetTenant(context.Background(), "tenant-uuid")
+	require.NoError(t, err)
+	require.Equal(t, version.ComponentWorker, component)
+
+	seen := client.ServerVersions()
+	require.Len(t, seen, 1)
+	require.Equal(t, version.ComponentServer, seen[0].Component)
+	require.Equal(t, "v1.4.0", seen[0].Version)
+}
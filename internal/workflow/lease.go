@@ -0,0 +1,100 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+// LeaseConfig bounds a compute operation run by a workflow worker. An operation holds a lease
+// that each of its heartbeats renews, so slow operations that report progress keep running and
+// operations that stop reporting it are cancelled.
+type LeaseConfig struct {
+	// HeartbeatTimeout is how long an operation that has reported a heartbeat may go without
+	// another before it is treated as stalled. Operations that never heartbeat are bounded only by
+	// MaxDuration, since they cannot be told apart from slow ones. Zero disables stall detection.
+	HeartbeatTimeout time.Duration
+
+	// MaxDuration bounds the operation however often it heartbeats. Zero means no bound.
+	MaxDuration time.Duration
+
+	// OnHeartbeat, when set, is called with each heartbeat's detail
+	OnHeartbeat func(detail string)
+}
+
+// RunLeased runs fn under a lease. fn's context is cancelled when the operation stalls or runs
+// past MaxDuration, and the error returned then wraps ErrHeartbeatTimeout or ErrOperationTimeout
+// so callers can tell the two apart. Compute providers renew the lease with compute.Heartbeat.
+func RunLeased(ctx context.Context, cfg LeaseConfig, fn func(ctx context.Context) error) error {
+	if cfg.HeartbeatTimeout <= 0 && cfg.MaxDuration <= 0 && cfg.OnHeartbeat == nil {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if cfg.MaxDuration > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeoutCause(ctx, cfg.MaxDuration, fmt.Errorf("%w after %s", ErrOperationTimeout, cfg.MaxDuration))
+		defer cancelTimeout()
+	}
+
+	var (
+		mu         sync.Mutex
+		stallTimer *time.Timer
+		lastDetail string
+		stopped    bool
+	)
+	stall := func() {
+		mu.Lock()
+		detail := lastDetail
+		mu.Unlock()
+		cancel(fmt.Errorf("%w: no heartbeat for %s (last: %s)", ErrHeartbeatTimeout, cfg.HeartbeatTimeout, detail))
+	}
+	heartbeat := func(detail string) {
+		if cfg.OnHeartbeat != nil {
+			cfg.OnHeartbeat(detail)
+		}
+		if cfg.HeartbeatTimeout <= 0 {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if stopped {
+			return
+		}
+		lastDetail = detail
+		if stallTimer == nil {
+			stallTimer = time.AfterFunc(cfg.HeartbeatTimeout, stall)
+			return
+		}
+		stallTimer.Reset(cfg.HeartbeatTimeout)
+	}
+
+	err := fn(compute.WithHeartbeat(ctx, heartbeat))
+
+	mu.Lock()
+	stopped = true
+	if stallTimer != nil {
+		stallTimer.Stop()
+	}
+	mu.Unlock()
+
+	if err == nil {
+		return nil
+	}
+	// The operation usually reports a bare context error; the cause says which limit it hit
+	if cause := context.Cause(ctx); errors.Is(cause, ErrHeartbeatTimeout) || errors.Is(cause, ErrOperationTimeout) {
+		return fmt.Errorf("%w: %v", cause, err)
+	}
+	return err
+}
+
+// LeaseExpired reports whether err is a compute operation cancelled by its lease
+func LeaseExpired(err error) bool {
+	return errors.Is(err, ErrHeartbeatTimeout) || errors.Is(err, ErrOperationTimeout)
+}
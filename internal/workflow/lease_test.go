@@ -0,0 +1,71 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+func TestRunLeased(t *testing.T) {
+	cfg := LeaseConfig{HeartbeatTimeout: 50 * time.Millisecond, MaxDuration: time.Second}
+
+	// Heartbeats keep a slow operation alive well past the heartbeat timeout
+	var beats atomic.Int32
+	slow := cfg
+	slow.OnHeartbeat = func(string) { beats.Add(1) }
+	err := RunLeased(context.Background(), slow, func(ctx context.Context) error {
+		for i := 0; i < 10; i++ {
+			compute.Heartbeat(ctx, "pulling image")
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(20 * time.Millisecond):
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected a heartbeating operation to finish, got %v", err)
+	}
+	if beats.Load() != 10 {
+		t.Fatalf("expected 10 heartbeats, got %d", beats.Load())
+	}
+
+	// An operation that stops heartbeating is cancelled as stalled
+	err = RunLeased(context.Background(), cfg, func(ctx context.Context) error {
+		compute.Heartbeat(ctx, "pulling image nginx:1.27")
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, ErrHeartbeatTimeout) || errors.Is(err, ErrOperationTimeout) || !LeaseExpired(err) {
+		t.Fatalf("expected a stall, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "pulling image nginx:1.27") {
+		t.Fatalf("expected the last heartbeat in the error, got %v", err)
+	}
+
+	// An operation that never heartbeats is only bounded by the maximum duration
+	short := LeaseConfig{HeartbeatTimeout: 10 * time.Millisecond, MaxDuration: 100 * time.Millisecond}
+	started := time.Now()
+	err = RunLeased(context.Background(), short, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, ErrOperationTimeout) || errors.Is(err, ErrHeartbeatTimeout) {
+		t.Fatalf("expected the operation to time out, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed < 100*time.Millisecond {
+		t.Fatalf("expected the operation to run for its maximum duration, ran %s", elapsed)
+	}
+
+	// Errors unrelated to the lease pass through
+	failure := errors.New("image not found")
+	if err := RunLeased(context.Background(), cfg, func(context.Context) error { return failure }); err != failure || LeaseExpired(err) {
+		t.Fatalf("expected the operation's error, got %v", err)
+	}
+}
@@ -3,8 +3,11 @@ package workflow
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
 )
 
 // Manager coordinates workflow operations
@@ -69,7 +72,7 @@ func (m *Manager) StartExecution(ctx context.Context, workflowID string, provide
 	}
 
 	// Get provider
-	provider, err := m.registry.Get(providerType)
+	provider, err := m.registry.GetForExecution(providerType)
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +102,16 @@ func (m *Manager) Invoke(ctx context.Context, workflowID string, providerType st
 		zap.String("provider", providerType),
 	)
 
-	provider, err := m.registry.Get(providerType)
+	// Reject payloads the workers would misread before anything is started
+	if err := schema.Validate(schema.ProvisionRequest, request); err != nil {
+		m.logger.Error("workflow request rejected",
+			zap.String("workflow_id", workflowID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	provider, err := m.registry.GetForExecution(providerType)
 	if err != nil {
 		return nil, err
 	}
@@ -138,6 +150,55 @@ func (m *Manager) GetExecutionStatus(ctx context.Context, executionID string, pr
 	return status, nil
 }
 
+// maxStatusQueries bounds the status queries run at once for providers without batch queries
+const maxStatusQueries = 8
+
+// GetExecutionStatuses queries the status of many executions, keyed by execution ID. Providers that
+// implement BatchStatusProvider are asked once; others are queried concurrently, at most
+// maxStatusQueries at a time. Executions whose status could not be read are left out, so callers
+// can fall back to GetExecutionStatus for them.
+func (m *Manager) GetExecutionStatuses(ctx context.Context, executionIDs []string, providerType string) (map[string]*ExecutionStatus, error) {
+	provider, err := m.registry.Get(providerType)
+	if err != nil {
+		return nil, err
+	}
+	if len(executionIDs) == 0 {
+		return map[string]*ExecutionStatus{}, nil
+	}
+	if batch, ok := provider.(BatchStatusProvider); ok {
+		return batch.GetExecutionStatuses(ctx, executionIDs)
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		statuses = make(map[string]*ExecutionStatus, len(executionIDs))
+		slots    = make(chan struct{}, maxStatusQueries)
+	)
+	for _, executionID := range executionIDs {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(executionID string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			status, err := provider.GetExecutionStatus(ctx, executionID)
+			if err != nil || status == nil {
+				m.logger.Debug("failed to get execution status",
+					zap.String("execution_id", executionID),
+					zap.Error(err))
+				return
+			}
+			mu.Lock()
+			statuses[executionID] = status
+			mu.Unlock()
+		}(executionID)
+	}
+	wg.Wait()
+
+	return statuses, nil
+}
+
 // GetWorkflowStatus queries simplified execution status
 func (m *Manager) GetWorkflowStatus(ctx context.Context, executionID string, providerType string) (*WorkflowStatus, error) {
 	provider, err := m.registry.Get(providerType)
@@ -233,3 +294,28 @@ func (m *Manager) ValidateWorkflowSpec(ctx context.Context, spec *WorkflowSpec)
 func (m *Manager) ListProviders() []string {
 	return m.registry.List()
 }
+
+// ListWorkflows returns the registered workflow definitions for each provider.
+// Providers that cannot enumerate their workflows are included with an empty list.
+func (m *Manager) ListWorkflows(ctx context.Context) (map[string][]WorkflowDefinition, error) {
+	result := make(map[string][]WorkflowDefinition)
+	for _, name := range m.registry.List() {
+		provider, err := m.registry.Get(name)
+		if err != nil {
+			return nil, err
+		}
+
+		lister, ok := provider.(WorkflowLister)
+		if !ok {
+			result[name] = []WorkflowDefinition{}
+			continue
+		}
+
+		definitions, err := lister.ListWorkflows(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list workflows for provider %s: %w", name, err)
+		}
+		result[name] = definitions
+	}
+	return result, nil
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
 	"go.uber.org/zap"
 )
 
@@ -231,6 +232,57 @@ func TestManagerStartExecutionInvalidInput(t *testing.T) {
 	}
 }
 
+func TestManagerInvokeIncompatibleRequest(t *testing.T) {
+	registry := NewRegistry(zap.NewNop())
+	invoked := false
+	registry.Register(&mockProvider{name: "test", invokeFunc: func(ctx context.Context, workflowID string, request *ProvisionRequest) (*ExecutionResult, error) {
+		invoked = true
+		return &ExecutionResult{ExecutionID: "exec-123"}, nil
+	}})
+
+	manager := New(registry, zap.NewNop())
+
+	request := &ProvisionRequest{TenantID: "t", Operation: "provision", SchemaVersion: "v99"}
+	if _, err := manager.Invoke(context.Background(), "test-workflow", "test", request); !errors.Is(err, schema.ErrIncompatible) {
+		t.Fatalf("expected ErrIncompatible, got %v", err)
+	}
+	if invoked {
+		t.Fatal("expected the provider not to be invoked")
+	}
+
+	request.SchemaVersion = schema.ProvisionRequestVersion
+	if _, err := manager.Invoke(context.Background(), "test-workflow", "test", request); err != nil || !invoked {
+		t.Fatalf("expected a v1 request to be invoked, got %v", err)
+	}
+}
+
+func TestManagerDisabledProvider(t *testing.T) {
+	registry := NewRegistry(zap.NewNop())
+	provider := &mockProvider{name: "test"}
+	registry.Register(provider)
+	if err := registry.SetEnabled("test", false); err != nil {
+		t.Fatalf("SetEnabled failed: %v", err)
+	}
+
+	manager := New(registry, zap.NewNop())
+
+	input := &ExecutionInput{ExecutionName: "test-execution", Input: json.RawMessage(`{}`)}
+	if _, err := manager.StartExecution(context.Background(), "test-workflow", "test", input); !errors.Is(err, ErrProviderDisabled) {
+		t.Errorf("expected ErrProviderDisabled from StartExecution, got %v", err)
+	}
+	if _, err := manager.Invoke(context.Background(), "test-workflow", "test", &ProvisionRequest{TenantID: "t"}); !errors.Is(err, ErrProviderDisabled) {
+		t.Errorf("expected ErrProviderDisabled from Invoke, got %v", err)
+	}
+
+	// Running executions can still be observed and stopped
+	if _, err := manager.GetExecutionStatus(context.Background(), "exec-123", "test"); err != nil {
+		t.Errorf("GetExecutionStatus failed on disabled provider: %v", err)
+	}
+	if err := manager.StopExecution(context.Background(), "exec-123", "test", "drain"); err != nil {
+		t.Errorf("StopExecution failed on disabled provider: %v", err)
+	}
+}
+
 func TestManagerGetExecutionStatus(t *testing.T) {
 	registry := NewRegistry(zap.NewNop())
 	provider := &mockProvider{name: "test"}
@@ -248,6 +300,53 @@ func TestManagerGetExecutionStatus(t *testing.T) {
 	}
 }
 
+type batchMockProvider struct {
+	mockProvider
+	batches [][]string
+}
+
+func (b *batchMockProvider) GetExecutionStatuses(ctx context.Context, executionIDs []string) (map[string]*ExecutionStatus, error) {
+	b.batches = append(b.batches, executionIDs)
+	statuses := make(map[string]*ExecutionStatus, len(executionIDs))
+	for _, id := range executionIDs {
+		statuses[id] = &ExecutionStatus{ExecutionID: id, State: StateRunning}
+	}
+	return statuses, nil
+}
+
+func TestManagerGetExecutionStatuses(t *testing.T) {
+	registry := NewRegistry(zap.NewNop())
+	registry.Register(&mockProvider{
+		name: "single",
+		getExecutionStatusFunc: func(ctx context.Context, executionID string) (*ExecutionStatus, error) {
+			if executionID == "missing" {
+				return nil, ErrExecutionNotFound
+			}
+			return &ExecutionStatus{ExecutionID: executionID, State: StateSucceeded}, nil
+		},
+	})
+	batch := &batchMockProvider{mockProvider: mockProvider{name: "batch"}}
+	registry.Register(batch)
+	manager := New(registry, zap.NewNop())
+
+	ids := []string{"exec-1", "exec-2", "missing"}
+	statuses, err := manager.GetExecutionStatuses(context.Background(), ids, "single")
+	if err != nil {
+		t.Fatalf("GetExecutionStatuses failed: %v", err)
+	}
+	if len(statuses) != 2 || statuses["exec-1"].State != StateSucceeded || statuses["missing"] != nil {
+		t.Fatalf("expected the two found executions, got %v", statuses)
+	}
+
+	statuses, err = manager.GetExecutionStatuses(context.Background(), ids, "batch")
+	if err != nil {
+		t.Fatalf("GetExecutionStatuses failed: %v", err)
+	}
+	if len(batch.batches) != 1 || len(statuses) != 3 {
+		t.Fatalf("expected one batch query for 3 executions, got %d queries and %d statuses", len(batch.batches), len(statuses))
+	}
+}
+
 func TestManagerStopExecution(t *testing.T) {
 	registry := NewRegistry(zap.NewNop())
 	provider := &mockProvider{name: "test"}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 
 	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/resource"
 )
 
 // Provider defines the interface for workflow providers
@@ -45,6 +46,19 @@ type Provider interface {
 	PostComputeCallback(ctx context.Context, executionID string, payload *compute.CallbackPayload, opts *compute.CallbackOptions) error
 }
 
+// Reconfigurable is implemented by providers whose settings can change at runtime
+type Reconfigurable interface {
+	// Reconfigure applies new provider settings; on error the old settings stay in place
+	Reconfigure(settings map[string]interface{}) error
+}
+
+// BatchStatusProvider is implemented by providers that can query many executions in one request
+type BatchStatusProvider interface {
+	// GetExecutionStatuses queries the state of each execution, keyed by execution ID
+	// Executions the provider cannot find are left out rather than failing the batch
+	GetExecutionStatuses(ctx context.Context, executionIDs []string) (map[string]*ExecutionStatus, error)
+}
+
 // ProvisionRequest is a simplified execution request for workflow providers
 type ProvisionRequest struct {
 	TenantID        string                 `json:"tenant_id"`
@@ -54,6 +68,28 @@ type ProvisionRequest struct {
 	ComputeProvider string                 `json:"compute_provider,omitempty"`
 	APIBaseURL      string                 `json:"api_base_url,omitempty"`
 	Metadata        map[string]string      `json:"metadata,omitempty"` // Metadata like config_hash
+	WorkflowVersion string                 `json:"workflow_version,omitempty"`
+	// PreviousResources are the resources recorded in the tenant's observed config, so removed ones can be destroyed
+	PreviousResources []resource.Ref `json:"previous_resources,omitempty"`
+	// PreviousEndpoints are the endpoints recorded in the tenant's observed config, so updates can run its readiness probe
+	PreviousEndpoints []compute.Endpoint `json:"previous_endpoints,omitempty"`
+	// PreviousComponents are the components recorded in the tenant's observed config, so removed ones can be destroyed
+	PreviousComponents []ComponentRecord `json:"previous_components,omitempty"`
+	// SourceComputeProvider is the provider a migrate operation moves the tenant off; ComputeProvider is the target
+	SourceComputeProvider string `json:"source_compute_provider,omitempty"`
+	// MigrationPhase is the step a migrate operation runs (provisioning-target, switching-endpoints, destroying-source)
+	MigrationPhase string `json:"migration_phase,omitempty"`
+	// SchemaVersion is the provision-request schema version the payload was written against
+	SchemaVersion string `json:"schema_version,omitempty"`
+	// LandlordVersion is the version of the landlord server that triggered the workflow, so workers can detect skew
+	LandlordVersion string `json:"landlord_version,omitempty"`
+}
+
+// ComponentRecord is a tenant component as recorded by the last successful workflow
+type ComponentRecord struct {
+	Name string `json:"name"`
+	// Endpoints are the component's endpoints, so updates can run its readiness probe
+	Endpoints []compute.Endpoint `json:"endpoints,omitempty"`
 }
 
 // WorkflowStatus is a simplified execution status response
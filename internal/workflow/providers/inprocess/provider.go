@@ -0,0 +1,354 @@
+// Package inprocess provides a workflow provider that runs executions in the process that triggers
+// them, for all-in-one deployments where the API server, controller and worker share one binary.
+// Starting an execution calls the worker's tenant service directly in a goroutine, with no
+// workflow engine or HTTP round trip in between.
+package inprocess
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// ProviderName identifies the in-process provider in execution statuses
+const ProviderName = "inprocess"
+
+// Executor runs one execution of a tenant workflow to completion. The worker's tenant service
+// implements it; ctx is cancelled when the execution is stopped.
+type Executor func(ctx context.Context, executionID string, request *workflow.ProvisionRequest) (*workflow.ExecutionStatus, error)
+
+// Provider runs workflow executions in goroutines. Executions are tracked in memory, so those
+// running when the process stops are lost; the controller's workflow_timeouts retry them.
+// Failed executions are not retried by the provider; the controller retries them up to max_retries.
+type Provider struct {
+	execute Executor
+	logger  *zap.Logger
+
+	// startMu serializes starts, so a retried trigger cannot start a second execution
+	startMu sync.Mutex
+
+	mu         sync.RWMutex
+	executions map[string]*workflow.ExecutionStatus
+	// running maps the execution name of each unfinished execution to its ID
+	running map[string]string
+	cancels map[string]context.CancelCauseFunc
+	wg      sync.WaitGroup
+}
+
+var (
+	_ workflow.Provider            = (*Provider)(nil)
+	_ workflow.BatchStatusProvider = (*Provider)(nil)
+)
+
+// New creates an in-process provider that runs executions with execute
+func New(execute Executor, logger *zap.Logger) *Provider {
+	return &Provider{
+		execute:    execute,
+		logger:     logger.With(zap.String("provider", ProviderName)),
+		executions: make(map[string]*workflow.ExecutionStatus),
+		running:    make(map[string]string),
+		cancels:    make(map[string]context.CancelCauseFunc),
+	}
+}
+
+// Name returns the provider identifier
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+// Invoke starts a workflow execution using a simplified request payload
+func (p *Provider) Invoke(ctx context.Context, workflowID string, request *workflow.ProvisionRequest) (*workflow.ExecutionResult, error) {
+	if request == nil {
+		return nil, fmt.Errorf("provision request is required")
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	tenantIdentifier := request.TenantUUID
+	if tenantIdentifier == "" {
+		tenantIdentifier = request.TenantID
+	}
+	operation := request.Operation
+	if operation == "" {
+		operation = "provision"
+	}
+
+	executionName := fmt.Sprintf("tenant-%s-%s-%s", tenantIdentifier, workflowID, operation)
+	if request.MigrationPhase != "" {
+		// Each migration phase is its own execution of the migrate workflow
+		executionName = fmt.Sprintf("%s-%s", executionName, request.MigrationPhase)
+	}
+	return p.StartExecution(ctx, workflowID, &workflow.ExecutionInput{
+		ExecutionName:   executionName,
+		Input:           payload,
+		Metadata:        request.Metadata,
+		TriggerSource:   "reconciler",
+		WorkflowVersion: workflow.EffectiveWorkflowVersion(request.WorkflowVersion),
+	})
+}
+
+// GetWorkflowStatus returns a simplified workflow status for an execution
+func (p *Provider) GetWorkflowStatus(ctx context.Context, executionID string) (*workflow.WorkflowStatus, error) {
+	status, err := p.GetExecutionStatus(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &workflow.WorkflowStatus{
+		ExecutionID: status.ExecutionID,
+		State:       status.State,
+		Output:      status.Output,
+		Error:       status.Error,
+	}, nil
+}
+
+// CreateWorkflow is a no-op; the workflow is the worker's tenant service
+func (p *Provider) CreateWorkflow(ctx context.Context, spec *workflow.WorkflowSpec) (*workflow.CreateWorkflowResult, error) {
+	return &workflow.CreateWorkflowResult{
+		WorkflowID:   spec.WorkflowID,
+		ProviderType: ProviderName,
+		CreatedAt:    time.Now(),
+		Message:      "workflow runs in process",
+	}, nil
+}
+
+// StartExecution runs an execution in the background. While an execution with the same name is
+// unfinished, it is returned instead of starting another.
+func (p *Provider) StartExecution(ctx context.Context, workflowID string, input *workflow.ExecutionInput) (*workflow.ExecutionResult, error) {
+	if input == nil {
+		return nil, fmt.Errorf("execution input is required")
+	}
+	var request workflow.ProvisionRequest
+	if err := json.Unmarshal(input.Input, &request); err != nil {
+		return nil, fmt.Errorf("decode execution input: %w", err)
+	}
+
+	p.startMu.Lock()
+	defer p.startMu.Unlock()
+
+	if input.ExecutionName != "" {
+		p.mu.RLock()
+		var existing *workflow.ExecutionStatus
+		if status, ok := p.executions[p.running[input.ExecutionName]]; ok {
+			existing = copyStatus(status)
+		}
+		p.mu.RUnlock()
+		if existing != nil {
+			return &workflow.ExecutionResult{
+				ExecutionID:  existing.ExecutionID,
+				WorkflowID:   existing.WorkflowID,
+				ProviderType: ProviderName,
+				State:        existing.State,
+				StartedAt:    existing.StartTime,
+				Message:      "execution already started (idempotent result)",
+			}, nil
+		}
+	}
+
+	executionID := "inproc-" + uuid.NewString()
+	now := time.Now()
+	status := &workflow.ExecutionStatus{
+		ExecutionID:  executionID,
+		WorkflowID:   workflowID,
+		ProviderType: ProviderName,
+		State:        workflow.StateRunning,
+		StartTime:    now,
+		Input:        input.Input,
+		History:      []workflow.ExecutionEvent{{Timestamp: now, Type: "ExecutionStarted"}},
+		Metadata:     map[string]string{"execution_name": input.ExecutionName},
+	}
+	// The execution outlives the trigger's request, so it only stops when it is stopped
+	execCtx, cancel := context.WithCancelCause(context.Background())
+
+	p.mu.Lock()
+	p.executions[executionID] = status
+	if input.ExecutionName != "" {
+		p.running[input.ExecutionName] = executionID
+	}
+	p.cancels[executionID] = cancel
+	p.wg.Add(1)
+	p.mu.Unlock()
+
+	go p.run(execCtx, executionID, &request)
+
+	p.logger.Info("execution started",
+		zap.String("workflow_id", workflowID),
+		zap.String("execution_id", executionID),
+		zap.String("execution_name", input.ExecutionName))
+
+	return &workflow.ExecutionResult{
+		ExecutionID:  executionID,
+		WorkflowID:   workflowID,
+		ProviderType: ProviderName,
+		State:        workflow.StateRunning,
+		StartedAt:    now,
+		Message:      "execution started in process",
+	}, nil
+}
+
+func (p *Provider) run(ctx context.Context, executionID string, request *workflow.ProvisionRequest) {
+	defer p.wg.Done()
+
+	result, err := p.execute(ctx, executionID, request)
+
+	var output json.RawMessage
+	if err == nil && result != nil {
+		if output, err = json.Marshal(result); err != nil {
+			err = fmt.Errorf("marshal execution result: %w", err)
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status := p.executions[executionID]
+	if terminal(status.State) {
+		// Stopped while it ran
+		return
+	}
+	if err != nil {
+		status.Error = &workflow.ExecutionError{Code: "execution_failed", Message: err.Error()}
+		details, _ := json.Marshal(map[string]string{"error": err.Error()})
+		p.finish(status, workflow.StateFailed, "ExecutionFailed", details)
+		p.logger.Warn("execution failed", zap.String("execution_id", executionID), zap.Error(err))
+		return
+	}
+	status.Output = output
+	p.finish(status, workflow.StateSucceeded, "ExecutionSucceeded", nil)
+	p.logger.Info("execution succeeded", zap.String("execution_id", executionID))
+}
+
+// GetExecutionStatus returns the execution's status
+func (p *Provider) GetExecutionStatus(ctx context.Context, executionID string) (*workflow.ExecutionStatus, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status, ok := p.executions[executionID]
+	if !ok {
+		return nil, workflow.ErrExecutionNotFound
+	}
+	return copyStatus(status), nil
+}
+
+// GetExecutionStatuses returns the status of each known execution
+func (p *Provider) GetExecutionStatuses(ctx context.Context, executionIDs []string) (map[string]*workflow.ExecutionStatus, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	statuses := make(map[string]*workflow.ExecutionStatus, len(executionIDs))
+	for _, id := range executionIDs {
+		if status, ok := p.executions[id]; ok {
+			statuses[id] = copyStatus(status)
+		}
+	}
+	return statuses, nil
+}
+
+// StopExecution cancels an execution and marks it cancelled. Compute operations in flight stop
+// with the execution's context.
+func (p *Provider) StopExecution(ctx context.Context, executionID string, reason string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status, ok := p.executions[executionID]
+	if !ok {
+		return workflow.ErrExecutionNotFound
+	}
+	if terminal(status.State) {
+		return nil
+	}
+	p.cancels[executionID](fmt.Errorf("%w: %s", compute.ErrExecutionCancelled, reason))
+	details, _ := json.Marshal(map[string]string{"reason": reason})
+	p.finish(status, workflow.StateCancelled, "ExecutionCancelled", details)
+	return nil
+}
+
+// Stop cancels every running execution and waits for them to return, or for ctx to be done.
+// Cancelled executions are marked failed, so the controller retries them.
+func (p *Provider) Stop(ctx context.Context) error {
+	p.mu.RLock()
+	for _, cancel := range p.cancels {
+		cancel(fmt.Errorf("%w: shutting down", compute.ErrExecutionCancelled))
+	}
+	p.mu.RUnlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("executions still running: %w", ctx.Err())
+	}
+}
+
+// DeleteWorkflow is a no-op; the workflow is the worker's tenant service
+func (p *Provider) DeleteWorkflow(ctx context.Context, workflowID string) error {
+	return nil
+}
+
+// Validate performs basic validation on the workflow spec
+func (p *Provider) Validate(ctx context.Context, spec *workflow.WorkflowSpec) error {
+	if spec == nil || spec.WorkflowID == "" {
+		return workflow.ErrInvalidSpec
+	}
+	return nil
+}
+
+// PostComputeCallback is a no-op: executions wait for their compute operations in process, so no
+// callback is needed to resume them
+func (p *Provider) PostComputeCallback(ctx context.Context, executionID string, payload *compute.CallbackPayload, opts *compute.CallbackOptions) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if _, ok := p.executions[executionID]; !ok {
+		return workflow.ErrExecutionNotFound
+	}
+	return nil
+}
+
+// finish records that status reached a terminal state; callers hold p.mu
+func (p *Provider) finish(status *workflow.ExecutionStatus, state workflow.ExecutionState, eventType string, details json.RawMessage) {
+	now := time.Now()
+	status.State = state
+	status.StopTime = &now
+	status.History = append(status.History, workflow.ExecutionEvent{Timestamp: now, Type: eventType, Details: details})
+	if name := status.Metadata["execution_name"]; p.running[name] == status.ExecutionID {
+		delete(p.running, name)
+	}
+	if cancel, ok := p.cancels[status.ExecutionID]; ok {
+		cancel(nil)
+		delete(p.cancels, status.ExecutionID)
+	}
+}
+
+func terminal(state workflow.ExecutionState) bool {
+	switch state {
+	case workflow.StateSucceeded, workflow.StateFailed, workflow.StateTimedOut, workflow.StateCancelled:
+		return true
+	}
+	return false
+}
+
+// copyStatus returns a copy of status that callers can keep while the execution updates the original
+func copyStatus(status *workflow.ExecutionStatus) *workflow.ExecutionStatus {
+	copied := *status
+	copied.History = append([]workflow.ExecutionEvent(nil), status.History...)
+	copied.Metadata = make(map[string]string, len(status.Metadata))
+	for k, v := range status.Metadata {
+		copied.Metadata[k] = v
+	}
+	return &copied
+}
@@ -0,0 +1,91 @@
+package inprocess
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+func waitForState(t *testing.T, p *Provider, executionID string, state workflow.ExecutionState) *workflow.ExecutionStatus {
+	t.Helper()
+	var status *workflow.ExecutionStatus
+	require.Eventually(t, func() bool {
+		var err error
+		status, err = p.GetExecutionStatus(context.Background(), executionID)
+		require.NoError(t, err)
+		return status.State == state
+	}, 5*time.Second, 5*time.Millisecond, "execution did not reach %s", state)
+	return status
+}
+
+func TestProviderRunsExecutions(t *testing.T) {
+	ctx := context.Background()
+	release := make(chan struct{})
+	var seenID string
+	p := New(func(ctx context.Context, executionID string, request *workflow.ProvisionRequest) (*workflow.ExecutionStatus, error) {
+		seenID = executionID
+		<-release
+		if request.TenantID == "broken" {
+			return nil, errors.New("image not found")
+		}
+		return &workflow.ExecutionStatus{State: workflow.StateSucceeded, Output: json.RawMessage(`{"status":"ready"}`)}, nil
+	}, zaptest.NewLogger(t))
+
+	result, err := p.Invoke(ctx, "tenant-provisioning", &workflow.ProvisionRequest{TenantID: "acme", TenantUUID: "1234", Operation: "provision"})
+	require.NoError(t, err)
+	require.Equal(t, workflow.StateRunning, result.State)
+
+	// A retried trigger gets the running execution back
+	again, err := p.Invoke(ctx, "tenant-provisioning", &workflow.ProvisionRequest{TenantID: "acme", TenantUUID: "1234", Operation: "provision"})
+	require.NoError(t, err)
+	require.Equal(t, result.ExecutionID, again.ExecutionID)
+
+	close(release)
+	status := waitForState(t, p, result.ExecutionID, workflow.StateSucceeded)
+	require.Equal(t, result.ExecutionID, seenID)
+	require.NotNil(t, status.StopTime)
+	var output workflow.ExecutionStatus
+	require.NoError(t, json.Unmarshal(status.Output, &output))
+	require.Equal(t, workflow.StateSucceeded, output.State)
+
+	// Once finished, the same trigger starts a new execution
+	failed, err := p.Invoke(ctx, "tenant-provisioning", &workflow.ProvisionRequest{TenantID: "broken", Operation: "provision"})
+	require.NoError(t, err)
+	status = waitForState(t, p, failed.ExecutionID, workflow.StateFailed)
+	require.Equal(t, "image not found", status.Error.Message)
+
+	statuses, err := p.GetExecutionStatuses(ctx, []string{result.ExecutionID, failed.ExecutionID, "missing"})
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	_, err = p.GetExecutionStatus(ctx, "missing")
+	require.ErrorIs(t, err, workflow.ErrExecutionNotFound)
+}
+
+func TestProviderStopsExecutions(t *testing.T) {
+	ctx := context.Background()
+	p := New(func(ctx context.Context, executionID string, request *workflow.ProvisionRequest) (*workflow.ExecutionStatus, error) {
+		<-ctx.Done()
+		return nil, context.Cause(ctx)
+	}, zaptest.NewLogger(t))
+
+	stopped, err := p.Invoke(ctx, "tenant-provisioning", &workflow.ProvisionRequest{TenantID: "acme", Operation: "update"})
+	require.NoError(t, err)
+	require.NoError(t, p.StopExecution(ctx, stopped.ExecutionID, "config changed"))
+	status := waitForState(t, p, stopped.ExecutionID, workflow.StateCancelled)
+	require.NotNil(t, status.StopTime)
+
+	// Shutting down cancels running executions, which then fail
+	running, err := p.Invoke(ctx, "tenant-provisioning", &workflow.ProvisionRequest{TenantID: "other", Operation: "provision"})
+	require.NoError(t, err)
+	require.NoError(t, p.Stop(ctx))
+	waitForState(t, p, running.ExecutionID, workflow.StateFailed)
+	waitForState(t, p, stopped.ExecutionID, workflow.StateCancelled)
+}
@@ -197,3 +197,33 @@ func TestProviderStatusAfterIdempotentTrigger(t *testing.T) {
 		t.Errorf("expected same state after idempotent trigger, got %s and %s", status1.State, status2.State)
 	}
 }
+
+// TestInvokeMigrationPhasesCreateDifferentExecutions tests that each migration phase is a separate execution
+func TestInvokeMigrationPhasesCreateDifferentExecutions(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	provider := New(logger)
+
+	if _, err := provider.CreateWorkflow(context.Background(), &workflow.WorkflowSpec{
+		WorkflowID:   "tenant-migrate",
+		Name:         "tenant-migrate",
+		ProviderType: "mock",
+	}); err != nil {
+		t.Fatalf("CreateWorkflow failed: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, phase := range []string{"provisioning-target", "switching-endpoints", "destroying-source"} {
+		result, err := provider.Invoke(context.Background(), "tenant-migrate", &workflow.ProvisionRequest{
+			TenantID:       "acme",
+			Operation:      "migrate",
+			MigrationPhase: phase,
+		})
+		if err != nil {
+			t.Fatalf("Invoke(%s) failed: %v", phase, err)
+		}
+		if seen[result.ExecutionID] {
+			t.Errorf("phase %s reused execution %s", phase, result.ExecutionID)
+		}
+		seen[result.ExecutionID] = true
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/jaxxstorm/landlord/internal/compute"
 	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
 	"go.uber.org/zap"
 )
 
@@ -60,13 +61,18 @@ func (p *Provider) Invoke(ctx context.Context, workflowID string, request *workf
 	}
 
 	executionName := fmt.Sprintf("tenant-%s-%s", request.TenantID, workflowID)
+	if request.MigrationPhase != "" {
+		// Each migration phase is its own execution of the migrate workflow
+		executionName = fmt.Sprintf("%s-%s", executionName, request.MigrationPhase)
+	}
 	input := &workflow.ExecutionInput{
 		ExecutionName: executionName,
 		Input:         payload,
 		Tags: map[string]string{
 			"tenant_id": request.TenantID,
 		},
-		TriggerSource: "reconciler",
+		TriggerSource:   "reconciler",
+		WorkflowVersion: workflow.EffectiveWorkflowVersion(request.WorkflowVersion),
 	}
 
 	return p.StartExecution(ctx, workflowID, input)
@@ -273,6 +279,10 @@ func (p *Provider) Validate(ctx context.Context, spec *workflow.WorkflowSpec) er
 
 // PostComputeCallback is a stub for the mock provider
 func (p *Provider) PostComputeCallback(ctx context.Context, executionID string, payload *compute.CallbackPayload, opts *compute.CallbackOptions) error {
+	if err := schema.Validate(schema.ComputeCallback, payload); err != nil {
+		return err
+	}
+
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
@@ -288,3 +298,15 @@ func (p *Provider) PostComputeCallback(ctx context.Context, executionID string,
 
 	return nil
 }
+
+// ListWorkflows returns the created workflows at every supported version
+func (p *Provider) ListWorkflows(ctx context.Context) ([]workflow.WorkflowDefinition, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	ids := make([]string, 0, len(p.workflows))
+	for id := range p.workflows {
+		ids = append(ids, id)
+	}
+	return workflow.VersionedDefinitions(ids), nil
+}
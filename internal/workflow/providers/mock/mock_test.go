@@ -3,10 +3,13 @@ package mock
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"sync"
 	"testing"
 
+	"github.com/jaxxstorm/landlord/internal/compute"
 	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
 	"go.uber.org/zap"
 )
 
@@ -245,6 +248,35 @@ func TestProvider_Invoke(t *testing.T) {
 	}
 }
 
+func TestProvider_PostComputeCallback(t *testing.T) {
+	p := New(zap.NewNop())
+	ctx := context.Background()
+
+	spec := &workflow.WorkflowSpec{WorkflowID: "provision", ProviderType: "mock", Name: "Provision", Definition: json.RawMessage(`{}`)}
+	if _, err := p.CreateWorkflow(ctx, spec); err != nil {
+		t.Fatalf("CreateWorkflow failed: %v", err)
+	}
+	result, err := p.Invoke(ctx, "provision", &workflow.ProvisionRequest{TenantID: "tenant-123", Operation: "provision"})
+	if err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+
+	payload := &compute.CallbackPayload{
+		ExecutionID:   "compute-1",
+		TenantID:      "tenant-123",
+		Status:        compute.ExecutionStatusSucceeded,
+		SchemaVersion: schema.ComputeCallbackVersion,
+	}
+	if err := p.PostComputeCallback(ctx, result.ExecutionID, payload, nil); err != nil {
+		t.Fatalf("PostComputeCallback failed: %v", err)
+	}
+
+	payload.SchemaVersion = "v99"
+	if err := p.PostComputeCallback(ctx, result.ExecutionID, payload, nil); !errors.Is(err, schema.ErrIncompatible) {
+		t.Errorf("expected ErrIncompatible for an unknown schema version, got %v", err)
+	}
+}
+
 func TestProvider_GetWorkflowStatus(t *testing.T) {
 	p := New(zap.NewNop())
 	ctx := context.Background()
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/logger"
 	"github.com/jaxxstorm/landlord/internal/workflow"
 	"go.uber.org/zap"
 )
@@ -212,6 +213,58 @@ func (c *Client) RegisterDeployment(ctx context.Context, uri string) error {
 	return nil
 }
 
+type listDeploymentsResponse struct {
+	Deployments []struct {
+		ID  string `json:"id"`
+		URI string `json:"uri"`
+	} `json:"deployments"`
+}
+
+// DeploymentExists reports whether a deployment with the given URI is registered with Restate.
+func (c *Client) DeploymentExists(ctx context.Context, uri string) (bool, error) {
+	if uri == "" {
+		return false, fmt.Errorf("deployment uri is required")
+	}
+
+	url := fmt.Sprintf("%s/deployments", c.adminEndpoint)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create deployments request: %w", err)
+	}
+
+	if err := c.addAuthHeader(req); err != nil {
+		return false, fmt.Errorf("failed to add auth header: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+		return false, fmt.Errorf("%w: list deployments returned %d", errAdminAPINotSupported, resp.StatusCode)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return false, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var deployments listDeploymentsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&deployments); err != nil {
+		return false, fmt.Errorf("failed to decode deployments: %w", err)
+	}
+
+	want := strings.TrimSuffix(uri, "/")
+	for _, deployment := range deployments.Deployments {
+		if strings.TrimSuffix(deployment.URI, "/") == want {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // InvokeService invokes a Restate service and returns the execution ID
 func (c *Client) InvokeService(ctx context.Context, serviceName, executionName string, input json.RawMessage) (string, error) {
 	if serviceName == "" {
@@ -223,6 +276,14 @@ func (c *Client) InvokeService(ctx context.Context, serviceName, executionName s
 	// Using "send" for async invocation (fire-and-forget with idempotency key)
 	url := fmt.Sprintf("%s/%s/execute/send?idempotency_key=%s", c.endpoint, serviceName, executionName)
 
+	if logger.DebugFlag(c.logger, logger.FlagRestatePayloads) {
+		c.logger.Info("invoking service",
+			zap.String("service_name", serviceName),
+			zap.String("execution_name", executionName),
+			zap.ByteString("input", input),
+		)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(input))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
@@ -285,6 +346,11 @@ func (c *Client) GetExecutionStatus(ctx context.Context, executionID string) (*w
 		return nil, err
 	}
 
+	return c.statusFromInvocation(executionID, invocation), nil
+}
+
+// statusFromInvocation maps a sys_invocation row to a workflow execution status
+func (c *Client) statusFromInvocation(executionID string, invocation map[string]interface{}) *workflow.ExecutionStatus {
 	// Map Restate invocation status to workflow status
 	state := workflow.StateRunning
 	if status, ok := invocation["status"].(string); ok {
@@ -352,7 +418,7 @@ func (c *Client) GetExecutionStatus(ctx context.Context, executionID string) (*w
 		}
 	}
 
-	return status, nil
+	return status
 }
 
 func (c *Client) getExecutionStatusByIdempotencyKey(ctx context.Context, idempotencyKey string) (*workflow.ExecutionStatus, error) {
@@ -448,13 +514,43 @@ func (c *Client) fetchInvocationStatus(ctx context.Context, executionID string)
 }
 
 func (c *Client) queryInvocationStatus(ctx context.Context, executionID string) (map[string]interface{}, error) {
-	queryPayload := map[string]string{
-		"query": fmt.Sprintf("select * from sys_invocation where id = '%s';", executionID),
+	response, err := c.query(ctx, fmt.Sprintf("select * from sys_invocation where id = '%s';", executionID))
+	if err != nil {
+		return nil, err
+	}
+
+	c.logger.Info("raw invocation query response", zap.Any("response", response))
+
+	if rows, ok := response["rows"].([]interface{}); ok {
+		if len(rows) == 0 {
+			return nil, fmt.Errorf("%w: execution not found", workflow.ErrExecutionNotFound)
+		}
+		if invocations := invocationRows(response); len(invocations) > 0 {
+			c.logger.Info("invocation data from query row", zap.Any("invocation", invocations[0]))
+			return invocations[0], nil
+		}
+	}
+
+	if data, ok := response["data"].(map[string]interface{}); ok {
+		return data, nil
+	}
+
+	if status, ok := response["status"]; ok {
+		return map[string]interface{}{"status": status}, nil
 	}
-	body, err := json.Marshal(queryPayload)
+
+	return nil, fmt.Errorf("failed to query execution status: unexpected response format")
+}
+
+// query runs a SQL query against the admin API's introspection tables
+func (c *Client) query(ctx context.Context, sql string) (map[string]interface{}, error) {
+	body, err := json.Marshal(map[string]string{"query": sql})
 	if err != nil {
 		return nil, fmt.Errorf("failed to build query payload: %w", err)
 	}
+	if logger.DebugFlag(c.logger, logger.FlagRestatePayloads) {
+		c.logger.Info("querying restate admin API", zap.String("query", sql))
+	}
 
 	url := fmt.Sprintf("%s/query", c.adminEndpoint)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(body)))
@@ -483,46 +579,91 @@ func (c *Client) queryInvocationStatus(ctx context.Context, executionID string)
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
 		return nil, fmt.Errorf("failed to decode query response: %w", err)
 	}
+	return response, nil
+}
 
-	c.logger.Info("raw invocation query response", zap.Any("response", response))
+// GetExecutionStatuses retrieves the status of many executions, keyed by execution ID. Invocation
+// IDs are read with one sys_invocation query; executions known by idempotency key are queried one
+// at a time. Executions that cannot be found are left out.
+func (c *Client) GetExecutionStatuses(ctx context.Context, executionIDs []string) (map[string]*workflow.ExecutionStatus, error) {
+	statuses := make(map[string]*workflow.ExecutionStatus, len(executionIDs))
 
-	if rows, ok := response["rows"].([]interface{}); ok {
-		if len(rows) == 0 {
-			return nil, fmt.Errorf("%w: execution not found", workflow.ErrExecutionNotFound)
+	var invocationIDs []string
+	for _, executionID := range executionIDs {
+		if executionID == "" {
+			continue
 		}
-
-		if rowMap, ok := rows[0].(map[string]interface{}); ok {
-			c.logger.Info("invocation data from row map", zap.Any("invocation", rowMap))
-			return rowMap, nil
+		if strings.HasPrefix(executionID, "inv_") {
+			invocationIDs = append(invocationIDs, executionID)
+			continue
 		}
-
-		if rowSlice, ok := rows[0].([]interface{}); ok {
-			if columns, ok := response["columns"].([]interface{}); ok {
-				invocation := make(map[string]interface{}, len(columns))
-				for i, column := range columns {
-					name, ok := column.(string)
-					if !ok {
-						continue
-					}
-					if i < len(rowSlice) {
-						invocation[name] = rowSlice[i]
-					}
-				}
-				c.logger.Info("invocation data from row slice", zap.Any("invocation", invocation))
-				return invocation, nil
-			}
+		status, err := c.getExecutionStatusByIdempotencyKey(ctx, executionID)
+		if err != nil {
+			c.logger.Debug("failed to get execution status",
+				zap.String("execution_id", executionID),
+				zap.Error(err))
+			continue
 		}
+		statuses[executionID] = status
 	}
-
-	if data, ok := response["data"].(map[string]interface{}); ok {
-		return data, nil
+	if len(invocationIDs) == 0 {
+		return statuses, nil
 	}
 
-	if status, ok := response["status"]; ok {
-		return map[string]interface{}{"status": status}, nil
+	if c.adminEndpoint == "" {
+		return nil, fmt.Errorf("failed to query execution statuses: admin endpoint is not configured")
+	}
+	quoted := make([]string, len(invocationIDs))
+	for i, id := range invocationIDs {
+		quoted[i] = "'" + strings.ReplaceAll(id, "'", "''") + "'"
+	}
+	response, err := c.query(ctx, fmt.Sprintf("select * from sys_invocation where id in (%s);", strings.Join(quoted, ", ")))
+	if err != nil {
+		return nil, err
+	}
+	for _, invocation := range invocationRows(response) {
+		id, _ := invocation["id"].(string)
+		if id == "" {
+			continue
+		}
+		statuses[id] = c.statusFromInvocation(id, invocation)
 	}
 
-	return nil, fmt.Errorf("failed to query execution status: unexpected response format")
+	c.logger.Debug("queried execution statuses",
+		zap.Int("executions", len(executionIDs)),
+		zap.Int("found", len(statuses)))
+	return statuses, nil
+}
+
+// invocationRows returns the rows of a sys_invocation query response, which the admin API returns
+// either as objects or as value arrays alongside the column names
+func invocationRows(response map[string]interface{}) []map[string]interface{} {
+	rows, _ := response["rows"].([]interface{})
+	columns, _ := response["columns"].([]interface{})
+
+	invocations := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		if rowMap, ok := row.(map[string]interface{}); ok {
+			invocations = append(invocations, rowMap)
+			continue
+		}
+		rowSlice, ok := row.([]interface{})
+		if !ok || columns == nil {
+			continue
+		}
+		invocation := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			name, ok := column.(string)
+			if !ok {
+				continue
+			}
+			if i < len(rowSlice) {
+				invocation[name] = rowSlice[i]
+			}
+		}
+		invocations = append(invocations, invocation)
+	}
+	return invocations
 }
 
 // CancelExecution cancels a Restate execution
@@ -603,6 +744,34 @@ func (c *Client) DeleteService(ctx context.Context, serviceName string) error {
 	return nil
 }
 
+// AdminHealth checks that the admin API answers its health endpoint
+func (c *Client) AdminHealth(ctx context.Context) error {
+	return c.testConnection(ctx)
+}
+
+// IngressHealth checks that the ingress, which workflows are invoked through, answers its health endpoint
+func (c *Client) IngressHealth(ctx context.Context) error {
+	url := fmt.Sprintf("%s/restate/health", strings.TrimSuffix(c.endpoint, "/"))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create ingress health request: %w", err)
+	}
+	if err := c.addAuthHeader(req); err != nil {
+		return fmt.Errorf("failed to add auth header: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to restate ingress: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ingress health check failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
 // testConnection tests the connection to the Restate server
 func (c *Client) testConnection(ctx context.Context) error {
 	url := fmt.Sprintf("%s/health", c.adminEndpoint)
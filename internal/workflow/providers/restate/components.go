@@ -0,0 +1,235 @@
+package restate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/featureflag"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/vulnscan"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// componentOutput is one component's entry in the components section of a workflow output
+type componentOutput struct {
+	ComputeID string                `json:"compute_id"`
+	Result    interface{}           `json:"result"`
+	Endpoints []compute.Endpoint    `json:"endpoints,omitempty"`
+	Scan      *vulnscan.Summary     `json:"vulnerability_scan,omitempty"`
+	Probe     *workflow.ProbeResult `json:"readiness_probe,omitempty"`
+
+	name   string
+	config map[string]interface{}
+}
+
+// scanComponents scans every component's image, keyed by component name
+func (s *TenantProvisioningService) scanComponents(ctx context.Context, tenantID string, components []tenant.Component) (map[string]*vulnscan.Summary, error) {
+	scans := make(map[string]*vulnscan.Summary, len(components))
+	for _, component := range components {
+		scan, err := s.scanImage(ctx, tenant.ComponentComputeID(tenantID, component.Name), component.Config)
+		if err != nil {
+			return nil, err
+		}
+		scans[component.Name] = scan
+	}
+	return scans, nil
+}
+
+// provisionComponent provisions one component's compute, rolling it back when provisioning fails
+func (s *TenantProvisioningService) provisionComponent(ctx context.Context, tenantID, providerType string, computeProvider compute.Provider, component tenant.Component, secretRefs []compute.SecretReference, labels map[string]string) (*componentOutput, error) {
+	computeID := tenant.ComponentComputeID(tenantID, component.Name)
+	spec := buildComputeSpec(computeID, providerType, component.Config, labels)
+	spec.Secrets = secretRefs
+	var provisioned *compute.ProvisionResult
+	err := s.leased(ctx, computeProvider, compute.OperationTypeProvision, computeID, "provision", func(ctx context.Context) (err error) {
+		provisioned, err = computeProvider.Provision(ctx, spec)
+		return err
+	})
+	out := &componentOutput{ComputeID: computeID, Result: provisioned, name: component.Name, config: component.Config}
+	if err != nil {
+		// A stalled or timed-out provision left resources in an unknown state, so it is not
+		// treated as done even if they exist
+		status, statusErr := computeProvider.GetStatus(ctx, computeID)
+		if statusErr != nil || workflow.LeaseExpired(err) {
+			s.logger.Error("compute provisioning failed", zap.String("component", component.Name), zap.Error(err))
+			return nil, fmt.Errorf("compute provisioning failed: %w", s.rollbackProvision(ctx, computeID, computeProvider, err))
+		}
+		out.Result = status
+		return out, nil
+	}
+	if provisioned != nil {
+		out.Endpoints = provisioned.Endpoints
+	}
+	return out, nil
+}
+
+// componentRollbackTimeout bounds destroying the components of a failed provision, which runs even
+// when the invocation was cancelled
+const componentRollbackTimeout = 30 * time.Second
+
+// rollbackComponents destroys the components a failed provision created before the one that failed,
+// newest first, so Restate's retry of the invocation starts from a clean slate. Components that
+// already existed are left alone. It returns err, joined with the errors of components left behind.
+func (s *TenantProvisioningService) rollbackComponents(ctx context.Context, tenantID string, computeProvider compute.Provider, outputs []*componentOutput, err error) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), componentRollbackTimeout)
+	defer cancel()
+	for i := len(outputs) - 1; i >= 0; i-- {
+		if _, created := outputs[i].Result.(*compute.ProvisionResult); !created {
+			continue
+		}
+		if destroyErr := s.destroyComponent(ctx, tenantID, outputs[i].name, computeProvider); destroyErr != nil {
+			operationMetrics.Add("rollback_failed_total", 1)
+			err = errors.Join(err, fmt.Errorf("%w: rollback of component %s failed: %v", compute.ErrPartiallyProvisioned, outputs[i].name, destroyErr))
+		}
+	}
+	return err
+}
+
+// updateComponent updates one component's compute. Updates report no endpoints, so the component
+// keeps the ones recorded for it.
+func (s *TenantProvisioningService) updateComponent(ctx context.Context, tenantID, providerType string, computeProvider compute.Provider, component tenant.Component, secretRefs []compute.SecretReference, labels map[string]string, endpoints []compute.Endpoint) (*componentOutput, error) {
+	computeID := tenant.ComponentComputeID(tenantID, component.Name)
+	spec := buildComputeSpec(computeID, providerType, component.Config, labels)
+	spec.Secrets = secretRefs
+	var result *compute.UpdateResult
+	err := s.leased(ctx, computeProvider, compute.OperationTypeUpdate, computeID, "update", func(ctx context.Context) (err error) {
+		result, err = computeProvider.Update(ctx, computeID, spec)
+		return err
+	})
+	if err != nil {
+		s.logger.Error("compute update failed", zap.String("component", component.Name), zap.Error(err))
+		return nil, fmt.Errorf("compute update failed: %w", err)
+	}
+	return &componentOutput{
+		ComputeID: computeID,
+		Result:    updateOutput{UpdateResult: result, Endpoints: endpoints},
+		Endpoints: endpoints,
+		name:      component.Name,
+		config:    component.Config,
+	}, nil
+}
+
+// destroyComponent destroys one component's compute; compute that is already gone is not an error
+func (s *TenantProvisioningService) destroyComponent(ctx context.Context, tenantID, component string, computeProvider compute.Provider) error {
+	computeID := tenant.ComponentComputeID(tenantID, component)
+	err := s.leased(ctx, computeProvider, compute.OperationTypeDelete, computeID, "destroy", func(ctx context.Context) error {
+		return computeProvider.Destroy(ctx, computeID)
+	})
+	if err != nil {
+		if errors.Is(err, compute.ErrTenantNotFound) {
+			s.logger.Info("compute resources already removed", zap.String("tenant_id", computeID))
+			return nil
+		}
+		s.logger.Error("compute deprovisioning failed", zap.String("component", component), zap.Error(err))
+		return fmt.Errorf("compute deprovisioning failed: %w", err)
+	}
+	return nil
+}
+
+// probeComponents runs each component's readiness probe, unless the tenant sets the
+// skip-health-check feature flag. The tenant is only ready once every component is, so the first
+// probe that never passes fails the workflow.
+func (s *TenantProvisioningService) probeComponents(ctx context.Context, req *ProvisioningRequest, outputs []*componentOutput, computeProvider compute.Provider) error {
+	if featureflag.FromMetadata(req.Metadata).SkipHealthCheck {
+		s.logger.Info("skipping readiness probes",
+			zap.String("tenant_id", req.TenantID),
+			zap.String("feature_flag", featureflag.SkipHealthCheck))
+		return nil
+	}
+	for _, out := range outputs {
+		probe, err := s.probeReadiness(ctx, out.ComputeID, out.config, out.Endpoints, computeProvider)
+		if err != nil {
+			if len(outputs) > 1 {
+				return fmt.Errorf("component %s: %w", out.name, err)
+			}
+			return err
+		}
+		out.Probe = probe
+	}
+	return nil
+}
+
+// previousComponents returns the components the tenant ran before this workflow with their
+// endpoints. Tenants whose output records no components ran as the default component.
+func previousComponents(req *ProvisioningRequest) map[string][]compute.Endpoint {
+	if len(req.PreviousComponents) == 0 {
+		return map[string][]compute.Endpoint{tenant.DefaultComponent: req.PreviousEndpoints}
+	}
+	previous := make(map[string][]compute.Endpoint, len(req.PreviousComponents))
+	for _, record := range req.PreviousComponents {
+		previous[record.Name] = record.Endpoints
+	}
+	return previous
+}
+
+// marshalComponentsOutput encodes a workflow output. The default component (or the first, when there
+// is none) is reported at the top level as before components existed. Tenants that declare
+// components also get a components section, every component's endpoints and resource IDs, the
+// latter prefixed with the component name.
+func marshalComponentsOutput(desiredConfig map[string]interface{}, outputs []*componentOutput, hookResults []workflow.HookResult, resourceOutputs map[string]interface{}) ([]byte, error) {
+	primary := outputs[0]
+	for _, out := range outputs {
+		if out.name == tenant.DefaultComponent {
+			primary = out
+		}
+	}
+	output, err := marshalOutput(primary.Result, hookResults, resourceOutputs, primary.Scan, primary.Probe)
+	if err != nil || !tenant.HasComponents(desiredConfig) {
+		return output, err
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(output, &fields); err != nil {
+		return nil, fmt.Errorf("marshal output: %w", err)
+	}
+	resourceIDs, _ := fields["resource_ids"].(map[string]interface{})
+	if resourceIDs == nil {
+		resourceIDs = make(map[string]interface{})
+	}
+	components := make(map[string]*componentOutput, len(outputs))
+	var endpoints []compute.Endpoint
+	for _, out := range outputs {
+		components[out.name] = out
+		endpoints = append(endpoints, out.Endpoints...)
+		if out == primary {
+			continue
+		}
+		for key, id := range componentResourceIDs(out.Result) {
+			resourceIDs[out.name+"."+key] = id
+		}
+	}
+	fields[tenant.ComponentsConfigKey] = components
+	delete(fields, "endpoints")
+	if len(endpoints) > 0 {
+		fields["endpoints"] = endpoints
+	}
+	if len(resourceIDs) > 0 {
+		fields["resource_ids"] = resourceIDs
+	}
+	output, err = json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("marshal output: %w", err)
+	}
+	return output, nil
+}
+
+// componentResourceIDs returns the provider resource IDs a component's compute result reports
+func componentResourceIDs(result interface{}) map[string]string {
+	switch result := result.(type) {
+	case *compute.ProvisionResult:
+		if result != nil {
+			return result.ResourceIDs
+		}
+	case *compute.ComputeStatus:
+		if result != nil {
+			return result.ResourceIDs
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package restate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jaxxstorm/landlord/internal/doctor"
+)
+
+var _ doctor.Diagnoser = (*Provider)(nil)
+
+// Diagnose checks that Restate's admin API and ingress answer, and that the worker deployment
+// serving tenant workflows is registered with Restate
+func (p *Provider) Diagnose(ctx context.Context) []doctor.Result {
+	client, err := p.ensureClient(ctx)
+	if err != nil {
+		return []doctor.Result{doctor.Fail("", doctor.SeverityCritical, fmt.Sprintf("failed to create restate client: %v", err),
+			"Check workflow.restate.endpoint and workflow.restate.admin_endpoint")}
+	}
+
+	var results []doctor.Result
+	if err := client.AdminHealth(ctx); err != nil {
+		results = append(results, doctor.Fail("admin", doctor.SeverityCritical, err.Error(),
+			fmt.Sprintf("Check that Restate is running and its admin API is reachable at %s (workflow.restate.admin_endpoint)", client.adminEndpoint)))
+	} else {
+		results = append(results, doctor.Pass("admin", "admin API is reachable"))
+	}
+
+	if err := client.IngressHealth(ctx); err != nil {
+		results = append(results, doctor.Fail("ingress", doctor.SeverityCritical, err.Error(),
+			fmt.Sprintf("Check that Restate is running and its ingress is reachable at %s (workflow.restate.endpoint)", client.endpoint)))
+	} else {
+		results = append(results, doctor.Pass("ingress", "ingress is reachable"))
+	}
+
+	return append(results, p.diagnoseWorkerDeployment(ctx, client))
+}
+
+func (p *Provider) diagnoseWorkerDeployment(ctx context.Context, client *Client) doctor.Result {
+	const name = "worker-deployment"
+	uri := p.config.WorkerAdvertisedURL
+	if uri == "" {
+		return doctor.Skip(name, "workflow.restate.worker_advertised_url is not set, so the worker deployment cannot be looked up")
+	}
+
+	exists, err := client.DeploymentExists(ctx, uri)
+	switch {
+	case errors.Is(err, errAdminAPINotSupported):
+		return doctor.Skip(name, "the restate admin API cannot list deployments")
+	case err != nil:
+		return doctor.Fail(name, doctor.SeverityCritical, fmt.Sprintf("failed to list deployments: %v", err),
+			"Check the admin API result above")
+	case !exists:
+		return doctor.Fail(name, doctor.SeverityCritical, fmt.Sprintf("worker deployment %s is not registered, so tenant workflows cannot run", uri),
+			fmt.Sprintf("Start the worker with workflow.restate.worker_register_on_startup, or register it with `restate deployments register %s`", uri))
+	}
+	return doctor.Pass(name, fmt.Sprintf("worker deployment %s is registered", uri))
+}
@@ -0,0 +1,68 @@
+package restate_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/doctor"
+	"github.com/jaxxstorm/landlord/internal/workflow/providers/restate"
+)
+
+func TestDiagnose(t *testing.T) {
+	server := newFakeRestateServer(t)
+	cfg := config.RestateConfig{
+		Endpoint:            server.URL(),
+		AdminEndpoint:       server.URL(),
+		ExecutionMechanism:  "local",
+		AuthType:            "none",
+		Timeout:             time.Second,
+		WorkerAdvertisedURL: "http://worker:9080",
+	}
+	provider, err := restate.New(cfg, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	statuses := func() map[string]doctor.Status {
+		byCheck := make(map[string]doctor.Status)
+		for _, result := range provider.Diagnose(context.Background()) {
+			byCheck[result.Check] = result.Status
+		}
+		return byCheck
+	}
+
+	assert.Equal(t, map[string]doctor.Status{
+		"admin":             doctor.StatusPass,
+		"ingress":           doctor.StatusPass,
+		"worker-deployment": doctor.StatusFail,
+	}, statuses())
+
+	client, err := restate.NewClient(context.Background(), cfg, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	require.NoError(t, client.RegisterDeployment(context.Background(), cfg.WorkerAdvertisedURL))
+	assert.Equal(t, doctor.StatusPass, statuses()["worker-deployment"])
+}
+
+func TestDiagnoseUnreachableRestate(t *testing.T) {
+	cfg := config.RestateConfig{
+		Endpoint:           "http://127.0.0.1:1",
+		ExecutionMechanism: "local",
+		AuthType:           "none",
+		Timeout:            100 * time.Millisecond,
+	}
+	provider, err := restate.New(cfg, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	results := provider.Diagnose(context.Background())
+	require.Len(t, results, 3)
+	for _, result := range results[:2] {
+		assert.Equal(t, doctor.StatusFail, result.Status, result.Check)
+		assert.Equal(t, doctor.SeverityCritical, result.Severity, result.Check)
+		assert.NotEmpty(t, result.Fix, result.Check)
+	}
+	assert.Equal(t, doctor.StatusSkip, results[2].Status)
+}
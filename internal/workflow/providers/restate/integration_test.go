@@ -125,6 +125,12 @@ func TestRestateWorkerLifecycleWithRegistration(t *testing.T) {
 		t.Fatalf("failed to create worker engine: %v", err)
 	}
 
+	workerCtx, cancel := context.WithCancel(ctx)
+	t.Cleanup(cancel)
+	go func() {
+		_ = worker.Start(workerCtx, "127.0.0.1:0")
+	}()
+
 	if err := worker.Register(ctx); err != nil {
 		t.Fatalf("worker registration failed: %v", err)
 	}
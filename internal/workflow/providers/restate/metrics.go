@@ -0,0 +1,97 @@
+package restate
+
+import (
+	"expvar"
+	"time"
+)
+
+// registrationMetrics are published under the "restate_worker_registration" expvar
+// and served from /debug/vars on the worker listener.
+var registrationMetrics = newRegistrationStats(expvar.NewMap("restate_worker_registration"))
+
+// versionSkewEvents counts landlord server versions first seen more than one minor version from
+// this worker's, served from /debug/vars as "restate_worker_version_skew_events_total"
+var versionSkewEvents = expvar.NewInt("restate_worker_version_skew_events_total")
+
+// RegistrationStats tracks the state of the worker's deployment registration with Restate.
+type RegistrationStats struct {
+	registered          expvar.Int
+	registrations       expvar.Int
+	registrationErrors  expvar.Int
+	checks              expvar.Int
+	checkErrors         expvar.Int
+	missingDeployments  expvar.Int
+	lastRegisteredUnix  expvar.Int
+	lastCheckUnix       expvar.Int
+	lastCheckSuccessful expvar.Int
+}
+
+func newRegistrationStats(vars *expvar.Map) *RegistrationStats {
+	s := &RegistrationStats{}
+	vars.Set("registered", &s.registered)
+	vars.Set("registrations_total", &s.registrations)
+	vars.Set("registration_errors_total", &s.registrationErrors)
+	vars.Set("checks_total", &s.checks)
+	vars.Set("check_errors_total", &s.checkErrors)
+	vars.Set("missing_deployments_total", &s.missingDeployments)
+	vars.Set("last_registered_unix", &s.lastRegisteredUnix)
+	vars.Set("last_check_unix", &s.lastCheckUnix)
+	vars.Set("last_check_successful", &s.lastCheckSuccessful)
+	return s
+}
+
+// RegistrationMetrics returns the process-wide worker registration metrics.
+func RegistrationMetrics() *RegistrationStats {
+	return registrationMetrics
+}
+
+// Registered reports whether the last known registration state is registered.
+func (s *RegistrationStats) Registered() bool {
+	return s.registered.Value() == 1
+}
+
+// Registrations returns the number of successful deployment registrations.
+func (s *RegistrationStats) Registrations() int64 {
+	return s.registrations.Value()
+}
+
+// MissingDeployments returns how many checks found the deployment missing from Restate.
+func (s *RegistrationStats) MissingDeployments() int64 {
+	return s.missingDeployments.Value()
+}
+
+func (s *RegistrationStats) recordRegistration(err error, now time.Time) {
+	if err != nil {
+		s.registrationErrors.Add(1)
+		s.registered.Set(0)
+		return
+	}
+	s.registrations.Add(1)
+	s.registered.Set(1)
+	s.lastRegisteredUnix.Set(now.Unix())
+}
+
+func (s *RegistrationStats) recordCheck(exists bool, err error, now time.Time) {
+	s.checks.Add(1)
+	s.lastCheckUnix.Set(now.Unix())
+	if err != nil {
+		s.checkErrors.Add(1)
+		s.lastCheckSuccessful.Set(0)
+		return
+	}
+	s.lastCheckSuccessful.Set(1)
+	if !exists {
+		s.missingDeployments.Add(1)
+		s.registered.Set(0)
+	}
+}
+
+// operationMetrics count compute operation heartbeats, the operations cancelled because they
+// stalled or ran past their maximum duration, and failed provisions whose rollback left resources
+// behind, served from /debug/vars as
+// "restate_worker_compute_operations"
+var operationMetrics = expvar.NewMap("restate_worker_compute_operations")
+
+// stepMetrics count workflow steps by outcome, and the step reports the landlord API did not
+// accept, served from /debug/vars as "restate_worker_steps"
+var stepMetrics = expvar.NewMap("restate_worker_steps")
@@ -10,6 +10,7 @@ import (
 	"github.com/jaxxstorm/landlord/internal/compute"
 	"github.com/jaxxstorm/landlord/internal/config"
 	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
 	"go.uber.org/zap"
 )
 
@@ -84,6 +85,10 @@ func (p *Provider) Invoke(ctx context.Context, workflowID string, request *workf
 	}
 
 	executionName := fmt.Sprintf("tenant-%s-%s-%s", tenantIdentifier, workflowID, operation)
+	if request.MigrationPhase != "" {
+		// Each migration phase is its own execution of the migrate workflow
+		executionName = fmt.Sprintf("%s-%s", executionName, request.MigrationPhase)
+	}
 	input := &workflow.ExecutionInput{
 		ExecutionName: executionName,
 		Input:         payload,
@@ -93,7 +98,8 @@ func (p *Provider) Invoke(ctx context.Context, workflowID string, request *workf
 			"operation":   operation,
 		},
 		Metadata:      request.Metadata, // Pass through metadata (e.g., config_hash)
-		TriggerSource: "reconciler",
+		TriggerSource:   "reconciler",
+		WorkflowVersion: workflow.EffectiveWorkflowVersion(request.WorkflowVersion),
 	}
 
 	return p.StartExecution(ctx, workflowID, input)
@@ -145,6 +151,9 @@ func (p *Provider) PostComputeCallback(ctx context.Context, executionID string,
 	if payload == nil {
 		return fmt.Errorf("callback payload is required")
 	}
+	if err := schema.Validate(schema.ComputeCallback, payload); err != nil {
+		return err
+	}
 
 	// Restate callback mechanism would typically involve:
 	// 1. Calling a Restate service endpoint to deliver the callback
@@ -328,6 +337,24 @@ func (p *Provider) GetExecutionStatus(ctx context.Context, executionID string) (
 	return status, nil
 }
 
+// GetExecutionStatuses queries the state of many executions in one admin query
+func (p *Provider) GetExecutionStatuses(ctx context.Context, executionIDs []string) (map[string]*workflow.ExecutionStatus, error) {
+	client, err := p.ensureClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize restate client: %w", err)
+	}
+
+	statuses, err := client.GetExecutionStatuses(ctx, executionIDs)
+	if err != nil {
+		p.logger.Error("failed to get execution statuses",
+			zap.Int("executions", len(executionIDs)),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("failed to get execution statuses: %w", err)
+	}
+	return statuses, nil
+}
+
 // StopExecution stops a running execution
 func (p *Provider) StopExecution(ctx context.Context, executionID string, reason string) error {
 	if executionID == "" {
@@ -358,6 +385,11 @@ func (p *Provider) StopExecution(ctx context.Context, executionID string, reason
 	return nil
 }
 
+// ListWorkflows returns the tenant workflows served by Restate workers at every supported version
+func (p *Provider) ListWorkflows(ctx context.Context) ([]workflow.WorkflowDefinition, error) {
+	return workflow.VersionedDefinitions(defaultWorkflowIDs()), nil
+}
+
 // DeleteWorkflow removes a workflow definition
 func (p *Provider) DeleteWorkflow(ctx context.Context, workflowID string) error {
 	if workflowID == "" {
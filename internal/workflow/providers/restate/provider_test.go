@@ -244,6 +244,27 @@ func TestGetExecutionStatus(t *testing.T) {
 	assert.Equal(t, "restate", status.ProviderType)
 }
 
+// TestGetExecutionStatuses tests that Provider.GetExecutionStatuses reads invocations in one query
+func TestGetExecutionStatuses(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	server := newFakeRestateServer(t)
+	cfg := config.RestateConfig{
+		Endpoint:           server.URL(),
+		ExecutionMechanism: "local",
+		AuthType:           "none",
+		Timeout:            30 * time.Minute,
+	}
+
+	provider, err := restate.New(cfg, logger)
+	require.NoError(t, err)
+
+	statuses, err := provider.GetExecutionStatuses(context.Background(), []string{"inv_one", "inv_two", "inv_three"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, server.QueryCount())
+	require.Len(t, statuses, 3)
+	assert.Equal(t, workflow.StateSucceeded, statuses["inv_two"].State)
+}
+
 // TestStopExecution tests Provider.StopExecution
 func TestStopExecution(t *testing.T) {
 	logger := zaptest.NewLogger(t)
@@ -0,0 +1,104 @@
+package restate
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+// stepReportTimeout bounds each step report, so an unreachable API server cannot stall a workflow
+const stepReportTimeout = 5 * time.Second
+
+type executionIDKey struct{}
+type stepTrackerKey struct{}
+
+// withExecutionID returns a context carrying the Restate invocation ID the workflow runs as
+func withExecutionID(ctx context.Context, executionID string) context.Context {
+	return context.WithValue(ctx, executionIDKey{}, executionID)
+}
+
+func executionIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(executionIDKey{}).(string)
+	return id
+}
+
+// stepTracker reports the steps of one execution as the workflow moves through them. Only
+// one step runs at a time: beginning a step finishes the previous one as succeeded.
+type stepTracker struct {
+	reporter    workflow.StepReporter
+	executionID string
+	tenantID    string
+	logger      *zap.Logger
+
+	current *workflow.StepReport
+}
+
+// trackSteps returns ctx carrying a tracker for the execution, when the service has a reporter
+// and the execution is known
+func (s *TenantProvisioningService) trackSteps(ctx context.Context, tenantID string) (context.Context, *stepTracker) {
+	executionID := executionIDFromContext(ctx)
+	if s.stepReporter == nil || executionID == "" {
+		return ctx, nil
+	}
+	tracker := &stepTracker{
+		reporter:    s.stepReporter,
+		executionID: executionID,
+		tenantID:    tenantID,
+		logger:      s.logger,
+	}
+	return context.WithValue(ctx, stepTrackerKey{}, tracker), tracker
+}
+
+// beginStep starts reporting step for the execution ctx belongs to, finishing the previous one
+func beginStep(ctx context.Context, step string) {
+	tracker, _ := ctx.Value(stepTrackerKey{}).(*stepTracker)
+	if tracker == nil {
+		return
+	}
+	tracker.finish(ctx, nil)
+	tracker.current = &workflow.StepReport{
+		ExecutionID: tracker.executionID,
+		TenantID:    tracker.tenantID,
+		Step:        step,
+		Status:      workflow.StepRunning,
+		StartedAt:   time.Now().UTC(),
+	}
+	tracker.report(ctx, *tracker.current)
+}
+
+// finish reports the running step as succeeded, or failed with err
+func (t *stepTracker) finish(ctx context.Context, err error) {
+	if t == nil || t.current == nil {
+		return
+	}
+	report := *t.current
+	t.current = nil
+
+	finished := time.Now().UTC()
+	report.FinishedAt = &finished
+	report.Status = workflow.StepSucceeded
+	if err != nil {
+		report.Status = workflow.StepFailed
+		report.Error = err.Error()
+	}
+	stepMetrics.Add(string(report.Status)+"_total", 1)
+	t.report(ctx, report)
+}
+
+func (t *stepTracker) report(ctx context.Context, report workflow.StepReport) {
+	// A failed step is still reported after its invocation was cancelled
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), stepReportTimeout)
+	defer cancel()
+	if err := t.reporter.ReportStep(ctx, report); err != nil {
+		stepMetrics.Add("report_errors_total", 1)
+		t.logger.Warn("failed to report workflow step",
+			zap.String("execution_id", report.ExecutionID),
+			zap.String("tenant_id", report.TenantID),
+			zap.String("step", report.Step),
+			zap.String("status", string(report.Status)),
+			zap.Error(err))
+	}
+}
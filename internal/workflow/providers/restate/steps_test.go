@@ -0,0 +1,89 @@
+package restate
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	computemock "github.com/jaxxstorm/landlord/internal/compute/providers/mock"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+type recordingStepReporter struct {
+	mu      sync.Mutex
+	reports []workflow.StepReport
+}
+
+func (r *recordingStepReporter) ReportStep(ctx context.Context, report workflow.StepReport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, report)
+	return nil
+}
+
+func (r *recordingStepReporter) sequence() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seq := make([]string, 0, len(r.reports))
+	for _, report := range r.reports {
+		seq = append(seq, report.Step+":"+string(report.Status))
+	}
+	return seq
+}
+
+func TestExecuteReportsSteps(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	registry := compute.NewRegistry(logger)
+	require.NoError(t, registry.Register(computemock.New()))
+
+	service := NewTenantProvisioningService(registry, "mock", nil, logger)
+	reporter := &recordingStepReporter{}
+	service.SetStepReporter(reporter)
+
+	_, err := service.Execute(withExecutionID(context.Background(), "inv_1"), &ProvisioningRequest{
+		TenantID:      "tenant-steps",
+		Operation:     "apply",
+		DesiredConfig: map[string]interface{}{"image": "example:v1"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"validate:running", "validate:succeeded",
+		"provision:running", "provision:succeeded",
+		"health-check:running", "health-check:succeeded",
+		"finalize:running", "finalize:succeeded",
+	}, reporter.sequence())
+	for _, report := range reporter.reports {
+		require.Equal(t, "inv_1", report.ExecutionID)
+		require.Equal(t, "tenant-steps", report.TenantID)
+		require.Equal(t, report.Status != workflow.StepRunning, report.FinishedAt != nil)
+	}
+
+	failing := &recordingStepReporter{}
+	service.SetStepReporter(failing)
+	_, err = service.Execute(withExecutionID(context.Background(), "inv_2"), &ProvisioningRequest{
+		TenantID:  "tenant-steps",
+		Operation: "apply",
+		DesiredConfig: map[string]interface{}{
+			"image":                 "example:v1",
+			workflow.HooksConfigKey: map[string]interface{}{"unknown": true},
+		},
+	})
+	require.Error(t, err)
+	require.Equal(t, []string{"validate:running", "validate:failed"}, failing.sequence())
+	require.NotEmpty(t, failing.reports[1].Error)
+
+	// Without an execution ID there is nothing to report the steps against
+	untracked := &recordingStepReporter{}
+	service.SetStepReporter(untracked)
+	_, err = service.Execute(context.Background(), &ProvisioningRequest{
+		TenantID:      "tenant-steps",
+		Operation:     "apply",
+		DesiredConfig: map[string]interface{}{"image": "example:v1"},
+	})
+	require.NoError(t, err)
+	require.Empty(t, untracked.sequence())
+}
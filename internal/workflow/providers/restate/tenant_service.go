@@ -5,10 +5,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/jaxxstorm/landlord/internal/compute"
 	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/endpointauth"
+	"github.com/jaxxstorm/landlord/internal/resource"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	landlordversion "github.com/jaxxstorm/landlord/internal/version"
+	"github.com/jaxxstorm/landlord/internal/vulnscan"
 	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
 	restate "github.com/restatedev/sdk-go"
 	"github.com/restatedev/sdk-go/server"
 	"go.uber.org/zap"
@@ -19,6 +28,15 @@ type TenantProvisioningService struct {
 	computeRegistry        *compute.Registry
 	defaultComputeProvider string
 	computeResolver        workflow.ComputeProviderResolver
+	hookRunner             *workflow.HookRunner
+	resources              *resource.Registry
+	vulnScan               *vulnscan.Gate
+	endpointAuth           *endpointauth.Generator
+	versions               *landlordversion.Tracker
+	lease                  workflow.LeaseConfig
+	limiter                *compute.Limiter
+	abortTimeout           time.Duration
+	stepReporter           workflow.StepReporter
 	logger                 *zap.Logger
 }
 
@@ -36,15 +54,97 @@ func NewTenantProvisioningService(
 		computeRegistry:        computeRegistry,
 		defaultComputeProvider: defaultComputeProvider,
 		computeResolver:        computeResolver,
+		hookRunner:             workflow.NewHookRunner(nil, logger),
+		versions:               landlordversion.NewTracker(landlordversion.Current(landlordversion.ComponentWorker), logger),
 		logger:                 logger.With(zap.String("component", "tenant-provisioning-service")),
 	}
 }
 
+// SetResourceRegistry sets the resource providers tenants can declare under desired_config.resources.
+func (s *TenantProvisioningService) SetResourceRegistry(registry *resource.Registry) {
+	s.resources = registry
+}
+
+// SetVulnerabilityScanner scans tenant images before they are provisioned or updated.
+func (s *TenantProvisioningService) SetVulnerabilityScanner(gate *vulnscan.Gate) {
+	s.vulnScan = gate
+}
+
+// SetEndpointAuth injects endpoint credentials into tenants that set desired_config.endpoint_auth.
+func (s *TenantProvisioningService) SetEndpointAuth(generator *endpointauth.Generator) {
+	s.endpointAuth = generator
+}
+
+// SetOperationLease bounds compute provisioning, updates and deletions: operations that stop
+// heartbeating are cancelled as stalled, and none runs past the lease's MaxDuration.
+func (s *TenantProvisioningService) SetOperationLease(lease workflow.LeaseConfig) {
+	s.lease = lease
+}
+
+// SetConcurrencyLimiter limits how many provisions, updates and destroys each compute provider
+// runs at once. Operations over a limit wait their turn before their lease starts.
+func (s *TenantProvisioningService) SetConcurrencyLimiter(limiter *compute.Limiter) {
+	s.limiter = limiter
+}
+
+// SetAbortTimeout registers an abort timeout with the service when it is bound, overriding
+// Restate's default, so Restate does not abort an invocation whose operation is still alive.
+// Zero keeps Restate's default.
+func (s *TenantProvisioningService) SetAbortTimeout(timeout time.Duration) {
+	s.abortTimeout = timeout
+}
+
+// SetStepReporter reports each workflow step's start and finish, so operators can see where a
+// running or failed execution is. Nil disables step reporting.
+func (s *TenantProvisioningService) SetStepReporter(reporter workflow.StepReporter) {
+	s.stepReporter = reporter
+}
+
+// leased runs a compute operation under the service's lease, counting its heartbeats and why it
+// was cancelled, if it was. The operation first waits for a slot when the provider is at its
+// concurrency limit for the operation's type.
+func (s *TenantProvisioningService) leased(ctx context.Context, provider compute.Provider, operationType compute.ComputeOperationType, tenantID, operation string, fn func(ctx context.Context) error) error {
+	release, err := s.limiter.Acquire(ctx, provider.Name(), operationType)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	lease := s.lease
+	lease.OnHeartbeat = func(detail string) {
+		operationMetrics.Add("heartbeats_total", 1)
+		s.logger.Debug("compute operation heartbeat",
+			zap.String("tenant_id", tenantID),
+			zap.String("operation", operation),
+			zap.String("detail", detail),
+		)
+	}
+
+	err = workflow.RunLeased(ctx, lease, fn)
+	switch {
+	case errors.Is(err, workflow.ErrHeartbeatTimeout):
+		operationMetrics.Add("stalled_total", 1)
+		s.logger.Warn("compute operation stalled", zap.String("tenant_id", tenantID), zap.String("operation", operation), zap.Error(err))
+	case errors.Is(err, workflow.ErrOperationTimeout):
+		operationMetrics.Add("timed_out_total", 1)
+		s.logger.Warn("compute operation timed out", zap.String("tenant_id", tenantID), zap.String("operation", operation), zap.Error(err))
+	}
+	return err
+}
+
 // Execute handles tenant lifecycle operations.
-func (s *TenantProvisioningService) Execute(ctx context.Context, req *ProvisioningRequest) (*workflow.ExecutionStatus, error) {
+func (s *TenantProvisioningService) Execute(ctx context.Context, req *ProvisioningRequest) (_ *workflow.ExecutionStatus, err error) {
 	if req == nil {
 		return nil, fmt.Errorf("request is required")
 	}
+	// Reject requests written against a schema version this worker predates rather than misread them
+	if err := schema.Validate(schema.ProvisionRequest, req); err != nil {
+		return nil, err
+	}
+	// The server that triggered the workflow reports its version, so skew is noticed on the worker too
+	if s.versions.Observe(landlordversion.ComponentServer, req.LandlordVersion) {
+		versionSkewEvents.Add(1)
+	}
 
 	tenantID := req.TenantUUID
 	if tenantID == "" {
@@ -59,31 +159,53 @@ func (s *TenantProvisioningService) Execute(ctx context.Context, req *Provisioni
 		operation = "provision"
 	}
 
+	// Executions finish on the definition they started with, so reject versions this worker no longer runs
+	version := workflow.EffectiveWorkflowVersion(req.WorkflowVersion)
+	if !workflow.IsSupportedWorkflowVersion(version) {
+		return nil, fmt.Errorf("unsupported workflow version %q (supported: %s)", version, strings.Join(workflow.SupportedWorkflowVersions(), ", "))
+	}
+
+	ctx, steps := s.trackSteps(ctx, tenantID)
+	defer func() { steps.finish(ctx, err) }()
+
 	s.logger.Info("executing tenant workflow",
 		zap.String("tenant_id", tenantID),
 		zap.String("tenant_name", req.TenantID),
 		zap.String("operation", operation),
+		zap.String("workflow_version", version),
 	)
 
 	switch operation {
 	case "plan":
-		return s.plan(tenantID)
+		return s.plan(ctx, tenantID, req)
 	case "create", "apply", "provision":
 		return s.provision(ctx, tenantID, req)
 	case "destroy", "delete":
 		return s.destroy(ctx, tenantID, req)
 	case "update":
 		return s.update(ctx, tenantID, req)
+	case "migrate":
+		return s.migrate(ctx, tenantID, req)
 	default:
 		return nil, fmt.Errorf("unknown operation: %s", req.Operation)
 	}
 }
 
-func (s *TenantProvisioningService) plan(tenantID string) (*workflow.ExecutionStatus, error) {
-	output, err := json.Marshal(map[string]string{
+func (s *TenantProvisioningService) plan(ctx context.Context, tenantID string, req *ProvisioningRequest) (*workflow.ExecutionStatus, error) {
+	fields := map[string]interface{}{
 		"status":    "planned",
 		"tenant_id": tenantID,
-	})
+	}
+
+	resourceStatus, err := s.resourceStatus(ctx, tenantID, req.DesiredConfig)
+	if err != nil {
+		return nil, err
+	}
+	if len(resourceStatus) > 0 {
+		fields[resource.ResourcesConfigKey] = resourceStatus
+	}
+
+	output, err := json.Marshal(fields)
 	if err != nil {
 		return nil, fmt.Errorf("marshal output: %w", err)
 	}
@@ -97,33 +219,71 @@ func (s *TenantProvisioningService) plan(tenantID string) (*workflow.ExecutionSt
 }
 
 func (s *TenantProvisioningService) provision(ctx context.Context, tenantID string, req *ProvisioningRequest) (*workflow.ExecutionStatus, error) {
+	beginStep(ctx, workflow.StepValidate)
 	computeProvider, providerType, err := s.resolveComputeProvider(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	spec := buildComputeSpec(tenantID, providerType, req.DesiredConfig)
-	result, err := computeProvider.Provision(ctx, spec)
+	components, err := tenant.ParseComponents(req.DesiredConfig)
 	if err != nil {
-		if status, statusErr := computeProvider.GetStatus(ctx, tenantID); statusErr == nil {
-			output, marshalErr := json.Marshal(status)
-			if marshalErr != nil {
-				return nil, fmt.Errorf("marshal output: %w", marshalErr)
-			}
-			return &workflow.ExecutionStatus{
-				ExecutionID:  fmt.Sprintf("provision-%s", tenantID),
-				ProviderType: "restate",
-				State:        workflow.StateSucceeded,
-				Output:       output,
-			}, nil
+		return nil, err
+	}
+	scans, err := s.scanComponents(ctx, tenantID, components)
+	if err != nil {
+		return nil, err
+	}
+	hooks, err := workflow.ParseHooks(req.DesiredConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	beginStep(ctx, workflow.StepProvision)
+	desiredConfig, secretRefs, resourceOutputs, err := s.provisionResources(ctx, tenantID, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredConfig, secretRefs, err = s.injectEndpointAuth(tenantID, req, desiredConfig, secretRefs)
+	if err != nil {
+		return nil, err
+	}
+
+	hookResults, err := s.runHooks(ctx, tenantID, workflow.HookPhasePreProvision, hooks, computeProvider, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Components inherit the resource credentials and endpoint auth injected into the tenant's config
+	components, err = tenant.ParseComponents(desiredConfig)
+	if err != nil {
+		return nil, err
+	}
+	labels := tenant.ManagedLabels(req.Metadata)
+	outputs := make([]*componentOutput, 0, len(components))
+	for _, component := range components {
+		out, err := s.provisionComponent(ctx, tenantID, providerType, computeProvider, component, secretRefs, labels)
+		if err != nil {
+			return nil, s.rollbackComponents(ctx, tenantID, computeProvider, outputs, err)
 		}
-		s.logger.Error("compute provisioning failed", zap.Error(err))
-		return nil, fmt.Errorf("compute provisioning failed: %w", err)
+		out.Scan = scans[component.Name]
+		outputs = append(outputs, out)
 	}
 
-	output, err := json.Marshal(result)
+	hookResults, err = s.runHooks(ctx, tenantID, workflow.HookPhasePostProvision, hooks, computeProvider, hookResults)
 	if err != nil {
-		return nil, fmt.Errorf("marshal output: %w", err)
+		return nil, err
+	}
+
+	beginStep(ctx, workflow.StepHealthCheck)
+	if err := s.probeComponents(ctx, req, outputs, computeProvider); err != nil {
+		return nil, err
+	}
+
+	beginStep(ctx, workflow.StepFinalize)
+	output, err := marshalComponentsOutput(req.DesiredConfig, outputs, hookResults, resourceOutputs)
+	if err != nil {
+		return nil, err
 	}
 
 	return &workflow.ExecutionStatus{
@@ -134,21 +294,51 @@ func (s *TenantProvisioningService) provision(ctx context.Context, tenantID stri
 	}, nil
 }
 
+// rollbackProvision tears down what a failed provision created, so Restate's retry of the
+// invocation starts from a clean slate. It returns err, joined with the rollback's error when
+// resources were left behind.
+func (s *TenantProvisioningService) rollbackProvision(ctx context.Context, tenantID string, computeProvider compute.Provider, err error) error {
+	rollbackErr := compute.RollbackProvision(ctx, computeProvider, tenantID, err)
+	if rollbackErr != nil {
+		operationMetrics.Add("rollback_failed_total", 1)
+		s.logger.Error("failed to roll back partial provisioning", zap.String("tenant_id", tenantID), zap.Error(rollbackErr))
+		return errors.Join(err, rollbackErr)
+	}
+	return err
+}
+
 func (s *TenantProvisioningService) destroy(ctx context.Context, tenantID string, req *ProvisioningRequest) (*workflow.ExecutionStatus, error) {
+	beginStep(ctx, workflow.StepValidate)
 	computeProvider, _, err := s.resolveComputeProvider(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := computeProvider.Destroy(ctx, tenantID); err != nil {
-		if errors.Is(err, compute.ErrTenantNotFound) {
-			s.logger.Info("compute resources already removed", zap.String("tenant_id", tenantID))
-		} else {
-			s.logger.Error("compute deprovisioning failed", zap.Error(err))
-			return nil, fmt.Errorf("compute deprovisioning failed: %w", err)
+	// Components dropped from the spec without a successful update still have compute to remove
+	names, err := tenant.ComponentNames(req.DesiredConfig)
+	if err != nil {
+		return nil, err
+	}
+	for name := range previousComponents(req) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	beginStep(ctx, workflow.StepDestroy)
+	// Providers that stop compute gracefully report its phases, which become steps of their own
+	stopCtx := compute.WithPhases(ctx, func(phase string) { beginStep(ctx, phase) })
+	for _, name := range names {
+		if err := s.destroyComponent(stopCtx, tenantID, name, computeProvider); err != nil {
+			return nil, err
 		}
 	}
 
+	if err := s.destroyResources(ctx, tenantID, req); err != nil {
+		return nil, err
+	}
+
+	beginStep(ctx, workflow.StepFinalize)
 	output, err := json.Marshal(map[string]string{
 		"status":    "archived",
 		"tenant_id": tenantID,
@@ -166,21 +356,86 @@ func (s *TenantProvisioningService) destroy(ctx context.Context, tenantID string
 }
 
 func (s *TenantProvisioningService) update(ctx context.Context, tenantID string, req *ProvisioningRequest) (*workflow.ExecutionStatus, error) {
+	beginStep(ctx, workflow.StepValidate)
 	computeProvider, providerType, err := s.resolveComputeProvider(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	spec := buildComputeSpec(tenantID, providerType, req.DesiredConfig)
-	result, err := computeProvider.Update(ctx, tenantID, spec)
+	components, err := tenant.ParseComponents(req.DesiredConfig)
+	if err != nil {
+		return nil, err
+	}
+	scans, err := s.scanComponents(ctx, tenantID, components)
+	if err != nil {
+		return nil, err
+	}
+	hooks, err := workflow.ParseHooks(req.DesiredConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	beginStep(ctx, workflow.StepUpdate)
+	desiredConfig, secretRefs, resourceOutputs, err := s.provisionResources(ctx, tenantID, req, true)
 	if err != nil {
-		s.logger.Error("compute update failed", zap.Error(err))
-		return nil, fmt.Errorf("compute update failed: %w", err)
+		return nil, err
 	}
 
-	output, err := json.Marshal(result)
+	desiredConfig, secretRefs, err = s.injectEndpointAuth(tenantID, req, desiredConfig, secretRefs)
 	if err != nil {
-		return nil, fmt.Errorf("marshal output: %w", err)
+		return nil, err
+	}
+
+	hookResults, err := s.runHooks(ctx, tenantID, workflow.HookPhasePreProvision, hooks, computeProvider, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	components, err = tenant.ParseComponents(desiredConfig)
+	if err != nil {
+		return nil, err
+	}
+	// Components added to the spec are provisioned, the rest updated in place
+	previous := previousComponents(req)
+	labels := tenant.ManagedLabels(req.Metadata)
+	outputs := make([]*componentOutput, 0, len(components))
+	for _, component := range components {
+		var out *componentOutput
+		if endpoints, ok := previous[component.Name]; ok {
+			out, err = s.updateComponent(ctx, tenantID, providerType, computeProvider, component, secretRefs, labels, endpoints)
+		} else {
+			out, err = s.provisionComponent(ctx, tenantID, providerType, computeProvider, component, secretRefs, labels)
+		}
+		if err != nil {
+			return nil, err
+		}
+		out.Scan = scans[component.Name]
+		outputs = append(outputs, out)
+	}
+
+	// Components dropped from the spec are destroyed once the remaining ones are up to date
+	for name := range previous {
+		if !slices.ContainsFunc(components, func(component tenant.Component) bool { return component.Name == name }) {
+			if err := s.destroyComponent(ctx, tenantID, name, computeProvider); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	hookResults, err = s.runHooks(ctx, tenantID, workflow.HookPhasePostProvision, hooks, computeProvider, hookResults)
+	if err != nil {
+		return nil, err
+	}
+
+	beginStep(ctx, workflow.StepHealthCheck)
+	if err := s.probeComponents(ctx, req, outputs, computeProvider); err != nil {
+		return nil, err
+	}
+
+	beginStep(ctx, workflow.StepFinalize)
+	output, err := marshalComponentsOutput(req.DesiredConfig, outputs, hookResults, resourceOutputs)
+	if err != nil {
+		return nil, err
 	}
 
 	return &workflow.ExecutionStatus{
@@ -191,6 +446,384 @@ func (s *TenantProvisioningService) update(ctx context.Context, tenantID string,
 	}, nil
 }
 
+// migrate runs one phase of moving a tenant to another compute provider. The controller starts
+// the next phase once this one succeeds, so the source keeps serving until the target is healthy.
+func (s *TenantProvisioningService) migrate(ctx context.Context, tenantID string, req *ProvisioningRequest) (*workflow.ExecutionStatus, error) {
+	beginStep(ctx, workflow.StepValidate)
+	if req.SourceComputeProvider == "" {
+		return nil, fmt.Errorf("source compute provider is required to migrate")
+	}
+	targetProvider, targetType, err := s.resolveComputeProvider(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if targetType == req.SourceComputeProvider {
+		return nil, fmt.Errorf("tenant is already on compute provider %s", targetType)
+	}
+	components, err := tenant.ParseComponents(req.DesiredConfig)
+	if err != nil {
+		return nil, err
+	}
+	if len(components) > 1 {
+		return nil, fmt.Errorf("tenants with several components cannot be migrated")
+	}
+	// A tenant whose only component is not the default one runs under that component's identifier
+	computeID := tenant.ComponentComputeID(tenantID, components[0].Name)
+
+	beginStep(ctx, workflow.StepMigrate)
+	var output []byte
+	switch tenant.MigrationPhase(req.MigrationPhase) {
+	case tenant.MigrationPhaseProvisionTarget:
+		// Resources are provider independent, so they are updated in place and only their credentials re-injected
+		desiredConfig, secretRefs, resourceOutputs, err := s.provisionResources(ctx, tenantID, req, true)
+		if err != nil {
+			return nil, err
+		}
+		desiredConfig, secretRefs, err = s.injectEndpointAuth(tenantID, req, desiredConfig, secretRefs)
+		if err != nil {
+			return nil, err
+		}
+
+		components, err := tenant.ParseComponents(desiredConfig)
+		if err != nil {
+			return nil, err
+		}
+		spec := buildComputeSpec(computeID, targetType, components[0].Config, tenant.ManagedLabels(req.Metadata))
+		spec.Secrets = secretRefs
+		var result interface{}
+		var provisioned *compute.ProvisionResult
+		err = s.leased(ctx, targetProvider, compute.OperationTypeProvision, computeID, "migrate", func(ctx context.Context) (err error) {
+			provisioned, err = targetProvider.Provision(ctx, spec)
+			return err
+		})
+		result = provisioned
+		if err != nil {
+			status, statusErr := targetProvider.GetStatus(ctx, computeID)
+			if statusErr != nil || workflow.LeaseExpired(err) {
+				s.logger.Error("target compute provisioning failed", zap.String("target", targetType), zap.Error(err))
+				return nil, fmt.Errorf("target compute provisioning failed: %w", err)
+			}
+			result = status
+		}
+
+		// The component is recorded again, so the tenant keeps its identifier on the target
+		out := &componentOutput{ComputeID: computeID, Result: result, name: components[0].Name, config: components[0].Config}
+		if provisioned != nil {
+			out.Endpoints = provisioned.Endpoints
+		}
+		output, err = marshalComponentsOutput(req.DesiredConfig, []*componentOutput{out}, nil, resourceOutputs)
+		if err != nil {
+			return nil, err
+		}
+
+	case tenant.MigrationPhaseSwitchover:
+		// Only switch once the target is running (or, for a job, has completed); failing here leaves the source serving
+		status, err := targetProvider.GetStatus(ctx, computeID)
+		if err != nil {
+			return nil, fmt.Errorf("target compute status failed: %w", err)
+		}
+		if !status.IsReady() {
+			return nil, fmt.Errorf("target compute is not ready for switchover (state %s, health %s)", status.State, status.Health)
+		}
+
+		output, err = json.Marshal(map[string]interface{}{
+			"status":           "switched",
+			"tenant_id":        tenantID,
+			"compute_provider": targetType,
+			"compute_status":   status,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("marshal output: %w", err)
+		}
+
+	case tenant.MigrationPhaseDestroySource:
+		sourceProvider, err := s.computeRegistry.Get(req.SourceComputeProvider)
+		if err != nil {
+			return nil, fmt.Errorf("compute provider lookup failed: %w", err)
+		}
+		err = s.leased(ctx, sourceProvider, compute.OperationTypeDelete, computeID, "migrate", func(ctx context.Context) error {
+			return sourceProvider.Destroy(ctx, computeID)
+		})
+		if err != nil {
+			if errors.Is(err, compute.ErrTenantNotFound) {
+				s.logger.Info("source compute resources already removed", zap.String("tenant_id", computeID), zap.String("source", req.SourceComputeProvider))
+			} else {
+				s.logger.Error("source compute deprovisioning failed", zap.String("source", req.SourceComputeProvider), zap.Error(err))
+				return nil, fmt.Errorf("source compute deprovisioning failed: %w", err)
+			}
+		}
+
+		output, err = json.Marshal(map[string]string{
+			"status":           "migrated",
+			"tenant_id":        tenantID,
+			"compute_provider": targetType,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("marshal output: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown migration phase: %q", req.MigrationPhase)
+	}
+
+	beginStep(ctx, workflow.StepFinalize)
+	return &workflow.ExecutionStatus{
+		ExecutionID:  fmt.Sprintf("migrate-%s-%s", req.MigrationPhase, tenantID),
+		ProviderType: "restate",
+		State:        workflow.StateSucceeded,
+		Output:       output,
+	}, nil
+}
+
+// runHooks runs the hooks declared for a phase and appends their results to previous
+func (s *TenantProvisioningService) runHooks(ctx context.Context, tenantID, phase string, hooks *workflow.TenantHooks, computeProvider compute.Provider, previous []workflow.HookResult) ([]workflow.HookResult, error) {
+	if hooks == nil {
+		return previous, nil
+	}
+
+	specs := hooks.PreProvision
+	if phase == workflow.HookPhasePostProvision {
+		specs = hooks.PostProvision
+	}
+	if len(specs) == 0 {
+		return previous, nil
+	}
+
+	jobRunner, _ := computeProvider.(compute.JobRunner)
+	results, err := s.hookRunner.RunPhase(ctx, tenantID, phase, specs, jobRunner)
+	if err != nil {
+		s.logger.Error("tenant hook failed", zap.String("tenant_id", tenantID), zap.String("phase", phase), zap.Error(err))
+		return append(previous, results...), err
+	}
+	return append(previous, results...), nil
+}
+
+// probeReadiness runs the tenant's readiness probe, when it declares one, against its endpoints.
+// It fails when the probe never passes, so the tenant is not reported ready.
+func (s *TenantProvisioningService) probeReadiness(ctx context.Context, tenantID string, desiredConfig map[string]interface{}, endpoints []compute.Endpoint, computeProvider compute.Provider) (*workflow.ProbeResult, error) {
+	probe, err := workflow.ParseReadinessProbe(desiredConfig)
+	if err != nil || probe == nil {
+		return nil, err
+	}
+
+	image, _ := desiredConfig["image"].(string)
+	jobRunner, _ := computeProvider.(compute.JobRunner)
+	result, err := s.hookRunner.RunReadinessProbe(ctx, tenantID, probe, endpoints, image, jobRunner)
+	if err != nil {
+		s.logger.Error("tenant readiness probe failed", zap.String("tenant_id", tenantID), zap.Error(err))
+		return result, err
+	}
+	return result, nil
+}
+
+// updateOutput is the update workflow output: the compute update result plus the tenant's endpoints
+type updateOutput struct {
+	*compute.UpdateResult
+	Endpoints []compute.Endpoint `json:"endpoints,omitempty"`
+}
+
+// scanImage scans the tenant's image for vulnerabilities before it is deployed. It fails when the
+// scanner blocks the image, so nothing is provisioned for it.
+func (s *TenantProvisioningService) scanImage(ctx context.Context, tenantID string, desiredConfig map[string]interface{}) (*vulnscan.Summary, error) {
+	image, _ := desiredConfig["image"].(string)
+	summary, err := s.vulnScan.Check(ctx, image)
+	if err != nil {
+		s.logger.Error("image vulnerability scan blocked provisioning", zap.String("tenant_id", tenantID), zap.String("image", image), zap.Error(err))
+		return nil, err
+	}
+	return summary, nil
+}
+
+// provisionResources provisions (or, on update, updates) the resources the tenant declares, in declaration order.
+// On update, resources dropped from the declaration are destroyed first.
+// It returns the desired config with resource credentials injected into env, plus secret references for the compute spec.
+func (s *TenantProvisioningService) provisionResources(ctx context.Context, tenantID string, req *ProvisioningRequest, update bool) (map[string]interface{}, []compute.SecretReference, map[string]interface{}, error) {
+	specs, err := resource.ParseSpecs(req.DesiredConfig)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if update {
+		removed := resource.Removed(req.PreviousResources, specs)
+		for i := len(removed) - 1; i >= 0; i-- {
+			if err := s.destroyResource(ctx, tenantID, removed[i]); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+
+	if len(specs) == 0 {
+		return req.DesiredConfig, nil, nil, nil
+	}
+
+	env := make(map[string]interface{})
+	if existing, ok := req.DesiredConfig["env"].(map[string]interface{}); ok {
+		for key, value := range existing {
+			env[key] = value
+		}
+	}
+
+	var refs []compute.SecretReference
+	outputs := make(map[string]interface{}, len(specs))
+	for _, spec := range specs {
+		provider, err := s.resources.Get(spec.Type)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("resource %s: %w", spec.Name, err)
+		}
+
+		var result *resource.Result
+		if update {
+			result, err = provider.Update(ctx, spec.Request(tenantID))
+		} else {
+			result, err = provider.Provision(ctx, spec.Request(tenantID))
+		}
+		if err != nil {
+			s.logger.Error("resource provisioning failed", zap.String("tenant_id", tenantID), zap.String("resource", spec.Name), zap.String("type", spec.Type), zap.Error(err))
+			return nil, nil, nil, fmt.Errorf("resource %s provisioning failed: %w", spec.Name, err)
+		}
+
+		outputs[spec.Name] = map[string]interface{}{
+			"type":    spec.Type,
+			"outputs": result.Outputs,
+		}
+		for key, value := range result.Env {
+			env[key] = value
+		}
+		for _, secret := range result.Secrets {
+			env[secret.EnvVar] = secret.Value
+			refs = append(refs, compute.SecretReference{
+				Name:   secret.Name,
+				Source: "landlord-resource-" + spec.Name,
+				Key:    secret.Name,
+				EnvVar: secret.EnvVar,
+			})
+		}
+	}
+
+	injected := make(map[string]interface{}, len(req.DesiredConfig)+1)
+	for key, value := range req.DesiredConfig {
+		injected[key] = value
+	}
+	if len(env) > 0 {
+		injected["env"] = env
+	}
+	return injected, refs, outputs, nil
+}
+
+// injectEndpointAuth adds the credentials of a tenant that protects its endpoints to its env and
+// ingress labels, and their secret references to refs
+func (s *TenantProvisioningService) injectEndpointAuth(tenantID string, req *ProvisioningRequest, desiredConfig map[string]interface{}, refs []compute.SecretReference) (map[string]interface{}, []compute.SecretReference, error) {
+	if s.endpointAuth == nil {
+		spec, err := endpointauth.Parse(desiredConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		if spec != nil {
+			// Provisioning the tenant without credentials would leave it open
+			return nil, nil, fmt.Errorf("tenant sets %s but endpoint auth is not configured on the worker", endpointauth.ConfigKey)
+		}
+		return desiredConfig, refs, nil
+	}
+
+	injected, authRefs, err := s.endpointAuth.Inject(req.TenantUUID, tenantID, desiredConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return injected, append(refs, authRefs...), nil
+}
+
+// destroyResources destroys the declared and previously provisioned resources in reverse declaration order
+func (s *TenantProvisioningService) destroyResources(ctx context.Context, tenantID string, req *ProvisioningRequest) error {
+	specs, err := resource.ParseSpecs(req.DesiredConfig)
+	if err != nil {
+		return err
+	}
+
+	refs := make([]resource.Ref, 0, len(specs))
+	for _, spec := range specs {
+		refs = append(refs, resource.Ref{Name: spec.Name, Type: spec.Type})
+	}
+	refs = append(refs, resource.Removed(req.PreviousResources, specs)...)
+
+	for i := len(refs) - 1; i >= 0; i-- {
+		if err := s.destroyResource(ctx, tenantID, refs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TenantProvisioningService) destroyResource(ctx context.Context, tenantID string, ref resource.Ref) error {
+	provider, err := s.resources.Get(ref.Type)
+	if err != nil {
+		return fmt.Errorf("resource %s: %w", ref.Name, err)
+	}
+	if err := provider.Destroy(ctx, &resource.Request{TenantID: tenantID, Name: ref.Name}); err != nil {
+		s.logger.Error("resource destroy failed", zap.String("tenant_id", tenantID), zap.String("resource", ref.Name), zap.String("type", ref.Type), zap.Error(err))
+		return fmt.Errorf("resource %s destroy failed: %w", ref.Name, err)
+	}
+	return nil
+}
+
+// resourceStatus reports the status of each declared resource
+func (s *TenantProvisioningService) resourceStatus(ctx context.Context, tenantID string, desiredConfig map[string]interface{}) (map[string]interface{}, error) {
+	specs, err := resource.ParseSpecs(desiredConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[string]interface{}, len(specs))
+	for _, spec := range specs {
+		provider, err := s.resources.Get(spec.Type)
+		if err != nil {
+			return nil, fmt.Errorf("resource %s: %w", spec.Name, err)
+		}
+		status, err := provider.Status(ctx, spec.Request(tenantID))
+		if err != nil {
+			return nil, fmt.Errorf("resource %s status failed: %w", spec.Name, err)
+		}
+		statuses[spec.Name] = map[string]interface{}{
+			"type":   spec.Type,
+			"status": status,
+		}
+	}
+	return statuses, nil
+}
+
+// marshalOutput encodes the compute result, adding hook results under "hooks", resource outputs under "resources",
+// the vulnerability scan summary under "vulnerability_scan" and the readiness probe result under "readiness_probe"
+func marshalOutput(result interface{}, hookResults []workflow.HookResult, resourceOutputs map[string]interface{}, scan *vulnscan.Summary, probe *workflow.ProbeResult) ([]byte, error) {
+	output, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("marshal output: %w", err)
+	}
+	if len(hookResults) == 0 && len(resourceOutputs) == 0 && scan == nil && probe == nil {
+		return output, nil
+	}
+
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(output, &fields); err != nil {
+		return nil, fmt.Errorf("marshal output: %w", err)
+	}
+	if len(hookResults) > 0 {
+		fields[workflow.HooksConfigKey] = hookResults
+	}
+	if len(resourceOutputs) > 0 {
+		fields[resource.ResourcesConfigKey] = resourceOutputs
+	}
+	if scan != nil {
+		fields[vulnscan.OutputKey] = scan
+	}
+	if probe != nil {
+		fields[workflow.ReadinessProbeConfigKey] = probe
+	}
+	output, err = json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("marshal output: %w", err)
+	}
+	return output, nil
+}
+
 func (s *TenantProvisioningService) resolveComputeProvider(ctx context.Context, req *ProvisioningRequest) (compute.Provider, string, error) {
 	providerType := req.ComputeProvider
 	if providerType == "" && s.computeResolver != nil {
@@ -214,10 +847,13 @@ func (s *TenantProvisioningService) resolveComputeProvider(ctx context.Context,
 	return provider, providerType, nil
 }
 
-func buildComputeSpec(tenantID, providerType string, desiredConfig map[string]interface{}) *compute.TenantComputeSpec {
+// buildComputeSpec builds the spec of one compute. labels are the tenant's managed labels, which the
+// controller sends in the request metadata.
+func buildComputeSpec(tenantID, providerType string, desiredConfig map[string]interface{}, labels map[string]string) *compute.TenantComputeSpec {
 	spec := &compute.TenantComputeSpec{
 		TenantID:     tenantID,
 		ProviderType: providerType,
+		Labels:       labels,
 	}
 
 	if providerType == "docker" {
@@ -245,17 +881,42 @@ func (s *TenantProvisioningService) RegisterService(ctx context.Context, client
 	return client.RegisterService(ctx, serviceName)
 }
 
+// ExecuteAs runs a lifecycle operation outside Restate as the given execution, so its steps are
+// reported under executionID. The in-process workflow provider of all-in-one mode calls it.
+func (s *TenantProvisioningService) ExecuteAs(ctx context.Context, executionID string, req *ProvisioningRequest) (*workflow.ExecutionStatus, error) {
+	return s.Execute(withExecutionID(ctx, executionID), req)
+}
+
 // Bind registers the tenant provisioning handlers with a Restate server.
 func (s *TenantProvisioningService) Bind(server *server.Restate, serviceName string) {
 	if serviceName == "" {
 		serviceName = workflowServiceName(config.RestateConfig{}, tenantProvisioningWorkflowID)
 	}
 
+	var opts []restate.ServiceDefinitionOption
+	if s.abortTimeout > 0 {
+		opts = append(opts, restate.WithAbortTimeout(s.abortTimeout))
+	}
+
 	server.Bind(
-		restate.NewService(serviceName).
-			Handler("execute", restate.NewServiceHandler(func(_ restate.Context, req ProvisioningRequest) (workflow.ExecutionStatus, error) {
-				status, err := s.Execute(context.Background(), &req)
+		restate.NewService(serviceName, opts...).
+			Handler("execute", restate.NewServiceHandler(func(rctx restate.Context, req ProvisioningRequest) (workflow.ExecutionStatus, error) {
+				// Only cancellation is taken from the invocation: stopping an execution kills its
+				// invocation, which closes the request, and in-flight compute operations stop with it
+				ctx, cancel := context.WithCancelCause(context.Background())
+				defer cancel(nil)
+				stop := context.AfterFunc(rctx, func() {
+					cancel(fmt.Errorf("%w: invocation ended", compute.ErrExecutionCancelled))
+				})
+				defer stop()
+				ctx = withExecutionID(ctx, rctx.Request().ID)
+
+				status, err := s.Execute(ctx, &req)
 				if err != nil {
+					// Retrying cannot change the scan result or the payload, so fail the invocation for good
+					if errors.Is(err, vulnscan.ErrThresholdExceeded) || errors.Is(err, schema.ErrIncompatible) {
+						return workflow.ExecutionStatus{}, restate.TerminalError(err)
+					}
 					return workflow.ExecutionStatus{}, err
 				}
 				if status == nil {
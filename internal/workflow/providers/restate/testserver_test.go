@@ -2,9 +2,11 @@ package restate_test
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"strings"
 	"sync"
 	"testing"
@@ -15,7 +17,10 @@ type fakeRestateServer struct {
 	server   *httptest.Server
 	mu       sync.Mutex
 	services map[string]struct{}
-	invokes  [][]byte
+	// deployments are the URIs registered through POST /deployments
+	deployments []string
+	invokes     [][]byte
+	queries     int
 }
 
 func newFakeRestateServer(t *testing.T) *fakeRestateServer {
@@ -47,6 +52,12 @@ func (f *fakeRestateServer) InvokeCount() int {
 	return len(f.invokes)
 }
 
+func (f *fakeRestateServer) QueryCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.queries
+}
+
 func (f *fakeRestateServer) LastInvokePayload() []byte {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -60,9 +71,18 @@ func (f *fakeRestateServer) LastInvokePayload() []byte {
 
 func (f *fakeRestateServer) handle(w http.ResponseWriter, r *http.Request) {
 	switch {
-	case r.Method == http.MethodGet && r.URL.Path == "/health":
+	case r.Method == http.MethodGet && (r.URL.Path == "/health" || r.URL.Path == "/restate/health"):
 		w.WriteHeader(http.StatusOK)
 		return
+	case r.Method == http.MethodGet && r.URL.Path == "/deployments":
+		f.mu.Lock()
+		deployments := make([]map[string]string, 0, len(f.deployments))
+		for i, uri := range f.deployments {
+			deployments = append(deployments, map[string]string{"id": fmt.Sprintf("dp_%d", i), "uri": uri})
+		}
+		f.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"deployments": deployments})
+		return
 	case r.Method == http.MethodGet && r.URL.Path == "/services":
 		f.mu.Lock()
 		names := make([]string, 0, len(f.services))
@@ -154,6 +174,9 @@ func (f *fakeRestateServer) handleRegisterDeployment(w http.ResponseWriter, r *h
 		return
 	}
 
+	f.mu.Lock()
+	f.deployments = append(f.deployments, payload.URI)
+	f.mu.Unlock()
 	w.WriteHeader(http.StatusOK)
 }
 
@@ -186,10 +209,19 @@ func (f *fakeRestateServer) handleQuery(w http.ResponseWriter, r *http.Request)
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
+	f.mu.Lock()
+	f.queries++
+	f.mu.Unlock()
+
+	// Every invocation the query names has completed
+	rows := []interface{}{}
+	for _, match := range invocationIDPattern.FindAllStringSubmatch(query, -1) {
+		rows = append(rows, []interface{}{match[1], "completed"})
+	}
 	_ = json.NewEncoder(w).Encode(map[string]interface{}{
 		"columns": []interface{}{"id", "status"},
-		"rows": []interface{}{
-			[]interface{}{"inv_test-execution", "completed"},
-		},
+		"rows":    rows,
 	})
 }
+
+var invocationIDPattern = regexp.MustCompile(`'(inv_[^']*)'`)
@@ -3,22 +3,42 @@ package restate
 import (
 	"context"
 	"errors"
+	"expvar"
 	"fmt"
+	"net"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/jaxxstorm/landlord/internal/compute"
 	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/endpointauth"
+	"github.com/jaxxstorm/landlord/internal/resource"
+	"github.com/jaxxstorm/landlord/internal/vulnscan"
 	"github.com/jaxxstorm/landlord/internal/workflow"
 	"github.com/restatedev/sdk-go/server"
 	"go.uber.org/zap"
 )
 
+// abortTimeoutGrace is how long after the worker's operation timeout Restate aborts an invocation
+const abortTimeoutGrace = time.Minute
+
 // WorkerEngine implements a Restate workflow worker.
 type WorkerEngine struct {
 	config          config.RestateConfig
 	logger          *zap.Logger
 	computeRegistry *compute.Registry
 	computeResolver workflow.ComputeProviderResolver
+	resources       *resource.Registry
+	vulnScan        *vulnscan.Gate
+	endpointAuth    *endpointauth.Generator
+	limiter         *compute.Limiter
+	stepReporter    workflow.StepReporter
+
+	// ready is closed once Start has bound its listener (or failed to); readyErr holds the failure
+	ready     chan struct{}
+	readyOnce sync.Once
+	readyErr  error
 }
 
 // NewWorkerEngine creates a new Restate worker engine.
@@ -34,9 +54,35 @@ func NewWorkerEngine(cfg config.RestateConfig, computeRegistry *compute.Registry
 		logger:          logger.With(zap.String("component", "restate-worker-engine")),
 		computeRegistry: computeRegistry,
 		computeResolver: computeResolver,
+		ready:           make(chan struct{}),
 	}, nil
 }
 
+// SetResourceRegistry sets the resource providers the tenant service can provision. Call before Start.
+func (w *WorkerEngine) SetResourceRegistry(registry *resource.Registry) {
+	w.resources = registry
+}
+
+// SetVulnerabilityScanner scans tenant images before they are provisioned or updated. Call before Start.
+func (w *WorkerEngine) SetVulnerabilityScanner(gate *vulnscan.Gate) {
+	w.vulnScan = gate
+}
+
+// SetEndpointAuth injects endpoint credentials into tenants that protect their endpoints. Call before Start.
+func (w *WorkerEngine) SetEndpointAuth(generator *endpointauth.Generator) {
+	w.endpointAuth = generator
+}
+
+// SetConcurrencyLimiter limits how many operations each compute provider runs at once. Call before Start.
+func (w *WorkerEngine) SetConcurrencyLimiter(limiter *compute.Limiter) {
+	w.limiter = limiter
+}
+
+// SetStepReporter reports the progress of each workflow step, usually to the landlord API. Call before Start.
+func (w *WorkerEngine) SetStepReporter(reporter workflow.StepReporter) {
+	w.stepReporter = reporter
+}
+
 // Name returns the worker engine identifier.
 func (w *WorkerEngine) Name() string {
 	return "restate"
@@ -49,18 +95,22 @@ func (w *WorkerEngine) Register(ctx context.Context) error {
 		return nil
 	}
 
-	clientCfg := w.config
-	if w.config.WorkerAdminEndpoint != "" {
-		clientCfg.AdminEndpoint = w.config.WorkerAdminEndpoint
-	}
-
-	client, err := NewClient(ctx, clientCfg, w.logger)
+	client, err := w.adminClient(ctx)
 	if err != nil {
-		return fmt.Errorf("init restate client: %w", err)
+		return err
 	}
 
 	_ = WorkerServiceName(w.config)
 
+	if w.config.WorkerAdvertisedURL == "" {
+		return fmt.Errorf("worker_advertised_url is required for registration")
+	}
+
+	// Restate calls back into the worker during registration, so the listener must be up first
+	if err := w.WaitReady(ctx); err != nil {
+		return fmt.Errorf("worker not ready for registration: %w", err)
+	}
+
 	attempts := w.config.RetryAttempts
 	if attempts < 1 {
 		attempts = 1
@@ -69,8 +119,13 @@ func (w *WorkerEngine) Register(ctx context.Context) error {
 	var lastErr error
 	backoff := 500 * time.Millisecond
 	for i := 0; i < attempts; i++ {
-		if w.config.WorkerAdvertisedURL == "" {
-			return fmt.Errorf("worker_advertised_url is required for registration")
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("worker registration canceled: %w", ctx.Err())
+			case <-time.After(backoff):
+			}
+			backoff = backoff * 2
 		}
 
 		if err := client.RegisterDeployment(ctx, w.config.WorkerAdvertisedURL); err != nil {
@@ -79,11 +134,15 @@ func (w *WorkerEngine) Register(ctx context.Context) error {
 				return nil
 			}
 			lastErr = err
-			w.logger.Warn("worker registration failed", zap.Error(err))
-			time.Sleep(backoff)
-			backoff = backoff * 2
+			registrationMetrics.recordRegistration(err, time.Now())
+			w.logger.Warn("worker registration failed",
+				zap.Int("attempt", i+1),
+				zap.Int("max_attempts", attempts),
+				zap.Error(err),
+			)
 			continue
 		}
+		registrationMetrics.recordRegistration(nil, time.Now())
 		w.logger.Info("worker registered",
 			zap.String("service_name", WorkerServiceName(w.config)),
 			zap.String("uri", w.config.WorkerAdvertisedURL),
@@ -94,20 +153,176 @@ func (w *WorkerEngine) Register(ctx context.Context) error {
 	return fmt.Errorf("worker registration failed after %d attempt(s): %w", attempts, lastErr)
 }
 
+func (w *WorkerEngine) adminClient(ctx context.Context) (*Client, error) {
+	clientCfg := w.config
+	if w.config.WorkerAdminEndpoint != "" {
+		clientCfg.AdminEndpoint = w.config.WorkerAdminEndpoint
+	}
+
+	client, err := NewClient(ctx, clientCfg, w.logger)
+	if err != nil {
+		return nil, fmt.Errorf("init restate client: %w", err)
+	}
+	return client, nil
+}
+
+// reconcileRegistration periodically verifies the worker deployment is still known to
+// Restate and re-registers it when missing, e.g. after Restate lost its metadata on restart.
+func (w *WorkerEngine) reconcileRegistration(ctx context.Context, interval time.Duration) {
+	client, err := w.adminClient(ctx)
+	if err != nil {
+		w.logger.Error("registration reconciliation disabled", zap.Error(err))
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !w.checkRegistration(ctx, client) {
+			return
+		}
+	}
+}
+
+// checkRegistration runs a single verification pass and reports whether reconciliation should continue.
+func (w *WorkerEngine) checkRegistration(ctx context.Context, client *Client) bool {
+	uri := w.config.WorkerAdvertisedURL
+
+	exists, err := client.DeploymentExists(ctx, uri)
+	registrationMetrics.recordCheck(exists, err, time.Now())
+	if err != nil {
+		if errors.Is(err, errAdminAPINotSupported) {
+			w.logger.Warn("deployment listing not supported by restate admin api, stopping registration reconciliation", zap.Error(err))
+			return false
+		}
+		w.logger.Warn("failed to verify worker deployment", zap.String("uri", uri), zap.Error(err))
+		return true
+	}
+	if exists {
+		return true
+	}
+
+	w.logger.Warn("worker deployment missing from restate, re-registering", zap.String("uri", uri))
+	err = client.RegisterDeployment(ctx, uri)
+	registrationMetrics.recordRegistration(err, time.Now())
+	if err != nil {
+		w.logger.Error("worker re-registration failed", zap.String("uri", uri), zap.Error(err))
+		return true
+	}
+	w.logger.Info("worker re-registered",
+		zap.String("service_name", WorkerServiceName(w.config)),
+		zap.String("uri", uri),
+	)
+	return true
+}
+
+// Ready returns a channel that is closed once Start has bound its listener
+// and initialized the Restate SDK handler, or has failed trying.
+func (w *WorkerEngine) Ready() <-chan struct{} {
+	return w.ready
+}
+
+// WaitReady blocks until the worker is accepting connections, Start fails, or ctx is done.
+func (w *WorkerEngine) WaitReady(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-w.ready:
+		return w.readyErr
+	}
+}
+
+func (w *WorkerEngine) markReady(err error) {
+	w.readyOnce.Do(func() {
+		w.readyErr = err
+		close(w.ready)
+	})
+}
+
 // Start starts the Restate worker server.
+// Ready is signalled once the listener is bound, so Register can safely run concurrently.
 func (w *WorkerEngine) Start(ctx context.Context, addr string) error {
 	if addr == "" {
-		return fmt.Errorf("worker address is required")
+		err := fmt.Errorf("worker address is required")
+		w.markReady(err)
+		return err
 	}
 
 	restateServer := server.NewRestate()
 	service := NewTenantProvisioningService(w.computeRegistry, w.config.WorkerComputeProvider, w.computeResolver, w.logger)
+	service.SetResourceRegistry(w.resources)
+	service.SetVulnerabilityScanner(w.vulnScan)
+	service.SetEndpointAuth(w.endpointAuth)
+	service.SetConcurrencyLimiter(w.limiter)
+	service.SetStepReporter(w.stepReporter)
+	service.SetOperationLease(workflow.LeaseConfig{
+		HeartbeatTimeout: w.config.WorkerHeartbeatTimeout,
+		MaxDuration:      w.config.WorkerOperationTimeout,
+	})
+	if w.config.WorkerAbortTimeout {
+		// Restate aborts a little after the worker's own timeout, so the worker reports why
+		service.SetAbortTimeout(w.config.WorkerOperationTimeout + abortTimeoutGrace)
+	}
 	service.Bind(restateServer, WorkerServiceName(w.config))
 
+	handler, err := restateServer.Handler()
+	if err != nil {
+		err = fmt.Errorf("init restate handler: %w", err)
+		w.markReady(err)
+		return err
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		err = fmt.Errorf("listen on %s: %w", addr, err)
+		w.markReady(err)
+		return err
+	}
+
+	// Mirrors server.Restate.Start, which does not expose the bound listener.
+	// HTTP/1 is also accepted so /debug/vars can be scraped by ordinary clients.
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	protocols.SetHTTP1(true)
+	// Expose registration metrics alongside the Restate endpoints
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.Handle("/", handler)
+
+	httpServer := &http.Server{
+		Handler:           mux,
+		Protocols:         &protocols,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			w.logger.Error("restate worker shutdown failed", zap.Error(err))
+		}
+	}()
+
 	w.logger.Info("starting restate worker",
-		zap.String("address", addr),
+		zap.String("address", listener.Addr().String()),
 		zap.String("service_name", WorkerServiceName(w.config)),
 	)
+	w.markReady(nil)
+
+	if w.config.WorkerRegisterOnStartup && w.config.WorkerAdvertisedURL != "" && w.config.WorkerRegistrationInterval > 0 {
+		go w.reconcileRegistration(ctx, w.config.WorkerRegistrationInterval)
+	}
 
-	return restateServer.Start(ctx, addr)
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("restate worker server: %w", err)
+	}
+	return nil
 }
@@ -2,9 +2,11 @@ package restate_test
 
 import (
 	"context"
+	"encoding/json"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -27,6 +29,7 @@ func TestWorkerEngineRegistrationRetries(t *testing.T) {
 	if err != nil {
 		t.Skipf("skipping test; cannot open local listener: %v", err)
 	}
+	workerAddr := listener.Addr().String()
 	listener.Close()
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -64,6 +67,174 @@ func TestWorkerEngineRegistrationRetries(t *testing.T) {
 	worker, err := restate.NewWorkerEngine(cfg, registry, resolver, logger)
 	require.NoError(t, err)
 
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- worker.Start(workerCtx, workerAddr)
+	}()
+
 	require.NoError(t, worker.Register(ctx))
 	require.Equal(t, int32(2), atomic.LoadInt32(&deployAttempts))
+
+	cancel()
+	require.NoError(t, <-startErr)
+}
+
+func TestWorkerEngineRegisterWaitsForReadiness(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	var deployAttempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/deployments" {
+			atomic.AddInt32(&deployAttempts, 1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := config.RestateConfig{
+		Endpoint:                server.URL,
+		AdminEndpoint:           server.URL,
+		AuthType:                "none",
+		WorkerRegisterOnStartup: true,
+		WorkerAdvertisedURL:     "http://127.0.0.1:9999",
+		RetryAttempts:           1,
+		Timeout:                 2 * time.Second,
+	}
+
+	registry := compute.NewRegistry(logger)
+	require.NoError(t, registry.Register(computemock.New()))
+	worker, err := restate.NewWorkerEngine(cfg, registry, nil, logger)
+	require.NoError(t, err)
+
+	// Register must not contact the admin API before Start has bound its listener
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, worker.Register(ctx), context.DeadlineExceeded)
+	require.Equal(t, int32(0), atomic.LoadInt32(&deployAttempts))
+
+	select {
+	case <-worker.Ready():
+		t.Fatal("worker reported ready before Start")
+	default:
+	}
+}
+
+func TestWorkerEngineStartFailureUnblocksRegister(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("skipping test; cannot open local listener: %v", err)
+	}
+	t.Cleanup(func() { occupied.Close() })
+
+	cfg := config.RestateConfig{
+		Endpoint:                "http://127.0.0.1:1",
+		AuthType:                "none",
+		WorkerRegisterOnStartup: true,
+		WorkerAdvertisedURL:     "http://127.0.0.1:9999",
+		Timeout:                 time.Second,
+	}
+
+	registry := compute.NewRegistry(logger)
+	require.NoError(t, registry.Register(computemock.New()))
+	worker, err := restate.NewWorkerEngine(cfg, registry, nil, logger)
+	require.NoError(t, err)
+
+	require.Error(t, worker.Start(context.Background(), occupied.Addr().String()))
+
+	err = worker.WaitReady(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "listen on")
+}
+
+func TestWorkerEngineReRegistersMissingDeployment(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("skipping test; cannot open local listener: %v", err)
+	}
+	workerAddr := listener.Addr().String()
+	listener.Close()
+	advertisedURL := "http://" + workerAddr + "/"
+
+	var mu sync.Mutex
+	deployments := map[string]struct{}{}
+	var registrations int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/health":
+			w.WriteHeader(http.StatusOK)
+		case r.URL.Path == "/deployments" && r.Method == http.MethodPost:
+			var payload struct {
+				URI string `json:"uri"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			mu.Lock()
+			deployments[payload.URI] = struct{}{}
+			mu.Unlock()
+			atomic.AddInt32(&registrations, 1)
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/deployments" && r.Method == http.MethodGet:
+			mu.Lock()
+			list := make([]map[string]string, 0, len(deployments))
+			for uri := range deployments {
+				list = append(list, map[string]string{"id": "dp_1", "uri": uri})
+			}
+			mu.Unlock()
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"deployments": list})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := config.RestateConfig{
+		Endpoint:                   server.URL,
+		AdminEndpoint:              server.URL,
+		AuthType:                   "none",
+		WorkerRegisterOnStartup:    true,
+		WorkerAdvertisedURL:        advertisedURL,
+		WorkerRegistrationInterval: 20 * time.Millisecond,
+		RetryAttempts:              1,
+		Timeout:                    2 * time.Second,
+	}
+
+	registry := compute.NewRegistry(logger)
+	require.NoError(t, registry.Register(computemock.New()))
+	worker, err := restate.NewWorkerEngine(cfg, registry, nil, logger)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = worker.Start(ctx, workerAddr)
+	}()
+
+	metrics := restate.RegistrationMetrics()
+	missingBefore := metrics.MissingDeployments()
+
+	require.NoError(t, worker.Register(ctx))
+	require.True(t, metrics.Registered())
+
+	// Simulate Restate losing its deployment metadata
+	mu.Lock()
+	delete(deployments, advertisedURL)
+	mu.Unlock()
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&registrations) >= 2
+	}, 2*time.Second, 10*time.Millisecond)
+	require.Greater(t, metrics.MissingDeployments(), missingBefore)
+	require.Eventually(t, metrics.Registered, time.Second, 10*time.Millisecond)
+
+	resp, err := http.Get("http://" + workerAddr + "/debug/vars")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var vars map[string]json.RawMessage
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&vars))
+	require.Contains(t, vars, "restate_worker_registration")
 }
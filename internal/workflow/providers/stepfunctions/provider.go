@@ -10,6 +10,7 @@ import (
 
 	"github.com/jaxxstorm/landlord/internal/compute"
 	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
 )
 
 type Provider struct {
@@ -51,13 +52,18 @@ func (p *Provider) Invoke(ctx context.Context, workflowID string, request *workf
 	}
 
 	executionName := fmt.Sprintf("tenant-%s-%s", request.TenantID, workflowID)
+	if request.MigrationPhase != "" {
+		// Each migration phase is its own execution of the migrate workflow
+		executionName = fmt.Sprintf("%s-%s", executionName, request.MigrationPhase)
+	}
 	input := &workflow.ExecutionInput{
 		ExecutionName: executionName,
 		Input:         payload,
 		Tags: map[string]string{
 			"tenant_id": request.TenantID,
 		},
-		TriggerSource: "reconciler",
+		TriggerSource:   "reconciler",
+		WorkflowVersion: workflow.EffectiveWorkflowVersion(request.WorkflowVersion),
 	}
 
 	return p.StartExecution(ctx, workflowID, input)
@@ -145,6 +151,9 @@ func (p *Provider) Validate(ctx context.Context, spec *workflow.WorkflowSpec) er
 func (p *Provider) PostComputeCallback(ctx context.Context, executionID string, payload *compute.CallbackPayload, opts *compute.CallbackOptions) error {
 	_ = executionID
 	_ = opts
+	if err := schema.Validate(schema.ComputeCallback, payload); err != nil {
+		return err
+	}
 	// Marshal the callback payload to JSON for logging/debug
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
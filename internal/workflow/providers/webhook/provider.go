@@ -0,0 +1,464 @@
+// Package webhook provides a workflow provider that hands executions to an external orchestrator,
+// such as Argo Workflows or Airflow. Starting an execution POSTs the provision request to the
+// orchestrator's URL, signed with HMAC-SHA256, and the orchestrator reports the execution's progress
+// back to /v1/workflow-callbacks, which authenticates it with callbackauth.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/callbackauth"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	"github.com/jaxxstorm/landlord/internal/workflow/schema"
+)
+
+// ProviderName identifies the webhook provider in configuration and execution statuses
+const ProviderName = "webhook"
+
+// ErrExecutionFinished is returned when a callback reports on an execution that already finished
+var ErrExecutionFinished = errors.New("execution already finished")
+
+// Event types sent to the orchestrator
+const (
+	EventExecutionStart  = "execution.start"
+	EventExecutionStop   = "execution.stop"
+	EventComputeCallback = "compute.callback"
+)
+
+// Event is the body of each request sent to the orchestrator
+type Event struct {
+	Type        string `json:"type"`
+	ExecutionID string `json:"execution_id"`
+
+	// WorkflowID, ExecutionName, Input, Tags, Metadata and WorkflowVersion are set on execution.start.
+	// ExecutionName is also sent as the Idempotency-Key header.
+	WorkflowID      string            `json:"workflow_id,omitempty"`
+	ExecutionName   string            `json:"execution_name,omitempty"`
+	Input           json.RawMessage   `json:"input,omitempty"`
+	Tags            map[string]string `json:"tags,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	WorkflowVersion string            `json:"workflow_version,omitempty"`
+
+	// CallbackURL is where the orchestrator reports the execution's progress
+	CallbackURL string `json:"callback_url,omitempty"`
+
+	// Reason is set on execution.stop
+	Reason string `json:"reason,omitempty"`
+
+	// ComputeCallback is set on compute.callback
+	ComputeCallback *compute.CallbackPayload `json:"compute_callback,omitempty"`
+}
+
+// Callback is a status update the orchestrator posts for an execution
+type Callback struct {
+	ExecutionID string
+	State       workflow.ExecutionState
+	Output      json.RawMessage
+	Error       *workflow.ExecutionError
+
+	// Message is a short description of what the execution is doing
+	Message string
+}
+
+// Provider is a workflow provider backed by an external orchestrator. Executions are tracked in
+// memory, so the provider that started an execution must also receive its callbacks.
+type Provider struct {
+	url         string
+	secret      []byte
+	callbackURL string
+	client      *http.Client
+	logger      *zap.Logger
+
+	// startMu serializes starts, so a retried trigger cannot start a second execution
+	startMu sync.Mutex
+
+	mu         sync.RWMutex
+	executions map[string]*workflow.ExecutionStatus
+	// running maps the execution name of each unfinished execution to its ID
+	running map[string]string
+}
+
+var (
+	_ workflow.Provider            = (*Provider)(nil)
+	_ workflow.BatchStatusProvider = (*Provider)(nil)
+)
+
+// New creates a webhook provider
+func New(cfg config.WebhookConfig, logger *zap.Logger) (*Provider, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &Provider{
+		url:         cfg.URL,
+		secret:      []byte(cfg.Secret),
+		callbackURL: cfg.CallbackURL,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		logger:      logger.With(zap.String("provider", ProviderName)),
+		executions:  make(map[string]*workflow.ExecutionStatus),
+		running:     make(map[string]string),
+	}, nil
+}
+
+// Name returns the provider identifier
+func (p *Provider) Name() string {
+	return ProviderName
+}
+
+// Invoke starts a workflow execution using a simplified request payload
+func (p *Provider) Invoke(ctx context.Context, workflowID string, request *workflow.ProvisionRequest) (*workflow.ExecutionResult, error) {
+	if request == nil {
+		return nil, fmt.Errorf("provision request is required")
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	tenantIdentifier := request.TenantUUID
+	if tenantIdentifier == "" {
+		tenantIdentifier = request.TenantID
+	}
+	operation := request.Operation
+	if operation == "" {
+		operation = "provision"
+	}
+
+	executionName := fmt.Sprintf("tenant-%s-%s-%s", tenantIdentifier, workflowID, operation)
+	if request.MigrationPhase != "" {
+		// Each migration phase is its own execution of the migrate workflow
+		executionName = fmt.Sprintf("%s-%s", executionName, request.MigrationPhase)
+	}
+	input := &workflow.ExecutionInput{
+		ExecutionName: executionName,
+		Input:         payload,
+		Tags: map[string]string{
+			"tenant_id":   request.TenantID,
+			"tenant_uuid": request.TenantUUID,
+			"operation":   operation,
+		},
+		Metadata:        request.Metadata,
+		TriggerSource:   "reconciler",
+		WorkflowVersion: workflow.EffectiveWorkflowVersion(request.WorkflowVersion),
+	}
+
+	return p.StartExecution(ctx, workflowID, input)
+}
+
+// GetWorkflowStatus returns a simplified workflow status for an execution
+func (p *Provider) GetWorkflowStatus(ctx context.Context, executionID string) (*workflow.WorkflowStatus, error) {
+	status, err := p.GetExecutionStatus(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &workflow.WorkflowStatus{
+		ExecutionID: status.ExecutionID,
+		State:       status.State,
+		Output:      status.Output,
+		Error:       status.Error,
+	}, nil
+}
+
+// CreateWorkflow is a no-op; workflow definitions live in the orchestrator
+func (p *Provider) CreateWorkflow(ctx context.Context, spec *workflow.WorkflowSpec) (*workflow.CreateWorkflowResult, error) {
+	return &workflow.CreateWorkflowResult{
+		WorkflowID:   spec.WorkflowID,
+		ProviderType: ProviderName,
+		ResourceIDs:  map[string]string{"url": p.url},
+		CreatedAt:    time.Now(),
+		Message:      "workflow is defined in the external orchestrator",
+	}, nil
+}
+
+// StartExecution asks the orchestrator to run an execution. While an execution with the same
+// name is unfinished, it is returned instead of starting another.
+func (p *Provider) StartExecution(ctx context.Context, workflowID string, input *workflow.ExecutionInput) (*workflow.ExecutionResult, error) {
+	if input == nil {
+		return nil, fmt.Errorf("execution input is required")
+	}
+
+	p.startMu.Lock()
+	defer p.startMu.Unlock()
+
+	if input.ExecutionName != "" {
+		p.mu.RLock()
+		var existing *workflow.ExecutionStatus
+		if status, ok := p.executions[p.running[input.ExecutionName]]; ok {
+			existing = copyStatus(status)
+		}
+		p.mu.RUnlock()
+		if existing != nil {
+			return &workflow.ExecutionResult{
+				ExecutionID:  existing.ExecutionID,
+				WorkflowID:   existing.WorkflowID,
+				ProviderType: ProviderName,
+				State:        existing.State,
+				StartedAt:    existing.StartTime,
+				Message:      "execution already started (idempotent result)",
+			}, nil
+		}
+	}
+
+	executionID := "wh-" + uuid.NewString()
+	event := &Event{
+		Type:            EventExecutionStart,
+		ExecutionID:     executionID,
+		WorkflowID:      workflowID,
+		ExecutionName:   input.ExecutionName,
+		Input:           input.Input,
+		Tags:            input.Tags,
+		Metadata:        input.Metadata,
+		WorkflowVersion: input.WorkflowVersion,
+		CallbackURL:     p.callbackURL,
+	}
+	if err := p.send(ctx, event, input.ExecutionName); err != nil {
+		p.logger.Error("failed to start execution",
+			zap.String("workflow_id", workflowID),
+			zap.String("execution_name", input.ExecutionName),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to start execution: %w", err)
+	}
+
+	now := time.Now()
+	status := &workflow.ExecutionStatus{
+		ExecutionID:  executionID,
+		WorkflowID:   workflowID,
+		ProviderType: ProviderName,
+		State:        workflow.StatePending,
+		StartTime:    now,
+		Input:        input.Input,
+		History:      []workflow.ExecutionEvent{{Timestamp: now, Type: "ExecutionStarted"}},
+		Metadata:     map[string]string{"execution_name": input.ExecutionName},
+	}
+	p.mu.Lock()
+	p.executions[executionID] = status
+	if input.ExecutionName != "" {
+		p.running[input.ExecutionName] = executionID
+	}
+	p.mu.Unlock()
+
+	p.logger.Info("execution started",
+		zap.String("workflow_id", workflowID),
+		zap.String("execution_id", executionID),
+		zap.String("execution_name", input.ExecutionName))
+
+	return &workflow.ExecutionResult{
+		ExecutionID:  executionID,
+		WorkflowID:   workflowID,
+		ProviderType: ProviderName,
+		State:        workflow.StatePending,
+		StartedAt:    now,
+		Message:      "execution sent to the orchestrator",
+	}, nil
+}
+
+// GetExecutionStatus returns the execution's status as last reported by the orchestrator
+func (p *Provider) GetExecutionStatus(ctx context.Context, executionID string) (*workflow.ExecutionStatus, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status, ok := p.executions[executionID]
+	if !ok {
+		return nil, workflow.ErrExecutionNotFound
+	}
+	return copyStatus(status), nil
+}
+
+// GetExecutionStatuses returns the status of each known execution
+func (p *Provider) GetExecutionStatuses(ctx context.Context, executionIDs []string) (map[string]*workflow.ExecutionStatus, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	statuses := make(map[string]*workflow.ExecutionStatus, len(executionIDs))
+	for _, id := range executionIDs {
+		if status, ok := p.executions[id]; ok {
+			statuses[id] = copyStatus(status)
+		}
+	}
+	return statuses, nil
+}
+
+// StopExecution asks the orchestrator to stop an execution and marks it cancelled. The execution
+// is cancelled even if the orchestrator cannot be told, so callbacks for it are then rejected.
+func (p *Provider) StopExecution(ctx context.Context, executionID string, reason string) error {
+	p.mu.RLock()
+	_, ok := p.executions[executionID]
+	p.mu.RUnlock()
+	if !ok {
+		return workflow.ErrExecutionNotFound
+	}
+
+	if err := p.send(ctx, &Event{Type: EventExecutionStop, ExecutionID: executionID, Reason: reason}, ""); err != nil {
+		p.logger.Warn("failed to tell the orchestrator to stop an execution",
+			zap.String("execution_id", executionID),
+			zap.Error(err))
+	}
+
+	details, _ := json.Marshal(map[string]string{"reason": reason})
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.finish(p.executions[executionID], workflow.StateCancelled, "ExecutionCancelled", details)
+	return nil
+}
+
+// DeleteWorkflow is a no-op; workflow definitions live in the orchestrator
+func (p *Provider) DeleteWorkflow(ctx context.Context, workflowID string) error {
+	return nil
+}
+
+// Validate performs basic validation on the workflow spec
+func (p *Provider) Validate(ctx context.Context, spec *workflow.WorkflowSpec) error {
+	if spec == nil || spec.WorkflowID == "" {
+		return workflow.ErrInvalidSpec
+	}
+	if len(spec.Definition) > 0 && !json.Valid(spec.Definition) {
+		return fmt.Errorf("definition must be valid JSON")
+	}
+	return nil
+}
+
+// PostComputeCallback forwards a compute execution callback to the orchestrator; the caller retries failed deliveries
+func (p *Provider) PostComputeCallback(ctx context.Context, executionID string, payload *compute.CallbackPayload, opts *compute.CallbackOptions) error {
+	if err := schema.Validate(schema.ComputeCallback, payload); err != nil {
+		return err
+	}
+	return p.send(ctx, &Event{Type: EventComputeCallback, ExecutionID: executionID, ComputeCallback: payload}, "")
+}
+
+// HandleCallback applies a status update from the orchestrator. Once an execution has finished,
+// further callbacks for it are rejected with ErrExecutionFinished.
+func (p *Provider) HandleCallback(ctx context.Context, callback *Callback) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status, ok := p.executions[callback.ExecutionID]
+	if !ok {
+		return workflow.ErrExecutionNotFound
+	}
+	if terminal(status.State) {
+		if status.State == callback.State {
+			// The orchestrator retried a callback that was already applied
+			return nil
+		}
+		return fmt.Errorf("%w: %s is %s", ErrExecutionFinished, callback.ExecutionID, status.State)
+	}
+
+	details, _ := json.Marshal(map[string]string{"message": callback.Message})
+	switch callback.State {
+	case workflow.StateSucceeded:
+		status.Output = callback.Output
+		status.Error = nil
+		p.finish(status, callback.State, "ExecutionSucceeded", details)
+	case workflow.StateFailed, workflow.StateTimedOut, workflow.StateCancelled:
+		status.Output = callback.Output
+		status.Error = callback.Error
+		if status.Error == nil {
+			status.Error = &workflow.ExecutionError{Code: strings.ToUpper(string(callback.State)), Message: callback.Message}
+		}
+		p.finish(status, callback.State, "Execution"+eventName(callback.State), details)
+	case workflow.StatePending, workflow.StateRunning:
+		// A running execution may report an error it is retrying
+		status.State = callback.State
+		status.Error = callback.Error
+		status.History = append(status.History, workflow.ExecutionEvent{Timestamp: time.Now(), Type: "Execution" + eventName(callback.State), Details: details})
+	default:
+		return fmt.Errorf("unknown execution state %q", callback.State)
+	}
+
+	p.logger.Info("execution callback applied",
+		zap.String("execution_id", callback.ExecutionID),
+		zap.String("state", string(callback.State)))
+	return nil
+}
+
+// finish records that status reached a terminal state; callers hold p.mu
+func (p *Provider) finish(status *workflow.ExecutionStatus, state workflow.ExecutionState, eventType string, details json.RawMessage) {
+	now := time.Now()
+	status.State = state
+	status.StopTime = &now
+	status.History = append(status.History, workflow.ExecutionEvent{Timestamp: now, Type: eventType, Details: details})
+	if name := status.Metadata["execution_name"]; p.running[name] == status.ExecutionID {
+		delete(p.running, name)
+	}
+}
+
+// send POSTs a signed event to the orchestrator, which must answer with a 2xx status
+func (p *Provider) send(ctx context.Context, event *Event, idempotencyKey string) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(callbackauth.SignatureHeader, callbackauth.Sign(p.secret, time.Now(), body))
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", event.Type, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: orchestrator returned %d: %s", event.Type, resp.StatusCode, bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+func terminal(state workflow.ExecutionState) bool {
+	switch state {
+	case workflow.StateSucceeded, workflow.StateFailed, workflow.StateTimedOut, workflow.StateCancelled:
+		return true
+	}
+	return false
+}
+
+// eventName turns a state such as timed_out into TimedOut for history event types
+func eventName(state workflow.ExecutionState) string {
+	switch state {
+	case workflow.StatePending:
+		return "Pending"
+	case workflow.StateRunning:
+		return "Running"
+	case workflow.StateSucceeded:
+		return "Succeeded"
+	case workflow.StateFailed:
+		return "Failed"
+	case workflow.StateTimedOut:
+		return "TimedOut"
+	case workflow.StateCancelled:
+		return "Cancelled"
+	}
+	return "Updated"
+}
+
+// copyStatus returns a copy of status that callers can keep while callbacks update the original
+func copyStatus(status *workflow.ExecutionStatus) *workflow.ExecutionStatus {
+	copied := *status
+	copied.History = append([]workflow.ExecutionEvent(nil), status.History...)
+	copied.Metadata = make(map[string]string, len(status.Metadata))
+	for k, v := range status.Metadata {
+		copied.Metadata[k] = v
+	}
+	return &copied
+}
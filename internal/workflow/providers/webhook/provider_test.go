@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"github.com/jaxxstorm/landlord/internal/callbackauth"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+)
+
+const testSecret = "0123456789abcdef"
+
+// orchestrator records the events the provider sends, checking their signatures
+type orchestrator struct {
+	auth   *callbackauth.Verifier
+	mu     sync.Mutex
+	events []Event
+	keys   []string
+	status int
+}
+
+func (o *orchestrator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if err := o.auth.Verify("", r.Header.Get(callbackauth.SignatureHeader), body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, event)
+	o.keys = append(o.keys, r.Header.Get("Idempotency-Key"))
+	if o.status != 0 {
+		w.WriteHeader(o.status)
+	}
+}
+
+func TestProvider(t *testing.T) {
+	ctx := context.Background()
+	orch := &orchestrator{auth: callbackauth.New(time.Minute, []config.CallbackSourceConfig{{Name: callbackauth.DefaultSource, Secrets: []string{testSecret}}})}
+	server := httptest.NewServer(orch)
+	defer server.Close()
+
+	p, err := New(config.WebhookConfig{
+		URL:         server.URL,
+		Secret:      testSecret,
+		CallbackURL: "https://landlord.example.com/v1/workflow-callbacks",
+		Timeout:     5 * time.Second,
+	}, zaptest.NewLogger(t))
+	require.NoError(t, err)
+
+	request := &workflow.ProvisionRequest{TenantID: "acme", TenantUUID: "7c0f7d4e-6a43-4c5a-9f0e-1d2b3c4d5e6f", Operation: "provision"}
+	started, err := p.Invoke(ctx, "tenant-provisioning", request)
+	require.NoError(t, err)
+	require.Equal(t, workflow.StatePending, started.State)
+
+	require.Len(t, orch.events, 1)
+	event := orch.events[0]
+	require.Equal(t, EventExecutionStart, event.Type)
+	require.Equal(t, started.ExecutionID, event.ExecutionID)
+	require.Equal(t, "https://landlord.example.com/v1/workflow-callbacks", event.CallbackURL)
+	require.Equal(t, event.ExecutionName, orch.keys[0])
+	var input workflow.ProvisionRequest
+	require.NoError(t, json.Unmarshal(event.Input, &input))
+	require.Equal(t, "acme", input.TenantID)
+
+	// A retried trigger returns the unfinished execution instead of starting another
+	again, err := p.Invoke(ctx, "tenant-provisioning", request)
+	require.NoError(t, err)
+	require.Equal(t, started.ExecutionID, again.ExecutionID)
+	require.Len(t, orch.events, 1)
+
+	require.NoError(t, p.HandleCallback(ctx, &Callback{ExecutionID: started.ExecutionID, State: workflow.StateRunning, Message: "argo workflow submitted"}))
+	status, err := p.GetExecutionStatus(ctx, started.ExecutionID)
+	require.NoError(t, err)
+	require.Equal(t, workflow.StateRunning, status.State)
+	require.Nil(t, status.StopTime)
+
+	output := json.RawMessage(`{"endpoint":"https://acme.example.com"}`)
+	require.NoError(t, p.HandleCallback(ctx, &Callback{ExecutionID: started.ExecutionID, State: workflow.StateSucceeded, Output: output}))
+	status, err = p.GetExecutionStatus(ctx, started.ExecutionID)
+	require.NoError(t, err)
+	require.Equal(t, workflow.StateSucceeded, status.State)
+	require.JSONEq(t, string(output), string(status.Output))
+	require.NotNil(t, status.StopTime)
+
+	// A repeated callback is accepted, but a finished execution cannot change state
+	require.NoError(t, p.HandleCallback(ctx, &Callback{ExecutionID: started.ExecutionID, State: workflow.StateSucceeded}))
+	err = p.HandleCallback(ctx, &Callback{ExecutionID: started.ExecutionID, State: workflow.StateFailed})
+	require.ErrorIs(t, err, ErrExecutionFinished)
+	require.ErrorIs(t, p.HandleCallback(ctx, &Callback{ExecutionID: "wh-unknown", State: workflow.StateRunning}), workflow.ErrExecutionNotFound)
+
+	// Once finished, the same tenant operation starts a new execution
+	next, err := p.Invoke(ctx, "tenant-provisioning", request)
+	require.NoError(t, err)
+	require.NotEqual(t, started.ExecutionID, next.ExecutionID)
+
+	require.NoError(t, p.HandleCallback(ctx, &Callback{ExecutionID: next.ExecutionID, State: workflow.StateFailed, Error: &workflow.ExecutionError{Code: "QUOTA", Message: "quota exceeded"}}))
+	statuses, err := p.GetExecutionStatuses(ctx, []string{started.ExecutionID, next.ExecutionID, "wh-unknown"})
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	require.Equal(t, "quota exceeded", statuses[next.ExecutionID].Error.Message)
+
+	// Stopping tells the orchestrator and cancels the execution
+	archive, err := p.Invoke(ctx, "tenant-provisioning", &workflow.ProvisionRequest{TenantID: "acme", Operation: "delete"})
+	require.NoError(t, err)
+	require.NoError(t, p.StopExecution(ctx, archive.ExecutionID, "superseded"))
+	require.Equal(t, EventExecutionStop, orch.events[len(orch.events)-1].Type)
+	status, err = p.GetExecutionStatus(ctx, archive.ExecutionID)
+	require.NoError(t, err)
+	require.Equal(t, workflow.StateCancelled, status.State)
+
+	// An orchestrator that refuses the execution fails the trigger
+	orch.mu.Lock()
+	orch.status = http.StatusServiceUnavailable
+	orch.mu.Unlock()
+	_, err = p.Invoke(ctx, "tenant-provisioning", &workflow.ProvisionRequest{TenantID: "other", Operation: "provision"})
+	require.ErrorContains(t, err, "orchestrator returned 503")
+}
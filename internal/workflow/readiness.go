@@ -0,0 +1,427 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"go.uber.org/zap"
+)
+
+// ReadinessProbeConfigKey is the desired_config key holding the tenant's readiness probe
+const ReadinessProbeConfigKey = "readiness_probe"
+
+// Readiness probe types
+const (
+	ProbeTypeHTTP    = "http"
+	ProbeTypeTCP     = "tcp"
+	ProbeTypeCommand = "command"
+)
+
+const (
+	defaultProbePeriod           = 5 * time.Second
+	defaultProbeTimeout          = 5 * time.Second
+	defaultProbeFailureThreshold = 12
+)
+
+// ConditionReadinessProbePassed is the tenant condition recording the outcome of its readiness probe
+const ConditionReadinessProbePassed = "ReadinessProbePassed"
+
+// Reasons set on the ReadinessProbePassed condition
+const (
+	ReasonProbeSucceeded = tenant.ReasonProbeSucceeded
+	ReasonProbeFailed    = tenant.ReasonProbeFailed
+)
+
+// ErrReadinessProbeFailed is wrapped by the error returned when a tenant never passed its readiness probe
+var ErrReadinessProbeFailed = errors.New("readiness probe failed")
+
+// ReadinessProbe declares how a tenant is checked after provisioning before it is reported ready
+type ReadinessProbe struct {
+	Type             string            `json:"type"`
+	InitialDelay     string            `json:"initial_delay,omitempty"`     // Go duration before the first attempt (default none)
+	Period           string            `json:"period,omitempty"`            // Go duration between attempts (default 5s)
+	Timeout          string            `json:"timeout,omitempty"`           // Go duration, per attempt (default 5s)
+	FailureThreshold int               `json:"failure_threshold,omitempty"` // attempts before the probe fails (default 12)
+	HTTP             *HTTPProbeSpec    `json:"http,omitempty"`
+	TCP              *TCPProbeSpec     `json:"tcp,omitempty"`
+	Command          *CommandProbeSpec `json:"command,omitempty"`
+}
+
+// HTTPProbeSpec sends a GET to the tenant; any 2xx response (or one of ExpectedStatus) passes
+type HTTPProbeSpec struct {
+	Path           string `json:"path,omitempty"`   // default /
+	Port           int    `json:"port"`             // tenant port to probe
+	Host           string `json:"host,omitempty"`   // default the address of the tenant endpoint on Port
+	Scheme         string `json:"scheme,omitempty"` // http or https (default http)
+	ExpectedStatus []int  `json:"expected_status,omitempty"`
+}
+
+// TCPProbeSpec passes once a TCP connection to the tenant can be opened
+type TCPProbeSpec struct {
+	Port int    `json:"port"`           // tenant port to probe
+	Host string `json:"host,omitempty"` // default the address of the tenant endpoint on Port
+}
+
+// CommandProbeSpec runs a one-off container through the tenant's compute provider; exit code 0 passes
+type CommandProbeSpec struct {
+	Command []string          `json:"command"`
+	Image   string            `json:"image,omitempty"` // default the tenant's image
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// ProbeResult records the outcome of a readiness probe
+type ProbeResult struct {
+	Type        string    `json:"type"`
+	Target      string    `json:"target,omitempty"`
+	Status      string    `json:"status"` // succeeded or failed, as for hooks
+	Attempts    int       `json:"attempts"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	Error       string    `json:"error,omitempty"`
+	Output      string    `json:"output,omitempty"`
+}
+
+// ParseReadinessProbe extracts and validates the readiness probe from a desired config.
+// Returns nil when no probe is declared.
+func ParseReadinessProbe(desiredConfig map[string]interface{}) (*ReadinessProbe, error) {
+	raw, ok := desiredConfig[ReadinessProbeConfigKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("encode readiness probe: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var probe ReadinessProbe
+	if err := decoder.Decode(&probe); err != nil {
+		return nil, fmt.Errorf("invalid readiness probe: %w", err)
+	}
+
+	if err := probe.Validate(); err != nil {
+		return nil, fmt.Errorf("readiness_probe: %w", err)
+	}
+	return &probe, nil
+}
+
+// Validate checks the probe declaration
+func (p *ReadinessProbe) Validate() error {
+	switch p.Type {
+	case ProbeTypeHTTP:
+		if p.HTTP == nil {
+			return fmt.Errorf("http is required for http probes")
+		}
+		if err := validateProbePort(p.HTTP.Port); err != nil {
+			return fmt.Errorf("http.%w", err)
+		}
+		if p.HTTP.Scheme != "" && p.HTTP.Scheme != "http" && p.HTTP.Scheme != "https" {
+			return fmt.Errorf("http.scheme must be http or https")
+		}
+		if p.HTTP.Path != "" && !strings.HasPrefix(p.HTTP.Path, "/") {
+			return fmt.Errorf("http.path must start with /")
+		}
+	case ProbeTypeTCP:
+		if p.TCP == nil {
+			return fmt.Errorf("tcp is required for tcp probes")
+		}
+		if err := validateProbePort(p.TCP.Port); err != nil {
+			return fmt.Errorf("tcp.%w", err)
+		}
+	case ProbeTypeCommand:
+		if p.Command == nil || len(p.Command.Command) == 0 {
+			return fmt.Errorf("command.command is required for command probes")
+		}
+	default:
+		return fmt.Errorf("type must be %q, %q or %q", ProbeTypeHTTP, ProbeTypeTCP, ProbeTypeCommand)
+	}
+
+	if p.FailureThreshold < 0 {
+		return fmt.Errorf("failure_threshold must be non-negative")
+	}
+	if p.InitialDelay != "" {
+		if d, err := time.ParseDuration(p.InitialDelay); err != nil || d < 0 {
+			return fmt.Errorf("invalid initial_delay: must be a non-negative duration")
+		}
+	}
+	if _, err := parseHookDuration(p.Period, defaultProbePeriod); err != nil {
+		return fmt.Errorf("invalid period: %w", err)
+	}
+	if _, err := parseHookDuration(p.Timeout, defaultProbeTimeout); err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+	return nil
+}
+
+func validateProbePort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535")
+	}
+	return nil
+}
+
+// RunReadinessProbe probes a freshly provisioned or updated tenant until it passes or the probe's
+// failure threshold is reached. HTTP and TCP probes without a host target the tenant endpoint on
+// the probe's port, falling back to the first endpoint's address. Command probes run in a one-off
+// container from image unless the probe names its own. jobRunner may be nil when the compute
+// provider cannot run container jobs. The returned error wraps ErrReadinessProbeFailed.
+func (r *HookRunner) RunReadinessProbe(ctx context.Context, tenantID string, probe *ReadinessProbe, endpoints []compute.Endpoint, image string, jobRunner compute.JobRunner) (*ProbeResult, error) {
+	timeout, _ := parseHookDuration(probe.Timeout, defaultProbeTimeout)
+	period, _ := parseHookDuration(probe.Period, defaultProbePeriod)
+	attempts := defaultProbeFailureThreshold
+	if probe.FailureThreshold > 0 {
+		attempts = probe.FailureThreshold
+	}
+
+	result := &ProbeResult{
+		Type:      probe.Type,
+		StartedAt: time.Now(),
+	}
+	check, target, err := r.probeCheck(tenantID, probe, endpoints, image, jobRunner)
+	result.Target = target
+	if err != nil {
+		result.Status = HookStatusFailed
+		result.Error = err.Error()
+		result.CompletedAt = time.Now()
+		return result, fmt.Errorf("%w: %s", ErrReadinessProbeFailed, result.Error)
+	}
+
+	if probe.InitialDelay != "" {
+		if delay, _ := time.ParseDuration(probe.InitialDelay); delay > 0 {
+			if err := r.sleep(ctx, delay); err != nil {
+				result.Status = HookStatusFailed
+				result.Error = err.Error()
+				result.CompletedAt = time.Now()
+				return result, fmt.Errorf("%w: %s", ErrReadinessProbeFailed, result.Error)
+			}
+		}
+	}
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		result.Attempts = attempt
+
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		output, err := check(attemptCtx)
+		cancel()
+
+		result.Output = truncateHookOutput(output)
+		if err == nil {
+			result.Status = HookStatusSucceeded
+			result.Error = ""
+			break
+		}
+
+		result.Status = HookStatusFailed
+		result.Error = err.Error()
+		r.logger.Debug("readiness probe attempt failed",
+			zap.String("tenant_id", tenantID),
+			zap.String("type", probe.Type),
+			zap.String("target", target),
+			zap.Int("attempt", attempt),
+			zap.Int("failure_threshold", attempts),
+			zap.Error(err),
+		)
+
+		if attempt == attempts || ctx.Err() != nil {
+			break
+		}
+		if err := r.sleep(ctx, period); err != nil {
+			break
+		}
+	}
+
+	result.CompletedAt = time.Now()
+	r.logger.Info("readiness probe completed",
+		zap.String("tenant_id", tenantID),
+		zap.String("type", probe.Type),
+		zap.String("target", target),
+		zap.String("status", result.Status),
+		zap.Int("attempts", result.Attempts),
+	)
+	if result.Status != HookStatusSucceeded {
+		return result, fmt.Errorf("%w after %d attempt(s) against %s: %s", ErrReadinessProbeFailed, result.Attempts, target, result.Error)
+	}
+	return result, nil
+}
+
+// probeCheck resolves the probe's target and returns a single probe attempt against it
+func (r *HookRunner) probeCheck(tenantID string, probe *ReadinessProbe, endpoints []compute.Endpoint, image string, jobRunner compute.JobRunner) (func(ctx context.Context) (string, error), string, error) {
+	switch probe.Type {
+	case ProbeTypeHTTP:
+		host, err := probeHost(probe.HTTP.Host, probe.HTTP.Port, endpoints)
+		if err != nil {
+			return nil, "", err
+		}
+		scheme := probe.HTTP.Scheme
+		if scheme == "" {
+			scheme = "http"
+		}
+		path := probe.HTTP.Path
+		if path == "" {
+			path = "/"
+		}
+		target := (&url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(probe.HTTP.Port)), Path: path}).String()
+		return func(ctx context.Context) (string, error) {
+			return r.probeHTTP(ctx, target, probe.HTTP.ExpectedStatus)
+		}, target, nil
+	case ProbeTypeTCP:
+		host, err := probeHost(probe.TCP.Host, probe.TCP.Port, endpoints)
+		if err != nil {
+			return nil, "", err
+		}
+		target := net.JoinHostPort(host, strconv.Itoa(probe.TCP.Port))
+		return func(ctx context.Context) (string, error) {
+			var dialer net.Dialer
+			conn, err := dialer.DialContext(ctx, "tcp", target)
+			if err != nil {
+				return "", err
+			}
+			return "", conn.Close()
+		}, "tcp://" + target, nil
+	case ProbeTypeCommand:
+		if jobRunner == nil {
+			return nil, "", fmt.Errorf("compute provider does not support command probes")
+		}
+		job := &compute.JobSpec{
+			Name:    "readiness-probe",
+			Image:   probe.Command.Image,
+			Command: probe.Command.Command,
+			Env:     probe.Command.Env,
+		}
+		if job.Image == "" {
+			job.Image = image
+		}
+		if job.Image == "" {
+			return nil, "", fmt.Errorf("command probe needs an image when the tenant has none")
+		}
+		target := strings.Join(job.Command, " ")
+		return func(ctx context.Context) (string, error) {
+			result, err := jobRunner.RunJob(ctx, tenantID, job)
+			if err != nil {
+				return "", err
+			}
+			if result.ExitCode != 0 {
+				return result.Output, fmt.Errorf("command exited with code %d", result.ExitCode)
+			}
+			return result.Output, nil
+		}, target, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported probe type %q", probe.Type)
+	}
+}
+
+func (r *HookRunner) probeHTTP(ctx context.Context, target string, expected []int) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxHookOutputBytes))
+	if !expectedHookStatus(resp.StatusCode, expected) {
+		return string(body), fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return string(body), nil
+}
+
+// probeHost returns host when set, else the address of the endpoint on port, else the first endpoint's address
+func probeHost(host string, port int, endpoints []compute.Endpoint) (string, error) {
+	if host != "" {
+		return host, nil
+	}
+	var fallback string
+	for _, endpoint := range endpoints {
+		address := endpointAddress(endpoint)
+		if address == "" {
+			continue
+		}
+		if endpoint.Port == port {
+			return address, nil
+		}
+		if fallback == "" {
+			fallback = address
+		}
+	}
+	if fallback == "" {
+		return "", fmt.Errorf("tenant reported no endpoint to probe; set the probe's host")
+	}
+	return fallback, nil
+}
+
+func endpointAddress(endpoint compute.Endpoint) string {
+	if endpoint.Address != "" {
+		return endpoint.Address
+	}
+	if parsed, err := url.Parse(endpoint.URL); err == nil {
+		return parsed.Hostname()
+	}
+	return ""
+}
+
+// SetReadinessCondition records a probe result as t's ReadinessProbePassed condition.
+// Returns true when the condition changed.
+func SetReadinessCondition(t *tenant.Tenant, result *ProbeResult, now time.Time) bool {
+	condition := tenant.Condition{
+		Type:    ConditionReadinessProbePassed,
+		Status:  tenant.ConditionTrue,
+		Reason:  ReasonProbeSucceeded,
+		Message: fmt.Sprintf("%s probe against %s passed after %d attempt(s)", result.Type, result.Target, result.Attempts),
+	}
+	if result.Status != HookStatusSucceeded {
+		condition.Status = tenant.ConditionFalse
+		condition.Reason = ReasonProbeFailed
+		condition.Message = fmt.Sprintf("%s probe against %s failed after %d attempt(s): %s", result.Type, result.Target, result.Attempts, result.Error)
+	}
+	return t.SetCondition(condition, now)
+}
+
+// SetReadinessFailure marks t's ReadinessProbePassed condition false when a workflow error message
+// reports a failed readiness probe. Returns false when the message is about something else.
+func SetReadinessFailure(t *tenant.Tenant, message string, now time.Time) bool {
+	index := strings.Index(message, ErrReadinessProbeFailed.Error())
+	if index < 0 {
+		return false
+	}
+	t.SetCondition(tenant.Condition{
+		Type:    ConditionReadinessProbePassed,
+		Status:  tenant.ConditionFalse,
+		Reason:  ReasonProbeFailed,
+		Message: message[index:],
+	}, now)
+	return true
+}
+
+// ProbeResultFromOutput extracts the readiness probe result from decoded workflow output, or nil when there is none
+func ProbeResultFromOutput(output map[string]interface{}) (*ProbeResult, error) {
+	raw, ok := output[ReadinessProbeConfigKey]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var result ProbeResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", ReadinessProbeConfigKey, err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,182 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestParseReadinessProbe(t *testing.T) {
+	probe, err := ParseReadinessProbe(map[string]interface{}{
+		"image": "nginx:latest",
+		"readiness_probe": map[string]interface{}{
+			"type":              "http",
+			"period":            "2s",
+			"failure_threshold": 30,
+			"http":              map[string]interface{}{"path": "/healthz", "port": 8080, "expected_status": []interface{}{200}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("parse readiness probe: %v", err)
+	}
+	if probe.HTTP.Port != 8080 || probe.HTTP.Path != "/healthz" || probe.FailureThreshold != 30 {
+		t.Fatalf("unexpected probe: %+v", probe)
+	}
+
+	none, err := ParseReadinessProbe(map[string]interface{}{"image": "nginx:latest"})
+	if err != nil || none != nil {
+		t.Fatalf("expected no probe, got %+v, %v", none, err)
+	}
+}
+
+func TestParseReadinessProbeRejectsInvalidProbes(t *testing.T) {
+	cases := map[string]map[string]interface{}{
+		"unknown type":      {"type": "grpc"},
+		"missing http":      {"type": "http"},
+		"missing port":      {"type": "tcp", "tcp": map[string]interface{}{}},
+		"port out of range": {"type": "tcp", "tcp": map[string]interface{}{"port": 70000}},
+		"bad scheme":        {"type": "http", "http": map[string]interface{}{"port": 80, "scheme": "ftp"}},
+		"relative path":     {"type": "http", "http": map[string]interface{}{"port": 80, "path": "healthz"}},
+		"missing command":   {"type": "command", "command": map[string]interface{}{}},
+		"bad period":        {"type": "tcp", "period": "often", "tcp": map[string]interface{}{"port": 80}},
+		"negative delay":    {"type": "tcp", "initial_delay": "-1s", "tcp": map[string]interface{}{"port": 80}},
+		"unknown field":     {"type": "tcp", "retries": 3, "tcp": map[string]interface{}{"port": 80}},
+	}
+
+	for name, probe := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseReadinessProbe(map[string]interface{}{"readiness_probe": probe}); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+}
+
+func TestReadinessProbeHTTPTargetsTenantEndpoint(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(serverURL.Port())
+	endpoints := []compute.Endpoint{
+		{Type: "tcp", Address: "10.0.0.1", Port: 9090},
+		{Type: "http", Address: serverURL.Hostname(), Port: port},
+	}
+
+	runner := NewHookRunner(server.Client(), zaptest.NewLogger(t))
+	runner.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	result, err := runner.RunReadinessProbe(context.Background(), "tenant-1", &ReadinessProbe{
+		Type: ProbeTypeHTTP,
+		HTTP: &HTTPProbeSpec{Path: "/healthz", Port: port},
+	}, endpoints, "", nil)
+	if err != nil {
+		t.Fatalf("run readiness probe: %v", err)
+	}
+	if result.Status != HookStatusSucceeded || result.Attempts != 3 || result.Target != server.URL+"/healthz" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestReadinessProbeTCPFailsAfterThreshold(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	runner := NewHookRunner(nil, zaptest.NewLogger(t))
+	var sleeps int
+	runner.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps++
+		return nil
+	}
+
+	result, err := runner.RunReadinessProbe(context.Background(), "tenant-1", &ReadinessProbe{
+		Type:             ProbeTypeTCP,
+		FailureThreshold: 3,
+		TCP:              &TCPProbeSpec{Host: "127.0.0.1", Port: port},
+	}, nil, "", nil)
+	if !errors.Is(err, ErrReadinessProbeFailed) {
+		t.Fatalf("expected ErrReadinessProbeFailed, got %v", err)
+	}
+	if result.Status != HookStatusFailed || result.Attempts != 3 || sleeps != 2 {
+		t.Fatalf("unexpected result %+v after %d sleeps", result, sleeps)
+	}
+
+	if _, err := runner.RunReadinessProbe(context.Background(), "tenant-1", &ReadinessProbe{
+		Type: ProbeTypeTCP,
+		TCP:  &TCPProbeSpec{Port: port},
+	}, nil, "", nil); err == nil || !strings.Contains(err.Error(), "no endpoint") {
+		t.Fatalf("expected an error without endpoints, got %v", err)
+	}
+}
+
+func TestReadinessProbeCommandUsesTenantImage(t *testing.T) {
+	jobs := &fakeJobRunner{}
+	runner := NewHookRunner(nil, zaptest.NewLogger(t))
+
+	result, err := runner.RunReadinessProbe(context.Background(), "tenant-1", &ReadinessProbe{
+		Type:    ProbeTypeCommand,
+		Command: &CommandProbeSpec{Command: []string{"pg_isready"}},
+	}, nil, "postgres:16", jobs)
+	if err != nil {
+		t.Fatalf("run readiness probe: %v", err)
+	}
+	if result.Status != HookStatusSucceeded || len(jobs.jobs) != 1 || jobs.jobs[0].Image != "postgres:16" {
+		t.Fatalf("unexpected result %+v with jobs %+v", result, jobs.jobs)
+	}
+
+	if _, err := runner.RunReadinessProbe(context.Background(), "tenant-1", &ReadinessProbe{
+		Type:    ProbeTypeCommand,
+		Command: &CommandProbeSpec{Command: []string{"pg_isready"}},
+	}, nil, "postgres:16", nil); !errors.Is(err, ErrReadinessProbeFailed) {
+		t.Fatalf("expected ErrReadinessProbeFailed without a job runner, got %v", err)
+	}
+}
+
+func TestSetReadinessConditions(t *testing.T) {
+	now := time.Now()
+	tn := &tenant.Tenant{}
+
+	SetReadinessCondition(tn, &ProbeResult{Type: ProbeTypeTCP, Target: "tcp://10.0.0.1:5432", Status: HookStatusSucceeded, Attempts: 2}, now)
+	condition := tn.Condition(ConditionReadinessProbePassed)
+	if condition == nil || condition.Status != tenant.ConditionTrue || condition.Reason != ReasonProbeSucceeded {
+		t.Fatalf("unexpected condition %+v", condition)
+	}
+
+	if SetReadinessFailure(tn, "compute update failed: timeout", now) {
+		t.Fatal("expected other failures to leave the condition alone")
+	}
+	if !SetReadinessFailure(tn, "update-abc: readiness probe failed after 3 attempt(s) against tcp://10.0.0.1:5432: connection refused", now) {
+		t.Fatal("expected a readiness failure to be recorded")
+	}
+	condition = tn.Condition(ConditionReadinessProbePassed)
+	if condition.Status != tenant.ConditionFalse || !strings.HasPrefix(condition.Message, "readiness probe failed after 3 attempt(s)") {
+		t.Fatalf("unexpected condition %+v", condition)
+	}
+}
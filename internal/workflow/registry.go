@@ -12,6 +12,7 @@ import (
 type Registry struct {
 	mu        sync.RWMutex
 	providers map[string]Provider
+	disabled  map[string]bool
 	logger    *zap.Logger
 }
 
@@ -19,6 +20,7 @@ type Registry struct {
 func NewRegistry(logger *zap.Logger) *Registry {
 	return &Registry{
 		providers: make(map[string]Provider),
+		disabled:  make(map[string]bool),
 		logger:    logger.With(zap.String("component", "workflow-registry")),
 	}
 }
@@ -83,3 +85,72 @@ func (r *Registry) Has(providerType string) bool {
 	_, exists := r.providers[providerType]
 	return exists
 }
+
+// SetEnabled enables or disables a provider for new executions.
+// Disabled providers stay registered so running executions can still be polled and stopped.
+func (r *Registry) SetEnabled(providerType string, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.providers[providerType]; !exists {
+		return fmt.Errorf("%w: %s", ErrProviderNotFound, providerType)
+	}
+
+	if enabled {
+		delete(r.disabled, providerType)
+	} else {
+		r.disabled[providerType] = true
+	}
+	r.logger.Info("workflow provider availability changed",
+		zap.String("provider", providerType),
+		zap.Bool("enabled", enabled),
+	)
+
+	return nil
+}
+
+// Enabled reports whether a registered provider accepts new executions
+func (r *Registry) Enabled(providerType string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, exists := r.providers[providerType]
+	return exists && !r.disabled[providerType]
+}
+
+// GetForExecution retrieves a provider that may start new executions
+func (r *Registry) GetForExecution(providerType string) (Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	provider, exists := r.providers[providerType]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrProviderNotFound, providerType)
+	}
+	if r.disabled[providerType] {
+		return nil, fmt.Errorf("%w: %s", ErrProviderDisabled, providerType)
+	}
+
+	return provider, nil
+}
+
+// Reconfigure applies new settings to a provider that implements Reconfigurable
+func (r *Registry) Reconfigure(providerType string, settings map[string]interface{}) error {
+	provider, err := r.Get(providerType)
+	if err != nil {
+		return err
+	}
+
+	reconfigurable, ok := provider.(Reconfigurable)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrReconfigureUnsupported, providerType)
+	}
+	if err := reconfigurable.Reconfigure(settings); err != nil {
+		return err
+	}
+	r.logger.Info("workflow provider reconfigured",
+		zap.String("provider", providerType),
+	)
+
+	return nil
+}
@@ -198,3 +198,24 @@ func TestRegistryEmptyProviderName(t *testing.T) {
 		t.Fatal("expected error for empty provider name")
 	}
 }
+
+func TestRegistrySetEnabled(t *testing.T) {
+	registry := NewRegistry(zap.NewNop())
+	registry.Register(&testProvider{name: "test"})
+
+	if !registry.Enabled("test") {
+		t.Fatal("expected registered provider to be enabled")
+	}
+	if err := registry.SetEnabled("test", false); err != nil {
+		t.Fatalf("SetEnabled failed: %v", err)
+	}
+	if _, err := registry.GetForExecution("test"); !errors.Is(err, ErrProviderDisabled) {
+		t.Errorf("expected ErrProviderDisabled, got %v", err)
+	}
+	if err := registry.SetEnabled("missing", true); !errors.Is(err, ErrProviderNotFound) {
+		t.Errorf("expected ErrProviderNotFound, got %v", err)
+	}
+	if err := registry.Reconfigure("test", nil); !errors.Is(err, ErrReconfigureUnsupported) {
+		t.Errorf("expected ErrReconfigureUnsupported, got %v", err)
+	}
+}
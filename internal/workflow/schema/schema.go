@@ -0,0 +1,218 @@
+// Package schema is the registry of versioned JSON schemas for the payloads exchanged between the
+// API, the reconciler, workflow providers and workers. Payloads are validated against the schema
+// version they declare, so a component receiving a payload it does not understand rejects it
+// instead of silently dropping fields.
+package schema
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Schema names
+const (
+	// ProvisionRequest is the input the controller sends to a workflow provider
+	ProvisionRequest = "provision-request"
+
+	// ComputeCallback is the result a compute execution posts back to its workflow execution
+	ComputeCallback = "compute-callback"
+)
+
+// Schema versions
+const (
+	// V1 is the first version of every schema
+	V1 = "v1"
+
+	// V2 of the provision request lets the desired config declare components
+	V2 = "v2"
+)
+
+// Latest versions written by this build. Bump a version (and add its schema file) when a change
+// alters or requires fields; optional fields may be added to the latest version, and components
+// that predate them reject payloads that set them.
+const (
+	ProvisionRequestVersion = V2
+	ComputeCallbackVersion  = V1
+)
+
+// ErrIncompatible is returned for payloads that do not match a known schema version
+var ErrIncompatible = errors.New("incompatible payload")
+
+//go:embed schemas/*.json
+var files embed.FS
+
+var descriptions = map[string]string{
+	ProvisionRequest: "Input the controller sends to a workflow provider to run one tenant lifecycle operation",
+	ComputeCallback:  "Result a compute execution posts back to the workflow execution waiting on it",
+}
+
+var latest = map[string]string{
+	ProvisionRequest: ProvisionRequestVersion,
+	ComputeCallback:  ComputeCallbackVersion,
+}
+
+// Schema is one version of a payload schema
+type Schema struct {
+	Name        string          `json:"name"`
+	Version     string          `json:"version"`
+	Latest      bool            `json:"latest"`
+	Description string          `json:"description"`
+	Document    json.RawMessage `json:"document"`
+}
+
+var (
+	compileOnce sync.Once
+	compiled    map[string]*jsonschema.Schema
+	documents   map[string]json.RawMessage
+	compileErr  error
+)
+
+func key(name, version string) string {
+	return name + "." + version
+}
+
+func load() error {
+	compileOnce.Do(func() {
+		entries, err := files.ReadDir("schemas")
+		if err != nil {
+			compileErr = err
+			return
+		}
+		compiled = make(map[string]*jsonschema.Schema, len(entries))
+		documents = make(map[string]json.RawMessage, len(entries))
+
+		compiler := jsonschema.NewCompiler()
+		for _, entry := range entries {
+			data, err := files.ReadFile("schemas/" + entry.Name())
+			if err != nil {
+				compileErr = err
+				return
+			}
+			id := strings.TrimSuffix(entry.Name(), ".json")
+			if err := compiler.AddResource(entry.Name(), bytes.NewReader(data)); err != nil {
+				compileErr = fmt.Errorf("load schema %s: %w", id, err)
+				return
+			}
+			s, err := compiler.Compile(entry.Name())
+			if err != nil {
+				compileErr = fmt.Errorf("compile schema %s: %w", id, err)
+				return
+			}
+			compiled[id] = s
+			documents[id] = data
+		}
+	})
+	return compileErr
+}
+
+// List returns every schema version, sorted by name and version
+func List() ([]Schema, error) {
+	if err := load(); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(documents))
+	for id := range documents {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	schemas := make([]Schema, 0, len(ids))
+	for _, id := range ids {
+		dot := strings.LastIndex(id, ".")
+		name, version := id[:dot], id[dot+1:]
+		schemas = append(schemas, Schema{
+			Name:        name,
+			Version:     version,
+			Latest:      latest[name] == version,
+			Description: descriptions[name],
+			Document:    documents[id],
+		})
+	}
+	return schemas, nil
+}
+
+// Get returns one version of a schema; the boolean is false when it does not exist
+func Get(name, version string) (*Schema, bool, error) {
+	schemas, err := List()
+	if err != nil {
+		return nil, false, err
+	}
+	for i := range schemas {
+		if schemas[i].Name == name && schemas[i].Version == version {
+			return &schemas[i], true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// Validate checks a payload against the version of the named schema it declares in
+// schema_version; payloads without one are v1. payload is a JSON document or a value that encodes
+// to one. Errors wrap ErrIncompatible when the version is unknown or the payload does not match.
+func Validate(name string, payload interface{}) error {
+	if err := load(); err != nil {
+		return err
+	}
+
+	data, ok := payload.([]byte)
+	if !ok {
+		if raw, isRaw := payload.(json.RawMessage); isRaw {
+			data = raw
+		} else {
+			encoded, err := json.Marshal(payload)
+			if err != nil {
+				return fmt.Errorf("encode %s: %w", name, err)
+			}
+			data = encoded
+		}
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("%w: %s is not valid JSON: %v", ErrIncompatible, name, err)
+	}
+
+	version := V1
+	if fields, ok := document.(map[string]interface{}); ok {
+		if declared, ok := fields["schema_version"].(string); ok && declared != "" {
+			version = declared
+		}
+	}
+
+	s, ok := compiled[key(name, version)]
+	if !ok {
+		return fmt.Errorf("%w: unknown %s schema version %q", ErrIncompatible, name, version)
+	}
+	if err := s.Validate(document); err != nil {
+		var vErr *jsonschema.ValidationError
+		if errors.As(err, &vErr) {
+			return fmt.Errorf("%w: %s %s: %s", ErrIncompatible, name, version, strings.Join(flatten(vErr), "; "))
+		}
+		return fmt.Errorf("%w: %s %s: %v", ErrIncompatible, name, version, err)
+	}
+	return nil
+}
+
+// flatten returns the leaf causes of a validation error, which name the offending fields
+func flatten(err *jsonschema.ValidationError) []string {
+	if len(err.Causes) == 0 {
+		location := err.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		return []string{fmt.Sprintf("%s: %s", location, err.Message)}
+	}
+	var details []string
+	for _, cause := range err.Causes {
+		details = append(details, flatten(cause)...)
+	}
+	return details
+}
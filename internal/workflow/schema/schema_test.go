@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestList(t *testing.T) {
+	schemas, err := List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(schemas) != 3 {
+		t.Fatalf("expected 3 schemas, got %d", len(schemas))
+	}
+	if schemas[0].Name != ComputeCallback || schemas[1].Name != ProvisionRequest || schemas[2].Name != ProvisionRequest {
+		t.Fatalf("unexpected order %s, %s, %s", schemas[0].Name, schemas[1].Name, schemas[2].Name)
+	}
+	for i, want := range []struct {
+		version string
+		latest  bool
+	}{{V1, true}, {V1, false}, {V2, true}} {
+		s := schemas[i]
+		if s.Version != want.version || s.Latest != want.latest || s.Description == "" || !json.Valid(s.Document) {
+			t.Fatalf("unexpected schema %+v", s)
+		}
+	}
+
+	if _, ok, err := Get(ProvisionRequest, "v9"); err != nil || ok {
+		t.Fatalf("expected no v9 schema, got %v, %v", ok, err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := map[string]interface{}{
+		"schema_version":  V1,
+		"tenant_id":       "acme",
+		"operation":       "migrate",
+		"desired_config":  map[string]interface{}{"image": "nginx:latest"},
+		"migration_phase": "switching-endpoints",
+		"previous_endpoints": []interface{}{
+			map[string]interface{}{"type": "http", "address": "localhost", "port": 8080},
+		},
+	}
+	if err := Validate(ProvisionRequest, valid); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if err := Validate(ProvisionRequest, []byte(`{"tenant_id":"acme"}`)); err != nil {
+		t.Fatalf("expected a payload without schema_version to validate as v1, got %v", err)
+	}
+	v2 := `{"schema_version":"v2","tenant_id":"acme","operation":"update",` +
+		`"desired_config":{"components":{"app":{"image":"nginx:latest"}}},"previous_components":[{"name":"app"},{"name":"worker","endpoints":[{"type":"http","address":"localhost","port":8080}]}]}`
+	if err := Validate(ProvisionRequest, []byte(v2)); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if err := Validate(ComputeCallback, []byte(`{"execution_id":"exec-1","tenant_id":"acme","status":"succeeded","is_retriable":false}`)); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		schema  string
+		payload string
+		detail  string
+	}{
+		"unknown version": {ProvisionRequest, `{"schema_version":"v9","tenant_id":"acme"}`, `version "v9"`},
+		"newer field":     {ProvisionRequest, `{"tenant_id":"acme","previous_components":[{"name":"app"}]}`, "previous_components"},
+		"unknown field":   {ProvisionRequest, `{"tenant_id":"acme","priority":1}`, "priority"},
+		"missing tenant":  {ProvisionRequest, `{"operation":"provision"}`, "tenant_id"},
+		"bad operation":   {ProvisionRequest, `{"tenant_id":"acme","operation":"reboot"}`, "/operation"},
+		"bad status":      {ComputeCallback, `{"execution_id":"exec-1","tenant_id":"acme","status":"done"}`, "/status"},
+		"not json":        {ComputeCallback, `{`, "not valid JSON"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := Validate(tc.schema, []byte(tc.payload))
+			if !errors.Is(err, ErrIncompatible) {
+				t.Fatalf("expected ErrIncompatible, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tc.detail) {
+				t.Fatalf("expected error to mention %q, got %v", tc.detail, err)
+			}
+		})
+	}
+}
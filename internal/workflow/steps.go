@@ -0,0 +1,61 @@
+package workflow
+
+import (
+	"context"
+	"time"
+)
+
+// Steps workers report as they move through a tenant workflow
+const (
+	StepValidate    = "validate"
+	StepProvision   = "provision"
+	StepUpdate      = "update"
+	StepDestroy     = "destroy"
+	StepMigrate     = "migrate"
+	StepHealthCheck = "health-check"
+	StepFinalize    = "finalize"
+)
+
+// StepStatus is where a workflow step is
+type StepStatus string
+
+const (
+	StepRunning   StepStatus = "running"
+	StepSucceeded StepStatus = "succeeded"
+	StepFailed    StepStatus = "failed"
+)
+
+// IsValid reports whether s is a known step status
+func (s StepStatus) IsValid() bool {
+	switch s {
+	case StepRunning, StepSucceeded, StepFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// StepReport is the start or finish of one step of an execution
+type StepReport struct {
+	// ExecutionID is the execution the step belongs to, as the workflow provider reports it to the server
+	ExecutionID string `json:"-"`
+
+	// TenantID is the tenant's UUID or name
+	TenantID string `json:"tenant_id"`
+
+	Step      string     `json:"step"`
+	Status    StepStatus `json:"status"`
+	StartedAt time.Time  `json:"started_at"`
+
+	// FinishedAt is set once the step succeeded or failed
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+
+	// Error is why a failed step failed
+	Error string `json:"error,omitempty"`
+}
+
+// StepReporter receives workflow step progress from workers. Reports are best effort: a
+// worker carries on with its workflow when one cannot be delivered.
+type StepReporter interface {
+	ReportStep(ctx context.Context, report StepReport) error
+}
@@ -33,6 +33,8 @@ type ExecutionInput struct {
 	Tags          map[string]string `json:"tags,omitempty"`
 	Metadata      map[string]string `json:"metadata,omitempty"` // Metadata like config_hash for change detection
 	TriggerSource string            `json:"trigger_source,omitempty"` // "api" or "controller"
+	// WorkflowVersion pins the execution to a workflow definition version (empty means v1)
+	WorkflowVersion string `json:"workflow_version,omitempty"`
 }
 
 // CreateWorkflowResult is result from creating a workflow
@@ -91,6 +93,25 @@ const (
 	SubStateError      WorkflowSubState = "error"
 	SubStateSucceeded  WorkflowSubState = "succeeded"
 	SubStateFailed     WorkflowSubState = "failed"
+
+	// SubStatePendingCapacity marks a tenant whose workflow is held back by admission control
+	// until the workflow engine and compute providers recover
+	SubStatePendingCapacity WorkflowSubState = "pending-capacity"
+
+	// SubStateQueued marks a tenant whose workflow trigger is in the trigger outbox, waiting for
+	// the dispatcher to start it
+	SubStateQueued WorkflowSubState = "queued"
+
+	// SubStatePreStop, SubStateDraining and SubStateStopping mark an archiving or deleting tenant
+	// whose compute is being stopped gracefully: its pre-stop hook is running, it is draining
+	// within its termination grace period, or it is being stopped and removed
+	SubStatePreStop  WorkflowSubState = "pre-stop"
+	SubStateDraining WorkflowSubState = "draining"
+	SubStateStopping WorkflowSubState = "stopping"
+
+	// SubStateFinalizing marks a deleting or archiving tenant whose workflow has succeeded, waiting
+	// for its finalizers before it is deleted or archived
+	SubStateFinalizing WorkflowSubState = "finalizing"
 )
 
 // MapExecutionStateToSubState maps execution state to canonical workflow sub-state
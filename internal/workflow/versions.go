@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"context"
+	"sort"
+)
+
+// WorkflowVersionV1 is the original tenant lifecycle workflow definition.
+const WorkflowVersionV1 = "v1"
+
+// LatestWorkflowVersion is the definition version used for new executions.
+// Bump this (and add the previous value to supportedWorkflowVersions) when a
+// workflow changes incompatibly; in-flight executions keep their original version.
+const LatestWorkflowVersion = WorkflowVersionV1
+
+var supportedWorkflowVersions = []string{WorkflowVersionV1}
+
+// SupportedWorkflowVersions returns every workflow version workers can still execute, oldest first.
+func SupportedWorkflowVersions() []string {
+	versions := make([]string, len(supportedWorkflowVersions))
+	copy(versions, supportedWorkflowVersions)
+	return versions
+}
+
+// EffectiveWorkflowVersion returns the version an execution runs on.
+// Executions recorded before versioning existed ran on v1.
+func EffectiveWorkflowVersion(version string) string {
+	if version == "" {
+		return WorkflowVersionV1
+	}
+	return version
+}
+
+// IsSupportedWorkflowVersion reports whether workers can execute the given version.
+func IsSupportedWorkflowVersion(version string) bool {
+	version = EffectiveWorkflowVersion(version)
+	for _, supported := range supportedWorkflowVersions {
+		if supported == version {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkflowDefinition describes a registered workflow at a specific version
+type WorkflowDefinition struct {
+	WorkflowID string `json:"workflow_id"`
+	Version    string `json:"version"`
+	Latest     bool   `json:"latest"`
+}
+
+// WorkflowLister is implemented by providers that can enumerate their registered workflows
+type WorkflowLister interface {
+	ListWorkflows(ctx context.Context) ([]WorkflowDefinition, error)
+}
+
+// VersionedDefinitions expands workflow IDs into one definition per supported version.
+func VersionedDefinitions(workflowIDs []string) []WorkflowDefinition {
+	ids := append([]string(nil), workflowIDs...)
+	sort.Strings(ids)
+
+	definitions := make([]WorkflowDefinition, 0, len(ids)*len(supportedWorkflowVersions))
+	for _, id := range ids {
+		for _, version := range supportedWorkflowVersions {
+			definitions = append(definitions, WorkflowDefinition{
+				WorkflowID: id,
+				Version:    version,
+				Latest:     version == LatestWorkflowVersion,
+			})
+		}
+	}
+	return definitions
+}
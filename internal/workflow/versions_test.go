@@ -0,0 +1,62 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+type listingProvider struct {
+	mockProvider
+	ids []string
+}
+
+func (l *listingProvider) ListWorkflows(ctx context.Context) ([]WorkflowDefinition, error) {
+	return VersionedDefinitions(l.ids), nil
+}
+
+func TestWorkflowVersionHelpers(t *testing.T) {
+	if got := EffectiveWorkflowVersion(""); got != WorkflowVersionV1 {
+		t.Fatalf("expected empty version to resolve to %s, got %s", WorkflowVersionV1, got)
+	}
+	if !IsSupportedWorkflowVersion(LatestWorkflowVersion) {
+		t.Fatal("expected latest version to be supported")
+	}
+	if !IsSupportedWorkflowVersion("") {
+		t.Fatal("expected legacy executions to be supported")
+	}
+	if IsSupportedWorkflowVersion("v0") {
+		t.Fatal("expected unknown version to be unsupported")
+	}
+}
+
+func TestManagerListWorkflows(t *testing.T) {
+	registry := NewRegistry(zap.NewNop())
+	if err := registry.Register(&listingProvider{mockProvider: mockProvider{name: "restate"}, ids: []string{"tenant-provisioning"}}); err != nil {
+		t.Fatalf("register restate: %v", err)
+	}
+	if err := registry.Register(&mockProvider{name: "step-functions"}); err != nil {
+		t.Fatalf("register step-functions: %v", err)
+	}
+	manager := New(registry, zap.NewNop())
+
+	workflows, err := manager.ListWorkflows(context.Background())
+	if err != nil {
+		t.Fatalf("list workflows: %v", err)
+	}
+
+	restate := workflows["restate"]
+	if len(restate) != len(SupportedWorkflowVersions()) {
+		t.Fatalf("expected one restate definition per supported version, got %d", len(restate))
+	}
+	latest := restate[len(restate)-1]
+	if latest.WorkflowID != "tenant-provisioning" || latest.Version != LatestWorkflowVersion || !latest.Latest {
+		t.Fatalf("unexpected latest definition: %+v", latest)
+	}
+
+	stepFunctions, ok := workflows["step-functions"]
+	if !ok || len(stepFunctions) != 0 {
+		t.Fatalf("expected empty list for provider without listing support, got %+v (present=%v)", stepFunctions, ok)
+	}
+}
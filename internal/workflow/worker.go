@@ -14,6 +14,13 @@ type WorkerEngine interface {
 	Start(ctx context.Context, addr string) error
 }
 
+// ReadinessNotifier is implemented by worker engines that can signal when Start
+// has bound its listener and is able to accept requests from the workflow backend.
+type ReadinessNotifier interface {
+	// WaitReady blocks until the worker is ready, startup fails, or ctx is done.
+	WaitReady(ctx context.Context) error
+}
+
 // WorkerJobPayload is the standard job payload passed to workflow workers.
 type WorkerJobPayload = ProvisionRequest
 
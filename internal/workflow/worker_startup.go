@@ -0,0 +1,65 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRegisterInitialBackoff = time.Second
+	defaultRegisterMaxBackoff     = 30 * time.Second
+)
+
+// RegisterWorker registers a worker engine with its workflow backend once the
+// worker is ready, retrying with exponential backoff until it succeeds or ctx is done.
+// It is intended to run alongside Start so a slow or unavailable backend does not
+// take the worker down.
+func RegisterWorker(ctx context.Context, worker WorkerEngine, initialBackoff, maxBackoff time.Duration, logger *zap.Logger) error {
+	if initialBackoff <= 0 {
+		initialBackoff = defaultRegisterInitialBackoff
+	}
+	if maxBackoff < initialBackoff {
+		maxBackoff = defaultRegisterMaxBackoff
+		if maxBackoff < initialBackoff {
+			maxBackoff = initialBackoff
+		}
+	}
+	logger = logger.With(zap.String("worker_engine", worker.Name()))
+
+	if notifier, ok := worker.(ReadinessNotifier); ok {
+		if err := notifier.WaitReady(ctx); err != nil {
+			return fmt.Errorf("wait for worker readiness: %w", err)
+		}
+	}
+
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		err := worker.Register(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("register worker: %w", ctx.Err())
+		}
+
+		logger.Warn("worker registration failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("register worker: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
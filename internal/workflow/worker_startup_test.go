@@ -0,0 +1,75 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type flakyWorker struct {
+	testWorker
+	failures int32
+	calls    int32
+	ready    chan struct{}
+}
+
+func (f *flakyWorker) Register(ctx context.Context) error {
+	if atomic.AddInt32(&f.calls, 1) <= f.failures {
+		return errors.New("admin api unavailable")
+	}
+	return nil
+}
+
+func (f *flakyWorker) WaitReady(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-f.ready:
+		return nil
+	}
+}
+
+func TestRegisterWorkerRetriesUntilSuccess(t *testing.T) {
+	worker := &flakyWorker{testWorker: testWorker{name: "restate"}, failures: 2, ready: make(chan struct{})}
+	close(worker.ready)
+
+	err := RegisterWorker(context.Background(), worker, time.Millisecond, 2*time.Millisecond, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("expected registration to succeed, got %v", err)
+	}
+	if got := atomic.LoadInt32(&worker.calls); got != 3 {
+		t.Fatalf("expected 3 register calls, got %d", got)
+	}
+}
+
+func TestRegisterWorkerWaitsForReadiness(t *testing.T) {
+	worker := &flakyWorker{testWorker: testWorker{name: "restate"}, ready: make(chan struct{})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := RegisterWorker(ctx, worker, time.Millisecond, time.Millisecond, zaptest.NewLogger(t))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if got := atomic.LoadInt32(&worker.calls); got != 0 {
+		t.Fatalf("expected no register calls before readiness, got %d", got)
+	}
+}
+
+func TestRegisterWorkerStopsOnCancel(t *testing.T) {
+	worker := &flakyWorker{testWorker: testWorker{name: "restate"}, failures: 1000, ready: make(chan struct{})}
+	close(worker.ready)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := RegisterWorker(ctx, worker, time.Millisecond, 5*time.Millisecond, zaptest.NewLogger(t))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,502 @@
+// Package computetest is a conformance suite for compute.Provider implementations. It checks the
+// behavior the controller, the compute manager and the workflow workers rely on, so every provider
+// meets the same contract:
+//
+//	func TestConformance(t *testing.T) {
+//		computetest.Run(t, computetest.Config{
+//			NewProvider: func(t *testing.T) compute.Provider { return mock.New() },
+//		})
+//	}
+//
+// The suite provisions real compute through the provider, so against a provider with a real backend,
+// such as Docker or ECS, it needs that backend and the images its specs use. Tenants it creates are
+// destroyed when each test finishes.
+package computetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/jaxxstorm/landlord/internal/compute"
+)
+
+const (
+	defaultReadyTimeout = 2 * time.Minute
+	defaultPollInterval = time.Second
+	defaultTenantPrefix = "conformance"
+
+	// cleanupTimeout bounds destroying a test's tenants when it finishes
+	cleanupTimeout = time.Minute
+)
+
+// Config configures a conformance run. NewProvider is required.
+type Config struct {
+	// NewProvider returns the provider under test. It is called once per test.
+	NewProvider func(t *testing.T) compute.Provider
+
+	// Spec returns a spec the provider can provision for a tenant (default DefaultSpec). The suite
+	// adds the default metadata labels to it, as the compute manager does.
+	Spec func(tenantID string) *compute.TenantComputeSpec
+
+	// Change changes a spec in a way the provider must report as a change on update (default:
+	// doubles the memory of the spec and of each container that sets it)
+	Change func(spec *compute.TenantComputeSpec)
+
+	// Labels returns the labels the backend holds for a tenant's compute, such as a container's
+	// labels or a service's tags. The label propagation test is skipped when it is nil.
+	Labels func(ctx context.Context, provider compute.Provider, tenantID string) (map[string]string, error)
+
+	// Restart stands in for a restart of the process running provider: it returns a new provider
+	// over the same backend, which must find and manage the compute the old one created. The restart
+	// recovery test is skipped when it is nil, such as for providers that keep their state in memory.
+	Restart func(t *testing.T, provider compute.Provider) compute.Provider
+
+	// ReadyTimeout bounds how long the suite waits for compute to become ready, stop or go away
+	// (default 2m)
+	ReadyTimeout time.Duration
+
+	// PollInterval is how often the suite polls GetStatus while it waits (default 1s)
+	PollInterval time.Duration
+
+	// TenantPrefix starts the ID of every tenant the suite creates (default "conformance"), so
+	// leftovers from an interrupted run can be found
+	TenantPrefix string
+}
+
+// DefaultSpec is a single nginx service container serving port 80, with small resource limits
+func DefaultSpec(tenantID string) *compute.TenantComputeSpec {
+	return &compute.TenantComputeSpec{
+		TenantID: tenantID,
+		Containers: []compute.ContainerSpec{{
+			Name:  "app",
+			Image: "nginx:alpine",
+			Ports: []compute.PortMapping{{ContainerPort: 80, Protocol: "tcp"}},
+			Env:   map[string]string{"LANDLORD_CONFORMANCE": "true"},
+		}},
+		Resources: compute.ResourceRequirements{CPU: 250, Memory: 128},
+		Labels:    map[string]string{"landlord.conformance": "true"},
+	}
+}
+
+// Run runs the conformance suite as subtests of t
+func Run(t *testing.T, cfg Config) {
+	if cfg.NewProvider == nil {
+		t.Fatal("computetest: Config.NewProvider is required")
+	}
+	s := &suite{cfg: cfg}
+	if s.cfg.Spec == nil {
+		s.cfg.Spec = DefaultSpec
+	}
+	if s.cfg.Change == nil {
+		s.cfg.Change = doubleMemory
+	}
+	if s.cfg.ReadyTimeout <= 0 {
+		s.cfg.ReadyTimeout = defaultReadyTimeout
+	}
+	if s.cfg.PollInterval <= 0 {
+		s.cfg.PollInterval = defaultPollInterval
+	}
+	if s.cfg.TenantPrefix == "" {
+		s.cfg.TenantPrefix = defaultTenantPrefix
+	}
+
+	t.Run("Schema", s.testSchema)
+	t.Run("ProvisionThenStatus", s.testProvisionThenStatus)
+	t.Run("DuplicateProvision", s.testDuplicateProvision)
+	t.Run("StatusOfUnknownTenant", s.testStatusOfUnknownTenant)
+	t.Run("IdempotentDestroy", s.testIdempotentDestroy)
+	t.Run("UpdateDiff", s.testUpdateDiff)
+	t.Run("LabelPropagation", s.testLabelPropagation)
+	t.Run("RestartRecovery", s.testRestartRecovery)
+	t.Run("PowerManager", s.testPowerManager)
+}
+
+type suite struct {
+	cfg Config
+}
+
+// testSchema checks the provider describes its compute_config consistently
+func (s *suite) testSchema(t *testing.T) {
+	p := s.cfg.NewProvider(t)
+	if p.Name() == "" {
+		t.Fatal("Name() is empty")
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(p.ConfigSchema(), &schema); err != nil {
+		t.Fatalf("ConfigSchema() is not a JSON object: %v", err)
+	}
+
+	defaults := p.ConfigDefaults()
+	if defaults == nil {
+		return
+	}
+	var object map[string]interface{}
+	if err := json.Unmarshal(defaults, &object); err != nil {
+		t.Fatalf("ConfigDefaults() is not a JSON object: %v", err)
+	}
+	if err := p.ValidateConfig(defaults); err != nil {
+		t.Errorf("ValidateConfig() rejects the provider's own ConfigDefaults(): %v", err)
+	}
+}
+
+// testProvisionThenStatus checks that what Provision reports agrees with what GetStatus reports
+// once the compute is ready
+func (s *suite) testProvisionThenStatus(t *testing.T) {
+	p := s.cfg.NewProvider(t)
+	ctx := context.Background()
+	spec := s.spec(t, p)
+
+	if err := p.Validate(ctx, spec); err != nil {
+		t.Fatalf("Validate() rejects the spec: %v", err)
+	}
+	result := s.provision(t, p, spec)
+	if result.TenantID != spec.TenantID {
+		t.Errorf("Provision() reported tenant %q, want %q", result.TenantID, spec.TenantID)
+	}
+	if result.ProviderType != p.Name() {
+		t.Errorf("Provision() reported provider %q, want %q", result.ProviderType, p.Name())
+	}
+	if result.Status != compute.ProvisionStatusSuccess && result.Status != compute.ProvisionStatusInProgress {
+		t.Errorf("Provision() succeeded with status %q, want %q or %q", result.Status, compute.ProvisionStatusSuccess, compute.ProvisionStatusInProgress)
+	}
+	if len(result.ResourceIDs) == 0 {
+		t.Error("Provision() reported no resource IDs")
+	}
+
+	status := s.waitReady(t, p, spec.TenantID)
+	if status.TenantID != spec.TenantID {
+		t.Errorf("GetStatus() reported tenant %q, want %q", status.TenantID, spec.TenantID)
+	}
+	if status.ProviderType != p.Name() {
+		t.Errorf("GetStatus() reported provider %q, want %q", status.ProviderType, p.Name())
+	}
+	for key, id := range status.ResourceIDs {
+		if provisioned, ok := result.ResourceIDs[key]; ok && provisioned != id {
+			t.Errorf("GetStatus() reported resource %s = %q, but Provision() reported %q", key, id, provisioned)
+		}
+	}
+}
+
+// testDuplicateProvision checks that provisioning a tenant that already has compute fails with
+// ErrAlreadyProvisioned, which keeps rollback from removing the existing compute
+func (s *suite) testDuplicateProvision(t *testing.T) {
+	p := s.cfg.NewProvider(t)
+	spec := s.spec(t, p)
+	s.provision(t, p, spec)
+
+	_, err := p.Provision(context.Background(), s.withMetadata(p, s.cfg.Spec(spec.TenantID)))
+	if !errors.Is(err, compute.ErrAlreadyProvisioned) {
+		t.Fatalf("provisioning %s again returned %v, want an error wrapping ErrAlreadyProvisioned", spec.TenantID, err)
+	}
+	s.waitReady(t, p, spec.TenantID)
+}
+
+// testStatusOfUnknownTenant checks that the operations on a tenant without compute fail with
+// ErrTenantNotFound
+func (s *suite) testStatusOfUnknownTenant(t *testing.T) {
+	p := s.cfg.NewProvider(t)
+	ctx := context.Background()
+	tenantID := s.tenantID()
+
+	if _, err := p.GetStatus(ctx, tenantID); !errors.Is(err, compute.ErrTenantNotFound) {
+		t.Errorf("GetStatus() of an unknown tenant returned %v, want an error wrapping ErrTenantNotFound", err)
+	}
+	spec := s.withMetadata(p, s.cfg.Spec(tenantID))
+	if _, err := p.Update(ctx, tenantID, spec); !errors.Is(err, compute.ErrTenantNotFound) {
+		t.Errorf("Update() of an unknown tenant returned %v, want an error wrapping ErrTenantNotFound", err)
+	}
+	if inventory, ok := p.(compute.Inventory); ok {
+		tenantIDs, err := inventory.ListTenants(ctx)
+		if err != nil {
+			t.Fatalf("ListTenants() failed: %v", err)
+		}
+		if slices.Contains(tenantIDs, tenantID) {
+			t.Errorf("ListTenants() includes %s, which was never provisioned", tenantID)
+		}
+	}
+}
+
+// testIdempotentDestroy checks that Destroy succeeds whether or not the tenant has compute, and
+// that destroyed compute is gone
+func (s *suite) testIdempotentDestroy(t *testing.T) {
+	p := s.cfg.NewProvider(t)
+	ctx := context.Background()
+
+	if err := p.Destroy(ctx, s.tenantID()); err != nil {
+		t.Errorf("Destroy() of a tenant that was never provisioned failed: %v", err)
+	}
+
+	spec := s.spec(t, p)
+	s.provision(t, p, spec)
+	s.waitReady(t, p, spec.TenantID)
+	s.expectInventory(t, p, spec.TenantID, true)
+
+	if err := p.Destroy(ctx, spec.TenantID); err != nil {
+		t.Fatalf("Destroy() failed: %v", err)
+	}
+	s.waitGone(t, p, spec.TenantID)
+	if err := p.Destroy(ctx, spec.TenantID); err != nil {
+		t.Errorf("Destroy() of a destroyed tenant failed: %v", err)
+	}
+	s.expectInventory(t, p, spec.TenantID, false)
+}
+
+// testUpdateDiff checks that Update reports changes exactly when the spec changed, so the
+// controller can tell a no-op update from a rollout
+func (s *suite) testUpdateDiff(t *testing.T) {
+	p := s.cfg.NewProvider(t)
+	ctx := context.Background()
+	spec := s.spec(t, p)
+	s.provision(t, p, spec)
+	s.waitReady(t, p, spec.TenantID)
+
+	unchanged := s.withMetadata(p, s.cfg.Spec(spec.TenantID))
+	result, err := p.Update(ctx, spec.TenantID, unchanged)
+	if err != nil {
+		t.Fatalf("Update() with the provisioned spec failed: %v", err)
+	}
+	if result.Status != compute.UpdateStatusNoChanges || len(result.Changes) > 0 {
+		t.Errorf("Update() with the provisioned spec reported status %q and changes %v, want %q and none",
+			result.Status, result.Changes, compute.UpdateStatusNoChanges)
+	}
+
+	changed := s.withMetadata(p, s.cfg.Spec(spec.TenantID))
+	s.cfg.Change(changed)
+	result, err = p.Update(ctx, spec.TenantID, changed)
+	if err != nil {
+		t.Fatalf("Update() with a changed spec failed: %v", err)
+	}
+	if result.Status != compute.UpdateStatusSuccess && result.Status != compute.UpdateStatusInProgress {
+		t.Errorf("Update() with a changed spec reported status %q, want %q or %q", result.Status, compute.UpdateStatusSuccess, compute.UpdateStatusInProgress)
+	}
+	if len(result.Changes) == 0 {
+		t.Error("Update() with a changed spec reported no changes")
+	}
+	if result.TenantID != spec.TenantID {
+		t.Errorf("Update() reported tenant %q, want %q", result.TenantID, spec.TenantID)
+	}
+	s.waitReady(t, p, spec.TenantID)
+
+	again := s.withMetadata(p, s.cfg.Spec(spec.TenantID))
+	s.cfg.Change(again)
+	result, err = p.Update(ctx, spec.TenantID, again)
+	if err != nil {
+		t.Fatalf("repeating Update() failed: %v", err)
+	}
+	if result.Status != compute.UpdateStatusNoChanges || len(result.Changes) > 0 {
+		t.Errorf("repeating Update() reported status %q and changes %v, want %q and none",
+			result.Status, result.Changes, compute.UpdateStatusNoChanges)
+	}
+}
+
+// testLabelPropagation checks that the spec's labels, including the default metadata, reach the
+// backend, where inventory and operators find compute by them
+func (s *suite) testLabelPropagation(t *testing.T) {
+	if s.cfg.Labels == nil {
+		t.Skip("Config.Labels is not set")
+	}
+	p := s.cfg.NewProvider(t)
+	spec := s.spec(t, p)
+	spec.Labels = compute.MergeLabels(spec.Labels, map[string]string{"landlord.conformance.label": "propagated"})
+	s.provision(t, p, spec)
+	s.waitReady(t, p, spec.TenantID)
+
+	labels, err := s.cfg.Labels(context.Background(), p, spec.TenantID)
+	if err != nil {
+		t.Fatalf("reading the backend's labels failed: %v", err)
+	}
+	for key, value := range spec.Labels {
+		if got, ok := labels[key]; !ok || got != value {
+			t.Errorf("label %s = %q on the backend, want %q", key, got, value)
+		}
+	}
+}
+
+// testRestartRecovery checks that a restarted provider finds and manages compute created before
+// the restart
+func (s *suite) testRestartRecovery(t *testing.T) {
+	if s.cfg.Restart == nil {
+		t.Skip("Config.Restart is not set")
+	}
+	p := s.cfg.NewProvider(t)
+	ctx := context.Background()
+	spec := s.spec(t, p)
+	s.provision(t, p, spec)
+	s.waitReady(t, p, spec.TenantID)
+
+	restarted := s.cfg.Restart(t, p)
+	s.waitReady(t, restarted, spec.TenantID)
+	s.expectInventory(t, restarted, spec.TenantID, true)
+
+	result, err := restarted.Update(ctx, spec.TenantID, s.withMetadata(restarted, s.cfg.Spec(spec.TenantID)))
+	if err != nil {
+		t.Fatalf("Update() after a restart failed: %v", err)
+	}
+	if result.Status != compute.UpdateStatusNoChanges || len(result.Changes) > 0 {
+		t.Errorf("Update() with the provisioned spec after a restart reported status %q and changes %v, want %q and none",
+			result.Status, result.Changes, compute.UpdateStatusNoChanges)
+	}
+
+	if err := restarted.Destroy(ctx, spec.TenantID); err != nil {
+		t.Fatalf("Destroy() after a restart failed: %v", err)
+	}
+	s.waitGone(t, restarted, spec.TenantID)
+}
+
+// testPowerManager checks restart, suspend and resume for providers that implement PowerManager
+func (s *suite) testPowerManager(t *testing.T) {
+	p := s.cfg.NewProvider(t)
+	power, ok := p.(compute.PowerManager)
+	if !ok {
+		t.Skip("the provider does not implement compute.PowerManager")
+	}
+	ctx := context.Background()
+
+	if err := power.Restart(ctx, s.tenantID()); !errors.Is(err, compute.ErrTenantNotFound) {
+		t.Errorf("Restart() of an unknown tenant returned %v, want an error wrapping ErrTenantNotFound", err)
+	}
+
+	spec := s.spec(t, p)
+	s.provision(t, p, spec)
+	s.waitReady(t, p, spec.TenantID)
+
+	if err := power.Restart(ctx, spec.TenantID); err != nil {
+		t.Fatalf("Restart() failed: %v", err)
+	}
+	s.waitReady(t, p, spec.TenantID)
+
+	if err := power.Suspend(ctx, spec.TenantID); err != nil {
+		t.Fatalf("Suspend() failed: %v", err)
+	}
+	s.waitFor(t, p, spec.TenantID, "stopped", func(status *compute.ComputeStatus) bool {
+		return status.State == compute.ComputeStateStopped
+	})
+
+	if err := power.Resume(ctx, spec.TenantID); err != nil {
+		t.Fatalf("Resume() failed: %v", err)
+	}
+	s.waitReady(t, p, spec.TenantID)
+	if err := power.Resume(ctx, spec.TenantID); err != nil {
+		t.Errorf("Resume() of a running tenant failed: %v", err)
+	}
+}
+
+// tenantID returns a tenant ID no other test uses
+func (s *suite) tenantID() string {
+	return fmt.Sprintf("%s-%s", s.cfg.TenantPrefix, strings.ReplaceAll(uuid.NewString(), "-", "")[:12])
+}
+
+// spec returns a spec for a new tenant and destroys the tenant's compute when the test finishes
+func (s *suite) spec(t *testing.T, p compute.Provider) *compute.TenantComputeSpec {
+	t.Helper()
+	tenantID := s.tenantID()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+		if err := p.Destroy(ctx, tenantID); err != nil && !errors.Is(err, compute.ErrTenantNotFound) {
+			t.Logf("cleaning up %s failed: %v", tenantID, err)
+		}
+	})
+	return s.withMetadata(p, s.cfg.Spec(tenantID))
+}
+
+// withMetadata sets the provider type and default metadata labels, as the compute manager does
+func (s *suite) withMetadata(p compute.Provider, spec *compute.TenantComputeSpec) *compute.TenantComputeSpec {
+	spec.ProviderType = p.Name()
+	compute.ApplyDefaultMetadata(spec)
+	return spec
+}
+
+func (s *suite) provision(t *testing.T, p compute.Provider, spec *compute.TenantComputeSpec) *compute.ProvisionResult {
+	t.Helper()
+	result, err := p.Provision(context.Background(), spec)
+	if err != nil {
+		t.Fatalf("Provision() failed: %v", err)
+	}
+	if result == nil {
+		t.Fatal("Provision() returned no result")
+	}
+	return result
+}
+
+func (s *suite) waitReady(t *testing.T, p compute.Provider, tenantID string) *compute.ComputeStatus {
+	t.Helper()
+	return s.waitFor(t, p, tenantID, "ready", func(status *compute.ComputeStatus) bool {
+		return status.IsReady()
+	})
+}
+
+// waitFor polls GetStatus until done reports true, failing the test on timeout or when the
+// compute fails
+func (s *suite) waitFor(t *testing.T, p compute.Provider, tenantID, want string, done func(*compute.ComputeStatus) bool) *compute.ComputeStatus {
+	t.Helper()
+	deadline := time.Now().Add(s.cfg.ReadyTimeout)
+	for {
+		status, err := p.GetStatus(context.Background(), tenantID)
+		switch {
+		case err != nil:
+			t.Fatalf("GetStatus() of %s failed while waiting for it to be %s: %v", tenantID, want, err)
+		case status == nil:
+			t.Fatalf("GetStatus() of %s returned no status", tenantID)
+		case done(status):
+			return status
+		case status.State == compute.ComputeStateFailed:
+			t.Fatalf("%s failed while waiting for it to be %s: %+v", tenantID, want, status.Containers)
+		case time.Now().After(deadline):
+			t.Fatalf("%s was not %s within %s (last state %s, health %s)", tenantID, want, s.cfg.ReadyTimeout, status.State, status.Health)
+		}
+		time.Sleep(s.cfg.PollInterval)
+	}
+}
+
+// waitGone polls GetStatus until it reports ErrTenantNotFound
+func (s *suite) waitGone(t *testing.T, p compute.Provider, tenantID string) {
+	t.Helper()
+	deadline := time.Now().Add(s.cfg.ReadyTimeout)
+	for {
+		_, err := p.GetStatus(context.Background(), tenantID)
+		switch {
+		case errors.Is(err, compute.ErrTenantNotFound):
+			return
+		case time.Now().After(deadline):
+			t.Fatalf("GetStatus() of %s still returned %v %s after Destroy(), want an error wrapping ErrTenantNotFound", tenantID, err, s.cfg.ReadyTimeout)
+		}
+		time.Sleep(s.cfg.PollInterval)
+	}
+}
+
+// expectInventory checks whether ListTenants includes the tenant, for providers that implement
+// Inventory
+func (s *suite) expectInventory(t *testing.T, p compute.Provider, tenantID string, want bool) {
+	t.Helper()
+	inventory, ok := p.(compute.Inventory)
+	if !ok {
+		return
+	}
+	tenantIDs, err := inventory.ListTenants(context.Background())
+	if err != nil {
+		t.Fatalf("ListTenants() failed: %v", err)
+	}
+	if got := slices.Contains(tenantIDs, tenantID); got != want {
+		t.Errorf("ListTenants() includes %s: %t, want %t", tenantID, got, want)
+	}
+}
+
+// doubleMemory is the default Change
+func doubleMemory(spec *compute.TenantComputeSpec) {
+	spec.Resources.Memory = max(spec.Resources.Memory*2, 256)
+	for i := range spec.Containers {
+		if r := spec.Containers[i].Resources; r != nil && r.Memory > 0 {
+			r.Memory *= 2
+		}
+	}
+}
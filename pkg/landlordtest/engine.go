@@ -0,0 +1,92 @@
+package landlordtest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	workflowmock "github.com/jaxxstorm/landlord/internal/workflow/providers/mock"
+)
+
+// engine is the mock workflow provider with workflows created on first use and injectable failures.
+// The controller names a workflow per tenant and action, so they cannot be created up front.
+type engine struct {
+	*workflowmock.Provider
+
+	mu       sync.Mutex
+	failNext map[string][]string // operation -> queued failure messages
+	failed   map[string]string   // execution ID -> failure message
+}
+
+func newEngine(provider *workflowmock.Provider) *engine {
+	return &engine{
+		Provider: provider,
+		failNext: make(map[string][]string),
+		failed:   make(map[string]string),
+	}
+}
+
+// failNextExecution queues a failure for the next execution of the operation
+func (e *engine) failNextExecution(operation, message string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failNext[operation] = append(e.failNext[operation], message)
+}
+
+func (e *engine) Invoke(ctx context.Context, workflowID string, request *workflow.ProvisionRequest) (*workflow.ExecutionResult, error) {
+	if _, err := e.Provider.CreateWorkflow(ctx, &workflow.WorkflowSpec{
+		WorkflowID:   workflowID,
+		ProviderType: e.Name(),
+		Name:         workflowID,
+		Definition:   []byte(`{}`),
+	}); err != nil {
+		return nil, err
+	}
+
+	result, err := e.Provider.Invoke(ctx, workflowID, request)
+	if err != nil || request == nil {
+		return result, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if queued := e.failNext[request.Operation]; len(queued) > 0 {
+		e.failed[result.ExecutionID] = queued[0]
+		e.failNext[request.Operation] = queued[1:]
+		result.State = workflow.StateFailed
+	}
+	return result, nil
+}
+
+func (e *engine) GetExecutionStatus(ctx context.Context, executionID string) (*workflow.ExecutionStatus, error) {
+	status, err := e.Provider.GetExecutionStatus(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	message, failed := e.failed[executionID]
+	e.mu.Unlock()
+	if !failed {
+		return status, nil
+	}
+
+	failedStatus := *status
+	failedStatus.State = workflow.StateFailed
+	failedStatus.Output = nil
+	failedStatus.Error = &workflow.ExecutionError{Code: "InjectedFailure", Message: message}
+	return &failedStatus, nil
+}
+
+func (e *engine) GetWorkflowStatus(ctx context.Context, executionID string) (*workflow.WorkflowStatus, error) {
+	status, err := e.GetExecutionStatus(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	return &workflow.WorkflowStatus{
+		ExecutionID: status.ExecutionID,
+		State:       status.State,
+		Output:      status.Output,
+		Error:       status.Error,
+	}, nil
+}
@@ -0,0 +1,513 @@
+// Package landlordtest runs a complete Landlord control plane in-process for integration tests.
+//
+// A Harness serves the HTTP API from an httptest server and runs the reconciler against an
+// in-memory tenant repository, the mock compute provider and a mock workflow engine, so tests
+// can drive tenants through their lifecycle without containers or external services:
+//
+//	h := landlordtest.New(t, landlordtest.Options{})
+//	tenant := h.CreateTenantAndWaitReady("acme", map[string]interface{}{"image": "nginx:latest"})
+package landlordtest
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jaxxstorm/landlord/internal/api"
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/approval"
+	approvalmemory "github.com/jaxxstorm/landlord/internal/approval/memory"
+	"github.com/jaxxstorm/landlord/internal/cli"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	computemock "github.com/jaxxstorm/landlord/internal/compute/providers/mock"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/controller"
+	"github.com/jaxxstorm/landlord/internal/cost"
+	"github.com/jaxxstorm/landlord/internal/doctor"
+	"github.com/jaxxstorm/landlord/internal/endpointauth"
+	executionmemory "github.com/jaxxstorm/landlord/internal/execution/memory"
+	"github.com/jaxxstorm/landlord/internal/imagepolicy"
+	"github.com/jaxxstorm/landlord/internal/imageupdate"
+	"github.com/jaxxstorm/landlord/internal/observe"
+	operationmemory "github.com/jaxxstorm/landlord/internal/operation/memory"
+	"github.com/jaxxstorm/landlord/internal/project"
+	projectmemory "github.com/jaxxstorm/landlord/internal/project/memory"
+	"github.com/jaxxstorm/landlord/internal/providerconfig"
+	providerconfigmemory "github.com/jaxxstorm/landlord/internal/providerconfig/memory"
+	"github.com/jaxxstorm/landlord/internal/resolution"
+	"github.com/jaxxstorm/landlord/internal/schedule"
+	schedulememory "github.com/jaxxstorm/landlord/internal/schedule/memory"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	tenantcache "github.com/jaxxstorm/landlord/internal/tenant/cache"
+	"github.com/jaxxstorm/landlord/internal/tenant/memory"
+	"github.com/jaxxstorm/landlord/internal/uptime"
+	"github.com/jaxxstorm/landlord/internal/warmpool"
+	"github.com/jaxxstorm/landlord/internal/workflow"
+	workflowmock "github.com/jaxxstorm/landlord/internal/workflow/providers/mock"
+)
+
+const (
+	defaultReconcileInterval = 20 * time.Millisecond
+	defaultWaitTimeout       = 10 * time.Second
+
+	// maxStatusWait bounds each long poll, within the client's request timeout
+	maxStatusWait = 5 * time.Second
+)
+
+// Options configures a Harness. The zero value is ready to use.
+type Options struct {
+	// ReconcileInterval is how often the reconciler polls for work (default 20ms)
+	ReconcileInterval time.Duration
+
+	// WaitTimeout bounds how long the wait helpers poll before failing the test (default 10s)
+	WaitTimeout time.Duration
+
+	// Logger receives control plane logs (default: discarded)
+	Logger *zap.Logger
+
+	// ImagePolicy is enforced on tenant creates and updates when it restricts anything, and
+	// re-checked by the compliance scan when its ScanInterval is set
+	ImagePolicy config.ImagePolicyConfig
+
+	// ImageUpdate moves tenants annotated with landlord/image-update to new images from its registries.
+	// Promotions pin images in its registries to their digest whether or not it is enabled.
+	ImageUpdate config.ImageUpdateConfig
+
+	// Approvals makes archive and delete of protected tenants wait for a second principal
+	Approvals config.ApprovalConfig
+
+	// Emergency enables POST /v1/admin/tenants/{id}/emergency, which acts on the mock compute provider directly
+	Emergency config.EmergencyConfig
+
+	// Schedules runs tenant schedules against the mock compute provider
+	Schedules config.ScheduleConfig
+
+	// ComputeResolution chooses a compute provider for tenants that do not name one
+	ComputeResolution config.ComputeResolutionConfig
+
+	// Cost prices the tenants of /v1/simulate
+	Cost config.CostConfig
+
+	// WarmPools keeps the warm pools in project settings filled, and lets creates claim from them
+	WarmPools config.WarmPoolConfig
+
+	// Uptime probes ready tenants' endpoints and serves the results from /v1/tenants/{id}/uptime
+	Uptime config.UptimeConfig
+
+	// EndpointAuth serves the endpoint credentials of tenants that set compute_config.endpoint_auth.
+	// The mock workflow engine does not inject them into the tenant.
+	EndpointAuth config.EndpointAuthConfig
+
+	// TenantCache serves the read-only tenant endpoints from an in-process cache
+	TenantCache config.TenantCacheConfig
+
+	// ListCache sets the Cache-Control header of tenant lists and serves identical lists from an
+	// in-process cache
+	ListCache config.ListCacheConfig
+
+	// TriggerOutbox makes the reconciler queue workflow triggers in the repository's outbox and start
+	// them from there. Queueing writes to the repository directly, so it bypasses TenantCache.
+	TriggerOutbox config.TriggerOutboxConfig
+
+	// Doctor configures the checks served from /v1/admin/doctor (default: 30m stuck threshold, 10s check timeout).
+	// Compute resources are not compared with tenants, because the mock workflow engine does not provision them.
+	Doctor config.DoctorConfig
+
+	// Observe refreshes ready tenants' observed_config.compute_status from the mock compute provider.
+	// The mock workflow engine does not provision mock compute, so tenants it made ready read as unknown
+	// until their compute is provisioned through ComputeProvider.
+	Observe config.ObserveConfig
+}
+
+// Harness is an in-process Landlord control plane
+type Harness struct {
+	tb          testing.TB
+	server      *httptest.Server
+	client      *cli.Client
+	repo        *memory.Repository
+	projects    *projectmemory.Store
+	compute     *computemock.Provider
+	engine      *engine
+	reconciler  *controller.Reconciler
+	scanner     *imagepolicy.Scanner
+	updater     *imageupdate.Updater
+	schedules   *schedule.Controller
+	warmPools   *warmpool.Controller
+	uptime      *uptime.Checker
+	refresher   *observe.Refresher
+	waitTimeout time.Duration
+}
+
+// New starts a harness; it is shut down when the test finishes
+func New(tb testing.TB, opts Options) *Harness {
+	tb.Helper()
+
+	if opts.ReconcileInterval <= 0 {
+		opts.ReconcileInterval = defaultReconcileInterval
+	}
+	if opts.WaitTimeout <= 0 {
+		opts.WaitTimeout = defaultWaitTimeout
+	}
+	if opts.Doctor.StuckThreshold <= 0 {
+		opts.Doctor.StuckThreshold = 30 * time.Minute
+	}
+	if opts.Doctor.CheckTimeout <= 0 {
+		opts.Doctor.CheckTimeout = 10 * time.Second
+	}
+	log := opts.Logger
+	if log == nil {
+		log = zap.NewNop()
+	}
+
+	repo := memory.New()
+	projects := projectmemory.New()
+	// Status changes go through the notifying repository so project webhooks fire as in production
+	var tenants tenant.Repository = project.NewNotifyingRepository(repo, project.NewNotifier(projects, log))
+	// Every component writes through the cache, so none of them reads a tenant it changed from it
+	var cache *tenantcache.Cache
+	if opts.TenantCache.Enabled {
+		cache = tenantcache.New(tenants, opts.TenantCache, log)
+		tenants = cache
+	}
+
+	computeRegistry := compute.NewRegistry(log)
+	computeProvider := computemock.New()
+	if err := computeRegistry.Register(computeProvider); err != nil {
+		tb.Fatalf("register mock compute provider: %v", err)
+	}
+
+	workflowRegistry := workflow.NewRegistry(log)
+	engine := newEngine(workflowmock.New(log))
+	if err := workflowRegistry.Register(engine); err != nil {
+		tb.Fatalf("register mock workflow engine: %v", err)
+	}
+	workflowClient := controller.NewWorkflowClient(workflow.New(workflowRegistry, log), log, 5*time.Second, engine.Name())
+
+	reconciler := controller.NewReconciler(tenants, workflowClient, config.ControllerConfig{
+		Enabled:                true,
+		ReconciliationInterval: opts.ReconcileInterval,
+		StatusPollInterval:     opts.ReconcileInterval,
+		Workers:                2,
+		WorkflowTriggerTimeout: 5 * time.Second,
+		ShutdownTimeout:        5 * time.Second,
+		MaxRetries:             3,
+		TriggerOutbox:          opts.TriggerOutbox,
+	}, log)
+	if opts.TriggerOutbox.Enabled {
+		reconciler.SetTriggerOutbox(repo)
+	}
+
+	srv := api.New(&config.HTTPConfig{}, healthyDatabase{}, computeRegistry, computeProvider.Name(), tenants, workflowClient, log)
+	srv.SetController(reconciler)
+	if cache != nil {
+		srv.SetTenantCache(cache)
+	}
+	srv.SetListCache(opts.ListCache)
+	srv.SetProjects(projects)
+	srv.SetProviderAdmin(providerconfig.NewManager(computeRegistry, workflowRegistry, providerconfigmemory.New(), log))
+	srv.SetComputeResolution(resolution.New(opts.ComputeResolution))
+	srv.SetCostEstimator(cost.New(opts.Cost))
+	diagnostics := doctor.New(opts.Doctor, log)
+	diagnostics.Register(
+		doctor.DatabaseCheck(healthyDatabase{}),
+		doctor.ControllerCheck(reconciler),
+		doctor.ComputeProvidersCheck(computeRegistry),
+		doctor.WorkflowProvidersCheck(workflowRegistry),
+		doctor.StuckTenantsCheck(tenants, opts.Doctor.StuckThreshold),
+	)
+	srv.SetDoctor(diagnostics)
+	var policy *imagepolicy.Policy
+	var scanner *imagepolicy.Scanner
+	if opts.ImagePolicy.Enabled() {
+		policy = imagepolicy.New(opts.ImagePolicy, log)
+		srv.SetImagePolicy(policy)
+		if opts.ImagePolicy.ScanInterval > 0 {
+			scanner = imagepolicy.NewScanner(policy, tenants, opts.ImagePolicy.ScanInterval, log)
+		}
+	}
+	registries := imageupdate.NewRegistries(opts.ImageUpdate.Registries)
+	srv.SetImageRegistries(registries)
+	var updater *imageupdate.Updater
+	if opts.ImageUpdate.Enabled {
+		updater = imageupdate.NewUpdater(tenants, registries, policy, opts.ImageUpdate.Interval, log)
+	}
+	if opts.Approvals.Enabled {
+		srv.SetApprovals(approvalmemory.New(), approval.NewPolicy(opts.Approvals))
+	}
+	srv.SetEmergency(opts.Emergency)
+	srv.SetHistoryScrubber(repo)
+	srv.SetOperations(operationmemory.New())
+	executionSteps := executionmemory.New()
+	srv.SetExecutions(executionSteps)
+	reconciler.SetExecutionSteps(executionSteps)
+	var schedules *schedule.Controller
+	if opts.Schedules.Enabled {
+		store := schedulememory.New()
+		srv.SetSchedules(store)
+		executor := schedule.NewComputeExecutor(computeRegistry, computeProvider.Name(), workflow.NewHookRunner(nil, log))
+		schedules = schedule.NewController(store, tenants, executor, opts.Schedules, log)
+	}
+	var warmPools *warmpool.Controller
+	if opts.WarmPools.Enabled {
+		warmPools = warmpool.NewController(tenants, projects, opts.WarmPools, log)
+		srv.SetWarmPools(warmPools)
+	}
+	var checker *uptime.Checker
+	if opts.Uptime.Enabled {
+		checker = uptime.NewChecker(tenants, opts.Uptime, log)
+		srv.SetUptime(checker)
+	}
+	var refresher *observe.Refresher
+	if opts.Observe.Enabled {
+		refresher = observe.NewRefresher(tenants, computeRegistry, computeProvider.Name(), resolution.New(opts.ComputeResolution), opts.Observe, log)
+	}
+	if opts.EndpointAuth.Enabled {
+		srv.SetEndpointAuth(endpointauth.New(opts.EndpointAuth))
+	}
+	server := httptest.NewServer(srv.Handler())
+
+	if err := reconciler.Start(); err != nil {
+		server.Close()
+		tb.Fatalf("start reconciler: %v", err)
+	}
+	if scanner != nil {
+		if err := scanner.Start(); err != nil {
+			_ = reconciler.Stop()
+			server.Close()
+			tb.Fatalf("start image policy scanner: %v", err)
+		}
+	}
+	if updater != nil {
+		if err := updater.Start(); err != nil {
+			if scanner != nil {
+				scanner.Stop()
+			}
+			_ = reconciler.Stop()
+			server.Close()
+			tb.Fatalf("start image updater: %v", err)
+		}
+	}
+	if schedules != nil {
+		if err := schedules.Start(); err != nil {
+			if updater != nil {
+				updater.Stop()
+			}
+			if scanner != nil {
+				scanner.Stop()
+			}
+			_ = reconciler.Stop()
+			server.Close()
+			tb.Fatalf("start schedule controller: %v", err)
+		}
+	}
+	if warmPools != nil {
+		if err := warmPools.Start(); err != nil {
+			if schedules != nil {
+				schedules.Stop()
+			}
+			if updater != nil {
+				updater.Stop()
+			}
+			if scanner != nil {
+				scanner.Stop()
+			}
+			_ = reconciler.Stop()
+			server.Close()
+			tb.Fatalf("start warm pool controller: %v", err)
+		}
+	}
+	if checker != nil {
+		if err := checker.Start(); err != nil {
+			if warmPools != nil {
+				warmPools.Stop()
+			}
+			if schedules != nil {
+				schedules.Stop()
+			}
+			if updater != nil {
+				updater.Stop()
+			}
+			if scanner != nil {
+				scanner.Stop()
+			}
+			_ = reconciler.Stop()
+			server.Close()
+			tb.Fatalf("start uptime checker: %v", err)
+		}
+	}
+	if refresher != nil {
+		if err := refresher.Start(); err != nil {
+			if checker != nil {
+				checker.Stop()
+			}
+			if warmPools != nil {
+				warmPools.Stop()
+			}
+			if schedules != nil {
+				schedules.Stop()
+			}
+			if updater != nil {
+				updater.Stop()
+			}
+			if scanner != nil {
+				scanner.Stop()
+			}
+			_ = reconciler.Stop()
+			server.Close()
+			tb.Fatalf("start observe refresher: %v", err)
+		}
+	}
+
+	h := &Harness{
+		tb:          tb,
+		server:      server,
+		client:      cli.NewClient(server.URL),
+		repo:        repo,
+		projects:    projects,
+		compute:     computeProvider,
+		engine:      engine,
+		reconciler:  reconciler,
+		scanner:     scanner,
+		updater:     updater,
+		schedules:   schedules,
+		warmPools:   warmPools,
+		uptime:      checker,
+		refresher:   refresher,
+		waitTimeout: opts.WaitTimeout,
+	}
+	tb.Cleanup(h.close)
+	return h
+}
+
+func (h *Harness) close() {
+	if h.refresher != nil {
+		h.refresher.Stop()
+	}
+	if h.uptime != nil {
+		h.uptime.Stop()
+	}
+	if h.warmPools != nil {
+		h.warmPools.Stop()
+	}
+	if h.schedules != nil {
+		h.schedules.Stop()
+	}
+	if h.updater != nil {
+		h.updater.Stop()
+	}
+	if h.scanner != nil {
+		h.scanner.Stop()
+	}
+	if err := h.reconciler.Stop(); err != nil {
+		h.tb.Logf("stop reconciler: %v", err)
+	}
+	h.server.Close()
+}
+
+// URL returns the base URL of the API server
+func (h *Harness) URL() string {
+	return h.server.URL
+}
+
+// Client returns an API client for the harness
+func (h *Harness) Client() *cli.Client {
+	return h.client
+}
+
+// Projects returns the organization and project store, for seeding projects that tests create tenants in
+func (h *Harness) Projects() project.Store {
+	return h.projects
+}
+
+// Repository returns the tenant store, for assertions on state the API does not expose
+func (h *Harness) Repository() tenant.Repository {
+	return h.repo
+}
+
+// ComputeProvider returns the mock compute provider tenants are placed on
+func (h *Harness) ComputeProvider() *computemock.Provider {
+	return h.compute
+}
+
+// FailNextProvision makes the next provision workflow fail with message
+func (h *Harness) FailNextProvision(message string) {
+	h.FailNext("provision", message)
+}
+
+// FailNext makes the next workflow for the operation (provision, update or delete) fail with message
+func (h *Harness) FailNext(operation, message string) {
+	h.engine.failNextExecution(operation, message)
+}
+
+// CreateTenant creates a tenant through the API, failing the test on error
+func (h *Harness) CreateTenant(name string, computeConfig map[string]interface{}) *models.TenantResponse {
+	h.tb.Helper()
+
+	created, err := h.client.CreateTenant(context.Background(), models.CreateTenantRequest{
+		Name:          name,
+		ComputeConfig: computeConfig,
+	})
+	if err != nil {
+		h.tb.Fatalf("create tenant %s: %v", name, err)
+	}
+	return created
+}
+
+// CreateTenantAndWaitReady creates a tenant and waits for the reconciler to make it ready
+func (h *Harness) CreateTenantAndWaitReady(name string, computeConfig map[string]interface{}) *models.TenantResponse {
+	h.tb.Helper()
+
+	created := h.CreateTenant(name, computeConfig)
+	return h.WaitForStatus(created.ID, tenant.StatusReady)
+}
+
+// WaitForStatus long-polls the API until the tenant reaches status, failing the test on timeout.
+// Reaching failed while waiting for another status fails the test immediately.
+func (h *Harness) WaitForStatus(tenantID string, status tenant.Status) *models.TenantResponse {
+	h.tb.Helper()
+
+	ctx := context.Background()
+	deadline := time.Now().Add(h.waitTimeout)
+	var last *models.TenantStatusResponse
+	version := -1
+	for {
+		wait := min(time.Until(deadline), maxStatusWait)
+		current, err := h.client.WaitForTenantStatus(ctx, tenantID, version, max(wait, 0))
+		if err != nil {
+			h.tb.Fatalf("get tenant %s status: %v", tenantID, err)
+		}
+		if current != nil {
+			last = current
+			version = current.Version
+			if current.Status == string(status) {
+				resp, err := h.client.GetTenant(ctx, current.ID)
+				if err != nil {
+					h.tb.Fatalf("get tenant %s: %v", tenantID, err)
+				}
+				return resp
+			}
+			if current.Status == string(tenant.StatusFailed) {
+				h.tb.Fatalf("tenant %s failed while waiting for %s: %s", tenantID, status, current.StatusMessage)
+			}
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+	}
+
+	h.tb.Fatalf("tenant %s did not reach %s within %s (last status %s: %s)",
+		tenantID, status, h.waitTimeout, last.Status, last.StatusMessage)
+	return nil
+}
+
+// healthyDatabase satisfies the readiness check; the harness has no database
+type healthyDatabase struct{}
+
+func (healthyDatabase) Pool() interface{} { return nil }
+
+func (healthyDatabase) Health(ctx context.Context) error { return nil }
+
+func (healthyDatabase) Close() {}
@@ -0,0 +1,207 @@
+package landlordtest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaxxstorm/landlord/internal/api/models"
+	"github.com/jaxxstorm/landlord/internal/compute"
+	"github.com/jaxxstorm/landlord/internal/config"
+	"github.com/jaxxstorm/landlord/internal/observe"
+	"github.com/jaxxstorm/landlord/internal/project"
+	"github.com/jaxxstorm/landlord/internal/tenant"
+	"github.com/jaxxstorm/landlord/internal/warmpool"
+)
+
+func TestCreateTenantAndWaitReady(t *testing.T) {
+	h := New(t, Options{})
+
+	ready := h.CreateTenantAndWaitReady("acme", map[string]interface{}{"image": "nginx:latest"})
+	if ready.Name != "acme" {
+		t.Errorf("expected tenant acme, got %s", ready.Name)
+	}
+
+	stored, err := h.Repository().GetTenantByName(context.Background(), "acme")
+	if err != nil {
+		t.Fatalf("GetTenantByName() error = %v", err)
+	}
+	if stored.Status != tenant.StatusReady {
+		t.Errorf("expected stored status ready, got %s", stored.Status)
+	}
+}
+
+func TestFailNextProvision(t *testing.T) {
+	h := New(t, Options{})
+
+	h.FailNextProvision("quota exceeded")
+	created := h.CreateTenant("doomed", map[string]interface{}{"image": "nginx:latest"})
+	failed := h.WaitForStatus(created.ID, tenant.StatusFailed)
+	if !strings.Contains(failed.StatusMessage, "quota exceeded") {
+		t.Errorf("expected injected failure in status message, got %q", failed.StatusMessage)
+	}
+
+	// Only the next provision fails
+	h.CreateTenantAndWaitReady("healthy", map[string]interface{}{"image": "nginx:latest"})
+}
+
+func TestWarmPoolClaim(t *testing.T) {
+	h := New(t, Options{WarmPools: config.WarmPoolConfig{Enabled: true, Interval: 20 * time.Millisecond}})
+	ctx := context.Background()
+
+	p, err := h.Projects().GetProjectByID(ctx, project.DefaultProjectID)
+	if err != nil {
+		t.Fatalf("GetProjectByID() error = %v", err)
+	}
+	p.Settings.Templates = map[string]map[string]interface{}{"small": {"image": "nginx:latest"}}
+	p.Settings.WarmPools = []project.WarmPool{{Template: "small", Size: 1}}
+	if err := h.Projects().UpdateProject(ctx, p); err != nil {
+		t.Fatalf("UpdateProject() error = %v", err)
+	}
+
+	warm := waitForWarmTenant(t, h)
+	created, err := h.Client().CreateTenant(ctx, models.CreateTenantRequest{Name: "acme", Template: "small"})
+	if err != nil {
+		t.Fatalf("CreateTenant() error = %v", err)
+	}
+	if created.ID != warm.ID.String() || created.Status != string(tenant.StatusReady) {
+		t.Fatalf("expected acme to be the ready warm tenant %s, got %s in %s", warm.ID, created.ID, created.Status)
+	}
+	if created.Annotations[tenant.AnnotationComputeName] != warm.Name {
+		t.Errorf("expected acme to keep the warm tenant's compute %s, got %v", warm.Name, created.Annotations)
+	}
+
+	// The pool is refilled, and a config beyond the template is applied by an update
+	waitForWarmTenant(t, h)
+	created, err = h.Client().CreateTenant(ctx, models.CreateTenantRequest{
+		Name:          "globex",
+		Template:      "small",
+		ComputeConfig: map[string]interface{}{"env": map[string]interface{}{"REGION": "eu"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateTenant() error = %v", err)
+	}
+	if created.Status != string(tenant.StatusUpdating) {
+		t.Fatalf("expected globex to be updated from the template, got %s", created.Status)
+	}
+	h.WaitForStatus(created.ID, tenant.StatusReady)
+}
+
+// waitForWarmTenant waits for the default project's warm pool to hold a ready tenant
+func waitForWarmTenant(t *testing.T, h *Harness) *tenant.Tenant {
+	t.Helper()
+	deadline := time.Now().Add(defaultWaitTimeout)
+	for time.Now().Before(deadline) {
+		tenants, err := h.Repository().ListTenants(context.Background(), tenant.ListFilters{Statuses: []tenant.Status{tenant.StatusReady}})
+		if err != nil {
+			t.Fatalf("ListTenants() error = %v", err)
+		}
+		for _, candidate := range tenants {
+			if warmpool.IsWarm(candidate) {
+				return candidate
+			}
+		}
+		time.Sleep(defaultReconcileInterval)
+	}
+	t.Fatalf("no warm tenant became ready within %s", defaultWaitTimeout)
+	return nil
+}
+
+func TestTenantCacheSeesControllerWrites(t *testing.T) {
+	h := New(t, Options{TenantCache: config.TenantCacheConfig{Enabled: true, TTL: time.Hour, MaxEntries: 100}})
+
+	// Waiting reads the tenant through the cached API on every poll, so each status the
+	// reconciler writes must drop the cached copy
+	created := h.CreateTenant("cached", map[string]interface{}{"image": "nginx:latest"})
+	h.WaitForStatus(created.ID, tenant.StatusReady)
+
+	archived, err := h.Client().ArchiveTenant(context.Background(), created.ID)
+	if err != nil {
+		t.Fatalf("ArchiveTenant() error = %v", err)
+	}
+	h.WaitForStatus(archived.ID, tenant.StatusArchived)
+}
+
+func TestTriggerOutboxRunsLifecycle(t *testing.T) {
+	h := New(t, Options{TriggerOutbox: config.TriggerOutboxConfig{
+		Enabled:      true,
+		PollInterval: 10 * time.Millisecond,
+		BatchSize:    10,
+		ClaimTimeout: time.Minute,
+		MaxAttempts:  3,
+	}})
+
+	created := h.CreateTenantAndWaitReady("outbox", map[string]interface{}{"image": "nginx:latest"})
+	archived, err := h.Client().ArchiveTenant(context.Background(), created.ID)
+	if err != nil {
+		t.Fatalf("ArchiveTenant() error = %v", err)
+	}
+	h.WaitForStatus(archived.ID, tenant.StatusArchived)
+
+	// Provisioning and archiving were each queued once and published
+	entries := h.repo.OutboxEntries()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 outbox entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.PublishedAt == nil {
+			t.Errorf("outbox entry %s was not published", entry.DedupKey)
+		}
+	}
+}
+
+func TestDoctorReportsFailedTenants(t *testing.T) {
+	h := New(t, Options{})
+	ctx := context.Background()
+	h.CreateTenantAndWaitReady("acme", map[string]interface{}{"image": "nginx:latest"})
+
+	report, err := h.Client().Doctor(ctx)
+	if err != nil {
+		t.Fatalf("Doctor() error = %v", err)
+	}
+	if report.Status != "pass" {
+		t.Fatalf("expected a healthy control plane to pass, got %+v", report)
+	}
+
+	h.FailNextProvision("quota exceeded")
+	failed := h.CreateTenant("doomed", map[string]interface{}{"image": "nginx:latest"})
+	h.WaitForStatus(failed.ID, tenant.StatusFailed)
+
+	report, err = h.Client().Doctor(ctx)
+	if err != nil {
+		t.Fatalf("Doctor() error = %v", err)
+	}
+	if report.Status != "warn" || len(report.Results) == 0 {
+		t.Fatalf("expected the failed tenant to warn, got %+v", report)
+	}
+	first := report.Results[0]
+	if first.Check != "tenants/failed" || len(first.Details) != 1 || !strings.Contains(first.Details[0], failed.ID) || first.Fix == "" {
+		t.Errorf("expected the failed tenant to be reported first with a fix, got %+v", first)
+	}
+}
+
+func TestObserveRefreshesComputeStatus(t *testing.T) {
+	h := New(t, Options{Observe: config.ObserveConfig{Enabled: true, Interval: 20 * time.Millisecond, Timeout: time.Second}})
+	ctx := context.Background()
+	ready := h.CreateTenantAndWaitReady("acme", map[string]interface{}{"image": "nginx:latest"})
+	if _, err := h.ComputeProvider().Provision(ctx, &compute.TenantComputeSpec{TenantID: ready.Name, ProviderType: "mock"}); err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := h.Client().GetTenant(ctx, ready.ID)
+		if err != nil {
+			t.Fatalf("GetTenant() error = %v", err)
+		}
+		status, _ := got.ObservedConfig[observe.ComputeStatusKey].(map[string]interface{})
+		if status["state"] == "running" && got.ObservedResourceIDs["tenant"] == ready.Name {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the running compute to be observed, got %+v", got.ObservedConfig)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}